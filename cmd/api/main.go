@@ -4,15 +4,42 @@
 package main
 
 import (
-	"log" // For logging application startup and errors
-	"os"  // For reading environment variables and getting port
+	"context"       // For the background job context
+	"crypto/rand"   // For generated signing keys
+	"encoding/hex"  // For encoding generated keys
+	"encoding/json" // For parsing structured environment variables
+	"fmt"           // For describing invalid settings
+	"log"           // For logging application startup and errors
+	"os"            // For reading environment variables and getting port
+	"strconv"       // For parsing numeric environment variables
+	"strings"       // For parsing list-valued environment variables
+	"time"          // For parsing job intervals
 
+	"myexpenses/internal/auth"                 // Signed calendar feed and export download URLs
+	"myexpenses/internal/cache"                // In-memory cache for dashboards
+	"myexpenses/internal/clock"                // Time source shared by services and jobs
 	"myexpenses/internal/db"                   // Database configuration
 	"myexpenses/internal/expenses/application" // Business logic layer
 
-	// Domain layer (for error types)
-	"myexpenses/internal/expenses/infrastructure/http"     // HTTP handlers and routes
-	"myexpenses/internal/expenses/infrastructure/postgres" // Database implementation
+	"myexpenses/internal/expenses/domain"                      // Domain layer (for interfaces and error types)
+	"myexpenses/internal/expenses/infrastructure/cached"       // Query result caching decorators
+	"myexpenses/internal/expenses/infrastructure/eventsourced" // Event-sourced expense storage
+	"myexpenses/internal/expenses/infrastructure/http"         // HTTP handlers and routes
+	"myexpenses/internal/expenses/infrastructure/instrumented" // Metrics decorators
+	"myexpenses/internal/expenses/infrastructure/notifying"    // Write notification decorators
+	"myexpenses/internal/expenses/infrastructure/ocr"          // Receipt text recognition
+	"myexpenses/internal/expenses/infrastructure/postgres"     // Database implementation
+	"myexpenses/internal/expenses/infrastructure/stripe"       // Subscription payments for hosted tenants
+	"myexpenses/internal/expenses/infrastructure/telemetry"    // Opt-in usage reports
+	"myexpenses/internal/fieldcrypt"                           // Field-level encryption keys
+	"myexpenses/internal/fx"                                   // Daily exchange rates
+	"myexpenses/internal/language"                             // Search languages
+	"myexpenses/internal/metrics"                              // In-process metrics
+	"myexpenses/internal/plugins"                              // Extension modules
+	"myexpenses/internal/queue"                                // Background task workers
+	"myexpenses/internal/ratelimit"                            // Per-user request limits
+	"myexpenses/internal/scheduler"                            // Background jobs
+	"myexpenses/internal/storage"                              // Blob storage for attachments
 
 	"github.com/gin-gonic/gin" // HTTP web framework
 	"github.com/joho/godotenv" // For loading .env files
@@ -29,6 +56,12 @@ func main() {
 		log.Println("No .env file found, using system environment variables")
 	}
 
+	// --mock serves fake expenses from memory instead of connecting to the database; see mock.go
+	if mock := parseFlags(); mock.enabled {
+		runMock(mock)
+		return
+	}
+
 	// Step 2: Initialize database configuration
 	// NewConfig() reads database settings from environment variables
 	// It provides sensible defaults if environment variables are not set
@@ -43,49 +76,985 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// The clock is the single source of "now" for services, background jobs and row timestamps
+	// GORM's NowFunc fills CreatedAt/UpdatedAt, so pointing it at the clock keeps them consistent
+	clk := clock.Real{}
+	database.Config.NowFunc = clk.Now
+
+	// EXPENSE_ID_VERSION picks the layout of new expense IDs: "v7" (default, time-ordered) or "v4" (random)
+	// Existing IDs are kept as they are, so switching is safe in both directions
+	idVersion, err := domain.ParseIDVersion(os.Getenv("EXPENSE_ID_VERSION"))
+	if err != nil {
+		log.Fatalf("Invalid EXPENSE_ID_VERSION: %v", err)
+	}
+	domain.SetExpenseIDVersion(idVersion)
+
+	// Active-passive deployments run one region per database: REGION_ROLE=active (default) on the
+	// primary, REGION_ROLE=passive on a standby's replica. Passive regions serve reads only, skip
+	// migrations and background jobs, and are promoted by promoting their database and restarting
+	// them as active. REGION_NAME labels the region in GET /health; REGION_ID_NODE (0-255, different
+	// in every region) keeps expense IDs from colliding when writes from both sides are merged
+	regionRole, err := domain.ParseRegionRole(os.Getenv("REGION_ROLE"))
+	if err != nil {
+		log.Fatalf("Invalid REGION_ROLE: %v", err)
+	}
+	if value := os.Getenv("REGION_ID_NODE"); value != "" {
+		node, err := strconv.ParseUint(value, 10, 8)
+		if err != nil {
+			log.Fatalf("Invalid REGION_ID_NODE: %q", value)
+		}
+		domain.SetExpenseIDNode(byte(node))
+	}
+	// REPLICATION_MAX_LAG (default "30s") is how far behind a passive region's replica may be
+	// before GET /health reports it as degraded
+	maxReplicationLag, err := time.ParseDuration(getEnv("REPLICATION_MAX_LAG", "30s"))
+	if err != nil || maxReplicationLag <= 0 {
+		log.Fatalf("Invalid REPLICATION_MAX_LAG: %q", os.Getenv("REPLICATION_MAX_LAG"))
+	}
+	passive := regionRole == domain.RegionPassive
+
+	// FIELD_ENCRYPTION_KEYS encrypts expense descriptions and merchants in the database with
+	// AES-256-GCM, as "id:base64key[,id:base64key...]" with the current key first; older keys stay
+	// listed until the re-encryption job has moved every row to the current one. Keys held in a KMS
+	// or secret manager can be mounted as a file instead and named by FIELD_ENCRYPTION_KEYS_FILE
+	fieldKeys := os.Getenv("FIELD_ENCRYPTION_KEYS")
+	if path := os.Getenv("FIELD_ENCRYPTION_KEYS_FILE"); path != "" {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read FIELD_ENCRYPTION_KEYS_FILE: %v", err)
+		}
+		fieldKeys = strings.TrimSpace(string(contents))
+	}
+	if fieldKeys != "" {
+		fieldCipher, err := fieldcrypt.ParseKeys(fieldKeys)
+		if err != nil {
+			log.Fatalf("Invalid field encryption keys: %v", err)
+		}
+		postgres.EnableFieldEncryption(fieldCipher)
+	}
+
+	// Descriptions and search queries are stemmed in their detected language; when a text is
+	// too short to tell, SEARCH_LANGUAGE is assumed (default: the DEFAULT_LOCALE language, "none"
+	// searches such texts unstemmed). TENANT_SEARCH_LANGUAGES sets it per tenant as JSON,
+	// e.g. {"acme": "de"}. Supported: en, de, fr, es, it, nl, pt
+	searchLanguage := getEnv("SEARCH_LANGUAGE", domain.NormalizeLocale(getEnv("DEFAULT_LOCALE", domain.DefaultLocale)))
+	if searchLanguage == "none" {
+		searchLanguage = ""
+	}
+	var tenantSearchLanguages map[string]string
+	if value := os.Getenv("TENANT_SEARCH_LANGUAGES"); value != "" {
+		if err := json.Unmarshal([]byte(value), &tenantSearchLanguages); err != nil {
+			log.Fatalf("Invalid TENANT_SEARCH_LANGUAGES: %v", err)
+		}
+	}
+	searchLanguages, err := language.NewPreferences(searchLanguage, tenantSearchLanguages)
+	if err != nil {
+		log.Fatalf("Invalid search languages: %v", err)
+	}
+
 	// Step 4: Initialize the repository layer
 	// NewRepository() creates a PostgreSQL implementation of the repository interface
 	// This is where we choose which database implementation to use
-	repo := postgres.NewRepository(database)
+	repo := postgres.NewRepository(database, postgres.WithLanguages(searchLanguages))
+	attachmentRepo := postgres.NewAttachmentRepository(database)
+	attachmentBlobRepo := postgres.NewAttachmentBlobRepository(database)
+	mccRepo := postgres.NewMCCRepository(database)
+	ruleRepo := postgres.NewRuleRepository(database)
+	correctionRepo := postgres.NewCorrectionRepository(database)
+	normalizationRuleRepo := postgres.NewNormalizationRuleRepository(database)
+	accountRepo := postgres.NewAccountRepository(database)
+	budgetRepo := postgres.NewBudgetRepository(database)
+	integrityRepo := postgres.NewIntegrityRepository(database)
+	categoryRepo := postgres.NewCategoryRepository(database)
+	indexStatsRepo := postgres.NewIndexStatsRepository(database)
+	flagRepo := postgres.NewFlagRepository(database)
+	pendingReceiptRepo := postgres.NewPendingReceiptRepository(database)
+	reconciliationRepo := postgres.NewReconciliationRepository(database)
+	tripRepo := postgres.NewTripRepository(database)
+	approvalChainRepo := postgres.NewApprovalChainRepository(database)
+	tripApprovalRepo := postgres.NewTripApprovalRepository(database)
+	delegationRepo := postgres.NewApprovalDelegationRepository(database)
+	policyRepo := postgres.NewPolicyRepository(database)
+	dimensionRepo := postgres.NewDimensionRepository(database)
+	exportRepo := postgres.NewExportRepository(database)
+	userRepo := postgres.NewUserRepository(database)
+	refreshTokenRepo := postgres.NewRefreshTokenRepository(database)
+	apiKeyRepo := postgres.NewAPIKeyRepository(database)
+	auditRepo := postgres.NewAuditRepository(database)
+	statsRepo := postgres.NewStatsRepository(database)
+	rerateRepo := postgres.NewRerateRepository(database)
+	brandingRepo := postgres.NewBrandingRepository(database)
+	groupRepo := postgres.NewGroupRepository(database)
+	publicFormRepo := postgres.NewPublicFormRepository(database)
+	recurringRepo := postgres.NewRecurringExpenseRepository(database)
+	receivableRepo := postgres.NewReceivableRepository(database)
+	employerRepo := postgres.NewEmployerRepository(database)
+	bookRepo := postgres.NewBookRepository(database)
+	bookArchiveRepo := postgres.NewBookArchiveRepository(database)
+	plannedPurchaseRepo := postgres.NewPlannedPurchaseRepository(database)
+	loanRepo := postgres.NewLoanRepository(database)
+	viewRepo := postgres.NewViewRepository(database)
+	customFieldRepo := postgres.NewCustomFieldRepository(database)
+	commentRepo := postgres.NewCommentRepository(database)
+
+	// Receipt files are kept in blob storage rather than in PostgreSQL
+	// STORAGE_DIR selects where the local storage backend keeps them
+	fileStorage, err := storage.NewLocalStorage(getEnv("STORAGE_DIR", "./data"))
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+
+	// features records which optional features this deployment runs, for GET /version
+	// Only on/off switches go in here, never the settings behind them
+	features := make(map[string]bool)
+	features["passive_region"] = passive
+
+	// OCR_ENABLED turns on receipt text recognition (requires tesseract/pdftotext installed)
+	// OCR_LANGUAGES selects the tesseract languages, e.g. "eng+deu"
+	// OCR_CLOUD_API_KEY (a Google Cloud Vision key) adds the hosted provider tenants may choose instead
+	// OCR_PROVIDER is the provider of tenants that haven't chosen (default: local when enabled)
+	ocrProviders := map[string]domain.OCRProvider{}
+	if getEnv("OCR_ENABLED", "false") == "true" {
+		ocrProviders[domain.OCRProviderLocal] = ocr.NewTesseract(getEnv("OCR_LANGUAGES", "eng"))
+	}
+	if key := os.Getenv("OCR_CLOUD_API_KEY"); key != "" {
+		ocrProviders[domain.OCRProviderCloud] = ocr.NewVision(key)
+	}
+	defaultOCRProvider := domain.OCRProviderLocal
+	if _, ok := ocrProviders[defaultOCRProvider]; !ok {
+		defaultOCRProvider = domain.OCRProviderCloud
+	}
+	defaultOCRProvider = getEnv("OCR_PROVIDER", defaultOCRProvider)
+	if _, ok := ocrProviders[defaultOCRProvider]; len(ocrProviders) > 0 && !ok {
+		log.Fatalf("Invalid OCR_PROVIDER: %q is not configured", defaultOCRProvider)
+	}
+	features["ocr"] = len(ocrProviders) > 0
+	features["ocr_cloud"] = ocrProviders[domain.OCRProviderCloud] != nil
+
+	// An active region must write to a primary: starting one on a standby would fail every write,
+	// and running two primaries after a failover would let their data diverge
+	replicationRepo := postgres.NewReplicationRepository(database)
+	replication, err := replicationRepo.ReplicationStatus(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to check the database role: %v", err)
+	}
+	if !passive && replication.InRecovery {
+		log.Fatalf("REGION_ROLE is active but the database is a standby; promote it first or set REGION_ROLE=passive")
+	}
 
 	// Step 5: Run database migrations
 	// AutoMigrate() creates database tables based on our struct definitions
 	// It ensures the database schema matches our domain models
-	if err := repo.AutoMigrate(); err != nil {
+	// A passive region's replica receives the schema from the primary, so it can't migrate itself
+	if passive {
+		log.Printf("Passive region: skipping migrations, the schema is replicated from the active region")
+	} else if err := repo.AutoMigrate(); err != nil {
 		log.Fatalf("Failed to run database migrations: %v", err)
 	}
 
+	// Expense repository calls and cache lookups are timed and counted for GET /metrics
+	// Decorators wrap the postgres repository, which stays free of observability code
+	metricsRegistry := metrics.NewRegistry()
+
 	// Step 6: Initialize the application service layer
 	// NewService() creates the business logic layer with the repository dependency
 	// This follows dependency injection - the service gets its dependencies from outside
-	service := application.NewService(repo)
+	// BASE_CURRENCY is the home currency all expenses are converted into for reporting
+	// FX_PROVIDER picks where the rates of foreign currency expenses are looked up on their date:
+	// "ecb" (the ECB's euro reference rates) or "exchangerate.host" (needs FX_ACCESS_KEY)
+	// Without one, foreign currency expenses must be entered with a rate or a converted amount
+	var exchangeRates domain.ExchangeRateProvider = domain.NoExchangeRates{}
+	var fxProvider fx.Provider
+	switch name := os.Getenv("FX_PROVIDER"); name {
+	case "":
+	case "ecb":
+		fxProvider = fx.NewECB(clk)
+	case "exchangerate.host":
+		if os.Getenv("FX_ACCESS_KEY") == "" {
+			log.Fatalf("FX_PROVIDER %q needs FX_ACCESS_KEY", name)
+		}
+		fxProvider = fx.NewExchangeRateHost(os.Getenv("FX_ACCESS_KEY"))
+	default:
+		log.Fatalf("Invalid FX_PROVIDER: %q (use ecb or exchangerate.host)", name)
+	}
+	if fxProvider != nil {
+		fxCache := cache.NewMemory(clk, cache.WithRecorder("exchange_rates", metricsRegistry))
+		exchangeRates = application.NewFXRates(fx.NewRates(fxProvider, fxCache, clk))
+	}
+	features["exchange_rates"] = fxProvider != nil
+	converter, err := application.NewCurrencyConverter(getEnv("BASE_CURRENCY", domain.DefaultBaseCurrency), exchangeRates)
+	if err != nil {
+		log.Fatalf("Invalid BASE_CURRENCY: %v", err)
+	}
+	accountService := application.NewAccountService(accountRepo, converter)
+	// Page, list, export and attachment size limits; see loadLimits for the variables
+	// LIST_OVERFLOW decides what happens to unpaginated lists over MAX_LIST_RESULTS:
+	// "stream" (default) or "paginate" (reject with 400)
+	limits, err := loadLimits()
+	if err != nil {
+		log.Fatalf("Invalid limits: %v", err)
+	}
+	features["list_streaming"] = getEnv("LIST_OVERFLOW", "stream") != "paginate"
+	// Dashboards are cached; every expense or budget write drops them, whichever use case wrote
+	dashboardCache := cache.NewMemory(clk, cache.WithRecorder("dashboard", metricsRegistry))
+	invalidateDashboards := func(context.Context) { application.InvalidateDashboards(dashboardCache) }
+	// Use cases that write to several tables share one transactor
+	transactor := postgres.NewTransactor(database)
+	// EXPENSE_STORE=events keeps every expense as an append-only event stream, with the expenses
+	// table as its projection; it enables GET /expenses/:id?as_of= and GET /expenses/:id/events
+	// "table" (default) only keeps the current state
+	var storedExpenses domain.Repository = repo
+	var history domain.ExpenseHistory
+	switch getEnv("EXPENSE_STORE", "table") {
+	case "table":
+	case "events":
+		eventRepo := eventsourced.NewRepository(repo, postgres.NewEventStore(database), transactor)
+		storedExpenses, history = eventRepo, eventRepo
+	default:
+		log.Fatalf("Invalid EXPENSE_STORE: %q (want \"table\" or \"events\")", os.Getenv("EXPENSE_STORE"))
+	}
+	features["event_store"] = history != nil
+	var expenseRepo domain.Repository = notifying.NewRepository(instrumented.NewRepository(storedExpenses, metricsRegistry), invalidateDashboards)
+	observedBudgetRepo := notifying.NewBudgetRepository(budgetRepo, invalidateDashboards)
+
+	// QUERY_CACHE_TTL (default "1m") is how long identical expense lists and spending totals are
+	// answered from memory; writes through the API drop them at once, the TTL bounds staleness from
+	// writes elsewhere (e.g. other instances). "0" turns the cache off
+	queryCacheTTL, err := time.ParseDuration(getEnv("QUERY_CACHE_TTL", "1m"))
+	if err != nil || queryCacheTTL < 0 {
+		log.Fatalf("Invalid QUERY_CACHE_TTL: %q", os.Getenv("QUERY_CACHE_TTL"))
+	}
+	var spendingRepo domain.SpendingRepository = repo
+	// invalidateReports drops every cached report, for changes made around the decorators
+	invalidateReports := invalidateDashboards
+	features["query_cache"] = queryCacheTTL > 0
+	if queryCacheTTL > 0 {
+		queryCache := cache.NewMemory(clk, cache.WithRecorder("expense_queries", metricsRegistry))
+		expenseRepo = cached.NewRepository(expenseRepo, queryCache, queryCacheTTL)
+		spendingRepo = cached.NewSpendingRepository(repo, queryCache, queryCacheTTL)
+		invalidateReports = func(ctx context.Context) {
+			queryCache.DeletePrefix("")
+			invalidateDashboards(ctx)
+		}
+	}
+
+	// Imported interest and bank fees are filed under BANK_FEE_CATEGORY and INTEREST_CATEGORY
+	// (default: the localized "Bank Fees" and "Interest"); DETECT_BANK_CHARGES=false turns detection off
+	locale := getEnv("DEFAULT_LOCALE", domain.DefaultLocale)
+	chargeCategories := domain.ChargeCategories{
+		Interest: getEnv("INTEREST_CATEGORY", domain.LocalizeCategory(locale, domain.DefaultChargeCategories.Interest)),
+		Fees:     getEnv("BANK_FEE_CATEGORY", domain.LocalizeCategory(locale, domain.DefaultChargeCategories.Fees)),
+	}
+	if getEnv("DETECT_BANK_CHARGES", "true") == "false" {
+		chargeCategories = domain.ChargeCategories{}
+	}
+
+	budgetService := application.NewBudgetService(observedBudgetRepo, application.NewForecaster(spendingRepo), clk)
+	// DASHBOARD_TIMEZONE is the IANA timezone of users who haven't sent theirs with GET /dashboard?tz=
+	dashboardLocation, err := time.LoadLocation(getEnv("DASHBOARD_TIMEZONE", "UTC"))
+	if err != nil {
+		log.Fatalf("Invalid DASHBOARD_TIMEZONE: %v", err)
+	}
+	dashboardService := application.NewDashboardService(spendingRepo, budgetService, chargeCategories, dashboardCache, dashboardLocation, clk)
+	// Patterns are grouped in SQL on the raw repository; hours default to the dashboard's timezone
+	insightService := application.NewInsightService(repo, dashboardLocation, clk)
+	// Imported expenses users move to another category are turned into suggested rules
+	ruleSuggestionService := application.NewRuleSuggestionService(correctionRepo, ruleRepo, clk)
+	// Extension modules are compiled in with build tags (see cmd/api/modules_*.go); PLUGINS adds
+	// Go plugins as a comma-separated list of .so files. Their hooks and reports are handed to
+	// the services below
+	if paths := os.Getenv("PLUGINS"); paths != "" {
+		for _, path := range strings.Split(paths, ",") {
+			if err := plugins.Load(strings.TrimSpace(path)); err != nil {
+				log.Fatalf("Failed to load plugin: %v", err)
+			}
+		}
+	}
+	if err := plugins.Setup(&plugins.Host{
+		Expenses:   expenseRepo,
+		Spending:   spendingRepo,
+		Categories: categoryRepo,
+		Budgets:    observedBudgetRepo,
+		Clock:      clk,
+	}); err != nil {
+		log.Fatalf("Failed to set up extension modules: %v", err)
+	}
+	if names := plugins.Names(); len(names) > 0 {
+		log.Printf("Extension modules: %s", strings.Join(names, ", "))
+	}
+	features["plugins"] = len(plugins.Names()) > 0
+
+	policyService := application.NewPolicyService(policyRepo, expenseRepo, attachmentRepo, auditRepo, clk)
+	dimensionService := application.NewDimensionService(dimensionRepo, auditRepo, clk)
+	service := application.NewService(expenseRepo,
+		application.WithCurrencyConverter(converter),
+		application.WithAccounts(accountService),
+		application.WithMaxListResults(limits.MaxListResults),
+		application.WithPageSizes(limits.DefaultPageSize, limits.MaxPageSize),
+		application.WithStreamingFallback(features["list_streaming"]),
+		application.WithBudgets(budgetService),
+		application.WithTransactor(transactor),
+		application.WithCategoryVAT(categoryRepo),
+		application.WithTripApprovals(tripRepo),
+		application.WithPolicies(policyService),
+		application.WithDimensions(dimensionService),
+		application.WithEmployers(employerRepo),
+		application.WithViews(viewRepo),
+		application.WithCustomFields(customFieldRepo),
+		application.WithCorrections(correctionRepo),
+		application.WithHistory(history),
+		application.WithBeforeCreateHooks(plugins.BeforeCreateHooks()...),
+	)
+	// Receipts are recognized with each tenant's provider and languages; tenants that haven't
+	// chosen get the default provider with its own languages
+	ocrService := application.NewOCRService(postgres.NewOCRRepository(database), ocrProviders,
+		&domain.OCRSettings{Provider: defaultOCRProvider, Languages: []string{}}, metricsRegistry, clk)
+	var textExtractor domain.TextExtractor = ocr.Noop{}
+	if features["ocr"] {
+		textExtractor = ocrService
+	}
+	attachmentService := application.NewAttachmentService(expenseRepo, attachmentRepo, attachmentBlobRepo, fileStorage, textExtractor, limits.MaxAttachmentBytes)
+	searchService := application.NewSearchService(repo, searchLanguages)
+	// RULE_MODE picks how categorization rules combine: "first" (the highest priority match wins,
+	// the default) or "all" (every matching rule has to agree on the category)
+	ruleMode, err := domain.ParseRuleMode(os.Getenv("RULE_MODE"))
+	if err != nil {
+		log.Fatalf("Invalid RULE_MODE: %q", os.Getenv("RULE_MODE"))
+	}
+	ruleCategorizer := application.NewRuleCategorizer(ruleRepo, ruleMode)
+	categorizationService := application.NewCategorizationService(mccRepo, ruleRepo, ruleCategorizer)
+	// Reports can be rebuilt as of a past time from the expense history the database keeps
+	reportService := application.NewReportService(spendingRepo, flagRepo, repo,
+		application.WithCategoryRollup(categoryRepo), application.WithReportHistory(repo))
+	integrityService := application.NewIntegrityService(integrityRepo, expenseRepo, attachmentRepo, attachmentBlobRepo, categoryRepo, fileStorage, clk)
+	categoryService := application.NewCategoryService(categoryRepo, clk)
+	indexStatsService := application.NewIndexStatsService(indexStatsRepo)
+	flagService := application.NewFlagService(flagRepo, expenseRepo)
+	// RECEIPT_MATCH_THRESHOLD (0-1) is the confidence from which bulk-uploaded receipts are attached automatically
+	receiptThreshold, err := strconv.ParseFloat(getEnv("RECEIPT_MATCH_THRESHOLD", strconv.FormatFloat(domain.DefaultReceiptMatchThreshold, 'f', -1, 64)), 64)
+	if err != nil || receiptThreshold <= 0 || receiptThreshold > 1 {
+		log.Fatalf("Invalid RECEIPT_MATCH_THRESHOLD: %q", os.Getenv("RECEIPT_MATCH_THRESHOLD"))
+	}
+	receiptService := application.NewReceiptService(expenseRepo, attachmentRepo, pendingReceiptRepo, attachmentBlobRepo, fileStorage, textExtractor, receiptThreshold, limits.MaxAttachmentBytes)
+	reconciliationService := application.NewReconciliationService(reconciliationRepo, expenseRepo, accountRepo, clk)
+	tripService := application.NewTripService(tripRepo, expenseRepo)
+	groupService := application.NewGroupService(groupRepo, clk)
+	publicFormService := application.NewPublicFormService(publicFormRepo, service, transactor, clk)
+	integrationService := application.NewIntegrationService(service, flagService, clk)
+	calendarService := application.NewCalendarService(recurringRepo, receivableRepo, budgetRepo, clk)
+	receivableService := application.NewReceivableService(receivableRepo, expenseRepo, converter.BaseCurrency(), clk)
+	employerService := application.NewEmployerService(employerRepo)
+	// Reimbursable expenses are claimed in batches, which are reconciled when the payout arrives
+	reimbursementService := application.NewReimbursementService(postgres.NewReimbursementRepository(database), employerRepo)
+	bookService := application.NewBookService(bookRepo)
+	bookArchiveService := application.NewBookArchiveService(bookRepo, categoryRepo, observedBudgetRepo, ruleRepo, expenseRepo, bookArchiveRepo, transactor, clk)
+	plannedPurchaseService := application.NewPlannedPurchaseService(plannedPurchaseRepo, expenseRepo)
+	viewService := application.NewViewService(viewRepo)
+	customFieldService := application.NewCustomFieldService(customFieldRepo)
+	commentService := application.NewCommentService(commentRepo)
+	// Loans read their payments uncached: linking a payment bypasses the expense cache
+	loanService := application.NewLoanService(loanRepo, repo, converter.BaseCurrency())
+
+	// Admins switch the deployment or single tenants to read-only at runtime (PUT /admin/read-only);
+	// READ_ONLY=maintenance or READ_ONLY=billing_suspended does it for the whole deployment from
+	// startup, with READ_ONLY_MESSAGE as the text users see, and holds until the next restart
+	var fixedReadOnly *domain.ReadOnlyMode
+	if reason := os.Getenv("READ_ONLY"); reason != "" {
+		fixedReadOnly, err = domain.NewReadOnlyMode(domain.ReadOnlyAllTenants, reason, os.Getenv("READ_ONLY_MESSAGE"))
+		if err != nil {
+			log.Fatalf("Invalid READ_ONLY: %v", err)
+		}
+		log.Printf("Read-only mode is on for every tenant (%s)", reason)
+	}
+	readOnlyService := application.NewReadOnlyService(postgres.NewReadOnlyRepository(database), fixedReadOnly, clk)
+
+	// Requests can be logged with their responses, personal data redacted, to debug client issues:
+	// REQUEST_LOG_SAMPLE_PERCENT of all requests (0 by default), and every request of users an admin
+	// flags with PUT /admin/request-logs/users/:userId. Logs are kept for REQUEST_LOG_TTL, with
+	// the first REQUEST_LOG_MAX_BODY bytes of each body
+	requestLogSample, err := strconv.ParseFloat(getEnv("REQUEST_LOG_SAMPLE_PERCENT", "0"), 64)
+	if err != nil || requestLogSample < 0 || requestLogSample > 100 {
+		log.Fatalf("Invalid REQUEST_LOG_SAMPLE_PERCENT: %q", os.Getenv("REQUEST_LOG_SAMPLE_PERCENT"))
+	}
+	requestLogTTL, err := time.ParseDuration(getEnv("REQUEST_LOG_TTL", application.DefaultRequestLogTTL.String()))
+	if err != nil || requestLogTTL <= 0 {
+		log.Fatalf("Invalid REQUEST_LOG_TTL: %q", os.Getenv("REQUEST_LOG_TTL"))
+	}
+	requestLogMaxBody, err := strconv.Atoi(getEnv("REQUEST_LOG_MAX_BODY", "65536"))
+	if err != nil || requestLogMaxBody < 0 {
+		log.Fatalf("Invalid REQUEST_LOG_MAX_BODY: %q", os.Getenv("REQUEST_LOG_MAX_BODY"))
+	}
+	requestLogService := application.NewRequestLogService(postgres.NewRequestLogRepository(database), requestLogSample, requestLogTTL, clk)
+	features["request_log_sampling"] = requestLogSample > 0
+
+	// Hosted deployments bill tenants with Stripe: STRIPE_SECRET_KEY turns billing on,
+	// STRIPE_WEBHOOK_SECRET checks the webhooks Stripe sends to POST /billing/webhook and
+	// STRIPE_PRICES names the Stripe price of a seat of each paid plan, e.g. {"pro": "price_123"}
+	// Plans then gate capabilities and limit the users of a tenant to its seats
+	var billingService *application.BillingService
+	var capabilityOptions []application.CapabilityOption
+	var userOptions []application.UserServiceOption
+	features["billing"] = os.Getenv("STRIPE_SECRET_KEY") != ""
+	if features["billing"] {
+		// Without the secret anyone could sign webhooks, e.g. to activate an unpaid subscription
+		if os.Getenv("STRIPE_WEBHOOK_SECRET") == "" {
+			log.Fatalf("STRIPE_WEBHOOK_SECRET is required with STRIPE_SECRET_KEY")
+		}
+		plans := application.DefaultPlans()
+		var prices map[string]string
+		if value := os.Getenv("STRIPE_PRICES"); value != "" {
+			if err := json.Unmarshal([]byte(value), &prices); err != nil {
+				log.Fatalf("Invalid STRIPE_PRICES: %v", err)
+			}
+		}
+		for _, plan := range plans {
+			plan.PriceID = prices[plan.ID]
+		}
+		provider := stripe.NewClient(os.Getenv("STRIPE_SECRET_KEY"), os.Getenv("STRIPE_WEBHOOK_SECRET"), clk)
+		billingService, err = application.NewBillingService(postgres.NewSubscriptionRepository(database), provider, userRepo, readOnlyService, transactor, plans)
+		if err != nil {
+			log.Fatalf("Invalid billing plans: %v", err)
+		}
+		capabilityOptions = append(capabilityOptions, application.WithPlans(billingService))
+		userOptions = append(userOptions, application.WithSeatLimit(billingService))
+	}
+
+	// Capabilities tell clients which optional features to offer (GET /meta/capabilities)
+	// CAPABILITIES overrides the deployment defaults, e.g. {"groups": false}
+	// TENANT_CAPABILITIES overrides them per tenant, e.g. {"acme": {"bank_sync": true}}
+	deploymentCapabilities := map[string]bool{
+		application.CapabilityMultiCurrency: true,
+		application.CapabilityAttachments:   true,
+		application.CapabilityGroups:        true,
+		// There is no bank connection yet; statements are imported as files
+		application.CapabilityBankSync: false,
+	}
+	if value := os.Getenv("CAPABILITIES"); value != "" {
+		if err := json.Unmarshal([]byte(value), &deploymentCapabilities); err != nil {
+			log.Fatalf("Invalid CAPABILITIES: %v", err)
+		}
+	}
+	var tenantCapabilities map[string]map[string]bool
+	if value := os.Getenv("TENANT_CAPABILITIES"); value != "" {
+		if err := json.Unmarshal([]byte(value), &tenantCapabilities); err != nil {
+			log.Fatalf("Invalid TENANT_CAPABILITIES: %v", err)
+		}
+	}
+	capabilityService, err := application.NewCapabilityService(deploymentCapabilities, tenantCapabilities, capabilityOptions...)
+	if err != nil {
+		log.Fatalf("Invalid capabilities: %v", err)
+	}
+
+	// Users log in with their email and password and get a JWT that lasts JWT_TTL (default "15m")
+	// JWT_SIGNING_KEY (at least 32 bytes) signs the tokens; every instance behind a load balancer
+	// needs the same key. Without it a key is generated at startup and restarting logs everyone out
+	signingKey := os.Getenv("JWT_SIGNING_KEY")
+	if signingKey == "" {
+		log.Printf("JWT_SIGNING_KEY is not set; using a random key, tokens end with this process")
+		signingKey = randomKey()
+	}
+	tokenTTL, err := time.ParseDuration(getEnv("JWT_TTL", auth.DefaultTokenTTL.String()))
+	if err != nil || tokenTTL <= 0 {
+		log.Fatalf("Invalid JWT_TTL: %q", os.Getenv("JWT_TTL"))
+	}
+	accessTokens, err := auth.NewJWTIssuer(signingKey, tokenTTL, clk)
+	if err != nil {
+		log.Fatalf("Invalid JWT_SIGNING_KEY: %v", err)
+	}
+	// Clients renew expired access tokens with a refresh token, which lasts
+	// REFRESH_TOKEN_TTL (default 30 days) and is revoked by logging out
+	refreshTokenTTL, err := time.ParseDuration(getEnv("REFRESH_TOKEN_TTL", auth.DefaultRefreshTokenTTL.String()))
+	if err != nil || refreshTokenTTL <= 0 {
+		log.Fatalf("Invalid REFRESH_TOKEN_TTL: %q", os.Getenv("REFRESH_TOKEN_TTL"))
+	}
+	// Failed logins lock the account for LOGIN_LOCKOUT (default 15m) after LOGIN_MAX_FAILURES
+	// (default 5) wrong passwords; "0" turns account lockout off
+	loginThrottle := application.DefaultLoginThrottle
+	loginThrottle.MaxAccountFailures, err = strconv.Atoi(getEnv("LOGIN_MAX_FAILURES", strconv.Itoa(loginThrottle.MaxAccountFailures)))
+	if err != nil || loginThrottle.MaxAccountFailures < 0 {
+		log.Fatalf("Invalid LOGIN_MAX_FAILURES: %q", os.Getenv("LOGIN_MAX_FAILURES"))
+	}
+	loginThrottle.Lockout, err = time.ParseDuration(getEnv("LOGIN_LOCKOUT", loginThrottle.Lockout.String()))
+	if err != nil || loginThrottle.Lockout <= 0 {
+		log.Fatalf("Invalid LOGIN_LOCKOUT: %q", os.Getenv("LOGIN_LOCKOUT"))
+	}
+	userService, err := application.NewUserService(userRepo, accessTokens, refreshTokenRepo, refreshTokenTTL, clk, append(userOptions, application.WithLoginThrottle(loginThrottle))...)
+	if err != nil {
+		log.Fatalf("Failed to initialize users: %v", err)
+	}
+	// Cron jobs and importers authenticate as service accounts with the client credentials grant
+	serviceAccountService := application.NewServiceAccountService(postgres.NewServiceAccountRepository(database), accessTokens, auditRepo, clk)
+
+	// Admins re-convert provider rates with POST /admin/rerate when the rate source publishes
+	// corrections; every change is written to the audit log
+	auditService := application.NewAuditService(auditRepo)
+	userAdminService := application.NewUserAdminService(userRepo, refreshTokenRepo, statsRepo, auditRepo, clk)
+	rerateService := application.NewRerateService(rerateRepo, auditRepo, converter, transactor, invalidateReports)
+
+	// Scripts and integrations authenticate as a user with an API key sent as X-API-Key
+	apiKeyService := application.NewAPIKeyService(apiKeyRepo, userRepo, clk)
+
+	// Tenants label their generated PDFs and report emails with their own name, logo and texts
+	brandingService := application.NewBrandingService(brandingRepo)
+
+	// Exports with more than EXPORT_ASYNC_THRESHOLD rows run as background jobs on
+	// EXPORT_WORKERS workers (default 2) instead of inside the request
+	exportWorkers, err := strconv.Atoi(getEnv("EXPORT_WORKERS", "2"))
+	if err != nil || exportWorkers <= 0 {
+		log.Fatalf("Invalid EXPORT_WORKERS: %q", os.Getenv("EXPORT_WORKERS"))
+	}
+	exportQueue := queue.New(exportWorkers, 100)
+	exportService := application.NewExportService(exportRepo, expenseRepo, fileStorage, exportQueue, limits.ExportAsyncThreshold, limits.MaxExportRows, clk)
+
+	// New installations start with localized default categories, sample rules and a starter budget
+	// DEFAULT_LOCALE picks the language of the seeded names (en, de, fr, es)
+	// Passive regions get them from the active region through replication
+	// With DEMO_DATA=true they also get three months of sample expenses, flagged as demo, to
+	// explore the app with; admins remove them with POST /admin/demo-data/wipe before real use
+	provisioningService := application.NewProvisioningService(categoryRepo, ruleRepo, budgetRepo, mccRepo)
+	demoDataService := application.NewDemoDataService(expenseRepo, repo, transactor, invalidateReports, clk)
+	if !passive {
+		if result, err := provisioningService.EnsureProvisioned(context.Background(), getEnv("DEFAULT_LOCALE", domain.DefaultLocale)); err != nil {
+			log.Fatalf("Failed to provision defaults: %v", err)
+		} else if result != nil {
+			log.Printf("Provisioned %d default categories (%s)", len(result.CategoriesCreated), result.Locale)
+			if getEnv("DEMO_DATA", "false") == "true" {
+				demo, err := demoDataService.Generate(context.Background(), result.Locale)
+				if err != nil {
+					log.Fatalf("Failed to generate demo data: %v", err)
+				}
+				log.Printf("Generated %d demo expenses", demo.Expenses)
+			}
+		}
+	}
+
+	// NORMALIZATION_STEPS configures the description clean-up pipeline as a comma separated list
+	// e.g. "strip_store_numbers,transliterate,user_rules,collapse_whitespace" (the default)
+	var normalizationSteps []string
+	if steps := os.Getenv("NORMALIZATION_STEPS"); steps != "" {
+		normalizationSteps = strings.Split(steps, ",")
+	}
+	normalizer, err := application.NewDescriptionNormalizer(normalizationRuleRepo, normalizationSteps)
+	if err != nil {
+		log.Fatalf("Invalid NORMALIZATION_STEPS: %v", err)
+	}
+	normalizationService := application.NewNormalizationService(normalizer, normalizationRuleRepo, repo)
+	tagService := application.NewTagService(repo)
+	// Clients prefill the category of new expenses from what the user picked for similar ones
+	categorySuggestionService := application.NewCategorySuggestionService(repo, normalizer)
+
+	// Imported transactions are categorized by MCC first, then by rules, then as bank charges
+	categorizer := application.CategorizerChain{
+		application.NewMCCCategorizer(mccRepo),
+		ruleCategorizer,
+		application.NewChargeCategorizer(chargeCategories),
+	}
+	importService := application.NewImportService(expenseRepo, categorizer, normalizer, converter,
+		application.WithAfterImportHooks(plugins.AfterImportHooks()...),
+	)
+	// New users bring their history from other expense apps; it is cleaned up like imports
+	migrationService := application.NewMigrationService(expenseRepo, categoryRepo, repo, normalizer, converter, transactor)
+
+	// Corporate card transactions use the same categories as a first guess, and wait for their cardholder
+	corporateCardRepo := postgres.NewCorporateCardRepository(database)
+	cardFeedService := application.NewCardFeedService(corporateCardRepo, publicFormRepo, userRepo, categorizer, normalizer, auditRepo)
 
 	// Step 7: Initialize the HTTP server
 	// gin.Default() creates a new Gin router with default middleware
 	// Default middleware includes logging and panic recovery
 	router := gin.Default()
 
+	// Client addresses (used to throttle failed logins) are only taken from X-Forwarded-For
+	// when the request comes through one of TRUSTED_PROXIES (comma-separated IPs or CIDRs)
+	var trustedProxies []string
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		trustedProxies = strings.Split(proxies, ",")
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Step 8: Add additional middleware
 	// Middleware functions process requests before they reach handlers
 	// They can add logging, authentication, CORS, etc.
+	// Every routed request is timed for GET /metrics and checked against the SLO of its route.
+	// SLOS sets the objectives as "METHOD /route=latency/target%" entries separated by semicolons
+	// (default metrics.DefaultObjectives); GET /admin/slo shows the burn rates, and alerts are
+	// logged and, with SLO_ALERT_WEBHOOK_URL, posted there. It runs before the recovery below, so
+	// panics count as errors
+	objectives, err := metrics.ParseObjectives(getEnv("SLOS", metrics.DefaultObjectives))
+	if err != nil {
+		log.Fatalf("Invalid SLOS: %v", err)
+	}
+	alertHooks := []metrics.AlertHook{metrics.LogAlerts}
+	if url := os.Getenv("SLO_ALERT_WEBHOOK_URL"); url != "" {
+		alertHooks = append(alertHooks, metrics.WebhookAlerts(url))
+	}
+	sloTracker := metrics.NewSLOTracker(objectives, clk, alertHooks...)
+	router.Use(http.RecordRequests(metrics.Tee(metricsRegistry, sloTracker)))
 	router.Use(gin.Logger())   // Logs HTTP requests (method, path, status, duration)
 	router.Use(gin.Recovery()) // Recovers from panics and returns 500 errors
 
+	// Passive regions refuse every write with 503 and reason "passive_region", before anything
+	// else (logging in included) gets to touch the read-only replica
+	if passive {
+		router.Use(http.RejectWrites())
+	}
+
+	// Every request carries its caller (auth.Principal) in its context from here on
+	// Logged-in users send their JWT as "Authorization: Bearer <token>", scripts their API key as X-API-Key
+	// Users act with the role stored on their account (admin, member or viewer); ADMIN_TOKEN
+	// additionally grants the admin role for debug features such as ?explain=true (sent as X-Admin-Token)
+	router.Use(http.Authenticate(os.Getenv("ADMIN_TOKEN"), accessTokens, apiKeyService, userService))
+
+	// Sampled and flagged requests are logged from here on, once the caller is known
+	// Reading the logs isn't logged, and neither are probes and scrapes
+	router.Use(http.LogRequests(requestLogService, requestLogMaxBody, "/admin/request-logs", "/health", "/metrics"))
+
+	// Routes open to callers who aren't logged in: probes, logging in, and the routes that let
+	// callers in by other means (signed feed and download links, public form tokens and the
+	// payment provider's webhook signature). Callers with the admin token
+	// have no user but are let in as admins, and every /admin route checks the role itself
+	publicRoutes := []string{
+		"/health", "/metrics", "/version", "/meta", "/branding",
+		"/auth/register", "/auth/login", "/auth/refresh", "/auth/logout", "/auth/token",
+		"/public", "/calendar/feed.ics", "/exports/:id/download",
+		"/billing/plans", "/billing/webhook",
+	}
+
+	// Viewers and read-only API keys can read but not change anything; they may still log out.
+	// Anonymous callers have no role, so the public routes that take writes are exempt too
+	router.Use(http.Authorize("/auth", "/public", "/billing/webhook"))
+
+	// Read-only tenants (or all of them) can't change anything either; refused writes carry the
+	// reason code ("maintenance", "billing_suspended"). Logging in and out, lifting the switch and
+	// paying (or receiving the payment provider's webhooks) still work
+	router.Use(http.EnforceReadOnly(readOnlyService, "/auth", "/admin/read-only", "/billing"))
+
+	// Everything else needs a logged-in user; get a token from POST /auth/login
+	router.Use(http.RequireUser(publicRoutes...))
+
+	// Requests work in the caller's default book unless they pick another one with X-Book-ID or ?book=
+	router.Use(http.UseBook(bookService))
+
+	// Each user may make RATE_LIMIT requests per minute to /expenses (default 600, "0" for no limit),
+	// RATE_LIMIT_BURST of them at once (default 60). With REDIS_URL the limit is shared by all
+	// instances; otherwise every instance limits on its own
+	rateLimit, err := strconv.Atoi(getEnv("RATE_LIMIT", "600"))
+	if err != nil || rateLimit < 0 {
+		log.Fatalf("Invalid RATE_LIMIT: %q", os.Getenv("RATE_LIMIT"))
+	}
+	if rateLimit > 0 {
+		burst, err := strconv.Atoi(getEnv("RATE_LIMIT_BURST", "60"))
+		if err != nil || burst <= 0 {
+			log.Fatalf("Invalid RATE_LIMIT_BURST: %q", os.Getenv("RATE_LIMIT_BURST"))
+		}
+		var limiter ratelimit.Limiter
+		if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+			limiter, err = ratelimit.NewRedis(redisURL, "myexpenses:ratelimit:", ratelimit.PerMinute(rateLimit, burst), clk)
+		} else {
+			limiter, err = ratelimit.NewMemory(ratelimit.PerMinute(rateLimit, burst), clk)
+		}
+		if err != nil {
+			log.Fatalf("Failed to set up rate limiting: %v", err)
+		}
+		router.Use(http.RateLimit(limiter, "/expenses"))
+	}
+	features["rate_limit"] = rateLimit > 0
+
+	// Requests are also charged their cost against a budget of COST_BUDGET per user and minute
+	// (default 300, "0" for no budget), which may all be spent at once. COST_WEIGHTS sets the costs
+	// as /prefix=cost pairs (default ratelimit.DefaultCosts: exports and analytics cost more than CRUD)
+	costBudget, err := strconv.Atoi(getEnv("COST_BUDGET", "300"))
+	if err != nil || costBudget < 0 {
+		log.Fatalf("Invalid COST_BUDGET: %q", os.Getenv("COST_BUDGET"))
+	}
+	if costBudget > 0 {
+		costs, err := ratelimit.ParseCosts(getEnv("COST_WEIGHTS", ratelimit.DefaultCosts))
+		if err != nil {
+			log.Fatalf("Invalid COST_WEIGHTS: %v", err)
+		}
+		var limiter ratelimit.Limiter
+		if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+			limiter, err = ratelimit.NewRedis(redisURL, "myexpenses:cost:", ratelimit.PerMinute(costBudget, costBudget), clk)
+		} else {
+			limiter, err = ratelimit.NewMemory(ratelimit.PerMinute(costBudget, costBudget), clk)
+		}
+		if err != nil {
+			log.Fatalf("Failed to set up cost budgets: %v", err)
+		}
+		router.Use(http.CostLimit(limiter, costs))
+	}
+	features["cost_budget"] = costBudget > 0
+
+	// Rendered reports (PDF, HTML email) carry the tenant's branding
+	router.Use(http.UseBranding(brandingService))
+
 	// Step 9: Setup API routes
 	// SetupRoutes() configures all the expense endpoints
 	// It maps HTTP requests to the appropriate handler methods
 	http.SetupRoutes(router, service)
+	http.SetupUserRoutes(router, userService)
+	// Users who delete their account can cancel for ERASURE_GRACE_PERIOD (default 30 days);
+	// after that the erasure job removes the account and everything in it
+	erasureGrace, err := time.ParseDuration(getEnv("ERASURE_GRACE_PERIOD", domain.DefaultErasureGracePeriod.String()))
+	if err != nil || erasureGrace <= 0 {
+		log.Fatalf("Invalid ERASURE_GRACE_PERIOD: %q", os.Getenv("ERASURE_GRACE_PERIOD"))
+	}
+	erasureService := application.NewErasureService(userRepo, refreshTokenRepo, postgres.NewErasureRepository(database), attachmentBlobRepo, fileStorage, auditRepo, clk, erasureGrace)
+	http.SetupErasureRoutes(router, erasureService)
+	http.SetupBrandingRoutes(router, brandingService)
+	http.SetupReadOnlyRoutes(router, readOnlyService)
+	http.SetupRequestLogRoutes(router, requestLogService)
+	if billingService != nil {
+		http.SetupBillingRoutes(router, billingService, stripe.SignatureHeader)
+	}
+	http.SetupAPIKeyRoutes(router, apiKeyService)
+	http.SetupRerateRoutes(router, rerateService, auditService)
+	http.SetupReceivableRoutes(router, receivableService)
+	http.SetupEmployerRoutes(router, employerService)
+	http.SetupReimbursementRoutes(router, reimbursementService)
+	http.SetupBookRoutes(router, bookService, bookArchiveService)
+	http.SetupPlannedPurchaseRoutes(router, plannedPurchaseService)
+	http.SetupViewRoutes(router, viewService)
+	http.SetupCustomFieldRoutes(router, customFieldService)
+	http.SetupCommentRoutes(router, commentService)
+	http.SetupLoanRoutes(router, loanService)
+	http.SetupUserAdminRoutes(router, userAdminService)
+	http.SetupAttachmentRoutes(router, attachmentService, searchService)
+	http.SetupCategorizationRoutes(router, importService, categorizationService)
+	http.SetupMigrationRoutes(router, migrationService)
+	http.SetupOCRRoutes(router, ocrService)
+	http.SetupNormalizationRoutes(router, normalizationService)
+	http.SetupTagRoutes(router, tagService)
+	http.SetupCategorySuggestionRoutes(router, categorySuggestionService)
+	http.SetupAccountRoutes(router, accountService)
+	http.SetupBudgetRoutes(router, budgetService, groupService)
+	http.SetupDashboardRoutes(router, dashboardService)
+	http.SetupInsightRoutes(router, insightService, ruleSuggestionService)
+	http.SetupGroupRoutes(router, groupService)
+	http.SetupPublicFormRoutes(router, publicFormService)
+	http.SetupCardFeedRoutes(router, cardFeedService)
+	http.SetupServiceAccountRoutes(router, serviceAccountService)
+	http.SetupPolicyRoutes(router, policyService)
+	http.SetupDimensionRoutes(router, dimensionService)
+	// The Zapier/IFTTT endpoints act as the user whose API key they send (X-API-Key)
+	if os.Getenv("INTEGRATION_API_KEY") != "" {
+		log.Printf("INTEGRATION_API_KEY is no longer used; connect integrations with a user API key")
+	}
+	http.SetupIntegrationRoutes(router, integrationService)
+	http.SetupReportRoutes(router, reportService)
+	http.SetupCustomReportRoutes(router, application.NewCustomReportService(plugins.Reports()))
+	http.SetupAdminRoutes(router, integrityService, provisioningService, indexStatsService)
+	http.SetupDemoDataRoutes(router, demoDataService)
+	http.SetupCategoryRoutes(router, categoryService)
+	http.SetupFlagRoutes(router, flagService)
+	// Donations of DONATION_RECEIPT_THRESHOLD or more (base currency, default 0: every donation)
+	// are reported as missing their receipt until a file is attached
+	donationReceiptThreshold, err := domain.ParseMoney(getEnv("DONATION_RECEIPT_THRESHOLD", "0"), "")
+	if err != nil || donationReceiptThreshold.IsNegative() {
+		log.Fatalf("Invalid DONATION_RECEIPT_THRESHOLD: %q", os.Getenv("DONATION_RECEIPT_THRESHOLD"))
+	}
+	donationService := application.NewDonationService(postgres.NewDonationRepository(database), expenseRepo, donationReceiptThreshold)
+	http.SetupDonationRoutes(router, donationService)
+	http.SetupReceiptRoutes(router, receiptService)
+	http.SetupReconciliationRoutes(router, reconciliationService)
+	http.SetupTripRoutes(router, tripService)
+	// ARCHIVE_SIGNING_KEY (a 32-byte Ed25519 seed, 64 hex characters) signs the archives of approved
+	// trip reports; without it a random key is used, so archives can't be verified after a restart
+	var archiveSigner *auth.ArchiveSigner
+	if key := os.Getenv("ARCHIVE_SIGNING_KEY"); key != "" {
+		archiveSigner, err = auth.NewArchiveSigner(key)
+		if err != nil {
+			log.Fatalf("Invalid ARCHIVE_SIGNING_KEY: %v", err)
+		}
+	} else {
+		log.Printf("ARCHIVE_SIGNING_KEY is not set; report archives signed now can't be verified after a restart")
+		archiveSigner, err = auth.GenerateArchiveSigner()
+		if err != nil {
+			log.Fatalf("Failed to generate archive signing key: %v", err)
+		}
+	}
+	reportArchiveService := application.NewReportArchiveService(tripRepo, attachmentRepo, fileStorage, archiveSigner, auditRepo)
+	approvalService := application.NewApprovalService(approvalChainRepo, tripApprovalRepo, delegationRepo, userRepo, tripService, reportArchiveService, policyService, auditRepo, clk)
+	http.SetupReportArchiveRoutes(router, reportArchiveService)
+	http.SetupApprovalRoutes(router, approvalService)
+	// CALENDAR_SIGNING_KEY signs the iCal feed URLs; without it the feed is disabled
+	// Changing the key invalidates every feed URL handed out so far
+	var feedSigner *auth.URLSigner
+	if key := os.Getenv("CALENDAR_SIGNING_KEY"); key != "" {
+		feedSigner, err = auth.NewURLSigner(key)
+		if err != nil {
+			log.Fatalf("Invalid CALENDAR_SIGNING_KEY: %v", err)
+		}
+	}
+	features["calendar_feed"] = feedSigner != nil
+	http.SetupCalendarRoutes(router, calendarService, feedSigner)
+	// EXPORT_SIGNING_KEY signs export download links; without it a random key is used,
+	// so links handed out before a restart stop working
+	exportKey := os.Getenv("EXPORT_SIGNING_KEY")
+	if exportKey == "" {
+		exportKey = randomKey()
+		log.Printf("EXPORT_SIGNING_KEY is not set; export download links won't survive a restart")
+	}
+	exportSigner, err := auth.NewURLSigner(exportKey)
+	if err != nil {
+		log.Fatalf("Invalid EXPORT_SIGNING_KEY: %v", err)
+	}
+	http.SetupExportRoutes(router, exportService, exportSigner, clk)
+	http.SetupMetricsRoutes(router, metricsRegistry)
+	http.SetupSLORoutes(router, sloTracker)
+	http.SetupMetaRoutes(router, limits, features, capabilityService)
 
 	// Step 10: Add a health check endpoint
 	// This endpoint is useful for load balancers and monitoring systems
-	// It allows external systems to check if the API is running
-	router.GET("/health", func(c *gin.Context) {
-		// Return a simple JSON response indicating the service is healthy
-		c.JSON(200, gin.H{
-			"status":  "ok",             // Health status
-			"service": "MyExpenses API", // Service name
+	// It reports the region's role and replication state, and answers 503 when the region
+	// shouldn't get traffic
+	http.SetupHealthRoutes(router, application.NewHealthService(replicationRepo, os.Getenv("REGION_NAME"), regionRole, maxReplicationLag))
+
+	// Background jobs
+	// INTEGRITY_CHECK_INTERVAL (e.g. "24h") runs the integrity checker on a schedule
+	// INTEGRITY_CHECK_AUTOFIX=true applies the safe repairs on scheduled runs too
+	jobs := scheduler.New(clk)
+	features["scheduled_integrity_check"] = os.Getenv("INTEGRITY_CHECK_INTERVAL") != ""
+	if value := os.Getenv("INTEGRITY_CHECK_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			log.Fatalf("Invalid INTEGRITY_CHECK_INTERVAL: %q", value)
+		}
+		autoFix := getEnv("INTEGRITY_CHECK_AUTOFIX", "false") == "true"
+		jobs.Every("integrity-check", interval, func(ctx context.Context) error {
+			result, err := integrityService.Run(ctx, autoFix)
+			if err != nil {
+				return err
+			}
+			log.Printf("Integrity check: %d issues, %d fixed", result.IssueCount, result.FixedCount)
+			return nil
+		})
+	}
+	// DASHBOARD_WARM_INTERVAL (default "15m") is how often the warmer looks for users whose local
+	// midnight has passed and precomputes their dashboard; "0" turns the warmer off
+	warmInterval, err := time.ParseDuration(getEnv("DASHBOARD_WARM_INTERVAL", "15m"))
+	if err != nil || warmInterval < 0 {
+		log.Fatalf("Invalid DASHBOARD_WARM_INTERVAL: %q", os.Getenv("DASHBOARD_WARM_INTERVAL"))
+	}
+	features["dashboard_warmer"] = warmInterval > 0
+	if warmInterval > 0 {
+		jobs.Every("dashboard-warmer", warmInterval, func(ctx context.Context) error {
+			warmed, err := dashboardService.WarmActive(ctx)
+			if warmed > 0 {
+				log.Printf("Dashboard warmer: %d dashboards precomputed", warmed)
+			}
+			return err
 		})
+	}
+	// SLO alerts start and stop as the burn rates are checked, every minute
+	jobs.Every("slo-alerts", time.Minute, func(context.Context) error {
+		sloTracker.Evaluate()
+		return nil
+	})
+	// Delegations that have run out are recorded as reverted in the audit log
+	jobs.Every("delegation-reversion", time.Hour, func(ctx context.Context) error {
+		reverted, err := approvalService.RevertEnded(ctx)
+		if reverted > 0 {
+			log.Printf("Delegation reversion: %d delegations ended", reverted)
+		}
+		return err
+	})
+	// Corrections too old to count for rule suggestions are deleted
+	jobs.Every("correction-purge", 24*time.Hour, func(ctx context.Context) error {
+		purged, err := ruleSuggestionService.PurgeExpired(ctx)
+		if purged > 0 {
+			log.Printf("Correction purge: %d old category corrections deleted", purged)
+		}
+		return err
 	})
+	// Finished export files are deleted once they expire
+	jobs.Every("export-purge", time.Hour, func(ctx context.Context) error {
+		purged, err := exportService.PurgeExpired(ctx)
+		if purged > 0 {
+			log.Printf("Export purge: %d expired exports deleted", purged)
+		}
+		return err
+	})
+	// Request logs are deleted once they are older than REQUEST_LOG_TTL
+	jobs.Every("request-log-purge", time.Hour, func(ctx context.Context) error {
+		purged, err := requestLogService.PurgeExpired(ctx)
+		if purged > 0 {
+			log.Printf("Request log purge: %d expired logs deleted", purged)
+		}
+		return err
+	})
+	// Accounts whose erasure grace period is over are erased
+	jobs.Every("account-erasure", time.Hour, func(ctx context.Context) error {
+		erased, err := erasureService.PurgeDue(ctx)
+		if erased > 0 {
+			log.Printf("Account erasure: %d accounts erased", erased)
+		}
+		return err
+	})
+	// Expenses written before field encryption was enabled, or under a rotated-out key,
+	// are re-encrypted under the current key in batches
+	features["field_encryption"] = fieldKeys != ""
+	if fieldKeys != "" {
+		jobs.Every("field-encryption", time.Hour, func(ctx context.Context) error {
+			total := 0
+			for {
+				rewritten, err := repo.EncryptPlaintextExpenses(ctx, 500)
+				total += rewritten
+				if err != nil || rewritten == 0 {
+					if total > 0 {
+						log.Printf("Field encryption: %d expenses re-encrypted", total)
+					}
+					return err
+				}
+			}
+		})
+	}
+	// Expired refresh tokens can't be used anymore and only take up space
+	jobs.Every("refresh-token-purge", 24*time.Hour, func(ctx context.Context) error {
+		purged, err := userService.PurgeExpiredRefreshTokens(ctx)
+		if purged > 0 {
+			log.Printf("Refresh token purge: %d expired tokens deleted", purged)
+		}
+		return err
+	})
+	// Anonymous usage telemetry is off unless the operator opts in with TELEMETRY_ENABLED=true;
+	// reports (counts rounded to powers of ten, build and feature flags, never financial data)
+	// then go to TELEMETRY_ENDPOINT every TELEMETRY_INTERVAL (default "24h")
+	// GET /admin/telemetry shows the exact report either way
+	var telemetrySender domain.TelemetrySender
+	features["telemetry"] = getEnv("TELEMETRY_ENABLED", "false") == "true"
+	if features["telemetry"] {
+		endpoint := os.Getenv("TELEMETRY_ENDPOINT")
+		if endpoint == "" {
+			log.Fatalf("TELEMETRY_ENABLED needs TELEMETRY_ENDPOINT")
+		}
+		telemetrySender = telemetry.NewHTTPSender(endpoint)
+	}
+	telemetryService := application.NewTelemetryService(postgres.NewTelemetryRepository(database), features, telemetrySender, clk)
+	http.SetupTelemetryRoutes(router, telemetryService)
+	if telemetryService.Enabled() {
+		telemetryInterval, err := time.ParseDuration(getEnv("TELEMETRY_INTERVAL", "24h"))
+		if err != nil || telemetryInterval <= 0 {
+			log.Fatalf("Invalid TELEMETRY_INTERVAL: %q", os.Getenv("TELEMETRY_INTERVAL"))
+		}
+		jobs.Every("telemetry", telemetryInterval, telemetryService.Send)
+	}
+	// Jobs and exports write to the database, so only the active region runs them
+	if !passive {
+		jobs.Start(context.Background())
+		defer jobs.Stop()
+
+		// Exports that were queued or running when the API last stopped start over
+		exportQueue.Start(context.Background())
+		defer exportQueue.Stop()
+		if resumed, err := exportService.ResumeUnfinished(context.Background()); err != nil {
+			log.Printf("Failed to resume exports: %v", err)
+		} else if resumed > 0 {
+			log.Printf("Resumed %d unfinished exports", resumed)
+		}
+	}
 
 	// Step 11: Get the port from environment or use default
 	// os.Getenv("PORT") reads the PORT environment variable
@@ -109,3 +1078,76 @@ func main() {
 	// Note: The application will run indefinitely until interrupted
 	// To stop the server, send a SIGINT signal (Ctrl+C) or SIGTERM
 }
+
+// getEnv gets an environment variable with a fallback default value
+// It returns the environment variable value if set, otherwise returns the fallback
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// randomKey returns a new random signing key
+// Tokens signed with it stop working when the process restarts
+func randomKey() string {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		log.Fatalf("Failed to generate signing key: %v", err)
+	}
+	return hex.EncodeToString(random)
+}
+
+// loadLimits reads the size limits from the environment, falling back to the defaults
+//   - DEFAULT_PAGE_SIZE: page size of GET /expenses without limit (default 0, unpaginated)
+//   - MAX_PAGE_SIZE: largest limit a client may ask for (default MAX_LIST_RESULTS)
+//   - MAX_LIST_RESULTS: most expenses an unpaginated list builds in memory
+//   - EXPORT_ASYNC_THRESHOLD: rows above which an export runs as a background job
+//   - MAX_EXPORT_ROWS: most rows one export may have (default 0, no limit)
+//   - MAX_ATTACHMENT_BYTES: largest attachment or receipt upload
+func loadLimits() (application.Limits, error) {
+	limits := application.DefaultLimits()
+	ints := []struct {
+		key    string
+		target *int
+	}{
+		{"MAX_LIST_RESULTS", &limits.MaxListResults},
+		{"DEFAULT_PAGE_SIZE", &limits.DefaultPageSize},
+		{"EXPORT_ASYNC_THRESHOLD", &limits.ExportAsyncThreshold},
+	}
+	for _, setting := range ints {
+		if value := os.Getenv(setting.key); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return limits, fmt.Errorf("%s: %q is not a number", setting.key, value)
+			}
+			*setting.target = parsed
+		}
+	}
+	limits.MaxPageSize = limits.MaxListResults
+	if value := os.Getenv("MAX_PAGE_SIZE"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return limits, fmt.Errorf("MAX_PAGE_SIZE: %q is not a number", value)
+		}
+		limits.MaxPageSize = parsed
+	}
+
+	int64s := []struct {
+		key    string
+		target *int64
+	}{
+		{"MAX_EXPORT_ROWS", &limits.MaxExportRows},
+		{"MAX_ATTACHMENT_BYTES", &limits.MaxAttachmentBytes},
+	}
+	for _, setting := range int64s {
+		if value := os.Getenv(setting.key); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return limits, fmt.Errorf("%s: %q is not a number", setting.key, value)
+			}
+			*setting.target = parsed
+		}
+	}
+	return limits, limits.Validate()
+}
@@ -0,0 +1,138 @@
+// Package main is the entry point for the MyExpenses API application
+// This file runs the API in mock mode (--mock): the expense, budget, report, dashboard, account
+// and current user endpoints, served from memory with fake data, so frontends can be developed
+// without a database
+package main
+
+import (
+	"context" // For seeding as the mock user
+	"flag"    // For the mock mode flags
+	"log"     // For logging startup and fatal errors
+	"time"    // For the fixed clock and latency
+
+	"myexpenses/internal/auth"                           // The fixed caller of mock mode
+	"myexpenses/internal/cache"                          // Dashboard cache
+	"myexpenses/internal/clock"                          // Fixed time source
+	"myexpenses/internal/expenses/application"           // Business logic layer
+	"myexpenses/internal/expenses/domain"                // Base currency and charge categories
+	"myexpenses/internal/expenses/infrastructure/http"   // HTTP handlers and routes
+	"myexpenses/internal/expenses/infrastructure/memory" // In-memory expense storage
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+	"github.com/google/uuid"   // For the mock user's ID
+)
+
+// DefaultMockNow is the time mock mode runs at unless --mock-now says otherwise
+// The fake data and every timestamp follow from it, so responses are the same on every start
+const DefaultMockNow = "2025-06-30T12:00:00Z"
+
+// mockUserID is the user every request acts as in mock mode
+const mockUserID = "00000000-0000-4000-8000-000000000001"
+
+// mockFlags are the command line flags of mock mode
+type mockFlags struct {
+	enabled bool
+	latency time.Duration
+	jitter  time.Duration
+	now     string
+	locale  string
+}
+
+// parseFlags reads the command line; only mock mode has flags, everything else is configured
+// through the environment
+func parseFlags() mockFlags {
+	var mock mockFlags
+	flag.BoolVar(&mock.enabled, "mock", false, "serve deterministic fake data from memory instead of the database")
+	flag.DurationVar(&mock.latency, "mock-latency", 0, "delay every mock response by this much (e.g. 150ms)")
+	flag.DurationVar(&mock.jitter, "mock-jitter", 0, "add up to this much random delay on top of --mock-latency")
+	flag.StringVar(&mock.now, "mock-now", DefaultMockNow, "the RFC 3339 time mock mode runs at; the fake data ends there")
+	flag.StringVar(&mock.locale, "mock-locale", "en", "the language of the fake data's categories")
+	flag.Parse()
+	return mock
+}
+
+// runMock serves the expense endpoints and those built on them from memory until the server stops
+// Time stands still at --mock-now: the fake data ends there and new expenses are stamped with it
+// Every request acts as one member user, logged in on one session; changes last until the process exits
+func runMock(mock mockFlags) {
+	now, err := time.Parse(time.RFC3339, mock.now)
+	if err != nil {
+		log.Fatalf("Invalid --mock-now: %q is not an RFC 3339 time", mock.now)
+	}
+	if mock.latency < 0 || mock.jitter < 0 {
+		log.Fatalf("Invalid --mock-latency or --mock-jitter: delays can't be negative")
+	}
+	clk := clock.NewFake(now)
+
+	repo := memory.NewRepository(clk)
+	budgetRepo := memory.NewBudgetRepository(clk)
+	accountRepo := memory.NewAccountRepository(repo, clk)
+	userRepo := memory.NewUserRepository(clk)
+	refreshTokenRepo := memory.NewRefreshTokenRepository(clk)
+
+	session, err := memory.SeedUser(context.Background(), userRepo, refreshTokenRepo, uuid.MustParse(mockUserID), now)
+	if err != nil {
+		log.Fatalf("Failed to seed mock data: %v", err)
+	}
+	principal := auth.Principal{UserID: mockUserID, SessionID: session.String(), Roles: []auth.Role{auth.RoleMember}}
+	ctx := auth.WithPrincipal(context.Background(), principal)
+	seeded, err := memory.Seed(ctx, repo, mock.locale, now)
+	if err == nil {
+		_, err = memory.SeedBudgets(ctx, budgetRepo, mock.locale, now)
+	}
+	if err == nil {
+		_, err = memory.SeedAccounts(ctx, accountRepo, mock.locale, now)
+	}
+	if err != nil {
+		log.Fatalf("Failed to seed mock data: %v", err)
+	}
+
+	limits := application.DefaultLimits()
+	service := application.NewService(repo,
+		application.WithMaxListResults(limits.MaxListResults),
+		application.WithPageSizes(limits.DefaultPageSize, limits.MaxPageSize),
+	)
+	capabilityService, err := application.NewCapabilityService(nil, nil)
+	if err != nil {
+		log.Fatalf("Invalid capabilities: %v", err)
+	}
+	budgetService := application.NewBudgetService(budgetRepo, application.NewForecaster(repo), clk)
+	chargeCategories := domain.ChargeCategories{
+		Interest: domain.LocalizeCategory(mock.locale, domain.DefaultChargeCategories.Interest),
+		Fees:     domain.LocalizeCategory(mock.locale, domain.DefaultChargeCategories.Fees),
+	}
+	dashboardService := application.NewDashboardService(repo, budgetService, chargeCategories, cache.NewMemory(clk), time.UTC, clk)
+	// Reports as of a past time need the expense history, which isn't kept in memory
+	reportService := application.NewReportService(repo, memory.NewFlagRepository(), repo)
+	converter, err := application.NewCurrencyConverter(domain.DefaultBaseCurrency, nil)
+	if err != nil {
+		log.Fatalf("Invalid base currency: %v", err)
+	}
+	accountService := application.NewAccountService(accountRepo, converter)
+	accessTokens, err := auth.NewJWTIssuer(randomKey(), auth.DefaultTokenTTL, clk)
+	if err != nil {
+		log.Fatalf("Failed to create the token issuer: %v", err)
+	}
+	userService, err := application.NewUserService(userRepo, accessTokens, refreshTokenRepo, auth.DefaultRefreshTokenTTL, clk)
+	if err != nil {
+		log.Fatalf("Failed to initialize users: %v", err)
+	}
+
+	router := gin.Default()
+	router.Use(http.Latency(mock.latency, mock.jitter))
+	router.Use(http.MockPrincipal(principal))
+	http.SetupRoutes(router, service)
+	http.SetupMetaRoutes(router, limits, map[string]bool{"mock": true}, capabilityService)
+	// Shared budgets belong to groups, which mock mode doesn't have
+	http.SetupBudgetRoutes(router, budgetService, nil)
+	http.SetupReportRoutes(router, reportService)
+	http.SetupDashboardRoutes(router, dashboardService)
+	http.SetupAccountRoutes(router, accountService)
+	http.SetupUserRoutes(router, userService)
+
+	port := getEnv("PORT", "8080")
+	log.Printf("Mock mode: %d fake expenses as of %s, %s latency (+%s jitter), on port %s", seeded, now.Format(time.RFC3339), mock.latency, mock.jitter, port)
+	if err := router.Run(":" + port); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build weekday
+
+// Package main is the entry point for the MyExpenses API application
+// This file compiles in the example weekday report module; build with -tags weekday
+// Forks add their own modules the same way: one file per module behind its own build tag
+package main
+
+import (
+	_ "myexpenses/internal/plugins/weekday" // Registers the module in its init function
+)
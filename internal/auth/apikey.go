@@ -0,0 +1,37 @@
+// Package auth carries the authenticated caller through a request
+// This file contains user API keys: long-lived random secrets for scripts and integrations
+package auth
+
+import (
+	"crypto/rand"     // For unguessable keys
+	"encoding/base64" // For encoding keys as text
+	"strings"         // For recognizing keys by their prefix
+)
+
+// APIKeyPrefix starts every user API key
+// It tells user keys apart from other secrets sent in the same header, and makes
+// leaked keys easy to find with secret scanners
+const APIKeyPrefix = "mxk_"
+
+// apiKeyDisplayLength is how much of a key is kept to show in key lists
+const apiKeyDisplayLength = len(APIKeyPrefix) + 6
+
+// NewAPIKey returns a new random API key, the hash to store for it and its display prefix
+func NewAPIKey() (key, hash, prefix string, err error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", "", err
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+	return key, HashAPIKey(key), key[:apiKeyDisplayLength], nil
+}
+
+// HashAPIKey returns the hash under which an API key is stored
+func HashAPIKey(key string) string {
+	return hashSecret(key)
+}
+
+// IsAPIKey reports whether s looks like a user API key
+func IsAPIKey(s string) bool {
+	return strings.HasPrefix(s, APIKeyPrefix)
+}
@@ -0,0 +1,78 @@
+// Package auth carries the authenticated caller through a request
+// This file contains the server's signature on approved report archives
+// Archives are signed with Ed25519 rather than an HMAC, so an auditor holding only the public key
+// can check an archive came from this server without being able to sign one
+package auth
+
+import (
+	"crypto/ed25519"  // Public-key signatures
+	"crypto/rand"     // For generating a key when none is configured
+	"crypto/sha256"   // For deriving the key ID
+	"encoding/base64" // For putting keys and signatures in JSON
+	"encoding/hex"    // For parsing the configured seed
+	"errors"          // For the invalid key error
+)
+
+// ErrInvalidArchiveKey occurs when the archive signing key isn't a hex encoded 32-byte seed
+var ErrInvalidArchiveKey = errors.New("archive signing key must be 64 hex characters (a 32-byte Ed25519 seed)")
+
+// ArchiveSignatureAlgorithm names the signature scheme in archives, so a later change of scheme can be told apart
+const ArchiveSignatureAlgorithm = "ed25519"
+
+// ArchiveSigner signs the digest of an archive's manifest
+type ArchiveSigner struct {
+	key ed25519.PrivateKey
+}
+
+// NewArchiveSigner creates a signer from a hex encoded 32-byte seed
+// Changing the key doesn't invalidate archives signed so far: they carry the public key they were
+// signed with, but only archives signed with the current key are reported as signed by this server
+func NewArchiveSigner(seed string) (*ArchiveSigner, error) {
+	if seed == "" {
+		return nil, ErrNoSigningKey
+	}
+	raw, err := hex.DecodeString(seed)
+	if err != nil || len(raw) != ed25519.SeedSize {
+		return nil, ErrInvalidArchiveKey
+	}
+	return &ArchiveSigner{key: ed25519.NewKeyFromSeed(raw)}, nil
+}
+
+// GenerateArchiveSigner creates a signer with a new random key
+func GenerateArchiveSigner() (*ArchiveSigner, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &ArchiveSigner{key: key}, nil
+}
+
+// Sign returns the base64 signature of digest
+func (s *ArchiveSigner) Sign(digest []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, digest))
+}
+
+// PublicKey returns the base64 public key that verifies the signatures
+func (s *ArchiveSigner) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// KeyID is a short fingerprint of the public key (the first 8 bytes of its SHA-256, in hex)
+func (s *ArchiveSigner) KeyID() string {
+	sum := sha256.Sum256(s.key.Public().(ed25519.PublicKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+// VerifyArchiveSignature reports whether signature is a valid signature of digest by publicKey
+// (both base64, as produced by ArchiveSigner)
+func VerifyArchiveSignature(publicKey string, digest []byte, signature string) bool {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(key), digest, sig)
+}
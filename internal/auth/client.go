@@ -0,0 +1,47 @@
+// Package auth carries the authenticated caller through a request
+// This file contains the credentials of service accounts: a public client ID and a random client secret,
+// exchanged for an access token with the OAuth 2.0 client credentials grant (RFC 6749 section 4.4)
+package auth
+
+import (
+	"crypto/rand"     // For unguessable IDs and secrets
+	"crypto/subtle"   // For comparing secret hashes in constant time
+	"encoding/base64" // For encoding secrets as text
+	"encoding/hex"    // For encoding client IDs as text
+)
+
+// ClientIDPrefix starts every service account client ID
+const ClientIDPrefix = "mxc_"
+
+// ClientSecretPrefix starts every service account client secret, so leaked secrets are easy to find
+const ClientSecretPrefix = "mxs_"
+
+// NewClientID returns a new random client ID
+// Client IDs aren't secret, but they are random so they don't reveal how many accounts exist
+func NewClientID() (string, error) {
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return ClientIDPrefix + hex.EncodeToString(random), nil
+}
+
+// NewClientSecret returns a new random client secret and the hash to store for it
+func NewClientSecret() (secret, hash string, err error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", err
+	}
+	secret = ClientSecretPrefix + base64.RawURLEncoding.EncodeToString(random)
+	return secret, HashClientSecret(secret), nil
+}
+
+// HashClientSecret returns the hash under which a client secret is stored
+func HashClientSecret(secret string) string {
+	return hashSecret(secret)
+}
+
+// ClientSecretMatches reports whether secret is the secret stored as hash
+func ClientSecretMatches(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashClientSecret(secret)), []byte(hash)) == 1
+}
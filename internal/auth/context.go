@@ -0,0 +1,122 @@
+// Package auth carries the authenticated caller through a request
+// Middleware stores a Principal in the context.Context once; services and repositories read it
+// back with the accessors below instead of receiving user IDs as extra parameters
+package auth
+
+import (
+	"context" // The request context that carries the principal
+	"errors"  // For the missing principal error
+)
+
+// Role is a permission level granted to a principal
+type Role string
+
+const (
+	// RoleViewer can read their own data but not change it
+	RoleViewer Role = "viewer"
+
+	// RoleMember can manage their own data
+	RoleMember Role = "member"
+
+	// RoleAdmin can run maintenance and diagnostics, and manage other users
+	RoleAdmin Role = "admin"
+)
+
+// Principal is the caller a request is made on behalf of
+type Principal struct {
+	// UserID identifies the user; empty for anonymous callers
+	UserID string `json:"user_id"`
+
+	// TenantID identifies the tenant (household, company) the user acts in
+	TenantID string `json:"tenant_id,omitempty"`
+
+	// Roles are the roles granted for this request
+	Roles []Role `json:"roles"`
+
+	// APIKeyID is the API key the request authenticated with; empty for logins
+	APIKeyID string `json:"api_key_id,omitempty"`
+
+	// SessionID is the login session of the access token; empty for API keys and service accounts
+	SessionID string `json:"session_id,omitempty"`
+
+	// ClientID is the service account client the request authenticated as; empty for users
+	ClientID string `json:"client_id,omitempty"`
+
+	// ReadOnly is set for read-only API keys, which may only read
+	ReadOnly bool `json:"read_only,omitempty"`
+
+	// BookID is the book (e.g. a side business) the request works in; empty for the user's default book
+	// Expenses, categories and budgets are kept per book
+	BookID string `json:"book_id,omitempty"`
+}
+
+// HasRole reports whether the principal was granted role
+func (p Principal) HasRole(role Role) bool {
+	for _, granted := range p.Roles {
+		if granted == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Anonymous reports whether the principal has no user
+func (p Principal) Anonymous() bool {
+	return p.UserID == ""
+}
+
+// ServiceAccount reports whether the principal is a service account rather than a person
+func (p Principal) ServiceAccount() bool {
+	return p.ClientID != ""
+}
+
+// ErrNoPrincipal occurs when code that needs a caller runs without one in its context
+var ErrNoPrincipal = errors.New("no authenticated principal in context")
+
+// principalKey is the context key for the principal
+// It is an unexported type, so no other package can read or overwrite the value by accident
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, if any
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Require returns the principal stored in ctx, or ErrNoPrincipal
+func Require(ctx context.Context) (Principal, error) {
+	p, ok := FromContext(ctx)
+	if !ok || p.Anonymous() {
+		return Principal{}, ErrNoPrincipal
+	}
+	return p, nil
+}
+
+// UserID returns the user ID stored in ctx, or "" when there is none
+func UserID(ctx context.Context) string {
+	p, _ := FromContext(ctx)
+	return p.UserID
+}
+
+// TenantID returns the tenant ID stored in ctx, or "" when there is none
+func TenantID(ctx context.Context) string {
+	p, _ := FromContext(ctx)
+	return p.TenantID
+}
+
+// BookID returns the book ID stored in ctx, or "" for the default book
+func BookID(ctx context.Context) string {
+	p, _ := FromContext(ctx)
+	return p.BookID
+}
+
+// HasRole reports whether the principal stored in ctx was granted role
+func HasRole(ctx context.Context, role Role) bool {
+	p, _ := FromContext(ctx)
+	return p.HasRole(role)
+}
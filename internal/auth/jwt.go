@@ -0,0 +1,133 @@
+// Package auth carries the authenticated caller through a request
+// This file contains access tokens: HS256-signed JSON Web Tokens (RFC 7519) issued at login
+package auth
+
+import (
+	"crypto/hmac"     // For signing tokens and comparing signatures in constant time
+	"crypto/sha256"   // Hash function for HS256
+	"encoding/base64" // For the base64url token segments
+	"encoding/json"   // For the token header and claims
+	"errors"          // For the token errors
+	"strings"         // For splitting tokens
+	"time"            // For token lifetimes
+
+	"myexpenses/internal/clock" // Time source, so expiry can be tested with a fake clock
+)
+
+// DefaultTokenTTL is how long an access token stays valid when no lifetime is configured
+// Access tokens can't be revoked, so they are short-lived; clients renew them with a refresh token
+const DefaultTokenTTL = 15 * time.Minute
+
+// tokenIssuer is the "iss" claim of our tokens, so tokens of other services are never accepted
+const tokenIssuer = "myexpenses"
+
+// minSigningKeyBytes is the shortest accepted signing key; HS256 keys should be at least 256 bits
+const minSigningKeyBytes = 32
+
+// ErrInvalidToken occurs when a bearer token is malformed, forged or expired
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// ErrSessionRevoked occurs when a bearer token belongs to a login session that was logged out or revoked
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+// ErrWeakSigningKey occurs when a token signing key is shorter than 32 bytes
+var ErrWeakSigningKey = errors.New("token signing key must be at least 32 bytes")
+
+// jwtHeader is the only header we issue and accept
+// Accepting just HS256 closes the classic "alg": "none" and algorithm confusion attacks
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Claims are the claims carried by an access token
+type Claims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`            // the user ID
+	Role      string `json:"role,omitempty"` // the user's role; empty in tokens issued before roles existed
+	SessionID string `json:"sid,omitempty"`  // the login session the token belongs to
+	ClientID  string `json:"cid,omitempty"`  // the service account client the token was issued to
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// JWTIssuer issues and verifies access tokens
+// Verify checks tokens without a database lookup; whether the login session they name is
+// still logged in is left to the caller (see http.Authenticate)
+type JWTIssuer struct {
+	key   []byte
+	ttl   time.Duration
+	clock clock.Clock
+}
+
+// NewJWTIssuer creates an issuer signing with key, whose tokens last ttl (<= 0 uses DefaultTokenTTL)
+func NewJWTIssuer(key string, ttl time.Duration, clk clock.Clock) (*JWTIssuer, error) {
+	if len(key) < minSigningKeyBytes {
+		return nil, ErrWeakSigningKey
+	}
+	if ttl <= 0 {
+		ttl = DefaultTokenTTL
+	}
+	return &JWTIssuer{key: []byte(key), ttl: ttl, clock: clock.Or(clk)}, nil
+}
+
+// Issue returns an access token for userID with the given role and when it expires
+// The role is fixed for the token's lifetime: a role change applies from the next refresh
+// sessionID names the login session (see GET /auth/sessions) the token was issued in
+func (j *JWTIssuer) Issue(userID, role, sessionID string) (string, time.Time, error) {
+	return j.issue(Claims{Subject: userID, Role: role, SessionID: sessionID})
+}
+
+// IssueClient returns an access token for a service account and when it expires
+// Its subject is the account's ID, so everything the account creates is attributed to it;
+// there is no session and no refresh token: the client asks for a new token with its secret
+func (j *JWTIssuer) IssueClient(accountID, role, clientID string) (string, time.Time, error) {
+	return j.issue(Claims{Subject: accountID, Role: role, ClientID: clientID})
+}
+
+// issue fills in the issuer and the lifetime of claims and signs them
+func (j *JWTIssuer) issue(claims Claims) (string, time.Time, error) {
+	now := j.clock.Now()
+	expiresAt := now.Add(j.ttl).Truncate(time.Second)
+	claims.Issuer = tokenIssuer
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = expiresAt.Unix()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	signed := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(j.sign(signed)), expiresAt, nil
+}
+
+// Verify checks token and returns its claims, or ErrInvalidToken
+func (j *JWTIssuer) Verify(token string) (*Claims, error) {
+	// Step 1: Check the shape, the header and the signature before looking at the claims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, j.sign(parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidToken
+	}
+
+	// Step 2: Decode the claims and check who issued the token, for whom and until when
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Issuer != tokenIssuer || claims.Subject == "" || !j.clock.Now().Before(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+// sign computes the HS256 signature of the signing input "<header>.<claims>"
+func (j *JWTIssuer) sign(input string) []byte {
+	mac := hmac.New(sha256.New, j.key)
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,29 @@
+// Package auth carries the authenticated caller through a request
+// This file hashes and checks passwords
+package auth
+
+import (
+	"errors" // For telling a wrong password from a broken hash
+
+	"golang.org/x/crypto/bcrypt" // Slow, salted password hashing
+)
+
+// HashPassword returns the bcrypt hash of password
+// bcrypt only looks at the first 72 bytes; longer passwords are rejected rather than truncated
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches hash
+// A malformed hash is an error rather than a mismatch, so it isn't mistaken for a typo
+func CheckPassword(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
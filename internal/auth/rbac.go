@@ -0,0 +1,65 @@
+// Package auth carries the authenticated caller through a request
+// This file maps roles to what they permit
+package auth
+
+import "context" // The request context that carries the principal
+
+// Permission is something a role allows
+type Permission string
+
+const (
+	// PermissionRead allows reading one's own data
+	PermissionRead Permission = "read"
+
+	// PermissionWrite allows creating, changing and deleting one's own data
+	PermissionWrite Permission = "write"
+
+	// PermissionAdmin allows the admin-only endpoints (user management, global stats, maintenance)
+	PermissionAdmin Permission = "admin"
+)
+
+// rolePermissions lists what each role permits
+var rolePermissions = map[Role][]Permission{
+	RoleViewer: {PermissionRead},
+	RoleMember: {PermissionRead, PermissionWrite},
+	RoleAdmin:  {PermissionRead, PermissionWrite, PermissionAdmin},
+}
+
+// ParseRole returns the role named name, or ok=false for unknown names
+func ParseRole(name string) (role Role, ok bool) {
+	role = Role(name)
+	_, ok = rolePermissions[role]
+	return role, ok
+}
+
+// RolesFor returns the roles a user with the stored role name is granted
+// Unknown or empty names (e.g. from tokens issued before roles existed) count as members
+func RolesFor(name string) []Role {
+	role, ok := ParseRole(name)
+	if !ok {
+		role = RoleMember
+	}
+	return []Role{role}
+}
+
+// Can reports whether the principal's roles permit perm
+// Read-only principals (read-only API keys) are never permitted more than reading
+func (p Principal) Can(perm Permission) bool {
+	if p.ReadOnly && perm != PermissionRead {
+		return false
+	}
+	for _, role := range p.Roles {
+		for _, granted := range rolePermissions[role] {
+			if granted == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Can reports whether the principal stored in ctx is permitted perm
+func Can(ctx context.Context, perm Permission) bool {
+	p, _ := FromContext(ctx)
+	return p.Can(perm)
+}
@@ -0,0 +1,37 @@
+// Package auth carries the authenticated caller through a request
+// This file contains refresh tokens: opaque random values exchanged for new access tokens
+package auth
+
+import (
+	"crypto/rand"     // For unguessable tokens
+	"crypto/sha256"   // For hashing tokens before they are stored
+	"encoding/base64" // For encoding tokens as text
+	"encoding/hex"    // For encoding token hashes as text
+	"time"            // For token lifetimes
+)
+
+// DefaultRefreshTokenTTL is how long a refresh token stays valid when no lifetime is configured
+const DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+// NewRefreshToken returns a new random refresh token and the hash to store for it
+// Unlike access tokens, refresh tokens carry no claims: they are only looked up by their hash
+func NewRefreshToken() (token, hash string, err error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(random)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the hash under which a refresh token is stored
+func HashRefreshToken(token string) string {
+	return hashSecret(token)
+}
+
+// hashSecret returns the SHA-256 of a random secret as hex
+// A fast hash is enough: the secrets have 256 random bits, so they can't be guessed from their hash
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,110 @@
+// Package cache keeps computed values in memory so expensive queries don't run on every request
+// It is a single-process cache: every API instance has its own, and nothing survives a restart,
+// so callers must always be able to recompute a value that is missing
+package cache
+
+import (
+	"strings" // For prefix invalidation
+	"sync"    // For guarding the entries
+	"time"    // For expiry
+
+	"myexpenses/internal/clock"   // Time source, so expiry can be driven by a fake clock
+	"myexpenses/internal/metrics" // Hit and miss counters
+)
+
+// entry is a cached value and when it stops being valid
+type entry struct {
+	value     any
+	expiresAt time.Time // zero means it never expires
+}
+
+// Memory is a concurrency-safe in-memory cache with optional expiry per entry
+type Memory struct {
+	clock    clock.Clock
+	name     string
+	recorder metrics.CacheRecorder
+	mu       sync.RWMutex
+	entries  map[string]entry
+}
+
+// Option configures optional behavior of a Memory cache
+type Option func(*Memory)
+
+// WithRecorder counts every lookup as a hit or miss of the cache called name
+func WithRecorder(name string, recorder metrics.CacheRecorder) Option {
+	return func(m *Memory) {
+		m.name = name
+		m.recorder = recorder
+	}
+}
+
+// NewMemory creates an empty cache whose expiry is measured with clk (nil means the system clock)
+func NewMemory(clk clock.Clock, opts ...Option) *Memory {
+	m := &Memory{
+		clock:    clock.Or(clk),
+		recorder: metrics.Nop{},
+		entries:  make(map[string]entry),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Get returns the value stored under key, if there is one that hasn't expired
+func (m *Memory) Get(key string) (any, bool) {
+	value, ok := m.get(key)
+	m.recorder.RecordCacheLookup(m.name, ok)
+	return value, ok
+}
+
+// get looks key up without counting the lookup
+func (m *Memory) get(key string) (any, bool) {
+	m.mu.RLock()
+	e, ok := m.entries[key]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if !e.expiresAt.IsZero() && !m.clock.Now().Before(e.expiresAt) {
+		m.Delete(key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores value under key for ttl (0 keeps it until it is deleted or replaced)
+func (m *Memory) Set(key string, value any, ttl time.Duration) {
+	e := entry{value: value}
+	if ttl > 0 {
+		e.expiresAt = m.clock.Now().Add(ttl)
+	}
+	m.mu.Lock()
+	m.entries[key] = e
+	m.mu.Unlock()
+}
+
+// Delete removes the value stored under key
+func (m *Memory) Delete(key string) {
+	m.mu.Lock()
+	delete(m.entries, key)
+	m.mu.Unlock()
+}
+
+// DeletePrefix removes every value whose key starts with prefix
+func (m *Memory) DeletePrefix(prefix string) {
+	m.mu.Lock()
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.entries, key)
+		}
+	}
+	m.mu.Unlock()
+}
+
+// Len returns how many values are stored, including expired ones not yet cleaned up
+func (m *Memory) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.entries)
+}
@@ -0,0 +1,115 @@
+// Package calendar writes iCalendar (RFC 5545) feeds that calendar apps can subscribe to
+// Events are whole days: money events happen on a date, not at a time of day
+package calendar
+
+import (
+	"bufio"   // For buffering the feed while it is written
+	"io"      // For streaming the feed
+	"strings" // For escaping and folding text
+	"time"    // For handling dates and times
+)
+
+// ContentType is the MIME type of an iCalendar feed
+const ContentType = "text/calendar; charset=utf-8"
+
+// Event is one all-day calendar entry
+type Event struct {
+	// UID identifies the event across refreshes, so calendar apps update it instead of adding a copy
+	UID string
+
+	// Date is the day the event is on
+	Date time.Time
+
+	// Summary is the title shown in the calendar
+	Summary string
+
+	// Description is the longer text shown when the event is opened
+	Description string
+
+	// Categories are labels some calendar apps use for colors and filters
+	Categories []string
+}
+
+// Calendar is a named feed of events
+type Calendar struct {
+	// Name is the feed name calendar apps show (X-WR-CALNAME)
+	Name string
+
+	// Generated is when the feed was built; it is the DTSTAMP of every event
+	Generated time.Time
+
+	// Events are the entries of the feed
+	Events []Event
+}
+
+// Write streams cal as an iCalendar document into w
+func Write(w io.Writer, cal *Calendar) error {
+	out := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(out, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//MyExpenses//Calendar Feed//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", escapeText(cal.Name))
+	}
+
+	for _, event := range cal.Events {
+		day := event.Date.UTC()
+		line("BEGIN", "VEVENT")
+		line("UID", escapeText(event.UID))
+		line("DTSTAMP", cal.Generated.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE", day.Format("20060102"))
+		line("DTEND;VALUE=DATE", day.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY", escapeText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", escapeText(event.Description))
+		}
+		if len(event.Categories) > 0 {
+			escaped := make([]string, len(event.Categories))
+			for i, category := range event.Categories {
+				escaped[i] = escapeText(category)
+			}
+			line("CATEGORIES", strings.Join(escaped, ","))
+		}
+		// All-day events are free time, so the entries don't block the user's availability
+		line("TRANSP", "TRANSPARENT")
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return out.Flush()
+}
+
+// escapeText escapes a TEXT value: backslashes, separators and line breaks
+func escapeText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(value)
+}
+
+// writeFolded writes a content line, folded at 75 octets as RFC 5545 requires
+// Continuation lines start with a space; multi-byte characters are never split
+func writeFolded(out *bufio.Writer, content string) {
+	const limit = 75
+	width := 0
+	for _, r := range content {
+		size := len(string(r))
+		if width+size > limit {
+			out.WriteString("\r\n ")
+			width = 1
+		}
+		out.WriteRune(r)
+		width += size
+	}
+	out.WriteString("\r\n")
+}
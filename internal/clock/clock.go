@@ -0,0 +1,112 @@
+// Package clock abstracts the current time so time-dependent code can be made deterministic
+// Production code uses Real; tests and demos can use a Fake and move time forward by hand
+// Anything that asks "what time is it?" or "wake me up later" should take a Clock
+// instead of calling time.Now or time.After directly
+package clock
+
+import (
+	"sync" // For guarding the fake clock's state
+	"time" // For handling dates and times
+)
+
+// Clock tells the time and schedules wake-ups
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// After returns a channel that receives the current time once d has elapsed
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the system clock
+type Real struct{}
+
+// Now returns the system time
+func (Real) Now() time.Time { return time.Now() }
+
+// After waits on the system clock
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Or returns c, or the system clock if c is nil
+// Constructors use it so callers may pass nil for "real time"
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real{}
+	}
+	return c
+}
+
+// Fake is a manually driven clock
+// Time only moves when Set or Advance is called, and pending After channels
+// fire as soon as the fake time reaches their deadline
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+// fakeWaiter is a pending After call
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFake creates a fake clock stopped at now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that fires once the fake time has advanced by d
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, fakeWaiter{deadline: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the fake time forward by d and fires the waiters that are due
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	now := f.now.Add(d)
+	f.mu.Unlock()
+	f.Set(now)
+}
+
+// Set moves the fake time to t and fires the waiters that are due
+// Setting a time in the past is allowed and fires nothing
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = t
+	pending := f.waiters[:0]
+	for _, w := range f.waiters {
+		if !w.deadline.After(t) {
+			w.ch <- t
+			continue
+		}
+		pending = append(pending, w)
+	}
+	f.waiters = pending
+}
+
+// Waiters returns how many After calls are still pending
+// Tests use it to wait until a goroutine is blocked on the clock before advancing it
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
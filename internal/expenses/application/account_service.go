@@ -0,0 +1,262 @@
+// Package application contains the business logic and use cases
+// This file contains the account use cases, including ATM withdrawals into the cash wallet
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching domain errors
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// AccountService handles business logic for accounts and transfers
+type AccountService struct {
+	accounts  domain.AccountRepository
+	converter *CurrencyConverter
+}
+
+// NewAccountService creates a new account service
+// converter provides the base currency new accounts default to
+func NewAccountService(accounts domain.AccountRepository, converter *CurrencyConverter) *AccountService {
+	return &AccountService{
+		accounts:  accounts,
+		converter: converter,
+	}
+}
+
+// CreateAccountRequest represents the request body for POST /accounts
+type CreateAccountRequest struct {
+	Name           string             `json:"name" binding:"required"`
+	Type           domain.AccountType `json:"type" binding:"required"`
+	Currency       string             `json:"currency"`
+	OpeningBalance domain.Money       `json:"opening_balance"`
+}
+
+// UpdateAccountRequest represents the request body for PUT /accounts/{id}
+// Fields left out stay unchanged
+type UpdateAccountRequest struct {
+	Name           *string       `json:"name"`
+	OpeningBalance *domain.Money `json:"opening_balance"`
+}
+
+// WithdrawCashRequest represents the request body for POST /cash/withdrawals
+type WithdrawCashRequest struct {
+	// FromAccountID is the bank account the ATM debited
+	FromAccountID string `json:"from_account_id" binding:"required"`
+
+	// Amount is how much cash was withdrawn
+	// It must be greater than 0; NewTransfer rejects it otherwise (a missing amount is 0)
+	Amount domain.Money `json:"amount"`
+
+	// Date is when the withdrawal happened
+	Date time.Time `json:"date" binding:"required"`
+
+	// Envelope optionally earmarks the cash for a category (e.g. "Groceries")
+	Envelope string `json:"envelope"`
+
+	// Description is an optional note such as the ATM location
+	Description string `json:"description"`
+}
+
+// AccountBalance is an account together with its computed balance
+type AccountBalance struct {
+	Account *domain.Account       `json:"account"`
+	Totals  *domain.AccountTotals `json:"totals"`
+	Balance domain.Money          `json:"balance"`
+}
+
+// AccountLedger is an account with every movement on it and the running balance after each
+type AccountLedger struct {
+	Account *domain.Account        `json:"account"`
+	Entries []*domain.AccountEntry `json:"entries"`
+	Balance domain.Money           `json:"balance"`
+}
+
+// CashSummary describes the cash wallet and how its envelopes are doing
+type CashSummary struct {
+	AccountBalance
+	Envelopes []*EnvelopeStatus `json:"envelopes"`
+}
+
+// EnvelopeStatus is the funded/spent/remaining state of one cash envelope
+type EnvelopeStatus struct {
+	*domain.EnvelopeTotals
+	Remaining domain.Money `json:"remaining"`
+}
+
+// CreateAccount adds a new account
+func (s *AccountService) CreateAccount(ctx context.Context, req *CreateAccountRequest) (*domain.Account, error) {
+	currency := req.Currency
+	if currency == "" {
+		currency = s.converter.BaseCurrency()
+	}
+
+	account, err := domain.NewAccount(req.Name, req.Type, currency, req.OpeningBalance)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accounts.Create(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to save account: %w", err)
+	}
+	return account, nil
+}
+
+// ListAccounts returns all accounts
+func (s *AccountService) ListAccounts(ctx context.Context) ([]*domain.Account, error) {
+	accounts, err := s.accounts.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// GetBalance computes the current balance of an account
+// balance = opening balance + transfers in - transfers out - expenses paid from the account
+func (s *AccountService) GetBalance(ctx context.Context, accountID string) (*AccountBalance, error) {
+	account, err := s.accounts.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return s.balanceOf(ctx, account)
+}
+
+// UpdateAccount renames an account or corrects its opening balance
+func (s *AccountService) UpdateAccount(ctx context.Context, accountID string, req *UpdateAccountRequest) (*AccountBalance, error) {
+	account, err := s.accounts.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if err := account.Update(req.Name, req.OpeningBalance); err != nil {
+		return nil, err
+	}
+	if err := s.accounts.Update(ctx, account); err != nil {
+		return nil, fmt.Errorf("failed to save account: %w", err)
+	}
+	return s.balanceOf(ctx, account)
+}
+
+// DeleteAccount removes an account nothing was paid from or transferred with
+func (s *AccountService) DeleteAccount(ctx context.Context, accountID string) error {
+	if err := s.accounts.Delete(ctx, accountID); err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+	return nil
+}
+
+// GetLedger lists the movements of an account, oldest first, with the running balance after each
+// The last running balance is the account's balance
+func (s *AccountService) GetLedger(ctx context.Context, accountID string) (*AccountLedger, error) {
+	// Step 1: The account and what moved money in or out of it
+	account, err := s.accounts.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	expenses, err := s.accounts.ListExpenses(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := s.accounts.ListTransfers(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Run the balance through the movements in order
+	entries := domain.NewAccountEntries(account, expenses, transfers)
+	var balance domain.Money
+	for _, entry := range entries {
+		balance = balance.Add(entry.Amount)
+		entry.Balance = balance
+	}
+	return &AccountLedger{Account: account, Entries: entries, Balance: balance}, nil
+}
+
+// WithdrawCash records an ATM withdrawal as a transfer from a bank account into the cash wallet
+// The withdrawal itself is not an expense; spending happens later when cash expenses are entered
+func (s *AccountService) WithdrawCash(ctx context.Context, req *WithdrawCashRequest) (*domain.Transfer, error) {
+	// Step 1: The money must come from a known, non-cash account
+	from, err := s.accounts.GetByID(ctx, req.FromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source account: %w", err)
+	}
+	if from.Type == domain.AccountTypeCash {
+		return nil, domain.ErrInvalidTransfer
+	}
+
+	// Step 2: Find the cash wallet, creating it on the first withdrawal
+	cash, err := s.CashAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3: Record the transfer
+	transfer, err := domain.NewTransfer(from.ID, cash.ID, req.Amount, req.Date, req.Envelope, req.Description)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accounts.CreateTransfer(ctx, transfer); err != nil {
+		return nil, fmt.Errorf("failed to save withdrawal: %w", err)
+	}
+	return transfer, nil
+}
+
+// GetCashSummary returns the cash wallet balance and the state of each envelope
+func (s *AccountService) GetCashSummary(ctx context.Context) (*CashSummary, error) {
+	cash, err := s.CashAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.balanceOf(ctx, cash)
+	if err != nil {
+		return nil, err
+	}
+
+	envelopes, err := s.accounts.EnvelopeTotals(ctx, cash.ID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get envelopes: %w", err)
+	}
+
+	summary := &CashSummary{AccountBalance: *balance, Envelopes: make([]*EnvelopeStatus, 0, len(envelopes))}
+	for _, envelope := range envelopes {
+		summary.Envelopes = append(summary.Envelopes, &EnvelopeStatus{
+			EnvelopeTotals: envelope,
+			Remaining:      envelope.Funded.Sub(envelope.Spent),
+		})
+	}
+	return summary, nil
+}
+
+// CashAccount returns the cash wallet, creating it if it doesn't exist yet
+func (s *AccountService) CashAccount(ctx context.Context) (*domain.Account, error) {
+	cash, err := s.accounts.FindByType(ctx, domain.AccountTypeCash)
+	if err == nil {
+		return cash, nil
+	}
+	if !errors.Is(err, domain.ErrAccountNotFound) {
+		return nil, fmt.Errorf("failed to find cash account: %w", err)
+	}
+
+	cash, err = domain.NewAccount(domain.CashAccountName, domain.AccountTypeCash, s.converter.BaseCurrency(), domain.Money{})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accounts.Create(ctx, cash); err != nil {
+		return nil, fmt.Errorf("failed to create cash account: %w", err)
+	}
+	return cash, nil
+}
+
+// balanceOf computes the balance of an already loaded account
+func (s *AccountService) balanceOf(ctx context.Context, account *domain.Account) (*AccountBalance, error) {
+	totals, err := s.accounts.Totals(ctx, account.ID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account totals: %w", err)
+	}
+	return &AccountBalance{
+		Account: account,
+		Totals:  totals,
+		Balance: account.OpeningBalance.Add(totals.TransfersIn).Sub(totals.TransfersOut).Sub(totals.Spent),
+	}, nil
+}
@@ -0,0 +1,171 @@
+// Package application contains the business logic and use cases
+// This file contains API key management and authentication
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching repository errors
+	"fmt"     // For formatted string operations and error wrapping
+	"log"     // For logging failed last-used updates
+	"time"    // For throttling last-used updates
+
+	"myexpenses/internal/auth"            // The caller and key generation
+	"myexpenses/internal/clock"           // Time source for last-used times
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For user IDs
+)
+
+// apiKeyTouchInterval is how stale a key's last-used time may get
+// Scripts can send many requests a second; recording every use would be one write per read
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService lets users manage their API keys and authenticates requests made with them
+type APIKeyService struct {
+	repo  domain.APIKeyRepository
+	users domain.UserRepository
+	clock clock.Clock
+}
+
+// NewAPIKeyService creates a new API key service
+// users provides the role of a key's user, which the key acts with
+func NewAPIKeyService(repo domain.APIKeyRepository, users domain.UserRepository, clk clock.Clock) *APIKeyService {
+	return &APIKeyService{repo: repo, users: users, clock: clock.Or(clk)}
+}
+
+// APIKeyRequest represents the request to create or change an API key
+type APIKeyRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Scope string `json:"scope" binding:"required"`
+}
+
+// CreatedAPIKey is a new API key together with its secret, which is never shown again
+type CreatedAPIKey struct {
+	*domain.APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKey creates an API key for the caller
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, req *APIKeyRequest) (*CreatedAPIKey, error) {
+	userID, err := keyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := domain.NewAPIKey(userID, req.Name, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+	secret, hash, prefix, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key.KeyHash, key.Prefix = hash, prefix
+
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+// ListAPIKeys returns the caller's API keys
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	userID, err := keyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, userID)
+}
+
+// GetAPIKey returns one of the caller's API keys
+func (s *APIKeyService) GetAPIKey(ctx context.Context, id string) (*domain.APIKey, error) {
+	userID, err := keyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, userID, id)
+}
+
+// UpdateAPIKey renames one of the caller's API keys or changes its scope
+func (s *APIKeyService) UpdateAPIKey(ctx context.Context, id string, req *APIKeyRequest) (*domain.APIKey, error) {
+	userID, err := keyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := key.Rename(req.Name, req.Scope); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DeleteAPIKey revokes one of the caller's API keys; requests using it fail from then on
+func (s *APIKeyService) DeleteAPIKey(ctx context.Context, id string) error {
+	userID, err := keyOwner(ctx)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, userID, id)
+}
+
+// Authenticate returns the caller a request made with the given API key secret acts as,
+// or ErrAPIKeyNotFound
+// The key acts with its user's current role; a read-only key can't do more than read
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (auth.Principal, error) {
+	key, err := s.repo.GetByHash(ctx, auth.HashAPIKey(secret))
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	user, err := s.users.GetByID(ctx, key.UserID.String())
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			// The keys of removed users stop working with them
+			return auth.Principal{}, domain.ErrAPIKeyNotFound
+		}
+		return auth.Principal{}, err
+	}
+	if user.Disabled() {
+		// Disabling a user switches their keys off too
+		return auth.Principal{}, domain.ErrAPIKeyNotFound
+	}
+
+	// Keep track of use, but don't fail the request if that doesn't work
+	now := s.clock.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			log.Printf("failed to record use of API key %s: %v", key.ID, err)
+		}
+	}
+	return auth.Principal{
+		UserID:   user.ID.String(),
+		Roles:    auth.RolesFor(user.Role),
+		APIKeyID: key.ID.String(),
+		ReadOnly: key.ReadOnly(),
+	}, nil
+}
+
+// keyOwner returns the logged-in caller's user ID
+// API keys are managed by users who logged in: a leaked key mustn't be able to mint more keys
+func keyOwner(ctx context.Context) (uuid.UUID, error) {
+	principal, err := auth.Require(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if principal.APIKeyID != "" {
+		return uuid.Nil, fmt.Errorf("%w: API keys can't manage API keys", domain.ErrForbidden)
+	}
+	if principal.ServiceAccount() {
+		return uuid.Nil, fmt.Errorf("%w: service accounts can't manage API keys", domain.ErrForbidden)
+	}
+	userID, err := uuid.Parse(principal.UserID)
+	if err != nil {
+		return uuid.Nil, domain.ErrForbidden
+	}
+	return userID, nil
+}
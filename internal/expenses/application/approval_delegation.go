@@ -0,0 +1,243 @@
+// Package application contains the business logic and use cases
+// This file contains out-of-office delegations: approvers hand their pending approvals to a delegate
+// for a date range, and the approvals return to them by themselves when it ends
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For parsing the date range
+
+	"myexpenses/internal/auth"            // The approver and the tenant
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For parsing the caller's ID
+)
+
+// Audit actions recorded for delegations
+const (
+	// AuditDelegationCreated is recorded when an approver delegates their approvals
+	AuditDelegationCreated = "approval_delegation.created"
+
+	// AuditDelegationCancelled is recorded when a delegation is ended early
+	AuditDelegationCancelled = "approval_delegation.cancelled"
+
+	// AuditDelegationReverted is recorded once a delegation has run out and the approvals went back
+	AuditDelegationReverted = "approval_delegation.reverted"
+)
+
+// delegationSystemActor is the audit actor of the reversion job, which runs without a caller
+const delegationSystemActor = "system"
+
+// CreateDelegationRequest represents the request body for POST /approval-delegations
+// Dates are YYYY-MM-DD; both days are included
+type CreateDelegationRequest struct {
+	DelegateID string `json:"delegate_id" binding:"required"`
+	StartsOn   string `json:"starts_on" binding:"required"`
+	EndsOn     string `json:"ends_on" binding:"required"`
+	Reason     string `json:"reason"`
+}
+
+// DelegationView is a delegation with its status today
+type DelegationView struct {
+	*domain.ApprovalDelegation
+	Status string `json:"status"`
+}
+
+// PendingApproval is a trip report waiting for the caller's approval
+type PendingApproval struct {
+	*TripApprovalStatus
+
+	// OnBehalfOf names the approver the caller stands in for (empty for the caller's own steps)
+	OnBehalfOf string `json:"on_behalf_of,omitempty"`
+}
+
+// CreateDelegation routes the caller's approvals to a delegate for a date range
+// Delegations of one approver can't overlap, so it is always clear who stands in
+func (s *ApprovalService) CreateDelegation(ctx context.Context, req *CreateDelegationRequest) (*DelegationView, error) {
+	// Step 1: Only users who can approve can delegate
+	approverID, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	delegateID, err := s.userID(ctx, req.DelegateID)
+	if err != nil || delegateID == nil {
+		return nil, domain.ErrInvalidDelegation
+	}
+	startsOn, errStart := time.Parse("2006-01-02", req.StartsOn)
+	endsOn, errEnd := time.Parse("2006-01-02", req.EndsOn)
+	if errStart != nil || errEnd != nil {
+		return nil, domain.ErrInvalidDelegation
+	}
+	now := s.clock.Now()
+	delegation, err := domain.NewApprovalDelegation(approverID, *delegateID, startsOn, endsOn, req.Reason, now)
+	if err != nil {
+		return nil, err
+	}
+	delegation.TenantID = auth.TenantID(ctx)
+
+	// Step 2: Refuse overlapping delegations of the same approver
+	existing, err := s.delegations.ListByUser(ctx, approverID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range existing {
+		if other.ApproverID == approverID && other.CancelledAt == nil && other.Overlaps(delegation) {
+			return nil, domain.ErrDelegationOverlap
+		}
+	}
+
+	// Step 3: Save and record it
+	if err := s.delegations.Create(ctx, delegation); err != nil {
+		return nil, err
+	}
+	if err := s.recordDelegation(ctx, auditActor(ctx), AuditDelegationCreated, delegation); err != nil {
+		return nil, err
+	}
+	return &DelegationView{ApprovalDelegation: delegation, Status: delegation.Status(now)}, nil
+}
+
+// ListDelegations returns the delegations the caller gave or received
+func (s *ApprovalService) ListDelegations(ctx context.Context) ([]*DelegationView, error) {
+	principal, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := uuid.Parse(principal.UserID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	delegations, err := s.delegations.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	views := make([]*DelegationView, len(delegations))
+	for i, delegation := range delegations {
+		views[i] = &DelegationView{ApprovalDelegation: delegation, Status: delegation.Status(now)}
+	}
+	return views, nil
+}
+
+// CancelDelegation ends one of the caller's delegations early; admins may end anyone's
+// From then on the approvals go back to the approver
+func (s *ApprovalService) CancelDelegation(ctx context.Context, id string) error {
+	delegation, err := s.delegations.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if delegation.ApproverID.String() != auth.UserID(ctx) && !auth.Can(ctx, auth.PermissionAdmin) {
+		return domain.ErrDelegationNotFound
+	}
+	if delegation.Status(s.clock.Now()) == domain.DelegationEnded {
+		// Nothing to end any more
+		return domain.ErrDelegationNotFound
+	}
+	at := s.clock.Now().UTC()
+	if err := s.delegations.Cancel(ctx, delegation.ID, at); err != nil {
+		return err
+	}
+	delegation.CancelledAt = &at
+	return s.recordDelegation(ctx, auditActor(ctx), AuditDelegationCancelled, delegation)
+}
+
+// RevertEnded records the end of every delegation that has run out and returns how many there were
+// The approvals went back to the approvers on the day after each delegation's last day anyway;
+// this puts the reversion in the audit log, next to the creation
+func (s *ApprovalService) RevertEnded(ctx context.Context) (int, error) {
+	now := s.clock.Now().UTC()
+	ended, err := s.delegations.ListUnreverted(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	reverted := 0
+	for _, delegation := range ended {
+		if delegation.Status(now) != domain.DelegationEnded {
+			continue
+		}
+		if err := s.recordDelegation(ctx, delegationSystemActor, AuditDelegationReverted, delegation); err != nil {
+			return reverted, err
+		}
+		if err := s.delegations.MarkReverted(ctx, delegation.ID, now); err != nil {
+			return reverted, err
+		}
+		reverted++
+	}
+	return reverted, nil
+}
+
+// PendingApprovals returns the trip reports waiting for a step the caller may approve,
+// including the steps of approvers the caller stands in for
+func (s *ApprovalService) PendingApprovals(ctx context.Context) ([]*PendingApproval, error) {
+	// Step 1: Whom the caller stands in for today
+	now := s.clock.Now().UTC()
+	standingIn, err := s.standingIn(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Every open report whose pending step the caller may approve
+	trips, err := s.reports.ListTrips(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending := []*PendingApproval{}
+	for _, trip := range trips {
+		if trip.Approved() {
+			continue
+		}
+		result, err := s.reports.Report(ctx, trip.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		status, err := s.status(ctx, result)
+		if err != nil {
+			return nil, err
+		}
+		if status.Pending == nil {
+			continue
+		}
+		if onBehalfOf, _, ok := approverOf(ctx, status.Pending, now, standingIn); ok {
+			pending = append(pending, &PendingApproval{TripApprovalStatus: status, OnBehalfOf: onBehalfOf})
+		}
+	}
+	return pending, nil
+}
+
+// standingIn returns the out-of-office delegations to the caller that are active at now
+func (s *ApprovalService) standingIn(ctx context.Context, now time.Time) ([]*domain.ApprovalDelegation, error) {
+	callerID, err := uuid.Parse(auth.UserID(ctx))
+	if err != nil {
+		// Anonymous and admin-token callers receive no delegations
+		return nil, nil
+	}
+	return s.delegations.ListActiveByDelegate(ctx, callerID, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+}
+
+// caller returns the ID of a caller who may approve (and so delegate)
+func (s *ApprovalService) caller(ctx context.Context) (uuid.UUID, error) {
+	principal, err := auth.Require(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !principal.Can(auth.PermissionWrite) {
+		return uuid.Nil, domain.ErrForbidden
+	}
+	id, err := uuid.Parse(principal.UserID)
+	if err != nil {
+		return uuid.Nil, domain.ErrUserNotFound
+	}
+	return id, nil
+}
+
+// recordDelegation writes an audit entry for a delegation
+func (s *ApprovalService) recordDelegation(ctx context.Context, actor, action string, delegation *domain.ApprovalDelegation) error {
+	entry, err := domain.NewAuditEntry(actor, action, "approval_delegation", delegation.ID.String(), delegation)
+	if err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record delegation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+// Package application contains the business logic and use cases
+// This file contains approval chains: the steps a tenant's trip reports are approved in,
+// who may approve each step (including delegates during a vacation) and where a report stands
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching repository errors
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For matching step names
+	"time"    // For parsing delegation dates
+
+	"myexpenses/internal/auth"            // The caller and the tenant
+	"myexpenses/internal/clock"           // Time source for approvals and delegations
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For parsing user IDs
+)
+
+// Approval step statuses in a TripApprovalStatus
+const (
+	// StepApproved has been given
+	StepApproved = "approved"
+
+	// StepPending is the step the report is waiting for
+	StepPending = "pending"
+
+	// StepWaiting comes after the pending step
+	StepWaiting = "waiting"
+
+	// StepSkipped doesn't apply to the report (amount below its tier, or none of its categories)
+	StepSkipped = "skipped"
+)
+
+// AuditStepApproved is recorded for every step approval of a trip report
+const AuditStepApproved = "trip.step_approved"
+
+// ApprovalService manages the tenant's approval chain and takes trip reports through it
+// When the last applicable step approves, the report is frozen and archived
+type ApprovalService struct {
+	chains      domain.ApprovalChainRepository
+	approvals   domain.TripApprovalRepository
+	delegations domain.ApprovalDelegationRepository
+	users       domain.UserRepository
+	reports     *TripService
+	archives    *ReportArchiveService
+	policies    *PolicyService
+	audit       domain.AuditRepository
+	clock       clock.Clock
+}
+
+// NewApprovalService creates a new approval service
+// reports builds the reports the steps are matched against; archives freezes fully approved reports
+// policies checks reports against the expense policy when their first step comes up (nil skips the check)
+func NewApprovalService(chains domain.ApprovalChainRepository, approvals domain.TripApprovalRepository, delegations domain.ApprovalDelegationRepository, users domain.UserRepository, reports *TripService, archives *ReportArchiveService, policies *PolicyService, audit domain.AuditRepository, clk clock.Clock) *ApprovalService {
+	return &ApprovalService{
+		chains:      chains,
+		approvals:   approvals,
+		delegations: delegations,
+		users:       users,
+		reports:     reports,
+		archives:    archives,
+		policies:    policies,
+		audit:       audit,
+		clock:       clock.Or(clk),
+	}
+}
+
+// ApprovalStepRequest is one step of an ApprovalChainRequest
+// Give approver_id or approver_role; delegate_from and delegate_until are YYYY-MM-DD and needed with delegate_id
+type ApprovalStepRequest struct {
+	Name string `json:"name" binding:"required"`
+
+	// MinAmount mustn't be negative; NewApprovalStep rejects it otherwise
+	MinAmount     domain.Money `json:"min_amount"`
+	Categories    []string     `json:"categories"`
+	ApproverID    string       `json:"approver_id"`
+	ApproverRole  string       `json:"approver_role"`
+	DelegateID    string       `json:"delegate_id"`
+	DelegateFrom  string       `json:"delegate_from"`
+	DelegateUntil string       `json:"delegate_until"`
+}
+
+// ApprovalChainRequest represents the request body for PUT /admin/approval-chain
+type ApprovalChainRequest struct {
+	Steps []*ApprovalStepRequest `json:"steps" binding:"required,min=1,dive"`
+}
+
+// ApprovalStepStatus is where one step of the chain stands for a report
+type ApprovalStepStatus struct {
+	Step     *domain.ApprovalStep `json:"step"`
+	Status   string               `json:"status"`
+	Approval *domain.TripApproval `json:"approval,omitempty"`
+}
+
+// TripApprovalStatus is where a trip report stands in the approval chain
+type TripApprovalStatus struct {
+	Trip *domain.Trip `json:"trip"`
+
+	// Total is the report total in the home currency the amount tiers are compared with
+	Total domain.Money `json:"total"`
+
+	Steps []*ApprovalStepStatus `json:"steps"`
+
+	// Pending is the step the report is waiting for (nil once it is approved)
+	Pending *domain.ApprovalStep `json:"pending,omitempty"`
+}
+
+// GetChain returns the caller's tenant chain, or the default chain if the tenant hasn't set its own
+func (s *ApprovalService) GetChain(ctx context.Context) (*domain.ApprovalChain, error) {
+	tenantID := auth.TenantID(ctx)
+	chain, err := s.chains.Get(ctx, tenantID)
+	if errors.Is(err, domain.ErrApprovalChainNotFound) {
+		return domain.DefaultApprovalChain(tenantID), nil
+	}
+	return chain, err
+}
+
+// UpdateChain replaces the caller's tenant chain (admins only)
+// Steps are identified by name: a step that keeps its name keeps the approvals it gave,
+// so reports in progress continue through the new chain without starting over
+func (s *ApprovalService) UpdateChain(ctx context.Context, req *ApprovalChainRequest) (*domain.ApprovalChain, error) {
+	// Step 1: Only admins decide who approves
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	current, err := s.chains.Get(ctx, auth.TenantID(ctx))
+	if err != nil && !errors.Is(err, domain.ErrApprovalChainNotFound) {
+		return nil, err
+	}
+	kept := make(map[string]uuid.UUID)
+	if current != nil {
+		for _, step := range current.Steps {
+			kept[strings.ToLower(step.Name)] = step.ID
+		}
+	}
+
+	// Step 2: Build and validate the steps
+	steps := make([]*domain.ApprovalStep, 0, len(req.Steps))
+	for _, r := range req.Steps {
+		approverID, err := s.userID(ctx, r.ApproverID)
+		if err != nil {
+			return nil, err
+		}
+		step, err := domain.NewApprovalStep(r.Name, r.MinAmount, r.Categories, approverID, r.ApproverRole)
+		if err != nil {
+			return nil, err
+		}
+		if id, ok := kept[strings.ToLower(step.Name)]; ok {
+			step.ID = id
+		}
+		if r.DelegateID != "" || r.DelegateFrom != "" || r.DelegateUntil != "" {
+			delegateID, err := s.userID(ctx, r.DelegateID)
+			if err != nil {
+				return nil, err
+			}
+			from, errFrom := time.Parse("2006-01-02", r.DelegateFrom)
+			until, errUntil := time.Parse("2006-01-02", r.DelegateUntil)
+			if delegateID == nil || errFrom != nil || errUntil != nil {
+				return nil, domain.ErrInvalidApprovalChain
+			}
+			if err := step.SetDelegate(*delegateID, from, until); err != nil {
+				return nil, err
+			}
+		}
+		steps = append(steps, step)
+	}
+
+	// Step 3: Replace the chain
+	chain, err := domain.NewApprovalChain(auth.TenantID(ctx), steps)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.chains.Save(ctx, chain); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// ResetChain removes the caller's tenant chain, so reports use the default chain again (admins only)
+func (s *ApprovalService) ResetChain(ctx context.Context) error {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return domain.ErrForbidden
+	}
+	return s.chains.Delete(ctx, auth.TenantID(ctx))
+}
+
+// Status returns where a trip report stands in the approval chain
+func (s *ApprovalService) Status(ctx context.Context, tripID string) (*TripApprovalStatus, error) {
+	result, err := s.reports.Report(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	return s.status(ctx, result)
+}
+
+// Approve gives the approval of the step a trip report is waiting for
+// The caller must be the step's approver, have its role, or be its delegate during the delegation;
+// the approval of the last applicable step freezes and archives the report
+func (s *ApprovalService) Approve(ctx context.Context, tripID string) (*TripApprovalStatus, error) {
+	// Step 1: Find the step the report is waiting for
+	result, err := s.reports.Report(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if result.Trip.Approved() {
+		return nil, domain.ErrTripApproved
+	}
+	status, err := s.status(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	step := status.Pending
+	if step == nil {
+		// No step applies to the report, or freezing failed after the last approval:
+		// an admin approves it as it is
+		if !auth.Can(ctx, auth.PermissionAdmin) {
+			return nil, domain.ErrNotApprover
+		}
+		return s.finish(ctx, result, status)
+	}
+
+	// Step 2: Check the caller may approve it, themselves or standing in for someone
+	now := s.clock.Now().UTC()
+	standingIn, err := s.standingIn(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	onBehalfOf, delegation, ok := approverOf(ctx, step, now, standingIn)
+	if !ok {
+		return nil, domain.ErrNotApprover
+	}
+
+	// Step 2b: A report is submitted with its first approval, so check it against the expense policy then
+	// A blocking violation keeps it from being approved until the expenses are fixed
+	if err := s.checkPolicy(ctx, result, status); err != nil {
+		return nil, err
+	}
+
+	// Step 3: Record the approval and who gave it
+	approval := &domain.TripApproval{
+		ID:         uuid.New(),
+		TripID:     result.Trip.ID,
+		StepID:     step.ID,
+		StepName:   step.Name,
+		ApprovedBy: auditActor(ctx),
+		OnBehalfOf: onBehalfOf,
+		ApprovedAt: now,
+	}
+	if delegation != nil {
+		approval.DelegationID = &delegation.ID
+	}
+	if err := s.approvals.Create(ctx, approval); err != nil {
+		return nil, err
+	}
+	entry, err := domain.NewAuditEntry(approval.ApprovedBy, AuditStepApproved, "trip", result.Trip.ID.String(), approval)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to record approval: %w", err)
+	}
+
+	// Step 4: Move on to the next step, or freeze the report after the last one
+	status, err = s.status(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	if status.Pending != nil {
+		return status, nil
+	}
+	return s.finish(ctx, result, status)
+}
+
+// checkPolicy checks a report against the expense policy before its first step is approved
+func (s *ApprovalService) checkPolicy(ctx context.Context, result *TripReport, status *TripApprovalStatus) error {
+	if s.policies == nil {
+		return nil
+	}
+	for _, step := range status.Steps {
+		if step.Status == StepApproved {
+			// Checked at submission already
+			return nil
+		}
+	}
+	_, err := s.policies.CheckReport(ctx, result.Trip.ID, result.Expenses)
+	return err
+}
+
+// finish freezes and archives a report whose every applicable step is approved
+func (s *ApprovalService) finish(ctx context.Context, result *TripReport, status *TripApprovalStatus) (*TripApprovalStatus, error) {
+	now := s.clock.Now().UTC()
+	result.Trip.ApprovedAt = &now
+	result.Trip.ApprovedBy = auditActor(ctx)
+	trip, err := s.archives.freeze(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	status.Trip = trip
+	return status, nil
+}
+
+// status matches the report against the chain and the approvals given so far
+func (s *ApprovalService) status(ctx context.Context, result *TripReport) (*TripApprovalStatus, error) {
+	chain, err := s.GetChain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	approvals, err := s.approvals.ListByTrip(ctx, result.Trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	given := make(map[uuid.UUID]*domain.TripApproval, len(approvals))
+	for _, approval := range approvals {
+		given[approval.StepID] = approval
+	}
+
+	// The amount tiers compare with the home-currency total, the category steps with every category used
+	var total domain.Money
+	for _, amount := range result.BaseTotals {
+		total = total.Add(amount)
+	}
+	categories := make([]string, 0, len(result.Expenses))
+	for _, expense := range result.Expenses {
+		categories = append(categories, expense.Category)
+	}
+
+	status := &TripApprovalStatus{Trip: result.Trip, Total: total, Steps: make([]*ApprovalStepStatus, 0, len(chain.Steps))}
+	for _, step := range chain.Steps {
+		entry := &ApprovalStepStatus{Step: step, Approval: given[step.ID]}
+		switch {
+		case entry.Approval != nil:
+			entry.Status = StepApproved
+		case !step.Applies(total, categories):
+			entry.Status = StepSkipped
+		case status.Pending == nil:
+			entry.Status = StepPending
+			status.Pending = step
+		default:
+			entry.Status = StepWaiting
+		}
+		status.Steps = append(status.Steps, entry)
+	}
+	return status, nil
+}
+
+// approverOf reports whether the caller may approve step at now
+// standingIn are the out-of-office delegations the caller received that are active now
+// onBehalfOf names the approver when the caller approves as a delegate, and delegation is
+// the out-of-office delegation used (nil for the step's own delegate)
+func approverOf(ctx context.Context, step *domain.ApprovalStep, now time.Time, standingIn []*domain.ApprovalDelegation) (onBehalfOf string, delegation *domain.ApprovalDelegation, ok bool) {
+	// Read-only callers can't approve anything
+	if !auth.Can(ctx, auth.PermissionWrite) {
+		return "", nil, false
+	}
+	caller := auth.UserID(ctx)
+	switch {
+	case step.ApproverID != nil && step.ApproverID.String() == caller:
+		return "", nil, true
+	case step.ApproverRole != "" && auth.HasRole(ctx, auth.Role(step.ApproverRole)):
+		return "", nil, true
+	case step.DelegateActive(now) && step.DelegateID.String() == caller:
+		return step.Approver(), nil, true
+	}
+	// Out-of-office delegations cover the steps that name the absent approver
+	if step.ApproverID != nil {
+		for _, d := range standingIn {
+			if d.ApproverID == *step.ApproverID && d.Status(now) == domain.DelegationActive {
+				return step.Approver(), d, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// userID parses and checks a user ID of the chain (nil for "")
+func (s *ApprovalService) userID(ctx context.Context, raw string) (*uuid.UUID, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	user, err := s.users.GetByID(ctx, raw)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidApprovalChain
+		}
+		return nil, err
+	}
+	return &user.ID, nil
+}
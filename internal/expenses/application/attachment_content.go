@@ -0,0 +1,84 @@
+// Package application contains the business logic and use cases
+// This file stores attachment content once per tenant, however often the same file is uploaded
+package application
+
+import (
+	"context"       // For request context (cancellation, timeouts)
+	"crypto/sha256" // For hashing the content while it is stored
+	"encoding/hex"  // For the hash in text form
+	"errors"        // For tolerating files that are already gone
+	"fmt"           // For formatted string operations and error wrapping
+	"io"            // For streaming the content
+	"log"           // For reporting files that couldn't be deleted
+
+	"myexpenses/internal/auth"            // For the tenant content is shared within
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/storage"         // Blob storage for the file content
+)
+
+// attachmentContent stores and deletes attachment files, sharing identical content within a tenant
+// Content is hashed while it is written; if the tenant already has the same content, the new copy
+// is dropped and the attachment points at the existing file, whose reference count goes up
+type attachmentContent struct {
+	blobs   domain.AttachmentBlobRepository
+	storage storage.Storage
+}
+
+// store writes content for attachment, at most maxSize bytes, and points the attachment at
+// the shared copy. It returns domain.ErrInvalidAttachment when the content is too large
+// The attachment holds a reference afterwards; release it if the attachment isn't saved
+func (c *attachmentContent) store(ctx context.Context, attachment *domain.Attachment, content io.Reader, maxSize int64) error {
+	// Step 1: Write the content to the attachment's own key, hashing it on the way
+	hash := sha256.New()
+	written, err := c.storage.Put(ctx, attachment.StorageKey, io.TeeReader(io.LimitReader(content, maxSize+1), hash))
+	if err != nil {
+		return fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if written > maxSize {
+		_ = c.storage.Delete(ctx, attachment.StorageKey)
+		return domain.ErrInvalidAttachment
+	}
+	attachment.Size = written
+	attachment.ContentHash = hex.EncodeToString(hash.Sum(nil))
+	attachment.TenantID = auth.TenantID(ctx)
+
+	// Step 2: Reference the tenant's copy of that content, or make this one it
+	blob, err := c.blobs.Acquire(ctx, &domain.AttachmentBlob{
+		TenantID:   attachment.TenantID,
+		Hash:       attachment.ContentHash,
+		StorageKey: attachment.StorageKey,
+		Size:       written,
+	})
+	if err != nil {
+		_ = c.storage.Delete(ctx, attachment.StorageKey)
+		return err
+	}
+
+	// Step 3: Drop our copy if the content was already there
+	if blob.StorageKey != attachment.StorageKey {
+		if err := c.storage.Delete(ctx, attachment.StorageKey); err != nil {
+			log.Printf("failed to delete duplicate attachment content %s: %v", attachment.StorageKey, err)
+		}
+		attachment.StorageKey = blob.StorageKey
+	}
+	return nil
+}
+
+// release drops the attachment's reference to its content and deletes the file once nothing uses it
+// Attachments stored before deduplication own their file, which is deleted right away
+func (c *attachmentContent) release(ctx context.Context, attachment *domain.Attachment) error {
+	key := attachment.StorageKey
+	if attachment.ContentHash != "" {
+		var err error
+		if key, err = c.blobs.Release(ctx, attachment.TenantID, attachment.ContentHash); err != nil {
+			return err
+		}
+		if key == "" {
+			return nil
+		}
+	}
+	if err := c.storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+		return err
+	}
+	return nil
+}
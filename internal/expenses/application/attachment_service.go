@@ -0,0 +1,200 @@
+// Package application contains the business logic and use cases
+// This file contains the use cases for receipt attachments and their OCR indexing
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"io"      // For streaming uploaded files
+	"log"     // For reporting non-fatal OCR failures
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/storage"         // Blob storage for the file content
+)
+
+// AttachmentService handles business logic for expense attachments
+// It coordinates three dependencies: the expense repository (ownership checks),
+// the attachment repository (metadata) and blob storage (file content, shared between identical files)
+type AttachmentService struct {
+	expenses    domain.Repository
+	attachments domain.AttachmentRepository
+	storage     storage.Storage
+	content     *attachmentContent
+	extractor   domain.TextExtractor
+	maxSize     int64
+}
+
+// NewAttachmentService creates a new attachment service
+// The extractor is used to recognize receipt text so it can be searched later
+// blobs reference-counts file content, so a file uploaded again is stored only once per tenant
+// maxSize is the largest file accepted (<= 0 uses domain.MaxAttachmentSize)
+func NewAttachmentService(expenses domain.Repository, attachments domain.AttachmentRepository, blobs domain.AttachmentBlobRepository, store storage.Storage, extractor domain.TextExtractor, maxSize int64) *AttachmentService {
+	if maxSize <= 0 {
+		maxSize = domain.MaxAttachmentSize
+	}
+	return &AttachmentService{
+		expenses:    expenses,
+		attachments: attachments,
+		storage:     store,
+		content:     &attachmentContent{blobs: blobs, storage: store},
+		extractor:   extractor,
+		maxSize:     maxSize,
+	}
+}
+
+// UploadAttachmentRequest describes a file uploaded for an expense
+type UploadAttachmentRequest struct {
+	// FileName is the original name of the uploaded file
+	FileName string
+
+	// ContentType is the MIME type reported by the client
+	ContentType string
+
+	// Size is the file size in bytes as reported by the multipart form
+	Size int64
+
+	// Content streams the file bytes
+	Content io.Reader
+}
+
+// UploadAttachment stores a file for an expense and indexes its OCR text
+// OCR problems never fail the upload - the receipt is kept, it just won't be searchable by content
+func (s *AttachmentService) UploadAttachment(ctx context.Context, expenseID string, req *UploadAttachmentRequest) (*domain.Attachment, error) {
+	// Step 1: Make sure the expense exists before storing anything for it
+	expense, err := s.expenses.GetByID(ctx, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense: %w", err)
+	}
+
+	// Step 2: Create and validate the attachment metadata
+	attachment, err := domain.NewAttachment(expense.ID, req.FileName, req.ContentType, req.Size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+	if req.Size > s.maxSize {
+		return nil, domain.ErrInvalidAttachment
+	}
+
+	// Step 3: Store the file content, or reference it if the same file was uploaded before
+	// The size limit guards against clients lying about the size in the multipart header
+	if err := s.content.store(ctx, attachment, req.Content, s.maxSize); err != nil {
+		return nil, err
+	}
+
+	// Step 4: Recognize the receipt text so it becomes searchable
+	attachment.OCRText = s.extractText(ctx, attachment)
+
+	// Step 5: Save the metadata; release the content if that fails so we don't leak files
+	if err := s.attachments.Create(ctx, attachment); err != nil {
+		_ = s.content.release(ctx, attachment)
+		return nil, fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// ListAttachments returns all attachments of an expense
+func (s *AttachmentService) ListAttachments(ctx context.Context, expenseID string) ([]*domain.Attachment, error) {
+	// Check the expense first so unknown expenses give a 404 instead of an empty list
+	exists, err := s.expenses.Exists(ctx, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check expense existence: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrExpenseNotFound
+	}
+
+	attachments, err := s.attachments.ListByExpense(ctx, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// OpenAttachment returns an attachment's metadata together with a reader for its content
+// The caller must close the returned reader
+func (s *AttachmentService) OpenAttachment(ctx context.Context, expenseID, attachmentID string) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.getOwnedAttachment(ctx, expenseID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.storage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open attachment: %w", err)
+	}
+	return attachment, content, nil
+}
+
+// DeleteAttachment removes an attachment's metadata and its stored file
+// The file is kept while other attachments share it
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, expenseID, attachmentID string) error {
+	attachment, err := s.getOwnedAttachment(ctx, expenseID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	// Delete metadata first: a leftover blob is harmless, a dangling row is not
+	if err := s.attachments.Delete(ctx, attachmentID); err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	if err := s.content.release(ctx, attachment); err != nil {
+		log.Printf("failed to delete attachment content %s: %v", attachment.StorageKey, err)
+	}
+	return nil
+}
+
+// ReindexAttachment runs OCR again for an existing attachment
+// This is useful after installing better OCR languages or fixing a broken scan
+func (s *AttachmentService) ReindexAttachment(ctx context.Context, expenseID, attachmentID string) (*domain.Attachment, error) {
+	attachment, err := s.getOwnedAttachment(ctx, expenseID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment.OCRText = s.extractText(ctx, attachment)
+	if err := s.attachments.UpdateOCRText(ctx, attachmentID, attachment.OCRText); err != nil {
+		return nil, fmt.Errorf("failed to update attachment: %w", err)
+	}
+	return attachment, nil
+}
+
+// getOwnedAttachment loads an attachment and checks that it belongs to the given expense,
+// and that the expense belongs to the caller
+// This prevents reading another expense's receipt by guessing attachment IDs
+func (s *AttachmentService) getOwnedAttachment(ctx context.Context, expenseID, attachmentID string) (*domain.Attachment, error) {
+	exists, err := s.expenses.Exists(ctx, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check expense: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrAttachmentNotFound
+	}
+
+	attachment, err := s.attachments.GetByID(ctx, attachmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	if attachment.ExpenseID.String() != expenseID {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// extractText reads the stored file back and runs it through the text extractor
+// Errors are logged and swallowed because OCR is a best-effort enrichment
+func (s *AttachmentService) extractText(ctx context.Context, attachment *domain.Attachment) string {
+	content, err := s.storage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		log.Printf("failed to open attachment %s for OCR: %v", attachment.ID, err)
+		return ""
+	}
+	defer content.Close()
+
+	text, err := s.extractor.ExtractText(ctx, attachment.ContentType, content)
+	if err != nil {
+		log.Printf("failed to extract text from attachment %s: %v", attachment.ID, err)
+		return ""
+	}
+	return text
+}
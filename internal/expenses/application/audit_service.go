@@ -0,0 +1,30 @@
+// Package application contains the business logic and use cases
+// This file contains reading the audit log
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// maxAuditResults caps a single audit log listing
+const maxAuditResults = 1000
+
+// AuditService lists the audit log
+type AuditService struct {
+	repo domain.AuditRepository
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo domain.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// ListEntries returns the matching audit entries, newest first
+func (s *AuditService) ListEntries(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	if filter.Limit > maxAuditResults {
+		filter.Limit = maxAuditResults
+	}
+	return s.repo.List(ctx, filter)
+}
@@ -0,0 +1,398 @@
+// Package application contains the business logic and use cases
+// This file contains billing for hosted tenants: plans, trials, seats and the subscription state
+// the payment provider reports through webhooks
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching repository errors
+	"fmt"     // For formatted string operations and error wrapping
+	"log"     // For webhooks that are ignored
+
+	"myexpenses/internal/auth"            // The tenant a request is made in
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// PlanFree is the plan of tenants without a current subscription
+const PlanFree = "free"
+
+// DefaultPlans returns the plans of hosted deployments
+// Only the free plan can be used as is; paid plans need the payment provider's price of a seat
+func DefaultPlans() []*domain.Plan {
+	return []*domain.Plan{
+		{
+			ID:   PlanFree,
+			Name: "Free",
+			Capabilities: map[string]bool{
+				CapabilityMultiCurrency: false,
+				CapabilityGroups:        false,
+				CapabilityBankSync:      false,
+			},
+			MaxSeats: 1,
+		},
+		{
+			ID:        "pro",
+			Name:      "Pro",
+			MaxSeats:  10,
+			TrialDays: 14,
+		},
+		{
+			ID:        "business",
+			Name:      "Business",
+			TrialDays: 14,
+		},
+	}
+}
+
+// BillingService manages the subscription of the caller's tenant
+// Plans decide which capabilities a tenant gets (see WithPlans) and how many users it may have
+// (see WithSeatLimit). A subscription the provider gives up collecting makes its tenant read-only
+// until it is paid or canceled
+type BillingService struct {
+	subscriptions domain.SubscriptionRepository
+	provider      domain.BillingProvider
+	users         domain.UserRepository
+	readOnly      *ReadOnlyService
+	transactor    domain.Transactor
+
+	// plans are the plans by ID; prices maps the provider's prices back to them
+	plans  map[string]*domain.Plan
+	order  []*domain.Plan
+	prices map[string]string
+}
+
+// NewBillingService creates a new billing service
+// plans must include PlanFree; their capabilities must be known ones
+func NewBillingService(subscriptions domain.SubscriptionRepository, provider domain.BillingProvider, users domain.UserRepository, readOnly *ReadOnlyService, transactor domain.Transactor, plans []*domain.Plan) (*BillingService, error) {
+	s := &BillingService{
+		subscriptions: subscriptions,
+		provider:      provider,
+		users:         users,
+		readOnly:      readOnly,
+		transactor:    transactor,
+		plans:         make(map[string]*domain.Plan, len(plans)),
+		order:         plans,
+		prices:        make(map[string]string, len(plans)),
+	}
+	for _, plan := range plans {
+		if _, ok := s.plans[plan.ID]; ok {
+			return nil, fmt.Errorf("plan %s is defined twice", plan.ID)
+		}
+		if err := checkCapabilities(plan.Capabilities); err != nil {
+			return nil, fmt.Errorf("plan %s: %w", plan.ID, err)
+		}
+		if plan.MaxSeats < 0 || plan.TrialDays < 0 {
+			return nil, fmt.Errorf("plan %s: seats and trial days can't be negative", plan.ID)
+		}
+		s.plans[plan.ID] = plan
+		if plan.Paid() {
+			s.prices[plan.PriceID] = plan.ID
+		}
+	}
+	if free, ok := s.plans[PlanFree]; !ok || free.Paid() {
+		return nil, fmt.Errorf("a free plan %q without a price is required", PlanFree)
+	}
+	return s, nil
+}
+
+// SubscribeRequest represents the request to subscribe to a paid plan
+type SubscribeRequest struct {
+	Plan  string `json:"plan" binding:"required"`
+	Seats int    `json:"seats" binding:"required"`
+
+	// Email is where invoices and receipts are sent
+	Email string `json:"email" binding:"required"`
+}
+
+// ChangeSubscriptionRequest represents the request to change plans or seats; omitted fields stay
+type ChangeSubscriptionRequest struct {
+	Plan  *string `json:"plan"`
+	Seats *int    `json:"seats"`
+}
+
+// BillingStatus is the caller's tenant's plan and subscription
+type BillingStatus struct {
+	Plan *domain.Plan `json:"plan"`
+
+	// Subscription is nil for tenants that never subscribed
+	Subscription *domain.Subscription `json:"subscription"`
+
+	// Seats is how many users the tenant may have (0 means no limit); SeatsUsed how many it has
+	Seats     int   `json:"seats"`
+	SeatsUsed int64 `json:"seats_used"`
+}
+
+// SubscribeResult is a new subscription and where to pay for it
+type SubscribeResult struct {
+	Subscription *domain.Subscription `json:"subscription"`
+
+	// PaymentURL is the payment provider's page where the customer enters their card; the
+	// subscription becomes active once they do
+	PaymentURL string `json:"payment_url,omitempty"`
+}
+
+// Plans returns every plan, in the order they were configured
+func (s *BillingService) Plans() []*domain.Plan {
+	return s.order
+}
+
+// Status returns the plan and subscription of the caller's tenant
+func (s *BillingService) Status(ctx context.Context) (*BillingStatus, error) {
+	subscription, err := s.subscription(ctx, auth.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.seatsUsed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &BillingStatus{
+		Plan:         s.planOf(subscription),
+		Subscription: subscription,
+		Seats:        s.seatsOf(subscription),
+		SeatsUsed:    used,
+	}, nil
+}
+
+// Plan returns the plan the caller's tenant is on
+func (s *BillingService) Plan(ctx context.Context) (*domain.Plan, error) {
+	subscription, err := s.subscription(ctx, auth.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return s.planOf(subscription), nil
+}
+
+// Subscribe subscribes the caller's tenant to a paid plan
+// A tenant's first subscription starts with the plan's trial; later ones are paid from the start
+func (s *BillingService) Subscribe(ctx context.Context, req *SubscribeRequest) (*SubscribeResult, error) {
+	// Step 1: Check the plan and seats
+	tenantID := auth.TenantID(ctx)
+	plan, ok := s.plans[req.Plan]
+	if !ok || !plan.Paid() {
+		return nil, domain.ErrUnknownPlan
+	}
+	if err := s.checkSeats(ctx, plan, req.Seats); err != nil {
+		return nil, err
+	}
+
+	// Step 2: One current subscription per tenant, and one trial
+	subscription, err := s.subscriptions.Get(ctx, tenantID)
+	switch {
+	case errors.Is(err, domain.ErrSubscriptionNotFound):
+		subscription = &domain.Subscription{TenantID: tenantID}
+	case err != nil:
+		return nil, err
+	case subscription.Current() || subscription.Status == domain.SubscriptionSuspended:
+		return nil, domain.ErrSubscriptionExists
+	}
+	trialDays := plan.TrialDays
+	if subscription.TrialEndsAt != nil {
+		trialDays = 0
+	}
+
+	// Step 3: Subscribe at the provider and remember what it says
+	state, err := s.provider.Subscribe(ctx, &domain.NewSubscription{
+		TenantID:  tenantID,
+		Email:     req.Email,
+		PriceID:   plan.PriceID,
+		Seats:     req.Seats,
+		TrialDays: trialDays,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+	subscription.Apply(state, plan.ID)
+	if err := s.subscriptions.Save(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return &SubscribeResult{Subscription: subscription, PaymentURL: state.PaymentURL}, nil
+}
+
+// ChangeSubscription moves the caller's tenant to another paid plan or number of seats
+func (s *BillingService) ChangeSubscription(ctx context.Context, req *ChangeSubscriptionRequest) (*domain.Subscription, error) {
+	subscription, err := s.subscriptions.Get(ctx, auth.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if !subscription.Current() {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	plan := s.plans[subscription.PlanID]
+	if req.Plan != nil {
+		var ok bool
+		if plan, ok = s.plans[*req.Plan]; !ok || !plan.Paid() {
+			return nil, domain.ErrUnknownPlan
+		}
+	}
+	if plan == nil {
+		return nil, domain.ErrUnknownPlan
+	}
+	seats := subscription.Seats
+	if req.Seats != nil {
+		seats = *req.Seats
+	}
+	if err := s.checkSeats(ctx, plan, seats); err != nil {
+		return nil, err
+	}
+
+	state, err := s.provider.Update(ctx, subscription.ProviderID, plan.PriceID, seats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to change subscription: %w", err)
+	}
+	subscription.Apply(state, plan.ID)
+	if err := s.subscriptions.Save(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// CancelSubscription ends the caller's tenant's subscription; the tenant goes back to the free plan
+func (s *BillingService) CancelSubscription(ctx context.Context) (*domain.Subscription, error) {
+	subscription, err := s.subscriptions.Get(ctx, auth.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Status == domain.SubscriptionCanceled {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	state, err := s.provider.Cancel(ctx, subscription.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel subscription: %w", err)
+	}
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		subscription.Apply(state, "")
+		if err := s.subscriptions.Save(ctx, subscription); err != nil {
+			return err
+		}
+		// A canceled tenant owes nothing more, so a suspension ends with it
+		return s.readOnly.ResumeAfterBilling(ctx, subscription.TenantID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// HandleWebhook applies a webhook of the payment provider
+// Events are delivered at least once and in no particular order; each is applied once, and
+// every event carries the whole subscription, so a late one at worst repeats an older state
+// until the next event. Events about subscriptions this deployment doesn't know are ignored
+func (s *BillingService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	// Step 1: Check where the event comes from
+	event, err := s.provider.ParseEvent(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Step 2: Skip events that were applied before
+		if fresh, err := s.subscriptions.RecordEvent(ctx, event); err != nil || !fresh {
+			return err
+		}
+		state := event.Subscription
+		if state == nil {
+			return nil
+		}
+
+		// Step 3: Find the subscription, by the provider's ID or else the tenant it was created for
+		subscription, err := s.subscriptions.GetByProviderID(ctx, state.ProviderID)
+		if errors.Is(err, domain.ErrSubscriptionNotFound) {
+			subscription, err = s.subscriptions.Get(ctx, state.TenantID)
+		}
+		if errors.Is(err, domain.ErrSubscriptionNotFound) {
+			log.Printf("Ignoring billing event %s (%s) for unknown subscription %s", event.ID, event.Type, state.ProviderID)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Step 4: Take over its state
+		subscription.Apply(state, s.prices[state.PriceID])
+		if err := s.subscriptions.Save(ctx, subscription); err != nil {
+			return err
+		}
+
+		// Step 5: Suspended tenants can only read until they pay
+		if subscription.Status == domain.SubscriptionSuspended {
+			return s.readOnly.SuspendForBilling(ctx, subscription.TenantID)
+		}
+		return s.readOnly.ResumeAfterBilling(ctx, subscription.TenantID)
+	})
+}
+
+// CheckSeat returns domain.ErrSeatLimitReached if the caller's tenant has no seat left for another user
+func (s *BillingService) CheckSeat(ctx context.Context) error {
+	subscription, err := s.subscription(ctx, auth.TenantID(ctx))
+	if err != nil {
+		return err
+	}
+	seats := s.seatsOf(subscription)
+	if seats == 0 {
+		return nil
+	}
+	used, err := s.seatsUsed(ctx)
+	if err != nil {
+		return err
+	}
+	if used >= int64(seats) {
+		return domain.ErrSeatLimitReached
+	}
+	return nil
+}
+
+// subscription returns a tenant's subscription, or nil if it never subscribed
+func (s *BillingService) subscription(ctx context.Context, tenantID string) (*domain.Subscription, error) {
+	subscription, err := s.subscriptions.Get(ctx, tenantID)
+	if errors.Is(err, domain.ErrSubscriptionNotFound) {
+		return nil, nil
+	}
+	return subscription, err
+}
+
+// planOf returns the plan a subscription grants; tenants without a current one are on the free plan
+func (s *BillingService) planOf(subscription *domain.Subscription) *domain.Plan {
+	if subscription != nil && subscription.Current() {
+		if plan, ok := s.plans[subscription.PlanID]; ok {
+			return plan
+		}
+	}
+	return s.plans[PlanFree]
+}
+
+// seatsOf returns how many users a subscription allows (0 means no limit)
+func (s *BillingService) seatsOf(subscription *domain.Subscription) int {
+	plan := s.planOf(subscription)
+	if plan.Paid() {
+		return subscription.Seats
+	}
+	return plan.MaxSeats
+}
+
+// checkSeats checks a number of seats against the plan and the users the tenant already has
+func (s *BillingService) checkSeats(ctx context.Context, plan *domain.Plan, seats int) error {
+	if seats < 1 || seats > domain.MaxSeats || (plan.MaxSeats > 0 && seats > plan.MaxSeats) {
+		return domain.ErrInvalidSeats
+	}
+	used, err := s.seatsUsed(ctx)
+	if err != nil {
+		return err
+	}
+	if int64(seats) < used {
+		return domain.ErrInvalidSeats
+	}
+	return nil
+}
+
+// seatsUsed counts the users of the caller's tenant
+// Users aren't assigned to tenants yet, so every user account takes a seat of the default tenant
+func (s *BillingService) seatsUsed(ctx context.Context) (int64, error) {
+	_, total, err := s.users.List(ctx, domain.UserFilter{Limit: 1})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return total, nil
+}
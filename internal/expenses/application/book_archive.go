@@ -0,0 +1,255 @@
+// Package application contains the business logic and use cases
+// This file contains the export and import of complete books, for moving between deployments
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For matching rules case-insensitively
+
+	"myexpenses/internal/auth"            // Request-scoped caller identity
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// BookArchiveService exports books into archives and imports archives as new books
+// An archive holds everything a book keeps, so a self-hosted book can be moved to the hosted
+// service and back; the receipt files themselves are copied separately, using its manifest
+type BookArchiveService struct {
+	books      domain.BookRepository
+	categories domain.CategoryRepository
+	budgets    domain.BudgetRepository
+	rules      domain.RuleRepository
+	expenses   domain.Repository
+	archives   domain.BookArchiveRepository
+	transactor domain.Transactor
+	clock      clock.Clock
+}
+
+// NewBookArchiveService creates a new book archive service
+func NewBookArchiveService(books domain.BookRepository, categories domain.CategoryRepository, budgets domain.BudgetRepository, rules domain.RuleRepository, expenses domain.Repository, archives domain.BookArchiveRepository, transactor domain.Transactor, clk clock.Clock) *BookArchiveService {
+	return &BookArchiveService{
+		books:      books,
+		categories: categories,
+		budgets:    budgets,
+		rules:      rules,
+		expenses:   expenses,
+		archives:   archives,
+		transactor: transactor,
+		clock:      clock.Or(clk),
+	}
+}
+
+// Export returns the archive of the caller's current book
+func (s *BookArchiveService) Export(ctx context.Context) (*domain.BookArchive, error) {
+	// Step 1: The book's name
+	archive := &domain.BookArchive{Version: domain.BookArchiveVersion, ExportedAt: s.clock.Now().UTC(), Book: domain.DefaultBookName}
+	if bookID := auth.BookID(ctx); bookID != "" {
+		book, err := s.books.GetByID(ctx, bookID)
+		if err != nil {
+			return nil, err
+		}
+		archive.Book = book.Name
+	}
+
+	// Step 2: Categories, budgets and rules
+	categories, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	archive.Categories = make([]*domain.ArchivedCategory, 0, len(categories))
+	for _, category := range categories {
+		archive.Categories = append(archive.Categories, domain.NewArchivedCategory(category))
+	}
+
+	budgets, err := s.budgets.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	archive.Budgets = domain.NewBudgetConfig(budgets, archive.ExportedAt).Budgets
+
+	rules, err := s.rules.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	archive.Rules = make([]*domain.ArchivedRule, 0, len(rules))
+	for _, rule := range rules {
+		archive.Rules = append(archive.Rules, &domain.ArchivedRule{
+			Pattern:        rule.Pattern,
+			Category:       rule.Category,
+			Priority:       rule.Priority,
+			RuleConditions: rule.Conditions(),
+		})
+	}
+
+	// Step 3: The expenses and the manifest of their attachments
+	archive.Expenses = []*domain.Expense{}
+	err = s.expenses.Stream(ctx, map[string]interface{}{}, func(expense *domain.Expense) error {
+		archive.Expenses = append(archive.Expenses, domain.NewArchivedExpense(expense))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if archive.Attachments, err = s.archives.Attachments(ctx); err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// Import recreates an archive as a new book of the caller, with new IDs throughout
+// name names the new book; empty keeps the archived book's name. An archive of a default book
+// can't take its name again, so it becomes "Personal (imported)". Nothing is saved unless
+// the whole archive is
+func (s *BookArchiveService) Import(ctx context.Context, archive *domain.BookArchive, name string) (*domain.BookArchiveImport, error) {
+	// Step 1: Check the archive and name the new book
+	if err := archive.Validate(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(name) == "" {
+		name = archive.Book
+		if strings.EqualFold(strings.TrimSpace(name), domain.DefaultBookName) {
+			name = domain.DefaultBookName + " (imported)"
+		}
+	}
+	book, err := domain.NewBook(name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.BookArchiveImport{
+		Book:        book,
+		ExpenseIDs:  make(map[string]string, len(archive.Expenses)),
+		Attachments: []*domain.ArchivedAttachment{},
+	}
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Step 2: Create the book and work in it from here on
+		if err := s.books.Create(ctx, book); err != nil {
+			return err
+		}
+		principal, _ := auth.FromContext(ctx)
+		principal.BookID = book.ID.String()
+		ctx = auth.WithPrincipal(ctx, principal)
+
+		// Step 3: Categories, parents before their subcategories
+		if err := s.importCategories(ctx, archive.Categories); err != nil {
+			return err
+		}
+		result.Categories = len(archive.Categories)
+
+		// Step 4: Budgets
+		config := &domain.BudgetConfig{Version: domain.BudgetConfigVersion, Budgets: archive.Budgets}
+		budgets, err := config.Validate()
+		if err != nil {
+			return err
+		}
+		for _, budget := range budgets {
+			if err := s.budgets.Create(ctx, budget); err != nil {
+				return fmt.Errorf("failed to import budget for %s: %w", budget.Category, err)
+			}
+		}
+		result.Budgets = len(budgets)
+
+		// Step 5: Rules, each once
+		if result.Rules, err = s.importRules(ctx, archive.Rules); err != nil {
+			return err
+		}
+
+		// Step 6: Expenses, remembering their new IDs for the attachments
+		for _, archived := range archive.Expenses {
+			expense := domain.NewArchivedExpense(archived)
+			expense.ID = uuid.New()
+			if err := s.expenses.Create(ctx, expense); err != nil {
+				return fmt.Errorf("failed to import expense: %w", err)
+			}
+			result.ExpenseIDs[archived.ID.String()] = expense.ID.String()
+		}
+		result.Expenses = len(archive.Expenses)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 7: The manifest of the files to upload again, pointing at the new expenses
+	for _, attachment := range archive.Attachments {
+		imported := *attachment
+		imported.ExpenseID = uuid.MustParse(result.ExpenseIDs[attachment.ExpenseID.String()])
+		result.Attachments = append(result.Attachments, &imported)
+	}
+	return result, nil
+}
+
+// importCategories creates the archived categories in the current book with new IDs
+// The archive has been validated, so every parent is in it and there are no cycles
+func (s *BookArchiveService) importCategories(ctx context.Context, archived []*domain.ArchivedCategory) error {
+	byID := make(map[uuid.UUID]*domain.ArchivedCategory, len(archived))
+	for _, category := range archived {
+		byID[category.ID] = category
+	}
+
+	created := make(map[uuid.UUID]uuid.UUID, len(archived))
+	var create func(entry *domain.ArchivedCategory) (uuid.UUID, error)
+	create = func(entry *domain.ArchivedCategory) (uuid.UUID, error) {
+		if id, ok := created[entry.ID]; ok {
+			return id, nil
+		}
+		category, err := domain.NewCategory(entry.Name, entry.VATRate)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if entry.ParentID != nil {
+			parentID, err := create(byID[*entry.ParentID])
+			if err != nil {
+				return uuid.Nil, err
+			}
+			category.ParentID = &parentID
+		}
+		category.ArchivedAt = entry.ArchivedAt
+		if err := s.categories.Create(ctx, category); err != nil {
+			return uuid.Nil, fmt.Errorf("failed to import category %s: %w", category.Name, err)
+		}
+		created[entry.ID] = category.ID
+		return category.ID, nil
+	}
+
+	for _, category := range archived {
+		if _, err := create(category); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importRules adds the archived rules the book doesn't have yet and returns how many
+// Rules with the same pattern, category and conditions are the same rule, whatever their priority
+func (s *BookArchiveService) importRules(ctx context.Context, archived []*domain.ArchivedRule) (int, error) {
+	existing, err := s.rules.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	known := make(map[string]bool, len(existing)+len(archived))
+	for _, rule := range existing {
+		known[rule.Key()] = true
+	}
+
+	added := 0
+	for _, archivedRule := range archived {
+		rule, err := domain.NewConditionalCategoryRule(archivedRule.Pattern, archivedRule.Category, archivedRule.Priority, archivedRule.RuleConditions)
+		if err != nil {
+			return added, err
+		}
+		key := rule.Key()
+		if known[key] {
+			continue
+		}
+		if err := s.rules.Create(ctx, rule); err != nil {
+			return added, fmt.Errorf("failed to import rule %q: %w", rule.Pattern, err)
+		}
+		known[key] = true
+		added++
+	}
+	return added, nil
+}
@@ -0,0 +1,72 @@
+// Package application contains the business logic and use cases
+// This file contains books: separate sets of expenses, categories and budgets of one user
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// BookService manages the caller's books
+// Which book a request works in is picked per request (see http.UseBook); the repositories
+// then only see the expenses, categories and budgets kept in it
+type BookService struct {
+	books domain.BookRepository
+}
+
+// NewBookService creates a new book service
+func NewBookService(books domain.BookRepository) *BookService {
+	return &BookService{books: books}
+}
+
+// CreateBookRequest represents the request body for POST /books
+type CreateBookRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// BookSummary is a book as listed by GET /books
+// The default book has no ID: requests that don't pick a book work in it
+type BookSummary struct {
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+}
+
+// CreateBook adds a book to keep expenses apart from the others (e.g. a side business)
+func (s *BookService) CreateBook(ctx context.Context, req *CreateBookRequest) (*domain.Book, error) {
+	book, err := domain.NewBook(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.books.Create(ctx, book); err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// ListBooks returns the caller's books, the default book first
+func (s *BookService) ListBooks(ctx context.Context) ([]*BookSummary, error) {
+	books, err := s.books.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]*BookSummary, 0, len(books)+1)
+	summaries = append(summaries, &BookSummary{Name: domain.DefaultBookName, Default: true})
+	for _, book := range books {
+		summaries = append(summaries, &BookSummary{ID: book.ID.String(), Name: book.Name})
+	}
+	return summaries, nil
+}
+
+// GetBook returns one of the caller's books, or domain.ErrBookNotFound
+// UseBook calls it to check the book a request picks
+func (s *BookService) GetBook(ctx context.Context, id string) (*domain.Book, error) {
+	return s.books.GetByID(ctx, id)
+}
+
+// DeleteBook removes one of the caller's books once it has no expenses left,
+// together with its categories and budgets
+func (s *BookService) DeleteBook(ctx context.Context, id string) error {
+	return s.books.Delete(ctx, id)
+}
@@ -0,0 +1,75 @@
+// Package application contains the business logic and use cases
+// This file contains tenant branding: how a tenant's generated PDFs and report emails are labelled
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching repository errors
+
+	"myexpenses/internal/auth"            // The tenant a request is made in
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/report"          // Report documents the branding is applied to
+)
+
+// BrandingService manages the branding of the caller's tenant
+type BrandingService struct {
+	repo domain.BrandingRepository
+}
+
+// NewBrandingService creates a new branding service
+func NewBrandingService(repo domain.BrandingRepository) *BrandingService {
+	return &BrandingService{repo: repo}
+}
+
+// BrandingRequest represents the request to set a tenant's branding
+type BrandingRequest struct {
+	Name       string `json:"name" binding:"required"`
+	LogoURL    string `json:"logo_url"`
+	HeaderText string `json:"header_text"`
+	FooterText string `json:"footer_text"`
+}
+
+// GetBranding returns the branding of the caller's tenant, or the default branding
+// if the tenant hasn't set its own
+func (s *BrandingService) GetBranding(ctx context.Context) (*domain.Branding, error) {
+	tenantID := auth.TenantID(ctx)
+	branding, err := s.repo.Get(ctx, tenantID)
+	if errors.Is(err, domain.ErrBrandingNotFound) {
+		return domain.DefaultBranding(tenantID), nil
+	}
+	return branding, err
+}
+
+// UpdateBranding replaces the branding of the caller's tenant
+func (s *BrandingService) UpdateBranding(ctx context.Context, req *BrandingRequest) (*domain.Branding, error) {
+	branding, err := domain.NewBranding(auth.TenantID(ctx), req.Name, req.LogoURL, req.HeaderText, req.FooterText)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, branding); err != nil {
+		return nil, err
+	}
+	return branding, nil
+}
+
+// ResetBranding removes the caller's tenant branding, so reports use the default again
+func (s *BrandingService) ResetBranding(ctx context.Context) error {
+	return s.repo.Delete(ctx, auth.TenantID(ctx))
+}
+
+// Brand returns doc labelled with the caller's tenant branding
+// doc itself is left untouched, so documents can be shared between requests
+func (s *BrandingService) Brand(ctx context.Context, doc *report.Document) (*report.Document, error) {
+	branding, err := s.GetBranding(ctx)
+	if err != nil {
+		return nil, err
+	}
+	branded := *doc
+	branded.Branding = &report.Branding{
+		Name:    branding.Name,
+		LogoURL: branding.LogoURL,
+		Header:  branding.HeaderText,
+		Footer:  branding.FooterText,
+	}
+	return &branded, nil
+}
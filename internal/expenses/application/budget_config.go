@@ -0,0 +1,136 @@
+// Package application contains the business logic and use cases
+// This file contains the export and import of complete budget configurations,
+// and setting up budgets from the built-in templates
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For matching categories
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// BudgetTemplateRequest represents the request body for POST /budgets/from-template
+type BudgetTemplateRequest struct {
+	// Template is the name of a built-in template (see GET /budgets/templates)
+	Template string `json:"template" binding:"required"`
+
+	// Income is the monthly income the template divides, in the base currency
+	// It must be greater than 0; Instantiate rejects it otherwise
+	Income domain.Money `json:"income"`
+
+	// Categories renames template categories to the user's own (e.g. {"Dining": "Restaurants"})
+	Categories map[string]string `json:"categories"`
+
+	// Mode is merge (the default) or replace, like for imports
+	Mode string `json:"mode"`
+}
+
+// BudgetImportResult tells what an import changed
+type BudgetImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Removed int `json:"removed"`
+
+	// Budgets are all budgets after the import
+	Budgets []*domain.Budget `json:"budgets"`
+}
+
+// ExportBudgets returns the complete budget configuration, ready to be imported elsewhere
+func (s *BudgetService) ExportBudgets(ctx context.Context) (*domain.BudgetConfig, error) {
+	budgets, err := s.ListBudgets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewBudgetConfig(budgets, s.clock.Now()), nil
+}
+
+// ListBudgetTemplates returns the built-in budget templates
+func (s *BudgetService) ListBudgetTemplates() []*domain.BudgetTemplate {
+	return domain.BudgetTemplates()
+}
+
+// FromTemplate sets up the budgets of a built-in template for a monthly income
+func (s *BudgetService) FromTemplate(ctx context.Context, req *BudgetTemplateRequest) (*BudgetImportResult, error) {
+	template, err := domain.LookupBudgetTemplate(req.Template)
+	if err != nil {
+		return nil, err
+	}
+	config, err := template.Instantiate(req.Income, req.Categories)
+	if err != nil {
+		return nil, err
+	}
+	return s.ImportBudgets(ctx, config, req.Mode)
+}
+
+// ImportBudgets applies a budget configuration
+// Budgets of categories that have one already get the new amount; merge (the default) keeps the other
+// budgets, replace removes them. The whole configuration is checked before anything is changed
+func (s *BudgetService) ImportBudgets(ctx context.Context, config *domain.BudgetConfig, mode string) (*BudgetImportResult, error) {
+	// Step 1: Check the configuration and the mode
+	if mode == "" {
+		mode = domain.BudgetImportMerge
+	}
+	if mode != domain.BudgetImportMerge && mode != domain.BudgetImportReplace {
+		return nil, domain.ErrInvalidBudgetConfig
+	}
+	imported, err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Match the imported budgets with the existing ones by category and month
+	existing, err := s.ListBudgets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byCategory := make(map[string]*domain.Budget, len(existing))
+	for _, budget := range existing {
+		byCategory[budgetKey(budget)] = budget
+	}
+
+	// Step 3: Create or update the imported budgets
+	result := &BudgetImportResult{}
+	for _, budget := range imported {
+		key := budgetKey(budget)
+		current, ok := byCategory[key]
+		if !ok {
+			if err := s.budgets.Create(ctx, budget); err != nil {
+				return nil, fmt.Errorf("failed to import budget for %s: %w", budget.Category, err)
+			}
+			result.Created++
+			continue
+		}
+		delete(byCategory, key)
+		if current.Amount == budget.Amount && current.Rollover == budget.Rollover {
+			continue
+		}
+		current.Amount = budget.Amount
+		current.Rollover = budget.Rollover
+		if err := s.budgets.Update(ctx, current); err != nil {
+			return nil, fmt.Errorf("failed to import budget for %s: %w", budget.Category, err)
+		}
+		result.Updated++
+	}
+
+	// Step 4: Replacing removes the budgets the configuration doesn't list
+	if mode == domain.BudgetImportReplace {
+		for _, budget := range byCategory {
+			if err := s.budgets.Delete(ctx, budget.ID.String()); err != nil {
+				return nil, fmt.Errorf("failed to remove budget for %s: %w", budget.Category, err)
+			}
+			result.Removed++
+		}
+	}
+
+	if result.Budgets, err = s.ListBudgets(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// budgetKey identifies a budget by its category (ignoring case) and month
+func budgetKey(budget *domain.Budget) string {
+	return strings.ToLower(budget.Category) + "\x00" + budget.Month
+}
@@ -0,0 +1,267 @@
+// Package application contains the business logic and use cases
+// This file contains the budget use cases, including what-if simulations
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For normalizing category names
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// BudgetService handles business logic for budgets
+type BudgetService struct {
+	budgets    domain.BudgetRepository
+	forecaster *Forecaster
+	clock      clock.Clock
+}
+
+// NewBudgetService creates a new budget service
+// clk decides what "now" is for simulations (nil means the system clock)
+func NewBudgetService(budgets domain.BudgetRepository, forecaster *Forecaster, clk clock.Clock) *BudgetService {
+	return &BudgetService{
+		budgets:    budgets,
+		forecaster: forecaster,
+		clock:      clock.Or(clk),
+	}
+}
+
+// CreateBudgetRequest represents the request body for POST /budgets
+type CreateBudgetRequest struct {
+	Category string `json:"category" binding:"required"`
+
+	// Amount must be greater than 0; NewBudget rejects it otherwise (a missing amount is 0)
+	Amount domain.Money `json:"amount"`
+
+	// Month limits the budget to one month in YYYY-MM format; empty applies it to every month
+	Month string `json:"month"`
+
+	// Rollover carries unspent budget (or overspending) into the next month
+	Rollover bool `json:"rollover"`
+}
+
+// UpdateBudgetRequest represents the request body for PUT /budgets/{id}
+type UpdateBudgetRequest struct {
+	// Amount must be greater than 0; SetAmount rejects it otherwise
+	Amount domain.Money `json:"amount"`
+
+	// Rollover switches carrying into the next month on or off; omitted leaves it as it is
+	Rollover *bool `json:"rollover"`
+}
+
+// BudgetChange is a hypothetical change to one category's monthly limit
+// An amount of 0 simulates removing the budget
+type BudgetChange struct {
+	Category string       `json:"category" binding:"required"`
+	Amount   domain.Money `json:"amount"`
+}
+
+// SimulateBudgetRequest represents the request body for POST /budgets/simulate
+type SimulateBudgetRequest struct {
+	// Month is the month to simulate in YYYY-MM format (defaults to the current month)
+	Month string `json:"month"`
+
+	// BudgetChanges replace the saved limits of the given categories
+	BudgetChanges []BudgetChange `json:"budget_changes" binding:"dive"`
+
+	// PlannedExpenses are hypothetical expenses added on top of actual spending
+	PlannedExpenses []PlannedExpense `json:"planned_expenses" binding:"dive"`
+}
+
+// SimulationResult compares the forecast with the saved budgets to the hypothetical scenario
+type SimulationResult struct {
+	Baseline *Forecast `json:"baseline"`
+	Scenario *Forecast `json:"scenario"`
+}
+
+// BudgetStatus is how a category's budget stands in one month
+type BudgetStatus struct {
+	Category string       `json:"category"`
+	Month    string       `json:"month"`
+	Budget   domain.Money `json:"budget"`
+
+	// CarriedOver and Available are as in BudgetPacing
+	CarriedOver domain.Money `json:"carried_over"`
+	Available   domain.Money `json:"available"`
+
+	Spent       domain.Money `json:"spent"`
+	Remaining   domain.Money `json:"remaining"`
+	PercentUsed float64      `json:"percent_used"`
+	OverBudget  bool         `json:"over_budget"`
+}
+
+// CreateBudget adds a monthly limit for a category, for every month or just one
+func (s *BudgetService) CreateBudget(ctx context.Context, req *CreateBudgetRequest) (*domain.Budget, error) {
+	budget, err := domain.NewBudget(req.Category, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := budget.SetMonth(req.Month); err != nil {
+		return nil, err
+	}
+	budget.Rollover = req.Rollover
+	if err := s.budgets.Create(ctx, budget); err != nil {
+		return nil, fmt.Errorf("failed to save budget: %w", err)
+	}
+	return budget, nil
+}
+
+// ListBudgets returns all budgets
+func (s *BudgetService) ListBudgets(ctx context.Context) ([]*domain.Budget, error) {
+	budgets, err := s.budgets.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+// UpdateBudget changes the monthly limit of a budget
+func (s *BudgetService) UpdateBudget(ctx context.Context, id string, req *UpdateBudgetRequest) (*domain.Budget, error) {
+	budget, err := s.budgets.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	if err := budget.SetAmount(req.Amount); err != nil {
+		return nil, err
+	}
+	if req.Rollover != nil {
+		budget.Rollover = *req.Rollover
+	}
+	if err := s.budgets.Update(ctx, budget); err != nil {
+		return nil, fmt.Errorf("failed to save budget: %w", err)
+	}
+	return budget, nil
+}
+
+// DeleteBudget removes a budget
+func (s *BudgetService) DeleteBudget(ctx context.Context, id string) error {
+	if err := s.budgets.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	return nil
+}
+
+// Simulate forecasts the month-end outcome of hypothetical budget changes and planned expenses
+// Nothing is persisted: the saved budgets are copied, changed in memory and forecast twice
+func (s *BudgetService) Simulate(ctx context.Context, req *SimulateBudgetRequest) (*SimulationResult, error) {
+	// Step 1: Work out which month to simulate
+	now := s.clock.Now()
+	month := domain.MonthStart(now)
+	if req.Month != "" {
+		parsed, err := domain.ParseMonth(req.Month)
+		if err != nil {
+			return nil, err
+		}
+		month = parsed
+	}
+
+	// Step 2: Load the budgets that apply in that month as the baseline
+	saved, err := s.budgets.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	saved = domain.EffectiveBudgets(saved, month)
+	baseline := make(map[string]domain.Money, len(saved))
+	for _, budget := range saved {
+		baseline[budget.Category] = budget.Amount
+	}
+
+	// Step 3: Apply the hypothetical changes to a copy
+	scenario := make(map[string]domain.Money, len(baseline))
+	for category, amount := range baseline {
+		scenario[category] = amount
+	}
+	for _, change := range req.BudgetChanges {
+		category := strings.TrimSpace(change.Category)
+		if category == "" {
+			return nil, domain.ErrInvalidCategory
+		}
+		if change.Amount.IsNegative() {
+			return nil, domain.ErrInvalidBudget
+		}
+		if change.Amount.IsZero() {
+			delete(scenario, category)
+			continue
+		}
+		scenario[category] = change.Amount
+	}
+
+	// Step 4: Forecast both and return them side by side
+	baselineForecast, err := s.forecaster.Forecast(ctx, ForecastInput{Month: month, AsOf: now, Budgets: baseline})
+	if err != nil {
+		return nil, err
+	}
+	scenarioForecast, err := s.forecaster.Forecast(ctx, ForecastInput{
+		Month:   month,
+		AsOf:    now,
+		Budgets: scenario,
+		Planned: req.PlannedExpenses,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SimulationResult{Baseline: baselineForecast, Scenario: scenarioForecast}, nil
+}
+
+// CategoryStatus returns how the budget of category stands in the month containing on
+// It returns nil (and no error) when the category has no budget
+// Called with a transaction context, it includes expenses written earlier in that transaction
+func (s *BudgetService) CategoryStatus(ctx context.Context, category string, on time.Time) (*BudgetStatus, error) {
+	// Step 1: Find the category's budget for that month
+	start := domain.MonthStart(on)
+	budgets, err := s.budgets.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	var budget *domain.Budget
+	for _, candidate := range domain.EffectiveBudgets(budgets, start) {
+		if strings.EqualFold(candidate.Category, category) {
+			budget = candidate
+			break
+		}
+	}
+	if budget == nil {
+		return nil, nil
+	}
+
+	// Step 2: Add up the whole month's spending in that category
+	spending, err := s.forecaster.spending.SpendingByCategory(ctx, start, start.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spending: %w", err)
+	}
+	var spent domain.Money
+	for _, row := range spending {
+		if strings.EqualFold(row.Category, budget.Category) {
+			spent = spent.Add(row.Amount)
+		}
+	}
+
+	// Step 3: Compare with the budget plus what it carries over
+	available := budget.Amount
+	var carried domain.Money
+	if budget.Rollover {
+		carry, err := s.carryOver(ctx, budgets, start)
+		if err != nil {
+			return nil, err
+		}
+		carried = carry[strings.ToLower(budget.Category)]
+		available = budget.Amount.Add(carried)
+	}
+	status := &BudgetStatus{
+		Category:    budget.Category,
+		Month:       start.Format("2006-01"),
+		Budget:      budget.Amount,
+		CarriedOver: carried,
+		Available:   available,
+		Spent:       spent,
+		Remaining:   available.Sub(spent),
+		OverBudget:  spent.Minor > available.Minor,
+	}
+	if available.Minor > 0 {
+		status.PercentUsed = domain.RoundAmount(spent.Float64() / available.Float64() * 100)
+	}
+	return status, nil
+}
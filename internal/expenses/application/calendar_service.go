@@ -0,0 +1,246 @@
+// Package application contains the business logic and use cases
+// This file contains recurring expenses and the calendar feed of upcoming money events
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatting event texts
+	"sort"    // For ordering events
+	"strings" // For building event descriptions
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/calendar"        // iCalendar feed documents
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// Calendar feed horizon
+const (
+	// DefaultCalendarDays is how far ahead the feed looks when the client doesn't say
+	DefaultCalendarDays = 90
+
+	// MaxCalendarDays is the furthest ahead the feed looks
+	MaxCalendarDays = 366
+)
+
+// CalendarService manages recurring expenses and turns them, together with the budget periods,
+// into calendar events: due dates, bill reminders, and the start and end of each budget month
+// Money owed to the user shows up too, with its own reminders
+type CalendarService struct {
+	recurring   domain.RecurringExpenseRepository
+	receivables domain.ReceivableRepository
+	budgets     domain.BudgetRepository
+	clock       clock.Clock
+}
+
+// NewCalendarService creates a new calendar service
+func NewCalendarService(recurring domain.RecurringExpenseRepository, receivables domain.ReceivableRepository, budgets domain.BudgetRepository, clk clock.Clock) *CalendarService {
+	return &CalendarService{
+		recurring:   recurring,
+		receivables: receivables,
+		budgets:     budgets,
+		clock:       clock.Or(clk),
+	}
+}
+
+// CreateRecurringExpenseRequest represents the request body for POST /recurring-expenses
+type CreateRecurringExpenseRequest struct {
+	Description string `json:"description" binding:"required"`
+
+	// Amount must be greater than 0; NewRecurringExpense rejects it otherwise
+	Amount       domain.Money `json:"amount"`
+	Currency     string       `json:"currency"`
+	Category     string       `json:"category" binding:"required"`
+	Cadence      string       `json:"cadence" binding:"required"`
+	StartDate    time.Time    `json:"start_date" binding:"required"`
+	EndDate      *time.Time   `json:"end_date"`
+	ReminderDays int          `json:"reminder_days"`
+}
+
+// CreateRecurringExpense saves a new recurring expense
+func (s *CalendarService) CreateRecurringExpense(ctx context.Context, req *CreateRecurringExpenseRequest) (*domain.RecurringExpense, error) {
+	recurring, err := domain.NewRecurringExpense(req.Description, req.Amount, req.Currency, req.Category,
+		domain.Cadence(strings.ToLower(strings.TrimSpace(req.Cadence))), req.StartDate, req.EndDate, req.ReminderDays)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recurring.Create(ctx, recurring); err != nil {
+		return nil, err
+	}
+	return recurring, nil
+}
+
+// ListRecurringExpenses returns all recurring expenses
+func (s *CalendarService) ListRecurringExpenses(ctx context.Context) ([]*domain.RecurringExpense, error) {
+	return s.recurring.List(ctx)
+}
+
+// DeleteRecurringExpense removes a recurring expense; its events leave the feed on the next refresh
+func (s *CalendarService) DeleteRecurringExpense(ctx context.Context, id string) error {
+	return s.recurring.Delete(ctx, id)
+}
+
+// Feed returns the money events of the next days days
+// The feed starts at the beginning of the current month so the running budget period is included
+func (s *CalendarService) Feed(ctx context.Context, days int) (*calendar.Calendar, error) {
+	if days <= 0 {
+		days = DefaultCalendarDays
+	}
+	if days > MaxCalendarDays {
+		days = MaxCalendarDays
+	}
+	now := s.clock.Now().UTC()
+	from := domain.MonthStart(now)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+
+	recurring, err := s.recurring.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := s.budgets.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Settled receivables have nothing left to remind about
+	receivables, err := s.receivables.List(ctx, domain.ReceivableFilter{AsOf: now})
+	if err != nil {
+		return nil, err
+	}
+
+	var events []calendar.Event
+	for _, item := range recurring {
+		events = append(events, recurringEvents(item, from, to)...)
+	}
+	for _, item := range receivables {
+		if item.SettledAt == nil {
+			events = append(events, receivableEvents(item, from, to)...)
+		}
+	}
+	events = append(events, budgetPeriodEvents(budgets, from, to)...)
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date) })
+	return &calendar.Calendar{Name: "MyExpenses", Generated: now, Events: events}, nil
+}
+
+// recurringEvents returns the due dates of a recurring expense between from and to,
+// each preceded by its reminder when one is configured
+// Reminders are separate events rather than alarms: many calendar apps ignore alarms in subscribed feeds
+func recurringEvents(item *domain.RecurringExpense, from, to time.Time) []calendar.Event {
+	amount := formatCalendarAmount(item.Amount, item.Currency)
+
+	var events []calendar.Event
+	// Reminders for due dates just after the window still fall inside it
+	for _, due := range item.Occurrences(from, to.AddDate(0, 0, item.ReminderDays)) {
+		key := item.ID.String() + "-" + due.Format("20060102")
+		if !due.After(to) {
+			events = append(events, calendar.Event{
+				UID:         key + "@myexpenses",
+				Date:        due,
+				Summary:     fmt.Sprintf("%s due (%s)", item.Description, amount),
+				Description: fmt.Sprintf("%s payment of %s (%s), booked under %s", item.Description, amount, item.Cadence, item.Category),
+				Categories:  []string{"Bill", item.Category},
+			})
+		}
+		if item.ReminderDays > 0 {
+			remindOn := due.AddDate(0, 0, -item.ReminderDays)
+			if remindOn.Before(from) || remindOn.After(to) {
+				continue
+			}
+			events = append(events, calendar.Event{
+				UID:         key + "-reminder@myexpenses",
+				Date:        remindOn,
+				Summary:     fmt.Sprintf("Reminder: %s due in %d days", item.Description, item.ReminderDays),
+				Description: fmt.Sprintf("%s of %s is due on %s", item.Description, amount, due.Format("2006-01-02")),
+				Categories:  []string{"Reminder", item.Category},
+			})
+		}
+	}
+	return events
+}
+
+// receivableEvents returns the due date of a receivable between from and to,
+// preceded by its reminder when one is configured
+// Receivables without a due date have no events
+func receivableEvents(item *domain.Receivable, from, to time.Time) []calendar.Event {
+	if item.DueDate == nil {
+		return nil
+	}
+	due := *item.DueDate
+	amount := formatCalendarAmount(item.Outstanding(), item.Currency)
+	what := item.Debtor + " owes you " + amount
+	if item.Description != "" {
+		what += " for " + item.Description
+	}
+	key := "receivable-" + item.ID.String()
+
+	var events []calendar.Event
+	if !due.Before(from) && !due.After(to) {
+		events = append(events, calendar.Event{
+			UID:         key + "@myexpenses",
+			Date:        due,
+			Summary:     fmt.Sprintf("%s to pay back %s", item.Debtor, amount),
+			Description: what,
+			Categories:  []string{"Receivable"},
+		})
+	}
+	if item.ReminderDays > 0 {
+		remindOn := due.AddDate(0, 0, -item.ReminderDays)
+		if !remindOn.Before(from) && !remindOn.After(to) {
+			events = append(events, calendar.Event{
+				UID:         key + "-reminder@myexpenses",
+				Date:        remindOn,
+				Summary:     fmt.Sprintf("Reminder: %s owes you %s in %d days", item.Debtor, amount, item.ReminderDays),
+				Description: fmt.Sprintf("%s, due on %s", what, due.Format("2006-01-02")),
+				Categories:  []string{"Reminder", "Receivable"},
+			})
+		}
+	}
+	return events
+}
+
+// budgetPeriodEvents returns the first and last day of every budget month between from and to
+// Each month lists the budgets that apply in it; months without budgets aren't marked
+func budgetPeriodEvents(budgets []*domain.Budget, from, to time.Time) []calendar.Event {
+	var events []calendar.Event
+	for month := domain.MonthStart(from); !month.After(to); month = month.AddDate(0, 1, 0) {
+		effective := domain.EffectiveBudgets(budgets, month)
+		if len(effective) == 0 {
+			continue
+		}
+		var total domain.Money
+		lines := make([]string, len(effective))
+		for i, budget := range effective {
+			total = total.Add(budget.Amount)
+			lines[i] = fmt.Sprintf("%s: %s", budget.Category, budget.Amount)
+		}
+		description := fmt.Sprintf("Monthly budgets (%s in total):\n%s", total, strings.Join(lines, "\n"))
+
+		label := month.Format("January 2006")
+		key := month.Format("200601")
+		events = append(events, calendar.Event{
+			UID:         "budget-" + key + "-start@myexpenses",
+			Date:        month,
+			Summary:     "Budget period starts: " + label,
+			Description: description,
+			Categories:  []string{"Budget"},
+		})
+		if end := month.AddDate(0, 1, -1); !end.After(to) {
+			events = append(events, calendar.Event{
+				UID:         "budget-" + key + "-end@myexpenses",
+				Date:        end,
+				Summary:     "Budget period ends: " + label,
+				Description: description,
+				Categories:  []string{"Budget"},
+			})
+		}
+	}
+	return events
+}
+
+// formatCalendarAmount formats an amount with its currency when it has one
+func formatCalendarAmount(amount domain.Money, currency string) string {
+	if currency == "" {
+		return amount.String()
+	}
+	return fmt.Sprintf("%s %s", amount, currency)
+}
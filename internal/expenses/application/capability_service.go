@@ -0,0 +1,117 @@
+// Package application contains the business logic and use cases
+// This file contains capability discovery: which optional features a client should offer,
+// so one client build can serve deployments and tenants with different features switched on
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For describing unknown capabilities
+	"log"     // For plans that can't be read
+	"sort"    // For listing capability names in a stable order
+
+	"myexpenses/internal/auth" // The tenant a request is made in
+)
+
+// Optional features a client may offer
+const (
+	// CapabilityMultiCurrency is recording expenses in foreign currencies
+	CapabilityMultiCurrency = "multi_currency"
+
+	// CapabilityAttachments is uploading receipts and other files to expenses
+	CapabilityAttachments = "attachments"
+
+	// CapabilityGroups is shared group budgets
+	CapabilityGroups = "groups"
+
+	// CapabilityBankSync is pulling transactions from bank accounts automatically
+	CapabilityBankSync = "bank_sync"
+)
+
+// KnownCapabilities are the capability names the API advertises
+var KnownCapabilities = []string{CapabilityMultiCurrency, CapabilityAttachments, CapabilityGroups, CapabilityBankSync}
+
+// CapabilityService tells clients which optional features are on for the caller's tenant
+// The deployment decides the defaults; tenants can be switched on early (a soft launch)
+// or off, without the client having to know which deployment or tenant it talks to
+type CapabilityService struct {
+	deployment map[string]bool
+	tenants    map[string]map[string]bool
+
+	// billing gates capabilities by the tenant's plan (nil when tenants don't pay)
+	billing *BillingService
+}
+
+// CapabilityOption configures optional behavior of the capability service
+type CapabilityOption func(*CapabilityService)
+
+// WithPlans gates capabilities by the plan of the caller's tenant
+// A plan can switch off what the deployment offers, but per-tenant overrides still win over it
+func WithPlans(billing *BillingService) CapabilityOption {
+	return func(s *CapabilityService) {
+		s.billing = billing
+	}
+}
+
+// NewCapabilityService creates a capability service
+// deployment holds the deployment-wide settings (missing capabilities are off);
+// tenants holds per-tenant overrides of them, by tenant ID
+func NewCapabilityService(deployment map[string]bool, tenants map[string]map[string]bool, opts ...CapabilityOption) (*CapabilityService, error) {
+	if err := checkCapabilities(deployment); err != nil {
+		return nil, err
+	}
+	for tenantID, overrides := range tenants {
+		if err := checkCapabilities(overrides); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+	}
+	s := &CapabilityService{deployment: deployment, tenants: tenants}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Capabilities returns every known capability and whether it is on for the caller's tenant
+func (s *CapabilityService) Capabilities(ctx context.Context) map[string]bool {
+	overrides := s.tenants[auth.TenantID(ctx)]
+	var plan map[string]bool
+	if s.billing != nil {
+		// Without the plan the deployment's settings apply; that beats failing the request
+		if current, err := s.billing.Plan(ctx); err != nil {
+			log.Printf("Failed to get the plan for capabilities: %v", err)
+		} else {
+			plan = current.Capabilities
+		}
+	}
+	capabilities := make(map[string]bool, len(KnownCapabilities))
+	for _, name := range KnownCapabilities {
+		enabled := s.deployment[name]
+		if allowed, ok := plan[name]; ok {
+			enabled = enabled && allowed
+		}
+		if override, ok := overrides[name]; ok {
+			enabled = override
+		}
+		capabilities[name] = enabled
+	}
+	return capabilities
+}
+
+// checkCapabilities rejects capability names the API doesn't know, which are usually typos
+func checkCapabilities(capabilities map[string]bool) error {
+	known := make(map[string]bool, len(KnownCapabilities))
+	for _, name := range KnownCapabilities {
+		known[name] = true
+	}
+	var unknown []string
+	for name := range capabilities {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown capabilities %v (known: %v)", unknown, KnownCapabilities)
+	}
+	return nil
+}
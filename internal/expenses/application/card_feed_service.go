@@ -0,0 +1,210 @@
+// Package application contains the business logic and use cases
+// This file contains corporate card feeds: admins map cards to their holders and import the feed,
+// and every transaction lands in its cardholder's staging queue to be coded and approved
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For telling unmapped cards apart from failures
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/auth"            // The caller's permissions and tenant
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// Audit actions recorded for corporate cards
+const (
+	// AuditCorporateCardMapped is recorded when an admin maps a card to its holder
+	AuditCorporateCardMapped = "corporate_card.mapped"
+
+	// AuditCorporateCardUnmapped is recorded when an admin removes a card mapping
+	AuditCorporateCardUnmapped = "corporate_card.unmapped"
+)
+
+// CardFeedService manages the card-to-cardholder mapping and imports corporate card feeds
+// Transactions don't become expenses here: the cardholder codes each one in the staging area,
+// and approving it there creates the expense in their name
+type CardFeedService struct {
+	cards       domain.CorporateCardRepository
+	staging     domain.PublicFormRepository
+	users       domain.UserRepository
+	categorizer domain.Categorizer
+	normalizer  *DescriptionNormalizer
+	audit       domain.AuditRepository
+}
+
+// NewCardFeedService creates a new card feed service
+// categorizer pre-fills the category of staged transactions (usually the same chain imports use)
+func NewCardFeedService(cards domain.CorporateCardRepository, staging domain.PublicFormRepository, users domain.UserRepository, categorizer domain.Categorizer, normalizer *DescriptionNormalizer, audit domain.AuditRepository) *CardFeedService {
+	return &CardFeedService{
+		cards:       cards,
+		staging:     staging,
+		users:       users,
+		categorizer: categorizer,
+		normalizer:  normalizer,
+		audit:       audit,
+	}
+}
+
+// MapCorporateCardRequest represents the request body for POST /admin/corporate-cards
+type MapCorporateCardRequest struct {
+	Last4  string `json:"last4" binding:"required,len=4,numeric"`
+	UserID string `json:"user_id" binding:"required"`
+	Label  string `json:"label"`
+}
+
+// ImportCardFeedRequest represents the request body for POST /admin/card-feeds
+type ImportCardFeedRequest struct {
+	Transactions []domain.CardTransaction `json:"transactions" binding:"required,min=1,dive"`
+}
+
+// CardFeedResult summarizes what happened during a feed import
+type CardFeedResult struct {
+	// Staged are the transactions that landed in a cardholder's queue
+	Staged []*domain.StagedExpense `json:"staged"`
+
+	// Skipped counts transactions whose feed ID was imported before
+	Skipped int `json:"skipped"`
+
+	// Unassigned are the transactions of cards nobody is mapped to; once the card is mapped,
+	// importing the feed again stages them
+	Unassigned []domain.CardTransaction `json:"unassigned"`
+}
+
+// MapCard maps a card's last four digits to its holder
+func (s *CardFeedService) MapCard(ctx context.Context, req *MapCorporateCardRequest) (*domain.CorporateCard, error) {
+	// Step 1: Only admins hand out cards
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+
+	// Step 2: The cardholder must be a user
+	holder, err := s.users.GetByID(ctx, req.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidCorporateCard
+		}
+		return nil, err
+	}
+	card, err := domain.NewCorporateCard(req.Last4, holder.ID, req.Label)
+	if err != nil {
+		return nil, err
+	}
+	card.TenantID = auth.TenantID(ctx)
+
+	// Step 3: Save and record it
+	if err := s.cards.Create(ctx, card); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, AuditCorporateCardMapped, card.ID.String(), card); err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// ListCards returns the cards of the caller's tenant
+func (s *CardFeedService) ListCards(ctx context.Context) ([]*domain.CorporateCard, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	return s.cards.List(ctx, auth.TenantID(ctx))
+}
+
+// UnmapCard removes a card mapping; later transactions of the card are left unassigned
+func (s *CardFeedService) UnmapCard(ctx context.Context, id string) error {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return domain.ErrForbidden
+	}
+	if err := s.cards.Delete(ctx, auth.TenantID(ctx), id); err != nil {
+		return err
+	}
+	return s.record(ctx, AuditCorporateCardUnmapped, id, nil)
+}
+
+// ImportFeed stages every new transaction of a card feed in its cardholder's queue
+// Transactions whose feed ID was staged before are skipped, so importing a feed twice is safe
+func (s *CardFeedService) ImportFeed(ctx context.Context, req *ImportCardFeedRequest) (*CardFeedResult, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	tenantID := auth.TenantID(ctx)
+	result := &CardFeedResult{Staged: []*domain.StagedExpense{}, Unassigned: []domain.CardTransaction{}}
+	cards := make(map[string]*domain.CorporateCard)
+
+	for i := range req.Transactions {
+		tx := &req.Transactions[i]
+		if tx.Amount.Minor <= 0 {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, domain.ErrInvalidAmount)
+		}
+		if tx.MCC != "" && !domain.IsValidMCC(tx.MCC) {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, domain.ErrInvalidMCC)
+		}
+
+		// Step 1: Find the cardholder (each card is looked up once per feed)
+		card, seen := cards[tx.CardLast4]
+		if !seen {
+			var err error
+			card, err = s.cards.GetByLast4(ctx, tenantID, tx.CardLast4)
+			if err != nil && !errors.Is(err, domain.ErrCorporateCardNotFound) {
+				return nil, err
+			}
+			cards[tx.CardLast4] = card
+		}
+		if card == nil {
+			result.Unassigned = append(result.Unassigned, *tx)
+			continue
+		}
+
+		// Step 2: Skip transactions we've staged before
+		if tx.ExternalID != "" {
+			exists, err := s.staging.StagedExternalIDExists(ctx, tx.ExternalID)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				result.Skipped++
+				continue
+			}
+		}
+
+		// Step 3: Guess the category from the normalized statement text and the MCC
+		normalized, err := s.normalizer.Normalize(ctx, tx.Description)
+		if err != nil {
+			return nil, err
+		}
+		normalizedTx := tx.ImportedTransaction
+		normalizedTx.Description = normalized
+		normalizedTx.Source = domain.RuleSourceCardFeed
+		if normalizedTx.Account == "" {
+			normalizedTx.Account = tx.CardLast4
+		}
+		category, _, err := s.categorizer.Categorize(ctx, &normalizedTx)
+		if err != nil {
+			return nil, err
+		}
+
+		// Step 4: Stage it in the cardholder's queue
+		staged, err := domain.NewCardStagedExpense(card, tx, category)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, err)
+		}
+		if err := s.staging.CreateStaged(ctx, staged); err != nil {
+			return nil, err
+		}
+		result.Staged = append(result.Staged, staged)
+	}
+
+	return result, nil
+}
+
+// record writes an audit entry for a card mapping
+func (s *CardFeedService) record(ctx context.Context, action, cardID string, details any) error {
+	entry, err := domain.NewAuditEntry(auditActor(ctx), action, "corporate_card", cardID, details)
+	if err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record corporate card change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+// Package application contains the business logic and use cases
+// This file contains auto-categorization (MCC table first, then keyword rules) and its management use cases
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching domain errors
+	"fmt"     // For formatted string operations and error wrapping
+	"log"     // For reporting rule evaluations that ran out of time
+	"strings" // For comparing categories
+	"time"    // For the rule evaluation budget
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// MCCCategorizer categorizes card transactions using the MCC mapping table
+// Card networks assign the MCC, so it is the most reliable signal we have
+type MCCCategorizer struct {
+	mappings domain.MCCRepository
+}
+
+// NewMCCCategorizer creates a categorizer backed by the MCC mapping table
+func NewMCCCategorizer(mappings domain.MCCRepository) *MCCCategorizer {
+	return &MCCCategorizer{mappings: mappings}
+}
+
+// Categorize implements domain.Categorizer
+func (c *MCCCategorizer) Categorize(ctx context.Context, tx *domain.ImportedTransaction) (string, bool, error) {
+	// Transactions without a (valid) MCC are left for the next categorizer
+	if !domain.IsValidMCC(tx.MCC) {
+		return "", false, nil
+	}
+
+	mapping, err := c.mappings.Get(ctx, tx.MCC)
+	if err != nil {
+		if errors.Is(err, domain.ErrMCCMappingNotFound) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return mapping.Category, true, nil
+}
+
+// RuleBudget is how long the rules may take on one transaction
+// Every match is bounded (see domain.MaxRuleInputLength), but a user with thousands of regex
+// rules shouldn't stall an import; when the budget runs out the transaction is left uncategorized
+const RuleBudget = 50 * time.Millisecond
+
+// RuleCategorizer categorizes transactions using the user's rules
+type RuleCategorizer struct {
+	rules  domain.RuleRepository
+	mode   string
+	budget time.Duration
+}
+
+// NewRuleCategorizer creates a categorizer backed by the rules
+// mode is one of the domain.RuleMode* values (see domain.ParseRuleMode)
+func NewRuleCategorizer(rules domain.RuleRepository, mode string) *RuleCategorizer {
+	if mode == "" {
+		mode = domain.RuleModeFirstMatch
+	}
+	return &RuleCategorizer{rules: rules, mode: mode, budget: RuleBudget}
+}
+
+// Categorize implements domain.Categorizer
+func (c *RuleCategorizer) Categorize(ctx context.Context, tx *domain.ImportedTransaction) (string, bool, error) {
+	rules, err := c.rules.List(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	matched, complete := c.match(ctx, rules, tx, c.mode == domain.RuleModeFirstMatch)
+	if !complete {
+		log.Printf("categorization rules ran out of time on %q, leaving it uncategorized", tx.Description)
+		return "", false, nil
+	}
+	category, ok := pickRuleCategory(matched)
+	return category, ok, nil
+}
+
+// pickRuleCategory returns the category of the matching rules
+// In first-match mode there is at most one; in all-match mode, rules that disagree assign nothing
+func pickRuleCategory(matched []*domain.CategoryRule) (string, bool) {
+	if len(matched) == 0 {
+		return "", false
+	}
+	for _, rule := range matched[1:] {
+		if !strings.EqualFold(rule.Category, matched[0].Category) {
+			return "", false
+		}
+	}
+	return matched[0].Category, true
+}
+
+// match returns the rules matching the transaction, in priority order
+// Rules come back highest priority first, so with first set only the first match is returned
+// complete is false when the budget or the context ran out before all rules were tried
+func (c *RuleCategorizer) match(ctx context.Context, rules []*domain.CategoryRule, tx *domain.ImportedTransaction, first bool) (matched []*domain.CategoryRule, complete bool) {
+	deadline := time.Now().Add(c.budget)
+	for _, rule := range rules {
+		if ctx.Err() != nil || time.Now().After(deadline) {
+			return matched, false
+		}
+		if rule.Matches(tx) {
+			matched = append(matched, rule)
+			if first {
+				break
+			}
+		}
+	}
+	return matched, true
+}
+
+// ChargeCategorizer files interest and bank fees under their own categories using the built-in
+// phrases (domain.DefaultChargePhrases). It runs after the keyword rules, so a user's rule overrides it
+type ChargeCategorizer struct {
+	categories domain.ChargeCategories
+}
+
+// NewChargeCategorizer creates a categorizer for bank charges
+// categories names where interest and fees go; an empty name leaves that kind to the next categorizer
+func NewChargeCategorizer(categories domain.ChargeCategories) *ChargeCategorizer {
+	return &ChargeCategorizer{categories: categories}
+}
+
+// Categorize implements domain.Categorizer
+func (c *ChargeCategorizer) Categorize(_ context.Context, tx *domain.ImportedTransaction) (string, bool, error) {
+	kind, ok := domain.DetectCharge(tx)
+	if !ok {
+		return "", false, nil
+	}
+	category := c.categories.Category(kind)
+	return category, category != "", nil
+}
+
+// CategorizerChain tries several categorizers in order
+// The order is MCC table -> keyword rules -> built-in bank charges -> (future) learned models
+type CategorizerChain []domain.Categorizer
+
+// Categorize implements domain.Categorizer
+// If nobody recognizes the transaction, it falls back to domain.UncategorizedCategory
+func (chain CategorizerChain) Categorize(ctx context.Context, tx *domain.ImportedTransaction) (string, bool, error) {
+	for _, categorizer := range chain {
+		category, ok, err := categorizer.Categorize(ctx, tx)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to categorize transaction: %w", err)
+		}
+		if ok {
+			return category, true, nil
+		}
+	}
+	return domain.UncategorizedCategory, false, nil
+}
+
+// CategorizationService manages the MCC mapping table and rules through the API
+type CategorizationService struct {
+	mappings        domain.MCCRepository
+	rules           domain.RuleRepository
+	ruleCategorizer *RuleCategorizer
+}
+
+// NewCategorizationService creates a new categorization management service
+// categorizer is the rule categorizer imports use, so rule tests match the way imports do
+func NewCategorizationService(mappings domain.MCCRepository, rules domain.RuleRepository, categorizer *RuleCategorizer) *CategorizationService {
+	return &CategorizationService{
+		mappings:        mappings,
+		rules:           rules,
+		ruleCategorizer: categorizer,
+	}
+}
+
+// SetMCCMappingRequest represents the request body for PUT /mcc-mappings/{mcc}
+type SetMCCMappingRequest struct {
+	// Category is the expense category assigned to the code
+	Category string `json:"category" binding:"required"`
+}
+
+// CreateRuleRequest represents the request body for POST /rules
+type CreateRuleRequest struct {
+	// Pattern is the keyword or regex matched against description and merchant
+	// It may be left out when the rule has a condition
+	Pattern string `json:"pattern"`
+
+	// Conditions are the matcher ("contains" or "regex") and the optional amount range, account and source
+	domain.RuleConditions
+
+	// Category is assigned when the pattern matches
+	Category string `json:"category" binding:"required"`
+
+	// Priority orders rules; higher runs first
+	Priority int `json:"priority"`
+}
+
+// ListMCCMappings returns the whole MCC mapping table
+func (s *CategorizationService) ListMCCMappings(ctx context.Context) ([]*domain.MCCMapping, error) {
+	mappings, err := s.mappings.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MCC mappings: %w", err)
+	}
+	return mappings, nil
+}
+
+// SetMCCMapping creates or replaces the category of a merchant category code
+func (s *CategorizationService) SetMCCMapping(ctx context.Context, mcc string, req *SetMCCMappingRequest) (*domain.MCCMapping, error) {
+	mapping, err := domain.NewMCCMapping(mcc, req.Category)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mappings.Upsert(ctx, mapping); err != nil {
+		return nil, fmt.Errorf("failed to save MCC mapping: %w", err)
+	}
+	return mapping, nil
+}
+
+// DeleteMCCMapping removes a merchant category code from the table
+func (s *CategorizationService) DeleteMCCMapping(ctx context.Context, mcc string) error {
+	if !domain.IsValidMCC(mcc) {
+		return domain.ErrInvalidMCC
+	}
+	return s.mappings.Delete(ctx, mcc)
+}
+
+// ListRules returns the caller's rules in their current book, highest priority first
+func (s *CategorizationService) ListRules(ctx context.Context) ([]*domain.CategoryRule, error) {
+	rules, err := s.rules.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	return rules, nil
+}
+
+// CreateRule adds a rule to the caller's current book
+func (s *CategorizationService) CreateRule(ctx context.Context, req *CreateRuleRequest) (*domain.CategoryRule, error) {
+	rule, err := domain.NewConditionalCategoryRule(req.Pattern, req.Category, req.Priority, req.RuleConditions)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rules.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to save rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule removes one of the caller's rules
+func (s *CategorizationService) DeleteRule(ctx context.Context, id string) error {
+	return s.rules.Delete(ctx, id)
+}
+
+// TestRulesRequest represents the request body for POST /rules/test
+// Only what the rules look at is needed
+type TestRulesRequest struct {
+	Description string       `json:"description"`
+	Merchant    string       `json:"merchant"`
+	Amount      domain.Money `json:"amount"`
+	Account     string       `json:"account"`
+
+	// Source is the source to test as (one of the domain.RuleSource* values; default import)
+	Source string `json:"source"`
+}
+
+// RuleTestResult shows how the rules treat a transaction
+type RuleTestResult struct {
+	// Mode is the rule mode of this deployment ("first" or "all")
+	Mode string `json:"mode"`
+
+	// Matched are all rules that match, highest priority first
+	Matched []*domain.CategoryRule `json:"matched"`
+
+	// Category is what the rules assign, and Categorized whether they assign anything
+	// In all-match mode, matching rules that disagree assign nothing
+	Category    string `json:"category,omitempty"`
+	Categorized bool   `json:"categorized"`
+
+	// TimedOut is set when the rules ran out of time (see RuleBudget); imports leave such
+	// transactions uncategorized, and Matched only has the rules tried in time
+	TimedOut bool `json:"timed_out,omitempty"`
+}
+
+// TestRules runs the rules on a transaction without importing it, to check new or changed rules
+func (s *CategorizationService) TestRules(ctx context.Context, req *TestRulesRequest) (*RuleTestResult, error) {
+	tx := domain.ImportedTransaction{
+		Description: req.Description,
+		Merchant:    req.Merchant,
+		Amount:      req.Amount,
+		Account:     req.Account,
+		Source:      req.Source,
+	}
+	if tx.Source == "" {
+		tx.Source = domain.RuleSourceImport
+	}
+
+	rules, err := s.rules.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	matched, complete := s.ruleCategorizer.match(ctx, rules, &tx, false)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := &RuleTestResult{Mode: s.ruleCategorizer.mode, Matched: []*domain.CategoryRule{}, TimedOut: !complete}
+	result.Matched = append(result.Matched, matched...)
+	if !complete {
+		return result, nil
+	}
+
+	// In first-match mode only the first matching rule counts
+	if s.ruleCategorizer.mode == domain.RuleModeFirstMatch && len(matched) > 1 {
+		matched = matched[:1]
+	}
+	result.Category, result.Categorized = pickRuleCategory(matched)
+	return result, nil
+}
@@ -0,0 +1,170 @@
+// Package application contains the business logic and use cases
+// This file contains the category list use cases
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/clock"           // Time source for archiving
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For parent category IDs
+)
+
+// CategoryService handles business logic for the category list
+type CategoryService struct {
+	categories domain.CategoryRepository
+	clock      clock.Clock
+}
+
+// NewCategoryService creates a new category service
+func NewCategoryService(categories domain.CategoryRepository, clk clock.Clock) *CategoryService {
+	return &CategoryService{categories: categories, clock: clock.Or(clk)}
+}
+
+// CreateCategoryRequest represents the request body for POST /categories
+type CreateCategoryRequest struct {
+	Name string `json:"name" binding:"required"`
+
+	// VATRate is the default VAT rate in percent of expenses in the category (optional)
+	VATRate *float64 `json:"vat_rate" binding:"omitempty,gte=0,lte=100"`
+
+	// ParentID makes the new category a subcategory of an existing one (optional)
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+// UpdateCategoryRequest represents the request body for PUT /categories/{id}
+type UpdateCategoryRequest struct {
+	// VATRate replaces the category's default VAT rate; null or missing removes it
+	VATRate *float64 `json:"vat_rate" binding:"omitempty,gte=0,lte=100"`
+
+	// ParentID moves the category under another one; null or missing makes it top-level
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+// ListCategories returns the categories to pick from; archived ones only with includeArchived
+func (s *CategoryService) ListCategories(ctx context.Context, includeArchived bool) ([]*domain.Category, error) {
+	categories, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	if includeArchived {
+		return categories, nil
+	}
+	active := make([]*domain.Category, 0, len(categories))
+	for _, category := range categories {
+		if !category.Archived() {
+			active = append(active, category)
+		}
+	}
+	return active, nil
+}
+
+// CreateCategory adds a category to the list
+func (s *CategoryService) CreateCategory(ctx context.Context, req *CreateCategoryRequest) (*domain.Category, error) {
+	category, err := domain.NewCategory(req.Name, req.VATRate)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setParent(ctx, category, req.ParentID); err != nil {
+		return nil, err
+	}
+	if err := s.categories.Create(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to save category: %w", err)
+	}
+	return category, nil
+}
+
+// UpdateCategory changes the default VAT rate and the parent of a category
+// Expenses already saved keep their split; new and edited expenses use the new rate
+func (s *CategoryService) UpdateCategory(ctx context.Context, id string, req *UpdateCategoryRequest) (*domain.Category, error) {
+	category, err := s.categories.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := category.SetVATRate(req.VATRate); err != nil {
+		return nil, err
+	}
+	if err := s.setParent(ctx, category, req.ParentID); err != nil {
+		return nil, err
+	}
+	if err := s.categories.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to save category: %w", err)
+	}
+	return category, nil
+}
+
+// ArchiveCategory hides a category from pickers
+// Expenses filed under it keep its name, and reports and filters still find them;
+// its subcategories stay in use until they are archived themselves
+func (s *CategoryService) ArchiveCategory(ctx context.Context, id string) (*domain.Category, error) {
+	category, err := s.categories.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category.Archived() {
+		return category, nil
+	}
+	now := s.clock.Now().UTC()
+	category.ArchivedAt = &now
+	if err := s.categories.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to save category: %w", err)
+	}
+	return category, nil
+}
+
+// RestoreCategory offers an archived category in pickers again
+func (s *CategoryService) RestoreCategory(ctx context.Context, id string) (*domain.Category, error) {
+	category, err := s.categories.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !category.Archived() {
+		return category, nil
+	}
+	category.ArchivedAt = nil
+	if err := s.categories.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to save category: %w", err)
+	}
+	return category, nil
+}
+
+// setParent files category under parentID (nil makes it top-level) after checking the move
+// against the current category list
+func (s *CategoryService) setParent(ctx context.Context, category *domain.Category, parentID *uuid.UUID) error {
+	if parentID != nil {
+		tree, err := s.Tree(ctx)
+		if err != nil {
+			return err
+		}
+		if err := tree.CheckParent(category, parentID); err != nil {
+			return err
+		}
+	}
+	category.ParentID = parentID
+	return nil
+}
+
+// Tree returns the category list arranged by parent
+func (s *CategoryService) Tree(ctx context.Context) (*domain.CategoryTree, error) {
+	return loadCategoryTree(ctx, s.categories)
+}
+
+// loadCategoryTree loads the category list and arranges it by parent
+func loadCategoryTree(ctx context.Context, categories domain.CategoryRepository) (*domain.CategoryTree, error) {
+	list, err := categories.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	return domain.NewCategoryTree(list), nil
+}
+
+// DeleteCategory removes a category from the list
+// Its subcategories move up to its parent
+func (s *CategoryService) DeleteCategory(ctx context.Context, id string) error {
+	if err := s.categories.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	return nil
+}
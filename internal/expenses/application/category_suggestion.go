@@ -0,0 +1,56 @@
+// Package application contains the business logic and use cases
+// This file contains category suggestions, so clients can prefill the category of a new expense
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For checking the description
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// CategoryHistorySize is how many recent expenses suggestions learn from
+// Recent habits matter more than old ones, and it keeps learning fast enough to run per request
+const CategoryHistorySize = 2000
+
+// CategorySuggestionService suggests categories from the caller's own past expenses
+// The model is learned on every request: counting a few thousand expenses is quick, and
+// suggestions then follow every recategorization at once
+type CategorySuggestionService struct {
+	history    domain.CategoryHistoryRepository
+	normalizer *DescriptionNormalizer
+}
+
+// NewCategorySuggestionService creates a new category suggestion service
+// Descriptions are normalized like imported ones before they are compared (nil skips that)
+func NewCategorySuggestionService(history domain.CategoryHistoryRepository, normalizer *DescriptionNormalizer) *CategorySuggestionService {
+	return &CategorySuggestionService{history: history, normalizer: normalizer}
+}
+
+// SuggestCategory returns the categories the caller most likely means for an expense, most likely first
+// amount is 0 when it isn't known yet. Users without categorized expenses get no suggestions
+func (s *CategorySuggestionService) SuggestCategory(ctx context.Context, description string, amount float64) ([]*domain.CategorySuggestion, error) {
+	// Step 1: Check the input
+	if strings.TrimSpace(description) == "" {
+		return nil, domain.ErrInvalidDescription
+	}
+	if amount < 0 {
+		return nil, domain.ErrInvalidAmount
+	}
+	if s.normalizer != nil {
+		normalized, err := s.normalizer.Normalize(ctx, description)
+		if err != nil {
+			return nil, err
+		}
+		description = normalized
+	}
+
+	// Step 2: Learn from the caller's recent expenses and ask the model
+	history, err := s.history.CategoryHistory(ctx, CategoryHistorySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest category: %w", err)
+	}
+	model := domain.TrainCategoryModel(history)
+	return model.Suggest(description, amount, domain.MaxCategorySuggestions), nil
+}
@@ -0,0 +1,52 @@
+// Package application contains the business logic and use cases
+// This file contains comments on expenses, for discussing a charge within a household
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For parsing expense IDs
+)
+
+// CommentService adds and lists comments on expenses
+// Who may see an expense's comments is decided by the repository: its owner and their household
+type CommentService struct {
+	comments domain.CommentRepository
+}
+
+// NewCommentService creates a new comment service
+func NewCommentService(comments domain.CommentRepository) *CommentService {
+	return &CommentService{comments: comments}
+}
+
+// CreateCommentRequest represents the request body for POST /expenses/{id}/comments
+type CreateCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// AddComment adds a comment by the caller to an expense
+func (s *CommentService) AddComment(ctx context.Context, expenseID string, req *CreateCommentRequest) (*domain.ExpenseComment, error) {
+	id, err := uuid.Parse(expenseID)
+	if err != nil {
+		return nil, domain.ErrExpenseNotFound
+	}
+	comment, err := domain.NewExpenseComment(id, req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.comments.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// ListComments returns the comments on an expense, oldest first
+func (s *CommentService) ListComments(ctx context.Context, expenseID string) ([]*domain.ExpenseComment, error) {
+	id, err := uuid.Parse(expenseID)
+	if err != nil {
+		return nil, domain.ErrExpenseNotFound
+	}
+	return s.comments.List(ctx, id)
+}
@@ -0,0 +1,110 @@
+// Package application contains the business logic and use cases
+// This file contains the currency conversion lock-in applied when expenses are entered
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching fx errors
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For historical rate lookups
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/fx"              // Daily exchange rates
+)
+
+// CurrencyConverter locks the base-currency amount of an expense at entry time
+type CurrencyConverter struct {
+	baseCurrency string
+	rates        domain.ExchangeRateProvider
+}
+
+// NewCurrencyConverter creates a converter into baseCurrency using the given rate provider
+func NewCurrencyConverter(baseCurrency string, rates domain.ExchangeRateProvider) (*CurrencyConverter, error) {
+	base, err := domain.NormalizeCurrency(baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if rates == nil {
+		rates = domain.NoExchangeRates{}
+	}
+	return &CurrencyConverter{baseCurrency: base, rates: rates}, nil
+}
+
+// BaseCurrency returns the deployment's home currency
+func (c *CurrencyConverter) BaseCurrency() string {
+	return c.baseCurrency
+}
+
+// ConversionInput carries the user's currency choices for an expense
+type ConversionInput struct {
+	// Currency is the currency the expense was paid in (empty = base currency)
+	Currency string
+
+	// ExchangeRate overrides the provider's rate when set
+	ExchangeRate float64
+
+	// ConvertedAmount overrides the converted amount (e.g. to match the card statement) when set
+	ConvertedAmount domain.Money
+}
+
+// Apply sets the currency of the expense and locks its conversion into the base currency
+// Priority: explicit converted amount > explicit rate > provider rate for the expense date
+func (c *CurrencyConverter) Apply(ctx context.Context, expense *domain.Expense, input ConversionInput) error {
+	// Step 1: Work out the expense currency (defaults to the base currency)
+	currency := c.baseCurrency
+	if input.Currency != "" {
+		normalized, err := domain.NormalizeCurrency(input.Currency)
+		if err != nil {
+			return err
+		}
+		currency = normalized
+	}
+	expense.Currency = currency
+
+	// Step 2: Apply the user's overrides first - they know what their bank charged
+	switch {
+	case !input.ConvertedAmount.IsZero():
+		expense.ConversionSource = domain.ConversionAmount
+		return expense.OverrideConvertedAmount(c.baseCurrency, input.ConvertedAmount)
+	case input.ExchangeRate != 0:
+		expense.ConversionSource = domain.ConversionRate
+		return expense.LockConversion(c.baseCurrency, input.ExchangeRate)
+	case currency == c.baseCurrency:
+		expense.ConversionSource = domain.ConversionBase
+		return expense.LockConversion(c.baseCurrency, 1)
+	}
+
+	// Step 3: Otherwise ask the rate provider for the rate on the expense date
+	rate, err := c.rates.Rate(ctx, currency, c.baseCurrency, expense.Date)
+	if err != nil {
+		return fmt.Errorf("failed to convert %s to %s: %w", currency, c.baseCurrency, err)
+	}
+	expense.ConversionSource = domain.ConversionProvider
+	return expense.LockConversion(c.baseCurrency, rate)
+}
+
+// Rates returns the exchange rate provider the converter looks rates up with
+func (c *CurrencyConverter) Rates() domain.ExchangeRateProvider {
+	return c.rates
+}
+
+// FXRates is a domain.ExchangeRateProvider looking rates up with the fx package
+type FXRates struct {
+	rates *fx.Rates
+}
+
+// NewFXRates creates a rate provider over fx rates
+func NewFXRates(rates *fx.Rates) *FXRates {
+	return &FXRates{rates: rates}
+}
+
+// Rate implements domain.ExchangeRateProvider
+// Pairs and days the provider has no rate for are domain.ErrExchangeRateUnavailable, so the
+// user is asked for a rate; failing to reach the provider is an error of its own
+func (r *FXRates) Rate(ctx context.Context, from, to string, on time.Time) (float64, error) {
+	rate, err := r.rates.Rate(ctx, from, to, on)
+	if errors.Is(err, fx.ErrUnavailable) {
+		return 0, fmt.Errorf("%w (%v)", domain.ErrExchangeRateUnavailable, err)
+	}
+	return rate, err
+}
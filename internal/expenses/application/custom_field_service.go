@@ -0,0 +1,84 @@
+// Package application contains the business logic and use cases
+// This file contains the caller's custom field schema, which expense metadata is checked against
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// CustomFieldService manages the caller's custom fields
+// Values are set on expenses through their metadata; see Service.WithCustomFields
+type CustomFieldService struct {
+	fields domain.CustomFieldRepository
+}
+
+// NewCustomFieldService creates a new custom field service
+func NewCustomFieldService(fields domain.CustomFieldRepository) *CustomFieldService {
+	return &CustomFieldService{fields: fields}
+}
+
+// CreateCustomFieldRequest represents the request body for POST /custom-fields
+type CreateCustomFieldRequest struct {
+	Key   string `json:"key" binding:"required"`
+	Label string `json:"label"`
+	Type  string `json:"type" binding:"required"`
+}
+
+// UpdateCustomFieldRequest represents the request body for PUT /custom-fields/{key}
+// Only the label can change: the key and type are what stored values depend on
+type UpdateCustomFieldRequest struct {
+	Label string `json:"label" binding:"required"`
+}
+
+// CreateCustomField declares a new custom field for the caller's expenses
+func (s *CustomFieldService) CreateCustomField(ctx context.Context, req *CreateCustomFieldRequest) (*domain.CustomField, error) {
+	field, err := domain.NewCustomField(req.Key, req.Label, req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.fields.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= domain.MaxCustomFields {
+		return nil, domain.ErrTooManyCustomFields
+	}
+	for _, other := range existing {
+		if other.Key == field.Key {
+			return nil, domain.ErrCustomFieldExists
+		}
+	}
+
+	if err := s.fields.Create(ctx, field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// ListCustomFields returns the caller's custom fields by key
+func (s *CustomFieldService) ListCustomFields(ctx context.Context) ([]*domain.CustomField, error) {
+	return s.fields.List(ctx)
+}
+
+// UpdateCustomField changes the label of one of the caller's custom fields
+func (s *CustomFieldService) UpdateCustomField(ctx context.Context, key string, req *UpdateCustomFieldRequest) (*domain.CustomField, error) {
+	field, err := s.fields.GetByKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := field.Relabel(req.Label); err != nil {
+		return nil, err
+	}
+	if err := s.fields.UpdateLabel(ctx, field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// DeleteCustomField removes one of the caller's custom fields along with its values on their expenses
+func (s *CustomFieldService) DeleteCustomField(ctx context.Context, key string) error {
+	return s.fields.Delete(ctx, key)
+}
@@ -0,0 +1,287 @@
+// Package application contains the business logic and use cases
+// This file contains the dashboard: the aggregates the app shows on first load, kept warm in a cache
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For ordering the category totals
+	"sync"    // For guarding the active user list
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/auth"            // Request-scoped caller identity
+	"myexpenses/internal/cache"           // In-memory cache for computed dashboards
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// DashboardActiveWindow is how long after their last dashboard load a user's dashboard is kept warm
+const DashboardActiveWindow = 7 * 24 * time.Hour
+
+// dashboardKeyPrefix starts the cache key of every dashboard
+const dashboardKeyPrefix = "dashboard:"
+
+// DashboardService computes the dashboard aggregates and serves them from a cache
+// A dashboard is valid for one day in the user's timezone: the month summary and budget pacing
+// move on at local midnight, so the warmer recomputes them shortly after it, before the user looks
+type DashboardService struct {
+	spending domain.SpendingRepository
+	budgets  *BudgetService
+	charges  domain.ChargeCategories
+	cache    *cache.Memory
+	location *time.Location
+	clock    clock.Clock
+
+	// active holds the users whose dashboards are kept warm, by dashboardKey
+	mu     sync.Mutex
+	active map[string]*dashboardUser
+}
+
+// dashboardUser is a user whose dashboard of one of their books is kept warm
+type dashboardUser struct {
+	userID   string
+	bookID   string
+	location *time.Location
+	lastSeen time.Time
+}
+
+// NewDashboardService creates a new dashboard service
+// location is the timezone of users who never said which one they are in (nil means UTC)
+// charges names the categories imported bank charges are filed under, for the fees insight
+// Writes must be reported through InvalidateDashboards on the same cache, or dashboards go stale
+func NewDashboardService(spending domain.SpendingRepository, budgets *BudgetService, charges domain.ChargeCategories, dashboards *cache.Memory, location *time.Location, clk clock.Clock) *DashboardService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &DashboardService{
+		spending: spending,
+		budgets:  budgets,
+		charges:  charges,
+		cache:    dashboards,
+		location: location,
+		clock:    clock.Or(clk),
+		active:   make(map[string]*dashboardUser),
+	}
+}
+
+// Dashboard is what the app shows on first load
+type Dashboard struct {
+	// Date is the local day the dashboard was computed for
+	Date string `json:"date"`
+
+	// Timezone is the IANA name of the timezone Date is in
+	Timezone string `json:"timezone"`
+
+	// GeneratedAt is when the aggregates were computed
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// Month summarizes the spending of the current month
+	Month *MonthSummary `json:"month"`
+
+	// Budgets is the pacing of every budget in the current month
+	Budgets *BudgetStatusReport `json:"budgets"`
+
+	// Fees is what the bank charged this month (nil when bank charges aren't tracked)
+	Fees *FeesInsight `json:"fees,omitempty"`
+}
+
+// FeesInsight is what was paid in bank fees and interest in a month, compared with the month before
+// Money lost to charges is easy to overlook among everyday spending, so the dashboard calls it out
+type FeesInsight struct {
+	Month    string       `json:"month"`
+	Fees     domain.Money `json:"fees"`
+	Interest domain.Money `json:"interest"`
+	Total    domain.Money `json:"total"`
+
+	// PreviousTotal is what fees and interest came to the month before; Change is Total minus it
+	PreviousTotal domain.Money `json:"previous_total"`
+	Change        domain.Money `json:"change"`
+}
+
+// MonthSummary is the spending of one month, in total and per category
+type MonthSummary struct {
+	Month      string                     `json:"month"`
+	Total      domain.Money               `json:"total"`
+	Categories []*domain.CategorySpending `json:"categories"`
+}
+
+// InvalidateDashboards drops every cached dashboard in dashboards
+// Expenses and budgets aren't owned by individual users yet, so any write changes every dashboard
+func InvalidateDashboards(dashboards *cache.Memory) {
+	dashboards.DeletePrefix(dashboardKeyPrefix)
+}
+
+// Dashboard returns the caller's dashboard of their current book and whether it came from the cache
+// timezone is an IANA name (e.g. "Europe/Berlin"); empty uses the one the caller sent last time
+func (s *DashboardService) Dashboard(ctx context.Context, timezone string) (*Dashboard, bool, error) {
+	key := dashboardKey(auth.UserID(ctx), auth.BookID(ctx))
+	location, err := s.touch(ctx, key, timezone)
+	if err != nil {
+		return nil, false, err
+	}
+
+	today := s.clock.Now().In(location).Format("2006-01-02")
+	if cached, ok := s.cache.Get(key); ok {
+		dashboard := cached.(*Dashboard)
+		if dashboard.Date == today && dashboard.Timezone == location.String() {
+			return dashboard, true, nil
+		}
+	}
+
+	dashboard, err := s.compute(ctx, key, location)
+	if err != nil {
+		return nil, false, err
+	}
+	return dashboard, false, nil
+}
+
+// WarmActive recomputes the dashboards of active users whose local day has moved on
+// It is meant to run every few minutes; each user is recomputed at most once per local day,
+// shortly after their midnight. It returns how many dashboards were computed
+func (s *DashboardService) WarmActive(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+
+	// Step 1: Take a snapshot of the active users, forgetting the ones who stopped coming
+	s.mu.Lock()
+	users := make(map[string]dashboardUser, len(s.active))
+	for key, user := range s.active {
+		if now.Sub(user.lastSeen) > DashboardActiveWindow {
+			delete(s.active, key)
+			continue
+		}
+		users[key] = *user
+	}
+	s.mu.Unlock()
+
+	// Step 2: Recompute the dashboards that are missing or from an earlier day
+	warmed := 0
+	for key, user := range users {
+		if err := ctx.Err(); err != nil {
+			return warmed, err
+		}
+		location := user.location
+		if cached, ok := s.cache.Get(key); ok {
+			dashboard := cached.(*Dashboard)
+			if dashboard.Date == now.In(location).Format("2006-01-02") && dashboard.Timezone == location.String() {
+				continue
+			}
+		}
+
+		// The computation runs on behalf of the user in the same book, like their own request would
+		userCtx := auth.WithPrincipal(ctx, auth.Principal{UserID: user.userID, BookID: user.bookID, Roles: []auth.Role{auth.RoleMember}})
+		if _, err := s.compute(userCtx, key, location); err != nil {
+			return warmed, fmt.Errorf("failed to warm dashboard: %w", err)
+		}
+		warmed++
+	}
+	return warmed, nil
+}
+
+// touch records that the caller loaded the dashboard stored under key and returns the timezone to use for them
+func (s *DashboardService) touch(ctx context.Context, key, timezone string) (*time.Location, error) {
+	var location *time.Location
+	if timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, domain.ErrInvalidTimezone
+		}
+		location = loaded
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.active[key]
+	if !ok {
+		user = &dashboardUser{userID: auth.UserID(ctx), bookID: auth.BookID(ctx), location: s.location}
+		s.active[key] = user
+	}
+	if location != nil {
+		user.location = location
+	}
+	user.lastSeen = s.clock.Now()
+	return user.location, nil
+}
+
+// compute builds the dashboard for the local day of location and caches it under key
+func (s *DashboardService) compute(ctx context.Context, key string, location *time.Location) (*Dashboard, error) {
+	now := s.clock.Now()
+	local := now.In(location)
+	month := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// Step 1: The month summary
+	rows, err := s.spending.SpendingByCategory(ctx, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spending: %w", err)
+	}
+	summary := &MonthSummary{Month: month.Format("2006-01"), Categories: rows}
+	for _, row := range rows {
+		summary.Total = summary.Total.Add(row.Amount)
+	}
+	sort.SliceStable(summary.Categories, func(i, j int) bool {
+		return summary.Categories[i].Amount.Minor > summary.Categories[j].Amount.Minor
+	})
+
+	// Step 2: The budget status of the same month
+	status, err := s.budgets.Status(ctx, summary.Month)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3: The fees paid this month against last month
+	fees, err := s.feesInsight(ctx, month, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &Dashboard{
+		Date:        local.Format("2006-01-02"),
+		Timezone:    location.String(),
+		GeneratedAt: now,
+		Month:       summary,
+		Budgets:     status,
+		Fees:        fees,
+	}
+	// Entries of users who stop coming are dropped once they would no longer be warmed
+	s.cache.Set(key, dashboard, DashboardActiveWindow)
+	return dashboard, nil
+}
+
+// feesInsight sums the bank charge categories of month (from its category totals) and of the month before
+// It returns nil when neither fees nor interest are tracked
+func (s *DashboardService) feesInsight(ctx context.Context, month time.Time, rows []*domain.CategorySpending) (*FeesInsight, error) {
+	if s.charges.Fees == "" && s.charges.Interest == "" {
+		return nil, nil
+	}
+	previous, err := s.spending.SpendingByCategory(ctx, month.AddDate(0, -1, 0), month)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load last month's spending: %w", err)
+	}
+
+	insight := &FeesInsight{Month: month.Format("2006-01")}
+	for _, row := range rows {
+		switch {
+		case row.Category == "":
+		case row.Category == s.charges.Fees:
+			insight.Fees = insight.Fees.Add(row.Amount)
+		case row.Category == s.charges.Interest:
+			insight.Interest = insight.Interest.Add(row.Amount)
+		}
+	}
+	for _, row := range previous {
+		if row.Category != "" && (row.Category == s.charges.Fees || row.Category == s.charges.Interest) {
+			insight.PreviousTotal = insight.PreviousTotal.Add(row.Amount)
+		}
+	}
+	insight.Total = insight.Fees.Add(insight.Interest)
+	insight.Change = insight.Total.Sub(insight.PreviousTotal)
+	return insight, nil
+}
+
+// dashboardKey is the cache key of a user's dashboard of a book ("" for the default book)
+// Each book has its own expenses and budgets, so each has its own dashboard
+func dashboardKey(userID, bookID string) string {
+	if bookID == "" {
+		return dashboardKeyPrefix + userID
+	}
+	return dashboardKeyPrefix + userID + ":" + bookID
+}
@@ -0,0 +1,90 @@
+// Package application contains the business logic and use cases
+// This file contains the sample data new tenants explore the app with, and wiping it before real use
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// DemoDataService generates and wipes the sample data
+// Sample expenses are flagged as demo (their "demo" field is true), so clients can mark them and
+// wiping never touches anything a user entered
+type DemoDataService struct {
+	expenses   domain.Repository
+	demo       domain.DemoDataRepository
+	transactor domain.Transactor
+	clock      clock.Clock
+
+	// invalidate drops cached reports and dashboards after a wipe, which bypasses the caches
+	invalidate func(ctx context.Context)
+}
+
+// NewDemoDataService creates a new demo data service
+func NewDemoDataService(expenses domain.Repository, demo domain.DemoDataRepository, transactor domain.Transactor, invalidate func(ctx context.Context), clk clock.Clock) *DemoDataService {
+	return &DemoDataService{
+		expenses:   expenses,
+		demo:       demo,
+		transactor: transactor,
+		invalidate: invalidate,
+		clock:      clock.Or(clk),
+	}
+}
+
+// DemoDataStatus reports how much sample data there is
+type DemoDataStatus struct {
+	Expenses int64 `json:"expenses"`
+}
+
+// Status returns how much sample data there is
+func (s *DemoDataService) Status(ctx context.Context) (*DemoDataStatus, error) {
+	count, err := s.demo.CountDemoExpenses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &DemoDataStatus{Expenses: count}, nil
+}
+
+// Generate adds DemoMonths of sample expenses to the caller's current book, with categories
+// in the locale's language. It refuses while there is sample data, so it is never doubled
+func (s *DemoDataService) Generate(ctx context.Context, locale string) (*DemoDataStatus, error) {
+	existing, err := s.demo.CountDemoExpenses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, domain.ErrDemoDataExists
+	}
+
+	expenses, err := domain.DemoExpenses(domain.NormalizeLocale(locale), s.clock.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, expense := range expenses {
+			if err := s.expenses.Create(ctx, expense); err != nil {
+				return fmt.Errorf("failed to create demo expense: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &DemoDataStatus{Expenses: int64(len(expenses))}, nil
+}
+
+// Wipe removes all sample data, of every user, and returns how many expenses went
+func (s *DemoDataService) Wipe(ctx context.Context) (*DemoDataStatus, error) {
+	deleted, err := s.demo.DeleteDemoExpenses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.invalidate != nil {
+		s.invalidate(ctx)
+	}
+	return &DemoDataStatus{Expenses: deleted}, nil
+}
@@ -0,0 +1,220 @@
+// Package application contains the business logic and use cases
+// This file contains cost centers and departments: admins manage them per organization,
+// expenses are charged to them, and the chargeback report bills the spending back to them
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching repository errors
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/auth"            // The caller's permissions and tenant
+	"myexpenses/internal/clock"           // Time source for archiving
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/report"          // Shared report renderers
+)
+
+// Audit actions recorded for cost centers and departments
+const (
+	// AuditDimensionCreated is recorded when an admin adds a cost center or department
+	AuditDimensionCreated = "dimension.created"
+
+	// AuditDimensionArchived is recorded when an admin retires a cost center or department
+	AuditDimensionArchived = "dimension.archived"
+)
+
+// DimensionService manages the organization's cost centers and departments
+type DimensionService struct {
+	dimensions domain.DimensionRepository
+	audit      domain.AuditRepository
+	clock      clock.Clock
+}
+
+// NewDimensionService creates a new dimension service
+func NewDimensionService(dimensions domain.DimensionRepository, audit domain.AuditRepository, clk clock.Clock) *DimensionService {
+	return &DimensionService{
+		dimensions: dimensions,
+		audit:      audit,
+		clock:      clock.Or(clk),
+	}
+}
+
+// CreateDimensionRequest represents the request body for POST /admin/dimensions
+type CreateDimensionRequest struct {
+	Kind string `json:"kind" binding:"required"`
+	Code string `json:"code" binding:"required"`
+	Name string `json:"name" binding:"required"`
+}
+
+// ListDimensions returns the caller's organization's cost centers and departments
+// kind limits the list to one kind (empty for both); archived ones are only listed on request
+func (s *DimensionService) ListDimensions(ctx context.Context, kind string, includeArchived bool) ([]*domain.Dimension, error) {
+	if kind != "" && !domain.IsValidDimensionKind(kind) {
+		return nil, domain.ErrInvalidDimension
+	}
+	return s.dimensions.List(ctx, auth.TenantID(ctx), kind, includeArchived)
+}
+
+// CreateDimension adds a cost center or department
+func (s *DimensionService) CreateDimension(ctx context.Context, req *CreateDimensionRequest) (*domain.Dimension, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	dimension, err := domain.NewDimension(req.Kind, req.Code, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	dimension.TenantID = auth.TenantID(ctx)
+	if err := s.dimensions.Create(ctx, dimension); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, AuditDimensionCreated, dimension.ID.String(), dimension); err != nil {
+		return nil, err
+	}
+	return dimension, nil
+}
+
+// ArchiveDimension retires a cost center or department
+// New expenses can't be charged to it; the expenses charged to it keep it
+func (s *DimensionService) ArchiveDimension(ctx context.Context, id string) error {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return domain.ErrForbidden
+	}
+	if err := s.dimensions.Archive(ctx, auth.TenantID(ctx), id, s.clock.Now().UTC()); err != nil {
+		return err
+	}
+	return s.record(ctx, AuditDimensionArchived, id, nil)
+}
+
+// Resolve returns the stored form of a code an expense is charged to (empty for none)
+// It fails with domain.ErrUnknownDimension when the organization has no such active cost center or department
+func (s *DimensionService) Resolve(ctx context.Context, kind, code string) (string, error) {
+	code = domain.NormalizeDimensionCode(code)
+	if code == "" {
+		return "", nil
+	}
+	dimension, err := s.dimensions.GetByCode(ctx, auth.TenantID(ctx), kind, code)
+	if errors.Is(err, domain.ErrDimensionNotFound) {
+		return "", domain.ErrUnknownDimension
+	}
+	if err != nil {
+		return "", err
+	}
+	if dimension.Archived() {
+		return "", domain.ErrUnknownDimension
+	}
+	return dimension.Code, nil
+}
+
+// ChargebackLine is the spending charged to one cost center or department, with its categories
+type ChargebackLine struct {
+	Code       string                   `json:"code"`
+	Name       string                   `json:"name"`
+	Count      int64                    `json:"count"`
+	Amount     domain.Money             `json:"amount"`
+	Categories []*domain.DimensionTotal `json:"categories"`
+}
+
+// ChargebackReport sums a period's spending per cost center or department, so it can be billed back
+// Amounts are in the home currency; expenses not charged to any are left out
+type ChargebackReport struct {
+	Period domain.Period `json:"period"`
+
+	// Kind is the dimension the report is grouped by
+	Kind string `json:"kind"`
+
+	Total domain.Money      `json:"total"`
+	Lines []*ChargebackLine `json:"lines"`
+}
+
+// Chargeback builds the chargeback report of a period grouped by kind (admin only)
+func (s *DimensionService) Chargeback(ctx context.Context, period, kind string) (*ChargebackReport, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	if !domain.IsValidDimensionKind(kind) {
+		return nil, domain.ErrInvalidDimension
+	}
+	p, err := domain.ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.dimensions.Totals(ctx, auth.TenantID(ctx), kind, p.Start, p.End)
+	if err != nil {
+		return nil, err
+	}
+
+	// Totals come ordered by code, so each dimension's categories are adjacent
+	result := &ChargebackReport{Period: p, Kind: kind, Lines: []*ChargebackLine{}}
+	var line *ChargebackLine
+	for _, total := range totals {
+		if line == nil || line.Code != total.Code {
+			line = &ChargebackLine{Code: total.Code, Name: total.Name}
+			result.Lines = append(result.Lines, line)
+		}
+		line.Count += total.Count
+		line.Amount = line.Amount.Add(total.Amount)
+		line.Categories = append(line.Categories, total)
+		result.Total = result.Total.Add(total.Amount)
+	}
+	return result, nil
+}
+
+// Document converts the chargeback report into a renderable document
+func (r *ChargebackReport) Document() *report.Document {
+	title := "Cost center"
+	if r.Kind == domain.DimensionDepartment {
+		title = "Department"
+	}
+	var lines, categories []report.Row
+	for _, line := range r.Lines {
+		lines = append(lines, report.Row{line.Code, line.Name, line.Count, line.Amount})
+		for _, total := range line.Categories {
+			categories = append(categories, report.Row{line.Code, total.Category, total.Count, total.Amount})
+		}
+	}
+	return &report.Document{
+		Title: "Chargeback by " + title + " " + r.Period.Label,
+		Summary: []report.Field{
+			{Key: "period", Label: "Period", Kind: report.KindText, Value: r.Period.Label},
+			{Key: "kind", Label: "Grouped by", Kind: report.KindText, Value: r.Kind},
+			{Key: "total", Label: "Total", Kind: report.KindAmount, Value: r.Total},
+		},
+		Sections: []*report.Section{
+			{
+				Key:   "lines",
+				Title: title + "s",
+				Columns: []report.Column{
+					{Key: "code", Title: "Code", Kind: report.KindText},
+					{Key: "name", Title: "Name", Kind: report.KindText},
+					{Key: "count", Title: "Expenses", Kind: report.KindNumber},
+					{Key: "amount", Title: "Amount", Kind: report.KindAmount},
+				},
+				Rows: report.SliceRows(lines),
+			},
+			{
+				Key:   "categories",
+				Title: "By category",
+				Columns: []report.Column{
+					{Key: "code", Title: "Code", Kind: report.KindText},
+					{Key: "category", Title: "Category", Kind: report.KindText},
+					{Key: "count", Title: "Expenses", Kind: report.KindNumber},
+					{Key: "amount", Title: "Amount", Kind: report.KindAmount},
+				},
+				Rows: report.SliceRows(categories),
+			},
+		},
+	}
+}
+
+// record writes an audit entry for a cost center or department
+func (s *DimensionService) record(ctx context.Context, action, id string, details any) error {
+	entry, err := domain.NewAuditEntry(auditActor(ctx), action, "dimension", id, details)
+	if err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record dimension change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+// Package application contains the business logic and use cases
+// This file contains donations: marking expenses as charitable gifts and the annual giving report
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For ordering recipients
+	"time"    // For the report year
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/report"          // Renderable report documents
+)
+
+// DonationService marks expenses as donations and reports the year's giving
+type DonationService struct {
+	donations domain.DonationRepository
+	expenses  domain.Repository
+
+	// receiptThreshold is the amount from which a donation needs a receipt on file
+	receiptThreshold domain.Money
+}
+
+// NewDonationService creates a new donation service
+// Donations of receiptThreshold or more (in the base currency) without an attachment are
+// reported as missing their receipt; 0 expects a receipt for every donation
+func NewDonationService(donations domain.DonationRepository, expenses domain.Repository, receiptThreshold domain.Money) *DonationService {
+	return &DonationService{donations: donations, expenses: expenses, receiptThreshold: receiptThreshold}
+}
+
+// DonationRequest represents the request body for PUT /expenses/{id}/donation
+type DonationRequest struct {
+	RecipientName    string `json:"recipient_name" binding:"required"`
+	RecipientTaxID   string `json:"recipient_tax_id"`
+	RecipientAddress string `json:"recipient_address"`
+	ReceiptNumber    string `json:"receipt_number"`
+}
+
+// MarkDonation marks one of the caller's expenses as a donation (marking it again replaces the details)
+func (s *DonationService) MarkDonation(ctx context.Context, expenseID string, req *DonationRequest) (*domain.Donation, error) {
+	expense, err := s.expenses.GetByID(ctx, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense: %w", err)
+	}
+	donation, err := domain.NewDonation(expense.ID, req.RecipientName, req.RecipientTaxID, req.RecipientAddress, req.ReceiptNumber)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.donations.Save(ctx, donation); err != nil {
+		return nil, err
+	}
+	return donation, nil
+}
+
+// GetDonation returns the donation details of one of the caller's expenses
+func (s *DonationService) GetDonation(ctx context.Context, expenseID string) (*domain.Donation, error) {
+	expense, err := s.expenses.GetByID(ctx, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense: %w", err)
+	}
+	return s.donations.Get(ctx, expense.ID)
+}
+
+// UnmarkDonation makes one of the caller's expenses an ordinary expense again
+func (s *DonationService) UnmarkDonation(ctx context.Context, expenseID string) error {
+	expense, err := s.expenses.GetByID(ctx, expenseID)
+	if err != nil {
+		return fmt.Errorf("failed to get expense: %w", err)
+	}
+	return s.donations.Delete(ctx, expense.ID)
+}
+
+// GivingDonation is one line of the giving report
+type GivingDonation struct {
+	*domain.GivingLine
+
+	// ReceiptMissing is set when the donation needs a receipt and has no attachment
+	ReceiptMissing bool `json:"receipt_missing"`
+}
+
+// RecipientGiving sums the donations to one recipient
+type RecipientGiving struct {
+	RecipientName  string       `json:"recipient_name"`
+	RecipientTaxID string       `json:"recipient_tax_id,omitempty"`
+	Count          int          `json:"count"`
+	Amount         domain.Money `json:"amount"`
+}
+
+// GivingReport lists a year's donations for a tax return, with totals per recipient
+// Amounts are in the base currency
+type GivingReport struct {
+	Year       int                `json:"year"`
+	Donations  []*GivingDonation  `json:"donations"`
+	Recipients []*RecipientGiving `json:"recipients"`
+
+	Total domain.Money `json:"total"`
+	Count int          `json:"count"`
+
+	// ReceiptThreshold is the amount from which a receipt is expected
+	ReceiptThreshold domain.Money `json:"receipt_threshold"`
+
+	// MissingReceipts counts the donations that need a receipt and have none attached
+	MissingReceipts int `json:"missing_receipts"`
+}
+
+// GivingReport builds the giving report of a calendar year ("2025")
+func (s *DonationService) GivingReport(ctx context.Context, year string) (*GivingReport, error) {
+	// Step 1: The year's donations
+	start, err := time.Parse("2006", year)
+	if err != nil {
+		return nil, domain.ErrInvalidPeriod
+	}
+	lines, err := s.donations.ListGiving(ctx, start, start.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Check the receipts and sum per recipient
+	// Recipients are told apart by tax ID when they have one, so a renamed charity stays one line
+	result := &GivingReport{Year: start.Year(), Donations: []*GivingDonation{}, Recipients: []*RecipientGiving{}, ReceiptThreshold: s.receiptThreshold}
+	byRecipient := map[string]*RecipientGiving{}
+	for _, line := range lines {
+		donation := &GivingDonation{GivingLine: line}
+		if line.Attachments == 0 && line.Amount.Minor >= s.receiptThreshold.Minor {
+			donation.ReceiptMissing = true
+			result.MissingReceipts++
+		}
+		result.Donations = append(result.Donations, donation)
+		result.Total = result.Total.Add(line.Amount)
+		result.Count++
+
+		key := "name:" + line.RecipientName
+		if line.RecipientTaxID != "" {
+			key = "tax:" + line.RecipientTaxID
+		}
+		recipient := byRecipient[key]
+		if recipient == nil {
+			recipient = &RecipientGiving{RecipientName: line.RecipientName, RecipientTaxID: line.RecipientTaxID}
+			byRecipient[key] = recipient
+			result.Recipients = append(result.Recipients, recipient)
+		}
+		recipient.Count++
+		recipient.Amount = recipient.Amount.Add(line.Amount)
+	}
+
+	// Step 3: Largest recipients first
+	sort.SliceStable(result.Recipients, func(i, j int) bool {
+		return result.Recipients[i].Amount.Minor > result.Recipients[j].Amount.Minor
+	})
+	return result, nil
+}
+
+// Document converts the giving report into a renderable document
+// The donations section has the columns a tax return's schedule of gifts asks for
+func (r *GivingReport) Document() *report.Document {
+	recipients := make([]report.Row, len(r.Recipients))
+	for i, recipient := range r.Recipients {
+		recipients[i] = report.Row{recipient.RecipientName, recipient.RecipientTaxID, recipient.Count, recipient.Amount}
+	}
+	donations := make([]report.Row, len(r.Donations))
+	for i, d := range r.Donations {
+		receipt := "attached"
+		switch {
+		case d.ReceiptMissing:
+			receipt = "missing"
+		case d.Attachments == 0:
+			receipt = "not required"
+		}
+		donations[i] = report.Row{d.Date, d.RecipientName, d.RecipientTaxID, d.RecipientAddress, d.Description, d.ReceiptNumber, receipt, d.Amount}
+	}
+	return &report.Document{
+		Title: fmt.Sprintf("Charitable giving %d", r.Year),
+		Summary: []report.Field{
+			{Key: "year", Label: "Tax year", Kind: report.KindText, Value: fmt.Sprint(r.Year)},
+			{Key: "total", Label: "Total donated", Kind: report.KindAmount, Value: r.Total},
+			{Key: "count", Label: "Donations", Kind: report.KindNumber, Value: r.Count},
+			{Key: "missing_receipts", Label: "Missing receipts", Kind: report.KindNumber, Value: r.MissingReceipts},
+		},
+		Sections: []*report.Section{
+			{
+				Key:   "recipients",
+				Title: "By recipient",
+				Columns: []report.Column{
+					{Key: "recipient_name", Title: "Recipient", Kind: report.KindText},
+					{Key: "recipient_tax_id", Title: "Tax ID", Kind: report.KindText},
+					{Key: "count", Title: "Donations", Kind: report.KindNumber},
+					{Key: "amount", Title: "Amount", Kind: report.KindAmount},
+				},
+				Rows: report.SliceRows(recipients),
+			},
+			{
+				Key:   "donations",
+				Title: "Donations",
+				Columns: []report.Column{
+					{Key: "date", Title: "Date", Kind: report.KindDate},
+					{Key: "recipient_name", Title: "Recipient", Kind: report.KindText},
+					{Key: "recipient_tax_id", Title: "Tax ID", Kind: report.KindText},
+					{Key: "recipient_address", Title: "Address", Kind: report.KindText},
+					{Key: "description", Title: "Description", Kind: report.KindText},
+					{Key: "receipt_number", Title: "Receipt no.", Kind: report.KindText},
+					{Key: "receipt", Title: "Receipt", Kind: report.KindText},
+					{Key: "amount", Title: "Amount", Kind: report.KindAmount},
+				},
+				Rows: report.SliceRows(donations),
+			},
+		},
+	}
+}
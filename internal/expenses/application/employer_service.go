@@ -0,0 +1,122 @@
+// Package application contains the business logic and use cases
+// This file contains employers: who pays back reimbursable expenses, and what they still owe
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For the day a reimbursement arrived
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// EmployerService keeps track of what employers owe the user for reimbursable expenses
+// The balance of an employer goes up with every reimbursable expense charged to it and
+// down with every reimbursement received from it
+type EmployerService struct {
+	employers domain.EmployerRepository
+}
+
+// NewEmployerService creates a new employer service
+func NewEmployerService(employers domain.EmployerRepository) *EmployerService {
+	return &EmployerService{employers: employers}
+}
+
+// CreateEmployerRequest represents the request body for POST /employers
+type CreateEmployerRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// RecordReimbursementRequest represents the request body for POST /employers/{id}/reimbursements
+type RecordReimbursementRequest struct {
+	// Amount is what was received, in the base currency
+	// It must be greater than 0; NewReimbursementIncome rejects it otherwise
+	Amount domain.Money `json:"amount"`
+
+	// ReceivedOn is the day the money arrived
+	ReceivedOn time.Time `json:"received_on" binding:"required"`
+
+	// Reference identifies the payment (optional)
+	Reference string `json:"reference"`
+}
+
+// CreateEmployer adds an employer reimbursable expenses can be charged to
+func (s *EmployerService) CreateEmployer(ctx context.Context, req *CreateEmployerRequest) (*domain.Employer, error) {
+	employer, err := domain.NewEmployer(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.employers.Create(ctx, employer); err != nil {
+		return nil, err
+	}
+	return employer, nil
+}
+
+// ListEmployers returns the caller's employers
+func (s *EmployerService) ListEmployers(ctx context.Context) ([]*domain.Employer, error) {
+	return s.employers.List(ctx)
+}
+
+// DeleteEmployer removes one of the caller's employers that nothing is charged to any more
+func (s *EmployerService) DeleteEmployer(ctx context.Context, id string) error {
+	return s.employers.Delete(ctx, id)
+}
+
+// Balances returns what each of the caller's employers still owes
+func (s *EmployerService) Balances(ctx context.Context) ([]*domain.EmployerBalance, error) {
+	return s.employers.Balances(ctx)
+}
+
+// Balance returns what one of the caller's employers still owes
+func (s *EmployerService) Balance(ctx context.Context, id string) (*domain.EmployerBalance, error) {
+	employer, err := s.employers.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := s.employers.Balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, balance := range balances {
+		if balance.Employer.ID == employer.ID {
+			return balance, nil
+		}
+	}
+	// Deleted between the two reads
+	return nil, domain.ErrEmployerNotFound
+}
+
+// RecordReimbursement records money received from one of the caller's employers,
+// which brings down its outstanding balance
+func (s *EmployerService) RecordReimbursement(ctx context.Context, employerID string, req *RecordReimbursementRequest) (*domain.ReimbursementIncome, error) {
+	employer, err := s.employers.GetByID(ctx, employerID)
+	if err != nil {
+		return nil, err
+	}
+	income, err := domain.NewReimbursementIncome(employer.ID, req.Amount, req.ReceivedOn, req.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.employers.CreateIncome(ctx, income); err != nil {
+		return nil, fmt.Errorf("failed to save reimbursement: %w", err)
+	}
+	return income, nil
+}
+
+// ListReimbursements returns the reimbursements received from one of the caller's employers
+func (s *EmployerService) ListReimbursements(ctx context.Context, employerID string) ([]*domain.ReimbursementIncome, error) {
+	employer, err := s.employers.GetByID(ctx, employerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.employers.ListIncomes(ctx, employer.ID)
+}
+
+// DeleteReimbursement removes a reimbursement recorded by mistake, which raises the balance again
+func (s *EmployerService) DeleteReimbursement(ctx context.Context, employerID, id string) error {
+	employer, err := s.employers.GetByID(ctx, employerID)
+	if err != nil {
+		return err
+	}
+	return s.employers.DeleteIncome(ctx, employer.ID, id)
+}
@@ -0,0 +1,177 @@
+// Package application contains the business logic and use cases
+// This file contains account erasure: users ask for their account to be deleted, can change
+// their mind during a grace period, and are then erased by a background job
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For tolerating files that are already gone
+	"fmt"     // For formatted string operations and error wrapping
+	"log"     // For reporting files that couldn't be deleted
+	"time"    // For the grace period
+
+	"myexpenses/internal/auth"            // The caller
+	"myexpenses/internal/clock"           // Time source for scheduling and purging
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/storage"         // Blob storage for attachments and exports
+)
+
+// Audit actions recorded for account erasure
+const (
+	// AuditUserErasureScheduled is recorded when a user asks for their account to be erased
+	AuditUserErasureScheduled = "user.erasure_scheduled"
+
+	// AuditUserErasureCancelled is recorded when a user keeps their account after all
+	AuditUserErasureCancelled = "user.erasure_cancelled"
+
+	// AuditUserErased is recorded once an account has been erased; it doesn't name the user
+	AuditUserErased = "user.erased"
+)
+
+// erasureSystemActor is the audit actor of the purge job, which runs without a caller
+const erasureSystemActor = "system"
+
+// ErasureService schedules, cancels and carries out account erasures
+type ErasureService struct {
+	users         domain.UserRepository
+	refreshTokens domain.RefreshTokenRepository
+	erasures      domain.ErasureRepository
+	storage       storage.Storage
+	content       *attachmentContent
+	audit         domain.AuditRepository
+	clock         clock.Clock
+	grace         time.Duration
+}
+
+// NewErasureService creates a new erasure service
+// grace is how long users can cancel an erasure (<= 0 uses domain.DefaultErasureGracePeriod)
+// blobs and store let the purge delete attachment and export files along with the rows
+func NewErasureService(users domain.UserRepository, refreshTokens domain.RefreshTokenRepository, erasures domain.ErasureRepository, blobs domain.AttachmentBlobRepository, store storage.Storage, audit domain.AuditRepository, clk clock.Clock, grace time.Duration) *ErasureService {
+	if grace <= 0 {
+		grace = domain.DefaultErasureGracePeriod
+	}
+	return &ErasureService{
+		users:         users,
+		refreshTokens: refreshTokens,
+		erasures:      erasures,
+		storage:       store,
+		content:       &attachmentContent{blobs: blobs, storage: store},
+		audit:         audit,
+		clock:         clock.Or(clk),
+		grace:         grace,
+	}
+}
+
+// ScheduleErasure schedules the erasure of the caller's account at the end of the grace period
+// Every session is logged out; logging in again before the erasure lets the user cancel it
+// Asking again keeps the date already set, so the grace period can't be pushed back by accident
+func (s *ErasureService) ScheduleErasure(ctx context.Context) (*domain.User, error) {
+	// Step 1: Only the account holder may ask, and not with a read-only API key
+	user, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user.ErasurePending() {
+		return user, nil
+	}
+
+	// Step 2: Set the date and log the user out everywhere
+	now := s.clock.Now().UTC()
+	erasesAt := now.Add(s.grace)
+	user.ErasesAt = &erasesAt
+	if err := s.users.UpdateErasure(ctx, user); err != nil {
+		return nil, err
+	}
+	if _, err := s.refreshTokens.RevokeAllForUser(ctx, user.ID, now); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, auditActor(ctx), AuditUserErasureScheduled, user.ID.String(), map[string]any{"erases_at": erasesAt}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CancelErasure keeps the caller's account, or returns ErrErasureNotScheduled when nothing was scheduled
+func (s *ErasureService) CancelErasure(ctx context.Context) (*domain.User, error) {
+	user, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !user.ErasurePending() {
+		return nil, domain.ErrErasureNotScheduled
+	}
+	user.ErasesAt = nil
+	if err := s.users.UpdateErasure(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, auditActor(ctx), AuditUserErasureCancelled, user.ID.String(), nil); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// PurgeDue erases every account whose grace period is over and returns how many there were
+// Each account is erased in its own transaction; file content is deleted afterwards, so a file
+// that fails to go is only logged and left for the integrity checker to find
+func (s *ErasureService) PurgeDue(ctx context.Context) (int, error) {
+	due, err := s.erasures.ListDue(ctx, s.clock.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	erased := 0
+	for _, user := range due {
+		// Step 1: Remove the rows and anonymize the audit log
+		erasure, err := s.erasures.Erase(ctx, user)
+		if err != nil {
+			return erased, err
+		}
+		erased++
+
+		// Step 2: Release the files nothing points at any more
+		for _, attachment := range erasure.Attachments {
+			if err := s.content.release(ctx, attachment); err != nil {
+				log.Printf("failed to release attachment content %s: %v", attachment.StorageKey, err)
+			}
+		}
+		for _, key := range erasure.ExportFiles {
+			if err := s.storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+				log.Printf("failed to delete export file %s: %v", key, err)
+			}
+		}
+		for _, key := range erasure.PendingReceiptFiles {
+			if err := s.storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+				log.Printf("failed to delete receipt content %s: %v", key, err)
+			}
+		}
+
+		// Step 3: Record that an account was erased, without saying whose
+		if err := s.record(ctx, erasureSystemActor, AuditUserErased, domain.ErasedUser, erasure); err != nil {
+			return erased, err
+		}
+	}
+	return erased, nil
+}
+
+// caller returns the logged-in user, refusing read-only API keys
+func (s *ErasureService) caller(ctx context.Context) (*domain.User, error) {
+	principal, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if principal.ReadOnly {
+		return nil, domain.ErrForbidden
+	}
+	return s.users.GetByID(ctx, principal.UserID)
+}
+
+// record writes an audit entry about an erasure
+func (s *ErasureService) record(ctx context.Context, actor, action, userID string, details any) error {
+	entry, err := domain.NewAuditEntry(actor, action, "user", userID, details)
+	if err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record erasure: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,376 @@
+// Package application contains the business logic and use cases
+// This file contains expense exports: small ones are written straight into the response,
+// large ones run as background jobs whose finished file is downloaded later
+package application
+
+import (
+	"context"       // For request context (cancellation, timeouts)
+	"encoding/csv"  // For correctly quoted CSV output
+	"encoding/json" // For storing the filters of a job
+	"errors"        // For matching queue errors
+	"fmt"           // For formatted string operations and error wrapping
+	"io"            // For streaming the export into storage
+	"strings"       // For input normalization
+	"time"          // For handling dates and times
+
+	"myexpenses/internal/auth"            // The caller an export belongs to
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/queue"           // Background task workers
+	"myexpenses/internal/storage"         // Where finished exports are kept
+)
+
+// Export settings
+const (
+	// DefaultExportAsyncThreshold is how many rows an export may have before it runs as a job
+	DefaultExportAsyncThreshold = 10000
+
+	// ExportRetention is how long a finished export file is kept
+	ExportRetention = 24 * time.Hour
+
+	// exportProgressEvery is how many rows are written between two progress updates
+	exportProgressEvery = 5000
+)
+
+// exportColumns is the header line of exported CSV files
+var exportColumns = []string{"id", "date", "description", "category", "amount", "currency", "base_amount", "base_currency", "merchant", "created_at"}
+
+// ExportService exports expenses, choosing between an immediate and a background export by size
+type ExportService struct {
+	exports   domain.ExportRepository
+	expenses  domain.Repository
+	store     storage.Storage
+	tasks     *queue.Queue
+	threshold int64
+	maxRows   int64
+	clock     clock.Clock
+}
+
+// NewExportService creates a new export service
+// Exports with more than threshold rows run on tasks (threshold <= 0 uses the default);
+// exports with more than maxRows rows are refused (maxRows <= 0 means no limit)
+func NewExportService(exports domain.ExportRepository, expenses domain.Repository, store storage.Storage, tasks *queue.Queue, threshold int, maxRows int64, clk clock.Clock) *ExportService {
+	if threshold <= 0 {
+		threshold = DefaultExportAsyncThreshold
+	}
+	return &ExportService{
+		exports:   exports,
+		expenses:  expenses,
+		store:     store,
+		tasks:     tasks,
+		threshold: int64(threshold),
+		maxRows:   maxRows,
+		clock:     clock.Or(clk),
+	}
+}
+
+// ExportRequest describes which expenses to export
+// The filters mean the same as on GET /expenses
+type ExportRequest struct {
+	Format      string       `json:"format" form:"format"`
+	Category    string       `json:"category,omitempty" form:"category"`
+	DateFrom    string       `json:"date_from,omitempty" form:"date_from"`
+	DateTo      string       `json:"date_to,omitempty" form:"date_to"`
+	MinAmount   domain.Money `json:"min_amount,omitzero" form:"min_amount"`
+	MaxAmount   domain.Money `json:"max_amount,omitzero" form:"max_amount"`
+	Description string       `json:"description,omitempty" form:"description"`
+}
+
+// normalize fills in the default format and validates the request
+func (r *ExportRequest) normalize() error {
+	r.Format = strings.ToLower(strings.TrimSpace(r.Format))
+	if r.Format == "" {
+		r.Format = domain.ExportFormatCSV
+	}
+	for _, date := range []string{r.DateFrom, r.DateTo} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return domain.ErrInvalidExport
+		}
+	}
+	if r.MinAmount.IsNegative() || r.MaxAmount.IsNegative() {
+		return domain.ErrInvalidExport
+	}
+	return nil
+}
+
+// filters converts the request into repository filters
+func (r *ExportRequest) filters() map[string]interface{} {
+	filters := make(map[string]interface{})
+	if r.Category != "" {
+		filters["category"] = r.Category
+	}
+	if r.DateFrom != "" {
+		filters["date_from"] = r.DateFrom
+	}
+	if r.DateTo != "" {
+		filters["date_to"] = r.DateTo
+	}
+	if r.MinAmount.Minor > 0 {
+		filters["min_amount"] = r.MinAmount
+	}
+	if r.MaxAmount.Minor > 0 {
+		filters["max_amount"] = r.MaxAmount
+	}
+	if r.Description != "" {
+		filters["description"] = r.Description
+	}
+	return filters
+}
+
+// Export writes a small export straight into w, or starts a job for a large one
+// It returns the job when the export was moved to the background, and nil when it was written to w
+// Nothing is written to w before the decision, so the caller can still choose the response status
+func (s *ExportService) Export(ctx context.Context, req *ExportRequest, begin func() io.Writer) (*domain.ExportJob, error) {
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+	filters := req.filters()
+	count, err := s.expenses.Count(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count expenses: %w", err)
+	}
+	if s.maxRows > 0 && count > s.maxRows {
+		return nil, domain.ErrExportTooLarge
+	}
+	if count > s.threshold {
+		return s.createJob(ctx, req)
+	}
+	_, err = s.writeCSV(ctx, filters, begin(), nil)
+	return nil, err
+}
+
+// CreateJob queues a background export, whatever its size
+func (s *ExportService) CreateJob(ctx context.Context, req *ExportRequest) (*domain.ExportJob, error) {
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+	if s.maxRows > 0 {
+		count, err := s.expenses.Count(ctx, req.filters())
+		if err != nil {
+			return nil, fmt.Errorf("failed to count expenses: %w", err)
+		}
+		if count > s.maxRows {
+			return nil, domain.ErrExportTooLarge
+		}
+	}
+	return s.createJob(ctx, req)
+}
+
+// createJob queues a background export of a validated request
+func (s *ExportService) createJob(ctx context.Context, req *ExportRequest) (*domain.ExportJob, error) {
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	job, err := domain.NewExportJob(auth.UserID(ctx), auth.BookID(ctx), req.Format, string(encoded))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.exports.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if err := s.enqueue(job); err != nil {
+		job.Fail(err.Error(), s.clock.Now())
+		if updateErr := s.exports.Update(ctx, job); updateErr != nil {
+			return nil, updateErr
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetJob returns one of the caller's export jobs
+func (s *ExportService) GetJob(ctx context.Context, id string) (*domain.ExportJob, error) {
+	job, err := s.exports.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != auth.UserID(ctx) {
+		return nil, domain.ErrExportNotFound
+	}
+	return job, nil
+}
+
+// ListJobs returns the caller's export jobs, newest first
+func (s *ExportService) ListJobs(ctx context.Context) ([]*domain.ExportJob, error) {
+	return s.exports.ListByUser(ctx, auth.UserID(ctx))
+}
+
+// OpenDownload opens the file of a finished export
+// It doesn't check who asks: downloads are authorized by the signed URL the owner was given
+func (s *ExportService) OpenDownload(ctx context.Context, id string) (*domain.ExportJob, io.ReadCloser, error) {
+	job, err := s.exports.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !job.Downloadable(s.clock.Now()) {
+		return nil, nil, domain.ErrExportNotReady
+	}
+	file, err := s.store.Get(ctx, job.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil, domain.ErrExportNotReady
+		}
+		return nil, nil, err
+	}
+	return job, file, nil
+}
+
+// ResumeUnfinished queues the jobs that were queued or running when the process last stopped
+// They start over from the first row
+func (s *ExportService) ResumeUnfinished(ctx context.Context) (int, error) {
+	jobs, err := s.exports.ListUnfinished(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i, job := range jobs {
+		if err := s.enqueue(job); err != nil {
+			return i, err
+		}
+	}
+	return len(jobs), nil
+}
+
+// PurgeExpired deletes the files of exports past their expiry and returns how many were deleted
+func (s *ExportService) PurgeExpired(ctx context.Context) (int, error) {
+	jobs, err := s.exports.ListExpired(ctx, s.clock.Now())
+	if err != nil {
+		return 0, err
+	}
+	for i, job := range jobs {
+		if err := s.store.Delete(ctx, job.StorageKey); err != nil {
+			return i, fmt.Errorf("failed to delete export file: %w", err)
+		}
+		job.StorageKey = ""
+		if err := s.exports.Update(ctx, job); err != nil {
+			return i, err
+		}
+	}
+	return len(jobs), nil
+}
+
+// enqueue schedules job to run on the task queue
+func (s *ExportService) enqueue(job *domain.ExportJob) error {
+	id := job.ID.String()
+	err := s.tasks.Enqueue("export "+id, func(ctx context.Context) error {
+		return s.run(ctx, id)
+	})
+	if errors.Is(err, queue.ErrFull) {
+		return domain.ErrExportQueueFull
+	}
+	return err
+}
+
+// run executes one export job: it streams the matching expenses as CSV into storage
+func (s *ExportService) run(ctx context.Context, id string) error {
+	// Step 1: Load the job and its filters
+	job, err := s.exports.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	var req ExportRequest
+	if err := json.Unmarshal([]byte(job.Filters), &req); err != nil {
+		return s.fail(ctx, job, "", fmt.Errorf("invalid export filters: %w", err))
+	}
+	filters := req.filters()
+
+	// The export runs on behalf of whoever requested it
+	ctx = auth.WithPrincipal(ctx, auth.Principal{UserID: job.UserID, BookID: job.BookID, Roles: []auth.Role{auth.RoleMember}})
+
+	// Step 2: Count the rows so progress can be reported
+	total, err := s.expenses.Count(ctx, filters)
+	if err != nil {
+		return s.fail(ctx, job, "", err)
+	}
+	job.Start(total, s.clock.Now())
+	if err := s.exports.Update(ctx, job); err != nil {
+		return err
+	}
+
+	// Step 3: Stream the CSV into storage through a pipe, so the file is never held in memory
+	key := "exports/" + job.ID.String() + "." + job.Format
+	reader, writer := io.Pipe()
+	rowsWritten := make(chan int64, 1)
+	go func() {
+		rows, err := s.writeCSV(ctx, filters, writer, func(rows int64) error {
+			job.ProcessedRows = rows
+			return s.exports.Update(ctx, job)
+		})
+		rowsWritten <- rows
+		writer.CloseWithError(err)
+	}()
+	size, err := s.store.Put(ctx, key, reader)
+	// Unblocks the writer if storage gave up before reading everything
+	reader.CloseWithError(err)
+	rows := <-rowsWritten
+	if err != nil {
+		return s.fail(ctx, job, key, err)
+	}
+
+	// Step 4: Make the file available for download
+	now := s.clock.Now()
+	job.Complete(key, size, rows, now, now.Add(ExportRetention))
+	return s.exports.Update(ctx, job)
+}
+
+// fail records why job failed and removes its partial file, returning cause
+func (s *ExportService) fail(ctx context.Context, job *domain.ExportJob, key string, cause error) error {
+	if key != "" {
+		if err := s.store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("%w (and failed to delete the partial export: %v)", cause, err)
+		}
+	}
+	job.Fail(cause.Error(), s.clock.Now())
+	if err := s.exports.Update(ctx, job); err != nil {
+		return fmt.Errorf("%w (and failed to record the failure: %v)", cause, err)
+	}
+	return cause
+}
+
+// writeCSV streams the expenses matching filters as CSV into w and returns how many were written
+// progress, if set, is called every exportProgressEvery rows
+func (s *ExportService) writeCSV(ctx context.Context, filters map[string]interface{}, w io.Writer, progress func(rows int64) error) (int64, error) {
+	out := csv.NewWriter(w)
+	if err := out.Write(exportColumns); err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	err := s.expenses.Stream(ctx, filters, func(expense *domain.Expense) error {
+		record := []string{
+			expense.ID.String(),
+			expense.Date.Format("2006-01-02"),
+			expense.Description,
+			expense.Category,
+			expense.Amount.String(),
+			expense.Currency,
+			expense.BaseAmount.String(),
+			expense.BaseCurrency,
+			expense.Merchant,
+			expense.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportProgressEvery == 0 {
+			out.Flush()
+			if err := out.Error(); err != nil {
+				return err
+			}
+			if progress != nil {
+				return progress(rows)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return rows, fmt.Errorf("failed to export expenses: %w", err)
+	}
+	out.Flush()
+	return rows, out.Error()
+}
@@ -0,0 +1,114 @@
+// Package application contains the business logic and use cases
+// This file contains the extension points modules outside the core can hook into
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"log"     // For logging hooks that fail after an import
+	"sort"    // For listing reports by name
+	"strings" // For matching report names
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/report"          // Shared report renderers
+)
+
+// BeforeCreateHook runs before a new expense is saved through POST /expenses
+// It may change the expense (e.g. fill in a field a fork needs) or refuse it by returning an
+// error that wraps domain.ErrExpenseRejected; any other error fails the request
+type BeforeCreateHook func(ctx context.Context, expense *domain.Expense) error
+
+// AfterImportHook runs after a bank or card statement import with the expenses it created
+// The expenses are already saved, so a failing hook is logged and doesn't fail the import
+type AfterImportHook func(ctx context.Context, imported []*domain.Expense) error
+
+// ReportProvider adds a report to GET /reports/custom/{name}
+// Build gets the query parameters of the request and works in the caller's book, like the
+// built-in reports; wrapping domain.ErrInvalidReportParameters (or returning
+// domain.ErrInvalidPeriod) answers 400
+type ReportProvider struct {
+	// Name identifies the report in the URL (e.g. "weekday")
+	Name string `json:"name"`
+
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	Build func(ctx context.Context, params map[string]string) (*report.Document, error) `json:"-"`
+}
+
+// WithBeforeCreateHooks runs hooks, in order, before every expense created through CreateExpense
+func WithBeforeCreateHooks(hooks ...BeforeCreateHook) ServiceOption {
+	return func(s *Service) {
+		s.beforeCreate = append(s.beforeCreate, hooks...)
+	}
+}
+
+// runBeforeCreate runs the before-create hooks, stopping at the first that fails
+func (s *Service) runBeforeCreate(ctx context.Context, expense *domain.Expense) error {
+	for _, hook := range s.beforeCreate {
+		if err := hook(ctx, expense); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportServiceOption configures optional behavior of the import service
+type ImportServiceOption func(*ImportService)
+
+// WithAfterImportHooks runs hooks, in order, after every import that created expenses
+func WithAfterImportHooks(hooks ...AfterImportHook) ImportServiceOption {
+	return func(s *ImportService) {
+		s.afterImport = append(s.afterImport, hooks...)
+	}
+}
+
+// runAfterImport runs the after-import hooks; one failing doesn't keep the others from running
+func (s *ImportService) runAfterImport(ctx context.Context, imported []*domain.Expense) {
+	if len(imported) == 0 {
+		return
+	}
+	for i, hook := range s.afterImport {
+		if err := hook(ctx, imported); err != nil {
+			log.Printf("after-import hook %d failed: %v", i+1, err)
+		}
+	}
+}
+
+// CustomReportService builds the reports extension modules provide
+type CustomReportService struct {
+	providers map[string]*ReportProvider
+}
+
+// NewCustomReportService creates a service for the given report providers
+// Names are matched case-insensitively; a later provider with the same name replaces an earlier one
+func NewCustomReportService(providers []*ReportProvider) *CustomReportService {
+	s := &CustomReportService{providers: make(map[string]*ReportProvider, len(providers))}
+	for _, provider := range providers {
+		s.providers[strings.ToLower(provider.Name)] = provider
+	}
+	return s
+}
+
+// List returns the available reports ordered by name
+func (s *CustomReportService) List() []*ReportProvider {
+	providers := make([]*ReportProvider, 0, len(s.providers))
+	for _, provider := range s.providers {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Name < providers[j].Name })
+	return providers
+}
+
+// Build builds the report called name
+func (s *CustomReportService) Build(ctx context.Context, name string, params map[string]string) (*report.Document, error) {
+	provider, ok := s.providers[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, domain.ErrCustomReportNotFound
+	}
+	doc, err := provider.Build(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s report: %w", provider.Name, err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,61 @@
+// Package application contains the business logic and use cases
+// This file contains the review flag use cases
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// FlagService handles business logic for review flags
+type FlagService struct {
+	flags    domain.FlagRepository
+	expenses domain.Repository
+}
+
+// NewFlagService creates a new flag service
+func NewFlagService(flags domain.FlagRepository, expenses domain.Repository) *FlagService {
+	return &FlagService{flags: flags, expenses: expenses}
+}
+
+// SetFlagRequest represents the optional request body for PUT /expenses/{id}/flags/{flag}
+type SetFlagRequest struct {
+	Note string `json:"note"`
+}
+
+// SetFlag sets a flag on an expense (setting it again just updates the note)
+func (s *FlagService) SetFlag(ctx context.Context, expenseID, name string, req *SetFlagRequest) (*domain.ExpenseFlag, error) {
+	flag, err := domain.ParseFlag(name)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.expenses.GetByID(ctx, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense: %w", err)
+	}
+
+	expenseFlag := &domain.ExpenseFlag{ExpenseID: expense.ID, Flag: flag, Note: req.Note}
+	if err := s.flags.Set(ctx, expenseFlag); err != nil {
+		return nil, err
+	}
+	return expenseFlag, nil
+}
+
+// RemoveFlag clears a flag from an expense
+func (s *FlagService) RemoveFlag(ctx context.Context, expenseID, name string) error {
+	flag, err := domain.ParseFlag(name)
+	if err != nil {
+		return err
+	}
+	return s.flags.Remove(ctx, expenseID, flag)
+}
+
+// ListFlags returns the flags set on an expense
+func (s *FlagService) ListFlags(ctx context.Context, expenseID string) ([]*domain.ExpenseFlag, error) {
+	if _, err := s.expenses.GetByID(ctx, expenseID); err != nil {
+		return nil, fmt.Errorf("failed to get expense: %w", err)
+	}
+	return s.flags.ListForExpense(ctx, expenseID)
+}
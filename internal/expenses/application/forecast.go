@@ -0,0 +1,165 @@
+// Package application contains the business logic and use cases
+// This file contains the forecasting engine that projects month-end spending against budgets
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For ordering the category forecasts
+	"strings" // For normalizing category names
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// PlannedExpense is a known upcoming expense that isn't recorded yet
+type PlannedExpense struct {
+	Description string       `json:"description"`
+	Category    string       `json:"category" binding:"required"`
+	Amount      domain.Money `json:"amount"`
+	Date        time.Time    `json:"date" binding:"required"`
+}
+
+// ForecastInput describes what to forecast
+type ForecastInput struct {
+	// Month is the first day of the month to forecast
+	Month time.Time
+
+	// AsOf is the point in time actual spending is known up to
+	AsOf time.Time
+
+	// Budgets maps category to monthly limit
+	Budgets map[string]domain.Money
+
+	// Planned are extra expenses expected during the month
+	Planned []PlannedExpense
+}
+
+// CategoryForecast is the projected month-end outcome for one category
+type CategoryForecast struct {
+	Category string `json:"category"`
+
+	// Budget is the monthly limit (0 if the category has no budget)
+	Budget domain.Money `json:"budget"`
+
+	// SpentToDate is what was actually spent in the month up to AsOf
+	SpentToDate domain.Money `json:"spent_to_date"`
+
+	// Projected is the extra spending expected from the current daily run rate
+	Projected domain.Money `json:"projected"`
+
+	// Planned is the sum of the planned expenses in the category
+	Planned domain.Money `json:"planned"`
+
+	// ProjectedTotal = SpentToDate + Projected + Planned
+	ProjectedTotal domain.Money `json:"projected_total"`
+
+	// Remaining is Budget - ProjectedTotal (negative when over budget)
+	Remaining domain.Money `json:"remaining"`
+
+	// OverBudget is true when a budgeted category is projected to exceed its limit
+	OverBudget bool `json:"over_budget"`
+}
+
+// Forecast is the projected month-end outcome across all categories
+type Forecast struct {
+	Month       string              `json:"month"`
+	AsOf        time.Time           `json:"as_of"`
+	DaysElapsed int                 `json:"days_elapsed"`
+	DaysInMonth int                 `json:"days_in_month"`
+	Categories  []*CategoryForecast `json:"categories"`
+
+	TotalBudget    domain.Money `json:"total_budget"`
+	TotalProjected domain.Money `json:"total_projected"`
+	TotalRemaining domain.Money `json:"total_remaining"`
+}
+
+// Forecaster projects month-end spending from actuals, the daily run rate and planned expenses
+// The projection is linear: each category keeps spending at its average daily rate so far
+type Forecaster struct {
+	spending domain.SpendingRepository
+}
+
+// NewForecaster creates a forecaster reading actual spending from the given repository
+func NewForecaster(spending domain.SpendingRepository) *Forecaster {
+	return &Forecaster{spending: spending}
+}
+
+// Forecast projects the month-end outcome for input.Month as of input.AsOf
+// Nothing is persisted, so callers can freely pass hypothetical budgets and planned expenses
+func (f *Forecaster) Forecast(ctx context.Context, input ForecastInput) (*Forecast, error) {
+	// Step 1: Work out how far into the month we are
+	start := domain.MonthStart(input.Month)
+	end := start.AddDate(0, 1, 0)
+	daysInMonth := int(end.Sub(start).Hours() / 24)
+
+	asOf := input.AsOf.UTC()
+	if asOf.Before(start) {
+		asOf = start
+	}
+	if asOf.After(end) {
+		asOf = end
+	}
+	daysElapsed := int(asOf.Sub(start).Hours() / 24)
+
+	// Step 2: Load actual spending up to AsOf
+	actuals, err := f.spending.SpendingByCategory(ctx, start, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spending: %w", err)
+	}
+
+	// Step 3: Collect every category that has a budget, spending or planned expenses
+	byCategory := make(map[string]*CategoryForecast)
+	row := func(category string) *CategoryForecast {
+		if c, ok := byCategory[category]; ok {
+			return c
+		}
+		c := &CategoryForecast{Category: category}
+		byCategory[category] = c
+		return c
+	}
+
+	for category, amount := range input.Budgets {
+		row(category).Budget = amount
+	}
+	for _, actual := range actuals {
+		c := row(actual.Category)
+		c.SpentToDate = c.SpentToDate.Add(actual.Amount)
+	}
+	for _, planned := range input.Planned {
+		category := strings.TrimSpace(planned.Category)
+		if category == "" || planned.Amount.Minor <= 0 || planned.Date.Before(start) || !planned.Date.Before(end) {
+			return nil, domain.ErrInvalidPlannedExpense
+		}
+		c := row(category)
+		c.Planned = c.Planned.Add(planned.Amount)
+	}
+
+	// Step 4: Project each category and add up the totals
+	forecast := &Forecast{
+		Month:       start.Format("2006-01"),
+		AsOf:        asOf,
+		DaysElapsed: daysElapsed,
+		DaysInMonth: daysInMonth,
+		Categories:  make([]*CategoryForecast, 0, len(byCategory)),
+	}
+	for _, c := range byCategory {
+		if daysElapsed > 0 {
+			// The daily run rate over the days still to come
+			c.Projected = c.SpentToDate.Mul(float64(daysInMonth-daysElapsed) / float64(daysElapsed))
+		}
+		c.ProjectedTotal = c.SpentToDate.Add(c.Projected).Add(c.Planned)
+		c.Remaining = c.Budget.Sub(c.ProjectedTotal)
+		c.OverBudget = c.Budget.Minor > 0 && c.ProjectedTotal.Minor > c.Budget.Minor
+
+		forecast.TotalBudget = forecast.TotalBudget.Add(c.Budget)
+		forecast.TotalProjected = forecast.TotalProjected.Add(c.ProjectedTotal)
+		forecast.Categories = append(forecast.Categories, c)
+	}
+	forecast.TotalRemaining = forecast.TotalBudget.Sub(forecast.TotalProjected)
+
+	sort.Slice(forecast.Categories, func(i, j int) bool {
+		return forecast.Categories[i].Category < forecast.Categories[j].Category
+	})
+	return forecast, nil
+}
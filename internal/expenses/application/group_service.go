@@ -0,0 +1,304 @@
+// Package application contains the business logic and use cases
+// This file contains groups, their shared budgets and the group budget status with per-member contributions
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For matching category names
+
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For parsing member, budget and expense IDs
+)
+
+// GroupService manages groups and their shared budgets
+type GroupService struct {
+	groups domain.GroupRepository
+	clock  clock.Clock
+}
+
+// NewGroupService creates a new group service
+// clk decides which month the budget status defaults to (nil means the system clock)
+func NewGroupService(groups domain.GroupRepository, clk clock.Clock) *GroupService {
+	return &GroupService{
+		groups: groups,
+		clock:  clock.Or(clk),
+	}
+}
+
+// GroupMemberRequest represents a member in CreateGroupRequest and the body of POST /groups/{id}/members
+type GroupMemberRequest struct {
+	Name   string  `json:"name" binding:"required"`
+	UserID string  `json:"user_id"`
+	Share  float64 `json:"share" binding:"gte=0"`
+}
+
+// CreateGroupRequest represents the request body for POST /groups
+type CreateGroupRequest struct {
+	Name    string                `json:"name" binding:"required"`
+	Members []*GroupMemberRequest `json:"members" binding:"dive"`
+}
+
+// SaveGroupBudgetRequest represents the request body for PUT /groups/{id}/budgets
+type SaveGroupBudgetRequest struct {
+	Category string `json:"category" binding:"required"`
+
+	// Amount must be greater than 0; NewGroupBudget rejects it otherwise (a missing amount is 0)
+	Amount domain.Money `json:"amount"`
+}
+
+// AttributeExpensesRequest represents the request body for POST /groups/{id}/members/{memberId}/expenses
+type AttributeExpensesRequest struct {
+	ExpenseIDs []string `json:"expense_ids" binding:"required,min=1"`
+}
+
+// CreateGroup creates a group with its first members
+func (s *GroupService) CreateGroup(ctx context.Context, req *CreateGroupRequest) (*domain.Group, error) {
+	members := make([]*domain.GroupMember, 0, len(req.Members))
+	for _, m := range req.Members {
+		member, err := domain.NewGroupMember(m.Name, m.UserID, m.Share)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	group, err := domain.NewGroup(req.Name, members)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.groups.Create(ctx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// ListGroups returns all groups with their members
+func (s *GroupService) ListGroups(ctx context.Context) ([]*domain.Group, error) {
+	return s.groups.List(ctx)
+}
+
+// GetGroup returns a group with its members
+func (s *GroupService) GetGroup(ctx context.Context, id string) (*domain.Group, error) {
+	return s.groups.GetByID(ctx, id)
+}
+
+// AddMember adds a member to a group
+func (s *GroupService) AddMember(ctx context.Context, groupID string, req *GroupMemberRequest) (*domain.GroupMember, error) {
+	group, err := s.groups.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := domain.NewGroupMember(req.Name, req.UserID, req.Share)
+	if err != nil {
+		return nil, err
+	}
+	member.GroupID = group.ID
+	if err := s.groups.AddMember(ctx, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember removes a member from a group; their expenses stay but no longer count for the group
+func (s *GroupService) RemoveMember(ctx context.Context, groupID, memberID string) error {
+	group, err := s.groups.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Parse(memberID)
+	if err != nil {
+		return domain.ErrGroupMemberNotFound
+	}
+	return s.groups.RemoveMember(ctx, group.ID, id)
+}
+
+// AttributeExpenses records which member paid the given expenses
+func (s *GroupService) AttributeExpenses(ctx context.Context, groupID, memberID string, req *AttributeExpensesRequest) error {
+	group, err := s.groups.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Parse(memberID)
+	if err != nil || group.Member(id) == nil {
+		return domain.ErrGroupMemberNotFound
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.ExpenseIDs))
+	ids := make([]uuid.UUID, 0, len(req.ExpenseIDs))
+	for _, raw := range req.ExpenseIDs {
+		expenseID, err := uuid.Parse(raw)
+		if err != nil {
+			return domain.ErrExpenseNotFound
+		}
+		if !seen[expenseID] {
+			seen[expenseID] = true
+			ids = append(ids, expenseID)
+		}
+	}
+	return s.groups.AttributeExpenses(ctx, id, ids)
+}
+
+// ListBudgets returns a group's budgets
+func (s *GroupService) ListBudgets(ctx context.Context, groupID string) ([]*domain.GroupBudget, error) {
+	group, err := s.groups.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.groups.Budgets(ctx, group.ID)
+}
+
+// SaveBudget sets the group's monthly budget for a category, creating it if needed
+// Categories match case-insensitively, like personal budgets
+func (s *GroupService) SaveBudget(ctx context.Context, groupID string, req *SaveGroupBudgetRequest) (*domain.GroupBudget, error) {
+	group, err := s.groups.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := s.groups.Budgets(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := domain.NewGroupBudget(group.ID, req.Category, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range budgets {
+		if strings.EqualFold(existing.Category, budget.Category) {
+			existing.Amount = budget.Amount
+			budget = existing
+			break
+		}
+	}
+	if err := s.groups.SaveBudget(ctx, budget); err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+// DeleteBudget removes one of the group's budgets
+func (s *GroupService) DeleteBudget(ctx context.Context, groupID, budgetID string) error {
+	group, err := s.groups.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Parse(budgetID)
+	if err != nil {
+		return domain.ErrBudgetNotFound
+	}
+	return s.groups.DeleteBudget(ctx, group.ID, id)
+}
+
+// MemberContribution is what one member spent against a group budget compared with their share
+type MemberContribution struct {
+	MemberID uuid.UUID `json:"member_id"`
+	Name     string    `json:"name"`
+
+	// Share is the member's fraction (0-1) of the budget and Expected the matching amount
+	Share    float64      `json:"share"`
+	Expected domain.Money `json:"expected"`
+	Spent    domain.Money `json:"spent"`
+
+	// Difference is Spent - Expected: positive when the member paid more than their share
+	Difference domain.Money `json:"difference"`
+}
+
+// GroupBudgetStatus is the group's consumption of one shared budget
+type GroupBudgetStatus struct {
+	Category    string                `json:"category"`
+	Budget      domain.Money          `json:"budget"`
+	Spent       domain.Money          `json:"spent"`
+	Remaining   domain.Money          `json:"remaining"`
+	PercentUsed float64               `json:"percent_used"`
+	Members     []*MemberContribution `json:"members"`
+}
+
+// GroupBudgetStatusReport is the status of all of a group's budgets in one month
+type GroupBudgetStatusReport struct {
+	GroupID   uuid.UUID `json:"group_id"`
+	GroupName string    `json:"group_name"`
+	Month     string    `json:"month"`
+
+	Budgets []*GroupBudgetStatus `json:"budgets"`
+
+	// Members totals every member's spending and share across all budgeted categories
+	Members []*MemberContribution `json:"members"`
+}
+
+// BudgetStatus returns the group's consumption of its budgets in month (YYYY-MM, defaults to the current month)
+// with each member's spending compared with their share
+func (s *GroupService) BudgetStatus(ctx context.Context, groupID, month string) (*GroupBudgetStatusReport, error) {
+	// Step 1: Resolve the group and the month
+	group, err := s.groups.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	start := domain.MonthStart(s.clock.Now())
+	if month != "" {
+		if start, err = domain.ParseMonth(month); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 2: Load the budgets and what each member spent per category
+	budgets, err := s.groups.Budgets(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.groups.SpendingByMember(ctx, group.ID, start, start.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	spent := make(map[string]map[uuid.UUID]domain.Money)
+	for _, row := range rows {
+		key := strings.ToLower(row.Category)
+		if spent[key] == nil {
+			spent[key] = make(map[uuid.UUID]domain.Money)
+		}
+		spent[key][row.MemberID] = spent[key][row.MemberID].Add(row.Amount)
+	}
+
+	// Step 3: Compare every budget, and every member within it, with the shares
+	report := &GroupBudgetStatusReport{
+		GroupID:   group.ID,
+		GroupName: group.Name,
+		Month:     start.Format("2006-01"),
+		Budgets:   []*GroupBudgetStatus{},
+		Members:   []*MemberContribution{},
+	}
+	overall := make(map[uuid.UUID]*MemberContribution, len(group.Members))
+	for _, member := range group.Members {
+		overall[member.ID] = &MemberContribution{MemberID: member.ID, Name: member.Name, Share: group.ShareOf(member)}
+		report.Members = append(report.Members, overall[member.ID])
+	}
+
+	for _, budget := range budgets {
+		status := &GroupBudgetStatus{Category: budget.Category, Budget: budget.Amount, Members: []*MemberContribution{}}
+		bySpender := spent[strings.ToLower(budget.Category)]
+		for _, member := range group.Members {
+			share := group.ShareOf(member)
+			c := &MemberContribution{
+				MemberID: member.ID,
+				Name:     member.Name,
+				Share:    share,
+				Expected: budget.Amount.Mul(share),
+				Spent:    bySpender[member.ID],
+			}
+			c.Difference = c.Spent.Sub(c.Expected)
+			status.Members = append(status.Members, c)
+			status.Spent = status.Spent.Add(c.Spent)
+
+			total := overall[member.ID]
+			total.Expected = total.Expected.Add(c.Expected)
+			total.Spent = total.Spent.Add(c.Spent)
+		}
+		status.Remaining = budget.Amount.Sub(status.Spent)
+		status.PercentUsed = domain.RoundAmount(status.Spent.Float64() / budget.Amount.Float64() * 100)
+		report.Budgets = append(report.Budgets, status)
+	}
+	for _, total := range report.Members {
+		total.Difference = total.Spent.Sub(total.Expected)
+	}
+	return report, nil
+}
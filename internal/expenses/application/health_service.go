@@ -0,0 +1,80 @@
+// Package application contains the business logic and use cases
+// This file contains the health check, which knows about the region's role in an
+// active-passive deployment
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For describing problems
+	"time"    // For the replication lag limit
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// Health statuses
+const (
+	// HealthOK means the region can do everything its role allows
+	HealthOK = "ok"
+
+	// HealthDegraded means the region still serves requests, but someone should look at it
+	HealthDegraded = "degraded"
+
+	// HealthDown means the region shouldn't get traffic
+	HealthDown = "down"
+)
+
+// HealthReport is the answer to GET /health
+type HealthReport struct {
+	Status  string `json:"status"`
+	Service string `json:"service"`
+
+	// Region names the deployment region (empty when it isn't configured) and Role is its role
+	Region string `json:"region,omitempty"`
+	Role   string `json:"role"`
+
+	Replication *domain.ReplicationStatus `json:"replication,omitempty"`
+
+	// Problems explain a status other than ok
+	Problems []string `json:"problems,omitempty"`
+}
+
+// HealthService checks the region and its database
+type HealthService struct {
+	replication domain.ReplicationRepository
+	region      string
+	role        string
+	maxLag      time.Duration
+}
+
+// NewHealthService creates a new health service
+// maxLag is how far a passive region's replica may fall behind before it counts as degraded
+func NewHealthService(replication domain.ReplicationRepository, region, role string, maxLag time.Duration) *HealthService {
+	return &HealthService{replication: replication, region: region, role: role, maxLag: maxLag}
+}
+
+// Check reports the health of the region
+// An active region whose database is a standby is down: it would fail every write, and a
+// primary running as active in two regions at once is how failovers corrupt data
+func (s *HealthService) Check(ctx context.Context) *HealthReport {
+	report := &HealthReport{Status: HealthOK, Service: "MyExpenses API", Region: s.region, Role: s.role}
+	status, err := s.replication.ReplicationStatus(ctx)
+	if err != nil {
+		report.Status = HealthDown
+		report.Problems = append(report.Problems, "database unreachable")
+		return report
+	}
+	report.Replication = status
+
+	switch {
+	case s.role == domain.RegionActive && status.InRecovery:
+		report.Status = HealthDown
+		report.Problems = append(report.Problems, "the database is a read-only standby; promote it or run this region as passive")
+	case s.role == domain.RegionPassive && !status.InRecovery:
+		report.Status = HealthDegraded
+		report.Problems = append(report.Problems, "the database has been promoted; restart this region with REGION_ROLE=active")
+	case s.role == domain.RegionPassive && status.LagSeconds != nil && *status.LagSeconds > s.maxLag.Seconds():
+		report.Status = HealthDegraded
+		report.Problems = append(report.Problems, fmt.Sprintf("replication lag of %.0fs exceeds %s", *status.LagSeconds, s.maxLag))
+	}
+	return report
+}
@@ -0,0 +1,155 @@
+// Package application contains the business logic and use cases
+// This file contains the bank/card transaction import use case
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For the duplicate detection date window
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// ImportService turns bank and card statement lines into categorized expenses
+type ImportService struct {
+	repo        domain.Repository
+	categorizer domain.Categorizer
+	normalizer  *DescriptionNormalizer
+	converter   *CurrencyConverter
+
+	// afterImport are the extension hooks told about the expenses an import created
+	afterImport []AfterImportHook
+}
+
+// NewImportService creates a new import service
+// categorizer is usually a CategorizerChain (MCC table first, then rules)
+// normalizer cleans up statement descriptions before they are categorized and deduplicated
+// converter locks in the base-currency amount of foreign transactions
+func NewImportService(repo domain.Repository, categorizer domain.Categorizer, normalizer *DescriptionNormalizer, converter *CurrencyConverter, opts ...ImportServiceOption) *ImportService {
+	s := &ImportService{
+		repo:        repo,
+		categorizer: categorizer,
+		normalizer:  normalizer,
+		converter:   converter,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ImportTransactionsRequest represents the request body for POST /imports/transactions
+type ImportTransactionsRequest struct {
+	// Transactions are the statement lines to import
+	Transactions []domain.ImportedTransaction `json:"transactions" binding:"required,min=1,dive"`
+}
+
+// ImportResult summarizes what happened during an import
+type ImportResult struct {
+	// Imported are the expenses that were created
+	Imported []*domain.Expense `json:"imported"`
+
+	// Skipped counts transactions that had already been imported before
+	Skipped int `json:"skipped"`
+
+	// Uncategorized counts imported expenses no categorizer recognized
+	Uncategorized int `json:"uncategorized"`
+}
+
+// ImportTransactions creates an expense for every new transaction
+// Transactions whose ExternalID was already imported are skipped, so re-uploading a statement is safe
+func (s *ImportService) ImportTransactions(ctx context.Context, req *ImportTransactionsRequest) (*ImportResult, error) {
+	result := &ImportResult{Imported: []*domain.Expense{}}
+
+	for i := range req.Transactions {
+		tx := &req.Transactions[i]
+		if tx.Amount.Minor <= 0 {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, domain.ErrInvalidAmount)
+		}
+		if tx.MCC != "" && !domain.IsValidMCC(tx.MCC) {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, domain.ErrInvalidMCC)
+		}
+
+		// Step 1: Normalize the statement text
+		normalized, err := s.normalizer.Normalize(ctx, tx.Description)
+		if err != nil {
+			return nil, err
+		}
+
+		// Step 2: Skip transactions we've seen before
+		duplicate, err := s.isDuplicate(ctx, tx, normalized)
+		if err != nil {
+			return nil, err
+		}
+		if duplicate {
+			result.Skipped++
+			continue
+		}
+
+		// Step 3: Work out the category (MCC table, then rules, then Uncategorized)
+		// Categorizers see the normalized text so rules don't have to cope with store numbers
+		normalizedTx := *tx
+		normalizedTx.Description = normalized
+		normalizedTx.Source = domain.RuleSourceImport
+		category, recognized, err := s.categorizer.Categorize(ctx, &normalizedTx)
+		if err != nil {
+			return nil, err
+		}
+		if !recognized {
+			result.Uncategorized++
+		}
+
+		// Step 4: Create the expense through the domain factory so all rules apply
+		expense, err := domain.NewExpense(tx.Description, tx.Amount, category, tx.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, err)
+		}
+		expense.Merchant = tx.Merchant
+		expense.MCC = tx.MCC
+		expense.Source = domain.SourceImport
+		expense.ExternalID = tx.ExternalID
+		expense.NormalizedDescription = normalized
+
+		// The bank's converted amount is the most accurate rate we can get, so it wins
+		if err := s.converter.Apply(ctx, expense, ConversionInput{
+			Currency:        tx.Currency,
+			ConvertedAmount: tx.ConvertedAmount,
+		}); err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, err)
+		}
+
+		// Step 5: Save it
+		if err := s.repo.Create(ctx, expense); err != nil {
+			return nil, fmt.Errorf("failed to save imported expense: %w", err)
+		}
+		result.Imported = append(result.Imported, expense)
+	}
+
+	// Step 6: Tell the extension modules what was imported
+	s.runAfterImport(ctx, result.Imported)
+	return result, nil
+}
+
+// isDuplicate reports whether a transaction was already imported
+// Banks that provide transaction IDs are matched on the ID; for the others we match
+// on same day + same amount + same normalized description
+func (s *ImportService) isDuplicate(ctx context.Context, tx *domain.ImportedTransaction, normalized string) (bool, error) {
+	filters := map[string]interface{}{"external_id": tx.ExternalID}
+	if tx.ExternalID == "" {
+		day := tx.Date.Truncate(24 * time.Hour)
+		amount := tx.Amount.In(tx.Currency)
+		filters = map[string]interface{}{
+			"normalized_description": normalized,
+			"date_from":              day.Format(time.RFC3339),
+			"date_to":                day.Add(24*time.Hour - time.Nanosecond).Format(time.RFC3339Nano),
+			"min_amount":             amount,
+			"max_amount":             amount,
+		}
+	}
+
+	existing, err := s.repo.GetAll(ctx, filters)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for duplicate transaction: %w", err)
+	}
+	return len(existing) > 0, nil
+}
@@ -0,0 +1,33 @@
+// Package application contains the business logic and use cases
+// This file contains the index usage report for administrators
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// IndexStatsService reports how the database indexes are being used
+// It helps decide whether an index earns its write and storage cost
+type IndexStatsService struct {
+	stats domain.IndexStatsRepository
+}
+
+// NewIndexStatsService creates a new index statistics service
+func NewIndexStatsService(stats domain.IndexStatsRepository) *IndexStatsService {
+	return &IndexStatsService{stats: stats}
+}
+
+// IndexUsage returns the usage of every index, marking the ones never scanned
+// Primary keys and unique indexes show up as unused too, but they are still needed for constraints
+func (s *IndexStatsService) IndexUsage(ctx context.Context) ([]*domain.IndexUsage, error) {
+	usage, err := s.stats.IndexUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range usage {
+		index.Unused = index.Scans == 0
+	}
+	return usage, nil
+}
@@ -0,0 +1,181 @@
+// Package application contains the business logic and use cases
+// This file contains spending insights: the weekday and hour-of-day patterns of the caller's spending
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"math"    // For rounding the weekend ratio
+	"strings" // For weekday names
+	"time"    // For periods, weekdays and timezones
+
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// defaultPatternDays is the period patterns are computed over when the caller doesn't name one
+// A year has every weekday about 52 times, enough for one unusual week not to show
+const defaultPatternDays = 365
+
+// InsightService reveals behavioral patterns in the caller's spending
+type InsightService struct {
+	patterns domain.PatternRepository
+	location *time.Location
+	clock    clock.Clock
+}
+
+// NewInsightService creates a new insight service
+// location is the timezone hours are shown in when the caller doesn't send one (nil means UTC)
+func NewInsightService(patterns domain.PatternRepository, location *time.Location, clk clock.Clock) *InsightService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &InsightService{
+		patterns: patterns,
+		location: location,
+		clock:    clock.Or(clk),
+	}
+}
+
+// WeekdayPattern is what was spent on one weekday over the period
+type WeekdayPattern struct {
+	Weekday string       `json:"weekday"`
+	Amount  domain.Money `json:"amount"`
+	Count   int          `json:"count"`
+
+	// DailyAverage is Amount divided by how often the weekday occurs in the period, so
+	// weekdays compare fairly even when the period has five Mondays and four Sundays
+	DailyAverage domain.Money `json:"daily_average"`
+}
+
+// HourPattern is what was spent in one hour of the day, over the expenses that have a time
+type HourPattern struct {
+	Hour   int          `json:"hour"`
+	Amount domain.Money `json:"amount"`
+	Count  int          `json:"count"`
+}
+
+// HeatmapCell is what was spent in one hour of one weekday
+type HeatmapCell struct {
+	Weekday string       `json:"weekday"`
+	Hour    int          `json:"hour"`
+	Amount  domain.Money `json:"amount"`
+	Count   int          `json:"count"`
+}
+
+// SpendingPatterns is the answer of GET /insights/patterns
+type SpendingPatterns struct {
+	Period   domain.Period `json:"period"`
+	Timezone string        `json:"timezone"`
+
+	// Weekdays has all seven weekdays, Monday first
+	Weekdays []*WeekdayPattern `json:"weekdays"`
+
+	// Hours has all 24 hours; only expenses imported with a time of day count
+	Hours []*HourPattern `json:"hours"`
+
+	// Heatmap has the weekday and hour cells anything was spent in
+	Heatmap []*HeatmapCell `json:"heatmap"`
+
+	// WeekendRatio is the daily average of Saturdays and Sundays over that of Monday to Friday:
+	// 1.5 means half again as much is spent on a weekend day (0 without weekday spending)
+	WeekendRatio float64 `json:"weekend_ratio"`
+
+	// Timestamped counts the expenses that have a time of day, Untimed those that only have a date
+	Timestamped int `json:"timestamped"`
+	Untimed     int `json:"untimed"`
+}
+
+// Patterns aggregates the caller's spending by weekday and hour of day
+// period is a domain.ParsePeriod expression (empty means the last 365 days); timezone is an IANA
+// name the hours are shown in (empty uses the service's default)
+func (s *InsightService) Patterns(ctx context.Context, period, timezone string) (*SpendingPatterns, error) {
+	// Step 1: Resolve the period and timezone
+	location := s.location
+	if timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, domain.ErrInvalidTimezone
+		}
+		location = loaded
+	}
+	var span domain.Period
+	if period == "" {
+		today := s.clock.Now().In(location)
+		end := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+		start := end.AddDate(0, 0, -defaultPatternDays)
+		span = domain.Period{Label: start.Format("2006-01-02") + ".." + end.AddDate(0, 0, -1).Format("2006-01-02"), Start: start, End: end}
+	} else {
+		parsed, err := domain.ParsePeriod(period)
+		if err != nil {
+			return nil, err
+		}
+		span = parsed
+	}
+
+	// Step 2: Let the database group the expenses
+	cells, err := s.patterns.SpendingByWeekdayHour(ctx, span.Start, span.End, location)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3: Fold the cells into weekdays, hours and the heatmap
+	patterns := &SpendingPatterns{Period: span, Timezone: location.String(), Heatmap: []*HeatmapCell{}}
+	weekdays := make(map[time.Weekday]*WeekdayPattern, 7)
+	for _, day := range patternWeekdays {
+		weekdays[day] = &WeekdayPattern{Weekday: strings.ToLower(day.String())}
+		patterns.Weekdays = append(patterns.Weekdays, weekdays[day])
+	}
+	for hour := 0; hour < 24; hour++ {
+		patterns.Hours = append(patterns.Hours, &HourPattern{Hour: hour})
+	}
+	for _, cell := range cells {
+		weekday := weekdays[cell.Weekday]
+		weekday.Amount = weekday.Amount.Add(cell.Amount)
+		weekday.Count += cell.Count
+		if cell.Hour == nil {
+			patterns.Untimed += cell.Count
+			continue
+		}
+		patterns.Timestamped += cell.Count
+		hour := patterns.Hours[*cell.Hour]
+		hour.Amount = hour.Amount.Add(cell.Amount)
+		hour.Count += cell.Count
+		patterns.Heatmap = append(patterns.Heatmap, &HeatmapCell{Weekday: weekday.Weekday, Hour: *cell.Hour, Amount: cell.Amount, Count: cell.Count})
+	}
+
+	// Step 4: Averages per occurrence of each weekday, and weekend against weekdays
+	occurrences := weekdayOccurrences(span)
+	var weekendTotal, weekdayTotal domain.Money
+	var weekendDays, weekdayDays int
+	for day, pattern := range weekdays {
+		if occurrences[day] > 0 {
+			pattern.DailyAverage = pattern.Amount.Mul(1 / float64(occurrences[day]))
+		}
+		if day == time.Saturday || day == time.Sunday {
+			weekendTotal, weekendDays = weekendTotal.Add(pattern.Amount), weekendDays+occurrences[day]
+		} else {
+			weekdayTotal, weekdayDays = weekdayTotal.Add(pattern.Amount), weekdayDays+occurrences[day]
+		}
+	}
+	if weekdayTotal.Minor > 0 && weekendDays > 0 {
+		ratio := (weekendTotal.Float64() / float64(weekendDays)) / (weekdayTotal.Float64() / float64(weekdayDays))
+		patterns.WeekendRatio = math.Round(ratio*100) / 100
+	}
+	return patterns, nil
+}
+
+// patternWeekdays are the weekdays in the order patterns list them
+var patternWeekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// weekdayOccurrences counts how often each weekday occurs in the period
+func weekdayOccurrences(period domain.Period) map[time.Weekday]int {
+	occurrences := make(map[time.Weekday]int, 7)
+	days := int(period.End.Sub(period.Start).Hours()+12) / 24
+	for _, day := range patternWeekdays {
+		occurrences[day] = days / 7
+	}
+	for i := 0; i < days%7; i++ {
+		occurrences[period.Start.AddDate(0, 0, i).Weekday()]++
+	}
+	return occurrences
+}
@@ -0,0 +1,207 @@
+// Package application contains the business logic and use cases
+// This file contains the integration surface for no-code platforms (Zapier, IFTTT, Make):
+// a polling trigger for new expenses and simple actions, all with flat payloads
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For trimming input
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/auth"            // Request-scoped caller identity
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// Trigger page sizes
+const (
+	// DefaultTriggerLimit is how many expenses a poll returns when the platform doesn't say
+	DefaultTriggerLimit = 50
+
+	// MaxTriggerLimit is the most expenses one poll returns
+	MaxTriggerLimit = 100
+)
+
+// IntegrationService adapts expenses to the flat shapes no-code platforms expect
+// Their field pickers work best with one level of plain strings and numbers, and their
+// polling triggers deduplicate on "id", so every item carries one
+type IntegrationService struct {
+	expenses *Service
+	flags    *FlagService
+	clock    clock.Clock
+}
+
+// NewIntegrationService creates a new integration service
+func NewIntegrationService(expenses *Service, flags *FlagService, clk clock.Clock) *IntegrationService {
+	return &IntegrationService{
+		expenses: expenses,
+		flags:    flags,
+		clock:    clock.Or(clk),
+	}
+}
+
+// IntegrationExpense is the flat representation of an expense used by triggers and actions
+// Dates are plain strings so they can be mapped into other apps without parsing
+type IntegrationExpense struct {
+	ID           string       `json:"id"`
+	Description  string       `json:"description"`
+	Amount       domain.Money `json:"amount"`
+	Currency     string       `json:"currency"`
+	BaseAmount   domain.Money `json:"base_amount"`
+	BaseCurrency string       `json:"base_currency"`
+	Category     string       `json:"category"`
+	Merchant     string       `json:"merchant"`
+	Date         string       `json:"date"`
+	CreatedAt    string       `json:"created_at"`
+
+	// Cursor is the position of this expense; passing it as ?cursor= returns what came after it
+	Cursor string `json:"cursor"`
+}
+
+// IntegrationCreateExpenseRequest represents the flat body of POST /integrations/actions/create-expense
+// Date accepts YYYY-MM-DD or a full RFC 3339 timestamp and defaults to today
+type IntegrationCreateExpenseRequest struct {
+	Description string `json:"description" form:"description" binding:"required"`
+
+	// Amount must be greater than 0; NewExpense rejects it otherwise
+	Amount domain.Money `json:"amount" form:"amount"`
+
+	Category string `json:"category" form:"category" binding:"required"`
+	Currency string `json:"currency" form:"currency"`
+	Date     string `json:"date" form:"date"`
+}
+
+// IntegrationFlagExpenseRequest represents the flat body of POST /integrations/actions/flag-expense
+type IntegrationFlagExpenseRequest struct {
+	ExpenseID string `json:"expense_id" form:"expense_id" binding:"required"`
+	Flag      string `json:"flag" form:"flag" binding:"required"`
+	Note      string `json:"note" form:"note"`
+}
+
+// IntegrationFlagResult is the flat response of the flag action
+type IntegrationFlagResult struct {
+	ID        string `json:"id"`
+	ExpenseID string `json:"expense_id"`
+	Flag      string `json:"flag"`
+	Note      string `json:"note"`
+}
+
+// NewExpenses returns the caller's expenses recorded after cursor, newest first
+// Without a cursor it returns the most recent expenses (what platforms use for samples and first polls)
+// With a cursor it returns the oldest `limit` expenses after it, so nothing is skipped when more
+// than a page arrived between two polls; next is the cursor to send on the following poll
+func (s *IntegrationService) NewExpenses(ctx context.Context, cursor string, limit int) (items []*IntegrationExpense, next string, err error) {
+	// Without a user the expense filters fall back to the ownerless legacy rows
+	if auth.UserID(ctx) == "" {
+		return nil, "", domain.ErrForbidden
+	}
+	if limit <= 0 {
+		limit = DefaultTriggerLimit
+	}
+	if limit > MaxTriggerLimit {
+		limit = MaxTriggerLimit
+	}
+
+	filters := map[string]interface{}{"limit": limit, "order": "created_desc"}
+	if cursor != "" {
+		after, err := domain.ParseExpenseCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		filters["created_after"] = after
+		filters["order"] = "created_asc"
+	}
+
+	expenses, err := s.expenses.GetAllExpenses(ctx, filters)
+	if err != nil {
+		return nil, "", err
+	}
+	if cursor != "" {
+		// Fetched oldest first to page forward; platforms want newest first
+		for i, j := 0, len(expenses)-1; i < j; i, j = i+1, j-1 {
+			expenses[i], expenses[j] = expenses[j], expenses[i]
+		}
+	}
+
+	items = make([]*IntegrationExpense, len(expenses))
+	for i, expense := range expenses {
+		items[i] = flattenExpense(expense)
+	}
+
+	// The next poll continues after the newest expense returned, or from the same place if nothing was new
+	next = cursor
+	if len(items) > 0 {
+		next = items[0].Cursor
+	}
+	return items, next, nil
+}
+
+// CreateExpense creates an expense for the caller from a flat action payload
+func (s *IntegrationService) CreateExpense(ctx context.Context, req *IntegrationCreateExpenseRequest) (*IntegrationExpense, error) {
+	// An expense without an owner would be visible to nobody
+	if auth.UserID(ctx) == "" {
+		return nil, domain.ErrForbidden
+	}
+	date, err := parseIntegrationDate(req.Date, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.expenses.CreateExpense(ctx, &CreateExpenseRequest{
+		Description: req.Description,
+		Amount:      req.Amount,
+		Category:    req.Category,
+		Currency:    strings.ToUpper(strings.TrimSpace(req.Currency)),
+		Date:        date,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flattenExpense(expense), nil
+}
+
+// FlagExpense sets a review flag on one of the caller's expenses from a flat action payload
+func (s *IntegrationService) FlagExpense(ctx context.Context, req *IntegrationFlagExpenseRequest) (*IntegrationFlagResult, error) {
+	flag, err := s.flags.SetFlag(ctx, req.ExpenseID, req.Flag, &SetFlagRequest{Note: req.Note})
+	if err != nil {
+		return nil, err
+	}
+	return &IntegrationFlagResult{
+		ID:        flag.ExpenseID.String() + ":" + string(flag.Flag),
+		ExpenseID: flag.ExpenseID.String(),
+		Flag:      string(flag.Flag),
+		Note:      flag.Note,
+	}, nil
+}
+
+// flattenExpense converts an expense into its integration shape
+func flattenExpense(expense *domain.Expense) *IntegrationExpense {
+	return &IntegrationExpense{
+		ID:           expense.ID.String(),
+		Description:  expense.Description,
+		Amount:       expense.Amount,
+		Currency:     expense.Currency,
+		BaseAmount:   expense.BaseAmount,
+		BaseCurrency: expense.BaseCurrency,
+		Category:     expense.Category,
+		Merchant:     expense.Merchant,
+		Date:         expense.Date.Format("2006-01-02"),
+		CreatedAt:    expense.CreatedAt.UTC().Format(time.RFC3339),
+		Cursor:       domain.CursorAfter(expense).String(),
+	}
+}
+
+// parseIntegrationDate parses the date formats no-code platforms commonly send
+// An empty value means the day of now
+func parseIntegrationDate(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return now.UTC().Truncate(24 * time.Hour), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, domain.ErrInvalidDate
+}
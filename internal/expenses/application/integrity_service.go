@@ -0,0 +1,332 @@
+// Package application contains the business logic and use cases
+// This file contains the admin data integrity checker
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching storage errors
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/clock"           // Time source for the report timestamps
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/report"          // Shared report renderers
+	"myexpenses/internal/storage"         // Blob storage holding attachment files
+)
+
+// baseAmountTolerance is how far a stored base amount may be from amount x rate before it counts as drift
+// It absorbs the one-cent rounding of converted amounts entered from card statements
+const baseAmountTolerance = 0.01
+
+// IntegrityService scans the data for inconsistencies and optionally repairs them
+type IntegrityService struct {
+	integrity   domain.IntegrityRepository
+	expenses    domain.Repository
+	attachments domain.AttachmentRepository
+	categories  domain.CategoryRepository
+	storage     storage.Storage
+	content     *attachmentContent
+	clock       clock.Clock
+}
+
+// NewIntegrityService creates a new integrity checker
+func NewIntegrityService(integrity domain.IntegrityRepository, expenses domain.Repository, attachments domain.AttachmentRepository, blobs domain.AttachmentBlobRepository, categories domain.CategoryRepository, store storage.Storage, clk clock.Clock) *IntegrityService {
+	return &IntegrityService{
+		integrity:   integrity,
+		expenses:    expenses,
+		attachments: attachments,
+		categories:  categories,
+		storage:     store,
+		content:     &attachmentContent{blobs: blobs, storage: store},
+		clock:       clock.Or(clk),
+	}
+}
+
+// integrityCheck is one named check; fix says whether repairs should be applied
+type integrityCheck struct {
+	name string
+	run  func(ctx context.Context, fix bool) (*domain.IntegrityCheckResult, error)
+}
+
+// Run executes every check and returns the repair report
+// With autoFix, issues that have a safe repair are fixed and marked as such
+func (s *IntegrityService) Run(ctx context.Context, autoFix bool) (*domain.IntegrityReport, error) {
+	result := &domain.IntegrityReport{StartedAt: s.clock.Now(), AutoFix: autoFix}
+
+	checks := []integrityCheck{
+		{domain.CheckOrphanedAttachments, s.checkOrphanedAttachments},
+		{domain.CheckMissingAttachmentFiles, s.checkMissingAttachmentFiles},
+		{domain.CheckSplitTotals, s.checkSplitTotals},
+		{domain.CheckAccountReferences, s.checkAccountReferences},
+		{domain.CheckBaseAmountDrift, s.checkBaseAmountDrift},
+		{domain.CheckDeletedCategories, s.checkDeletedCategories},
+	}
+	for _, check := range checks {
+		outcome, err := check.run(ctx, autoFix)
+		if err != nil {
+			return nil, fmt.Errorf("integrity check %s failed: %w", check.name, err)
+		}
+		outcome.Check = check.name
+		if outcome.Status == "" {
+			outcome.Status = domain.IntegrityOK
+			if len(outcome.Issues) > 0 {
+				outcome.Status = domain.IntegrityIssues
+			}
+		}
+		if outcome.Issues == nil {
+			outcome.Issues = []*domain.IntegrityIssue{}
+		}
+
+		result.Checks = append(result.Checks, outcome)
+		for _, issue := range outcome.Issues {
+			result.IssueCount++
+			if issue.Fixed {
+				result.FixedCount++
+			}
+		}
+	}
+
+	result.FinishedAt = s.clock.Now()
+	return result, nil
+}
+
+// checkOrphanedAttachments finds attachments of deleted expenses
+// Fix: delete the record and its file (unless other attachments share it)
+func (s *IntegrityService) checkOrphanedAttachments(ctx context.Context, fix bool) (*domain.IntegrityCheckResult, error) {
+	orphans, err := s.integrity.OrphanedAttachments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &domain.IntegrityCheckResult{}
+	for _, attachment := range orphans {
+		issue := &domain.IntegrityIssue{
+			EntityType:  "attachment",
+			EntityID:    attachment.ID.String(),
+			Description: fmt.Sprintf("attachment %q belongs to missing expense %s", attachment.FileName, attachment.ExpenseID),
+			Fixable:     true,
+		}
+		if fix {
+			applyFix(issue, func() error {
+				if err := s.attachments.Delete(ctx, attachment.ID.String()); err != nil {
+					return err
+				}
+				// Files shared with other attachments are kept
+				return s.content.release(ctx, attachment)
+			})
+		}
+		outcome.Issues = append(outcome.Issues, issue)
+	}
+	return outcome, nil
+}
+
+// checkMissingAttachmentFiles finds attachment records whose file is gone from storage
+// Fix: delete the record, since the receipt can't be recovered and downloads would fail
+func (s *IntegrityService) checkMissingAttachmentFiles(ctx context.Context, fix bool) (*domain.IntegrityCheckResult, error) {
+	attachments, err := s.integrity.ListAttachments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &domain.IntegrityCheckResult{}
+	for _, attachment := range attachments {
+		content, err := s.storage.Get(ctx, attachment.StorageKey)
+		if err == nil {
+			content.Close()
+			continue
+		}
+		if !errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, fmt.Errorf("failed to open attachment %s: %w", attachment.ID, err)
+		}
+
+		issue := &domain.IntegrityIssue{
+			EntityType:  "attachment",
+			EntityID:    attachment.ID.String(),
+			Description: fmt.Sprintf("file of attachment %q is missing from storage", attachment.FileName),
+			Fixable:     true,
+		}
+		if fix {
+			applyFix(issue, func() error {
+				if err := s.attachments.Delete(ctx, attachment.ID.String()); err != nil {
+					return err
+				}
+				// Drop the reference so the shared blob record goes once its last attachment does
+				return s.content.release(ctx, attachment)
+			})
+		}
+		outcome.Issues = append(outcome.Issues, issue)
+	}
+	return outcome, nil
+}
+
+// checkSplitTotals would compare split items with their expense total
+// Expenses can't be split yet, so there is nothing to check
+func (s *IntegrityService) checkSplitTotals(ctx context.Context, fix bool) (*domain.IntegrityCheckResult, error) {
+	return &domain.IntegrityCheckResult{
+		Status: domain.IntegritySkipped,
+		Note:   "expense splits are not supported",
+	}, nil
+}
+
+// checkAccountReferences finds rows that make account balances drift from their transactions
+// Fix: detach expenses from the missing account; transfers are only reported,
+// because deleting one would move money between the remaining accounts
+func (s *IntegrityService) checkAccountReferences(ctx context.Context, fix bool) (*domain.IntegrityCheckResult, error) {
+	expenses, err := s.integrity.ExpensesWithMissingAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := s.integrity.TransfersWithMissingAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &domain.IntegrityCheckResult{}
+	for _, expense := range expenses {
+		issue := &domain.IntegrityIssue{
+			EntityType:  "expense",
+			EntityID:    expense.ID.String(),
+			Description: fmt.Sprintf("expense %q is paid from missing account %s", expense.Description, expense.AccountID),
+			Fixable:     true,
+		}
+		if fix {
+			applyFix(issue, func() error { return s.integrity.ClearExpenseAccount(ctx, expense.ID.String()) })
+		}
+		outcome.Issues = append(outcome.Issues, issue)
+	}
+	for _, transfer := range transfers {
+		outcome.Issues = append(outcome.Issues, &domain.IntegrityIssue{
+			EntityType:  "transfer",
+			EntityID:    transfer.ID.String(),
+			Description: fmt.Sprintf("transfer of %s references a missing account", transfer.Amount),
+		})
+	}
+	return outcome, nil
+}
+
+// checkBaseAmountDrift finds expenses whose base-currency amount no longer matches amount x locked rate
+// Fix: recompute the base amount from the locked rate
+func (s *IntegrityService) checkBaseAmountDrift(ctx context.Context, fix bool) (*domain.IntegrityCheckResult, error) {
+	expenses, err := s.integrity.ExpensesWithBaseAmountDrift(ctx, baseAmountTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &domain.IntegrityCheckResult{}
+	for _, expense := range expenses {
+		issue := &domain.IntegrityIssue{
+			EntityType: "expense",
+			EntityID:   expense.ID.String(),
+			Description: fmt.Sprintf("base amount %s %s doesn't match %s %s at rate %g",
+				expense.BaseAmount, expense.BaseCurrency, expense.Amount, expense.Currency, expense.ExchangeRate),
+			Fixable: true,
+		}
+		if fix {
+			applyFix(issue, func() error {
+				if err := expense.LockConversion(expense.BaseCurrency, expense.ExchangeRate); err != nil {
+					return err
+				}
+				return s.expenses.Update(ctx, expense)
+			})
+		}
+		outcome.Issues = append(outcome.Issues, issue)
+	}
+	return outcome, nil
+}
+
+// checkDeletedCategories finds expenses whose category is no longer in the category list
+// Fix: add the category back, since the expenses still use it
+func (s *IntegrityService) checkDeletedCategories(ctx context.Context, fix bool) (*domain.IntegrityCheckResult, error) {
+	count, err := s.integrity.CountCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return &domain.IntegrityCheckResult{
+			Status: domain.IntegritySkipped,
+			Note:   "no category list is defined",
+		}, nil
+	}
+
+	unknown, err := s.integrity.UnknownExpenseCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &domain.IntegrityCheckResult{}
+	for _, usage := range unknown {
+		issue := &domain.IntegrityIssue{
+			EntityType:  "category",
+			EntityID:    usage.Category,
+			Description: fmt.Sprintf("%d expenses use category %q which is not in the category list", usage.Count, usage.Category),
+			Fixable:     true,
+		}
+		if fix {
+			applyFix(issue, func() error {
+				category, err := domain.NewCategory(usage.Category, nil)
+				if err != nil {
+					return err
+				}
+				return s.categories.Create(ctx, category)
+			})
+		}
+		outcome.Issues = append(outcome.Issues, issue)
+	}
+	return outcome, nil
+}
+
+// applyFix runs a repair and records its outcome on the issue
+func applyFix(issue *domain.IntegrityIssue, repair func() error) {
+	if err := repair(); err != nil {
+		issue.FixError = err.Error()
+		return
+	}
+	issue.Fixed = true
+}
+
+// IntegrityDocument describes an integrity report for the shared report renderers
+func IntegrityDocument(r *domain.IntegrityReport) *report.Document {
+	checks := make([]report.Row, 0, len(r.Checks))
+	var issues []report.Row
+	for _, check := range r.Checks {
+		checks = append(checks, report.Row{check.Check, check.Status, len(check.Issues), check.Note})
+		for _, issue := range check.Issues {
+			issues = append(issues, report.Row{check.Check, issue.EntityType, issue.EntityID, issue.Description, issue.Fixable, issue.Fixed})
+		}
+	}
+
+	return &report.Document{
+		Title: "Data integrity report",
+		Summary: []report.Field{
+			{Key: "started_at", Label: "Started", Kind: report.KindText, Value: r.StartedAt},
+			{Key: "auto_fix", Label: "Auto-fix", Kind: report.KindText, Value: r.AutoFix},
+			{Key: "issue_count", Label: "Issues", Kind: report.KindNumber, Value: r.IssueCount},
+			{Key: "fixed_count", Label: "Fixed", Kind: report.KindNumber, Value: r.FixedCount},
+		},
+		Sections: []*report.Section{
+			{
+				Key:   "checks",
+				Title: "Checks",
+				Columns: []report.Column{
+					{Key: "check", Title: "Check", Kind: report.KindText},
+					{Key: "status", Title: "Status", Kind: report.KindText},
+					{Key: "issues", Title: "Issues", Kind: report.KindNumber},
+					{Key: "note", Title: "Note", Kind: report.KindText},
+				},
+				Rows: report.SliceRows(checks),
+			},
+			{
+				Key:   "issues",
+				Title: "Issues",
+				Columns: []report.Column{
+					{Key: "check", Title: "Check", Kind: report.KindText},
+					{Key: "entity_type", Title: "Type", Kind: report.KindText},
+					{Key: "entity_id", Title: "ID", Kind: report.KindText},
+					{Key: "description", Title: "Problem", Kind: report.KindText},
+					{Key: "fixable", Title: "Fixable", Kind: report.KindText},
+					{Key: "fixed", Title: "Fixed", Kind: report.KindText},
+				},
+				Rows: report.SliceRows(issues),
+			},
+		},
+	}
+}
@@ -0,0 +1,69 @@
+// Package application contains the business logic and use cases
+// This file collects the size limits a deployment can configure, so they can be validated
+// together and published to clients through GET /meta/limits
+package application
+
+import (
+	"fmt" // For describing invalid limits
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// DefaultMaxPageSize is the largest page of expenses returned when none is configured
+const DefaultMaxPageSize = 1000
+
+// Limits are the size limits of a deployment
+// Zero values mean "no limit" only where noted; everything else falls back to its default
+type Limits struct {
+	// DefaultPageSize is the page size of GET /expenses when the client doesn't send limit
+	// 0 returns unpaginated lists (up to MaxListResults)
+	DefaultPageSize int `json:"default_page_size"`
+
+	// MaxPageSize is the largest limit a client may ask for; larger ones are reduced to it
+	MaxPageSize int `json:"max_page_size"`
+
+	// MaxListResults is the most expenses an unpaginated list may hold
+	MaxListResults int `json:"max_list_results"`
+
+	// ExportAsyncThreshold is how many rows an export may have before it runs as a background job
+	ExportAsyncThreshold int `json:"export_async_threshold"`
+
+	// MaxExportRows is the most rows one export may have (0 means no limit)
+	MaxExportRows int64 `json:"max_export_rows"`
+
+	// MaxAttachmentBytes is the largest file accepted as an attachment or receipt
+	MaxAttachmentBytes int64 `json:"max_attachment_bytes"`
+
+	// MaxReceiptBatch is the most files one bulk receipt upload may contain (not configurable)
+	MaxReceiptBatch int `json:"max_receipt_batch"`
+}
+
+// DefaultLimits returns the limits used when nothing is configured
+func DefaultLimits() Limits {
+	return Limits{
+		MaxPageSize:          DefaultMaxPageSize,
+		MaxListResults:       DefaultMaxListResults,
+		ExportAsyncThreshold: DefaultExportAsyncThreshold,
+		MaxAttachmentBytes:   domain.MaxAttachmentSize,
+		MaxReceiptBatch:      domain.MaxReceiptBatch,
+	}
+}
+
+// Validate checks that the limits are usable together
+func (l Limits) Validate() error {
+	switch {
+	case l.MaxPageSize <= 0:
+		return fmt.Errorf("max page size must be positive, got %d", l.MaxPageSize)
+	case l.DefaultPageSize < 0 || l.DefaultPageSize > l.MaxPageSize:
+		return fmt.Errorf("default page size must be between 0 and the max page size (%d), got %d", l.MaxPageSize, l.DefaultPageSize)
+	case l.MaxListResults <= 0:
+		return fmt.Errorf("max list results must be positive, got %d", l.MaxListResults)
+	case l.ExportAsyncThreshold <= 0:
+		return fmt.Errorf("export async threshold must be positive, got %d", l.ExportAsyncThreshold)
+	case l.MaxExportRows < 0:
+		return fmt.Errorf("max export rows must not be negative, got %d", l.MaxExportRows)
+	case l.MaxAttachmentBytes <= 0:
+		return fmt.Errorf("max attachment size must be positive, got %d", l.MaxAttachmentBytes)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+// Package application contains the business logic and use cases
+// This file contains loans: debts the user pays off, their linked payments and their amortization
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For error wrapping
+	"strings" // For defaulting the currency
+	"time"    // For the loan start date
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/report"          // For rendering the amortization table
+
+	"github.com/google/uuid" // For parsing payment expense IDs
+)
+
+// LoanService tracks loans and how far they are paid off
+// Payments are ordinary expenses linked to a loan, so they still count as spending
+type LoanService struct {
+	loans        domain.LoanRepository
+	expenses     domain.Repository
+	baseCurrency string
+}
+
+// NewLoanService creates a new loan service
+// baseCurrency is used for loans that give no currency
+func NewLoanService(loans domain.LoanRepository, expenses domain.Repository, baseCurrency string) *LoanService {
+	return &LoanService{
+		loans:        loans,
+		expenses:     expenses,
+		baseCurrency: baseCurrency,
+	}
+}
+
+// CreateLoanRequest represents the request body for POST /loans
+type CreateLoanRequest struct {
+	Name      string  `json:"name" binding:"required"`
+	Principal float64 `json:"principal" binding:"required,gt=0"`
+
+	// Currency defaults to the base currency
+	Currency string `json:"currency"`
+
+	// APR is the annual percentage rate (e.g. 6.5 for 6.5%)
+	APR float64 `json:"apr" binding:"gte=0,lte=100"`
+
+	// Either MonthlyPayment or TermMonths (to derive the payment from) is required
+	MonthlyPayment float64 `json:"monthly_payment" binding:"omitempty,gt=0"`
+	TermMonths     int     `json:"term_months" binding:"omitempty,gt=0"`
+
+	// StartDate is the day the money was borrowed
+	StartDate time.Time `json:"start_date" binding:"required"`
+}
+
+// AssignLoanPaymentsRequest represents the request body for POST /loans/{id}/payments
+type AssignLoanPaymentsRequest struct {
+	ExpenseIDs []string `json:"expense_ids" binding:"required,min=1"`
+}
+
+// LoanAmortization is a loan with its amortization as of its recorded payments
+type LoanAmortization struct {
+	Loan *domain.Loan `json:"loan"`
+	*domain.Amortization
+}
+
+// CreateLoan records a new loan
+func (s *LoanService) CreateLoan(ctx context.Context, req *CreateLoanRequest) (*domain.Loan, error) {
+	currency := req.Currency
+	if strings.TrimSpace(currency) == "" {
+		currency = s.baseCurrency
+	}
+	loan, err := domain.NewLoan(req.Name, req.Principal, currency, req.APR, req.MonthlyPayment, req.TermMonths, req.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loans.Create(ctx, loan); err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+// ListLoans returns the caller's loans
+func (s *LoanService) ListLoans(ctx context.Context) ([]*domain.Loan, error) {
+	return s.loans.List(ctx)
+}
+
+// GetLoan returns one of the caller's loans
+func (s *LoanService) GetLoan(ctx context.Context, id string) (*domain.Loan, error) {
+	return s.loans.GetByID(ctx, id)
+}
+
+// DeleteLoan removes a loan; its payments stay as ordinary expenses
+func (s *LoanService) DeleteLoan(ctx context.Context, id string) error {
+	return s.loans.Delete(ctx, id)
+}
+
+// AssignPayments links expenses to a loan as payments
+// A payment must be in the loan's currency, otherwise it can't be subtracted from the balance
+func (s *LoanService) AssignPayments(ctx context.Context, loanID string, req *AssignLoanPaymentsRequest) error {
+	loan, err := s.loans.GetByID(ctx, loanID)
+	if err != nil {
+		return err
+	}
+
+	// Step 1: Parse and de-duplicate the IDs
+	seen := make(map[uuid.UUID]bool, len(req.ExpenseIDs))
+	ids := make([]uuid.UUID, 0, len(req.ExpenseIDs))
+	for _, raw := range req.ExpenseIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return domain.ErrExpenseNotFound
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	// Step 2: Check every expense is the caller's and in the loan currency
+	for _, id := range ids {
+		expense, err := s.expenses.GetByID(ctx, id.String())
+		if err != nil {
+			return err
+		}
+		if expense.Currency != loan.Currency {
+			return domain.ErrInvalidLoanPayment
+		}
+	}
+
+	// Step 3: Link them all at once
+	return s.loans.AssignPayments(ctx, loan.ID, ids)
+}
+
+// RemovePayment unlinks a payment from a loan; the expense itself is kept
+func (s *LoanService) RemovePayment(ctx context.Context, loanID, expenseID string) error {
+	loan, err := s.loans.GetByID(ctx, loanID)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Parse(expenseID)
+	if err != nil {
+		return domain.ErrExpenseNotFound
+	}
+	return s.loans.RemovePayment(ctx, loan.ID, id)
+}
+
+// Amortization applies the loan's recorded payments and projects the rest of the schedule,
+// giving the remaining balance and the projected payoff date
+func (s *LoanService) Amortization(ctx context.Context, id string) (*LoanAmortization, error) {
+	loan, err := s.loans.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.expenses.GetAll(ctx, map[string]interface{}{"loan_id": loan.ID.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load loan payments: %w", err)
+	}
+	return &LoanAmortization{Loan: loan, Amortization: loan.Amortize(payments)}, nil
+}
+
+// Document converts the amortization into a renderable document
+// Recorded payments and projected instalments share one table; the kind column tells them apart
+func (r *LoanAmortization) Document() *report.Document {
+	loan := r.Loan
+	summary := []report.Field{
+		{Key: "loan", Label: "Loan", Kind: report.KindText, Value: loan.Name},
+		{Key: "principal", Label: "Principal (" + loan.Currency + ")", Kind: report.KindAmount, Value: loan.Principal},
+		{Key: "apr", Label: "APR", Kind: report.KindPercent, Value: loan.APR},
+		{Key: "monthly_payment", Label: "Monthly payment", Kind: report.KindAmount, Value: loan.MonthlyPayment},
+		{Key: "principal_paid", Label: "Principal paid", Kind: report.KindAmount, Value: r.PrincipalPaid},
+		{Key: "interest_paid", Label: "Interest paid", Kind: report.KindAmount, Value: r.InterestPaid},
+		{Key: "balance", Label: "Remaining balance", Kind: report.KindAmount, Value: r.Balance},
+		{Key: "projected_interest", Label: "Projected interest", Kind: report.KindAmount, Value: r.ProjectedInterest},
+	}
+	if r.PayoffDate != nil {
+		summary = append(summary, report.Field{Key: "payoff_date", Label: "Projected payoff", Kind: report.KindDate, Value: *r.PayoffDate})
+	}
+
+	rows := make([]report.Row, 0, len(r.Payments)+len(r.Schedule))
+	for _, row := range r.Payments {
+		rows = append(rows, report.Row{row.Date, "paid", row.Payment, row.Interest, row.Principal, row.Balance})
+	}
+	for _, row := range r.Schedule {
+		rows = append(rows, report.Row{row.Date, "projected", row.Payment, row.Interest, row.Principal, row.Balance})
+	}
+
+	return &report.Document{
+		Title:   "Amortization " + loan.Name,
+		Summary: summary,
+		Sections: []*report.Section{
+			{
+				Key:   "schedule",
+				Title: "Payments",
+				Columns: []report.Column{
+					{Key: "date", Title: "Date", Kind: report.KindDate},
+					{Key: "kind", Title: "Kind", Kind: report.KindText},
+					{Key: "payment", Title: "Payment", Kind: report.KindAmount},
+					{Key: "interest", Title: "Interest", Kind: report.KindAmount},
+					{Key: "principal", Title: "Principal", Kind: report.KindAmount},
+					{Key: "balance", Title: "Balance", Kind: report.KindAmount},
+				},
+				Rows: report.SliceRows(rows),
+			},
+		},
+	}
+}
@@ -0,0 +1,166 @@
+// Package application contains the business logic and use cases
+// This file throttles failed logins, to slow down password guessing and credential stuffing
+package application
+
+import (
+	"sync" // For guarding the attempt counters
+	"time" // For windows and lockouts
+
+	"myexpenses/internal/clock" // Time source for windows and lockouts
+)
+
+// maxTrackedLogins bounds how many emails and addresses are tracked at once
+// Stale entries are dropped first; when all of them are recent, the oldest is evicted
+const maxTrackedLogins = 10000
+
+// LoginThrottle configures how failed logins are limited
+// Failures are counted per account (the email tried) and per client IP address.
+// Too many failures for an account lock it for Lockout, whoever tries; too many from one
+// address block that address for Lockout, whichever accounts it tries
+type LoginThrottle struct {
+	// MaxAccountFailures is how many failed logins within Window lock an account (0 disables account lockout)
+	MaxAccountFailures int
+
+	// MaxAddressFailures is how many failed logins within Window block an address (0 disables address throttling)
+	MaxAddressFailures int
+
+	// Window is how long failures are remembered
+	Window time.Duration
+
+	// Lockout is how long an account or address stays blocked
+	Lockout time.Duration
+}
+
+// DefaultLoginThrottle allows 5 wrong passwords per account and 20 per address in 15 minutes
+var DefaultLoginThrottle = LoginThrottle{
+	MaxAccountFailures: 5,
+	MaxAddressFailures: 20,
+	Window:             15 * time.Minute,
+	Lockout:            15 * time.Minute,
+}
+
+// LoginBlockedError is returned for a login refused without checking the password
+// Err is domain.ErrAccountLocked or domain.ErrTooManyLoginAttempts
+type LoginBlockedError struct {
+	Err error
+
+	// RetryAfter is how long until the next attempt is accepted
+	RetryAfter time.Duration
+}
+
+// Error returns the message of the underlying error
+func (e *LoginBlockedError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap lets errors.Is match the underlying error
+func (e *LoginBlockedError) Unwrap() error {
+	return e.Err
+}
+
+// loginFailures are the recent failures of one account or address
+type loginFailures struct {
+	count       int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// loginAttempts counts failed logins in memory
+// Like the query cache it is per process: every API instance counts on its own and a restart forgets
+type loginAttempts struct {
+	clock   clock.Clock
+	window  time.Duration
+	lockout time.Duration
+	mu      sync.Mutex
+	entries map[string]*loginFailures
+}
+
+// newLoginAttempts creates an empty counter
+func newLoginAttempts(window, lockout time.Duration, clk clock.Clock) *loginAttempts {
+	return &loginAttempts{
+		clock:   clock.Or(clk),
+		window:  window,
+		lockout: lockout,
+		entries: make(map[string]*loginFailures),
+	}
+}
+
+// blocked returns how long key is still locked (0 if it isn't)
+func (a *loginAttempts) blocked(key string) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry, ok := a.entries[key]
+	if !ok {
+		return 0
+	}
+	if wait := entry.lockedUntil.Sub(a.clock.Now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// fail records a failed login for key and returns how long it is now locked (0 if max isn't reached yet)
+func (a *loginAttempts) fail(key string, max int) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.clock.Now()
+	if _, tracked := a.entries[key]; !tracked && len(a.entries) >= maxTrackedLogins {
+		a.prune(now)
+		if len(a.entries) >= maxTrackedLogins {
+			a.evict(now)
+		}
+	}
+
+	// Step 1: Start counting afresh once the window (or a lockout) is over
+	entry, ok := a.entries[key]
+	if !ok || now.Sub(entry.windowStart) >= a.window || (!entry.lockedUntil.IsZero() && !now.Before(entry.lockedUntil)) {
+		entry = &loginFailures{windowStart: now}
+		a.entries[key] = entry
+	}
+
+	// Step 2: Lock once there were too many failures
+	entry.count++
+	if entry.count >= max {
+		entry.lockedUntil = now.Add(a.lockout)
+		return a.lockout
+	}
+	return 0
+}
+
+// reset forgets the failures of key, after a successful login
+func (a *loginAttempts) reset(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.entries, key)
+}
+
+// evict drops the entry whose window started first among those that aren't locked, so spraying
+// unique emails or addresses can't grow the map past maxTrackedLogins; callers hold mu
+// Lockouts are only cut short, the one ending first, when every entry is locked
+func (a *loginAttempts) evict(now time.Time) {
+	var oldest, soonest string
+	for key, entry := range a.entries {
+		if now.Before(entry.lockedUntil) {
+			if soonest == "" || entry.lockedUntil.Before(a.entries[soonest].lockedUntil) {
+				soonest = key
+			}
+			continue
+		}
+		if oldest == "" || entry.windowStart.Before(a.entries[oldest].windowStart) {
+			oldest = key
+		}
+	}
+	if oldest == "" {
+		oldest = soonest
+	}
+	delete(a.entries, oldest)
+}
+
+// prune drops the entries that are neither locked nor within their window; callers hold mu
+func (a *loginAttempts) prune(now time.Time) {
+	for key, entry := range a.entries {
+		if now.Sub(entry.windowStart) >= a.window && !now.Before(entry.lockedUntil) {
+			delete(a.entries, key)
+		}
+	}
+}
@@ -0,0 +1,520 @@
+// Package application contains the business logic and use cases
+// This file contains the migration assistant, which moves a new user's history over from another expense app
+package application
+
+import (
+	"bytes"        // For looking at the first line of an export
+	"context"      // For request context (cancellation, timeouts)
+	"encoding/csv" // For reading exports
+	"errors"       // For telling the end of an export apart from read errors
+	"fmt"          // For formatted string operations and error wrapping
+	"io"           // For reading uploads
+	"math"         // For rounding totals to cents
+	"sort"         // For listing created categories in a stable order
+	"strings"      // For matching categories and descriptions
+	"time"         // For handling dates and times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// MigrationService analyzes exports of other expense apps and migrates them
+// Migrating takes two steps with the same file: Analyze says which app the export comes from,
+// how its categories would be mapped and what would happen, without changing anything; Commit
+// then does it, with the mappings the user corrected. Nothing is kept between the two steps
+type MigrationService struct {
+	expenses   domain.Repository
+	categories domain.CategoryRepository
+	history    domain.CategoryHistoryRepository
+	normalizer *DescriptionNormalizer
+	converter  *CurrencyConverter
+	transactor domain.Transactor
+}
+
+// NewMigrationService creates a new migration service
+// history is what categories are suggested from; normalizer and converter are the ones imports use
+func NewMigrationService(expenses domain.Repository, categories domain.CategoryRepository, history domain.CategoryHistoryRepository, normalizer *DescriptionNormalizer, converter *CurrencyConverter, transactor domain.Transactor) *MigrationService {
+	return &MigrationService{
+		expenses:   expenses,
+		categories: categories,
+		history:    history,
+		normalizer: normalizer,
+		converter:  converter,
+		transactor: transactor,
+	}
+}
+
+// MigrationRequest is an uploaded export
+type MigrationRequest struct {
+	// Content is the export, a CSV file
+	Content io.Reader
+
+	// Source is the ID of the app it comes from; empty detects it from the columns
+	Source string
+
+	// Mappings override the proposed mappings, from a category of the export to one to file its
+	// expenses under; categories that don't exist yet are created. Only Commit uses them
+	Mappings map[string]string
+}
+
+// CategoryMapping is where the expenses of one category of an export go
+type CategoryMapping struct {
+	// From is the category in the other app ("" for rows without one)
+	From string `json:"from"`
+
+	// To is the category the expenses get
+	To string `json:"to"`
+
+	// Match is how To was found (one of the domain.Mapping* kinds)
+	Match string `json:"match"`
+
+	// Expenses is how many expenses of the export have the category
+	Expenses int `json:"expenses"`
+}
+
+// MigrationProblem is a row of an export that can't be migrated
+type MigrationProblem struct {
+	Line    int    `json:"line"`
+	Problem string `json:"problem"`
+}
+
+// MigrationOutcome is what a migration does, or would do
+type MigrationOutcome struct {
+	// Expenses is how many expenses are created
+	Expenses int `json:"expenses"`
+
+	// Skipped counts the rows that don't become expenses by reason (see the domain.MigrationSkip* reasons)
+	Skipped map[string]int `json:"skipped"`
+
+	// CategoriesCreated are the categories that don't exist yet and are created
+	CategoriesCreated []string `json:"categories_created"`
+
+	// From and To are the dates of the first and last expense
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+
+	// Totals are the sums of the expenses by currency
+	Totals map[string]float64 `json:"totals"`
+}
+
+// MigrationAnalysis is what Analyze found out about an export
+type MigrationAnalysis struct {
+	Source *domain.MigrationSource `json:"source"`
+
+	// Confidence is how sure the detection of the source is, from 0 to 1 (1 when the source was given)
+	Confidence float64 `json:"confidence"`
+
+	// DateFormat is the format of the export's dates, in Go's notation (02/01/2006 is day first)
+	DateFormat string `json:"date_format"`
+
+	// Rows is how many rows the export has, not counting the header
+	Rows int `json:"rows"`
+
+	Categories []*CategoryMapping `json:"categories"`
+
+	// Problems are the first rows that can't be migrated, with what's wrong with them
+	Problems []*MigrationProblem `json:"problems"`
+
+	// Expected is what committing the migration would do
+	Expected *MigrationOutcome `json:"expected"`
+}
+
+// MigrationResult is what Commit did
+type MigrationResult struct {
+	Source     *domain.MigrationSource `json:"source"`
+	Categories []*CategoryMapping      `json:"categories"`
+	Result     *MigrationOutcome       `json:"result"`
+}
+
+// migrationPlan is an export read and mapped, ready to be reported or committed
+type migrationPlan struct {
+	analysis *MigrationAnalysis
+
+	// rows are the rows that become expenses, and normalized their normalized descriptions
+	rows       []*domain.MigrationRow
+	normalized []string
+
+	// existing are the names of the caller's categories
+	existing []string
+}
+
+// Analyze reads an export and reports what migrating it would do, without changing anything
+func (s *MigrationService) Analyze(ctx context.Context, req *MigrationRequest) (*MigrationAnalysis, error) {
+	plan, err := s.plan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return plan.analysis, nil
+}
+
+// Commit migrates an export: it creates the missing categories and an expense for every row
+// that is one, in one transaction. Expenses that already exist are skipped, so committing the
+// same export twice is safe
+func (s *MigrationService) Commit(ctx context.Context, req *MigrationRequest) (*MigrationResult, error) {
+	// Step 1: Plan the migration again and apply the user's mappings
+	plan, err := s.plan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := plan.override(req.Mappings); err != nil {
+		return nil, err
+	}
+	plan.analysis.Expected.CategoriesCreated = plan.newCategories()
+	targets := make(map[string]string, len(plan.analysis.Categories))
+	for _, mapping := range plan.analysis.Categories {
+		targets[mapping.From] = mapping.To
+	}
+
+	// Step 2: Create the categories and expenses
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, name := range plan.analysis.Expected.CategoriesCreated {
+			category, err := domain.NewCategory(name, nil)
+			if err != nil {
+				return err
+			}
+			if err := s.categories.Create(ctx, category); err != nil {
+				return fmt.Errorf("failed to create category %q: %w", name, err)
+			}
+		}
+
+		for i, row := range plan.rows {
+			expense, err := domain.NewExpense(row.Description, domain.NewMoney(row.Amount, ""), targets[row.Category], row.Date)
+			if err != nil {
+				return fmt.Errorf("invalid row %d: %w", row.Line, err)
+			}
+			expense.Source = domain.SourceMigration
+			expense.NormalizedDescription = plan.normalized[i]
+			if err := s.converter.Apply(ctx, expense, ConversionInput{Currency: row.Currency}); err != nil {
+				return fmt.Errorf("invalid row %d: %w", row.Line, err)
+			}
+			if err := s.expenses.Create(ctx, expense); err != nil {
+				return fmt.Errorf("failed to save migrated expense: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MigrationResult{
+		Source:     plan.analysis.Source,
+		Categories: plan.analysis.Categories,
+		Result:     plan.analysis.Expected,
+	}, nil
+}
+
+// plan reads an export, sorts out the rows that don't become expenses and maps its categories
+func (s *MigrationService) plan(ctx context.Context, req *MigrationRequest) (*migrationPlan, error) {
+	// Step 1: Read the export
+	header, records, lines, err := readMigrationCSV(req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Work out where it comes from
+	analysis := &MigrationAnalysis{
+		Rows:       len(records),
+		Categories: []*CategoryMapping{},
+		Problems:   []*MigrationProblem{},
+		Expected: &MigrationOutcome{
+			Skipped:           map[string]int{},
+			CategoriesCreated: []string{},
+			Totals:            map[string]float64{},
+		},
+	}
+	if req.Source != "" {
+		analysis.Source, err = domain.MigrationSourceByID(req.Source)
+		analysis.Confidence = 1
+	} else {
+		analysis.Source, analysis.Confidence, err = domain.DetectMigrationSource(header)
+	}
+	if err != nil {
+		return nil, err
+	}
+	reader, err := domain.NewMigrationReader(analysis.Source, header, records)
+	if err != nil {
+		return nil, err
+	}
+	analysis.DateFormat = reader.DateLayout()
+
+	// Step 3: Read the rows; invalid ones are listed, the others that aren't expenses counted
+	plan := &migrationPlan{analysis: analysis}
+	for i, record := range records {
+		row := reader.Read(lines[i], record)
+		if row.Skip == "" {
+			if _, err := domain.NewExpense(row.Description, domain.NewMoney(row.Amount, ""), domain.UncategorizedCategory, row.Date); err != nil {
+				row.Skip, row.Problem = domain.MigrationSkipInvalid, err.Error()
+			}
+		}
+		if row.Skip != "" {
+			analysis.Expected.Skipped[row.Skip]++
+			if row.Skip == domain.MigrationSkipInvalid && len(analysis.Problems) < domain.MaxMigrationProblems {
+				analysis.Problems = append(analysis.Problems, &MigrationProblem{Line: row.Line, Problem: row.Problem})
+			}
+			continue
+		}
+		normalized, err := s.normalizer.Normalize(ctx, row.Description)
+		if err != nil {
+			return nil, err
+		}
+		plan.rows = append(plan.rows, row)
+		plan.normalized = append(plan.normalized, normalized)
+	}
+
+	// Step 4: Leave out the expenses that already exist
+	if err := s.skipDuplicates(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	// Step 5: Map the categories
+	if err := s.mapCategories(ctx, plan); err != nil {
+		return nil, err
+	}
+	analysis.Expected.CategoriesCreated = plan.newCategories()
+
+	// Step 6: Sum up what would be created
+	for _, row := range plan.rows {
+		analysis.Expected.Expenses++
+		currency := row.Currency
+		if currency == "" {
+			currency = s.converter.BaseCurrency()
+		}
+		analysis.Expected.Totals[currency] = math.Round((analysis.Expected.Totals[currency]+row.Amount)*100) / 100
+		date := row.Date
+		if analysis.Expected.From == nil || date.Before(*analysis.Expected.From) {
+			analysis.Expected.From = &date
+		}
+		if analysis.Expected.To == nil || date.After(*analysis.Expected.To) {
+			analysis.Expected.To = &date
+		}
+	}
+	return plan, nil
+}
+
+// skipDuplicates removes the rows that match an existing expense from the plan
+// Like imports without transaction IDs, a row matches an expense of the same day with the same
+// amount and normalized description; every existing expense matches at most one row, so two
+// identical coffees on a day are only skipped when both were migrated before
+func (s *MigrationService) skipDuplicates(ctx context.Context, plan *migrationPlan) error {
+	if len(plan.rows) == 0 {
+		return nil
+	}
+
+	// Step 1: Count the existing expenses in the export's date range
+	from, to := plan.rows[0].Date, plan.rows[0].Date
+	for _, row := range plan.rows {
+		if row.Date.Before(from) {
+			from = row.Date
+		}
+		if row.Date.After(to) {
+			to = row.Date
+		}
+	}
+	existing, err := s.expenses.GetAll(ctx, map[string]interface{}{
+		"date_from": from.Truncate(24 * time.Hour).Format(time.RFC3339),
+		"date_to":   to.Truncate(24 * time.Hour).Add(24*time.Hour - time.Nanosecond).Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check for existing expenses: %w", err)
+	}
+	counts := make(map[string]int, len(existing))
+	for _, expense := range existing {
+		normalized := expense.NormalizedDescription
+		if normalized == "" {
+			if normalized, err = s.normalizer.Normalize(ctx, expense.Description); err != nil {
+				return err
+			}
+		}
+		counts[duplicateKey(expense.Date, expense.Amount, normalized)]++
+	}
+
+	// Step 2: Drop the rows that match one
+	rows, normalized := plan.rows[:0], plan.normalized[:0]
+	for i, row := range plan.rows {
+		key := duplicateKey(row.Date, domain.NewMoney(row.Amount, ""), plan.normalized[i])
+		if counts[key] > 0 {
+			counts[key]--
+			plan.analysis.Expected.Skipped[domain.MigrationSkipDuplicate]++
+			continue
+		}
+		rows = append(rows, row)
+		normalized = append(normalized, plan.normalized[i])
+	}
+	plan.rows, plan.normalized = rows, normalized
+	return nil
+}
+
+// duplicateKey identifies an expense for finding duplicates
+func duplicateKey(date time.Time, amount domain.Money, normalized string) string {
+	return fmt.Sprintf("%s|%s|%s", date.Format("2006-01-02"), amount, strings.ToLower(normalized))
+}
+
+// mapCategories proposes a category for every category of the export
+// In order: an existing category of the same name or one it is a common alias of; the category
+// the caller's past expenses with the same descriptions mostly have; a new category of the
+// same name. Rows without a category get a suggested category or stay Uncategorized
+func (s *MigrationService) mapCategories(ctx context.Context, plan *migrationPlan) error {
+	// Step 1: The caller's categories and what their expenses teach
+	categories, err := s.categories.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list categories: %w", err)
+	}
+	for _, category := range categories {
+		if !category.Archived() {
+			plan.existing = append(plan.existing, category.Name)
+		}
+	}
+	history, err := s.history.CategoryHistory(ctx, CategoryHistorySize)
+	if err != nil {
+		return fmt.Errorf("failed to load category history: %w", err)
+	}
+	model := domain.TrainCategoryModel(history)
+
+	// Step 2: Group the rows by category, in the order the categories first appear
+	groups := map[string][]int{}
+	for i, row := range plan.rows {
+		if _, ok := groups[row.Category]; !ok {
+			plan.analysis.Categories = append(plan.analysis.Categories, &CategoryMapping{From: row.Category})
+		}
+		groups[row.Category] = append(groups[row.Category], i)
+	}
+
+	// Step 3: Map every category
+	for _, mapping := range plan.analysis.Categories {
+		rows := groups[mapping.From]
+		mapping.Expenses = len(rows)
+		if to, match := domain.MatchCategory(mapping.From, plan.existing); match != "" {
+			mapping.To, mapping.Match = to, match
+			continue
+		}
+		if suggested := suggestMigrationCategory(model, plan, rows); suggested != "" {
+			mapping.To, mapping.Match = suggested, domain.MappingSuggested
+			continue
+		}
+		if mapping.From == "" {
+			mapping.To, mapping.Match = domain.UncategorizedCategory, domain.MappingUncategorized
+			continue
+		}
+		mapping.To, mapping.Match = mapping.From, domain.MappingNew
+	}
+	return nil
+}
+
+// suggestMigrationCategory returns the category the model confidently suggests for most of
+// the rows, or "" when there is none
+func suggestMigrationCategory(model *domain.CategoryModel, plan *migrationPlan, rows []int) string {
+	if model.Empty() {
+		return ""
+	}
+	votes := map[string]int{}
+	for _, i := range rows {
+		suggestions := model.Suggest(plan.normalized[i], plan.rows[i].Amount, 1)
+		if len(suggestions) > 0 && suggestions[0].Confidence >= 0.5 {
+			votes[suggestions[0].Category]++
+		}
+	}
+	for category, count := range votes {
+		if count*2 > len(rows) {
+			return category
+		}
+	}
+	return ""
+}
+
+// override applies the user's mappings, from a category of the export to a target category
+func (p *migrationPlan) override(mappings map[string]string) error {
+	byFrom := make(map[string]*CategoryMapping, len(p.analysis.Categories))
+	for _, mapping := range p.analysis.Categories {
+		byFrom[mapping.From] = mapping
+	}
+	for from, to := range mappings {
+		mapping, ok := byFrom[from]
+		to = strings.TrimSpace(to)
+		if !ok || to == "" {
+			return fmt.Errorf("%w: %q", domain.ErrInvalidMigrationMapping, from)
+		}
+		// Existing categories keep their spelling
+		if existing, match := domain.MatchCategory(to, p.existing); match == domain.MappingExact {
+			to = existing
+		}
+		mapping.To, mapping.Match = to, domain.MappingManual
+	}
+	return nil
+}
+
+// newCategories returns the targets of the mappings that don't exist yet, which are created
+// Uncategorized isn't one: unrecognized imports are filed under it without a category to match
+func (p *migrationPlan) newCategories() []string {
+	seen := map[string]bool{}
+	for _, name := range p.existing {
+		seen[strings.ToLower(name)] = true
+	}
+	created := []string{}
+	for _, mapping := range p.analysis.Categories {
+		key := strings.ToLower(mapping.To)
+		if mapping.Match == domain.MappingUncategorized || seen[key] {
+			continue
+		}
+		seen[key] = true
+		created = append(created, mapping.To)
+	}
+	sort.Strings(created)
+	return created
+}
+
+// readMigrationCSV reads an export into its header, its rows and the line each row starts on
+// The separator is whichever of comma, semicolon and tab the header line has most of, since
+// spreadsheet apps in many countries write semicolons. Blank rows are left out
+func readMigrationCSV(content io.Reader) ([]string, [][]string, []int, error) {
+	// Step 1: Read the whole export, up to the limit
+	data, err := io.ReadAll(io.LimitReader(content, domain.MaxMigrationBytes+1))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	if len(data) > domain.MaxMigrationBytes {
+		return nil, nil, nil, domain.ErrMigrationTooLarge
+	}
+
+	// Step 2: Guess the separator from the header line
+	first := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		first = data[:i]
+	}
+	separator := ','
+	for _, candidate := range []rune{';', '\t'} {
+		if bytes.Count(first, []byte(string(candidate))) > bytes.Count(first, []byte(string(separator))) {
+			separator = candidate
+		}
+	}
+
+	// Step 3: Read the rows
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = separator
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %v", domain.ErrUnrecognizedExport, err)
+	}
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: %v", domain.ErrUnrecognizedExport, err)
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		if len(records) == domain.MaxMigrationRows {
+			return nil, nil, nil, domain.ErrMigrationTooLarge
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return header, records, lines, nil
+}
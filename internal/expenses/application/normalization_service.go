@@ -0,0 +1,144 @@
+// Package application contains the business logic and use cases
+// This file contains the description normalization pipeline and its management use cases
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For cleaning up user input
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// maxSuggestions caps the number of autocomplete entries returned at once
+const maxSuggestions = 20
+
+// DescriptionNormalizer runs descriptions through the configured normalization steps
+type DescriptionNormalizer struct {
+	rules domain.NormalizationRuleRepository
+	steps []string
+}
+
+// NewDescriptionNormalizer creates a normalizer running the named steps in order
+// An empty list uses domain.DefaultNormalizationSteps
+func NewDescriptionNormalizer(rules domain.NormalizationRuleRepository, steps []string) (*DescriptionNormalizer, error) {
+	if len(steps) == 0 {
+		steps = domain.DefaultNormalizationSteps
+	}
+
+	// Validate the configuration up front so typos fail at startup, not on the first import
+	for _, step := range steps {
+		if _, ok := domain.BuiltinNormalizationSteps[step]; !ok && step != domain.StepUserRules {
+			return nil, fmt.Errorf("%w: %q", domain.ErrUnknownNormalizationStep, step)
+		}
+	}
+
+	return &DescriptionNormalizer{rules: rules, steps: steps}, nil
+}
+
+// Normalize returns the normalized form of a description
+func (n *DescriptionNormalizer) Normalize(ctx context.Context, description string) (string, error) {
+	for _, step := range n.steps {
+		if step == domain.StepUserRules {
+			// User rules are loaded on every call so changes apply immediately
+			rules, err := n.rules.List(ctx)
+			if err != nil {
+				return "", fmt.Errorf("failed to load normalization rules: %w", err)
+			}
+			for _, rule := range rules {
+				description = rule.Apply(description)
+			}
+			continue
+		}
+		description = domain.BuiltinNormalizationSteps[step](description)
+	}
+	return description, nil
+}
+
+// NormalizationService manages replacement rules and serves description autocomplete
+type NormalizationService struct {
+	normalizer   *DescriptionNormalizer
+	rules        domain.NormalizationRuleRepository
+	autocomplete domain.AutocompleteRepository
+}
+
+// NewNormalizationService creates a new normalization management service
+func NewNormalizationService(normalizer *DescriptionNormalizer, rules domain.NormalizationRuleRepository, autocomplete domain.AutocompleteRepository) *NormalizationService {
+	return &NormalizationService{
+		normalizer:   normalizer,
+		rules:        rules,
+		autocomplete: autocomplete,
+	}
+}
+
+// CreateNormalizationRuleRequest represents the request body for POST /normalization-rules
+type CreateNormalizationRuleRequest struct {
+	// Find is the text to replace (case-insensitive)
+	Find string `json:"find" binding:"required"`
+
+	// Replace is the replacement text (may be empty)
+	Replace string `json:"replace"`
+
+	// Position orders the rules; lower runs first
+	Position int `json:"position"`
+}
+
+// PreviewNormalizationRequest represents the request body for POST /normalization-rules/preview
+type PreviewNormalizationRequest struct {
+	// Description is the text to run through the pipeline
+	Description string `json:"description" binding:"required"`
+}
+
+// ListRules returns all replacement rules in application order
+func (s *NormalizationService) ListRules(ctx context.Context) ([]*domain.NormalizationRule, error) {
+	rules, err := s.rules.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list normalization rules: %w", err)
+	}
+	return rules, nil
+}
+
+// CreateRule adds a replacement rule
+func (s *NormalizationService) CreateRule(ctx context.Context, req *CreateNormalizationRuleRequest) (*domain.NormalizationRule, error) {
+	rule, err := domain.NewNormalizationRule(req.Find, req.Replace, req.Position)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rules.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to save normalization rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteRule removes a replacement rule
+func (s *NormalizationService) DeleteRule(ctx context.Context, id string) error {
+	return s.rules.Delete(ctx, id)
+}
+
+// Preview shows what the pipeline would make of a description without saving anything
+// This lets users test a new rule before importing a statement
+func (s *NormalizationService) Preview(ctx context.Context, req *PreviewNormalizationRequest) (string, error) {
+	return s.normalizer.Normalize(ctx, req.Description)
+}
+
+// SuggestDescriptions returns autocomplete entries for a typed prefix
+// The prefix is normalized the same way stored descriptions are, so "Café" finds "CAFE"
+func (s *NormalizationService) SuggestDescriptions(ctx context.Context, prefix string, limit int) ([]*domain.DescriptionSuggestion, error) {
+	if limit <= 0 || limit > maxSuggestions {
+		limit = maxSuggestions
+	}
+
+	normalized, err := s.normalizer.Normalize(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(normalized) == "" {
+		return []*domain.DescriptionSuggestion{}, nil
+	}
+
+	suggestions, err := s.autocomplete.SuggestDescriptions(ctx, normalized, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest descriptions: %w", err)
+	}
+	return suggestions, nil
+}
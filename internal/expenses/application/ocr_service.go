@@ -0,0 +1,155 @@
+// Package application contains the business logic and use cases
+// This file contains receipt recognition with each tenant's choice of OCR provider and languages
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching repository errors
+	"io"      // For streaming attachment content
+	"log"     // For reporting usage that couldn't be recorded
+	"sort"    // For listing providers and languages in a stable order
+	"strings" // For checking content types
+
+	"myexpenses/internal/auth"            // The tenant a request is made in
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/metrics"         // Per-provider call metrics
+)
+
+// ocrComponent is the component label of OCR metrics; the method label is the provider
+const ocrComponent = "ocr"
+
+// OCRService recognizes receipts with the provider and languages the caller's tenant chose
+// It implements domain.TextExtractor, so attachments and the receipt inbox use it like any
+// extractor. Every recognition is counted per tenant, provider and month for GET /admin/ocr/usage,
+// and timed per provider for GET /metrics
+type OCRService struct {
+	repo      domain.OCRRepository
+	providers map[string]domain.OCRProvider
+	defaults  *domain.OCRSettings
+	recorder  metrics.Recorder
+	clock     clock.Clock
+}
+
+// NewOCRService creates a new OCR service
+// providers are the providers this deployment runs, by name; defaults are the settings of
+// tenants that haven't chosen, and the fallback when a tenant's provider is no longer available
+func NewOCRService(repo domain.OCRRepository, providers map[string]domain.OCRProvider, defaults *domain.OCRSettings, recorder metrics.Recorder, clk clock.Clock) *OCRService {
+	if recorder == nil {
+		recorder = metrics.Nop{}
+	}
+	return &OCRService{
+		repo:      repo,
+		providers: providers,
+		defaults:  defaults,
+		recorder:  recorder,
+		clock:     clock.Or(clk),
+	}
+}
+
+// OCRSettingsRequest represents the request to set a tenant's OCR settings
+type OCRSettingsRequest struct {
+	Provider  string   `json:"provider" binding:"required"`
+	Languages []string `json:"languages" binding:"required"`
+}
+
+// OCROptions lists what a tenant can choose from
+type OCROptions struct {
+	// Providers are the providers this deployment runs
+	Providers []string `json:"providers"`
+
+	// Languages are the supported language codes; a region may be added, e.g. "en-GB"
+	Languages []string `json:"languages"`
+}
+
+// Options returns the providers and languages tenants can choose from
+func (s *OCRService) Options() *OCROptions {
+	options := &OCROptions{Providers: []string{}, Languages: domain.OCRLanguages()}
+	for name := range s.providers {
+		options.Providers = append(options.Providers, name)
+	}
+	sort.Strings(options.Providers)
+	sort.Strings(options.Languages)
+	return options
+}
+
+// Settings returns the OCR settings of the caller's tenant, or the defaults if it hasn't set its own
+func (s *OCRService) Settings(ctx context.Context) (*domain.OCRSettings, error) {
+	tenantID := auth.TenantID(ctx)
+	settings, err := s.repo.GetSettings(ctx, tenantID)
+	if errors.Is(err, domain.ErrOCRSettingsNotFound) {
+		defaults := *s.defaults
+		defaults.TenantID = tenantID
+		return &defaults, nil
+	}
+	return settings, err
+}
+
+// UpdateSettings replaces the OCR settings of the caller's tenant
+// Only providers this deployment runs can be chosen
+func (s *OCRService) UpdateSettings(ctx context.Context, req *OCRSettingsRequest) (*domain.OCRSettings, error) {
+	settings, err := domain.NewOCRSettings(auth.TenantID(ctx), req.Provider, req.Languages)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.providers[settings.Provider]; !ok {
+		return nil, domain.ErrOCRProviderUnavailable
+	}
+	if err := s.repo.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// ResetSettings removes the OCR settings of the caller's tenant, so it gets the defaults again
+func (s *OCRService) ResetSettings(ctx context.Context) error {
+	return s.repo.DeleteSettings(ctx, auth.TenantID(ctx))
+}
+
+// Usage returns the caller's tenant's recognitions per provider and month, most recent first
+func (s *OCRService) Usage(ctx context.Context) ([]*domain.OCRUsage, error) {
+	return s.repo.ListUsage(ctx, auth.TenantID(ctx))
+}
+
+// ExtractText implements domain.TextExtractor with the tenant's provider and languages
+// A deployment without providers recognizes nothing, like the no-op extractor
+func (s *OCRService) ExtractText(ctx context.Context, contentType string, r io.Reader) (string, error) {
+	// Step 1: Pick the provider; one the deployment stopped running falls back to the default
+	// Only images and PDFs carry text, so nothing else is sent anywhere or counted
+	if !strings.HasPrefix(contentType, "image/") && contentType != "application/pdf" {
+		return "", nil
+	}
+	settings, err := s.Settings(ctx)
+	if err != nil {
+		return "", err
+	}
+	name := settings.Provider
+	provider, ok := s.providers[name]
+	if !ok {
+		name = s.defaults.Provider
+		if provider, ok = s.providers[name]; !ok {
+			return "", nil
+		}
+	}
+
+	// Step 2: Recognize the text
+	start := s.clock.Now()
+	text, err := provider.Recognize(ctx, contentType, r, settings.Languages)
+	s.recorder.RecordCall(ocrComponent, name, s.clock.Now().Sub(start), 0, err)
+
+	// Step 3: Count it; losing a count is better than losing the text
+	usage := &domain.OCRUsage{
+		TenantID:   settings.TenantID,
+		Provider:   name,
+		Month:      s.clock.Now().UTC().Format("2006-01"),
+		Documents:  1,
+		Characters: int64(len([]rune(text))),
+	}
+	if err != nil {
+		usage.Failures = 1
+	}
+	if recordErr := s.repo.RecordUsage(ctx, usage); recordErr != nil {
+		log.Printf("failed to record OCR usage: %v", recordErr)
+	}
+	return text, err
+}
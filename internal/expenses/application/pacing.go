@@ -0,0 +1,212 @@
+// Package application contains the business logic and use cases
+// This file contains budget pacing: how spending so far compares to an even spread of the budget
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For matching category names
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// Pace values
+const (
+	// PaceAhead means more has been spent than an even spread of the budget allows by now
+	PaceAhead = "ahead"
+
+	// PaceOnTrack means spending is within PaceTolerance of the expected amount
+	PaceOnTrack = "on_track"
+
+	// PaceBehind means less has been spent than expected, leaving room for the rest of the month
+	PaceBehind = "behind"
+)
+
+// PaceTolerance is how far (as a fraction of the expected amount) spending may deviate and still be on track
+const PaceTolerance = 0.05
+
+// WeekPacing compares one week of the month with its share of the budget
+// Weeks are counted from the 1st of the month: days 1-7, 8-14, 15-21 and 22-end
+type WeekPacing struct {
+	Week     int          `json:"week"`
+	Start    time.Time    `json:"start"`
+	End      time.Time    `json:"end"`
+	Expected domain.Money `json:"expected"`
+	Spent    domain.Money `json:"spent"`
+}
+
+// BudgetPacing compares a budget's spending to date with an even spread of the budget
+type BudgetPacing struct {
+	Category string       `json:"category"`
+	Budget   domain.Money `json:"budget"`
+
+	// CarriedOver is what a rollover budget brings along from the previous months (negative
+	// after overspending); Available is Budget + CarriedOver, the amount the month is paced against
+	CarriedOver domain.Money `json:"carried_over"`
+	Available   domain.Money `json:"available"`
+
+	Spent          domain.Money `json:"spent"`
+	ExpectedToDate domain.Money `json:"expected_to_date"`
+
+	// Difference is Spent - ExpectedToDate: positive when ahead of pace
+	Difference domain.Money `json:"difference"`
+
+	// Pace is PaceAhead, PaceOnTrack or PaceBehind
+	Pace string `json:"pace"`
+
+	// PercentUsed is how much of the budget has been spent; over 100 when OverBudget
+	PercentUsed float64 `json:"percent_used"`
+	OverBudget  bool    `json:"over_budget"`
+
+	// Remaining and DailyAllowance tell the user what they can still spend
+	Remaining      domain.Money `json:"remaining"`
+	DailyAllowance domain.Money `json:"daily_allowance"`
+
+	Weeks []*WeekPacing `json:"weeks"`
+}
+
+// BudgetStatusReport is the pacing of every budget in one month
+type BudgetStatusReport struct {
+	Month       string          `json:"month"`
+	AsOf        time.Time       `json:"as_of"`
+	DaysElapsed int             `json:"days_elapsed"`
+	DaysInMonth int             `json:"days_in_month"`
+	Budgets     []*BudgetPacing `json:"budgets"`
+}
+
+// Status returns the pacing of every budget in month (YYYY-MM, defaults to the current month)
+// Past months are measured at their end and future months at their start
+func (s *BudgetService) Status(ctx context.Context, month string) (*BudgetStatusReport, error) {
+	// Step 1: Work out the month and how far into it we are
+	now := s.clock.Now().UTC()
+	start := domain.MonthStart(now)
+	if month != "" {
+		parsed, err := domain.ParseMonth(month)
+		if err != nil {
+			return nil, err
+		}
+		start = parsed
+	}
+	end := start.AddDate(0, 1, 0)
+	asOf := now
+	if asOf.Before(start) {
+		asOf = start
+	}
+	if asOf.After(end) {
+		asOf = end
+	}
+	daysInMonth := int(end.Sub(start).Hours() / 24)
+	elapsed := asOf.Sub(start).Hours() / 24
+	remainingDays := float64(daysInMonth) - elapsed
+
+	report := &BudgetStatusReport{
+		Month:       start.Format("2006-01"),
+		AsOf:        asOf,
+		DaysElapsed: int(elapsed),
+		DaysInMonth: daysInMonth,
+		Budgets:     []*BudgetPacing{},
+	}
+
+	// Step 2: Load the budgets that apply in the month and what rollover budgets carry into it
+	saved, err := s.budgets.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	budgets := domain.EffectiveBudgets(saved, start)
+	if len(budgets) == 0 {
+		return report, nil
+	}
+	carried, err := s.carryOver(ctx, saved, start)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3: Load each week's spending up to AsOf
+	type week struct {
+		start, end time.Time
+		spent      map[string]domain.Money
+	}
+	var weeks []*week
+	for weekStart := start; weekStart.Before(end); weekStart = weekStart.AddDate(0, 0, 7) {
+		weekEnd := weekStart.AddDate(0, 0, 7)
+		// The last week absorbs the days after the 28th
+		if weekEnd.AddDate(0, 0, 7).After(end) {
+			weekEnd = end
+		}
+		w := &week{start: weekStart, end: weekEnd, spent: make(map[string]domain.Money)}
+		weeks = append(weeks, w)
+
+		if weekStart.Before(asOf) {
+			to := weekEnd
+			if asOf.Before(to) {
+				to = asOf
+			}
+			rows, err := s.forecaster.spending.SpendingByCategory(ctx, weekStart, to)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load spending: %w", err)
+			}
+			for _, row := range rows {
+				key := strings.ToLower(row.Category)
+				w.spent[key] = w.spent[key].Add(row.Amount)
+			}
+		}
+		if weekEnd.Equal(end) {
+			break
+		}
+	}
+
+	// Step 4: Compare each budget with an even spread over the month
+	// Rollover budgets are paced against what is available after the carry-over
+	for _, budget := range budgets {
+		key := strings.ToLower(budget.Category)
+		available := budget.Amount.Add(carried[key])
+		paced := available
+		if paced.IsNegative() {
+			paced = domain.Money{}
+		}
+		pacing := &BudgetPacing{
+			Category:       budget.Category,
+			Budget:         budget.Amount,
+			CarriedOver:    carried[key],
+			Available:      available,
+			ExpectedToDate: paced.Mul(elapsed / float64(daysInMonth)),
+		}
+		for i, w := range weeks {
+			days := w.end.Sub(w.start).Hours() / 24
+			pacing.Spent = pacing.Spent.Add(w.spent[key])
+			pacing.Weeks = append(pacing.Weeks, &WeekPacing{
+				Week:     i + 1,
+				Start:    w.start,
+				End:      w.end.AddDate(0, 0, -1),
+				Expected: paced.Mul(days / float64(daysInMonth)),
+				Spent:    w.spent[key],
+			})
+		}
+		pacing.Difference = pacing.Spent.Sub(pacing.ExpectedToDate)
+		pacing.Pace = pace(pacing.Spent, pacing.ExpectedToDate)
+		pacing.Remaining = available.Sub(pacing.Spent)
+		pacing.OverBudget = pacing.Spent.Minor > available.Minor
+		if available.Minor > 0 {
+			pacing.PercentUsed = domain.RoundAmount(pacing.Spent.Float64() / available.Float64() * 100)
+		}
+		if remainingDays > 0 && pacing.Remaining.Minor > 0 {
+			pacing.DailyAllowance = pacing.Remaining.Mul(1 / remainingDays)
+		}
+		report.Budgets = append(report.Budgets, pacing)
+	}
+	return report, nil
+}
+
+// pace classifies spent against expected using PaceTolerance
+func pace(spent, expected domain.Money) string {
+	tolerance := expected.Mul(PaceTolerance)
+	switch {
+	case spent.Minor > expected.Add(tolerance).Minor:
+		return PaceAhead
+	case spent.Minor < expected.Sub(tolerance).Minor:
+		return PaceBehind
+	default:
+		return PaceOnTrack
+	}
+}
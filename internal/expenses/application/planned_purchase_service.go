@@ -0,0 +1,274 @@
+// Package application contains the business logic and use cases
+// This file contains planned purchases: estimates of coming purchases, their link to the
+// actual expenses, and the report on how accurate the estimates turned out to be
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For planned days
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/report"          // Shared report renderers
+)
+
+// PlannedPurchaseService records planned purchases and compares them with what they cost
+type PlannedPurchaseService struct {
+	purchases domain.PlannedPurchaseRepository
+	expenses  domain.Repository
+}
+
+// NewPlannedPurchaseService creates a new planned purchase service
+// expenses looks up the actual expenses linked to planned purchases
+func NewPlannedPurchaseService(purchases domain.PlannedPurchaseRepository, expenses domain.Repository) *PlannedPurchaseService {
+	return &PlannedPurchaseService{
+		purchases: purchases,
+		expenses:  expenses,
+	}
+}
+
+// CreatePlannedPurchaseRequest represents the request body for POST /planned-purchases
+type CreatePlannedPurchaseRequest struct {
+	Description string `json:"description" binding:"required"`
+	Category    string `json:"category"`
+
+	// EstimatedAmount is the expected cost in the home currency
+	// It must be greater than 0; NewPlannedPurchase rejects it otherwise
+	EstimatedAmount domain.Money `json:"estimated_amount"`
+
+	// PlannedFor is the day the purchase is planned for (optional)
+	PlannedFor *time.Time `json:"planned_for"`
+}
+
+// LinkPlannedPurchaseRequest represents the request body for POST /planned-purchases/{id}/link
+type LinkPlannedPurchaseRequest struct {
+	ExpenseID string `json:"expense_id" binding:"required"`
+}
+
+// PlannedPurchaseView is a planned purchase with its status and, once bought, how far off the estimate was
+type PlannedPurchaseView struct {
+	*domain.PlannedPurchase
+	Status string `json:"status"`
+
+	// Variance is actual minus estimate (nil while open); VariancePercent relates it to the estimate
+	Variance        *domain.Money `json:"variance,omitempty"`
+	VariancePercent *float64      `json:"variance_percent,omitempty"`
+}
+
+// newPlannedPurchaseView describes a planned purchase for the API
+func newPlannedPurchaseView(purchase *domain.PlannedPurchase) *PlannedPurchaseView {
+	view := &PlannedPurchaseView{PlannedPurchase: purchase, Status: purchase.Status()}
+	if purchase.Status() == domain.PlannedPurchaseBought {
+		variance, percent := purchase.Variance(), purchase.VariancePercent()
+		view.Variance, view.VariancePercent = &variance, &percent
+	}
+	return view
+}
+
+// CreatePlannedPurchase records a purchase the caller plans
+func (s *PlannedPurchaseService) CreatePlannedPurchase(ctx context.Context, req *CreatePlannedPurchaseRequest) (*PlannedPurchaseView, error) {
+	purchase, err := domain.NewPlannedPurchase(req.Description, req.Category, req.EstimatedAmount, req.PlannedFor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.purchases.Create(ctx, purchase); err != nil {
+		return nil, err
+	}
+	return newPlannedPurchaseView(purchase), nil
+}
+
+// ListPlannedPurchases returns the caller's planned purchases, optionally only the open or bought ones
+func (s *PlannedPurchaseService) ListPlannedPurchases(ctx context.Context, status string) ([]*PlannedPurchaseView, error) {
+	if status != "" && status != domain.PlannedPurchaseOpen && status != domain.PlannedPurchaseBought {
+		return nil, domain.ErrInvalidPlannedPurchase
+	}
+	purchases, err := s.purchases.List(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]*PlannedPurchaseView, len(purchases))
+	for i, purchase := range purchases {
+		views[i] = newPlannedPurchaseView(purchase)
+	}
+	return views, nil
+}
+
+// GetPlannedPurchase returns one of the caller's planned purchases
+func (s *PlannedPurchaseService) GetPlannedPurchase(ctx context.Context, id string) (*PlannedPurchaseView, error) {
+	purchase, err := s.purchases.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return newPlannedPurchaseView(purchase), nil
+}
+
+// LinkExpense records one of the caller's expenses as the actual purchase
+// The expense's amount is taken over as it is now; relink to pick up later corrections
+func (s *PlannedPurchaseService) LinkExpense(ctx context.Context, id string, req *LinkPlannedPurchaseRequest) (*PlannedPurchaseView, error) {
+	purchase, err := s.purchases.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.expenses.GetByID(ctx, req.ExpenseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := purchase.Link(expense); err != nil {
+		return nil, err
+	}
+	if err := s.purchases.UpdateLink(ctx, purchase); err != nil {
+		return nil, err
+	}
+	return newPlannedPurchaseView(purchase), nil
+}
+
+// UnlinkExpense makes a planned purchase open again
+func (s *PlannedPurchaseService) UnlinkExpense(ctx context.Context, id string) (*PlannedPurchaseView, error) {
+	purchase, err := s.purchases.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	purchase.Unlink()
+	if err := s.purchases.UpdateLink(ctx, purchase); err != nil {
+		return nil, err
+	}
+	return newPlannedPurchaseView(purchase), nil
+}
+
+// DeletePlannedPurchase removes one of the caller's planned purchases; a linked expense stays
+func (s *PlannedPurchaseService) DeletePlannedPurchase(ctx context.Context, id string) error {
+	return s.purchases.Delete(ctx, id)
+}
+
+// EstimateAccuracy sums the estimates and actual costs of the purchases bought in some stretch of time
+type EstimateAccuracy struct {
+	Count     int          `json:"count"`
+	Estimated domain.Money `json:"estimated"`
+	Actual    domain.Money `json:"actual"`
+
+	// Variance is actual minus estimated; positive when purchases cost more than expected
+	Variance domain.Money `json:"variance"`
+
+	// MeanAbsoluteError is the average deviation from the estimate in percent, in either direction
+	MeanAbsoluteError float64 `json:"mean_absolute_error"`
+
+	// Over, Under and Exact count the purchases that cost more, less and exactly as estimated
+	Over  int `json:"over"`
+	Under int `json:"under"`
+	Exact int `json:"exact"`
+
+	absoluteErrors float64
+}
+
+// add counts a bought purchase
+func (a *EstimateAccuracy) add(purchase *domain.PlannedPurchase) {
+	a.Count++
+	a.Estimated = a.Estimated.Add(purchase.EstimatedAmount)
+	a.Actual = a.Actual.Add(purchase.ActualAmount)
+	a.Variance = a.Actual.Sub(a.Estimated)
+	a.absoluteErrors += purchase.AbsoluteError()
+	a.MeanAbsoluteError = domain.RoundAmount(a.absoluteErrors / float64(a.Count))
+	switch variance := purchase.Variance(); {
+	case variance.Minor > 0:
+		a.Over++
+	case variance.IsNegative():
+		a.Under++
+	default:
+		a.Exact++
+	}
+}
+
+// MonthAccuracy is the estimate accuracy of the purchases bought in one month
+type MonthAccuracy struct {
+	// Month is YYYY-MM
+	Month string `json:"month"`
+	EstimateAccuracy
+}
+
+// EstimateAccuracyReport compares the estimates of the purchases bought in a period with their actual cost,
+// in total and month by month, so the user sees whether their estimates get better over time
+type EstimateAccuracyReport struct {
+	Period domain.Period `json:"period"`
+	EstimateAccuracy
+	Months    []*MonthAccuracy       `json:"months"`
+	Purchases []*PlannedPurchaseView `json:"purchases"`
+}
+
+// AccuracyReport builds the estimate accuracy report of the caller's purchases bought in a period
+func (s *PlannedPurchaseService) AccuracyReport(ctx context.Context, period string) (*EstimateAccuracyReport, error) {
+	p, err := domain.ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	bought, err := s.purchases.ListBought(ctx, p.Start, p.End)
+	if err != nil {
+		return nil, err
+	}
+
+	// Purchases come oldest first, so each month's purchases are adjacent
+	result := &EstimateAccuracyReport{Period: p, Months: []*MonthAccuracy{}, Purchases: []*PlannedPurchaseView{}}
+	var month *MonthAccuracy
+	for _, purchase := range bought {
+		label := purchase.BoughtOn.Format("2006-01")
+		if month == nil || month.Month != label {
+			month = &MonthAccuracy{Month: label}
+			result.Months = append(result.Months, month)
+		}
+		month.add(purchase)
+		result.add(purchase)
+		result.Purchases = append(result.Purchases, newPlannedPurchaseView(purchase))
+	}
+	return result, nil
+}
+
+// Document converts the estimate accuracy report into a renderable document
+func (r *EstimateAccuracyReport) Document() *report.Document {
+	months := make([]report.Row, len(r.Months))
+	for i, m := range r.Months {
+		months[i] = report.Row{m.Month, m.Count, m.Estimated, m.Actual, m.Variance, m.MeanAbsoluteError}
+	}
+	purchases := make([]report.Row, len(r.Purchases))
+	for i, p := range r.Purchases {
+		purchases[i] = report.Row{p.BoughtOn.Format("2006-01-02"), p.Description, p.Category,
+			p.EstimatedAmount, p.ActualAmount, *p.Variance, *p.VariancePercent}
+	}
+	return &report.Document{
+		Title: "Estimate accuracy " + r.Period.Label,
+		Summary: []report.Field{
+			{Key: "period", Label: "Period", Kind: report.KindText, Value: r.Period.Label},
+			{Key: "count", Label: "Purchases", Kind: report.KindNumber, Value: r.Count},
+			{Key: "estimated", Label: "Estimated", Kind: report.KindAmount, Value: r.Estimated},
+			{Key: "actual", Label: "Actual", Kind: report.KindAmount, Value: r.Actual},
+			{Key: "variance", Label: "Variance", Kind: report.KindAmount, Value: r.Variance},
+			{Key: "mean_absolute_error", Label: "Mean deviation", Kind: report.KindPercent, Value: r.MeanAbsoluteError},
+		},
+		Sections: []*report.Section{
+			{
+				Key:   "months",
+				Title: "By month",
+				Columns: []report.Column{
+					{Key: "month", Title: "Month", Kind: report.KindText},
+					{Key: "count", Title: "Purchases", Kind: report.KindNumber},
+					{Key: "estimated", Title: "Estimated", Kind: report.KindAmount},
+					{Key: "actual", Title: "Actual", Kind: report.KindAmount},
+					{Key: "variance", Title: "Variance", Kind: report.KindAmount},
+					{Key: "mean_absolute_error", Title: "Mean deviation", Kind: report.KindPercent},
+				},
+				Rows: report.SliceRows(months),
+			},
+			{
+				Key:   "purchases",
+				Title: "Purchases",
+				Columns: []report.Column{
+					{Key: "bought_on", Title: "Bought on", Kind: report.KindText},
+					{Key: "description", Title: "Description", Kind: report.KindText},
+					{Key: "category", Title: "Category", Kind: report.KindText},
+					{Key: "estimated", Title: "Estimated", Kind: report.KindAmount},
+					{Key: "actual", Title: "Actual", Kind: report.KindAmount},
+					{Key: "variance", Title: "Variance", Kind: report.KindAmount},
+					{Key: "variance_percent", Title: "Variance %", Kind: report.KindPercent},
+				},
+				Rows: report.SliceRows(purchases),
+			},
+		},
+	}
+}
@@ -0,0 +1,423 @@
+// Package application contains the business logic and use cases
+// This file contains the expense policy: admins manage the rules, and expenses are checked
+// against them when they are recorded, changed and submitted in a trip report; the violations are reported per period
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For the day of daily limits
+
+	"myexpenses/internal/auth"            // The caller's permissions and tenant
+	"myexpenses/internal/clock"           // Time source for violation times
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/report"          // Shared report renderers
+
+	"github.com/google/uuid" // For the owner of blocked expenses
+)
+
+// Audit actions recorded for policy rules
+const (
+	// AuditPolicyRuleCreated is recorded when an admin adds a policy rule
+	AuditPolicyRuleCreated = "policy_rule.created"
+
+	// AuditPolicyRuleUpdated is recorded when an admin changes a policy rule
+	AuditPolicyRuleUpdated = "policy_rule.updated"
+
+	// AuditPolicyRuleDeleted is recorded when an admin removes a policy rule
+	AuditPolicyRuleDeleted = "policy_rule.deleted"
+)
+
+// PolicyService manages the expense policy and checks expenses against it
+type PolicyService struct {
+	policies    domain.PolicyRepository
+	expenses    domain.Repository
+	attachments domain.AttachmentRepository
+	audit       domain.AuditRepository
+	clock       clock.Clock
+}
+
+// NewPolicyService creates a new policy service
+// expenses provides what was already spent on a day, attachments whether an expense has a receipt
+func NewPolicyService(policies domain.PolicyRepository, expenses domain.Repository, attachments domain.AttachmentRepository, audit domain.AuditRepository, clk clock.Clock) *PolicyService {
+	return &PolicyService{
+		policies:    policies,
+		expenses:    expenses,
+		attachments: attachments,
+		audit:       audit,
+		clock:       clock.Or(clk),
+	}
+}
+
+// PolicyRuleRequest represents the request body for POST and PUT /admin/policies
+type PolicyRuleRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Kind     string `json:"kind" binding:"required"`
+	Category string `json:"category"`
+
+	// Amount mustn't be negative; the rule rejects it otherwise
+	Amount domain.Money `json:"amount"`
+
+	Severity string `json:"severity" binding:"required"`
+	Message  string `json:"message"`
+
+	// Script is the condition of a script rule, e.g. `amount > 100 && weekday == "Sunday"`
+	Script string `json:"script"`
+
+	// Enabled switches the rule on or off (default on)
+	Enabled *bool `json:"enabled"`
+}
+
+// ListRules returns the rules of the caller's organization, so everyone can see what is expected
+func (s *PolicyService) ListRules(ctx context.Context) ([]*domain.PolicyRule, error) {
+	return s.policies.ListRules(ctx, auth.TenantID(ctx))
+}
+
+// CreateRule adds a rule to the policy
+func (s *PolicyService) CreateRule(ctx context.Context, req *PolicyRuleRequest) (*domain.PolicyRule, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	rule, err := domain.NewPolicyRule(req.Name, req.Kind, req.Category, req.Amount, req.Severity, req.Message)
+	if err != nil {
+		return nil, err
+	}
+	if err := rule.SetScript(req.Script); err != nil {
+		return nil, err
+	}
+	rule.TenantID = auth.TenantID(ctx)
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if err := s.policies.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, AuditPolicyRuleCreated, rule.ID.String(), rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateRule replaces a rule's settings; violations recorded under the old settings are kept
+func (s *PolicyService) UpdateRule(ctx context.Context, id string, req *PolicyRuleRequest) (*domain.PolicyRule, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	rule, err := s.policies.GetRule(ctx, auth.TenantID(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if err := rule.Change(req.Name, req.Kind, req.Category, req.Amount, req.Severity, req.Message); err != nil {
+		return nil, err
+	}
+	if err := rule.SetScript(req.Script); err != nil {
+		return nil, err
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if err := s.policies.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, AuditPolicyRuleUpdated, rule.ID.String(), rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule removes a rule from the policy
+func (s *PolicyService) DeleteRule(ctx context.Context, id string) error {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return domain.ErrForbidden
+	}
+	if err := s.policies.DeleteRule(ctx, auth.TenantID(ctx), id); err != nil {
+		return err
+	}
+	return s.record(ctx, AuditPolicyRuleDeleted, id, nil)
+}
+
+// CheckExpense checks an expense that is about to be saved at stage (create or update)
+// It returns the warnings, which RecordWarnings records once the expense is saved. When a rule
+// blocks the expense, the violations are recorded right away and a *domain.PolicyBlockedError is returned
+func (s *PolicyService) CheckExpense(ctx context.Context, expense *domain.Expense, stage string) ([]*domain.PolicyViolation, error) {
+	// Step 1: The organization's rules
+	rules, err := s.policies.ListRules(ctx, auth.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Check each rule with the facts it needs
+	var violations []*domain.PolicyViolation
+	for _, rule := range rules {
+		facts := domain.PolicyFacts{Stage: stage}
+		if rule.Enabled && rule.AppliesTo(expense.Category) {
+			switch rule.Kind {
+			case domain.PolicyDailyLimit:
+				if facts.DaySpent, err = s.daySpent(ctx, expense, rule); err != nil {
+					return nil, err
+				}
+			case domain.PolicyReceiptRequired:
+				if facts.HasReceipt, err = s.hasReceipt(ctx, expense, stage); err != nil {
+					return nil, err
+				}
+			case domain.PolicyScript:
+				if facts.DaySpent, err = s.daySpent(ctx, expense, rule); err != nil {
+					return nil, err
+				}
+				if facts.HasReceipt, err = s.hasReceipt(ctx, expense, stage); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if violation := rule.Check(expense, facts); violation != nil {
+			violations = append(violations, violation)
+		}
+	}
+
+	// Step 3: Blocked expenses are never saved, so their violations are recorded now
+	if !anyBlocking(violations) {
+		return violations, nil
+	}
+	owner := expense.UserID
+	if owner == nil {
+		if id, err := uuid.Parse(auth.UserID(ctx)); err == nil {
+			owner = &id
+		}
+	}
+	now := s.clock.Now()
+	for _, violation := range violations {
+		violation.UserID = owner
+		violation.OccurredAt = now
+		if stage == domain.PolicyStageUpdate {
+			violation.ExpenseID = &expense.ID
+		}
+	}
+	if err := s.policies.RecordViolations(ctx, violations...); err != nil {
+		return nil, err
+	}
+	return nil, &domain.PolicyBlockedError{Violations: violations}
+}
+
+// RecordWarnings records the warnings CheckExpense returned, once the expense is saved
+// Missing receipts before submission are reminders rather than violations, so they aren't recorded
+func (s *PolicyService) RecordWarnings(ctx context.Context, expense *domain.Expense, warnings []*domain.PolicyViolation) error {
+	now := s.clock.Now()
+	var recorded []*domain.PolicyViolation
+	for _, warning := range warnings {
+		if warning.Kind == domain.PolicyReceiptRequired && warning.Stage != domain.PolicyStageSubmit {
+			continue
+		}
+		warning.ExpenseID = &expense.ID
+		warning.UserID = expense.UserID
+		warning.OccurredAt = now
+		recorded = append(recorded, warning)
+	}
+	return s.policies.RecordViolations(ctx, recorded...)
+}
+
+// CheckReport checks the expenses of a trip report that is submitted for approval and records the violations
+// Daily limits count the report's own expenses, and receipts must be attached by now.
+// When a rule blocks the report, a *domain.PolicyBlockedError is returned
+func (s *PolicyService) CheckReport(ctx context.Context, tripID uuid.UUID, expenses []*domain.Expense) ([]*domain.PolicyViolation, error) {
+	rules, err := s.policies.ListRules(ctx, auth.TenantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	var violations []*domain.PolicyViolation
+	for _, expense := range expenses {
+		for _, rule := range rules {
+			facts := domain.PolicyFacts{Stage: domain.PolicyStageSubmit}
+			if rule.Enabled && rule.AppliesTo(expense.Category) {
+				switch rule.Kind {
+				case domain.PolicyDailyLimit:
+					facts.DaySpent = spentOn(expenses, rule, startOfDay(expense.Date), uuid.Nil)
+				case domain.PolicyReceiptRequired:
+					if facts.HasReceipt, err = s.hasReceipt(ctx, expense, domain.PolicyStageSubmit); err != nil {
+						return nil, err
+					}
+				case domain.PolicyScript:
+					facts.DaySpent = spentOn(expenses, rule, startOfDay(expense.Date), uuid.Nil)
+					if facts.HasReceipt, err = s.hasReceipt(ctx, expense, domain.PolicyStageSubmit); err != nil {
+						return nil, err
+					}
+				}
+			}
+			violation := rule.Check(expense, facts)
+			if violation == nil {
+				continue
+			}
+			violation.UserID = expense.UserID
+			violation.ExpenseID = &expense.ID
+			violation.TripID = &tripID
+			violation.OccurredAt = now
+			violations = append(violations, violation)
+		}
+	}
+
+	if err := s.policies.RecordViolations(ctx, violations...); err != nil {
+		return nil, err
+	}
+	if anyBlocking(violations) {
+		return nil, &domain.PolicyBlockedError{Violations: violations}
+	}
+	return violations, nil
+}
+
+// PolicyViolationReport totals the policy violations of a period by user, category and rule,
+// so finance teams can see which rules are broken often and by whom
+type PolicyViolationReport struct {
+	Period domain.Period `json:"period"`
+
+	// Count is the number of violations in the period, Blocked how many of them stopped an expense or report
+	Count   int64 `json:"count"`
+	Blocked int64 `json:"blocked"`
+
+	ByUser     []*domain.PolicyViolationTotal `json:"by_user"`
+	ByCategory []*domain.PolicyViolationTotal `json:"by_category"`
+	ByRule     []*domain.PolicyViolationTotal `json:"by_rule"`
+}
+
+// ViolationReport builds the policy violation report of the caller's organization for a period
+// Violations are counted in the period they occurred in; the report is for admins only
+func (s *PolicyService) ViolationReport(ctx context.Context, period string) (*PolicyViolationReport, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	p, err := domain.ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PolicyViolationReport{Period: p}
+	groupings := []struct {
+		by     string
+		totals *[]*domain.PolicyViolationTotal
+	}{
+		{domain.PolicyViolationsByUser, &result.ByUser},
+		{domain.PolicyViolationsByCategory, &result.ByCategory},
+		{domain.PolicyViolationsByRule, &result.ByRule},
+	}
+	for _, grouping := range groupings {
+		totals, err := s.policies.SumViolations(ctx, auth.TenantID(ctx), grouping.by, p.Start, p.End)
+		if err != nil {
+			return nil, err
+		}
+		*grouping.totals = totals
+	}
+
+	// Every violation has exactly one rule, so the rule totals add up to the period's
+	for _, total := range result.ByRule {
+		result.Count += total.Count
+		result.Blocked += total.Blocked
+	}
+	return result, nil
+}
+
+// Document converts the policy violation report into a renderable document
+func (r *PolicyViolationReport) Document() *report.Document {
+	return &report.Document{
+		Title: "Policy violations " + r.Period.Label,
+		Summary: []report.Field{
+			{Key: "period", Label: "Period", Kind: report.KindText, Value: r.Period.Label},
+			{Key: "count", Label: "Violations", Kind: report.KindNumber, Value: r.Count},
+			{Key: "blocked", Label: "Blocked", Kind: report.KindNumber, Value: r.Blocked},
+		},
+		Sections: []*report.Section{
+			violationSection("by_user", "By user", "User", r.ByUser),
+			violationSection("by_category", "By category", "Category", r.ByCategory),
+			violationSection("by_rule", "By rule", "Rule", r.ByRule),
+		},
+	}
+}
+
+// violationSection lays out one grouping of the policy violation report
+func violationSection(key, title, groupTitle string, totals []*domain.PolicyViolationTotal) *report.Section {
+	rows := make([]report.Row, len(totals))
+	for i, total := range totals {
+		rows[i] = report.Row{total.Label, total.Key, total.Count, total.Blocked, total.Amount}
+	}
+	return &report.Section{
+		Key:   key,
+		Title: title,
+		Columns: []report.Column{
+			{Key: "label", Title: groupTitle, Kind: report.KindText},
+			{Key: "key", Title: "ID", Kind: report.KindText},
+			{Key: "count", Title: "Violations", Kind: report.KindNumber},
+			{Key: "blocked", Title: "Blocked", Kind: report.KindNumber},
+			{Key: "amount", Title: "Amount", Kind: report.KindAmount},
+		},
+		Rows: report.SliceRows(rows),
+	}
+}
+
+// daySpent returns what the caller spent on the expense's day in the rule's categories, the expense included
+func (s *PolicyService) daySpent(ctx context.Context, expense *domain.Expense, rule *domain.PolicyRule) (domain.Money, error) {
+	day := startOfDay(expense.Date)
+	filters := map[string]interface{}{
+		"date_from": day.Format(time.RFC3339),
+		"date_to":   day.Add(24*time.Hour - time.Nanosecond).Format(time.RFC3339Nano),
+	}
+	if rule.Category != "" {
+		filters["category"] = rule.Category
+	}
+	others, err := s.expenses.GetAll(ctx, filters)
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("failed to sum the day's expenses: %w", err)
+	}
+	return spentOn(others, rule, day, expense.ID).Add(expense.ReportingAmount()), nil
+}
+
+// hasReceipt reports whether an expense has an attachment
+// New expenses can't have one yet
+func (s *PolicyService) hasReceipt(ctx context.Context, expense *domain.Expense, stage string) (bool, error) {
+	if stage == domain.PolicyStageCreate {
+		return false, nil
+	}
+	attachments, err := s.attachments.ListByExpense(ctx, expense.ID.String())
+	if err != nil {
+		return false, fmt.Errorf("failed to look for a receipt: %w", err)
+	}
+	return len(attachments) > 0, nil
+}
+
+// record writes an audit entry for a policy rule
+func (s *PolicyService) record(ctx context.Context, action, ruleID string, details any) error {
+	entry, err := domain.NewAuditEntry(auditActor(ctx), action, "policy_rule", ruleID, details)
+	if err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record policy change: %w", err)
+	}
+	return nil
+}
+
+// spentOn sums the reporting amounts of the expenses on day that the rule covers, leaving out skip
+func spentOn(expenses []*domain.Expense, rule *domain.PolicyRule, day time.Time, skip uuid.UUID) domain.Money {
+	var total domain.Money
+	for _, expense := range expenses {
+		if expense.ID == skip || !rule.AppliesTo(expense.Category) || !startOfDay(expense.Date).Equal(day) {
+			continue
+		}
+		total = total.Add(expense.ReportingAmount())
+	}
+	return total
+}
+
+// anyBlocking reports whether any of the violations blocks
+func anyBlocking(violations []*domain.PolicyViolation) bool {
+	for _, violation := range violations {
+		if violation.Blocking() {
+			return true
+		}
+	}
+	return false
+}
+
+// startOfDay returns midnight UTC of t's day, the day daily limits count
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,144 @@
+// Package application contains the business logic and use cases
+// This file contains the provisioning service that seeds new installations with starter data
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching domain errors
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For comparing category names
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// ProvisioningService seeds localized default categories, sample rules and a starter budget
+// so new installations aren't empty and clients don't have to reimplement the defaults
+type ProvisioningService struct {
+	categories domain.CategoryRepository
+	rules      domain.RuleRepository
+	budgets    domain.BudgetRepository
+	mcc        domain.MCCRepository
+}
+
+// NewProvisioningService creates a new provisioning service
+func NewProvisioningService(categories domain.CategoryRepository, rules domain.RuleRepository, budgets domain.BudgetRepository, mcc domain.MCCRepository) *ProvisioningService {
+	return &ProvisioningService{
+		categories: categories,
+		rules:      rules,
+		budgets:    budgets,
+		mcc:        mcc,
+	}
+}
+
+// ProvisioningResult reports what was seeded
+type ProvisioningResult struct {
+	Locale               string   `json:"locale"`
+	CategoriesCreated    []string `json:"categories_created"`
+	RulesCreated         int      `json:"rules_created"`
+	BudgetCreated        bool     `json:"budget_created"`
+	MCCMappingsLocalized int      `json:"mcc_mappings_localized"`
+}
+
+// EnsureProvisioned seeds the starter data on first run, i.e. while no categories exist yet
+// Later runs do nothing, so categories a user deleted don't come back on restart
+// Archived categories still exist, so archiving every category doesn't re-provision either
+func (s *ProvisioningService) EnsureProvisioned(ctx context.Context, locale string) (*ProvisioningResult, error) {
+	existing, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, nil
+	}
+	return s.Provision(ctx, locale)
+}
+
+// Provision seeds the starter data for a locale
+// It is safe to run again: existing categories (archived ones too) are kept, rules are only added
+// when there are none, and the starter budget only when no budget exists
+func (s *ProvisioningService) Provision(ctx context.Context, locale string) (*ProvisioningResult, error) {
+	locale = domain.NormalizeLocale(locale)
+	result := &ProvisioningResult{Locale: locale, CategoriesCreated: []string{}}
+
+	// Step 1: Categories, translated into the locale
+	existing, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	known := make(map[string]bool, len(existing))
+	for _, category := range existing {
+		known[strings.ToLower(category.Name)] = true
+	}
+	for _, canonical := range domain.DefaultCategories {
+		name := domain.LocalizeCategory(locale, canonical)
+		if known[strings.ToLower(name)] {
+			continue
+		}
+		category, err := domain.NewCategory(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.categories.Create(ctx, category); err != nil && !errors.Is(err, domain.ErrCategoryExists) {
+			return nil, fmt.Errorf("failed to create category %q: %w", name, err)
+		}
+		result.CategoriesCreated = append(result.CategoriesCreated, name)
+	}
+
+	// Step 2: Sample categorization rules
+	rules, err := s.rules.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	if len(rules) == 0 {
+		for _, seed := range domain.DefaultRules {
+			rule, err := domain.NewCategoryRule(seed.Pattern, domain.LocalizeCategory(locale, seed.Category), 0)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.rules.Create(ctx, rule); err != nil {
+				return nil, fmt.Errorf("failed to create rule %q: %w", seed.Pattern, err)
+			}
+			result.RulesCreated++
+		}
+	}
+
+	// Step 3: Starter budget
+	budgets, err := s.budgets.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	if len(budgets) == 0 {
+		budget, err := domain.NewBudget(domain.LocalizeCategory(locale, domain.StarterBudgetCategory), domain.NewMoney(domain.StarterBudgetAmount, ""))
+		if err != nil {
+			return nil, err
+		}
+		if err := s.budgets.Create(ctx, budget); err != nil && !errors.Is(err, domain.ErrBudgetExists) {
+			return nil, fmt.Errorf("failed to create starter budget: %w", err)
+		}
+		result.BudgetCreated = true
+	}
+
+	// Step 4: Point the default MCC mappings at the localized categories
+	// Mappings the user already changed are left alone
+	if locale != domain.DefaultLocale {
+		for code, canonical := range domain.DefaultMCCMappings {
+			mapping, err := s.mcc.Get(ctx, code)
+			if err != nil {
+				if errors.Is(err, domain.ErrMCCMappingNotFound) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to get MCC mapping %s: %w", code, err)
+			}
+			if mapping.Category != canonical {
+				continue
+			}
+			mapping.Category = domain.LocalizeCategory(locale, canonical)
+			if err := s.mcc.Upsert(ctx, mapping); err != nil {
+				return nil, fmt.Errorf("failed to update MCC mapping %s: %w", code, err)
+			}
+			result.MCCMappingsLocalized++
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,251 @@
+// Package application contains the business logic and use cases
+// This file contains public forms and the staging area their submissions (and card transactions) land in
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// PublicFormService manages public submission forms and reviews what is submitted through them
+// Approved submissions are created through the expense Service, so they get the same
+// currency conversion and normalization as expenses entered by hand
+type PublicFormService struct {
+	forms      domain.PublicFormRepository
+	expenses   *Service
+	transactor domain.Transactor
+	clock      clock.Clock
+}
+
+// NewPublicFormService creates a new public form service
+// transactor makes approval atomic (nil runs the steps without a transaction)
+func NewPublicFormService(forms domain.PublicFormRepository, expenses *Service, transactor domain.Transactor, clk clock.Clock) *PublicFormService {
+	return &PublicFormService{
+		forms:      forms,
+		expenses:   expenses,
+		transactor: transactor,
+		clock:      clock.Or(clk),
+	}
+}
+
+// CreatePublicFormRequest represents the request body for POST /forms
+type CreatePublicFormRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Category string `json:"category" binding:"required"`
+
+	// MaxAmount must be greater than 0; NewPublicForm rejects it otherwise
+	MaxAmount domain.Money `json:"max_amount"`
+	ExpiresAt *time.Time   `json:"expires_at"`
+}
+
+// CreatedPublicForm is a new form together with its token
+// The token is part of the public link and can't be retrieved again later
+type CreatedPublicForm struct {
+	*domain.PublicForm
+	Token string `json:"token"`
+}
+
+// PublicFormInfo is what the public sees about a form before submitting
+type PublicFormInfo struct {
+	Name      string       `json:"name"`
+	Category  string       `json:"category"`
+	MaxAmount domain.Money `json:"max_amount"`
+	ExpiresAt *time.Time   `json:"expires_at,omitempty"`
+}
+
+// SubmitExpenseRequest represents the request body for POST /public/forms/{token}/expenses
+// There is no category field: every submission gets the form's category
+type SubmitExpenseRequest struct {
+	SubmittedBy string `json:"submitted_by"`
+	Description string `json:"description" binding:"required"`
+
+	// Amount must be greater than 0; NewStagedExpense rejects it otherwise
+	Amount domain.Money `json:"amount"`
+
+	Date time.Time `json:"date" binding:"required"`
+	Note string    `json:"note"`
+}
+
+// CodeStagedRequest represents the request body for PUT /staging/{id}
+// Empty description and category keep what the card feed provided
+type CodeStagedRequest struct {
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Note        string `json:"note"`
+}
+
+// RejectSubmissionRequest represents the request body for POST /staging/{id}/reject
+type RejectSubmissionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// CreateForm creates a public form and returns it with its token
+func (s *PublicFormService) CreateForm(ctx context.Context, req *CreatePublicFormRequest) (*CreatedPublicForm, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.clock.Now()) {
+		return nil, domain.ErrInvalidPublicForm
+	}
+	form, token, err := domain.NewPublicForm(req.Name, req.Category, req.MaxAmount, req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.forms.CreateForm(ctx, form); err != nil {
+		return nil, err
+	}
+	return &CreatedPublicForm{PublicForm: form, Token: token}, nil
+}
+
+// ListForms returns all public forms (without their tokens)
+func (s *PublicFormService) ListForms(ctx context.Context) ([]*domain.PublicForm, error) {
+	return s.forms.ListForms(ctx)
+}
+
+// RevokeForm stops a form from accepting submissions; what was already submitted stays staged
+func (s *PublicFormService) RevokeForm(ctx context.Context, id string) (*domain.PublicForm, error) {
+	form, err := s.forms.GetForm(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if form.RevokedAt == nil {
+		now := s.clock.Now()
+		form.RevokedAt = &now
+		if err := s.forms.UpdateForm(ctx, form); err != nil {
+			return nil, err
+		}
+	}
+	return form, nil
+}
+
+// FormInfo returns what the public form behind token looks like
+func (s *PublicFormService) FormInfo(ctx context.Context, token string) (*PublicFormInfo, error) {
+	form, err := s.openForm(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &PublicFormInfo{
+		Name:      form.Name,
+		Category:  form.Category,
+		MaxAmount: form.MaxAmount,
+		ExpiresAt: form.ExpiresAt,
+	}, nil
+}
+
+// Submit stages an expense submitted through the public form behind token
+func (s *PublicFormService) Submit(ctx context.Context, token string, req *SubmitExpenseRequest) (*domain.StagedExpense, error) {
+	form, err := s.openForm(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	staged, err := domain.NewStagedExpense(form, req.SubmittedBy, req.Description, req.Amount, req.Date, req.Note)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.forms.CreateStaged(ctx, staged); err != nil {
+		return nil, err
+	}
+	return staged, nil
+}
+
+// ListStaged returns the staging area, optionally only the submissions with one status
+func (s *PublicFormService) ListStaged(ctx context.Context, status string) ([]*domain.StagedExpense, error) {
+	switch status {
+	case "", domain.StagedPending, domain.StagedApproved, domain.StagedRejected:
+		return s.forms.ListStaged(ctx, status)
+	default:
+		return nil, domain.ErrInvalidSubmission
+	}
+}
+
+// Approve turns a staged submission into a real expense
+// The expense is created and the submission marked approved in one transaction
+func (s *PublicFormService) Approve(ctx context.Context, id string) (*domain.Expense, error) {
+	staged, err := s.forms.GetStaged(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if staged.Status != domain.StagedPending {
+		return nil, domain.ErrSubmissionReviewed
+	}
+
+	var expense *domain.Expense
+	err = s.withinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		expense, err = s.expenses.CreateExpense(ctx, &CreateExpenseRequest{
+			Description: staged.Description,
+			Amount:      staged.Amount,
+			Category:    staged.Category,
+			Date:        staged.Date,
+
+			// Foreign card transactions keep what the card was actually charged
+			Currency:        staged.Currency,
+			ConvertedAmount: staged.ConvertedAmount,
+		})
+		if err != nil {
+			return err
+		}
+		if err := staged.Approve(expense.ID, s.clock.Now()); err != nil {
+			return err
+		}
+		return s.forms.UpdateStaged(ctx, staged)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+// Code sets what a staged card transaction was for, ahead of its approval
+func (s *PublicFormService) Code(ctx context.Context, id string, req *CodeStagedRequest) (*domain.StagedExpense, error) {
+	staged, err := s.forms.GetStaged(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := staged.Code(req.Description, req.Category, req.Note); err != nil {
+		return nil, err
+	}
+	if err := s.forms.UpdateStaged(ctx, staged); err != nil {
+		return nil, err
+	}
+	return staged, nil
+}
+
+// Reject declines a staged submission
+func (s *PublicFormService) Reject(ctx context.Context, id string, req *RejectSubmissionRequest) (*domain.StagedExpense, error) {
+	staged, err := s.forms.GetStaged(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := staged.Reject(req.Reason, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.forms.UpdateStaged(ctx, staged); err != nil {
+		return nil, err
+	}
+	return staged, nil
+}
+
+// openForm returns the form behind token if it still accepts submissions
+// Unknown tokens and closed forms are told apart so the submitter knows the link has expired
+func (s *PublicFormService) openForm(ctx context.Context, token string) (*domain.PublicForm, error) {
+	if token == "" {
+		return nil, domain.ErrPublicFormNotFound
+	}
+	form, err := s.forms.GetFormByTokenHash(ctx, domain.HashFormToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if !form.IsOpen(s.clock.Now()) {
+		return nil, domain.ErrPublicFormClosed
+	}
+	return form, nil
+}
+
+// withinTransaction runs fn in a transaction when a transactor is configured
+func (s *PublicFormService) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.transactor == nil {
+		return fn(ctx)
+	}
+	return s.transactor.WithinTransaction(ctx, fn)
+}
@@ -0,0 +1,152 @@
+// Package application contains the business logic and use cases
+// This file contains read-only mode: switching the deployment or a tenant to reads only,
+// for maintenance or while a tenant's billing is suspended
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"log"     // For reporting switches that can't be read
+	"sync"    // For guarding the cached switches
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/auth"            // The tenant a request is made in
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// ReadOnlyRefreshInterval is how long the switches are cached
+// Every request that writes checks them, so they aren't read from the database each time;
+// a switch made on another instance takes effect here at most this much later
+const ReadOnlyRefreshInterval = 10 * time.Second
+
+// ReadOnlyService decides whether the caller's tenant may write
+// The deployment-wide switch wins over a tenant's own, so a tenant suspended for billing
+// still sees "maintenance" while maintenance lasts
+type ReadOnlyService struct {
+	repo  domain.ReadOnlyRepository
+	fixed *domain.ReadOnlyMode
+	clock clock.Clock
+
+	// modes caches the stored switches by tenant ID, as loaded at loadedAt
+	mu       sync.Mutex
+	modes    map[string]*domain.ReadOnlyMode
+	loadedAt time.Time
+}
+
+// NewReadOnlyService creates a new read-only service
+// fixed is the deployment-wide switch set in the configuration (nil when there is none); unlike
+// the stored switches it holds even while the database can't be reached, and only a restart lifts it
+func NewReadOnlyService(repo domain.ReadOnlyRepository, fixed *domain.ReadOnlyMode, clk clock.Clock) *ReadOnlyService {
+	return &ReadOnlyService{
+		repo:  repo,
+		fixed: fixed,
+		clock: clock.Or(clk),
+	}
+}
+
+// ReadOnlyRequest represents the request to switch read-only mode on
+type ReadOnlyRequest struct {
+	Reason  string `json:"reason" binding:"required"`
+	Message string `json:"message"`
+}
+
+// Mode returns the switch that keeps the caller's tenant from writing, or nil if it may write
+// If the switches can't be loaded the last ones known are used, so a database hiccup
+// neither locks everybody out nor lifts a suspension
+func (s *ReadOnlyService) Mode(ctx context.Context) *domain.ReadOnlyMode {
+	if s.fixed != nil {
+		return s.fixed
+	}
+	modes := s.load(ctx)
+	if mode, ok := modes[domain.ReadOnlyAllTenants]; ok {
+		return mode
+	}
+	return modes[auth.TenantID(ctx)]
+}
+
+// List returns every switch that is on, the one from the configuration first
+func (s *ReadOnlyService) List(ctx context.Context) ([]*domain.ReadOnlyMode, error) {
+	modes, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.fixed != nil {
+		modes = append([]*domain.ReadOnlyMode{s.fixed}, modes...)
+	}
+	return modes, nil
+}
+
+// Enable switches tenantID (domain.ReadOnlyAllTenants for every tenant) to read-only
+func (s *ReadOnlyService) Enable(ctx context.Context, tenantID string, req *ReadOnlyRequest) (*domain.ReadOnlyMode, error) {
+	mode, err := domain.NewReadOnlyMode(tenantID, req.Reason, req.Message)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, mode); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return mode, nil
+}
+
+// Disable switches tenantID back to writable
+// The switch from the configuration can't be lifted here; it isn't stored, so this returns
+// domain.ErrReadOnlyModeNotFound for it
+func (s *ReadOnlyService) Disable(ctx context.Context, tenantID string) error {
+	if err := s.repo.Delete(ctx, tenantID); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// SuspendForBilling switches a tenant to read-only because its subscription lapsed
+func (s *ReadOnlyService) SuspendForBilling(ctx context.Context, tenantID string) error {
+	_, err := s.Enable(ctx, tenantID, &ReadOnlyRequest{Reason: domain.ReadOnlyBillingSuspended})
+	return err
+}
+
+// ResumeAfterBilling lifts a tenant's billing suspension once its subscription is paid or ended
+// Tenants that are read-only for another reason stay read-only
+func (s *ReadOnlyService) ResumeAfterBilling(ctx context.Context, tenantID string) error {
+	modes, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, mode := range modes {
+		if mode.TenantID == tenantID && mode.Reason == domain.ReadOnlyBillingSuspended {
+			return s.Disable(ctx, tenantID)
+		}
+	}
+	return nil
+}
+
+// load returns the stored switches, reading them again once they are older than ReadOnlyRefreshInterval
+func (s *ReadOnlyService) load(ctx context.Context) map[string]*domain.ReadOnlyMode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if s.modes != nil && now.Sub(s.loadedAt) < ReadOnlyRefreshInterval {
+		return s.modes
+	}
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		log.Printf("Failed to load read-only modes, using the last known ones: %v", err)
+		// Try again on the next request rather than after a full interval
+		return s.modes
+	}
+	s.modes = make(map[string]*domain.ReadOnlyMode, len(list))
+	for _, mode := range list {
+		s.modes[mode.TenantID] = mode
+	}
+	s.loadedAt = now
+	return s.modes
+}
+
+// invalidate makes the next check read the switches again, so changes here take effect at once
+func (s *ReadOnlyService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Time{}
+}
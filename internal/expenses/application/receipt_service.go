@@ -0,0 +1,312 @@
+// Package application contains the business logic and use cases
+// This file contains the receipt inbox: bulk receipt uploads matched to existing expenses
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"io"      // For streaming uploaded files
+	"log"     // For reporting non-fatal OCR and cleanup failures
+	"sort"    // For ranking candidates
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/storage"         // Blob storage for the file content
+)
+
+// maxReceiptCandidates is how many candidate expenses are suggested per unmatched receipt
+const maxReceiptCandidates = 5
+
+// ReceiptService uploads receipts in bulk and attaches each one to the expense it documents
+// Receipts are read with OCR, matched by amount, date and merchant, and attached automatically
+// above the confidence threshold; the rest wait in an inbox for manual matching
+type ReceiptService struct {
+	expenses    domain.Repository
+	attachments domain.AttachmentRepository
+	pending     domain.PendingReceiptRepository
+	storage     storage.Storage
+	content     *attachmentContent
+	extractor   domain.TextExtractor
+	threshold   float64
+	maxSize     int64
+}
+
+// NewReceiptService creates a new receipt inbox service
+// threshold is the confidence (0-1) from which receipts are attached without asking
+// blobs reference-counts attachment content, so a receipt identical to an earlier one is stored once
+// maxSize is the largest receipt file accepted (<= 0 uses domain.MaxAttachmentSize)
+func NewReceiptService(expenses domain.Repository, attachments domain.AttachmentRepository, pending domain.PendingReceiptRepository, blobs domain.AttachmentBlobRepository, store storage.Storage, extractor domain.TextExtractor, threshold float64, maxSize int64) *ReceiptService {
+	if maxSize <= 0 {
+		maxSize = domain.MaxAttachmentSize
+	}
+	if threshold <= 0 || threshold > 1 {
+		threshold = domain.DefaultReceiptMatchThreshold
+	}
+	return &ReceiptService{
+		expenses:    expenses,
+		attachments: attachments,
+		pending:     pending,
+		storage:     store,
+		content:     &attachmentContent{blobs: blobs, storage: store},
+		extractor:   extractor,
+		threshold:   threshold,
+		maxSize:     maxSize,
+	}
+}
+
+// ReceiptCandidate is an expense a receipt may belong to
+type ReceiptCandidate struct {
+	Expense    *domain.Expense `json:"expense"`
+	Confidence float64         `json:"confidence"`
+}
+
+// ReceiptUploadResult is what happened to one file of a bulk upload
+type ReceiptUploadResult struct {
+	FileName string `json:"file_name"`
+
+	// Status is "attached", "pending" or "failed"
+	Status string `json:"status"`
+
+	Facts *domain.ReceiptFacts `json:"facts,omitempty"`
+
+	// Attachment is set when the receipt was attached automatically
+	Attachment *domain.Attachment `json:"attachment,omitempty"`
+	Confidence float64            `json:"confidence,omitempty"`
+
+	// PendingReceipt and Candidates are set when the receipt waits for manual matching
+	PendingReceipt *domain.PendingReceipt `json:"pending_receipt,omitempty"`
+	Candidates     []*ReceiptCandidate    `json:"candidates,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// Receipt upload statuses
+const (
+	ReceiptAttached = "attached"
+	ReceiptPending  = "pending"
+	ReceiptFailed   = "failed"
+)
+
+// PendingReceiptView is an inbox entry with its best candidate expenses
+type PendingReceiptView struct {
+	*domain.PendingReceipt
+	Candidates []*ReceiptCandidate `json:"candidates"`
+}
+
+// UploadReceipts processes a batch of receipt files
+// Every file gets its own result; one unreadable file doesn't fail the batch
+func (s *ReceiptService) UploadReceipts(ctx context.Context, files []*UploadAttachmentRequest) ([]*ReceiptUploadResult, error) {
+	if len(files) == 0 || len(files) > domain.MaxReceiptBatch {
+		return nil, domain.ErrTooManyFiles
+	}
+
+	results := make([]*ReceiptUploadResult, 0, len(files))
+	for _, file := range files {
+		result, err := s.uploadReceipt(ctx, file)
+		if err != nil {
+			result = &ReceiptUploadResult{FileName: file.FileName, Status: ReceiptFailed, Error: err.Error()}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// uploadReceipt stores one file in the inbox, then attaches it if a candidate is confident enough
+func (s *ReceiptService) uploadReceipt(ctx context.Context, file *UploadAttachmentRequest) (*ReceiptUploadResult, error) {
+	// Step 1: Store the file as a pending receipt
+	receipt, err := domain.NewPendingReceipt(file.FileName, file.ContentType, file.Size)
+	if err != nil {
+		return nil, err
+	}
+	if file.Size > s.maxSize {
+		return nil, domain.ErrInvalidAttachment
+	}
+	written, err := s.storage.Put(ctx, receipt.StorageKey, io.LimitReader(file.Content, s.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store receipt: %w", err)
+	}
+	if written > s.maxSize {
+		_ = s.storage.Delete(ctx, receipt.StorageKey)
+		return nil, domain.ErrInvalidAttachment
+	}
+	receipt.Size = written
+
+	// Step 2: Read the receipt
+	receipt.OCRText = s.extractText(ctx, receipt)
+	facts := domain.ParseReceiptText(receipt.OCRText)
+	receipt.Amount, receipt.Date, receipt.Merchant = facts.Amount, facts.Date, facts.Merchant
+
+	// Step 3: Find the best candidates and attach when the best one is confident enough
+	candidates, err := s.candidates(ctx, facts)
+	if err != nil {
+		_ = s.storage.Delete(ctx, receipt.StorageKey)
+		return nil, err
+	}
+	if len(candidates) > 0 && candidates[0].Confidence >= s.threshold {
+		// A clear winner only: two equally good candidates need a human
+		if len(candidates) == 1 || candidates[1].Confidence < candidates[0].Confidence {
+			attachment, err := s.attach(ctx, receipt, candidates[0].Expense)
+			if err != nil {
+				_ = s.storage.Delete(ctx, receipt.StorageKey)
+				return nil, err
+			}
+			return &ReceiptUploadResult{
+				FileName:   receipt.FileName,
+				Status:     ReceiptAttached,
+				Facts:      &facts,
+				Attachment: attachment,
+				Confidence: candidates[0].Confidence,
+			}, nil
+		}
+	}
+
+	// Step 4: Queue the rest for manual matching
+	if err := s.pending.Create(ctx, receipt); err != nil {
+		_ = s.storage.Delete(ctx, receipt.StorageKey)
+		return nil, fmt.Errorf("failed to save pending receipt: %w", err)
+	}
+	return &ReceiptUploadResult{
+		FileName:       receipt.FileName,
+		Status:         ReceiptPending,
+		Facts:          &facts,
+		PendingReceipt: receipt,
+		Candidates:     candidates,
+	}, nil
+}
+
+// ListPending returns the receipts waiting for manual matching with their best candidates
+func (s *ReceiptService) ListPending(ctx context.Context) ([]*PendingReceiptView, error) {
+	receipts, err := s.pending.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]*PendingReceiptView, 0, len(receipts))
+	for _, receipt := range receipts {
+		candidates, err := s.candidates(ctx, receipt.Facts())
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, &PendingReceiptView{PendingReceipt: receipt, Candidates: candidates})
+	}
+	return views, nil
+}
+
+// MatchReceiptRequest represents the request body for POST /receipts/pending/{id}/match
+type MatchReceiptRequest struct {
+	ExpenseID string `json:"expense_id" binding:"required"`
+}
+
+// MatchPending attaches a pending receipt to the expense chosen by the user
+func (s *ReceiptService) MatchPending(ctx context.Context, receiptID string, req *MatchReceiptRequest) (*domain.Attachment, error) {
+	receipt, err := s.pending.GetByID(ctx, receiptID)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.expenses.GetByID(ctx, req.ExpenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense: %w", err)
+	}
+
+	attachment, err := s.attach(ctx, receipt, expense)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.pending.Delete(ctx, receiptID); err != nil {
+		return nil, fmt.Errorf("failed to remove pending receipt: %w", err)
+	}
+	return attachment, nil
+}
+
+// DiscardPending removes a pending receipt and its file
+func (s *ReceiptService) DiscardPending(ctx context.Context, receiptID string) error {
+	receipt, err := s.pending.GetByID(ctx, receiptID)
+	if err != nil {
+		return err
+	}
+	if err := s.pending.Delete(ctx, receiptID); err != nil {
+		return err
+	}
+	if err := s.storage.Delete(ctx, receipt.StorageKey); err != nil {
+		log.Printf("failed to delete receipt content %s: %v", receipt.StorageKey, err)
+	}
+	return nil
+}
+
+// candidates returns the expenses the receipt may belong to, most likely first
+// Only expenses close in amount (and in date, when the receipt has one) are considered
+func (s *ReceiptService) candidates(ctx context.Context, facts domain.ReceiptFacts) ([]*ReceiptCandidate, error) {
+	if facts.Amount.Minor <= 0 {
+		return nil, nil
+	}
+
+	filters := map[string]interface{}{
+		"min_amount": facts.Amount.Mul(0.99),
+		"max_amount": facts.Amount.Mul(1.01),
+		"limit":      50,
+	}
+	if facts.Date != nil {
+		filters["date_from"] = facts.Date.AddDate(0, 0, -domain.ReceiptMatchWindow).Format("2006-01-02")
+		filters["date_to"] = facts.Date.AddDate(0, 0, domain.ReceiptMatchWindow+1).Format("2006-01-02")
+	}
+	expenses, err := s.expenses.GetAll(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find candidate expenses: %w", err)
+	}
+
+	candidates := make([]*ReceiptCandidate, 0, len(expenses))
+	for _, expense := range expenses {
+		candidates = append(candidates, &ReceiptCandidate{Expense: expense, Confidence: domain.ScoreReceiptMatch(facts, expense)})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Confidence > candidates[j].Confidence })
+	if len(candidates) > maxReceiptCandidates {
+		candidates = candidates[:maxReceiptCandidates]
+	}
+	return candidates, nil
+}
+
+// attach turns a stored receipt into an attachment of expense
+// The file is copied to the attachment's storage key (or shared with an identical attachment)
+// and the inbox copy is removed
+func (s *ReceiptService) attach(ctx context.Context, receipt *domain.PendingReceipt, expense *domain.Expense) (*domain.Attachment, error) {
+	attachment, err := domain.NewAttachment(expense.ID, receipt.FileName, receipt.ContentType, receipt.Size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+	attachment.OCRText = receipt.OCRText
+
+	content, err := s.storage.Get(ctx, receipt.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open receipt: %w", err)
+	}
+	err = s.content.store(ctx, attachment, content, s.maxSize)
+	content.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachments.Create(ctx, attachment); err != nil {
+		_ = s.content.release(ctx, attachment)
+		return nil, fmt.Errorf("failed to save attachment: %w", err)
+	}
+	if err := s.storage.Delete(ctx, receipt.StorageKey); err != nil {
+		log.Printf("failed to delete receipt content %s: %v", receipt.StorageKey, err)
+	}
+	return attachment, nil
+}
+
+// extractText runs OCR on a stored receipt
+// Errors are logged and swallowed: an unreadable receipt simply ends up in the inbox
+func (s *ReceiptService) extractText(ctx context.Context, receipt *domain.PendingReceipt) string {
+	content, err := s.storage.Get(ctx, receipt.StorageKey)
+	if err != nil {
+		log.Printf("failed to open receipt %s for OCR: %v", receipt.ID, err)
+		return ""
+	}
+	defer content.Close()
+
+	text, err := s.extractor.ExtractText(ctx, receipt.ContentType, content)
+	if err != nil {
+		log.Printf("failed to extract text from receipt %s: %v", receipt.ID, err)
+		return ""
+	}
+	return text
+}
@@ -0,0 +1,149 @@
+// Package application contains the business logic and use cases
+// This file contains receivables: money owed to the user, its repayment and its reminders
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For normalizing the status filter
+	"time"    // For due dates and settlement times
+
+	"myexpenses/internal/clock"           // Time source for overdue checks and settlements
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For parsing linked expense IDs
+)
+
+// ReceivableService records money owed to the user and its repayment
+// It covers one-off "I covered the dinner" situations; shared spending goes through groups
+type ReceivableService struct {
+	receivables  domain.ReceivableRepository
+	expenses     domain.Repository
+	baseCurrency string
+	clock        clock.Clock
+}
+
+// NewReceivableService creates a new receivable service
+// baseCurrency is used for receivables that give no currency and link no expense
+func NewReceivableService(receivables domain.ReceivableRepository, expenses domain.Repository, baseCurrency string, clk clock.Clock) *ReceivableService {
+	return &ReceivableService{
+		receivables:  receivables,
+		expenses:     expenses,
+		baseCurrency: baseCurrency,
+		clock:        clock.Or(clk),
+	}
+}
+
+// CreateReceivableRequest represents the request body for POST /receivables
+type CreateReceivableRequest struct {
+	Debtor      string `json:"debtor" binding:"required"`
+	Description string `json:"description"`
+
+	// Amount must be greater than 0; NewReceivable rejects it otherwise
+	Amount domain.Money `json:"amount"`
+
+	// Currency defaults to the linked expense's currency, or the base currency
+	Currency string `json:"currency"`
+
+	// ExpenseID links the expense the user paid on the debtor's behalf (optional)
+	ExpenseID string `json:"expense_id"`
+
+	// DueDate and ReminderDays schedule a reminder in the calendar feed (optional)
+	DueDate      *time.Time `json:"due_date"`
+	ReminderDays int        `json:"reminder_days"`
+}
+
+// SettleReceivableRequest represents the request body for POST /receivables/{id}/settle
+type SettleReceivableRequest struct {
+	// Amount is what was paid back; 0 settles everything still outstanding
+	// Settle rejects negative amounts
+	Amount domain.Money `json:"amount"`
+}
+
+// ReceivableList is a list of receivables with what is still owed, per currency
+type ReceivableList struct {
+	Receivables []*domain.Receivable    `json:"receivables"`
+	Outstanding map[string]domain.Money `json:"outstanding"`
+}
+
+// CreateReceivable records money someone owes the caller
+func (s *ReceivableService) CreateReceivable(ctx context.Context, req *CreateReceivableRequest) (*domain.Receivable, error) {
+	// Step 1: Check the linked expense is one of the caller's and take over its currency
+	currency := req.Currency
+	var expenseID *uuid.UUID
+	if req.ExpenseID != "" {
+		expense, err := s.expenses.GetByID(ctx, req.ExpenseID)
+		if err != nil {
+			return nil, err
+		}
+		expenseID = &expense.ID
+		if strings.TrimSpace(currency) == "" {
+			currency = expense.Currency
+		}
+	}
+	if strings.TrimSpace(currency) == "" {
+		currency = s.baseCurrency
+	}
+
+	// Step 2: Validate and save
+	receivable, err := domain.NewReceivable(req.Debtor, req.Description, req.Amount, currency, expenseID, req.DueDate, req.ReminderDays)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.receivables.Create(ctx, receivable); err != nil {
+		return nil, err
+	}
+	return receivable, nil
+}
+
+// ListReceivables returns the caller's receivables, optionally only those of one status
+// (open, overdue or settled) or one debtor
+func (s *ReceivableService) ListReceivables(ctx context.Context, status, debtor string) (*ReceivableList, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	switch status {
+	case "", domain.ReceivableOpen, domain.ReceivableOverdue, domain.ReceivableSettled:
+	default:
+		return nil, domain.ErrInvalidReceivableStatus
+	}
+
+	receivables, err := s.receivables.List(ctx, domain.ReceivableFilter{Status: status, AsOf: s.clock.Now(), Debtor: debtor})
+	if err != nil {
+		return nil, err
+	}
+	outstanding := make(map[string]domain.Money)
+	for _, receivable := range receivables {
+		if receivable.SettledAt == nil {
+			amount := receivable.Outstanding().In(receivable.Currency)
+			outstanding[receivable.Currency] = outstanding[receivable.Currency].Add(amount)
+		}
+	}
+	return &ReceivableList{Receivables: receivables, Outstanding: outstanding}, nil
+}
+
+// GetReceivable returns one of the caller's receivables
+func (s *ReceivableService) GetReceivable(ctx context.Context, id string) (*domain.Receivable, error) {
+	return s.receivables.GetByID(ctx, id)
+}
+
+// SettleReceivable records a (partial) repayment
+func (s *ReceivableService) SettleReceivable(ctx context.Context, id string, req *SettleReceivableRequest) (*domain.Receivable, error) {
+	receivable, err := s.receivables.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	amount := req.Amount
+	if amount.IsZero() {
+		amount = receivable.Outstanding()
+	}
+	if err := receivable.Settle(amount, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.receivables.Update(ctx, receivable); err != nil {
+		return nil, err
+	}
+	return receivable, nil
+}
+
+// DeleteReceivable removes a receivable, e.g. one entered by mistake or written off
+func (s *ReceivableService) DeleteReceivable(ctx context.Context, id string) error {
+	return s.receivables.Delete(ctx, id)
+}
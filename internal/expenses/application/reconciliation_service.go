@@ -0,0 +1,402 @@
+// Package application contains the business logic and use cases
+// This file contains the reconciliation workspace: checking a statement period against the recorded expenses
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For ranking match candidates
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For parsing account and expense IDs
+)
+
+// ReconciliationService runs reconciliation sessions
+// A session holds the lines of one statement period; lines are matched to expenses automatically
+// (same bank ID, or same amount within a few days) or by hand, and closing the session marks the
+// matched expenses as reconciled
+type ReconciliationService struct {
+	reconciliations domain.ReconciliationRepository
+	expenses        domain.Repository
+	accounts        domain.AccountRepository
+	clock           clock.Clock
+}
+
+// NewReconciliationService creates a new reconciliation service
+// clk stamps closed sessions and reconciled expenses (nil means the system clock)
+func NewReconciliationService(reconciliations domain.ReconciliationRepository, expenses domain.Repository, accounts domain.AccountRepository, clk clock.Clock) *ReconciliationService {
+	return &ReconciliationService{
+		reconciliations: reconciliations,
+		expenses:        expenses,
+		accounts:        accounts,
+		clock:           clock.Or(clk),
+	}
+}
+
+// StartReconciliationRequest represents the request body for POST /reconciliations
+type StartReconciliationRequest struct {
+	// AccountID limits matching to the expenses paid from this account (optional)
+	AccountID string `json:"account_id"`
+
+	// PeriodStart and PeriodEnd are the first and last day of the statement (YYYY-MM-DD)
+	PeriodStart string `json:"period_start" binding:"required"`
+	PeriodEnd   string `json:"period_end" binding:"required"`
+
+	// Lines are the statement's debits, in the same format the transaction import accepts
+	Lines []domain.ImportedTransaction `json:"lines" binding:"required,min=1,dive"`
+}
+
+// MatchStatementLineRequest represents the request body for PUT /reconciliations/{id}/lines/{lineId}/match
+type MatchStatementLineRequest struct {
+	ExpenseID string `json:"expense_id" binding:"required"`
+}
+
+// ReconciliationMatch is a statement line with the expense it was matched to
+type ReconciliationMatch struct {
+	Line    *domain.StatementLine `json:"line"`
+	Expense *domain.Expense       `json:"expense"`
+}
+
+// ReconciliationWorkspace is the state of a session: what matched and what is left on either side
+type ReconciliationWorkspace struct {
+	Session *domain.ReconciliationSession `json:"session"`
+	Matched []*ReconciliationMatch        `json:"matched"`
+
+	// UnmatchedLines are statement lines no recorded expense accounts for (missing expenses)
+	UnmatchedLines []*domain.StatementLine `json:"unmatched_lines"`
+
+	// UnmatchedExpenses are unreconciled expenses in the period that no line accounts for
+	// (not yet booked, paid another way, or recorded by mistake)
+	UnmatchedExpenses []*domain.Expense `json:"unmatched_expenses"`
+
+	StatementTotal         domain.Money `json:"statement_total"`
+	MatchedTotal           domain.Money `json:"matched_total"`
+	UnmatchedLinesTotal    domain.Money `json:"unmatched_lines_total"`
+	UnmatchedExpensesTotal domain.Money `json:"unmatched_expenses_total"`
+}
+
+// StartSession imports a statement period and auto-matches its lines
+func (s *ReconciliationService) StartSession(ctx context.Context, req *StartReconciliationRequest) (*ReconciliationWorkspace, error) {
+	// Step 1: Validate the period and the account
+	start, err := time.Parse("2006-01-02", req.PeriodStart)
+	if err != nil {
+		return nil, domain.ErrInvalidStatement
+	}
+	end, err := time.Parse("2006-01-02", req.PeriodEnd)
+	if err != nil {
+		return nil, domain.ErrInvalidStatement
+	}
+	if len(req.Lines) > domain.MaxStatementLines {
+		return nil, domain.ErrInvalidStatement
+	}
+	var accountID *uuid.UUID
+	if req.AccountID != "" {
+		account, err := s.accounts.GetByID(ctx, req.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		accountID = &account.ID
+	}
+	session, err := domain.NewReconciliationSession(accountID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Turn the statement into lines; every line must fall in the period
+	lines := make([]*domain.StatementLine, 0, len(req.Lines))
+	for _, tx := range req.Lines {
+		if !session.Covers(tx.Date) || tx.Amount.Minor <= 0 {
+			return nil, domain.ErrInvalidStatement
+		}
+		lines = append(lines, domain.NewStatementLine(session.ID, tx))
+	}
+
+	// Step 3: Match the lines before saving, so the session starts out matched
+	expenses, err := s.candidateExpenses(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	autoMatch(lines, expenses)
+
+	if err := s.reconciliations.Create(ctx, session, lines); err != nil {
+		return nil, err
+	}
+	return s.workspace(ctx, session, lines, expenses)
+}
+
+// ListSessions returns all reconciliation sessions, newest statement period first
+func (s *ReconciliationService) ListSessions(ctx context.Context) ([]*domain.ReconciliationSession, error) {
+	return s.reconciliations.List(ctx)
+}
+
+// GetWorkspace returns the current state of a session
+func (s *ReconciliationService) GetWorkspace(ctx context.Context, sessionID string) (*ReconciliationWorkspace, error) {
+	session, lines, err := s.load(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.candidateExpenses(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	return s.workspace(ctx, session, lines, expenses)
+}
+
+// AutoMatch matches the session's unmatched lines again
+// Useful after the missing expenses have been recorded; existing matches are kept
+func (s *ReconciliationService) AutoMatch(ctx context.Context, sessionID string) (*ReconciliationWorkspace, error) {
+	session, lines, err := s.loadOpen(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.candidateExpenses(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	if changed := autoMatch(lines, expenses); len(changed) > 0 {
+		if err := s.reconciliations.SaveLines(ctx, changed); err != nil {
+			return nil, err
+		}
+	}
+	return s.workspace(ctx, session, lines, expenses)
+}
+
+// MatchLine matches a statement line to the expense chosen by the user, replacing any earlier match
+func (s *ReconciliationService) MatchLine(ctx context.Context, sessionID, lineID string, req *MatchStatementLineRequest) (*domain.StatementLine, error) {
+	_, lines, err := s.loadOpen(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	line, err := findLine(lines, lineID)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.expenses.GetByID(ctx, req.ExpenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense: %w", err)
+	}
+
+	// An expense accounts for one statement line only, and never for a line of another period
+	if expense.ReconciledAt != nil {
+		return nil, domain.ErrExpenseAlreadyMatched
+	}
+	for _, other := range lines {
+		if other != line && other.ExpenseID != nil && *other.ExpenseID == expense.ID {
+			return nil, domain.ErrExpenseAlreadyMatched
+		}
+	}
+
+	line.Match(expense.ID, 1)
+	if err := s.reconciliations.SaveLines(ctx, []*domain.StatementLine{line}); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// UnmatchLine removes the match of a statement line
+func (s *ReconciliationService) UnmatchLine(ctx context.Context, sessionID, lineID string) (*domain.StatementLine, error) {
+	_, lines, err := s.loadOpen(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	line, err := findLine(lines, lineID)
+	if err != nil {
+		return nil, err
+	}
+	line.Unmatch()
+	if err := s.reconciliations.SaveLines(ctx, []*domain.StatementLine{line}); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// CloseSession ends a session and marks every matched expense as reconciled
+// Unmatched lines and expenses are allowed: they stay visible in the closed session's workspace
+func (s *ReconciliationService) CloseSession(ctx context.Context, sessionID string) (*ReconciliationWorkspace, error) {
+	session, lines, err := s.loadOpen(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.candidateExpenses(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	session.Status = domain.ReconciliationClosed
+	session.ClosedAt = &now
+
+	var expenseIDs []uuid.UUID
+	for _, line := range lines {
+		if line.IsMatched() {
+			expenseIDs = append(expenseIDs, *line.ExpenseID)
+		}
+	}
+	if err := s.reconciliations.Close(ctx, session, expenseIDs); err != nil {
+		return nil, err
+	}
+
+	// Reflect the update in the expenses we already loaded
+	for _, expense := range expenses {
+		for _, id := range expenseIDs {
+			if expense.ID == id {
+				expense.ReconciledAt = &now
+			}
+		}
+	}
+	return s.workspace(ctx, session, lines, expenses)
+}
+
+// load returns a session and its lines
+func (s *ReconciliationService) load(ctx context.Context, sessionID string) (*domain.ReconciliationSession, []*domain.StatementLine, error) {
+	session, err := s.reconciliations.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := s.reconciliations.Lines(ctx, session.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, lines, nil
+}
+
+// loadOpen is load for operations that change the session, which closed sessions don't allow
+func (s *ReconciliationService) loadOpen(ctx context.Context, sessionID string) (*domain.ReconciliationSession, []*domain.StatementLine, error) {
+	session, lines, err := s.load(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !session.IsOpen() {
+		return nil, nil, domain.ErrReconciliationClosed
+	}
+	return session, lines, nil
+}
+
+// candidateExpenses returns the expenses a line of the session may match
+// The period is widened by the match window on both sides because of booking delays
+func (s *ReconciliationService) candidateExpenses(ctx context.Context, session *domain.ReconciliationSession) ([]*domain.Expense, error) {
+	filters := map[string]interface{}{
+		"date_from": session.PeriodStart.AddDate(0, 0, -domain.ReconciliationMatchWindow).Format("2006-01-02"),
+		"date_to":   session.PeriodEnd.AddDate(0, 0, domain.ReconciliationMatchWindow+1).Format("2006-01-02"),
+	}
+	if session.AccountID != nil {
+		filters["account_id"] = session.AccountID.String()
+	}
+	expenses, err := s.expenses.GetAll(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load expenses for reconciliation: %w", err)
+	}
+	return expenses, nil
+}
+
+// workspace assembles the matched and unmatched items of a session
+func (s *ReconciliationService) workspace(ctx context.Context, session *domain.ReconciliationSession, lines []*domain.StatementLine, expenses []*domain.Expense) (*ReconciliationWorkspace, error) {
+	ws := &ReconciliationWorkspace{
+		Session:           session,
+		Matched:           []*ReconciliationMatch{},
+		UnmatchedLines:    []*domain.StatementLine{},
+		UnmatchedExpenses: []*domain.Expense{},
+	}
+
+	byID := make(map[uuid.UUID]*domain.Expense, len(expenses))
+	for _, expense := range expenses {
+		byID[expense.ID] = expense
+	}
+
+	// Left side: the statement
+	matched := make(map[uuid.UUID]bool)
+	for _, line := range lines {
+		ws.StatementTotal = ws.StatementTotal.Add(line.Amount)
+		if !line.IsMatched() {
+			ws.UnmatchedLines = append(ws.UnmatchedLines, line)
+			ws.UnmatchedLinesTotal = ws.UnmatchedLinesTotal.Add(line.Amount)
+			continue
+		}
+		matched[*line.ExpenseID] = true
+		expense, ok := byID[*line.ExpenseID]
+		if !ok {
+			// Manual matches may point outside the candidate window
+			var err error
+			if expense, err = s.expenses.GetByID(ctx, line.ExpenseID.String()); err != nil {
+				return nil, fmt.Errorf("failed to get matched expense: %w", err)
+			}
+		}
+		ws.Matched = append(ws.Matched, &ReconciliationMatch{Line: line, Expense: expense})
+		ws.MatchedTotal = ws.MatchedTotal.Add(line.Amount)
+	}
+
+	// Right side: unreconciled expenses of the period that no line accounts for
+	for _, expense := range expenses {
+		if matched[expense.ID] || expense.ReconciledAt != nil || !session.Covers(expense.Date) {
+			continue
+		}
+		ws.UnmatchedExpenses = append(ws.UnmatchedExpenses, expense)
+		ws.UnmatchedExpensesTotal = ws.UnmatchedExpensesTotal.Add(expense.Amount)
+	}
+	sort.SliceStable(ws.UnmatchedExpenses, func(i, j int) bool {
+		return ws.UnmatchedExpenses[i].Date.Before(ws.UnmatchedExpenses[j].Date)
+	})
+
+	return ws, nil
+}
+
+// autoMatch pairs unmatched lines with free, unreconciled expenses and returns the lines it matched
+// The best-scoring pairs are taken first, so when two expenses have the same amount the one
+// closest in date wins, and every expense is used at most once
+func autoMatch(lines []*domain.StatementLine, expenses []*domain.Expense) []*domain.StatementLine {
+	taken := make(map[uuid.UUID]bool)
+	for _, line := range lines {
+		if line.IsMatched() {
+			taken[*line.ExpenseID] = true
+		}
+	}
+
+	type pair struct {
+		line    *domain.StatementLine
+		expense *domain.Expense
+		score   float64
+	}
+	var pairs []pair
+	for _, line := range lines {
+		if line.IsMatched() {
+			continue
+		}
+		for _, expense := range expenses {
+			if taken[expense.ID] || expense.ReconciledAt != nil {
+				continue
+			}
+			if score := domain.ScoreStatementMatch(line, expense); score > 0 {
+				pairs = append(pairs, pair{line: line, expense: expense, score: score})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].score > pairs[j].score })
+
+	var changed []*domain.StatementLine
+	for _, p := range pairs {
+		if p.line.IsMatched() || taken[p.expense.ID] {
+			continue
+		}
+		p.line.Match(p.expense.ID, p.score)
+		taken[p.expense.ID] = true
+		changed = append(changed, p.line)
+	}
+	return changed
+}
+
+// findLine returns the line of the session with the given ID
+func findLine(lines []*domain.StatementLine, lineID string) (*domain.StatementLine, error) {
+	id, err := uuid.Parse(lineID)
+	if err != nil {
+		return nil, domain.ErrStatementLineNotFound
+	}
+	for _, line := range lines {
+		if line.ID == id {
+			return line, nil
+		}
+	}
+	return nil, domain.ErrStatementLineNotFound
+}
@@ -0,0 +1,150 @@
+// Package application contains the business logic and use cases
+// This file contains the use cases for refunds of expenses
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For identifying the refunded expense
+)
+
+// CreateRefundRequest represents the request to record a refund of an expense
+type CreateRefundRequest struct {
+	// Amount is what was given back, in the currency of the expense (must be above 0)
+	Amount domain.Money `json:"amount"`
+
+	// Date is when the money came back; it can't be before the expense
+	Date time.Time `json:"date" binding:"required"`
+
+	// Description defaults to "Refund: <description of the expense>"
+	Description string `json:"description"`
+}
+
+// RefundSummary is an expense with its refunds
+type RefundSummary struct {
+	Expense *domain.Expense   `json:"expense"`
+	Refunds []*domain.Expense `json:"refunds"`
+
+	// Refunded is what the refunds gave back and Net what the expense cost after them,
+	// both in the currency of the expense
+	Refunded domain.Money `json:"refunded"`
+	Net      domain.Money `json:"net"`
+}
+
+// RefundExpense records a refund of one of the caller's expenses
+// The expense and its earlier refunds are read and the refund saved in one transaction
+func (s *Service) RefundExpense(ctx context.Context, id string, req *CreateRefundRequest) (*domain.Expense, error) {
+	var refund *domain.Expense
+	err := s.withinTransaction(ctx, func(ctx context.Context) error {
+		// Step 1: The expense and what was already refunded
+		parent, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get expense: %w", err)
+		}
+		refunds, err := s.refundsOf(ctx, parent.ID)
+		if err != nil {
+			return err
+		}
+
+		// Step 2: Save the refund
+		refund, err = domain.NewRefund(parent, req.Amount, domain.RefundedAmount(refunds), req.Description, req.Date)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, refund); err != nil {
+			return fmt.Errorf("failed to save refund: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// ListRefunds returns one of the caller's expenses with its refunds
+func (s *Service) ListRefunds(ctx context.Context, id string) (*RefundSummary, error) {
+	expense, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense: %w", err)
+	}
+	if expense.IsRefund() {
+		return nil, domain.ErrInvalidRefund
+	}
+	refunds, err := s.refundsOf(ctx, expense.ID)
+	if err != nil {
+		return nil, err
+	}
+	refunded := domain.RefundedAmount(refunds)
+	return &RefundSummary{
+		Expense:  expense,
+		Refunds:  refunds,
+		Refunded: refunded,
+		Net:      expense.Amount.Sub(refunded),
+	}, nil
+}
+
+// refundsOf returns the refunds of an expense
+func (s *Service) refundsOf(ctx context.Context, parentID uuid.UUID) ([]*domain.Expense, error) {
+	refunds, err := s.repo.GetAll(ctx, map[string]interface{}{"parent_expense_id": parentID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refunds: %w", err)
+	}
+	return refunds, nil
+}
+
+// checkRefunds keeps an update from breaking the link between expenses and their refunds
+// A refund keeps the currency, conversion and employer of its expense and can't give back more
+// than is left of it; an expense can't be lowered below what was refunded of it
+func (s *Service) checkRefunds(ctx context.Context, expense *domain.Expense, req *UpdateExpenseRequest) error {
+	// Step 1: Ordinary expenses only matter when their amount changes and they were refunded
+	if !expense.IsRefund() {
+		if req.Amount.Minor <= 0 {
+			return nil
+		}
+		refunds, err := s.refundsOf(ctx, expense.ID)
+		if err != nil {
+			return err
+		}
+		if domain.RefundedAmount(refunds).Minor > expense.Amount.Minor {
+			return domain.ErrRefundExceedsExpense
+		}
+		return nil
+	}
+
+	// Step 2: A refund can't change what it inherits from its expense
+	if req.Currency != "" || req.ExchangeRate != 0 || !req.ConvertedAmount.IsZero() || req.TaxAmount != nil ||
+		(req.Reimbursable != nil && *req.Reimbursable) || (req.EmployerID != nil && *req.EmployerID != "") {
+		return domain.ErrInvalidRefund
+	}
+	if req.Amount.Minor <= 0 && req.Date.IsZero() {
+		return nil
+	}
+
+	// Step 3: Together with the other refunds it still fits in the expense, after it
+	parent, err := s.repo.GetByID(ctx, expense.ParentExpenseID.String())
+	if err != nil {
+		return fmt.Errorf("failed to get refunded expense: %w", err)
+	}
+	if !parent.RefundableOn(expense.Date) {
+		return domain.ErrInvalidRefund
+	}
+	refunds, err := s.refundsOf(ctx, parent.ID)
+	if err != nil {
+		return err
+	}
+	others := make([]*domain.Expense, 0, len(refunds))
+	for _, refund := range refunds {
+		if refund.ID != expense.ID {
+			others = append(others, refund)
+		}
+	}
+	if domain.RefundedAmount(others).Sub(expense.Amount).Minor > parent.Amount.Minor {
+		return domain.ErrRefundExceedsExpense
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+// Package application contains the business logic and use cases
+// This file contains the reimbursement lifecycle: claiming reimbursable expenses in batches
+// and reconciling the batches when their payout arrives
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For the day a payout arrived
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For expense and employer IDs
+)
+
+// ReimbursementService moves reimbursable expenses from pending to submitted to paid
+// Expenses are submitted together in a batch, e.g. the monthly expense report, and the
+// batch is paid when the money arrives; paying a batch charged to an employer also records
+// the payout on the employer's balance
+type ReimbursementService struct {
+	reimbursements domain.ReimbursementRepository
+	employers      domain.EmployerRepository
+}
+
+// NewReimbursementService creates a new reimbursement service
+func NewReimbursementService(reimbursements domain.ReimbursementRepository, employers domain.EmployerRepository) *ReimbursementService {
+	return &ReimbursementService{
+		reimbursements: reimbursements,
+		employers:      employers,
+	}
+}
+
+// SubmitBatchRequest represents the request body for POST /reimbursements/batches
+type SubmitBatchRequest struct {
+	// ExpenseIDs are the pending reimbursable expenses to claim
+	ExpenseIDs []string `json:"expense_ids" binding:"required,min=1"`
+
+	// EmployerID is who they are claimed from (optional); every expense must be charged to it
+	EmployerID string `json:"employer_id"`
+
+	// Reference identifies the claim (optional), e.g. "Expense report March"
+	Reference string `json:"reference"`
+}
+
+// MarkBatchPaidRequest represents the request body for POST /reimbursements/batches/{id}/paid
+type MarkBatchPaidRequest struct {
+	// PaidOn is the day the money arrived
+	PaidOn time.Time `json:"paid_on" binding:"required"`
+
+	// Amount is what arrived, in the base currency; left out, the claim was paid in full
+	// MarkPaid rejects negative amounts
+	Amount domain.Money `json:"amount"`
+}
+
+// ReimbursementBatchDetail is a batch with the expenses claimed in it
+type ReimbursementBatchDetail struct {
+	*domain.ReimbursementBatch
+	ExpenseList []*domain.Expense `json:"expense_list"`
+}
+
+// Summary returns where the caller's reimbursable expenses stand, and the batches still waiting to be paid
+func (s *ReimbursementService) Summary(ctx context.Context) (*domain.ReimbursementSummary, error) {
+	// Step 1: The expenses per status
+	totals, err := s.reimbursements.Totals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summary := &domain.ReimbursementSummary{
+		Pending:   domain.ReimbursementTotal{Status: domain.ReimbursementPending},
+		Submitted: domain.ReimbursementTotal{Status: domain.ReimbursementSubmitted},
+		Paid:      domain.ReimbursementTotal{Status: domain.ReimbursementPaid},
+		Open:      []*domain.ReimbursementBatch{},
+	}
+	for _, total := range totals {
+		target := map[string]*domain.ReimbursementTotal{
+			domain.ReimbursementPending:   &summary.Pending,
+			domain.ReimbursementSubmitted: &summary.Submitted,
+			domain.ReimbursementPaid:      &summary.Paid,
+		}[total.Status]
+		if target != nil {
+			target.Count, target.Amount = total.Count, total.Amount
+		}
+	}
+
+	// Step 2: The batches waiting for their payout, and what paid ones fell short
+	batches, err := s.reimbursements.ListBatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(batches) - 1; i >= 0; i-- {
+		batch := batches[i]
+		switch {
+		case batch.Status == domain.ReimbursementSubmitted:
+			summary.Open = append(summary.Open, batch)
+		case batch.Difference != nil:
+			summary.Shortfall = summary.Shortfall.Sub(*batch.Difference)
+		}
+	}
+	return summary, nil
+}
+
+// SubmitBatch claims pending reimbursable expenses in a new batch, which marks them submitted
+func (s *ReimbursementService) SubmitBatch(ctx context.Context, req *SubmitBatchRequest) (*domain.ReimbursementBatch, error) {
+	// Step 1: The expenses, each once
+	ids := make([]uuid.UUID, 0, len(req.ExpenseIDs))
+	seen := make(map[uuid.UUID]bool, len(req.ExpenseIDs))
+	for _, raw := range req.ExpenseIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, domain.ErrExpenseNotFound
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > domain.MaxReimbursementBatchSize {
+		return nil, domain.ErrInvalidReimbursementBatch
+	}
+	expenses, err := s.reimbursements.ClaimableExpenses(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: The employer they are claimed from, which must be one of the caller's
+	var employerID *uuid.UUID
+	if req.EmployerID != "" {
+		employer, err := s.employers.GetByID(ctx, req.EmployerID)
+		if err != nil {
+			return nil, err
+		}
+		employerID = &employer.ID
+	}
+
+	// Step 3: Create the batch
+	batch, err := domain.NewReimbursementBatch(employerID, req.Reference, expenses)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reimbursements.CreateBatch(ctx, batch, ids); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// ListBatches returns the caller's batches, newest first
+func (s *ReimbursementService) ListBatches(ctx context.Context) ([]*domain.ReimbursementBatch, error) {
+	return s.reimbursements.ListBatches(ctx)
+}
+
+// GetBatch returns one of the caller's batches with its expenses
+func (s *ReimbursementService) GetBatch(ctx context.Context, id string) (*ReimbursementBatchDetail, error) {
+	batch, err := s.reimbursements.GetBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.reimbursements.BatchExpenses(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ReimbursementBatchDetail{ReimbursementBatch: batch, ExpenseList: expenses}, nil
+}
+
+// MarkBatchPaid records the payout of a batch and marks its expenses paid
+// For a batch claimed from an employer, the payout is also recorded as a reimbursement
+// from it, so the employer's balance goes down by what actually arrived
+func (s *ReimbursementService) MarkBatchPaid(ctx context.Context, id string, req *MarkBatchPaidRequest) (*domain.ReimbursementBatch, error) {
+	batch, err := s.reimbursements.GetBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := batch.MarkPaid(req.Amount, req.PaidOn); err != nil {
+		return nil, err
+	}
+
+	var income *domain.ReimbursementIncome
+	if batch.EmployerID != nil {
+		reference := batch.Reference
+		if reference == "" {
+			reference = "Reimbursement batch " + batch.SubmittedAt.Format("2006-01-02")
+		}
+		if income, err = domain.NewReimbursementIncome(*batch.EmployerID, *batch.Paid, *batch.PaidOn, reference); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.reimbursements.MarkPaid(ctx, batch, income); err != nil {
+		return nil, fmt.Errorf("failed to mark reimbursement batch paid: %w", err)
+	}
+	return batch, nil
+}
+
+// WithdrawBatch deletes a batch that hasn't been paid, so its expenses are pending again
+func (s *ReimbursementService) WithdrawBatch(ctx context.Context, id string) error {
+	batch, err := s.reimbursements.GetBatch(ctx, id)
+	if err != nil {
+		return err
+	}
+	if batch.Status != domain.ReimbursementSubmitted {
+		return domain.ErrReimbursementBatchPaid
+	}
+	return s.reimbursements.DeleteBatch(ctx, batch)
+}
@@ -0,0 +1,440 @@
+// Package application contains the business logic and use cases
+// This file contains the signed archives of approved trip reports: once the last approval is given the
+// trip is frozen and its report, expenses and receipts are packed into a zip file whose manifest lists
+// the checksum of every file and carries the server's signature, so the archive can be verified later
+package application
+
+import (
+	"archive/zip"   // The archive container
+	"bytes"         // For the report files built in memory
+	"context"       // For request context (cancellation, timeouts)
+	"crypto/sha256" // For the file and manifest checksums
+	"encoding/hex"  // For printing checksums
+	"encoding/json" // For the manifest, the signature and the JSON report
+	"errors"        // For matching storage errors
+	"fmt"           // For formatted string operations and error wrapping
+	"hash"          // For hashing files while they are written
+	"io"            // For streaming files into and out of the archive
+	"strings"       // For building safe file names
+	"time"          // For handling dates and times
+
+	"myexpenses/internal/auth"            // The server's signing key
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/report"          // For the PDF copy of the report
+	"myexpenses/internal/storage"         // Where receipts and archives are kept
+)
+
+// Report archive layout
+const (
+	// ArchiveFormat identifies the archive layout in its manifest
+	ArchiveFormat = "myexpenses-report-archive/1"
+
+	// archiveManifest lists every other file of the archive with its checksum
+	archiveManifest = "manifest.json"
+
+	// archiveSignature holds the checksum of the manifest and the server's signature over it
+	archiveSignature = "signature.json"
+
+	// maxArchiveManifestBytes bounds how much of a manifest is read when verifying an archive
+	maxArchiveManifestBytes = 16 << 20
+)
+
+// AuditTripApproved is recorded when a trip report is approved and archived
+const AuditTripApproved = "trip.approved"
+
+// ArchiveFile is one file of an archive as listed in its manifest
+type ArchiveFile struct {
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	ContentType string `json:"content_type,omitempty"`
+
+	// ExpenseID is the expense a receipt documents (empty for the report files)
+	ExpenseID string `json:"expense_id,omitempty"`
+}
+
+// ArchiveManifest describes an archive: which trip, who approved it and what it contains
+type ArchiveManifest struct {
+	Format     string         `json:"format"`
+	TripID     string         `json:"trip_id"`
+	TripName   string         `json:"trip_name"`
+	ApprovedAt time.Time      `json:"approved_at"`
+	ApprovedBy string         `json:"approved_by"`
+	Files      []*ArchiveFile `json:"files"`
+}
+
+// ArchiveSignature is the signature file of an archive
+// Checksum is the hex SHA-256 of manifest.json and Signature the server's signature over that digest;
+// the public key is included so an auditor can check the signature without asking the server
+type ArchiveSignature struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"`
+	Checksum  string `json:"checksum"`
+	Signature string `json:"signature"`
+}
+
+// ArchiveVerification is the outcome of verifying an archive
+type ArchiveVerification struct {
+	// Valid is true when every file matches the manifest and the manifest carries this server's signature
+	Valid bool `json:"valid"`
+
+	// SignatureValid means the manifest was signed by the key in the archive;
+	// SignedByServer that this key is the server's current key
+	SignatureValid bool `json:"signature_valid"`
+	SignedByServer bool `json:"signed_by_server"`
+
+	// MatchesTrip means the checksum is the one recorded when the trip was approved
+	// (false when the trip isn't known here, e.g. the archive comes from another deployment)
+	MatchesTrip bool `json:"matches_trip"`
+
+	TripID     string     `json:"trip_id,omitempty"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+	ApprovedBy string     `json:"approved_by,omitempty"`
+	Checksum   string     `json:"checksum,omitempty"`
+	KeyID      string     `json:"key_id,omitempty"`
+	Files      int        `json:"files"`
+
+	// Problems explains everything that didn't verify
+	Problems []string `json:"problems"`
+}
+
+// ArchivePublicKey is the key that verifies the archives signed by this server
+type ArchivePublicKey struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"`
+}
+
+// ReportArchiveService makes and verifies the signed archives of approved trip reports
+type ReportArchiveService struct {
+	trips       domain.TripRepository
+	attachments domain.AttachmentRepository
+	store       storage.Storage
+	signer      *auth.ArchiveSigner
+	audit       domain.AuditRepository
+}
+
+// NewReportArchiveService creates a new report archive service
+// signer signs the manifests of the archives
+func NewReportArchiveService(trips domain.TripRepository, attachments domain.AttachmentRepository, store storage.Storage, signer *auth.ArchiveSigner, audit domain.AuditRepository) *ReportArchiveService {
+	return &ReportArchiveService{
+		trips:       trips,
+		attachments: attachments,
+		store:       store,
+		signer:      signer,
+		audit:       audit,
+	}
+}
+
+// PublicKey returns the key that verifies this server's archives
+func (s *ReportArchiveService) PublicKey() *ArchivePublicKey {
+	return &ArchivePublicKey{Algorithm: auth.ArchiveSignatureAlgorithm, KeyID: s.signer.KeyID(), PublicKey: s.signer.PublicKey()}
+}
+
+// freeze stores the signed archive of an approved report and freezes its trip
+// The caller has stamped the approval (ApprovedAt and ApprovedBy) on result.Trip
+func (s *ReportArchiveService) freeze(ctx context.Context, result *TripReport) (*domain.Trip, error) {
+	trip := result.Trip
+	now := *trip.ApprovedAt
+
+	// Step 1: Write the archive into storage while it is being built
+	// Every approval gets its own key, so a lost race never overwrites the winner's archive
+	key := "archives/trips/" + trip.ID.String() + "/" + now.Format("20060102T150405.000000000Z") + ".zip"
+	reader, writer := io.Pipe()
+	signed := make(chan *ArchiveSignature, 1)
+	go func() {
+		signature, err := s.writeArchive(ctx, writer, result)
+		signed <- signature
+		writer.CloseWithError(err)
+	}()
+	size, err := s.store.Put(ctx, key, reader)
+	reader.CloseWithError(err)
+	signature := <-signed
+	if err != nil {
+		_ = s.store.Delete(ctx, key)
+		return nil, fmt.Errorf("failed to store report archive: %w", err)
+	}
+
+	// Step 2: Freeze the trip; if someone else approved it first, their archive stands
+	trip.ArchiveKey = key
+	trip.ArchiveSize = size
+	trip.ArchiveChecksum = signature.Checksum
+	trip.ArchiveSignature = signature.Signature
+	if err := s.trips.Approve(ctx, trip); err != nil {
+		_ = s.store.Delete(ctx, key)
+		return nil, err
+	}
+
+	// Step 3: Record the approval
+	entry, err := domain.NewAuditEntry(trip.ApprovedBy, AuditTripApproved, "trip", trip.ID.String(), map[string]any{
+		"checksum": signature.Checksum,
+		"key_id":   signature.KeyID,
+		"expenses": len(result.Expenses),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to record approval: %w", err)
+	}
+	return trip, nil
+}
+
+// OpenArchive opens the signed archive of an approved trip
+// The caller is responsible for closing the returned reader
+func (s *ReportArchiveService) OpenArchive(ctx context.Context, tripID string) (*domain.Trip, io.ReadCloser, error) {
+	trip, err := s.trips.GetByID(ctx, tripID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !trip.Approved() || trip.ArchiveKey == "" {
+		return nil, nil, domain.ErrTripNotApproved
+	}
+	content, err := s.store.Get(ctx, trip.ArchiveKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open report archive: %w", err)
+	}
+	return trip, content, nil
+}
+
+// writeArchive writes the archive of an approved report to w and returns its signature
+// The report files and receipts come first; the manifest can only be written once all of them are hashed
+func (s *ReportArchiveService) writeArchive(ctx context.Context, w io.Writer, result *TripReport) (*ArchiveSignature, error) {
+	trip := result.Trip
+	archive := zip.NewWriter(w)
+	manifest := &ArchiveManifest{
+		Format:     ArchiveFormat,
+		TripID:     trip.ID.String(),
+		TripName:   trip.Name,
+		ApprovedAt: *trip.ApprovedAt,
+		ApprovedBy: trip.ApprovedBy,
+		Files:      []*ArchiveFile{},
+	}
+
+	// Step 1: The report, as data and as the PDF handed to the employer
+	encoded, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	file, err := addArchiveFile(archive, "report.json", "application/json", *trip.ApprovedAt, bytes.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+	manifest.Files = append(manifest.Files, file)
+
+	var pdf bytes.Buffer
+	if err := (report.PDFRenderer{}).Render(&pdf, result.Document()); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	file, err = addArchiveFile(archive, "report.pdf", "application/pdf", *trip.ApprovedAt, &pdf)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Files = append(manifest.Files, file)
+
+	// Step 2: Every receipt of every expense; a receipt missing from storage fails the approval,
+	// since an archive without it would not be complete
+	for _, expense := range result.Expenses {
+		attachments, err := s.attachments.ListByExpense(ctx, expense.ID.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list receipts: %w", err)
+		}
+		for _, attachment := range attachments {
+			content, err := s.store.Get(ctx, attachment.StorageKey)
+			if err != nil {
+				if errors.Is(err, storage.ErrObjectNotFound) {
+					return nil, fmt.Errorf("receipt %s of expense %s is missing from storage", attachment.ID, expense.ID)
+				}
+				return nil, fmt.Errorf("failed to open receipt: %w", err)
+			}
+			name := "receipts/" + expense.ID.String() + "/" + attachment.ID.String() + "-" + archiveFileName(attachment.FileName)
+			file, err := addArchiveFile(archive, name, attachment.ContentType, *trip.ApprovedAt, content)
+			content.Close()
+			if err != nil {
+				return nil, err
+			}
+			file.ExpenseID = expense.ID.String()
+			manifest.Files = append(manifest.Files, file)
+		}
+	}
+
+	// Step 3: The manifest, then its checksum and signature
+	encoded, err = json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := addArchiveFile(archive, archiveManifest, "application/json", *trip.ApprovedAt, bytes.NewReader(encoded)); err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(encoded)
+	signature := &ArchiveSignature{
+		Algorithm: auth.ArchiveSignatureAlgorithm,
+		KeyID:     s.signer.KeyID(),
+		PublicKey: s.signer.PublicKey(),
+		Checksum:  hex.EncodeToString(digest[:]),
+		Signature: s.signer.Sign(digest[:]),
+	}
+	encoded, err = json.MarshalIndent(signature, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := addArchiveFile(archive, archiveSignature, "application/json", *trip.ApprovedAt, bytes.NewReader(encoded)); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish report archive: %w", err)
+	}
+	return signature, nil
+}
+
+// addArchiveFile copies r into the archive as name and returns its manifest entry
+func addArchiveFile(archive *zip.Writer, name, contentType string, modified time.Time, r io.Reader) (*ArchiveFile, error) {
+	w, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, hasher), r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	return &ArchiveFile{Path: name, Size: size, SHA256: hex.EncodeToString(hasher.Sum(nil)), ContentType: contentType}, nil
+}
+
+// archiveFileName makes an uploaded file name safe to use inside the archive
+// Path separators would create directories (or escape the receipt folder when extracted)
+func archiveFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "receipt"
+	}
+	return name
+}
+
+// Verify checks an archive: every file against the manifest, the manifest against its checksum,
+// the checksum against the signature and, when the trip is known here, against the approval
+// It returns domain.ErrInvalidArchive when r isn't a report archive at all
+func (s *ReportArchiveService) Verify(ctx context.Context, r io.ReaderAt, size int64) (*ArchiveVerification, error) {
+	// Step 1: Open the archive and read the manifest and the signature
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, domain.ErrInvalidArchive
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	rawManifest, err := readArchiveEntry(files[archiveManifest])
+	if err != nil {
+		return nil, err
+	}
+	rawSignature, err := readArchiveEntry(files[archiveSignature])
+	if err != nil {
+		return nil, err
+	}
+	var manifest ArchiveManifest
+	var signature ArchiveSignature
+	if json.Unmarshal(rawManifest, &manifest) != nil || json.Unmarshal(rawSignature, &signature) != nil || manifest.Format != ArchiveFormat {
+		return nil, domain.ErrInvalidArchive
+	}
+
+	result := &ArchiveVerification{
+		TripID:     manifest.TripID,
+		ApprovedAt: &manifest.ApprovedAt,
+		ApprovedBy: manifest.ApprovedBy,
+		Checksum:   signature.Checksum,
+		KeyID:      signature.KeyID,
+		Files:      len(manifest.Files),
+		Problems:   []string{},
+	}
+
+	// Step 2: The manifest must match its checksum, and the checksum its signature
+	digest := sha256.Sum256(rawManifest)
+	if hex.EncodeToString(digest[:]) != signature.Checksum {
+		result.Problems = append(result.Problems, "manifest.json does not match the signed checksum")
+	}
+	result.SignatureValid = signature.Algorithm == auth.ArchiveSignatureAlgorithm &&
+		auth.VerifyArchiveSignature(signature.PublicKey, digest[:], signature.Signature)
+	if !result.SignatureValid {
+		result.Problems = append(result.Problems, "the signature does not match the manifest")
+	}
+	result.SignedByServer = result.SignatureValid && signature.PublicKey == s.signer.PublicKey()
+	if result.SignatureValid && !result.SignedByServer {
+		result.Problems = append(result.Problems, "the archive was signed with a key this server doesn't use")
+	}
+
+	// Step 3: Every file must match its checksum, and nothing may have been added
+	listed := make(map[string]bool, len(manifest.Files)+2)
+	listed[archiveManifest], listed[archiveSignature] = true, true
+	for _, file := range manifest.Files {
+		listed[file.Path] = true
+		f, ok := files[file.Path]
+		if !ok {
+			result.Problems = append(result.Problems, file.Path+" is missing")
+			continue
+		}
+		sum, n, err := hashArchiveEntry(f, sha256.New())
+		if err != nil {
+			result.Problems = append(result.Problems, file.Path+" can't be read")
+			continue
+		}
+		if sum != file.SHA256 || n != file.Size {
+			result.Problems = append(result.Problems, file.Path+" has been modified")
+		}
+	}
+	for _, f := range archive.File {
+		if !listed[f.Name] {
+			result.Problems = append(result.Problems, f.Name+" is not part of the signed archive")
+		}
+	}
+
+	// Step 4: Compare with the approval recorded here
+	if trip, err := s.trips.GetByID(ctx, manifest.TripID); err == nil {
+		result.MatchesTrip = trip.ArchiveChecksum != "" && trip.ArchiveChecksum == signature.Checksum
+		if !result.MatchesTrip {
+			result.Problems = append(result.Problems, "the checksum is not the one recorded when the trip was approved")
+		}
+	} else if !errors.Is(err, domain.ErrTripNotFound) {
+		return nil, err
+	}
+
+	result.Valid = len(result.Problems) == 0 && result.SignedByServer
+	return result, nil
+}
+
+// readArchiveEntry reads a small archive file (the manifest or the signature)
+func readArchiveEntry(f *zip.File) ([]byte, error) {
+	if f == nil {
+		return nil, domain.ErrInvalidArchive
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, domain.ErrInvalidArchive
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, maxArchiveManifestBytes+1))
+	if err != nil || len(data) > maxArchiveManifestBytes {
+		return nil, domain.ErrInvalidArchive
+	}
+	return data, nil
+}
+
+// hashArchiveEntry returns the hex checksum and the size of an archive file
+func hashArchiveEntry(f *zip.File, hasher hash.Hash) (string, int64, error) {
+	r, err := f.Open()
+	if err != nil {
+		return "", 0, err
+	}
+	defer r.Close()
+	n, err := io.Copy(hasher, r)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), n, nil
+}
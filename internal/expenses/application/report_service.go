@@ -0,0 +1,486 @@
+// Package application contains the business logic and use cases
+// This file contains the reporting use cases
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For ordering report rows
+	"time"    // For reports as of a past time
+
+	"myexpenses/internal/auth"            // Request-scoped caller identity
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/report"          // Shared report renderers
+)
+
+// ReportService builds spending reports
+type ReportService struct {
+	spending domain.SpendingRepository
+	flags    domain.FlagRepository
+	tax      domain.TaxRepository
+
+	// categories nests categories, for rolling subcategory spend up into its top-level category
+	categories domain.CategoryRepository
+
+	// history rebuilds reports as the expenses were at a past time
+	history domain.ReportHistory
+}
+
+// ReportServiceOption configures optional behavior of the report service
+type ReportServiceOption func(*ReportService)
+
+// WithCategoryRollup lets reports roll subcategory spend up into the top-level categories
+// Without it, rolled-up reports fail with domain.ErrRollupUnavailable
+func WithCategoryRollup(categories domain.CategoryRepository) ReportServiceOption {
+	return func(s *ReportService) {
+		s.categories = categories
+	}
+}
+
+// WithReportHistory lets reports be built as the expenses were at a past time
+// Without it, reports as of a past time fail with domain.ErrReportHistoryUnavailable
+func WithReportHistory(history domain.ReportHistory) ReportServiceOption {
+	return func(s *ReportService) {
+		s.history = history
+	}
+}
+
+// NewReportService creates a new report service
+func NewReportService(spending domain.SpendingRepository, flags domain.FlagRepository, tax domain.TaxRepository, opts ...ReportServiceOption) *ReportService {
+	s := &ReportService{spending: spending, flags: flags, tax: tax}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CategoryDelta compares one category's spending across two periods
+type CategoryDelta struct {
+	Category string       `json:"category"`
+	AmountA  domain.Money `json:"amount_a"`
+	AmountB  domain.Money `json:"amount_b"`
+	Delta    domain.Money `json:"delta"`
+
+	// ChangePercent is (B - A) / A * 100, or null when nothing was spent in period A
+	ChangePercent *float64 `json:"change_percent"`
+}
+
+// ComparisonReport compares spending between two arbitrary periods ("self vs. past self")
+type ComparisonReport struct {
+	PeriodA domain.Period `json:"period_a"`
+	PeriodB domain.Period `json:"period_b"`
+
+	TotalA        domain.Money `json:"total_a"`
+	TotalB        domain.Money `json:"total_b"`
+	TotalDelta    domain.Money `json:"total_delta"`
+	ChangePercent *float64     `json:"change_percent"`
+
+	// Categories holds one row per category spent in either period, biggest change first
+	Categories []*CategoryDelta `json:"categories"`
+
+	// RolledUp is set when subcategory spend was added to the top-level categories
+	RolledUp bool `json:"rolled_up"`
+
+	// AsOf is the past time the report was rebuilt as of, if any
+	AsOf *time.Time `json:"as_of,omitempty"`
+
+	// NewMerchants were spent at in period B but not in period A
+	NewMerchants []*domain.MerchantSpending `json:"new_merchants"`
+
+	// DisappearedMerchants were spent at in period A but not in period B
+	DisappearedMerchants []*domain.MerchantSpending `json:"disappeared_merchants"`
+}
+
+// ExplainCompare returns the execution plans of the queries Compare runs for the two periods
+func (s *ReportService) ExplainCompare(ctx context.Context, periodA, periodB string) ([]*domain.QueryPlan, error) {
+	// Query plans expose the schema and run real queries, so they are for admins only
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	explainer, ok := s.spending.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+
+	var plans []*domain.QueryPlan
+	for _, value := range []string{periodA, periodB} {
+		period, err := domain.ParsePeriod(value)
+		if err != nil {
+			return nil, err
+		}
+		periodPlans, err := explainer.ExplainSpending(ctx, period.Start, period.End)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain period %s: %w", period.Label, err)
+		}
+		plans = append(plans, periodPlans...)
+	}
+	return plans, nil
+}
+
+// Compare builds a comparison report between periodA (the baseline) and periodB
+// With rollup, each row is a top-level category including the spend of its subcategories
+// A non-zero asOf builds it from the expenses as they were at that time
+func (s *ReportService) Compare(ctx context.Context, periodA, periodB string, rollup bool, asOf time.Time) (*ComparisonReport, error) {
+	// Step 1: Parse both periods
+	a, err := domain.ParsePeriod(periodA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := domain.ParsePeriod(periodB)
+	if err != nil {
+		return nil, err
+	}
+	spending, _, err := s.figures(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Load category and merchant totals for both periods
+	categoriesA, err := spending.SpendingByCategory(ctx, a.Start, a.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load period A: %w", err)
+	}
+	categoriesB, err := spending.SpendingByCategory(ctx, b.Start, b.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load period B: %w", err)
+	}
+	if rollup {
+		if s.categories == nil {
+			return nil, domain.ErrRollupUnavailable
+		}
+		tree, err := loadCategoryTree(ctx, s.categories)
+		if err != nil {
+			return nil, err
+		}
+		categoriesA, categoriesB = tree.RollUp(categoriesA), tree.RollUp(categoriesB)
+	}
+	merchantsA, err := spending.SpendingByMerchant(ctx, a.Start, a.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load period A: %w", err)
+	}
+	merchantsB, err := spending.SpendingByMerchant(ctx, b.Start, b.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load period B: %w", err)
+	}
+
+	result := &ComparisonReport{
+		PeriodA:              a,
+		PeriodB:              b,
+		RolledUp:             rollup,
+		AsOf:                 asOfTime(asOf),
+		NewMerchants:         merchantDifference(merchantsB, merchantsA),
+		DisappearedMerchants: merchantDifference(merchantsA, merchantsB),
+	}
+
+	// Step 3: Merge the category totals into one row per category
+	rows := make(map[string]*CategoryDelta)
+	for _, spent := range categoriesA {
+		rows[spent.Category] = &CategoryDelta{Category: spent.Category, AmountA: spent.Amount}
+		result.TotalA = result.TotalA.Add(spent.Amount)
+	}
+	for _, spent := range categoriesB {
+		row, ok := rows[spent.Category]
+		if !ok {
+			row = &CategoryDelta{Category: spent.Category}
+			rows[spent.Category] = row
+		}
+		row.AmountB = spent.Amount
+		result.TotalB = result.TotalB.Add(spent.Amount)
+	}
+
+	result.Categories = make([]*CategoryDelta, 0, len(rows))
+	for _, row := range rows {
+		row.Delta = row.AmountB.Sub(row.AmountA)
+		row.ChangePercent = percentChange(row.AmountA, row.AmountB)
+		result.Categories = append(result.Categories, row)
+	}
+	sort.Slice(result.Categories, func(i, j int) bool {
+		di, dj := result.Categories[i].Delta.Abs().Minor, result.Categories[j].Delta.Abs().Minor
+		if di != dj {
+			return di > dj
+		}
+		return result.Categories[i].Category < result.Categories[j].Category
+	})
+
+	// Step 4: Totals
+	result.TotalDelta = result.TotalB.Sub(result.TotalA)
+	result.ChangePercent = percentChange(result.TotalA, result.TotalB)
+	return result, nil
+}
+
+// figures returns where report totals come from: the current expenses for a zero asOf,
+// otherwise the expenses as they were at asOf
+func (s *ReportService) figures(ctx context.Context, asOf time.Time) (domain.SpendingRepository, domain.TaxRepository, error) {
+	if asOf.IsZero() {
+		return s.spending, s.tax, nil
+	}
+	if s.history == nil {
+		return nil, nil, domain.ErrReportHistoryUnavailable
+	}
+	past, err := s.history.FiguresAsOf(ctx, asOf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return past, past, nil
+}
+
+// asOfTime returns asOf for a report, or nil for the zero time
+func asOfTime(asOf time.Time) *time.Time {
+	if asOf.IsZero() {
+		return nil
+	}
+	return &asOf
+}
+
+// merchantDifference returns the merchants in from that don't appear in other
+func merchantDifference(from, other []*domain.MerchantSpending) []*domain.MerchantSpending {
+	seen := make(map[string]bool, len(other))
+	for _, m := range other {
+		seen[m.Merchant] = true
+	}
+
+	difference := make([]*domain.MerchantSpending, 0)
+	for _, m := range from {
+		if !seen[m.Merchant] {
+			difference = append(difference, m)
+		}
+	}
+	return difference
+}
+
+// percentChange returns the change from a to b in percent, or nil when a is zero
+func percentChange(a, b domain.Money) *float64 {
+	if a.IsZero() {
+		return nil
+	}
+	change := domain.RoundAmount(float64(b.Minor-a.Minor) / float64(a.Minor) * 100)
+	return &change
+}
+
+// Document describes the comparison report for the shared report renderers
+func (r *ComparisonReport) Document() *report.Document {
+	categories := make([]report.Row, len(r.Categories))
+	for i, c := range r.Categories {
+		categories[i] = report.Row{c.Category, c.AmountA, c.AmountB, c.Delta, c.ChangePercent}
+	}
+
+	merchantColumns := []report.Column{
+		{Key: "merchant", Title: "Merchant", Kind: report.KindText},
+		{Key: "amount", Title: "Amount", Kind: report.KindAmount},
+		{Key: "count", Title: "Expenses", Kind: report.KindNumber},
+	}
+	merchantRows := func(merchants []*domain.MerchantSpending) report.RowSource {
+		rows := make([]report.Row, len(merchants))
+		for i, m := range merchants {
+			rows[i] = report.Row{m.Merchant, m.Amount, m.Count}
+		}
+		return report.SliceRows(rows)
+	}
+
+	return &report.Document{
+		Title: fmt.Sprintf("Spending %s vs %s", r.PeriodB.Label, r.PeriodA.Label),
+		Summary: withAsOf([]report.Field{
+			{Key: "period_a", Label: "Period A", Kind: report.KindText, Value: r.PeriodA.Label},
+			{Key: "period_b", Label: "Period B", Kind: report.KindText, Value: r.PeriodB.Label},
+			{Key: "total_a", Label: "Total A", Kind: report.KindAmount, Value: r.TotalA},
+			{Key: "total_b", Label: "Total B", Kind: report.KindAmount, Value: r.TotalB},
+			{Key: "total_delta", Label: "Change", Kind: report.KindAmount, Value: r.TotalDelta},
+			{Key: "change_percent", Label: "Change %", Kind: report.KindPercent, Value: r.ChangePercent},
+		}, r.AsOf),
+		Sections: []*report.Section{
+			{
+				Key:   "categories",
+				Title: "Categories",
+				Columns: []report.Column{
+					{Key: "category", Title: "Category", Kind: report.KindText},
+					{Key: "amount_a", Title: "Period A", Kind: report.KindAmount},
+					{Key: "amount_b", Title: "Period B", Kind: report.KindAmount},
+					{Key: "delta", Title: "Change", Kind: report.KindAmount},
+					{Key: "change_percent", Title: "Change %", Kind: report.KindPercent},
+				},
+				Rows: report.SliceRows(categories),
+			},
+			{Key: "new_merchants", Title: "New merchants", Columns: merchantColumns, Rows: merchantRows(r.NewMerchants)},
+			{Key: "disappeared_merchants", Title: "Disappeared merchants", Columns: merchantColumns, Rows: merchantRows(r.DisappearedMerchants)},
+		},
+	}
+}
+
+// FlagReport counts the flagged expenses of a period, e.g. before submitting expenses for reimbursement
+type FlagReport struct {
+	Period domain.Period       `json:"period"`
+	Flags  []*domain.FlagCount `json:"flags"`
+}
+
+// Flags builds the flag report for a period
+// Every known flag is listed, with zero counts for flags nobody used
+func (s *ReportService) Flags(ctx context.Context, period string) (*FlagReport, error) {
+	p, err := domain.ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.flags.CountByFlag(ctx, p.Start, p.End)
+	if err != nil {
+		return nil, err
+	}
+
+	byFlag := make(map[domain.Flag]*domain.FlagCount, len(counts))
+	for _, count := range counts {
+		byFlag[count.Flag] = count
+	}
+	result := &FlagReport{Period: p}
+	for _, flag := range domain.Flags {
+		count, ok := byFlag[flag]
+		if !ok {
+			count = &domain.FlagCount{Flag: flag}
+		}
+		result.Flags = append(result.Flags, count)
+	}
+	return result, nil
+}
+
+// Document converts the flag report into a renderable document
+func (r *FlagReport) Document() *report.Document {
+	rows := make([]report.Row, len(r.Flags))
+	for i, f := range r.Flags {
+		rows[i] = report.Row{string(f.Flag), f.Count, f.Amount}
+	}
+	return &report.Document{
+		Title: "Flagged expenses " + r.Period.Label,
+		Summary: []report.Field{
+			{Key: "period", Label: "Period", Kind: report.KindText, Value: r.Period.Label},
+		},
+		Sections: []*report.Section{
+			{
+				Key:   "flags",
+				Title: "Flags",
+				Columns: []report.Column{
+					{Key: "flag", Title: "Flag", Kind: report.KindText},
+					{Key: "count", Title: "Expenses", Kind: report.KindNumber},
+					{Key: "amount", Title: "Amount", Kind: report.KindAmount},
+				},
+				Rows: report.SliceRows(rows),
+			},
+		},
+	}
+}
+
+// TaxReport sums the VAT paid in a period per VAT rate, e.g. for a VAT return, and the
+// business, reimbursable and tax-deductible expenses per category for the tax return
+// Amounts are in the base currency; expenses without VAT information are left out of the rates
+type TaxReport struct {
+	Period domain.Period      `json:"period"`
+	Rates  []*domain.VATTotal `json:"rates"`
+
+	TotalGross domain.Money `json:"total_gross"`
+	TotalNet   domain.Money `json:"total_net"`
+	TotalTax   domain.Money `json:"total_tax"`
+
+	// Marks are the categories with marked expenses
+	Marks []*domain.MarkTotal `json:"marks"`
+
+	TotalBusiness      domain.Money `json:"total_business"`
+	TotalReimbursable  domain.Money `json:"total_reimbursable"`
+	TotalTaxDeductible domain.Money `json:"total_tax_deductible"`
+
+	// AsOf is the past time the report was rebuilt as of, if any
+	AsOf *time.Time `json:"as_of,omitempty"`
+}
+
+// Tax builds the tax report for a period
+// A non-zero asOf builds it from the expenses as they were at that time
+func (s *ReportService) Tax(ctx context.Context, period string, asOf time.Time) (*TaxReport, error) {
+	p, err := domain.ParsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	_, tax, err := s.figures(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := tax.VATTotals(ctx, p.Start, p.End)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TaxReport{Period: p, Rates: totals, AsOf: asOfTime(asOf)}
+	for _, total := range totals {
+		// Derive net from gross and tax so every row adds up
+		total.Net = total.Gross.Sub(total.Tax)
+		result.TotalGross = result.TotalGross.Add(total.Gross)
+		result.TotalNet = result.TotalNet.Add(total.Net)
+		result.TotalTax = result.TotalTax.Add(total.Tax)
+	}
+
+	// The marked expenses per category
+	marks, err := tax.MarkTotals(ctx, p.Start, p.End)
+	if err != nil {
+		return nil, err
+	}
+	result.Marks = marks
+	for _, mark := range marks {
+		result.TotalBusiness = result.TotalBusiness.Add(mark.Business)
+		result.TotalReimbursable = result.TotalReimbursable.Add(mark.Reimbursable)
+		result.TotalTaxDeductible = result.TotalTaxDeductible.Add(mark.TaxDeductible)
+	}
+	return result, nil
+}
+
+// Document converts the tax report into a renderable document
+func (r *TaxReport) Document() *report.Document {
+	rows := make([]report.Row, len(r.Rates))
+	for i, t := range r.Rates {
+		// Tax entered without a rate shows up as an empty rate
+		rows[i] = report.Row{t.Rate, t.Count, t.Gross, t.Net, t.Tax}
+	}
+	markRows := make([]report.Row, len(r.Marks))
+	for i, m := range r.Marks {
+		markRows[i] = report.Row{m.Category, m.Business, m.Reimbursable, m.TaxDeductible, m.Total}
+	}
+	return &report.Document{
+		Title: "VAT " + r.Period.Label,
+		Summary: withAsOf([]report.Field{
+			{Key: "period", Label: "Period", Kind: report.KindText, Value: r.Period.Label},
+			{Key: "total_gross", Label: "Gross", Kind: report.KindAmount, Value: r.TotalGross},
+			{Key: "total_net", Label: "Net", Kind: report.KindAmount, Value: r.TotalNet},
+			{Key: "total_tax", Label: "VAT", Kind: report.KindAmount, Value: r.TotalTax},
+			{Key: "total_business", Label: "Business", Kind: report.KindAmount, Value: r.TotalBusiness},
+			{Key: "total_reimbursable", Label: "Reimbursable", Kind: report.KindAmount, Value: r.TotalReimbursable},
+			{Key: "total_tax_deductible", Label: "Tax-deductible", Kind: report.KindAmount, Value: r.TotalTaxDeductible},
+		}, r.AsOf),
+		Sections: []*report.Section{
+			{
+				Key:   "rates",
+				Title: "VAT rates",
+				Columns: []report.Column{
+					{Key: "rate", Title: "Rate", Kind: report.KindPercent},
+					{Key: "count", Title: "Expenses", Kind: report.KindNumber},
+					{Key: "gross", Title: "Gross", Kind: report.KindAmount},
+					{Key: "net", Title: "Net", Kind: report.KindAmount},
+					{Key: "tax", Title: "VAT", Kind: report.KindAmount},
+				},
+				Rows: report.SliceRows(rows),
+			},
+			{
+				Key:   "marks",
+				Title: "Business, reimbursable and tax-deductible expenses",
+				Columns: []report.Column{
+					{Key: "category", Title: "Category", Kind: report.KindText},
+					{Key: "business", Title: "Business", Kind: report.KindAmount},
+					{Key: "reimbursable", Title: "Reimbursable", Kind: report.KindAmount},
+					{Key: "tax_deductible", Title: "Tax-deductible", Kind: report.KindAmount},
+					{Key: "total", Title: "Category total", Kind: report.KindAmount},
+				},
+				Rows: report.SliceRows(markRows),
+			},
+		},
+	}
+}
+
+// withAsOf adds the time a report was rebuilt as of to its summary, if it was
+func withAsOf(summary []report.Field, asOf *time.Time) []report.Field {
+	if asOf == nil {
+		return summary
+	}
+	return append(summary, report.Field{Key: "as_of", Label: "As of", Kind: report.KindText, Value: *asOf})
+}
@@ -0,0 +1,194 @@
+// Package application contains the business logic and use cases
+// This file contains request logging: keeping a sample of requests and responses, and every
+// request of users flagged by an admin, for a while to debug hard-to-reproduce client issues
+package application
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"log"          // For reporting flags that can't be read
+	"math/rand/v2" // For sampling
+	"strings"      // For trimming notes
+	"sync"         // For guarding the cached flags
+	"time"         // For handling dates and times
+
+	"myexpenses/internal/auth"            // Request-scoped caller identity
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For user IDs
+)
+
+// RequestLogRefreshInterval is how long the flagged users are cached
+// Every request is checked against them, so they aren't read from the database each time
+const RequestLogRefreshInterval = 30 * time.Second
+
+// DefaultRequestLogTTL is how long request logs are kept by default
+const DefaultRequestLogTTL = 72 * time.Hour
+
+// maxRequestLogList caps how many logs one list returns
+const maxRequestLogList = 200
+
+// RequestLogService decides which requests are logged, stores them and shows them to admins
+type RequestLogService struct {
+	repo  domain.RequestLogRepository
+	clock clock.Clock
+
+	// sampleRate is the share of requests logged, between 0 and 1
+	sampleRate float64
+
+	// ttl is how long logs are kept
+	ttl time.Duration
+
+	// targets caches the flagged users and until when, as loaded at loadedAt
+	mu       sync.Mutex
+	targets  map[uuid.UUID]time.Time
+	loadedAt time.Time
+}
+
+// NewRequestLogService creates a request log service logging samplePercent percent of all requests
+// (0 logs only flagged users) and keeping the logs for ttl
+func NewRequestLogService(repo domain.RequestLogRepository, samplePercent float64, ttl time.Duration, clk clock.Clock) *RequestLogService {
+	return &RequestLogService{
+		repo:       repo,
+		clock:      clock.Or(clk),
+		sampleRate: samplePercent / 100,
+		ttl:        ttl,
+	}
+}
+
+// RequestLogTargetRequest represents the request to flag a user for request logging
+type RequestLogTargetRequest struct {
+	// Hours is how long their requests are logged, at most a week
+	Hours int    `json:"hours" binding:"required"`
+	Note  string `json:"note"`
+}
+
+// Reason returns why the caller's request is logged (domain.RequestLogFlagged or
+// domain.RequestLogSampled), or "" when it isn't
+func (s *RequestLogService) Reason(ctx context.Context) string {
+	if userID, err := uuid.Parse(auth.UserID(ctx)); err == nil {
+		if until, ok := s.load(ctx)[userID]; ok && s.clock.Now().Before(until) {
+			return domain.RequestLogFlagged
+		}
+	}
+	if s.sampleRate > 0 && rand.Float64() < s.sampleRate {
+		return domain.RequestLogSampled
+	}
+	return ""
+}
+
+// Record stores a request log of the caller, to be purged once it is older than the TTL
+func (s *RequestLogService) Record(ctx context.Context, entry *domain.RequestLog) error {
+	if userID, err := uuid.Parse(auth.UserID(ctx)); err == nil {
+		entry.UserID = &userID
+	}
+	entry.ExpiresAt = s.clock.Now().Add(s.ttl)
+	return s.repo.Create(ctx, entry)
+}
+
+// List returns the logs matching filter, newest first; it is for admins only
+func (s *RequestLogService) List(ctx context.Context, filter domain.RequestLogFilter) ([]*domain.RequestLog, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	if filter.Limit <= 0 || filter.Limit > maxRequestLogList {
+		filter.Limit = maxRequestLogList
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Get returns one log; it is for admins only
+func (s *RequestLogService) Get(ctx context.Context, id string) (*domain.RequestLog, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	logID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrRequestLogNotFound
+	}
+	return s.repo.GetByID(ctx, logID)
+}
+
+// ListTargets returns the users whose requests are being logged; it is for admins only
+func (s *RequestLogService) ListTargets(ctx context.Context) ([]*domain.RequestLogTarget, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	return s.repo.ListTargets(ctx, s.clock.Now())
+}
+
+// Flag logs every request of a user for the next req.Hours hours; it is for admins only
+func (s *RequestLogService) Flag(ctx context.Context, userID string, req *RequestLogTargetRequest) (*domain.RequestLogTarget, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	duration := time.Duration(req.Hours) * time.Hour
+	if duration <= 0 || duration > domain.MaxRequestLogTargetDuration {
+		return nil, domain.ErrInvalidRequestLogTarget
+	}
+
+	target := &domain.RequestLogTarget{
+		UserID: id,
+		Until:  s.clock.Now().Add(duration),
+		Note:   strings.TrimSpace(req.Note),
+	}
+	if err := s.repo.SaveTarget(ctx, target); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return target, nil
+}
+
+// Unflag stops logging every request of a user; it is for admins only
+func (s *RequestLogService) Unflag(ctx context.Context, userID string) error {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return domain.ErrForbidden
+	}
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return domain.ErrRequestLogTargetNotFound
+	}
+	if err := s.repo.DeleteTarget(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// PurgeExpired deletes the logs that are older than the TTL
+func (s *RequestLogService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repo.Purge(ctx, s.clock.Now())
+}
+
+// load returns the flagged users, reading them again once they are older than RequestLogRefreshInterval
+func (s *RequestLogService) load(ctx context.Context) map[uuid.UUID]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if s.targets != nil && now.Sub(s.loadedAt) < RequestLogRefreshInterval {
+		return s.targets
+	}
+	list, err := s.repo.ListTargets(ctx, now)
+	if err != nil {
+		log.Printf("Failed to load users flagged for request logging, using the last known ones: %v", err)
+		return s.targets
+	}
+	s.targets = make(map[uuid.UUID]time.Time, len(list))
+	for _, target := range list {
+		s.targets[target.UserID] = target.Until
+	}
+	s.loadedAt = now
+	return s.targets
+}
+
+// invalidate makes the next request read the flagged users again, so changes here take effect at once
+func (s *RequestLogService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Time{}
+}
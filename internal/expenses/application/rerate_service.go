@@ -0,0 +1,231 @@
+// Package application contains the business logic and use cases
+// This file contains re-rating: replacing locked exchange rates after a rate source corrected them
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For telling missing rates from failures
+	"fmt"     // For formatted string operations and error wrapping
+	"math"    // For comparing rates
+	"strings" // For normalizing currency codes
+	"time"    // For the date range
+
+	"myexpenses/internal/auth"            // The admin performing the re-rating
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// Audit actions recorded by re-rating
+const (
+	// AuditExpenseRerated is recorded for every expense whose conversion changed
+	AuditExpenseRerated = "expense.rerated"
+
+	// AuditRatesCorrected is recorded once per re-rating, with the range and totals
+	AuditRatesCorrected = "exchange_rates.corrected"
+)
+
+// RerateService re-converts locked conversions when exchange rates were corrected
+// Conversions are locked so reports don't move with the market, but a wrong rate from the
+// rate source is a mistake, not market movement. Only rates that came from the rate provider
+// are replaced: rates and amounts the user entered are what their bank actually charged
+type RerateService struct {
+	expenses   domain.RerateRepository
+	audit      domain.AuditRepository
+	converter  *CurrencyConverter
+	transactor domain.Transactor
+	onChange   func(ctx context.Context)
+}
+
+// NewRerateService creates a re-rating service
+// onChange is called after conversions changed, to drop cached reports and dashboards (may be nil)
+func NewRerateService(expenses domain.RerateRepository, audit domain.AuditRepository, converter *CurrencyConverter, transactor domain.Transactor, onChange func(ctx context.Context)) *RerateService {
+	return &RerateService{expenses: expenses, audit: audit, converter: converter, transactor: transactor, onChange: onChange}
+}
+
+// RateCorrectionRequest is a corrected rate for one currency on one day (one unit in the base currency)
+type RateCorrectionRequest struct {
+	Currency string  `json:"currency" binding:"required"`
+	Date     string  `json:"date" binding:"required"`
+	Rate     float64 `json:"rate" binding:"required"`
+}
+
+// RerateRequest represents the request to re-rate a date range
+type RerateRequest struct {
+	// DateFrom and DateTo (YYYY-MM-DD, inclusive) limit the expenses re-rated
+	DateFrom string `json:"date_from" binding:"required"`
+	DateTo   string `json:"date_to" binding:"required"`
+
+	// Currency limits the re-rating to one currency (optional)
+	Currency string `json:"currency"`
+
+	// Rates are the published corrections; days and currencies without one are looked up
+	// from the rate provider again
+	Rates []RateCorrectionRequest `json:"rates"`
+
+	// DryRun only computes the changes
+	DryRun bool `json:"dry_run"`
+}
+
+// RerateResult is the outcome (or, for a dry run, the preview) of a re-rating
+type RerateResult struct {
+	DryRun bool `json:"dry_run"`
+
+	// Examined counts the provider-converted expenses in the range
+	Examined int `json:"examined"`
+
+	// Changed counts the expenses whose conversion changed (or would change)
+	Changed int `json:"changed"`
+
+	// Unavailable counts the expenses for which no rate could be found; they keep their rate
+	Unavailable int `json:"unavailable"`
+
+	// BaseAmountDelta is the total change of the converted amounts, in the base currency
+	BaseAmountDelta domain.Money `json:"base_amount_delta"`
+
+	Changes []*domain.RerateChange `json:"changes"`
+}
+
+// Rerate recomputes the provider-converted expenses in the requested range
+func (s *RerateService) Rerate(ctx context.Context, req *RerateRequest) (*RerateResult, error) {
+	// Step 1: Validate the range and index the corrections by currency and day
+	from, err := time.Parse("2006-01-02", req.DateFrom)
+	if err != nil {
+		return nil, domain.ErrInvalidRerate
+	}
+	to, err := time.Parse("2006-01-02", req.DateTo)
+	if err != nil || to.Before(from) {
+		return nil, domain.ErrInvalidRerate
+	}
+	currency := ""
+	if req.Currency != "" {
+		if currency, err = domain.NormalizeCurrency(req.Currency); err != nil {
+			return nil, err
+		}
+	}
+	corrections := make(map[string]float64, len(req.Rates))
+	for _, correction := range req.Rates {
+		code, err := domain.NormalizeCurrency(correction.Currency)
+		if err != nil {
+			return nil, err
+		}
+		day, err := time.Parse("2006-01-02", correction.Date)
+		if err != nil || correction.Rate <= 0 || math.IsInf(correction.Rate, 0) {
+			return nil, domain.ErrInvalidRerate
+		}
+		corrections[correctionKey(code, day)] = correction.Rate
+	}
+
+	// Step 2: Work out the new conversion of every provider-converted expense
+	expenses, err := s.expenses.ProviderConverted(ctx, from, to.AddDate(0, 0, 1), currency)
+	if err != nil {
+		return nil, err
+	}
+	result := &RerateResult{DryRun: req.DryRun, Examined: len(expenses), Changes: []*domain.RerateChange{}}
+	for _, expense := range expenses {
+		// Conversions into a former base currency can't be compared with today's rates
+		if expense.BaseCurrency != s.converter.BaseCurrency() {
+			result.Unavailable++
+			continue
+		}
+		rate, ok := corrections[correctionKey(expense.Currency, expense.Date)]
+		if !ok {
+			rate, err = s.converter.Rates().Rate(ctx, expense.Currency, expense.BaseCurrency, expense.Date)
+			if errors.Is(err, domain.ErrExchangeRateUnavailable) {
+				result.Unavailable++
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up %s rate: %w", expense.Currency, err)
+			}
+		}
+		if rate == expense.ExchangeRate {
+			continue
+		}
+		result.Changes = append(result.Changes, &domain.RerateChange{
+			ExpenseID:     expense.ID,
+			Date:          expense.Date,
+			Currency:      expense.Currency,
+			Amount:        expense.Amount,
+			OldRate:       expense.ExchangeRate,
+			NewRate:       rate,
+			OldBaseAmount: expense.BaseAmount,
+			NewBaseAmount: expense.Amount.Mul(rate),
+		})
+	}
+	if req.DryRun {
+		result.summarize()
+		return result, nil
+	}
+
+	// Step 3: Store the new conversions and their audit entries together
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		applied, err := s.expenses.ApplyRerate(ctx, result.Changes)
+		if err != nil {
+			return err
+		}
+		if applied == nil {
+			applied = []*domain.RerateChange{}
+		}
+		result.Changes = applied
+		result.summarize()
+		return s.record(ctx, req, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 4: Cached reports still show the old amounts
+	if result.Changed > 0 && s.onChange != nil {
+		s.onChange(ctx)
+	}
+	return result, nil
+}
+
+// record writes one audit entry per changed expense and one for the whole re-rating
+func (s *RerateService) record(ctx context.Context, req *RerateRequest, result *RerateResult) error {
+	actor := auditActor(ctx)
+	entries := make([]*domain.AuditEntry, 0, len(result.Changes)+1)
+	for _, change := range result.Changes {
+		entry, err := domain.NewAuditEntry(actor, AuditExpenseRerated, "expense", change.ExpenseID.String(), change)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+	summary, err := domain.NewAuditEntry(actor, AuditRatesCorrected, "exchange_rates", "", map[string]any{
+		"date_from":         req.DateFrom,
+		"date_to":           req.DateTo,
+		"currency":          strings.ToUpper(req.Currency),
+		"corrections":       len(req.Rates),
+		"examined":          result.Examined,
+		"changed":           result.Changed,
+		"unavailable":       result.Unavailable,
+		"base_amount_delta": result.BaseAmountDelta,
+	})
+	if err != nil {
+		return err
+	}
+	return s.audit.Record(ctx, append(entries, summary)...)
+}
+
+// summarize counts the changes and totals their effect
+func (r *RerateResult) summarize() {
+	r.Changed = len(r.Changes)
+	var delta domain.Money
+	for _, change := range r.Changes {
+		delta = delta.Add(change.NewBaseAmount.Sub(change.OldBaseAmount))
+	}
+	r.BaseAmountDelta = delta
+}
+
+// correctionKey identifies the corrected rate of a currency on a calendar day
+func correctionKey(currency string, day time.Time) string {
+	return currency + "@" + day.Format("2006-01-02")
+}
+
+// auditActor names the caller in audit entries: their user ID, or "admin" for the admin token
+func auditActor(ctx context.Context) string {
+	if userID := auth.UserID(ctx); userID != "" {
+		return userID
+	}
+	return "admin"
+}
@@ -0,0 +1,94 @@
+// Package application contains the business logic and use cases
+// This file contains budget rollover: carrying what is left of a month's budget into the next
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For matching category names
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// MaxRolloverMonths bounds how many previous months are followed to work out a carry-over
+// Anything left (or overspent) longer ago than that is forgotten
+const MaxRolloverMonths = 12
+
+// carryOver returns what every rollover budget of month brings along from the previous
+// months, keyed by lower-case category; categories without rollover aren't in it
+// budgets are all saved budgets, not just those of month. Each previous month leaves what its
+// budget (plus its own carry-over, if that budget rolls over too) minus its spending; a month
+// without a budget for the category leaves nothing. Budgets count from the month they were
+// created in, unless they were made for one particular month
+func (s *BudgetService) carryOver(ctx context.Context, budgets []*domain.Budget, month time.Time) (map[string]domain.Money, error) {
+	// Step 1: Find the categories whose budget rolls over this month
+	carry := make(map[string]domain.Money)
+	earliest := month
+	for _, budget := range domain.EffectiveBudgets(budgets, month) {
+		if budget.Rollover {
+			carry[strings.ToLower(budget.Category)] = domain.Money{}
+		}
+	}
+	if len(carry) == 0 {
+		return carry, nil
+	}
+	for _, budget := range budgets {
+		if _, ok := carry[strings.ToLower(budget.Category)]; ok {
+			if since := budgetStart(budget); since.Before(earliest) {
+				earliest = since
+			}
+		}
+	}
+
+	// Step 2: Walk the previous months in order, carrying each month's leftover into the next
+	from := month.AddDate(0, -MaxRolloverMonths, 0)
+	if earliest.After(from) {
+		from = earliest
+	}
+	for m := from; m.Before(month); m = m.AddDate(0, 1, 0) {
+		var existing []*domain.Budget
+		for _, budget := range budgets {
+			if !budgetStart(budget).After(m) {
+				existing = append(existing, budget)
+			}
+		}
+		active := make(map[string]*domain.Budget)
+		for _, budget := range domain.EffectiveBudgets(existing, m) {
+			active[strings.ToLower(budget.Category)] = budget
+		}
+
+		rows, err := s.forecaster.spending.SpendingByCategory(ctx, m, m.AddDate(0, 1, 0))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load spending: %w", err)
+		}
+		spent := make(map[string]domain.Money)
+		for _, row := range rows {
+			key := strings.ToLower(row.Category)
+			spent[key] = spent[key].Add(row.Amount)
+		}
+
+		for key, carried := range carry {
+			budget, ok := active[key]
+			if !ok {
+				carry[key] = domain.Money{}
+				continue
+			}
+			if !budget.Rollover {
+				carried = domain.Money{}
+			}
+			carry[key] = budget.Amount.Add(carried).Sub(spent[key])
+		}
+	}
+	return carry, nil
+}
+
+// budgetStart is the first month a budget counts in
+func budgetStart(budget *domain.Budget) time.Time {
+	if budget.Month != "" {
+		if start, err := domain.ParseMonth(budget.Month); err == nil {
+			return start
+		}
+	}
+	return domain.MonthStart(budget.CreatedAt)
+}
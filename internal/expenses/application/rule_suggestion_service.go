@@ -0,0 +1,101 @@
+// Package application contains the business logic and use cases
+// This file contains the rules suggested from the caller's category corrections
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For matching suggestions regardless of case
+
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// RuleSuggestionService suggests categorization rules from the imported expenses the caller
+// moved to another category, e.g. "always categorize 'SHELL' as Fuel?"
+// Suggestions are worked out from the recent corrections whenever they are asked for, so they
+// follow the rules as they change; accepting one creates the rule
+type RuleSuggestionService struct {
+	corrections domain.CorrectionRepository
+	rules       domain.RuleRepository
+	clock       clock.Clock
+}
+
+// NewRuleSuggestionService creates a new rule suggestion service
+func NewRuleSuggestionService(corrections domain.CorrectionRepository, rules domain.RuleRepository, clk clock.Clock) *RuleSuggestionService {
+	return &RuleSuggestionService{
+		corrections: corrections,
+		rules:       rules,
+		clock:       clock.Or(clk),
+	}
+}
+
+// RuleSuggestionRequest names a suggestion to accept or dismiss
+type RuleSuggestionRequest struct {
+	Pattern  string `json:"pattern" binding:"required"`
+	Category string `json:"category" binding:"required"`
+}
+
+// Suggestions returns the rules the caller's corrections of the last domain.CorrectionWindow
+// ask for, most corrected first
+func (s *RuleSuggestionService) Suggestions(ctx context.Context) ([]*domain.RuleSuggestion, error) {
+	corrections, err := s.corrections.List(ctx, s.clock.Now().Add(-domain.CorrectionWindow))
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.rules.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	return domain.SuggestRules(corrections, rules), nil
+}
+
+// Accept creates the suggested rule, with the priority it was suggested with
+// The corrections behind it are dropped, since the rule takes care of them from now on
+func (s *RuleSuggestionService) Accept(ctx context.Context, req *RuleSuggestionRequest) (*domain.CategoryRule, error) {
+	suggestion, err := s.find(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := domain.NewCategoryRule(suggestion.Pattern, suggestion.Category, suggestion.Priority)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rules.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to save rule: %w", err)
+	}
+	if err := s.corrections.Delete(ctx, suggestion.CorrectionIDs()); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Dismiss drops the corrections behind a suggestion, so it only comes back if the caller
+// keeps making the same correction
+func (s *RuleSuggestionService) Dismiss(ctx context.Context, req *RuleSuggestionRequest) error {
+	suggestion, err := s.find(ctx, req)
+	if err != nil {
+		return err
+	}
+	return s.corrections.Delete(ctx, suggestion.CorrectionIDs())
+}
+
+// PurgeExpired deletes the corrections older than domain.CorrectionWindow, which no longer count
+func (s *RuleSuggestionService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.corrections.Purge(ctx, s.clock.Now().Add(-domain.CorrectionWindow))
+}
+
+// find returns the current suggestion with the requested pattern and category
+func (s *RuleSuggestionService) find(ctx context.Context, req *RuleSuggestionRequest) (*domain.RuleSuggestion, error) {
+	suggestions, err := s.Suggestions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, suggestion := range suggestions {
+		if strings.EqualFold(suggestion.Pattern, strings.TrimSpace(req.Pattern)) &&
+			strings.EqualFold(suggestion.Category, strings.TrimSpace(req.Category)) {
+			return suggestion, nil
+		}
+	}
+	return nil, domain.ErrRuleSuggestionNotFound
+}
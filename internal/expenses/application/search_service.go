@@ -0,0 +1,53 @@
+// Package application contains the business logic and use cases
+// This file contains the full-text search use case
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For cleaning up the search text
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/language"        // For stemming queries in the right language
+)
+
+// SearchService handles full-text search over expenses and receipt text
+type SearchService struct {
+	// repo performs the actual full-text query
+	repo domain.SearchRepository
+
+	// languages is the tenant language assumed for queries too short to detect
+	languages *language.Preferences
+}
+
+// NewSearchService creates a new search service
+// languages may be nil to search queries of unknown language without stemming
+func NewSearchService(repo domain.SearchRepository, languages *language.Preferences) *SearchService {
+	return &SearchService{
+		repo:      repo, // Store the repository dependency
+		languages: languages,
+	}
+}
+
+// SearchExpenses finds expenses whose description, category or receipt text match the query
+// scope is the raw ?scope= query parameter ("expenses", "receipts" or "all")
+func (s *SearchService) SearchExpenses(ctx context.Context, query, scope string) ([]*domain.Expense, error) {
+	// Step 1: Validate the input
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, domain.ErrInvalidSearchQuery
+	}
+	searchScope, err := domain.ParseSearchScope(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Run the search, stemming the query in its own language if it tells,
+	// otherwise in the tenant's (most queries are a single word)
+	searchConfig := language.SearchConfig(s.languages.DetectOr(ctx, query))
+	expenses, err := s.repo.Search(ctx, query, searchScope, searchConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search expenses: %w", err)
+	}
+	return expenses, nil
+}
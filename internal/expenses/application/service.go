@@ -5,10 +5,15 @@ package application
 
 import (
 	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching repository errors
 	"fmt"     // For formatted string operations and error wrapping
+	"log"     // For reporting corrections that couldn't be recorded
 	"time"    // For handling dates and times
 
+	"myexpenses/internal/auth"            // Request-scoped caller identity
 	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For comparing employer IDs
 )
 
 // Service handles business logic for expenses
@@ -19,15 +24,223 @@ type Service struct {
 	// This follows the Dependency Inversion Principle - depend on abstractions, not concretions
 	// The actual implementation (PostgreSQL, in-memory, etc.) is injected later
 	repo domain.Repository
+
+	// converter locks in the base-currency amount of foreign currency expenses
+	converter *CurrencyConverter
+
+	// accounts resolves the account an expense is paid from (including the cash wallet)
+	accounts *AccountService
+
+	// maxListResults is the most expenses GetAllExpenses loads into memory at once
+	maxListResults int
+
+	// defaultPageSize is the page size used when the caller doesn't ask for one (0 means unpaginated)
+	defaultPageSize int
+
+	// maxPageSize is the largest page GetAllExpenses returns
+	maxPageSize int
+
+	// streamingFallback streams lists over maxListResults instead of rejecting them
+	streamingFallback bool
+
+	// budgets reports the budget impact of new expenses
+	budgets *BudgetService
+
+	// transactor groups writes with the reads that must see them
+	transactor domain.Transactor
+
+	// categories provides the default VAT rate of each category and the subcategories of the list filter
+	categories domain.CategoryRepository
+
+	// trips keeps the expenses of approved trip reports from being changed
+	trips domain.TripRepository
+
+	// policies checks new and changed expenses against the organization's expense policy
+	policies *PolicyService
+
+	// dimensions checks the cost centers and departments expenses are charged to
+	dimensions *DimensionService
+
+	// employers checks the employers reimbursable expenses are charged to
+	employers domain.EmployerRepository
+
+	// beforeCreate are the extension hooks that see new expenses before they are saved
+	beforeCreate []BeforeCreateHook
+
+	// views are the caller's saved filter sets, applied to lists with ?view=
+	views domain.ViewRepository
+
+	// customFields are the caller's custom field schema, which metadata is checked against
+	customFields domain.CustomFieldRepository
+
+	// corrections records re-categorized imports, which rule suggestions are made from
+	corrections domain.CorrectionRepository
+
+	// history answers time-travel queries; nil unless the event store is on
+	history domain.ExpenseHistory
+}
+
+// DefaultMaxListResults is the in-memory result limit used when none is configured
+const DefaultMaxListResults = 1000
+
+// ServiceOption configures optional dependencies of the Service
+// Options keep NewService(repo) working while new features add collaborators
+type ServiceOption func(*Service)
+
+// WithCurrencyConverter sets the converter used for foreign currency expenses
+// Without it, every expense is treated as being in domain.DefaultBaseCurrency
+func WithCurrencyConverter(converter *CurrencyConverter) ServiceOption {
+	return func(s *Service) {
+		s.converter = converter
+	}
+}
+
+// WithAccounts enables paying expenses from accounts (account_id / paid_in_cash)
+func WithAccounts(accounts *AccountService) ServiceOption {
+	return func(s *Service) {
+		s.accounts = accounts
+	}
+}
+
+// WithBudgets enables reporting the budget impact of new expenses
+func WithBudgets(budgets *BudgetService) ServiceOption {
+	return func(s *Service) {
+		s.budgets = budgets
+	}
+}
+
+// WithTransactor sets how multi-step use cases are made atomic
+// Without it, each repository call commits on its own
+func WithTransactor(transactor domain.Transactor) ServiceOption {
+	return func(s *Service) {
+		s.transactor = transactor
+	}
+}
+
+// WithCategoryVAT splits the gross amount of new and edited expenses into net and tax
+// using the default VAT rate of their category
+// Without it, expenses only get a split when the request sends vat_rate or tax_amount
+func WithCategoryVAT(categories domain.CategoryRepository) ServiceOption {
+	return func(s *Service) {
+		s.categories = categories
+	}
+}
+
+// WithTripApprovals freezes the expenses of approved trips: editing or deleting them fails
+// with domain.ErrTripApproved. Without it, only the trip's list of expenses is frozen
+func WithTripApprovals(trips domain.TripRepository) ServiceOption {
+	return func(s *Service) {
+		s.trips = trips
+	}
+}
+
+// WithPolicies checks expenses against the expense policy when they are created or updated
+// Blocking rules make the save fail with a *domain.PolicyBlockedError; warnings are returned with the expense
+func WithPolicies(policies *PolicyService) ServiceOption {
+	return func(s *Service) {
+		s.policies = policies
+	}
+}
+
+// WithDimensions lets expenses be charged to the organization's cost centers and departments
+// Without it, expenses can't be charged to any
+func WithDimensions(dimensions *DimensionService) ServiceOption {
+	return func(s *Service) {
+		s.dimensions = dimensions
+	}
+}
+
+// WithEmployers lets reimbursable expenses be charged to the caller's employers
+// Without it, expenses can still be marked reimbursable but not charged to an employer
+func WithEmployers(employers domain.EmployerRepository) ServiceOption {
+	return func(s *Service) {
+		s.employers = employers
+	}
+}
+
+// WithViews lets expense lists be filtered by a saved view (ApplyView)
+// Without it, every view is reported as not found
+func WithViews(views domain.ViewRepository) ServiceOption {
+	return func(s *Service) {
+		s.views = views
+	}
+}
+
+// WithCustomFields lets expenses carry metadata for the caller's custom fields and be filtered by it
+// Without it, any metadata fails with domain.ErrUnknownCustomField
+func WithCustomFields(fields domain.CustomFieldRepository) ServiceOption {
+	return func(s *Service) {
+		s.customFields = fields
+	}
+}
+
+// WithCorrections records every imported expense moved to another category, so rules can be
+// suggested from them (see RuleSuggestionService)
+func WithCorrections(corrections domain.CorrectionRepository) ServiceOption {
+	return func(s *Service) {
+		s.corrections = corrections
+	}
+}
+
+// WithHistory lets expenses be read as they were at a past time, and their changes be listed
+func WithHistory(history domain.ExpenseHistory) ServiceOption {
+	return func(s *Service) {
+		s.history = history
+	}
+}
+
+// WithMaxListResults sets how many expenses GetAllExpenses may load into memory
+// Larger unpaginated lists fail with domain.ErrResultTooLarge and must be paginated or streamed
+func WithMaxListResults(limit int) ServiceOption {
+	return func(s *Service) {
+		if limit > 0 {
+			s.maxListResults = limit
+		}
+	}
+}
+
+// WithPageSizes sets the page size used when the caller doesn't send a limit (0 keeps lists
+// unpaginated) and the largest page a caller may ask for
+func WithPageSizes(defaultSize, maxSize int) ServiceOption {
+	return func(s *Service) {
+		if defaultSize >= 0 {
+			s.defaultPageSize = defaultSize
+		}
+		if maxSize > 0 {
+			s.maxPageSize = maxSize
+		}
+	}
+}
+
+// WithStreamingFallback chooses what happens to lists over the limit:
+// true streams them in chunks (the default), false requires the client to paginate
+func WithStreamingFallback(enabled bool) ServiceOption {
+	return func(s *Service) {
+		s.streamingFallback = enabled
+	}
 }
 
 // NewService creates a new expense service
 // This is a constructor function that implements dependency injection
 // It takes a repository implementation and returns a configured service
-func NewService(repo domain.Repository) *Service {
-	return &Service{
-		repo: repo, // Store the repository dependency
+func NewService(repo domain.Repository, opts ...ServiceOption) *Service {
+	s := &Service{
+		repo:              repo, // Store the repository dependency
+		maxListResults:    DefaultMaxListResults,
+		maxPageSize:       DefaultMaxPageSize,
+		streamingFallback: true,
+	}
+
+	// Apply the optional dependencies
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	// Fall back to the default base currency with no automatic rates
+	if s.converter == nil {
+		s.converter, _ = NewCurrencyConverter(domain.DefaultBaseCurrency, nil)
+	}
+	return s
 }
 
 // CreateExpenseRequest represents the request to create an expense
@@ -39,14 +252,58 @@ type CreateExpenseRequest struct {
 	Description string `json:"description" binding:"required"`
 
 	// Amount is how much the expense cost
-	// binding:"required,gt=0" ensures the amount is provided and greater than 0
-	Amount float64 `json:"amount" binding:"required,gt=0"`
+	// It must be greater than 0; NewExpense rejects it otherwise (a missing amount is 0)
+	Amount domain.Money `json:"amount"`
 
 	// Category helps organize the expense
 	Category string `json:"category" binding:"required"`
 
 	// Date is when the expense occurred
 	Date time.Time `json:"date" binding:"required"`
+
+	// Currency is the ISO 4217 code the expense was paid in (defaults to the base currency)
+	Currency string `json:"currency"`
+
+	// ExchangeRate optionally overrides the rate used to convert into the base currency
+	ExchangeRate float64 `json:"exchange_rate" binding:"omitempty,gt=0"`
+
+	// ConvertedAmount optionally overrides the base-currency amount (e.g. from the card statement)
+	ConvertedAmount domain.Money `json:"converted_amount"`
+
+	// AccountID is the account the expense was paid from (optional)
+	AccountID string `json:"account_id"`
+
+	// PaidInCash attributes the expense to the cash wallet, drawing down its balance
+	PaidInCash bool `json:"paid_in_cash"`
+
+	// VATRate overrides the category's default VAT rate in percent (0 for zero-rated purchases)
+	VATRate *float64 `json:"vat_rate" binding:"omitempty,gte=0,lte=100"`
+
+	// TaxAmount overrides the computed tax, e.g. with the total printed on the receipt
+	TaxAmount *domain.Money `json:"tax_amount"`
+
+	// CostCenter and Department are the codes of the cost center and department the expense is charged to (optional)
+	CostCenter string `json:"cost_center"`
+	Department string `json:"department"`
+
+	// Reimbursable marks a work expense someone else pays back (personal by default)
+	Reimbursable bool `json:"reimbursable"`
+
+	// EmployerID is the employer who pays it back; giving one makes the expense reimbursable (optional)
+	EmployerID string `json:"employer_id"`
+
+	// Business and TaxDeductible mark business and tax-deductible expenses (both false by default)
+	Business      bool `json:"business"`
+	TaxDeductible bool `json:"tax_deductible"`
+
+	// Tags are free-form labels (optional); "#Travel" is stored as "travel"
+	Tags []string `json:"tags"`
+
+	// Metadata are values of the caller's custom fields by key (optional), e.g. {"project": "ACME"}
+	Metadata map[string]interface{} `json:"metadata"`
+
+	// Notes are free-text remarks on the expense (optional)
+	Notes string `json:"notes"`
 }
 
 // UpdateExpenseRequest represents the request to update an expense
@@ -54,10 +311,45 @@ type CreateExpenseRequest struct {
 // A client can update just the amount without changing other fields
 type UpdateExpenseRequest struct {
 	// All fields are optional for updates
-	Description string    `json:"description"`
-	Amount      float64   `json:"amount"`
-	Category    string    `json:"category"`
-	Date        time.Time `json:"date"`
+	Description string       `json:"description"`
+	Amount      domain.Money `json:"amount"`
+	Category    string       `json:"category"`
+	Date        time.Time    `json:"date"`
+
+	// Setting any of these re-locks the currency conversion
+	Currency        string       `json:"currency"`
+	ExchangeRate    float64      `json:"exchange_rate" binding:"omitempty,gt=0"`
+	ConvertedAmount domain.Money `json:"converted_amount"`
+
+	// Setting either of these, the amount or the category recomputes the VAT split
+	VATRate   *float64      `json:"vat_rate" binding:"omitempty,gte=0,lte=100"`
+	TaxAmount *domain.Money `json:"tax_amount"`
+
+	// Setting either of these charges the expense to another cost center or department ("" removes it)
+	CostCenter *string `json:"cost_center"`
+	Department *string `json:"department"`
+
+	// Reimbursable switches between reimbursable and personal; marking it personal removes the employer
+	Reimbursable *bool `json:"reimbursable"`
+
+	// EmployerID charges the expense to another employer, making it reimbursable ("" removes it)
+	EmployerID *string `json:"employer_id"`
+
+	// Business and TaxDeductible switch the business and tax-deductible marks
+	Business      *bool `json:"business"`
+	TaxDeductible *bool `json:"tax_deductible"`
+
+	// Tags replaces the expense's tags ([] removes them all)
+	Tags *[]string `json:"tags"`
+
+	// Metadata sets the given custom fields; null removes one and fields left out are kept
+	Metadata map[string]interface{} `json:"metadata"`
+
+	// Notes replaces the expense's notes ("" removes them)
+	Notes *string `json:"notes"`
+
+	// AccountID moves the expense to another account it was paid from ("" detaches it)
+	AccountID *string `json:"account_id"`
 }
 
 // CreateExpense creates a new expense
@@ -73,16 +365,110 @@ func (s *Service) CreateExpense(ctx context.Context, req *CreateExpenseRequest)
 		return nil, fmt.Errorf("failed to create expense: %w", err)
 	}
 
-	// Step 2: Save the expense to the repository (database)
+	// Step 2: Lock in the conversion into the base currency
+	// The converted amount is stored now so later rate changes don't alter past reports
+	if err := s.converter.Apply(ctx, expense, ConversionInput{
+		Currency:        req.Currency,
+		ExchangeRate:    req.ExchangeRate,
+		ConvertedAmount: req.ConvertedAmount,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create expense: %w", err)
+	}
+
+	// Step 2b: Attribute the expense to the account it was paid from
+	if err := s.assignAccount(ctx, expense, req.AccountID, req.PaidInCash); err != nil {
+		return nil, fmt.Errorf("failed to create expense: %w", err)
+	}
+
+	// Step 2c: Split the gross amount into net and tax
+	if err := s.applyVAT(ctx, expense, req.VATRate, req.TaxAmount); err != nil {
+		return nil, fmt.Errorf("failed to create expense: %w", err)
+	}
+
+	// Step 2d: Charge it to a cost center and department
+	if err := s.chargeTo(ctx, expense, &req.CostCenter, &req.Department); err != nil {
+		return nil, fmt.Errorf("failed to create expense: %w", err)
+	}
+
+	// Step 2e: Mark it reimbursable and charge it to an employer, and mark business and tax-deductible expenses
+	if err := s.assignEmployer(ctx, expense, &req.Reimbursable, &req.EmployerID); err != nil {
+		return nil, fmt.Errorf("failed to create expense: %w", err)
+	}
+	expense.Business, expense.TaxDeductible = req.Business, req.TaxDeductible
+
+	// Step 2f: Tag it
+	if req.Tags != nil {
+		if expense.Tags, err = domain.NormalizeTags(req.Tags); err != nil {
+			return nil, fmt.Errorf("failed to create expense: %w", err)
+		}
+	}
+
+	// Step 2g: Fill in its custom fields and notes
+	if err := s.applyMetadata(ctx, expense, req.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to create expense: %w", err)
+	}
+	if err := expense.SetNotes(req.Notes); err != nil {
+		return nil, fmt.Errorf("failed to create expense: %w", err)
+	}
+
+	// Step 2h: Let extension modules adjust or refuse it
+	if err := s.runBeforeCreate(ctx, expense); err != nil {
+		return nil, fmt.Errorf("failed to create expense: %w", err)
+	}
+
+	// Step 2i: Check the expense policy; a blocking rule keeps the expense from being saved
+	warnings, err := s.checkPolicy(ctx, expense, domain.PolicyStageCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3: Save the expense to the repository (database)
 	if err := s.repo.Create(ctx, expense); err != nil {
 		// If persistence fails, wrap the error with context
 		return nil, fmt.Errorf("failed to save expense: %w", err)
 	}
 
-	// Step 3: Return the created expense
+	// Step 4: Return the created expense with its policy warnings
+	if err := s.recordPolicyWarnings(ctx, expense, warnings); err != nil {
+		return nil, err
+	}
 	return expense, nil
 }
 
+// CreateExpenseWithBudgetImpact creates an expense and returns the updated budget status of its category
+// The expense is saved and the month's spending re-read in one transaction, so the status
+// includes the new expense and nothing is saved if the status can't be computed
+// The status is nil when the category has no budget (or budgets aren't configured)
+func (s *Service) CreateExpenseWithBudgetImpact(ctx context.Context, req *CreateExpenseRequest) (*domain.Expense, *BudgetStatus, error) {
+	var expense *domain.Expense
+	var status *BudgetStatus
+	err := s.withinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if expense, err = s.CreateExpense(ctx, req); err != nil {
+			return err
+		}
+		if s.budgets == nil {
+			return nil
+		}
+		if status, err = s.budgets.CategoryStatus(ctx, expense.Category, expense.Date); err != nil {
+			return fmt.Errorf("failed to compute budget impact: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return expense, status, nil
+}
+
+// withinTransaction runs fn in a transaction when a transactor is configured
+func (s *Service) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.transactor == nil {
+		return fn(ctx)
+	}
+	return s.transactor.WithinTransaction(ctx, fn)
+}
+
 // GetExpense retrieves an expense by ID
 // This is a simple query use case
 func (s *Service) GetExpense(ctx context.Context, id string) (*domain.Expense, error) {
@@ -95,9 +481,54 @@ func (s *Service) GetExpense(ctx context.Context, id string) (*domain.Expense, e
 	return expense, nil
 }
 
+// GetExpenseAsOf retrieves an expense as it was at the given time
+// It returns domain.ErrHistoryUnavailable while the event store is off
+func (s *Service) GetExpenseAsOf(ctx context.Context, id string, at time.Time) (*domain.Expense, error) {
+	if s.history == nil {
+		return nil, domain.ErrHistoryUnavailable
+	}
+	expense, err := s.history.AsOf(ctx, id, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense: %w", err)
+	}
+	return expense, nil
+}
+
+// ExpenseEvents lists every recorded change of an expense, oldest first
+func (s *Service) ExpenseEvents(ctx context.Context, id string) ([]*domain.ExpenseEvent, error) {
+	if s.history == nil {
+		return nil, domain.ErrHistoryUnavailable
+	}
+	events, err := s.history.Events(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expense events: %w", err)
+	}
+	return events, nil
+}
+
 // GetAllExpenses retrieves all expenses with optional filtering
 // This is a query use case that supports filtering
 func (s *Service) GetAllExpenses(ctx context.Context, filters map[string]interface{}) ([]*domain.Expense, error) {
+	if err := s.expandSubcategories(ctx, filters); err != nil {
+		return nil, err
+	}
+	if err := s.typeMetadataFilter(ctx, filters); err != nil {
+		return nil, err
+	}
+
+	// Enforce the soft quota: a page is capped at the max page size, and an unpaginated
+	// list is only loaded when it is known to fit
+	s.applyPageSize(filters)
+	if _, paginated := filters["limit"]; !paginated {
+		count, err := s.repo.Count(ctx, filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count expenses: %w", err)
+		}
+		if count > int64(s.maxListResults) {
+			return nil, domain.ErrResultTooLarge
+		}
+	}
+
 	// Delegate to the repository to fetch expenses with filters
 	expenses, err := s.repo.GetAll(ctx, filters)
 	if err != nil {
@@ -107,6 +538,158 @@ func (s *Service) GetAllExpenses(ctx context.Context, filters map[string]interfa
 	return expenses, nil
 }
 
+// ApplyView adds the filters of one of the caller's saved views to the filters of a list
+// Filters already present are kept, so a request can narrow a view down
+func (s *Service) ApplyView(ctx context.Context, id string, filters map[string]interface{}) error {
+	if s.views == nil {
+		return domain.ErrViewNotFound
+	}
+	view, err := s.views.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	view.Filters.Apply(filters)
+	return nil
+}
+
+// expandSubcategories turns a category filter with "include_subcategories" into an exact match on
+// the category and every category nested under it, so ?category=Food also lists Coffee
+// Without the category list (WithCategoryVAT) the filter is left as it is
+func (s *Service) expandSubcategories(ctx context.Context, filters map[string]interface{}) error {
+	include, _ := filters["include_subcategories"].(bool)
+	delete(filters, "include_subcategories")
+	category, _ := filters["category"].(string)
+	if !include || category == "" || s.categories == nil {
+		return nil
+	}
+	tree, err := loadCategoryTree(ctx, s.categories)
+	if err != nil {
+		return err
+	}
+	delete(filters, "category")
+	filters["categories"] = tree.Subtree(category)
+	return nil
+}
+
+// typeMetadataFilter turns the raw ?meta.<key>= values ("meta") into a typed "metadata" filter,
+// so ?meta.hours=8 matches the number 8 and ?meta.billable=true the boolean
+func (s *Service) typeMetadataFilter(ctx context.Context, filters map[string]interface{}) error {
+	raw, _ := filters["meta"].(map[string]string)
+	delete(filters, "meta")
+	if len(raw) == 0 {
+		return nil
+	}
+	fields, err := s.listCustomFields(ctx)
+	if err != nil {
+		return err
+	}
+	byKey := make(map[string]*domain.CustomField, len(fields))
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+
+	metadata := make(domain.Metadata, len(raw))
+	for key, value := range raw {
+		field, ok := byKey[key]
+		if !ok {
+			return domain.ErrUnknownCustomField
+		}
+		if metadata[key], err = field.ParseValue(value); err != nil {
+			return err
+		}
+	}
+	filters["metadata"] = metadata
+	return nil
+}
+
+// applyMetadata sets custom field values on an expense, checked against the caller's schema
+func (s *Service) applyMetadata(ctx context.Context, expense *domain.Expense, values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+	fields, err := s.listCustomFields(ctx)
+	if err != nil {
+		return err
+	}
+	metadata, err := domain.ApplyMetadata(expense.Metadata, values, fields)
+	if err != nil {
+		return err
+	}
+	expense.Metadata = metadata
+	return nil
+}
+
+// listCustomFields returns the caller's custom fields (none without WithCustomFields)
+func (s *Service) listCustomFields(ctx context.Context) ([]*domain.CustomField, error) {
+	if s.customFields == nil {
+		return nil, nil
+	}
+	fields, err := s.customFields.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load custom fields: %w", err)
+	}
+	return fields, nil
+}
+
+// applyPageSize fills in the default page size and caps the requested one
+func (s *Service) applyPageSize(filters map[string]interface{}) {
+	limit, ok := filters["limit"].(int)
+	switch {
+	case !ok || limit <= 0:
+		delete(filters, "limit")
+		if s.defaultPageSize > 0 {
+			filters["limit"] = s.defaultPageSize
+		}
+	case limit > s.maxPageSize:
+		filters["limit"] = s.maxPageSize
+	}
+}
+
+// ExplainExpenses returns the execution plan of the list query for the given filters
+// The same page-size cap as GetAllExpenses is applied, so the plan matches what would run
+func (s *Service) ExplainExpenses(ctx context.Context, filters map[string]interface{}) (*domain.QueryPlan, error) {
+	// Query plans expose the schema and run real queries, so they are for admins only
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	explainer, ok := s.repo.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+	if err := s.expandSubcategories(ctx, filters); err != nil {
+		return nil, err
+	}
+	if err := s.typeMetadataFilter(ctx, filters); err != nil {
+		return nil, err
+	}
+	s.applyPageSize(filters)
+	plan, err := explainer.ExplainExpenses(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain expenses: %w", err)
+	}
+	return plan, nil
+}
+
+// StreamingFallback reports whether lists over the limit should be streamed rather than rejected
+func (s *Service) StreamingFallback() bool {
+	return s.streamingFallback
+}
+
+// StreamExpenses calls fn for every expense matching the filters, without the in-memory limit
+// It is the fallback for lists too large for GetAllExpenses
+func (s *Service) StreamExpenses(ctx context.Context, filters map[string]interface{}, fn func(*domain.Expense) error) error {
+	if err := s.expandSubcategories(ctx, filters); err != nil {
+		return err
+	}
+	if err := s.typeMetadataFilter(ctx, filters); err != nil {
+		return err
+	}
+	if err := s.repo.Stream(ctx, filters, fn); err != nil {
+		return fmt.Errorf("failed to stream expenses: %w", err)
+	}
+	return nil
+}
+
 // UpdateExpense updates an existing expense
 // This is a complex use case that involves validation and coordination
 func (s *Service) UpdateExpense(ctx context.Context, id string, req *UpdateExpenseRequest) (*domain.Expense, error) {
@@ -126,22 +709,203 @@ func (s *Service) UpdateExpense(ctx context.Context, id string, req *UpdateExpen
 	if err != nil {
 		return nil, fmt.Errorf("failed to get expense: %w", err)
 	}
+	if err := s.checkNotFrozen(ctx, expense); err != nil {
+		return nil, err
+	}
 
 	// Step 3: Update the expense fields using the domain method
 	// This ensures business rules are still enforced during updates
+	previousCategory := expense.Category
 	if err := expense.Update(req.Description, req.Amount, req.Category, req.Date); err != nil {
 		return nil, fmt.Errorf("failed to update expense: %w", err)
 	}
+	if err := s.checkRefunds(ctx, expense, req); err != nil {
+		return nil, fmt.Errorf("failed to update expense: %w", err)
+	}
+
+	// Step 3b: Re-lock the conversion only if the user changed the currency or overrode it
+	// Otherwise the rate locked at entry time stays in place
+	if req.Currency != "" || req.ExchangeRate != 0 || !req.ConvertedAmount.IsZero() {
+		currency := req.Currency
+		if currency == "" {
+			currency = expense.Currency
+		}
+		if err := s.converter.Apply(ctx, expense, ConversionInput{
+			Currency:        currency,
+			ExchangeRate:    req.ExchangeRate,
+			ConvertedAmount: req.ConvertedAmount,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to update expense: %w", err)
+		}
+	}
+
+	// Step 3c: Recompute the VAT split when anything it depends on changed
+	// Without a new rate the expense keeps its own, unless it moved to another category
+	if req.Amount.Minor > 0 || req.Category != "" || req.VATRate != nil || req.TaxAmount != nil {
+		rate := req.VATRate
+		if rate == nil && req.Category == "" {
+			rate = expense.VATRate
+		}
+		if err := s.applyVAT(ctx, expense, rate, req.TaxAmount); err != nil {
+			return nil, fmt.Errorf("failed to update expense: %w", err)
+		}
+	}
+
+	// Step 3d: Charge it to another cost center or department, or move it to another account, when asked to
+	if err := s.chargeTo(ctx, expense, req.CostCenter, req.Department); err != nil {
+		return nil, fmt.Errorf("failed to update expense: %w", err)
+	}
+	if req.AccountID != nil {
+		expense.AccountID = nil
+		if err := s.assignAccount(ctx, expense, *req.AccountID, false); err != nil {
+			return nil, fmt.Errorf("failed to update expense: %w", err)
+		}
+	}
+
+	// Step 3e: Mark it reimbursable or personal, or charge it to another employer, and switch
+	// the business and tax-deductible marks when asked to
+	if err := s.assignEmployer(ctx, expense, req.Reimbursable, req.EmployerID); err != nil {
+		return nil, fmt.Errorf("failed to update expense: %w", err)
+	}
+	if req.Business != nil {
+		expense.Business = *req.Business
+	}
+	if req.TaxDeductible != nil {
+		expense.TaxDeductible = *req.TaxDeductible
+	}
+
+	// Step 3f: Replace its tags when asked to
+	if req.Tags != nil {
+		if expense.Tags, err = domain.NormalizeTags(*req.Tags); err != nil {
+			return nil, fmt.Errorf("failed to update expense: %w", err)
+		}
+	}
+
+	// Step 3g: Set or clear the custom fields and notes it was sent
+	if err := s.applyMetadata(ctx, expense, req.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to update expense: %w", err)
+	}
+	if req.Notes != nil {
+		if err := expense.SetNotes(*req.Notes); err != nil {
+			return nil, fmt.Errorf("failed to update expense: %w", err)
+		}
+	}
+
+	// Step 3h: Check the changed expense against the expense policy
+	warnings, err := s.checkPolicy(ctx, expense, domain.PolicyStageUpdate)
+	if err != nil {
+		return nil, err
+	}
 
 	// Step 4: Save the updated expense back to the repository
 	if err := s.repo.Update(ctx, expense); err != nil {
 		return nil, fmt.Errorf("failed to save updated expense: %w", err)
 	}
 
-	// Step 5: Return the updated expense
+	// Step 5: Learn from a re-categorized import; losing a correction is better than failing the update
+	if correction := domain.NewCategoryCorrection(expense, previousCategory); correction != nil && s.corrections != nil {
+		if err := s.corrections.Record(ctx, correction); err != nil {
+			log.Printf("failed to record category correction: %v", err)
+		}
+	}
+
+	// Step 6: Return the updated expense with its policy warnings
+	if err := s.recordPolicyWarnings(ctx, expense, warnings); err != nil {
+		return nil, err
+	}
 	return expense, nil
 }
 
+// chargeTo sets the cost center and department of an expense; a nil code leaves it unchanged
+// Codes must name active dimensions of the caller's organization
+func (s *Service) chargeTo(ctx context.Context, expense *domain.Expense, costCenter, department *string) error {
+	for _, field := range []struct {
+		kind   string
+		code   *string
+		target *string
+	}{
+		{domain.DimensionCostCenter, costCenter, &expense.CostCenter},
+		{domain.DimensionDepartment, department, &expense.Department},
+	} {
+		if field.code == nil {
+			continue
+		}
+		if *field.code == "" {
+			*field.target = ""
+			continue
+		}
+		if s.dimensions == nil {
+			return domain.ErrUnknownDimension
+		}
+		code, err := s.dimensions.Resolve(ctx, field.kind, *field.code)
+		if err != nil {
+			return err
+		}
+		*field.target = code
+	}
+	return nil
+}
+
+// assignEmployer marks an expense reimbursable or personal and sets the employer that pays it back
+// A nil argument leaves that part unchanged; an employer implies reimbursable, and a personal
+// expense has no employer. The employer must be one of the caller's
+func (s *Service) assignEmployer(ctx context.Context, expense *domain.Expense, reimbursable *bool, employerID *string) error {
+	claimedBy := expense.EmployerID
+	if reimbursable != nil {
+		expense.Reimbursable = *reimbursable
+	}
+	if employerID != nil {
+		switch {
+		case *employerID == "":
+			expense.EmployerID = nil
+		case s.employers == nil:
+			return domain.ErrEmployerNotFound
+		default:
+			employer, err := s.employers.GetByID(ctx, *employerID)
+			if err != nil {
+				return err
+			}
+			expense.EmployerID = &employer.ID
+			expense.Reimbursable = true
+		}
+	}
+	if !expense.Reimbursable {
+		expense.EmployerID = nil
+	}
+
+	// An expense claimed in a batch stays reimbursable by the employer it was claimed from
+	if expense.ReimbursementBatchID != nil && (!expense.Reimbursable || !sameEmployer(claimedBy, expense.EmployerID)) {
+		return domain.ErrReimbursementClaimed
+	}
+	expense.SyncReimbursementStatus()
+	return nil
+}
+
+// sameEmployer reports whether two optional employer IDs are the same
+func sameEmployer(a, b *uuid.UUID) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// checkPolicy checks an expense against the expense policy when one is configured
+func (s *Service) checkPolicy(ctx context.Context, expense *domain.Expense, stage string) ([]*domain.PolicyViolation, error) {
+	if s.policies == nil {
+		return nil, nil
+	}
+	return s.policies.CheckExpense(ctx, expense, stage)
+}
+
+// recordPolicyWarnings records the policy warnings of a saved expense and attaches them to it
+func (s *Service) recordPolicyWarnings(ctx context.Context, expense *domain.Expense, warnings []*domain.PolicyViolation) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+	if err := s.policies.RecordWarnings(ctx, expense, warnings); err != nil {
+		return fmt.Errorf("failed to record policy warnings: %w", err)
+	}
+	expense.PolicyWarnings = warnings
+	return nil
+}
+
 // DeleteExpense removes an expense
 // This is a simple command use case
 func (s *Service) DeleteExpense(ctx context.Context, id string) error {
@@ -155,11 +919,99 @@ func (s *Service) DeleteExpense(ctx context.Context, id string) error {
 		return domain.ErrExpenseNotFound
 	}
 
-	// Step 2: Delete the expense from the repository
+	// Step 2: Refuse to delete expenses of an approved trip report
+	if s.trips != nil {
+		expense, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get expense: %w", err)
+		}
+		if err := s.checkNotFrozen(ctx, expense); err != nil {
+			return err
+		}
+	}
+
+	// Step 3: Refuse to delete expenses that were refunded; their refunds would be left dangling
+	parentID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrExpenseNotFound
+	}
+	refunds, err := s.repo.Count(ctx, map[string]interface{}{"parent_expense_id": parentID})
+	if err != nil {
+		return fmt.Errorf("failed to count refunds: %w", err)
+	}
+	if refunds > 0 {
+		return domain.ErrExpenseHasRefunds
+	}
+
+	// Step 4: Delete the expense from the repository
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete expense: %w", err)
 	}
 
-	// Step 3: Return nil to indicate success
+	// Step 5: Return nil to indicate success
+	return nil
+}
+
+// checkNotFrozen returns domain.ErrTripApproved when the expense belongs to an approved trip
+func (s *Service) checkNotFrozen(ctx context.Context, expense *domain.Expense) error {
+	if s.trips == nil || expense.TripID == nil {
+		return nil
+	}
+	trip, err := s.trips.GetByID(ctx, expense.TripID.String())
+	if err != nil {
+		if errors.Is(err, domain.ErrTripNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get trip: %w", err)
+	}
+	if trip.Approved() {
+		return domain.ErrTripApproved
+	}
+	return nil
+}
+
+// applyVAT splits the expense's gross amount into net and tax
+// rate and taxAmount are the caller's overrides; without a rate the category's default is used,
+// and without either the expense has no VAT information
+func (s *Service) applyVAT(ctx context.Context, expense *domain.Expense, rate *float64, taxAmount *domain.Money) error {
+	if rate == nil && s.categories != nil {
+		category, err := s.categories.GetByName(ctx, expense.Category)
+		switch {
+		case err == nil:
+			rate = category.VATRate
+		case !errors.Is(err, domain.ErrCategoryNotFound):
+			// Categories typed in freely aren't in the list and simply have no default
+			return fmt.Errorf("failed to get category VAT rate: %w", err)
+		}
+	}
+	if rate == nil && taxAmount == nil {
+		expense.ClearVAT()
+		return nil
+	}
+	return expense.ApplyVAT(rate, taxAmount)
+}
+
+// assignAccount links the expense to the account it was paid from
+// paidInCash picks the cash wallet (created on demand) so cash spending draws it down
+func (s *Service) assignAccount(ctx context.Context, expense *domain.Expense, accountID string, paidInCash bool) error {
+	if accountID == "" && !paidInCash {
+		return nil
+	}
+	if s.accounts == nil {
+		return domain.ErrAccountNotFound
+	}
+
+	var account *domain.Account
+	var err error
+	if paidInCash {
+		account, err = s.accounts.CashAccount(ctx)
+	} else {
+		account, err = s.accounts.accounts.GetByID(ctx, accountID)
+	}
+	if err != nil {
+		return err
+	}
+
+	expense.AccountID = &account.ID
 	return nil
 }
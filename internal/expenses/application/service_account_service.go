@@ -0,0 +1,197 @@
+// Package application contains the business logic and use cases
+// This file contains service accounts: admins create them, and machine clients exchange
+// their client ID and secret for an access token (the OAuth 2.0 client credentials grant)
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For matching repository errors
+	"fmt"     // For formatted string operations and error wrapping
+	"log"     // For logging failed last-used updates
+	"time"    // For token lifetimes
+
+	"myexpenses/internal/auth"            // The caller's permissions, secrets and tokens
+	"myexpenses/internal/clock"           // Time source for last-used times
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// GrantTypeClientCredentials is the only OAuth 2.0 grant type POST /auth/token accepts
+const GrantTypeClientCredentials = "client_credentials"
+
+// Audit actions recorded for service accounts
+const (
+	// AuditServiceAccountCreated is recorded when an admin creates a service account
+	AuditServiceAccountCreated = "service_account.created"
+
+	// AuditServiceAccountSecretRotated is recorded when an admin replaces an account's secret
+	AuditServiceAccountSecretRotated = "service_account.secret_rotated"
+
+	// AuditServiceAccountDeleted is recorded when an admin deletes a service account
+	AuditServiceAccountDeleted = "service_account.deleted"
+)
+
+// ServiceAccountService manages service accounts and issues their access tokens
+type ServiceAccountService struct {
+	accounts domain.ServiceAccountRepository
+	tokens   *auth.JWTIssuer
+	audit    domain.AuditRepository
+	clock    clock.Clock
+}
+
+// NewServiceAccountService creates a new service account service
+// tokens signs the access tokens, with the same key and lifetime as user logins
+func NewServiceAccountService(accounts domain.ServiceAccountRepository, tokens *auth.JWTIssuer, audit domain.AuditRepository, clk clock.Clock) *ServiceAccountService {
+	return &ServiceAccountService{accounts: accounts, tokens: tokens, audit: audit, clock: clock.Or(clk)}
+}
+
+// CreateServiceAccountRequest represents the request body for POST /admin/service-accounts
+type CreateServiceAccountRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Scope string `json:"scope" binding:"required"`
+}
+
+// ServiceAccountCredentials is a service account together with its client secret, which is never shown again
+type ServiceAccountCredentials struct {
+	*domain.ServiceAccount
+	ClientSecret string `json:"client_secret"`
+}
+
+// TokenRequest represents the request body for POST /auth/token
+// Clients may send their credentials in the body or with HTTP Basic authentication
+type TokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type" binding:"required"`
+	ClientID     string `form:"client_id" json:"client_id"`
+	ClientSecret string `form:"client_secret" json:"client_secret"`
+}
+
+// TokenResponse is an access token in the shape OAuth 2.0 clients expect (RFC 6749 section 5.1)
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// CreateAccount creates a service account and returns it with its client secret
+func (s *ServiceAccountService) CreateAccount(ctx context.Context, req *CreateServiceAccountRequest) (*ServiceAccountCredentials, error) {
+	// Step 1: Only admins create machine clients
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+
+	// Step 2: Generate the credentials
+	account, err := domain.NewServiceAccount(req.Name, req.Scope, auditActor(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if account.ClientID, err = auth.NewClientID(); err != nil {
+		return nil, fmt.Errorf("failed to generate client ID: %w", err)
+	}
+	secret, hash, err := auth.NewClientSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate client secret: %w", err)
+	}
+	account.SecretHash = hash
+
+	// Step 3: Save and record it
+	if err := s.accounts.Create(ctx, account); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, AuditServiceAccountCreated, account); err != nil {
+		return nil, err
+	}
+	return &ServiceAccountCredentials{ServiceAccount: account, ClientSecret: secret}, nil
+}
+
+// ListAccounts returns every service account
+func (s *ServiceAccountService) ListAccounts(ctx context.Context) ([]*domain.ServiceAccount, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	return s.accounts.List(ctx)
+}
+
+// RotateSecret replaces an account's client secret and returns the new one
+// The old secret stops working at once; tokens issued with it last until they expire
+func (s *ServiceAccountService) RotateSecret(ctx context.Context, id string) (*ServiceAccountCredentials, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	account, err := s.accounts.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	secret, hash, err := auth.NewClientSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate client secret: %w", err)
+	}
+	account.SecretHash = hash
+	if err := s.accounts.UpdateSecret(ctx, account); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, AuditServiceAccountSecretRotated, account); err != nil {
+		return nil, err
+	}
+	return &ServiceAccountCredentials{ServiceAccount: account, ClientSecret: secret}, nil
+}
+
+// DeleteAccount removes a service account; it can't get new tokens from then on
+func (s *ServiceAccountService) DeleteAccount(ctx context.Context, id string) error {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return domain.ErrForbidden
+	}
+	account, err := s.accounts.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.accounts.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.record(ctx, AuditServiceAccountDeleted, account)
+}
+
+// IssueToken exchanges a client ID and secret for an access token
+// Unknown clients and wrong secrets both return ErrInvalidClient, so client IDs can't be probed
+func (s *ServiceAccountService) IssueToken(ctx context.Context, req *TokenRequest) (*TokenResponse, error) {
+	// Step 1: Check the credentials
+	account, err := s.accounts.GetByClientID(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrServiceAccountNotFound) {
+			return nil, domain.ErrInvalidClient
+		}
+		return nil, err
+	}
+	if !auth.ClientSecretMatches(req.ClientSecret, account.SecretHash) {
+		return nil, domain.ErrInvalidClient
+	}
+
+	// Step 2: Issue a token acting as the account
+	token, expiresAt, err := s.tokens.IssueClient(account.ID.String(), account.Role(), account.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue token: %w", err)
+	}
+
+	// Step 3: Keep track of use, but don't fail the request if that doesn't work
+	now := s.clock.Now()
+	if err := s.accounts.TouchLastUsed(ctx, account.ID, now); err != nil {
+		log.Printf("failed to record use of service account %s: %v", account.ID, err)
+	}
+	return &TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(expiresAt.Sub(now) / time.Second),
+		Scope:       account.Scope,
+	}, nil
+}
+
+// record writes an audit entry for a service account
+func (s *ServiceAccountService) record(ctx context.Context, action string, account *domain.ServiceAccount) error {
+	entry, err := domain.NewAuditEntry(auditActor(ctx), action, "service_account", account.ID.String(), account)
+	if err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record service account change: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// Package application contains the business logic and use cases
+// This file contains tag autocomplete
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// TagService suggests tags from the ones the caller already uses
+type TagService struct {
+	tags domain.TagRepository
+}
+
+// NewTagService creates a new tag service
+func NewTagService(tags domain.TagRepository) *TagService {
+	return &TagService{tags: tags}
+}
+
+// SuggestTags returns the caller's tags starting with prefix, most used first
+// The prefix is normalized like stored tags, so "#Tra" finds "travel"; an empty prefix
+// returns the most used tags overall
+func (s *TagService) SuggestTags(ctx context.Context, prefix string, limit int) ([]*domain.TagSuggestion, error) {
+	if limit <= 0 || limit > maxSuggestions {
+		limit = maxSuggestions
+	}
+
+	suggestions, err := s.tags.SuggestTags(ctx, domain.NormalizeTag(prefix), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest tags: %w", err)
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,81 @@
+// Package application contains the business logic and use cases
+// This file contains the opt-in telemetry of self-hosted instances
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"runtime" // For the platform the instance runs on
+
+	"myexpenses/internal/clock"           // Time source
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/version"         // Build information
+)
+
+// TelemetryService builds the anonymous usage reports of this instance and sends them if the
+// operator opted in. Operators can always see the report, whether it is sent or not, so they
+// know exactly what would leave their server before switching it on
+type TelemetryService struct {
+	telemetry domain.TelemetryRepository
+	features  map[string]bool
+	sender    domain.TelemetrySender
+	clock     clock.Clock
+}
+
+// NewTelemetryService creates a new telemetry service
+// features maps the optional features to whether they are switched on, like GET /version
+// sender is nil unless telemetry is switched on; then nothing is ever sent
+func NewTelemetryService(telemetry domain.TelemetryRepository, features map[string]bool, sender domain.TelemetrySender, clk clock.Clock) *TelemetryService {
+	return &TelemetryService{
+		telemetry: telemetry,
+		features:  features,
+		sender:    sender,
+		clock:     clock.Or(clk),
+	}
+}
+
+// Enabled reports whether the operator opted in to sending reports
+func (s *TelemetryService) Enabled() bool {
+	return s.sender != nil
+}
+
+// Report builds the report this instance sends, or would send if telemetry were switched on
+func (s *TelemetryService) Report(ctx context.Context) (*domain.TelemetryReport, error) {
+	instanceID, err := s.telemetry.InstanceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.telemetry.Counts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A copy, so the report doesn't change if it is encoded later
+	features := make(map[string]bool, len(s.features))
+	for name, on := range s.features {
+		features[name] = on
+	}
+
+	build := version.Get()
+	return &domain.TelemetryReport{
+		InstanceID:  instanceID.String(),
+		Version:     build.Version,
+		GoVersion:   build.GoVersion,
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		GeneratedAt: s.clock.Now().UTC(),
+		Counts:      counts.Rounded(),
+		Features:    features,
+	}, nil
+}
+
+// Send builds the report and sends it; it does nothing unless telemetry is switched on
+func (s *TelemetryService) Send(ctx context.Context) error {
+	if !s.Enabled() {
+		return nil
+	}
+	report, err := s.Report(ctx)
+	if err != nil {
+		return err
+	}
+	return s.sender.Send(ctx, report)
+}
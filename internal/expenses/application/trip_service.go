@@ -0,0 +1,362 @@
+// Package application contains the business logic and use cases
+// This file contains trips and the trip report: a trip's expenses by leg and currency,
+// with the original amounts, the home-currency amounts and the rates that were applied
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"math"    // For rounding exchange rates
+	"sort"    // For ordering currencies
+	"strconv" // For formatting exchange rates
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/report"          // Renderable report documents
+
+	"github.com/google/uuid" // For parsing expense IDs
+)
+
+// otherLegName labels the expenses of a trip that fall outside every leg (e.g. flights booked in advance)
+const otherLegName = "Outside legs"
+
+// TripService manages trips and builds trip reports
+type TripService struct {
+	trips    domain.TripRepository
+	expenses domain.Repository
+}
+
+// NewTripService creates a new trip service
+func NewTripService(trips domain.TripRepository, expenses domain.Repository) *TripService {
+	return &TripService{
+		trips:    trips,
+		expenses: expenses,
+	}
+}
+
+// TripLegRequest is one leg of a CreateTripRequest
+type TripLegRequest struct {
+	Name      string `json:"name"`
+	Country   string `json:"country" binding:"required,len=2"`
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+}
+
+// CreateTripRequest represents the request body for POST /trips
+// Dates are YYYY-MM-DD; legs must lie within the trip and must not overlap
+type CreateTripRequest struct {
+	Name      string            `json:"name" binding:"required"`
+	Purpose   string            `json:"purpose"`
+	StartDate string            `json:"start_date" binding:"required"`
+	EndDate   string            `json:"end_date" binding:"required"`
+	Legs      []*TripLegRequest `json:"legs" binding:"dive"`
+}
+
+// AssignTripExpensesRequest represents the request body for POST /trips/{id}/expenses
+type AssignTripExpensesRequest struct {
+	ExpenseIDs []string `json:"expense_ids" binding:"required,min=1"`
+}
+
+// CreateTrip creates a trip with its legs
+func (s *TripService) CreateTrip(ctx context.Context, req *CreateTripRequest) (*domain.Trip, error) {
+	start, end, err := parseTripDates(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	legs := make([]*domain.TripLeg, 0, len(req.Legs))
+	for _, l := range req.Legs {
+		legStart, legEnd, err := parseTripDates(l.StartDate, l.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		leg, err := domain.NewTripLeg(l.Name, l.Country, legStart, legEnd)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, leg)
+	}
+
+	trip, err := domain.NewTrip(req.Name, req.Purpose, start, end, legs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.trips.Create(ctx, trip); err != nil {
+		return nil, err
+	}
+	return trip, nil
+}
+
+// ListTrips returns all trips, most recent first
+func (s *TripService) ListTrips(ctx context.Context) ([]*domain.Trip, error) {
+	return s.trips.List(ctx)
+}
+
+// GetTrip returns a trip with its legs
+func (s *TripService) GetTrip(ctx context.Context, id string) (*domain.Trip, error) {
+	return s.trips.GetByID(ctx, id)
+}
+
+// AssignExpenses adds expenses to a trip (moving them from any other trip)
+// Approved trips are frozen: nothing can be added to them or moved out of them
+func (s *TripService) AssignExpenses(ctx context.Context, tripID string, req *AssignTripExpensesRequest) error {
+	trip, err := s.trips.GetByID(ctx, tripID)
+	if err != nil {
+		return err
+	}
+	if trip.Approved() {
+		return domain.ErrTripApproved
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.ExpenseIDs))
+	ids := make([]uuid.UUID, 0, len(req.ExpenseIDs))
+	for _, raw := range req.ExpenseIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return domain.ErrExpenseNotFound
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return s.trips.AssignExpenses(ctx, trip.ID, ids)
+}
+
+// RemoveExpense takes an expense off a trip that hasn't been approved
+func (s *TripService) RemoveExpense(ctx context.Context, tripID, expenseID string) error {
+	trip, err := s.trips.GetByID(ctx, tripID)
+	if err != nil {
+		return err
+	}
+	if trip.Approved() {
+		return domain.ErrTripApproved
+	}
+	id, err := uuid.Parse(expenseID)
+	if err != nil {
+		return domain.ErrExpenseNotFound
+	}
+	return s.trips.RemoveExpense(ctx, trip.ID, id)
+}
+
+// TripCurrencyTotal is what was spent in one currency during a leg
+type TripCurrencyTotal struct {
+	Currency     string       `json:"currency"`
+	Count        int          `json:"count"`
+	Amount       domain.Money `json:"amount"`
+	BaseCurrency string       `json:"base_currency"`
+	BaseAmount   domain.Money `json:"base_amount"`
+
+	// Rate is the effective rate over the leg (BaseAmount / Amount)
+	// MinRate and MaxRate show how much the locked-in rates varied between expenses
+	Rate    float64 `json:"rate"`
+	MinRate float64 `json:"min_rate"`
+	MaxRate float64 `json:"max_rate"`
+}
+
+// TripLegReport is one leg of a trip report
+type TripLegReport struct {
+	// Leg is nil for the expenses outside every leg
+	Leg        *domain.TripLeg      `json:"leg,omitempty"`
+	Name       string               `json:"name"`
+	Currencies []*TripCurrencyTotal `json:"currencies"`
+
+	// BaseTotals are the leg's home-currency totals (one entry unless the home currency changed)
+	BaseTotals map[string]domain.Money `json:"base_totals"`
+}
+
+// TripReport is a trip's spending by leg and currency, ready for submission to an employer
+type TripReport struct {
+	Trip       *domain.Trip            `json:"trip"`
+	Legs       []*TripLegReport        `json:"legs"`
+	BaseTotals map[string]domain.Money `json:"base_totals"`
+	Expenses   []*domain.Expense       `json:"expenses"`
+}
+
+// Report builds the trip report
+// Expenses are placed in the leg covering their date; the amounts use the rate locked in
+// when each expense was entered, so the report matches what was actually charged
+func (s *TripService) Report(ctx context.Context, tripID string) (*TripReport, error) {
+	trip, err := s.trips.GetByID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.expenses.GetAll(ctx, map[string]interface{}{"trip_id": trip.ID.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trip expenses: %w", err)
+	}
+	// Itemize in date order
+	sort.SliceStable(expenses, func(i, j int) bool { return expenses[i].Date.Before(expenses[j].Date) })
+
+	// Step 1: One entry per leg, plus one for expenses outside the legs
+	legs := make(map[*domain.TripLeg]*TripLegReport, len(trip.Legs))
+	ordered := make([]*TripLegReport, 0, len(trip.Legs)+1)
+	for _, leg := range trip.Legs {
+		legs[leg] = &TripLegReport{Leg: leg, Name: leg.Name}
+		ordered = append(ordered, legs[leg])
+	}
+	other := &TripLegReport{Name: otherLegName}
+
+	// Step 2: Add every expense to its leg and currency
+	result := &TripReport{Trip: trip, Legs: []*TripLegReport{}, BaseTotals: map[string]domain.Money{}, Expenses: expenses}
+	totals := make(map[*TripLegReport]map[string]*TripCurrencyTotal)
+	for _, expense := range expenses {
+		leg := other
+		if l := trip.LegFor(expense.Date); l != nil {
+			leg = legs[l]
+		}
+		if totals[leg] == nil {
+			totals[leg] = make(map[string]*TripCurrencyTotal)
+		}
+		key := expense.Currency + "/" + expense.BaseCurrency
+		total, ok := totals[leg][key]
+		if !ok {
+			total = &TripCurrencyTotal{
+				Currency:     expense.Currency,
+				BaseCurrency: expense.BaseCurrency,
+				MinRate:      expense.ExchangeRate,
+				MaxRate:      expense.ExchangeRate,
+			}
+			totals[leg][key] = total
+		}
+		total.Count++
+		total.Amount = total.Amount.Add(expense.Amount)
+		total.BaseAmount = total.BaseAmount.Add(expense.BaseAmount)
+		if expense.ExchangeRate < total.MinRate {
+			total.MinRate = expense.ExchangeRate
+		}
+		if expense.ExchangeRate > total.MaxRate {
+			total.MaxRate = expense.ExchangeRate
+		}
+		result.BaseTotals[expense.BaseCurrency] = result.BaseTotals[expense.BaseCurrency].Add(expense.BaseAmount)
+	}
+
+	// Step 3: Compute effective rates and drop the empty "outside legs" entry
+	if totals[other] != nil {
+		ordered = append(ordered, other)
+	}
+	for _, leg := range ordered {
+		leg.Currencies = []*TripCurrencyTotal{}
+		leg.BaseTotals = map[string]domain.Money{}
+		for _, total := range totals[leg] {
+			if !total.Amount.IsZero() {
+				total.Rate = roundRate(total.BaseAmount.Float64() / total.Amount.Float64())
+			}
+			leg.BaseTotals[total.BaseCurrency] = leg.BaseTotals[total.BaseCurrency].Add(total.BaseAmount)
+			leg.Currencies = append(leg.Currencies, total)
+		}
+		sort.Slice(leg.Currencies, func(i, j int) bool { return leg.Currencies[i].Currency < leg.Currencies[j].Currency })
+		result.Legs = append(result.Legs, leg)
+	}
+	return result, nil
+}
+
+// Document converts the trip report into a renderable document
+// The PDF rendering is meant to be handed to an employer as is
+func (r *TripReport) Document() *report.Document {
+	trip := r.Trip
+	summary := []report.Field{
+		{Key: "trip", Label: "Trip", Kind: report.KindText, Value: trip.Name},
+		{Key: "start_date", Label: "From", Kind: report.KindDate, Value: trip.StartDate},
+		{Key: "end_date", Label: "To", Kind: report.KindDate, Value: trip.EndDate},
+	}
+	if trip.Purpose != "" {
+		summary = append(summary, report.Field{Key: "purpose", Label: "Purpose", Kind: report.KindText, Value: trip.Purpose})
+	}
+	currencies := make([]string, 0, len(r.BaseTotals))
+	for currency := range r.BaseTotals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		summary = append(summary, report.Field{Key: "total_" + currency, Label: "Total (" + currency + ")", Kind: report.KindAmount, Value: r.BaseTotals[currency]})
+	}
+
+	// Leg breakdown: one row per leg and currency
+	var legRows []report.Row
+	for _, leg := range r.Legs {
+		country := ""
+		if leg.Leg != nil {
+			country = leg.Leg.Country
+		}
+		for _, total := range leg.Currencies {
+			legRows = append(legRows, report.Row{
+				leg.Name, country, total.Currency, total.Count, total.Amount,
+				formatRate(total.Rate), total.BaseCurrency, total.BaseAmount,
+			})
+		}
+	}
+
+	// Itemized expenses with the rate each one was converted at
+	expenseRows := make([]report.Row, len(r.Expenses))
+	for i, expense := range r.Expenses {
+		leg := otherLegName
+		if l := trip.LegFor(expense.Date); l != nil {
+			leg = l.Name
+		}
+		expenseRows[i] = report.Row{
+			expense.Date, leg, expense.Description, expense.Category, expense.Currency, expense.Amount,
+			formatRate(expense.ExchangeRate), expense.BaseCurrency, expense.BaseAmount,
+		}
+	}
+
+	return &report.Document{
+		Title:   "Trip report: " + trip.Name,
+		Summary: summary,
+		Sections: []*report.Section{
+			{
+				Key:   "legs",
+				Title: "By leg and currency",
+				Columns: []report.Column{
+					{Key: "leg", Title: "Leg", Kind: report.KindText},
+					{Key: "country", Title: "Country", Kind: report.KindText},
+					{Key: "currency", Title: "Currency", Kind: report.KindText},
+					{Key: "count", Title: "Expenses", Kind: report.KindNumber},
+					{Key: "amount", Title: "Amount", Kind: report.KindAmount},
+					{Key: "rate", Title: "Rate", Kind: report.KindText},
+					{Key: "base_currency", Title: "Home currency", Kind: report.KindText},
+					{Key: "base_amount", Title: "Home amount", Kind: report.KindAmount},
+				},
+				Rows: report.SliceRows(legRows),
+			},
+			{
+				Key:   "expenses",
+				Title: "Expenses",
+				Columns: []report.Column{
+					{Key: "date", Title: "Date", Kind: report.KindDate},
+					{Key: "leg", Title: "Leg", Kind: report.KindText},
+					{Key: "description", Title: "Description", Kind: report.KindText},
+					{Key: "category", Title: "Category", Kind: report.KindText},
+					{Key: "currency", Title: "Currency", Kind: report.KindText},
+					{Key: "amount", Title: "Amount", Kind: report.KindAmount},
+					{Key: "rate", Title: "Rate", Kind: report.KindText},
+					{Key: "base_currency", Title: "Home currency", Kind: report.KindText},
+					{Key: "base_amount", Title: "Home amount", Kind: report.KindAmount},
+				},
+				Rows: report.SliceRows(expenseRows),
+			},
+		},
+	}
+}
+
+// parseTripDates parses a YYYY-MM-DD date range
+func parseTripDates(from, to string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return time.Time{}, time.Time{}, domain.ErrInvalidTrip
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return time.Time{}, time.Time{}, domain.ErrInvalidTrip
+	}
+	return start, end, nil
+}
+
+// roundRate rounds an exchange rate to 6 decimals, the precision rates are quoted in
+func roundRate(rate float64) float64 {
+	return math.Round(rate*1e6) / 1e6
+}
+
+// formatRate formats an exchange rate for the report tables (amount columns only have two decimals)
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', 4, 64)
+}
@@ -0,0 +1,271 @@
+// Package application contains the business logic and use cases
+// This file contains the admin-only view of users: listing, disabling and deleting them,
+// resetting passwords, changing roles and global stats
+package application
+
+import (
+	"context"         // For request context (cancellation, timeouts)
+	"crypto/rand"     // For generating temporary passwords
+	"encoding/base64" // For encoding temporary passwords as text
+	"fmt"             // For formatted string operations and error wrapping
+
+	"myexpenses/internal/auth"            // The caller's permissions and password hashing
+	"myexpenses/internal/clock"           // Time source for disabling and revocations
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// Audit actions recorded for user management
+const (
+	// AuditUserRoleChanged is recorded when an admin changes a user's role
+	AuditUserRoleChanged = "user.role_changed"
+
+	// AuditUserDisabled is recorded when an admin disables a user
+	AuditUserDisabled = "user.disabled"
+
+	// AuditUserEnabled is recorded when an admin enables a disabled user again
+	AuditUserEnabled = "user.enabled"
+
+	// AuditUserDeleted is recorded when an admin deletes a user
+	AuditUserDeleted = "user.deleted"
+
+	// AuditUserPasswordReset is recorded when an admin resets a user's password
+	AuditUserPasswordReset = "user.password_reset"
+)
+
+// temporaryPasswordBytes is how many random bytes a generated temporary password carries
+const temporaryPasswordBytes = 12
+
+// UserAdminService lets admins manage users and see figures across all of them
+// Every use case checks the caller is an admin, so it can't be reached by mistake from another route
+type UserAdminService struct {
+	users         domain.UserRepository
+	refreshTokens domain.RefreshTokenRepository
+	stats         domain.StatsRepository
+	audit         domain.AuditRepository
+	clock         clock.Clock
+}
+
+// NewUserAdminService creates a new user admin service
+// refreshTokens lets disabling a user and resetting their password log them out everywhere
+func NewUserAdminService(users domain.UserRepository, refreshTokens domain.RefreshTokenRepository, stats domain.StatsRepository, audit domain.AuditRepository, clk clock.Clock) *UserAdminService {
+	return &UserAdminService{users: users, refreshTokens: refreshTokens, stats: stats, audit: audit, clock: clock.Or(clk)}
+}
+
+// SetUserRoleRequest represents the request body for PUT /admin/users/{id}/role
+type SetUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// ResetPasswordRequest represents the request body for POST /admin/users/{id}/reset-password
+// Without a password a random temporary one is generated and returned once
+type ResetPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// UserList is one page of a user listing
+type UserList struct {
+	Users []*domain.User `json:"users"`
+
+	// Total is how many users match the search across all pages
+	Total  int64 `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// PasswordReset is the outcome of a password reset
+type PasswordReset struct {
+	User *domain.User `json:"user"`
+
+	// TemporaryPassword is the generated password (empty when the admin chose one)
+	// It is shown only in this response; the user should change it after logging in
+	TemporaryPassword string `json:"temporary_password,omitempty"`
+
+	// RevokedSessions counts the sessions that were logged out
+	RevokedSessions int64 `json:"revoked_sessions"`
+}
+
+// ListUsers returns one page of the users matching filter
+func (s *UserAdminService) ListUsers(ctx context.Context, filter domain.UserFilter) (*UserList, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = domain.DefaultUserPageSize
+	}
+	filter.Limit = min(filter.Limit, domain.MaxUserPageSize)
+	filter.Offset = max(filter.Offset, 0)
+
+	users, total, err := s.users.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &UserList{Users: users, Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+// SetUserRole changes a user's role and records the change in the audit log
+// The user's current access token keeps its old role until it is refreshed
+func (s *UserAdminService) SetUserRole(ctx context.Context, id string, req *SetUserRoleRequest) (*domain.User, error) {
+	// Step 1: Only admins may hand out roles
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+
+	// Step 2: Change the role
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	previous := user.Role
+	if err := user.SetRole(req.Role); err != nil {
+		return nil, err
+	}
+	if user.Role == previous {
+		return user, nil
+	}
+	if err := s.users.UpdateRole(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// Step 3: Record who changed it
+	if err := s.record(ctx, AuditUserRoleChanged, user, map[string]string{
+		"from": previous,
+		"to":   user.Role,
+	}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetDisabled disables or enables a user
+// Disabling logs the user out everywhere, access tokens already issued included
+func (s *UserAdminService) SetDisabled(ctx context.Context, id string, disabled bool) (*domain.User, error) {
+	// Step 1: Only admins, and not on their own account
+	user, err := s.managedUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled() == disabled {
+		return user, nil
+	}
+
+	// Step 2: Save the new state
+	now := s.clock.Now()
+	user.DisabledAt = nil
+	action := AuditUserEnabled
+	if disabled {
+		user.DisabledAt = &now
+		action = AuditUserDisabled
+	}
+	if err := s.users.UpdateDisabled(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// Step 3: End the user's sessions and record who did it
+	if disabled {
+		if _, err := s.refreshTokens.RevokeAllForUser(ctx, user.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.record(ctx, action, user, map[string]string{"email": user.Email}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser removes a user account
+// Their sessions and API keys stop working with the account; the expenses they recorded are kept
+// for the books but no longer reachable by anyone
+func (s *UserAdminService) DeleteUser(ctx context.Context, id string) error {
+	user, err := s.managedUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.refreshTokens.RevokeAllForUser(ctx, user.ID, s.clock.Now()); err != nil {
+		return err
+	}
+	if err := s.users.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.record(ctx, AuditUserDeleted, user, map[string]string{"email": user.Email, "role": user.Role})
+}
+
+// ResetPassword sets a new password for a user and logs them out everywhere
+func (s *UserAdminService) ResetPassword(ctx context.Context, id string, req *ResetPasswordRequest) (*PasswordReset, error) {
+	// Step 1: Only admins may reset passwords
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Use the admin's password or generate one
+	reset := &PasswordReset{User: user}
+	password := req.Password
+	if password == "" {
+		random := make([]byte, temporaryPasswordBytes)
+		if _, err := rand.Read(random); err != nil {
+			return nil, fmt.Errorf("failed to generate password: %w", err)
+		}
+		password = base64.RawURLEncoding.EncodeToString(random)
+		reset.TemporaryPassword = password
+	}
+	if len(password) < domain.MinPasswordLength || len(password) > maxPasswordBytes {
+		return nil, domain.ErrWeakPassword
+	}
+	if user.PasswordHash, err = auth.HashPassword(password); err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.users.UpdatePassword(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// Step 3: Whoever knew the old password is logged out
+	if reset.RevokedSessions, err = s.refreshTokens.RevokeAllForUser(ctx, user.ID, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, AuditUserPasswordReset, user, map[string]any{
+		"generated":        reset.TemporaryPassword != "",
+		"revoked_sessions": reset.RevokedSessions,
+	}); err != nil {
+		return nil, err
+	}
+	return reset, nil
+}
+
+// managedUser returns the user an admin is about to disable or delete
+// Admins can't lock themselves out, which also keeps the last admin from disappearing by accident
+func (s *UserAdminService) managedUser(ctx context.Context, id string) (*domain.User, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID.String() == auth.UserID(ctx) {
+		return nil, domain.ErrCannotManageSelf
+	}
+	return user, nil
+}
+
+// record writes an audit entry about a user
+func (s *UserAdminService) record(ctx context.Context, action string, user *domain.User, details any) error {
+	entry, err := domain.NewAuditEntry(auditActor(ctx), action, "user", user.ID.String(), details)
+	if err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record user change: %w", err)
+	}
+	return nil
+}
+
+// Stats returns figures across all users
+func (s *UserAdminService) Stats(ctx context.Context) (*domain.GlobalStats, error) {
+	if !auth.Can(ctx, auth.PermissionAdmin) {
+		return nil, domain.ErrForbidden
+	}
+	return s.stats.GlobalStats(ctx)
+}
@@ -0,0 +1,425 @@
+// Package application contains the business logic and use cases
+// This file contains user registration and login
+package application
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"errors"       // For matching repository errors
+	"fmt"          // For formatted string operations and error wrapping
+	"time"         // For token expiry
+	"unicode/utf8" // For shortening user agents
+
+	"myexpenses/internal/auth"            // Password hashing, access tokens and the caller
+	"myexpenses/internal/clock"           // Time source for refresh token expiry
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For parsing user and session IDs
+)
+
+// maxPasswordBytes is the longest password bcrypt can hash without truncating it
+const maxPasswordBytes = 72
+
+// maxUserAgentLength is how much of a client's User-Agent is kept for the session list
+const maxUserAgentLength = 512
+
+// UserService registers users and logs them in
+// A login hands out a short-lived access token (a JWT) and a long-lived refresh token;
+// clients exchange the refresh token for new tokens instead of asking for the password again
+type UserService struct {
+	users         domain.UserRepository
+	tokens        *auth.JWTIssuer
+	refreshTokens domain.RefreshTokenRepository
+	refreshTTL    time.Duration
+	clock         clock.Clock
+
+	// dummyHash is compared against when a login names an unknown email,
+	// so unknown and known emails take equally long to reject
+	dummyHash string
+
+	// throttle limits failed logins; attempts counts them
+	throttle LoginThrottle
+	attempts *loginAttempts
+
+	// billing limits new accounts to the seats of the tenant's plan (nil means no limit)
+	billing *BillingService
+}
+
+// UserServiceOption configures optional behavior of the user service
+type UserServiceOption func(*UserService)
+
+// WithLoginThrottle replaces DefaultLoginThrottle
+func WithLoginThrottle(throttle LoginThrottle) UserServiceOption {
+	return func(s *UserService) {
+		s.throttle = throttle
+	}
+}
+
+// WithSeatLimit refuses registrations once every seat of the tenant's plan is taken
+func WithSeatLimit(billing *BillingService) UserServiceOption {
+	return func(s *UserService) {
+		s.billing = billing
+	}
+}
+
+// NewUserService creates a new user service
+// refreshTTL is how long refresh tokens last (<= 0 uses auth.DefaultRefreshTokenTTL)
+// Failed logins are throttled with DefaultLoginThrottle unless WithLoginThrottle says otherwise
+func NewUserService(users domain.UserRepository, tokens *auth.JWTIssuer, refreshTokens domain.RefreshTokenRepository, refreshTTL time.Duration, clk clock.Clock, opts ...UserServiceOption) (*UserService, error) {
+	dummyHash, err := auth.HashPassword("not a real password")
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare password checks: %w", err)
+	}
+	if refreshTTL <= 0 {
+		refreshTTL = auth.DefaultRefreshTokenTTL
+	}
+	s := &UserService{
+		users:         users,
+		tokens:        tokens,
+		refreshTokens: refreshTokens,
+		refreshTTL:    refreshTTL,
+		clock:         clock.Or(clk),
+		dummyHash:     dummyHash,
+		throttle:      DefaultLoginThrottle,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.attempts = newLoginAttempts(s.throttle.Window, s.throttle.Lockout, s.clock)
+	return s, nil
+}
+
+// RegisterRequest represents the request to create a user account
+type RegisterRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Name     string `json:"name"`
+}
+
+// LoginRequest represents the request to log in
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+
+	// ClientIP is the address the login comes from, set by the handler for throttling
+	// Together with UserAgent it also describes the new session
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// RefreshRequest represents the request to exchange a refresh token, or to revoke it on logout
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+
+	// ClientIP and UserAgent update the device shown for the session, set by the handler
+	ClientIP  string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// LoginResult is what a successful login or refresh returns
+type LoginResult struct {
+	// Token is sent back as "Authorization: Bearer <token>" on later requests
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// RefreshToken gets a new Token (and RefreshToken) from POST /auth/refresh once Token expires
+	// It works once: every refresh returns a new one
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+
+	User *domain.User `json:"user"`
+}
+
+// Register creates a user account
+func (s *UserService) Register(ctx context.Context, req *RegisterRequest) (*domain.User, error) {
+	// Step 1: Validate the email and password
+	user, err := domain.NewUser(req.Email, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Password) < domain.MinPasswordLength || len(req.Password) > maxPasswordBytes {
+		return nil, domain.ErrWeakPassword
+	}
+
+	// Step 2: Refuse emails that already have an account
+	if _, err := s.users.GetByEmail(ctx, user.Email); err == nil {
+		return nil, domain.ErrEmailTaken
+	} else if !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+
+	// Step 3: Make sure the tenant has a seat for them
+	if s.billing != nil {
+		if err := s.billing.CheckSeat(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 4: Store the user with a hash of the password
+	user.PasswordHash, err = auth.HashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login checks a user's credentials and issues an access token (a signed JWT) and a refresh token
+// Failed logins are counted per account and per address; once either is blocked the login is
+// refused with a *LoginBlockedError before the password is even checked
+func (s *UserService) Login(ctx context.Context, req *LoginRequest) (*LoginResult, error) {
+	// Step 1: Refuse blocked addresses and locked accounts
+	// Accounts are keyed by the email tried, so unknown emails lock like known ones and don't stand out
+	accountKey := "account:" + domain.NormalizeEmail(req.Email)
+	addressKey := "address:" + req.ClientIP
+	if s.throttle.MaxAddressFailures > 0 && req.ClientIP != "" {
+		if wait := s.attempts.blocked(addressKey); wait > 0 {
+			return nil, &LoginBlockedError{Err: domain.ErrTooManyLoginAttempts, RetryAfter: wait}
+		}
+	}
+	if s.throttle.MaxAccountFailures > 0 {
+		if wait := s.attempts.blocked(accountKey); wait > 0 {
+			return nil, &LoginBlockedError{Err: domain.ErrAccountLocked, RetryAfter: wait}
+		}
+	}
+
+	// Step 2: Check the credentials
+	user, err := s.users.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			return nil, err
+		}
+		// Spend the same time as a wrong password would
+		_, _ = auth.CheckPassword(s.dummyHash, req.Password)
+		return nil, s.loginFailed(accountKey, addressKey, req.ClientIP)
+	}
+
+	ok, err := auth.CheckPassword(user.PasswordHash, req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check password: %w", err)
+	}
+	if !ok {
+		return nil, s.loginFailed(accountKey, addressKey, req.ClientIP)
+	}
+	if user.Disabled() {
+		// Only said to callers who know the password, so it doesn't reveal which accounts exist
+		return nil, domain.ErrUserDisabled
+	}
+
+	// Step 3: A successful login clears the account's failures (the address keeps its own)
+	s.attempts.reset(accountKey)
+	return s.issueTokens(ctx, user, nil, req.UserAgent, req.ClientIP)
+}
+
+// loginFailed counts a failed login and returns the error for it:
+// a *LoginBlockedError when this failure locked the account or blocked the address, ErrInvalidCredentials otherwise
+func (s *UserService) loginFailed(accountKey, addressKey, clientIP string) error {
+	var blocked error
+	if s.throttle.MaxAddressFailures > 0 && clientIP != "" {
+		if wait := s.attempts.fail(addressKey, s.throttle.MaxAddressFailures); wait > 0 {
+			blocked = &LoginBlockedError{Err: domain.ErrTooManyLoginAttempts, RetryAfter: wait}
+		}
+	}
+	if s.throttle.MaxAccountFailures > 0 {
+		if wait := s.attempts.fail(accountKey, s.throttle.MaxAccountFailures); wait > 0 {
+			blocked = &LoginBlockedError{Err: domain.ErrAccountLocked, RetryAfter: wait}
+		}
+	}
+	if blocked != nil {
+		return blocked
+	}
+	return domain.ErrInvalidCredentials
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token
+// The refresh token used is revoked. Presenting it again means it was copied, so every
+// login of its user is revoked: the thief and the user both have to log in again,
+// and only the user knows the password
+func (s *UserService) Refresh(ctx context.Context, req *RefreshRequest) (*LoginResult, error) {
+	// Step 1: Find the token
+	stored, err := s.refreshTokens.GetByHash(ctx, auth.HashRefreshToken(req.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	if now.After(stored.ExpiresAt) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	// Step 2: Use it up; losing the race against another refresh counts as reuse
+	used, err := s.refreshTokens.Use(ctx, stored.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		if _, err := s.refreshTokens.RevokeAllForUser(ctx, stored.UserID, now); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	// Step 3: Issue new tokens, unless the user has been removed or disabled meanwhile
+	user, err := s.users.GetByID(ctx, stored.UserID.String())
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if user.Disabled() {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	return s.issueTokens(ctx, user, stored, req.UserAgent, req.ClientIP)
+}
+
+// Logout revokes a refresh token
+// Access tokens already issued in its session stop working with it
+// Unknown and already revoked tokens are not an error, so logging out twice is harmless
+func (s *UserService) Logout(ctx context.Context, req *RefreshRequest) error {
+	stored, err := s.refreshTokens.GetByHash(ctx, auth.HashRefreshToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) {
+			return nil
+		}
+		return err
+	}
+	_, err = s.refreshTokens.Revoke(ctx, stored.ID, s.clock.Now())
+	return err
+}
+
+// PurgeExpiredRefreshTokens deletes refresh tokens that expired and returns how many there were
+func (s *UserService) PurgeExpiredRefreshTokens(ctx context.Context) (int64, error) {
+	return s.refreshTokens.DeleteExpired(ctx, s.clock.Now())
+}
+
+// issueTokens issues an access token and a stored refresh token for user
+// session is the token being refreshed, whose session continues; nil starts a new session
+// userAgent and clientIP describe the device for the session list
+func (s *UserService) issueTokens(ctx context.Context, user *domain.User, session *domain.RefreshToken, userAgent, clientIP string) (*LoginResult, error) {
+	// Step 1: The refresh token, which carries the session
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue refresh token: %w", err)
+	}
+	now := s.clock.Now()
+	stored := domain.NewRefreshToken(user.ID, refreshHash, now.Add(s.refreshTTL), session, now)
+	stored.UserAgent = truncate(userAgent, maxUserAgentLength)
+	stored.IPAddress = clientIP
+	if err := s.refreshTokens.Create(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	// Step 2: The access token, naming the session so it can be recognized as the current one
+	token, expiresAt, err := s.tokens.Issue(user.ID.String(), user.Role, stored.Session().String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue token: %w", err)
+	}
+
+	return &LoginResult{
+		Token:                 token,
+		ExpiresAt:             expiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: stored.ExpiresAt,
+		User:                  user,
+	}, nil
+}
+
+// ListSessions returns the caller's active sessions (logged-in devices), most recently used first
+// The session of the access token the request was made with is marked as current
+func (s *UserService) ListSessions(ctx context.Context) ([]*domain.Session, error) {
+	principal, userID, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.refreshTokens.ListUsable(ctx, userID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*domain.Session, 0, len(tokens))
+	seen := make(map[uuid.UUID]bool, len(tokens))
+	for _, token := range tokens {
+		// A refresh racing the listing may briefly leave two tokens in a session; show it once
+		if seen[token.Session()] {
+			continue
+		}
+		seen[token.Session()] = true
+		session := token.AsSession()
+		session.Current = principal.SessionID == session.ID.String()
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// RevokeSession logs one of the caller's sessions out, e.g. a lost phone
+// Its refresh token and the access tokens already issued in it stop working at once
+// Viewers may log out their own devices, but read-only API keys may not
+func (s *UserService) RevokeSession(ctx context.Context, id string) error {
+	principal, userID, err := s.caller(ctx)
+	if err != nil {
+		return err
+	}
+	if principal.ReadOnly {
+		return domain.ErrForbidden
+	}
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrSessionNotFound
+	}
+	revoked, err := s.refreshTokens.RevokeSession(ctx, userID, sessionID, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+// SessionActive reports whether the login session an access token was issued in is still logged in
+// Sessions that were logged out or revoked, or whose tokens have all been purged, are not
+func (s *UserService) SessionActive(ctx context.Context, userID, sessionID string) (bool, error) {
+	user, err := uuid.Parse(userID)
+	if err != nil {
+		return false, nil
+	}
+	session, err := uuid.Parse(sessionID)
+	if err != nil {
+		return false, nil
+	}
+	return s.refreshTokens.SessionActive(ctx, user, session)
+}
+
+// caller returns the logged-in caller and their user ID
+func (s *UserService) caller(ctx context.Context) (auth.Principal, uuid.UUID, error) {
+	principal, err := auth.Require(ctx)
+	if err != nil {
+		return auth.Principal{}, uuid.Nil, err
+	}
+	userID, err := uuid.Parse(principal.UserID)
+	if err != nil {
+		return auth.Principal{}, uuid.Nil, domain.ErrUserNotFound
+	}
+	return principal, userID, nil
+}
+
+// truncate shortens s to at most limit bytes, without splitting a UTF-8 character
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
+
+// CurrentUser returns the logged-in caller
+func (s *UserService) CurrentUser(ctx context.Context) (*domain.User, error) {
+	principal, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.users.GetByID(ctx, principal.UserID)
+}
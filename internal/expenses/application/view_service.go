@@ -0,0 +1,103 @@
+// Package application contains the business logic and use cases
+// This file contains saved views: named filter sets of the expense list, applied with ?view=
+package application
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For comparing names case-insensitively
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// ViewService manages the caller's saved views
+// Applying a view to the expense list is done by Service.ApplyView
+type ViewService struct {
+	views domain.ViewRepository
+}
+
+// NewViewService creates a new view service
+func NewViewService(views domain.ViewRepository) *ViewService {
+	return &ViewService{views: views}
+}
+
+// ViewRequest represents the request body for POST /views and PUT /views/{id}
+// The filters are sent either structured or as a ?q= query, e.g. "category:Travel AND date:2024"
+type ViewRequest struct {
+	Name    string             `json:"name" binding:"required"`
+	Query   string             `json:"query"`
+	Filters domain.ViewFilters `json:"filters"`
+}
+
+// CreateView saves a new view for the caller
+// Names are unique per user (ignoring case), so clients can show them as tabs
+func (s *ViewService) CreateView(ctx context.Context, req *ViewRequest) (*domain.View, error) {
+	view, err := domain.NewView(req.Name, req.Query, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.views.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= domain.MaxViews {
+		return nil, domain.ErrTooManyViews
+	}
+	if nameTaken(existing, view) {
+		return nil, domain.ErrViewNameTaken
+	}
+
+	if err := s.views.Create(ctx, view); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// ListViews returns the caller's views by name
+func (s *ViewService) ListViews(ctx context.Context) ([]*domain.View, error) {
+	return s.views.List(ctx)
+}
+
+// GetView returns one of the caller's views
+func (s *ViewService) GetView(ctx context.Context, id string) (*domain.View, error) {
+	return s.views.GetByID(ctx, id)
+}
+
+// UpdateView replaces the name and filters of one of the caller's views
+func (s *ViewService) UpdateView(ctx context.Context, id string, req *ViewRequest) (*domain.View, error) {
+	view, err := s.views.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := view.Change(req.Name, req.Query, req.Filters); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.views.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if nameTaken(existing, view) {
+		return nil, domain.ErrViewNameTaken
+	}
+
+	if err := s.views.Update(ctx, view); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// DeleteView removes one of the caller's views
+func (s *ViewService) DeleteView(ctx context.Context, id string) error {
+	return s.views.Delete(ctx, id)
+}
+
+// nameTaken reports whether another of the views has the view's name, ignoring case
+func nameTaken(views []*domain.View, view *domain.View) bool {
+	for _, other := range views {
+		if other.ID != view.ID && strings.EqualFold(other.Name, view.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,313 @@
+// Package domain contains the core business logic and entities
+// This file defines accounts (where money is held) and transfers between them
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"sort"    // For ordering ledger entries
+	"strings" // For normalizing names
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// AccountType describes what kind of place money is held in
+type AccountType string
+
+const (
+	// AccountTypeChecking is a regular bank account
+	AccountTypeChecking AccountType = "checking"
+
+	// AccountTypeCreditCard is a credit card account
+	AccountTypeCreditCard AccountType = "credit_card"
+
+	// AccountTypeCash is the physical cash wallet
+	// ATM withdrawals move money into it and cash expenses draw it down
+	AccountTypeCash AccountType = "cash"
+)
+
+// CashAccountName is the name of the cash wallet created automatically on the first withdrawal
+const CashAccountName = "Cash"
+
+// IsValid reports whether t is one of the known account types
+func (t AccountType) IsValid() bool {
+	switch t {
+	case AccountTypeChecking, AccountTypeCreditCard, AccountTypeCash:
+		return true
+	}
+	return false
+}
+
+// Account represents a place money is held: a bank account, a credit card or the cash wallet
+type Account struct {
+	// ID is a unique identifier for each account
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user the account belongs to; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// Name is what the user calls the account (e.g. "Main checking", "Cash")
+	Name string `json:"name" gorm:"not null"`
+
+	// Type is checking, credit_card or cash
+	Type AccountType `json:"type" gorm:"not null;index"`
+
+	// Currency is the ISO 4217 currency the account is held in
+	Currency string `json:"currency" gorm:"size:3;not null"`
+
+	// OpeningBalance is the balance when the account was added to MyExpenses
+	OpeningBalance Money `json:"opening_balance" gorm:"type:numeric(19,2);not null;default:0"`
+
+	// CreatedAt is automatically set when the account is first saved
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// UpdatedAt is automatically updated whenever the account is renamed or its opening balance corrected
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewAccount creates a validated account
+func NewAccount(name string, accountType AccountType, currency string, openingBalance Money) (*Account, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidAccount
+	}
+	if !accountType.IsValid() {
+		return nil, ErrInvalidAccountType
+	}
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		ID:             uuid.New(),
+		Name:           name,
+		Type:           accountType,
+		Currency:       currency,
+		OpeningBalance: openingBalance,
+	}, nil
+}
+
+// Update renames the account and corrects its opening balance; nil leaves a field unchanged
+// The type and currency stay: the account's history was recorded in them
+func (a *Account) Update(name *string, openingBalance *Money) error {
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if trimmed == "" {
+			return ErrInvalidAccount
+		}
+		a.Name = trimmed
+	}
+	if openingBalance != nil {
+		a.OpeningBalance = *openingBalance
+	}
+	return nil
+}
+
+// AmountOf returns what an expense paid from the account took from its balance, in the
+// account's currency: the expense's own amount, or its converted amount when the account is
+// held in the currency it was converted into. Refunds are negative: they put money back
+func (a *Account) AmountOf(expense *Expense) Money {
+	if expense.Currency != a.Currency && expense.BaseCurrency == a.Currency && !expense.BaseAmount.IsZero() {
+		return expense.BaseAmount
+	}
+	return expense.Amount
+}
+
+// Transfer moves money between two of the user's accounts
+// Transfers are not expenses: withdrawing cash doesn't spend it, it only moves it to the wallet
+type Transfer struct {
+	// ID is a unique identifier for each transfer
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user whose accounts the money moved between; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// FromAccountID is the account the money leaves
+	FromAccountID uuid.UUID `json:"from_account_id" gorm:"type:uuid;not null;index"`
+
+	// ToAccountID is the account the money arrives in
+	ToAccountID uuid.UUID `json:"to_account_id" gorm:"type:uuid;not null;index"`
+
+	// Amount is how much was moved
+	Amount Money `json:"amount" gorm:"type:numeric(19,2);not null"`
+
+	// Envelope optionally earmarks the money for a spending category (e.g. "Groceries")
+	// Cash expenses in that category are then attributed to the envelope
+	Envelope string `json:"envelope,omitempty"`
+
+	// Description is an optional note (e.g. "ATM Main Street")
+	Description string `json:"description,omitempty"`
+
+	// Date is when the transfer happened
+	Date time.Time `json:"date" gorm:"not null"`
+
+	// CreatedAt is automatically set when the transfer is first saved
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewTransfer creates a validated transfer between two different accounts
+func NewTransfer(from, to uuid.UUID, amount Money, date time.Time, envelope, description string) (*Transfer, error) {
+	if from == to {
+		return nil, ErrInvalidTransfer
+	}
+	if amount.Minor <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if date.IsZero() {
+		return nil, ErrInvalidDate
+	}
+	return &Transfer{
+		ID:            uuid.New(),
+		FromAccountID: from,
+		ToAccountID:   to,
+		Amount:        amount,
+		Envelope:      strings.TrimSpace(envelope),
+		Description:   strings.TrimSpace(description),
+		Date:          date,
+	}, nil
+}
+
+// AccountTotals are the raw sums needed to compute an account balance
+type AccountTotals struct {
+	// TransfersIn is the sum of transfers into the account
+	TransfersIn Money `json:"transfers_in"`
+
+	// TransfersOut is the sum of transfers out of the account
+	TransfersOut Money `json:"transfers_out"`
+
+	// Spent is the sum of expenses paid from the account
+	Spent Money `json:"spent"`
+}
+
+// EnvelopeTotals are the sums for one cash envelope
+type EnvelopeTotals struct {
+	// Envelope is the category the cash was earmarked for
+	Envelope string `json:"envelope"`
+
+	// Funded is how much cash was withdrawn for the envelope
+	Funded Money `json:"funded"`
+
+	// Spent is how much cash was spent in the envelope's category
+	Spent Money `json:"spent"`
+}
+
+// Kinds of account ledger entries
+const (
+	// AccountEntryOpening is the balance the account was added with
+	AccountEntryOpening = "opening"
+
+	// AccountEntryExpense is an expense paid from the account
+	AccountEntryExpense = "expense"
+
+	// AccountEntryRefund is money given back on an expense paid from the account
+	AccountEntryRefund = "refund"
+
+	// AccountEntryTransferIn and AccountEntryTransferOut are transfers into and out of the account
+	AccountEntryTransferIn  = "transfer_in"
+	AccountEntryTransferOut = "transfer_out"
+)
+
+// AccountEntry is one movement on an account with the balance after it
+type AccountEntry struct {
+	// Kind is one of the AccountEntry* constants
+	Kind string `json:"kind"`
+
+	Date        time.Time `json:"date"`
+	Description string    `json:"description,omitempty"`
+
+	// Amount is what the movement did to the balance: negative for money leaving the account
+	Amount Money `json:"amount"`
+
+	// Balance is the running balance after the movement
+	Balance Money `json:"balance"`
+
+	// ExpenseID or TransferID is the movement's expense or transfer
+	ExpenseID  *uuid.UUID `json:"expense_id,omitempty"`
+	TransferID *uuid.UUID `json:"transfer_id,omitempty"`
+
+	// recordedAt orders movements of the same day
+	recordedAt time.Time
+}
+
+// NewAccountEntries lists the movements of an account, oldest first, without their balances
+// The opening balance comes first; movements of the same day are in the order they were recorded
+func NewAccountEntries(account *Account, expenses []*Expense, transfers []*Transfer) []*AccountEntry {
+	entries := make([]*AccountEntry, 0, len(expenses)+len(transfers))
+	for _, expense := range expenses {
+		kind := AccountEntryExpense
+		if expense.IsRefund() {
+			kind = AccountEntryRefund
+		}
+		id := expense.ID
+		entries = append(entries, &AccountEntry{
+			Kind:        kind,
+			Date:        expense.Date,
+			Description: expense.Description,
+			Amount:      account.AmountOf(expense).Neg(),
+			ExpenseID:   &id,
+			recordedAt:  expense.CreatedAt,
+		})
+	}
+	for _, transfer := range transfers {
+		kind, amount := AccountEntryTransferIn, transfer.Amount
+		if transfer.FromAccountID == account.ID {
+			kind, amount = AccountEntryTransferOut, transfer.Amount.Neg()
+		}
+		id := transfer.ID
+		entries = append(entries, &AccountEntry{
+			Kind:        kind,
+			Date:        transfer.Date,
+			Description: transfer.Description,
+			Amount:      amount,
+			TransferID:  &id,
+			recordedAt:  transfer.CreatedAt,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := dayOf(entries[i].Date), dayOf(entries[j].Date)
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return entries[i].recordedAt.Before(entries[j].recordedAt)
+	})
+
+	opening := &AccountEntry{Kind: AccountEntryOpening, Date: dayOf(account.CreatedAt), Amount: account.OpeningBalance}
+	return append([]*AccountEntry{opening}, entries...)
+}
+
+// AccountRepository defines the data access operations for accounts and transfers
+// Accounts belong to the user who created them; every operation is limited to the caller's
+type AccountRepository interface {
+	// Create saves a new account
+	Create(ctx context.Context, account *Account) error
+
+	// GetByID retrieves an account by its unique identifier
+	GetByID(ctx context.Context, id string) (*Account, error)
+
+	// List returns all accounts ordered by name
+	List(ctx context.Context) ([]*Account, error)
+
+	// FindByType returns the first account of the given type, or ErrAccountNotFound
+	FindByType(ctx context.Context, accountType AccountType) (*Account, error)
+
+	// CreateTransfer saves a transfer between accounts
+	CreateTransfer(ctx context.Context, transfer *Transfer) error
+
+	// ListTransfers returns the transfers into or out of an account, newest first
+	ListTransfers(ctx context.Context, accountID string) ([]*Transfer, error)
+
+	// Totals returns the transfer and expense sums of an account
+	Totals(ctx context.Context, accountID string) (*AccountTotals, error)
+
+	// EnvelopeTotals returns funded vs spent per envelope for an account
+	EnvelopeTotals(ctx context.Context, accountID string) ([]*EnvelopeTotals, error)
+
+	// Update saves the name and opening balance of an account
+	Update(ctx context.Context, account *Account) error
+
+	// Delete removes an account, or returns ErrAccountInUse if expenses or transfers refer to it
+	Delete(ctx context.Context, id string) error
+
+	// ListExpenses returns the expenses paid from an account
+	ListExpenses(ctx context.Context, accountID string) ([]*Expense, error)
+}
@@ -0,0 +1,101 @@
+// Package domain contains the core business logic and entities
+// This file defines API keys, which let scripts and integrations act as a user without a login
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For trimming names
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring name length in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// API key scopes
+const (
+	// APIKeyScopeRead keys can only read (GET requests)
+	APIKeyScopeRead = "read"
+
+	// APIKeyScopeReadWrite keys can do everything their user can, except manage API keys
+	APIKeyScopeReadWrite = "read_write"
+)
+
+// MaxAPIKeyNameLength is the longest accepted API key name
+const MaxAPIKeyNameLength = 100
+
+// APIKey is a named, scoped credential of a user
+// Only a hash of the key is stored; the key itself is shown once, when it is created
+type APIKey struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+
+	// Name says what the key is used for (e.g. "backup script")
+	Name string `json:"name" gorm:"not null"`
+
+	// Scope is APIKeyScopeRead or APIKeyScopeReadWrite
+	Scope string `json:"scope" gorm:"not null"`
+
+	// Prefix is the start of the key, so users can tell their keys apart without the secret
+	Prefix string `json:"prefix" gorm:"not null"`
+
+	// KeyHash is the SHA-256 of the key
+	KeyHash string `json:"-" gorm:"not null;uniqueIndex"`
+
+	// LastUsedAt is roughly when the key was last used (nil if never), to spot unused keys
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewAPIKey creates an API key record with validation; the key hash and prefix are set by the caller
+func NewAPIKey(userID uuid.UUID, name, scope string) (*APIKey, error) {
+	key := &APIKey{ID: uuid.New(), UserID: userID}
+	if err := key.Rename(name, scope); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Rename changes the name and scope of the key with validation
+func (k *APIKey) Rename(name, scope string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxAPIKeyNameLength {
+		return ErrInvalidAPIKey
+	}
+	if scope != APIKeyScopeRead && scope != APIKeyScopeReadWrite {
+		return ErrInvalidAPIKey
+	}
+	k.Name, k.Scope = name, scope
+	return nil
+}
+
+// ReadOnly reports whether the key may only read
+func (k *APIKey) ReadOnly() bool {
+	return k.Scope != APIKeyScopeReadWrite
+}
+
+// APIKeyRepository defines how API keys are stored
+// Every method but GetByHash only sees the keys of the given user
+type APIKeyRepository interface {
+	// Create saves a new API key
+	Create(ctx context.Context, key *APIKey) error
+
+	// List returns a user's API keys, newest first
+	List(ctx context.Context, userID uuid.UUID) ([]*APIKey, error)
+
+	// GetByID retrieves one of a user's keys, or returns ErrAPIKeyNotFound
+	GetByID(ctx context.Context, userID uuid.UUID, id string) (*APIKey, error)
+
+	// GetByHash retrieves the key with the given hash, or returns ErrAPIKeyNotFound
+	GetByHash(ctx context.Context, keyHash string) (*APIKey, error)
+
+	// Update saves a key's name and scope
+	Update(ctx context.Context, key *APIKey) error
+
+	// Delete removes one of a user's keys, or returns ErrAPIKeyNotFound
+	Delete(ctx context.Context, userID uuid.UUID, id string) error
+
+	// TouchLastUsed records that a key was used at the given time
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
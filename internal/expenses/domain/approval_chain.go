@@ -0,0 +1,213 @@
+// Package domain contains the core business logic and entities
+// This file defines approval chains: the steps a tenant's trip reports are approved in,
+// and the record of each step's approval
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For input normalization
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// MaxApprovalSteps is the longest approval chain accepted
+const MaxApprovalSteps = 10
+
+// ApprovalChain is the sequence of approvals a tenant's trip reports go through before they are frozen
+// It is data rather than code, so admins change who approves what without a deployment
+// There is at most one per tenant; tenants without one use DefaultApprovalChain
+type ApprovalChain struct {
+	TenantID string `json:"tenant_id" gorm:"primaryKey"`
+
+	// Steps are approved in order; a step whose conditions don't match the report is skipped
+	Steps []*ApprovalStep `json:"steps" gorm:"foreignKey:TenantID;references:TenantID"`
+
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ApprovalStep is one approval of a chain: who approves, and which reports need it
+// The approver is a specific user, or anyone with a role (e.g. "admin")
+type ApprovalStep struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TenantID string    `json:"-" gorm:"not null;index"`
+
+	// Position orders the steps of the chain, starting at 1
+	Position int `json:"position" gorm:"not null"`
+
+	// Name describes the step (e.g. "Team lead", "Finance")
+	Name string `json:"name" gorm:"not null"`
+
+	// MinAmount limits the step to reports totalling at least this much in the home currency
+	// (0 for every report), so larger reports can need more approvals
+	MinAmount Money `json:"min_amount" gorm:"type:numeric(19,2);not null;default:0"`
+
+	// Categories limits the step to reports with an expense in one of these categories (empty for every report)
+	Categories []string `json:"categories,omitempty" gorm:"serializer:json"`
+
+	// ApproverID is the user who approves; ApproverRole lets anyone with the role approve instead
+	ApproverID   *uuid.UUID `json:"approver_id,omitempty" gorm:"type:uuid"`
+	ApproverRole string     `json:"approver_role,omitempty" gorm:"size:16"`
+
+	// DelegateID may approve in the approver's place from DelegateFrom to DelegateUntil
+	// (both days included), e.g. while the approver is on vacation
+	DelegateID    *uuid.UUID `json:"delegate_id,omitempty" gorm:"type:uuid"`
+	DelegateFrom  *time.Time `json:"delegate_from,omitempty"`
+	DelegateUntil *time.Time `json:"delegate_until,omitempty"`
+}
+
+// NewApprovalStep creates a validated approval step
+// Exactly one of approverID and approverRole must be given; the role must be a known user role
+func NewApprovalStep(name string, minAmount Money, categories []string, approverID *uuid.UUID, approverRole string) (*ApprovalStep, error) {
+	name = strings.TrimSpace(name)
+	approverRole = strings.ToLower(strings.TrimSpace(approverRole))
+	if name == "" || minAmount.IsNegative() || (approverID == nil) == (approverRole == "") {
+		return nil, ErrInvalidApprovalChain
+	}
+	switch approverRole {
+	case "", UserRoleViewer, UserRoleMember, UserRoleAdmin:
+	default:
+		return nil, ErrInvalidApprovalChain
+	}
+
+	// Keep each category once, compared case-insensitively like expense categories are
+	var cleaned []string
+	seen := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		category = strings.TrimSpace(category)
+		if category == "" || seen[strings.ToLower(category)] {
+			continue
+		}
+		seen[strings.ToLower(category)] = true
+		cleaned = append(cleaned, category)
+	}
+
+	return &ApprovalStep{
+		ID:           uuid.New(),
+		Name:         name,
+		MinAmount:    minAmount,
+		Categories:   cleaned,
+		ApproverID:   approverID,
+		ApproverRole: approverRole,
+	}, nil
+}
+
+// SetDelegate lets delegateID approve in the approver's place from from to until (both days included)
+func (s *ApprovalStep) SetDelegate(delegateID uuid.UUID, from, until time.Time) error {
+	from, until = dayOf(from), dayOf(until)
+	if delegateID == uuid.Nil || until.Before(from) || (s.ApproverID != nil && *s.ApproverID == delegateID) {
+		return ErrInvalidApprovalChain
+	}
+	s.DelegateID = &delegateID
+	s.DelegateFrom = &from
+	s.DelegateUntil = &until
+	return nil
+}
+
+// Applies reports whether a report totalling total, with expenses in categories, needs this step
+func (s *ApprovalStep) Applies(total Money, categories []string) bool {
+	if total.Minor < s.MinAmount.Minor {
+		return false
+	}
+	if len(s.Categories) == 0 {
+		return true
+	}
+	for _, wanted := range s.Categories {
+		for _, category := range categories {
+			if strings.EqualFold(wanted, category) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DelegateActive reports whether the delegate may approve on the day of now
+func (s *ApprovalStep) DelegateActive(now time.Time) bool {
+	if s.DelegateID == nil || s.DelegateFrom == nil || s.DelegateUntil == nil {
+		return false
+	}
+	day := dayOf(now)
+	return !day.Before(*s.DelegateFrom) && !day.After(*s.DelegateUntil)
+}
+
+// Approver describes who approves the step, for audit records ("user:<id>" or "role:<name>")
+func (s *ApprovalStep) Approver() string {
+	if s.ApproverID != nil {
+		return "user:" + s.ApproverID.String()
+	}
+	return "role:" + s.ApproverRole
+}
+
+// NewApprovalChain creates a validated chain from its steps, numbering them in the given order
+// Step names identify the steps, so they must differ (ignoring case)
+func NewApprovalChain(tenantID string, steps []*ApprovalStep) (*ApprovalChain, error) {
+	if len(steps) == 0 || len(steps) > MaxApprovalSteps {
+		return nil, ErrInvalidApprovalChain
+	}
+	names := make(map[string]bool, len(steps))
+	for i, step := range steps {
+		if names[strings.ToLower(step.Name)] {
+			return nil, ErrInvalidApprovalChain
+		}
+		names[strings.ToLower(step.Name)] = true
+		step.TenantID = tenantID
+		step.Position = i + 1
+	}
+	return &ApprovalChain{TenantID: tenantID, Steps: steps}, nil
+}
+
+// DefaultApprovalChain is the chain of tenants that haven't set their own: a single approval by an admin
+// Its step has a fixed (nil) ID, so approvals recorded against it stay valid
+func DefaultApprovalChain(tenantID string) *ApprovalChain {
+	return &ApprovalChain{TenantID: tenantID, Steps: []*ApprovalStep{{
+		TenantID:     tenantID,
+		Position:     1,
+		Name:         "Approval",
+		ApproverRole: UserRoleAdmin,
+	}}}
+}
+
+// TripApproval records that one step of the approval chain approved a trip report
+type TripApproval struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// TripID and StepID identify the approval; a step approves a trip only once
+	TripID uuid.UUID `json:"trip_id" gorm:"type:uuid;not null;uniqueIndex:idx_trip_approval_step"`
+	StepID uuid.UUID `json:"step_id" gorm:"type:uuid;not null;uniqueIndex:idx_trip_approval_step"`
+
+	// StepName is kept so the record still reads well after the chain changes
+	StepName string `json:"step_name" gorm:"not null"`
+
+	// ApprovedBy is the user who approved
+	ApprovedBy string `json:"approved_by" gorm:"not null"`
+
+	// OnBehalfOf is the approver a delegate approved for (empty when the approver approved)
+	OnBehalfOf string `json:"on_behalf_of,omitempty"`
+
+	// DelegationID is the out-of-office delegation the delegate approved under (nil otherwise)
+	DelegationID *uuid.UUID `json:"delegation_id,omitempty" gorm:"type:uuid"`
+
+	ApprovedAt time.Time `json:"approved_at" gorm:"not null"`
+}
+
+// ApprovalChainRepository defines how tenants' approval chains are stored
+type ApprovalChainRepository interface {
+	// Get retrieves a tenant's chain with its steps in order, or returns ErrApprovalChainNotFound
+	Get(ctx context.Context, tenantID string) (*ApprovalChain, error)
+
+	// Save creates or replaces a tenant's chain and all of its steps
+	Save(ctx context.Context, chain *ApprovalChain) error
+
+	// Delete removes a tenant's chain, or returns ErrApprovalChainNotFound
+	Delete(ctx context.Context, tenantID string) error
+}
+
+// TripApprovalRepository defines how step approvals of trip reports are stored
+type TripApprovalRepository interface {
+	// ListByTrip returns the approvals of a trip in the order they were given
+	ListByTrip(ctx context.Context, tripID uuid.UUID) ([]*TripApproval, error)
+
+	// Create records an approval, or returns ErrStepAlreadyApproved when the step approved the trip already
+	Create(ctx context.Context, approval *TripApproval) error
+}
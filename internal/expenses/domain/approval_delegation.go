@@ -0,0 +1,134 @@
+// Package domain contains the core business logic and entities
+// This file defines out-of-office delegations: an approver hands their approvals to a colleague
+// for a date range, after which they return to the approver by themselves
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For input normalization
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring the reason in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Delegation limits
+const (
+	// MaxDelegationDays is the longest delegation accepted; longer absences call for a change of the chain
+	MaxDelegationDays = 365
+
+	// MaxDelegationReasonLength bounds the free-text reason
+	MaxDelegationReasonLength = 200
+)
+
+// Delegation statuses, derived from the dates and the cancellation
+const (
+	// DelegationScheduled starts on a later day
+	DelegationScheduled = "scheduled"
+
+	// DelegationActive lets the delegate approve today
+	DelegationActive = "active"
+
+	// DelegationEnded is past its last day; the approvals went back to the approver
+	DelegationEnded = "ended"
+
+	// DelegationCancelled was ended early by the approver
+	DelegationCancelled = "cancelled"
+)
+
+// ApprovalDelegation routes an approver's pending approvals to a delegate from StartsOn to EndsOn
+// (both days included), e.g. during a vacation
+// It covers the chain steps that name the approver as a user; steps approved by a role
+// have other approvers already. A delegate can't pass the approvals on again
+type ApprovalDelegation struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// TenantID is the tenant of the approver (empty for the default tenant)
+	TenantID string `json:"-" gorm:"index"`
+
+	ApproverID uuid.UUID `json:"approver_id" gorm:"type:uuid;not null;index"`
+	DelegateID uuid.UUID `json:"delegate_id" gorm:"type:uuid;not null;index"`
+
+	StartsOn time.Time `json:"starts_on" gorm:"not null"`
+	EndsOn   time.Time `json:"ends_on" gorm:"not null;index"`
+
+	// Reason is shown to the delegate (e.g. "Vacation")
+	Reason string `json:"reason,omitempty"`
+
+	// CancelledAt is when the approver ended the delegation early (nil otherwise)
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+
+	// RevertedAt is when the end of the delegation was recorded in the audit log (nil until then)
+	RevertedAt *time.Time `json:"reverted_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewApprovalDelegation creates a validated delegation
+// It can't start before today (the day of now), and the approver can't delegate to themselves
+func NewApprovalDelegation(approverID, delegateID uuid.UUID, startsOn, endsOn time.Time, reason string, now time.Time) (*ApprovalDelegation, error) {
+	startsOn, endsOn = dayOf(startsOn), dayOf(endsOn)
+	reason = strings.TrimSpace(reason)
+	if approverID == uuid.Nil || delegateID == uuid.Nil || approverID == delegateID {
+		return nil, ErrInvalidDelegation
+	}
+	if startsOn.Before(dayOf(now)) || endsOn.Before(startsOn) || endsOn.After(startsOn.AddDate(0, 0, MaxDelegationDays-1)) {
+		return nil, ErrInvalidDelegation
+	}
+	if utf8.RuneCountInString(reason) > MaxDelegationReasonLength {
+		return nil, ErrInvalidDelegation
+	}
+	return &ApprovalDelegation{
+		ID:         uuid.New(),
+		ApproverID: approverID,
+		DelegateID: delegateID,
+		StartsOn:   startsOn,
+		EndsOn:     endsOn,
+		Reason:     reason,
+	}, nil
+}
+
+// Status returns whether the delegation is scheduled, active, ended or cancelled on the day of now
+func (d *ApprovalDelegation) Status(now time.Time) string {
+	day := dayOf(now)
+	switch {
+	case d.CancelledAt != nil:
+		return DelegationCancelled
+	case day.Before(d.StartsOn):
+		return DelegationScheduled
+	case day.After(d.EndsOn):
+		return DelegationEnded
+	default:
+		return DelegationActive
+	}
+}
+
+// Overlaps reports whether the two delegations share a day
+func (d *ApprovalDelegation) Overlaps(other *ApprovalDelegation) bool {
+	return !d.EndsOn.Before(other.StartsOn) && !other.EndsOn.Before(d.StartsOn)
+}
+
+// ApprovalDelegationRepository defines how delegations are stored
+type ApprovalDelegationRepository interface {
+	// Create saves a new delegation
+	Create(ctx context.Context, delegation *ApprovalDelegation) error
+
+	// GetByID retrieves a delegation, or returns ErrDelegationNotFound
+	GetByID(ctx context.Context, id string) (*ApprovalDelegation, error)
+
+	// ListByUser returns the delegations userID gave or received, latest start first
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*ApprovalDelegation, error)
+
+	// ListActiveByDelegate returns the uncancelled delegations to delegateID covering day
+	ListActiveByDelegate(ctx context.Context, delegateID uuid.UUID, day time.Time) ([]*ApprovalDelegation, error)
+
+	// Cancel ends a delegation early, or returns ErrDelegationNotFound when it is cancelled already
+	Cancel(ctx context.Context, id uuid.UUID, at time.Time) error
+
+	// ListUnreverted returns the uncancelled delegations whose last day is before day
+	// and whose end hasn't been recorded yet
+	ListUnreverted(ctx context.Context, day time.Time) ([]*ApprovalDelegation, error)
+
+	// MarkReverted records that the end of a delegation has been recorded
+	MarkReverted(ctx context.Context, id uuid.UUID, at time.Time) error
+}
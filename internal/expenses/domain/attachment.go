@@ -0,0 +1,182 @@
+// Package domain contains the core business logic and entities
+// This file defines receipt attachments and the full-text search contracts built on top of them
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"io"      // For streaming attachment content to text extractors
+	"strings" // For normalizing user supplied values
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// MaxAttachmentSize is the default largest attachment (in bytes) accepted for a single upload
+// 10 MB comfortably fits phone photos of receipts and multi-page PDF invoices
+// Deployments can configure another limit, which the upload services enforce
+const MaxAttachmentSize = 10 << 20
+
+// Attachment represents a file (usually a receipt photo or PDF) linked to an expense
+// The binary content lives in blob storage; this entity only keeps the metadata
+// and the text extracted from the file by OCR so it can be searched
+type Attachment struct {
+	// ID is a unique identifier for each attachment
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// ExpenseID links the attachment to the expense it documents
+	// index speeds up "list attachments of an expense" queries
+	ExpenseID uuid.UUID `json:"expense_id" gorm:"type:uuid;not null;index"`
+
+	// FileName is the original name of the uploaded file (e.g. "receipt.jpg")
+	FileName string `json:"file_name" gorm:"not null"`
+
+	// ContentType is the MIME type of the file (e.g. "image/jpeg", "application/pdf")
+	ContentType string `json:"content_type" gorm:"not null"`
+
+	// Size is the file size in bytes
+	Size int64 `json:"size" gorm:"not null"`
+
+	// StorageKey is the key under which the binary content is kept in blob storage
+	// It is an internal detail, so it is never serialized to API clients
+	// Attachments with identical content share one stored file (see AttachmentBlob)
+	StorageKey string `json:"-" gorm:"not null"`
+
+	// ContentHash is the hex SHA-256 of the content; empty for attachments stored before deduplication
+	ContentHash string `json:"content_hash,omitempty" gorm:"size:64;index"`
+
+	// TenantID is the tenant the content is shared within (empty for the default tenant)
+	TenantID string `json:"-" gorm:"index"`
+
+	// OCRText is the text recognized on the receipt (items, merchant, totals)
+	// It is indexed for full-text search so users can find expenses by what they bought
+	OCRText string `json:"ocr_text,omitempty" gorm:"type:text"`
+
+	// CreatedAt is automatically set when the attachment is first saved
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewAttachment creates a new attachment with validation
+// This factory function ensures attachments always reference an expense and a real file
+func NewAttachment(expenseID uuid.UUID, fileName, contentType string, size int64) (*Attachment, error) {
+	// Validation: the attachment must belong to an expense
+	if expenseID == uuid.Nil {
+		return nil, ErrExpenseNotFound
+	}
+
+	// Validation: we need a file name and a non-empty file
+	// The size limit is configurable, so it is checked by the services that store the file
+	fileName = strings.TrimSpace(fileName)
+	if fileName == "" || size <= 0 {
+		return nil, ErrInvalidAttachment
+	}
+
+	// Default to a generic binary type if the client didn't tell us
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	id := uuid.New()
+	return &Attachment{
+		ID:          id,
+		ExpenseID:   expenseID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		// Keys are namespaced by expense so all files of an expense live together
+		StorageKey: "attachments/" + expenseID.String() + "/" + id.String(),
+	}, nil
+}
+
+// AttachmentRepository defines the data access operations for attachments
+// It is separate from Repository so expense storage and file metadata can evolve independently
+type AttachmentRepository interface {
+	// Create saves the attachment metadata
+	Create(ctx context.Context, attachment *Attachment) error
+
+	// GetByID retrieves an attachment by its unique identifier
+	// Returns ErrAttachmentNotFound if it doesn't exist
+	GetByID(ctx context.Context, id string) (*Attachment, error)
+
+	// ListByExpense returns all attachments of the given expense, oldest first
+	ListByExpense(ctx context.Context, expenseID string) ([]*Attachment, error)
+
+	// UpdateOCRText replaces the recognized text of an attachment (used when re-running OCR)
+	UpdateOCRText(ctx context.Context, id string, text string) error
+
+	// Delete removes the attachment metadata by its ID
+	Delete(ctx context.Context, id string) error
+}
+
+// AttachmentBlob is a stored file shared by every attachment of a tenant with the same content
+// Recurring invoices are often uploaded again and again; each copy only adds a reference
+type AttachmentBlob struct {
+	// TenantID and Hash (hex SHA-256 of the content) identify the blob
+	// Content is never shared across tenants
+	TenantID string `json:"tenant_id" gorm:"primaryKey"`
+	Hash     string `json:"hash" gorm:"primaryKey;size:64"`
+
+	// StorageKey is where the content is kept: the key of the first upload
+	StorageKey string `json:"-" gorm:"not null"`
+
+	// Size is the content size in bytes
+	Size int64 `json:"size" gorm:"not null"`
+
+	// RefCount is the number of attachments using the blob; the file is deleted when it drops to 0
+	RefCount int64 `json:"ref_count" gorm:"not null;default:1"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// AttachmentBlobRepository keeps the reference counts of shared attachment content
+type AttachmentBlobRepository interface {
+	// Acquire adds a reference to the blob of blob.TenantID and blob.Hash, creating it from blob
+	// if it doesn't exist yet, and returns the stored blob. Concurrent calls are safe: when the
+	// returned StorageKey differs from blob.StorageKey, the content was already stored there
+	Acquire(ctx context.Context, blob *AttachmentBlob) (*AttachmentBlob, error)
+
+	// Release drops a reference to a blob; once none are left the blob is removed and its
+	// storage key is returned so the caller can delete the file ("" while it is still in use)
+	Release(ctx context.Context, tenantID, hash string) (string, error)
+}
+
+// TextExtractor recognizes text in attachment content (OCR for images, text layer for PDFs)
+// Implementations live in the infrastructure layer (local tesseract, cloud OCR APIs, ...)
+type TextExtractor interface {
+	// ExtractText reads the file content from r and returns the recognized text
+	// contentType tells the extractor how to interpret the bytes
+	ExtractText(ctx context.Context, contentType string, r io.Reader) (string, error)
+}
+
+// SearchScope selects which text the search endpoint looks at
+type SearchScope string
+
+const (
+	// SearchScopeExpenses searches the expense descriptions and categories
+	SearchScopeExpenses SearchScope = "expenses"
+
+	// SearchScopeReceipts searches the OCR text of attached receipts
+	SearchScopeReceipts SearchScope = "receipts"
+
+	// SearchScopeAll searches both expenses and receipts
+	SearchScopeAll SearchScope = "all"
+)
+
+// ParseSearchScope converts a query parameter into a SearchScope
+// An empty value defaults to searching expenses only
+func ParseSearchScope(value string) (SearchScope, error) {
+	switch scope := SearchScope(strings.ToLower(strings.TrimSpace(value))); scope {
+	case "":
+		return SearchScopeExpenses, nil
+	case SearchScopeExpenses, SearchScopeReceipts, SearchScopeAll:
+		return scope, nil
+	default:
+		return "", ErrInvalidSearchScope
+	}
+}
+
+// SearchRepository defines full-text search over expenses and their receipts
+type SearchRepository interface {
+	// Search returns the expenses matching query within the given scope, best matches first
+	// searchConfig is the text search configuration the query is stemmed with (e.g. "german")
+	Search(ctx context.Context, query string, scope SearchScope, searchConfig string) ([]*Expense, error)
+}
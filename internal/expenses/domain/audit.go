@@ -0,0 +1,65 @@
+// Package domain contains the core business logic and entities
+// This file defines the audit log: an append-only record of administrative changes
+package domain
+
+import (
+	"context"       // For request context (cancellation, timeouts)
+	"encoding/json" // For storing the details of an entry
+	"time"          // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// AuditEntry records one change: who did what to which entity, and the details
+// Entries are never updated or deleted by the API
+type AuditEntry struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// Action is what happened, as "<entity>.<verb>" (e.g. "expense.rerated")
+	Action string `json:"action" gorm:"not null;index"`
+
+	// Actor is the user ID of whoever made the change, or "admin" for the admin token
+	Actor string `json:"actor" gorm:"not null"`
+
+	// EntityType and EntityID identify what was changed (EntityID may be empty for bulk operations)
+	EntityType string `json:"entity_type" gorm:"not null;index:idx_audit_entity"`
+	EntityID   string `json:"entity_id,omitempty" gorm:"index:idx_audit_entity"`
+
+	// Details holds the change as JSON (e.g. the old and new values)
+	Details json.RawMessage `json:"details,omitempty" gorm:"type:jsonb"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// NewAuditEntry creates an audit entry, encoding details as JSON
+func NewAuditEntry(actor, action, entityType, entityID string, details any) (*AuditEntry, error) {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditEntry{
+		ID:         uuid.New(),
+		Action:     action,
+		Actor:      actor,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Details:    encoded,
+	}, nil
+}
+
+// AuditFilter narrows down an audit log listing; empty fields don't filter
+type AuditFilter struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	Limit      int
+}
+
+// AuditRepository defines how audit entries are stored
+type AuditRepository interface {
+	// Record appends entries to the audit log
+	Record(ctx context.Context, entries ...*AuditEntry) error
+
+	// List returns the matching entries, newest first
+	List(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error)
+}
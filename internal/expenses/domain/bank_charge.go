@@ -0,0 +1,135 @@
+// Package domain contains the core business logic and entities
+// This file defines the built-in detection of bank charges (interest and fees) on imported statement lines
+package domain
+
+import (
+	"strings" // For case-insensitive phrase matching
+	"unicode" // For splitting statement text into words
+)
+
+// Kinds of bank charge
+const (
+	// ChargeInterest is interest charged on an overdraft, a card balance or a loan
+	ChargeInterest = "interest"
+
+	// ChargeFee is a fee or commission charged by the bank
+	ChargeFee = "fee"
+)
+
+// ChargeCategories names the categories detected bank charges are filed under
+// An empty name turns detection of that kind off
+type ChargeCategories struct {
+	Interest string
+	Fees     string
+}
+
+// DefaultChargeCategories are the canonical (English) charge categories; see DefaultCategories
+var DefaultChargeCategories = ChargeCategories{
+	Interest: "Interest",
+	Fees:     "Bank Fees",
+}
+
+// Category returns the category of a kind of charge ("" if that kind isn't detected)
+func (c ChargeCategories) Category(kind string) string {
+	switch kind {
+	case ChargeInterest:
+		return c.Interest
+	case ChargeFee:
+		return c.Fees
+	default:
+		return ""
+	}
+}
+
+// ChargePhrase is a phrase that marks a statement line as a bank charge
+type ChargePhrase struct {
+	Kind   string
+	Phrase string
+}
+
+// DefaultChargePhrases is the built-in rule set, in the order it is tried
+// Phrases match whole words, so "fee" matches "ATM FEE" but not "COFFEE"
+// Interest comes first: "interest fee" and "fee interest" lines are interest
+var DefaultChargePhrases = []ChargePhrase{
+	{Kind: ChargeInterest, Phrase: "interest"},
+	{Kind: ChargeInterest, Phrase: "debit interest"},
+	{Kind: ChargeInterest, Phrase: "finance charge"},
+	{Kind: ChargeInterest, Phrase: "int charge"},
+	{Kind: ChargeInterest, Phrase: "zinsen"},
+	{Kind: ChargeInterest, Phrase: "sollzinsen"},
+	{Kind: ChargeInterest, Phrase: "interets"},
+	{Kind: ChargeInterest, Phrase: "intereses"},
+	{Kind: ChargeFee, Phrase: "fee"},
+	{Kind: ChargeFee, Phrase: "fees"},
+	{Kind: ChargeFee, Phrase: "service charge"},
+	{Kind: ChargeFee, Phrase: "account charge"},
+	{Kind: ChargeFee, Phrase: "maintenance charge"},
+	{Kind: ChargeFee, Phrase: "overdraft charge"},
+	{Kind: ChargeFee, Phrase: "non sterling transaction"},
+	{Kind: ChargeFee, Phrase: "foreign transaction"},
+	{Kind: ChargeFee, Phrase: "commission"},
+	{Kind: ChargeFee, Phrase: "gebuhr"},
+	{Kind: ChargeFee, Phrase: "gebuehr"},
+	{Kind: ChargeFee, Phrase: "kontofuhrung"},
+	{Kind: ChargeFee, Phrase: "kontofuhrungsgebuhr"},
+	{Kind: ChargeFee, Phrase: "kontofuhrungsentgelt"},
+	{Kind: ChargeFee, Phrase: "frais"},
+	{Kind: ChargeFee, Phrase: "comision"},
+}
+
+// DetectCharge reports which kind of bank charge a statement line is, if any
+// It looks at the description and the merchant; accents and punctuation are ignored
+func DetectCharge(tx *ImportedTransaction) (string, bool) {
+	text := " " + chargeWords(tx.Description) + " " + chargeWords(tx.Merchant) + " "
+	for _, phrase := range DefaultChargePhrases {
+		if strings.Contains(text, " "+phrase.Phrase+" ") {
+			return phrase.Kind, true
+		}
+	}
+	return "", false
+}
+
+// chargeWords lowercases text, drops accents and turns everything but letters and digits into single spaces
+func chargeWords(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			space = false
+		case unicode.IsLetter(r):
+			// The common accented letters are enough for statement text
+			b.WriteString(unaccent(r))
+			space = false
+		case !space:
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// unaccent maps accented Latin letters to their base letter
+func unaccent(r rune) string {
+	switch r {
+	case 'à', 'á', 'â', 'ä', 'ã':
+		return "a"
+	case 'è', 'é', 'ê', 'ë':
+		return "e"
+	case 'ì', 'í', 'î', 'ï':
+		return "i"
+	case 'ò', 'ó', 'ô', 'ö', 'õ':
+		return "o"
+	case 'ù', 'ú', 'û', 'ü':
+		return "u"
+	case 'ç':
+		return "c"
+	case 'ñ':
+		return "n"
+	case 'ß':
+		return "ss"
+	default:
+		return string(r)
+	}
+}
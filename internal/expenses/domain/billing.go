@@ -0,0 +1,185 @@
+// Package domain contains the core business logic and entities
+// This file defines billing for hosted tenants: plans, subscriptions and the payment provider behind them
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For handling dates and times
+)
+
+// Subscription statuses
+// The payment provider has more of them; BillingProvider implementations map theirs onto these
+const (
+	// SubscriptionTrialing is a trial of a paid plan that hasn't been paid for yet
+	SubscriptionTrialing = "trialing"
+
+	// SubscriptionActive is a paid plan whose last invoice was paid
+	SubscriptionActive = "active"
+
+	// SubscriptionPastDue is a paid plan whose last invoice failed; the provider is still retrying
+	SubscriptionPastDue = "past_due"
+
+	// SubscriptionSuspended is a paid plan the provider gave up collecting; the tenant becomes read-only
+	SubscriptionSuspended = "suspended"
+
+	// SubscriptionCanceled is a subscription that ended; the tenant is back on the free plan
+	SubscriptionCanceled = "canceled"
+)
+
+// MaxSeats is the most seats one subscription may have
+const MaxSeats = 10000
+
+// Plan is what a tenant can subscribe to
+type Plan struct {
+	// ID names the plan in requests, e.g. "pro"
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// Capabilities switches capabilities off (or back on) for tenants on the plan, on top of the
+	// deployment's settings; capabilities it doesn't mention keep the deployment's setting
+	Capabilities map[string]bool `json:"capabilities"`
+
+	// MaxSeats is the most users a tenant on the plan may have (0 means no limit)
+	// Paid plans are limited to the seats the tenant bought, up to this
+	MaxSeats int `json:"max_seats"`
+
+	// TrialDays is how long a tenant may try the plan before paying (0 means no trial)
+	TrialDays int `json:"trial_days"`
+
+	// PriceID is the payment provider's price of one seat; plans without one are free
+	PriceID string `json:"-"`
+}
+
+// Paid reports whether subscribing to the plan goes through the payment provider
+func (p *Plan) Paid() bool {
+	return p.PriceID != ""
+}
+
+// Subscription is a tenant's subscription to a paid plan
+// There is at most one per tenant. Its state follows the payment provider: the API changes
+// it when the tenant subscribes or changes plans, the provider's webhooks whenever it bills
+type Subscription struct {
+	TenantID string `json:"tenant_id" gorm:"primaryKey"`
+
+	PlanID string `json:"plan"`
+	Status string `json:"status" gorm:"size:16;not null"`
+
+	// Seats is how many users the tenant pays for
+	Seats int `json:"seats" gorm:"not null"`
+
+	// CustomerID and ProviderID identify the customer and subscription at the payment provider
+	CustomerID string `json:"-"`
+	ProviderID string `json:"-" gorm:"index"`
+
+	// TrialEndsAt is when the trial ends or ended; a tenant gets only one trial
+	TrialEndsAt *time.Time `json:"trial_ends_at,omitempty"`
+
+	// CurrentPeriodEnd is when the paid period ends and the next invoice is due
+	CurrentPeriodEnd *time.Time `json:"current_period_end,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Current reports whether the subscription grants its plan
+// Past due subscriptions keep it while the provider retries the payment
+func (s *Subscription) Current() bool {
+	return s.Status == SubscriptionTrialing || s.Status == SubscriptionActive || s.Status == SubscriptionPastDue
+}
+
+// Apply copies what the payment provider says about the subscription onto it
+// planID is the plan of the state's price; empty keeps the plan
+func (s *Subscription) Apply(state *SubscriptionState, planID string) {
+	if planID != "" {
+		s.PlanID = planID
+	}
+	s.Status = state.Status
+	if state.Seats > 0 {
+		s.Seats = state.Seats
+	}
+	if state.CustomerID != "" {
+		s.CustomerID = state.CustomerID
+	}
+	s.ProviderID = state.ProviderID
+	if state.TrialEndsAt != nil {
+		s.TrialEndsAt = state.TrialEndsAt
+	}
+	s.CurrentPeriodEnd = state.CurrentPeriodEnd
+}
+
+// SubscriptionState is a subscription as the payment provider sees it
+type SubscriptionState struct {
+	ProviderID string
+	CustomerID string
+
+	// TenantID is the tenant the subscription was created for, from its metadata
+	TenantID string
+
+	// PriceID is the price subscribed to; Seats how many of it
+	PriceID string
+	Seats   int
+
+	// Status is one of the Subscription* statuses
+	Status string
+
+	TrialEndsAt      *time.Time
+	CurrentPeriodEnd *time.Time
+
+	// PaymentURL is the provider's page where the customer pays, when there is something to pay
+	PaymentURL string
+}
+
+// BillingEvent is a webhook the payment provider sent
+type BillingEvent struct {
+	// ID is the provider's event ID; events are delivered at least once, so it is remembered
+	ID         string    `json:"id" gorm:"primaryKey"`
+	Type       string    `json:"type" gorm:"not null"`
+	ReceivedAt time.Time `json:"received_at" gorm:"autoCreateTime"`
+
+	// Subscription is the subscription the event is about (nil for events that don't change one)
+	Subscription *SubscriptionState `json:"-" gorm:"-"`
+}
+
+// NewSubscription is what subscribing to a paid plan asks the payment provider for
+type NewSubscription struct {
+	TenantID string
+
+	// Email is where the provider sends invoices and receipts
+	Email string
+
+	PriceID   string
+	Seats     int
+	TrialDays int
+}
+
+// BillingProvider takes payments for subscriptions
+// The API never handles card details: the provider collects them, and tells the API about
+// payments through webhooks that ParseEvent turns into events
+type BillingProvider interface {
+	// Subscribe creates a customer and their subscription
+	Subscribe(ctx context.Context, req *NewSubscription) (*SubscriptionState, error)
+
+	// Update moves a subscription to another price or number of seats, prorating the difference
+	Update(ctx context.Context, providerID, priceID string, seats int) (*SubscriptionState, error)
+
+	// Cancel ends a subscription at once
+	Cancel(ctx context.Context, providerID string) (*SubscriptionState, error)
+
+	// ParseEvent checks a webhook's signature and decodes it, or returns ErrInvalidWebhook
+	ParseEvent(payload []byte, signature string) (*BillingEvent, error)
+}
+
+// SubscriptionRepository defines how subscriptions and the webhooks changing them are stored
+type SubscriptionRepository interface {
+	// Get retrieves a tenant's subscription, or returns ErrSubscriptionNotFound
+	Get(ctx context.Context, tenantID string) (*Subscription, error)
+
+	// GetByProviderID retrieves a subscription by the provider's ID, or returns ErrSubscriptionNotFound
+	GetByProviderID(ctx context.Context, providerID string) (*Subscription, error)
+
+	// Save creates or replaces a tenant's subscription
+	Save(ctx context.Context, subscription *Subscription) error
+
+	// RecordEvent remembers a webhook and reports whether it is new
+	RecordEvent(ctx context.Context, event *BillingEvent) (bool, error)
+}
@@ -0,0 +1,63 @@
+// Package domain contains the core business logic and entities
+// This file defines books: separate sets of expenses, categories and budgets kept by one user,
+// e.g. personal spending and a side business
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For input normalization
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring text in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// DefaultBookName is what the default book is called in book lists
+// Every user has it without creating it; it holds everything recorded outside of other books
+const DefaultBookName = "Personal"
+
+// MaxBookNameLength bounds the name of a book
+const MaxBookNameLength = 100
+
+// MaxBooksPerUser bounds how many books a user can create besides the default one
+const MaxBooksPerUser = 20
+
+// Book is a separate set of expenses, categories and budgets of one user
+// Requests pick a book by its ID; without one they work in the default book, which isn't stored
+type Book struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user who keeps the book; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// Name is how the user calls the book (e.g. "Photography business")
+	Name string `json:"name" gorm:"not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewBook creates a validated book
+func NewBook(name string) (*Book, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxBookNameLength || strings.EqualFold(name, DefaultBookName) {
+		return nil, ErrInvalidBook
+	}
+	return &Book{ID: uuid.New(), Name: name}, nil
+}
+
+// BookRepository defines the data access operations for books
+// Every operation is limited to the books of the caller in ctx
+type BookRepository interface {
+	// Create saves a new book owned by the caller
+	Create(ctx context.Context, book *Book) error
+
+	// GetByID retrieves one of the caller's books, or returns ErrBookNotFound
+	GetByID(ctx context.Context, id string) (*Book, error)
+
+	// List returns the caller's books by name
+	List(ctx context.Context) ([]*Book, error)
+
+	// Delete removes one of the caller's books with its categories and budgets,
+	// or returns ErrBookNotEmpty while it still has expenses or pending receipts
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,185 @@
+// Package domain contains the core business logic and entities
+// This file defines book archives: everything kept in a book, in one file that can be imported
+// into another deployment (e.g. moving from a self-hosted server to the hosted service)
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For matching names case-insensitively
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// BookArchiveVersion is the version of the book archive format
+// Imports of other versions are refused, so the format can change safely later
+const BookArchiveVersion = 1
+
+// MaxBookArchiveExpenses bounds how many expenses one archive may hold
+const MaxBookArchiveExpenses = 200000
+
+// BookArchive is a full copy of a book: its categories, budgets and expenses, the categorization
+// rules and a manifest of the receipts attached to the expenses
+// IDs in the archive are those of the exporting deployment; they only link the entries to each
+// other (subcategories to parents, attachments to expenses). Importing creates everything anew
+type BookArchive struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at,omitempty"`
+
+	// Book is the name of the exported book
+	Book string `json:"book"`
+
+	Categories []*ArchivedCategory `json:"categories"`
+	Budgets    []*BudgetLine       `json:"budgets"`
+	Rules      []*ArchivedRule     `json:"rules"`
+
+	// Expenses are complete, with the fields that point at other records of the exporting
+	// deployment (accounts, trips, employers, loans, members) left out
+	Expenses []*Expense `json:"expenses"`
+
+	// Attachments lists the receipt files of the expenses; the files themselves aren't in the
+	// archive and are uploaded again to the expenses the import returns for them
+	Attachments []*ArchivedAttachment `json:"attachments"`
+}
+
+// ArchivedCategory is a category in a BookArchive
+type ArchivedCategory struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	VATRate    *float64   `json:"vat_rate,omitempty"`
+	ParentID   *uuid.UUID `json:"parent_id,omitempty"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// ArchivedRule is a categorization rule in a BookArchive
+// Archives written before rules had conditions have none, which makes keyword rules
+type ArchivedRule struct {
+	Pattern  string `json:"pattern"`
+	Category string `json:"category"`
+	Priority int    `json:"priority"`
+	RuleConditions
+}
+
+// ArchivedAttachment is an entry of the attachment manifest of a BookArchive
+type ArchivedAttachment struct {
+	// ExpenseID is the archived expense the file is attached to
+	ExpenseID   uuid.UUID `json:"expense_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+
+	// ContentHash is the hex SHA-256 of the file, to check the uploaded copy against
+	ContentHash string `json:"content_hash,omitempty"`
+}
+
+// NewArchivedCategory describes a category for an archive
+func NewArchivedCategory(category *Category) *ArchivedCategory {
+	return &ArchivedCategory{
+		ID:         category.ID,
+		Name:       category.Name,
+		VATRate:    category.VATRate,
+		ParentID:   category.ParentID,
+		ArchivedAt: category.ArchivedAt,
+	}
+}
+
+// NewArchivedExpense copies an expense for an archive, leaving out where it is kept and what
+// it points at in this deployment
+func NewArchivedExpense(expense *Expense) *Expense {
+	archived := *expense
+	archived.UserID = nil
+	archived.BookID = nil
+	archived.AccountID = nil
+	archived.TripID = nil
+	archived.EmployerID = nil
+	archived.LoanID = nil
+	archived.MemberID = nil
+	archived.PolicyWarnings = nil
+	return &archived
+}
+
+// Validate checks the archive can be imported
+// Categories must have unique names and parents in the archive, budgets and rules must be
+// valid, and expenses valid with unique IDs that the attachments refer to
+func (a *BookArchive) Validate() error {
+	if a.Version != BookArchiveVersion || len(a.Expenses) > MaxBookArchiveExpenses {
+		return ErrInvalidBookArchive
+	}
+
+	// Categories: unique names, and parents that are in the archive
+	names := make(map[string]bool, len(a.Categories))
+	categories := make(map[uuid.UUID]*ArchivedCategory, len(a.Categories))
+	for _, category := range a.Categories {
+		key := strings.ToLower(strings.TrimSpace(category.Name))
+		if key == "" || names[key] || categories[category.ID] != nil {
+			return ErrInvalidBookArchive
+		}
+		if category.VATRate != nil && ValidateVATRate(*category.VATRate) != nil {
+			return ErrInvalidBookArchive
+		}
+		names[key] = true
+		categories[category.ID] = category
+	}
+	for _, category := range a.Categories {
+		// Walking up from every category must reach a top-level one within MaxCategoryDepth levels
+		depth := 1
+		for parent := category.ParentID; parent != nil; parent = categories[*parent].ParentID {
+			if categories[*parent] == nil || depth >= MaxCategoryDepth {
+				return ErrInvalidBookArchive
+			}
+			depth++
+		}
+	}
+
+	// Budgets follow the rules of budget configurations
+	config := &BudgetConfig{Version: BudgetConfigVersion, Budgets: a.Budgets}
+	if _, err := config.Validate(); err != nil {
+		return ErrInvalidBookArchive
+	}
+
+	for _, rule := range a.Rules {
+		if _, err := NewConditionalCategoryRule(rule.Pattern, rule.Category, rule.Priority, rule.RuleConditions); err != nil {
+			return ErrInvalidBookArchive
+		}
+	}
+
+	// Expenses: valid and uniquely identified, so the attachments can be linked to them
+	expenses := make(map[uuid.UUID]bool, len(a.Expenses))
+	for _, expense := range a.Expenses {
+		if expense == nil || expense.Validate() != nil || expenses[expense.ID] {
+			return ErrInvalidBookArchive
+		}
+		expenses[expense.ID] = true
+	}
+	for _, attachment := range a.Attachments {
+		if !expenses[attachment.ExpenseID] || strings.TrimSpace(attachment.FileName) == "" || attachment.Size <= 0 {
+			return ErrInvalidBookArchive
+		}
+	}
+	return nil
+}
+
+// BookArchiveImport is the outcome of importing a BookArchive
+type BookArchiveImport struct {
+	// Book is the book the archive was imported into
+	Book *Book `json:"book"`
+
+	Categories int `json:"categories"`
+	Budgets    int `json:"budgets"`
+	Expenses   int `json:"expenses"`
+
+	// Rules counts the rules added; duplicates in the archive are skipped
+	Rules int `json:"rules"`
+
+	// ExpenseIDs maps the archived expense IDs to the imported ones, to upload the attachments to
+	ExpenseIDs map[string]string `json:"expense_ids"`
+
+	// Attachments is the manifest of the files to upload again, with the imported expense IDs
+	Attachments []*ArchivedAttachment `json:"attachments"`
+}
+
+// BookArchiveRepository reads what book archives need beyond the other repositories
+type BookArchiveRepository interface {
+	// Attachments returns the attachment manifest of the caller's expenses in their current book
+	Attachments(ctx context.Context) ([]*ArchivedAttachment, error)
+}
@@ -0,0 +1,86 @@
+// Package domain contains the core business logic and entities
+// This file defines tenant branding: the name, logo and texts shown on generated reports
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"net/url"      // For validating logo URLs
+	"strings"      // For trimming the texts
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring text length in characters
+)
+
+// Branding limits keep headers and footers to a line or two on a PDF page
+const (
+	MaxBrandingNameLength = 100
+	MaxBrandingTextLength = 300
+	MaxLogoURLLength      = 2048
+)
+
+// DefaultBrandingName is the name on reports of tenants that haven't set their own branding
+const DefaultBrandingName = "MyExpenses"
+
+// Branding is how a tenant's generated PDFs and report emails are labelled
+// There is at most one per tenant; the default tenant (no tenant ID) has the ID ""
+type Branding struct {
+	TenantID string `json:"tenant_id" gorm:"primaryKey"`
+
+	// Name replaces "MyExpenses" at the top of reports (e.g. the company name)
+	Name string `json:"name" gorm:"not null"`
+
+	// LogoURL is an absolute http(s) URL of the logo shown in report emails
+	// The image is linked, not stored, so it has to stay reachable for recipients
+	LogoURL string `json:"logo_url,omitempty"`
+
+	// HeaderText is printed under the name, FooterText at the bottom of every report
+	HeaderText string `json:"header_text,omitempty"`
+	FooterText string `json:"footer_text,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewBranding creates a tenant's branding with validation
+func NewBranding(tenantID, name, logoURL, headerText, footerText string) (*Branding, error) {
+	branding := &Branding{
+		TenantID:   tenantID,
+		Name:       strings.TrimSpace(name),
+		LogoURL:    strings.TrimSpace(logoURL),
+		HeaderText: strings.TrimSpace(headerText),
+		FooterText: strings.TrimSpace(footerText),
+	}
+
+	if branding.Name == "" || utf8.RuneCountInString(branding.Name) > MaxBrandingNameLength {
+		return nil, ErrInvalidBranding
+	}
+	if utf8.RuneCountInString(branding.HeaderText) > MaxBrandingTextLength ||
+		utf8.RuneCountInString(branding.FooterText) > MaxBrandingTextLength {
+		return nil, ErrInvalidBranding
+	}
+	if branding.LogoURL != "" {
+		// Only absolute web URLs: anything else (javascript:, data:, relative paths)
+		// would be unsafe or broken inside an email
+		parsed, err := url.Parse(branding.LogoURL)
+		if err != nil || len(branding.LogoURL) > MaxLogoURLLength ||
+			(parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return nil, ErrInvalidBranding
+		}
+	}
+	return branding, nil
+}
+
+// DefaultBranding is the branding of tenants that haven't set their own
+func DefaultBranding(tenantID string) *Branding {
+	return &Branding{TenantID: tenantID, Name: DefaultBrandingName}
+}
+
+// BrandingRepository defines how tenant branding is stored
+type BrandingRepository interface {
+	// Get retrieves a tenant's branding, or returns ErrBrandingNotFound
+	Get(ctx context.Context, tenantID string) (*Branding, error)
+
+	// Save creates or replaces a tenant's branding
+	Save(ctx context.Context, branding *Branding) error
+
+	// Delete removes a tenant's branding, or returns ErrBrandingNotFound
+	Delete(ctx context.Context, tenantID string) error
+}
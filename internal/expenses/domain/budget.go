@@ -0,0 +1,141 @@
+// Package domain contains the core business logic and entities
+// This file defines budgets (monthly spending limits per category) and the spending data used to forecast them
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For normalizing category names
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Budget is a monthly spending limit for one category
+// A budget applies to every month unless it names one; a budget for a single month replaces the
+// category's recurring budget in that month (e.g. a bigger "Gifts" budget for December)
+// Amounts are in the base currency, like Expense.BaseAmount
+type Budget struct {
+	// ID is a unique identifier for each budget
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// BookID is the book the budget belongs to (nil for the default book)
+	BookID *uuid.UUID `json:"book_id,omitempty" gorm:"type:uuid;index"`
+
+	// Category is the expense category the budget applies to
+	// A book has one budget per category and month, plus one recurring budget per category
+	Category string `json:"category" gorm:"not null"`
+
+	// Month is the month the budget applies to in YYYY-MM format, empty for every month
+	Month string `json:"month,omitempty" gorm:"size:7;not null;default:''"`
+
+	// Rollover carries what was left of the previous month's budget into this one; overspending
+	// reduces it instead
+	Rollover bool `json:"rollover" gorm:"not null;default:false"`
+
+	// Amount is how much may be spent in the category per month
+	Amount Money `json:"amount" gorm:"type:numeric(19,2);not null"`
+
+	// CreatedAt is automatically set when the budget is first saved
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// UpdatedAt is automatically updated whenever the budget is modified
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewBudget creates a validated budget
+func NewBudget(category string, amount Money) (*Budget, error) {
+	category = strings.TrimSpace(category)
+	if category == "" {
+		return nil, ErrInvalidCategory
+	}
+	if amount.Minor <= 0 {
+		return nil, ErrInvalidBudget
+	}
+	return &Budget{
+		ID:       uuid.New(),
+		Category: category,
+		Amount:   amount,
+	}, nil
+}
+
+// SetAmount changes the monthly limit
+func (b *Budget) SetAmount(amount Money) error {
+	if amount.Minor <= 0 {
+		return ErrInvalidBudget
+	}
+	b.Amount = amount
+	return nil
+}
+
+// SetMonth limits the budget to one month in YYYY-MM format; empty makes it apply to every month
+func (b *Budget) SetMonth(month string) error {
+	if strings.TrimSpace(month) == "" {
+		b.Month = ""
+		return nil
+	}
+	start, err := ParseMonth(month)
+	if err != nil {
+		return err
+	}
+	b.Month = start.Format("2006-01")
+	return nil
+}
+
+// EffectiveBudgets returns the budgets that apply in the month starting at month
+// A category's budget for that month wins over its recurring budget, and budgets for other
+// months are left out; categories are matched case-insensitively and the order is kept
+func EffectiveBudgets(budgets []*Budget, month time.Time) []*Budget {
+	key := MonthStart(month).Format("2006-01")
+	specific := make(map[string]bool)
+	for _, budget := range budgets {
+		if budget.Month == key {
+			specific[strings.ToLower(budget.Category)] = true
+		}
+	}
+
+	effective := make([]*Budget, 0, len(budgets))
+	for _, budget := range budgets {
+		switch budget.Month {
+		case key:
+			effective = append(effective, budget)
+		case "":
+			if !specific[strings.ToLower(budget.Category)] {
+				effective = append(effective, budget)
+			}
+		}
+	}
+	return effective
+}
+
+// BudgetRepository defines the data access operations for budgets
+type BudgetRepository interface {
+	// Create saves a new budget, or returns ErrBudgetExists if the category already has one for the same month
+	Create(ctx context.Context, budget *Budget) error
+
+	// GetByID retrieves a budget by its unique identifier
+	GetByID(ctx context.Context, id string) (*Budget, error)
+
+	// List returns all budgets ordered by category, recurring budgets before those of single months
+	List(ctx context.Context) ([]*Budget, error)
+
+	// Update saves changes to an existing budget
+	Update(ctx context.Context, budget *Budget) error
+
+	// Delete removes a budget by its unique identifier
+	Delete(ctx context.Context, id string) error
+}
+
+// MonthStart returns midnight UTC on the first day of t's month
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ParseMonth parses a "YYYY-MM" month into the first day of that month
+func ParseMonth(month string) (time.Time, error) {
+	start, err := time.Parse("2006-01", strings.TrimSpace(month))
+	if err != nil {
+		return time.Time{}, ErrInvalidMonth
+	}
+	return start, nil
+}
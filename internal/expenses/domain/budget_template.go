@@ -0,0 +1,192 @@
+// Package domain contains the core business logic and entities
+// This file defines budget configurations, the portable form budgets are exported and imported in,
+// and the built-in templates a configuration can be started from
+package domain
+
+import (
+	"strings" // For matching template names and categories
+	"time"    // For the export time
+)
+
+// BudgetConfigVersion is the version of the budget configuration format
+// Imports of other versions are refused, so the format can change safely later
+const BudgetConfigVersion = 1
+
+// MaxBudgetConfigLines bounds how many budgets one configuration may hold
+const MaxBudgetConfigLines = 500
+
+// Budget import modes
+const (
+	// BudgetImportMerge adds and updates budgets and keeps the others
+	BudgetImportMerge = "merge"
+
+	// BudgetImportReplace also removes the budgets the configuration doesn't list
+	BudgetImportReplace = "replace"
+)
+
+// BudgetConfig is a complete budget configuration: every category's monthly limit
+// Most budgets apply to every month, so importing a configuration sets up the coming months;
+// importing it into another deployment or tenant copies the budgets there
+type BudgetConfig struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at,omitempty"`
+	Budgets    []*BudgetLine `json:"budgets" binding:"dive"`
+}
+
+// BudgetLine is one category's monthly limit in a BudgetConfig
+type BudgetLine struct {
+	Category string `json:"category" binding:"required"`
+
+	// Amount must be greater than 0; importing the line rejects it otherwise
+	Amount Money `json:"amount"`
+
+	// Month limits the line to one month (YYYY-MM); empty applies it to every month
+	Month string `json:"month,omitempty"`
+
+	// Rollover carries unspent budget into the next month, like Budget.Rollover
+	Rollover bool `json:"rollover,omitempty"`
+}
+
+// NewBudgetConfig describes the given budgets as a configuration
+func NewBudgetConfig(budgets []*Budget, now time.Time) *BudgetConfig {
+	config := &BudgetConfig{Version: BudgetConfigVersion, ExportedAt: now.UTC(), Budgets: []*BudgetLine{}}
+	for _, budget := range budgets {
+		config.Budgets = append(config.Budgets, &BudgetLine{Category: budget.Category, Amount: budget.Amount, Month: budget.Month, Rollover: budget.Rollover})
+	}
+	return config
+}
+
+// Validate checks the configuration can be imported and returns its budgets
+// Every category may appear only once per month (compared case-insensitively, like budgets are matched)
+func (c *BudgetConfig) Validate() ([]*Budget, error) {
+	if c.Version != BudgetConfigVersion || len(c.Budgets) > MaxBudgetConfigLines {
+		return nil, ErrInvalidBudgetConfig
+	}
+	seen := make(map[string]bool, len(c.Budgets))
+	budgets := make([]*Budget, 0, len(c.Budgets))
+	for _, line := range c.Budgets {
+		budget, err := NewBudget(line.Category, line.Amount)
+		if err != nil {
+			return nil, ErrInvalidBudgetConfig
+		}
+		if err := budget.SetMonth(line.Month); err != nil {
+			return nil, ErrInvalidBudgetConfig
+		}
+		budget.Rollover = line.Rollover
+		key := strings.ToLower(budget.Category) + "\x00" + budget.Month
+		if seen[key] {
+			return nil, ErrInvalidBudgetConfig
+		}
+		seen[key] = true
+		budgets = append(budgets, budget)
+	}
+	return budgets, nil
+}
+
+// BudgetTemplate is a built-in budget plan that divides a monthly income between categories
+type BudgetTemplate struct {
+	// Name identifies the template (e.g. "50-30-20")
+	Name string `json:"name"`
+
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	Lines []*BudgetTemplateLine `json:"lines"`
+}
+
+// BudgetTemplateLine gives one category of a template its share of the income
+type BudgetTemplateLine struct {
+	Category string `json:"category"`
+
+	// Group is the part of the plan the category belongs to (e.g. "needs")
+	Group string `json:"group"`
+
+	// Share is the category's percentage of the income; the shares of a template add up to 100
+	Share float64 `json:"share"`
+}
+
+// budgetTemplates are the built-in templates, in the order they are listed
+var budgetTemplates = []*BudgetTemplate{
+	{
+		Name:        "50-30-20",
+		Title:       "50/30/20",
+		Description: "Half of the income for needs, 30% for wants and 20% for savings and debt repayment",
+		Lines: []*BudgetTemplateLine{
+			{Category: "Housing", Group: "needs", Share: 25},
+			{Category: "Groceries", Group: "needs", Share: 10},
+			{Category: "Transportation", Group: "needs", Share: 8},
+			{Category: "Utilities", Group: "needs", Share: 7},
+			{Category: "Dining", Group: "wants", Share: 10},
+			{Category: "Entertainment", Group: "wants", Share: 10},
+			{Category: "Shopping", Group: "wants", Share: 10},
+			{Category: "Savings", Group: "savings", Share: 20},
+		},
+	},
+	{
+		Name:        "zero-based",
+		Title:       "Zero-based",
+		Description: "Every unit of income gets a job: the categories add up to exactly the income",
+		Lines: []*BudgetTemplateLine{
+			{Category: "Housing", Group: "fixed", Share: 30},
+			{Category: "Utilities", Group: "fixed", Share: 6},
+			{Category: "Insurance", Group: "fixed", Share: 6},
+			{Category: "Groceries", Group: "variable", Share: 12},
+			{Category: "Transportation", Group: "variable", Share: 9},
+			{Category: "Health", Group: "variable", Share: 5},
+			{Category: "Personal", Group: "variable", Share: 5},
+			{Category: "Entertainment", Group: "variable", Share: 5},
+			{Category: "Debt", Group: "goals", Share: 7},
+			{Category: "Savings", Group: "goals", Share: 15},
+		},
+	},
+}
+
+// BudgetTemplates returns the built-in templates
+func BudgetTemplates() []*BudgetTemplate {
+	return budgetTemplates
+}
+
+// LookupBudgetTemplate finds a built-in template by name (ignoring case)
+func LookupBudgetTemplate(name string) (*BudgetTemplate, error) {
+	for _, template := range budgetTemplates {
+		if strings.EqualFold(template.Name, strings.TrimSpace(name)) {
+			return template, nil
+		}
+	}
+	return nil, ErrBudgetTemplateNotFound
+}
+
+// Instantiate divides a monthly income between the template's categories
+// renames maps template categories to the user's own (e.g. "Dining" to "Restaurants");
+// categories renamed to the same one are added up
+func (t *BudgetTemplate) Instantiate(income Money, renames map[string]string) (*BudgetConfig, error) {
+	if income.Minor <= 0 {
+		return nil, ErrInvalidBudget
+	}
+	config := &BudgetConfig{Version: BudgetConfigVersion, Budgets: []*BudgetLine{}}
+	byCategory := make(map[string]*BudgetLine, len(t.Lines))
+	var total Money
+	for _, line := range t.Lines {
+		category := line.Category
+		for from, to := range renames {
+			if strings.EqualFold(from, category) && strings.TrimSpace(to) != "" {
+				category = strings.TrimSpace(to)
+			}
+		}
+		amount := income.Mul(line.Share / 100)
+		total = total.Add(amount)
+		if existing, ok := byCategory[strings.ToLower(category)]; ok {
+			existing.Amount = existing.Amount.Add(amount)
+			continue
+		}
+		budget := &BudgetLine{Category: category, Amount: amount}
+		byCategory[strings.ToLower(category)] = budget
+		config.Budgets = append(config.Budgets, budget)
+	}
+
+	// Rounding each share to cents can leave a cent over or missing; the last budget takes it,
+	// so the budgets add up to the income
+	last := config.Budgets[len(config.Budgets)-1]
+	last.Amount = last.Amount.Add(income.Sub(total))
+	return config, nil
+}
@@ -0,0 +1,405 @@
+// Package domain contains the core business logic and entities
+// This file defines the auto-categorization model: MCC mappings, keyword rules and imported transactions
+package domain
+
+import (
+	"context"       // For request context (cancellation, timeouts)
+	"regexp"        // For regex rules (RE2: matching time is linear in the input)
+	"regexp/syntax" // For measuring how big a regex compiles
+	"strings"       // For case-insensitive matching
+	"time"          // For handling dates and times
+	"unicode/utf8"  // For measuring patterns in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// UncategorizedCategory is assigned when no categorizer recognizes a transaction
+const UncategorizedCategory = "Uncategorized"
+
+// ImportedTransaction is a single line of a bank or card statement before it becomes an expense
+// It carries the raw data the bank gives us, including the merchant category code
+type ImportedTransaction struct {
+	// ExternalID is the bank's unique ID for the transaction (used to skip duplicates)
+	ExternalID string `json:"external_id"`
+
+	// Date is when the transaction was booked
+	Date time.Time `json:"date" binding:"required"`
+
+	// Amount is the transaction amount (positive for money spent)
+	// Binding can't check it; every importer refuses transactions whose amount isn't positive
+	Amount Money `json:"amount"`
+
+	// Currency is the currency of Amount (empty = base currency)
+	Currency string `json:"currency"`
+
+	// ConvertedAmount is what the bank charged in the base currency for foreign transactions
+	ConvertedAmount Money `json:"converted_amount"`
+
+	// Description is the statement text (e.g. "CARD PAYMENT SHELL 1234 LONDON")
+	Description string `json:"description" binding:"required"`
+
+	// Merchant is the merchant name when the bank provides it separately
+	Merchant string `json:"merchant"`
+
+	// MCC is the 4-digit merchant category code for card transactions
+	MCC string `json:"mcc"`
+
+	// Account names the bank account or card the statement came from (e.g. "Amex Gold")
+	// The corporate card feed uses the last four digits of the card
+	Account string `json:"account"`
+
+	// Source is how the transaction arrived (one of the RuleSource* values), set by the importer
+	Source string `json:"-"`
+}
+
+// MCCMapping maps a merchant category code to one of our expense categories
+// The table is configurable so users can decide e.g. that 5812 (restaurants) is "Dining" rather than "Food"
+type MCCMapping struct {
+	// MCC is the 4-digit merchant category code and the primary key of the table
+	MCC string `json:"mcc" gorm:"primary_key;size:4"`
+
+	// Category is the expense category assigned to transactions with this code
+	Category string `json:"category" gorm:"not null"`
+
+	// UpdatedAt is automatically updated whenever the mapping changes
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewMCCMapping creates a validated MCC mapping
+func NewMCCMapping(mcc, category string) (*MCCMapping, error) {
+	if !IsValidMCC(mcc) {
+		return nil, ErrInvalidMCC
+	}
+	category = strings.TrimSpace(category)
+	if category == "" {
+		return nil, ErrInvalidCategory
+	}
+	return &MCCMapping{MCC: mcc, Category: category}, nil
+}
+
+// IsValidMCC reports whether code is a well-formed merchant category code (exactly 4 digits)
+func IsValidMCC(code string) bool {
+	if len(code) != 4 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultMCCMappings is the mapping table installed on first run
+// It covers the most common consumer merchant category codes
+var DefaultMCCMappings = map[string]string{
+	"4111": "Transportation", // Commuter transport, ferries
+	"4121": "Transportation", // Taxicabs and limousines
+	"4131": "Transportation", // Bus lines
+	"4511": "Travel",         // Airlines
+	"4814": "Utilities",      // Telecommunication services
+	"4900": "Utilities",      // Utilities - electric, gas, water
+	"5411": "Food",           // Grocery stores, supermarkets
+	"5499": "Food",           // Miscellaneous food stores
+	"5541": "Transportation", // Service stations
+	"5542": "Transportation", // Automated fuel dispensers
+	"5812": "Food",           // Eating places, restaurants
+	"5814": "Food",           // Fast food restaurants
+	"5912": "Health",         // Drug stores and pharmacies
+	"5942": "Entertainment",  // Book stores
+	"5999": "Shopping",       // Miscellaneous retail
+	"7011": "Travel",         // Hotels, motels, resorts
+	"7832": "Entertainment",  // Motion picture theaters
+	"8011": "Health",         // Doctors
+	"8021": "Health",         // Dentists
+}
+
+// MCCRepository defines the data access operations for the MCC mapping table
+type MCCRepository interface {
+	// List returns all mappings ordered by code
+	List(ctx context.Context) ([]*MCCMapping, error)
+
+	// Get returns the mapping for a code, or ErrMCCMappingNotFound
+	Get(ctx context.Context, mcc string) (*MCCMapping, error)
+
+	// Upsert creates or replaces the mapping for mapping.MCC
+	Upsert(ctx context.Context, mapping *MCCMapping) error
+
+	// Delete removes the mapping for a code
+	Delete(ctx context.Context, mcc string) error
+}
+
+// How a rule's pattern is matched against the description and merchant
+const (
+	// RuleMatchContains matches when the text contains the pattern, ignoring case
+	RuleMatchContains = "contains"
+
+	// RuleMatchRegex matches the pattern as a regular expression (RE2 syntax), ignoring case
+	RuleMatchRegex = "regex"
+)
+
+// Where an imported transaction came from, for rules that only apply to one source
+const (
+	// RuleSourceImport is a statement uploaded to POST /imports/transactions
+	RuleSourceImport = SourceImport
+
+	// RuleSourceCardFeed is the corporate card feed
+	RuleSourceCardFeed = "card_feed"
+)
+
+// How the rule categorizer picks among the rules that match a transaction
+const (
+	// RuleModeFirstMatch takes the category of the highest priority matching rule
+	RuleModeFirstMatch = "first"
+
+	// RuleModeAllMatch evaluates every rule and only categorizes the transaction if all the
+	// matching rules agree on the category. Conflicts are left for the next categorizer, so
+	// overlapping rules end up for review instead of silently resolved by priority
+	RuleModeAllMatch = "all"
+)
+
+// ParseRuleMode checks a rule mode; empty means RuleModeFirstMatch
+func ParseRuleMode(mode string) (string, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "":
+		return RuleModeFirstMatch, nil
+	case RuleModeFirstMatch, RuleModeAllMatch:
+		return mode, nil
+	}
+	return "", ErrInvalidRuleMode
+}
+
+// Limits that keep regex rules cheap: Go's regexp runs in time linear in the input, so bounding
+// the size of the compiled pattern and of the text it reads bounds the time of every match
+const (
+	// MaxRulePatternLength is the longest pattern, in characters
+	MaxRulePatternLength = 200
+
+	// maxRuleProgramSize is the most instructions a regex pattern may compile to
+	// "(a|b){1,100}" style repetitions blow up here long before they are slow
+	maxRuleProgramSize = 2000
+
+	// MaxRuleInputLength is how much of the description and merchant a rule reads, in bytes
+	MaxRuleInputLength = 1000
+)
+
+// CategoryRule assigns a category to transactions whose text contains a keyword or matches a regex
+// Rules are the fallback when a transaction has no MCC or the MCC isn't mapped
+// Besides the pattern, a rule may require an amount range, an account and a source; every
+// condition the rule sets has to hold
+// Rules belong to a user's book, like the categories they assign
+type CategoryRule struct {
+	// ID is a unique identifier for each rule
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// Pattern is the keyword or regex searched for (case-insensitive) in the description and merchant
+	// It may be empty when the rule has other conditions, e.g. "everything on the Amex over 500"
+	Pattern string `json:"pattern" gorm:"not null;default:''"`
+
+	// Matcher is how Pattern is matched (one of the RuleMatch* values)
+	Matcher string `json:"matcher" gorm:"size:16;not null;default:'contains'"`
+
+	// MinAmount and MaxAmount bound the transaction amount (both inclusive; nil = unbounded)
+	MinAmount *Money `json:"min_amount,omitempty" gorm:"type:numeric(19,2)"`
+	MaxAmount *Money `json:"max_amount,omitempty" gorm:"type:numeric(19,2)"`
+
+	// Account limits the rule to one account or card (case-insensitive; "" = any)
+	Account string `json:"account,omitempty"`
+
+	// Source limits the rule to one way transactions arrive (one of the RuleSource* values; "" = any)
+	Source string `json:"source,omitempty" gorm:"size:16"`
+
+	// Category is assigned when the rule matches
+	Category string `json:"category" gorm:"not null"`
+
+	// Priority orders rules; higher priority rules are tried first
+	Priority int `json:"priority" gorm:"not null;default:0"`
+
+	// UserID is the user who added the rule; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// BookID is the book whose imports the rule categorizes (nil for the owner's default book)
+	BookID *uuid.UUID `json:"book_id,omitempty" gorm:"type:uuid"`
+
+	// CreatedAt is automatically set when the rule is first saved
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// regex is Pattern compiled, for regex rules
+	regex *regexp.Regexp
+}
+
+// RuleConditions are the optional conditions of a rule besides its pattern
+type RuleConditions struct {
+	// Matcher is how the pattern is matched; empty means RuleMatchContains
+	Matcher string `json:"matcher"`
+
+	MinAmount *Money `json:"min_amount"`
+	MaxAmount *Money `json:"max_amount"`
+	Account   string `json:"account"`
+	Source    string `json:"source"`
+}
+
+// NewCategoryRule creates a validated keyword rule
+func NewCategoryRule(pattern, category string, priority int) (*CategoryRule, error) {
+	return NewConditionalCategoryRule(pattern, category, priority, RuleConditions{})
+}
+
+// NewConditionalCategoryRule creates a validated rule with a matcher and conditions
+// Regex patterns are compiled here, so a pattern that doesn't compile or is too big never gets saved
+func NewConditionalCategoryRule(pattern, category string, priority int, conditions RuleConditions) (*CategoryRule, error) {
+	rule := &CategoryRule{
+		ID:        uuid.New(),
+		Pattern:   strings.TrimSpace(pattern),
+		Matcher:   strings.ToLower(strings.TrimSpace(conditions.Matcher)),
+		MinAmount: conditions.MinAmount,
+		MaxAmount: conditions.MaxAmount,
+		Account:   strings.TrimSpace(conditions.Account),
+		Source:    strings.ToLower(strings.TrimSpace(conditions.Source)),
+		Category:  strings.TrimSpace(category),
+		Priority:  priority,
+	}
+	if rule.Matcher == "" {
+		rule.Matcher = RuleMatchContains
+	}
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+	if rule.Category == "" {
+		return nil, ErrInvalidCategory
+	}
+	return rule, nil
+}
+
+// Conditions returns the matcher and conditions of the rule
+func (r *CategoryRule) Conditions() RuleConditions {
+	return RuleConditions{Matcher: r.Matcher, MinAmount: r.MinAmount, MaxAmount: r.MaxAmount, Account: r.Account, Source: r.Source}
+}
+
+// Key identifies what the rule does, ignoring its priority: rules with the same key are the same rule
+func (r *CategoryRule) Key() string {
+	bound := func(amount *Money) string {
+		if amount == nil {
+			return ""
+		}
+		return amount.String()
+	}
+	return strings.Join([]string{
+		strings.ToLower(r.Pattern), strings.ToLower(r.Category), r.Matcher,
+		bound(r.MinAmount), bound(r.MaxAmount), strings.ToLower(r.Account), r.Source,
+	}, "\x00")
+}
+
+// validate checks the rule and compiles its regex
+func (r *CategoryRule) validate() error {
+	// Step 1: A rule needs something to match on
+	conditional := r.MinAmount != nil || r.MaxAmount != nil || r.Account != "" || r.Source != ""
+	if r.Pattern == "" && !conditional {
+		return ErrInvalidRule
+	}
+	if utf8.RuneCountInString(r.Pattern) > MaxRulePatternLength {
+		return ErrInvalidRule
+	}
+
+	// Step 2: Check the conditions
+	if (r.MinAmount != nil && r.MinAmount.IsNegative()) || (r.MaxAmount != nil && r.MaxAmount.IsNegative()) ||
+		(r.MinAmount != nil && r.MaxAmount != nil && r.MaxAmount.Minor < r.MinAmount.Minor) {
+		return ErrInvalidRule
+	}
+	if r.Source != "" && r.Source != RuleSourceImport && r.Source != RuleSourceCardFeed {
+		return ErrInvalidRule
+	}
+
+	// Step 3: Check the pattern
+	switch r.Matcher {
+	case RuleMatchContains:
+		return nil
+	case RuleMatchRegex:
+		if r.Pattern == "" {
+			return ErrInvalidRule
+		}
+		return r.compile()
+	}
+	return ErrInvalidRule
+}
+
+// compile compiles a regex pattern, refusing ones that compile too big
+func (r *CategoryRule) compile() error {
+	parsed, err := syntax.Parse("(?i)"+r.Pattern, syntax.Perl)
+	if err != nil {
+		return ErrInvalidRule
+	}
+	program, err := syntax.Compile(parsed.Simplify())
+	if err != nil || len(program.Inst) > maxRuleProgramSize {
+		return ErrInvalidRule
+	}
+	r.regex, err = regexp.Compile("(?i)" + r.Pattern)
+	if err != nil {
+		return ErrInvalidRule
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to the given transaction
+// A stored regex that no longer compiles (or that got too big) matches nothing
+func (r *CategoryRule) Matches(tx *ImportedTransaction) bool {
+	// Step 1: The cheap conditions first
+	if r.MinAmount != nil && tx.Amount.Minor < r.MinAmount.Minor {
+		return false
+	}
+	if r.MaxAmount != nil && tx.Amount.Minor > r.MaxAmount.Minor {
+		return false
+	}
+	if r.Account != "" && !strings.EqualFold(r.Account, strings.TrimSpace(tx.Account)) {
+		return false
+	}
+	if r.Source != "" && r.Source != tx.Source {
+		return false
+	}
+	if r.Pattern == "" {
+		return true
+	}
+
+	// Step 2: The pattern, on a bounded amount of text
+	description, merchant := truncateRuleInput(tx.Description), truncateRuleInput(tx.Merchant)
+	if r.Matcher == RuleMatchRegex {
+		if r.regex == nil && r.compile() != nil {
+			return false
+		}
+		return r.regex.MatchString(description) || r.regex.MatchString(merchant)
+	}
+	pattern := strings.ToLower(r.Pattern)
+	return strings.Contains(strings.ToLower(description), pattern) ||
+		strings.Contains(strings.ToLower(merchant), pattern)
+}
+
+// truncateRuleInput cuts text to MaxRuleInputLength bytes without splitting a character
+func truncateRuleInput(text string) string {
+	if len(text) <= MaxRuleInputLength {
+		return text
+	}
+	cut := MaxRuleInputLength
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
+
+// RuleRepository defines the data access operations for categorization rules
+type RuleRepository interface {
+	// Create saves a new rule in the caller's current book
+	Create(ctx context.Context, rule *CategoryRule) error
+
+	// List returns the caller's rules in their current book, highest priority first
+	List(ctx context.Context) ([]*CategoryRule, error)
+
+	// Delete removes one of the caller's rules in their current book by its ID
+	Delete(ctx context.Context, id string) error
+}
+
+// Categorizer decides which category an imported transaction belongs to
+// Several categorizers are chained; the first one that recognizes the transaction wins
+type Categorizer interface {
+	// Categorize returns the category and true if the transaction was recognized
+	// Returning false passes the transaction on to the next categorizer in the chain
+	Categorize(ctx context.Context, tx *ImportedTransaction) (string, bool, error)
+}
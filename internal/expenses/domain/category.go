@@ -0,0 +1,237 @@
+// Package domain contains the core business logic and entities
+// This file defines the managed list of expense categories
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"sort"    // For ordering rolled-up totals
+	"strings" // For normalizing names
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Category is an entry in the list of categories offered to users
+// Expenses store the category name as text, so the list guides input without constraining history
+// The default book shares one list; every other book has a list of its own
+type Category struct {
+	// ID is a unique identifier for each category
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// BookID is the book the category belongs to (nil for the shared list of the default book)
+	BookID *uuid.UUID `json:"book_id,omitempty" gorm:"type:uuid;index"`
+
+	// Name is the category name as stored on expenses (e.g. "Food"), unique within its book
+	Name string `json:"name" gorm:"not null"`
+
+	// VATRate is the default VAT rate in percent of expenses in this category (nil for none)
+	// Entering a gross amount then fills in the expense's net and tax amounts
+	VATRate *float64 `json:"vat_rate,omitempty"`
+
+	// ParentID is the category this one is a subcategory of (nil for a top-level category)
+	// e.g. "Coffee" under "Restaurants" under "Food"; reports can roll subcategory spend up into it
+	ParentID *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid;index"`
+
+	// ArchivedAt is when the category was retired (nil while in use)
+	// Archived categories are hidden from pickers, but expenses filed under them keep the name
+	// and reports still count them
+	ArchivedAt *time.Time `json:"archived_at,omitempty" gorm:"index"`
+
+	// CreatedAt is automatically set when the category is first saved
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewCategory creates a validated category
+// vatRate is the category's default VAT rate in percent, or nil for none
+func NewCategory(name string, vatRate *float64) (*Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidCategory
+	}
+	category := &Category{
+		ID:   uuid.New(),
+		Name: name,
+	}
+	if err := category.SetVATRate(vatRate); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Archived reports whether the category was retired
+func (c *Category) Archived() bool {
+	return c.ArchivedAt != nil
+}
+
+// SetVATRate changes the default VAT rate of the category (nil removes it)
+// Existing expenses keep the split they were saved with
+func (c *Category) SetVATRate(rate *float64) error {
+	if rate == nil {
+		c.VATRate = nil
+		return nil
+	}
+	if err := ValidateVATRate(*rate); err != nil {
+		return err
+	}
+	value := *rate
+	c.VATRate = &value
+	return nil
+}
+
+// MaxCategoryDepth is how many levels categories can be nested, counting the top level
+const MaxCategoryDepth = 5
+
+// CategoryTree is the category list arranged by parent, for resolving subcategories
+// Names are matched case-insensitively, like the category filter of the expense list
+type CategoryTree struct {
+	byID     map[uuid.UUID]*Category
+	byName   map[string]*Category
+	children map[uuid.UUID][]*Category
+}
+
+// NewCategoryTree arranges categories (usually the whole list) by parent
+// A parent that isn't in the list is treated as missing, which makes its children top-level
+func NewCategoryTree(categories []*Category) *CategoryTree {
+	tree := &CategoryTree{
+		byID:     make(map[uuid.UUID]*Category, len(categories)),
+		byName:   make(map[string]*Category, len(categories)),
+		children: make(map[uuid.UUID][]*Category),
+	}
+	for _, category := range categories {
+		tree.byID[category.ID] = category
+		tree.byName[strings.ToLower(category.Name)] = category
+	}
+	for _, category := range categories {
+		if parent := tree.parent(category); parent != nil {
+			tree.children[parent.ID] = append(tree.children[parent.ID], category)
+		}
+	}
+	return tree
+}
+
+// parent returns the parent of category, or nil for a top-level category
+func (t *CategoryTree) parent(category *Category) *Category {
+	if category.ParentID == nil {
+		return nil
+	}
+	return t.byID[*category.ParentID]
+}
+
+// Subtree returns the names of the category called name and of all its subcategories
+// An unknown name is returned on its own, so expenses filed under it are still found
+func (t *CategoryTree) Subtree(name string) []string {
+	category, ok := t.byName[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return []string{name}
+	}
+	names := []string{}
+	pending := []*Category{category}
+	for len(pending) > 0 {
+		next := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		names = append(names, next.Name)
+		pending = append(pending, t.children[next.ID]...)
+	}
+	return names
+}
+
+// Root returns the name of the top-level category the category called name is filed under
+// Top-level and unknown names are returned as they are
+func (t *CategoryTree) Root(name string) string {
+	category, ok := t.byName[strings.ToLower(name)]
+	if !ok {
+		return name
+	}
+	// The depth bound guards against a cycle written to the database by hand
+	for depth := 0; depth < MaxCategoryDepth; depth++ {
+		parent := t.parent(category)
+		if parent == nil {
+			break
+		}
+		category = parent
+	}
+	return category.Name
+}
+
+// CheckParent reports whether category can be moved under parentID (nil makes it top-level)
+// The parent must exist and not be the category or one of its subcategories, and the category's
+// subtree must still fit within MaxCategoryDepth levels; otherwise it returns ErrInvalidCategoryParent
+func (t *CategoryTree) CheckParent(category *Category, parentID *uuid.UUID) error {
+	if parentID == nil {
+		return nil
+	}
+	parent, ok := t.byID[*parentID]
+	if !ok || parent.ID == category.ID {
+		return ErrInvalidCategoryParent
+	}
+
+	// Step 1: Walk up from the parent; meeting the category means the move would make a cycle
+	depth := 1
+	for ancestor := parent; ancestor != nil; ancestor = t.parent(ancestor) {
+		if ancestor.ID == category.ID || depth > MaxCategoryDepth {
+			return ErrInvalidCategoryParent
+		}
+		depth++
+	}
+
+	// Step 2: The category's own subcategories move along and must fit below it
+	if depth+t.height(category.ID, 0) > MaxCategoryDepth {
+		return ErrInvalidCategoryParent
+	}
+	return nil
+}
+
+// height is how many levels of subcategories are below the category with id
+func (t *CategoryTree) height(id uuid.UUID, depth int) int {
+	if depth > MaxCategoryDepth {
+		return depth
+	}
+	highest := 0
+	for _, child := range t.children[id] {
+		if h := t.height(child.ID, depth+1) + 1; h > highest {
+			highest = h
+		}
+	}
+	return highest
+}
+
+// RollUp sums category spending into the top-level categories, biggest first
+// Spending filed under a subcategory is added to the category it is nested in
+func (t *CategoryTree) RollUp(rows []*CategorySpending) []*CategorySpending {
+	totals := make(map[string]*CategorySpending)
+	rolled := []*CategorySpending{}
+	for _, row := range rows {
+		root := t.Root(row.Category)
+		total, ok := totals[root]
+		if !ok {
+			total = &CategorySpending{Category: root}
+			totals[root] = total
+			rolled = append(rolled, total)
+		}
+		total.Amount = total.Amount.Add(row.Amount)
+	}
+	sort.SliceStable(rolled, func(i, j int) bool { return rolled[i].Amount.Minor > rolled[j].Amount.Minor })
+	return rolled
+}
+
+// CategoryRepository defines the data access operations for categories
+type CategoryRepository interface {
+	// Create saves a new category, or returns ErrCategoryExists if the name is taken
+	Create(ctx context.Context, category *Category) error
+
+	// List returns all categories ordered by name, archived ones included
+	List(ctx context.Context) ([]*Category, error)
+
+	// GetByID retrieves a category, or returns ErrCategoryNotFound
+	GetByID(ctx context.Context, id string) (*Category, error)
+
+	// GetByName retrieves the category expenses name as their category, or returns ErrCategoryNotFound
+	GetByName(ctx context.Context, name string) (*Category, error)
+
+	// Update saves changes to an existing category
+	Update(ctx context.Context, category *Category) error
+
+	// Delete removes a category by its unique identifier
+	// Its subcategories move up to its parent, so they aren't left pointing at nothing
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,160 @@
+// Package domain contains the core business logic and entities
+// This file defines category suggestions learned from the categories a user gave past expenses
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"math"    // For log probabilities and amount magnitudes
+	"sort"    // For ranking the suggestions
+	"strconv" // For naming amount magnitudes
+	"strings" // For splitting descriptions into words
+	"unicode" // For telling word characters apart
+)
+
+// MaxCategorySuggestions is the most suggestions returned for one description
+const MaxCategorySuggestions = 5
+
+// CategorySuggestion is a category the user would likely pick for an expense
+type CategorySuggestion struct {
+	Category string `json:"category"`
+
+	// Confidence is the probability of the category among the user's categories, from 0 to 1
+	Confidence float64 `json:"confidence"`
+}
+
+// CategoryModel is a naive Bayes classifier over the words of descriptions and the size of amounts
+// It learns which words go with which category from past expenses: "uber" mostly with Transport,
+// "rewe" with Groceries. Every word counts independently ("naive"), which is crude but needs no
+// training beyond counting and works well on the short, repetitive descriptions of expenses
+type CategoryModel struct {
+	// expenses counts the expenses of each category; words the features seen with each
+	expenses map[string]int
+	words    map[string]map[string]int
+
+	// featureTotals is the number of features seen with each category; vocabulary the distinct features
+	featureTotals map[string]int
+	vocabulary    map[string]bool
+	total         int
+}
+
+// TrainCategoryModel learns a model from past expenses
+// Expenses without a category or anything to learn from are skipped; the normalized description
+// is used when there is one, so bank noise like card numbers doesn't count as words
+func TrainCategoryModel(expenses []*Expense) *CategoryModel {
+	model := &CategoryModel{
+		expenses:      map[string]int{},
+		words:         map[string]map[string]int{},
+		featureTotals: map[string]int{},
+		vocabulary:    map[string]bool{},
+	}
+	for _, expense := range expenses {
+		category := strings.TrimSpace(expense.Category)
+		if category == "" {
+			continue
+		}
+		description := expense.NormalizedDescription
+		if description == "" {
+			description = expense.Description
+		}
+		features := categoryFeatures(description, expense.Amount.Float64())
+		if len(features) == 0 {
+			continue
+		}
+
+		model.expenses[category]++
+		model.total++
+		if model.words[category] == nil {
+			model.words[category] = map[string]int{}
+		}
+		for _, feature := range features {
+			model.words[category][feature]++
+			model.featureTotals[category]++
+			model.vocabulary[feature] = true
+		}
+	}
+	return model
+}
+
+// Empty reports whether the model learned nothing, e.g. for a new user
+func (m *CategoryModel) Empty() bool {
+	return m.total == 0
+}
+
+// Suggest returns the most likely categories for an expense, most likely first
+// amount may be 0 when it isn't known yet. Categories are scored as
+// log P(category) + the sum of log P(feature | category), with add-one smoothing so a word never
+// seen with a category lowers its score instead of ruling it out, then turned into probabilities
+func (m *CategoryModel) Suggest(description string, amount float64, limit int) []*CategorySuggestion {
+	if m.Empty() {
+		return []*CategorySuggestion{}
+	}
+	features := categoryFeatures(description, amount)
+	vocabulary := float64(len(m.vocabulary))
+
+	// Step 1: The log score of every category
+	scores := make(map[string]float64, len(m.expenses))
+	best := math.Inf(-1)
+	for category, count := range m.expenses {
+		score := math.Log(float64(count) / float64(m.total))
+		for _, feature := range features {
+			seen := float64(m.words[category][feature])
+			score += math.Log((seen + 1) / (float64(m.featureTotals[category]) + vocabulary))
+		}
+		scores[category] = score
+		best = math.Max(best, score)
+	}
+
+	// Step 2: Normalize into probabilities; subtracting the best score keeps exp from underflowing
+	var sum float64
+	for category, score := range scores {
+		scores[category] = math.Exp(score - best)
+		sum += scores[category]
+	}
+	suggestions := make([]*CategorySuggestion, 0, len(scores))
+	for category, score := range scores {
+		suggestions = append(suggestions, &CategorySuggestion{
+			Category:   category,
+			Confidence: math.Round(score/sum*1000) / 1000,
+		})
+	}
+
+	// Step 3: Most likely first; ties alphabetically so answers are stable
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].Category < suggestions[j].Category
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// categoryFeatures returns what the model learns from: the lowercase words of the description
+// (letters and digits, at least two characters) and the order of magnitude of the amount, so
+// 4.50 looks like a coffee and 450 like rent even when the words say little
+func categoryFeatures(description string, amount float64) []string {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	features := make([]string, 0, len(words)+1)
+	for _, word := range words {
+		if len([]rune(word)) >= 2 {
+			features = append(features, word)
+		}
+	}
+	if amount > 0 {
+		// A prefix no word can have, since words never contain ":"
+		magnitude := min(max(int(math.Floor(math.Log10(amount)))+1, 0), 9)
+		features = append(features, "amount:"+strconv.Itoa(magnitude))
+	}
+	return features
+}
+
+// CategoryHistoryRepository provides the past expenses category suggestions learn from
+type CategoryHistoryRepository interface {
+	// CategoryHistory returns up to limit of the caller's most recent categorized expenses
+	// in the current book; only their descriptions, amounts and categories are filled in
+	CategoryHistory(ctx context.Context, limit int) ([]*Expense, error)
+}
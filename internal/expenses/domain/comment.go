@@ -0,0 +1,69 @@
+// Package domain contains the core business logic and entities
+// This file defines comments on expenses, so the members of a household can discuss a charge
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For input normalization
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring text in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// MaxCommentLength bounds the text of a comment
+const MaxCommentLength = 2000
+
+// MaxNotesLength bounds the notes of an expense
+const MaxNotesLength = 5000
+
+// ExpenseComment is a remark someone made on an expense, e.g. "Was this the birthday present?"
+// Comments can't be edited: the thread stays as it was written
+type ExpenseComment struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// ExpenseID is the expense the comment is on
+	ExpenseID uuid.UUID `json:"expense_id" gorm:"type:uuid;not null;index"`
+
+	// AuthorID is the user who wrote the comment; nil for the anonymous local user
+	AuthorID *uuid.UUID `json:"author_id,omitempty" gorm:"type:uuid;index"`
+
+	// Author is the author's name (or email) as it is now; it is looked up when comments are listed
+	Author string `json:"author,omitempty" gorm:"-"`
+
+	// Body is the text of the comment; it is stored encrypted when field encryption is enabled
+	Body string `json:"body" gorm:"not null;serializer:encrypted"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewExpenseComment creates a validated comment on an expense
+func NewExpenseComment(expenseID uuid.UUID, body string) (*ExpenseComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || utf8.RuneCountInString(body) > MaxCommentLength {
+		return nil, ErrInvalidComment
+	}
+	return &ExpenseComment{ID: uuid.New(), ExpenseID: expenseID, Body: body}, nil
+}
+
+// SetNotes replaces the notes of the expense ("" removes them)
+func (e *Expense) SetNotes(notes string) error {
+	notes = strings.TrimSpace(notes)
+	if utf8.RuneCountInString(notes) > MaxNotesLength {
+		return ErrInvalidNotes
+	}
+	e.Notes = notes
+	return nil
+}
+
+// CommentRepository defines how comments on expenses are stored
+// Comments can be read and written by the expense's owner and by the members of the groups
+// (households) the expense is attributed to; for anyone else the expense doesn't exist
+type CommentRepository interface {
+	// Create saves a comment by the caller, or returns ErrExpenseNotFound if they can't see the expense
+	Create(ctx context.Context, comment *ExpenseComment) error
+
+	// List returns the comments on an expense, oldest first, or returns ErrExpenseNotFound if
+	// the caller can't see the expense
+	List(ctx context.Context, expenseID uuid.UUID) ([]*ExpenseComment, error)
+}
@@ -0,0 +1,107 @@
+// Package domain contains the core business logic and entities
+// This file defines corporate cards: the mapping from a card's last four digits to its cardholder,
+// which routes each transaction of a card feed to the right user's staging queue
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For input normalization
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// CorporateCard maps a company card to the user who holds it
+// Card feeds only carry the last four digits, so they must be unique within a tenant
+type CorporateCard struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// TenantID is the tenant that issued the card (empty for the default tenant)
+	TenantID string `json:"-" gorm:"uniqueIndex:idx_corporate_card_last4"`
+
+	// Last4 is the last four digits of the card number
+	Last4 string `json:"last4" gorm:"size:4;not null;uniqueIndex:idx_corporate_card_last4"`
+
+	// UserID is the cardholder; the card's transactions land in their staging queue
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+
+	// Label describes the card (e.g. "Travel card")
+	Label string `json:"label,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewCorporateCard creates a validated card mapping
+func NewCorporateCard(last4 string, userID uuid.UUID, label string) (*CorporateCard, error) {
+	last4 = strings.TrimSpace(last4)
+	if !IsValidCardLast4(last4) || userID == uuid.Nil {
+		return nil, ErrInvalidCorporateCard
+	}
+	return &CorporateCard{
+		ID:     uuid.New(),
+		Last4:  last4,
+		UserID: userID,
+		Label:  strings.TrimSpace(label),
+	}, nil
+}
+
+// IsValidCardLast4 reports whether last4 is exactly four digits
+func IsValidCardLast4(last4 string) bool {
+	if len(last4) != 4 {
+		return false
+	}
+	for _, r := range last4 {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// CardTransaction is one line of a corporate card feed
+type CardTransaction struct {
+	ImportedTransaction
+
+	// CardLast4 identifies the card, and so the cardholder, the transaction belongs to
+	CardLast4 string `json:"card_last4" binding:"required,len=4,numeric"`
+}
+
+// NewCardStagedExpense stages a card transaction in the cardholder's queue
+// category is a first guess (e.g. from the MCC); the cardholder codes the transaction before approving it
+func NewCardStagedExpense(card *CorporateCard, tx *CardTransaction, category string) (*StagedExpense, error) {
+	description := strings.TrimSpace(tx.Description)
+	if description == "" || tx.Amount.Minor <= 0 || tx.Date.IsZero() {
+		return nil, ErrInvalidSubmission
+	}
+	userID := card.UserID
+	return &StagedExpense{
+		ID:              uuid.New(),
+		UserID:          &userID,
+		CardLast4:       card.Last4,
+		Merchant:        strings.TrimSpace(tx.Merchant),
+		MCC:             tx.MCC,
+		ExternalID:      strings.TrimSpace(tx.ExternalID),
+		Description:     description,
+		Amount:          tx.Amount,
+		Currency:        strings.ToUpper(strings.TrimSpace(tx.Currency)),
+		ConvertedAmount: tx.ConvertedAmount,
+		Category:        category,
+		Date:            tx.Date,
+		Status:          StagedPending,
+	}, nil
+}
+
+// CorporateCardRepository defines how card mappings are stored
+type CorporateCardRepository interface {
+	// Create saves a new card, or returns ErrCorporateCardExists when the tenant has a card with the same digits
+	Create(ctx context.Context, card *CorporateCard) error
+
+	// List returns the cards of a tenant, ordered by their digits
+	List(ctx context.Context, tenantID string) ([]*CorporateCard, error)
+
+	// GetByLast4 retrieves a tenant's card by its digits, or returns ErrCorporateCardNotFound
+	GetByLast4(ctx context.Context, tenantID, last4 string) (*CorporateCard, error)
+
+	// Delete removes a tenant's card, or returns ErrCorporateCardNotFound
+	Delete(ctx context.Context, tenantID, id string) error
+}
@@ -0,0 +1,100 @@
+// Package domain contains the core business logic and entities
+// This file defines currencies and the conversion rate that is locked in when an expense is entered
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"math"    // For rounding converted amounts
+	"strings" // For normalizing currency codes
+	"time"    // For historical rate lookups
+)
+
+// DefaultBaseCurrency is the home currency used when a deployment doesn't configure one
+const DefaultBaseCurrency = "EUR"
+
+// Where the locked exchange rate of an expense came from
+const (
+	// ConversionBase is used for expenses in the base currency (rate 1)
+	ConversionBase = "base"
+
+	// ConversionProvider is used for rates looked up from the exchange rate provider
+	ConversionProvider = "provider"
+
+	// ConversionRate is used for rates the user entered
+	ConversionRate = "rate"
+
+	// ConversionAmount is used for converted amounts the user entered (e.g. from a card statement)
+	ConversionAmount = "amount"
+)
+
+// NormalizeCurrency upper-cases a currency code and validates it is a 3-letter ISO 4217 code
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", ErrInvalidCurrency
+		}
+	}
+	return code, nil
+}
+
+// RoundAmount rounds an amount to whole cents
+// Converted amounts are always rounded so they match what shows up on a card statement
+func RoundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// ExchangeRateProvider looks up the rate to convert one unit of from into to on a given day
+// Implementations live in the infrastructure layer (static tables, ECB, exchangerate.host, ...)
+type ExchangeRateProvider interface {
+	// Rate returns how many units of to one unit of from was worth on the given date
+	// Returns ErrExchangeRateUnavailable if the provider has no rate for that pair/date
+	Rate(ctx context.Context, from, to string, on time.Time) (float64, error)
+}
+
+// NoExchangeRates is an ExchangeRateProvider that never knows any rate
+// With it, foreign currency expenses must be entered with an explicit rate or converted amount
+type NoExchangeRates struct{}
+
+// Rate implements ExchangeRateProvider
+func (NoExchangeRates) Rate(ctx context.Context, from, to string, on time.Time) (float64, error) {
+	return 0, ErrExchangeRateUnavailable
+}
+
+// LockConversion records the rate used to convert the expense into the base currency
+// The converted amount is stored with the expense and never recomputed from live rates,
+// so reports keep showing what was actually charged even when rates move later
+func (e *Expense) LockConversion(baseCurrency string, rate float64) error {
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return ErrInvalidExchangeRate
+	}
+	e.BaseCurrency = baseCurrency
+	e.ExchangeRate = rate
+	e.BaseAmount = e.Amount.Mul(rate)
+	return nil
+}
+
+// OverrideConvertedAmount locks the conversion to the amount that appeared on the user's statement
+// The effective rate is derived from it so the two numbers always stay consistent
+func (e *Expense) OverrideConvertedAmount(baseCurrency string, convertedAmount Money) error {
+	if convertedAmount.Minor <= 0 {
+		return ErrInvalidExchangeRate
+	}
+	e.BaseCurrency = baseCurrency
+	e.ExchangeRate = convertedAmount.Float64() / e.Amount.Float64()
+	e.BaseAmount = convertedAmount
+	return nil
+}
+
+// ReportingAmount returns the amount to use in reports, in the base currency
+// It is the locked converted amount; expenses entered in the base currency report their own amount
+// Refunds report a negative amount
+func (e *Expense) ReportingAmount() Money {
+	if !e.BaseAmount.IsZero() {
+		return e.BaseAmount
+	}
+	return e.Amount
+}
@@ -0,0 +1,51 @@
+// Package domain contains the core business logic and entities
+// This file defines expense cursors: opaque positions in the order expenses were recorded,
+// used by polling integrations to fetch only what is new since their last poll
+package domain
+
+import (
+	"encoding/base64" // For making cursors URL-safe and opaque
+	"strings"         // For splitting the cursor parts
+	"time"            // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// ExpenseCursor points just after an expense in creation order
+// The ID breaks ties between expenses recorded in the same instant
+type ExpenseCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// CursorAfter returns the cursor positioned at expense
+func CursorAfter(expense *Expense) ExpenseCursor {
+	return ExpenseCursor{CreatedAt: expense.CreatedAt, ID: expense.ID}
+}
+
+// String encodes the cursor for clients; they should treat it as opaque
+func (c ExpenseCursor) String() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseExpenseCursor decodes a cursor produced by ExpenseCursor.String
+func ParseExpenseCursor(value string) (ExpenseCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return ExpenseCursor{}, ErrInvalidCursor
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return ExpenseCursor{}, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return ExpenseCursor{}, ErrInvalidCursor
+	}
+	expenseID, err := uuid.Parse(id)
+	if err != nil {
+		return ExpenseCursor{}, ErrInvalidCursor
+	}
+	return ExpenseCursor{CreatedAt: createdAt, ID: expenseID}, nil
+}
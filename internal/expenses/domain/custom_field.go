@@ -0,0 +1,195 @@
+// Package domain contains the core business logic and entities
+// This file defines custom fields: the user's own fields on expenses (e.g. project code or client
+// name), declared in a schema and stored in the expense's metadata
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"regexp"       // For checking field keys
+	"strconv"      // For parsing filter values
+	"strings"      // For input normalization
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring text in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Custom field types
+const (
+	CustomFieldText    = "text"
+	CustomFieldNumber  = "number"
+	CustomFieldBoolean = "boolean"
+	CustomFieldDate    = "date"
+)
+
+// MaxCustomFields is how many custom fields one user may declare
+const MaxCustomFields = 50
+
+// MaxCustomFieldLabelLength bounds the label of a custom field
+const MaxCustomFieldLabelLength = 100
+
+// MaxCustomTextLength bounds the value of a text field
+const MaxCustomTextLength = 500
+
+// customFieldKey is what a key looks like: it is used in JSON and as ?meta.<key>=, so it is
+// kept to lowercase letters, digits and underscores
+var customFieldKey = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// Metadata are the values of an expense's custom fields, by field key
+type Metadata map[string]interface{}
+
+// CustomField is a field a user added to their expenses
+// The key and type are fixed once declared, so stored values always match; the label can change
+type CustomField struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user who declared the field; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_custom_field_key"`
+
+	// Key names the field in the expense's metadata and in filters (e.g. "project")
+	Key string `json:"key" gorm:"size:40;not null;uniqueIndex:idx_custom_field_key"`
+
+	// Label is how clients show the field (e.g. "Project code")
+	Label string `json:"label" gorm:"not null"`
+
+	// Type is one of the CustomField* types
+	Type string `json:"type" gorm:"size:16;not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewCustomField creates a validated custom field
+// The label defaults to the key
+func NewCustomField(key, label, fieldType string) (*CustomField, error) {
+	field := &CustomField{
+		ID:   uuid.New(),
+		Key:  strings.ToLower(strings.TrimSpace(key)),
+		Type: strings.ToLower(strings.TrimSpace(fieldType)),
+	}
+	if !customFieldKey.MatchString(field.Key) {
+		return nil, ErrInvalidCustomField
+	}
+	switch field.Type {
+	case CustomFieldText, CustomFieldNumber, CustomFieldBoolean, CustomFieldDate:
+	default:
+		return nil, ErrInvalidCustomField
+	}
+	if label == "" {
+		label = field.Key
+	}
+	if err := field.Relabel(label); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// Relabel changes how clients show the field
+func (f *CustomField) Relabel(label string) error {
+	label = strings.TrimSpace(label)
+	if label == "" || utf8.RuneCountInString(label) > MaxCustomFieldLabelLength {
+		return ErrInvalidCustomField
+	}
+	f.Label = label
+	return nil
+}
+
+// Value checks a value of the field as it came from JSON and returns it in stored form:
+// trimmed text, a number, a boolean, or a date as YYYY-MM-DD
+func (f *CustomField) Value(value interface{}) (interface{}, error) {
+	switch f.Type {
+	case CustomFieldText:
+		if text, ok := value.(string); ok {
+			text = strings.TrimSpace(text)
+			if text != "" && utf8.RuneCountInString(text) <= MaxCustomTextLength {
+				return text, nil
+			}
+		}
+	case CustomFieldNumber:
+		if number, ok := value.(float64); ok {
+			return number, nil
+		}
+	case CustomFieldBoolean:
+		if flag, ok := value.(bool); ok {
+			return flag, nil
+		}
+	case CustomFieldDate:
+		if text, ok := value.(string); ok {
+			if day, err := time.Parse("2006-01-02", strings.TrimSpace(text)); err == nil {
+				return day.Format("2006-01-02"), nil
+			}
+		}
+	}
+	return nil, ErrInvalidMetadata
+}
+
+// ParseValue reads a value of the field from a query parameter, e.g. ?meta.billable=true
+func (f *CustomField) ParseValue(raw string) (interface{}, error) {
+	switch f.Type {
+	case CustomFieldNumber:
+		number, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, ErrInvalidMetadata
+		}
+		return number, nil
+	case CustomFieldBoolean:
+		flag, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, ErrInvalidMetadata
+		}
+		return flag, nil
+	}
+	return f.Value(raw)
+}
+
+// ApplyMetadata sets custom field values on metadata, checked against the user's fields
+// A nil value removes the field, so {"client": null} clears the client; fields not in
+// values are left as they are. Keys without a declared field fail with ErrUnknownCustomField
+func ApplyMetadata(metadata Metadata, values map[string]interface{}, fields []*CustomField) (Metadata, error) {
+	byKey := make(map[string]*CustomField, len(fields))
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+
+	result := make(Metadata, len(metadata)+len(values))
+	for key, value := range metadata {
+		result[key] = value
+	}
+	for key, value := range values {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, ErrUnknownCustomField
+		}
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		stored, err := field.Value(value)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = stored
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+// CustomFieldRepository defines how the caller's custom fields are stored
+type CustomFieldRepository interface {
+	// Create saves a new custom field owned by the caller
+	Create(ctx context.Context, field *CustomField) error
+
+	// List returns the caller's custom fields by key
+	List(ctx context.Context) ([]*CustomField, error)
+
+	// GetByKey retrieves one of the caller's custom fields, or returns ErrCustomFieldNotFound
+	GetByKey(ctx context.Context, key string) (*CustomField, error)
+
+	// UpdateLabel saves the label of one of the caller's custom fields, or returns ErrCustomFieldNotFound
+	UpdateLabel(ctx context.Context, field *CustomField) error
+
+	// Delete removes one of the caller's custom fields and its values from all of the caller's
+	// expenses, or returns ErrCustomFieldNotFound
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,77 @@
+// Package domain contains the core business logic and entities
+// This file defines the sample data new tenants can explore the app with before using it for real
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For handling dates and times
+)
+
+// DemoMonths is how many months of sample data are generated, the current one included
+// Three months are enough for comparisons, budgets and forecasts to show something
+const DemoMonths = 3
+
+// demoExpense is a recurring expense of the sample data, with its category in canonical form
+type demoExpense struct {
+	description string
+	category    string
+	amount      float64
+
+	// days are the days of the month it happens on
+	days []int
+}
+
+// demoExpenses is what a plausible household spends in a month
+var demoExpenses = []demoExpense{
+	{"Rent", "Housing", 950, []int{1}},
+	{"Electricity bill", "Utilities", 64.20, []int{3}},
+	{"Internet", "Utilities", 39.99, []int{5}},
+	{"Supermarket", "Food", 58.40, []int{2, 9, 16, 23}},
+	{"Bakery", "Food", 6.80, []int{4, 11, 18, 25}},
+	{"Pizza night", "Food", 32.50, []int{13}},
+	{"Uber ride", "Transportation", 14.30, []int{7, 21}},
+	{"Monthly transit pass", "Transportation", 49, []int{1}},
+	{"Pharmacy", "Health", 18.75, []int{12}},
+	{"Netflix", "Entertainment", 13.99, []int{15}},
+	{"Cinema", "Entertainment", 24, []int{20}},
+	{"Amazon order", "Shopping", 42.90, []int{17}},
+	{"Account fee", "Bank Fees", 4.90, []int{28}},
+}
+
+// DemoExpenses returns the sample expenses up to now, with categories in the locale's language
+// Amounts vary a little from month to month (up to ±10%) so charts don't look flat; the variation
+// is fixed rather than random, so the sample data is the same every time
+func DemoExpenses(locale string, now time.Time) ([]*Expense, error) {
+	var expenses []*Expense
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1-DemoMonths, 0)
+	for month := 0; month < DemoMonths; month++ {
+		start := first.AddDate(0, month, 0)
+		for i, sample := range demoExpenses {
+			for _, day := range sample.days {
+				date := start.AddDate(0, 0, day-1)
+				if date.After(now) {
+					continue
+				}
+				variation := 1 + float64((i+month*3)%5-2)*0.05
+				amount := NewMoney(sample.amount*variation, "")
+				expense, err := NewExpense(sample.description, amount, LocalizeCategory(locale, sample.category), date)
+				if err != nil {
+					return nil, err
+				}
+				expense.Demo = true
+				expenses = append(expenses, expense)
+			}
+		}
+	}
+	return expenses, nil
+}
+
+// DemoDataRepository finds and removes the sample data
+// It works across every user of the deployment: the sample data belongs to the tenant as a whole
+type DemoDataRepository interface {
+	// CountDemoExpenses returns how many sample expenses there are
+	CountDemoExpenses(ctx context.Context) (int64, error)
+
+	// DeleteDemoExpenses removes every sample expense and what hangs off it, and returns how many there were
+	DeleteDemoExpenses(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,134 @@
+// Package domain contains the core business logic and entities
+// This file defines the organization's chargeback dimensions: the cost centers and departments
+// expenses are charged to, so company reports can bill spending back to the units that caused it
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For input normalization
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring names in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Dimension kinds
+const (
+	// DimensionCostCenter is a cost center (e.g. "CC-4100 Marketing")
+	DimensionCostCenter = "cost_center"
+
+	// DimensionDepartment is a department (e.g. "SALES")
+	DimensionDepartment = "department"
+)
+
+// Dimension limits
+const (
+	// MaxDimensionCodeLength bounds codes, which are stored on every expense
+	MaxDimensionCodeLength = 32
+
+	// MaxDimensionNameLength bounds the descriptive name
+	MaxDimensionNameLength = 100
+)
+
+// Dimension is one cost center or department of an organization
+// Expenses carry its code; codes are unique per tenant and kind
+type Dimension struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// TenantID is the organization the dimension belongs to (empty for the default tenant)
+	TenantID string `json:"-" gorm:"uniqueIndex:idx_dimension_code"`
+
+	// Kind is DimensionCostCenter or DimensionDepartment
+	Kind string `json:"kind" gorm:"size:16;not null;uniqueIndex:idx_dimension_code"`
+
+	// Code is what expenses carry (upper case, e.g. "CC-4100")
+	Code string `json:"code" gorm:"size:32;not null;uniqueIndex:idx_dimension_code"`
+
+	// Name describes it (e.g. "Marketing")
+	Name string `json:"name" gorm:"not null"`
+
+	// ArchivedAt is when the dimension was retired (nil while in use)
+	// Archived dimensions can't be charged any more, but stay in the reports of their expenses
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewDimension creates a validated cost center or department
+// The code is upper-cased, so "cc-4100" and "CC-4100" are the same code
+func NewDimension(kind, code, name string) (*Dimension, error) {
+	code = NormalizeDimensionCode(code)
+	name = strings.TrimSpace(name)
+	if !IsValidDimensionKind(kind) || !isValidDimensionCode(code) {
+		return nil, ErrInvalidDimension
+	}
+	if name == "" || utf8.RuneCountInString(name) > MaxDimensionNameLength {
+		return nil, ErrInvalidDimension
+	}
+	return &Dimension{
+		ID:   uuid.New(),
+		Kind: kind,
+		Code: code,
+		Name: name,
+	}, nil
+}
+
+// Archived reports whether the dimension was retired
+func (d *Dimension) Archived() bool {
+	return d.ArchivedAt != nil
+}
+
+// IsValidDimensionKind reports whether kind is one of the dimension kinds
+func IsValidDimensionKind(kind string) bool {
+	return kind == DimensionCostCenter || kind == DimensionDepartment
+}
+
+// NormalizeDimensionCode trims and upper-cases a code the way it is stored
+func NormalizeDimensionCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// isValidDimensionCode accepts letters, digits, '-', '_' and '.' up to MaxDimensionCodeLength
+func isValidDimensionCode(code string) bool {
+	if code == "" || len(code) > MaxDimensionCodeLength {
+		return false
+	}
+	for _, r := range code {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// DimensionTotal sums the expenses charged to one dimension in one category
+type DimensionTotal struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Count    int64  `json:"count"`
+	Amount   Money  `json:"amount"`
+}
+
+// DimensionRepository defines how cost centers and departments are stored
+type DimensionRepository interface {
+	// List returns a tenant's dimensions of a kind (every kind when kind is empty), by kind and code
+	// Archived dimensions are only included with includeArchived
+	List(ctx context.Context, tenantID, kind string, includeArchived bool) ([]*Dimension, error)
+
+	// Create saves a new dimension, or returns ErrDimensionExists when the code is taken
+	Create(ctx context.Context, dimension *Dimension) error
+
+	// GetByCode retrieves a tenant's dimension by kind and code, or returns ErrDimensionNotFound
+	GetByCode(ctx context.Context, tenantID, kind, code string) (*Dimension, error)
+
+	// Archive retires one of a tenant's dimensions, or returns ErrDimensionNotFound
+	// when it doesn't exist or is archived already
+	Archive(ctx context.Context, tenantID, id string, at time.Time) error
+
+	// Totals sums the expenses dated in [from, to) charged to the tenant's dimensions of a kind,
+	// per dimension and category, in the home currency
+	Totals(ctx context.Context, tenantID, kind string, from, to time.Time) ([]*DimensionTotal, error)
+}
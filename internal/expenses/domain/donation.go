@@ -0,0 +1,87 @@
+// Package domain contains the core business logic and entities
+// This file defines donations: expenses marked as gifts to a charity, with the recipient
+// details a tax return asks for
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For input normalization
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring text in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// MaxDonationFieldLength bounds the free-text recipient details
+const MaxDonationFieldLength = 200
+
+// Donation marks an expense as a charitable gift and records who received it
+// An expense is a donation at most once; the amount and date are the expense's own
+type Donation struct {
+	ExpenseID uuid.UUID `json:"expense_id" gorm:"type:uuid;primaryKey"`
+
+	// RecipientName is the charity as it appears on its receipt (e.g. "Red Cross")
+	RecipientName string `json:"recipient_name" gorm:"not null;index"`
+
+	// RecipientTaxID is the charity's registration or tax number (e.g. an EIN), if known
+	RecipientTaxID string `json:"recipient_tax_id,omitempty"`
+
+	// RecipientAddress is the charity's address, which some tax forms ask for
+	RecipientAddress string `json:"recipient_address,omitempty"`
+
+	// ReceiptNumber is the number of the donation receipt the charity issued
+	ReceiptNumber string `json:"receipt_number,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewDonation creates a validated donation for an expense
+// The recipient name is required; every field is trimmed and limited to MaxDonationFieldLength
+func NewDonation(expenseID uuid.UUID, recipientName, recipientTaxID, recipientAddress, receiptNumber string) (*Donation, error) {
+	donation := &Donation{
+		ExpenseID:        expenseID,
+		RecipientName:    strings.TrimSpace(recipientName),
+		RecipientTaxID:   strings.TrimSpace(recipientTaxID),
+		RecipientAddress: strings.TrimSpace(recipientAddress),
+		ReceiptNumber:    strings.TrimSpace(receiptNumber),
+	}
+	if donation.RecipientName == "" {
+		return nil, ErrInvalidDonation
+	}
+	for _, field := range []string{donation.RecipientName, donation.RecipientTaxID, donation.RecipientAddress, donation.ReceiptNumber} {
+		if utf8.RuneCountInString(field) > MaxDonationFieldLength {
+			return nil, ErrInvalidDonation
+		}
+	}
+	return donation, nil
+}
+
+// GivingLine is one donation with the expense it was made with, for the giving report
+type GivingLine struct {
+	*Donation
+
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+
+	// Amount is the reporting (base currency) amount of the expense
+	Amount Money `json:"amount"`
+
+	// Attachments is how many files are attached to the expense; 0 means no receipt is on file
+	Attachments int `json:"attachments"`
+}
+
+// DonationRepository defines how donations are stored
+type DonationRepository interface {
+	// Save marks the expense as a donation, or replaces its recipient details
+	Save(ctx context.Context, donation *Donation) error
+
+	// Get returns the donation of an expense, or returns ErrDonationNotFound
+	Get(ctx context.Context, expenseID uuid.UUID) (*Donation, error)
+
+	// Delete unmarks the expense, or returns ErrDonationNotFound when it isn't a donation
+	Delete(ctx context.Context, expenseID uuid.UUID) error
+
+	// ListGiving returns the caller's donations dated in [from, to), oldest first
+	ListGiving(ctx context.Context, from, to time.Time) ([]*GivingLine, error)
+}
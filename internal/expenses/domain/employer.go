@@ -0,0 +1,129 @@
+// Package domain contains the core business logic and entities
+// This file defines employers: who pays back the user's reimbursable expenses, and the
+// reimbursements received from them
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For input normalization
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring text in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// MaxEmployerNameLength bounds the name of an employer
+const MaxEmployerNameLength = 100
+
+// MaxReimbursementReferenceLength bounds the reference of a reimbursement (e.g. a payslip line)
+const MaxReimbursementReferenceLength = 200
+
+// Employer is someone who pays back the user's reimbursable expenses, e.g. their company or a client
+// Expenses marked reimbursable are charged to an employer; reimbursements received from it
+// bring down what it still owes
+type Employer struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user who is reimbursed; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// Name is how the user calls the employer (e.g. "Acme Corp")
+	Name string `json:"name" gorm:"not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewEmployer creates a validated employer
+func NewEmployer(name string) (*Employer, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxEmployerNameLength {
+		return nil, ErrInvalidEmployer
+	}
+	return &Employer{ID: uuid.New(), Name: name}, nil
+}
+
+// ReimbursementIncome is money an employer paid the user back for reimbursable expenses
+// It isn't linked to single expenses: employers usually pay several claims at once, so it
+// reduces the employer's outstanding balance as a whole
+type ReimbursementIncome struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user who was paid; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// EmployerID is the employer who paid
+	EmployerID uuid.UUID `json:"employer_id" gorm:"type:uuid;not null;index"`
+
+	// Amount is what was received, in the base currency like the expenses' reporting amounts
+	Amount Money `json:"amount" gorm:"type:numeric(19,2);not null"`
+
+	// ReceivedOn is the day the money arrived
+	ReceivedOn time.Time `json:"received_on" gorm:"type:date;not null"`
+
+	// Reference identifies the payment (e.g. "Payroll March"), if the user wants to keep one
+	Reference string `json:"reference,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewReimbursementIncome creates a validated reimbursement from an employer
+func NewReimbursementIncome(employerID uuid.UUID, amount Money, receivedOn time.Time, reference string) (*ReimbursementIncome, error) {
+	reference = strings.TrimSpace(reference)
+	if amount.Minor <= 0 || receivedOn.IsZero() || utf8.RuneCountInString(reference) > MaxReimbursementReferenceLength {
+		return nil, ErrInvalidReimbursement
+	}
+	return &ReimbursementIncome{
+		ID:         uuid.New(),
+		EmployerID: employerID,
+		Amount:     amount,
+		ReceivedOn: dayOf(receivedOn),
+		Reference:  reference,
+	}, nil
+}
+
+// EmployerBalance is what an employer owes the user for reimbursable expenses
+// Amounts are in the base currency
+type EmployerBalance struct {
+	Employer *Employer `json:"employer"`
+
+	// Expenses counts the reimbursable expenses charged to the employer
+	Expenses int `json:"expenses"`
+
+	// Claimed is the total of those expenses
+	Claimed Money `json:"claimed"`
+
+	// Reimbursed is the total of the reimbursements received from the employer
+	Reimbursed Money `json:"reimbursed"`
+
+	// Outstanding is Claimed minus Reimbursed; it is negative when the employer paid too much
+	Outstanding Money `json:"outstanding"`
+}
+
+// EmployerRepository defines the data access operations for employers and their reimbursements
+// Every operation is limited to the employers of the caller in ctx
+type EmployerRepository interface {
+	// Create saves a new employer owned by the caller
+	Create(ctx context.Context, employer *Employer) error
+
+	// GetByID retrieves one of the caller's employers, or returns ErrEmployerNotFound
+	GetByID(ctx context.Context, id string) (*Employer, error)
+
+	// List returns the caller's employers by name
+	List(ctx context.Context) ([]*Employer, error)
+
+	// Delete removes one of the caller's employers, or returns ErrEmployerInUse while expenses
+	// or reimbursements refer to it
+	Delete(ctx context.Context, id string) error
+
+	// CreateIncome saves a reimbursement received from one of the caller's employers
+	CreateIncome(ctx context.Context, income *ReimbursementIncome) error
+
+	// ListIncomes returns the reimbursements received from an employer, newest first
+	ListIncomes(ctx context.Context, employerID uuid.UUID) ([]*ReimbursementIncome, error)
+
+	// DeleteIncome removes a reimbursement of an employer, or returns ErrReimbursementNotFound
+	DeleteIncome(ctx context.Context, employerID uuid.UUID, id string) error
+
+	// Balances returns the balance of each of the caller's employers, by name
+	Balances(ctx context.Context) ([]*EmployerBalance, error)
+}
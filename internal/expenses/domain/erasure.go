@@ -0,0 +1,45 @@
+// Package domain contains the core business logic and entities
+// This file defines account erasure: a user asks for their account to be deleted, and once
+// a grace period has passed everything they stored is removed for good
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For handling dates and times
+)
+
+// DefaultErasureGracePeriod is how long a user has to change their mind after asking for erasure
+const DefaultErasureGracePeriod = 30 * 24 * time.Hour
+
+// ErasedUser replaces an erased user's ID and email wherever the audit log mentions them
+// The entries stay, so the log still shows what happened, but no longer to whom
+const ErasedUser = "erased-user"
+
+// Erasure is what erasing one account removed
+type Erasure struct {
+	// Deleted counts the removed rows by kind (e.g. "expenses", "api_keys")
+	Deleted map[string]int64 `json:"deleted"`
+
+	// AnonymizedAuditEntries is how many audit entries no longer name the user
+	AnonymizedAuditEntries int64 `json:"anonymized_audit_entries"`
+
+	// Attachments are the removed attachments, whose content still has to be released
+	Attachments []*Attachment `json:"-"`
+
+	// ExportFiles are the storage keys of the user's finished exports, which still have to be deleted
+	ExportFiles []string `json:"-"`
+
+	// PendingReceiptFiles are the storage keys of the receipts left in the user's inbox
+	PendingReceiptFiles []string `json:"-"`
+}
+
+// ErasureRepository defines how accounts due for erasure are found and erased
+type ErasureRepository interface {
+	// ListDue returns the users whose erasure was scheduled for now or earlier
+	ListDue(ctx context.Context, now time.Time) ([]*User, error)
+
+	// Erase removes the user with their expenses, attachments, tokens, API keys and everything else
+	// they own, and replaces their ID and email in the audit log with ErasedUser, all in one transaction
+	// File content isn't touched; the caller releases it afterwards
+	Erase(ctx context.Context, user *User) (*Erasure, error)
+}
@@ -31,4 +31,575 @@ var (
 	// ErrExpenseExists occurs when trying to create an expense that already exists
 	// This prevents duplicate expenses (though not currently used in this implementation)
 	ErrExpenseExists = errors.New("expense already exists")
+
+	// ErrInvalidAttachment occurs when an uploaded file is empty, unnamed or too large
+	ErrInvalidAttachment = errors.New("invalid attachment: file must be non-empty and within the size limit")
+
+	// ErrAttachmentNotFound occurs when trying to access an attachment that doesn't exist
+	ErrAttachmentNotFound = errors.New("attachment not found")
+
+	// ErrInvalidSearchQuery occurs when a search is requested without any search text
+	ErrInvalidSearchQuery = errors.New("invalid search query: cannot be empty")
+
+	// ErrInvalidSearchScope occurs when the search scope is not one of expenses, receipts or all
+	ErrInvalidSearchScope = errors.New("invalid search scope: must be expenses, receipts or all")
+
+	// ErrInvalidMCC occurs when a merchant category code is not exactly 4 digits
+	ErrInvalidMCC = errors.New("invalid MCC: must be exactly 4 digits")
+
+	// ErrMCCMappingNotFound occurs when no category is configured for a merchant category code
+	ErrMCCMappingNotFound = errors.New("MCC mapping not found")
+
+	// ErrInvalidRule occurs when a categorization rule has nothing to match on, or a bad pattern or condition
+	ErrInvalidRule = errors.New("invalid rule: needs a pattern or a condition, a pattern of at most 200 characters (regexes must compile and stay small), a valid amount range and a known matcher and source")
+
+	// ErrInvalidRuleMode occurs when the rule mode is neither first nor all
+	ErrInvalidRuleMode = errors.New("invalid rule mode: must be first or all")
+
+	// ErrRuleNotFound occurs when trying to access a categorization rule that doesn't exist
+	ErrRuleNotFound = errors.New("rule not found")
+
+	// ErrInvalidNormalizationRule occurs when a replacement rule has nothing to find
+	ErrInvalidNormalizationRule = errors.New("invalid normalization rule: find text cannot be empty")
+
+	// ErrNormalizationRuleNotFound occurs when trying to access a replacement rule that doesn't exist
+	ErrNormalizationRuleNotFound = errors.New("normalization rule not found")
+
+	// ErrUnknownNormalizationStep occurs when the pipeline configuration names a step that doesn't exist
+	ErrUnknownNormalizationStep = errors.New("unknown normalization step")
+
+	// ErrInvalidCurrency occurs when a currency is not a 3-letter ISO 4217 code
+	ErrInvalidCurrency = errors.New("invalid currency: must be a 3-letter ISO 4217 code")
+
+	// ErrInvalidExchangeRate occurs when a conversion rate or converted amount is not positive
+	ErrInvalidExchangeRate = errors.New("invalid exchange rate: must be greater than 0")
+
+	// ErrExchangeRateUnavailable occurs when no rate is known for a foreign currency expense
+	// The client can fix this by sending an explicit exchange_rate or converted_amount
+	ErrExchangeRateUnavailable = errors.New("exchange rate unavailable: provide exchange_rate or converted_amount")
+
+	// ErrInvalidAccount occurs when an account has no name
+	ErrInvalidAccount = errors.New("invalid account: name cannot be empty")
+
+	// ErrInvalidAccountType occurs when an account type is not checking, credit_card or cash
+	ErrInvalidAccountType = errors.New("invalid account type: must be checking, credit_card or cash")
+
+	// ErrAccountNotFound occurs when trying to access an account that doesn't exist
+	ErrAccountNotFound = errors.New("account not found")
+
+	// ErrInvalidTransfer occurs when money would be moved from an account to itself
+	ErrInvalidTransfer = errors.New("invalid transfer: source and destination must differ")
+
+	// ErrAccountInUse occurs when deleting an account that expenses or transfers still refer to
+	ErrAccountInUse = errors.New("account has expenses or transfers; move or delete them first")
+
+	// ErrInvalidBudget occurs when a budget amount is not positive
+	ErrInvalidBudget = errors.New("invalid budget: amount must be greater than 0")
+
+	// ErrBudgetNotFound occurs when trying to access a budget that doesn't exist
+	ErrBudgetNotFound = errors.New("budget not found")
+
+	// ErrBudgetExists occurs when creating a second budget for the same category and month
+	ErrBudgetExists = errors.New("budget already exists for this category and month")
+
+	// ErrInvalidMonth occurs when a month is not in YYYY-MM format
+	ErrInvalidMonth = errors.New("invalid month: must be in YYYY-MM format")
+
+	// ErrInvalidPlannedExpense occurs when a planned expense is incomplete or falls outside the simulated month
+	ErrInvalidPlannedExpense = errors.New("invalid planned expense: needs a category, a positive amount and a date in the simulated month")
+
+	// ErrInvalidPeriod occurs when a reporting period is not a year, month, day or day range
+	ErrInvalidPeriod = errors.New("invalid period: use YYYY, YYYY-MM, YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD")
+
+	// ErrCategoryExists occurs when creating a category whose name is already taken
+	ErrCategoryExists = errors.New("category already exists")
+
+	// ErrCategoryNotFound occurs when trying to access a category that doesn't exist
+	ErrCategoryNotFound = errors.New("category not found")
+
+	// ErrInvalidCategoryParent occurs when a category's parent doesn't exist, is the category itself
+	// or one of its subcategories, or would nest categories deeper than MaxCategoryDepth
+	ErrInvalidCategoryParent = errors.New("invalid parent category")
+
+	// ErrRollupUnavailable occurs when a report is asked to roll up subcategories without a category list
+	ErrRollupUnavailable = errors.New("category roll-up is not available")
+
+	// ErrResultTooLarge occurs when an unpaginated list would exceed the in-memory result limit
+	// The client should paginate with limit/offset (or accept a streamed response)
+	ErrResultTooLarge = errors.New("result too large: use limit and offset to paginate")
+
+	// ErrInvalidIDVersion occurs when the configured expense ID version is not v4 or v7
+	ErrInvalidIDVersion = errors.New("invalid ID version: use v4 or v7")
+
+	// ErrExplainUnavailable occurs when the storage backend can't explain its queries
+	ErrExplainUnavailable = errors.New("query plans are not available for this storage backend")
+
+	// ErrForbidden occurs when the caller lacks the role an operation requires
+	ErrForbidden = errors.New("forbidden: insufficient permissions")
+
+	// ErrInvalidFlag occurs when a flag name is not one of the known review flags
+	ErrInvalidFlag = errors.New("invalid flag: use needs_receipt, question or personal")
+
+	// ErrFlagNotFound occurs when clearing a flag that isn't set on the expense
+	ErrFlagNotFound = errors.New("flag not set on this expense")
+
+	// ErrPendingReceiptNotFound occurs when a receipt isn't (or is no longer) in the inbox
+	ErrPendingReceiptNotFound = errors.New("pending receipt not found")
+
+	// ErrTooManyFiles occurs when a bulk upload has more files than MaxReceiptBatch
+	ErrTooManyFiles = errors.New("too many files in one upload")
+
+	// ErrInvalidStatement occurs when a statement period is invalid or has lines outside of it
+	ErrInvalidStatement = errors.New("invalid statement: the period must end after it starts and contain every line")
+
+	// ErrReconciliationNotFound occurs when trying to access a reconciliation session that doesn't exist
+	ErrReconciliationNotFound = errors.New("reconciliation session not found")
+
+	// ErrReconciliationClosed occurs when changing a reconciliation session that was already closed
+	ErrReconciliationClosed = errors.New("reconciliation session is closed")
+
+	// ErrStatementLineNotFound occurs when a statement line doesn't belong to the reconciliation session
+	ErrStatementLineNotFound = errors.New("statement line not found")
+
+	// ErrExpenseAlreadyMatched occurs when matching an expense that another line (or an earlier session) already accounts for
+	ErrExpenseAlreadyMatched = errors.New("expense is already matched or reconciled")
+
+	// ErrInvalidTrip occurs when a trip has no name, or its dates or legs don't fit together
+	ErrInvalidTrip = errors.New("invalid trip: needs a name, and legs with a country code inside the trip dates that don't overlap")
+
+	// ErrTripNotFound occurs when trying to access a trip that doesn't exist
+	ErrTripNotFound = errors.New("trip not found")
+
+	// ErrInvalidGroup occurs when a group or member has no name or a member's share is negative
+	ErrInvalidGroup = errors.New("invalid group: groups and members need a name and shares can't be negative")
+
+	// ErrGroupNotFound occurs when trying to access a group that doesn't exist
+	ErrGroupNotFound = errors.New("group not found")
+
+	// ErrGroupMemberNotFound occurs when a member doesn't exist or belongs to another group
+	ErrGroupMemberNotFound = errors.New("group member not found")
+
+	// ErrInvalidPublicForm occurs when a public form has no name or no positive amount cap
+	ErrInvalidPublicForm = errors.New("invalid public form: needs a name, a category and a positive maximum amount")
+
+	// ErrPublicFormNotFound occurs when a public form doesn't exist or a form token is unknown
+	ErrPublicFormNotFound = errors.New("public form not found")
+
+	// ErrPublicFormClosed occurs when submitting through a form that was revoked or has expired
+	ErrPublicFormClosed = errors.New("this form no longer accepts submissions")
+
+	// ErrInvalidSubmission occurs when a form submission lacks a description, a positive amount or a date
+	ErrInvalidSubmission = errors.New("invalid submission: needs a description, a positive amount and a date")
+
+	// ErrAmountOverFormLimit occurs when a submission is above the form's maximum amount
+	ErrAmountOverFormLimit = errors.New("amount is above the limit of this form")
+
+	// ErrSubmissionNotFound occurs when a staged submission doesn't exist
+	ErrSubmissionNotFound = errors.New("submission not found")
+
+	// ErrSubmissionReviewed occurs when approving or rejecting a submission that was already reviewed
+	ErrSubmissionReviewed = errors.New("submission was already reviewed")
+
+	// ErrInvalidCursor occurs when a polling cursor wasn't produced by this API
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrInvalidRecurringExpense occurs when a recurring expense lacks a description, amount, category or valid schedule
+	ErrInvalidRecurringExpense = errors.New("invalid recurring expense: needs a description, a positive amount, a category, a cadence (weekly, monthly, yearly) and a start date; reminders can be 0-60 days before")
+
+	// ErrRecurringExpenseNotFound occurs when trying to access a recurring expense that doesn't exist
+	ErrRecurringExpenseNotFound = errors.New("recurring expense not found")
+
+	// ErrInvalidTimezone occurs when a timezone isn't a known IANA name such as "Europe/Berlin"
+	ErrInvalidTimezone = errors.New("invalid timezone: use an IANA name such as Europe/Berlin")
+
+	// ErrInvalidExport occurs when an export asks for an unsupported format or invalid filters
+	ErrInvalidExport = errors.New("invalid export: format must be csv and filters must be valid")
+
+	// ErrExportNotFound occurs when an export job doesn't exist or belongs to someone else
+	ErrExportNotFound = errors.New("export not found")
+
+	// ErrExportNotReady occurs when downloading an export that hasn't finished or has expired
+	ErrExportNotReady = errors.New("export is not available for download")
+
+	// ErrExportQueueFull occurs when too many exports are waiting to run
+	ErrExportQueueFull = errors.New("too many exports are waiting; try again later")
+
+	// ErrExportTooLarge occurs when an export would have more rows than the configured maximum
+	ErrExportTooLarge = errors.New("export too large: narrow the filters to export fewer rows")
+
+	// ErrInvalidUser occurs when registering with an email that isn't a valid address
+	ErrInvalidUser = errors.New("invalid user: email must be a valid address")
+
+	// ErrWeakPassword occurs when a password is shorter than MinPasswordLength
+	ErrWeakPassword = errors.New("password must be at least 8 characters long")
+
+	// ErrEmailTaken occurs when registering with an email that already has an account
+	ErrEmailTaken = errors.New("an account with this email already exists")
+
+	// ErrUserNotFound occurs when a user doesn't exist
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrInvalidCredentials occurs when a login's email or password is wrong
+	// It deliberately doesn't say which, so logins can't be used to find registered emails
+	ErrInvalidCredentials = errors.New("invalid email or password")
+
+	// ErrInvalidBranding occurs when branding has no name, overlong texts or a logo URL that isn't http(s)
+	ErrInvalidBranding = errors.New("invalid branding: name is required, texts must be at most 300 characters and the logo an http(s) URL")
+
+	// ErrBrandingNotFound occurs when a tenant hasn't set its own branding
+	ErrBrandingNotFound = errors.New("branding not found")
+
+	// ErrInvalidRefreshToken occurs when a refresh token is unknown, expired or already used
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+	// ErrInvalidAPIKey occurs when an API key has no name or an unknown scope
+	ErrInvalidAPIKey = errors.New("invalid API key: name is required and scope must be read or read_write")
+
+	// ErrAPIKeyNotFound occurs when an API key doesn't exist, belongs to someone else or is wrong
+	ErrAPIKeyNotFound = errors.New("API key not found")
+
+	// ErrInvalidRerate occurs when a re-rating has no valid date range or an invalid corrected rate
+	ErrInvalidRerate = errors.New("invalid re-rating: date_from and date_to are required and rates must be positive")
+
+	// ErrInvalidVAT occurs when a VAT rate is outside 0-100% or a tax amount doesn't fit the gross amount
+	ErrInvalidVAT = errors.New("invalid VAT: rate must be between 0 and 100 and the tax amount between 0 and the gross amount")
+
+	// ErrInvalidReceivable occurs when a receivable has no debtor, no positive amount or a reminder without a due date
+	ErrInvalidReceivable = errors.New("invalid receivable: needs a debtor and a positive amount; reminders need a due date and can be 0-60 days before")
+
+	// ErrReceivableNotFound occurs when a receivable doesn't exist or belongs to someone else
+	ErrReceivableNotFound = errors.New("receivable not found")
+
+	// ErrReceivableSettled occurs when a repayment is recorded for a receivable that was already paid back
+	ErrReceivableSettled = errors.New("receivable is already settled")
+
+	// ErrInvalidSettlement occurs when a repayment isn't positive or exceeds what is still owed
+	ErrInvalidSettlement = errors.New("invalid settlement: amount must be positive and at most the outstanding amount")
+
+	// ErrInvalidReceivableStatus occurs when receivables are filtered by an unknown status
+	ErrInvalidReceivableStatus = errors.New("invalid status: must be open, overdue or settled")
+
+	// ErrInvalidRole occurs when a user is given a role other than viewer, member or admin
+	ErrInvalidRole = errors.New("invalid role: must be viewer, member or admin")
+
+	// ErrInvalidLoan occurs when a loan lacks a name, a positive principal or a payment that can pay it off
+	ErrInvalidLoan = errors.New("invalid loan: needs a name, a positive principal, an APR of 0-100, a start date and a monthly payment (or term) that covers the interest")
+
+	// ErrLoanNotFound occurs when a loan doesn't exist or belongs to someone else
+	ErrLoanNotFound = errors.New("loan not found")
+
+	// ErrInvalidLoanPayment occurs when an expense in another currency is linked to a loan as a payment
+	ErrInvalidLoanPayment = errors.New("invalid loan payment: payments must be in the loan's currency")
+
+	// ErrAccountLocked occurs when an account is locked after too many failed logins
+	ErrAccountLocked = errors.New("account temporarily locked after too many failed logins")
+
+	// ErrTooManyLoginAttempts occurs when an address made too many failed logins
+	ErrTooManyLoginAttempts = errors.New("too many failed logins, try again later")
+
+	// ErrSessionNotFound occurs when a session doesn't exist, has ended or belongs to someone else
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrTripApproved occurs when changing a trip, or one of its expenses, after its report was approved
+	ErrTripApproved = errors.New("trip report is approved and can no longer be changed")
+
+	// ErrTripNotApproved occurs when asking for the archive of a trip that hasn't been approved
+	ErrTripNotApproved = errors.New("trip report has not been approved yet")
+
+	// ErrInvalidArchive occurs when a file to verify isn't a report archive at all
+	ErrInvalidArchive = errors.New("not a report archive")
+
+	// ErrInvalidApprovalChain occurs when an approval chain has no steps, too many, steps with the same name,
+	// or a step with other than exactly one approver (a user or a role) or an incomplete delegation
+	ErrInvalidApprovalChain = errors.New("invalid approval chain: 1 to 10 differently named steps, each approved by one existing user or one role")
+
+	// ErrApprovalChainNotFound occurs when a tenant hasn't set its own approval chain
+	ErrApprovalChainNotFound = errors.New("approval chain not found")
+
+	// ErrNotApprover occurs when the caller may not approve the step a trip report is waiting for
+	ErrNotApprover = errors.New("you are not the approver of the step this report is waiting for")
+
+	// ErrStepAlreadyApproved occurs when a step approves a trip report it has approved already
+	ErrStepAlreadyApproved = errors.New("this approval step has already been given")
+
+	// ErrInvalidDelegation occurs when a delegation goes to the approver themselves, starts in the past,
+	// ends before it starts or lasts longer than MaxDelegationDays
+	ErrInvalidDelegation = errors.New("invalid delegation: needs another existing user as delegate and a date range from today of at most 365 days")
+
+	// ErrDelegationOverlap occurs when an approver delegates days that are delegated already
+	ErrDelegationOverlap = errors.New("delegation overlaps another delegation of the same approver")
+
+	// ErrDelegationNotFound occurs when a delegation doesn't exist, is cancelled already or isn't the caller's
+	ErrDelegationNotFound = errors.New("delegation not found")
+
+	// ErrInvalidCorporateCard occurs when a card mapping lacks four digits or a cardholder
+	ErrInvalidCorporateCard = errors.New("invalid corporate card: needs the last four digits and a cardholder")
+
+	// ErrCorporateCardExists occurs when mapping card digits that are mapped already
+	ErrCorporateCardExists = errors.New("a corporate card with these digits is already mapped")
+
+	// ErrCorporateCardNotFound occurs when a corporate card doesn't exist
+	ErrCorporateCardNotFound = errors.New("corporate card not found")
+
+	// ErrSubmissionNotCodable occurs when coding a form submission, which keeps what was submitted
+	ErrSubmissionNotCodable = errors.New("only card transactions can be coded")
+
+	// ErrInvalidServiceAccount occurs when a service account lacks a name or has an unknown scope
+	ErrInvalidServiceAccount = errors.New("invalid service account: needs a name of at most 100 characters and a scope of read or read_write")
+
+	// ErrServiceAccountNotFound occurs when a service account doesn't exist
+	ErrServiceAccountNotFound = errors.New("service account not found")
+
+	// ErrInvalidClient occurs when a client ID and secret don't match a service account
+	ErrInvalidClient = errors.New("invalid client credentials")
+
+	// ErrUserDisabled occurs when a disabled user logs in with the right password
+	ErrUserDisabled = errors.New("this account has been disabled")
+
+	// ErrCannotManageSelf occurs when an admin disables or deletes their own account
+	ErrCannotManageSelf = errors.New("admins can't disable or delete their own account")
+
+	// ErrInvalidPolicyRule occurs when a policy rule has no name, an unknown kind or severity, or a missing limit
+	ErrInvalidPolicyRule = errors.New("invalid policy rule: needs a name, a known kind, a severity of warn or block, and a positive amount (or a category for forbidden_category)")
+
+	// ErrPolicyRuleNotFound occurs when a policy rule doesn't exist
+	ErrPolicyRuleNotFound = errors.New("policy rule not found")
+
+	// ErrPolicyBlocked occurs when an expense or a report breaks a blocking policy rule
+	ErrPolicyBlocked = errors.New("blocked by the expense policy")
+
+	// ErrInvalidDimension occurs when a cost center or department has an unknown kind, a bad code or no name
+	ErrInvalidDimension = errors.New("invalid cost center or department: needs a kind of cost_center or department, a code of letters, digits, '-', '_' or '.' (at most 32) and a name")
+
+	// ErrDimensionExists occurs when a cost center or department code is already in use
+	ErrDimensionExists = errors.New("a cost center or department with this code already exists")
+
+	// ErrDimensionNotFound occurs when a cost center or department doesn't exist (or is archived)
+	ErrDimensionNotFound = errors.New("cost center or department not found")
+
+	// ErrUnknownDimension occurs when an expense is charged to a cost center or department that doesn't exist or is archived
+	ErrUnknownDimension = errors.New("unknown or archived cost center or department")
+
+	// ErrInvalidBudgetConfig occurs when an imported budget configuration has another version, a bad or repeated budget, or too many
+	ErrInvalidBudgetConfig = errors.New("invalid budget configuration: needs version 1 and at most 500 budgets, each with a category used once and an amount greater than 0")
+
+	// ErrBudgetTemplateNotFound occurs when a budget template doesn't exist
+	ErrBudgetTemplateNotFound = errors.New("budget template not found")
+
+	// ErrInvalidPlannedPurchase occurs when a planned purchase has no description or a non-positive estimate
+	ErrInvalidPlannedPurchase = errors.New("invalid planned purchase: needs a description and an estimated amount greater than 0")
+
+	// ErrPlannedPurchaseNotFound occurs when a planned purchase doesn't exist
+	ErrPlannedPurchaseNotFound = errors.New("planned purchase not found")
+
+	// ErrPlannedPurchaseLinked occurs when linking an expense to a planned purchase that is bought already
+	ErrPlannedPurchaseLinked = errors.New("planned purchase is already linked to an expense")
+
+	// ErrExpenseAlreadyPlanned occurs when linking an expense that pays for another planned purchase
+	ErrExpenseAlreadyPlanned = errors.New("expense is already linked to another planned purchase")
+
+	// ErrErasureNotScheduled occurs when cancelling an account erasure that was never asked for
+	ErrErasureNotScheduled = errors.New("no account erasure is scheduled")
+
+	// ErrEncryptedFilter occurs when filtering on a column that is stored encrypted
+	// The database only sees ciphertext, so it can't match parts of the text
+	ErrEncryptedFilter = errors.New("filter unavailable: the column is stored encrypted")
+
+	// ErrInvalidDonation occurs when a donation has no recipient or overlong details
+	ErrInvalidDonation = errors.New("invalid donation: recipient_name is required and fields are limited to 200 characters")
+
+	// ErrDonationNotFound occurs when an expense isn't marked as a donation
+	ErrDonationNotFound = errors.New("expense is not marked as a donation")
+
+	// ErrInvalidEmployer occurs when an employer has no name or a name over MaxEmployerNameLength
+	ErrInvalidEmployer = errors.New("invalid employer: needs a name of at most 100 characters")
+
+	// ErrEmployerNotFound occurs when an employer doesn't exist or belongs to someone else
+	ErrEmployerNotFound = errors.New("employer not found")
+
+	// ErrEmployerInUse occurs when deleting an employer that expenses or reimbursements still refer to
+	ErrEmployerInUse = errors.New("employer still has reimbursable expenses or reimbursements")
+
+	// ErrInvalidReimbursement occurs when a reimbursement has no positive amount or no date
+	ErrInvalidReimbursement = errors.New("invalid reimbursement: needs a positive amount and the day it was received")
+
+	// ErrReimbursementNotFound occurs when a reimbursement doesn't exist or belongs to another employer
+	ErrReimbursementNotFound = errors.New("reimbursement not found")
+
+	// ErrInvalidBook occurs when a book has no name, a name over MaxBookNameLength or the default book's name
+	ErrInvalidBook = errors.New("invalid book: needs a name of at most 100 characters other than \"Personal\"")
+
+	// ErrBookNotFound occurs when a request picks a book that doesn't exist or belongs to someone else
+	ErrBookNotFound = errors.New("book not found")
+
+	// ErrBookNotEmpty occurs when deleting a book that still has expenses or pending receipts
+	ErrBookNotEmpty = errors.New("book still has expenses or pending receipts")
+
+	// ErrTooManyBooks occurs when a user already has MaxBooksPerUser books
+	ErrTooManyBooks = errors.New("too many books")
+
+	// ErrInvalidBookArchive occurs when an imported book archive has another version, too many
+	// expenses, or entries that are invalid or don't fit together
+	ErrInvalidBookArchive = errors.New("invalid book archive: needs version 1, valid entries and categories and attachments that refer to entries in the archive")
+
+	// ErrInvalidTags occurs when an expense gets an empty tag, one over MaxTagLength or more than MaxTagsPerExpense
+	ErrInvalidTags = errors.New("invalid tags: at most 20 tags of at most 32 characters each")
+
+	// ErrExpenseRejected is wrapped by extension hooks that refuse to let an expense be created
+	ErrExpenseRejected = errors.New("expense rejected")
+
+	// ErrCustomReportNotFound occurs when no extension module provides the requested report
+	ErrCustomReportNotFound = errors.New("report not found")
+
+	// ErrInvalidReportParameters is wrapped by report providers when the query parameters don't make sense
+	ErrInvalidReportParameters = errors.New("invalid report parameters")
+
+	// ErrInvalidScript occurs when a policy script can't be compiled; the error says why
+	ErrInvalidScript = errors.New("invalid script")
+
+	// ErrInvalidRegionRole occurs when REGION_ROLE is neither active nor passive
+	ErrInvalidRegionRole = errors.New("invalid region role: must be active or passive")
+
+	// ErrPassiveRegion occurs when something tries to write in a passive region
+	ErrPassiveRegion = errors.New("this region is a read-only standby; write to the active region")
+
+	// ErrInvalidReadOnlyMode occurs when read-only mode is switched on with an unknown reason or an overlong message
+	ErrInvalidReadOnlyMode = errors.New("invalid read-only mode: reason must be maintenance or billing_suspended and the message at most 300 characters")
+
+	// ErrReadOnlyModeNotFound occurs when switching off read-only mode that isn't on
+	ErrReadOnlyModeNotFound = errors.New("read-only mode is not on")
+
+	// ErrUnknownPlan occurs when subscribing to a plan that doesn't exist or can't be bought
+	ErrUnknownPlan = errors.New("unknown plan")
+
+	// ErrInvalidSeats occurs when a subscription would have fewer than one seat or more than its plan allows
+	ErrInvalidSeats = errors.New("invalid seats: must be at least 1, no more than the plan allows and at least the number of users")
+
+	// ErrSubscriptionNotFound occurs when a tenant has no subscription to change
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+
+	// ErrSubscriptionExists occurs when subscribing a tenant that already has a current subscription
+	ErrSubscriptionExists = errors.New("already subscribed; change the subscription instead")
+
+	// ErrSeatLimitReached occurs when adding a user would need more seats than the tenant has
+	ErrSeatLimitReached = errors.New("all seats are taken; add seats to the subscription first")
+
+	// ErrInvalidWebhook occurs when a billing webhook has a bad signature or can't be decoded
+	ErrInvalidWebhook = errors.New("invalid billing webhook")
+
+	// ErrDemoDataExists occurs when generating sample data while there still is some
+	ErrDemoDataExists = errors.New("demo data already exists; wipe it first")
+
+	// ErrUnknownMigrationSource occurs when a migration names an app exports aren't recognized from
+	ErrUnknownMigrationSource = errors.New("unknown migration source")
+
+	// ErrUnrecognizedExport occurs when an uploaded export isn't a CSV file with date, amount and description columns
+	ErrUnrecognizedExport = errors.New("unrecognized export: expected a CSV file with date, amount and description columns")
+
+	// ErrMigrationTooLarge occurs when an uploaded export has more bytes or rows than a migration takes
+	ErrMigrationTooLarge = errors.New("export too large to migrate")
+
+	// ErrInvalidMigrationMapping occurs when a category mapping names a category the export doesn't have, or no target
+	ErrInvalidMigrationMapping = errors.New("invalid category mapping: the category must appear in the export and the target can't be empty")
+
+	// ErrInvalidOCRSettings occurs when OCR settings name an unknown provider or unsupported languages
+	ErrInvalidOCRSettings = errors.New("invalid OCR settings: provider must be local or cloud, with 1 to 5 supported languages")
+
+	// ErrOCRSettingsNotFound occurs when a tenant has no OCR settings of its own
+	ErrOCRSettingsNotFound = errors.New("OCR settings not found")
+
+	// ErrOCRProviderUnavailable occurs when choosing an OCR provider this deployment doesn't run
+	ErrOCRProviderUnavailable = errors.New("OCR provider not available on this deployment")
+
+	// ErrInvalidView occurs when a view has no name, no filters or filters that aren't valid
+	ErrInvalidView = errors.New("invalid view: it needs a name and at least one valid filter")
+
+	// ErrViewNotFound occurs when a view doesn't exist or belongs to someone else
+	ErrViewNotFound = errors.New("view not found")
+
+	// ErrViewNameTaken occurs when the caller already has a view of the same name
+	ErrViewNameTaken = errors.New("a view with this name already exists")
+
+	// ErrTooManyViews occurs when saving a view beyond MaxViews
+	ErrTooManyViews = errors.New("too many views")
+
+	// ErrInvalidCustomField occurs when a custom field has a malformed key, an unknown type or no label
+	ErrInvalidCustomField = errors.New("invalid custom field: the key must be lowercase letters, digits or underscores and the type text, number, boolean or date")
+
+	// ErrCustomFieldNotFound occurs when a custom field doesn't exist or belongs to someone else
+	ErrCustomFieldNotFound = errors.New("custom field not found")
+
+	// ErrCustomFieldExists occurs when the caller already has a custom field with the key
+	ErrCustomFieldExists = errors.New("a custom field with this key already exists")
+
+	// ErrTooManyCustomFields occurs when declaring a custom field beyond MaxCustomFields
+	ErrTooManyCustomFields = errors.New("too many custom fields")
+
+	// ErrUnknownCustomField occurs when metadata or a ?meta. filter uses a key without a declared custom field
+	ErrUnknownCustomField = errors.New("unknown custom field: declare it under /custom-fields first")
+
+	// ErrInvalidMetadata occurs when a metadata value doesn't match the type of its custom field
+	ErrInvalidMetadata = errors.New("invalid metadata: a value doesn't match the type of its custom field")
+
+	// ErrInvalidQuery occurs when a ?q= query can't be parsed; the wrapping error says which term is wrong
+	ErrInvalidQuery = errors.New("invalid query")
+
+	// ErrInvalidComment occurs when a comment is empty or longer than MaxCommentLength
+	ErrInvalidComment = errors.New("invalid comment: it needs text of at most 2000 characters")
+
+	// ErrInvalidNotes occurs when the notes of an expense are longer than MaxNotesLength
+	ErrInvalidNotes = errors.New("invalid notes: at most 5000 characters")
+
+	// ErrRuleSuggestionNotFound occurs when accepting or dismissing a rule that isn't (or no longer is) suggested
+	ErrRuleSuggestionNotFound = errors.New("rule suggestion not found")
+
+	// ErrInvalidReimbursementBatch occurs when a batch claims no expenses, too many, or expenses of another employer
+	ErrInvalidReimbursementBatch = errors.New("invalid reimbursement batch: it needs 1 to 500 expenses, all charged to its employer, and a reference of at most 200 characters")
+
+	// ErrReimbursementNotPending occurs when claiming an expense that isn't reimbursable or was already claimed
+	ErrReimbursementNotPending = errors.New("expense is not a pending reimbursable expense")
+
+	// ErrReimbursementBatchNotFound occurs when a reimbursement batch doesn't exist
+	ErrReimbursementBatchNotFound = errors.New("reimbursement batch not found")
+
+	// ErrReimbursementBatchPaid occurs when changing a batch whose payout was already recorded
+	ErrReimbursementBatchPaid = errors.New("reimbursement batch is already paid")
+
+	// ErrReimbursementClaimed occurs when making a claimed expense personal or charging it to another employer
+	ErrReimbursementClaimed = errors.New("expense is claimed in a reimbursement batch; withdraw the batch first")
+
+	// ErrExpenseChangedConcurrently occurs when an expense was changed by someone else while it was being changed
+	ErrExpenseChangedConcurrently = errors.New("expense was changed concurrently, try again")
+
+	// ErrHistoryUnavailable occurs when asking for the past of expenses while the event store is off
+	ErrHistoryUnavailable = errors.New("expense history needs the event store (EXPENSE_STORE=events)")
+
+	// ErrHistoryNotRecorded occurs when asking for an expense at a time before its changes were recorded
+	// Expenses created before the event store was turned on only have history since their first change after
+	ErrHistoryNotRecorded = errors.New("the expense's history isn't recorded that far back")
+
+	// ErrInvalidRefund occurs when a refund has no amount, is dated before its expense, refunds
+	// a refund, or changes what a refund can't (its currency, conversion, tax amount or employer)
+	ErrInvalidRefund = errors.New("invalid refund: it needs a positive amount, dated on or after the expense it refunds")
+
+	// ErrRefundExceedsExpense occurs when refunds would give back more than the expense cost
+	ErrRefundExceedsExpense = errors.New("refunds can't exceed the amount of the expense")
+
+	// ErrExpenseHasRefunds occurs when deleting an expense that still has refunds
+	ErrExpenseHasRefunds = errors.New("expense has refunds; delete them first")
+
+	// ErrReportHistoryUnavailable occurs when asking for a report as of a past time without expense history
+	ErrReportHistoryUnavailable = errors.New("reports as of a past time are not available")
+
+	// ErrReportHistoryNotRecorded occurs when asking for a report as of a time before expense changes were recorded
+	ErrReportHistoryNotRecorded = errors.New("expense changes aren't recorded that far back")
+
+	// ErrRequestLogNotFound occurs when a request log doesn't exist or was purged
+	ErrRequestLogNotFound = errors.New("request log not found")
+
+	// ErrRequestLogTargetNotFound occurs when unflagging a user who isn't flagged for request logging
+	ErrRequestLogTargetNotFound = errors.New("user is not flagged for request logging")
+
+	// ErrInvalidRequestLogTarget occurs when flagging a user for request logging for no time or more than a week
+	ErrInvalidRequestLogTarget = errors.New("invalid request logging flag: hours must be between 1 and 168")
 )
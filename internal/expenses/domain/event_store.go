@@ -0,0 +1,266 @@
+// Package domain contains the core business logic and entities
+// This file defines the event store: an append-only stream of changes per expense, from which
+// the expense's state at any moment can be rebuilt
+package domain
+
+import (
+	"bytes"         // For comparing encoded values
+	"context"       // For request context (cancellation, timeouts)
+	"encoding/json" // For encoding expense state and changes
+	"time"          // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Expense event types
+const (
+	// ExpenseEventCreated carries the full state of a new expense
+	ExpenseEventCreated = "expense.created"
+
+	// ExpenseEventAdopted carries the full state of an expense created before the event store
+	// was turned on; it starts the stream at its first change since
+	ExpenseEventAdopted = "expense.adopted"
+
+	// ExpenseEventUpdated carries the fields that changed, with their new values
+	ExpenseEventUpdated = "expense.updated"
+
+	// ExpenseEventDeleted ends the stream; it carries no data
+	ExpenseEventDeleted = "expense.deleted"
+)
+
+// SnapshotInterval is how many events apart snapshots are taken, so rebuilding an expense
+// never replays more than that many events
+const SnapshotInterval = 50
+
+// ExpenseEvent is one change of an expense. Events are only ever appended: an expense's state
+// at any moment is its events up to then, applied in sequence order
+type ExpenseEvent struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// ExpenseID and Sequence place the event in its stream; sequences start at 1 and have no gaps
+	ExpenseID uuid.UUID `json:"expense_id" gorm:"type:uuid;not null;uniqueIndex:idx_expense_event_stream"`
+	Sequence  int64     `json:"sequence" gorm:"not null;uniqueIndex:idx_expense_event_stream"`
+
+	// Type is one of the ExpenseEvent* constants
+	Type string `json:"type" gorm:"size:32;not null"`
+
+	// UserID and BookID are the owner and book of the expense, so a stream is only visible to them
+	UserID *uuid.UUID `json:"-" gorm:"type:uuid;index"`
+	BookID *uuid.UUID `json:"-" gorm:"type:uuid"`
+
+	// Actor is who made the change: their user ID, or "admin" for the admin token
+	Actor string `json:"actor" gorm:"not null"`
+
+	// Data is the JSON of the event: the expense for created and adopted events, the changed
+	// fields for updated ones (null for a field that was cleared). It holds descriptions and
+	// merchants, so it is stored encrypted like they are
+	Data string `json:"-" gorm:"type:text;serializer:encrypted"`
+
+	// OccurredAt is when the change was made
+	OccurredAt time.Time `json:"occurred_at" gorm:"autoCreateTime;index"`
+}
+
+// Changes returns the event's data as JSON, for showing the event
+func (e *ExpenseEvent) Changes() json.RawMessage {
+	if e.Data == "" {
+		return nil
+	}
+	return json.RawMessage(e.Data)
+}
+
+// ExpenseSnapshot is the state of an expense after one of its events, so it can be rebuilt
+// without replaying the stream from the start
+type ExpenseSnapshot struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// ExpenseID and Sequence are the event the snapshot was taken after
+	ExpenseID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_expense_snapshot"`
+	Sequence  int64     `gorm:"not null;uniqueIndex:idx_expense_snapshot"`
+
+	// UserID and BookID are the owner and book of the expense, like on its events
+	UserID *uuid.UUID `gorm:"type:uuid;index"`
+	BookID *uuid.UUID `gorm:"type:uuid"`
+
+	// State is the expense as JSON, encrypted like event data
+	State string `gorm:"type:text;not null;serializer:encrypted"`
+
+	// OccurredAt is when the event the snapshot was taken after occurred
+	OccurredAt time.Time `gorm:"not null;index"`
+}
+
+// expenseState is an expense as its JSON fields, which is what events change
+type expenseState map[string]json.RawMessage
+
+// stateOf encodes an expense as its JSON fields
+func stateOf(expense *Expense) (expenseState, error) {
+	encoded, err := json.Marshal(expense)
+	if err != nil {
+		return nil, err
+	}
+	state := expenseState{}
+	if err := json.Unmarshal(encoded, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// NewExpenseEvent creates the event that takes an expense from before to after
+// before is nil for created and adopted events, after is nil for deleted ones
+// It returns nil when an update changed nothing but the update time
+func NewExpenseEvent(eventType string, before, after *Expense) (*ExpenseEvent, error) {
+	event := &ExpenseEvent{ID: uuid.New(), Type: eventType}
+	owner := after
+	if owner == nil {
+		owner = before
+	}
+	event.ExpenseID, event.UserID, event.BookID = owner.ID, owner.UserID, owner.BookID
+
+	switch eventType {
+	case ExpenseEventCreated, ExpenseEventAdopted:
+		encoded, err := json.Marshal(after)
+		if err != nil {
+			return nil, err
+		}
+		event.Data = string(encoded)
+	case ExpenseEventUpdated:
+		changes, err := expenseChanges(before, after)
+		if err != nil || changes == nil {
+			return nil, err
+		}
+		event.Data = string(changes)
+	}
+	return event, nil
+}
+
+// expenseChanges returns the fields that differ between before and after, with their values
+// in after; fields after no longer has (omitted when empty) are null
+func expenseChanges(before, after *Expense) (json.RawMessage, error) {
+	old, err := stateOf(before)
+	if err != nil {
+		return nil, err
+	}
+	current, err := stateOf(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := expenseState{}
+	for field, value := range current {
+		if !bytes.Equal(old[field], value) {
+			changes[field] = value
+		}
+	}
+	for field := range old {
+		if _, ok := current[field]; !ok {
+			changes[field] = json.RawMessage("null")
+		}
+	}
+	delete(changes, "updated_at")
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if value, ok := current["updated_at"]; ok {
+		changes["updated_at"] = value
+	}
+	return json.Marshal(changes)
+}
+
+// ReplayExpense rebuilds an expense from a snapshot (nil to start from the beginning of the
+// stream) and the events that followed it, in sequence order
+// It returns ErrExpenseNotFound when the expense didn't exist after the last event
+func ReplayExpense(snapshot *ExpenseSnapshot, events []*ExpenseEvent) (*Expense, error) {
+	// Step 1: Start from the snapshot, if there is one
+	var state expenseState
+	if snapshot != nil {
+		if err := json.Unmarshal([]byte(snapshot.State), &state); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 2: Apply the events
+	for _, event := range events {
+		switch event.Type {
+		case ExpenseEventCreated, ExpenseEventAdopted:
+			state = expenseState{}
+			if err := json.Unmarshal([]byte(event.Data), &state); err != nil {
+				return nil, err
+			}
+		case ExpenseEventUpdated:
+			if state == nil {
+				continue
+			}
+			var changes expenseState
+			if err := json.Unmarshal([]byte(event.Data), &changes); err != nil {
+				return nil, err
+			}
+			for field, value := range changes {
+				if bytes.Equal(value, []byte("null")) {
+					delete(state, field)
+				} else {
+					state[field] = value
+				}
+			}
+		case ExpenseEventDeleted:
+			state = nil
+		}
+	}
+	if state == nil {
+		return nil, ErrExpenseNotFound
+	}
+
+	// Step 3: Decode the expense
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	var expense Expense
+	if err := json.Unmarshal(encoded, &expense); err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+// NewExpenseSnapshot captures an expense after the event with the given sequence
+func NewExpenseSnapshot(expense *Expense, event *ExpenseEvent) (*ExpenseSnapshot, error) {
+	encoded, err := json.Marshal(expense)
+	if err != nil {
+		return nil, err
+	}
+	return &ExpenseSnapshot{
+		ID:         uuid.New(),
+		ExpenseID:  event.ExpenseID,
+		Sequence:   event.Sequence,
+		UserID:     event.UserID,
+		BookID:     event.BookID,
+		State:      string(encoded),
+		OccurredAt: event.OccurredAt,
+	}, nil
+}
+
+// EventStore defines how expense events and snapshots are stored
+// Reads are limited to the streams of the caller's expenses in their current book
+type EventStore interface {
+	// Append adds events to the end of their streams; it returns ErrExpenseChangedConcurrently
+	// if another event took one of their sequences first
+	Append(ctx context.Context, events ...*ExpenseEvent) error
+
+	// Load returns the latest snapshot of an expense taken at or before asOf (nil if there is
+	// none) and the events after it up to asOf. A zero asOf loads up to the latest event
+	Load(ctx context.Context, expenseID uuid.UUID, asOf time.Time) (*ExpenseSnapshot, []*ExpenseEvent, error)
+
+	// SaveSnapshot stores a snapshot
+	SaveSnapshot(ctx context.Context, snapshot *ExpenseSnapshot) error
+
+	// Events returns the whole stream of an expense, oldest first
+	Events(ctx context.Context, expenseID uuid.UUID) ([]*ExpenseEvent, error)
+}
+
+// ExpenseHistory answers questions about the past of expenses
+type ExpenseHistory interface {
+	// AsOf returns an expense as it was at the given time, or ErrExpenseNotFound if it
+	// didn't exist then
+	AsOf(ctx context.Context, id string, at time.Time) (*Expense, error)
+
+	// Events returns every change of an expense, oldest first
+	Events(ctx context.Context, id string) ([]*ExpenseEvent, error)
+}
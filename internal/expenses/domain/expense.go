@@ -8,6 +8,18 @@ import (
 	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
 )
 
+// Expense sources describe how an expense entered the system
+const (
+	// SourceManual is used for expenses typed in by the user
+	SourceManual = "manual"
+
+	// SourceImport is used for expenses created from bank or card transaction imports
+	SourceImport = "import"
+
+	// SourceMigration is used for expenses migrated from the export of another expense app
+	SourceMigration = "migration"
+)
+
 // Expense represents the core business entity for an expense
 // This is the main data structure that represents an expense in our system
 // It contains all the fields that define what an expense is in our business domain
@@ -20,20 +32,147 @@ type Expense struct {
 	// Description is what the expense was for (e.g., "Coffee", "Gas", "Groceries")
 	// string is Go's built-in type for text
 	// gorm:"not null" means this field cannot be empty in the database
-	Description string `json:"description" gorm:"not null"`
+	// serializer:encrypted stores it encrypted when field encryption is enabled
+	Description string `json:"description" gorm:"not null;serializer:encrypted"`
+
+	// Amount is how much the expense cost, in Currency
+	// Money keeps it in whole cents, so amounts like 12.99 add up exactly; it is stored as NUMERIC
+	Amount Money `json:"amount" gorm:"type:numeric(19,2);not null"`
 
-	// Amount is how much the expense cost
-	// float64 is Go's type for decimal numbers (64-bit precision)
-	// This allows us to store amounts like 12.99, 100.50, etc.
-	Amount float64 `json:"amount" gorm:"not null"`
+	// Currency is the ISO 4217 code of Amount (e.g. "USD" for a purchase made abroad)
+	Currency string `json:"currency" gorm:"size:3;not null;default:EUR"`
+
+	// BaseCurrency is the home currency BaseAmount is expressed in
+	BaseCurrency string `json:"base_currency" gorm:"size:3;not null;default:EUR"`
+
+	// ExchangeRate is the rate locked in at entry time (1 unit of Currency in BaseCurrency)
+	// It is 1 for expenses entered in the base currency
+	ExchangeRate float64 `json:"exchange_rate" gorm:"not null;default:1"`
+
+	// BaseAmount is Amount converted with ExchangeRate, rounded to cents
+	// Reports use this locked value instead of re-converting with today's rates
+	BaseAmount Money `json:"base_amount" gorm:"type:numeric(19,2);not null;default:0"`
+
+	// ConversionSource tells where ExchangeRate came from (see the Conversion* constants)
+	// Only rates from the rate provider are replaced when the provider publishes corrections;
+	// it is empty for expenses recorded before it was tracked
+	ConversionSource string `json:"conversion_source,omitempty" gorm:"size:16"`
 
 	// Category helps organize expenses (e.g., "Food", "Transportation", "Entertainment")
 	Category string `json:"category" gorm:"not null"`
 
+	// VATRate is the VAT rate in percent included in Amount (nil when unknown)
+	// It defaults to the category's rate and can be overridden per expense
+	VATRate *float64 `json:"vat_rate,omitempty"`
+
+	// NetAmount and TaxAmount split the gross Amount (in Currency); both are 0 without VAT information
+	// They are only sent when there is VAT information
+	NetAmount Money `json:"net_amount,omitzero" gorm:"type:numeric(19,2);not null;default:0"`
+	TaxAmount Money `json:"tax_amount,omitzero" gorm:"type:numeric(19,2);not null;default:0"`
+
 	// Date is when the expense occurred
 	// time.Time is Go's type for representing dates and times
 	Date time.Time `json:"date" gorm:"not null"`
 
+	// Merchant is the name of the shop or company that was paid (e.g. "SHELL 1234")
+	// It is filled in by bank/card imports; manual expenses may leave it empty
+	// Like the description, it is stored encrypted when field encryption is enabled
+	Merchant string `json:"merchant,omitempty" gorm:"serializer:encrypted"`
+
+	// MCC is the 4-digit ISO 18245 merchant category code reported by the card network
+	// It is only available for card-imported transactions
+	MCC string `json:"mcc,omitempty" gorm:"size:4"`
+
+	// Source tells where the expense came from ("manual", "import" or "migration")
+	Source string `json:"source" gorm:"not null;default:manual"`
+
+	// ExternalID is the bank's transaction ID for imported expenses
+	// It lets us skip transactions that were already imported
+	ExternalID string `json:"external_id,omitempty" gorm:"index"`
+
+	// NormalizedDescription is the description after the normalization pipeline ran
+	// (store numbers stripped, accents removed, user replacements applied)
+	// It is used to detect duplicate imports and to power description autocomplete
+	NormalizedDescription string `json:"normalized_description,omitempty" gorm:"index"`
+
+	// AccountID is the account the expense was paid from (nil if not tracked)
+	// Cash expenses point at the cash wallet so they draw down its balance
+	AccountID *uuid.UUID `json:"account_id,omitempty" gorm:"type:uuid;index"`
+
+	// ReconciledAt is when the expense was confirmed against a bank statement (nil if not yet)
+	// It is set when the reconciliation session that matched it is closed
+	ReconciledAt *time.Time `json:"reconciled_at,omitempty" gorm:"index"`
+
+	// TripID is the business trip or project the expense belongs to (nil if none)
+	TripID *uuid.UUID `json:"trip_id,omitempty" gorm:"type:uuid;index"`
+
+	// CostCenter and Department are the codes of the organization's dimensions the expense
+	// is charged to (empty when not charged), for chargeback reports
+	CostCenter string `json:"cost_center,omitempty" gorm:"size:32;index"`
+	Department string `json:"department,omitempty" gorm:"size:32;index"`
+
+	// Reimbursable marks an expense someone else pays back (e.g. a work expense) rather than a personal one
+	Reimbursable bool `json:"reimbursable" gorm:"not null;default:false;index"`
+
+	// EmployerID is who pays a reimbursable expense back (nil when it isn't charged to anyone yet)
+	EmployerID *uuid.UUID `json:"employer_id,omitempty" gorm:"type:uuid;index"`
+
+	// ReimbursementStatus is where a reimbursable expense is in its lifecycle: pending, submitted
+	// or paid ("" for personal expenses). It moves on with the batch it is claimed in
+	ReimbursementStatus string `json:"reimbursement_status,omitempty" gorm:"size:16;index"`
+
+	// ReimbursementBatchID is the batch the expense was claimed in (nil while pending)
+	ReimbursementBatchID *uuid.UUID `json:"reimbursement_batch_id,omitempty" gorm:"type:uuid;index"`
+
+	// Business marks an expense of the user's own business or self-employment (personal by default)
+	Business bool `json:"business" gorm:"not null;default:false;index"`
+
+	// TaxDeductible marks an expense that can be deducted on the tax return, for tax season
+	TaxDeductible bool `json:"tax_deductible" gorm:"not null;default:false;index"`
+
+	// Tags are free-form labels (e.g. "trip-rome", "gift"), normalized by NormalizeTags
+	// They are stored as a JSON array so expenses can be found by tag with an indexed lookup
+	Tags []string `json:"tags,omitempty" gorm:"type:jsonb;serializer:json"`
+
+	// Notes are the user's free-text remarks on the expense (e.g. what a gift was for)
+	// Like the description, they are stored encrypted when field encryption is enabled
+	Notes string `json:"notes,omitempty" gorm:"serializer:encrypted"`
+
+	// Metadata are the values of the user's custom fields (e.g. {"project": "ACME"})
+	// Keys are declared per user under /custom-fields; the JSON object can be filtered with ?meta.<key>=
+	Metadata Metadata `json:"metadata,omitempty" gorm:"type:jsonb;serializer:json"`
+
+	// LoanID is the loan the expense is a payment of (nil if none)
+	LoanID *uuid.UUID `json:"loan_id,omitempty" gorm:"type:uuid;index"`
+
+	// MemberID is the group member who paid the expense (nil outside of groups)
+	// Group budgets count the expenses of their members
+	MemberID *uuid.UUID `json:"member_id,omitempty" gorm:"type:uuid;index"`
+
+	// ParentExpenseID is the expense a refund gives money back on (nil for ordinary expenses)
+	// Refunds have a negative amount, so spending totals come out net of them
+	ParentExpenseID *uuid.UUID `json:"parent_expense_id,omitempty" gorm:"type:uuid;index"`
+
+	// Demo marks generated sample data that lets new tenants explore the app
+	// Wiping the demo data removes exactly these expenses, so real ones are never touched
+	Demo bool `json:"demo,omitempty" gorm:"not null;default:false;index"`
+
+	// BookID is the book the expense is kept in (nil for the owner's default book)
+	BookID *uuid.UUID `json:"book_id,omitempty" gorm:"type:uuid;index"`
+
+	// UserID is the user the expense belongs to; only they can see or change it
+	// Expenses recorded before user accounts existed have none and belong to the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid"`
+
+	// Language is the PostgreSQL text search configuration of the description (e.g. "german")
+	// It is detected when the expense is saved, so search can stem the words correctly;
+	// "simple" (or empty for older expenses) means the language is unknown
+	Language string `json:"language,omitempty" gorm:"size:32"`
+
+	// PolicyWarnings are the policy rules the expense broke without being blocked
+	// They are only filled in on the expense returned by a create or update, never stored with it
+	PolicyWarnings []*PolicyViolation `json:"policy_warnings,omitempty" gorm:"-"`
+
 	// CreatedAt is automatically set when the expense is first saved to the database
 	// gorm:"autoCreateTime" tells GORM to automatically set this field
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
@@ -47,7 +186,7 @@ type Expense struct {
 // This is a "factory function" - it ensures that all expenses are created with valid data
 // It returns a pointer to Expense (*Expense) and an error
 // The * means it's a pointer - a reference to the actual data in memory
-func NewExpense(description string, amount float64, category string, date time.Time) (*Expense, error) {
+func NewExpense(description string, amount Money, category string, date time.Time) (*Expense, error) {
 	// Validation: Check if description is empty
 	// In Go, "" represents an empty string
 	if description == "" {
@@ -58,7 +197,7 @@ func NewExpense(description string, amount float64, category string, date time.T
 
 	// Validation: Check if amount is less than or equal to 0
 	// We don't want negative or zero amounts for expenses
-	if amount <= 0 {
+	if amount.Minor <= 0 {
 		return nil, ErrInvalidAmount
 	}
 
@@ -76,11 +215,17 @@ func NewExpense(description string, amount float64, category string, date time.T
 	// If all validations pass, create and return a new expense
 	// &Expense{...} creates a new Expense struct and returns a pointer to it
 	return &Expense{
-		ID:          uuid.New(),  // Generate a new unique ID
-		Description: description, // Set the description
-		Amount:      amount,      // Set the amount
-		Category:    category,    // Set the category
-		Date:        date,        // Set the date
+		ID:          newExpenseID(), // Generate a new unique ID (time-ordered unless configured otherwise)
+		Description: description,    // Set the description
+		Amount:      amount,         // Set the amount
+		Category:    category,       // Set the category
+		Date:        date,           // Set the date
+		Source:      SourceManual,
+		// Until a conversion is locked in, the expense is assumed to be in the base currency
+		Currency:     DefaultBaseCurrency,
+		BaseCurrency: DefaultBaseCurrency,
+		ExchangeRate: 1,
+		BaseAmount:   amount,
 		// Note: CreatedAt and UpdatedAt will be set automatically by GORM
 	}, nil
 }
@@ -97,8 +242,8 @@ func (e *Expense) Validate() error {
 		return ErrInvalidDescription
 	}
 
-	// Check if amount is invalid
-	if e.Amount <= 0 {
+	// Check if amount is invalid: expenses cost something, refunds give something back
+	if (!e.IsRefund() && e.Amount.Minor <= 0) || (e.IsRefund() && e.Amount.Minor >= 0) {
 		return ErrInvalidAmount
 	}
 
@@ -120,15 +265,23 @@ func (e *Expense) Validate() error {
 // Update updates the expense fields
 // This method allows partial updates - only the provided fields will be changed
 // It takes the new values as parameters and only updates non-empty/non-zero values
-func (e *Expense) Update(description string, amount float64, category string, date time.Time) error {
+func (e *Expense) Update(description string, amount Money, category string, date time.Time) error {
 	// Update description only if a new one is provided (not empty)
 	if description != "" {
 		e.Description = description
 	}
 
 	// Update amount only if a valid new amount is provided (greater than 0)
-	if amount > 0 {
+	// For a refund it is the amount given back, which is stored negative
+	if amount.Minor > 0 {
+		if e.IsRefund() {
+			amount = amount.Neg()
+		}
 		e.Amount = amount
+		// Keep the locked rate but re-derive the converted amount from the new amount
+		if e.ExchangeRate > 0 {
+			e.BaseAmount = e.Amount.Mul(e.ExchangeRate)
+		}
 	}
 
 	// Update category only if a new one is provided (not empty)
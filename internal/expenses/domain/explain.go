@@ -0,0 +1,30 @@
+// Package domain contains the core business logic and entities
+// This file defines the query plan diagnostics available to administrators
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For date range arguments
+)
+
+// QueryPlan is the EXPLAIN ANALYZE output of one generated query
+type QueryPlan struct {
+	// Name identifies the query within the request (e.g. "spending_by_category")
+	Name string `json:"name"`
+
+	// SQL is the statement that was explained, with placeholders
+	SQL string `json:"sql"`
+
+	// Plan holds the planner output, one line per element
+	Plan []string `json:"plan"`
+}
+
+// QueryExplainer returns the execution plans of the queries behind lists and reports
+// It is an optional capability: repositories that can't explain queries simply don't implement it
+type QueryExplainer interface {
+	// ExplainExpenses explains the query GetAll runs for the given filters
+	ExplainExpenses(ctx context.Context, filters map[string]interface{}) (*QueryPlan, error)
+
+	// ExplainSpending explains the per-category and per-merchant totals for [from, to)
+	ExplainSpending(ctx context.Context, from, to time.Time) ([]*QueryPlan, error)
+}
@@ -0,0 +1,147 @@
+// Package domain contains the core business logic and entities
+// This file defines export jobs: exports too large to build within one request,
+// which run in the background and leave a file to download
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Export job statuses
+const (
+	ExportQueued    = "queued"
+	ExportRunning   = "running"
+	ExportCompleted = "completed"
+	ExportFailed    = "failed"
+)
+
+// ExportFormatCSV is the format of exported files
+const ExportFormatCSV = "csv"
+
+// ExportJob is a background export of expenses to a file
+type ExportJob struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is who requested the export; only they can see the job
+	UserID string `json:"-" gorm:"index"`
+
+	// BookID is the book the export was requested in; empty for the default book
+	BookID string `json:"book_id,omitempty"`
+
+	// Format is the file format of the export
+	Format string `json:"format" gorm:"not null"`
+
+	// Filters are the expense filters of the export, as JSON
+	Filters string `json:"filters" gorm:"type:text"`
+
+	// Status is queued, running, completed or failed
+	Status string `json:"status" gorm:"not null;index"`
+
+	// TotalRows is how many expenses matched when the export started
+	TotalRows int64 `json:"total_rows"`
+
+	// ProcessedRows is how many of them were written so far
+	ProcessedRows int64 `json:"processed_rows"`
+
+	// StorageKey is where the finished file is kept in blob storage
+	StorageKey string `json:"-"`
+
+	// SizeBytes is the size of the finished file
+	SizeBytes int64 `json:"size_bytes"`
+
+	// Error explains why a failed export failed
+	Error string `json:"error,omitempty"`
+
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	// ExpiresAt is when the finished file is deleted
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"index"`
+}
+
+// NewExportJob creates a queued export of the expenses matching filters (JSON) for userID,
+// from the book bookID ("" for the default book)
+func NewExportJob(userID, bookID, format, filters string) (*ExportJob, error) {
+	if format != ExportFormatCSV {
+		return nil, ErrInvalidExport
+	}
+	return &ExportJob{
+		ID:      uuid.New(),
+		UserID:  userID,
+		BookID:  bookID,
+		Format:  format,
+		Filters: filters,
+		Status:  ExportQueued,
+	}, nil
+}
+
+// Start marks the job as running over total rows
+func (j *ExportJob) Start(total int64, now time.Time) {
+	j.Status = ExportRunning
+	j.TotalRows = total
+	j.ProcessedRows = 0
+	j.StartedAt = &now
+}
+
+// Complete marks the job as finished, with its file kept until expiresAt
+func (j *ExportJob) Complete(storageKey string, size, rows int64, now, expiresAt time.Time) {
+	j.Status = ExportCompleted
+	j.StorageKey = storageKey
+	j.SizeBytes = size
+	j.ProcessedRows = rows
+	// Rows written after the count (new expenses) still count as done
+	if rows > j.TotalRows {
+		j.TotalRows = rows
+	}
+	j.CompletedAt = &now
+	j.ExpiresAt = &expiresAt
+}
+
+// Fail marks the job as failed with reason
+func (j *ExportJob) Fail(reason string, now time.Time) {
+	j.Status = ExportFailed
+	j.Error = reason
+	j.CompletedAt = &now
+}
+
+// Progress returns the share of rows written, from 0 to 1
+func (j *ExportJob) Progress() float64 {
+	switch {
+	case j.Status == ExportCompleted:
+		return 1
+	case j.TotalRows == 0:
+		return 0
+	default:
+		return float64(j.ProcessedRows) / float64(j.TotalRows)
+	}
+}
+
+// Downloadable reports whether the job's file can be downloaded at now
+func (j *ExportJob) Downloadable(now time.Time) bool {
+	return j.Status == ExportCompleted && j.ExpiresAt != nil && now.Before(*j.ExpiresAt)
+}
+
+// ExportRepository defines how export jobs are stored
+type ExportRepository interface {
+	// Create saves a new export job
+	Create(ctx context.Context, job *ExportJob) error
+
+	// GetByID retrieves an export job, or returns ErrExportNotFound
+	GetByID(ctx context.Context, id string) (*ExportJob, error)
+
+	// Update saves changes to an export job
+	Update(ctx context.Context, job *ExportJob) error
+
+	// ListByUser returns the export jobs of userID, newest first
+	ListByUser(ctx context.Context, userID string) ([]*ExportJob, error)
+
+	// ListUnfinished returns the jobs that are queued or running
+	ListUnfinished(ctx context.Context) ([]*ExportJob, error)
+
+	// ListExpired returns the completed jobs whose file expired before now
+	ListExpired(ctx context.Context, now time.Time) ([]*ExportJob, error)
+}
@@ -0,0 +1,73 @@
+// Package domain contains the core business logic and entities
+// This file defines review flags that mark expenses for follow-up
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For normalizing flag names
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Flag is a review marker on an expense
+type Flag string
+
+const (
+	// FlagNeedsReceipt marks an expense whose receipt still has to be attached
+	FlagNeedsReceipt Flag = "needs_receipt"
+
+	// FlagQuestion marks an expense someone has a question about
+	FlagQuestion Flag = "question"
+
+	// FlagPersonal marks a personal expense that must be left out of reimbursement submissions
+	FlagPersonal Flag = "personal"
+)
+
+// Flags lists every known flag in display order
+var Flags = []Flag{FlagNeedsReceipt, FlagQuestion, FlagPersonal}
+
+// ParseFlag normalizes and validates a flag name
+func ParseFlag(value string) (Flag, error) {
+	flag := Flag(strings.ToLower(strings.TrimSpace(value)))
+	for _, known := range Flags {
+		if flag == known {
+			return flag, nil
+		}
+	}
+	return "", ErrInvalidFlag
+}
+
+// ExpenseFlag is one flag set on one expense
+// An expense carries each flag at most once
+type ExpenseFlag struct {
+	ExpenseID uuid.UUID `json:"expense_id" gorm:"type:uuid;primaryKey"`
+	Flag      Flag      `json:"flag" gorm:"primaryKey;index"`
+
+	// Note optionally explains the flag (e.g. the question being asked)
+	Note string `json:"note,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// FlagCount is how many expenses carry a flag, and how much they add up to
+type FlagCount struct {
+	Flag   Flag  `json:"flag"`
+	Count  int   `json:"count"`
+	Amount Money `json:"amount"`
+}
+
+// FlagRepository defines the data access operations for expense flags
+type FlagRepository interface {
+	// Set adds the flag to the expense, or replaces its note if it is already set
+	Set(ctx context.Context, flag *ExpenseFlag) error
+
+	// Remove clears a flag, returning ErrFlagNotFound if it wasn't set
+	Remove(ctx context.Context, expenseID string, flag Flag) error
+
+	// ListForExpense returns the flags set on an expense
+	ListForExpense(ctx context.Context, expenseID string) ([]*ExpenseFlag, error)
+
+	// CountByFlag counts the flagged expenses dated in [from, to) per flag
+	CountByFlag(ctx context.Context, from, to time.Time) ([]*FlagCount, error)
+}
@@ -0,0 +1,166 @@
+// Package domain contains the core business logic and entities
+// This file defines groups (e.g. a household or flat share) whose members share budgets
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For normalizing names
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Group is a set of people who share budgets, e.g. a household or a flat share
+type Group struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user the group belongs to; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	Name string `json:"name" gorm:"not null"`
+
+	// Members are the people in the group, in the order they joined
+	Members []*GroupMember `json:"members" gorm:"foreignKey:GroupID"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// GroupMember is one person in a group
+// Expenses are attributed to a member through Expense.MemberID
+type GroupMember struct {
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	GroupID uuid.UUID `json:"group_id" gorm:"type:uuid;not null;index"`
+	Name    string    `json:"name" gorm:"not null"`
+
+	// UserID links the member to a user account once accounts exist (empty until then)
+	UserID string `json:"user_id,omitempty" gorm:"index"`
+
+	// Share is the member's agreed part of the group budgets, relative to the other members' shares
+	// (e.g. 2 and 1 means two thirds and one third); when every share is 0 the budgets are split evenly
+	Share float64 `json:"share"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// GroupBudget is a monthly spending limit for one category, shared by the members of a group
+// Amounts are in the base currency, like Budget
+type GroupBudget struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	GroupID  uuid.UUID `json:"group_id" gorm:"type:uuid;not null;uniqueIndex:idx_group_budget_category"`
+	Category string    `json:"category" gorm:"not null;uniqueIndex:idx_group_budget_category"`
+	Amount   Money     `json:"amount" gorm:"type:numeric(19,2);not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// MemberSpending is what one member spent in one category
+type MemberSpending struct {
+	MemberID uuid.UUID `json:"member_id"`
+	Category string    `json:"category"`
+	Amount   Money     `json:"amount"`
+}
+
+// NewGroup creates a validated group with its first members
+func NewGroup(name string, members []*GroupMember) (*Group, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidGroup
+	}
+	group := &Group{ID: uuid.New(), Name: name, Members: members}
+	for _, member := range members {
+		member.GroupID = group.ID
+	}
+	return group, nil
+}
+
+// NewGroupMember creates a validated group member (GroupID is set when it joins a group)
+func NewGroupMember(name, userID string, share float64) (*GroupMember, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || share < 0 {
+		return nil, ErrInvalidGroup
+	}
+	return &GroupMember{
+		ID:     uuid.New(),
+		Name:   name,
+		UserID: strings.TrimSpace(userID),
+		Share:  share,
+	}, nil
+}
+
+// NewGroupBudget creates a validated group budget
+func NewGroupBudget(groupID uuid.UUID, category string, amount Money) (*GroupBudget, error) {
+	category = strings.TrimSpace(category)
+	if category == "" {
+		return nil, ErrInvalidCategory
+	}
+	if amount.Minor <= 0 {
+		return nil, ErrInvalidBudget
+	}
+	return &GroupBudget{
+		ID:       uuid.New(),
+		GroupID:  groupID,
+		Category: category,
+		Amount:   amount,
+	}, nil
+}
+
+// Member returns the member with the given ID, or nil
+func (g *Group) Member(id uuid.UUID) *GroupMember {
+	for _, member := range g.Members {
+		if member.ID == id {
+			return member
+		}
+	}
+	return nil
+}
+
+// ShareOf returns the fraction (0-1) of the group budgets member is expected to contribute
+func (g *Group) ShareOf(member *GroupMember) float64 {
+	if len(g.Members) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, m := range g.Members {
+		total += m.Share
+	}
+	if total == 0 {
+		return 1 / float64(len(g.Members))
+	}
+	return member.Share / total
+}
+
+// GroupRepository defines the data access operations for groups, their members and budgets
+type GroupRepository interface {
+	// Create saves a new group together with its members
+	Create(ctx context.Context, group *Group) error
+
+	// GetByID retrieves a group with its members, or returns ErrGroupNotFound
+	GetByID(ctx context.Context, id string) (*Group, error)
+
+	// List returns all groups with their members ordered by name
+	List(ctx context.Context) ([]*Group, error)
+
+	// AddMember saves a new member of an existing group
+	AddMember(ctx context.Context, member *GroupMember) error
+
+	// RemoveMember deletes a member and clears the attribution of their expenses,
+	// or returns ErrGroupMemberNotFound
+	RemoveMember(ctx context.Context, groupID, memberID uuid.UUID) error
+
+	// AttributeExpenses records member as the one who paid the expenses
+	// It returns ErrExpenseNotFound (and changes nothing) when one of the expenses doesn't exist
+	AttributeExpenses(ctx context.Context, memberID uuid.UUID, expenseIDs []uuid.UUID) error
+
+	// SaveBudget creates the group's budget for the category, or replaces its amount
+	SaveBudget(ctx context.Context, budget *GroupBudget) error
+
+	// Budgets returns the group's budgets ordered by category
+	Budgets(ctx context.Context, groupID uuid.UUID) ([]*GroupBudget, error)
+
+	// DeleteBudget removes a group budget, or returns ErrBudgetNotFound
+	DeleteBudget(ctx context.Context, groupID, budgetID uuid.UUID) error
+
+	// SpendingByMember sums the group members' expenses dated in [from, to) per member and category
+	SpendingByMember(ctx context.Context, groupID uuid.UUID, from, to time.Time) ([]*MemberSpending, error)
+}
@@ -0,0 +1,136 @@
+// Package domain contains the core business logic and entities
+// This file decides how new expense IDs are generated
+package domain
+
+import (
+	"crypto/rand"     // For the random bits of an ID
+	"encoding/binary" // For writing the timestamp into the ID
+	"strings"         // For parsing the configured version
+	"sync"            // For keeping IDs ordered across goroutines
+	"time"            // For the timestamp part of UUIDv7
+
+	"github.com/google/uuid" // UUID type shared by all entities
+)
+
+// IDVersion selects the UUID layout used for new expense IDs
+type IDVersion string
+
+const (
+	// IDVersion4 is the fully random UUID used before time-ordered IDs existed
+	IDVersion4 IDVersion = "v4"
+
+	// IDVersion7 is a time-ordered UUID (RFC 9562): a millisecond timestamp followed by random bits
+	// New rows land at the "right edge" of the primary key B-tree instead of on random pages,
+	// which keeps inserts into the large expenses table cheap
+	IDVersion7 IDVersion = "v7"
+)
+
+// DefaultIDVersion is used when a deployment doesn't configure one
+const DefaultIDVersion = IDVersion7
+
+// ParseIDVersion parses "v4"/"4" or "v7"/"7"
+// An empty string means DefaultIDVersion
+func ParseIDVersion(value string) (IDVersion, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), "v") {
+	case "":
+		return DefaultIDVersion, nil
+	case "4":
+		return IDVersion4, nil
+	case "7":
+		return IDVersion7, nil
+	}
+	return "", ErrInvalidIDVersion
+}
+
+// expenseIDs is the generator used by NewExpense
+var expenseIDs = &idGenerator{version: DefaultIDVersion}
+
+// SetExpenseIDVersion chooses the layout of new expense IDs for this process
+// Existing IDs are never rewritten: v4 and v7 IDs are both plain UUIDs, so old and new rows
+// live side by side in the same column and every lookup keeps working
+func SetExpenseIDVersion(version IDVersion) {
+	expenseIDs.mu.Lock()
+	defer expenseIDs.mu.Unlock()
+	expenseIDs.version = version
+}
+
+// SetExpenseIDNode reserves byte 9 of new expense IDs for node, the number of this region
+// Regions with different nodes can never generate the same ID, so expenses written on both
+// sides of a failover (e.g. by a standby promoted while the old primary still took writes)
+// can be merged without collisions. The byte is otherwise random, so old IDs stay valid
+func SetExpenseIDNode(node byte) {
+	expenseIDs.mu.Lock()
+	defer expenseIDs.mu.Unlock()
+	expenseIDs.node = &node
+}
+
+// newExpenseID returns an ID in the configured layout
+func newExpenseID() uuid.UUID {
+	return expenseIDs.next()
+}
+
+// idGenerator creates expense IDs
+// For v7 it remembers the last timestamp and counter so IDs made in the same millisecond
+// still sort in creation order (the "fixed-length counter" method of RFC 9562)
+type idGenerator struct {
+	mu      sync.Mutex
+	version IDVersion
+	lastMs  int64
+	counter uint16
+
+	// node, when set, is written into byte 9 of every ID (see SetExpenseIDNode)
+	node *byte
+}
+
+// next returns a new ID
+func (g *idGenerator) next() uuid.UUID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.version != IDVersion7 {
+		return g.withNode(uuid.New())
+	}
+
+	var id uuid.UUID
+	if _, err := rand.Read(id[:]); err != nil {
+		// crypto/rand doesn't fail on supported platforms; fall back to v4 rather than panic
+		return uuid.New()
+	}
+
+	// The clock can step backwards (NTP); reuse the last timestamp so IDs never go back in order
+	ms := time.Now().UnixMilli()
+	if ms <= g.lastMs {
+		ms = g.lastMs
+		g.counter++
+		// The 12-bit counter ran out within one millisecond: borrow the next millisecond
+		if g.counter > 0x0fff {
+			ms++
+			g.counter = 0
+		}
+	} else {
+		// Start each millisecond at a random counter value in the lower half,
+		// leaving room to count up without overflowing
+		g.counter = binary.BigEndian.Uint16(id[6:8]) & 0x07ff
+	}
+	g.lastMs = ms
+
+	// Bytes 0-5: 48-bit big-endian Unix timestamp in milliseconds
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(ms))
+	copy(id[0:6], ts[2:8])
+
+	// Bytes 6-7: version 7 in the high nibble, then the 12-bit counter
+	binary.BigEndian.PutUint16(id[6:8], 0x7000|g.counter)
+
+	// Byte 8: RFC 4122 variant (10xxxxxx); the rest stays random
+	id[8] = (id[8] & 0x3f) | 0x80
+	return g.withNode(id)
+}
+
+// withNode writes the region's node into byte 9, which is random in both layouts
+func (g *idGenerator) withNode(id uuid.UUID) uuid.UUID {
+	if g.node != nil {
+		id[9] = *g.node
+	}
+	return id
+}
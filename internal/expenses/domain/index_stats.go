@@ -0,0 +1,23 @@
+// Package domain contains the core business logic and entities
+// This file defines the database index usage statistics shown to administrators
+package domain
+
+import "context" // For request context (cancellation, timeouts)
+
+// IndexUsage describes how often the database has used one index
+// The counters are cumulative since the database statistics were last reset
+type IndexUsage struct {
+	Table  string `json:"table"`
+	Index  string `json:"index"`
+	Scans  int64  `json:"scans"`
+	Reads  int64  `json:"tuples_read"`
+	Hits   int64  `json:"tuples_fetched"`
+	Bytes  int64  `json:"size_bytes"`
+	Unused bool   `json:"unused"`
+}
+
+// IndexStatsRepository reads index usage statistics from the database
+type IndexStatsRepository interface {
+	// IndexUsage returns the usage of every index on the application's tables, largest first
+	IndexUsage(ctx context.Context) ([]*IndexUsage, error)
+}
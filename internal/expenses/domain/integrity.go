@@ -0,0 +1,115 @@
+// Package domain contains the core business logic and entities
+// This file defines the data integrity checks an administrator can run and their report
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For handling dates and times
+)
+
+// Integrity check names
+const (
+	// CheckOrphanedAttachments finds attachment records whose expense no longer exists
+	CheckOrphanedAttachments = "orphaned_attachments"
+
+	// CheckMissingAttachmentFiles finds attachment records whose file is gone from storage
+	CheckMissingAttachmentFiles = "missing_attachment_files"
+
+	// CheckSplitTotals finds split expenses whose items don't add up to the expense amount
+	CheckSplitTotals = "split_totals"
+
+	// CheckAccountReferences finds expenses and transfers pointing at accounts that don't exist
+	// Account balances are derived from these rows, so a dangling reference makes balances drift
+	CheckAccountReferences = "account_references"
+
+	// CheckBaseAmountDrift finds expenses whose stored base amount no longer matches amount x rate
+	CheckBaseAmountDrift = "base_amount_drift"
+
+	// CheckDeletedCategories finds expenses in categories that were deleted
+	CheckDeletedCategories = "deleted_categories"
+)
+
+// Integrity check statuses
+const (
+	// IntegrityOK means the check ran and found nothing
+	IntegrityOK = "ok"
+
+	// IntegrityIssues means the check found problems
+	IntegrityIssues = "issues"
+
+	// IntegritySkipped means the check doesn't apply to this installation
+	IntegritySkipped = "skipped"
+)
+
+// IntegrityIssue is one problem found by a check
+type IntegrityIssue struct {
+	// EntityType is the kind of row affected (expense, attachment, transfer)
+	EntityType string `json:"entity_type"`
+
+	// EntityID identifies the affected row
+	EntityID string `json:"entity_id"`
+
+	// Description explains the problem
+	Description string `json:"description"`
+
+	// Fixable is true when the checker knows a safe automatic repair
+	Fixable bool `json:"fixable"`
+
+	// Fixed is true when the repair was applied in this run
+	Fixed bool `json:"fixed"`
+
+	// FixError explains why a repair failed
+	FixError string `json:"fix_error,omitempty"`
+}
+
+// IntegrityCheckResult is the outcome of one check
+type IntegrityCheckResult struct {
+	Check  string            `json:"check"`
+	Status string            `json:"status"`
+	Note   string            `json:"note,omitempty"`
+	Issues []*IntegrityIssue `json:"issues"`
+}
+
+// IntegrityReport is the repair report of a full integrity run
+type IntegrityReport struct {
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt time.Time               `json:"finished_at"`
+	AutoFix    bool                    `json:"auto_fix"`
+	Checks     []*IntegrityCheckResult `json:"checks"`
+	IssueCount int                     `json:"issue_count"`
+	FixedCount int                     `json:"fixed_count"`
+}
+
+// CategoryUsage is how many expenses use a category name
+type CategoryUsage struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+// IntegrityRepository provides the cross-table queries the integrity checks need
+// These queries look for rows that the normal repositories assume can't exist
+type IntegrityRepository interface {
+	// OrphanedAttachments returns attachments whose expense doesn't exist
+	OrphanedAttachments(ctx context.Context) ([]*Attachment, error)
+
+	// ListAttachments returns every attachment record
+	ListAttachments(ctx context.Context) ([]*Attachment, error)
+
+	// ExpensesWithMissingAccount returns expenses whose account_id points at no account
+	ExpensesWithMissingAccount(ctx context.Context) ([]*Expense, error)
+
+	// TransfersWithMissingAccount returns transfers from or to an account that doesn't exist
+	TransfersWithMissingAccount(ctx context.Context) ([]*Transfer, error)
+
+	// ClearExpenseAccount detaches an expense from its account
+	ClearExpenseAccount(ctx context.Context, expenseID string) error
+
+	// ExpensesWithBaseAmountDrift returns expenses where |base_amount - amount x exchange_rate| > tolerance
+	ExpensesWithBaseAmountDrift(ctx context.Context, tolerance float64) ([]*Expense, error)
+
+	// CountCategories returns how many categories are defined
+	CountCategories(ctx context.Context) (int64, error)
+
+	// UnknownExpenseCategories returns the categories used by expenses that aren't in the category list
+	UnknownExpenseCategories(ctx context.Context) ([]*CategoryUsage, error)
+}
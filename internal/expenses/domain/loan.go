@@ -0,0 +1,237 @@
+// Package domain contains the core business logic and entities
+// This file defines loans and debts the user is paying off, and their amortization
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"math"    // For the annuity formula
+	"sort"    // For ordering payments
+	"strings" // For input normalization
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// MaxLoanTermMonths is the longest term accepted, and how far ahead a payoff is projected
+const MaxLoanTermMonths = 600
+
+// Loan is a debt the user pays off in monthly instalments (a car loan, a student loan, a credit card balance)
+// Payments are ordinary expenses linked to the loan, so they count as spending too
+type Loan struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user who owes the loan; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// Name identifies the loan (e.g. "Car loan")
+	Name string `json:"name" gorm:"not null"`
+
+	// Principal is the amount borrowed, in Currency
+	Principal float64 `json:"principal" gorm:"not null"`
+	Currency  string  `json:"currency" gorm:"size:3;not null"`
+
+	// APR is the annual percentage rate (e.g. 6.5); interest accrues monthly at APR / 12
+	APR float64 `json:"apr" gorm:"not null;default:0"`
+
+	// MonthlyPayment is the scheduled instalment
+	MonthlyPayment float64 `json:"monthly_payment" gorm:"not null"`
+
+	// StartDate is the day the money was borrowed; the first instalment is due a month later
+	StartDate time.Time `json:"start_date" gorm:"not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewLoan creates a validated loan
+// Without a monthly payment it is derived from termMonths (the annuity that pays the loan off in that time);
+// a monthly payment that doesn't even cover the first month's interest could never pay the loan off
+func NewLoan(name string, principal float64, currency string, apr, monthlyPayment float64, termMonths int, start time.Time) (*Loan, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || principal <= 0 || apr < 0 || apr > 100 || monthlyPayment < 0 || start.IsZero() {
+		return nil, ErrInvalidLoan
+	}
+	code, err := NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	loan := &Loan{
+		ID:        uuid.New(),
+		Name:      name,
+		Principal: RoundAmount(principal),
+		Currency:  code,
+		APR:       apr,
+		StartDate: dayOf(start),
+	}
+	switch {
+	case monthlyPayment > 0:
+		loan.MonthlyPayment = RoundAmount(monthlyPayment)
+	case termMonths > 0 && termMonths <= MaxLoanTermMonths:
+		loan.MonthlyPayment = AnnuityPayment(loan.Principal, apr, termMonths)
+	default:
+		return nil, ErrInvalidLoan
+	}
+	if loan.MonthlyPayment <= monthlyInterest(loan.Principal, apr) {
+		return nil, ErrInvalidLoan
+	}
+	return loan, nil
+}
+
+// AnnuityPayment is the fixed monthly payment that pays off principal at apr percent in months instalments
+// It is rounded up to the cent so the last instalment isn't larger than the others
+func AnnuityPayment(principal, apr float64, months int) float64 {
+	rate := apr / 100 / 12
+	var payment float64
+	if rate == 0 {
+		payment = principal / float64(months)
+	} else {
+		payment = principal * rate / (1 - math.Pow(1+rate, -float64(months)))
+	}
+	return math.Ceil(payment*100-1e-6) / 100
+}
+
+// monthlyInterest is the interest balance accrues in one month at apr percent
+func monthlyInterest(balance, apr float64) float64 {
+	return RoundAmount(balance * apr / 100 / 12)
+}
+
+// AmortizationRow is one instalment: how it splits into interest and principal and what is left afterwards
+type AmortizationRow struct {
+	Date      time.Time `json:"date"`
+	Payment   float64   `json:"payment"`
+	Interest  float64   `json:"interest"`
+	Principal float64   `json:"principal"`
+	Balance   float64   `json:"balance"`
+
+	// ExpenseID is the expense that paid it; nil for projected instalments
+	ExpenseID *uuid.UUID `json:"expense_id,omitempty"`
+}
+
+// Amortization is the state of a loan after its recorded payments, and the projection until it is paid off
+type Amortization struct {
+	// Payments are the recorded payments in date order
+	Payments []*AmortizationRow `json:"payments"`
+
+	// Schedule are the projected instalments, starting the month after the last recorded payment
+	Schedule []*AmortizationRow `json:"schedule"`
+
+	// PrincipalPaid and InterestPaid split what has been paid so far
+	PrincipalPaid float64 `json:"principal_paid"`
+	InterestPaid  float64 `json:"interest_paid"`
+
+	// Balance is what is still owed today
+	Balance float64 `json:"balance"`
+
+	// ProjectedInterest is the interest still to pay if the schedule is kept
+	ProjectedInterest float64 `json:"projected_interest"`
+
+	// PayoffDate is when the last projected instalment is due (nil if paid off already)
+	PayoffDate *time.Time `json:"payoff_date,omitempty"`
+}
+
+// Amortize applies payments (in any order) to the loan and projects the rest of the schedule
+// Interest accrues monthly: each payment first pays the interest of every month since the previous
+// payment (at least one), then reduces the balance. A payment larger than the balance pays it off
+func (l *Loan) Amortize(payments []*Expense) *Amortization {
+	sorted := append([]*Expense(nil), payments...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	result := &Amortization{Payments: make([]*AmortizationRow, 0, len(sorted)), Schedule: make([]*AmortizationRow, 0)}
+	balance := l.Principal
+	last := l.StartDate
+
+	// Step 1: Apply the recorded payments
+	for _, payment := range sorted {
+		date := dayOf(payment.Date)
+		months := monthsBetween(last, date)
+		if months < 1 {
+			months = 1
+		}
+		interest := 0.0
+		for i := 0; i < months; i++ {
+			interest += monthlyInterest(balance+interest, l.APR)
+		}
+		row := l.applyPayment(&balance, payment.Amount.Float64(), RoundAmount(interest), date)
+		id := payment.ID
+		row.ExpenseID = &id
+		result.Payments = append(result.Payments, row)
+		result.PrincipalPaid += row.Principal
+		result.InterestPaid += row.Interest
+		if date.After(last) {
+			last = date
+		}
+	}
+	result.PrincipalPaid = RoundAmount(result.PrincipalPaid)
+	result.InterestPaid = RoundAmount(result.InterestPaid)
+	result.Balance = balance
+
+	// Step 2: Project the monthly instalments until nothing is left
+	// A payment that no longer covers the interest (after rate or payment changes) would never end
+	for n := 1; balance > 0 && n <= MaxLoanTermMonths; n++ {
+		interest := monthlyInterest(balance, l.APR)
+		if l.MonthlyPayment <= interest {
+			break
+		}
+		date := addMonths(last, n)
+		row := l.applyPayment(&balance, l.MonthlyPayment, interest, date)
+		result.Schedule = append(result.Schedule, row)
+		result.ProjectedInterest += row.Interest
+	}
+	result.ProjectedInterest = RoundAmount(result.ProjectedInterest)
+	if balance == 0 && len(result.Schedule) > 0 {
+		payoff := result.Schedule[len(result.Schedule)-1].Date
+		result.PayoffDate = &payoff
+	}
+	return result
+}
+
+// applyPayment pays interest and then principal from amount, lowering balance
+func (l *Loan) applyPayment(balance *float64, amount, interest float64, date time.Time) *AmortizationRow {
+	principal := RoundAmount(amount - interest)
+	if principal > *balance {
+		// The last instalment only pays what is left
+		principal = *balance
+		amount = RoundAmount(principal + interest)
+	}
+	*balance = RoundAmount(*balance - principal)
+	return &AmortizationRow{Date: date, Payment: RoundAmount(amount), Interest: interest, Principal: principal, Balance: *balance}
+}
+
+// monthsBetween counts the whole months from from to to (negative if to is earlier)
+func monthsBetween(from, to time.Time) int {
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	return months
+}
+
+// addMonths adds n months to date, keeping the day of month where possible
+// (the 31st falls on the last day of shorter months, as with recurring expenses)
+func addMonths(date time.Time, n int) time.Time {
+	months := int(date.Month()) - 1 + n
+	return clampedDate(date.Year()+months/12, time.Month(months%12+1), date.Day())
+}
+
+// LoanRepository defines the data access operations for loans
+// Every operation is limited to the loans of the caller in ctx
+type LoanRepository interface {
+	// Create saves a new loan owned by the caller
+	Create(ctx context.Context, loan *Loan) error
+
+	// GetByID retrieves one of the caller's loans, or returns ErrLoanNotFound
+	GetByID(ctx context.Context, id string) (*Loan, error)
+
+	// List returns the caller's loans, newest first
+	List(ctx context.Context) ([]*Loan, error)
+
+	// Delete removes one of the caller's loans and unlinks its payments, or returns ErrLoanNotFound
+	Delete(ctx context.Context, id string) error
+
+	// AssignPayments links the caller's expenses to the loan as payments
+	// It returns ErrExpenseNotFound (and links nothing) when one of the expenses isn't the caller's
+	AssignPayments(ctx context.Context, loanID uuid.UUID, expenseIDs []uuid.UUID) error
+
+	// RemovePayment unlinks a payment from the loan, or returns ErrExpenseNotFound
+	RemovePayment(ctx context.Context, loanID uuid.UUID, expenseID uuid.UUID) error
+}
@@ -0,0 +1,648 @@
+// Package domain contains the core business logic and entities
+// This file defines migrations from other expense apps: recognizing their exports and reading their rows
+package domain
+
+import (
+	"strconv" // For parsing amounts
+	"strings" // For matching column names and categories
+	"time"    // For parsing dates
+)
+
+// Migration limits
+const (
+	// MaxMigrationBytes is the largest export accepted; years of expenses fit in a few megabytes
+	MaxMigrationBytes = 20 << 20
+
+	// MaxMigrationRows is the most rows one export may have
+	MaxMigrationRows = 100000
+
+	// MaxMigrationProblems is how many problem rows an analysis lists; the rest are only counted
+	MaxMigrationProblems = 20
+
+	// MinMigrationConfidence is how much of an app's column signature an export must have to be taken for it
+	MinMigrationConfidence = 0.6
+
+	// genericMigrationConfidence is the confidence of the generic source: its columns are guessed
+	// from their names, so the user should check them before committing
+	genericMigrationConfidence = 0.5
+)
+
+// Reasons a row of an export doesn't become an expense
+const (
+	// MigrationSkipIncome is money received: income, refunds and inflows
+	MigrationSkipIncome = "income"
+
+	// MigrationSkipTransfer is money moved between the user's own accounts
+	MigrationSkipTransfer = "transfer"
+
+	// MigrationSkipInvalid is a row that can't be read, e.g. without a date or amount
+	MigrationSkipInvalid = "invalid"
+
+	// MigrationSkipDuplicate is an expense that already exists (same day, amount and description)
+	MigrationSkipDuplicate = "duplicate"
+)
+
+// How a category of an export was mapped onto the tenant's categories
+const (
+	// MappingExact is an existing category of the same name
+	MappingExact = "exact"
+
+	// MappingAlias is an existing category the name commonly means, e.g. Food for "Groceries"
+	MappingAlias = "alias"
+
+	// MappingSuggested is the category past expenses with the same descriptions mostly have
+	MappingSuggested = "suggested"
+
+	// MappingNew is a new category of the same name, created when the migration is committed
+	MappingNew = "new"
+
+	// MappingUncategorized leaves rows without a category as Uncategorized, like unrecognized imports
+	MappingUncategorized = "uncategorized"
+
+	// MappingManual is a category the user chose when committing
+	MappingManual = "manual"
+)
+
+// MigrationSource is an app whose exports can be migrated
+// Column names are lowercase; for each field the first column the export has is used
+type MigrationSource struct {
+	// ID names the source in requests, e.g. "ynab"
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// signature are the columns of the app's exports; an export with most of them comes from the app
+	signature []string
+
+	date        []string
+	description []string
+	amount      []string
+	category    []string
+	currency    []string
+
+	// outflow and inflow are the columns of apps that split amounts into money out and money in
+	outflow string
+	inflow  string
+
+	// kind is the column telling expenses from income, and expenseKinds its values for expenses
+	kind         string
+	expenseKinds []string
+
+	// negativeExpenses is set for apps exporting money spent as negative amounts
+	negativeExpenses bool
+
+	// dateLayouts are the date formats the app writes, most likely first
+	dateLayouts []string
+}
+
+// GenericMigrationSource reads exports of apps it doesn't know by guessing the columns from their names
+const GenericMigrationSource = "generic"
+
+// migrationSources are the apps whose exports are recognized
+var migrationSources = []*MigrationSource{
+	{
+		ID:           "mint",
+		Name:         "Mint",
+		signature:    []string{"date", "description", "original description", "amount", "transaction type", "category", "account name", "labels", "notes"},
+		date:         []string{"date"},
+		description:  []string{"description", "original description"},
+		amount:       []string{"amount"},
+		category:     []string{"category"},
+		kind:         "transaction type",
+		expenseKinds: []string{"debit"},
+		dateLayouts:  []string{"1/2/2006"},
+	},
+	{
+		ID:          "ynab",
+		Name:        "YNAB",
+		signature:   []string{"account", "flag", "date", "payee", "category group/category", "category group", "category", "memo", "outflow", "inflow", "cleared"},
+		date:        []string{"date"},
+		description: []string{"payee", "memo"},
+		category:    []string{"category"},
+		outflow:     "outflow",
+		inflow:      "inflow",
+		dateLayouts: []string{"01/02/2006", "02/01/2006", "2006-01-02"},
+	},
+	{
+		ID:               "spendee",
+		Name:             "Spendee",
+		signature:        []string{"date", "wallet", "type", "category name", "amount", "currency", "note", "labels", "author"},
+		date:             []string{"date"},
+		description:      []string{"note", "category name"},
+		amount:           []string{"amount"},
+		category:         []string{"category name"},
+		currency:         []string{"currency"},
+		kind:             "type",
+		expenseKinds:     []string{"expense"},
+		negativeExpenses: true,
+		dateLayouts:      []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"},
+	},
+	{
+		ID:          "toshl",
+		Name:        "Toshl",
+		signature:   []string{"date", "account", "category", "tags", "expense amount", "income amount", "currency", "in main currency", "main currency", "description"},
+		date:        []string{"date"},
+		description: []string{"description", "tags", "category"},
+		category:    []string{"category"},
+		currency:    []string{"currency"},
+		outflow:     "expense amount",
+		inflow:      "income amount",
+		dateLayouts: []string{"1/2/06", "2006-01-02", "02.01.2006"},
+	},
+	{
+		// Our own CSV export, for moving between installations
+		ID:          "myexpenses",
+		Name:        "MyExpenses",
+		signature:   []string{"id", "date", "description", "category", "amount", "currency", "base_amount", "base_currency", "merchant", "created_at"},
+		date:        []string{"date"},
+		description: []string{"description", "merchant"},
+		amount:      []string{"amount"},
+		category:    []string{"category"},
+		currency:    []string{"currency"},
+		dateLayouts: []string{"2006-01-02"},
+	},
+	{
+		ID:          GenericMigrationSource,
+		Name:        "Other app (CSV)",
+		date:        []string{"date", "transaction date", "booking date", "datum", "buchungstag", "fecha", "date d'opération"},
+		description: []string{"description", "payee", "merchant", "name", "title", "memo", "note", "notes", "details", "beschreibung", "verwendungszweck", "concepto", "libellé"},
+		amount:      []string{"amount", "value", "sum", "debit", "betrag", "importe", "montant"},
+		category:    []string{"category", "category name", "kategorie", "categoría", "catégorie"},
+		currency:    []string{"currency", "währung", "moneda", "devise"},
+	},
+}
+
+// migrationDateLayouts are tried after a source's own layouts
+// Day-first layouts come before month-first ones: most of the world writes dates that way
+var migrationDateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006/01/02",
+	"02.01.2006",
+	"2.1.2006",
+	"02/01/2006",
+	"01/02/2006",
+	"2/1/2006",
+	"1/2/2006",
+	"02.01.06",
+	"02/01/06",
+	"01/02/06",
+}
+
+// MigrationSources returns the apps whose exports can be migrated, the generic source last
+func MigrationSources() []*MigrationSource {
+	return migrationSources
+}
+
+// MigrationSourceByID returns the source called id, or ErrUnknownMigrationSource
+func MigrationSourceByID(id string) (*MigrationSource, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	for _, source := range migrationSources {
+		if source.ID == id {
+			return source, nil
+		}
+	}
+	return nil, ErrUnknownMigrationSource
+}
+
+// DetectMigrationSource works out which app an export comes from by its header line
+// Every app is scored by the share of its signature columns the header has; the best one
+// wins when it has at least MinMigrationConfidence. Otherwise the generic source is used when
+// it can find the columns it needs. It returns the source and how confident the guess is (0 to 1)
+func DetectMigrationSource(header []string) (*MigrationSource, float64, error) {
+	columns := migrationColumns(header)
+
+	var best *MigrationSource
+	var confidence float64
+	for _, source := range migrationSources {
+		if len(source.signature) == 0 {
+			continue
+		}
+		matched := 0
+		for _, column := range source.signature {
+			if _, ok := columns[column]; ok {
+				matched++
+			}
+		}
+		score := float64(matched) / float64(len(source.signature))
+		if score > confidence {
+			best, confidence = source, score
+		}
+	}
+	if best != nil && confidence >= MinMigrationConfidence {
+		return best, confidence, nil
+	}
+
+	generic, _ := MigrationSourceByID(GenericMigrationSource)
+	if _, err := NewMigrationReader(generic, header, nil); err != nil {
+		return nil, 0, err
+	}
+	return generic, genericMigrationConfidence, nil
+}
+
+// migrationColumns maps the lowercase names of an export's columns to their positions
+// Spreadsheet apps often start files with a byte order mark, which is dropped
+func migrationColumns(header []string) map[string]int {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := columns[name]; !ok && name != "" {
+			columns[name] = i
+		}
+	}
+	return columns
+}
+
+// MigrationRow is a row of an export, read as an expense
+type MigrationRow struct {
+	// Line is the row's line in the export, the header being line 1
+	Line int `json:"line"`
+
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`
+
+	// Currency is the row's currency ("" for the base currency)
+	Currency string `json:"currency,omitempty"`
+
+	// Category is the category in the other app ("" when the row has none)
+	Category string `json:"category"`
+
+	// Skip is why the row doesn't become an expense (one of the MigrationSkip* reasons, "" when it does)
+	// Problem says what is wrong with invalid rows
+	Skip    string `json:"skip,omitempty"`
+	Problem string `json:"problem,omitempty"`
+}
+
+// MigrationReader reads the rows of one export
+type MigrationReader struct {
+	source *MigrationSource
+
+	// The positions of the columns (-1 when the export doesn't have one)
+	date, amount, outflow, inflow, category, currency, kind int
+	description                                             []int
+
+	// dateLayout is the format of the export's dates
+	dateLayout string
+
+	negativeExpenses bool
+}
+
+// NewMigrationReader prepares reading an export of source with the given header line
+// records are the export's rows; the date format and, for the generic source, the sign of
+// expenses are worked out from them. An export lacking a date, amount or description column
+// returns ErrUnrecognizedExport
+func NewMigrationReader(source *MigrationSource, header []string, records [][]string) (*MigrationReader, error) {
+	columns := migrationColumns(header)
+	find := func(names ...string) int {
+		for _, name := range names {
+			if i, ok := columns[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// Step 1: Find the columns
+	r := &MigrationReader{
+		source:           source,
+		date:             find(source.date...),
+		amount:           find(source.amount...),
+		outflow:          find(source.outflow),
+		inflow:           find(source.inflow),
+		category:         find(source.category...),
+		currency:         find(source.currency...),
+		kind:             find(source.kind),
+		negativeExpenses: source.negativeExpenses,
+	}
+	for _, name := range source.description {
+		if i, ok := columns[name]; ok {
+			r.description = append(r.description, i)
+		}
+	}
+	if r.date < 0 || (r.amount < 0 && r.outflow < 0) || (len(r.description) == 0 && r.category < 0) {
+		return nil, ErrUnrecognizedExport
+	}
+
+	// Step 2: The date format is the first one every date of the export is in
+	var dates []string
+	for _, record := range records {
+		if value := field(record, r.date); value != "" {
+			dates = append(dates, value)
+		}
+	}
+	layouts := make([]string, 0, len(source.dateLayouts)+len(migrationDateLayouts))
+	layouts = append(append(layouts, source.dateLayouts...), migrationDateLayouts...)
+	r.dateLayout = detectDateLayout(layouts, dates)
+
+	// Step 3: Apps we don't know may write money spent as negative amounts; when most amounts
+	// are negative, those are the expenses and the positive ones income
+	if source.ID == GenericMigrationSource && r.amount >= 0 {
+		negative := 0
+		for _, record := range records {
+			if amount, ok := parseMigrationAmount(field(record, r.amount)); ok && amount < 0 {
+				negative++
+			}
+		}
+		r.negativeExpenses = negative*2 > len(records)
+	}
+	return r, nil
+}
+
+// DateLayout returns the format of the export's dates, in Go's reference time notation
+func (r *MigrationReader) DateLayout() string {
+	return r.dateLayout
+}
+
+// Read reads one row of the export
+func (r *MigrationReader) Read(line int, record []string) *MigrationRow {
+	row := &MigrationRow{Line: line, Category: field(record, r.category)}
+	invalid := func(problem string) *MigrationRow {
+		row.Skip, row.Problem = MigrationSkipInvalid, problem
+		return row
+	}
+
+	// Step 1: Transfers between the user's accounts aren't spending
+	kind := strings.ToLower(field(record, r.kind))
+	if strings.Contains(kind, "transfer") || isTransferCategory(row.Category) ||
+		strings.HasPrefix(strings.ToLower(field(record, r.firstDescription())), "transfer :") {
+		row.Skip = MigrationSkipTransfer
+		return row
+	}
+
+	// Step 2: The amount, and whether it is money spent
+	var amount float64
+	if r.outflow >= 0 {
+		out, _ := parseMigrationAmount(field(record, r.outflow))
+		in, _ := parseMigrationAmount(field(record, r.inflow))
+		switch {
+		case out != 0:
+			amount = out
+		case in != 0:
+			row.Skip = MigrationSkipIncome
+			return row
+		default:
+			return invalid("no amount")
+		}
+	} else {
+		value, ok := parseMigrationAmount(field(record, r.amount))
+		if !ok {
+			return invalid("unreadable amount")
+		}
+		amount = value
+		switch {
+		case r.kind >= 0:
+			if !containsString(r.source.expenseKinds, kind) {
+				row.Skip = MigrationSkipIncome
+				return row
+			}
+			if amount < 0 {
+				amount = -amount
+			}
+		case r.negativeExpenses:
+			amount = -amount
+		}
+		if amount < 0 {
+			row.Skip = MigrationSkipIncome
+			return row
+		}
+	}
+	if amount < 0 {
+		// Outflow columns hold money spent either way; some apps write it with a minus
+		amount = -amount
+	}
+	if amount == 0 {
+		return invalid("amount is zero")
+	}
+	row.Amount = amount
+
+	// Step 3: The date
+	date, err := time.Parse(r.dateLayout, field(record, r.date))
+	if r.dateLayout == "" || err != nil {
+		return invalid("unreadable date")
+	}
+	row.Date = date
+
+	// Step 4: The description; rows without one are named after their category
+	for _, i := range r.description {
+		if row.Description = field(record, i); row.Description != "" {
+			break
+		}
+	}
+	if row.Description == "" {
+		row.Description = row.Category
+	}
+	if row.Description == "" {
+		return invalid("no description")
+	}
+
+	// Step 5: The currency, when the export has one
+	if currency := field(record, r.currency); currency != "" {
+		normalized, err := NormalizeCurrency(currency)
+		if err != nil {
+			return invalid("unknown currency " + currency)
+		}
+		row.Currency = normalized
+	}
+	return row
+}
+
+// firstDescription returns the position of the first description column (-1 when there is none)
+func (r *MigrationReader) firstDescription() int {
+	if len(r.description) == 0 {
+		return -1
+	}
+	return r.description[0]
+}
+
+// field returns the trimmed value of column i of record ("" when it doesn't have one)
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransferCategory reports whether an app files the row as a transfer between accounts
+// Paying off a credit card is one too: the purchases on the card are the expenses
+func isTransferCategory(category string) bool {
+	category = strings.ToLower(category)
+	return strings.Contains(category, "transfer") || category == "credit card payment"
+}
+
+// detectDateLayout returns the first of layouts every date is in ("" when none fits)
+// Ambiguous dates like 03/04/2024 fit several layouts; the order of layouts decides, but a single
+// 13/04/2024 in the export rules out month-first layouts for all of them
+func detectDateLayout(layouts []string, dates []string) string {
+	for _, layout := range layouts {
+		fits := true
+		for _, date := range dates {
+			if _, err := time.Parse(layout, date); err != nil {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return layout
+		}
+	}
+	return ""
+}
+
+// parseMigrationAmount reads an amount the way apps write them: with currency symbols or codes,
+// with either "." or "," as decimal separator, with thousands separators, and negative with a
+// minus or in parentheses. It reports false when value holds no number
+func parseMigrationAmount(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+	}
+
+	// Step 1: Keep the digits and separators
+	var digits strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == ',':
+			digits.WriteRune(r)
+		case r == '-', r == '−':
+			negative = true
+		}
+	}
+	number := digits.String()
+	if number == "" {
+		return 0, false
+	}
+
+	// Step 2: The last separator is the decimal one, unless it is followed by exactly three digits
+	// and is a comma (1,234) or repeated (1.234.567): then there are only thousands separators
+	dot, comma := strings.LastIndex(number, "."), strings.LastIndex(number, ",")
+	switch {
+	case dot >= 0 && comma >= 0 && comma > dot:
+		number = strings.ReplaceAll(number, ".", "")
+		number = strings.Replace(number, ",", ".", 1)
+	case dot >= 0 && comma >= 0:
+		number = strings.ReplaceAll(number, ",", "")
+	case comma >= 0 && (len(number)-comma-1 == 3 || strings.Count(number, ",") > 1):
+		number = strings.ReplaceAll(number, ",", "")
+	case comma >= 0:
+		number = strings.Replace(number, ",", ".", 1)
+	case strings.Count(number, ".") > 1:
+		number = strings.ReplaceAll(number, ".", "")
+	}
+
+	amount, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, true
+}
+
+// categoryAliases maps the names other apps commonly give categories to the canonical starter
+// category they mean (see DefaultCategories)
+var categoryAliases = map[string]string{
+	"groceries":              "Food",
+	"restaurants":            "Food",
+	"food & dining":          "Food",
+	"dining":                 "Food",
+	"eating out":             "Food",
+	"fast food":              "Food",
+	"coffee shops":           "Food",
+	"auto & transport":       "Transportation",
+	"transport":              "Transportation",
+	"car":                    "Transportation",
+	"gas & fuel":             "Transportation",
+	"fuel":                   "Transportation",
+	"parking":                "Transportation",
+	"public transportation":  "Transportation",
+	"taxi":                   "Transportation",
+	"home":                   "Housing",
+	"rent":                   "Housing",
+	"mortgage & rent":        "Housing",
+	"bills & utilities":      "Utilities",
+	"bills":                  "Utilities",
+	"internet":               "Utilities",
+	"mobile phone":           "Utilities",
+	"phone":                  "Utilities",
+	"health & fitness":       "Health",
+	"healthcare":             "Health",
+	"doctor":                 "Health",
+	"pharmacy":               "Health",
+	"movies & dvds":          "Entertainment",
+	"music":                  "Entertainment",
+	"hobbies":                "Entertainment",
+	"subscriptions":          "Entertainment",
+	"clothing":               "Shopping",
+	"electronics & software": "Shopping",
+	"gifts":                  "Shopping",
+	"vacation":               "Travel",
+	"hotel":                  "Travel",
+	"air travel":             "Travel",
+	"fees & charges":         "Bank Fees",
+	"bank fee":               "Bank Fees",
+	"atm fee":                "Bank Fees",
+	"service fee":            "Bank Fees",
+	"finance charge":         "Interest",
+	"miscellaneous":          "Other",
+}
+
+// MatchCategory finds the existing category a category of another app means
+// existing are the names of the tenant's categories. An existing category of the same name
+// (ignoring case) is an exact match; one the name is a common alias or translation of a starter
+// category for is an alias match. It returns "" when no existing category fits
+func MatchCategory(name string, existing []string) (string, string) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", ""
+	}
+	for _, category := range existing {
+		if strings.ToLower(category) == name {
+			return category, MappingExact
+		}
+	}
+
+	canonical, ok := categoryAliases[name]
+	if !ok {
+		canonical = canonicalCategory(name)
+	}
+	if canonical == "" {
+		return "", ""
+	}
+	for _, category := range existing {
+		if canonicalCategory(category) == canonical {
+			return category, MappingAlias
+		}
+	}
+	return "", ""
+}
+
+// canonicalCategory returns the canonical starter category name is the name of in any
+// supported language ("" when it isn't one)
+func canonicalCategory(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, canonical := range DefaultCategories {
+		if strings.ToLower(canonical) == name {
+			return canonical
+		}
+		for _, translations := range categoryTranslations {
+			if strings.ToLower(translations[canonical]) == name {
+				return canonical
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,235 @@
+// Package domain contains the core business logic and entities
+// This file defines Money: amounts kept as whole minor units, so sums and splits are exact
+package domain
+
+import (
+	"database/sql/driver" // For storing amounts as NUMERIC
+	"errors"              // For parse errors
+	"fmt"                 // For formatted string operations and error wrapping
+	"math"                // For rounding floats to minor units
+	"strconv"             // For parsing the integer and fraction parts
+	"strings"             // For splitting decimals
+)
+
+// MinorUnits is how many minor units make one unit of a currency
+// Every currency is kept in hundredths, like RoundAmount always did: currencies without cents
+// simply have whole hundreds, and amounts are never finer than what a card statement shows
+const MinorUnits = 100
+
+// ErrInvalidMoney occurs when an amount isn't a decimal number or is too large
+var ErrInvalidMoney = errors.New("invalid amount: must be a decimal number")
+
+// maxMoney is the largest amount in units that fits NUMERIC(19,2) and int64 minor units
+const maxMoney = math.MaxInt64 / MinorUnits
+
+// Money is an amount of a currency in whole minor units (cents)
+// Adding and splitting amounts is exact integer arithmetic; only conversions with a rate go
+// through floats and are rounded back to cents. Amounts are stored as NUMERIC and sent as JSON
+// numbers ("12.5" is 1250 minor units), so the API looks the same as with float amounts
+// Currency is empty where the currency is kept next to the amount, like on an expense
+type Money struct {
+	Minor    int64
+	Currency string
+}
+
+// NewMoney returns amount of currency rounded to the nearest cent, halves away from zero
+// It is for amounts that come out of float arithmetic, e.g. a conversion with an exchange rate
+func NewMoney(amount float64, currency string) Money {
+	return Money{Minor: int64(math.Round(amount * MinorUnits)), Currency: currency}
+}
+
+// Cents returns the amount of minor units of currency
+func Cents(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: currency}
+}
+
+// ParseMoney parses a decimal number ("12.5", "-0.05", "1e3") exactly, rounding digits beyond
+// the cent half away from zero
+func ParseMoney(text, currency string) (Money, error) {
+	text = strings.TrimSpace(text)
+	if strings.ContainsAny(text, "eE") {
+		// Exponents only come from JSON encoders writing large or tiny floats; they round like floats
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) || math.Abs(value) > maxMoney {
+			return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, text)
+		}
+		return NewMoney(value, currency), nil
+	}
+
+	// At most one sign: "--5" and "+-5" aren't numbers
+	unsigned := strings.TrimPrefix(text, "-")
+	negative := unsigned != text
+	if !negative {
+		unsigned = strings.TrimPrefix(text, "+")
+	}
+	whole, fraction, _ := strings.Cut(unsigned, ".")
+	if whole == "" && fraction == "" {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, text)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if !digits(whole) || !digits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, text)
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, text)
+	}
+
+	// Two digits are cents; the third decides the rounding
+	padded := fraction + "000"
+	cents, _ := strconv.ParseInt(padded[:2], 10, 64)
+	if padded[2] >= '5' {
+		cents++
+	}
+	// Checked before multiplying, so the minor units can't overflow
+	if units > (math.MaxInt64-cents)/MinorUnits {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, text)
+	}
+	minor := units*MinorUnits + cents
+	if negative {
+		minor = -minor
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// digits reports whether s is made of decimal digits only
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Float64 returns the amount in units, for rates, percentages and charts
+// Don't add the results up: add the Money values
+func (m Money) Float64() float64 {
+	return float64(m.Minor) / MinorUnits
+}
+
+// String returns the amount as a decimal number with two decimals, e.g. "-12.50"
+func (m Money) String() string {
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/MinorUnits, minor%MinorUnits)
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+// IsNegative reports whether the amount is below zero, like a refund
+func (m Money) IsNegative() bool {
+	return m.Minor < 0
+}
+
+// Add returns m + other; both must be in the same currency
+// The result has m's currency, or other's when m has none (e.g. the zero Money a sum starts from)
+func (m Money) Add(other Money) Money {
+	if m.Currency == "" {
+		m.Currency = other.Currency
+	}
+	m.Minor += other.Minor
+	return m
+}
+
+// Sub returns m - other; both must be in the same currency
+func (m Money) Sub(other Money) Money {
+	return m.Add(other.Neg())
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	m.Minor = -m.Minor
+	return m
+}
+
+// Abs returns m without its sign
+func (m Money) Abs() Money {
+	if m.Minor < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// Mul returns m times factor (e.g. an exchange rate) rounded to the nearest cent
+func (m Money) Mul(factor float64) Money {
+	m.Minor = int64(math.Round(float64(m.Minor) * factor))
+	return m
+}
+
+// In returns the same amount labelled with currency
+func (m Money) In(currency string) Money {
+	m.Currency = currency
+	return m
+}
+
+// Value implements driver.Valuer: amounts are stored as exact decimals in NUMERIC columns
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan implements sql.Scanner for NUMERIC columns, and for the float columns of databases
+// that weren't migrated yet
+func (m *Money) Scan(src any) error {
+	var parsed Money
+	var err error
+	switch value := src.(type) {
+	case nil:
+		parsed = Money{}
+	case string:
+		parsed, err = ParseMoney(value, "")
+	case []byte:
+		parsed, err = ParseMoney(string(value), "")
+	case float64:
+		parsed = NewMoney(value, "")
+	case int64:
+		parsed = Money{Minor: value * MinorUnits}
+	default:
+		return fmt.Errorf("%w: can't scan %T", ErrInvalidMoney, src)
+	}
+	if err != nil {
+		return err
+	}
+	m.Minor = parsed.Minor
+	return nil
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimals
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number, or a string holding one, without going through a float
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	parsed, err := ParseMoney(text, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// SumMoney adds amounts of one currency up exactly
+func SumMoney(amounts ...Money) Money {
+	var total Money
+	for _, amount := range amounts {
+		total = total.Add(amount)
+	}
+	return total
+}
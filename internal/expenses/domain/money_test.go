@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    int64
+		wantErr bool
+	}{
+		// Plain decimals
+		{text: "12", want: 1200},
+		{text: "12.5", want: 1250},
+		{text: "12.50", want: 1250},
+		{text: "0.05", want: 5},
+		{text: ".5", want: 50},
+		{text: "7.", want: 700},
+		{text: "007.10", want: 710},
+		{text: "  3.20 ", want: 320},
+		{text: "0", want: 0},
+
+		// One sign at most
+		{text: "-12.5", want: -1250},
+		{text: "+12.5", want: 1250},
+		{text: "-.05", want: -5},
+		{text: "--5", wantErr: true},
+		{text: "+-5", wantErr: true},
+		{text: "-+5", wantErr: true},
+		{text: "-+-5", wantErr: true},
+		{text: "++5", wantErr: true},
+		{text: "5-", wantErr: true},
+
+		// Digits beyond the cent round half away from zero
+		{text: "0.004", want: 0},
+		{text: "0.005", want: 1},
+		{text: "1.239", want: 124},
+		{text: "-0.005", want: -1},
+		{text: "-2.994", want: -299},
+		{text: "9.995", want: 1000},
+
+		// Exponents round like floats
+		{text: "1e3", want: 100000},
+		{text: "1.5E-2", want: 2},
+		{text: "-2.5e1", want: -2500},
+
+		// Not numbers
+		{text: "", wantErr: true},
+		{text: "-", wantErr: true},
+		{text: ".", wantErr: true},
+		{text: "abc", wantErr: true},
+		{text: "1.2.3", wantErr: true},
+		{text: "1,50", wantErr: true},
+		{text: "1. 5", wantErr: true},
+		{text: "0x10", wantErr: true},
+		{text: "NaN", wantErr: true},
+		{text: "1e", wantErr: true},
+
+		// The range of int64 minor units
+		{text: "92233720368547758.07", want: math.MaxInt64},
+		{text: "-92233720368547758.07", want: -math.MaxInt64},
+		{text: "92233720368547758.08", wantErr: true},
+		{text: "92233720368547758.99", wantErr: true},
+		{text: "92233720368547758.069", want: math.MaxInt64},
+		{text: "92233720368547758.075", wantErr: true},
+		{text: "92233720368547759", wantErr: true},
+		{text: "99999999999999999999", wantErr: true},
+		{text: "1e18", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseMoney(tt.text, "EUR")
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidMoney) {
+					t.Fatalf("ParseMoney(%q) = %v, %v; want ErrInvalidMoney", tt.text, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMoney(%q) error = %v", tt.text, err)
+			}
+			if got.Minor != tt.want || got.Currency != "EUR" {
+				t.Errorf("ParseMoney(%q) = %d %s, want %d EUR", tt.text, got.Minor, got.Currency, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		minor int64
+		want  string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{50, "0.50"},
+		{1250, "12.50"},
+		{100000, "1000.00"},
+		{-5, "-0.05"},
+		{-1250, "-12.50"},
+		{math.MaxInt64, "92233720368547758.07"},
+		{-math.MaxInt64, "-92233720368547758.07"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			money := Cents(tt.minor, "EUR")
+			if got := money.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			// What String writes, ParseMoney reads back
+			parsed, err := ParseMoney(money.String(), "EUR")
+			if err != nil || parsed != money {
+				t.Errorf("ParseMoney(String()) = %v, %v; want %v", parsed, err, money)
+			}
+		})
+	}
+}
+
+func TestExpenseJSONOmitsZeroTax(t *testing.T) {
+	expense := &Expense{Amount: Cents(1190, "EUR"), Currency: "EUR"}
+	data, err := json.Marshal(expense)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), "net_amount") || strings.Contains(string(data), "tax_amount") {
+		t.Errorf("Marshal() = %s, want no net_amount or tax_amount without VAT information", data)
+	}
+
+	expense.NetAmount, expense.TaxAmount = Cents(1000, "EUR"), Cents(190, "EUR")
+	if data, err = json.Marshal(expense); err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"net_amount":10.00`) || !strings.Contains(string(data), `"tax_amount":1.90`) {
+		t.Errorf("Marshal() = %s, want net_amount 10.00 and tax_amount 1.90", data)
+	}
+}
@@ -0,0 +1,146 @@
+// Package domain contains the core business logic and entities
+// This file defines the description normalization pipeline used for imports, dedup and autocomplete
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"regexp"  // For recognizing store numbers
+	"strings" // For string manipulation
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// NormalizationStep is a single transformation of a description
+// Steps are pure functions so they can be combined in any order
+type NormalizationStep func(string) string
+
+// Names of the built-in normalization steps
+// They are used to configure the pipeline per deployment (see NORMALIZATION_STEPS)
+const (
+	StepStripStoreNumbers  = "strip_store_numbers"
+	StepTransliterate      = "transliterate"
+	StepCollapseWhitespace = "collapse_whitespace"
+	StepUserRules          = "user_rules"
+)
+
+// DefaultNormalizationSteps is the pipeline used when nothing else is configured
+var DefaultNormalizationSteps = []string{
+	StepStripStoreNumbers,
+	StepTransliterate,
+	StepUserRules,
+	StepCollapseWhitespace,
+}
+
+// BuiltinNormalizationSteps maps step names to their implementation
+// StepUserRules is not listed here because it needs the rules from the repository
+var BuiltinNormalizationSteps = map[string]NormalizationStep{
+	StepStripStoreNumbers:  StripStoreNumbers,
+	StepTransliterate:      Transliterate,
+	StepCollapseWhitespace: CollapseWhitespace,
+}
+
+// storeNumberPattern matches store/branch numbers printed by card terminals
+// Examples: "#1234", "NO. 55", "STORE 0042", and standalone numbers with 3+ digits
+var storeNumberPattern = regexp.MustCompile(`(?i)(#\s*\d+|\b(no|nr|store|str)\.?\s*\d+\b|\b\d{3,}\b)`)
+
+// StripStoreNumbers removes store and terminal numbers
+// "SHELL #1234 LONDON" and "SHELL 0099 LONDON" both become "SHELL  LONDON"
+func StripStoreNumbers(s string) string {
+	return storeNumberPattern.ReplaceAllString(s, " ")
+}
+
+// transliterations maps common non-ASCII Latin letters to their ASCII spelling
+// This keeps "Café Müller" and "CAFE MULLER" from the bank statement identical
+var transliterations = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a", "ā", "a",
+	"Á", "A", "À", "A", "Â", "A", "Ä", "A", "Ã", "A", "Å", "A", "Ā", "A",
+	"é", "e", "è", "e", "ê", "e", "ë", "e", "ē", "e", "ę", "e",
+	"É", "E", "È", "E", "Ê", "E", "Ë", "E", "Ē", "E", "Ę", "E",
+	"í", "i", "ì", "i", "î", "i", "ï", "i", "Í", "I", "Ì", "I", "Î", "I", "Ï", "I",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o", "ő", "o",
+	"Ó", "O", "Ò", "O", "Ô", "O", "Ö", "O", "Õ", "O", "Ø", "O", "Ő", "O",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u", "ű", "u", "Ú", "U", "Ù", "U", "Û", "U", "Ü", "U", "Ű", "U",
+	"ç", "c", "Ç", "C", "ć", "c", "č", "c", "Č", "C",
+	"ñ", "n", "Ñ", "N", "ń", "n", "ł", "l", "Ł", "L",
+	"š", "s", "Š", "S", "ś", "s", "ž", "z", "Ž", "Z", "ż", "z", "ź", "z",
+	"ß", "ss", "æ", "ae", "Æ", "AE", "œ", "oe", "Œ", "OE",
+)
+
+// Transliterate replaces accented Latin letters with plain ASCII letters
+func Transliterate(s string) string {
+	return transliterations.Replace(s)
+}
+
+// CollapseWhitespace trims the text and replaces runs of whitespace with a single space
+func CollapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// NormalizationRule is a user-defined replacement applied by the StepUserRules step
+// Example: Find "AMZN MKTP" Replace "Amazon" turns cryptic statement text into something readable
+type NormalizationRule struct {
+	// ID is a unique identifier for each rule
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// Find is the text to look for (case-insensitive)
+	Find string `json:"find" gorm:"not null"`
+
+	// Replace is the text inserted instead (may be empty to delete the match)
+	Replace string `json:"replace"`
+
+	// Position orders the rules; lower positions run first
+	Position int `json:"position" gorm:"not null;default:0"`
+
+	// CreatedAt is automatically set when the rule is first saved
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewNormalizationRule creates a validated replacement rule
+func NewNormalizationRule(find, replace string, position int) (*NormalizationRule, error) {
+	if strings.TrimSpace(find) == "" {
+		return nil, ErrInvalidNormalizationRule
+	}
+	return &NormalizationRule{
+		ID:       uuid.New(),
+		Find:     find,
+		Replace:  replace,
+		Position: position,
+	}, nil
+}
+
+// Apply replaces every case-insensitive occurrence of Find in s
+func (r *NormalizationRule) Apply(s string) string {
+	pattern := regexp.MustCompile("(?i)" + regexp.QuoteMeta(r.Find))
+	return pattern.ReplaceAllLiteralString(s, r.Replace)
+}
+
+// NormalizationRuleRepository defines the data access operations for replacement rules
+type NormalizationRuleRepository interface {
+	// Create saves a new rule
+	Create(ctx context.Context, rule *NormalizationRule) error
+
+	// List returns all rules in the order they are applied
+	List(ctx context.Context) ([]*NormalizationRule, error)
+
+	// Delete removes a rule by its ID
+	Delete(ctx context.Context, id string) error
+}
+
+// DescriptionSuggestion is an autocomplete entry built from past expenses
+type DescriptionSuggestion struct {
+	// Description is the normalized description
+	Description string `json:"description"`
+
+	// Category is the category most recently used with this description
+	Category string `json:"category"`
+
+	// Count is how many expenses used this description
+	Count int64 `json:"count"`
+}
+
+// AutocompleteRepository provides description suggestions from past expenses
+type AutocompleteRepository interface {
+	// SuggestDescriptions returns up to limit normalized descriptions starting with prefix, most used first
+	SuggestDescriptions(ctx context.Context, prefix string, limit int) ([]*DescriptionSuggestion, error)
+}
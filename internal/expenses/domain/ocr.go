@@ -0,0 +1,179 @@
+// Package domain contains the core business logic and entities
+// This file defines how a tenant's receipts are recognized: the OCR provider and the languages they are in
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"io"      // For streaming attachment content to providers
+	"strings" // For normalizing language tags
+	"time"    // For handling dates and times
+)
+
+// OCR providers a tenant can choose between
+const (
+	// OCRProviderLocal runs tesseract on the server: free, but weak on crumpled or dim photos
+	OCRProviderLocal = "local"
+
+	// OCRProviderCloud is a hosted OCR service: more accurate on photos, but paid per page
+	OCRProviderCloud = "cloud"
+)
+
+// MaxOCRLanguages is the most languages a tenant may list
+// Every extra language makes recognition slower and a wrong guess between them more likely
+const MaxOCRLanguages = 5
+
+// ocrLanguages maps the supported ISO 639-1 codes to tesseract's language data names
+var ocrLanguages = map[string]string{
+	"en": "eng",
+	"de": "deu",
+	"fr": "fra",
+	"es": "spa",
+	"it": "ita",
+	"nl": "nld",
+	"pt": "por",
+	"pl": "pol",
+	"cs": "ces",
+	"da": "dan",
+	"sv": "swe",
+	"nb": "nor",
+	"fi": "fin",
+	"tr": "tur",
+	"ru": "rus",
+	"ja": "jpn",
+	"zh": "chi_sim",
+	"ko": "kor",
+}
+
+// OCRSettings is how a tenant's receipts are recognized
+// There is at most one per tenant; tenants without one get the deployment's defaults
+type OCRSettings struct {
+	TenantID string `json:"tenant_id" gorm:"primaryKey"`
+
+	// Provider is the OCR provider receipts are recognized with (one of the OCRProvider* names)
+	Provider string `json:"provider" gorm:"size:16;not null"`
+
+	// Languages are what receipts are written in, as language tags with an optional region
+	// ("de", "en-GB"), most common first. The region helps hosted OCR read local formats
+	Languages []string `json:"languages" gorm:"serializer:json"`
+
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewOCRSettings creates a tenant's OCR settings with validation
+// Language tags are normalized to the usual case ("en-gb" becomes "en-GB") and deduplicated
+func NewOCRSettings(tenantID, provider string, languages []string) (*OCRSettings, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider != OCRProviderLocal && provider != OCRProviderCloud {
+		return nil, ErrInvalidOCRSettings
+	}
+	if len(languages) == 0 || len(languages) > MaxOCRLanguages {
+		return nil, ErrInvalidOCRSettings
+	}
+
+	settings := &OCRSettings{TenantID: tenantID, Provider: provider, Languages: make([]string, 0, len(languages))}
+	seen := map[string]bool{}
+	for _, tag := range languages {
+		normalized, ok := NormalizeOCRLanguage(tag)
+		if !ok {
+			return nil, ErrInvalidOCRSettings
+		}
+		if !seen[normalized] {
+			seen[normalized] = true
+			settings.Languages = append(settings.Languages, normalized)
+		}
+	}
+	return settings, nil
+}
+
+// OCRLanguages returns the supported ISO 639-1 language codes
+func OCRLanguages() []string {
+	codes := make([]string, 0, len(ocrLanguages))
+	for code := range ocrLanguages {
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// NormalizeOCRLanguage checks a language tag such as "de" or "en_gb" and returns it in the
+// usual case with a hyphen ("en-GB"). It reports false for unsupported languages and for
+// regions that aren't two letters or three digits
+func NormalizeOCRLanguage(tag string) (string, bool) {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+	language := strings.ToLower(parts[0])
+	if _, ok := ocrLanguages[language]; !ok || len(parts) > 2 {
+		return "", false
+	}
+	if len(parts) == 1 {
+		return language, true
+	}
+
+	region := strings.ToUpper(parts[1])
+	valid := len(region) == 2 || len(region) == 3
+	for _, r := range region {
+		if len(region) == 2 && (r < 'A' || r > 'Z') || len(region) == 3 && (r < '0' || r > '9') {
+			valid = false
+		}
+	}
+	if !valid {
+		return "", false
+	}
+	return language + "-" + region, true
+}
+
+// TesseractLanguages returns tesseract's language list for language tags, e.g. "deu+eng"
+// Tesseract has no notion of regions, so they are dropped
+func TesseractLanguages(tags []string) string {
+	var names []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		language, _, _ := strings.Cut(tag, "-")
+		if name, ok := ocrLanguages[strings.ToLower(language)]; ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "+")
+}
+
+// OCRProvider recognizes the text of attachments in the given languages
+type OCRProvider interface {
+	// Recognize reads the file content from r and returns the recognized text
+	// contentType tells the provider how to interpret the bytes; languages are language tags,
+	// most likely first (empty uses the provider's default)
+	Recognize(ctx context.Context, contentType string, r io.Reader, languages []string) (string, error)
+}
+
+// OCRUsage counts a tenant's recognitions with one provider in one month
+// Hosted OCR is billed per page, so tenants compare providers by it
+type OCRUsage struct {
+	TenantID string `json:"-" gorm:"primaryKey"`
+	Provider string `json:"provider" gorm:"primaryKey;size:16"`
+
+	// Month is the calendar month in UTC, e.g. "2024-05"
+	Month string `json:"month" gorm:"primaryKey;size:7"`
+
+	// Documents counts the attachments sent to the provider, Failures those it couldn't read
+	Documents int64 `json:"documents" gorm:"not null;default:0"`
+	Failures  int64 `json:"failures" gorm:"not null;default:0"`
+
+	// Characters is how much text the provider recognized, a rough measure of how well it reads
+	Characters int64 `json:"characters" gorm:"not null;default:0"`
+}
+
+// OCRRepository defines how OCR settings and usage are stored
+type OCRRepository interface {
+	// GetSettings retrieves a tenant's OCR settings, or returns ErrOCRSettingsNotFound
+	GetSettings(ctx context.Context, tenantID string) (*OCRSettings, error)
+
+	// SaveSettings creates or replaces a tenant's OCR settings
+	SaveSettings(ctx context.Context, settings *OCRSettings) error
+
+	// DeleteSettings removes a tenant's OCR settings, or returns ErrOCRSettingsNotFound
+	DeleteSettings(ctx context.Context, tenantID string) error
+
+	// RecordUsage adds one recognition to a tenant's usage of a provider in a month
+	RecordUsage(ctx context.Context, usage *OCRUsage) error
+
+	// ListUsage returns a tenant's usage, most recent month first
+	ListUsage(ctx context.Context, tenantID string) ([]*OCRUsage, error)
+}
@@ -0,0 +1,29 @@
+// Package domain contains the core business logic and entities
+// This file defines spending patterns: when in the week and day money is spent
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For handling dates and times
+)
+
+// PatternCell is what was spent in one hour of one weekday over a period
+// Most expenses only have a day; they are counted with a nil Hour. Only expenses imported with a
+// time of day (e.g. from card feeds and bank exports) tell the hour
+type PatternCell struct {
+	Weekday time.Weekday
+	Hour    *int
+
+	// Amount is the sum of the reporting (base currency) amounts
+	Amount Money
+
+	// Count is the number of expenses
+	Count int
+}
+
+// PatternRepository aggregates the caller's spending by weekday and hour of day
+type PatternRepository interface {
+	// SpendingByWeekdayHour sums the caller's expenses dated in [from, to) per weekday and hour
+	// Expenses with a time of day are placed in location; those without one stay on their date
+	SpendingByWeekdayHour(ctx context.Context, from, to time.Time, location *time.Location) ([]*PatternCell, error)
+}
@@ -0,0 +1,140 @@
+// Package domain contains the core business logic and entities
+// This file defines planned purchases: an estimate of something the user means to buy,
+// later linked to the actual expense so the accuracy of estimates can be tracked
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"math"    // For absolute deviations
+	"strings" // For input normalization
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Planned purchase statuses
+const (
+	// PlannedPurchaseOpen hasn't been bought yet
+	PlannedPurchaseOpen = "open"
+
+	// PlannedPurchaseBought is linked to the expense that paid for it
+	PlannedPurchaseBought = "bought"
+)
+
+// PlannedPurchase is a purchase the user plans, with an estimate of what it will cost
+// Once bought it is linked to the actual expense; amounts are in the home currency
+type PlannedPurchase struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user who plans the purchase; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// Description says what is to be bought (e.g. "New laptop")
+	Description string `json:"description" gorm:"not null"`
+
+	// Category is the category the expense is expected in (optional)
+	Category string `json:"category,omitempty"`
+
+	// EstimatedAmount is what the purchase is expected to cost
+	EstimatedAmount Money `json:"estimated_amount" gorm:"type:numeric(19,2);not null"`
+
+	// PlannedFor is the day the purchase is planned for (nil for "some time")
+	PlannedFor *time.Time `json:"planned_for,omitempty"`
+
+	// ExpenseID is the actual expense (nil until bought); an expense pays for one planned purchase at most
+	ExpenseID *uuid.UUID `json:"expense_id,omitempty" gorm:"type:uuid;uniqueIndex"`
+
+	// ActualAmount and BoughtOn are the expense's amount and date when it was linked
+	ActualAmount Money      `json:"actual_amount,omitzero" gorm:"type:numeric(19,2);not null;default:0"`
+	BoughtOn     *time.Time `json:"bought_on,omitempty" gorm:"index"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewPlannedPurchase creates a validated planned purchase
+func NewPlannedPurchase(description, category string, estimatedAmount Money, plannedFor *time.Time) (*PlannedPurchase, error) {
+	description = strings.TrimSpace(description)
+	if description == "" || estimatedAmount.Minor <= 0 {
+		return nil, ErrInvalidPlannedPurchase
+	}
+	if plannedFor != nil {
+		day := dayOf(*plannedFor)
+		plannedFor = &day
+	}
+	return &PlannedPurchase{
+		ID:              uuid.New(),
+		Description:     description,
+		Category:        strings.TrimSpace(category),
+		EstimatedAmount: estimatedAmount,
+		PlannedFor:      plannedFor,
+	}, nil
+}
+
+// Status returns whether the purchase is still open or bought
+func (p *PlannedPurchase) Status() string {
+	if p.ExpenseID != nil {
+		return PlannedPurchaseBought
+	}
+	return PlannedPurchaseOpen
+}
+
+// Link records expense as the actual purchase, or returns ErrPlannedPurchaseLinked when it is bought already
+func (p *PlannedPurchase) Link(expense *Expense) error {
+	if p.ExpenseID != nil {
+		return ErrPlannedPurchaseLinked
+	}
+	boughtOn := dayOf(expense.Date)
+	p.ExpenseID = &expense.ID
+	p.ActualAmount = expense.ReportingAmount()
+	p.BoughtOn = &boughtOn
+	return nil
+}
+
+// Unlink makes the purchase open again, e.g. after linking the wrong expense
+func (p *PlannedPurchase) Unlink() {
+	p.ExpenseID = nil
+	p.ActualAmount = Money{}
+	p.BoughtOn = nil
+}
+
+// Variance is how much more (positive) or less (negative) the purchase cost than estimated (0 while open)
+func (p *PlannedPurchase) Variance() Money {
+	if p.ExpenseID == nil {
+		return Money{}
+	}
+	return p.ActualAmount.Sub(p.EstimatedAmount)
+}
+
+// VariancePercent is the variance as a percentage of the estimate
+func (p *PlannedPurchase) VariancePercent() float64 {
+	return RoundAmount(p.Variance().Float64() / p.EstimatedAmount.Float64() * 100)
+}
+
+// AbsoluteError is how far off the estimate was, as a percentage of it, in either direction
+func (p *PlannedPurchase) AbsoluteError() float64 {
+	return math.Abs(p.VariancePercent())
+}
+
+// PlannedPurchaseRepository defines the data access operations for planned purchases
+// Every operation is limited to the planned purchases of the caller in ctx
+type PlannedPurchaseRepository interface {
+	// Create saves a new planned purchase owned by the caller
+	Create(ctx context.Context, purchase *PlannedPurchase) error
+
+	// GetByID retrieves one of the caller's planned purchases, or returns ErrPlannedPurchaseNotFound
+	GetByID(ctx context.Context, id string) (*PlannedPurchase, error)
+
+	// List returns the caller's planned purchases with the given status (empty for all), by planned day
+	List(ctx context.Context, status string) ([]*PlannedPurchase, error)
+
+	// ListBought returns the caller's purchases bought in [from, to), oldest first
+	ListBought(ctx context.Context, from, to time.Time) ([]*PlannedPurchase, error)
+
+	// UpdateLink saves the link to the actual expense, or returns ErrExpenseAlreadyPlanned
+	// when the expense pays for another planned purchase
+	UpdateLink(ctx context.Context, purchase *PlannedPurchase) error
+
+	// Delete removes one of the caller's planned purchases, or returns ErrPlannedPurchaseNotFound
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,354 @@
+// Package domain contains the core business logic and entities
+// This file defines expense policies: machine-checkable rules admins set for their organization
+// (e.g. "meals up to 50 a day", "no alcohol", "receipts over 25"), and the violations they produce
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For building the explanations
+	"strings" // For input normalization
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Policy rule kinds
+const (
+	// PolicyMaxAmount caps the amount of a single expense
+	PolicyMaxAmount = "max_amount"
+
+	// PolicyDailyLimit caps what is spent per day, e.g. on meals
+	PolicyDailyLimit = "daily_limit"
+
+	// PolicyForbiddenCategory refuses a category altogether, e.g. alcohol
+	PolicyForbiddenCategory = "forbidden_category"
+
+	// PolicyReceiptRequired asks for a receipt on expenses over an amount
+	PolicyReceiptRequired = "receipt_required"
+
+	// PolicyCostCenterRequired asks for a cost center on expenses over an amount (0 for every expense)
+	PolicyCostCenterRequired = "cost_center_required"
+
+	// PolicyDepartmentRequired asks for a department on expenses over an amount (0 for every expense)
+	PolicyDepartmentRequired = "department_required"
+
+	// PolicyScript is broken by expenses its script is true for (see PolicyScriptVariables)
+	PolicyScript = "script"
+)
+
+// Policy severities
+const (
+	// PolicyWarn lets the expense through with a warning
+	PolicyWarn = "warn"
+
+	// PolicyBlock refuses the expense (or the report it is submitted in)
+	PolicyBlock = "block"
+)
+
+// Policy stages: when a rule was checked
+const (
+	// PolicyStageCreate is the recording of a new expense
+	PolicyStageCreate = "create"
+
+	// PolicyStageUpdate is a change of an expense
+	PolicyStageUpdate = "update"
+
+	// PolicyStageSubmit is the submission of a trip report for approval
+	PolicyStageSubmit = "submit"
+)
+
+// MaxPolicyMessageLength bounds the admin's explanation of a rule
+const MaxPolicyMessageLength = 500
+
+// PolicyRule is one rule of an organization's expense policy
+// Rules are data, so admins change the policy without a deployment
+type PolicyRule struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// TenantID is the organization the rule belongs to (empty for the default tenant)
+	TenantID string `json:"-" gorm:"index"`
+
+	// Name describes the rule (e.g. "Meal allowance")
+	Name string `json:"name" gorm:"not null"`
+
+	// Kind is one of the Policy* kinds
+	Kind string `json:"kind" gorm:"size:32;not null"`
+
+	// Category limits the rule to one category (empty for every category); forbidden_category needs one
+	Category string `json:"category,omitempty"`
+
+	// Amount is the limit in the home currency (unused by forbidden_category)
+	Amount Money `json:"amount,omitzero" gorm:"type:numeric(19,2)"`
+
+	// Severity is PolicyWarn or PolicyBlock
+	Severity string `json:"severity" gorm:"size:8;not null"`
+
+	// Message is the admin's explanation, added to every violation (e.g. "see the travel policy, section 3")
+	Message string `json:"message,omitempty"`
+
+	// Script is the condition of a script rule (empty for the other kinds)
+	Script string `json:"script,omitempty" gorm:"type:text"`
+
+	// Enabled rules are checked; disabled ones are kept for later
+	Enabled bool `json:"enabled" gorm:"not null;default:true"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewPolicyRule creates a validated, enabled policy rule
+func NewPolicyRule(name, kind, category string, amount Money, severity, message string) (*PolicyRule, error) {
+	rule := &PolicyRule{ID: uuid.New(), Enabled: true}
+	if err := rule.Change(name, kind, category, amount, severity, message); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Change replaces the rule's settings with validation
+func (r *PolicyRule) Change(name, kind, category string, amount Money, severity, message string) error {
+	name = strings.TrimSpace(name)
+	category = strings.TrimSpace(category)
+	message = strings.TrimSpace(message)
+	if name == "" || len(message) > MaxPolicyMessageLength {
+		return ErrInvalidPolicyRule
+	}
+	switch kind {
+	case PolicyMaxAmount, PolicyDailyLimit:
+		if amount.Minor <= 0 {
+			return ErrInvalidPolicyRule
+		}
+	case PolicyReceiptRequired, PolicyCostCenterRequired, PolicyDepartmentRequired:
+		if amount.IsNegative() {
+			return ErrInvalidPolicyRule
+		}
+	case PolicyForbiddenCategory:
+		if category == "" {
+			return ErrInvalidPolicyRule
+		}
+		amount = Money{}
+	case PolicyScript:
+		amount = Money{}
+	default:
+		return ErrInvalidPolicyRule
+	}
+	if severity != PolicyWarn && severity != PolicyBlock {
+		return ErrInvalidPolicyRule
+	}
+	r.Name, r.Kind, r.Category = name, kind, category
+	r.Amount, r.Severity, r.Message = amount, severity, message
+	return nil
+}
+
+// AppliesTo reports whether the rule covers expenses in category (compared case-insensitively)
+func (r *PolicyRule) AppliesTo(category string) bool {
+	return r.Category == "" || strings.EqualFold(r.Category, category)
+}
+
+// PolicyFacts is what a rule needs to know beyond the expense itself
+type PolicyFacts struct {
+	// Stage is when the check runs (one of the PolicyStage* constants)
+	Stage string
+
+	// DaySpent is what was spent on the expense's day in the rule's category, the expense included
+	// (only needed for daily_limit rules)
+	DaySpent Money
+
+	// HasReceipt reports whether the expense has an attachment
+	HasReceipt bool
+}
+
+// Check returns the violation of the rule by expense, or nil if the expense complies
+// Receipts can only be attached once an expense exists, so before submission a missing receipt
+// is only ever a warning, reminding to attach one
+func (r *PolicyRule) Check(expense *Expense, facts PolicyFacts) *PolicyViolation {
+	if !r.Enabled || !r.AppliesTo(expense.Category) {
+		return nil
+	}
+	amount := expense.ReportingAmount()
+	severity := r.Severity
+	var explanation string
+	switch r.Kind {
+	case PolicyMaxAmount:
+		if amount.Minor <= r.Amount.Minor {
+			return nil
+		}
+		explanation = fmt.Sprintf("%s expenses may be at most %s; this one is %s", r.scope(), r.Amount, amount)
+	case PolicyDailyLimit:
+		if facts.DaySpent.Minor <= r.Amount.Minor {
+			return nil
+		}
+		explanation = fmt.Sprintf("%s spending is limited to %s per day; %s was spent on %s with this expense",
+			r.scope(), r.Amount, facts.DaySpent, expense.Date.Format("2006-01-02"))
+	case PolicyForbiddenCategory:
+		explanation = fmt.Sprintf("expenses in %q are not allowed", r.Category)
+	case PolicyReceiptRequired:
+		if amount.Minor <= r.Amount.Minor || facts.HasReceipt {
+			return nil
+		}
+		explanation = fmt.Sprintf("%s expenses over %s need a receipt; this one is %s", r.scope(), r.Amount, amount)
+		if facts.Stage != PolicyStageSubmit {
+			severity = PolicyWarn
+			explanation += "; attach one before the report is submitted"
+		}
+	case PolicyCostCenterRequired:
+		if amount.Minor <= r.Amount.Minor || expense.CostCenter != "" {
+			return nil
+		}
+		explanation = fmt.Sprintf("%s expenses over %s must be charged to a cost center", r.scope(), r.Amount)
+	case PolicyDepartmentRequired:
+		if amount.Minor <= r.Amount.Minor || expense.Department != "" {
+			return nil
+		}
+		explanation = fmt.Sprintf("%s expenses over %s must be charged to a department", r.scope(), r.Amount)
+	case PolicyScript:
+		var violated bool
+		if violated, explanation, severity = r.checkScript(expense, facts); !violated {
+			return nil
+		}
+	default:
+		return nil
+	}
+	if r.Message != "" {
+		explanation += " (" + r.Message + ")"
+	}
+
+	return &PolicyViolation{
+		ID:          uuid.New(),
+		TenantID:    r.TenantID,
+		RuleID:      r.ID,
+		RuleName:    r.Name,
+		Kind:        r.Kind,
+		Severity:    severity,
+		Stage:       facts.Stage,
+		UserID:      expense.UserID,
+		Category:    expense.Category,
+		Amount:      amount,
+		Explanation: explanation,
+	}
+}
+
+// scope names what the rule covers in explanations
+func (r *PolicyRule) scope() string {
+	if r.Category == "" {
+		return "All"
+	}
+	return r.Category
+}
+
+// PolicyViolation records that an expense broke a policy rule
+// Violations are kept, including those of blocked expenses that were never saved, so finance
+// teams can see which rules are broken how often
+type PolicyViolation struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TenantID string    `json:"-" gorm:"index"`
+
+	// RuleID is the rule broken; RuleName and Kind are kept so the record reads well after the rule changes
+	RuleID   uuid.UUID `json:"rule_id" gorm:"type:uuid;not null;index"`
+	RuleName string    `json:"rule_name" gorm:"not null"`
+	Kind     string    `json:"kind" gorm:"size:32;not null"`
+
+	// Severity is PolicyBlock when the violation stopped the expense or the report
+	Severity string `json:"severity" gorm:"size:8;not null"`
+
+	// Stage is when the rule was checked (one of the PolicyStage* constants)
+	Stage string `json:"stage" gorm:"size:8;not null"`
+
+	// UserID is the owner of the expense (nil for the anonymous local user)
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// ExpenseID is the expense (nil when it was blocked before being saved); TripID the submitted report
+	ExpenseID *uuid.UUID `json:"expense_id,omitempty" gorm:"type:uuid;index"`
+	TripID    *uuid.UUID `json:"trip_id,omitempty" gorm:"type:uuid"`
+
+	// Category and Amount (in the home currency) are the expense's at the time of the check
+	Category string `json:"category"`
+	Amount   Money  `json:"amount" gorm:"type:numeric(19,2)"`
+
+	// Explanation tells the user what is wrong in plain words
+	Explanation string `json:"explanation" gorm:"not null"`
+
+	OccurredAt time.Time `json:"occurred_at" gorm:"not null;index"`
+}
+
+// Blocking reports whether the violation stops the expense or report
+func (v *PolicyViolation) Blocking() bool {
+	return v.Severity == PolicyBlock
+}
+
+// PolicyBlockedError is returned when hard policy violations stop an expense or a report
+// It unwraps to ErrPolicyBlocked and carries every violation, warnings included, to show the user
+type PolicyBlockedError struct {
+	Violations []*PolicyViolation
+}
+
+// Error lists the blocking explanations
+func (e *PolicyBlockedError) Error() string {
+	var reasons []string
+	for _, violation := range e.Violations {
+		if violation.Blocking() {
+			reasons = append(reasons, violation.Explanation)
+		}
+	}
+	return ErrPolicyBlocked.Error() + ": " + strings.Join(reasons, "; ")
+}
+
+// Unwrap lets errors.Is match ErrPolicyBlocked
+func (e *PolicyBlockedError) Unwrap() error {
+	return ErrPolicyBlocked
+}
+
+// Groupings of the policy violation report
+const (
+	// PolicyViolationsByUser groups violations by the owner of the expense
+	PolicyViolationsByUser = "user"
+
+	// PolicyViolationsByCategory groups violations by expense category (ignoring case)
+	PolicyViolationsByCategory = "category"
+
+	// PolicyViolationsByRule groups violations by the rule broken
+	PolicyViolationsByRule = "rule"
+)
+
+// PolicyViolationTotal sums the violations of one user, category or rule in a period
+type PolicyViolationTotal struct {
+	// Key identifies the group: the user ID (empty for the anonymous local user), category or rule ID
+	Key string `json:"key"`
+
+	// Label names the group: the user's email, the category or the rule's latest name
+	Label string `json:"label"`
+
+	// Count is the number of violations, Blocked how many of them stopped an expense or report
+	Count   int64 `json:"count"`
+	Blocked int64 `json:"blocked"`
+
+	// Amount sums the expense amounts of the violations in the home currency
+	// An expense that broke a rule at several stages is counted at each of them
+	Amount Money `json:"amount"`
+}
+
+// PolicyRepository defines how policy rules and their violations are stored
+type PolicyRepository interface {
+	// ListRules returns a tenant's rules, oldest first
+	ListRules(ctx context.Context, tenantID string) ([]*PolicyRule, error)
+
+	// GetRule retrieves one of a tenant's rules, or returns ErrPolicyRuleNotFound
+	GetRule(ctx context.Context, tenantID, id string) (*PolicyRule, error)
+
+	// CreateRule saves a new rule
+	CreateRule(ctx context.Context, rule *PolicyRule) error
+
+	// UpdateRule saves changes to a rule, or returns ErrPolicyRuleNotFound
+	UpdateRule(ctx context.Context, rule *PolicyRule) error
+
+	// DeleteRule removes one of a tenant's rules, or returns ErrPolicyRuleNotFound
+	// Violations of the rule are kept
+	DeleteRule(ctx context.Context, tenantID, id string) error
+
+	// RecordViolations saves violations
+	RecordViolations(ctx context.Context, violations ...*PolicyViolation) error
+
+	// SumViolations totals a tenant's violations from from (inclusive) to to (exclusive), grouped by
+	// one of the PolicyViolationsBy* groupings, most violations first
+	SumViolations(ctx context.Context, tenantID, groupBy string, from, to time.Time) ([]*PolicyViolationTotal, error)
+}
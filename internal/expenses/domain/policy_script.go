@@ -0,0 +1,98 @@
+// Package domain contains the core business logic and entities
+// This file defines script policy rules: admin-written conditions over the fields of an
+// expense, for the edge cases the other rule kinds can't express
+// (e.g. `weekday in ["Saturday", "Sunday"] && category == "Meals" && amount > 30`)
+package domain
+
+import (
+	"fmt" // For wrapping script errors
+
+	"myexpenses/internal/script" // Sandboxed expression language
+)
+
+// PolicyScriptVariables are the names a policy script can use
+//   - amount: the amount in the home currency; original_amount and currency as entered
+//   - category, description, merchant, mcc, source, cost_center, department: text fields
+//   - date ("2006-01-02"), weekday ("Monday"), day (1-31) and month (1-12)
+//   - reimbursable: whether someone else pays it back; business and tax_deductible: its other
+//     marks; tags: the list of tags
+//   - has_receipt: whether a receipt is attached; day_spent: what was spent that day in the
+//     rule's category (all categories if it has none), this expense included
+//   - stage: "create", "update" or "submit"
+var PolicyScriptVariables = []string{
+	"amount", "original_amount", "currency", "category", "description", "merchant", "mcc", "source",
+	"cost_center", "department", "date", "weekday", "day", "month", "reimbursable", "business",
+	"tax_deductible", "tags", "has_receipt", "day_spent", "stage",
+}
+
+// SetScript attaches the condition of a script rule; the rule is broken when it is true
+// Other kinds of rules have no script, so it is cleared for them
+func (r *PolicyRule) SetScript(source string) error {
+	if r.Kind != PolicyScript {
+		r.Script = ""
+		return nil
+	}
+	program, err := compilePolicyScript(source)
+	if err != nil {
+		return err
+	}
+	r.Script = program.Source()
+	return nil
+}
+
+// compilePolicyScript compiles a policy script, explaining what is wrong with it if it can't
+func compilePolicyScript(source string) (*script.Program, error) {
+	if source == "" {
+		return nil, fmt.Errorf("%w: a script rule needs a script", ErrInvalidScript)
+	}
+	program, err := script.Compile(source, PolicyScriptVariables)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScript, err)
+	}
+	return program, nil
+}
+
+// checkScript evaluates the rule's script for expense
+// A script that fails (e.g. divides by zero) can't tell whether the expense complies, so it
+// produces a warning with the reason instead of blocking anything
+func (r *PolicyRule) checkScript(expense *Expense, facts PolicyFacts) (violated bool, explanation, severity string) {
+	program, err := compilePolicyScript(r.Script)
+	if err == nil {
+		violated, err = program.EvalBool(policyScriptValues(expense, facts))
+	}
+	if err != nil {
+		return true, fmt.Sprintf("the script of %q failed: %v", r.Name, err), PolicyWarn
+	}
+	return violated, fmt.Sprintf("the expense matches the condition of %q", r.Name), r.Severity
+}
+
+// policyScriptValues returns the values of PolicyScriptVariables for expense
+func policyScriptValues(expense *Expense, facts PolicyFacts) map[string]any {
+	tags := expense.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return map[string]any{
+		"amount":          expense.ReportingAmount().Float64(),
+		"original_amount": expense.Amount.Float64(),
+		"currency":        expense.Currency,
+		"category":        expense.Category,
+		"description":     expense.Description,
+		"merchant":        expense.Merchant,
+		"mcc":             expense.MCC,
+		"source":          expense.Source,
+		"cost_center":     expense.CostCenter,
+		"department":      expense.Department,
+		"date":            expense.Date.Format("2006-01-02"),
+		"weekday":         expense.Date.Weekday().String(),
+		"day":             expense.Date.Day(),
+		"month":           int(expense.Date.Month()),
+		"reimbursable":    expense.Reimbursable,
+		"business":        expense.Business,
+		"tax_deductible":  expense.TaxDeductible,
+		"tags":            tags,
+		"has_receipt":     facts.HasReceipt,
+		"day_spent":       facts.DaySpent.Float64(),
+		"stage":           facts.Stage,
+	}
+}
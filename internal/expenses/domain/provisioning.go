@@ -0,0 +1,111 @@
+// Package domain contains the core business logic and entities
+// This file defines the localized starter data new installations are provisioned with
+package domain
+
+import "strings" // For normalizing locale tags
+
+// DefaultLocale is used when a locale has no translated starter data
+const DefaultLocale = "en"
+
+// DefaultCategories are the canonical starter categories
+// They match the categories used by DefaultMCCMappings and DefaultChargeCategories
+var DefaultCategories = []string{
+	"Food",
+	"Transportation",
+	"Housing",
+	"Utilities",
+	"Health",
+	"Entertainment",
+	"Shopping",
+	"Travel",
+	"Bank Fees",
+	"Interest",
+	"Other",
+}
+
+// SeedRule is a starter categorization rule, with its category in canonical (English) form
+type SeedRule struct {
+	Pattern  string
+	Category string
+}
+
+// DefaultRules are the sample categorization rules every installation starts with
+var DefaultRules = []SeedRule{
+	{Pattern: "uber", Category: "Transportation"},
+	{Pattern: "netflix", Category: "Entertainment"},
+	{Pattern: "spotify", Category: "Entertainment"},
+	{Pattern: "amazon", Category: "Shopping"},
+	{Pattern: "pharmacy", Category: "Health"},
+}
+
+// StarterBudgetCategory and StarterBudgetAmount define the starter monthly budget
+// The amount is in the base currency
+const (
+	StarterBudgetCategory = "Food"
+	StarterBudgetAmount   = 400
+)
+
+// categoryTranslations maps locale -> canonical category -> localized name
+// Locales without an entry use the canonical English names
+var categoryTranslations = map[string]map[string]string{
+	"de": {
+		"Food":           "Lebensmittel",
+		"Transportation": "Verkehr",
+		"Housing":        "Wohnen",
+		"Utilities":      "Nebenkosten",
+		"Health":         "Gesundheit",
+		"Entertainment":  "Freizeit",
+		"Shopping":       "Einkaufen",
+		"Travel":         "Reisen",
+		"Bank Fees":      "Bankgebühren",
+		"Interest":       "Zinsen",
+		"Other":          "Sonstiges",
+	},
+	"fr": {
+		"Food":           "Alimentation",
+		"Transportation": "Transport",
+		"Housing":        "Logement",
+		"Utilities":      "Charges",
+		"Health":         "Santé",
+		"Entertainment":  "Loisirs",
+		"Shopping":       "Achats",
+		"Travel":         "Voyages",
+		"Bank Fees":      "Frais bancaires",
+		"Interest":       "Intérêts",
+		"Other":          "Autre",
+	},
+	"es": {
+		"Food":           "Alimentación",
+		"Transportation": "Transporte",
+		"Housing":        "Vivienda",
+		"Utilities":      "Suministros",
+		"Health":         "Salud",
+		"Entertainment":  "Ocio",
+		"Shopping":       "Compras",
+		"Travel":         "Viajes",
+		"Bank Fees":      "Comisiones bancarias",
+		"Interest":       "Intereses",
+		"Other":          "Otros",
+	},
+}
+
+// NormalizeLocale reduces a locale tag such as "de-DE" or "fr_CA" to a supported language code
+// Unsupported locales fall back to DefaultLocale
+func NormalizeLocale(locale string) string {
+	language := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	if _, ok := categoryTranslations[language]; ok {
+		return language
+	}
+	return DefaultLocale
+}
+
+// LocalizeCategory returns the name of a canonical category in the given locale
+func LocalizeCategory(locale, category string) string {
+	if name, ok := categoryTranslations[NormalizeLocale(locale)][category]; ok {
+		return name
+	}
+	return category
+}
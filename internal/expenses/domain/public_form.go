@@ -0,0 +1,249 @@
+// Package domain contains the core business logic and entities
+// This file defines public forms: token links that let people without an account submit expenses,
+// and the staging area where those submissions (and corporate card transactions) wait for review
+package domain
+
+import (
+	"context"         // For request context (cancellation, timeouts)
+	"crypto/rand"     // For generating form tokens
+	"crypto/sha256"   // For storing tokens as hashes
+	"encoding/base64" // For making tokens URL-safe
+	"encoding/hex"    // For encoding token hashes
+	"strings"         // For input normalization
+	"time"            // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Staged expense statuses
+const (
+	// StagedPending submissions wait for review
+	StagedPending = "pending"
+
+	// StagedApproved submissions were turned into real expenses
+	StagedApproved = "approved"
+
+	// StagedRejected submissions were declined and never became expenses
+	StagedRejected = "rejected"
+)
+
+// formTokenBytes is how many random bytes a form token carries (256 bits)
+const formTokenBytes = 32
+
+// PublicForm is a token-scoped link for submitting expenses without an account
+// Everything submitted through it is locked to one category, capped in amount and staged for review
+type PublicForm struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user the form belongs to and whose staging area its submissions go to;
+	// nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// Name says who the form is for (e.g. "Grandma"); it is shown on the form and in the staging area
+	Name string `json:"name" gorm:"not null"`
+
+	// TokenHash is the SHA-256 of the token; the token itself is only shown once, when the form is created
+	TokenHash string `json:"-" gorm:"not null;uniqueIndex"`
+
+	// Category is the only category submissions can have
+	Category string `json:"category" gorm:"not null"`
+
+	// MaxAmount is the largest amount one submission may have
+	MaxAmount Money `json:"max_amount" gorm:"type:numeric(19,2);not null"`
+
+	// ExpiresAt is when the form stops accepting submissions (nil = never)
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// RevokedAt is set when the owner switched the form off
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewPublicForm creates a validated public form and returns it with its token
+// The token is returned only here; the form keeps just its hash
+func NewPublicForm(name, category string, maxAmount Money, expiresAt *time.Time) (*PublicForm, string, error) {
+	name = strings.TrimSpace(name)
+	category = strings.TrimSpace(category)
+	if name == "" || maxAmount.Minor <= 0 {
+		return nil, "", ErrInvalidPublicForm
+	}
+	if category == "" {
+		return nil, "", ErrInvalidCategory
+	}
+
+	raw := make([]byte, formTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	return &PublicForm{
+		ID:        uuid.New(),
+		Name:      name,
+		TokenHash: HashFormToken(token),
+		Category:  category,
+		MaxAmount: maxAmount,
+		ExpiresAt: expiresAt,
+	}, token, nil
+}
+
+// HashFormToken returns the hash a form token is stored and looked up by
+func HashFormToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsOpen reports whether the form accepts submissions at now
+func (f *PublicForm) IsOpen(now time.Time) bool {
+	if f.RevokedAt != nil {
+		return false
+	}
+	return f.ExpiresAt == nil || now.Before(*f.ExpiresAt)
+}
+
+// StagedExpense is an expense waiting in the staging area: submitted through a public form,
+// or a corporate card transaction waiting for its cardholder to code it
+// It becomes a real expense only when the owner approves it
+type StagedExpense struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// FormID is the public form it was submitted through (nil for card transactions)
+	FormID *uuid.UUID `json:"form_id,omitempty" gorm:"type:uuid;index"`
+
+	// UserID is the user whose staging area it is in: the cardholder of a card transaction,
+	// or the owner of the form it was submitted through
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// SubmittedBy is the name the submitter typed in (unverified)
+	SubmittedBy string `json:"submitted_by"`
+
+	// CardLast4, Merchant, MCC and ExternalID come from the card feed (empty for form submissions)
+	CardLast4  string `json:"card_last4,omitempty" gorm:"size:4"`
+	Merchant   string `json:"merchant,omitempty"`
+	MCC        string `json:"mcc,omitempty" gorm:"size:4"`
+	ExternalID string `json:"external_id,omitempty" gorm:"index"`
+
+	// Currency and ConvertedAmount carry a foreign card transaction into the expense (empty otherwise)
+	Currency        string `json:"currency,omitempty" gorm:"size:3"`
+	ConvertedAmount Money  `json:"converted_amount,omitzero" gorm:"type:numeric(19,2)"`
+
+	Description string    `json:"description" gorm:"not null"`
+	Amount      Money     `json:"amount" gorm:"type:numeric(19,2);not null"`
+	Category    string    `json:"category" gorm:"not null"`
+	Date        time.Time `json:"date" gorm:"not null"`
+	Note        string    `json:"note,omitempty"`
+
+	// Status is StagedPending, StagedApproved or StagedRejected
+	Status string `json:"status" gorm:"not null;default:pending;index"`
+
+	// ExpenseID is the expense created on approval
+	ExpenseID *uuid.UUID `json:"expense_id,omitempty" gorm:"type:uuid"`
+
+	// RejectionReason tells why the submission was declined
+	RejectionReason string `json:"rejection_reason,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// NewStagedExpense validates a submission against the form's limits and stages it
+func NewStagedExpense(form *PublicForm, submittedBy, description string, amount Money, date time.Time, note string) (*StagedExpense, error) {
+	description = strings.TrimSpace(description)
+	if description == "" || amount.Minor <= 0 || date.IsZero() {
+		return nil, ErrInvalidSubmission
+	}
+	if amount.Minor > form.MaxAmount.Minor {
+		return nil, ErrAmountOverFormLimit
+	}
+	return &StagedExpense{
+		ID:          uuid.New(),
+		FormID:      &form.ID,
+		UserID:      form.UserID,
+		SubmittedBy: strings.TrimSpace(submittedBy),
+		Description: description,
+		Amount:      amount,
+		Category:    form.Category,
+		Date:        date,
+		Note:        strings.TrimSpace(note),
+		Status:      StagedPending,
+	}, nil
+}
+
+// Code sets what a card transaction was for before the cardholder approves it
+// Form submissions keep what was submitted, so only card transactions can be coded
+func (s *StagedExpense) Code(description, category, note string) error {
+	if s.Status != StagedPending {
+		return ErrSubmissionReviewed
+	}
+	if s.FormID != nil {
+		return ErrSubmissionNotCodable
+	}
+	description = strings.TrimSpace(description)
+	category = strings.TrimSpace(category)
+	if description != "" {
+		s.Description = description
+	}
+	if category != "" {
+		s.Category = category
+	}
+	s.Note = strings.TrimSpace(note)
+	return nil
+}
+
+// Approve records that the submission became expenseID
+func (s *StagedExpense) Approve(expenseID uuid.UUID, at time.Time) error {
+	if s.Status != StagedPending {
+		return ErrSubmissionReviewed
+	}
+	s.Status = StagedApproved
+	s.ExpenseID = &expenseID
+	s.ReviewedAt = &at
+	return nil
+}
+
+// Reject declines the submission
+func (s *StagedExpense) Reject(reason string, at time.Time) error {
+	if s.Status != StagedPending {
+		return ErrSubmissionReviewed
+	}
+	s.Status = StagedRejected
+	s.RejectionReason = strings.TrimSpace(reason)
+	s.ReviewedAt = &at
+	return nil
+}
+
+// PublicFormRepository defines the data access operations for public forms and the staging area
+type PublicFormRepository interface {
+	// CreateForm saves a new public form
+	CreateForm(ctx context.Context, form *PublicForm) error
+
+	// GetForm retrieves a form by its ID, or returns ErrPublicFormNotFound
+	GetForm(ctx context.Context, id string) (*PublicForm, error)
+
+	// GetFormByTokenHash retrieves the form a token belongs to, or returns ErrPublicFormNotFound
+	GetFormByTokenHash(ctx context.Context, tokenHash string) (*PublicForm, error)
+
+	// ListForms returns all forms, newest first
+	ListForms(ctx context.Context) ([]*PublicForm, error)
+
+	// UpdateForm saves changes to a form (e.g. revocation)
+	UpdateForm(ctx context.Context, form *PublicForm) error
+
+	// CreateStaged saves a new submission
+	CreateStaged(ctx context.Context, staged *StagedExpense) error
+
+	// GetStaged retrieves a submission, or returns ErrSubmissionNotFound
+	// Card transactions are only found for their cardholder
+	GetStaged(ctx context.Context, id string) (*StagedExpense, error)
+
+	// ListStaged returns the submissions with the given status (all when empty), oldest first
+	// Card transactions are only listed for their cardholder
+	ListStaged(ctx context.Context, status string) ([]*StagedExpense, error)
+
+	// StagedExternalIDExists reports whether a card transaction with this feed ID was staged before
+	StagedExternalIDExists(ctx context.Context, externalID string) (bool, error)
+
+	// UpdateStaged saves the review outcome of a submission
+	UpdateStaged(ctx context.Context, staged *StagedExpense) error
+}
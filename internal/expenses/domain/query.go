@@ -0,0 +1,334 @@
+// Package domain contains the core business logic and entities
+// This file defines the expense query language of ?q=, e.g. amount>50 AND category:Food AND date:2024-06
+package domain
+
+import (
+	"fmt"          // For saying which part of a query is wrong
+	"strconv"      // For parsing amounts and booleans
+	"strings"      // For splitting queries into terms
+	"time"         // For date bounds
+	"unicode"      // For finding the spaces between terms
+	"unicode/utf8" // For limiting the query length
+
+	"github.com/google/uuid" // For employer IDs
+)
+
+// MaxQueryLength bounds a query, which arrives in the URL
+const MaxQueryLength = 1000
+
+// queryOperators are the comparisons a term can use, longest first so ">=" isn't read as ">"
+var queryOperators = []string{">=", "<=", ">", "<", "=", ":"}
+
+// queryTerm is one field comparison of a query, e.g. amount>50
+type queryTerm struct {
+	field    string
+	operator string
+	value    string
+}
+
+// ParseExpenseQuery parses a query into the filters of the expense list
+//
+// A query is terms joined by spaces or AND; every term must match. A term compares a field:
+//
+//	amount>50, amount<=100, amount:42.50   the amount (>, >=, <, <=, = or :)
+//	date:2024-06, date>=2024-06-01         the date: a period as in reports, or a bound
+//	category:Food                          the category
+//	tag:trip-rome or #trip-rome            a tag (repeat for several)
+//	description:"coffee beans"             part of the description
+//	cost_center:CC-100, department:SALES   chargeback codes
+//	reimbursable:true, employer:<id>       reimbursement
+//	business:true, tax_deductible:true     the other marks
+//	flag:needs_receipt                     a review flag
+//	meta.project:ACME                      a custom field
+//
+// Words that aren't terms search the description. Values with spaces are quoted. OR and NOT
+// aren't supported: every list filter is a conjunction
+func ParseExpenseQuery(query string) (*ViewFilters, error) {
+	if utf8.RuneCountInString(query) > MaxQueryLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalidQuery, MaxQueryLength)
+	}
+	terms, err := splitQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := &ViewFilters{}
+	var words []string
+	for _, term := range terms {
+		if term.field == "" {
+			words = append(words, term.value)
+			continue
+		}
+		if err := filters.addTerm(term); err != nil {
+			return nil, err
+		}
+	}
+	if len(words) > 0 {
+		if filters.Description != "" {
+			return nil, fmt.Errorf("%w: description given twice", ErrInvalidQuery)
+		}
+		filters.Description = strings.Join(words, " ")
+	}
+
+	if err := filters.normalize(); err != nil {
+		return nil, fmt.Errorf("%w: the filters contradict each other or have invalid values", ErrInvalidQuery)
+	}
+	return filters, nil
+}
+
+// addTerm narrows the filters by one term
+func (f *ViewFilters) addTerm(term queryTerm) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %s%s%s: %s", ErrInvalidQuery, term.field, term.operator, term.value, reason)
+	}
+	equality := term.operator == ":" || term.operator == "="
+	if !equality && term.field != "amount" && term.field != "date" {
+		return invalid("only amount and date can be compared with < or >")
+	}
+	if term.value == "" {
+		return invalid("missing value")
+	}
+
+	switch field := term.field; {
+	case field == "amount":
+		amount, err := ParseMoney(term.value, "")
+		if err != nil || amount.IsNegative() {
+			return invalid("not an amount")
+		}
+		// Amounts are in cents, so a strict bound is the next cent
+		cent := Cents(1, "")
+		min, max := amount, amount
+		switch term.operator {
+		case ">":
+			min, max = amount.Add(cent), Money{}
+		case ">=":
+			max = Money{}
+		case "<":
+			min, max = Money{}, amount.Sub(cent)
+		case "<=":
+			min = Money{}
+		}
+		if max.IsNegative() || (term.operator == "<" && max.IsZero()) {
+			return invalid("no amount is that small")
+		}
+		if min.Minor > f.MinAmount.Minor {
+			f.MinAmount = min
+		}
+		if max.Minor > 0 && (f.MaxAmount.IsZero() || max.Minor < f.MaxAmount.Minor) {
+			f.MaxAmount = max
+		}
+	case field == "date":
+		return f.addDateTerm(term, invalid)
+	case field == "category":
+		if f.Category != "" && !strings.EqualFold(f.Category, term.value) {
+			return invalid("an expense has only one category")
+		}
+		f.Category = term.value
+	case field == "tag":
+		f.Tags = append(f.Tags, term.value)
+	case field == "description":
+		if f.Description != "" {
+			return invalid("description given twice")
+		}
+		f.Description = term.value
+	case field == "cost_center" || field == "department":
+		target := &f.CostCenter
+		if field == "department" {
+			target = &f.Department
+		}
+		if *target != "" && *target != NormalizeDimensionCode(term.value) {
+			return invalid("an expense is charged to only one")
+		}
+		*target = term.value
+	case field == "reimbursable" || field == "business" || field == "tax_deductible":
+		marked, err := strconv.ParseBool(term.value)
+		if err != nil {
+			return invalid("use true or false")
+		}
+		switch field {
+		case "reimbursable":
+			f.Reimbursable = &marked
+		case "business":
+			f.Business = &marked
+		default:
+			f.TaxDeductible = &marked
+		}
+	case field == "employer":
+		employerID, err := uuid.Parse(term.value)
+		if err != nil {
+			return invalid("not an employer ID")
+		}
+		f.EmployerID = &employerID
+	case field == "flag":
+		flag, err := ParseFlag(term.value)
+		if err != nil {
+			return invalid("unknown flag")
+		}
+		f.Flag = flag
+	case strings.HasPrefix(field, "meta."):
+		if f.Metadata == nil {
+			f.Metadata = map[string]string{}
+		}
+		f.Metadata[strings.TrimPrefix(field, "meta.")] = term.value
+	default:
+		return invalid("unknown field")
+	}
+	return nil
+}
+
+// addDateTerm narrows the date range by a date term
+// date:<period> takes any period reports take; the comparisons take a day
+func (f *ViewFilters) addDateTerm(term queryTerm, invalid func(string) error) error {
+	var from, to time.Time
+	if term.operator == ":" || term.operator == "=" {
+		period, err := ParsePeriod(term.value)
+		if err != nil {
+			return invalid("use YYYY, YYYY-MM, YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD")
+		}
+		from, to = period.Start, period.End.AddDate(0, 0, -1)
+	} else {
+		day, err := time.Parse("2006-01-02", term.value)
+		if err != nil {
+			return invalid("compare with a day (YYYY-MM-DD)")
+		}
+		switch term.operator {
+		case ">":
+			from = day.AddDate(0, 0, 1)
+		case ">=":
+			from = day
+		case "<":
+			to = day.AddDate(0, 0, -1)
+		case "<=":
+			to = day
+		}
+	}
+
+	// Several date terms narrow the range down to where they overlap
+	if !from.IsZero() && (f.DateFrom == "" || from.Format("2006-01-02") > f.DateFrom) {
+		f.DateFrom = from.Format("2006-01-02")
+	}
+	if !to.IsZero() && (f.DateTo == "" || to.Format("2006-01-02") < f.DateTo) {
+		f.DateTo = to.Format("2006-01-02")
+	}
+	return nil
+}
+
+// splitQuery splits a query into terms, dropping the ANDs between them
+// Spaces around an operator are allowed, so "amount > 50" is one term like "amount>50"
+func splitQuery(query string) ([]queryTerm, error) {
+	// Step 1: Split at spaces outside of quotes, remembering where a token's quoted part starts
+	// (operators inside quotes are text, so "foo:bar" searches the description)
+	type token struct {
+		text    string
+		quoteAt int
+	}
+	var tokens []token
+	var current strings.Builder
+	inQuotes, quoteAt := false, -1
+	flush := func() {
+		if current.Len() > 0 || quoteAt >= 0 {
+			tokens = append(tokens, token{text: current.String(), quoteAt: quoteAt})
+		}
+		current.Reset()
+		quoteAt = -1
+	}
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if quoteAt < 0 {
+				quoteAt = current.Len()
+			}
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("%w: unclosed quote", ErrInvalidQuery)
+	}
+	flush()
+
+	// head is the part of a token operators are looked for in
+	head := func(tok token) string {
+		if tok.quoteAt >= 0 {
+			return tok.text[:tok.quoteAt]
+		}
+		return tok.text
+	}
+
+	// Step 2: Join terms written with spaces around the operator
+	var joined []token
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if operator := queryOperator(head(tok)); operator != "" && strings.HasPrefix(tok.text, operator) &&
+			len(joined) > 0 && joined[len(joined)-1].quoteAt < 0 && queryOperator(joined[len(joined)-1].text) == "" {
+			// ">50" or ">" after "amount"
+			previous := joined[len(joined)-1]
+			joined = joined[:len(joined)-1]
+			if tok.quoteAt >= 0 {
+				tok.quoteAt += len(previous.text)
+			}
+			tok.text = previous.text + tok.text
+		}
+		for i+1 < len(tokens) && tok.quoteAt < 0 && endsWithOperator(tok.text) {
+			// "amount>" or "amount >" before the value
+			i++
+			if tokens[i].quoteAt >= 0 {
+				tok.quoteAt = len(tok.text) + tokens[i].quoteAt
+			}
+			tok.text += tokens[i].text
+		}
+		joined = append(joined, tok)
+	}
+
+	// Step 3: Read each token as a term, a tag shorthand or a word
+	var terms []queryTerm
+	for _, tok := range joined {
+		operator := queryOperator(head(tok))
+		if tok.quoteAt < 0 {
+			switch strings.ToUpper(tok.text) {
+			case "AND", "&&":
+				continue
+			case "OR", "NOT", "||":
+				return nil, fmt.Errorf("%w: only AND is supported", ErrInvalidQuery)
+			}
+			if strings.HasPrefix(tok.text, "#") && len(tok.text) > 1 {
+				terms = append(terms, queryTerm{field: "tag", operator: ":", value: tok.text})
+				continue
+			}
+		}
+		if operator == "" {
+			terms = append(terms, queryTerm{value: tok.text})
+			continue
+		}
+		at := strings.Index(tok.text, operator)
+		field := strings.ToLower(tok.text[:at])
+		if field == "" {
+			return nil, fmt.Errorf("%w: %s has no field", ErrInvalidQuery, tok.text)
+		}
+		terms = append(terms, queryTerm{field: field, operator: operator, value: strings.TrimSpace(tok.text[at+len(operator):])})
+	}
+	return terms, nil
+}
+
+// queryOperator returns the first operator in a token ("" if it has none)
+func queryOperator(text string) string {
+	at := strings.IndexAny(text, "><=:")
+	if at < 0 {
+		return ""
+	}
+	for _, operator := range queryOperators {
+		if strings.HasPrefix(text[at:], operator) {
+			return operator
+		}
+	}
+	return ""
+}
+
+// endsWithOperator reports whether a token stops right after its operator, e.g. "amount>"
+func endsWithOperator(text string) bool {
+	operator := queryOperator(text)
+	return operator != "" && strings.HasSuffix(text, operator) && strings.Index(text, operator)+len(operator) == len(text)
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseExpenseQueryAmounts(t *testing.T) {
+	tests := []struct {
+		query    string
+		min, max int64
+		wantErr  bool
+	}{
+		{query: "amount>50", min: 5001},
+		{query: "amount>=50", min: 5000},
+		{query: "amount<50", max: 4999},
+		{query: "amount<=50", max: 5000},
+		{query: "amount:42.50", min: 4250, max: 4250},
+		{query: "amount=0.1", min: 10, max: 10},
+
+		// Digits beyond the cent round like everywhere else
+		{query: "amount>=19.999", min: 2000},
+
+		// Several bounds narrow each other down
+		{query: "amount>10 amount>=20 amount<100 amount<=80", min: 2000, max: 8000},
+
+		{query: "amount<0.01", wantErr: true},
+		{query: "amount<0", wantErr: true},
+		{query: "amount>-5", wantErr: true},
+		{query: "amount>ten", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filters, err := ParseExpenseQuery(tt.query)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidQuery) {
+					t.Fatalf("ParseExpenseQuery(%q) error = %v, want ErrInvalidQuery", tt.query, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseExpenseQuery(%q) error = %v", tt.query, err)
+			}
+			if filters.MinAmount.Minor != tt.min || filters.MaxAmount.Minor != tt.max {
+				t.Errorf("ParseExpenseQuery(%q) = min %d, max %d cents; want %d, %d",
+					tt.query, filters.MinAmount.Minor, filters.MaxAmount.Minor, tt.min, tt.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Package domain contains the core business logic and entities
+// This file defines read-only mode: switching the whole deployment or a single tenant to reads only
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For trimming the message
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring the message in characters
+)
+
+// Reasons for switching to read-only mode
+// They are sent to clients as the machine-readable "reason" of refused writes, so clients
+// can tell a short maintenance window from an account that needs paying
+const (
+	// ReadOnlyMaintenance is planned maintenance, e.g. a database upgrade; writes come back by themselves
+	ReadOnlyMaintenance = "maintenance"
+
+	// ReadOnlyBillingSuspended is a tenant whose subscription lapsed; writes come back once it is paid
+	ReadOnlyBillingSuspended = "billing_suspended"
+)
+
+// ReadOnlyAllTenants is the tenant ID of the deployment-wide switch
+// Real tenant IDs never contain "*", and the default tenant has the ID ""
+const ReadOnlyAllTenants = "*"
+
+// MaxReadOnlyMessageLength keeps the message to a line in a client's banner
+const MaxReadOnlyMessageLength = 300
+
+// ReadOnlyMode switches a tenant, or with ReadOnlyAllTenants every tenant, to reads only
+// There is at most one per tenant; deleting it switches writes back on
+type ReadOnlyMode struct {
+	TenantID string `json:"tenant_id" gorm:"primaryKey"`
+
+	// Reason is one of the ReadOnly* reason codes
+	Reason string `json:"reason" gorm:"size:32;not null"`
+
+	// Message is shown to users instead of the default text of the reason, e.g. "back at 14:00 UTC"
+	Message string `json:"message,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewReadOnlyMode creates a read-only switch with validation
+func NewReadOnlyMode(tenantID, reason, message string) (*ReadOnlyMode, error) {
+	mode := &ReadOnlyMode{
+		TenantID: tenantID,
+		Reason:   strings.TrimSpace(reason),
+		Message:  strings.TrimSpace(message),
+	}
+	if mode.Reason != ReadOnlyMaintenance && mode.Reason != ReadOnlyBillingSuspended {
+		return nil, ErrInvalidReadOnlyMode
+	}
+	if utf8.RuneCountInString(mode.Message) > MaxReadOnlyMessageLength {
+		return nil, ErrInvalidReadOnlyMode
+	}
+	return mode, nil
+}
+
+// Error returns what refused writes tell the user
+func (m *ReadOnlyMode) Error() string {
+	if m.Message != "" {
+		return m.Message
+	}
+	if m.Reason == ReadOnlyBillingSuspended {
+		return "this account is read-only until its subscription is paid"
+	}
+	return "the service is read-only for maintenance; please try again later"
+}
+
+// ReadOnlyRepository defines how read-only switches are stored
+type ReadOnlyRepository interface {
+	// List returns every switch that is on, the deployment-wide one included
+	List(ctx context.Context) ([]*ReadOnlyMode, error)
+
+	// Save switches a tenant to read-only, replacing an earlier reason
+	Save(ctx context.Context, mode *ReadOnlyMode) error
+
+	// Delete switches a tenant back to writable, or returns ErrReadOnlyModeNotFound
+	Delete(ctx context.Context, tenantID string) error
+}
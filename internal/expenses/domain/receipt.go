@@ -0,0 +1,242 @@
+// Package domain contains the core business logic and entities
+// This file defines the receipt inbox: receipts uploaded without an expense, and how they are
+// matched to the expenses they document
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"math"    // For amount and date distances
+	"regexp"  // For finding amounts and dates in OCR text
+	"strings" // For text normalization
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// DefaultReceiptMatchThreshold is the confidence from which a receipt is attached automatically
+// 0.8 needs the exact amount plus either the same day or the same merchant
+const DefaultReceiptMatchThreshold = 0.8
+
+// MaxReceiptBatch is the most files accepted by one bulk upload
+const MaxReceiptBatch = 50
+
+// ReceiptMatchWindow is how many days a receipt's date may be away from the expense date
+// Card transactions are often booked a day or two after the purchase
+const ReceiptMatchWindow = 3
+
+// ReceiptFacts are the details read from a receipt's OCR text
+// Any of them may be missing when the text is unreadable
+type ReceiptFacts struct {
+	Amount   Money      `json:"amount,omitzero"`
+	Date     *time.Time `json:"date,omitempty"`
+	Merchant string     `json:"merchant,omitempty"`
+}
+
+var (
+	// receiptAmount matches money amounts such as 12.50, 12,50 or 1.234,50
+	receiptAmount = regexp.MustCompile(`\d{1,3}(?:[.,']?\d{3})*[.,]\d{2}\b`)
+
+	// receiptTotalLine matches the line that carries the amount paid
+	receiptTotalLine = regexp.MustCompile(`(?i)\b(total|gesamt|summe|montant|importe|amount due|to pay|zu zahlen)\b`)
+
+	// receiptISODate matches 2025-03-14
+	receiptISODate = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+
+	// receiptDayFirstDate matches 14.03.2025, 14/03/2025 and 14.03.25
+	receiptDayFirstDate = regexp.MustCompile(`\b(\d{1,2})[./](\d{1,2})[./](\d{4}|\d{2})\b`)
+)
+
+// ParseReceiptText reads the amount paid, the purchase date and the merchant from OCR text
+// The amount is taken from the "total" line when there is one, otherwise the largest amount wins
+// The merchant is the first line with letters, which is where shops print their name
+func ParseReceiptText(text string) ReceiptFacts {
+	var facts ReceiptFacts
+	lines := strings.Split(text, "\n")
+
+	// Amount: prefer the total line, fall back to the largest amount on the receipt
+	for _, line := range lines {
+		if receiptTotalLine.MatchString(line) {
+			if amounts := receiptAmount.FindAllString(line, -1); len(amounts) > 0 {
+				facts.Amount = parseReceiptAmount(amounts[len(amounts)-1])
+				break
+			}
+		}
+	}
+	if facts.Amount.IsZero() {
+		for _, match := range receiptAmount.FindAllString(text, -1) {
+			if amount := parseReceiptAmount(match); amount.Minor > facts.Amount.Minor {
+				facts.Amount = amount
+			}
+		}
+	}
+
+	// Date: the first date that parses
+	if m := receiptISODate.FindStringSubmatch(text); m != nil {
+		facts.Date = receiptDate(m[1], m[2], m[3])
+	}
+	if facts.Date == nil {
+		for _, m := range receiptDayFirstDate.FindAllStringSubmatch(text, -1) {
+			year := m[3]
+			if len(year) == 2 {
+				year = "20" + year
+			}
+			if facts.Date = receiptDate(year, m[2], m[1]); facts.Date != nil {
+				break
+			}
+		}
+	}
+
+	// Merchant: the first line that reads like a name
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.IndexFunc(line, func(r rune) bool { return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' }) >= 0 {
+			facts.Merchant = line
+			break
+		}
+	}
+	return facts
+}
+
+// parseReceiptAmount parses "1.234,50", "1,234.50" or "12.50"
+// The last separator is the decimal one; everything before it is a thousands separator
+func parseReceiptAmount(value string) Money {
+	decimal := value[len(value)-3]
+	whole := strings.NewReplacer(".", "", ",", "", "'", "").Replace(value[:len(value)-3])
+	amount, err := ParseMoney(whole+"."+value[len(value)-2:], "")
+	if err != nil || (decimal != '.' && decimal != ',') {
+		return Money{}
+	}
+	return amount
+}
+
+// receiptDate builds a date from its parts, or nil when they don't form a real date
+func receiptDate(year, month, day string) *time.Time {
+	t, err := time.Parse("2006-1-2", year+"-"+strings.TrimLeft(month, "0")+"-"+strings.TrimLeft(day, "0"))
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// ScoreReceiptMatch rates from 0 to 1 how likely expense is the purchase on the receipt
+// The amount weighs most (0.5), then the date (0.3) and the merchant (0.2)
+func ScoreReceiptMatch(facts ReceiptFacts, expense *Expense) float64 {
+	score := 0.0
+
+	// Amount: exact to the cent, or within 1% (tips, rounding, card fees)
+	if facts.Amount.Minor > 0 {
+		switch diff := expense.Amount.Sub(facts.Amount).Abs(); {
+		case diff.IsZero():
+			score += 0.5
+		case diff.Minor <= facts.Amount.Mul(0.01).Minor:
+			score += 0.3
+		}
+	}
+
+	// Date: same day, or a few days apart because of booking delays
+	if facts.Date != nil {
+		days := math.Abs(expense.Date.Sub(*facts.Date).Hours() / 24)
+		switch {
+		case days < 1:
+			score += 0.3
+		case days < 2:
+			score += 0.2
+		case days <= ReceiptMatchWindow:
+			score += 0.1
+		}
+	}
+
+	// Merchant: share of the receipt's merchant words found in the expense
+	if words := merchantWords(facts.Merchant); len(words) > 0 {
+		target := strings.ToLower(expense.Merchant + " " + expense.Description + " " + expense.NormalizedDescription)
+		found := 0
+		for _, word := range words {
+			if strings.Contains(target, word) {
+				found++
+			}
+		}
+		score += 0.2 * float64(found) / float64(len(words))
+	}
+
+	return math.Round(score*100) / 100
+}
+
+// merchantWords returns the lower-case words of a merchant name worth comparing
+// Words shorter than 3 characters are mostly OCR noise and are ignored
+func merchantWords(merchant string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(merchant), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		if len(word) >= 3 {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// PendingReceipt is an uploaded receipt that couldn't be matched to an expense with enough confidence
+// It waits in the inbox of whoever uploaded it until they match it by hand or discard it
+type PendingReceipt struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	FileName    string    `json:"file_name" gorm:"not null"`
+	ContentType string    `json:"content_type" gorm:"not null"`
+	Size        int64     `json:"size" gorm:"not null"`
+
+	// StorageKey is where the file waits in blob storage; it is never shown to clients
+	StorageKey string `json:"-" gorm:"not null"`
+
+	OCRText string `json:"ocr_text,omitempty" gorm:"type:text"`
+
+	// The facts read from the receipt, kept so the inbox can suggest candidates
+	Amount   Money      `json:"amount,omitzero" gorm:"type:numeric(19,2)"`
+	Date     *time.Time `json:"date,omitempty"`
+	Merchant string     `json:"merchant,omitempty"`
+
+	// UserID is the user who uploaded the receipt; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// BookID is the book the receipt was uploaded in (nil for the owner's default book)
+	BookID *uuid.UUID `json:"book_id,omitempty" gorm:"type:uuid"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewPendingReceipt creates an inbox entry for an uploaded file
+func NewPendingReceipt(fileName, contentType string, size int64) (*PendingReceipt, error) {
+	fileName = strings.TrimSpace(fileName)
+	if fileName == "" || size <= 0 {
+		return nil, ErrInvalidAttachment
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	id := uuid.New()
+	return &PendingReceipt{
+		ID:          id,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  "receipts/pending/" + id.String(),
+	}, nil
+}
+
+// Facts returns the details read from the receipt
+func (r *PendingReceipt) Facts() ReceiptFacts {
+	return ReceiptFacts{Amount: r.Amount, Date: r.Date, Merchant: r.Merchant}
+}
+
+// PendingReceiptRepository defines the data access operations for the receipt inbox
+type PendingReceiptRepository interface {
+	// Create saves a pending receipt in the caller's inbox
+	Create(ctx context.Context, receipt *PendingReceipt) error
+
+	// GetByID retrieves one of the caller's pending receipts, or returns ErrPendingReceiptNotFound
+	GetByID(ctx context.Context, id string) (*PendingReceipt, error)
+
+	// List returns the caller's pending receipts in their current book, oldest first
+	List(ctx context.Context) ([]*PendingReceipt, error)
+
+	// Delete removes a pending receipt
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,157 @@
+// Package domain contains the core business logic and entities
+// This file defines receivables: money someone owes the user, e.g. after covering a dinner
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For input normalization
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Receivable statuses, derived from the settlement and the due date
+const (
+	// ReceivableOpen is owed and not yet due (or without a due date)
+	ReceivableOpen = "open"
+
+	// ReceivableOverdue is owed past its due date
+	ReceivableOverdue = "overdue"
+
+	// ReceivableSettled has been paid back in full
+	ReceivableSettled = "settled"
+)
+
+// Receivable is money someone owes the user for a one-off favour ("I covered the dinner")
+// Unlike group budgets it involves a single debtor who doesn't need an account
+type Receivable struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user who is owed; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// Debtor is who owes the money (e.g. "Alex")
+	Debtor string `json:"debtor" gorm:"not null"`
+
+	// Description says what the money is for (e.g. "Dinner at Luigi's")
+	Description string `json:"description,omitempty"`
+
+	// Amount is what is owed in total, in Currency
+	Amount   Money  `json:"amount" gorm:"type:numeric(19,2);not null"`
+	Currency string `json:"currency" gorm:"size:3;not null"`
+
+	// SettledAmount is what has been paid back so far
+	SettledAmount Money `json:"settled_amount" gorm:"type:numeric(19,2);not null;default:0"`
+
+	// ExpenseID is the expense the user paid on the debtor's behalf (nil if not recorded)
+	ExpenseID *uuid.UUID `json:"expense_id,omitempty" gorm:"type:uuid;index"`
+
+	// DueDate is when the money should be paid back (nil for "whenever")
+	DueDate *time.Time `json:"due_date,omitempty"`
+
+	// ReminderDays is how many days before the due date to remind the user (0 for no reminder)
+	ReminderDays int `json:"reminder_days"`
+
+	// SettledAt is when the last of the money came back (nil while something is owed)
+	SettledAt *time.Time `json:"settled_at,omitempty" gorm:"index"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewReceivable creates a validated receivable
+// A reminder needs a due date to count back from
+func NewReceivable(debtor, description string, amount Money, currency string, expenseID *uuid.UUID, dueDate *time.Time, reminderDays int) (*Receivable, error) {
+	debtor = strings.TrimSpace(debtor)
+	if debtor == "" || amount.Minor <= 0 {
+		return nil, ErrInvalidReceivable
+	}
+	if reminderDays < 0 || reminderDays > MaxReminderDays || (reminderDays > 0 && dueDate == nil) {
+		return nil, ErrInvalidReceivable
+	}
+	code, err := NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	if dueDate != nil {
+		due := dayOf(*dueDate)
+		dueDate = &due
+	}
+
+	return &Receivable{
+		ID:           uuid.New(),
+		Debtor:       debtor,
+		Description:  strings.TrimSpace(description),
+		Amount:       amount,
+		Currency:     code,
+		ExpenseID:    expenseID,
+		DueDate:      dueDate,
+		ReminderDays: reminderDays,
+	}, nil
+}
+
+// Outstanding is what is still owed
+func (r *Receivable) Outstanding() Money {
+	return r.Amount.Sub(r.SettledAmount)
+}
+
+// Status returns whether the receivable is open, overdue or settled as of now
+// It is overdue from the day after its due date
+func (r *Receivable) Status(now time.Time) string {
+	switch {
+	case r.SettledAt != nil:
+		return ReceivableSettled
+	case r.DueDate != nil && dayOf(now).After(*r.DueDate):
+		return ReceivableOverdue
+	default:
+		return ReceivableOpen
+	}
+}
+
+// Settle records a repayment of amount at the given time
+// Partial repayments are allowed; the receivable is settled once nothing is outstanding
+// Paying back more than is owed is refused, as is settling an already settled receivable
+func (r *Receivable) Settle(amount Money, at time.Time) error {
+	if r.SettledAt != nil {
+		return ErrReceivableSettled
+	}
+	if amount.Minor <= 0 || amount.Minor > r.Outstanding().Minor {
+		return ErrInvalidSettlement
+	}
+	r.SettledAmount = r.SettledAmount.Add(amount)
+	if r.Outstanding().IsZero() {
+		r.SettledAt = &at
+	}
+	return nil
+}
+
+// ReceivableFilter narrows a receivable list
+type ReceivableFilter struct {
+	// Status keeps only receivables with this status (empty for all)
+	Status string
+
+	// AsOf is the time overdue is judged against
+	AsOf time.Time
+
+	// Debtor keeps only the receivables of this debtor, case-insensitively (empty for all)
+	Debtor string
+}
+
+// ReceivableRepository defines the data access operations for receivables
+// Every operation is limited to the receivables of the caller in ctx
+type ReceivableRepository interface {
+	// Create saves a new receivable owned by the caller
+	Create(ctx context.Context, receivable *Receivable) error
+
+	// GetByID retrieves one of the caller's receivables, or returns ErrReceivableNotFound
+	GetByID(ctx context.Context, id string) (*Receivable, error)
+
+	// List returns the caller's receivables matching filter, by due date (undated last)
+	List(ctx context.Context, filter ReceivableFilter) ([]*Receivable, error)
+
+	// Update saves the settlement of a receivable
+	Update(ctx context.Context, receivable *Receivable) error
+
+	// Delete removes one of the caller's receivables, or returns ErrReceivableNotFound
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,163 @@
+// Package domain contains the core business logic and entities
+// This file defines statement reconciliation: checking a bank statement line by line against the recorded expenses
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"math"    // For amount and date distances
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Reconciliation session statuses
+const (
+	// ReconciliationOpen sessions can still be matched and unmatched
+	ReconciliationOpen = "open"
+
+	// ReconciliationClosed sessions are final; their matched expenses are marked reconciled
+	ReconciliationClosed = "closed"
+)
+
+// ReconciliationMatchWindow is how many days a statement line may be booked away from the expense date
+// Card payments typically show up on the statement one to three days after the purchase
+const ReconciliationMatchWindow = 3
+
+// MaxStatementLines is the most lines accepted for one statement period
+const MaxStatementLines = 5000
+
+// ReconciliationSession is one statement period being checked against the recorded expenses
+type ReconciliationSession struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user the session belongs to; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// AccountID limits the session to the expenses paid from one account (nil = all expenses)
+	AccountID *uuid.UUID `json:"account_id,omitempty" gorm:"type:uuid;index"`
+
+	// PeriodStart and PeriodEnd are the first and last day the statement covers
+	PeriodStart time.Time `json:"period_start" gorm:"not null"`
+	PeriodEnd   time.Time `json:"period_end" gorm:"not null"`
+
+	// Status is ReconciliationOpen or ReconciliationClosed
+	Status string `json:"status" gorm:"not null;default:open"`
+
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+}
+
+// NewReconciliationSession creates an open session for the statement period from start to end (both inclusive)
+func NewReconciliationSession(accountID *uuid.UUID, start, end time.Time) (*ReconciliationSession, error) {
+	start, end = start.UTC().Truncate(24*time.Hour), end.UTC().Truncate(24*time.Hour)
+	if start.IsZero() || end.Before(start) {
+		return nil, ErrInvalidStatement
+	}
+	return &ReconciliationSession{
+		ID:          uuid.New(),
+		AccountID:   accountID,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Status:      ReconciliationOpen,
+	}, nil
+}
+
+// IsOpen reports whether the session can still be changed
+func (s *ReconciliationSession) IsOpen() bool {
+	return s.Status == ReconciliationOpen
+}
+
+// Covers reports whether t falls on one of the days of the statement period
+func (s *ReconciliationSession) Covers(t time.Time) bool {
+	return !t.Before(s.PeriodStart) && t.Before(s.PeriodEnd.AddDate(0, 0, 1))
+}
+
+// StatementLine is one transaction of the uploaded statement
+type StatementLine struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	SessionID uuid.UUID `json:"session_id" gorm:"type:uuid;not null;index"`
+
+	// ExternalID is the bank's transaction ID; it matches imported expenses exactly
+	ExternalID  string    `json:"external_id,omitempty"`
+	Date        time.Time `json:"date" gorm:"not null"`
+	Amount      Money     `json:"amount" gorm:"type:numeric(19,2);not null"`
+	Description string    `json:"description"`
+
+	// ExpenseID is the recorded expense the line was matched to (nil while unmatched)
+	ExpenseID *uuid.UUID `json:"expense_id,omitempty" gorm:"type:uuid;index"`
+
+	// MatchConfidence is how sure the auto-matcher was (1 for manual matches, 0 while unmatched)
+	MatchConfidence float64 `json:"match_confidence,omitempty"`
+}
+
+// NewStatementLine creates an unmatched line of session from an imported transaction
+func NewStatementLine(sessionID uuid.UUID, tx ImportedTransaction) *StatementLine {
+	return &StatementLine{
+		ID:          uuid.New(),
+		SessionID:   sessionID,
+		ExternalID:  tx.ExternalID,
+		Date:        tx.Date,
+		Amount:      tx.Amount,
+		Description: tx.Description,
+	}
+}
+
+// IsMatched reports whether the line has been matched to an expense
+func (l *StatementLine) IsMatched() bool {
+	return l.ExpenseID != nil
+}
+
+// Match links the line to expense
+func (l *StatementLine) Match(expenseID uuid.UUID, confidence float64) {
+	l.ExpenseID = &expenseID
+	l.MatchConfidence = confidence
+}
+
+// Unmatch removes the link to the expense
+func (l *StatementLine) Unmatch() {
+	l.ExpenseID = nil
+	l.MatchConfidence = 0
+}
+
+// ScoreStatementMatch rates from 0 to 1 how likely expense is the transaction on the statement line
+// The same bank transaction ID is a certain match; otherwise the amount must be exact to the cent
+// and the dates at most ReconciliationMatchWindow days apart, with closer dates scoring higher
+func ScoreStatementMatch(line *StatementLine, expense *Expense) float64 {
+	if line.ExternalID != "" && line.ExternalID == expense.ExternalID {
+		return 1
+	}
+	if line.Amount.Minor != expense.Amount.Minor {
+		return 0
+	}
+	switch days := math.Abs(line.Date.Sub(expense.Date).Hours() / 24); {
+	case days < 1:
+		return 0.95
+	case days < 2:
+		return 0.9
+	case days <= ReconciliationMatchWindow:
+		return 0.8
+	default:
+		return 0
+	}
+}
+
+// ReconciliationRepository defines the data access operations for reconciliation sessions
+type ReconciliationRepository interface {
+	// Create saves a new session together with its statement lines
+	Create(ctx context.Context, session *ReconciliationSession, lines []*StatementLine) error
+
+	// GetByID retrieves a session, or returns ErrReconciliationNotFound
+	GetByID(ctx context.Context, id string) (*ReconciliationSession, error)
+
+	// List returns all sessions, newest statement period first
+	List(ctx context.Context) ([]*ReconciliationSession, error)
+
+	// Lines returns the statement lines of a session in statement order (oldest first)
+	Lines(ctx context.Context, sessionID uuid.UUID) ([]*StatementLine, error)
+
+	// SaveLines stores the match state of the given lines
+	SaveLines(ctx context.Context, lines []*StatementLine) error
+
+	// Close marks the session closed and the given expenses reconciled, all at once
+	Close(ctx context.Context, session *ReconciliationSession, expenseIDs []uuid.UUID) error
+}
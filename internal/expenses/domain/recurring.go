@@ -0,0 +1,161 @@
+// Package domain contains the core business logic and entities
+// This file defines recurring expenses: bills and subscriptions that are due on a fixed schedule
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For input normalization
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Cadence is how often a recurring expense is due
+type Cadence string
+
+const (
+	// CadenceWeekly is due every week on the weekday of the first due date
+	CadenceWeekly Cadence = "weekly"
+
+	// CadenceMonthly is due every month on the day of the first due date
+	// In shorter months it falls on the last day (a bill due on the 31st is due on 30 April)
+	CadenceMonthly Cadence = "monthly"
+
+	// CadenceYearly is due every year on the date of the first due date
+	CadenceYearly Cadence = "yearly"
+)
+
+// MaxReminderDays is the longest a bill reminder can come before the due date
+const MaxReminderDays = 60
+
+// RecurringExpense is a bill or subscription that is due on a fixed schedule (rent, insurance, streaming)
+// It doesn't create expenses by itself; it tells the user what is coming up and when to pay it
+type RecurringExpense struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user the bill belongs to; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// Description names the bill (e.g. "Rent")
+	Description string `json:"description" gorm:"not null"`
+
+	// Amount is what is due each time, in Currency
+	Amount   Money  `json:"amount" gorm:"type:numeric(19,2);not null"`
+	Currency string `json:"currency,omitempty" gorm:"size:3"`
+
+	// Category is the category the payments are booked under
+	Category string `json:"category" gorm:"not null"`
+
+	// Cadence is how often it is due
+	Cadence Cadence `json:"cadence" gorm:"not null"`
+
+	// StartDate is the first due date; later due dates are counted from it
+	StartDate time.Time `json:"start_date" gorm:"not null"`
+
+	// EndDate is the last day it can be due (nil while the contract runs)
+	EndDate *time.Time `json:"end_date,omitempty"`
+
+	// ReminderDays is how many days before each due date to remind the user (0 for no reminder)
+	ReminderDays int `json:"reminder_days"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewRecurringExpense creates a validated recurring expense
+func NewRecurringExpense(description string, amount Money, currency, category string, cadence Cadence, start time.Time, end *time.Time, reminderDays int) (*RecurringExpense, error) {
+	description = strings.TrimSpace(description)
+	category = strings.TrimSpace(category)
+	if description == "" || amount.Minor <= 0 || category == "" || start.IsZero() {
+		return nil, ErrInvalidRecurringExpense
+	}
+	switch cadence {
+	case CadenceWeekly, CadenceMonthly, CadenceYearly:
+	default:
+		return nil, ErrInvalidRecurringExpense
+	}
+	if end != nil && end.Before(start) {
+		return nil, ErrInvalidRecurringExpense
+	}
+	if reminderDays < 0 || reminderDays > MaxReminderDays {
+		return nil, ErrInvalidRecurringExpense
+	}
+	if strings.TrimSpace(currency) != "" {
+		code, err := NormalizeCurrency(currency)
+		if err != nil {
+			return nil, err
+		}
+		currency = code
+	}
+
+	return &RecurringExpense{
+		ID:           uuid.New(),
+		Description:  description,
+		Amount:       amount,
+		Currency:     currency,
+		Category:     category,
+		Cadence:      cadence,
+		StartDate:    dayOf(start),
+		EndDate:      end,
+		ReminderDays: reminderDays,
+	}, nil
+}
+
+// Occurrences returns the due dates from from to to (both inclusive), in order
+func (r *RecurringExpense) Occurrences(from, to time.Time) []time.Time {
+	from, to = dayOf(from), dayOf(to)
+	if r.EndDate != nil && dayOf(*r.EndDate).Before(to) {
+		to = dayOf(*r.EndDate)
+	}
+
+	var dates []time.Time
+	for n := 0; ; n++ {
+		due := r.occurrence(n)
+		if due.After(to) {
+			return dates
+		}
+		if !due.Before(from) {
+			dates = append(dates, due)
+		}
+	}
+}
+
+// occurrence returns the n-th due date, counting the start date as 0
+func (r *RecurringExpense) occurrence(n int) time.Time {
+	start := dayOf(r.StartDate)
+	switch r.Cadence {
+	case CadenceWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case CadenceYearly:
+		return clampedDate(start.Year()+n, start.Month(), start.Day())
+	default:
+		months := int(start.Month()) - 1 + n
+		return clampedDate(start.Year()+months/12, time.Month(months%12+1), start.Day())
+	}
+}
+
+// clampedDate returns the given date, or the last day of the month if the month is shorter
+func clampedDate(year int, month time.Month, day int) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// dayOf returns midnight UTC of t's date
+func dayOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// RecurringExpenseRepository defines how recurring expenses are stored
+type RecurringExpenseRepository interface {
+	// Create saves a new recurring expense owned by the caller
+	Create(ctx context.Context, recurring *RecurringExpense) error
+
+	// List returns the caller's recurring expenses ordered by start date
+	List(ctx context.Context) ([]*RecurringExpense, error)
+
+	// Delete removes one of the caller's recurring expenses, or returns ErrRecurringExpenseNotFound
+	Delete(ctx context.Context, id string) error
+}
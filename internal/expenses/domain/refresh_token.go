@@ -0,0 +1,147 @@
+// Package domain contains the core business logic and entities
+// This file defines refresh tokens, which let clients get new access tokens without a new login
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// RefreshToken is a long-lived login of one user on one client
+// Only a hash of the token is stored, so a leaked database doesn't leak working tokens
+// Tokens are rotated: each refresh revokes the token used and issues a new one
+// The tokens rotated from one login form a session, which keeps its ID, start and device
+type RefreshToken struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+
+	// SessionID is shared by every token rotated from the same login
+	// It is unset (uuid.Nil) for tokens issued before sessions were tracked; their own ID stands in
+	SessionID uuid.UUID `json:"session_id" gorm:"type:uuid;index"`
+
+	// SessionStartedAt is when the session's login happened
+	SessionStartedAt *time.Time `json:"session_started_at,omitempty"`
+
+	// UserAgent and IPAddress identify the device of the latest login or refresh
+	UserAgent string `json:"user_agent,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+
+	// TokenHash is the SHA-256 of the token handed to the client
+	TokenHash string `json:"-" gorm:"not null;uniqueIndex"`
+
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+
+	// RevokedAt is when the token was used, logged out or revoked (nil while usable)
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+
+	// Used marks a token revoked by exchanging it for the next one of its session, which goes on
+	Used bool `json:"-" gorm:"not null;default:false"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// NewRefreshToken creates a refresh token record for userID from the hash of a new token
+// session is the session the token continues; nil starts a new session at startedAt
+func NewRefreshToken(userID uuid.UUID, tokenHash string, expiresAt time.Time, session *RefreshToken, startedAt time.Time) *RefreshToken {
+	token := &RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	}
+	if session != nil {
+		token.SessionID = session.Session()
+		token.SessionStartedAt = session.SessionStartedAt
+		if token.SessionStartedAt == nil {
+			token.SessionStartedAt = &session.CreatedAt
+		}
+	} else {
+		token.SessionID = token.ID
+		token.SessionStartedAt = &startedAt
+	}
+	return token
+}
+
+// Session returns the ID of the session the token belongs to
+func (t *RefreshToken) Session() uuid.UUID {
+	if t.SessionID == uuid.Nil {
+		return t.ID
+	}
+	return t.SessionID
+}
+
+// Usable reports whether the token can still be exchanged at time now
+func (t *RefreshToken) Usable(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// Session is a logged-in device of a user: the usable refresh token of one login
+type Session struct {
+	ID uuid.UUID `json:"id"`
+
+	// UserAgent and IPAddress are those of the latest login or refresh
+	UserAgent string `json:"user_agent,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+
+	// StartedAt is the login; LastUsedAt the latest refresh (or the login)
+	StartedAt  time.Time `json:"started_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+
+	// ExpiresAt is when the session ends unless it is refreshed
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Current marks the session of the access token the list was requested with
+	Current bool `json:"current"`
+}
+
+// AsSession describes the session of a usable token
+func (t *RefreshToken) AsSession() *Session {
+	started := t.CreatedAt
+	if t.SessionStartedAt != nil {
+		started = *t.SessionStartedAt
+	}
+	return &Session{
+		ID:         t.Session(),
+		UserAgent:  t.UserAgent,
+		IPAddress:  t.IPAddress,
+		StartedAt:  started,
+		LastUsedAt: t.CreatedAt,
+		ExpiresAt:  t.ExpiresAt,
+	}
+}
+
+// RefreshTokenRepository defines how refresh tokens are stored
+type RefreshTokenRepository interface {
+	// Create saves a new refresh token
+	Create(ctx context.Context, token *RefreshToken) error
+
+	// GetByHash retrieves a token by the hash of its value, or returns ErrInvalidRefreshToken
+	GetByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+
+	// Revoke marks a token as revoked at the given time
+	// It reports false if the token was already revoked
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+
+	// Use marks a token as used up by a refresh at the given time
+	// It reports false if the token was already revoked, so a token is only ever used once
+	Use(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+
+	// SessionActive reports whether a session of the user is still logged in: whether its newest
+	// token is unrevoked or was used for the next one. Expiry is left to the access tokens
+	SessionActive(ctx context.Context, userID, sessionID uuid.UUID) (bool, error)
+
+	// ListUsable returns the tokens of a user that are neither revoked nor expired at now, newest first
+	// Tokens are rotated, so there is one per session
+	ListUsable(ctx context.Context, userID uuid.UUID, now time.Time) ([]*RefreshToken, error)
+
+	// RevokeSession revokes the usable tokens of one of a user's sessions and returns how many there were
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID, at time.Time) (int64, error)
+
+	// RevokeAllForUser revokes every usable token of a user and returns how many there were
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) (int64, error)
+
+	// DeleteExpired deletes the tokens that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
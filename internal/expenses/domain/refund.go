@@ -0,0 +1,80 @@
+// Package domain contains the core business logic and entities
+// This file defines refunds: money given back on an expense, recorded as a negative
+// adjustment linked to it so spending is reported net of what came back
+package domain
+
+import (
+	"strings" // For input normalization
+	"time"    // For handling dates and times
+)
+
+// IsRefund reports whether the expense is a refund of another expense
+func (e *Expense) IsRefund() bool {
+	return e.ParentExpenseID != nil
+}
+
+// RefundableOn reports whether money can come back on the expense on date: not before it was spent
+func (e *Expense) RefundableOn(date time.Time) bool {
+	return !date.IsZero() && !dayOf(date).Before(dayOf(e.Date))
+}
+
+// RefundedAmount totals what refunds gave back, as a positive amount in their currency
+func RefundedAmount(refunds []*Expense) Money {
+	var total Money
+	for _, refund := range refunds {
+		total = total.Sub(refund.Amount)
+	}
+	return total
+}
+
+// NewRefund creates a refund of amount (in the expense's currency) on parent, given back on date
+// refunded is what earlier refunds of parent already gave back; together they can't exceed it
+// The refund takes over the expense's category, conversion, VAT split and marks, so every
+// report the expense counts in is reduced by it
+func NewRefund(parent *Expense, amount, refunded Money, description string, date time.Time) (*Expense, error) {
+	// Step 1: Validate
+	if parent.IsRefund() || amount.Minor <= 0 || !parent.RefundableOn(date) {
+		return nil, ErrInvalidRefund
+	}
+	if refunded.Add(amount).Minor > parent.Amount.Minor {
+		return nil, ErrRefundExceedsExpense
+	}
+	description = strings.TrimSpace(description)
+	if description == "" {
+		description = "Refund: " + parent.Description
+	}
+
+	// Step 2: Mirror the expense, negated
+	parentID := parent.ID
+	refund := &Expense{
+		ID:               newExpenseID(),
+		ParentExpenseID:  &parentID,
+		Description:      description,
+		Amount:           amount.Neg(),
+		Currency:         parent.Currency,
+		BaseCurrency:     parent.BaseCurrency,
+		ExchangeRate:     parent.ExchangeRate,
+		BaseAmount:       amount.Mul(parent.ExchangeRate).Neg(),
+		ConversionSource: parent.ConversionSource,
+		Category:         parent.Category,
+		Date:             date,
+		Merchant:         parent.Merchant,
+		MCC:              parent.MCC,
+		Source:           SourceManual,
+		AccountID:        parent.AccountID,
+		CostCenter:       parent.CostCenter,
+		Department:       parent.Department,
+		Business:         parent.Business,
+		TaxDeductible:    parent.TaxDeductible,
+		Tags:             parent.Tags,
+		MemberID:         parent.MemberID,
+	}
+
+	// Step 3: Give back the matching share of the VAT
+	if parent.VATRate != nil || !parent.TaxAmount.IsZero() {
+		refund.VATRate = parent.VATRate
+		refund.TaxAmount = parent.TaxAmount.Mul(amount.Float64() / parent.Amount.Float64()).Neg()
+		refund.NetAmount = refund.Amount.Sub(refund.TaxAmount)
+	}
+	return refund, nil
+}
@@ -0,0 +1,45 @@
+// Package domain contains the core business logic and entities
+// This file defines the roles of the regions in an active-passive deployment and the
+// replication state of their databases
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For parsing the configured role
+)
+
+// Region roles
+const (
+	// RegionActive serves reads and writes against the primary database
+	RegionActive = "active"
+
+	// RegionPassive is a standby: it serves reads from a replica and refuses writes until it
+	// is promoted (the database first, then the region is restarted as active)
+	RegionPassive = "passive"
+)
+
+// ParseRegionRole parses "active" or "passive"; an empty string means active
+func ParseRegionRole(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", RegionActive:
+		return RegionActive, nil
+	case RegionPassive:
+		return RegionPassive, nil
+	}
+	return "", ErrInvalidRegionRole
+}
+
+// ReplicationStatus is the replication state of the database a region uses
+type ReplicationStatus struct {
+	// InRecovery is true for a standby that replays the primary's changes (and can't be written)
+	InRecovery bool `json:"in_recovery"`
+
+	// LagSeconds is how far a standby's replay is behind the primary (nil on a primary, or
+	// before the standby replayed anything); 0 when it has replayed everything it received
+	LagSeconds *float64 `json:"lag_seconds,omitempty"`
+}
+
+// ReplicationRepository reads the replication state of the database
+type ReplicationRepository interface {
+	ReplicationStatus(ctx context.Context) (*ReplicationStatus, error)
+}
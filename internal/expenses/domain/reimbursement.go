@@ -0,0 +1,177 @@
+// Package domain contains the core business logic and entities
+// This file defines the reimbursement lifecycle: reimbursable expenses are pending until they
+// are submitted in a batch (a claim), and paid once the batch's payout arrives
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For input normalization
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring text in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Reimbursement statuses of an expense; expenses that aren't reimbursable have none ("")
+const (
+	// ReimbursementPending is a reimbursable expense that hasn't been claimed yet
+	ReimbursementPending = "pending"
+
+	// ReimbursementSubmitted is an expense claimed in a batch that hasn't been paid yet
+	ReimbursementSubmitted = "submitted"
+
+	// ReimbursementPaid is an expense whose batch was paid out
+	ReimbursementPaid = "paid"
+)
+
+// MaxReimbursementBatchSize is the most expenses one batch may claim
+const MaxReimbursementBatchSize = 500
+
+// IsReimbursementStatus reports whether status is one of the reimbursement statuses
+func IsReimbursementStatus(status string) bool {
+	return status == ReimbursementPending || status == ReimbursementSubmitted || status == ReimbursementPaid
+}
+
+// SyncReimbursementStatus keeps the reimbursement status in line with the reimbursable mark:
+// a newly reimbursable expense is pending, and a personal one has no status
+func (e *Expense) SyncReimbursementStatus() {
+	switch {
+	case !e.Reimbursable:
+		e.ReimbursementStatus = ""
+	case e.ReimbursementStatus == "":
+		e.ReimbursementStatus = ReimbursementPending
+	}
+}
+
+// ReimbursementBatch is a set of reimbursable expenses claimed together, e.g. a monthly
+// expense report handed to an employer. It is submitted when created and paid once the
+// payout arrives; the payout can be checked against what was claimed
+type ReimbursementBatch struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user who claimed; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// EmployerID is who the expenses were claimed from (nil when they aren't charged to an employer)
+	EmployerID *uuid.UUID `json:"employer_id,omitempty" gorm:"type:uuid;index"`
+
+	// Reference identifies the claim (e.g. "Expense report March"), if the user wants to keep one
+	Reference string `json:"reference,omitempty"`
+
+	// Status is ReimbursementSubmitted or ReimbursementPaid
+	Status string `json:"status" gorm:"size:16;not null;index"`
+
+	// Expenses and Claimed are how many expenses were claimed and their total in the base currency
+	Expenses int   `json:"expenses" gorm:"not null"`
+	Claimed  Money `json:"claimed" gorm:"type:numeric(19,2);not null"`
+
+	// Paid is what arrived and PaidOn the day it did (set once the batch is paid)
+	// Difference is Paid minus Claimed: negative when the payout fell short
+	Paid       *Money     `json:"paid,omitempty" gorm:"type:numeric(19,2)"`
+	PaidOn     *time.Time `json:"paid_on,omitempty" gorm:"type:date"`
+	Difference *Money     `json:"difference,omitempty" gorm:"type:numeric(19,2)"`
+
+	// IncomeID is the reimbursement the payout was recorded as on the employer's balance
+	IncomeID *uuid.UUID `json:"income_id,omitempty" gorm:"type:uuid"`
+
+	SubmittedAt time.Time `json:"submitted_at" gorm:"autoCreateTime"`
+}
+
+// NewReimbursementBatch creates a validated batch claiming the given expenses
+// They must all be pending reimbursable expenses, charged to employerID when it is given
+func NewReimbursementBatch(employerID *uuid.UUID, reference string, expenses []*Expense) (*ReimbursementBatch, error) {
+	reference = strings.TrimSpace(reference)
+	if len(expenses) == 0 || len(expenses) > MaxReimbursementBatchSize ||
+		utf8.RuneCountInString(reference) > MaxReimbursementReferenceLength {
+		return nil, ErrInvalidReimbursementBatch
+	}
+
+	batch := &ReimbursementBatch{
+		ID:         uuid.New(),
+		EmployerID: employerID,
+		Reference:  reference,
+		Status:     ReimbursementSubmitted,
+	}
+	var claimed Money
+	for _, expense := range expenses {
+		if !expense.Reimbursable || expense.ReimbursementStatus != ReimbursementPending {
+			return nil, ErrReimbursementNotPending
+		}
+		if employerID != nil && (expense.EmployerID == nil || *expense.EmployerID != *employerID) {
+			return nil, ErrInvalidReimbursementBatch
+		}
+		batch.Expenses++
+		claimed = claimed.Add(expense.ReportingAmount())
+	}
+	batch.Claimed = claimed
+	return batch, nil
+}
+
+// MarkPaid records the payout of the batch; amount 0 means the claim was paid in full
+func (b *ReimbursementBatch) MarkPaid(amount Money, paidOn time.Time) error {
+	if b.Status != ReimbursementSubmitted {
+		return ErrReimbursementBatchPaid
+	}
+	if amount.IsNegative() || paidOn.IsZero() {
+		return ErrInvalidReimbursement
+	}
+	if amount.IsZero() {
+		amount = b.Claimed
+	}
+	day := dayOf(paidOn)
+	difference := amount.Sub(b.Claimed)
+	b.Status, b.Paid, b.PaidOn, b.Difference = ReimbursementPaid, &amount, &day, &difference
+	return nil
+}
+
+// ReimbursementTotal counts the expenses in one reimbursement status and sums them in the base currency
+type ReimbursementTotal struct {
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+	Amount Money  `json:"amount"`
+}
+
+// ReimbursementSummary is where the caller's reimbursable expenses stand
+type ReimbursementSummary struct {
+	// Pending, Submitted and Paid total the expenses in each status
+	Pending   ReimbursementTotal `json:"pending"`
+	Submitted ReimbursementTotal `json:"submitted"`
+	Paid      ReimbursementTotal `json:"paid"`
+
+	// Open are the batches waiting for their payout, oldest first
+	Open []*ReimbursementBatch `json:"open"`
+
+	// Shortfall is what the paid batches were paid less than claimed (negative if overpaid)
+	Shortfall Money `json:"shortfall"`
+}
+
+// ReimbursementRepository defines how reimbursement batches are stored
+// Every operation is limited to the caller's batches and the expenses of their current book
+type ReimbursementRepository interface {
+	// ClaimableExpenses returns the caller's expenses with the given IDs, or
+	// ErrExpenseNotFound if any of them isn't one
+	ClaimableExpenses(ctx context.Context, ids []uuid.UUID) ([]*Expense, error)
+
+	// CreateBatch saves a batch owned by the caller and marks its expenses submitted, or returns
+	// ErrReimbursementNotPending if one of them was claimed in the meantime
+	CreateBatch(ctx context.Context, batch *ReimbursementBatch, expenseIDs []uuid.UUID) error
+
+	// GetBatch retrieves one of the caller's batches, or returns ErrReimbursementBatchNotFound
+	GetBatch(ctx context.Context, id string) (*ReimbursementBatch, error)
+
+	// ListBatches returns the caller's batches, newest first
+	ListBatches(ctx context.Context) ([]*ReimbursementBatch, error)
+
+	// BatchExpenses returns the expenses claimed in a batch, by date
+	BatchExpenses(ctx context.Context, batchID uuid.UUID) ([]*Expense, error)
+
+	// MarkPaid saves the payout of a batch, records income (if not nil) as a reimbursement
+	// from the batch's employer, and marks the batch's expenses paid
+	MarkPaid(ctx context.Context, batch *ReimbursementBatch, income *ReimbursementIncome) error
+
+	// DeleteBatch withdraws a submitted batch: its expenses are pending again
+	DeleteBatch(ctx context.Context, batch *ReimbursementBatch) error
+
+	// Totals returns the count and total of the caller's reimbursable expenses per status
+	Totals(ctx context.Context) ([]*ReimbursementTotal, error)
+}
@@ -0,0 +1,101 @@
+// Package domain contains the core business logic and entities
+// This file defines reporting periods and the aggregated spending reports are built from
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"strings" // For parsing period expressions
+	"time"    // For handling dates and times
+)
+
+// Period is a reporting date range
+// Start is inclusive and End is exclusive, so consecutive periods never overlap
+type Period struct {
+	// Label is the period as the user wrote it (e.g. "2026-03")
+	Label string `json:"label"`
+
+	// Start is the first instant in the period
+	Start time.Time `json:"start"`
+
+	// End is the first instant after the period
+	End time.Time `json:"end"`
+}
+
+// ParsePeriod parses a period expression into a date range
+// Supported forms are a year ("2025"), a month ("2025-03"), a day ("2025-03-14")
+// and an inclusive day range ("2025-03-01..2025-05-31")
+func ParsePeriod(value string) (Period, error) {
+	value = strings.TrimSpace(value)
+	period := Period{Label: value}
+
+	if from, to, ok := strings.Cut(value, ".."); ok {
+		start, err := time.Parse("2006-01-02", strings.TrimSpace(from))
+		if err != nil {
+			return Period{}, ErrInvalidPeriod
+		}
+		last, err := time.Parse("2006-01-02", strings.TrimSpace(to))
+		if err != nil || last.Before(start) {
+			return Period{}, ErrInvalidPeriod
+		}
+		period.Start, period.End = start, last.AddDate(0, 0, 1)
+		return period, nil
+	}
+
+	if start, err := time.Parse("2006-01-02", value); err == nil {
+		period.Start, period.End = start, start.AddDate(0, 0, 1)
+		return period, nil
+	}
+	if start, err := time.Parse("2006-01", value); err == nil {
+		period.Start, period.End = start, start.AddDate(0, 1, 0)
+		return period, nil
+	}
+	if start, err := time.Parse("2006", value); err == nil {
+		period.Start, period.End = start, start.AddDate(1, 0, 0)
+		return period, nil
+	}
+	return Period{}, ErrInvalidPeriod
+}
+
+// CategorySpending is the total spent in one category over a period
+type CategorySpending struct {
+	// Category is the expense category
+	Category string `json:"category"`
+
+	// Amount is the sum of the reporting (base currency) amounts
+	Amount Money `json:"amount"`
+}
+
+// MerchantSpending is the total spent at one merchant over a period
+type MerchantSpending struct {
+	// Merchant is the merchant name, or the normalized description for manual expenses
+	Merchant string `json:"merchant"`
+
+	// Amount is the sum of the reporting (base currency) amounts
+	Amount Money `json:"amount"`
+
+	// Count is the number of expenses
+	Count int `json:"count"`
+}
+
+// SpendingRepository provides aggregated spending for budgets, forecasts and reports
+type SpendingRepository interface {
+	// SpendingByCategory sums expenses dated in [from, to) per category
+	SpendingByCategory(ctx context.Context, from, to time.Time) ([]*CategorySpending, error)
+
+	// SpendingByMerchant sums expenses dated in [from, to) per merchant
+	SpendingByMerchant(ctx context.Context, from, to time.Time) ([]*MerchantSpending, error)
+}
+
+// ReportFigures are the totals reports are built from
+type ReportFigures interface {
+	SpendingRepository
+	TaxRepository
+}
+
+// ReportHistory provides report totals as the expenses were at a past time, so figures already
+// handed to an accountant can be reproduced after the expenses behind them were changed
+type ReportHistory interface {
+	// FiguresAsOf returns the totals as they were at the given time
+	// It returns ErrReportHistoryNotRecorded for times before expense changes were recorded
+	FiguresAsOf(ctx context.Context, at time.Time) (ReportFigures, error)
+}
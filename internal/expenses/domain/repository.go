@@ -48,4 +48,12 @@ type Repository interface {
 	// Returns true if the expense exists, false if not, and an error if the operation fails
 	// This is useful for validation before performing operations
 	Exists(ctx context.Context, id string) (bool, error)
+
+	// Count returns how many expenses match the filters
+	// It is used to decide whether a list fits in memory before loading it
+	Count(ctx context.Context, filters map[string]interface{}) (int64, error)
+
+	// Stream calls fn for each expense matching the filters without loading them all at once
+	// If fn returns an error, streaming stops and that error is returned
+	Stream(ctx context.Context, filters map[string]interface{}, fn func(*Expense) error) error
 }
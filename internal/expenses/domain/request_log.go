@@ -0,0 +1,195 @@
+// Package domain contains the core business logic and entities
+// This file defines request logs: sampled requests and responses, kept for a while to debug
+// client issues that can't be reproduced, with personal data redacted
+package domain
+
+import (
+	"context"       // For request context (cancellation, timeouts)
+	"encoding/json" // For redacting JSON bodies
+	"net/http"      // For request headers
+	"net/url"       // For query strings
+	"strings"       // For matching sensitive names
+	"time"          // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Why a request was logged
+const (
+	// RequestLogSampled is a request picked by the sample rate
+	RequestLogSampled = "sampled"
+
+	// RequestLogFlagged is a request of a user an admin flagged for logging
+	RequestLogFlagged = "flagged"
+)
+
+// Redacted replaces the values of sensitive fields, headers and query parameters
+const Redacted = "[REDACTED]"
+
+// MaxRequestLogTargetDuration is how long a user may be flagged for logging at most
+// Flags are for chasing one issue, not for watching users
+const MaxRequestLogTargetDuration = 7 * 24 * time.Hour
+
+// sensitiveNames are the parts of field, header and parameter names whose values are redacted,
+// compared case-insensitively: credentials, signatures and personal contact and payment details
+var sensitiveNames = []string{
+	"password", "token", "secret", "authorization", "cookie", "api-key", "api_key", "apikey",
+	"signature", "sig", "email", "phone", "iban", "account_number", "card_number", "cvv", "tax_id", "ssn",
+}
+
+// RequestLog is one logged request and its response
+// Bodies are kept only when they are JSON, with the sensitive fields redacted; other bodies
+// (uploads, PDFs, CSV files) may be anything and are only described by their type and size
+type RequestLog struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the caller; nil for anonymous callers and the local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// Reason is RequestLogSampled or RequestLogFlagged
+	Reason string `json:"reason" gorm:"size:16;not null"`
+
+	Method string `json:"method" gorm:"size:10;not null"`
+
+	// Route is the matched route ("/expenses/:id"), Path the requested one
+	Route string `json:"route"`
+	Path  string `json:"path" gorm:"not null;index"`
+	Query string `json:"query,omitempty"`
+
+	RequestHeaders map[string]string `json:"request_headers" gorm:"type:jsonb;serializer:json"`
+	RequestBody    string            `json:"request_body,omitempty"`
+
+	Status       int    `json:"status"`
+	ResponseBody string `json:"response_body,omitempty"`
+
+	// Truncated is set when a body was longer than the logging limit and was cut off
+	Truncated bool `json:"truncated"`
+
+	DurationMS int64 `json:"duration_ms"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+
+	// ExpiresAt is when the log is purged
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+// RequestLogTarget flags a user whose every request is logged until Until
+type RequestLogTarget struct {
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+
+	Until time.Time `json:"until" gorm:"not null"`
+
+	// Note says what is being debugged, e.g. the support ticket
+	Note string `json:"note,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// RequestLogFilter narrows down a list of request logs
+type RequestLogFilter struct {
+	UserID *uuid.UUID
+	Path   string // prefix of the requested path
+	Status int    // 0 for any status
+
+	Limit int
+}
+
+// RedactHeaders copies the headers worth logging, one value each, with sensitive ones redacted
+func RedactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for name, values := range header {
+		if len(values) == 0 {
+			continue
+		}
+		value := values[0]
+		if sensitiveName(name) {
+			value = Redacted
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// RedactQuery returns a raw query string with the values of sensitive parameters redacted
+func RedactQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Redacted
+	}
+	for name := range values {
+		if sensitiveName(name) {
+			values[name] = []string{Redacted}
+		}
+	}
+	return values.Encode()
+}
+
+// RedactJSON returns a JSON body with the values of sensitive fields redacted, at any depth
+// Bodies that aren't valid JSON are redacted entirely
+func RedactJSON(body []byte) string {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return Redacted
+	}
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return Redacted
+	}
+	return string(redacted)
+}
+
+// redactValue redacts the sensitive fields of a decoded JSON value
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if sensitiveName(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// sensitiveName reports whether values named name are redacted
+func sensitiveName(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range sensitiveNames {
+		// Short parts like "sig" only match whole names; longer ones anywhere in the name
+		if name == part || (len(part) > 3 && strings.Contains(name, part)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestLogRepository defines how request logs and the users flagged for logging are stored
+// It isn't scoped to the caller: only admins read it
+type RequestLogRepository interface {
+	// Create saves a request log
+	Create(ctx context.Context, log *RequestLog) error
+
+	// List returns the logs matching filter, newest first
+	List(ctx context.Context, filter RequestLogFilter) ([]*RequestLog, error)
+
+	// GetByID returns a log, or ErrRequestLogNotFound
+	GetByID(ctx context.Context, id uuid.UUID) (*RequestLog, error)
+
+	// Purge deletes the logs that expired before the given time
+	Purge(ctx context.Context, before time.Time) (int64, error)
+
+	// ListTargets returns the users flagged for logging until after the given time
+	ListTargets(ctx context.Context, after time.Time) ([]*RequestLogTarget, error)
+
+	// SaveTarget flags a user for logging, replacing an earlier flag
+	SaveTarget(ctx context.Context, target *RequestLogTarget) error
+
+	// DeleteTarget stops logging a user's requests, or returns ErrRequestLogTargetNotFound
+	DeleteTarget(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,34 @@
+// Package domain contains the core business logic and entities
+// This file defines re-rating: replacing locked exchange rates after a rate source corrected them
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // For expense IDs
+)
+
+// RerateChange is the new conversion of one expense
+type RerateChange struct {
+	ExpenseID     uuid.UUID `json:"expense_id"`
+	Date          time.Time `json:"date"`
+	Currency      string    `json:"currency"`
+	Amount        Money     `json:"amount"`
+	OldRate       float64   `json:"old_rate"`
+	NewRate       float64   `json:"new_rate"`
+	OldBaseAmount Money     `json:"old_base_amount"`
+	NewBaseAmount Money     `json:"new_base_amount"`
+}
+
+// RerateRepository finds and updates the locked conversions of every user's expenses
+// It isn't scoped to the caller: re-rating is an administrative operation
+type RerateRepository interface {
+	// ProviderConverted returns the expenses dated from (inclusive) to before (exclusive) whose rate
+	// came from the rate provider, optionally only those in currency
+	ProviderConverted(ctx context.Context, from, before time.Time, currency string) ([]*Expense, error)
+
+	// ApplyRerate stores the new conversions and returns the ones that were applied
+	// An expense whose rate changed since it was read is left alone
+	ApplyRerate(ctx context.Context, changes []*RerateChange) ([]*RerateChange, error)
+}
@@ -0,0 +1,183 @@
+// Package domain contains the core business logic and entities
+// This file defines category corrections of imported expenses and the rules suggested from them
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"sort"         // For ordering suggestions
+	"strings"      // For grouping keywords regardless of case
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring keywords in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// MinCorrectionsForSuggestion is how many times a user has to move the same merchant to the
+// same category before a rule is suggested; one correction may just be a one-off
+const MinCorrectionsForSuggestion = 2
+
+// minSuggestionShare is the share of a keyword's corrections that must agree on the category
+// A merchant the user files under several categories doesn't make a good rule
+const minSuggestionShare = 0.75
+
+// CorrectionWindow is how far back corrections count; older ones are purged
+const CorrectionWindow = 180 * 24 * time.Hour
+
+// CategoryCorrection records that a user moved an imported expense to another category
+// It is what the import got wrong, so enough of them say which rule is missing
+type CategoryCorrection struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is who made the correction; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	ExpenseID uuid.UUID `json:"expense_id" gorm:"type:uuid;not null;index"`
+
+	// Keyword is what a rule would match on: the merchant, or the normalized description of
+	// expenses without one. It comes from the expense, so it is stored encrypted like it
+	Keyword string `json:"keyword" gorm:"not null;serializer:encrypted"`
+
+	FromCategory string `json:"from_category"`
+	ToCategory   string `json:"to_category" gorm:"not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// NewCategoryCorrection records the re-categorization of an expense that was in category from
+// It returns nil when there is nothing to learn: the expense wasn't imported, its category
+// didn't change, or there is no text to match on
+func NewCategoryCorrection(expense *Expense, from string) *CategoryCorrection {
+	if expense.Source != SourceImport || strings.EqualFold(expense.Category, from) {
+		return nil
+	}
+	keyword := strings.TrimSpace(expense.Merchant)
+	if keyword == "" {
+		keyword = strings.TrimSpace(expense.NormalizedDescription)
+	}
+	if keyword == "" || utf8.RuneCountInString(keyword) > MaxRulePatternLength {
+		return nil
+	}
+	return &CategoryCorrection{
+		ID:           uuid.New(),
+		ExpenseID:    expense.ID,
+		Keyword:      keyword,
+		FromCategory: from,
+		ToCategory:   expense.Category,
+	}
+}
+
+// RuleSuggestion is a keyword rule the user's corrections ask for, e.g. "SHELL" -> Fuel
+type RuleSuggestion struct {
+	// Pattern and Category make the rule; Priority puts it ahead of rules it conflicts with
+	Pattern  string `json:"pattern"`
+	Category string `json:"category"`
+	Priority int    `json:"priority"`
+
+	// Corrections is how many times the user made this correction, LastCorrected the latest
+	Corrections   int       `json:"corrections"`
+	LastCorrected time.Time `json:"last_corrected"`
+
+	// correctionIDs are the corrections behind the suggestion, dropped once it is accepted or dismissed
+	correctionIDs []uuid.UUID
+}
+
+// CorrectionIDs returns the corrections behind the suggestion
+func (s *RuleSuggestion) CorrectionIDs() []uuid.UUID {
+	return s.correctionIDs
+}
+
+// SuggestRules groups corrections by keyword and suggests a rule for every keyword the user
+// consistently moved to one category. Keywords the rules already file under that category
+// are skipped; a suggestion that conflicts with existing rules gets a priority above them
+// Suggestions come most corrected first
+func SuggestRules(corrections []*CategoryCorrection, rules []*CategoryRule) []*RuleSuggestion {
+	// Step 1: Group by keyword (case-insensitive), then by target category
+	type group struct {
+		keyword    string
+		total      int
+		categories map[string]*RuleSuggestion
+	}
+	groups := map[string]*group{}
+	for _, correction := range corrections {
+		key := strings.ToLower(correction.Keyword)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{keyword: correction.Keyword, categories: map[string]*RuleSuggestion{}}
+			groups[key] = g
+		}
+		g.total++
+		category := strings.ToLower(correction.ToCategory)
+		suggestion, ok := g.categories[category]
+		if !ok {
+			suggestion = &RuleSuggestion{Pattern: g.keyword, Category: correction.ToCategory}
+			g.categories[category] = suggestion
+		}
+		suggestion.Corrections++
+		suggestion.correctionIDs = append(suggestion.correctionIDs, correction.ID)
+		if correction.CreatedAt.After(suggestion.LastCorrected) {
+			suggestion.LastCorrected = correction.CreatedAt
+		}
+	}
+
+	// Step 2: Keep the consistent corrections the rules don't already cover
+	suggestions := []*RuleSuggestion{}
+	for _, g := range groups {
+		for _, suggestion := range g.categories {
+			if suggestion.Corrections < MinCorrectionsForSuggestion ||
+				float64(suggestion.Corrections) < minSuggestionShare*float64(g.total) {
+				continue
+			}
+			covered, priority := ruleCoverage(suggestion, rules)
+			if covered {
+				continue
+			}
+			suggestion.Priority = priority
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	// Step 3: Most corrected first
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Corrections != suggestions[j].Corrections {
+			return suggestions[i].Corrections > suggestions[j].Corrections
+		}
+		return strings.ToLower(suggestions[i].Pattern) < strings.ToLower(suggestions[j].Pattern)
+	})
+	return suggestions
+}
+
+// ruleCoverage runs the rules on the keyword the way the rule categorizer would (highest
+// priority first). covered is true when the first matching rule already assigns the suggested
+// category; otherwise priority is one above the matching rules, so the suggested rule wins
+func ruleCoverage(suggestion *RuleSuggestion, rules []*CategoryRule) (covered bool, priority int) {
+	tx := &ImportedTransaction{Description: suggestion.Pattern, Merchant: suggestion.Pattern, Source: RuleSourceImport}
+	first := true
+	for _, rule := range rules {
+		if !rule.Matches(tx) {
+			continue
+		}
+		if first && strings.EqualFold(rule.Category, suggestion.Category) {
+			return true, 0
+		}
+		first = false
+		if rule.Priority+1 > priority {
+			priority = rule.Priority + 1
+		}
+	}
+	return false, priority
+}
+
+// CorrectionRepository defines how category corrections are stored
+type CorrectionRepository interface {
+	// Record saves a correction made by the caller
+	Record(ctx context.Context, correction *CategoryCorrection) error
+
+	// List returns the caller's corrections made since the given time
+	List(ctx context.Context, since time.Time) ([]*CategoryCorrection, error)
+
+	// Delete removes some of the caller's corrections
+	Delete(ctx context.Context, ids []uuid.UUID) error
+
+	// Purge removes everyone's corrections made before the given time and returns how many
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
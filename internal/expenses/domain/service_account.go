@@ -0,0 +1,103 @@
+// Package domain contains the core business logic and entities
+// This file defines service accounts: machine clients such as cron jobs and importers
+// that authenticate with a client ID and secret instead of a person's login
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For trimming names
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring name length in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Service account scopes
+const (
+	// ServiceAccountScopeRead accounts can only read (their tokens carry the viewer role)
+	ServiceAccountScopeRead = "read"
+
+	// ServiceAccountScopeReadWrite accounts can create and change data (their tokens carry the member role)
+	ServiceAccountScopeReadWrite = "read_write"
+)
+
+// MaxServiceAccountNameLength is the longest accepted service account name
+const MaxServiceAccountNameLength = 100
+
+// ServiceAccount is a machine client of the API
+// It acts as itself: its ID is the user ID of its requests, so the expenses it creates are attributed
+// to it rather than to the admin who set it up. Only a hash of the client secret is stored
+type ServiceAccount struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// Name says what the account is used for (e.g. "nightly bank import")
+	Name string `json:"name" gorm:"not null"`
+
+	// ClientID identifies the account in the client credentials grant; it isn't secret
+	ClientID string `json:"client_id" gorm:"not null;uniqueIndex"`
+
+	// SecretHash is the SHA-256 of the client secret
+	SecretHash string `json:"-" gorm:"not null"`
+
+	// Scope is ServiceAccountScopeRead or ServiceAccountScopeReadWrite
+	Scope string `json:"scope" gorm:"not null"`
+
+	// CreatedBy is the admin who created the account
+	CreatedBy string `json:"created_by"`
+
+	// LastUsedAt is when the account last got a token (nil if never), to spot unused accounts
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewServiceAccount creates a validated service account; the client ID and secret hash are set by the caller
+func NewServiceAccount(name, scope, createdBy string) (*ServiceAccount, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxServiceAccountNameLength {
+		return nil, ErrInvalidServiceAccount
+	}
+	if scope != ServiceAccountScopeRead && scope != ServiceAccountScopeReadWrite {
+		return nil, ErrInvalidServiceAccount
+	}
+	return &ServiceAccount{
+		ID:        uuid.New(),
+		Name:      name,
+		Scope:     scope,
+		CreatedBy: createdBy,
+	}, nil
+}
+
+// Role returns the user role the account's tokens carry
+// Service accounts are never admins: administration stays with people
+func (a *ServiceAccount) Role() string {
+	if a.Scope == ServiceAccountScopeReadWrite {
+		return UserRoleMember
+	}
+	return UserRoleViewer
+}
+
+// ServiceAccountRepository defines how service accounts are stored
+type ServiceAccountRepository interface {
+	// Create saves a new service account
+	Create(ctx context.Context, account *ServiceAccount) error
+
+	// List returns all service accounts, newest first
+	List(ctx context.Context) ([]*ServiceAccount, error)
+
+	// GetByID retrieves a service account, or returns ErrServiceAccountNotFound
+	GetByID(ctx context.Context, id string) (*ServiceAccount, error)
+
+	// GetByClientID retrieves the account with the given client ID, or returns ErrServiceAccountNotFound
+	GetByClientID(ctx context.Context, clientID string) (*ServiceAccount, error)
+
+	// UpdateSecret saves a new secret hash for an account
+	UpdateSecret(ctx context.Context, account *ServiceAccount) error
+
+	// Delete removes a service account, or returns ErrServiceAccountNotFound
+	Delete(ctx context.Context, id string) error
+
+	// TouchLastUsed records that an account got a token at the given time
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
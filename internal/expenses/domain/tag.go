@@ -0,0 +1,55 @@
+// Package domain contains the core business logic and entities
+// This file defines the free-form tags users attach to expenses
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For normalizing tags
+	"unicode/utf8" // For measuring text in characters
+)
+
+// MaxTagsPerExpense bounds how many tags one expense may carry
+const MaxTagsPerExpense = 20
+
+// MaxTagLength bounds the length of a single tag
+const MaxTagLength = 32
+
+// NormalizeTag reduces a tag to the form it is stored in: trimmed, lowercase and without a leading "#"
+// so "#Travel" and "travel" are the same tag
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// NormalizeTags normalizes a list of tags, dropping repeats and keeping their order
+// It returns ErrInvalidTags for empty or too long tags, or too many of them
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, ErrInvalidTags
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxTagsPerExpense {
+		return nil, ErrInvalidTags
+	}
+	return normalized, nil
+}
+
+// TagSuggestion is a tag offered while typing, with how often the user has used it
+type TagSuggestion struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// TagRepository provides tag suggestions from past expenses
+type TagRepository interface {
+	// SuggestTags returns up to limit of the caller's tags starting with prefix, most used first
+	SuggestTags(ctx context.Context, prefix string, limit int) ([]*TagSuggestion, error)
+}
@@ -0,0 +1,85 @@
+// Package domain contains the core business logic and entities
+// This file defines the anonymous usage reports self-hosted instances may opt in to send
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// TelemetryInstance identifies this installation in usage reports
+// It is a random ID created the first time a report is built, shared by every API instance on
+// the same database and unrelated to any user, host or tenant
+type TelemetryInstance struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TelemetryReport is everything an instance sends when telemetry is switched on
+// It describes how the software is used, never what it holds: no amounts, descriptions,
+// categories, names or addresses, and counts only as orders of magnitude
+type TelemetryReport struct {
+	// InstanceID tells reports of the same installation apart from others
+	InstanceID string `json:"instance_id"`
+
+	// Version and GoVersion identify the build; OS and Arch the platform it runs on
+	Version   string `json:"version"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Counts *TelemetryCounts `json:"counts"`
+
+	// Features maps the optional features to whether this instance has them switched on
+	Features map[string]bool `json:"features"`
+}
+
+// TelemetryCounts is the size of an instance, each figure rounded down to a power of ten
+// (0, 1, 10, 100, ...) so the report doesn't reveal exact numbers
+type TelemetryCounts struct {
+	Users       int64 `json:"users"`
+	Books       int64 `json:"books"`
+	Expenses    int64 `json:"expenses"`
+	Attachments int64 `json:"attachments"`
+}
+
+// Rounded returns the counts rounded down to powers of ten
+func (c *TelemetryCounts) Rounded() *TelemetryCounts {
+	return &TelemetryCounts{
+		Users:       magnitude(c.Users),
+		Books:       magnitude(c.Books),
+		Expenses:    magnitude(c.Expenses),
+		Attachments: magnitude(c.Attachments),
+	}
+}
+
+// magnitude rounds n down to a power of ten; zero and negative numbers are 0
+func magnitude(n int64) int64 {
+	if n <= 0 {
+		return 0
+	}
+	power := int64(1)
+	for power <= n/10 {
+		power *= 10
+	}
+	return power
+}
+
+// TelemetryRepository reads what usage reports contain from the database
+// Like StatsRepository it counts across all users
+type TelemetryRepository interface {
+	// InstanceID returns the ID of this installation, creating it on first use
+	InstanceID(ctx context.Context) (uuid.UUID, error)
+
+	// Counts returns the exact number of users, books, expenses and attachments
+	Counts(ctx context.Context) (*TelemetryCounts, error)
+}
+
+// TelemetrySender delivers usage reports to the project
+type TelemetrySender interface {
+	Send(ctx context.Context, report *TelemetryReport) error
+}
@@ -0,0 +1,28 @@
+// Package domain contains the core business logic and entities
+// This file defines how use cases group several repository calls into one transaction
+package domain
+
+import "context" // For request context (cancellation, timeouts)
+
+// Transactor runs a function inside a single database transaction
+// Repositories called with the context passed to fn take part in the transaction;
+// if fn returns an error, everything it wrote is rolled back
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// transactionKey marks contexts that run inside a transaction
+type transactionKey struct{}
+
+// MarkTransaction returns a copy of ctx marked as running inside a transaction
+// Transactor implementations mark the context they pass to fn
+func MarkTransaction(ctx context.Context) context.Context {
+	return context.WithValue(ctx, transactionKey{}, true)
+}
+
+// InTransaction reports whether ctx runs inside a transaction started by a Transactor
+// Caches use it to keep data that may still be rolled back out of their entries
+func InTransaction(ctx context.Context) bool {
+	marked, _ := ctx.Value(transactionKey{}).(bool)
+	return marked
+}
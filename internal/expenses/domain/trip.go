@@ -0,0 +1,174 @@
+// Package domain contains the core business logic and entities
+// This file defines trips: business trips or projects whose expenses are reported together,
+// split into legs (e.g. one per country visited)
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"sort"    // For ordering legs
+	"strings" // For input normalization
+	"time"    // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Trip is a business trip or project that groups expenses for one report (e.g. for the employer)
+type Trip struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user the trip belongs to; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	// Name identifies the trip (e.g. "Customer visits Spring 2025")
+	Name string `json:"name" gorm:"not null"`
+
+	// Purpose is the business reason shown on the report
+	Purpose string `json:"purpose,omitempty"`
+
+	// StartDate and EndDate are the first and last day of the trip
+	StartDate time.Time `json:"start_date" gorm:"not null"`
+	EndDate   time.Time `json:"end_date" gorm:"not null"`
+
+	// Legs are the stages of the trip in date order
+	Legs []*TripLeg `json:"legs" gorm:"foreignKey:TripID"`
+
+	// ApprovedAt is when the trip report was approved (nil while it is open)
+	// An approved trip is frozen: its expenses can no longer be changed, added or removed
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+
+	// ApprovedBy is the user who approved the report
+	ApprovedBy string `json:"approved_by,omitempty"`
+
+	// ArchiveKey is where the signed archive made on approval is kept in blob storage
+	ArchiveKey string `json:"-"`
+
+	// ArchiveSize is the size of the archive in bytes
+	ArchiveSize int64 `json:"archive_size,omitempty"`
+
+	// ArchiveChecksum is the hex SHA-256 of the archive's manifest and ArchiveSignature
+	// the server's signature over it, so a copy of the archive can be matched to this trip
+	ArchiveChecksum  string `json:"archive_checksum,omitempty"`
+	ArchiveSignature string `json:"archive_signature,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TripLeg is one stage of a trip, usually the days spent in one country
+type TripLeg struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TripID uuid.UUID `json:"trip_id" gorm:"type:uuid;not null;index"`
+
+	// Name describes the leg (e.g. "Madrid office")
+	Name string `json:"name" gorm:"not null"`
+
+	// Country is the ISO 3166-1 alpha-2 code of the country (e.g. "ES")
+	Country string `json:"country" gorm:"size:2;not null"`
+
+	// StartDate and EndDate are the first and last day of the leg
+	StartDate time.Time `json:"start_date" gorm:"not null"`
+	EndDate   time.Time `json:"end_date" gorm:"not null"`
+}
+
+// NewTripLeg creates a validated trip leg
+func NewTripLeg(name, country string, start, end time.Time) (*TripLeg, error) {
+	name = strings.TrimSpace(name)
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if !isCountryCode(country) || end.Before(start) {
+		return nil, ErrInvalidTrip
+	}
+	if name == "" {
+		name = country
+	}
+	return &TripLeg{
+		ID:        uuid.New(),
+		Name:      name,
+		Country:   country,
+		StartDate: start,
+		EndDate:   end,
+	}, nil
+}
+
+// NewTrip creates a validated trip from its legs
+// Legs must lie within the trip and must not overlap; they are stored in date order
+func NewTrip(name, purpose string, start, end time.Time, legs []*TripLeg) (*Trip, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || end.Before(start) {
+		return nil, ErrInvalidTrip
+	}
+
+	sort.SliceStable(legs, func(i, j int) bool { return legs[i].StartDate.Before(legs[j].StartDate) })
+	for i, leg := range legs {
+		if leg.StartDate.Before(start) || leg.EndDate.After(end) {
+			return nil, ErrInvalidTrip
+		}
+		if i > 0 && !leg.StartDate.After(legs[i-1].EndDate) {
+			return nil, ErrInvalidTrip
+		}
+	}
+
+	trip := &Trip{
+		ID:        uuid.New(),
+		Name:      name,
+		Purpose:   strings.TrimSpace(purpose),
+		StartDate: start,
+		EndDate:   end,
+		Legs:      legs,
+	}
+	for _, leg := range legs {
+		leg.TripID = trip.ID
+	}
+	return trip, nil
+}
+
+// LegFor returns the leg covering date, or nil when the date falls outside every leg
+// (e.g. a flight booked weeks before the trip)
+func (t *Trip) LegFor(date time.Time) *TripLeg {
+	for _, leg := range t.Legs {
+		if !date.Before(leg.StartDate) && date.Before(leg.EndDate.AddDate(0, 0, 1)) {
+			return leg
+		}
+	}
+	return nil
+}
+
+// Approved reports whether the trip report has been approved and is frozen
+func (t *Trip) Approved() bool {
+	return t.ApprovedAt != nil
+}
+
+// isCountryCode reports whether code looks like an ISO 3166-1 alpha-2 country code
+func isCountryCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// TripRepository defines the data access operations for trips
+type TripRepository interface {
+	// Create saves a new trip together with its legs
+	Create(ctx context.Context, trip *Trip) error
+
+	// GetByID retrieves a trip with its legs, or returns ErrTripNotFound
+	GetByID(ctx context.Context, id string) (*Trip, error)
+
+	// List returns all trips with their legs, most recent first
+	List(ctx context.Context) ([]*Trip, error)
+
+	// AssignExpenses links expenses to the trip
+	// It returns ErrExpenseNotFound (and links nothing) when one of the expenses doesn't exist,
+	// and ErrTripApproved when one of them belongs to an approved trip
+	AssignExpenses(ctx context.Context, tripID uuid.UUID, expenseIDs []uuid.UUID) error
+
+	// RemoveExpense unlinks an expense from the trip, or returns ErrExpenseNotFound
+	RemoveExpense(ctx context.Context, tripID uuid.UUID, expenseID uuid.UUID) error
+
+	// Approve saves the approval and archive fields of the trip
+	// It returns ErrTripApproved when the trip was approved in the meantime
+	Approve(ctx context.Context, trip *Trip) error
+}
@@ -0,0 +1,175 @@
+// Package domain contains the core business logic and entities
+// This file defines user accounts, which own expenses
+package domain
+
+import (
+	"context"  // For request context (cancellation, timeouts)
+	"net/mail" // For validating email addresses
+	"strings"  // For normalizing email addresses
+	"time"     // For handling dates and times
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// MinPasswordLength is the shortest password accepted at registration
+const MinPasswordLength = 8
+
+// User list page sizes for the admin user listing
+const (
+	// DefaultUserPageSize is how many users a listing returns when no limit is given
+	DefaultUserPageSize = 50
+
+	// MaxUserPageSize caps the limit of a user listing
+	MaxUserPageSize = 200
+)
+
+// User roles decide what a user may do
+const (
+	// UserRoleViewer can read their expenses but not change them
+	UserRoleViewer = "viewer"
+
+	// UserRoleMember can manage their own expenses; new users are members
+	UserRoleMember = "member"
+
+	// UserRoleAdmin can additionally use the admin endpoints (user management, global stats)
+	UserRoleAdmin = "admin"
+)
+
+// User is a person with their own login and their own expenses
+type User struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// Email is the login name, stored lowercase so it matches however it is typed
+	Email string `json:"email" gorm:"not null;uniqueIndex"`
+
+	// Name is how the user is addressed (optional)
+	Name string `json:"name,omitempty"`
+
+	// Role is the user's role (see the UserRole* constants)
+	Role string `json:"role" gorm:"size:16;not null;default:member"`
+
+	// PasswordHash is the bcrypt hash of the password; the password itself is never stored
+	PasswordHash string `json:"-" gorm:"not null"`
+
+	// DisabledAt is when an admin disabled the account (nil while it is enabled)
+	// Disabled users can't log in, refresh their tokens or use their API keys
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+
+	// ErasesAt is when the user's account and data are erased, after they asked for it
+	// (nil unless an erasure is scheduled). Until then they can log in and cancel it
+	ErasesAt *time.Time `json:"erases_at,omitempty" gorm:"index"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewUser creates a user with validation; the password hash is set by the caller
+func NewUser(email, name string) (*User, error) {
+	email = NormalizeEmail(email)
+	address, err := mail.ParseAddress(email)
+	// ParseAddress also accepts "Name <a@b>"; only a bare address is a valid login
+	if err != nil || address.Address != email {
+		return nil, ErrInvalidUser
+	}
+	return &User{
+		ID:    uuid.New(),
+		Email: email,
+		Name:  strings.TrimSpace(name),
+		Role:  UserRoleMember,
+	}, nil
+}
+
+// SetRole changes the user's role, or returns ErrInvalidRole for unknown roles
+func (u *User) SetRole(role string) error {
+	role = strings.ToLower(strings.TrimSpace(role))
+	switch role {
+	case UserRoleViewer, UserRoleMember, UserRoleAdmin:
+		u.Role = role
+		return nil
+	default:
+		return ErrInvalidRole
+	}
+}
+
+// Disabled reports whether an admin disabled the account
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
+}
+
+// ErasurePending reports whether the user asked for their account to be erased
+func (u *User) ErasurePending() bool {
+	return u.ErasesAt != nil
+}
+
+// NormalizeEmail returns email the way it is stored, so lookups ignore case and stray spaces
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// UserRepository defines how users are stored
+type UserRepository interface {
+	// Create saves a new user, or returns ErrEmailTaken
+	Create(ctx context.Context, user *User) error
+
+	// GetByID retrieves a user, or returns ErrUserNotFound
+	GetByID(ctx context.Context, id string) (*User, error)
+
+	// GetByEmail retrieves a user by normalized email, or returns ErrUserNotFound
+	GetByEmail(ctx context.Context, email string) (*User, error)
+
+	// List returns one page of the users matching filter, ordered by email,
+	// and how many users match in total
+	List(ctx context.Context, filter UserFilter) ([]*User, int64, error)
+
+	// UpdateRole saves the user's role, or returns ErrUserNotFound
+	UpdateRole(ctx context.Context, user *User) error
+
+	// UpdateDisabled saves whether the user is disabled, or returns ErrUserNotFound
+	UpdateDisabled(ctx context.Context, user *User) error
+
+	// UpdateErasure saves when the user's account is erased, or returns ErrUserNotFound
+	UpdateErasure(ctx context.Context, user *User) error
+
+	// UpdatePassword saves the user's password hash, or returns ErrUserNotFound
+	UpdatePassword(ctx context.Context, user *User) error
+
+	// Delete removes a user, or returns ErrUserNotFound
+	Delete(ctx context.Context, id string) error
+}
+
+// UserFilter narrows down and pages a user listing
+type UserFilter struct {
+	// Email matches users whose email contains it, ignoring case (empty matches everyone)
+	Email string
+
+	// Limit and Offset select the page; a Limit of 0 means DefaultUserPageSize
+	Limit  int
+	Offset int
+}
+
+// GlobalStats are figures across all users, for operators
+type GlobalStats struct {
+	// Users is the number of user accounts; UsersByRole splits it by role
+	Users       int64            `json:"users"`
+	UsersByRole map[string]int64 `json:"users_by_role"`
+
+	// Expenses is the number of expenses of every user, including the anonymous local user
+	Expenses int64 `json:"expenses"`
+
+	// ExpenseTotal is the sum of their reporting (base currency) amounts
+	ExpenseTotal Money `json:"expense_total"`
+
+	// Attachments is the number of stored attachments and AttachmentBytes their total size
+	Attachments     int64 `json:"attachments"`
+	AttachmentBytes int64 `json:"attachment_bytes"`
+
+	// StoredAttachmentBytes is what the attachments actually take up, identical files counted once
+	StoredAttachmentBytes int64 `json:"stored_attachment_bytes"`
+}
+
+// StatsRepository computes figures across all users
+// Unlike the other repositories it ignores who the caller is, so only admins may reach it
+type StatsRepository interface {
+	// GlobalStats counts users, expenses and attachments
+	GlobalStats(ctx context.Context) (*GlobalStats, error)
+}
@@ -0,0 +1,112 @@
+// Package domain contains the core business logic and entities
+// This file defines VAT (value-added tax) rates and how an expense's gross amount is split into net and tax
+package domain
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For date range arguments
+)
+
+// MaxVATRate is the highest VAT rate accepted, in percent
+const MaxVATRate = 100
+
+// ValidateVATRate checks that a VAT rate is a percentage between 0 and MaxVATRate
+// 0 is valid: it marks zero-rated purchases, which is different from having no rate at all
+func ValidateVATRate(rate float64) error {
+	if rate < 0 || rate > MaxVATRate {
+		return ErrInvalidVAT
+	}
+	return nil
+}
+
+// SplitGross splits a gross amount (tax included) into its net amount and tax at rate percent
+// The tax is rounded to cents and the net amount is what remains, so the two always add up to gross
+func SplitGross(gross Money, rate float64) (net, tax Money) {
+	tax = gross.Mul(rate / (100 + rate))
+	return gross.Sub(tax), tax
+}
+
+// ApplyVAT sets the expense's VAT rate and splits its gross Amount into NetAmount and TaxAmount
+// rate is the VAT rate in percent (nil when unknown); taxAmount overrides the computed tax,
+// e.g. with the tax printed on a receipt that mixes several rates
+// At least one of them must be given; use ClearVAT for expenses without VAT information
+func (e *Expense) ApplyVAT(rate *float64, taxAmount *Money) error {
+	if rate == nil && taxAmount == nil {
+		return ErrInvalidVAT
+	}
+	if rate != nil {
+		if err := ValidateVATRate(*rate); err != nil {
+			return err
+		}
+	}
+
+	var net, tax Money
+	if taxAmount != nil {
+		// The tax is part of the gross amount, so it can't be negative or take all of it
+		if taxAmount.IsNegative() || (!taxAmount.IsZero() && taxAmount.Minor >= e.Amount.Minor) {
+			return ErrInvalidVAT
+		}
+		tax = *taxAmount
+		net = e.Amount.Sub(tax)
+	} else {
+		net, tax = SplitGross(e.Amount, *rate)
+	}
+
+	if rate != nil {
+		value := *rate
+		e.VATRate = &value
+	} else {
+		e.VATRate = nil
+	}
+	e.NetAmount = net
+	e.TaxAmount = tax
+	return nil
+}
+
+// ClearVAT removes the VAT information of the expense
+func (e *Expense) ClearVAT() {
+	e.VATRate = nil
+	e.NetAmount = Money{}
+	e.TaxAmount = Money{}
+}
+
+// HasVAT reports whether the expense carries a VAT split
+func (e *Expense) HasVAT() bool {
+	return e.VATRate != nil || !e.TaxAmount.IsZero()
+}
+
+// VATTotal sums the expenses of one VAT rate over a period, in the base currency
+type VATTotal struct {
+	// Rate is the VAT rate in percent; nil groups expenses whose tax was entered without a rate
+	Rate *float64 `json:"rate"`
+
+	// Gross, Net and Tax are the sums of the reporting (base currency) amounts
+	Gross Money `json:"gross"`
+	Net   Money `json:"net"`
+	Tax   Money `json:"tax"`
+
+	// Count is the number of expenses
+	Count int `json:"count"`
+}
+
+// MarkTotal sums the business, reimbursable and tax-deductible expenses of one category over
+// a period, in the base currency. An expense can carry several marks, so it can count in
+// several columns; Total is what the category spent in all
+type MarkTotal struct {
+	Category string `json:"category"`
+
+	Business      Money `json:"business"`
+	Reimbursable  Money `json:"reimbursable"`
+	TaxDeductible Money `json:"tax_deductible"`
+	Total         Money `json:"total"`
+}
+
+// TaxRepository provides the totals behind the tax report
+type TaxRepository interface {
+	// VATTotals sums the expenses with VAT information dated in [from, to) per VAT rate
+	VATTotals(ctx context.Context, from, to time.Time) ([]*VATTotal, error)
+
+	// MarkTotals sums the expenses dated in [from, to) per category and mark
+	// Only categories with at least one marked expense are returned, by name
+	MarkTotals(ctx context.Context, from, to time.Time) ([]*MarkTotal, error)
+}
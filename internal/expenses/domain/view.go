@@ -0,0 +1,262 @@
+// Package domain contains the core business logic and entities
+// This file defines saved views: named filter sets applied to the expense list with ?view=
+package domain
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"strings"      // For input normalization
+	"time"         // For handling dates and times
+	"unicode/utf8" // For measuring names in characters
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// MaxViewNameLength bounds the name of a view, which clients show as a tab or menu entry
+const MaxViewNameLength = 100
+
+// MaxViews is how many views one user may save
+const MaxViews = 100
+
+// ViewFilters are the filters of the expense list a view saves
+// They are the query parameters of GET /expenses, under the same names
+type ViewFilters struct {
+	Category             string `json:"category,omitempty"`
+	IncludeSubcategories bool   `json:"include_subcategories,omitempty"`
+
+	// Tags are the tags an expense must all carry
+	Tags []string `json:"tags,omitempty"`
+
+	// DateFrom and DateTo bound the expense date, as YYYY-MM-DD (both inclusive)
+	DateFrom string `json:"date_from,omitempty"`
+	DateTo   string `json:"date_to,omitempty"`
+
+	MinAmount Money `json:"min_amount,omitzero"`
+	MaxAmount Money `json:"max_amount,omitzero"`
+
+	Description string `json:"description,omitempty"`
+
+	CostCenter string `json:"cost_center,omitempty"`
+	Department string `json:"department,omitempty"`
+
+	Reimbursable  *bool      `json:"reimbursable,omitempty"`
+	EmployerID    *uuid.UUID `json:"employer_id,omitempty"`
+	Business      *bool      `json:"business,omitempty"`
+	TaxDeductible *bool      `json:"tax_deductible,omitempty"`
+
+	Flag Flag `json:"flag,omitempty"`
+
+	// Metadata are custom field values an expense must have, by field key (?meta.<key>=)
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// normalize cleans up the filters and checks them
+func (f *ViewFilters) normalize() error {
+	f.Category = strings.TrimSpace(f.Category)
+	f.Description = strings.TrimSpace(f.Description)
+	f.CostCenter = NormalizeDimensionCode(f.CostCenter)
+	f.Department = NormalizeDimensionCode(f.Department)
+
+	tags, err := NormalizeTags(f.Tags)
+	if err != nil {
+		return ErrInvalidView
+	}
+	f.Tags = tags
+	if len(f.Tags) == 0 {
+		f.Tags = nil
+	}
+
+	var from, to time.Time
+	for _, bound := range []struct {
+		value *string
+		day   *time.Time
+	}{{&f.DateFrom, &from}, {&f.DateTo, &to}} {
+		if *bound.value == "" {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", strings.TrimSpace(*bound.value))
+		if err != nil {
+			return ErrInvalidView
+		}
+		*bound.value, *bound.day = day.Format("2006-01-02"), day
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return ErrInvalidView
+	}
+
+	if f.MinAmount.IsNegative() || f.MaxAmount.IsNegative() || (f.MaxAmount.Minor > 0 && f.MaxAmount.Minor < f.MinAmount.Minor) {
+		return ErrInvalidView
+	}
+	if f.Flag != "" {
+		flag, err := ParseFlag(string(f.Flag))
+		if err != nil {
+			return ErrInvalidView
+		}
+		f.Flag = flag
+	}
+
+	metadata := make(map[string]string, len(f.Metadata))
+	for key, value := range f.Metadata {
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		if key == "" || value == "" {
+			return ErrInvalidView
+		}
+		metadata[key] = value
+	}
+	f.Metadata = nil
+	if len(metadata) > 0 {
+		f.Metadata = metadata
+	}
+	return nil
+}
+
+// Apply adds the view's filters to the filters of an expense list request, in the form the
+// repository takes them. Filters the request already has win, so ?view=work&date_from=2024-06-01
+// narrows a saved view down without saving a new one
+func (f *ViewFilters) Apply(filters map[string]interface{}) {
+	set := func(key string, value interface{}) {
+		if _, ok := filters[key]; !ok {
+			filters[key] = value
+		}
+	}
+	if _, ok := filters["category"]; !ok && f.Category != "" {
+		// include_subcategories belongs to the view's category, not to one the request sent
+		filters["category"] = f.Category
+		if f.IncludeSubcategories {
+			set("include_subcategories", true)
+		}
+	}
+	if len(f.Tags) > 0 {
+		set("tags", f.Tags)
+	}
+	if f.DateFrom != "" {
+		set("date_from", f.DateFrom)
+	}
+	if f.DateTo != "" {
+		// The list filter compares timestamps, so the last day is included up to its end
+		set("date_to", f.DateTo+"T23:59:59.999999Z")
+	}
+	if f.MinAmount.Minor > 0 {
+		set("min_amount", f.MinAmount)
+	}
+	if f.MaxAmount.Minor > 0 {
+		set("max_amount", f.MaxAmount)
+	}
+	if f.Description != "" {
+		set("description", f.Description)
+	}
+	if f.CostCenter != "" {
+		set("cost_center", f.CostCenter)
+	}
+	if f.Department != "" {
+		set("department", f.Department)
+	}
+	if f.Reimbursable != nil {
+		set("reimbursable", *f.Reimbursable)
+	}
+	if f.EmployerID != nil {
+		set("employer_id", *f.EmployerID)
+	}
+	if f.Business != nil {
+		set("business", *f.Business)
+	}
+	if f.TaxDeductible != nil {
+		set("tax_deductible", *f.TaxDeductible)
+	}
+	if f.Flag != "" {
+		set("flag", f.Flag)
+	}
+	if len(f.Metadata) > 0 {
+		// Custom fields are merged one by one, so ?meta.client= only replaces the view's client
+		meta, _ := filters["meta"].(map[string]string)
+		if meta == nil {
+			meta = make(map[string]string, len(f.Metadata))
+			filters["meta"] = meta
+		}
+		for key, value := range f.Metadata {
+			if _, ok := meta[key]; !ok {
+				meta[key] = value
+			}
+		}
+	}
+}
+
+// View is a named filter set a user saved, e.g. "Work travel 2024"
+// Views belong to their user and apply in whichever book the user is working in
+type View struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	// UserID is the user who saved the view; nil for the anonymous local user
+	UserID *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+
+	Name    string      `json:"name" gorm:"not null"`
+	Filters ViewFilters `json:"filters" gorm:"type:jsonb;serializer:json"`
+
+	// Query is the ?q= query the filters were parsed from, kept so it can be edited as written
+	// ("" for views saved as filters)
+	Query string `json:"query,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// NewView creates a validated view from either a query or filters
+func NewView(name, query string, filters ViewFilters) (*View, error) {
+	view := &View{ID: uuid.New()}
+	if err := view.Change(name, query, filters); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// Change replaces the name and filters of the view, with validation
+// The filters are given either as a query (see ParseExpenseQuery) or as filters, not both
+// A view without any filter would just be the whole list, so it is refused
+func (v *View) Change(name, query string, filters ViewFilters) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxViewNameLength {
+		return ErrInvalidView
+	}
+	if query = strings.TrimSpace(query); query != "" {
+		if filters.applied() > 0 {
+			return ErrInvalidView
+		}
+		parsed, err := ParseExpenseQuery(query)
+		if err != nil {
+			return err
+		}
+		filters = *parsed
+	} else if err := filters.normalize(); err != nil {
+		return err
+	}
+	if filters.applied() == 0 {
+		return ErrInvalidView
+	}
+	v.Name, v.Query, v.Filters = name, query, filters
+	return nil
+}
+
+// applied counts the list filters the view sets
+func (f *ViewFilters) applied() int {
+	applied := map[string]interface{}{}
+	f.Apply(applied)
+	return len(applied)
+}
+
+// ViewRepository defines how saved views are stored
+// Every method works on the caller's own views
+type ViewRepository interface {
+	// Create saves a new view owned by the caller
+	Create(ctx context.Context, view *View) error
+
+	// GetByID retrieves one of the caller's views, or returns ErrViewNotFound
+	GetByID(ctx context.Context, id string) (*View, error)
+
+	// List returns the caller's views by name
+	List(ctx context.Context) ([]*View, error)
+
+	// Update saves the name, query and filters of one of the caller's views, or returns ErrViewNotFound
+	Update(ctx context.Context, view *View) error
+
+	// Delete removes one of the caller's views, or returns ErrViewNotFound
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,239 @@
+// Package cached contains decorators that serve repeated identical queries from memory
+// Results are keyed by the caller and a normalized hash of the query arguments, and every
+// successful write through the decorator drops all of them; a TTL bounds how stale a result can
+// get when rows change some other way (another API instance, a repository that updates expenses
+// directly, a manual fix in the database)
+package cached
+
+import (
+	"context"       // For request context (cancellation, timeouts)
+	"crypto/sha256" // For hashing query arguments into short keys
+	"encoding/hex"  // For printable keys
+	"encoding/json" // For a canonical encoding of the filters
+	"fmt"           // For recording argument types
+	"sort"          // For ordering filter keys
+	"time"          // For the TTL and time-range arguments
+
+	"myexpenses/internal/auth"            // The caller a result belongs to
+	"myexpenses/internal/cache"           // Where results are kept
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// Repository decorates a domain.Repository, caching GetAll and Count results
+// Results read inside a transaction are never cached: the transaction may still roll back
+type Repository struct {
+	next    domain.Repository
+	results *cache.Memory
+	ttl     time.Duration
+}
+
+// NewRepository wraps next so identical list queries are answered from results for up to ttl
+func NewRepository(next domain.Repository, results *cache.Memory, ttl time.Duration) *Repository {
+	return &Repository{next: next, results: results, ttl: ttl}
+}
+
+// Create implements domain.Repository
+func (r *Repository) Create(ctx context.Context, expense *domain.Expense) error {
+	return r.invalidate(r.next.Create(ctx, expense))
+}
+
+// GetByID implements domain.Repository
+// Single rows are cheap to load and often read right before an update, so they aren't cached
+func (r *Repository) GetByID(ctx context.Context, id string) (*domain.Expense, error) {
+	return r.next.GetByID(ctx, id)
+}
+
+// GetAll implements domain.Repository
+// Callers get their own copies of the expenses, so changing one can't alter the cached result
+func (r *Repository) GetAll(ctx context.Context, filters map[string]interface{}) ([]*domain.Expense, error) {
+	key, ok := queryKey(ctx, "GetAll", filters)
+	if !ok {
+		return r.next.GetAll(ctx, filters)
+	}
+	if cached, hit := r.results.Get(key); hit {
+		return copyRows(cached.([]*domain.Expense)), nil
+	}
+
+	expenses, err := r.next.GetAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	r.results.Set(key, copyRows(expenses), r.ttl)
+	return expenses, nil
+}
+
+// Update implements domain.Repository
+func (r *Repository) Update(ctx context.Context, expense *domain.Expense) error {
+	return r.invalidate(r.next.Update(ctx, expense))
+}
+
+// Delete implements domain.Repository
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	return r.invalidate(r.next.Delete(ctx, id))
+}
+
+// Exists implements domain.Repository
+func (r *Repository) Exists(ctx context.Context, id string) (bool, error) {
+	return r.next.Exists(ctx, id)
+}
+
+// Count implements domain.Repository
+func (r *Repository) Count(ctx context.Context, filters map[string]interface{}) (int64, error) {
+	key, ok := queryKey(ctx, "Count", filters)
+	if !ok {
+		return r.next.Count(ctx, filters)
+	}
+	if cached, hit := r.results.Get(key); hit {
+		return cached.(int64), nil
+	}
+
+	count, err := r.next.Count(ctx, filters)
+	if err != nil {
+		return 0, err
+	}
+	r.results.Set(key, count, r.ttl)
+	return count, nil
+}
+
+// Stream implements domain.Repository
+// Streams exist for results too large to hold in memory, so they always go to the database
+func (r *Repository) Stream(ctx context.Context, filters map[string]interface{}, fn func(*domain.Expense) error) error {
+	return r.next.Stream(ctx, filters, fn)
+}
+
+// ExplainExpenses passes query plan requests through to the wrapped repository
+// Decorators must forward optional capabilities, or wrapping would silently switch them off
+func (r *Repository) ExplainExpenses(ctx context.Context, filters map[string]interface{}) (*domain.QueryPlan, error) {
+	explainer, ok := r.next.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+	return explainer.ExplainExpenses(ctx, filters)
+}
+
+// ExplainSpending passes query plan requests through to the wrapped repository
+func (r *Repository) ExplainSpending(ctx context.Context, from, to time.Time) ([]*domain.QueryPlan, error) {
+	explainer, ok := r.next.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+	return explainer.ExplainSpending(ctx, from, to)
+}
+
+// invalidate drops every cached result after a successful write
+// Expenses aren't owned by individual users yet, so one write can change every user's results
+func (r *Repository) invalidate(err error) error {
+	if err == nil {
+		r.results.DeletePrefix("")
+	}
+	return err
+}
+
+// SpendingRepository decorates a domain.SpendingRepository, caching the per-category and
+// per-merchant totals behind reports, forecasts and the dashboard
+// It shares its cache with the expense Repository decorator, whose writes invalidate it
+type SpendingRepository struct {
+	next    domain.SpendingRepository
+	results *cache.Memory
+	ttl     time.Duration
+}
+
+// NewSpendingRepository wraps next so identical totals are answered from results for up to ttl
+func NewSpendingRepository(next domain.SpendingRepository, results *cache.Memory, ttl time.Duration) *SpendingRepository {
+	return &SpendingRepository{next: next, results: results, ttl: ttl}
+}
+
+// SpendingByCategory implements domain.SpendingRepository
+func (r *SpendingRepository) SpendingByCategory(ctx context.Context, from, to time.Time) ([]*domain.CategorySpending, error) {
+	key, ok := queryKey(ctx, "SpendingByCategory", map[string]interface{}{"from": from, "to": to})
+	if !ok {
+		return r.next.SpendingByCategory(ctx, from, to)
+	}
+	if cached, hit := r.results.Get(key); hit {
+		return copyRows(cached.([]*domain.CategorySpending)), nil
+	}
+
+	rows, err := r.next.SpendingByCategory(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	r.results.Set(key, copyRows(rows), r.ttl)
+	return rows, nil
+}
+
+// SpendingByMerchant implements domain.SpendingRepository
+func (r *SpendingRepository) SpendingByMerchant(ctx context.Context, from, to time.Time) ([]*domain.MerchantSpending, error) {
+	key, ok := queryKey(ctx, "SpendingByMerchant", map[string]interface{}{"from": from, "to": to})
+	if !ok {
+		return r.next.SpendingByMerchant(ctx, from, to)
+	}
+	if cached, hit := r.results.Get(key); hit {
+		return copyRows(cached.([]*domain.MerchantSpending)), nil
+	}
+
+	rows, err := r.next.SpendingByMerchant(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	r.results.Set(key, copyRows(rows), r.ttl)
+	return rows, nil
+}
+
+// ExplainExpenses passes query plan requests through to the wrapped repository
+func (r *SpendingRepository) ExplainExpenses(ctx context.Context, filters map[string]interface{}) (*domain.QueryPlan, error) {
+	explainer, ok := r.next.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+	return explainer.ExplainExpenses(ctx, filters)
+}
+
+// ExplainSpending passes query plan requests through to the wrapped repository
+func (r *SpendingRepository) ExplainSpending(ctx context.Context, from, to time.Time) ([]*domain.QueryPlan, error) {
+	explainer, ok := r.next.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+	return explainer.ExplainSpending(ctx, from, to)
+}
+
+// queryKey returns the cache key of a query by the caller in ctx, or ok=false if it mustn't be cached
+// Filters are encoded with sorted keys and their Go types, so equal filters always give the same key
+// while 5 and "5" (which the repository treats differently) don't
+func queryKey(ctx context.Context, method string, args map[string]interface{}) (key string, ok bool) {
+	if domain.InTransaction(ctx) {
+		return "", false
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	normalized := make([][3]interface{}, len(names))
+	for i, name := range names {
+		normalized[i] = [3]interface{}{name, fmt.Sprintf("%T", args[name]), args[name]}
+	}
+
+	encoded, err := json.Marshal(struct {
+		User   string           `json:"user"`
+		Tenant string           `json:"tenant"`
+		Args   [][3]interface{} `json:"args"`
+	}{auth.UserID(ctx), auth.TenantID(ctx), normalized})
+	if err != nil {
+		// Arguments that can't be encoded can't be compared either; such queries skip the cache
+		return "", false
+	}
+	sum := sha256.Sum256(encoded)
+	return method + ":" + hex.EncodeToString(sum[:]), true
+}
+
+// copyRows returns a slice of shallow copies of rows
+func copyRows[T any](rows []*T) []*T {
+	copied := make([]*T, len(rows))
+	for i, row := range rows {
+		clone := *row
+		copied[i] = &clone
+	}
+	return copied
+}
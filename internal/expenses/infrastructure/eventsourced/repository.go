@@ -0,0 +1,260 @@
+// Package eventsourced contains a decorator that keeps expenses as an append-only event stream
+// Every write through it appends an event in the same transaction as the write, so the
+// expenses table becomes a projection of the streams: it answers lists, filters and totals,
+// while the streams answer what an expense looked like at any moment and who changed what.
+// Writes that bypass the domain.Repository (bulk updates, reimbursement claims) don't append
+// events of their own; they show up in the next event of the expense they changed
+package eventsourced
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For time-travel queries
+
+	"myexpenses/internal/auth"            // Who made a change
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+)
+
+// Repository decorates a domain.Repository, recording every change as an event
+// It also implements domain.ExpenseHistory
+type Repository struct {
+	next       domain.Repository
+	events     domain.EventStore
+	transactor domain.Transactor
+}
+
+// NewRepository wraps next so every change is appended to events
+// transactor must be the one next's writes join, so a change and its event commit together
+func NewRepository(next domain.Repository, events domain.EventStore, transactor domain.Transactor) *Repository {
+	return &Repository{next: next, events: events, transactor: transactor}
+}
+
+// actor names the caller in events: their user ID, or "admin" for the admin token
+func actor(ctx context.Context) string {
+	if userID := auth.UserID(ctx); userID != "" {
+		return userID
+	}
+	return "admin"
+}
+
+// Create implements domain.Repository, starting the expense's stream
+func (r *Repository) Create(ctx context.Context, expense *domain.Expense) error {
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.next.Create(ctx, expense); err != nil {
+			return err
+		}
+		event, err := domain.NewExpenseEvent(domain.ExpenseEventCreated, nil, expense)
+		if err != nil {
+			return err
+		}
+		event.Sequence, event.Actor = 1, actor(ctx)
+		return r.events.Append(ctx, event)
+	})
+}
+
+// GetByID implements domain.Repository
+func (r *Repository) GetByID(ctx context.Context, id string) (*domain.Expense, error) {
+	return r.next.GetByID(ctx, id)
+}
+
+// GetAll implements domain.Repository
+func (r *Repository) GetAll(ctx context.Context, filters map[string]interface{}) ([]*domain.Expense, error) {
+	return r.next.GetAll(ctx, filters)
+}
+
+// Update implements domain.Repository, appending the fields that changed
+func (r *Repository) Update(ctx context.Context, expense *domain.Expense) error {
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Step 1: The expense as its stream has it
+		current, sequence, err := r.current(ctx, expense.ID)
+		if err != nil {
+			return err
+		}
+
+		// Step 2: Update the projection
+		if err := r.next.Update(ctx, expense); err != nil {
+			return err
+		}
+
+		// Step 3: Record what changed, if anything did
+		event, err := domain.NewExpenseEvent(domain.ExpenseEventUpdated, current, expense)
+		if err != nil || event == nil {
+			return err
+		}
+		return r.append(ctx, event, sequence+1, expense)
+	})
+}
+
+// Delete implements domain.Repository, ending the expense's stream
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	expenseID, err := uuid.Parse(id)
+	if err != nil {
+		return r.next.Delete(ctx, id)
+	}
+
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, sequence, err := r.current(ctx, expenseID)
+		if err != nil {
+			return err
+		}
+		if err := r.next.Delete(ctx, id); err != nil {
+			return err
+		}
+		event, err := domain.NewExpenseEvent(domain.ExpenseEventDeleted, current, nil)
+		if err != nil {
+			return err
+		}
+		return r.append(ctx, event, sequence+1, nil)
+	})
+}
+
+// append adds event to its stream as the given sequence, and snapshots state (the expense
+// after the event) every domain.SnapshotInterval events
+func (r *Repository) append(ctx context.Context, event *domain.ExpenseEvent, sequence int64, state *domain.Expense) error {
+	event.Sequence, event.Actor = sequence, actor(ctx)
+	if err := r.events.Append(ctx, event); err != nil {
+		return err
+	}
+	if state == nil || sequence%domain.SnapshotInterval != 0 {
+		return nil
+	}
+	snapshot, err := domain.NewExpenseSnapshot(state, event)
+	if err != nil {
+		return err
+	}
+	return r.events.SaveSnapshot(ctx, snapshot)
+}
+
+// current rebuilds the caller's expense from its stream and returns it with the sequence of
+// its latest event. Expenses that have no stream yet (created before the event store was
+// turned on) are adopted: their stream starts with their state as of their last update
+func (r *Repository) current(ctx context.Context, id uuid.UUID) (*domain.Expense, int64, error) {
+	// Step 1: Replay the stream
+	snapshot, events, err := r.events.Load(ctx, id, time.Time{})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(events) > 0 {
+		expense, err := domain.ReplayExpense(snapshot, events)
+		return expense, events[len(events)-1].Sequence, err
+	}
+	if snapshot != nil {
+		expense, err := domain.ReplayExpense(snapshot, nil)
+		return expense, snapshot.Sequence, err
+	}
+
+	// Step 2: No stream yet; adopt the expense as it is
+	expense, err := r.next.GetByID(ctx, id.String())
+	if err != nil {
+		return nil, 0, err
+	}
+	event, err := domain.NewExpenseEvent(domain.ExpenseEventAdopted, nil, expense)
+	if err != nil {
+		return nil, 0, err
+	}
+	event.Sequence, event.Actor, event.OccurredAt = 1, actor(ctx), expense.UpdatedAt
+	if err := r.events.Append(ctx, event); err != nil {
+		return nil, 0, err
+	}
+	return expense, 1, nil
+}
+
+// Exists implements domain.Repository
+func (r *Repository) Exists(ctx context.Context, id string) (bool, error) {
+	return r.next.Exists(ctx, id)
+}
+
+// Count implements domain.Repository
+func (r *Repository) Count(ctx context.Context, filters map[string]interface{}) (int64, error) {
+	return r.next.Count(ctx, filters)
+}
+
+// Stream implements domain.Repository
+func (r *Repository) Stream(ctx context.Context, filters map[string]interface{}, fn func(*domain.Expense) error) error {
+	return r.next.Stream(ctx, filters, fn)
+}
+
+// AsOf implements domain.ExpenseHistory by replaying the stream up to the given time
+func (r *Repository) AsOf(ctx context.Context, id string, at time.Time) (*domain.Expense, error) {
+	expenseID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrExpenseNotFound
+	}
+
+	// Step 1: Replay what was recorded up to then
+	snapshot, events, err := r.events.Load(ctx, expenseID, at)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot != nil || len(events) > 0 {
+		return domain.ReplayExpense(snapshot, events)
+	}
+
+	// Step 2: Nothing was; either the expense didn't exist yet, or it wasn't adopted until later
+	recorded, err := r.events.Events(ctx, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if len(recorded) > 0 {
+		if recorded[0].Type != domain.ExpenseEventAdopted {
+			return nil, domain.ErrExpenseNotFound
+		}
+		adopted, err := domain.ReplayExpense(nil, recorded[:1])
+		if err != nil {
+			return nil, err
+		}
+		if at.Before(adopted.CreatedAt) {
+			return nil, domain.ErrExpenseNotFound
+		}
+		return nil, domain.ErrHistoryNotRecorded
+	}
+
+	// Step 3: No stream at all; the expense hasn't changed since it was created, if it exists
+	expense, err := r.next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case at.Before(expense.CreatedAt):
+		return nil, domain.ErrExpenseNotFound
+	case at.Before(expense.UpdatedAt):
+		return nil, domain.ErrHistoryNotRecorded
+	}
+	return expense, nil
+}
+
+// Events implements domain.ExpenseHistory
+// Expenses without a stream have no recorded changes, as long as they exist
+func (r *Repository) Events(ctx context.Context, id string) ([]*domain.ExpenseEvent, error) {
+	expenseID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrExpenseNotFound
+	}
+	events, err := r.events.Events(ctx, expenseID)
+	if err != nil || len(events) > 0 {
+		return events, err
+	}
+	if _, err := r.next.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return []*domain.ExpenseEvent{}, nil
+}
+
+// ExplainExpenses passes query plan requests through to the wrapped repository
+func (r *Repository) ExplainExpenses(ctx context.Context, filters map[string]interface{}) (*domain.QueryPlan, error) {
+	explainer, ok := r.next.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+	return explainer.ExplainExpenses(ctx, filters)
+}
+
+// ExplainSpending passes query plan requests through to the wrapped repository
+func (r *Repository) ExplainSpending(ctx context.Context, from, to time.Time) ([]*domain.QueryPlan, error) {
+	explainer, ok := r.next.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+	return explainer.ExplainSpending(ctx, from, to)
+}
@@ -0,0 +1,206 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for accounts, cash withdrawals and the cash wallet
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// AccountHandler handles HTTP requests for accounts and cash
+type AccountHandler struct {
+	service *application.AccountService
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(service *application.AccountService) *AccountHandler {
+	return &AccountHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CreateAccount handles POST /accounts
+func (h *AccountHandler) CreateAccount(c *gin.Context) {
+	var req application.CreateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	account, err := h.service.CreateAccount(c.Request.Context(), &req)
+	if err != nil {
+		if isAccountError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Account created successfully",
+		"data":    account,
+	})
+}
+
+// ListAccounts handles GET /accounts
+func (h *AccountHandler) ListAccounts(c *gin.Context) {
+	accounts, err := h.service.ListAccounts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  accounts,
+		"count": len(accounts),
+	})
+}
+
+// GetAccountBalance handles GET /accounts/{id}/balance
+func (h *AccountHandler) GetAccountBalance(c *gin.Context) {
+	balance, err := h.service.GetBalance(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrAccountNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get account balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": balance,
+	})
+}
+
+// UpdateAccount handles PUT /accounts/{id}
+// It renames the account or corrects its opening balance, and returns its new balance
+func (h *AccountHandler) UpdateAccount(c *gin.Context) {
+	var req application.UpdateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	balance, err := h.service.UpdateAccount(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondAccountError(c, err, "Failed to update account")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account updated successfully",
+		"data":    balance,
+	})
+}
+
+// DeleteAccount handles DELETE /accounts/{id}
+// Only accounts nothing was paid from or transferred with can be deleted
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	if err := h.service.DeleteAccount(c.Request.Context(), c.Param("id")); err != nil {
+		respondAccountError(c, err, "Failed to delete account")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account deleted successfully",
+	})
+}
+
+// GetAccountLedger handles GET /accounts/{id}/ledger
+// It returns every movement on the account, oldest first, with the running balance after each
+func (h *AccountHandler) GetAccountLedger(c *gin.Context) {
+	ledger, err := h.service.GetLedger(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAccountError(c, err, "Failed to get account ledger")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  ledger,
+		"count": len(ledger.Entries),
+	})
+}
+
+// WithdrawCash handles POST /cash/withdrawals
+// An ATM withdrawal moves money from a bank account into the cash wallet
+func (h *AccountHandler) WithdrawCash(c *gin.Context) {
+	var req application.WithdrawCashRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	transfer, err := h.service.WithdrawCash(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrAccountNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		case isAccountError(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record withdrawal"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Cash withdrawal recorded successfully",
+		"data":    transfer,
+	})
+}
+
+// GetCashSummary handles GET /cash
+// It returns the cash wallet balance and how much is left in each envelope
+func (h *AccountHandler) GetCashSummary(c *gin.Context) {
+	summary, err := h.service.GetCashSummary(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cash summary"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": summary,
+	})
+}
+
+// respondAccountError maps account errors to HTTP responses
+func respondAccountError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+	case errors.Is(err, domain.ErrAccountInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case isAccountError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// isAccountError reports whether err is a validation problem with an account or transfer
+func isAccountError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidAccount) ||
+		errors.Is(err, domain.ErrInvalidAccountType) ||
+		errors.Is(err, domain.ErrInvalidTransfer) ||
+		errors.Is(err, domain.ErrInvalidAmount) ||
+		errors.Is(err, domain.ErrInvalidDate) ||
+		errors.Is(err, domain.ErrInvalidCurrency)
+}
@@ -0,0 +1,101 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for administrative maintenance tasks
+package http
+
+import (
+	"net/http" // Go's built-in HTTP package for status codes
+	"strconv"  // For parsing boolean query parameters
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for defaults)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// AdminHandler handles HTTP requests for maintenance tasks
+type AdminHandler struct {
+	integrity    *application.IntegrityService
+	provisioning *application.ProvisioningService
+	indexStats   *application.IndexStatsService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(integrity *application.IntegrityService, provisioning *application.ProvisioningService, indexStats *application.IndexStatsService) *AdminHandler {
+	return &AdminHandler{
+		integrity:    integrity, // Store the service dependencies
+		provisioning: provisioning,
+		indexStats:   indexStats,
+	}
+}
+
+// IntegrityCheck handles POST /admin/integrity-check?auto_fix=true
+// It scans the data for inconsistencies and returns a repair report
+// Like every report it accepts ?format=json|csv|pdf|html
+func (h *AdminHandler) IntegrityCheck(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "the integrity check requires the admin role"})
+		return
+	}
+
+	renderer, ok := reportRenderer(c)
+	if !ok {
+		return
+	}
+
+	autoFix, err := strconv.ParseBool(c.DefaultQuery("auto_fix", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "auto_fix must be true or false",
+		})
+		return
+	}
+
+	result, err := h.integrity.Run(c.Request.Context(), autoFix)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run integrity check"})
+		return
+	}
+
+	renderReport(c, renderer, "integrity-report", application.IntegrityDocument(result))
+}
+
+// Provision handles POST /admin/provision?locale=de
+// It seeds the localized default categories, sample rules and starter budget
+// Existing data is kept, so it is safe to call again (e.g. to add a second language's categories)
+func (h *AdminHandler) Provision(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "provisioning requires the admin role"})
+		return
+	}
+
+	result, err := h.provisioning.Provision(c.Request.Context(), c.DefaultQuery("locale", domain.DefaultLocale))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to provision defaults"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Defaults provisioned successfully",
+		"data":    result,
+	})
+}
+
+// IndexStats handles GET /admin/index-stats
+// It lists every index with its scan count and size, so unused indexes can be spotted
+func (h *AdminHandler) IndexStats(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "index statistics require the admin role"})
+		return
+	}
+
+	usage, err := h.indexStats.IndexUsage(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read index statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  usage,
+		"count": len(usage),
+	})
+}
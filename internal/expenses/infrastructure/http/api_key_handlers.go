@@ -0,0 +1,124 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for managing API keys
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/auth"                 // For the missing caller error
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// APIKeyHandler handles HTTP requests for API keys
+type APIKeyHandler struct {
+	service *application.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(service *application.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CreateAPIKey handles POST /api-keys
+// The response contains the key itself; it can't be retrieved later
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req application.APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(c.Request.Context(), &req)
+	if err != nil {
+		apiKeyError(c, err, "Failed to create API key")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created successfully; store it now, it won't be shown again",
+		"data":    key,
+	})
+}
+
+// ListAPIKeys handles GET /api-keys
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.service.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		apiKeyError(c, err, "Failed to list API keys")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  keys,
+		"count": len(keys),
+	})
+}
+
+// GetAPIKey handles GET /api-keys/:id
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	key, err := h.service.GetAPIKey(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apiKeyError(c, err, "Failed to get API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": key})
+}
+
+// UpdateAPIKey handles PUT /api-keys/:id
+func (h *APIKeyHandler) UpdateAPIKey(c *gin.Context) {
+	var req application.APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	key, err := h.service.UpdateAPIKey(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		apiKeyError(c, err, "Failed to update API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key updated successfully",
+		"data":    key,
+	})
+}
+
+// DeleteAPIKey handles DELETE /api-keys/:id
+func (h *APIKeyHandler) DeleteAPIKey(c *gin.Context) {
+	if err := h.service.DeleteAPIKey(c.Request.Context(), c.Param("id")); err != nil {
+		apiKeyError(c, err, "Failed to delete API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key deleted successfully"})
+}
+
+// apiKeyError writes the response for a failed API key operation
+func apiKeyError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, auth.ErrNoPrincipal):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not logged in"})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidAPIKey):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
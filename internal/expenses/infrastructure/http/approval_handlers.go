@@ -0,0 +1,221 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for the approval chain, the approval of trip reports
+// and out-of-office delegations
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/auth"                 // For recognizing anonymous callers
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ApprovalHandler handles HTTP requests for approvals
+type ApprovalHandler struct {
+	service *application.ApprovalService
+}
+
+// NewApprovalHandler creates a new approval handler
+func NewApprovalHandler(service *application.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// GetApprovalChain handles GET /approval-chain
+// Tenants that haven't set their own chain get the default: one approval by an admin
+func (h *ApprovalHandler) GetApprovalChain(c *gin.Context) {
+	chain, err := h.service.GetChain(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get approval chain"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": chain,
+	})
+}
+
+// UpdateApprovalChain handles PUT /admin/approval-chain
+func (h *ApprovalHandler) UpdateApprovalChain(c *gin.Context) {
+	var req application.ApprovalChainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	chain, err := h.service.UpdateChain(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidApprovalChain):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update approval chain"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Approval chain updated successfully",
+		"data":    chain,
+	})
+}
+
+// ResetApprovalChain handles DELETE /admin/approval-chain
+func (h *ApprovalHandler) ResetApprovalChain(c *gin.Context) {
+	if err := h.service.ResetChain(c.Request.Context()); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrApprovalChainNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset approval chain"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Approval chain reset successfully"})
+}
+
+// TripApprovals handles GET /trips/{id}/approvals
+// It lists every step of the chain with its status for the trip and the approvals given
+func (h *ApprovalHandler) TripApprovals(c *gin.Context) {
+	status, err := h.service.Status(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrTripNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trip approvals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": status,
+	})
+}
+
+// ApproveTrip handles POST /trips/{id}/approve
+// It gives the approval of the step the trip report is waiting for; after the last step
+// the trip is frozen and its signed archive is stored (see GET /trips/{id}/archive)
+func (h *ApprovalHandler) ApproveTrip(c *gin.Context) {
+	status, err := h.service.Approve(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if respondPolicyBlocked(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, domain.ErrNotApprover):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrTripNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		case errors.Is(err, domain.ErrTripApproved), errors.Is(err, domain.ErrStepAlreadyApproved):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve trip", "details": err.Error()})
+		}
+		return
+	}
+
+	message := "Approval recorded, waiting for the next step"
+	if status.Trip.Approved() {
+		message = "Trip report approved and archived successfully"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    status,
+	})
+}
+
+// PendingApprovals handles GET /approvals/pending
+// It lists the trip reports waiting for the caller, including those of approvers the caller stands in for
+func (h *ApprovalHandler) PendingApprovals(c *gin.Context) {
+	pending, err := h.service.PendingApprovals(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list pending approvals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  pending,
+		"count": len(pending),
+	})
+}
+
+// CreateDelegation handles POST /approval-delegations
+// The caller's approvals go to the delegate from starts_on to ends_on, and back to the caller afterwards
+func (h *ApprovalHandler) CreateDelegation(c *gin.Context) {
+	var req application.CreateDelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	delegation, err := h.service.CreateDelegation(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrNoPrincipal), errors.Is(err, domain.ErrUserNotFound):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Delegating approvals requires a logged-in user"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidDelegation):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrDelegationOverlap):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create delegation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Delegation created successfully",
+		"data":    delegation,
+	})
+}
+
+// ListDelegations handles GET /approval-delegations
+func (h *ApprovalHandler) ListDelegations(c *gin.Context) {
+	delegations, err := h.service.ListDelegations(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, auth.ErrNoPrincipal) || errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Listing delegations requires a logged-in user"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list delegations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  delegations,
+		"count": len(delegations),
+	})
+}
+
+// CancelDelegation handles DELETE /approval-delegations/{id}
+// The delegation ends at once; it stays listed as cancelled
+func (h *ApprovalHandler) CancelDelegation(c *gin.Context) {
+	if err := h.service.CancelDelegation(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrDelegationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delegation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel delegation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delegation cancelled successfully"})
+}
@@ -0,0 +1,177 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for receipt attachments and full-text search
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// AttachmentHandler handles HTTP requests for expense attachments
+type AttachmentHandler struct {
+	service *application.AttachmentService
+}
+
+// NewAttachmentHandler creates a new attachment handler
+func NewAttachmentHandler(service *application.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// UploadAttachment handles POST /expenses/{id}/attachments
+// The file is sent as multipart/form-data in a field called "file"
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	// Step 1: Read the uploaded file header from the multipart form
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "A file must be uploaded in the \"file\" form field",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Step 2: Open the file content
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to read uploaded file",
+		})
+		return
+	}
+	defer file.Close()
+
+	// Step 3: Store the attachment and index its text
+	attachment, err := h.service.UploadAttachment(c.Request.Context(), c.Param("id"), &application.UploadAttachmentRequest{
+		FileName:    fileHeader.Filename,
+		ContentType: fileHeader.Header.Get("Content-Type"),
+		Size:        fileHeader.Size,
+		Content:     file,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrExpenseNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+		case errors.Is(err, domain.ErrInvalidAttachment):
+			c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidAttachment.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload attachment"})
+		}
+		return
+	}
+
+	// Step 4: Return 201 Created with the attachment metadata
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Attachment uploaded successfully",
+		"data":    attachment,
+	})
+}
+
+// ListAttachments handles GET /expenses/{id}/attachments
+func (h *AttachmentHandler) ListAttachments(c *gin.Context) {
+	attachments, err := h.service.ListAttachments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list attachments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  attachments,
+		"count": len(attachments),
+	})
+}
+
+// DownloadAttachment handles GET /expenses/{id}/attachments/{attachmentId}
+// It streams the original file back with its content type
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	attachment, content, err := h.service.OpenAttachment(c.Request.Context(), c.Param("id"), c.Param("attachmentId"))
+	if err != nil {
+		if errors.Is(err, domain.ErrAttachmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get attachment"})
+		return
+	}
+	defer content.Close()
+
+	// Content-Disposition makes browsers offer the original file name when saving
+	c.Header("Content-Disposition", "attachment; filename=\""+attachment.FileName+"\"")
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, nil)
+}
+
+// ReindexAttachment handles POST /expenses/{id}/attachments/{attachmentId}/reindex
+// It re-runs OCR on the stored file and refreshes the search index
+func (h *AttachmentHandler) ReindexAttachment(c *gin.Context) {
+	attachment, err := h.service.ReindexAttachment(c.Request.Context(), c.Param("id"), c.Param("attachmentId"))
+	if err != nil {
+		if errors.Is(err, domain.ErrAttachmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reindex attachment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachment reindexed successfully",
+		"data":    attachment,
+	})
+}
+
+// DeleteAttachment handles DELETE /expenses/{id}/attachments/{attachmentId}
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	if err := h.service.DeleteAttachment(c.Request.Context(), c.Param("id"), c.Param("attachmentId")); err != nil {
+		if errors.Is(err, domain.ErrAttachmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attachment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachment deleted successfully",
+	})
+}
+
+// SearchHandler handles full-text search requests
+type SearchHandler struct {
+	service *application.SearchService
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(service *application.SearchService) *SearchHandler {
+	return &SearchHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// SearchExpenses handles GET /expenses/search?q=...&scope=expenses|receipts|all
+// With scope=receipts users can find an expense by an item printed on its receipt
+func (h *SearchHandler) SearchExpenses(c *gin.Context) {
+	expenses, err := h.service.SearchExpenses(c.Request.Context(), c.Query("q"), c.Query("scope"))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSearchQuery) || errors.Is(err, domain.ErrInvalidSearchScope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search expenses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  expenses,
+		"count": len(expenses),
+	})
+}
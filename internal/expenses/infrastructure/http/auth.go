@@ -0,0 +1,188 @@
+// Package http contains the HTTP handlers for the expense API
+// This file puts the caller into the request context, keeps anonymous callers out of
+// protected routes and gates admin-only debug features
+package http
+
+import (
+	"crypto/subtle" // For comparing tokens in constant time
+	"errors"        // For matching domain errors through wrapped errors
+	"net/http"      // Go's built-in HTTP package for status codes
+	"strconv"       // For parsing boolean query parameters
+	"strings"       // For parsing the Authorization header
+
+	"myexpenses/internal/auth"                 // Request-scoped caller identity
+	"myexpenses/internal/expenses/application" // Import our application layer (for API keys)
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for the query plan type)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// AdminTokenHeader is the request header that carries the admin token
+const AdminTokenHeader = "X-Admin-Token"
+
+// Authenticate returns middleware that stores the caller in the request context as an auth.Principal
+// Services and repositories read it with the auth package accessors
+// Requests with "Authorization: Bearer <JWT>" act as the user the token was issued to (its "sub" claim)
+// with the role in the token (service account tokens act as the account); requests with a user API key in X-API-Key act as the key's user with
+// the user's role. Invalid or expired credentials, and tokens of login sessions that were logged out
+// or revoked since (checked against users), are rejected with 401; what the role allows is
+// left to Authorize. Requests without either are anonymous and get no role at all: RequireUser keeps
+// them out of everything but the public routes. X-API-Key values that aren't user keys are ignored
+// Requests carrying the admin token additionally get auth.RoleAdmin. With an empty token nobody is an admin
+// Apart from bad credentials it never rejects a request by itself; handlers decide what needs which role
+func Authenticate(adminToken string, tokens *auth.JWTIssuer, apiKeys *application.APIKeyService, users *application.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var principal auth.Principal
+		if header := c.GetHeader("Authorization"); header != "" {
+			token, found := strings.CutPrefix(header, "Bearer ")
+			if !found || tokens == nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": auth.ErrInvalidToken.Error()})
+				return
+			}
+			claims, err := tokens.Verify(strings.TrimSpace(token))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			// Service account tokens belong to no login session
+			if claims.SessionID != "" && users != nil {
+				active, err := users.SessionActive(c.Request.Context(), claims.Subject, claims.SessionID)
+				if err != nil {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session"})
+					return
+				}
+				if !active {
+					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": auth.ErrSessionRevoked.Error()})
+					return
+				}
+			}
+			principal.UserID = claims.Subject
+			principal.Roles = auth.RolesFor(claims.Role)
+			principal.SessionID = claims.SessionID
+			principal.ClientID = claims.ClientID
+		} else if secret := c.GetHeader(APIKeyHeader); apiKeys != nil && auth.IsAPIKey(secret) {
+			keyPrincipal, err := apiKeys.Authenticate(c.Request.Context(), secret)
+			if err != nil {
+				if errors.Is(err, domain.ErrAPIKeyNotFound) {
+					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check API key"})
+				return
+			}
+			principal = keyPrincipal
+		}
+		if adminToken != "" && !principal.HasRole(auth.RoleAdmin) {
+			given := c.GetHeader(AdminTokenHeader)
+			if subtle.ConstantTimeCompare([]byte(given), []byte(adminToken)) == 1 {
+				principal.Roles = append(principal.Roles, auth.RoleAdmin)
+			}
+		}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// safeMethod reports whether an HTTP method only reads
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// Authorize returns middleware that enforces the caller's role on every route
+// Requests that change data (anything but GET, HEAD and OPTIONS) need write permission, so
+// viewers and read-only API keys are refused with 403. Routes under exempt (e.g. "/auth" for
+// logging out and refreshing) stay open to them
+// Admin-only routes additionally check isAdmin themselves
+func Authorize(exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if safeMethod(c.Request.Method) || auth.Can(c.Request.Context(), auth.PermissionWrite) {
+			c.Next()
+			return
+		}
+		path := c.Request.URL.Path
+		for _, prefix := range exempt {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				c.Next()
+				return
+			}
+		}
+		message := "viewers can only read"
+		if principal, _ := auth.FromContext(c.Request.Context()); principal.APIKeyID != "" && principal.ReadOnly {
+			message = "this API key is read-only"
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": message})
+	}
+}
+
+// RequireUser returns middleware that rejects anonymous callers with 401, except on the routes
+// under public, which let callers in by other means (a signed URL, an integration key) or need
+// nobody at all. public holds route patterns such as "/exports/:id/download"
+// It runs after Authenticate, which has already rejected bad tokens, so it only has to check
+// that a user is logged in; callers sending the admin token count as logged in. Registering it
+// on the router instead of on every route group keeps routes added later protected too. Unknown paths are left to the 404 handler
+func RequireUser(public ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		ctx := c.Request.Context()
+		if route == "" || auth.UserID(ctx) != "" || auth.Can(ctx, auth.PermissionAdmin) {
+			c.Next()
+			return
+		}
+		for _, prefix := range public {
+			if route == prefix || strings.HasPrefix(route, prefix+"/") {
+				c.Next()
+				return
+			}
+		}
+		c.Header("WWW-Authenticate", `Bearer realm="myexpenses"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "not logged in"})
+	}
+}
+
+// isAdmin reports whether the caller may use admin-only endpoints
+// Admin users and callers sending the admin token both qualify
+func isAdmin(c *gin.Context) bool {
+	return auth.Can(c.Request.Context(), auth.PermissionAdmin)
+}
+
+// explainRequested reports whether the client asked for ?explain=true
+// Explaining runs the query with EXPLAIN ANALYZE, so it is reserved for admins:
+// it writes a 400 or 403 response and returns ok=false when the flag is invalid or not allowed
+func explainRequested(c *gin.Context) (explain bool, ok bool) {
+	value := c.Query("explain")
+	if value == "" {
+		return false, true
+	}
+	explain, err := strconv.ParseBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "explain must be true or false"})
+		return false, false
+	}
+	if explain && !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "explain requires an admin token"})
+		return false, false
+	}
+	return explain, true
+}
+
+// respondWithPlans writes query plans in the usual list shape
+func respondWithPlans(c *gin.Context, plans []*domain.QueryPlan) {
+	c.JSON(http.StatusOK, gin.H{
+		"data":  plans,
+		"count": len(plans),
+	})
+}
+
+// explainError writes the response for a failed explain
+func explainError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExplainUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidPeriod), isMetadataError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to explain query", "details": err.Error()})
+	}
+}
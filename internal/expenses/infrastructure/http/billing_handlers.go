@@ -0,0 +1,172 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for plans, subscriptions and the payment provider's webhooks
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"io"       // For reading webhook bodies
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// maxWebhookBytes is the largest webhook body read; provider events are a few kilobytes
+const maxWebhookBytes = 1 << 20
+
+// BillingHandler handles HTTP requests for billing
+type BillingHandler struct {
+	service *application.BillingService
+
+	// signatureHeader is the request header the payment provider signs webhooks in
+	signatureHeader string
+}
+
+// NewBillingHandler creates a new billing handler
+func NewBillingHandler(service *application.BillingService, signatureHeader string) *BillingHandler {
+	return &BillingHandler{
+		service:         service, // Store the service dependency
+		signatureHeader: signatureHeader,
+	}
+}
+
+// ListPlans handles GET /billing/plans
+func (h *BillingHandler) ListPlans(c *gin.Context) {
+	plans := h.service.Plans()
+	c.JSON(http.StatusOK, gin.H{
+		"data":  plans,
+		"count": len(plans),
+	})
+}
+
+// GetBilling handles GET /billing
+// It shows the tenant's plan, subscription and seats, so clients can say why something is off
+func (h *BillingHandler) GetBilling(c *gin.Context) {
+	status, err := h.service.Status(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get billing status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
+
+// Subscribe handles POST /billing/subscription
+// The answer carries payment_url, the payment provider's page where the card is entered
+func (h *BillingHandler) Subscribe(c *gin.Context) {
+	if !requireBillingAdmin(c) {
+		return
+	}
+
+	var req application.SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.service.Subscribe(c.Request.Context(), &req)
+	if err != nil {
+		respondBillingError(c, err, "Failed to subscribe")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Subscription created successfully",
+		"data":    result,
+	})
+}
+
+// ChangeSubscription handles PATCH /billing/subscription
+func (h *BillingHandler) ChangeSubscription(c *gin.Context) {
+	if !requireBillingAdmin(c) {
+		return
+	}
+
+	var req application.ChangeSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	subscription, err := h.service.ChangeSubscription(c.Request.Context(), &req)
+	if err != nil {
+		respondBillingError(c, err, "Failed to change subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Subscription changed successfully",
+		"data":    subscription,
+	})
+}
+
+// CancelSubscription handles DELETE /billing/subscription
+func (h *BillingHandler) CancelSubscription(c *gin.Context) {
+	if !requireBillingAdmin(c) {
+		return
+	}
+
+	subscription, err := h.service.CancelSubscription(c.Request.Context())
+	if err != nil {
+		respondBillingError(c, err, "Failed to cancel subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Subscription canceled successfully",
+		"data":    subscription,
+	})
+}
+
+// Webhook handles POST /billing/webhook
+// Only the payment provider calls it; the signature proves that, so it needs no login.
+// Anything but a 2xx makes the provider deliver the event again later
+func (h *BillingHandler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read webhook"})
+		return
+	}
+
+	if err := h.service.HandleWebhook(c.Request.Context(), payload, c.GetHeader(h.signatureHeader)); err != nil {
+		if errors.Is(err, domain.ErrInvalidWebhook) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to handle webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+// respondBillingError writes the response for a failed subscription change
+func respondBillingError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrUnknownPlan), errors.Is(err, domain.ErrInvalidSeats):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrSubscriptionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrSubscriptionExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
+
+// requireBillingAdmin writes a 403 response and returns false unless the caller may manage the subscription
+func requireBillingAdmin(c *gin.Context) bool {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "managing the subscription requires an admin"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for books and the middleware that picks the book of a request
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+	"strings"  // For trimming the book parameter
+
+	"myexpenses/internal/auth"                 // Request-scoped caller identity
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// BookHeader is the request header that picks the book a request works in
+const BookHeader = "X-Book-ID"
+
+// BookHandler handles HTTP requests for books
+type BookHandler struct {
+	service *application.BookService
+}
+
+// NewBookHandler creates a new book handler
+func NewBookHandler(service *application.BookService) *BookHandler {
+	return &BookHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// UseBook returns middleware that puts the book a request picks into its principal
+// The book is given as the X-Book-ID header or the ?book= parameter (the header wins); without
+// either the request works in the caller's default book. Books that don't exist or belong to
+// someone else are refused with 404, so expenses, categories and budgets can't leak between books
+func UseBook(service *application.BookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bookID := strings.TrimSpace(c.GetHeader(BookHeader))
+		if bookID == "" {
+			bookID = strings.TrimSpace(c.Query("book"))
+		}
+		if bookID == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		book, err := service.GetBook(ctx, bookID)
+		if err != nil {
+			if errors.Is(err, domain.ErrBookNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check book"})
+			return
+		}
+
+		principal, _ := auth.FromContext(ctx)
+		principal.BookID = book.ID.String()
+		c.Request = c.Request.WithContext(auth.WithPrincipal(ctx, principal))
+		c.Next()
+	}
+}
+
+// CreateBook handles POST /books
+func (h *BookHandler) CreateBook(c *gin.Context) {
+	var req application.CreateBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	book, err := h.service.CreateBook(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidBook):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrTooManyBooks):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create book"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Book created successfully",
+		"data":    book,
+	})
+}
+
+// ListBooks handles GET /books
+// The default book is listed first, without an ID
+func (h *BookHandler) ListBooks(c *gin.Context) {
+	books, err := h.service.ListBooks(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list books"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  books,
+		"count": len(books),
+	})
+}
+
+// DeleteBook handles DELETE /books/{id}
+// Books that still have expenses or pending receipts can't be deleted (409)
+func (h *BookHandler) DeleteBook(c *gin.Context) {
+	if err := h.service.DeleteBook(c.Request.Context(), c.Param("id")); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrBookNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		case errors.Is(err, domain.ErrBookNotEmpty):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete book"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Book deleted successfully",
+	})
+}
+
+// BookArchiveHandler handles HTTP requests for book archives
+type BookArchiveHandler struct {
+	service *application.BookArchiveService
+}
+
+// NewBookArchiveHandler creates a new book archive handler
+func NewBookArchiveHandler(service *application.BookArchiveService) *BookArchiveHandler {
+	return &BookArchiveHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// ExportBook handles GET /books/export
+// The archive of the current book (see UseBook) is the whole response body (downloaded as
+// book.json), so it can be sent to POST /books/import of another deployment unchanged
+func (h *BookArchiveHandler) ExportBook(c *gin.Context) {
+	archive, err := h.service.Export(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export book"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="book.json"`)
+	c.JSON(http.StatusOK, archive)
+}
+
+// ImportBook handles POST /books/import?name=
+// The body is an archive from GET /books/export; it becomes a new book, named ?name= or as
+// the archived book. The response maps the archived expense IDs to the new ones, so the
+// receipt files of the manifest can be uploaded to them
+func (h *BookArchiveHandler) ImportBook(c *gin.Context) {
+	var archive domain.BookArchive
+	if err := c.ShouldBindJSON(&archive); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.service.Import(c.Request.Context(), &archive, c.Query("name"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidBookArchive), errors.Is(err, domain.ErrInvalidBook):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrTooManyBooks):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import book"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Book imported successfully",
+		"data":    result,
+	})
+}
@@ -0,0 +1,109 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for tenant branding and the middleware that brands reports
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// brandingKey is the gin context key under which UseBranding stores the branding service
+const brandingKey = "branding"
+
+// BrandingHandler handles HTTP requests for tenant branding
+type BrandingHandler struct {
+	service *application.BrandingService
+}
+
+// NewBrandingHandler creates a new branding handler
+func NewBrandingHandler(service *application.BrandingService) *BrandingHandler {
+	return &BrandingHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// UseBranding returns middleware that makes every report rendered by renderReport
+// carry the caller's tenant branding
+func UseBranding(service *application.BrandingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(brandingKey, service)
+		c.Next()
+	}
+}
+
+// GetBranding handles GET /branding
+// Anyone may read it, so clients can show the same name and logo as the reports
+func (h *BrandingHandler) GetBranding(c *gin.Context) {
+	branding, err := h.service.GetBranding(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get branding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": branding})
+}
+
+// UpdateBranding handles PUT /admin/branding
+func (h *BrandingHandler) UpdateBranding(c *gin.Context) {
+	if !requireBrandingAdmin(c) {
+		return
+	}
+
+	var req application.BrandingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	branding, err := h.service.UpdateBranding(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidBranding) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update branding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Branding updated successfully",
+		"data":    branding,
+	})
+}
+
+// ResetBranding handles DELETE /admin/branding
+// Reports go back to the default branding
+func (h *BrandingHandler) ResetBranding(c *gin.Context) {
+	if !requireBrandingAdmin(c) {
+		return
+	}
+
+	if err := h.service.ResetBranding(c.Request.Context()); err != nil {
+		if errors.Is(err, domain.ErrBrandingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset branding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Branding reset successfully"})
+}
+
+// requireBrandingAdmin writes a 403 response and returns false unless the caller administers the organisation
+// Until organisations have their own admins that is whoever holds the admin token
+func requireBrandingAdmin(c *gin.Context) bool {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "managing branding requires an admin token"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for exporting and importing budget configurations and for budget templates
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ExportBudgets handles GET /budgets/export
+// The configuration is the whole response body (downloaded as budgets.json), so it can be
+// sent to POST /budgets/import unchanged
+func (h *BudgetHandler) ExportBudgets(c *gin.Context) {
+	config, err := h.service.ExportBudgets(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export budgets"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="budgets.json"`)
+	c.JSON(http.StatusOK, config)
+}
+
+// ImportBudgets handles POST /budgets/import?mode=merge|replace
+// The body is a configuration from GET /budgets/export; replace also removes the budgets it doesn't list
+func (h *BudgetHandler) ImportBudgets(c *gin.Context) {
+	var config domain.BudgetConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.service.ImportBudgets(c.Request.Context(), &config, c.Query("mode"))
+	if err != nil {
+		respondBudgetImportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Budgets imported successfully",
+		"data":    result,
+	})
+}
+
+// ListBudgetTemplates handles GET /budgets/templates
+func (h *BudgetHandler) ListBudgetTemplates(c *gin.Context) {
+	templates := h.service.ListBudgetTemplates()
+	c.JSON(http.StatusOK, gin.H{
+		"data":  templates,
+		"count": len(templates),
+	})
+}
+
+// BudgetsFromTemplate handles POST /budgets/from-template
+// It divides a monthly income between the template's categories and saves the budgets
+func (h *BudgetHandler) BudgetsFromTemplate(c *gin.Context) {
+	var req application.BudgetTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.service.FromTemplate(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrBudgetTemplateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		respondBudgetImportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Budgets created from template successfully",
+		"data":    result,
+	})
+}
+
+// respondBudgetImportError maps the errors of imports and templates to status codes
+func respondBudgetImportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidBudgetConfig), errors.Is(err, domain.ErrInvalidBudget):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrBudgetExists):
+		// Another request created the same budget meanwhile
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import budgets"})
+	}
+}
@@ -0,0 +1,186 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for budgets and budget simulations
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// BudgetHandler handles HTTP requests for budgets
+type BudgetHandler struct {
+	service *application.BudgetService
+	groups  *application.GroupService
+}
+
+// NewBudgetHandler creates a new budget handler
+// groups serves the status of shared budgets (GET /budgets/status?group_id=); without it there
+// are no groups
+func NewBudgetHandler(service *application.BudgetService, groups *application.GroupService) *BudgetHandler {
+	return &BudgetHandler{
+		service: service, // Store the service dependency
+		groups:  groups,
+	}
+}
+
+// CreateBudget handles POST /budgets
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	var req application.CreateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	budget, err := h.service.CreateBudget(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrBudgetExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidBudget), errors.Is(err, domain.ErrInvalidCategory), errors.Is(err, domain.ErrInvalidMonth):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create budget"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Budget created successfully",
+		"data":    budget,
+	})
+}
+
+// ListBudgets handles GET /budgets
+func (h *BudgetHandler) ListBudgets(c *gin.Context) {
+	budgets, err := h.service.ListBudgets(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list budgets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  budgets,
+		"count": len(budgets),
+	})
+}
+
+// UpdateBudget handles PUT /budgets/{id}
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	var req application.UpdateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	budget, err := h.service.UpdateBudget(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrBudgetNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+		case errors.Is(err, domain.ErrInvalidBudget):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update budget"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Budget updated successfully",
+		"data":    budget,
+	})
+}
+
+// DeleteBudget handles DELETE /budgets/{id}
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	if err := h.service.DeleteBudget(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrBudgetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete budget"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Budget deleted successfully",
+	})
+}
+
+// SimulateBudget handles POST /budgets/simulate
+// It returns the projected month-end outcome with and without the hypothetical changes
+func (h *BudgetHandler) SimulateBudget(c *gin.Context) {
+	var req application.SimulateBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.service.Simulate(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidMonth) ||
+			errors.Is(err, domain.ErrInvalidBudget) ||
+			errors.Is(err, domain.ErrInvalidPlannedExpense) ||
+			errors.Is(err, domain.ErrInvalidCategory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to simulate budget"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
+
+// BudgetStatus handles GET /budgets/status?month=YYYY-MM
+// It shows for every budget whether spending is ahead of, on or behind an even pace,
+// with a week-by-week breakdown
+// With ?group_id= it shows the group's shared budgets instead: what the group consumed
+// in total and what each member spent compared with their share
+func (h *BudgetHandler) BudgetStatus(c *gin.Context) {
+	if groupID := c.Query("group_id"); groupID != "" {
+		if h.groups == nil {
+			respondGroupError(c, domain.ErrGroupNotFound, "Failed to get group budget status")
+			return
+		}
+		status, err := h.groups.BudgetStatus(c.Request.Context(), groupID, c.Query("month"))
+		if err != nil {
+			respondGroupError(c, err, "Failed to get group budget status")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": status,
+		})
+		return
+	}
+
+	status, err := h.service.Status(c.Request.Context(), c.Query("month"))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidMonth) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get budget status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": status,
+	})
+}
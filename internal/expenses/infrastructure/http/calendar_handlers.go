@@ -0,0 +1,174 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for recurring expenses and the signed iCal feed
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"log"      // For logging failures after the response has started
+	"net/http" // Go's built-in HTTP package for status codes
+	"net/url"  // For building the feed URL
+	"strconv"  // For parsing the feed horizon
+
+	"myexpenses/internal/auth"                 // Caller identity and URL signing
+	"myexpenses/internal/calendar"             // iCalendar output
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// CalendarFeedPurpose binds feed signatures to the calendar feed
+const CalendarFeedPurpose = "calendar-feed"
+
+// CalendarHandler handles HTTP requests for recurring expenses and the calendar feed
+type CalendarHandler struct {
+	service *application.CalendarService
+	signer  *auth.URLSigner
+}
+
+// NewCalendarHandler creates a new calendar handler
+// Without a signer the feed is disabled; recurring expenses can still be managed
+func NewCalendarHandler(service *application.CalendarService, signer *auth.URLSigner) *CalendarHandler {
+	return &CalendarHandler{
+		service: service, // Store the service dependency
+		signer:  signer,
+	}
+}
+
+// CreateRecurringExpense handles POST /recurring-expenses
+func (h *CalendarHandler) CreateRecurringExpense(c *gin.Context) {
+	var req application.CreateRecurringExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	recurring, err := h.service.CreateRecurringExpense(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRecurringExpense) || errors.Is(err, domain.ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create recurring expense"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Recurring expense created successfully",
+		"data":    recurring,
+	})
+}
+
+// ListRecurringExpenses handles GET /recurring-expenses
+func (h *CalendarHandler) ListRecurringExpenses(c *gin.Context) {
+	recurring, err := h.service.ListRecurringExpenses(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list recurring expenses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  recurring,
+		"count": len(recurring),
+	})
+}
+
+// DeleteRecurringExpense handles DELETE /recurring-expenses/{id}
+func (h *CalendarHandler) DeleteRecurringExpense(c *gin.Context) {
+	if err := h.service.DeleteRecurringExpense(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrRecurringExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Recurring expense not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete recurring expense"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Recurring expense deleted successfully"})
+}
+
+// FeedURL handles GET /calendar/url
+// It returns the caller's signed feed URL, to be pasted into a calendar app's "subscribe" dialog
+func (h *CalendarHandler) FeedURL(c *gin.Context) {
+	if h.signer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "calendar feed is disabled"})
+		return
+	}
+
+	userID := auth.UserID(c.Request.Context())
+	query := url.Values{}
+	query.Set("user", userID)
+	query.Set("sig", h.signer.Sign(CalendarFeedPurpose, userID))
+
+	feed := url.URL{
+		Scheme:   requestScheme(c),
+		Host:     c.Request.Host,
+		Path:     "/calendar/feed.ics",
+		RawQuery: query.Encode(),
+	}
+	webcal := feed
+	webcal.Scheme = "webcal"
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"url":        feed.String(),
+			"webcal_url": webcal.String(),
+		},
+	})
+}
+
+// Feed handles GET /calendar/feed.ics?user=&sig=&days=
+// Calendar apps fetch it without logging in, so the signature is what grants access;
+// the request then runs on behalf of the user the URL was signed for
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	if h.signer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "calendar feed is disabled"})
+		return
+	}
+
+	userID := c.Query("user")
+	if !h.signer.Verify(CalendarFeedPurpose, userID, c.Query("sig")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "invalid feed signature"})
+		return
+	}
+
+	days := 0
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive number"})
+			return
+		}
+		days = parsed
+	}
+
+	ctx := auth.WithPrincipal(c.Request.Context(), auth.Principal{UserID: userID, Roles: []auth.Role{auth.RoleMember}})
+	cal, err := h.service.Feed(ctx, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar feed"})
+		return
+	}
+
+	c.Header("Content-Type", calendar.ContentType)
+	c.Header("Content-Disposition", `inline; filename="myexpenses.ics"`)
+	c.Status(http.StatusOK)
+
+	// The status line is already sent, so a failure half-way can only be logged
+	if err := calendar.Write(c.Writer, cal); err != nil {
+		log.Printf("failed to write calendar feed: %v", err)
+	}
+}
+
+// requestScheme returns the scheme the client used, honoring a TLS-terminating proxy
+func requestScheme(c *gin.Context) string {
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		return proto
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
@@ -0,0 +1,132 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the admin handlers for corporate cards and card feed imports
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// CardFeedHandler handles the HTTP requests about corporate cards
+type CardFeedHandler struct {
+	service *application.CardFeedService
+}
+
+// NewCardFeedHandler creates a new card feed handler
+func NewCardFeedHandler(service *application.CardFeedService) *CardFeedHandler {
+	return &CardFeedHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// ListCards handles GET /admin/corporate-cards
+func (h *CardFeedHandler) ListCards(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "listing corporate cards requires the admin role"})
+		return
+	}
+
+	cards, err := h.service.ListCards(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list corporate cards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  cards,
+		"count": len(cards),
+	})
+}
+
+// MapCard handles POST /admin/corporate-cards
+func (h *CardFeedHandler) MapCard(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "mapping corporate cards requires the admin role"})
+		return
+	}
+
+	var req application.MapCorporateCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	card, err := h.service.MapCard(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidCorporateCard):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrCorporateCardExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to map corporate card"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Corporate card mapped successfully",
+		"data":    card,
+	})
+}
+
+// UnmapCard handles DELETE /admin/corporate-cards/{id}
+func (h *CardFeedHandler) UnmapCard(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "removing corporate cards requires the admin role"})
+		return
+	}
+
+	if err := h.service.UnmapCard(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrCorporateCardNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Corporate card not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove corporate card"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Corporate card removed successfully"})
+}
+
+// ImportFeed handles POST /admin/card-feeds
+// Each transaction lands in its cardholder's staging queue (GET /staging) to be coded and approved
+func (h *CardFeedHandler) ImportFeed(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "importing card feeds requires the admin role"})
+		return
+	}
+
+	var req application.ImportCardFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.service.ImportFeed(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidMCC), errors.Is(err, domain.ErrInvalidAmount), errors.Is(err, domain.ErrInvalidSubmission):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import card feed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Card feed imported successfully",
+		"data":    result,
+	})
+}
@@ -0,0 +1,196 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for transaction imports, the MCC mapping table and keyword rules
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// CategorizationHandler handles HTTP requests for imports and categorization settings
+type CategorizationHandler struct {
+	imports        *application.ImportService
+	categorization *application.CategorizationService
+}
+
+// NewCategorizationHandler creates a new categorization handler
+func NewCategorizationHandler(imports *application.ImportService, categorization *application.CategorizationService) *CategorizationHandler {
+	return &CategorizationHandler{
+		imports:        imports,
+		categorization: categorization,
+	}
+}
+
+// ImportTransactions handles POST /imports/transactions
+// Each transaction is auto-categorized: MCC table first, then keyword rules
+func (h *CategorizationHandler) ImportTransactions(c *gin.Context) {
+	var req application.ImportTransactionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.imports.ImportTransactions(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidMCC) || errors.Is(err, domain.ErrInvalidDescription) ||
+			errors.Is(err, domain.ErrInvalidAmount) || errors.Is(err, domain.ErrInvalidDate) ||
+			isCurrencyError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import transactions"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Transactions imported successfully",
+		"data":    result,
+	})
+}
+
+// ListMCCMappings handles GET /mcc-mappings
+func (h *CategorizationHandler) ListMCCMappings(c *gin.Context) {
+	mappings, err := h.categorization.ListMCCMappings(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list MCC mappings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  mappings,
+		"count": len(mappings),
+	})
+}
+
+// SetMCCMapping handles PUT /mcc-mappings/{mcc}
+// It creates the mapping if it doesn't exist yet, or replaces its category
+func (h *CategorizationHandler) SetMCCMapping(c *gin.Context) {
+	var req application.SetMCCMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	mapping, err := h.categorization.SetMCCMapping(c.Request.Context(), c.Param("mcc"), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidMCC) || errors.Is(err, domain.ErrInvalidCategory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save MCC mapping"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "MCC mapping saved successfully",
+		"data":    mapping,
+	})
+}
+
+// DeleteMCCMapping handles DELETE /mcc-mappings/{mcc}
+func (h *CategorizationHandler) DeleteMCCMapping(c *gin.Context) {
+	if err := h.categorization.DeleteMCCMapping(c.Request.Context(), c.Param("mcc")); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidMCC):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrMCCMappingNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "MCC mapping not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete MCC mapping"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "MCC mapping deleted successfully",
+	})
+}
+
+// ListRules handles GET /rules
+func (h *CategorizationHandler) ListRules(c *gin.Context) {
+	rules, err := h.categorization.ListRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  rules,
+		"count": len(rules),
+	})
+}
+
+// CreateRule handles POST /rules
+func (h *CategorizationHandler) CreateRule(c *gin.Context) {
+	var req application.CreateRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.categorization.CreateRule(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRule) || errors.Is(err, domain.ErrInvalidCategory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Rule created successfully",
+		"data":    rule,
+	})
+}
+
+// TestRules handles POST /rules/test
+// It shows which rules match a transaction and what they would assign, without importing anything
+func (h *CategorizationHandler) TestRules(c *gin.Context) {
+	var req application.TestRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.categorization.TestRules(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to test rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// DeleteRule handles DELETE /rules/{id}
+func (h *CategorizationHandler) DeleteRule(c *gin.Context) {
+	if err := h.categorization.DeleteRule(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rule deleted successfully",
+	})
+}
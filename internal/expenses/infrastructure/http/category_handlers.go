@@ -0,0 +1,158 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for the category list
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// CategoryHandler handles HTTP requests for categories
+type CategoryHandler struct {
+	service *application.CategoryService
+}
+
+// NewCategoryHandler creates a new category handler
+func NewCategoryHandler(service *application.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// ListCategories handles GET /categories?archived=true
+// Archived categories are only listed with ?archived=true
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	categories, err := h.service.ListCategories(c.Request.Context(), c.Query("archived") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  categories,
+		"count": len(categories),
+	})
+}
+
+// CreateCategory handles POST /categories
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req application.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	category, err := h.service.CreateCategory(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCategoryExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidCategory), errors.Is(err, domain.ErrInvalidVAT),
+			errors.Is(err, domain.ErrInvalidCategoryParent):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Category created successfully",
+		"data":    category,
+	})
+}
+
+// UpdateCategory handles PUT /categories/{id}
+// It sets (or, with a null vat_rate, removes) the category's default VAT rate, and moves the
+// category under parent_id (or, with a null parent_id, to the top level)
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	var req application.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	category, err := h.service.UpdateCategory(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCategoryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		case errors.Is(err, domain.ErrInvalidVAT), errors.Is(err, domain.ErrInvalidCategoryParent):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Category updated successfully",
+		"data":    category,
+	})
+}
+
+// ArchiveCategory handles POST /categories/{id}/archive
+// The category is hidden from pickers; expenses filed under it are unaffected
+func (h *CategoryHandler) ArchiveCategory(c *gin.Context) {
+	category, err := h.service.ArchiveCategory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Category archived successfully",
+		"data":    category,
+	})
+}
+
+// RestoreCategory handles POST /categories/{id}/restore
+func (h *CategoryHandler) RestoreCategory(c *gin.Context) {
+	category, err := h.service.RestoreCategory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Category restored successfully",
+		"data":    category,
+	})
+}
+
+// DeleteCategory handles DELETE /categories/{id}
+// Expenses keep their category text; the category just stops being offered
+// Its subcategories move up to its parent
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	if err := h.service.DeleteCategory(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Category deleted successfully",
+	})
+}
@@ -0,0 +1,55 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handler that suggests the category of a new expense
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+	"strconv"  // For parsing the amount
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// CategorySuggestionHandler handles HTTP requests for category suggestions
+type CategorySuggestionHandler struct {
+	service *application.CategorySuggestionService
+}
+
+// NewCategorySuggestionHandler creates a new category suggestion handler
+func NewCategorySuggestionHandler(service *application.CategorySuggestionService) *CategorySuggestionHandler {
+	return &CategorySuggestionHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// SuggestCategory handles GET /expenses/suggest-category?description=...&amount=...
+// data lists the likely categories with their confidence, most likely first; it is empty
+// for users who haven't categorized anything yet. amount is optional but sharpens the guess
+func (h *CategorySuggestionHandler) SuggestCategory(c *gin.Context) {
+	var amount float64
+	if value := c.Query("amount"); value != "" {
+		var err error
+		if amount, err = strconv.ParseFloat(value, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be a number"})
+			return
+		}
+	}
+
+	suggestions, err := h.service.SuggestCategory(c.Request.Context(), c.Query("description"), amount)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidDescription) || errors.Is(err, domain.ErrInvalidAmount) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  suggestions,
+		"count": len(suggestions),
+	})
+}
@@ -0,0 +1,76 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for comments on expenses
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// CommentHandler handles HTTP requests for comments on expenses
+type CommentHandler struct {
+	service *application.CommentService
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(service *application.CommentService) *CommentHandler {
+	return &CommentHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// AddComment handles POST /expenses/{id}/comments
+func (h *CommentHandler) AddComment(c *gin.Context) {
+	var req application.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	comment, err := h.service.AddComment(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondCommentError(c, err, "Failed to add comment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Comment added successfully",
+		"data":    comment,
+	})
+}
+
+// ListComments handles GET /expenses/{id}/comments
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	comments, err := h.service.ListComments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondCommentError(c, err, "Failed to list comments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  comments,
+		"count": len(comments),
+	})
+}
+
+// respondCommentError maps comment errors to HTTP responses
+func respondCommentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidComment):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExpenseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,115 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for the caller's custom field schema
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// CustomFieldHandler handles HTTP requests for custom fields
+type CustomFieldHandler struct {
+	service *application.CustomFieldService
+}
+
+// NewCustomFieldHandler creates a new custom field handler
+func NewCustomFieldHandler(service *application.CustomFieldService) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CreateCustomField handles POST /custom-fields
+// Expenses can then carry a value for it in their metadata and be filtered with ?meta.<key>=
+func (h *CustomFieldHandler) CreateCustomField(c *gin.Context) {
+	var req application.CreateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	field, err := h.service.CreateCustomField(c.Request.Context(), &req)
+	if err != nil {
+		respondCustomFieldError(c, err, "Failed to create custom field")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Custom field created successfully",
+		"data":    field,
+	})
+}
+
+// ListCustomFields handles GET /custom-fields
+func (h *CustomFieldHandler) ListCustomFields(c *gin.Context) {
+	fields, err := h.service.ListCustomFields(c.Request.Context())
+	if err != nil {
+		respondCustomFieldError(c, err, "Failed to list custom fields")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  fields,
+		"count": len(fields),
+	})
+}
+
+// UpdateCustomField handles PUT /custom-fields/{key}
+func (h *CustomFieldHandler) UpdateCustomField(c *gin.Context) {
+	var req application.UpdateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	field, err := h.service.UpdateCustomField(c.Request.Context(), c.Param("key"), &req)
+	if err != nil {
+		respondCustomFieldError(c, err, "Failed to update custom field")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Custom field updated successfully",
+		"data":    field,
+	})
+}
+
+// DeleteCustomField handles DELETE /custom-fields/{key}
+// The field's values are removed from the caller's expenses too
+func (h *CustomFieldHandler) DeleteCustomField(c *gin.Context) {
+	if err := h.service.DeleteCustomField(c.Request.Context(), c.Param("key")); err != nil {
+		respondCustomFieldError(c, err, "Failed to delete custom field")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Custom field deleted successfully",
+	})
+}
+
+// respondCustomFieldError maps custom field errors to HTTP responses
+func respondCustomFieldError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCustomField):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCustomFieldNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+	case errors.Is(err, domain.ErrCustomFieldExists), errors.Is(err, domain.ErrTooManyCustomFields):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,69 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for the reports extension modules provide
+package http
+
+import (
+	"errors"   // For matching domain errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// CustomReportHandler handles HTTP requests for extension reports
+type CustomReportHandler struct {
+	service *application.CustomReportService
+}
+
+// NewCustomReportHandler creates a new custom report handler
+func NewCustomReportHandler(service *application.CustomReportService) *CustomReportHandler {
+	return &CustomReportHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// ListCustomReports handles GET /reports/custom
+func (h *CustomReportHandler) ListCustomReports(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.service.List(),
+	})
+}
+
+// CustomReport handles GET /reports/custom/{name}
+// Every query parameter except format, page and page_size is passed to the report provider;
+// the report is rendered in any ?format= like the built-in reports
+func (h *CustomReportHandler) CustomReport(c *gin.Context) {
+	renderer, ok := reportRenderer(c)
+	if !ok {
+		return
+	}
+
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		switch key {
+		case "format", "page", "page_size":
+			continue
+		}
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	name := c.Param("name")
+	doc, err := h.service.Build(c.Request.Context(), name, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCustomReportNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		case errors.Is(err, domain.ErrInvalidReportParameters), errors.Is(err, domain.ErrInvalidPeriod), errors.Is(err, domain.ErrInvalidMonth):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+		}
+		return
+	}
+
+	renderReport(c, renderer, name, doc)
+}
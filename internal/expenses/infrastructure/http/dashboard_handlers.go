@@ -0,0 +1,52 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the dashboard handler
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// CacheStatusHeader tells clients whether a response was served from the cache (HIT) or computed (MISS)
+const CacheStatusHeader = "X-Cache"
+
+// DashboardHandler handles HTTP requests for the dashboard
+type DashboardHandler struct {
+	service *application.DashboardService
+}
+
+// NewDashboardHandler creates a new dashboard handler
+func NewDashboardHandler(service *application.DashboardService) *DashboardHandler {
+	return &DashboardHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// GetDashboard handles GET /dashboard?tz=
+// tz is the caller's IANA timezone; it is remembered, so the dashboard is warmed after their midnight
+func (h *DashboardHandler) GetDashboard(c *gin.Context) {
+	dashboard, cached, err := h.service.Dashboard(c.Request.Context(), c.Query("tz"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTimezone):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load dashboard"})
+		}
+		return
+	}
+
+	if cached {
+		c.Header(CacheStatusHeader, "HIT")
+	} else {
+		c.Header(CacheStatusHeader, "MISS")
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": dashboard,
+	})
+}
@@ -0,0 +1,92 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers that generate and wipe the sample data
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// DemoDataHandler handles HTTP requests for the sample data
+type DemoDataHandler struct {
+	service *application.DemoDataService
+}
+
+// NewDemoDataHandler creates a new demo data handler
+func NewDemoDataHandler(service *application.DemoDataService) *DemoDataHandler {
+	return &DemoDataHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// GetDemoData handles GET /admin/demo-data
+// Clients use it to offer wiping the sample data while there is some
+func (h *DemoDataHandler) GetDemoData(c *gin.Context) {
+	if !requireDemoDataAdmin(c) {
+		return
+	}
+
+	status, err := h.service.Status(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count demo data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
+
+// GenerateDemoData handles POST /admin/demo-data?locale=de
+func (h *DemoDataHandler) GenerateDemoData(c *gin.Context) {
+	if !requireDemoDataAdmin(c) {
+		return
+	}
+
+	status, err := h.service.Generate(c.Request.Context(), c.DefaultQuery("locale", domain.DefaultLocale))
+	if err != nil {
+		if errors.Is(err, domain.ErrDemoDataExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate demo data"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Demo data generated successfully",
+		"data":    status,
+	})
+}
+
+// WipeDemoData handles POST /admin/demo-data/wipe
+// It removes every expense flagged as demo and nothing else; data entered by users stays
+func (h *DemoDataHandler) WipeDemoData(c *gin.Context) {
+	if !requireDemoDataAdmin(c) {
+		return
+	}
+
+	status, err := h.service.Wipe(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to wipe demo data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Demo data wiped successfully",
+		"data":    status,
+	})
+}
+
+// requireDemoDataAdmin writes a 403 response and returns false unless the caller is an admin
+// The sample data belongs to the whole tenant, not to one user
+func requireDemoDataAdmin(c *gin.Context) bool {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "managing demo data requires an admin"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for cost centers, departments and the chargeback report
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// DimensionHandler handles the HTTP requests about cost centers and departments
+type DimensionHandler struct {
+	service *application.DimensionService
+}
+
+// NewDimensionHandler creates a new dimension handler
+func NewDimensionHandler(service *application.DimensionService) *DimensionHandler {
+	return &DimensionHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// ListDimensions handles GET /dimensions?kind=cost_center|department&archived=true
+// Everyone may list them, to pick what to charge their expenses to
+func (h *DimensionHandler) ListDimensions(c *gin.Context) {
+	dimensions, err := h.service.ListDimensions(c.Request.Context(), c.Query("kind"), c.Query("archived") == "true")
+	if err != nil {
+		respondDimensionError(c, err, "Failed to list cost centers and departments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  dimensions,
+		"count": len(dimensions),
+	})
+}
+
+// CreateDimension handles POST /admin/dimensions
+func (h *DimensionHandler) CreateDimension(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "managing cost centers and departments requires the admin role"})
+		return
+	}
+
+	var req application.CreateDimensionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	dimension, err := h.service.CreateDimension(c.Request.Context(), &req)
+	if err != nil {
+		respondDimensionError(c, err, "Failed to create cost center or department")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Cost center or department created successfully",
+		"data":    dimension,
+	})
+}
+
+// ArchiveDimension handles POST /admin/dimensions/{id}/archive
+func (h *DimensionHandler) ArchiveDimension(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "managing cost centers and departments requires the admin role"})
+		return
+	}
+
+	if err := h.service.ArchiveDimension(c.Request.Context(), c.Param("id")); err != nil {
+		respondDimensionError(c, err, "Failed to archive cost center or department")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cost center or department archived successfully"})
+}
+
+// ChargebackReport handles GET /reports/chargeback?period=&by=cost_center|department
+// It sums the period's spending per cost center (the default) or department, and per category (admin only)
+// Like every report it accepts ?format=json|csv|pdf|html and ?page=&page_size=
+func (h *DimensionHandler) ChargebackReport(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "the chargeback report requires the admin role"})
+		return
+	}
+	renderer, ok := reportRenderer(c)
+	if !ok {
+		return
+	}
+
+	period := c.Query("period")
+	if period == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "period is required",
+		})
+		return
+	}
+
+	result, err := h.service.Chargeback(c.Request.Context(), period, c.DefaultQuery("by", domain.DimensionCostCenter))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPeriod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		respondDimensionError(c, err, "Failed to build chargeback report")
+		return
+	}
+
+	renderReport(c, renderer, "chargeback", result.Document())
+}
+
+// respondDimensionError maps dimension errors to status codes
+func respondDimensionError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDimension):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDimensionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cost center or department not found"})
+	case errors.Is(err, domain.ErrDimensionExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
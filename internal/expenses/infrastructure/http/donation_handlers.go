@@ -0,0 +1,110 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for donations and the annual giving report
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// DonationHandler handles HTTP requests for donations
+type DonationHandler struct {
+	service *application.DonationService
+}
+
+// NewDonationHandler creates a new donation handler
+func NewDonationHandler(service *application.DonationService) *DonationHandler {
+	return &DonationHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// MarkDonation handles PUT /expenses/{id}/donation
+// Body: {"recipient_name": "Red Cross", "recipient_tax_id": "53-0196605", "receipt_number": "R-1042"}
+func (h *DonationHandler) MarkDonation(c *gin.Context) {
+	var req application.DonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	donation, err := h.service.MarkDonation(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondDonationError(c, err, "Failed to mark donation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Expense marked as a donation",
+		"data":    donation,
+	})
+}
+
+// GetDonation handles GET /expenses/{id}/donation
+func (h *DonationHandler) GetDonation(c *gin.Context) {
+	donation, err := h.service.GetDonation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondDonationError(c, err, "Failed to get donation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": donation})
+}
+
+// UnmarkDonation handles DELETE /expenses/{id}/donation
+func (h *DonationHandler) UnmarkDonation(c *gin.Context) {
+	if err := h.service.UnmarkDonation(c.Request.Context(), c.Param("id")); err != nil {
+		respondDonationError(c, err, "Failed to unmark donation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Expense is no longer marked as a donation",
+	})
+}
+
+// GivingReport handles GET /reports/giving?year=
+// It lists the year's donations with recipient details and whether each has its receipt attached
+// Like every report it accepts ?format=json|csv|pdf|html and ?page=&page_size=
+func (h *DonationHandler) GivingReport(c *gin.Context) {
+	renderer, ok := reportRenderer(c)
+	if !ok {
+		return
+	}
+
+	year := c.Query("year")
+	if year == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "year is required"})
+		return
+	}
+
+	result, err := h.service.GivingReport(c.Request.Context(), year)
+	if err != nil {
+		respondDonationError(c, err, "Failed to build giving report")
+		return
+	}
+
+	renderReport(c, renderer, "giving-"+year, result.Document())
+}
+
+// respondDonationError maps donation errors to HTTP responses
+func respondDonationError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDonation), errors.Is(err, domain.ErrInvalidPeriod):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExpenseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+	case errors.Is(err, domain.ErrDonationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,189 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for employers and the reimbursements received from them
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// EmployerHandler handles HTTP requests for employers
+type EmployerHandler struct {
+	service *application.EmployerService
+}
+
+// NewEmployerHandler creates a new employer handler
+func NewEmployerHandler(service *application.EmployerService) *EmployerHandler {
+	return &EmployerHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CreateEmployer handles POST /employers
+func (h *EmployerHandler) CreateEmployer(c *gin.Context) {
+	var req application.CreateEmployerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	employer, err := h.service.CreateEmployer(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidEmployer) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create employer"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Employer created successfully",
+		"data":    employer,
+	})
+}
+
+// ListEmployers handles GET /employers
+func (h *EmployerHandler) ListEmployers(c *gin.Context) {
+	employers, err := h.service.ListEmployers(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list employers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  employers,
+		"count": len(employers),
+	})
+}
+
+// DeleteEmployer handles DELETE /employers/{id}
+// Employers that expenses or reimbursements still refer to can't be deleted (409)
+func (h *EmployerHandler) DeleteEmployer(c *gin.Context) {
+	if err := h.service.DeleteEmployer(c.Request.Context(), c.Param("id")); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrEmployerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employer not found"})
+		case errors.Is(err, domain.ErrEmployerInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete employer"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Employer deleted successfully",
+	})
+}
+
+// Balances handles GET /employers/balances
+// It returns what each employer still owes for reimbursable expenses, and the total outstanding
+func (h *EmployerHandler) Balances(c *gin.Context) {
+	balances, err := h.service.Balances(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute employer balances"})
+		return
+	}
+
+	var outstanding domain.Money
+	for _, balance := range balances {
+		outstanding = outstanding.Add(balance.Outstanding)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":        balances,
+		"count":       len(balances),
+		"outstanding": outstanding,
+	})
+}
+
+// Balance handles GET /employers/{id}/balance
+func (h *EmployerHandler) Balance(c *gin.Context) {
+	balance, err := h.service.Balance(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrEmployerNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employer not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute employer balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": balance})
+}
+
+// RecordReimbursement handles POST /employers/{id}/reimbursements
+// The amount is in the base currency and is taken off the employer's outstanding balance
+func (h *EmployerHandler) RecordReimbursement(c *gin.Context) {
+	var req application.RecordReimbursementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	income, err := h.service.RecordReimbursement(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrEmployerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employer not found"})
+		case errors.Is(err, domain.ErrInvalidReimbursement):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record reimbursement"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Reimbursement recorded successfully",
+		"data":    income,
+	})
+}
+
+// ListReimbursements handles GET /employers/{id}/reimbursements
+func (h *EmployerHandler) ListReimbursements(c *gin.Context) {
+	incomes, err := h.service.ListReimbursements(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrEmployerNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employer not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list reimbursements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  incomes,
+		"count": len(incomes),
+	})
+}
+
+// DeleteReimbursement handles DELETE /employers/{id}/reimbursements/{reimbursementId}
+func (h *EmployerHandler) DeleteReimbursement(c *gin.Context) {
+	if err := h.service.DeleteReimbursement(c.Request.Context(), c.Param("id"), c.Param("reimbursementId")); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrEmployerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employer not found"})
+		case errors.Is(err, domain.ErrReimbursementNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Reimbursement not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reimbursement"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reimbursement deleted successfully",
+	})
+}
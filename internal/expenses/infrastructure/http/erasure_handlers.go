@@ -0,0 +1,70 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for erasing the caller's account
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/auth"                 // For the missing caller error
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ErasureHandler handles HTTP requests for account erasure
+type ErasureHandler struct {
+	service *application.ErasureService
+}
+
+// NewErasureHandler creates a new erasure handler
+func NewErasureHandler(service *application.ErasureService) *ErasureHandler {
+	return &ErasureHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// ScheduleErasure handles DELETE /auth/me
+// The account and everything in it are erased once the grace period in "erases_at" is over;
+// every session is logged out now
+func (h *ErasureHandler) ScheduleErasure(c *gin.Context) {
+	user, err := h.service.ScheduleErasure(c.Request.Context())
+	if err != nil {
+		respondErasureError(c, err, "Failed to schedule account erasure")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Account erasure scheduled; log in and cancel it before erases_at to keep the account",
+		"data":    user,
+	})
+}
+
+// CancelErasure handles POST /auth/me/erasure/cancel
+func (h *ErasureHandler) CancelErasure(c *gin.Context) {
+	user, err := h.service.CancelErasure(c.Request.Context())
+	if err != nil {
+		respondErasureError(c, err, "Failed to cancel account erasure")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account erasure cancelled",
+		"data":    user,
+	})
+}
+
+// respondErasureError maps erasure errors to HTTP responses
+func respondErasureError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, auth.ErrNoPrincipal), errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not logged in"})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "this API key is read-only"})
+	case errors.Is(err, domain.ErrErasureNotScheduled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,202 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for expense exports and their signed download links
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"io"       // For streaming the export file
+	"log"      // For logging failures after the response has started
+	"net/http" // Go's built-in HTTP package for status codes
+	"net/url"  // For building download URLs
+	"strconv"  // For the link expiry
+	"time"     // For the link lifetime
+
+	"myexpenses/internal/auth"                 // URL signing
+	"myexpenses/internal/clock"                // Time source for link expiry
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ExportDownloadPurpose binds download signatures to export files
+const ExportDownloadPurpose = "export-download"
+
+// ExportLinkTTL is how long a download link stays valid; a new one is issued on every GET /exports/{id}
+const ExportLinkTTL = time.Hour
+
+// ExportHandler handles HTTP requests for expense exports
+type ExportHandler struct {
+	service *application.ExportService
+	signer  *auth.URLSigner
+	clock   clock.Clock
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(service *application.ExportService, signer *auth.URLSigner, clk clock.Clock) *ExportHandler {
+	return &ExportHandler{
+		service: service, // Store the service dependency
+		signer:  signer,
+		clock:   clock.Or(clk),
+	}
+}
+
+// exportResponse is an export job as returned to clients
+type exportResponse struct {
+	*domain.ExportJob
+	Progress          float64    `json:"progress"`
+	DownloadURL       string     `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
+
+// CreateExport handles POST /exports
+// Exports up to the size threshold are returned right away as CSV (200);
+// larger ones are queued as a job (202) whose progress is at the Location header
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	var req application.ExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	started := false
+	job, err := h.service.Export(c.Request.Context(), &req, func() io.Writer {
+		started = true
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="expenses.csv"`)
+		c.Status(http.StatusOK)
+		return c.Writer
+	})
+	if err != nil {
+		if started {
+			// The status line is already sent, so a failure half-way can only be logged
+			log.Printf("failed to write export: %v", err)
+			return
+		}
+		switch {
+		case errors.Is(err, domain.ErrInvalidExport), errors.Is(err, domain.ErrExportTooLarge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrExportQueueFull):
+			c.Header("Retry-After", "60")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export expenses"})
+		}
+		return
+	}
+	if job == nil {
+		return
+	}
+
+	c.Header("Location", "/exports/"+job.ID.String())
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Export is too large to return directly and was queued",
+		"data":    h.response(c, job),
+	})
+}
+
+// ListExports handles GET /exports
+func (h *ExportHandler) ListExports(c *gin.Context) {
+	jobs, err := h.service.ListJobs(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list exports"})
+		return
+	}
+
+	responses := make([]exportResponse, len(jobs))
+	for i, job := range jobs {
+		responses[i] = h.response(c, job)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":  responses,
+		"count": len(responses),
+	})
+}
+
+// GetExport handles GET /exports/{id}
+// Once the export is completed the response carries a signed download URL
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	job, err := h.service.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrExportNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve export"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.response(c, job)})
+}
+
+// DownloadExport handles GET /exports/{id}/download?expires=&sig=
+// The link is opened without logging in (e.g. by a browser), so the signature is what grants access
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	id := c.Param("id")
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil || !h.signer.Verify(ExportDownloadPurpose, id+":"+c.Query("expires"), c.Query("sig")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "invalid download signature"})
+		return
+	}
+	if h.clock.Now().Unix() > expires {
+		c.JSON(http.StatusForbidden, gin.H{"error": "download link expired"})
+		return
+	}
+
+	job, file, err := h.service.OpenDownload(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrExportNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+		case errors.Is(err, domain.ErrExportNotReady):
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open export"})
+		}
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="expenses-`+job.ID.String()+`.csv"`)
+	c.Header("Content-Length", strconv.FormatInt(job.SizeBytes, 10))
+	c.Status(http.StatusOK)
+
+	// The status line is already sent, so a failure half-way can only be logged
+	if _, err := io.Copy(c.Writer, file); err != nil {
+		log.Printf("failed to send export %s: %v", job.ID, err)
+	}
+}
+
+// response adds progress and, for finished exports, a fresh download link to job
+func (h *ExportHandler) response(c *gin.Context, job *domain.ExportJob) exportResponse {
+	resp := exportResponse{ExportJob: job, Progress: job.Progress()}
+	if job.Status != domain.ExportCompleted || job.StorageKey == "" {
+		return resp
+	}
+
+	// A link never outlives the file it points to
+	expiresAt := h.clock.Now().Add(ExportLinkTTL)
+	if job.ExpiresAt != nil && job.ExpiresAt.Before(expiresAt) {
+		expiresAt = *job.ExpiresAt
+	}
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	id := job.ID.String()
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("sig", h.signer.Sign(ExportDownloadPurpose, id+":"+expires))
+	download := url.URL{
+		Scheme:   requestScheme(c),
+		Host:     c.Request.Host,
+		Path:     "/exports/" + id + "/download",
+		RawQuery: query.Encode(),
+	}
+
+	resp.DownloadURL = download.String()
+	resp.DownloadExpiresAt = &expiresAt
+	return resp
+}
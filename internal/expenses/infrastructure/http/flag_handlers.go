@@ -0,0 +1,90 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for review flags on expenses
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"io"       // For detecting an empty request body
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// FlagHandler handles HTTP requests for review flags
+type FlagHandler struct {
+	service *application.FlagService
+}
+
+// NewFlagHandler creates a new flag handler
+func NewFlagHandler(service *application.FlagService) *FlagHandler {
+	return &FlagHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// SetFlag handles PUT /expenses/{id}/flags/{flag}
+// The body is optional: {"note": "which client was this dinner with?"}
+func (h *FlagHandler) SetFlag(c *gin.Context) {
+	var req application.SetFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	flag, err := h.service.SetFlag(c.Request.Context(), c.Param("id"), c.Param("flag"), &req)
+	if err != nil {
+		flagError(c, err, "Failed to set flag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Flag set successfully",
+		"data":    flag,
+	})
+}
+
+// RemoveFlag handles DELETE /expenses/{id}/flags/{flag}
+func (h *FlagHandler) RemoveFlag(c *gin.Context) {
+	if err := h.service.RemoveFlag(c.Request.Context(), c.Param("id"), c.Param("flag")); err != nil {
+		flagError(c, err, "Failed to remove flag")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Flag removed successfully",
+	})
+}
+
+// ListFlags handles GET /expenses/{id}/flags
+func (h *FlagHandler) ListFlags(c *gin.Context) {
+	flags, err := h.service.ListFlags(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		flagError(c, err, "Failed to list flags")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  flags,
+		"count": len(flags),
+	})
+}
+
+// flagError maps flag errors to responses
+func flagError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidFlag):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExpenseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+	case errors.Is(err, domain.ErrFlagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
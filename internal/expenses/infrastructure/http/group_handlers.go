@@ -0,0 +1,202 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for groups, their members and their shared budgets
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// GroupHandler handles HTTP requests for groups
+type GroupHandler struct {
+	service *application.GroupService
+}
+
+// NewGroupHandler creates a new group handler
+func NewGroupHandler(service *application.GroupService) *GroupHandler {
+	return &GroupHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CreateGroup handles POST /groups
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	var req application.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	group, err := h.service.CreateGroup(c.Request.Context(), &req)
+	if err != nil {
+		respondGroupError(c, err, "Failed to create group")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Group created successfully",
+		"data":    group,
+	})
+}
+
+// ListGroups handles GET /groups
+func (h *GroupHandler) ListGroups(c *gin.Context) {
+	groups, err := h.service.ListGroups(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list groups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  groups,
+		"count": len(groups),
+	})
+}
+
+// GetGroup handles GET /groups/{id}
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	group, err := h.service.GetGroup(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondGroupError(c, err, "Failed to get group")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": group,
+	})
+}
+
+// AddGroupMember handles POST /groups/{id}/members
+func (h *GroupHandler) AddGroupMember(c *gin.Context) {
+	var req application.GroupMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	member, err := h.service.AddMember(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondGroupError(c, err, "Failed to add group member")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Group member added successfully",
+		"data":    member,
+	})
+}
+
+// RemoveGroupMember handles DELETE /groups/{id}/members/{memberId}
+func (h *GroupHandler) RemoveGroupMember(c *gin.Context) {
+	if err := h.service.RemoveMember(c.Request.Context(), c.Param("id"), c.Param("memberId")); err != nil {
+		respondGroupError(c, err, "Failed to remove group member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Group member removed successfully",
+	})
+}
+
+// AttributeMemberExpenses handles POST /groups/{id}/members/{memberId}/expenses
+// It records the member as the one who paid the listed expenses
+func (h *GroupHandler) AttributeMemberExpenses(c *gin.Context) {
+	var req application.AttributeExpensesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.AttributeExpenses(c.Request.Context(), c.Param("id"), c.Param("memberId"), &req); err != nil {
+		respondGroupError(c, err, "Failed to attribute expenses")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Expenses attributed successfully",
+	})
+}
+
+// ListGroupBudgets handles GET /groups/{id}/budgets
+func (h *GroupHandler) ListGroupBudgets(c *gin.Context) {
+	budgets, err := h.service.ListBudgets(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondGroupError(c, err, "Failed to list group budgets")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  budgets,
+		"count": len(budgets),
+	})
+}
+
+// SaveGroupBudget handles PUT /groups/{id}/budgets
+// The budget for the category is created, or its amount replaced if it already exists
+func (h *GroupHandler) SaveGroupBudget(c *gin.Context) {
+	var req application.SaveGroupBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	budget, err := h.service.SaveBudget(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondGroupError(c, err, "Failed to save group budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Group budget saved successfully",
+		"data":    budget,
+	})
+}
+
+// DeleteGroupBudget handles DELETE /groups/{id}/budgets/{budgetId}
+func (h *GroupHandler) DeleteGroupBudget(c *gin.Context) {
+	if err := h.service.DeleteBudget(c.Request.Context(), c.Param("id"), c.Param("budgetId")); err != nil {
+		respondGroupError(c, err, "Failed to delete group budget")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Group budget deleted successfully",
+	})
+}
+
+// respondGroupError maps group errors to HTTP responses
+// fallback is the message used for unexpected errors
+func respondGroupError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidGroup), errors.Is(err, domain.ErrInvalidCategory),
+		errors.Is(err, domain.ErrInvalidBudget), errors.Is(err, domain.ErrInvalidMonth):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrGroupNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+	case errors.Is(err, domain.ErrGroupMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group member not found"})
+	case errors.Is(err, domain.ErrBudgetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+	case errors.Is(err, domain.ErrExpenseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
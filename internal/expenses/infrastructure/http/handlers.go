@@ -4,13 +4,21 @@
 package http
 
 import (
-	"net/http" // Go's built-in HTTP package for status codes and request/response handling
-	"strconv"  // For converting strings to numbers (used for query parameters)
+	"bufio"         // For buffering streamed responses
+	"encoding/json" // For encoding streamed expenses one at a time
+	"errors"        // For matching domain errors through wrapped errors
+	"fmt"           // For writing the end of streamed responses
+	"log"           // For logging errors after a stream has started
+	"net/http"      // Go's built-in HTTP package for status codes and request/response handling
+	"strconv"       // For converting strings to numbers (used for query parameters)
+	"strings"       // For parsing list-valued query parameters
+	"time"          // For parsing ?as_of=
 
-	// For handling dates and times
 	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
 
 	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+	"github.com/google/uuid"   // For validating the employer filter
 )
 
 // Handler handles HTTP requests for expenses
@@ -53,8 +61,37 @@ func (h *Handler) CreateExpense(c *gin.Context) {
 
 	// Step 4: Call the business logic to create the expense
 	// c.Request.Context() provides the HTTP request context for cancellation/timeout
-	expense, err := h.service.CreateExpense(c.Request.Context(), &req)
+	// ?include=budget_impact also returns how the category's budget stands afterwards
+	var expense *domain.Expense
+	var budgetImpact *application.BudgetStatus
+	var err error
+	includeBudgetImpact := includes(c, "budget_impact")
+	if includeBudgetImpact {
+		expense, budgetImpact, err = h.service.CreateExpenseWithBudgetImpact(c.Request.Context(), &req)
+	} else {
+		expense, err = h.service.CreateExpense(c.Request.Context(), &req)
+	}
 	if err != nil {
+		// Expenses the expense policy blocks get a 422 with the explanations
+		if respondPolicyBlocked(c, err) {
+			return
+		}
+		// So do expenses an extension module refuses
+		if errors.Is(err, domain.ErrExpenseRejected) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		// Amount, currency, account, VAT, chargeback, employer, tag, custom field and notes problems are the client's to fix, so they get a 400 with the reason
+		if errors.Is(err, domain.ErrInvalidAmount) || isCurrencyError(err) || errors.Is(err, domain.ErrAccountNotFound) || errors.Is(err, domain.ErrInvalidVAT) ||
+			errors.Is(err, domain.ErrUnknownDimension) || errors.Is(err, domain.ErrEmployerNotFound) || errors.Is(err, domain.ErrInvalidTags) ||
+			isMetadataError(err) || errors.Is(err, domain.ErrInvalidNotes) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		// Step 5: Return a 500 Internal Server Error if business logic fails
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create expense",
@@ -64,10 +101,15 @@ func (h *Handler) CreateExpense(c *gin.Context) {
 
 	// Step 6: Return a 201 Created response with the created expense
 	// 201 is the standard HTTP status code for successful resource creation
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"message": "Expense created successfully", // Success message
 		"data":    expense,                        // The created expense data
-	})
+	}
+	if includeBudgetImpact {
+		// null when the category has no budget
+		response["budget_impact"] = budgetImpact
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
 // GetExpense handles GET /expenses/{id}
@@ -85,10 +127,31 @@ func (h *Handler) GetExpense(c *gin.Context) {
 		return
 	}
 
-	// Step 3: Call the business logic to get the expense
+	// Step 3: ?as_of= asks for the expense as it was at that time, rebuilt from its events
+	if asOf := c.Query("as_of"); asOf != "" {
+		at, err := time.Parse(time.RFC3339, asOf)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "as_of must be an RFC 3339 time, e.g. 2024-03-01T12:00:00Z",
+			})
+			return
+		}
+		expense, err := h.service.GetExpenseAsOf(c.Request.Context(), id, at)
+		if err != nil {
+			respondHistoryError(c, err, "Failed to get expense")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data":  expense,
+			"as_of": at,
+		})
+		return
+	}
+
+	// Step 4: Call the business logic to get the expense
 	expense, err := h.service.GetExpense(c.Request.Context(), id)
 	if err != nil {
-		// Step 4: Handle different types of errors
+		// Step 5: Handle different types of errors
 		if err.Error() == "expense not found" {
 			// Return 404 Not Found if the expense doesn't exist
 			c.JSON(http.StatusNotFound, gin.H{
@@ -103,7 +166,7 @@ func (h *Handler) GetExpense(c *gin.Context) {
 		return
 	}
 
-	// Step 5: Return 200 OK with the expense data
+	// Step 6: Return 200 OK with the expense data
 	c.JSON(http.StatusOK, gin.H{
 		"data": expense,
 	})
@@ -127,6 +190,16 @@ func (h *Handler) GetAllExpenses(c *gin.Context) {
 		filters["category"] = category
 	}
 
+	// ?include_subcategories=true also matches the categories nested under it (e.g. Food → Coffee)
+	if include := c.Query("include_subcategories"); include != "" {
+		value, err := strconv.ParseBool(include)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "include_subcategories must be true or false"})
+			return
+		}
+		filters["include_subcategories"] = value
+	}
+
 	// Check for date range filters
 	if dateFrom := c.Query("date_from"); dateFrom != "" {
 		filters["date_from"] = dateFrom
@@ -137,16 +210,16 @@ func (h *Handler) GetAllExpenses(c *gin.Context) {
 	}
 
 	// Check for amount range filters
-	// strconv.ParseFloat converts a string to a float64
+	// domain.ParseMoney reads the decimal string straight into cents, without going through a float
 	if minAmountStr := c.Query("min_amount"); minAmountStr != "" {
-		// Parse the string to a float64, ignore the error if parsing fails
-		if minAmount, err := strconv.ParseFloat(minAmountStr, 64); err == nil {
+		// Parse the string to an amount, ignore the error if parsing fails
+		if minAmount, err := domain.ParseMoney(minAmountStr, ""); err == nil {
 			filters["min_amount"] = minAmount
 		}
 	}
 
 	if maxAmountStr := c.Query("max_amount"); maxAmountStr != "" {
-		if maxAmount, err := strconv.ParseFloat(maxAmountStr, 64); err == nil {
+		if maxAmount, err := domain.ParseMoney(maxAmountStr, ""); err == nil {
 			filters["max_amount"] = maxAmount
 		}
 	}
@@ -156,9 +229,156 @@ func (h *Handler) GetAllExpenses(c *gin.Context) {
 		filters["description"] = description
 	}
 
+	// Check for chargeback filters (cost center and department codes)
+	if costCenter := c.Query("cost_center"); costCenter != "" {
+		filters["cost_center"] = domain.NormalizeDimensionCode(costCenter)
+	}
+	if department := c.Query("department"); department != "" {
+		filters["department"] = domain.NormalizeDimensionCode(department)
+	}
+
+	// Check for reimbursement filters (?reimbursable=true for work expenses, ?employer_id= for one employer)
+	if reimbursableStr := c.Query("reimbursable"); reimbursableStr != "" {
+		reimbursable, err := strconv.ParseBool(reimbursableStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reimbursable must be true or false"})
+			return
+		}
+		filters["reimbursable"] = reimbursable
+	}
+	// ?reimbursement_status= picks out pending, submitted or paid reimbursable expenses
+	if status := c.Query("reimbursement_status"); status != "" {
+		if !domain.IsReimbursementStatus(status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reimbursement_status must be pending, submitted or paid"})
+			return
+		}
+		filters["reimbursement_status"] = status
+	}
+	// ?business= and ?tax_deductible= pick out the expenses for a business or the tax return
+	for _, mark := range []string{"business", "tax_deductible"} {
+		if markStr := c.Query(mark); markStr != "" {
+			marked, err := strconv.ParseBool(markStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": mark + " must be true or false"})
+				return
+			}
+			filters[mark] = marked
+		}
+	}
+	if employerStr := c.Query("employer_id"); employerStr != "" {
+		employerID, err := uuid.Parse(employerStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "employer_id must be a UUID"})
+			return
+		}
+		filters["employer_id"] = employerID
+	}
+	// ?account_id= picks out the expenses paid from one account
+	if accountStr := c.Query("account_id"); accountStr != "" {
+		if _, err := uuid.Parse(accountStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "account_id must be a UUID"})
+			return
+		}
+		filters["account_id"] = accountStr
+	}
+
+	// Check for tag filter (e.g. ?tag=trip-rome, also written #trip-rome)
+	if tag := domain.NormalizeTag(c.Query("tag")); tag != "" {
+		filters["tag"] = tag
+	}
+
+	// Check for custom field filters (e.g. ?meta.project=ACME); the service types the values
+	for key, values := range c.Request.URL.Query() {
+		if field, ok := strings.CutPrefix(key, "meta."); ok && len(values) > 0 {
+			meta, _ := filters["meta"].(map[string]string)
+			if meta == nil {
+				meta = map[string]string{}
+				filters["meta"] = meta
+			}
+			meta[field] = values[0]
+		}
+	}
+
+	// Check for review flag filter (e.g. ?flag=needs_receipt)
+	if flagStr := c.Query("flag"); flagStr != "" {
+		flag, err := domain.ParseFlag(flagStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filters["flag"] = flag
+	}
+
+	// Check for pagination (limit/offset)
+	// Unpaginated lists are limited in size; bigger ones are streamed or rejected
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters["limit"] = limit
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset > 0 {
+			filters["offset"] = offset
+		}
+	}
+
+	// Apply a query (?q=amount>50 AND category:Food); the separate parameters take precedence over it
+	if q := c.Query("q"); q != "" {
+		query, err := domain.ParseExpenseQuery(q)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query.Apply(filters)
+	}
+
+	// Apply a saved view (?view=<id>); the filters sent with it take precedence over the view's
+	if viewID := c.Query("view"); viewID != "" {
+		if err := h.service.ApplyView(c.Request.Context(), viewID, filters); err != nil {
+			if errors.Is(err, domain.ErrViewNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply view"})
+			return
+		}
+	}
+
+	// Admins can ask for the query plan instead of the data (?explain=true)
+	explain, ok := explainRequested(c)
+	if !ok {
+		return
+	}
+	if explain {
+		plan, err := h.service.ExplainExpenses(c.Request.Context(), filters)
+		if err != nil {
+			explainError(c, err)
+			return
+		}
+		respondWithPlans(c, []*domain.QueryPlan{plan})
+		return
+	}
+
 	// Step 3: Call the business logic to get filtered expenses
 	expenses, err := h.service.GetAllExpenses(c.Request.Context(), filters)
 	if err != nil {
+		// The list is too big to build in memory: stream it, or ask the client to paginate
+		if errors.Is(err, domain.ErrResultTooLarge) {
+			if h.service.StreamingFallback() {
+				h.streamExpenses(c, filters)
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrEncryptedFilter) || isMetadataError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		// Step 4: Return 500 Internal Server Error if business logic fails
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get expenses",
@@ -199,6 +419,22 @@ func (h *Handler) UpdateExpense(c *gin.Context) {
 	// Step 3: Call the business logic to update the expense
 	expense, err := h.service.UpdateExpense(c.Request.Context(), id, &req)
 	if err != nil {
+		if respondPolicyBlocked(c, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrReimbursementClaimed) || errors.Is(err, domain.ErrExpenseChangedConcurrently) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if isCurrencyError(err) || errors.Is(err, domain.ErrInvalidVAT) || errors.Is(err, domain.ErrUnknownDimension) ||
+			errors.Is(err, domain.ErrEmployerNotFound) || errors.Is(err, domain.ErrInvalidTags) || isMetadataError(err) ||
+			errors.Is(err, domain.ErrInvalidNotes) || errors.Is(err, domain.ErrInvalidRefund) || errors.Is(err, domain.ErrAccountNotFound) ||
+			errors.Is(err, domain.ErrRefundExceedsExpense) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		// Step 4: Handle different types of errors
 		if err.Error() == "expense not found" {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -206,6 +442,12 @@ func (h *Handler) UpdateExpense(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, domain.ErrTripApproved) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update expense",
 		})
@@ -241,6 +483,13 @@ func (h *Handler) DeleteExpense(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, domain.ErrTripApproved) || errors.Is(err, domain.ErrExpenseChangedConcurrently) ||
+			errors.Is(err, domain.ErrExpenseHasRefunds) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to delete expense",
 		})
@@ -252,3 +501,72 @@ func (h *Handler) DeleteExpense(c *gin.Context) {
 		"message": "Expense deleted successfully",
 	})
 }
+
+// streamExpenses writes the same {"data": [...], "count": n} body as GetAllExpenses,
+// but encodes and flushes expenses in chunks as they are read from the database
+// "streamed": true tells clients the list was over the in-memory limit
+func (h *Handler) streamExpenses(c *gin.Context, filters map[string]interface{}) {
+	// streamFlushEvery is how many expenses are sent per chunk
+	const streamFlushEvery = 100
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := bufio.NewWriter(c.Writer)
+	w.WriteString(`{"data":[`)
+
+	count := 0
+	err := h.service.StreamExpenses(c.Request.Context(), filters, func(expense *domain.Expense) error {
+		if count > 0 {
+			w.WriteByte(',')
+		}
+		encoded, err := json.Marshal(expense)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(encoded); err != nil {
+			return err
+		}
+		count++
+
+		// Push each full chunk to the client
+		if count%streamFlushEvery == 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// The status line is already sent, so the client sees a truncated (invalid) JSON body
+		log.Printf("failed to stream expenses after %d rows: %v", count, err)
+		w.Flush()
+		return
+	}
+
+	fmt.Fprintf(w, `],"count":%d,"streamed":true}`, count)
+	w.Flush()
+}
+
+// includes reports whether the comma-separated ?include= parameter lists name
+func includes(c *gin.Context, name string) bool {
+	for _, value := range strings.Split(c.Query("include"), ",") {
+		if strings.TrimSpace(value) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isCurrencyError reports whether err is caused by an invalid or unconvertible currency
+func isCurrencyError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidCurrency) ||
+		errors.Is(err, domain.ErrInvalidExchangeRate) ||
+		errors.Is(err, domain.ErrExchangeRateUnavailable)
+}
+
+// isMetadataError reports whether err is caused by metadata or a ?meta. filter that doesn't fit the custom fields
+func isMetadataError(err error) bool {
+	return errors.Is(err, domain.ErrUnknownCustomField) || errors.Is(err, domain.ErrInvalidMetadata)
+}
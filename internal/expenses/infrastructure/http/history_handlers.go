@@ -0,0 +1,67 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for the recorded history of expenses
+package http
+
+import (
+	"encoding/json" // For returning event data as it was recorded
+	"errors"        // For matching domain errors through wrapped errors
+	"net/http"      // Go's built-in HTTP package for status codes
+	"time"          // For event times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+	"github.com/google/uuid"   // For event IDs
+)
+
+// expenseEventResponse is an event as the API shows it, with its data decoded
+type expenseEventResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	Sequence   int64           `json:"sequence"`
+	Type       string          `json:"type"`
+	Actor      string          `json:"actor"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// ListExpenseEvents handles GET /expenses/{id}/events
+// It returns every recorded change of the expense, oldest first
+func (h *Handler) ListExpenseEvents(c *gin.Context) {
+	events, err := h.service.ExpenseEvents(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondHistoryError(c, err, "Failed to list expense events")
+		return
+	}
+
+	response := make([]expenseEventResponse, len(events))
+	for i, event := range events {
+		response[i] = expenseEventResponse{
+			ID:         event.ID,
+			Sequence:   event.Sequence,
+			Type:       event.Type,
+			Actor:      event.Actor,
+			Data:       event.Changes(),
+			OccurredAt: event.OccurredAt,
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":  response,
+		"count": len(response),
+	})
+}
+
+// respondHistoryError maps expense history errors to HTTP responses
+func respondHistoryError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrHistoryUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrHistoryNotRecorded):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExpenseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
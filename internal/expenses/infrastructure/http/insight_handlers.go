@@ -0,0 +1,121 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for spending insights and rule suggestions
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// InsightHandler handles HTTP requests for spending insights
+type InsightHandler struct {
+	service     *application.InsightService
+	suggestions *application.RuleSuggestionService
+}
+
+// NewInsightHandler creates a new insight handler
+func NewInsightHandler(service *application.InsightService, suggestions *application.RuleSuggestionService) *InsightHandler {
+	return &InsightHandler{
+		service:     service, // Store the service dependencies
+		suggestions: suggestions,
+	}
+}
+
+// GetPatterns handles GET /insights/patterns?period=&tz=
+// It shows spending by weekday and hour of day, e.g. to spot weekend overspending
+// period defaults to the last 365 days; tz is the IANA timezone hours are shown in
+func (h *InsightHandler) GetPatterns(c *gin.Context) {
+	patterns, err := h.service.Patterns(c.Request.Context(), c.Query("period"), c.Query("tz"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidPeriod), errors.Is(err, domain.ErrInvalidTimezone):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute spending patterns"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": patterns,
+	})
+}
+
+// ListRuleSuggestions handles GET /insights/rule-suggestions
+// It suggests categorization rules from the imported expenses the caller moved to another category
+func (h *InsightHandler) ListRuleSuggestions(c *gin.Context) {
+	suggestions, err := h.suggestions.Suggestions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  suggestions,
+		"count": len(suggestions),
+	})
+}
+
+// AcceptRuleSuggestion handles POST /insights/rule-suggestions/accept
+// It creates the suggested rule named by pattern and category
+func (h *InsightHandler) AcceptRuleSuggestion(c *gin.Context) {
+	var req application.RuleSuggestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.suggestions.Accept(c.Request.Context(), &req)
+	if err != nil {
+		respondRuleSuggestionError(c, err, "Failed to accept rule suggestion")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Rule created successfully",
+		"data":    rule,
+	})
+}
+
+// DismissRuleSuggestion handles POST /insights/rule-suggestions/dismiss
+func (h *InsightHandler) DismissRuleSuggestion(c *gin.Context) {
+	var req application.RuleSuggestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.suggestions.Dismiss(c.Request.Context(), &req); err != nil {
+		respondRuleSuggestionError(c, err, "Failed to dismiss rule suggestion")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rule suggestion dismissed successfully",
+	})
+}
+
+// respondRuleSuggestionError maps rule suggestion errors to HTTP responses
+func respondRuleSuggestionError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrRuleSuggestionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidRule), errors.Is(err, domain.ErrInvalidCategory):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,145 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the integration endpoints for no-code platforms (Zapier, IFTTT, Make)
+// Unlike the rest of the API they answer with flat payloads, without the {"data": ...} envelope,
+// and authenticate with a user API key sent in the X-API-Key header, so what they create and
+// see belongs to the key's user
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+	"strconv"  // For parsing the page size
+
+	"myexpenses/internal/auth"                 // Request-scoped caller identity
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// Integration headers
+const (
+	// APIKeyHeader is the request header that carries the user API key
+	// There is no query parameter alternative: keys in URLs end up in access and proxy logs
+	APIKeyHeader = "X-API-Key"
+
+	// NextCursorHeader carries the cursor for the next poll of a trigger
+	NextCursorHeader = "X-Next-Cursor"
+)
+
+// IntegrationHandler handles HTTP requests from no-code platforms
+type IntegrationHandler struct {
+	service *application.IntegrationService
+}
+
+// NewIntegrationHandler creates a new integration handler
+func NewIntegrationHandler(service *application.IntegrationService) *IntegrationHandler {
+	return &IntegrationHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// RequireIntegrationKey returns middleware that only lets callers with a user API key through
+// Authenticate has already turned the key into its user; logged-in sessions are refused too, so
+// platforms are always connected with a key the user can revoke on its own
+func RequireIntegrationKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if principal, _ := auth.FromContext(c.Request.Context()); principal.APIKeyID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "integrations need a user API key in " + APIKeyHeader})
+			return
+		}
+		c.Next()
+	}
+}
+
+// TestAuth handles GET /integrations/me
+// Platforms call it when a user connects their account to check the API key, and label
+// the connection with the user it returns
+func (h *IntegrationHandler) TestAuth(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "user_id": auth.UserID(c.Request.Context())})
+}
+
+// NewExpensesTrigger handles GET /integrations/triggers/new-expenses?cursor=&limit=
+// It returns a flat array of expenses, newest first; platforms deduplicate on "id"
+// The cursor for the next poll is in the X-Next-Cursor header (and on every item as "cursor")
+func (h *IntegrationHandler) NewExpensesTrigger(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		limit = parsed
+	}
+
+	items, next, err := h.service.NewExpenses(c.Request.Context(), c.Query("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidCursor):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load expenses"})
+		}
+		return
+	}
+
+	if next != "" {
+		c.Header(NextCursorHeader, next)
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// CreateExpenseAction handles POST /integrations/actions/create-expense
+// The body may be JSON or form-encoded; the created expense is returned flat
+func (h *IntegrationHandler) CreateExpenseAction(c *gin.Context) {
+	var req application.IntegrationCreateExpenseRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	expense, err := h.service.CreateExpense(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidDate):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD or an RFC 3339 timestamp"})
+		case errors.Is(err, domain.ErrInvalidDescription), errors.Is(err, domain.ErrInvalidAmount),
+			errors.Is(err, domain.ErrInvalidCategory), errors.Is(err, domain.ErrInvalidCurrency):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create expense"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, expense)
+}
+
+// FlagExpenseAction handles POST /integrations/actions/flag-expense
+func (h *IntegrationHandler) FlagExpenseAction(c *gin.Context) {
+	var req application.IntegrationFlagExpenseRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.FlagExpense(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidFlag):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrExpenseNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to flag expense"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,169 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for loans and their amortization
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// LoanHandler handles HTTP requests for loans
+type LoanHandler struct {
+	service *application.LoanService
+}
+
+// NewLoanHandler creates a new loan handler
+func NewLoanHandler(service *application.LoanService) *LoanHandler {
+	return &LoanHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CreateLoan handles POST /loans
+func (h *LoanHandler) CreateLoan(c *gin.Context) {
+	var req application.CreateLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	loan, err := h.service.CreateLoan(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidLoan) || errors.Is(err, domain.ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create loan"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Loan created successfully",
+		"data":    loan,
+	})
+}
+
+// ListLoans handles GET /loans
+func (h *LoanHandler) ListLoans(c *gin.Context) {
+	loans, err := h.service.ListLoans(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list loans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  loans,
+		"count": len(loans),
+	})
+}
+
+// GetLoan handles GET /loans/{id}
+func (h *LoanHandler) GetLoan(c *gin.Context) {
+	loan, err := h.service.GetLoan(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrLoanNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get loan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": loan})
+}
+
+// DeleteLoan handles DELETE /loans/{id}
+// The payments are unlinked but kept as expenses
+func (h *LoanHandler) DeleteLoan(c *gin.Context) {
+	if err := h.service.DeleteLoan(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrLoanNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete loan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Loan deleted successfully",
+	})
+}
+
+// AssignLoanPayments handles POST /loans/{id}/payments
+func (h *LoanHandler) AssignLoanPayments(c *gin.Context) {
+	var req application.AssignLoanPaymentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.AssignPayments(c.Request.Context(), c.Param("id"), &req); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrLoanNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		case errors.Is(err, domain.ErrExpenseNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "One or more expenses not found"})
+		case errors.Is(err, domain.ErrInvalidLoanPayment):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign payments to loan"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payments assigned to loan successfully",
+	})
+}
+
+// RemoveLoanPayment handles DELETE /loans/{id}/payments/{expenseId}
+func (h *LoanHandler) RemoveLoanPayment(c *gin.Context) {
+	if err := h.service.RemovePayment(c.Request.Context(), c.Param("id"), c.Param("expenseId")); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrLoanNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		case errors.Is(err, domain.ErrExpenseNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found on this loan"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove payment from loan"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment removed from loan successfully",
+	})
+}
+
+// LoanAmortization handles GET /loans/{id}/amortization
+// It shows the recorded payments, the remaining balance and the projected schedule up to the payoff date
+// Like every report it accepts ?format=json|csv|pdf|html
+func (h *LoanHandler) LoanAmortization(c *gin.Context) {
+	renderer, ok := reportRenderer(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Amortization(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrLoanNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build amortization"})
+		return
+	}
+
+	renderReport(c, renderer, "loan-amortization", result.Document())
+}
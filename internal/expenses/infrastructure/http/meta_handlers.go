@@ -0,0 +1,50 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers that describe the deployment to clients
+package http
+
+import (
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/version"              // Build information
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// MetaHandler handles HTTP requests about the deployment itself
+type MetaHandler struct {
+	limits       application.Limits
+	features     map[string]bool
+	capabilities *application.CapabilityService
+}
+
+// NewMetaHandler creates a new meta handler
+// features maps the names of optional features to whether they are switched on
+func NewMetaHandler(limits application.Limits, features map[string]bool, capabilities *application.CapabilityService) *MetaHandler {
+	return &MetaHandler{limits: limits, features: features, capabilities: capabilities}
+}
+
+// GetVersion handles GET /version
+// It tells clients and operators which build is running and which optional features it has on;
+// it is public, so it never includes settings, keys or hostnames
+func (h *MetaHandler) GetVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"build":    version.Get(),
+			"features": h.features,
+		},
+	})
+}
+
+// GetLimits handles GET /meta/limits
+// Clients read it once to size their pages, uploads and exports for this deployment
+func (h *MetaHandler) GetLimits(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.limits})
+}
+
+// GetCapabilities handles GET /meta/capabilities
+// Clients show or hide optional features (multi-currency, attachments, groups, bank sync)
+// based on it, so features can be launched per deployment and tenant without a new client build
+func (h *MetaHandler) GetCapabilities(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.capabilities.Capabilities(c.Request.Context())})
+}
@@ -0,0 +1,70 @@
+// Package http contains the HTTP handlers for the expense API
+// This file serves the collected metrics to a Prometheus scraper and the SLO status to operators
+package http
+
+import (
+	"errors"   // For recording server errors
+	"log"      // For logging errors after the response has started
+	"net/http" // Go's built-in HTTP package for status codes
+	"time"     // For timing requests
+
+	"myexpenses/internal/metrics" // In-process metrics
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// errServerError is recorded for requests answered with a 5xx status
+var errServerError = errors.New("server error")
+
+// RecordRequests returns middleware that records every routed request into recorder, as a call
+// of metrics.HTTPComponent named after its route ("GET /expenses/:id"); 5xx responses count as failed
+// Requests to no route aren't recorded: their paths are unbounded
+func RecordRequests(recorder metrics.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+		var err error
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			err = errServerError
+		}
+		recorder.RecordCall(metrics.HTTPComponent, c.Request.Method+" "+route, time.Since(start), 0, err)
+	}
+}
+
+// SetupMetricsRoutes configures GET /metrics in the Prometheus text format
+func SetupMetricsRoutes(router *gin.Engine, registry *metrics.Registry) {
+	router.GET("/metrics", func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+		if err := registry.WritePrometheus(c.Writer); err != nil {
+			log.Printf("failed to write metrics: %v", err)
+		}
+	})
+}
+
+// SetupSLORoutes configures GET /admin/slo, the status of every route against its objective
+func SetupSLORoutes(router *gin.Engine, tracker *metrics.SLOTracker) {
+	router.GET("/admin/slo", func(c *gin.Context) {
+		if !isAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "viewing SLOs requires the admin role"})
+			return
+		}
+		statuses := tracker.Status()
+		firing := 0
+		for _, status := range statuses {
+			if len(status.Alerts) > 0 {
+				firing++
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data":   statuses,
+			"count":  len(statuses),
+			"firing": firing,
+		})
+	})
+}
@@ -0,0 +1,131 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers of the migration assistant, which moves history over from other expense apps
+package http
+
+import (
+	"encoding/json"  // For decoding the category mappings form field
+	"errors"         // For matching domain errors through wrapped errors
+	"mime/multipart" // For the uploaded export
+	"net/http"       // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// MigrationHandler handles HTTP requests for the migration assistant
+type MigrationHandler struct {
+	service *application.MigrationService
+}
+
+// NewMigrationHandler creates a new migration handler
+func NewMigrationHandler(service *application.MigrationService) *MigrationHandler {
+	return &MigrationHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// ListSources handles GET /migrations/sources
+// It lists the apps whose exports are recognized, for clients that let users pick one
+func (h *MigrationHandler) ListSources(c *gin.Context) {
+	sources := domain.MigrationSources()
+	c.JSON(http.StatusOK, gin.H{
+		"data":  sources,
+		"count": len(sources),
+	})
+}
+
+// Analyze handles POST /migrations/analyze
+// The export is sent as multipart/form-data in a field called "file"; the optional "source"
+// field names the app it comes from instead of detecting it. Nothing is changed
+func (h *MigrationHandler) Analyze(c *gin.Context) {
+	req, file, ok := h.bindMigration(c, false)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	analysis, err := h.service.Analyze(c.Request.Context(), req)
+	if err != nil {
+		respondMigrationError(c, err, "Failed to analyze export")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": analysis})
+}
+
+// Commit handles POST /migrations/commit
+// It takes the same form as Analyze, plus an optional "mappings" field: a JSON object from
+// categories of the export to the categories their expenses should get
+func (h *MigrationHandler) Commit(c *gin.Context) {
+	req, file, ok := h.bindMigration(c, true)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.Commit(c.Request.Context(), req)
+	if err != nil {
+		respondMigrationError(c, err, "Failed to migrate export")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Export migrated successfully",
+		"data":    result,
+	})
+}
+
+// bindMigration reads the form fields and opens the uploaded export, which the caller closes
+// It writes the error response and returns false when the form isn't usable
+func (h *MigrationHandler) bindMigration(c *gin.Context, withMappings bool) (*application.MigrationRequest, multipart.File, bool) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "The export must be uploaded in the \"file\" form field",
+		})
+		return nil, nil, false
+	}
+	if header.Size > domain.MaxMigrationBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": domain.ErrMigrationTooLarge.Error(),
+			"limit": domain.MaxMigrationBytes,
+		})
+		return nil, nil, false
+	}
+
+	req := &application.MigrationRequest{Source: c.PostForm("source")}
+	if mappings := c.PostForm("mappings"); withMappings && mappings != "" {
+		if err := json.Unmarshal([]byte(mappings), &req.Mappings); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid mappings: expected a JSON object from export categories to categories",
+				"details": err.Error(),
+			})
+			return nil, nil, false
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file " + header.Filename})
+		return nil, nil, false
+	}
+	req.Content = file
+	return req, file, true
+}
+
+// respondMigrationError writes the response for a failed analysis or migration
+func respondMigrationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrMigrationTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrUnknownMigrationSource), errors.Is(err, domain.ErrUnrecognizedExport),
+		errors.Is(err, domain.ErrInvalidMigrationMapping), errors.Is(err, domain.ErrInvalidCategory),
+		errors.Is(err, domain.ErrInvalidDescription), errors.Is(err, domain.ErrInvalidAmount),
+		errors.Is(err, domain.ErrInvalidDate), isCurrencyError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
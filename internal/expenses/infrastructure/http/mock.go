@@ -0,0 +1,46 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the middleware of mock mode, where the API serves fake data without a database
+package http
+
+import (
+	"math/rand/v2" // For latency jitter
+	"net/http"     // Go's built-in HTTP package for status codes
+	"time"         // For delays
+
+	"myexpenses/internal/auth" // The fixed caller of mock mode
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// MockPrincipal returns middleware that makes every request act as principal
+// Mock mode has no users to log in as, so every client works on the same fake data
+func MockPrincipal(principal auth.Principal) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Header("X-Mock", "true")
+		c.Next()
+	}
+}
+
+// Latency returns middleware that holds every request for latency plus up to jitter more,
+// so clients see their loading states and races against the mock server as they would in production
+// Requests the client gives up on are answered at once
+func Latency(latency, jitter time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		delay := latency
+		if jitter > 0 {
+			delay += rand.N(jitter)
+		}
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-c.Request.Context().Done():
+				c.AbortWithStatus(http.StatusRequestTimeout)
+				return
+			}
+		}
+		c.Next()
+	}
+}
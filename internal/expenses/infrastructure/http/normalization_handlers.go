@@ -0,0 +1,127 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for description normalization rules and autocomplete
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+	"strconv"  // For parsing the limit query parameter
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// NormalizationHandler handles HTTP requests for normalization rules and autocomplete
+type NormalizationHandler struct {
+	service *application.NormalizationService
+}
+
+// NewNormalizationHandler creates a new normalization handler
+func NewNormalizationHandler(service *application.NormalizationService) *NormalizationHandler {
+	return &NormalizationHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// ListRules handles GET /normalization-rules
+func (h *NormalizationHandler) ListRules(c *gin.Context) {
+	rules, err := h.service.ListRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list normalization rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  rules,
+		"count": len(rules),
+	})
+}
+
+// CreateRule handles POST /normalization-rules
+func (h *NormalizationHandler) CreateRule(c *gin.Context) {
+	var req application.CreateNormalizationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.service.CreateRule(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidNormalizationRule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create normalization rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Normalization rule created successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRule handles DELETE /normalization-rules/{id}
+func (h *NormalizationHandler) DeleteRule(c *gin.Context) {
+	if err := h.service.DeleteRule(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrNormalizationRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Normalization rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete normalization rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Normalization rule deleted successfully",
+	})
+}
+
+// Preview handles POST /normalization-rules/preview
+// It returns the normalized form of a description so users can test their rules
+func (h *NormalizationHandler) Preview(c *gin.Context) {
+	var req application.PreviewNormalizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	normalized, err := h.service.Preview(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to normalize description"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"description": req.Description,
+			"normalized":  normalized,
+		},
+	})
+}
+
+// Autocomplete handles GET /expenses/autocomplete?q=...&limit=...
+// It suggests previously used descriptions (with their usual category) for a typed prefix
+func (h *NormalizationHandler) Autocomplete(c *gin.Context) {
+	// limit is optional; invalid values fall back to the service default
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	suggestions, err := h.service.SuggestDescriptions(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest descriptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  suggestions,
+		"count": len(suggestions),
+	})
+}
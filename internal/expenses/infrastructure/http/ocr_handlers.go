@@ -0,0 +1,120 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for the tenant's OCR provider, languages and usage
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// OCRHandler handles HTTP requests for OCR settings
+type OCRHandler struct {
+	service *application.OCRService
+}
+
+// NewOCRHandler creates a new OCR handler
+func NewOCRHandler(service *application.OCRService) *OCRHandler {
+	return &OCRHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// GetOCRSettings handles GET /ocr
+// It returns the tenant's settings along with the providers and languages there are to choose from
+func (h *OCRHandler) GetOCRSettings(c *gin.Context) {
+	settings, err := h.service.Settings(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get OCR settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    settings,
+		"options": h.service.Options(),
+	})
+}
+
+// UpdateOCRSettings handles PUT /admin/ocr
+func (h *OCRHandler) UpdateOCRSettings(c *gin.Context) {
+	if !requireOCRAdmin(c) {
+		return
+	}
+
+	var req application.OCRSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidOCRSettings) || errors.Is(err, domain.ErrOCRProviderUnavailable) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save OCR settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "OCR settings saved successfully",
+		"data":    settings,
+	})
+}
+
+// ResetOCRSettings handles DELETE /admin/ocr
+func (h *OCRHandler) ResetOCRSettings(c *gin.Context) {
+	if !requireOCRAdmin(c) {
+		return
+	}
+
+	if err := h.service.ResetSettings(c.Request.Context()); err != nil {
+		if errors.Is(err, domain.ErrOCRSettingsNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "OCR settings not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset OCR settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "OCR settings reset successfully",
+	})
+}
+
+// GetOCRUsage handles GET /admin/ocr/usage
+// Admins compare providers by it: documents sent, failures and how much text came back
+func (h *OCRHandler) GetOCRUsage(c *gin.Context) {
+	if !requireOCRAdmin(c) {
+		return
+	}
+
+	usage, err := h.service.Usage(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get OCR usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  usage,
+		"count": len(usage),
+	})
+}
+
+// requireOCRAdmin writes a 403 response and returns false unless the caller is an admin
+// The provider choice affects the whole tenant's bill
+func requireOCRAdmin(c *gin.Context) bool {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "managing OCR requires an admin"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for planned purchases and the estimate accuracy report
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// PlannedPurchaseHandler handles HTTP requests for planned purchases
+type PlannedPurchaseHandler struct {
+	service *application.PlannedPurchaseService
+}
+
+// NewPlannedPurchaseHandler creates a new planned purchase handler
+func NewPlannedPurchaseHandler(service *application.PlannedPurchaseService) *PlannedPurchaseHandler {
+	return &PlannedPurchaseHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CreatePlannedPurchase handles POST /planned-purchases
+func (h *PlannedPurchaseHandler) CreatePlannedPurchase(c *gin.Context) {
+	var req application.CreatePlannedPurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	purchase, err := h.service.CreatePlannedPurchase(c.Request.Context(), &req)
+	if err != nil {
+		respondPlannedPurchaseError(c, err, "Failed to create planned purchase")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Planned purchase created successfully",
+		"data":    purchase,
+	})
+}
+
+// ListPlannedPurchases handles GET /planned-purchases?status=open|bought
+func (h *PlannedPurchaseHandler) ListPlannedPurchases(c *gin.Context) {
+	purchases, err := h.service.ListPlannedPurchases(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		respondPlannedPurchaseError(c, err, "Failed to list planned purchases")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  purchases,
+		"count": len(purchases),
+	})
+}
+
+// GetPlannedPurchase handles GET /planned-purchases/{id}
+func (h *PlannedPurchaseHandler) GetPlannedPurchase(c *gin.Context) {
+	purchase, err := h.service.GetPlannedPurchase(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondPlannedPurchaseError(c, err, "Failed to get planned purchase")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": purchase,
+	})
+}
+
+// LinkExpense handles POST /planned-purchases/{id}/link
+// It records the expense that paid for the purchase and shows how far off the estimate was
+func (h *PlannedPurchaseHandler) LinkExpense(c *gin.Context) {
+	var req application.LinkPlannedPurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	purchase, err := h.service.LinkExpense(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondPlannedPurchaseError(c, err, "Failed to link expense")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Expense linked successfully",
+		"data":    purchase,
+	})
+}
+
+// UnlinkExpense handles DELETE /planned-purchases/{id}/link
+func (h *PlannedPurchaseHandler) UnlinkExpense(c *gin.Context) {
+	purchase, err := h.service.UnlinkExpense(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondPlannedPurchaseError(c, err, "Failed to unlink expense")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Expense unlinked successfully",
+		"data":    purchase,
+	})
+}
+
+// DeletePlannedPurchase handles DELETE /planned-purchases/{id}
+func (h *PlannedPurchaseHandler) DeletePlannedPurchase(c *gin.Context) {
+	if err := h.service.DeletePlannedPurchase(c.Request.Context(), c.Param("id")); err != nil {
+		respondPlannedPurchaseError(c, err, "Failed to delete planned purchase")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Planned purchase deleted successfully",
+	})
+}
+
+// AccuracyReport handles GET /reports/estimate-accuracy?period=
+// It compares the estimates of the purchases bought in the period with what they cost, month by month
+// Like every report it accepts ?format=json|csv|pdf|html and ?page=&page_size=
+func (h *PlannedPurchaseHandler) AccuracyReport(c *gin.Context) {
+	renderer, ok := reportRenderer(c)
+	if !ok {
+		return
+	}
+
+	period := c.Query("period")
+	if period == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "period is required",
+		})
+		return
+	}
+
+	result, err := h.service.AccuracyReport(c.Request.Context(), period)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPeriod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build estimate accuracy report"})
+		return
+	}
+
+	renderReport(c, renderer, "estimate-accuracy", result.Document())
+}
+
+// respondPlannedPurchaseError maps planned purchase errors to status codes
+func respondPlannedPurchaseError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPlannedPurchase):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExpenseNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Linked expense not found"})
+	case errors.Is(err, domain.ErrPlannedPurchaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Planned purchase not found"})
+	case errors.Is(err, domain.ErrPlannedPurchaseLinked), errors.Is(err, domain.ErrExpenseAlreadyPlanned):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,176 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for the expense policy
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// PolicyHandler handles the HTTP requests about the expense policy
+type PolicyHandler struct {
+	service *application.PolicyService
+}
+
+// NewPolicyHandler creates a new policy handler
+func NewPolicyHandler(service *application.PolicyService) *PolicyHandler {
+	return &PolicyHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// ListRules handles GET /policies
+// Everyone may read the policy their expenses are checked against
+func (h *PolicyHandler) ListRules(c *gin.Context) {
+	rules, err := h.service.ListRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list policy rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  rules,
+		"count": len(rules),
+	})
+}
+
+// CreateRule handles POST /admin/policies
+func (h *PolicyHandler) CreateRule(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "changing the expense policy requires the admin role"})
+		return
+	}
+
+	var req application.PolicyRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.service.CreateRule(c.Request.Context(), &req)
+	if err != nil {
+		respondPolicyError(c, err, "Failed to create policy rule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Policy rule created successfully",
+		"data":    rule,
+	})
+}
+
+// UpdateRule handles PUT /admin/policies/{id}
+func (h *PolicyHandler) UpdateRule(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "changing the expense policy requires the admin role"})
+		return
+	}
+
+	var req application.PolicyRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.service.UpdateRule(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondPolicyError(c, err, "Failed to update policy rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Policy rule updated successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRule handles DELETE /admin/policies/{id}
+func (h *PolicyHandler) DeleteRule(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "changing the expense policy requires the admin role"})
+		return
+	}
+
+	if err := h.service.DeleteRule(c.Request.Context(), c.Param("id")); err != nil {
+		respondPolicyError(c, err, "Failed to delete policy rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Policy rule deleted successfully"})
+}
+
+// ViolationReport handles GET /reports/policy-violations?period=
+// It totals the policy violations of the period by user, category and rule (admin only)
+// Like every report it accepts ?format=json|csv|pdf|html and ?page=&page_size=
+func (h *PolicyHandler) ViolationReport(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "the policy violation report requires the admin role"})
+		return
+	}
+	renderer, ok := reportRenderer(c)
+	if !ok {
+		return
+	}
+
+	period := c.Query("period")
+	if period == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "period is required",
+		})
+		return
+	}
+
+	result, err := h.service.ViolationReport(c.Request.Context(), period)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidPeriod):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build policy violation report"})
+		}
+		return
+	}
+
+	renderReport(c, renderer, "policy-violations", result.Document())
+}
+
+// respondPolicyError maps policy rule errors to status codes
+func respondPolicyError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPolicyRule), errors.Is(err, domain.ErrInvalidScript):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPolicyRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Policy rule not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// respondPolicyBlocked answers 422 with the violations when err is a policy block
+// It reports whether it did, so callers fall through to their other errors otherwise
+func respondPolicyBlocked(c *gin.Context, err error) bool {
+	var blocked *domain.PolicyBlockedError
+	if !errors.As(err, &blocked) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":   domain.ErrPolicyBlocked.Error(),
+		"details": blocked.Violations,
+	})
+	return true
+}
@@ -0,0 +1,220 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for public submission forms and the staging area
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// PublicFormHandler handles HTTP requests for public forms and staged submissions
+type PublicFormHandler struct {
+	service *application.PublicFormService
+}
+
+// NewPublicFormHandler creates a new public form handler
+func NewPublicFormHandler(service *application.PublicFormService) *PublicFormHandler {
+	return &PublicFormHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CreateForm handles POST /forms
+// The response contains the form's token; it is not shown again
+func (h *PublicFormHandler) CreateForm(c *gin.Context) {
+	var req application.CreatePublicFormRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	form, err := h.service.CreateForm(c.Request.Context(), &req)
+	if err != nil {
+		respondPublicFormError(c, err, "Failed to create form")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Form created successfully",
+		"data":    form,
+	})
+}
+
+// ListForms handles GET /forms
+func (h *PublicFormHandler) ListForms(c *gin.Context) {
+	forms, err := h.service.ListForms(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list forms"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  forms,
+		"count": len(forms),
+	})
+}
+
+// RevokeForm handles DELETE /forms/{id}
+// The form stops accepting submissions; it stays listed so its submissions keep their origin
+func (h *PublicFormHandler) RevokeForm(c *gin.Context) {
+	form, err := h.service.RevokeForm(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondPublicFormError(c, err, "Failed to revoke form")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Form revoked successfully",
+		"data":    form,
+	})
+}
+
+// GetPublicForm handles GET /public/forms/{token}
+// It is called without an account and shows only what the submitter needs
+func (h *PublicFormHandler) GetPublicForm(c *gin.Context) {
+	info, err := h.service.FormInfo(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		respondPublicFormError(c, err, "Failed to load form")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": info,
+	})
+}
+
+// SubmitPublicExpense handles POST /public/forms/{token}/expenses
+// It is called without an account; the expense lands in the staging area for review
+func (h *PublicFormHandler) SubmitPublicExpense(c *gin.Context) {
+	var req application.SubmitExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	staged, err := h.service.Submit(c.Request.Context(), c.Param("token"), &req)
+	if err != nil {
+		respondPublicFormError(c, err, "Failed to submit expense")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Expense submitted for review",
+		"data":    staged,
+	})
+}
+
+// ListStaged handles GET /staging?status=pending|approved|rejected
+func (h *PublicFormHandler) ListStaged(c *gin.Context) {
+	staged, err := h.service.ListStaged(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSubmission) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved or rejected"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list submissions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  staged,
+		"count": len(staged),
+	})
+}
+
+// ApproveStaged handles POST /staging/{id}/approve
+// The submission becomes a real expense, which is returned
+func (h *PublicFormHandler) ApproveStaged(c *gin.Context) {
+	expense, err := h.service.Approve(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondPublicFormError(c, err, "Failed to approve submission")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Submission approved successfully",
+		"data":    expense,
+	})
+}
+
+// CodeStaged handles PUT /staging/{id}
+// The cardholder sets what a card transaction was for before approving it
+func (h *PublicFormHandler) CodeStaged(c *gin.Context) {
+	var req application.CodeStagedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	staged, err := h.service.Code(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondPublicFormError(c, err, "Failed to code transaction")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Transaction coded successfully",
+		"data":    staged,
+	})
+}
+
+// RejectStaged handles POST /staging/{id}/reject
+func (h *PublicFormHandler) RejectStaged(c *gin.Context) {
+	var req application.RejectSubmissionRequest
+	// The body is optional: a rejection doesn't need a reason
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	staged, err := h.service.Reject(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondPublicFormError(c, err, "Failed to reject submission")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Submission rejected successfully",
+		"data":    staged,
+	})
+}
+
+// respondPublicFormError maps public form and staging errors to HTTP responses
+// fallback is the message used for unexpected errors
+func respondPublicFormError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPublicForm), errors.Is(err, domain.ErrInvalidCategory),
+		errors.Is(err, domain.ErrInvalidSubmission), errors.Is(err, domain.ErrAmountOverFormLimit),
+		errors.Is(err, domain.ErrSubmissionNotCodable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPublicFormNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Form not found"})
+	case errors.Is(err, domain.ErrPublicFormClosed):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrSubmissionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+	case errors.Is(err, domain.ErrSubmissionReviewed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
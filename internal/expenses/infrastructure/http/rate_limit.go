@@ -0,0 +1,112 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the per-client rate limiting and cost budget middleware
+package http
+
+import (
+	"log"      // For reporting limiter failures
+	"math"     // For rounding waits up to whole seconds
+	"net/http" // Go's built-in HTTP package for status codes
+	"strconv"  // For the rate limit headers
+	"strings"  // For matching route prefixes
+	"time"     // For converting waits to seconds
+
+	"myexpenses/internal/auth"      // For the caller the bucket belongs to
+	"myexpenses/internal/ratelimit" // Token bucket limiters
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// RateLimit returns middleware that limits the requests to the routes under prefixes per client
+// Logged-in callers have one bucket per user, shared by all their tokens and API keys; anonymous
+// callers have one per address. Every response carries X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset (seconds until the bucket is full); refused requests get 429 with Retry-After
+// It runs after Authenticate so it knows the user. When the limiter itself fails (e.g. Redis is down)
+// requests are let through: losing the limit for a while is better than losing the API
+func RateLimit(limiter ratelimit.Limiter, prefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		limited := false
+		for _, prefix := range prefixes {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				limited = true
+				break
+			}
+		}
+		if !limited {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), clientKey(c))
+		if err != nil {
+			log.Printf("rate limiter failed, not limiting: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(wholeSeconds(result.Reset)))
+		if !result.Allowed {
+			retryAfter := wholeSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":       "rate limit exceeded",
+				"retry_after": retryAfter,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CostLimit returns middleware that charges every request its cost (see ratelimit.Costs) against
+// a per-client budget, so heavy users of exports and analytics can't starve everyone else
+// Clients are told apart like in RateLimit. Every response carries X-Cost-Budget, X-Cost-Remaining,
+// X-Cost-Reset (seconds until the budget is whole again) and X-Request-Cost; requests the budget
+// can't pay for get 429 with Retry-After. The limit is soft: an exhausted budget refuses expensive
+// requests first, cheap ones still go through, and limiter failures let everything through
+func CostLimit(limiter ratelimit.Limiter, costs ratelimit.Costs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cost := costs.Of(c.Request.URL.Path)
+		if cost == 0 {
+			c.Next()
+			return
+		}
+		result, err := limiter.AllowN(c.Request.Context(), clientKey(c), cost)
+		if err != nil {
+			log.Printf("cost limiter failed, not limiting: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-Cost-Budget", strconv.Itoa(result.Limit))
+		c.Header("X-Cost-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-Cost-Reset", strconv.Itoa(wholeSeconds(result.Reset)))
+		c.Header("X-Request-Cost", strconv.Itoa(cost))
+		if !result.Allowed {
+			retryAfter := wholeSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":       "cost budget exceeded",
+				"cost":        cost,
+				"retry_after": retryAfter,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// clientKey names the bucket of the caller: their user, or their address when anonymous
+func clientKey(c *gin.Context) string {
+	if userID := auth.UserID(c.Request.Context()); userID != "" {
+		return "user:" + userID
+	}
+	return "address:" + c.ClientIP()
+}
+
+// wholeSeconds rounds d up to whole seconds
+func wholeSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,164 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers that switch read-only mode and the middleware that enforces it
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+	"strings"  // For matching exempt paths
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ReadOnlyHandler handles HTTP requests for read-only mode
+type ReadOnlyHandler struct {
+	service *application.ReadOnlyService
+}
+
+// NewReadOnlyHandler creates a new read-only handler
+func NewReadOnlyHandler(service *application.ReadOnlyService) *ReadOnlyHandler {
+	return &ReadOnlyHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// EnforceReadOnly returns middleware that refuses requests that would change data (anything but
+// GET, HEAD and OPTIONS) while the caller's tenant or the whole deployment is read-only
+// It runs after Authenticate, which tells it the tenant. Refused requests get
+// {"error": ..., "reason": ...} with the reason code of the switch: 503 for maintenance, which
+// clients can retry later, and 402 for a suspended subscription, which needs paying first.
+// Routes under exempt (e.g. "/auth" for logging in and out) stay open
+func EnforceReadOnly(service *application.ReadOnlyService, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if safeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		path := c.Request.URL.Path
+		for _, prefix := range exempt {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				c.Next()
+				return
+			}
+		}
+		mode := service.Mode(c.Request.Context())
+		if mode == nil {
+			c.Next()
+			return
+		}
+		status := http.StatusServiceUnavailable
+		if mode.Reason == domain.ReadOnlyBillingSuspended {
+			status = http.StatusPaymentRequired
+		}
+		c.AbortWithStatusJSON(status, gin.H{
+			"error":  mode.Error(),
+			"reason": mode.Reason,
+		})
+	}
+}
+
+// GetReadOnly handles GET /read-only
+// Clients use it to show a banner and disable editing before users run into refused writes;
+// data is null while the caller's tenant may write
+func (h *ReadOnlyHandler) GetReadOnly(c *gin.Context) {
+	mode := h.service.Mode(c.Request.Context())
+	if mode == nil {
+		c.JSON(http.StatusOK, gin.H{"data": nil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"reason":     mode.Reason,
+		"message":    mode.Error(),
+		"updated_at": mode.UpdatedAt,
+	}})
+}
+
+// ListReadOnly handles GET /admin/read-only
+// It lists every tenant that is read-only; the tenant ID "*" is the whole deployment
+func (h *ReadOnlyHandler) ListReadOnly(c *gin.Context) {
+	if !requireReadOnlyAdmin(c) {
+		return
+	}
+
+	modes, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list read-only modes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  modes,
+		"count": len(modes),
+	})
+}
+
+// EnableReadOnly handles PUT /admin/read-only?tenant=acme
+// Without ?tenant= the whole deployment becomes read-only; ?tenant= with an empty value is the default tenant
+func (h *ReadOnlyHandler) EnableReadOnly(c *gin.Context) {
+	if !requireReadOnlyAdmin(c) {
+		return
+	}
+
+	var req application.ReadOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	mode, err := h.service.Enable(c.Request.Context(), readOnlyTenant(c), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidReadOnlyMode) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to switch on read-only mode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Read-only mode switched on",
+		"data":    mode,
+	})
+}
+
+// DisableReadOnly handles DELETE /admin/read-only?tenant=acme
+// Without ?tenant= it lifts the deployment-wide switch; tenants switched on their own stay read-only
+func (h *ReadOnlyHandler) DisableReadOnly(c *gin.Context) {
+	if !requireReadOnlyAdmin(c) {
+		return
+	}
+
+	if err := h.service.Disable(c.Request.Context(), readOnlyTenant(c)); err != nil {
+		if errors.Is(err, domain.ErrReadOnlyModeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to switch off read-only mode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Read-only mode switched off"})
+}
+
+// readOnlyTenant returns the tenant named by ?tenant=, or domain.ReadOnlyAllTenants without one
+func readOnlyTenant(c *gin.Context) string {
+	if tenantID, ok := c.GetQuery("tenant"); ok {
+		return tenantID
+	}
+	return domain.ReadOnlyAllTenants
+}
+
+// requireReadOnlyAdmin writes a 403 response and returns false unless the caller is an admin
+func requireReadOnlyAdmin(c *gin.Context) bool {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "switching read-only mode requires an admin token"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for bulk receipt uploads and the receipt inbox
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ReceiptHandler handles HTTP requests for the receipt inbox
+type ReceiptHandler struct {
+	service *application.ReceiptService
+}
+
+// NewReceiptHandler creates a new receipt handler
+func NewReceiptHandler(service *application.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// UploadReceipts handles POST /receipts/bulk
+// The files are sent as multipart/form-data, all in a field called "files"
+// Each file is reported as attached, pending (waiting in the inbox) or failed
+func (h *ReceiptHandler) UploadReceipts(c *gin.Context) {
+	// Step 1: Read the uploaded files from the multipart form
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["files"]) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Receipts must be uploaded in the \"files\" form field",
+		})
+		return
+	}
+	headers := form.File["files"]
+	if len(headers) > domain.MaxReceiptBatch {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": domain.ErrTooManyFiles.Error(),
+			"limit": domain.MaxReceiptBatch,
+		})
+		return
+	}
+
+	// Step 2: Open every file
+	files := make([]*application.UploadAttachmentRequest, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Failed to read uploaded file " + header.Filename,
+			})
+			return
+		}
+		defer file.Close()
+		files = append(files, &application.UploadAttachmentRequest{
+			FileName:    header.Filename,
+			ContentType: header.Header.Get("Content-Type"),
+			Size:        header.Size,
+			Content:     file,
+		})
+	}
+
+	// Step 3: Match and attach them
+	results, err := h.service.UploadReceipts(c.Request.Context(), files)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload receipts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  results,
+		"count": len(results),
+	})
+}
+
+// ListPendingReceipts handles GET /receipts/pending
+func (h *ReceiptHandler) ListPendingReceipts(c *gin.Context) {
+	receipts, err := h.service.ListPending(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list pending receipts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  receipts,
+		"count": len(receipts),
+	})
+}
+
+// MatchPendingReceipt handles POST /receipts/pending/{id}/match
+func (h *ReceiptHandler) MatchPendingReceipt(c *gin.Context) {
+	var req application.MatchReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	attachment, err := h.service.MatchPending(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrPendingReceiptNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pending receipt not found"})
+		case errors.Is(err, domain.ErrExpenseNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to match receipt"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Receipt attached successfully",
+		"data":    attachment,
+	})
+}
+
+// DiscardPendingReceipt handles DELETE /receipts/pending/{id}
+func (h *ReceiptHandler) DiscardPendingReceipt(c *gin.Context) {
+	if err := h.service.DiscardPending(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrPendingReceiptNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pending receipt not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to discard receipt"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Receipt discarded successfully",
+	})
+}
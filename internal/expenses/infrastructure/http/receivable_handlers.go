@@ -0,0 +1,141 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for receivables (money owed to the user)
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ReceivableHandler handles HTTP requests for receivables
+type ReceivableHandler struct {
+	service *application.ReceivableService
+}
+
+// NewReceivableHandler creates a new receivable handler
+func NewReceivableHandler(service *application.ReceivableService) *ReceivableHandler {
+	return &ReceivableHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CreateReceivable handles POST /receivables
+func (h *ReceivableHandler) CreateReceivable(c *gin.Context) {
+	var req application.CreateReceivableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	receivable, err := h.service.CreateReceivable(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidReceivable), errors.Is(err, domain.ErrInvalidCurrency):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrExpenseNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Linked expense not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create receivable"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Receivable created successfully",
+		"data":    receivable,
+	})
+}
+
+// ListReceivables handles GET /receivables?status=open|overdue|settled&debtor=
+// Besides the list it returns what is still owed in total, per currency
+func (h *ReceivableHandler) ListReceivables(c *gin.Context) {
+	result, err := h.service.ListReceivables(c.Request.Context(), c.Query("status"), c.Query("debtor"))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidReceivableStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list receivables"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        result.Receivables,
+		"count":       len(result.Receivables),
+		"outstanding": result.Outstanding,
+	})
+}
+
+// GetReceivable handles GET /receivables/{id}
+func (h *ReceivableHandler) GetReceivable(c *gin.Context) {
+	receivable, err := h.service.GetReceivable(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrReceivableNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Receivable not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get receivable"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": receivable})
+}
+
+// SettleReceivable handles POST /receivables/{id}/settle
+// Without an amount (or an empty body) everything still outstanding is settled
+func (h *ReceivableHandler) SettleReceivable(c *gin.Context) {
+	var req application.SettleReceivableRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	receivable, err := h.service.SettleReceivable(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrReceivableNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Receivable not found"})
+		case errors.Is(err, domain.ErrInvalidSettlement):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrReceivableSettled):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to settle receivable"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Repayment recorded successfully",
+		"data":    receivable,
+	})
+}
+
+// DeleteReceivable handles DELETE /receivables/{id}
+func (h *ReceivableHandler) DeleteReceivable(c *gin.Context) {
+	if err := h.service.DeleteReceivable(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrReceivableNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Receivable not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete receivable"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Receivable deleted successfully",
+	})
+}
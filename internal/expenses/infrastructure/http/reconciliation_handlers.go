@@ -0,0 +1,163 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for the statement reconciliation workspace
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ReconciliationHandler handles HTTP requests for reconciliation sessions
+type ReconciliationHandler struct {
+	service *application.ReconciliationService
+}
+
+// NewReconciliationHandler creates a new reconciliation handler
+func NewReconciliationHandler(service *application.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// StartReconciliation handles POST /reconciliations
+// The body carries the statement period and its lines; the response is the auto-matched workspace
+func (h *ReconciliationHandler) StartReconciliation(c *gin.Context) {
+	var req application.StartReconciliationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	workspace, err := h.service.StartSession(c.Request.Context(), &req)
+	if err != nil {
+		respondReconciliationError(c, err, "Failed to start reconciliation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Reconciliation started successfully",
+		"data":    workspace,
+	})
+}
+
+// ListReconciliations handles GET /reconciliations
+func (h *ReconciliationHandler) ListReconciliations(c *gin.Context) {
+	sessions, err := h.service.ListSessions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list reconciliations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  sessions,
+		"count": len(sessions),
+	})
+}
+
+// GetReconciliation handles GET /reconciliations/{id}
+// It returns the matched pairs and the unmatched items on both sides
+func (h *ReconciliationHandler) GetReconciliation(c *gin.Context) {
+	workspace, err := h.service.GetWorkspace(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondReconciliationError(c, err, "Failed to get reconciliation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": workspace,
+	})
+}
+
+// AutoMatchReconciliation handles POST /reconciliations/{id}/auto-match
+func (h *ReconciliationHandler) AutoMatchReconciliation(c *gin.Context) {
+	workspace, err := h.service.AutoMatch(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondReconciliationError(c, err, "Failed to match statement lines")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": workspace,
+	})
+}
+
+// MatchStatementLine handles PUT /reconciliations/{id}/lines/{lineId}/match
+func (h *ReconciliationHandler) MatchStatementLine(c *gin.Context) {
+	var req application.MatchStatementLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	line, err := h.service.MatchLine(c.Request.Context(), c.Param("id"), c.Param("lineId"), &req)
+	if err != nil {
+		respondReconciliationError(c, err, "Failed to match statement line")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Statement line matched successfully",
+		"data":    line,
+	})
+}
+
+// UnmatchStatementLine handles DELETE /reconciliations/{id}/lines/{lineId}/match
+func (h *ReconciliationHandler) UnmatchStatementLine(c *gin.Context) {
+	line, err := h.service.UnmatchLine(c.Request.Context(), c.Param("id"), c.Param("lineId"))
+	if err != nil {
+		respondReconciliationError(c, err, "Failed to unmatch statement line")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Statement line unmatched successfully",
+		"data":    line,
+	})
+}
+
+// CloseReconciliation handles POST /reconciliations/{id}/close
+// The matched expenses are marked reconciled and the session can't be changed afterwards
+func (h *ReconciliationHandler) CloseReconciliation(c *gin.Context) {
+	workspace, err := h.service.CloseSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondReconciliationError(c, err, "Failed to close reconciliation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reconciliation closed successfully",
+		"data":    workspace,
+	})
+}
+
+// respondReconciliationError maps reconciliation errors to HTTP responses
+// fallback is the message used for unexpected errors
+func respondReconciliationError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidStatement):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrReconciliationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reconciliation not found"})
+	case errors.Is(err, domain.ErrStatementLineNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Statement line not found"})
+	case errors.Is(err, domain.ErrExpenseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+	case errors.Is(err, domain.ErrAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+	case errors.Is(err, domain.ErrReconciliationClosed), errors.Is(err, domain.ErrExpenseAlreadyMatched):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,67 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for refunds of expenses
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// CreateRefund handles POST /expenses/{id}/refunds
+// The refund is saved as an expense with a negative amount linked to the refunded one
+func (h *Handler) CreateRefund(c *gin.Context) {
+	var req application.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	refund, err := h.service.RefundExpense(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondRefundError(c, err, "Failed to record refund")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Refund recorded successfully",
+		"data":    refund,
+	})
+}
+
+// ListRefunds handles GET /expenses/{id}/refunds
+// It returns the expense with its refunds and what it cost net of them
+func (h *Handler) ListRefunds(c *gin.Context) {
+	summary, err := h.service.ListRefunds(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondRefundError(c, err, "Failed to list refunds")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": summary,
+	})
+}
+
+// respondRefundError maps refund errors to HTTP responses
+func respondRefundError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidRefund), errors.Is(err, domain.ErrRefundExceedsExpense):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExpenseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExpenseChangedConcurrently):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
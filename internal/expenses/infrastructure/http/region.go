@@ -0,0 +1,55 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the health check and the write fence of passive regions
+package http
+
+import (
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ReasonPassiveRegion is the reason code of writes refused by a passive region
+const ReasonPassiveRegion = "passive_region"
+
+// RejectWrites returns middleware that refuses every request that would change data
+// (anything but GET, HEAD and OPTIONS) with 503 and a machine-readable reason, so clients
+// can retry against the active region. A passive region's database is a replica, so those
+// requests would fail anyway, only later and less clearly
+func RejectWrites() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if safeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":  domain.ErrPassiveRegion.Error(),
+			"reason": ReasonPassiveRegion,
+		})
+	}
+}
+
+// HealthHandler handles the health check
+type HealthHandler struct {
+	service *application.HealthService
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(service *application.HealthService) *HealthHandler {
+	return &HealthHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// Health handles GET /health
+// Load balancers and monitoring use it: regions that are down answer 503, degraded ones still 200
+func (h *HealthHandler) Health(c *gin.Context) {
+	report := h.service.Check(c.Request.Context())
+	status := http.StatusOK
+	if report.Status == application.HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
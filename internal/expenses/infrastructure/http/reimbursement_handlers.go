@@ -0,0 +1,141 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for the reimbursement lifecycle: batches and the summary
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ReimbursementHandler handles HTTP requests for reimbursement batches
+type ReimbursementHandler struct {
+	service *application.ReimbursementService
+}
+
+// NewReimbursementHandler creates a new reimbursement handler
+func NewReimbursementHandler(service *application.ReimbursementService) *ReimbursementHandler {
+	return &ReimbursementHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// Summary handles GET /reimbursements
+// It totals the reimbursable expenses that are pending, submitted and paid, and lists the
+// batches waiting for their payout
+func (h *ReimbursementHandler) Summary(c *gin.Context) {
+	summary, err := h.service.Summary(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarize reimbursements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+// SubmitBatch handles POST /reimbursements/batches
+// The expenses are marked submitted; they must be pending reimbursable expenses
+func (h *ReimbursementHandler) SubmitBatch(c *gin.Context) {
+	var req application.SubmitBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	batch, err := h.service.SubmitBatch(c.Request.Context(), &req)
+	if err != nil {
+		respondReimbursementError(c, err, "Failed to submit reimbursement batch")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Reimbursement batch submitted successfully",
+		"data":    batch,
+	})
+}
+
+// ListBatches handles GET /reimbursements/batches
+func (h *ReimbursementHandler) ListBatches(c *gin.Context) {
+	batches, err := h.service.ListBatches(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list reimbursement batches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  batches,
+		"count": len(batches),
+	})
+}
+
+// GetBatch handles GET /reimbursements/batches/{id}
+func (h *ReimbursementHandler) GetBatch(c *gin.Context) {
+	batch, err := h.service.GetBatch(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondReimbursementError(c, err, "Failed to get reimbursement batch")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": batch})
+}
+
+// MarkBatchPaid handles POST /reimbursements/batches/{id}/paid
+// The response shows how the payout compares to the claim (difference)
+func (h *ReimbursementHandler) MarkBatchPaid(c *gin.Context) {
+	var req application.MarkBatchPaidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	batch, err := h.service.MarkBatchPaid(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondReimbursementError(c, err, "Failed to mark reimbursement batch paid")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reimbursement batch marked paid successfully",
+		"data":    batch,
+	})
+}
+
+// WithdrawBatch handles DELETE /reimbursements/batches/{id}
+// Only batches that haven't been paid can be withdrawn; their expenses are pending again
+func (h *ReimbursementHandler) WithdrawBatch(c *gin.Context) {
+	if err := h.service.WithdrawBatch(c.Request.Context(), c.Param("id")); err != nil {
+		respondReimbursementError(c, err, "Failed to withdraw reimbursement batch")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reimbursement batch withdrawn successfully",
+	})
+}
+
+// respondReimbursementError maps reimbursement errors to HTTP responses
+func respondReimbursementError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrReimbursementBatchNotFound), errors.Is(err, domain.ErrExpenseNotFound),
+		errors.Is(err, domain.ErrEmployerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidReimbursementBatch), errors.Is(err, domain.ErrInvalidReimbursement):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrReimbursementNotPending), errors.Is(err, domain.ErrReimbursementBatchPaid):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
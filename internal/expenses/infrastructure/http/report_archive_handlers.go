@@ -0,0 +1,95 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for the signed archives of approved trip reports
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// maxArchiveUploadBytes is the largest archive accepted for verification
+// Archives hold every receipt of a trip, so this is well above the attachment limit
+const maxArchiveUploadBytes = 1 << 30
+
+// ReportArchiveHandler handles HTTP requests for report archives
+type ReportArchiveHandler struct {
+	service *application.ReportArchiveService
+}
+
+// NewReportArchiveHandler creates a new report archive handler
+func NewReportArchiveHandler(service *application.ReportArchiveService) *ReportArchiveHandler {
+	return &ReportArchiveHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// DownloadArchive handles GET /trips/{id}/archive
+// The zip holds the report (JSON and PDF), the receipts, manifest.json and signature.json
+func (h *ReportArchiveHandler) DownloadArchive(c *gin.Context) {
+	trip, content, err := h.service.OpenArchive(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTripNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		case errors.Is(err, domain.ErrTripNotApproved):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open report archive"})
+		}
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Disposition", "attachment; filename=\"trip-"+trip.ID.String()+".zip\"")
+	c.DataFromReader(http.StatusOK, trip.ArchiveSize, "application/zip", content, nil)
+}
+
+// PublicKey handles GET /archives/public-key
+// Auditors can check archive signatures with this key without calling the API again
+func (h *ReportArchiveHandler) PublicKey(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.service.PublicKey(),
+	})
+}
+
+// VerifyArchive handles POST /archives/verify
+// The archive is uploaded in the "file" form field; the response says whether it is intact,
+// signed by this server and the one recorded when its trip was approved
+func (h *ReportArchiveHandler) VerifyArchive(c *gin.Context) {
+	// Step 1: Read the uploaded archive
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxArchiveUploadBytes)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "An archive must be uploaded in the \"file\" form field",
+			"details": err.Error(),
+		})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded archive"})
+		return
+	}
+	defer file.Close()
+
+	// Step 2: Verify it
+	result, err := h.service.Verify(c.Request.Context(), file, fileHeader.Size)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidArchive) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify archive"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
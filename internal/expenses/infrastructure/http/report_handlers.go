@@ -0,0 +1,243 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for spending reports
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"fmt"      // For building the download file name
+	"log"      // For logging errors after the response has started
+	"net/http" // Go's built-in HTTP package for status codes
+	"strconv"  // For parsing pagination and boolean parameters
+	"time"     // For parsing ?as_of=
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+	"myexpenses/internal/report"               // Shared report renderers
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// maxReportPageSize caps page_size so a single request can't ask for an unbounded page
+const maxReportPageSize = 1000
+
+// ReportHandler handles HTTP requests for reports
+type ReportHandler struct {
+	service *application.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(service *application.ReportService) *ReportHandler {
+	return &ReportHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CompareReport handles GET /reports/compare?period_a=&period_b=
+// Periods can be a year, a month, a day or a day range (e.g. 2025-01-01..2025-03-31)
+// ?as_of=<RFC 3339 time> rebuilds it from the expenses as they were then
+// Like every report it accepts ?format=json|csv|pdf|html and ?page=&page_size=
+func (h *ReportHandler) CompareReport(c *gin.Context) {
+	renderer, ok := reportRenderer(c)
+	if !ok {
+		return
+	}
+
+	periodA, periodB := c.Query("period_a"), c.Query("period_b")
+	if periodA == "" || periodB == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "period_a and period_b are required",
+		})
+		return
+	}
+
+	// Admins can ask for the query plans instead of the report (?explain=true)
+	explain, ok := explainRequested(c)
+	if !ok {
+		return
+	}
+	if explain {
+		plans, err := h.service.ExplainCompare(c.Request.Context(), periodA, periodB)
+		if err != nil {
+			explainError(c, err)
+			return
+		}
+		respondWithPlans(c, plans)
+		return
+	}
+
+	// ?rollup=true adds subcategory spend to the top-level categories (Coffee counts as Food)
+	rollup := false
+	if value := c.Query("rollup"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "rollup must be true or false"})
+			return
+		}
+		rollup = parsed
+	}
+	asOf, ok := asOfParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Compare(c.Request.Context(), periodA, periodB, rollup, asOf)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPeriod) || errors.Is(err, domain.ErrRollupUnavailable) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if respondReportHistoryError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build comparison report"})
+		return
+	}
+
+	renderReport(c, renderer, "spending-comparison", result.Document())
+}
+
+// FlagReport handles GET /reports/flags?period=
+// It counts the expenses carrying each review flag in the period
+// Like every report it accepts ?format=json|csv|pdf|html and ?page=&page_size=
+func (h *ReportHandler) FlagReport(c *gin.Context) {
+	renderer, ok := reportRenderer(c)
+	if !ok {
+		return
+	}
+
+	period := c.Query("period")
+	if period == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "period is required",
+		})
+		return
+	}
+
+	result, err := h.service.Flags(c.Request.Context(), period)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPeriod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build flag report"})
+		return
+	}
+
+	renderReport(c, renderer, "flagged-expenses", result.Document())
+}
+
+// TaxReport handles GET /reports/tax?period=
+// It sums gross, net and VAT per VAT rate, in the base currency
+// ?as_of=<RFC 3339 time> rebuilds it from the expenses as they were then, e.g. to reproduce
+// the figures handed to an accountant
+// Like every report it accepts ?format=json|csv|pdf|html and ?page=&page_size=
+func (h *ReportHandler) TaxReport(c *gin.Context) {
+	renderer, ok := reportRenderer(c)
+	if !ok {
+		return
+	}
+
+	period := c.Query("period")
+	if period == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "period is required",
+		})
+		return
+	}
+
+	asOf, ok := asOfParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Tax(c.Request.Context(), period, asOf)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPeriod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if respondReportHistoryError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build tax report"})
+		return
+	}
+
+	renderReport(c, renderer, "vat", result.Document())
+}
+
+// asOfParam parses the optional ?as_of= of a report, the zero time if there is none
+// It writes a 400 response and returns false when it isn't an RFC 3339 time
+func asOfParam(c *gin.Context) (time.Time, bool) {
+	value := c.Query("as_of")
+	if value == "" {
+		return time.Time{}, true
+	}
+	asOf, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "as_of must be an RFC 3339 time, e.g. 2024-03-01T12:00:00Z",
+		})
+		return time.Time{}, false
+	}
+	return asOf, true
+}
+
+// respondReportHistoryError writes the response for errors of reports as of a past time
+// It returns false for other errors
+func respondReportHistoryError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrReportHistoryUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrReportHistoryNotRecorded):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
+
+// reportRenderer resolves the ?format= query parameter
+// It writes a 400 response and returns false when the format is unknown,
+// so the report isn't computed for nothing
+func reportRenderer(c *gin.Context) (report.Renderer, bool) {
+	renderer, err := report.Lookup(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return renderer, true
+}
+
+// renderReport paginates doc according to ?page= and ?page_size=, applies the tenant branding
+// (see UseBranding) and streams it with renderer
+// CSV and PDF are sent as downloads named after the report
+func renderReport(c *gin.Context, renderer report.Renderer, name string, doc *report.Document) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+	if pageSize > maxReportPageSize {
+		pageSize = maxReportPageSize
+	}
+	doc = report.Paginate(doc, page, pageSize)
+
+	// Label the report with the tenant's branding; a report without it is still worth sending
+	if value, ok := c.Get(brandingKey); ok {
+		branded, err := value.(*application.BrandingService).Brand(c.Request.Context(), doc)
+		if err != nil {
+			log.Printf("failed to brand %s report: %v", name, err)
+		} else {
+			doc = branded
+		}
+	}
+
+	c.Header("Content-Type", renderer.ContentType())
+	if ext := renderer.Extension(); ext == "csv" || ext == "pdf" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+ext))
+	}
+	c.Status(http.StatusOK)
+
+	// The status line is already sent, so a failure half-way can only be logged
+	if err := renderer.Render(c.Writer, doc); err != nil {
+		log.Printf("failed to render %s report %s: %v", renderer.Extension(), name, err)
+	}
+}
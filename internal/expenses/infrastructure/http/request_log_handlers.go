@@ -0,0 +1,259 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the middleware that logs sampled requests and the admin handlers that show them
+package http
+
+import (
+	"bytes"    // For capturing bodies
+	"errors"   // For matching domain errors through wrapped errors
+	"fmt"      // For describing bodies that aren't kept
+	"io"       // For capturing request bodies as they are read
+	"log"      // For reporting logs that couldn't be saved
+	"mime"     // For telling JSON bodies from others
+	"net/http" // Go's built-in HTTP package for status codes
+	"strconv"  // For parsing filters
+	"strings"  // For matching exempt paths
+	"time"     // For timing requests
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+	"github.com/google/uuid"   // For filtering by user
+)
+
+// RequestLogHandler handles HTTP requests for request logs
+type RequestLogHandler struct {
+	service *application.RequestLogService
+}
+
+// NewRequestLogHandler creates a new request log handler
+func NewRequestLogHandler(service *application.RequestLogService) *RequestLogHandler {
+	return &RequestLogHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// LogRequests returns middleware that logs the requests the service picks (a sample, and every
+// request of flagged users) with their responses, the first maxBody bytes of each body at most
+// It runs after Authenticate so it knows the user. Routes under exempt (e.g. the request logs
+// themselves) are never logged. Saving a log happens after the response was written, and a
+// failure only loses the log
+func LogRequests(service *application.RequestLogService, maxBody int, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		for _, prefix := range exempt {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				c.Next()
+				return
+			}
+		}
+		reason := service.Reason(c.Request.Context())
+		if reason == "" {
+			c.Next()
+			return
+		}
+
+		// Step 1: Capture the bodies as the handler reads and writes them
+		request := &bodyCapture{limit: maxBody}
+		if c.Request.Body != nil {
+			c.Request.Body = &capturingBody{ReadCloser: c.Request.Body, capture: request}
+		}
+		response := &capturingWriter{ResponseWriter: c.Writer, capture: &bodyCapture{limit: maxBody}}
+		c.Writer = response
+		start := time.Now()
+
+		c.Next()
+
+		// Step 2: Keep what can be kept, with personal data redacted
+		entry := &domain.RequestLog{
+			Reason:         reason,
+			Method:         c.Request.Method,
+			Route:          c.FullPath(),
+			Path:           path,
+			Query:          domain.RedactQuery(c.Request.URL.RawQuery),
+			RequestHeaders: domain.RedactHeaders(c.Request.Header),
+			RequestBody:    request.describe(c.Request.Header.Get("Content-Type")),
+			Status:         response.Status(),
+			ResponseBody:   response.capture.describe(response.Header().Get("Content-Type")),
+			Truncated:      request.truncated || response.capture.truncated,
+			DurationMS:     time.Since(start).Milliseconds(),
+		}
+		if err := service.Record(c.Request.Context(), entry); err != nil {
+			log.Printf("failed to save request log: %v", err)
+		}
+	}
+}
+
+// bodyCapture keeps the first limit bytes of a body
+type bodyCapture struct {
+	buf       bytes.Buffer
+	limit     int
+	size      int
+	truncated bool
+}
+
+// write keeps as much of p as fits
+func (b *bodyCapture) write(p []byte) {
+	b.size += len(p)
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			p = p[:room]
+		}
+		b.buf.Write(p)
+	}
+	if b.size > b.limit {
+		b.truncated = true
+	}
+}
+
+// describe returns what is logged of a body of contentType: the redacted body if it is
+// complete JSON, otherwise only its type and size
+func (b *bodyCapture) describe(contentType string) string {
+	if b.size == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !b.truncated && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return domain.RedactJSON(b.buf.Bytes())
+	}
+	if mediaType == "" {
+		mediaType = "unknown type"
+	}
+	return fmt.Sprintf("[%d bytes of %s]", b.size, mediaType)
+}
+
+// capturingBody copies a request body into a capture as the handler reads it
+type capturingBody struct {
+	io.ReadCloser
+	capture *bodyCapture
+}
+
+// Read reads from the body and captures what was read
+func (r *capturingBody) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.capture.write(p[:n])
+	return n, err
+}
+
+// capturingWriter copies a response body into a capture as the handler writes it
+type capturingWriter struct {
+	gin.ResponseWriter
+	capture *bodyCapture
+}
+
+// Write writes the response and captures it
+func (w *capturingWriter) Write(p []byte) (int, error) {
+	w.capture.write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// WriteString writes the response and captures it
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.capture.write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// ListRequestLogs handles GET /admin/request-logs?user_id=&path=&status=&limit=
+// It returns the kept requests, newest first
+func (h *RequestLogHandler) ListRequestLogs(c *gin.Context) {
+	filter := domain.RequestLogFilter{Path: c.Query("path")}
+	if value := c.Query("user_id"); value != "" {
+		userID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id must be a UUID"})
+			return
+		}
+		filter.UserID = &userID
+	}
+	if value := c.Query("status"); value != "" {
+		status, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be a number"})
+			return
+		}
+		filter.Status = status
+	}
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	logs, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		respondRequestLogError(c, err, "Failed to list request logs")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":  logs,
+		"count": len(logs),
+	})
+}
+
+// GetRequestLog handles GET /admin/request-logs/{id}
+func (h *RequestLogHandler) GetRequestLog(c *gin.Context) {
+	entry, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondRequestLogError(c, err, "Failed to get request log")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": entry})
+}
+
+// ListRequestLogTargets handles GET /admin/request-logs/users
+// It lists the users whose every request is being logged, and until when
+func (h *RequestLogHandler) ListRequestLogTargets(c *gin.Context) {
+	targets, err := h.service.ListTargets(c.Request.Context())
+	if err != nil {
+		respondRequestLogError(c, err, "Failed to list flagged users")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":  targets,
+		"count": len(targets),
+	})
+}
+
+// FlagRequestLogTarget handles PUT /admin/request-logs/users/{userId}
+// Every request of the user is logged for the next {"hours": n} hours, a week at most
+func (h *RequestLogHandler) FlagRequestLogTarget(c *gin.Context) {
+	var req application.RequestLogTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	target, err := h.service.Flag(c.Request.Context(), c.Param("userId"), &req)
+	if err != nil {
+		respondRequestLogError(c, err, "Failed to flag user")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User's requests are being logged",
+		"data":    target,
+	})
+}
+
+// UnflagRequestLogTarget handles DELETE /admin/request-logs/users/{userId}
+// The logs kept so far stay until they expire
+func (h *RequestLogHandler) UnflagRequestLogTarget(c *gin.Context) {
+	if err := h.service.Unflag(c.Request.Context(), c.Param("userId")); err != nil {
+		respondRequestLogError(c, err, "Failed to unflag user")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User's requests are no longer logged"})
+}
+
+// respondRequestLogError maps request log errors to HTTP responses
+func respondRequestLogError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "request logs require the admin role"})
+	case errors.Is(err, domain.ErrRequestLogNotFound), errors.Is(err, domain.ErrRequestLogTargetNotFound),
+		errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidRequestLogTarget):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
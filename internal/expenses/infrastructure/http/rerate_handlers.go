@@ -0,0 +1,98 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the admin handlers for re-rating expenses and reading the audit log
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+	"strconv"  // For parsing the limit
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// RerateHandler handles HTTP requests for re-rating and the audit log
+type RerateHandler struct {
+	rerate *application.RerateService
+	audit  *application.AuditService
+}
+
+// NewRerateHandler creates a new re-rating handler
+func NewRerateHandler(rerate *application.RerateService, audit *application.AuditService) *RerateHandler {
+	return &RerateHandler{
+		rerate: rerate, // Store the service dependencies
+		audit:  audit,
+	}
+}
+
+// Rerate handles POST /admin/rerate
+// With "dry_run": true it only returns the changes it would make
+func (h *RerateHandler) Rerate(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "re-rating requires an admin token"})
+		return
+	}
+
+	var req application.RerateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.rerate.Rerate(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRerate) || errors.Is(err, domain.ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-rate expenses", "details": err.Error()})
+		return
+	}
+
+	message := "Expenses re-rated successfully"
+	if result.DryRun {
+		message = "Dry run: no expenses were changed"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    result,
+	})
+}
+
+// AuditLog handles GET /admin/audit-log?action=&entity_type=&entity_id=&limit=
+func (h *RerateHandler) AuditLog(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "the audit log requires an admin token"})
+		return
+	}
+
+	filter := domain.AuditFilter{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.audit.ListEntries(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list audit entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  entries,
+		"count": len(entries),
+	})
+}
@@ -4,6 +4,8 @@
 package http
 
 import (
+	"myexpenses/internal/auth"                 // For signing calendar feed URLs
+	"myexpenses/internal/clock"                // Time source for export link expiry
 	"myexpenses/internal/expenses/application" // Import our application layer
 
 	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
@@ -34,8 +36,17 @@ func SetupRoutes(router *gin.Engine, service *application.Service) {
 		// GET /expenses/{id} - Get a specific expense by ID
 		// The {id} is a URL parameter that gets passed to the handler
 		// For example, GET /expenses/123e4567-e89b-12d3-a456-426614174000
+		// ?as_of=<RFC 3339 time> returns the expense as it was then (needs the event store)
 		expenses.GET("/:id", handler.GetExpense)
 
+		// GET /expenses/{id}/events - Every recorded change of an expense (needs the event store)
+		expenses.GET("/:id/events", handler.ListExpenseEvents)
+
+		// POST /expenses/{id}/refunds - Record money given back on an expense
+		// GET /expenses/{id}/refunds - The expense with its refunds and its net cost
+		expenses.POST("/:id/refunds", handler.CreateRefund)
+		expenses.GET("/:id/refunds", handler.ListRefunds)
+
 		// PUT /expenses/{id} - Update an existing expense
 		// This route accepts JSON data in the request body and updates the specified expense
 		expenses.PUT("/:id", handler.UpdateExpense)
@@ -53,3 +64,787 @@ func SetupRoutes(router *gin.Engine, service *application.Service) {
 	// - URLs use nouns (expenses) not verbs
 	// - HTTP status codes indicate the result (200 OK, 201 Created, 404 Not Found, etc.)
 }
+
+// SetupAttachmentRoutes configures the receipt attachment and search routes
+// Attachments are a sub-resource of an expense: /expenses/{id}/attachments
+func SetupAttachmentRoutes(router *gin.Engine, attachments *application.AttachmentService, search *application.SearchService) {
+	attachmentHandler := NewAttachmentHandler(attachments)
+	searchHandler := NewSearchHandler(search)
+
+	expenses := router.Group("/expenses")
+	{
+		// GET /expenses/search?q=milk&scope=receipts - Full-text search
+		// Static segments take precedence over the /:id parameter in Gin
+		expenses.GET("/search", searchHandler.SearchExpenses)
+
+		// Attachment sub-resource routes
+		expenses.POST("/:id/attachments", attachmentHandler.UploadAttachment)
+		expenses.GET("/:id/attachments", attachmentHandler.ListAttachments)
+		expenses.GET("/:id/attachments/:attachmentId", attachmentHandler.DownloadAttachment)
+		expenses.POST("/:id/attachments/:attachmentId/reindex", attachmentHandler.ReindexAttachment)
+		expenses.DELETE("/:id/attachments/:attachmentId", attachmentHandler.DeleteAttachment)
+	}
+}
+
+// SetupReceiptRoutes configures the bulk receipt upload and the receipt inbox
+func SetupReceiptRoutes(router *gin.Engine, service *application.ReceiptService) {
+	handler := NewReceiptHandler(service)
+
+	receipts := router.Group("/receipts")
+	{
+		receipts.POST("/bulk", handler.UploadReceipts)
+		receipts.GET("/pending", handler.ListPendingReceipts)
+		receipts.POST("/pending/:id/match", handler.MatchPendingReceipt)
+		receipts.DELETE("/pending/:id", handler.DiscardPendingReceipt)
+	}
+}
+
+// SetupReconciliationRoutes configures the statement reconciliation workspace
+func SetupReconciliationRoutes(router *gin.Engine, service *application.ReconciliationService) {
+	handler := NewReconciliationHandler(service)
+
+	reconciliations := router.Group("/reconciliations")
+	{
+		reconciliations.GET("", handler.ListReconciliations)
+		reconciliations.POST("", handler.StartReconciliation)
+		reconciliations.GET("/:id", handler.GetReconciliation)
+		reconciliations.POST("/:id/auto-match", handler.AutoMatchReconciliation)
+		reconciliations.PUT("/:id/lines/:lineId/match", handler.MatchStatementLine)
+		reconciliations.DELETE("/:id/lines/:lineId/match", handler.UnmatchStatementLine)
+		reconciliations.POST("/:id/close", handler.CloseReconciliation)
+	}
+}
+
+// SetupTripRoutes configures trips, their expenses and the trip report
+func SetupTripRoutes(router *gin.Engine, service *application.TripService) {
+	handler := NewTripHandler(service)
+
+	trips := router.Group("/trips")
+	{
+		trips.GET("", handler.ListTrips)
+		trips.POST("", handler.CreateTrip)
+		trips.GET("/:id", handler.GetTrip)
+		trips.POST("/:id/expenses", handler.AssignTripExpenses)
+		trips.DELETE("/:id/expenses/:expenseId", handler.RemoveTripExpense)
+		trips.GET("/:id/report", handler.TripReport)
+	}
+}
+
+// SetupPublicFormRoutes configures public submission forms and the staging area
+// /forms and /staging are for the owner; /public/forms/{token} is what the form link opens,
+// where the token alone grants access
+func SetupPublicFormRoutes(router *gin.Engine, service *application.PublicFormService) {
+	handler := NewPublicFormHandler(service)
+
+	forms := router.Group("/forms")
+	{
+		forms.GET("", handler.ListForms)
+		forms.POST("", handler.CreateForm)
+		forms.DELETE("/:id", handler.RevokeForm)
+	}
+
+	staging := router.Group("/staging")
+	{
+		staging.GET("", handler.ListStaged)
+		staging.PUT("/:id", handler.CodeStaged)
+		staging.POST("/:id/approve", handler.ApproveStaged)
+		staging.POST("/:id/reject", handler.RejectStaged)
+	}
+
+	public := router.Group("/public/forms")
+	{
+		public.GET("/:token", handler.GetPublicForm)
+		public.POST("/:token/expenses", handler.SubmitPublicExpense)
+	}
+}
+
+// SetupIntegrationRoutes configures the trigger and action endpoints for no-code platforms
+// Every route requires a user API key and acts as the key's user
+func SetupIntegrationRoutes(router *gin.Engine, service *application.IntegrationService) {
+	handler := NewIntegrationHandler(service)
+
+	integrations := router.Group("/integrations", RequireIntegrationKey())
+	{
+		integrations.GET("/me", handler.TestAuth)
+		integrations.GET("/triggers/new-expenses", handler.NewExpensesTrigger)
+		integrations.POST("/actions/create-expense", handler.CreateExpenseAction)
+		integrations.POST("/actions/flag-expense", handler.FlagExpenseAction)
+	}
+}
+
+// SetupFlagRoutes configures the review flag routes
+// Flags are a sub-resource of an expense: /expenses/{id}/flags/{flag}
+func SetupFlagRoutes(router *gin.Engine, service *application.FlagService) {
+	handler := NewFlagHandler(service)
+
+	expenses := router.Group("/expenses")
+	{
+		expenses.GET("/:id/flags", handler.ListFlags)
+		expenses.PUT("/:id/flags/:flag", handler.SetFlag)
+		expenses.DELETE("/:id/flags/:flag", handler.RemoveFlag)
+	}
+}
+
+// SetupCategorizationRoutes configures transaction imports and the auto-categorization settings
+func SetupCategorizationRoutes(router *gin.Engine, imports *application.ImportService, categorization *application.CategorizationService) {
+	handler := NewCategorizationHandler(imports, categorization)
+
+	// POST /imports/transactions - Import bank/card transactions as expenses
+	router.POST("/imports/transactions", handler.ImportTransactions)
+
+	// MCC -> category mapping table management
+	mccMappings := router.Group("/mcc-mappings")
+	{
+		mccMappings.GET("", handler.ListMCCMappings)
+		mccMappings.PUT("/:mcc", handler.SetMCCMapping)
+		mccMappings.DELETE("/:mcc", handler.DeleteMCCMapping)
+	}
+
+	// Keyword rules used when the MCC table doesn't recognize a transaction
+	rules := router.Group("/rules")
+	{
+		rules.GET("", handler.ListRules)
+		rules.POST("", handler.CreateRule)
+		rules.POST("/test", handler.TestRules)
+		rules.DELETE("/:id", handler.DeleteRule)
+	}
+}
+
+// SetupNormalizationRoutes configures the normalization rule management and autocomplete routes
+func SetupNormalizationRoutes(router *gin.Engine, service *application.NormalizationService) {
+	handler := NewNormalizationHandler(service)
+
+	// GET /expenses/autocomplete?q=caf - Suggest descriptions from past expenses
+	router.GET("/expenses/autocomplete", handler.Autocomplete)
+
+	// User-defined replacement rules applied on import
+	rules := router.Group("/normalization-rules")
+	{
+		rules.GET("", handler.ListRules)
+		rules.POST("", handler.CreateRule)
+		rules.POST("/preview", handler.Preview)
+		rules.DELETE("/:id", handler.DeleteRule)
+	}
+}
+
+// SetupAccountRoutes configures the account and cash wallet routes
+func SetupAccountRoutes(router *gin.Engine, service *application.AccountService) {
+	handler := NewAccountHandler(service)
+
+	accounts := router.Group("/accounts")
+	{
+		accounts.POST("", handler.CreateAccount)
+		accounts.GET("", handler.ListAccounts)
+		accounts.GET("/:id", handler.GetAccountBalance)
+		accounts.PUT("/:id", handler.UpdateAccount)
+		accounts.DELETE("/:id", handler.DeleteAccount)
+		accounts.GET("/:id/balance", handler.GetAccountBalance)
+		accounts.GET("/:id/ledger", handler.GetAccountLedger)
+	}
+
+	// Cash wallet: ATM withdrawals in, cash expenses out
+	cash := router.Group("/cash")
+	{
+		cash.GET("", handler.GetCashSummary)
+		cash.POST("/withdrawals", handler.WithdrawCash)
+	}
+}
+
+// SetupBudgetRoutes configures the budget routes
+func SetupBudgetRoutes(router *gin.Engine, service *application.BudgetService, groups *application.GroupService) {
+	handler := NewBudgetHandler(service, groups)
+
+	budgets := router.Group("/budgets")
+	{
+		budgets.POST("", handler.CreateBudget)
+		budgets.GET("", handler.ListBudgets)
+		budgets.GET("/status", handler.BudgetStatus)
+		budgets.PUT("/:id", handler.UpdateBudget)
+		budgets.DELETE("/:id", handler.DeleteBudget)
+
+		// What-if planning: forecasts hypothetical changes without saving them
+		budgets.POST("/simulate", handler.SimulateBudget)
+
+		// Whole configurations: export, import and built-in templates
+		budgets.GET("/export", handler.ExportBudgets)
+		budgets.POST("/import", handler.ImportBudgets)
+		budgets.GET("/templates", handler.ListBudgetTemplates)
+		budgets.POST("/from-template", handler.BudgetsFromTemplate)
+	}
+}
+
+// SetupGroupRoutes configures groups, their members and their shared budgets
+// The status of the shared budgets is served by GET /budgets/status?group_id=
+func SetupGroupRoutes(router *gin.Engine, service *application.GroupService) {
+	handler := NewGroupHandler(service)
+
+	groups := router.Group("/groups")
+	{
+		groups.GET("", handler.ListGroups)
+		groups.POST("", handler.CreateGroup)
+		groups.GET("/:id", handler.GetGroup)
+		groups.POST("/:id/members", handler.AddGroupMember)
+		groups.DELETE("/:id/members/:memberId", handler.RemoveGroupMember)
+		groups.POST("/:id/members/:memberId/expenses", handler.AttributeMemberExpenses)
+		groups.GET("/:id/budgets", handler.ListGroupBudgets)
+		groups.PUT("/:id/budgets", handler.SaveGroupBudget)
+		groups.DELETE("/:id/budgets/:budgetId", handler.DeleteGroupBudget)
+	}
+}
+
+// SetupReportRoutes configures the report routes
+func SetupReportRoutes(router *gin.Engine, service *application.ReportService) {
+	handler := NewReportHandler(service)
+
+	reports := router.Group("/reports")
+	{
+		// ?as_of=<RFC 3339 time> rebuilds the comparison and tax reports as the expenses were then
+		reports.GET("/compare", handler.CompareReport)
+		reports.GET("/flags", handler.FlagReport)
+		reports.GET("/tax", handler.TaxReport)
+	}
+}
+
+// SetupAdminRoutes configures the administrative routes
+func SetupAdminRoutes(router *gin.Engine, integrity *application.IntegrityService, provisioning *application.ProvisioningService, indexStats *application.IndexStatsService) {
+	handler := NewAdminHandler(integrity, provisioning, indexStats)
+
+	admin := router.Group("/admin")
+	{
+		admin.POST("/integrity-check", handler.IntegrityCheck)
+		admin.POST("/provision", handler.Provision)
+		admin.GET("/index-stats", handler.IndexStats)
+	}
+}
+
+// SetupCategoryRoutes configures the category list routes
+func SetupCategoryRoutes(router *gin.Engine, service *application.CategoryService) {
+	handler := NewCategoryHandler(service)
+
+	categories := router.Group("/categories")
+	{
+		categories.GET("", handler.ListCategories)
+		categories.POST("", handler.CreateCategory)
+		categories.PUT("/:id", handler.UpdateCategory)
+		categories.POST("/:id/archive", handler.ArchiveCategory)
+		categories.POST("/:id/restore", handler.RestoreCategory)
+		categories.DELETE("/:id", handler.DeleteCategory)
+	}
+}
+
+// SetupCalendarRoutes configures recurring expenses and the calendar feed
+// /calendar/feed.ics is opened by calendar apps without logging in; its signature grants access
+func SetupCalendarRoutes(router *gin.Engine, service *application.CalendarService, signer *auth.URLSigner) {
+	handler := NewCalendarHandler(service, signer)
+
+	recurring := router.Group("/recurring-expenses")
+	{
+		recurring.GET("", handler.ListRecurringExpenses)
+		recurring.POST("", handler.CreateRecurringExpense)
+		recurring.DELETE("/:id", handler.DeleteRecurringExpense)
+	}
+
+	feed := router.Group("/calendar")
+	{
+		feed.GET("/url", handler.FeedURL)
+		feed.GET("/feed.ics", handler.Feed)
+	}
+}
+
+// SetupDashboardRoutes configures the dashboard endpoint
+func SetupDashboardRoutes(router *gin.Engine, service *application.DashboardService) {
+	handler := NewDashboardHandler(service)
+
+	router.GET("/dashboard", handler.GetDashboard)
+}
+
+// SetupExportRoutes configures expense exports
+// /exports/{id}/download is opened without logging in; its signature grants access
+func SetupExportRoutes(router *gin.Engine, service *application.ExportService, signer *auth.URLSigner, clk clock.Clock) {
+	handler := NewExportHandler(service, signer, clk)
+
+	exports := router.Group("/exports")
+	{
+		exports.GET("", handler.ListExports)
+		exports.POST("", handler.CreateExport)
+		exports.GET("/:id", handler.GetExport)
+		exports.GET("/:id/download", handler.DownloadExport)
+	}
+}
+
+// SetupMetaRoutes configures the endpoints that describe the deployment
+// features lists the optional features that are switched on, by name
+func SetupMetaRoutes(router *gin.Engine, limits application.Limits, features map[string]bool, capabilities *application.CapabilityService) {
+	handler := NewMetaHandler(limits, features, capabilities)
+
+	router.GET("/version", handler.GetVersion)
+
+	meta := router.Group("/meta")
+	{
+		meta.GET("/limits", handler.GetLimits)
+		meta.GET("/capabilities", handler.GetCapabilities)
+	}
+}
+
+// SetupUserRoutes configures registration, login, token refresh, logout and the current user
+func SetupUserRoutes(router *gin.Engine, service *application.UserService) {
+	handler := NewUserHandler(service)
+
+	users := router.Group("/auth")
+	{
+		users.POST("/register", handler.Register)
+		users.POST("/login", handler.Login)
+		users.POST("/refresh", handler.Refresh)
+		users.POST("/logout", handler.Logout)
+		users.GET("/me", handler.CurrentUser)
+		users.GET("/sessions", handler.ListSessions)
+		users.DELETE("/sessions/:id", handler.RevokeSession)
+	}
+}
+
+// SetupDonationRoutes configures donations, a sub-resource of an expense, and the giving report
+func SetupDonationRoutes(router *gin.Engine, service *application.DonationService) {
+	handler := NewDonationHandler(service)
+
+	expenses := router.Group("/expenses")
+	{
+		expenses.GET("/:id/donation", handler.GetDonation)
+		expenses.PUT("/:id/donation", handler.MarkDonation)
+		expenses.DELETE("/:id/donation", handler.UnmarkDonation)
+	}
+
+	router.GET("/reports/giving", handler.GivingReport)
+}
+
+// SetupErasureRoutes configures erasing the caller's account
+func SetupErasureRoutes(router *gin.Engine, service *application.ErasureService) {
+	handler := NewErasureHandler(service)
+
+	me := router.Group("/auth/me")
+	{
+		me.DELETE("", handler.ScheduleErasure)
+		me.POST("/erasure/cancel", handler.CancelErasure)
+	}
+}
+
+// SetupBrandingRoutes configures reading and managing the tenant branding
+func SetupBrandingRoutes(router *gin.Engine, service *application.BrandingService) {
+	handler := NewBrandingHandler(service)
+
+	router.GET("/branding", handler.GetBranding)
+
+	admin := router.Group("/admin")
+	{
+		admin.PUT("/branding", handler.UpdateBranding)
+		admin.DELETE("/branding", handler.ResetBranding)
+	}
+}
+
+// SetupAPIKeyRoutes configures the management of the caller's API keys
+func SetupAPIKeyRoutes(router *gin.Engine, service *application.APIKeyService) {
+	handler := NewAPIKeyHandler(service)
+
+	keys := router.Group("/api-keys")
+	{
+		keys.POST("", handler.CreateAPIKey)
+		keys.GET("", handler.ListAPIKeys)
+		keys.GET("/:id", handler.GetAPIKey)
+		keys.PUT("/:id", handler.UpdateAPIKey)
+		keys.DELETE("/:id", handler.DeleteAPIKey)
+	}
+}
+
+// SetupRerateRoutes configures re-rating expenses after exchange rate corrections and the audit log
+func SetupRerateRoutes(router *gin.Engine, rerate *application.RerateService, audit *application.AuditService) {
+	handler := NewRerateHandler(rerate, audit)
+
+	admin := router.Group("/admin")
+	{
+		admin.POST("/rerate", handler.Rerate)
+		admin.GET("/audit-log", handler.AuditLog)
+	}
+}
+
+// SetupReceivableRoutes configures the routes for money owed to the user
+func SetupReceivableRoutes(router *gin.Engine, service *application.ReceivableService) {
+	handler := NewReceivableHandler(service)
+
+	receivables := router.Group("/receivables")
+	{
+		receivables.POST("", handler.CreateReceivable)
+		receivables.GET("", handler.ListReceivables)
+		receivables.GET("/:id", handler.GetReceivable)
+		receivables.POST("/:id/settle", handler.SettleReceivable)
+		receivables.DELETE("/:id", handler.DeleteReceivable)
+	}
+}
+
+// SetupUserAdminRoutes configures the admin-only user management and stats routes
+// Users are listed with ?email= search and ?limit=&offset= paging
+func SetupUserAdminRoutes(router *gin.Engine, service *application.UserAdminService) {
+	handler := NewUserAdminHandler(service)
+
+	admin := router.Group("/admin")
+	{
+		admin.GET("/users", handler.ListUsers)
+		admin.PUT("/users/:id/role", handler.SetUserRole)
+		admin.POST("/users/:id/disable", handler.DisableUser)
+		admin.POST("/users/:id/enable", handler.EnableUser)
+		admin.POST("/users/:id/reset-password", handler.ResetPassword)
+		admin.DELETE("/users/:id", handler.DeleteUser)
+		admin.GET("/stats", handler.Stats)
+	}
+}
+
+// SetupLoanRoutes configures the loan routes
+func SetupLoanRoutes(router *gin.Engine, service *application.LoanService) {
+	handler := NewLoanHandler(service)
+
+	loans := router.Group("/loans")
+	{
+		loans.POST("", handler.CreateLoan)
+		loans.GET("", handler.ListLoans)
+		loans.GET("/:id", handler.GetLoan)
+		loans.DELETE("/:id", handler.DeleteLoan)
+		loans.POST("/:id/payments", handler.AssignLoanPayments)
+		loans.DELETE("/:id/payments/:expenseId", handler.RemoveLoanPayment)
+		loans.GET("/:id/amortization", handler.LoanAmortization)
+	}
+}
+
+// SetupReportArchiveRoutes configures the signed archives of approved trip reports
+// Downloading sits next to the trip routes; /archives is about archives in general
+func SetupReportArchiveRoutes(router *gin.Engine, service *application.ReportArchiveService) {
+	handler := NewReportArchiveHandler(service)
+
+	router.GET("/trips/:id/archive", handler.DownloadArchive)
+
+	archives := router.Group("/archives")
+	{
+		archives.GET("/public-key", handler.PublicKey)
+		archives.POST("/verify", handler.VerifyArchive)
+	}
+}
+
+// SetupApprovalRoutes configures the approval chain, the approval of trip reports and delegations
+// Anyone may read the chain; changing it is for admins
+func SetupApprovalRoutes(router *gin.Engine, service *application.ApprovalService) {
+	handler := NewApprovalHandler(service)
+
+	router.GET("/approval-chain", handler.GetApprovalChain)
+	router.GET("/trips/:id/approvals", handler.TripApprovals)
+	router.POST("/trips/:id/approve", handler.ApproveTrip)
+	router.GET("/approvals/pending", handler.PendingApprovals)
+
+	delegations := router.Group("/approval-delegations")
+	{
+		delegations.POST("", handler.CreateDelegation)
+		delegations.GET("", handler.ListDelegations)
+		delegations.DELETE("/:id", handler.CancelDelegation)
+	}
+
+	admin := router.Group("/admin")
+	{
+		admin.PUT("/approval-chain", handler.UpdateApprovalChain)
+		admin.DELETE("/approval-chain", handler.ResetApprovalChain)
+	}
+}
+
+// SetupCardFeedRoutes configures the corporate card mapping and card feed imports (admin only)
+// The staged transactions are reviewed through the staging routes
+func SetupCardFeedRoutes(router *gin.Engine, service *application.CardFeedService) {
+	handler := NewCardFeedHandler(service)
+
+	admin := router.Group("/admin")
+	{
+		admin.GET("/corporate-cards", handler.ListCards)
+		admin.POST("/corporate-cards", handler.MapCard)
+		admin.DELETE("/corporate-cards/:id", handler.UnmapCard)
+		admin.POST("/card-feeds", handler.ImportFeed)
+	}
+}
+
+// SetupServiceAccountRoutes configures the OAuth 2.0 token endpoint and the admin routes for service accounts
+func SetupServiceAccountRoutes(router *gin.Engine, service *application.ServiceAccountService) {
+	handler := NewServiceAccountHandler(service)
+
+	router.POST("/auth/token", handler.IssueToken)
+
+	admin := router.Group("/admin")
+	{
+		admin.GET("/service-accounts", handler.ListAccounts)
+		admin.POST("/service-accounts", handler.CreateAccount)
+		admin.POST("/service-accounts/:id/rotate-secret", handler.RotateSecret)
+		admin.DELETE("/service-accounts/:id", handler.DeleteAccount)
+	}
+}
+
+// SetupPolicyRoutes configures the expense policy routes
+// Everyone may read the policy; changing it and the violation report are for admins
+func SetupPolicyRoutes(router *gin.Engine, service *application.PolicyService) {
+	handler := NewPolicyHandler(service)
+
+	router.GET("/policies", handler.ListRules)
+	router.GET("/reports/policy-violations", handler.ViolationReport)
+
+	admin := router.Group("/admin")
+	{
+		admin.POST("/policies", handler.CreateRule)
+		admin.PUT("/policies/:id", handler.UpdateRule)
+		admin.DELETE("/policies/:id", handler.DeleteRule)
+	}
+}
+
+// SetupDimensionRoutes configures the cost center and department routes and the chargeback report
+// Everyone may list them; managing them and the report are for admins
+func SetupDimensionRoutes(router *gin.Engine, service *application.DimensionService) {
+	handler := NewDimensionHandler(service)
+
+	router.GET("/dimensions", handler.ListDimensions)
+	router.GET("/reports/chargeback", handler.ChargebackReport)
+
+	admin := router.Group("/admin")
+	{
+		admin.POST("/dimensions", handler.CreateDimension)
+		admin.POST("/dimensions/:id/archive", handler.ArchiveDimension)
+	}
+}
+
+// SetupPlannedPurchaseRoutes configures the planned purchase routes and the estimate accuracy report
+func SetupPlannedPurchaseRoutes(router *gin.Engine, service *application.PlannedPurchaseService) {
+	handler := NewPlannedPurchaseHandler(service)
+
+	purchases := router.Group("/planned-purchases")
+	{
+		purchases.POST("", handler.CreatePlannedPurchase)
+		purchases.GET("", handler.ListPlannedPurchases)
+		purchases.GET("/:id", handler.GetPlannedPurchase)
+		purchases.DELETE("/:id", handler.DeletePlannedPurchase)
+		purchases.POST("/:id/link", handler.LinkExpense)
+		purchases.DELETE("/:id/link", handler.UnlinkExpense)
+	}
+
+	router.GET("/reports/estimate-accuracy", handler.AccuracyReport)
+}
+
+// SetupEmployerRoutes configures the routes for employers and what they owe for reimbursable expenses
+func SetupEmployerRoutes(router *gin.Engine, service *application.EmployerService) {
+	handler := NewEmployerHandler(service)
+
+	employers := router.Group("/employers")
+	{
+		employers.POST("", handler.CreateEmployer)
+		employers.GET("", handler.ListEmployers)
+		employers.GET("/balances", handler.Balances)
+		employers.DELETE("/:id", handler.DeleteEmployer)
+		employers.GET("/:id/balance", handler.Balance)
+		employers.POST("/:id/reimbursements", handler.RecordReimbursement)
+		employers.GET("/:id/reimbursements", handler.ListReimbursements)
+		employers.DELETE("/:id/reimbursements/:reimbursementId", handler.DeleteReimbursement)
+	}
+}
+
+// SetupBookRoutes configures the routes for books and their archives
+func SetupBookRoutes(router *gin.Engine, service *application.BookService, archives *application.BookArchiveService) {
+	handler := NewBookHandler(service)
+	archiveHandler := NewBookArchiveHandler(archives)
+
+	books := router.Group("/books")
+	{
+		books.POST("", handler.CreateBook)
+		books.GET("", handler.ListBooks)
+		books.GET("/export", archiveHandler.ExportBook)
+		books.POST("/import", archiveHandler.ImportBook)
+		books.DELETE("/:id", handler.DeleteBook)
+	}
+}
+
+// SetupTelemetryRoutes configures the route that shows the telemetry report
+func SetupTelemetryRoutes(router *gin.Engine, service *application.TelemetryService) {
+	handler := NewTelemetryHandler(service)
+
+	router.GET("/admin/telemetry", handler.GetTelemetry)
+}
+
+// SetupTagRoutes configures the tag autocomplete route
+func SetupTagRoutes(router *gin.Engine, service *application.TagService) {
+	handler := NewTagHandler(service)
+
+	// GET /tags/suggest?q=tra - Suggest the caller's tags for a typed prefix
+	router.GET("/tags/suggest", handler.SuggestTags)
+}
+
+// SetupCustomReportRoutes configures the routes of the reports extension modules provide
+func SetupCustomReportRoutes(router *gin.Engine, service *application.CustomReportService) {
+	handler := NewCustomReportHandler(service)
+
+	router.GET("/reports/custom", handler.ListCustomReports)
+	router.GET("/reports/custom/:name", handler.CustomReport)
+}
+
+// SetupHealthRoutes configures the health check
+func SetupHealthRoutes(router *gin.Engine, service *application.HealthService) {
+	handler := NewHealthHandler(service)
+
+	router.GET("/health", handler.Health)
+}
+
+// SetupReadOnlyRoutes configures the routes that show and switch read-only mode
+func SetupReadOnlyRoutes(router *gin.Engine, service *application.ReadOnlyService) {
+	handler := NewReadOnlyHandler(service)
+
+	// GET /read-only - Whether the caller's tenant is read-only, and why
+	router.GET("/read-only", handler.GetReadOnly)
+
+	admin := router.Group("/admin/read-only")
+	{
+		admin.GET("", handler.ListReadOnly)
+		admin.PUT("", handler.EnableReadOnly)
+		admin.DELETE("", handler.DisableReadOnly)
+	}
+}
+
+// SetupRequestLogRoutes configures the admin routes that show logged requests and flag users for logging
+func SetupRequestLogRoutes(router *gin.Engine, service *application.RequestLogService) {
+	handler := NewRequestLogHandler(service)
+
+	logs := router.Group("/admin/request-logs")
+	{
+		logs.GET("", handler.ListRequestLogs)
+		// Static segments take precedence over the /:id parameter in Gin
+		logs.GET("/users", handler.ListRequestLogTargets)
+		logs.PUT("/users/:userId", handler.FlagRequestLogTarget)
+		logs.DELETE("/users/:userId", handler.UnflagRequestLogTarget)
+		logs.GET("/:id", handler.GetRequestLog)
+	}
+}
+
+// SetupBillingRoutes configures the plan, subscription and webhook routes
+// signatureHeader is the request header the payment provider signs webhooks in
+func SetupBillingRoutes(router *gin.Engine, service *application.BillingService, signatureHeader string) {
+	handler := NewBillingHandler(service, signatureHeader)
+
+	billing := router.Group("/billing")
+	{
+		billing.GET("", handler.GetBilling)
+		billing.GET("/plans", handler.ListPlans)
+		billing.POST("/subscription", handler.Subscribe)
+		billing.PATCH("/subscription", handler.ChangeSubscription)
+		billing.DELETE("/subscription", handler.CancelSubscription)
+		billing.POST("/webhook", handler.Webhook)
+	}
+}
+
+// SetupCategorySuggestionRoutes configures the category suggestion route
+func SetupCategorySuggestionRoutes(router *gin.Engine, service *application.CategorySuggestionService) {
+	handler := NewCategorySuggestionHandler(service)
+
+	// GET /expenses/suggest-category?description=uber&amount=12.5 - Likely categories for a new expense
+	router.GET("/expenses/suggest-category", handler.SuggestCategory)
+}
+
+// SetupDemoDataRoutes configures the routes that generate and wipe the sample data
+func SetupDemoDataRoutes(router *gin.Engine, service *application.DemoDataService) {
+	handler := NewDemoDataHandler(service)
+
+	demo := router.Group("/admin/demo-data")
+	{
+		demo.GET("", handler.GetDemoData)
+		demo.POST("", handler.GenerateDemoData)
+		demo.POST("/wipe", handler.WipeDemoData)
+	}
+}
+
+// SetupMigrationRoutes configures the migration assistant routes
+func SetupMigrationRoutes(router *gin.Engine, service *application.MigrationService) {
+	handler := NewMigrationHandler(service)
+
+	migrations := router.Group("/migrations")
+	{
+		migrations.GET("/sources", handler.ListSources)
+		migrations.POST("/analyze", handler.Analyze)
+		migrations.POST("/commit", handler.Commit)
+	}
+}
+
+// SetupOCRRoutes configures the routes for the tenant's OCR settings and usage
+func SetupOCRRoutes(router *gin.Engine, service *application.OCRService) {
+	handler := NewOCRHandler(service)
+
+	router.GET("/ocr", handler.GetOCRSettings)
+
+	admin := router.Group("/admin/ocr")
+	{
+		admin.PUT("", handler.UpdateOCRSettings)
+		admin.DELETE("", handler.ResetOCRSettings)
+		admin.GET("/usage", handler.GetOCRUsage)
+	}
+}
+
+// SetupViewRoutes configures the routes for saved views of the expense list
+func SetupViewRoutes(router *gin.Engine, service *application.ViewService) {
+	handler := NewViewHandler(service)
+
+	views := router.Group("/views")
+	{
+		views.POST("", handler.CreateView)
+		views.GET("", handler.ListViews)
+		views.GET("/:id", handler.GetView)
+		views.PUT("/:id", handler.UpdateView)
+		views.DELETE("/:id", handler.DeleteView)
+	}
+}
+
+// SetupInsightRoutes configures the spending insight routes
+func SetupInsightRoutes(router *gin.Engine, service *application.InsightService, suggestions *application.RuleSuggestionService) {
+	handler := NewInsightHandler(service, suggestions)
+
+	insights := router.Group("/insights")
+	{
+		insights.GET("/patterns", handler.GetPatterns)
+
+		// Rules suggested from the caller's corrections of imported expenses
+		insights.GET("/rule-suggestions", handler.ListRuleSuggestions)
+		insights.POST("/rule-suggestions/accept", handler.AcceptRuleSuggestion)
+		insights.POST("/rule-suggestions/dismiss", handler.DismissRuleSuggestion)
+	}
+}
+
+// SetupCustomFieldRoutes configures the routes for the caller's custom field schema
+func SetupCustomFieldRoutes(router *gin.Engine, service *application.CustomFieldService) {
+	handler := NewCustomFieldHandler(service)
+
+	fields := router.Group("/custom-fields")
+	{
+		fields.POST("", handler.CreateCustomField)
+		fields.GET("", handler.ListCustomFields)
+		fields.PUT("/:key", handler.UpdateCustomField)
+		fields.DELETE("/:key", handler.DeleteCustomField)
+	}
+}
+
+// SetupCommentRoutes configures the comments on expenses
+func SetupCommentRoutes(router *gin.Engine, service *application.CommentService) {
+	handler := NewCommentHandler(service)
+
+	expenses := router.Group("/expenses")
+	{
+		expenses.GET("/:id/comments", handler.ListComments)
+		expenses.POST("/:id/comments", handler.AddComment)
+	}
+}
+
+// SetupReimbursementRoutes configures the routes for the reimbursement lifecycle
+func SetupReimbursementRoutes(router *gin.Engine, service *application.ReimbursementService) {
+	handler := NewReimbursementHandler(service)
+
+	router.GET("/reimbursements", handler.Summary)
+	batches := router.Group("/reimbursements/batches")
+	{
+		batches.POST("", handler.SubmitBatch)
+		batches.GET("", handler.ListBatches)
+		batches.GET("/:id", handler.GetBatch)
+		batches.POST("/:id/paid", handler.MarkBatchPaid)
+		batches.DELETE("/:id", handler.WithdrawBatch)
+	}
+}
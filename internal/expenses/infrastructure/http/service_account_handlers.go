@@ -0,0 +1,158 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the admin handlers for service accounts and the OAuth 2.0 token endpoint
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ServiceAccountHandler handles the HTTP requests about service accounts
+type ServiceAccountHandler struct {
+	service *application.ServiceAccountService
+}
+
+// NewServiceAccountHandler creates a new service account handler
+func NewServiceAccountHandler(service *application.ServiceAccountService) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// IssueToken handles POST /auth/token
+// It implements the client credentials grant (RFC 6749 section 4.4): the body is form-encoded
+// (JSON is accepted too), and the credentials come in the body or as HTTP Basic authentication.
+// Responses and errors have the shape OAuth 2.0 client libraries expect
+func (h *ServiceAccountHandler) IssueToken(c *gin.Context) {
+	// Tokens must never be cached (RFC 6749 section 5.1)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var req application.TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             "invalid_request",
+			"error_description": err.Error(),
+		})
+		return
+	}
+	if req.GrantType != application.GrantTypeClientCredentials {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+		return
+	}
+	if clientID, secret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID, req.ClientSecret = clientID, secret
+	}
+
+	token, err := h.service.IssueToken(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidClient) {
+			c.Header("WWW-Authenticate", `Basic realm="myexpenses"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// CreateAccount handles POST /admin/service-accounts
+// The response carries the client secret; it can't be retrieved again later
+func (h *ServiceAccountHandler) CreateAccount(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "creating service accounts requires the admin role"})
+		return
+	}
+
+	var req application.CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	credentials, err := h.service.CreateAccount(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidServiceAccount) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service account"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Service account created successfully; store the client secret now, it is not shown again",
+		"data":    credentials,
+	})
+}
+
+// ListAccounts handles GET /admin/service-accounts
+func (h *ServiceAccountHandler) ListAccounts(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "listing service accounts requires the admin role"})
+		return
+	}
+
+	accounts, err := h.service.ListAccounts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list service accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  accounts,
+		"count": len(accounts),
+	})
+}
+
+// RotateSecret handles POST /admin/service-accounts/{id}/rotate-secret
+func (h *ServiceAccountHandler) RotateSecret(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "rotating client secrets requires the admin role"})
+		return
+	}
+
+	credentials, err := h.service.RotateSecret(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrServiceAccountNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate client secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Client secret rotated successfully; store it now, it is not shown again",
+		"data":    credentials,
+	})
+}
+
+// DeleteAccount handles DELETE /admin/service-accounts/{id}
+func (h *ServiceAccountHandler) DeleteAccount(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "deleting service accounts requires the admin role"})
+		return
+	}
+
+	if err := h.service.DeleteAccount(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrServiceAccountNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete service account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service account deleted successfully"})
+}
@@ -0,0 +1,42 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handler for tag autocomplete
+package http
+
+import (
+	"net/http" // Go's built-in HTTP package for status codes
+	"strconv"  // For parsing the limit parameter
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// TagHandler handles HTTP requests for tags
+type TagHandler struct {
+	service *application.TagService
+}
+
+// NewTagHandler creates a new tag handler
+func NewTagHandler(service *application.TagService) *TagHandler {
+	return &TagHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// SuggestTags handles GET /tags/suggest?q=...&limit=...
+// It returns the caller's tags starting with the typed prefix, most used first
+func (h *TagHandler) SuggestTags(c *gin.Context) {
+	// limit is optional; invalid values fall back to the service default
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	suggestions, err := h.service.SuggestTags(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  suggestions,
+		"count": len(suggestions),
+	})
+}
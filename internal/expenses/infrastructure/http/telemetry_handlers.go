@@ -0,0 +1,44 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handler that shows operators the telemetry report of their instance
+package http
+
+import (
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// TelemetryHandler handles HTTP requests about telemetry
+type TelemetryHandler struct {
+	service *application.TelemetryService
+}
+
+// NewTelemetryHandler creates a new telemetry handler
+func NewTelemetryHandler(service *application.TelemetryService) *TelemetryHandler {
+	return &TelemetryHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// GetTelemetry handles GET /admin/telemetry
+// It returns exactly the report the instance sends, and whether it sends it at all, so
+// operators can review it before opting in with TELEMETRY_ENABLED=true
+func (h *TelemetryHandler) GetTelemetry(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "viewing telemetry requires the admin role"})
+		return
+	}
+
+	report, err := h.service.Report(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build telemetry report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": h.service.Enabled(),
+		"data":    report,
+	})
+}
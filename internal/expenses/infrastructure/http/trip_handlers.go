@@ -0,0 +1,156 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for trips and the trip report
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// TripHandler handles HTTP requests for trips
+type TripHandler struct {
+	service *application.TripService
+}
+
+// NewTripHandler creates a new trip handler
+func NewTripHandler(service *application.TripService) *TripHandler {
+	return &TripHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CreateTrip handles POST /trips
+func (h *TripHandler) CreateTrip(c *gin.Context) {
+	var req application.CreateTripRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	trip, err := h.service.CreateTrip(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidTrip) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create trip"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Trip created successfully",
+		"data":    trip,
+	})
+}
+
+// ListTrips handles GET /trips
+func (h *TripHandler) ListTrips(c *gin.Context) {
+	trips, err := h.service.ListTrips(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list trips"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  trips,
+		"count": len(trips),
+	})
+}
+
+// GetTrip handles GET /trips/{id}
+func (h *TripHandler) GetTrip(c *gin.Context) {
+	trip, err := h.service.GetTrip(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrTripNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trip"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": trip,
+	})
+}
+
+// AssignTripExpenses handles POST /trips/{id}/expenses
+func (h *TripHandler) AssignTripExpenses(c *gin.Context) {
+	var req application.AssignTripExpensesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.AssignExpenses(c.Request.Context(), c.Param("id"), &req); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTripNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		case errors.Is(err, domain.ErrExpenseNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+		case errors.Is(err, domain.ErrTripApproved):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add expenses to trip"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Expenses added to trip successfully",
+	})
+}
+
+// RemoveTripExpense handles DELETE /trips/{id}/expenses/{expenseId}
+func (h *TripHandler) RemoveTripExpense(c *gin.Context) {
+	if err := h.service.RemoveExpense(c.Request.Context(), c.Param("id"), c.Param("expenseId")); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTripNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+		case errors.Is(err, domain.ErrExpenseNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found on this trip"})
+		case errors.Is(err, domain.ErrTripApproved):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove expense from trip"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Expense removed from trip successfully",
+	})
+}
+
+// TripReport handles GET /trips/{id}/report
+// It breaks the trip down by leg and currency with original and home-currency totals
+// Like every report it accepts ?format=json|csv|pdf|html; ?format=pdf gives the version for the employer
+func (h *TripHandler) TripReport(c *gin.Context) {
+	renderer, ok := reportRenderer(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Report(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrTripNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build trip report"})
+		return
+	}
+
+	renderReport(c, renderer, "trip-report", result.Document())
+}
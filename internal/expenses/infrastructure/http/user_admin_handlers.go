@@ -0,0 +1,217 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the admin handlers for users (listing, disabling, deleting, password resets, roles)
+// and global stats
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+	"strconv"  // For parsing the paging parameters
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// UserAdminHandler handles the admin-only HTTP requests about users
+type UserAdminHandler struct {
+	service *application.UserAdminService
+}
+
+// NewUserAdminHandler creates a new user admin handler
+func NewUserAdminHandler(service *application.UserAdminService) *UserAdminHandler {
+	return &UserAdminHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// ListUsers handles GET /admin/users?email=&limit=&offset=
+// email finds users whose email contains it; the response says how many match across all pages
+func (h *UserAdminHandler) ListUsers(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "listing users requires the admin role"})
+		return
+	}
+
+	filter := domain.UserFilter{Email: c.Query("email")}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		filter.Limit = limit
+	}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be zero or a positive number"})
+			return
+		}
+		filter.Offset = offset
+	}
+
+	list, err := h.service.ListUsers(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   list.Users,
+		"count":  len(list.Users),
+		"total":  list.Total,
+		"limit":  list.Limit,
+		"offset": list.Offset,
+	})
+}
+
+// SetUserRole handles PUT /admin/users/{id}/role
+func (h *UserAdminHandler) SetUserRole(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "changing roles requires the admin role"})
+		return
+	}
+
+	var req application.SetUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.service.SetUserRole(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidRole):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change role"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role changed successfully",
+		"data":    user,
+	})
+}
+
+// DisableUser handles POST /admin/users/{id}/disable
+func (h *UserAdminHandler) DisableUser(c *gin.Context) {
+	h.setDisabled(c, true)
+}
+
+// EnableUser handles POST /admin/users/{id}/enable
+func (h *UserAdminHandler) EnableUser(c *gin.Context) {
+	h.setDisabled(c, false)
+}
+
+// setDisabled disables or enables the user named in the path
+func (h *UserAdminHandler) setDisabled(c *gin.Context, disabled bool) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "managing users requires the admin role"})
+		return
+	}
+
+	user, err := h.service.SetDisabled(c.Request.Context(), c.Param("id"), disabled)
+	if err != nil {
+		respondUserAdminError(c, err, "Failed to update user")
+		return
+	}
+
+	message := "User enabled successfully"
+	if disabled {
+		message = "User disabled successfully"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    user,
+	})
+}
+
+// DeleteUser handles DELETE /admin/users/{id}
+func (h *UserAdminHandler) DeleteUser(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "deleting users requires the admin role"})
+		return
+	}
+
+	if err := h.service.DeleteUser(c.Request.Context(), c.Param("id")); err != nil {
+		respondUserAdminError(c, err, "Failed to delete user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+// ResetPassword handles POST /admin/users/{id}/reset-password
+// With an empty body a temporary password is generated and returned once
+func (h *UserAdminHandler) ResetPassword(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "resetting passwords requires the admin role"})
+		return
+	}
+
+	var req application.ResetPasswordRequest
+	// The body is optional: without it a password is generated
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	reset, err := h.service.ResetPassword(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondUserAdminError(c, err, "Failed to reset password")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset successfully",
+		"data":    reset,
+	})
+}
+
+// respondUserAdminError maps user management errors to HTTP responses
+// fallback is the message used for unexpected errors
+func respondUserAdminError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, domain.ErrWeakPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCannotManageSelf):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// Stats handles GET /admin/stats
+// It counts users, expenses and attachments across all users
+func (h *UserAdminHandler) Stats(c *gin.Context) {
+	if !isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "global stats require the admin role"})
+		return
+	}
+
+	stats, err := h.service.Stats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
@@ -0,0 +1,220 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for registration, login and the current user
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+	"strconv"  // For the Retry-After header
+
+	"myexpenses/internal/auth"                 // For the missing caller error
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// UserHandler handles HTTP requests for user accounts
+type UserHandler struct {
+	service *application.UserService
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(service *application.UserService) *UserHandler {
+	return &UserHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// Register handles POST /auth/register
+func (h *UserHandler) Register(c *gin.Context) {
+	var req application.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.service.Register(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidUser), errors.Is(err, domain.ErrWeakPassword):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrEmailTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrSeatLimitReached):
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "User registered successfully",
+		"data":    user,
+	})
+}
+
+// Login handles POST /auth/login
+// After too many failed logins it answers 423 (account locked) or 429 (address throttled)
+// with a Retry-After header saying how many seconds to wait
+func (h *UserHandler) Login(c *gin.Context) {
+	var req application.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	result, err := h.service.Login(c.Request.Context(), &req)
+	if err != nil {
+		var blocked *application.LoginBlockedError
+		if errors.As(err, &blocked) {
+			retryAfter := wholeSeconds(blocked.RetryAfter)
+			status := http.StatusTooManyRequests
+			if errors.Is(err, domain.ErrAccountLocked) {
+				status = http.StatusLocked
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(status, gin.H{
+				"error":       err.Error(),
+				"retry_after": retryAfter,
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, domain.ErrUserDisabled) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged in successfully",
+		"data":    result,
+	})
+}
+
+// Refresh handles POST /auth/refresh
+// It exchanges a refresh token for a new access token and a new refresh token
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var req application.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	req.ClientIP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	result, err := h.service.Refresh(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Token refreshed successfully",
+		"data":    result,
+	})
+}
+
+// Logout handles POST /auth/logout
+// It revokes the refresh token; the client should discard its access token too
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req application.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.Logout(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// ListSessions handles GET /auth/sessions
+// It lists the caller's logged-in devices; "current" marks the one making the request
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	sessions, err := h.service.ListSessions(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, auth.ErrNoPrincipal) || errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "not logged in"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  sessions,
+		"count": len(sessions),
+	})
+}
+
+// RevokeSession handles DELETE /auth/sessions/{id}
+// The device can't refresh any more; its current access token works until it expires
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	if err := h.service.RevokeSession(c.Request.Context(), c.Param("id")); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrNoPrincipal), errors.Is(err, domain.ErrUserNotFound):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "not logged in"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "this API key is read-only"})
+		case errors.Is(err, domain.ErrSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
+
+// CurrentUser handles GET /auth/me
+func (h *UserHandler) CurrentUser(c *gin.Context) {
+	user, err := h.service.CurrentUser(c.Request.Context())
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrNoPrincipal):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "not logged in"})
+		case errors.Is(err, domain.ErrUserNotFound):
+			// The token is valid but its user was removed
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
@@ -0,0 +1,128 @@
+// Package http contains the HTTP handlers for the expense API
+// This file contains the handlers for saved views of the expense list
+package http
+
+import (
+	"errors"   // For matching domain errors through wrapped errors
+	"net/http" // Go's built-in HTTP package for status codes
+
+	"myexpenses/internal/expenses/application" // Import our application layer
+	"myexpenses/internal/expenses/domain"      // Import our domain layer (for error types)
+
+	"github.com/gin-gonic/gin" // Gin is a high-performance HTTP web framework for Go
+)
+
+// ViewHandler handles HTTP requests for saved views
+type ViewHandler struct {
+	service *application.ViewService
+}
+
+// NewViewHandler creates a new view handler
+func NewViewHandler(service *application.ViewService) *ViewHandler {
+	return &ViewHandler{
+		service: service, // Store the service dependency
+	}
+}
+
+// CreateView handles POST /views
+// The view is then applied to the expense list with GET /expenses?view=<id>
+func (h *ViewHandler) CreateView(c *gin.Context) {
+	var req application.ViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	view, err := h.service.CreateView(c.Request.Context(), &req)
+	if err != nil {
+		respondViewError(c, err, "Failed to create view")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "View created successfully",
+		"data":    view,
+	})
+}
+
+// ListViews handles GET /views
+func (h *ViewHandler) ListViews(c *gin.Context) {
+	views, err := h.service.ListViews(c.Request.Context())
+	if err != nil {
+		respondViewError(c, err, "Failed to list views")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  views,
+		"count": len(views),
+	})
+}
+
+// GetView handles GET /views/{id}
+func (h *ViewHandler) GetView(c *gin.Context) {
+	view, err := h.service.GetView(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondViewError(c, err, "Failed to get view")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": view,
+	})
+}
+
+// UpdateView handles PUT /views/{id}
+// The name and the whole filter set are replaced
+func (h *ViewHandler) UpdateView(c *gin.Context) {
+	var req application.ViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	view, err := h.service.UpdateView(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondViewError(c, err, "Failed to update view")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "View updated successfully",
+		"data":    view,
+	})
+}
+
+// DeleteView handles DELETE /views/{id}
+func (h *ViewHandler) DeleteView(c *gin.Context) {
+	if err := h.service.DeleteView(c.Request.Context(), c.Param("id")); err != nil {
+		respondViewError(c, err, "Failed to delete view")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "View deleted successfully",
+	})
+}
+
+// respondViewError maps view errors to HTTP responses
+func respondViewError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidView), errors.Is(err, domain.ErrInvalidQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrViewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+	case errors.Is(err, domain.ErrViewNameTaken), errors.Is(err, domain.ErrTooManyViews):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
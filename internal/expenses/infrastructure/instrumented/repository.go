@@ -0,0 +1,127 @@
+// Package instrumented contains decorators that record metrics around repository calls
+// A decorator implements the same domain interface as the repository it wraps, so it can be
+// stacked with other decorators (e.g. a cache) in any order without the wrapped code knowing
+package instrumented
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For measuring call latency
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/metrics"         // Where observations are recorded
+)
+
+// expenseComponent is the component label used for expense repository metrics
+const expenseComponent = "expense_repository"
+
+// Repository decorates a domain.Repository with latency, error and row count metrics
+type Repository struct {
+	next     domain.Repository
+	recorder metrics.Recorder
+}
+
+// NewRepository wraps next so every call is recorded into recorder
+func NewRepository(next domain.Repository, recorder metrics.Recorder) *Repository {
+	return &Repository{next: next, recorder: recorder}
+}
+
+// observe records one call that started at start
+func (r *Repository) observe(method string, start time.Time, rows int, err error) {
+	r.recorder.RecordCall(expenseComponent, method, time.Since(start), rows, err)
+}
+
+// Create implements domain.Repository
+func (r *Repository) Create(ctx context.Context, expense *domain.Expense) error {
+	start := time.Now()
+	err := r.next.Create(ctx, expense)
+	r.observe("Create", start, rowsAffected(err), err)
+	return err
+}
+
+// GetByID implements domain.Repository
+func (r *Repository) GetByID(ctx context.Context, id string) (*domain.Expense, error) {
+	start := time.Now()
+	expense, err := r.next.GetByID(ctx, id)
+	r.observe("GetByID", start, rowsAffected(err), err)
+	return expense, err
+}
+
+// GetAll implements domain.Repository
+func (r *Repository) GetAll(ctx context.Context, filters map[string]interface{}) ([]*domain.Expense, error) {
+	start := time.Now()
+	expenses, err := r.next.GetAll(ctx, filters)
+	r.observe("GetAll", start, len(expenses), err)
+	return expenses, err
+}
+
+// Update implements domain.Repository
+func (r *Repository) Update(ctx context.Context, expense *domain.Expense) error {
+	start := time.Now()
+	err := r.next.Update(ctx, expense)
+	r.observe("Update", start, rowsAffected(err), err)
+	return err
+}
+
+// Delete implements domain.Repository
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.next.Delete(ctx, id)
+	r.observe("Delete", start, rowsAffected(err), err)
+	return err
+}
+
+// Exists implements domain.Repository
+func (r *Repository) Exists(ctx context.Context, id string) (bool, error) {
+	start := time.Now()
+	exists, err := r.next.Exists(ctx, id)
+	r.observe("Exists", start, 0, err)
+	return exists, err
+}
+
+// Count implements domain.Repository
+func (r *Repository) Count(ctx context.Context, filters map[string]interface{}) (int64, error) {
+	start := time.Now()
+	count, err := r.next.Count(ctx, filters)
+	r.observe("Count", start, 0, err)
+	return count, err
+}
+
+// Stream implements domain.Repository
+// The recorded latency covers the whole stream, including the time spent in fn
+func (r *Repository) Stream(ctx context.Context, filters map[string]interface{}, fn func(*domain.Expense) error) error {
+	start := time.Now()
+	rows := 0
+	err := r.next.Stream(ctx, filters, func(expense *domain.Expense) error {
+		rows++
+		return fn(expense)
+	})
+	r.observe("Stream", start, rows, err)
+	return err
+}
+
+// ExplainExpenses passes query plan requests through to the wrapped repository
+// Decorators must forward optional capabilities, or wrapping would silently switch them off
+func (r *Repository) ExplainExpenses(ctx context.Context, filters map[string]interface{}) (*domain.QueryPlan, error) {
+	explainer, ok := r.next.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+	return explainer.ExplainExpenses(ctx, filters)
+}
+
+// ExplainSpending passes query plan requests through to the wrapped repository
+func (r *Repository) ExplainSpending(ctx context.Context, from, to time.Time) ([]*domain.QueryPlan, error) {
+	explainer, ok := r.next.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+	return explainer.ExplainSpending(ctx, from, to)
+}
+
+// rowsAffected is the row count of a single-row operation
+func rowsAffected(err error) int {
+	if err != nil {
+		return 0
+	}
+	return 1
+}
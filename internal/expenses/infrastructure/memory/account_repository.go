@@ -0,0 +1,298 @@
+// Package memory keeps expenses in memory instead of a database
+// This file implements the domain.AccountRepository interface for accounts and transfers
+package memory
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For list order
+	"sync"    // For guarding the accounts and transfers
+
+	"myexpenses/internal/clock"           // Time source for CreatedAt/UpdatedAt
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing
+)
+
+// AccountRepository implements domain.AccountRepository in memory
+// Like the postgres repository, accounts and transfers belong to their creator; the expenses
+// paid from an account are read from the expense repository
+type AccountRepository struct {
+	clock    clock.Clock
+	expenses *Repository
+
+	mu        sync.RWMutex
+	accounts  map[uuid.UUID]*domain.Account
+	transfers map[uuid.UUID]*domain.Transfer
+}
+
+// NewAccountRepository creates an empty account repository for the accounts of expenses
+func NewAccountRepository(expenses *Repository, clk clock.Clock) *AccountRepository {
+	return &AccountRepository{
+		clock:     clock.Or(clk),
+		expenses:  expenses,
+		accounts:  make(map[uuid.UUID]*domain.Account),
+		transfers: make(map[uuid.UUID]*domain.Transfer),
+	}
+}
+
+// Create saves a new account owned by the caller
+func (r *AccountRepository) Create(ctx context.Context, account *domain.Account) error {
+	owner, _, err := ownerAndBook(ctx)
+	if err != nil {
+		return err
+	}
+	account.UserID = owner
+	if account.ID == uuid.Nil {
+		account.ID = uuid.New()
+	}
+	now := r.clock.Now()
+	account.CreatedAt = now
+	account.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.accounts[account.ID]; exists {
+		return fmt.Errorf("account %s already exists", account.ID)
+	}
+	copied := *account
+	r.accounts[account.ID] = &copied
+	return nil
+}
+
+// GetByID retrieves one of the caller's accounts by its ID
+func (r *AccountRepository) GetByID(ctx context.Context, id string) (*domain.Account, error) {
+	accountID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	account, ok := r.accounts[accountID]
+	if !ok || !ownedByCaller(ctx, account.UserID) {
+		return nil, domain.ErrAccountNotFound
+	}
+	copied := *account
+	return &copied, nil
+}
+
+// List returns the caller's accounts ordered by name
+func (r *AccountRepository) List(ctx context.Context) ([]*domain.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	accounts := make([]*domain.Account, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		if ownedByCaller(ctx, account.UserID) {
+			copied := *account
+			accounts = append(accounts, &copied)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Name != accounts[j].Name {
+			return accounts[i].Name < accounts[j].Name
+		}
+		return accounts[i].ID.String() < accounts[j].ID.String()
+	})
+	return accounts, nil
+}
+
+// FindByType returns the caller's oldest account of the given type
+func (r *AccountRepository) FindByType(ctx context.Context, accountType domain.AccountType) (*domain.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var oldest *domain.Account
+	for _, account := range r.accounts {
+		if account.Type != accountType || !ownedByCaller(ctx, account.UserID) {
+			continue
+		}
+		if oldest == nil || account.CreatedAt.Before(oldest.CreatedAt) ||
+			(account.CreatedAt.Equal(oldest.CreatedAt) && account.ID.String() < oldest.ID.String()) {
+			oldest = account
+		}
+	}
+	if oldest == nil {
+		return nil, domain.ErrAccountNotFound
+	}
+	copied := *oldest
+	return &copied, nil
+}
+
+// CreateTransfer saves a transfer between the caller's accounts
+func (r *AccountRepository) CreateTransfer(ctx context.Context, transfer *domain.Transfer) error {
+	owner, _, err := ownerAndBook(ctx)
+	if err != nil {
+		return err
+	}
+	transfer.UserID = owner
+	if transfer.ID == uuid.Nil {
+		transfer.ID = uuid.New()
+	}
+	transfer.CreatedAt = r.clock.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.transfers[transfer.ID]; exists {
+		return fmt.Errorf("transfer %s already exists", transfer.ID)
+	}
+	copied := *transfer
+	r.transfers[transfer.ID] = &copied
+	return nil
+}
+
+// ListTransfers returns the caller's transfers into or out of an account, newest first
+func (r *AccountRepository) ListTransfers(ctx context.Context, accountID string) ([]*domain.Transfer, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var transfers []*domain.Transfer
+	for _, transfer := range r.transfers {
+		if (transfer.FromAccountID == id || transfer.ToAccountID == id) && ownedByCaller(ctx, transfer.UserID) {
+			copied := *transfer
+			transfers = append(transfers, &copied)
+		}
+	}
+	sort.Slice(transfers, func(i, j int) bool {
+		if !transfers[i].Date.Equal(transfers[j].Date) {
+			return transfers[i].Date.After(transfers[j].Date)
+		}
+		return transfers[i].ID.String() < transfers[j].ID.String()
+	})
+	return transfers, nil
+}
+
+// Totals returns the transfer and expense sums of an account
+func (r *AccountRepository) Totals(ctx context.Context, accountID string) (*domain.AccountTotals, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var transfersIn, transfersOut, spent domain.Money
+	for _, transfer := range r.transfers {
+		if transfer.ToAccountID == id {
+			transfersIn = transfersIn.Add(transfer.Amount)
+		}
+		if transfer.FromAccountID == id {
+			transfersOut = transfersOut.Add(transfer.Amount)
+		}
+	}
+	if account, ok := r.accounts[id]; ok {
+		for _, expense := range r.expenses.paidFrom(id) {
+			spent = spent.Add(account.AmountOf(expense))
+		}
+	}
+	return &domain.AccountTotals{
+		TransfersIn:  transfersIn,
+		TransfersOut: transfersOut,
+		Spent:        spent,
+	}, nil
+}
+
+// EnvelopeTotals returns, for each envelope funded into the account, how much was funded and spent
+// Spending is attributed to an envelope when the expense category equals the envelope name
+func (r *AccountRepository) EnvelopeTotals(ctx context.Context, accountID string) ([]*domain.EnvelopeTotals, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	funded := make(map[string]domain.Money)
+	for _, transfer := range r.transfers {
+		if transfer.ToAccountID == id && transfer.Envelope != "" {
+			funded[transfer.Envelope] = funded[transfer.Envelope].Add(transfer.Amount)
+		}
+	}
+	spent := make(map[string]domain.Money)
+	for _, expense := range r.expenses.paidFrom(id) {
+		spent[expense.Category] = spent[expense.Category].Add(expense.Amount)
+	}
+
+	totals := make([]*domain.EnvelopeTotals, 0, len(funded))
+	for envelope, amount := range funded {
+		totals = append(totals, &domain.EnvelopeTotals{
+			Envelope: envelope,
+			Funded:   amount,
+			Spent:    spent[envelope],
+		})
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Envelope < totals[j].Envelope })
+	return totals, nil
+}
+
+// Update saves the name and opening balance of one of the caller's accounts
+func (r *AccountRepository) Update(ctx context.Context, account *domain.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.accounts[account.ID]
+	if !ok || !ownedByCaller(ctx, stored.UserID) {
+		return domain.ErrAccountNotFound
+	}
+	stored.Name = account.Name
+	stored.OpeningBalance = account.OpeningBalance
+	stored.UpdatedAt = r.clock.Now()
+	return nil
+}
+
+// Delete removes one of the caller's accounts that nothing refers to
+func (r *AccountRepository) Delete(ctx context.Context, id string) error {
+	accountID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrAccountNotFound
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	account, ok := r.accounts[accountID]
+	if !ok || !ownedByCaller(ctx, account.UserID) {
+		return domain.ErrAccountNotFound
+	}
+	if len(r.expenses.paidFrom(accountID)) > 0 {
+		return domain.ErrAccountInUse
+	}
+	for _, transfer := range r.transfers {
+		if transfer.FromAccountID == accountID || transfer.ToAccountID == accountID {
+			return domain.ErrAccountInUse
+		}
+	}
+	delete(r.accounts, accountID)
+	return nil
+}
+
+// ListExpenses returns the caller's expenses paid from an account, oldest first
+// The account is checked by the caller
+func (r *AccountRepository) ListExpenses(ctx context.Context, accountID string) ([]*domain.Expense, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+	var expenses []*domain.Expense
+	for _, expense := range r.expenses.paidFrom(id) {
+		if ownedByCaller(ctx, expense.UserID) {
+			expenses = append(expenses, expense)
+		}
+	}
+	return expenses, nil
+}
+
+// paidFrom returns copies of every expense paid from an account, by date and then creation
+func (r *Repository) paidFrom(accountID uuid.UUID) []*domain.Expense {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var expenses []*domain.Expense
+	for _, expense := range r.expenses {
+		if expense.AccountID != nil && *expense.AccountID == accountID {
+			expenses = append(expenses, clone(expense))
+		}
+	}
+	sort.Slice(expenses, func(i, j int) bool {
+		if !expenses[i].Date.Equal(expenses[j].Date) {
+			return expenses[i].Date.Before(expenses[j].Date)
+		}
+		return createdBefore(expenses[i], expenses[j])
+	})
+	return expenses
+}
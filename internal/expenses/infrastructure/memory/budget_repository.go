@@ -0,0 +1,136 @@
+// Package memory keeps expenses in memory instead of a database
+// This file implements the domain.BudgetRepository interface
+package memory
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For list order
+	"sync"    // For guarding the budgets
+
+	"myexpenses/internal/clock"           // Time source for CreatedAt/UpdatedAt
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing
+)
+
+// BudgetRepository implements domain.BudgetRepository in memory
+// Like the postgres repository, each book has its own budgets and callers only see those of
+// their current book
+type BudgetRepository struct {
+	clock clock.Clock
+
+	mu      sync.RWMutex
+	budgets map[uuid.UUID]*domain.Budget
+}
+
+// NewBudgetRepository creates an empty budget repository
+func NewBudgetRepository(clk clock.Clock) *BudgetRepository {
+	return &BudgetRepository{
+		clock:   clock.Or(clk),
+		budgets: make(map[uuid.UUID]*domain.Budget),
+	}
+}
+
+// Create saves a new budget in the caller's current book
+// It returns domain.ErrBudgetExists if the category already has a budget for the same month
+func (r *BudgetRepository) Create(ctx context.Context, budget *domain.Budget) error {
+	_, book, err := ownerAndBook(ctx)
+	if err != nil {
+		return err
+	}
+	budget.BookID = book
+	if budget.ID == uuid.Nil {
+		budget.ID = uuid.New()
+	}
+	now := r.clock.Now()
+	budget.CreatedAt = now
+	budget.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stored := range r.budgets {
+		if sameID(stored.BookID, book) && stored.Category == budget.Category && stored.Month == budget.Month {
+			return domain.ErrBudgetExists
+		}
+	}
+	if _, exists := r.budgets[budget.ID]; exists {
+		return fmt.Errorf("budget %s already exists", budget.ID)
+	}
+	copied := *budget
+	r.budgets[budget.ID] = &copied
+	return nil
+}
+
+// GetByID retrieves a budget of the caller's current book by its ID
+func (r *BudgetRepository) GetByID(ctx context.Context, id string) (*domain.Budget, error) {
+	budgetID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	budget, ok := r.budgets[budgetID]
+	if !ok || !inCallersBook(ctx, budget.BookID) {
+		return nil, domain.ErrBudgetNotFound
+	}
+	copied := *budget
+	return &copied, nil
+}
+
+// List returns the budgets of the current book ordered by category, then month
+// Recurring budgets have an empty month, so they come before those of single months
+func (r *BudgetRepository) List(ctx context.Context) ([]*domain.Budget, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	budgets := make([]*domain.Budget, 0, len(r.budgets))
+	for _, budget := range r.budgets {
+		if inCallersBook(ctx, budget.BookID) {
+			copied := *budget
+			budgets = append(budgets, &copied)
+		}
+	}
+	sort.Slice(budgets, func(i, j int) bool {
+		if budgets[i].Category != budgets[j].Category {
+			return budgets[i].Category < budgets[j].Category
+		}
+		return budgets[i].Month < budgets[j].Month
+	})
+	return budgets, nil
+}
+
+// Update saves changes to a budget of the caller's current book
+func (r *BudgetRepository) Update(ctx context.Context, budget *domain.Budget) error {
+	_, book, err := ownerAndBook(ctx)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.budgets[budget.ID]
+	if !ok || !sameID(stored.BookID, book) {
+		return domain.ErrBudgetNotFound
+	}
+	budget.BookID = book
+	budget.CreatedAt = stored.CreatedAt
+	budget.UpdatedAt = r.clock.Now()
+	copied := *budget
+	r.budgets[budget.ID] = &copied
+	return nil
+}
+
+// Delete removes a budget of the caller's current book by its ID
+func (r *BudgetRepository) Delete(ctx context.Context, id string) error {
+	budgetID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	budget, ok := r.budgets[budgetID]
+	if !ok || !inCallersBook(ctx, budget.BookID) {
+		return domain.ErrBudgetNotFound
+	}
+	delete(r.budgets, budgetID)
+	return nil
+}
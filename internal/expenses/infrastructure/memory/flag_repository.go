@@ -0,0 +1,43 @@
+// Package memory keeps expenses in memory instead of a database
+// This file implements the domain.FlagRepository interface for the flag report
+package memory
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For the error of flagging
+	"time"    // For date range arguments
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// errFlagsNotKept is returned when an expense is flagged in memory
+var errFlagsNotKept = errors.New("review flags aren't kept in memory")
+
+// FlagRepository implements domain.FlagRepository without keeping any flags
+// Review flags aren't part of mock mode, so no expense carries one and the flag report is empty
+type FlagRepository struct{}
+
+// NewFlagRepository creates a flag repository without flags
+func NewFlagRepository() *FlagRepository {
+	return &FlagRepository{}
+}
+
+// Set refuses to flag an expense
+func (r *FlagRepository) Set(ctx context.Context, flag *domain.ExpenseFlag) error {
+	return errFlagsNotKept
+}
+
+// Remove returns domain.ErrFlagNotFound: no flag is ever set
+func (r *FlagRepository) Remove(ctx context.Context, expenseID string, flag domain.Flag) error {
+	return domain.ErrFlagNotFound
+}
+
+// ListForExpense returns no flags
+func (r *FlagRepository) ListForExpense(ctx context.Context, expenseID string) ([]*domain.ExpenseFlag, error) {
+	return nil, nil
+}
+
+// CountByFlag returns no counts
+func (r *FlagRepository) CountByFlag(ctx context.Context, from, to time.Time) ([]*domain.FlagCount, error) {
+	return nil, nil
+}
@@ -0,0 +1,196 @@
+// Package memory keeps expenses in memory instead of a database
+// This file implements the domain.SpendingRepository and domain.TaxRepository interfaces used by
+// budgets, the dashboard and reports
+package memory
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"sort"    // For report order
+	"time"    // For date range arguments
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// SpendingByCategory sums the caller's expenses dated in [from, to) per category, by category
+// Like the postgres repository it uses the locked base-currency amounts
+func (r *Repository) SpendingByCategory(ctx context.Context, from, to time.Time) ([]*domain.CategorySpending, error) {
+	expenses, err := r.dated(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]domain.Money)
+	for _, expense := range expenses {
+		totals[expense.Category] = totals[expense.Category].Add(expense.ReportingAmount())
+	}
+	spending := make([]*domain.CategorySpending, 0, len(totals))
+	for category, total := range totals {
+		spending = append(spending, &domain.CategorySpending{Category: category, Amount: total})
+	}
+	sort.Slice(spending, func(i, j int) bool { return spending[i].Category < spending[j].Category })
+	return spending, nil
+}
+
+// SpendingByMerchant sums the caller's expenses dated in [from, to) per merchant, biggest first
+// Expenses without a merchant are attributed to their normalized description, then their description
+func (r *Repository) SpendingByMerchant(ctx context.Context, from, to time.Time) ([]*domain.MerchantSpending, error) {
+	expenses, err := r.dated(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]domain.Money)
+	counts := make(map[string]int)
+	for _, expense := range expenses {
+		merchant := merchantName(expense)
+		totals[merchant] = totals[merchant].Add(expense.ReportingAmount())
+		counts[merchant]++
+	}
+	spending := make([]*domain.MerchantSpending, 0, len(totals))
+	for merchant, total := range totals {
+		spending = append(spending, &domain.MerchantSpending{Merchant: merchant, Amount: total, Count: counts[merchant]})
+	}
+	// Ties are broken by name so reports come out the same every time
+	sort.Slice(spending, func(i, j int) bool {
+		if spending[i].Amount.Minor != spending[j].Amount.Minor {
+			return spending[i].Amount.Minor > spending[j].Amount.Minor
+		}
+		return spending[i].Merchant < spending[j].Merchant
+	})
+	return spending, nil
+}
+
+// VATTotals sums the caller's expenses with VAT information dated in [from, to) per VAT rate,
+// highest rate first and expenses without a rate last
+func (r *Repository) VATTotals(ctx context.Context, from, to time.Time) ([]*domain.VATTotal, error) {
+	expenses, err := r.dated(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	// Expenses whose tax was entered without a rate are grouped under the zero key
+	type rateKey struct {
+		rate  bool
+		value float64
+	}
+	type sums struct {
+		total      *domain.VATTotal
+		gross, tax domain.Money
+	}
+	rates := make(map[rateKey]*sums)
+	for _, expense := range expenses {
+		if expense.VATRate == nil && expense.TaxAmount.IsZero() {
+			continue
+		}
+		key := rateKey{}
+		if expense.VATRate != nil {
+			key = rateKey{rate: true, value: *expense.VATRate}
+		}
+		rate := rates[key]
+		if rate == nil {
+			rate = &sums{total: &domain.VATTotal{Rate: expense.VATRate}}
+			rates[key] = rate
+		}
+		rate.gross = rate.gross.Add(expense.ReportingAmount())
+		rate.tax = rate.tax.Add(reportingTax(expense))
+		rate.total.Count++
+	}
+
+	totals := make([]*domain.VATTotal, 0, len(rates))
+	for _, rate := range rates {
+		rate.total.Gross = rate.gross
+		rate.total.Net = rate.gross.Sub(rate.tax)
+		rate.total.Tax = rate.tax
+		totals = append(totals, rate.total)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Rate == nil || totals[j].Rate == nil {
+			return totals[j].Rate == nil && totals[i].Rate != nil
+		}
+		return *totals[i].Rate > *totals[j].Rate
+	})
+	return totals, nil
+}
+
+// MarkTotals sums the caller's business, reimbursable and tax-deductible expenses dated in
+// [from, to) per category, in the base currency
+// Only categories with at least one marked expense are returned, by name
+func (r *Repository) MarkTotals(ctx context.Context, from, to time.Time) ([]*domain.MarkTotal, error) {
+	expenses, err := r.dated(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	type sums struct {
+		business, reimbursable, taxDeductible, total domain.Money
+		marked                                       bool
+	}
+	categories := make(map[string]*sums)
+	for _, expense := range expenses {
+		category := categories[expense.Category]
+		if category == nil {
+			category = &sums{}
+			categories[expense.Category] = category
+		}
+		amount := expense.ReportingAmount()
+		if expense.Business {
+			category.business = category.business.Add(amount)
+		}
+		if expense.Reimbursable {
+			category.reimbursable = category.reimbursable.Add(amount)
+		}
+		if expense.TaxDeductible {
+			category.taxDeductible = category.taxDeductible.Add(amount)
+		}
+		category.total = category.total.Add(amount)
+		category.marked = category.marked || expense.Business || expense.Reimbursable || expense.TaxDeductible
+	}
+
+	var totals []*domain.MarkTotal
+	for name, category := range categories {
+		if !category.marked {
+			continue
+		}
+		totals = append(totals, &domain.MarkTotal{
+			Category:      name,
+			Business:      category.business,
+			Reimbursable:  category.reimbursable,
+			TaxDeductible: category.taxDeductible,
+			Total:         category.total,
+		})
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Category < totals[j].Category })
+	return totals, nil
+}
+
+// dated returns copies of the caller's expenses dated in [from, to)
+func (r *Repository) dated(ctx context.Context, from, to time.Time) ([]*domain.Expense, error) {
+	expenses, err := r.matching(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	inRange := expenses[:0]
+	for _, expense := range expenses {
+		if !expense.Date.Before(from) && expense.Date.Before(to) {
+			inRange = append(inRange, expense)
+		}
+	}
+	return inRange, nil
+}
+
+// merchantName is the merchant an expense is attributed to, like the postgres repository's
+func merchantName(expense *domain.Expense) string {
+	switch {
+	case expense.Merchant != "":
+		return expense.Merchant
+	case expense.NormalizedDescription != "":
+		return expense.NormalizedDescription
+	default:
+		return expense.Description
+	}
+}
+
+// reportingTax is the tax share of an expense's reporting amount
+// The tax is entered in the expense's currency, so it is converted at the same ratio as the gross amount
+func reportingTax(expense *domain.Expense) domain.Money {
+	if expense.Amount.IsZero() {
+		return domain.Money{}
+	}
+	return expense.ReportingAmount().Mul(float64(expense.TaxAmount.Minor) / float64(expense.Amount.Minor))
+}
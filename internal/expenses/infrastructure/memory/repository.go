@@ -0,0 +1,382 @@
+// Package memory keeps expenses in memory instead of a database
+// It backs the API's mock mode (--mock), where frontends develop against the real endpoints
+// without Postgres; nothing is persisted and every instance has its own data
+package memory
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"reflect" // For matching custom field values
+	"slices"  // For tag filters and copying
+	"sort"    // For list order
+	"strings" // For case-insensitive partial matches
+	"sync"    // For guarding the expenses
+	"time"    // For date filters and timestamps
+
+	"myexpenses/internal/auth"            // The caller whose expenses are visible
+	"myexpenses/internal/clock"           // Time source for CreatedAt/UpdatedAt
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // Package for generating unique identifiers (UUIDs)
+)
+
+// Repository implements domain.Repository in memory
+// It mirrors the postgres repository: expenses belong to their creator's current book, lists
+// are newest first and take the same filter keys. Filters that need other tables (review
+// flags) match nothing
+type Repository struct {
+	clock clock.Clock
+
+	mu       sync.RWMutex
+	expenses map[uuid.UUID]*domain.Expense
+}
+
+// NewRepository creates an empty repository
+func NewRepository(clk clock.Clock) *Repository {
+	return &Repository{
+		clock:    clock.Or(clk),
+		expenses: make(map[uuid.UUID]*domain.Expense),
+	}
+}
+
+// Create adds a new expense
+// This method implements the domain.Repository.Create interface
+func (r *Repository) Create(ctx context.Context, expense *domain.Expense) error {
+	owner, book, err := ownerAndBook(ctx)
+	if err != nil {
+		return err
+	}
+	expense.UserID = owner
+	expense.BookID = book
+	if expense.ID == uuid.Nil {
+		expense.ID = uuid.New()
+	}
+	now := r.clock.Now()
+	if expense.CreatedAt.IsZero() {
+		expense.CreatedAt = now
+	}
+	expense.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.expenses[expense.ID]; exists {
+		return fmt.Errorf("expense %s already exists", expense.ID)
+	}
+	r.expenses[expense.ID] = clone(expense)
+	return nil
+}
+
+// GetByID retrieves an expense of the caller by its ID
+// This method implements the domain.Repository.GetByID interface
+func (r *Repository) GetByID(ctx context.Context, id string) (*domain.Expense, error) {
+	expenseID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	expense, ok := r.expenses[expenseID]
+	if !ok || !visible(ctx, expense) {
+		return nil, domain.ErrExpenseNotFound
+	}
+	return clone(expense), nil
+}
+
+// GetAll retrieves the caller's expenses matching the filters, newest first
+// This method implements the domain.Repository.GetAll interface
+func (r *Repository) GetAll(ctx context.Context, filters map[string]interface{}) ([]*domain.Expense, error) {
+	expenses, err := r.matching(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filters["order"] {
+	case "created_asc":
+		sort.SliceStable(expenses, func(i, j int) bool { return createdBefore(expenses[i], expenses[j]) })
+	case "created_desc":
+		sort.SliceStable(expenses, func(i, j int) bool { return createdBefore(expenses[j], expenses[i]) })
+	}
+
+	if offset, ok := filters["offset"].(int); ok && offset > 0 {
+		expenses = expenses[min(offset, len(expenses)):]
+	}
+	if limit, ok := filters["limit"].(int); ok && limit > 0 && limit < len(expenses) {
+		expenses = expenses[:limit]
+	}
+	return expenses, nil
+}
+
+// Count returns how many of the caller's expenses match the filters
+// This method implements the domain.Repository.Count interface
+func (r *Repository) Count(ctx context.Context, filters map[string]interface{}) (int64, error) {
+	expenses, err := r.matching(ctx, filters)
+	return int64(len(expenses)), err
+}
+
+// Stream calls fn for every expense matching the filters, newest first
+// This method implements the domain.Repository.Stream interface
+func (r *Repository) Stream(ctx context.Context, filters map[string]interface{}, fn func(*domain.Expense) error) error {
+	expenses, err := r.matching(ctx, filters)
+	if err != nil {
+		return err
+	}
+	for _, expense := range expenses {
+		if err := fn(expense); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update replaces an expense of the caller
+// This method implements the domain.Repository.Update interface
+func (r *Repository) Update(ctx context.Context, expense *domain.Expense) error {
+	owner, book, err := ownerAndBook(ctx)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.expenses[expense.ID]
+	if !ok || !visible(ctx, stored) {
+		return domain.ErrExpenseNotFound
+	}
+	expense.UserID = owner
+	expense.BookID = book
+	expense.CreatedAt = stored.CreatedAt
+	expense.UpdatedAt = r.clock.Now()
+	r.expenses[expense.ID] = clone(expense)
+	return nil
+}
+
+// Delete removes an expense of the caller by its ID
+// This method implements the domain.Repository.Delete interface
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	expenseID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expense, ok := r.expenses[expenseID]
+	if !ok || !visible(ctx, expense) {
+		return domain.ErrExpenseNotFound
+	}
+	delete(r.expenses, expenseID)
+	return nil
+}
+
+// Exists checks if the caller has an expense with the given ID
+// This method implements the domain.Repository.Exists interface
+func (r *Repository) Exists(ctx context.Context, id string) (bool, error) {
+	expenseID, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid UUID format: %w", err)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	expense, ok := r.expenses[expenseID]
+	return ok && visible(ctx, expense), nil
+}
+
+// matching returns copies of the caller's expenses that match the filters, newest first
+func (r *Repository) matching(ctx context.Context, filters map[string]interface{}) ([]*domain.Expense, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	expenses := make([]*domain.Expense, 0, len(r.expenses))
+	for _, expense := range r.expenses {
+		if visible(ctx, expense) && matches(expense, filters) {
+			expenses = append(expenses, clone(expense))
+		}
+	}
+	// Ties are broken by ID so lists come out the same every time
+	sort.Slice(expenses, func(i, j int) bool {
+		if !expenses[i].Date.Equal(expenses[j].Date) {
+			return expenses[i].Date.After(expenses[j].Date)
+		}
+		return expenses[i].ID.String() < expenses[j].ID.String()
+	})
+	return expenses, nil
+}
+
+// matches reports whether expense passes every filter, like the postgres repository's WHERE clauses
+// Unknown keys (including "limit", "offset" and "order") are ignored
+func matches(expense *domain.Expense, filters map[string]interface{}) bool {
+	for key, value := range filters {
+		switch key {
+		case "category", "description":
+			field := expense.Category
+			if key == "description" {
+				field = expense.Description
+			}
+			if text, ok := value.(string); ok && text != "" && !containsFold(field, text) {
+				return false
+			}
+		case "categories":
+			if categories, ok := value.([]string); ok && len(categories) > 0 && !slices.Contains(categories, expense.Category) {
+				return false
+			}
+		case "reimbursable", "business", "tax_deductible":
+			field := map[string]bool{"reimbursable": expense.Reimbursable, "business": expense.Business, "tax_deductible": expense.TaxDeductible}[key]
+			if marked, ok := value.(bool); ok && marked != field {
+				return false
+			}
+		case "reconciled":
+			if reconciled, ok := value.(bool); ok && reconciled != (expense.ReconciledAt != nil) {
+				return false
+			}
+		case "reimbursement_status", "external_id", "normalized_description", "cost_center", "department", "mcc":
+			field := map[string]string{
+				"reimbursement_status": expense.ReimbursementStatus, "external_id": expense.ExternalID,
+				"normalized_description": expense.NormalizedDescription, "cost_center": expense.CostCenter,
+				"department": expense.Department, "mcc": expense.MCC,
+			}[key]
+			if text, ok := value.(string); ok && text != "" && text != field {
+				return false
+			}
+		case "account_id", "trip_id", "loan_id":
+			field := map[string]*uuid.UUID{"account_id": expense.AccountID, "trip_id": expense.TripID, "loan_id": expense.LoanID}[key]
+			if id, ok := value.(string); ok && id != "" && (field == nil || field.String() != id) {
+				return false
+			}
+		case "employer_id", "parent_expense_id":
+			field := expense.EmployerID
+			if key == "parent_expense_id" {
+				field = expense.ParentExpenseID
+			}
+			if id, ok := value.(uuid.UUID); ok && (field == nil || *field != id) {
+				return false
+			}
+		case "tag":
+			if tag, ok := value.(string); ok && tag != "" && !slices.Contains(expense.Tags, tag) {
+				return false
+			}
+		case "tags":
+			if tags, ok := value.([]string); ok {
+				for _, tag := range tags {
+					if !slices.Contains(expense.Tags, tag) {
+						return false
+					}
+				}
+			}
+		case "metadata":
+			if metadata, ok := value.(domain.Metadata); ok {
+				for name, want := range metadata {
+					if !reflect.DeepEqual(expense.Metadata[name], want) {
+						return false
+					}
+				}
+			}
+		case "date_from", "date_to":
+			text, ok := value.(string)
+			if !ok || text == "" {
+				continue
+			}
+			bound, ok := parseDate(text)
+			if !ok || (key == "date_from" && expense.Date.Before(bound)) || (key == "date_to" && expense.Date.After(bound)) {
+				return false
+			}
+		case "min_amount":
+			if amount, ok := value.(domain.Money); ok && amount.Minor > 0 && expense.Amount.Minor < amount.Minor {
+				return false
+			}
+		case "max_amount":
+			if amount, ok := value.(domain.Money); ok && amount.Minor > 0 && expense.Amount.Minor > amount.Minor {
+				return false
+			}
+		case "created_after":
+			if cursor, ok := value.(domain.ExpenseCursor); ok && !createdBefore(&domain.Expense{CreatedAt: cursor.CreatedAt, ID: cursor.ID}, expense) {
+				return false
+			}
+		case "flag":
+			// Review flags aren't kept in memory, so no expense carries one
+			if flag, ok := value.(domain.Flag); ok && flag != "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ownerAndBook returns who new and updated expenses belong to, like the postgres repository
+func ownerAndBook(ctx context.Context) (owner, book *uuid.UUID, err error) {
+	if userID := auth.UserID(ctx); userID != "" {
+		parsed, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, nil, domain.ErrForbidden
+		}
+		owner = &parsed
+	}
+	if bookID := auth.BookID(ctx); bookID != "" {
+		parsed, err := uuid.Parse(bookID)
+		if err != nil {
+			return nil, nil, domain.ErrBookNotFound
+		}
+		book = &parsed
+	}
+	return owner, book, nil
+}
+
+// visible reports whether expense belongs to the caller and their current book
+func visible(ctx context.Context, expense *domain.Expense) bool {
+	owner, book, err := ownerAndBook(ctx)
+	return err == nil && sameID(owner, expense.UserID) && sameID(book, expense.BookID)
+}
+
+// inCallersBook reports whether a row kept per book, like a budget, is in the caller's current book
+func inCallersBook(ctx context.Context, book *uuid.UUID) bool {
+	_, current, err := ownerAndBook(ctx)
+	return err == nil && sameID(current, book)
+}
+
+// ownedByCaller reports whether a row kept per user, like an account, belongs to the caller
+func ownedByCaller(ctx context.Context, owner *uuid.UUID) bool {
+	caller, _, err := ownerAndBook(ctx)
+	return err == nil && sameID(caller, owner)
+}
+
+// sameID reports whether two optional IDs are both unset or equal
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// createdBefore orders expenses by creation, then ID, like the cursors of polling integrations
+func createdBefore(a, b *domain.Expense) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID.String() < b.ID.String()
+}
+
+// containsFold reports whether s contains substr, ignoring case like ILIKE
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// parseDate parses a date filter: a day (meaning its midnight, as in SQL) or an RFC 3339 time
+func parseDate(value string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, true
+	}
+	t, err := time.Parse("2006-01-02", value)
+	return t, err == nil
+}
+
+// clone copies an expense, so callers can't change a stored one without calling Update
+func clone(expense *domain.Expense) *domain.Expense {
+	copied := *expense
+	copied.Tags = slices.Clone(expense.Tags)
+	if expense.Metadata != nil {
+		copied.Metadata = make(domain.Metadata, len(expense.Metadata))
+		for name, value := range expense.Metadata {
+			copied.Metadata[name] = value
+		}
+	}
+	return &copied
+}
@@ -0,0 +1,126 @@
+// Package memory keeps expenses in memory instead of a database
+// This file fills a repository with the fake data of mock mode
+package memory
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For naming the seeded IDs
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/auth"            // For how long the seeded session lasts
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For deterministic IDs
+)
+
+// seedNamespace names the IDs of seeded rows, so they are the same on every start
+var seedNamespace = uuid.MustParse("6f1c2b0e-2a7d-4c55-9a57-3f0e5d1b8c42")
+
+// Seed adds the sample household expenses of the months up to now, in the caller of ctx's book
+// Everything about them follows from now (IDs, amounts, dates and timestamps), so a fixed
+// now gives the same responses on every start
+func Seed(ctx context.Context, repo *Repository, locale string, now time.Time) (int, error) {
+	expenses, err := domain.DemoExpenses(locale, now)
+	if err != nil {
+		return 0, err
+	}
+	for i, expense := range expenses {
+		expense.ID = uuid.NewSHA1(seedNamespace, []byte(fmt.Sprintf("%s/%d", now.UTC().Format(time.RFC3339), i)))
+		// The sample data isn't demo data here: clients should see what real expenses look like
+		expense.Demo = false
+		// Recorded in the evening of the day they happened
+		expense.CreatedAt = expense.Date.Add(19*time.Hour + time.Duration(i)*time.Minute)
+		if err := repo.Create(ctx, expense); err != nil {
+			return i, fmt.Errorf("failed to seed expense %q: %w", expense.Description, err)
+		}
+	}
+	return len(expenses), nil
+}
+
+// demoBudgets are the recurring budgets of the sample household, with categories in canonical form
+// Some are tight on purpose, so budget status shows categories on track, close and over
+var demoBudgets = []struct {
+	category string
+	amount   domain.Money
+}{
+	{"Housing", domain.Cents(100000, "")},
+	{"Food", domain.Cents(35000, "")},
+	{"Transportation", domain.Cents(8000, "")},
+	{"Entertainment", domain.Cents(3000, "")},
+	{"Shopping", domain.Cents(6000, "")},
+}
+
+// SeedBudgets adds the sample household's recurring budgets in the caller of ctx's book
+func SeedBudgets(ctx context.Context, budgets *BudgetRepository, locale string, now time.Time) (int, error) {
+	for i, sample := range demoBudgets {
+		budget, err := domain.NewBudget(domain.LocalizeCategory(locale, sample.category), sample.amount)
+		if err != nil {
+			return i, err
+		}
+		budget.ID = seedID(now, "budget", i)
+		if err := budgets.Create(ctx, budget); err != nil {
+			return i, fmt.Errorf("failed to seed budget %q: %w", budget.Category, err)
+		}
+	}
+	return len(demoBudgets), nil
+}
+
+// SeedAccounts adds a checking account and a cash wallet for the caller of ctx, with a cash
+// withdrawal earmarked for food at the start of now's month
+func SeedAccounts(ctx context.Context, accounts *AccountRepository, locale string, now time.Time) (int, error) {
+	checking, err := domain.NewAccount("Main checking", domain.AccountTypeChecking, domain.DefaultBaseCurrency, domain.Cents(250000, domain.DefaultBaseCurrency))
+	if err != nil {
+		return 0, err
+	}
+	checking.ID = seedID(now, "account", 0)
+	wallet, err := domain.NewAccount("Wallet", domain.AccountTypeCash, domain.DefaultBaseCurrency, domain.Cents(4000, domain.DefaultBaseCurrency))
+	if err != nil {
+		return 0, err
+	}
+	wallet.ID = seedID(now, "account", 1)
+	for i, account := range []*domain.Account{checking, wallet} {
+		if err := accounts.Create(ctx, account); err != nil {
+			return i, fmt.Errorf("failed to seed account %q: %w", account.Name, err)
+		}
+	}
+
+	withdrawal, err := domain.NewTransfer(checking.ID, wallet.ID, domain.Cents(10000, domain.DefaultBaseCurrency), domain.MonthStart(now), domain.LocalizeCategory(locale, "Food"), "ATM withdrawal")
+	if err != nil {
+		return 2, err
+	}
+	withdrawal.ID = seedID(now, "transfer", 0)
+	if err := accounts.CreateTransfer(ctx, withdrawal); err != nil {
+		return 2, fmt.Errorf("failed to seed transfer: %w", err)
+	}
+	return 2, nil
+}
+
+// SeedUser adds the user with the given ID and one session of theirs, logged in at now
+// It returns the ID of the session
+func SeedUser(ctx context.Context, users *UserRepository, tokens *RefreshTokenRepository, userID uuid.UUID, now time.Time) (uuid.UUID, error) {
+	user, err := domain.NewUser("mock@example.com", "Mock User")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	user.ID = userID
+	if err := users.Create(ctx, user); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to seed user: %w", err)
+	}
+
+	// Nobody holds the token behind the hash; the session is only there to be listed
+	id := seedID(now, "session", 0)
+	token := domain.NewRefreshToken(userID, id.String(), now.Add(auth.DefaultRefreshTokenTTL), nil, now)
+	token.ID = id
+	token.SessionID = id
+	token.UserAgent = "MyExpenses mock mode"
+	token.IPAddress = "127.0.0.1"
+	if err := tokens.Create(ctx, token); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to seed session: %w", err)
+	}
+	return id, nil
+}
+
+// seedID is the ID of the i-th seeded row of a kind
+func seedID(now time.Time, kind string, i int) uuid.UUID {
+	return uuid.NewSHA1(seedNamespace, []byte(fmt.Sprintf("%s/%s/%d", now.UTC().Format(time.RFC3339), kind, i)))
+}
@@ -0,0 +1,294 @@
+// Package memory keeps expenses in memory instead of a database
+// This file implements the domain.UserRepository and domain.RefreshTokenRepository interfaces
+package memory
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For list order
+	"strings" // For email searches
+	"sync"    // For guarding the users and tokens
+	"time"    // For revocation and expiry times
+
+	"myexpenses/internal/clock"           // Time source for CreatedAt/UpdatedAt
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing
+)
+
+// UserRepository implements domain.UserRepository in memory
+type UserRepository struct {
+	clock clock.Clock
+
+	mu    sync.RWMutex
+	users map[uuid.UUID]*domain.User
+}
+
+// NewUserRepository creates an empty user repository
+func NewUserRepository(clk clock.Clock) *UserRepository {
+	return &UserRepository{
+		clock: clock.Or(clk),
+		users: make(map[uuid.UUID]*domain.User),
+	}
+}
+
+// Create saves a new user, or returns domain.ErrEmailTaken
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	now := r.clock.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.users[user.ID]; exists {
+		return fmt.Errorf("user %s already exists", user.ID)
+	}
+	for _, stored := range r.users {
+		if stored.Email == user.Email {
+			return domain.ErrEmailTaken
+		}
+	}
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+// GetByID retrieves a user by its unique identifier
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// GetByEmail retrieves a user by email address
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	email = domain.NormalizeEmail(email)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+// List returns one page of the users matching filter, ordered by email, and how many match in total
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int64, error) {
+	email := domain.NormalizeEmail(filter.Email)
+	r.mu.RLock()
+	var users []*domain.User
+	for _, user := range r.users {
+		if strings.Contains(user.Email, email) {
+			copied := *user
+			users = append(users, &copied)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(users, func(i, j int) bool { return users[i].Email < users[j].Email })
+
+	total := int64(len(users))
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = domain.DefaultUserPageSize
+	}
+	users = users[min(max(filter.Offset, 0), len(users)):]
+	return users[:min(limit, len(users))], total, nil
+}
+
+// UpdateRole saves the user's role
+func (r *UserRepository) UpdateRole(ctx context.Context, user *domain.User) error {
+	return r.update(user.ID, func(stored *domain.User) { stored.Role = user.Role })
+}
+
+// UpdateDisabled saves whether the user is disabled
+func (r *UserRepository) UpdateDisabled(ctx context.Context, user *domain.User) error {
+	return r.update(user.ID, func(stored *domain.User) { stored.DisabledAt = user.DisabledAt })
+}
+
+// UpdateErasure saves when the user's account is erased
+func (r *UserRepository) UpdateErasure(ctx context.Context, user *domain.User) error {
+	return r.update(user.ID, func(stored *domain.User) { stored.ErasesAt = user.ErasesAt })
+}
+
+// UpdatePassword saves the user's password hash
+func (r *UserRepository) UpdatePassword(ctx context.Context, user *domain.User) error {
+	return r.update(user.ID, func(stored *domain.User) { stored.PasswordHash = user.PasswordHash })
+}
+
+// Delete removes a user
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[userID]; !ok {
+		return domain.ErrUserNotFound
+	}
+	delete(r.users, userID)
+	return nil
+}
+
+// update changes one column of a stored user, or returns domain.ErrUserNotFound
+func (r *UserRepository) update(id uuid.UUID, change func(*domain.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.users[id]
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	change(stored)
+	stored.UpdatedAt = r.clock.Now()
+	return nil
+}
+
+// RefreshTokenRepository implements domain.RefreshTokenRepository in memory
+type RefreshTokenRepository struct {
+	clock clock.Clock
+
+	mu     sync.RWMutex
+	tokens map[uuid.UUID]*domain.RefreshToken
+}
+
+// NewRefreshTokenRepository creates an empty refresh token repository
+func NewRefreshTokenRepository(clk clock.Clock) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		clock:  clock.Or(clk),
+		tokens: make(map[uuid.UUID]*domain.RefreshToken),
+	}
+}
+
+// Create saves a new refresh token
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	token.CreatedAt = r.clock.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *token
+	r.tokens[token.ID] = &copied
+	return nil
+}
+
+// GetByHash retrieves a token by the hash of its value
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrInvalidRefreshToken
+}
+
+// Revoke marks a token as revoked; of two concurrent revocations only one gets true
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[id]
+	if !ok || token.RevokedAt != nil {
+		return false, nil
+	}
+	token.RevokedAt = &at
+	return true, nil
+}
+
+// Use marks a token as used up by a refresh; like Revoke only one refresh gets true
+func (r *RefreshTokenRepository) Use(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[id]
+	if !ok || token.RevokedAt != nil {
+		return false, nil
+	}
+	token.RevokedAt = &at
+	token.Used = true
+	return true, nil
+}
+
+// SessionActive reports whether the newest token of a session is unrevoked or was used for the next one
+func (r *RefreshTokenRepository) SessionActive(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var newest *domain.RefreshToken
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.Session() == sessionID &&
+			(newest == nil || token.CreatedAt.After(newest.CreatedAt)) {
+			newest = token
+		}
+	}
+	return newest != nil && (newest.RevokedAt == nil || newest.Used), nil
+}
+
+// ListUsable returns the usable tokens of a user, newest first
+func (r *RefreshTokenRepository) ListUsable(ctx context.Context, userID uuid.UUID, now time.Time) ([]*domain.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var tokens []*domain.RefreshToken
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil && token.ExpiresAt.After(now) {
+			copied := *token
+			tokens = append(tokens, &copied)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CreatedAt.After(tokens[j].CreatedAt) })
+	return tokens, nil
+}
+
+// RevokeSession revokes the usable tokens of one session of a user
+func (r *RefreshTokenRepository) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID, at time.Time) (int64, error) {
+	return r.revoke(func(token *domain.RefreshToken) bool {
+		return token.UserID == userID && token.Session() == sessionID
+	}, at), nil
+}
+
+// RevokeAllForUser revokes every usable token of a user
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) (int64, error) {
+	return r.revoke(func(token *domain.RefreshToken) bool { return token.UserID == userID }, at), nil
+}
+
+// DeleteExpired deletes the tokens that expired before the given time
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted int64
+	for id, token := range r.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.tokens, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// revoke revokes the unrevoked tokens that match and returns how many there were
+func (r *RefreshTokenRepository) revoke(match func(*domain.RefreshToken) bool, at time.Time) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var revoked int64
+	for _, token := range r.tokens {
+		if token.RevokedAt == nil && match(token) {
+			token.RevokedAt = &at
+			revoked++
+		}
+	}
+	return revoked
+}
@@ -0,0 +1,136 @@
+// Package notifying contains decorators that report successful writes to a callback
+// They let caches drop what a write made stale no matter which use case wrote: the expense
+// service, imports, reconciliation and the rest all write through the same repository
+package notifying
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"time"    // For the explain pass-through signatures
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// WriteFunc is called after a write succeeded, with the context of the write
+type WriteFunc func(ctx context.Context)
+
+// Repository decorates a domain.Repository, calling onWrite after every successful change
+type Repository struct {
+	next    domain.Repository
+	onWrite WriteFunc
+}
+
+// NewRepository wraps next so successful writes are reported to onWrite
+func NewRepository(next domain.Repository, onWrite WriteFunc) *Repository {
+	return &Repository{next: next, onWrite: onWrite}
+}
+
+// notify reports a write if it succeeded
+func (r *Repository) notify(ctx context.Context, err error) error {
+	if err == nil {
+		r.onWrite(ctx)
+	}
+	return err
+}
+
+// Create implements domain.Repository
+func (r *Repository) Create(ctx context.Context, expense *domain.Expense) error {
+	return r.notify(ctx, r.next.Create(ctx, expense))
+}
+
+// GetByID implements domain.Repository
+func (r *Repository) GetByID(ctx context.Context, id string) (*domain.Expense, error) {
+	return r.next.GetByID(ctx, id)
+}
+
+// GetAll implements domain.Repository
+func (r *Repository) GetAll(ctx context.Context, filters map[string]interface{}) ([]*domain.Expense, error) {
+	return r.next.GetAll(ctx, filters)
+}
+
+// Update implements domain.Repository
+func (r *Repository) Update(ctx context.Context, expense *domain.Expense) error {
+	return r.notify(ctx, r.next.Update(ctx, expense))
+}
+
+// Delete implements domain.Repository
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	return r.notify(ctx, r.next.Delete(ctx, id))
+}
+
+// Exists implements domain.Repository
+func (r *Repository) Exists(ctx context.Context, id string) (bool, error) {
+	return r.next.Exists(ctx, id)
+}
+
+// Count implements domain.Repository
+func (r *Repository) Count(ctx context.Context, filters map[string]interface{}) (int64, error) {
+	return r.next.Count(ctx, filters)
+}
+
+// Stream implements domain.Repository
+func (r *Repository) Stream(ctx context.Context, filters map[string]interface{}, fn func(*domain.Expense) error) error {
+	return r.next.Stream(ctx, filters, fn)
+}
+
+// ExplainExpenses passes query plan requests through to the wrapped repository
+// Decorators must forward optional capabilities, or wrapping would silently switch them off
+func (r *Repository) ExplainExpenses(ctx context.Context, filters map[string]interface{}) (*domain.QueryPlan, error) {
+	explainer, ok := r.next.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+	return explainer.ExplainExpenses(ctx, filters)
+}
+
+// ExplainSpending passes query plan requests through to the wrapped repository
+func (r *Repository) ExplainSpending(ctx context.Context, from, to time.Time) ([]*domain.QueryPlan, error) {
+	explainer, ok := r.next.(domain.QueryExplainer)
+	if !ok {
+		return nil, domain.ErrExplainUnavailable
+	}
+	return explainer.ExplainSpending(ctx, from, to)
+}
+
+// BudgetRepository decorates a domain.BudgetRepository, calling onWrite after every successful change
+type BudgetRepository struct {
+	next    domain.BudgetRepository
+	onWrite WriteFunc
+}
+
+// NewBudgetRepository wraps next so successful writes are reported to onWrite
+func NewBudgetRepository(next domain.BudgetRepository, onWrite WriteFunc) *BudgetRepository {
+	return &BudgetRepository{next: next, onWrite: onWrite}
+}
+
+// notify reports a write if it succeeded
+func (r *BudgetRepository) notify(ctx context.Context, err error) error {
+	if err == nil {
+		r.onWrite(ctx)
+	}
+	return err
+}
+
+// Create implements domain.BudgetRepository
+func (r *BudgetRepository) Create(ctx context.Context, budget *domain.Budget) error {
+	return r.notify(ctx, r.next.Create(ctx, budget))
+}
+
+// GetByID implements domain.BudgetRepository
+func (r *BudgetRepository) GetByID(ctx context.Context, id string) (*domain.Budget, error) {
+	return r.next.GetByID(ctx, id)
+}
+
+// List implements domain.BudgetRepository
+func (r *BudgetRepository) List(ctx context.Context) ([]*domain.Budget, error) {
+	return r.next.List(ctx)
+}
+
+// Update implements domain.BudgetRepository
+func (r *BudgetRepository) Update(ctx context.Context, budget *domain.Budget) error {
+	return r.notify(ctx, r.next.Update(ctx, budget))
+}
+
+// Delete implements domain.BudgetRepository
+func (r *BudgetRepository) Delete(ctx context.Context, id string) error {
+	return r.notify(ctx, r.next.Delete(ctx, id))
+}
@@ -0,0 +1,81 @@
+// Package ocr contains text extraction implementations for receipt attachments
+// This is part of the infrastructure layer - it wraps external OCR tools behind domain.TextExtractor
+// and domain.OCRProvider
+package ocr
+
+import (
+	"bytes"   // For capturing command output
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"io"      // For streaming attachment content
+	"os/exec" // For running the OCR command line tools
+	"strings" // For cleaning up recognized text
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer (for language names)
+)
+
+// Tesseract extracts text using the tesseract (images) and pdftotext (PDFs) command line tools
+// Both tools read from stdin and write to stdout, so no temporary files are needed
+type Tesseract struct {
+	// languages is the tesseract language list, e.g. "eng" or "eng+deu"
+	languages string
+}
+
+// NewTesseract creates a new tesseract based extractor for the given languages
+// An empty language list falls back to English
+func NewTesseract(languages string) *Tesseract {
+	if languages == "" {
+		languages = "eng"
+	}
+	return &Tesseract{languages: languages}
+}
+
+// ExtractText implements domain.TextExtractor
+func (t *Tesseract) ExtractText(ctx context.Context, contentType string, r io.Reader) (string, error) {
+	return t.Recognize(ctx, contentType, r, nil)
+}
+
+// Recognize implements domain.OCRProvider
+// languages replace the extractor's languages for this file; PDFs are read from their text
+// layer, which needs no languages
+func (t *Tesseract) Recognize(ctx context.Context, contentType string, r io.Reader, languages []string) (string, error) {
+	list := domain.TesseractLanguages(languages)
+	if list == "" {
+		list = t.languages
+	}
+
+	// Step 1: Pick the tool matching the file type
+	var cmd *exec.Cmd
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		// "stdin stdout" tells tesseract to read the image from stdin and print the text
+		cmd = exec.CommandContext(ctx, "tesseract", "stdin", "stdout", "-l", list)
+	case contentType == "application/pdf":
+		// "- -" tells pdftotext to read from stdin and write to stdout
+		cmd = exec.CommandContext(ctx, "pdftotext", "-layout", "-", "-")
+	default:
+		// Other file types (spreadsheets, plain binaries) carry no recognizable text
+		return "", nil
+	}
+
+	// Step 2: Run the tool with the file content as input
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = r
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("text extraction failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// Step 3: Collapse whitespace so the stored text is compact and index friendly
+	return strings.Join(strings.Fields(stdout.String()), " "), nil
+}
+
+// Noop is a TextExtractor that never recognizes any text
+// It is used when OCR is disabled so uploads still work without the OCR tools installed
+type Noop struct{}
+
+// ExtractText implements domain.TextExtractor
+func (Noop) ExtractText(ctx context.Context, contentType string, r io.Reader) (string, error) {
+	return "", nil
+}
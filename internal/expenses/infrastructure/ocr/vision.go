@@ -0,0 +1,150 @@
+// Package ocr contains text extraction implementations for receipt attachments
+// This file implements domain.OCRProvider over Google Cloud Vision, the hosted OCR provider
+package ocr
+
+import (
+	"bytes"           // For building request bodies
+	"context"         // For request context (cancellation, timeouts)
+	"encoding/base64" // Vision takes file content base64 encoded
+	"encoding/json"   // For encoding requests and decoding answers
+	"fmt"             // For formatted string operations and error wrapping
+	"io"              // For reading attachment content and error answers
+	"net/http"        // For calling the API
+	"net/url"         // For passing the API key
+	"strings"         // For cleaning up recognized text
+	"time"            // For the request timeout
+)
+
+// DefaultVisionURL is Google Cloud Vision's API
+const DefaultVisionURL = "https://vision.googleapis.com"
+
+// visionMaxPDFPages is how many pages of a PDF Vision reads in one synchronous request
+// Receipts and invoices rarely have more; later pages are left out
+const visionMaxPDFPages = 5
+
+// Vision recognizes text with Google Cloud Vision's document text detection
+// It reads crumpled, skewed and dim receipt photos much better than tesseract, but every
+// image or PDF page is billed
+type Vision struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewVision creates a Vision provider authenticated with an API key
+func NewVision(apiKey string) *Vision {
+	return &Vision{
+		apiKey:  apiKey,
+		baseURL: DefaultVisionURL,
+		client:  &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// visionRequest is one file to annotate, in the shape both images:annotate and files:annotate take
+type visionRequest struct {
+	Image       *visionContent `json:"image,omitempty"`
+	InputConfig *visionContent `json:"inputConfig,omitempty"`
+	Features    []struct {
+		Type string `json:"type"`
+	} `json:"features"`
+	ImageContext struct {
+		LanguageHints []string `json:"languageHints,omitempty"`
+	} `json:"imageContext"`
+	Pages []int `json:"pages,omitempty"`
+}
+
+// visionContent is a file sent inline
+type visionContent struct {
+	Content  string `json:"content"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// visionAnswer is the annotation of one image or page
+type visionAnswer struct {
+	FullTextAnnotation struct {
+		Text string `json:"text"`
+	} `json:"fullTextAnnotation"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Recognize implements domain.OCRProvider
+// languages are passed as hints; Vision detects the language itself without them
+func (v *Vision) Recognize(ctx context.Context, contentType string, r io.Reader, languages []string) (string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	// Step 1: Images go to images:annotate, PDFs to files:annotate
+	req := visionRequest{}
+	req.Features = append(req.Features, struct {
+		Type string `json:"type"`
+	}{Type: "DOCUMENT_TEXT_DETECTION"})
+	req.ImageContext.LanguageHints = languages
+	encoded := base64.StdEncoding.EncodeToString(content)
+	path := "/v1/images:annotate"
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		req.Image = &visionContent{Content: encoded}
+	case contentType == "application/pdf":
+		path = "/v1/files:annotate"
+		req.InputConfig = &visionContent{Content: encoded, MimeType: contentType}
+		for page := 1; page <= visionMaxPDFPages; page++ {
+			req.Pages = append(req.Pages, page)
+		}
+	default:
+		return "", nil
+	}
+
+	// Step 2: Call the API
+	var answer struct {
+		Responses []struct {
+			visionAnswer
+
+			// Responses are the pages of a PDF
+			Responses []visionAnswer `json:"responses"`
+		} `json:"responses"`
+	}
+	if err := v.call(ctx, path, map[string]any{"requests": []visionRequest{req}}, &answer); err != nil {
+		return "", err
+	}
+
+	// Step 3: Join the text of the image or pages, collapsing whitespace like tesseract's
+	var texts []string
+	for _, response := range answer.Responses {
+		pages := append([]visionAnswer{response.visionAnswer}, response.Responses...)
+		for _, page := range pages {
+			if page.Error != nil {
+				return "", fmt.Errorf("vision text detection failed: %s", page.Error.Message)
+			}
+			texts = append(texts, page.FullTextAnnotation.Text)
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(texts, " ")), " "), nil
+}
+
+// call sends a JSON request to the API and decodes the answer into out
+func (v *Vision) call(ctx context.Context, path string, body any, out any) error {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.baseURL+path+"?key="+url.QueryEscape(v.apiKey), bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vision request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("vision answered %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
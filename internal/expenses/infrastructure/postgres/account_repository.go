@@ -0,0 +1,223 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.AccountRepository interface for accounts and transfers
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// accountAmount is the SQL for what an expense e took from its account a, in the account's
+// currency; it mirrors domain.Account.AmountOf
+const accountAmount = "CASE WHEN e.currency <> a.currency AND e.base_currency = a.currency AND e.base_amount <> 0 " +
+	"THEN e.base_amount ELSE e.amount END"
+
+// AccountRepository implements the domain.AccountRepository interface using PostgreSQL
+// Accounts and transfers are owned like employers: each caller only sees their own
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository creates a new PostgreSQL account repository
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// Create saves a new account owned by the caller
+func (r *AccountRepository) Create(ctx context.Context, account *domain.Account) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	account.UserID = owner
+	return r.db.WithContext(ctx).Create(account).Error
+}
+
+// GetByID retrieves an account by its ID
+func (r *AccountRepository) GetByID(ctx context.Context, id string) (*domain.Account, error) {
+	accountID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	var account domain.Account
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", accountID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return &account, nil
+}
+
+// List returns all accounts ordered by name
+func (r *AccountRepository) List(ctx context.Context) ([]*domain.Account, error) {
+	var accounts []*domain.Account
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Order("name ASC").Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// FindByType returns the oldest account of the given type
+func (r *AccountRepository) FindByType(ctx context.Context, accountType domain.AccountType) (*domain.Account, error) {
+	var account domain.Account
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("type = ?", accountType).Order("created_at ASC").First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to find account: %w", err)
+	}
+	return &account, nil
+}
+
+// CreateTransfer saves a transfer between the caller's accounts
+func (r *AccountRepository) CreateTransfer(ctx context.Context, transfer *domain.Transfer) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	transfer.UserID = owner
+	return r.db.WithContext(ctx).Create(transfer).Error
+}
+
+// ListTransfers returns the transfers into or out of an account, newest first
+func (r *AccountRepository) ListTransfers(ctx context.Context, accountID string) ([]*domain.Transfer, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	var transfers []*domain.Transfer
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").
+		Where("from_account_id = ? OR to_account_id = ?", id, id).
+		Order("date DESC").
+		Find(&transfers).Error; err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// Totals returns the transfer and expense sums of an account
+// The three sums are computed in one round trip with scalar sub-selects
+func (r *AccountRepository) Totals(ctx context.Context, accountID string) (*domain.AccountTotals, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	var totals domain.AccountTotals
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT
+			(SELECT COALESCE(SUM(amount), 0) FROM transfers WHERE to_account_id = @id)   AS transfers_in,
+			(SELECT COALESCE(SUM(amount), 0) FROM transfers WHERE from_account_id = @id) AS transfers_out,
+			(SELECT COALESCE(SUM(`+accountAmount+`), 0)
+			 FROM expenses e JOIN accounts a ON a.id = e.account_id
+			 WHERE e.account_id = @id)                                                  AS spent`,
+		map[string]interface{}{"id": id}).Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute account totals: %w", err)
+	}
+	return &totals, nil
+}
+
+// EnvelopeTotals returns, for each envelope funded into the account, how much was funded and spent
+// Spending is attributed to an envelope when the expense category equals the envelope name
+func (r *AccountRepository) EnvelopeTotals(ctx context.Context, accountID string) ([]*domain.EnvelopeTotals, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	var totals []*domain.EnvelopeTotals
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT f.envelope,
+		       f.funded,
+		       COALESCE((SELECT SUM(e.amount) FROM expenses e
+		                 WHERE e.account_id = @id AND e.category = f.envelope), 0) AS spent
+		FROM (SELECT envelope, SUM(amount) AS funded
+		      FROM transfers
+		      WHERE to_account_id = @id AND envelope <> ''
+		      GROUP BY envelope) f
+		ORDER BY f.envelope`,
+		map[string]interface{}{"id": id}).Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute envelope totals: %w", err)
+	}
+	return totals, nil
+}
+
+// Update saves the name and opening balance of one of the caller's accounts
+func (r *AccountRepository) Update(ctx context.Context, account *domain.Account) error {
+	result := ownedBy(ctx, r.db.WithContext(ctx).Model(account), "user_id").
+		Select("name", "opening_balance").
+		Updates(account)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update account: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAccountNotFound
+	}
+	return nil
+}
+
+// Delete removes one of the caller's accounts that nothing refers to
+func (r *AccountRepository) Delete(ctx context.Context, id string) error {
+	accountID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrAccountNotFound
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Step 1: The account must be the caller's
+		var account domain.Account
+		if err := ownedBy(ctx, tx, "user_id").Where("id = ?", accountID).First(&account).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrAccountNotFound
+			}
+			return fmt.Errorf("failed to get account: %w", err)
+		}
+
+		// Step 2: Refuse if expenses or transfers refer to it
+		var inUse bool
+		err := tx.Raw(`SELECT EXISTS (SELECT 1 FROM expenses WHERE account_id = @id)
+			OR EXISTS (SELECT 1 FROM transfers WHERE from_account_id = @id OR to_account_id = @id)`,
+			map[string]interface{}{"id": accountID}).Scan(&inUse).Error
+		if err != nil {
+			return fmt.Errorf("failed to check account use: %w", err)
+		}
+		if inUse {
+			return domain.ErrAccountInUse
+		}
+
+		// Step 3: Delete it
+		if err := tx.Delete(&account).Error; err != nil {
+			return fmt.Errorf("failed to delete account: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListExpenses returns the expenses paid from an account
+// The account is checked by the caller; expenses are only listed if they are the caller's too
+func (r *AccountRepository) ListExpenses(ctx context.Context, accountID string) ([]*domain.Expense, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	var expenses []*domain.Expense
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").
+		Where("account_id = ?", id).
+		Order("date ASC, created_at ASC").
+		Find(&expenses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list account expenses: %w", err)
+	}
+	return expenses, nil
+}
@@ -0,0 +1,112 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.APIKeyRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For last-used times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// APIKeyRepository implements the domain.APIKeyRepository interface using PostgreSQL
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new PostgreSQL API key repository
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create saves a new API key
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	return nil
+}
+
+// List returns a user's API keys, newest first
+func (r *APIKeyRepository) List(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// GetByID retrieves one of a user's keys
+func (r *APIKeyRepository) GetByID(ctx context.Context, userID uuid.UUID, id string) (*domain.APIKey, error) {
+	keyID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+
+	var key domain.APIKey
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", keyID, userID).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return &key, nil
+}
+
+// GetByHash retrieves the key with the given hash
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return &key, nil
+}
+
+// Update saves a key's name and scope
+func (r *APIKeyRepository) Update(ctx context.Context, key *domain.APIKey) error {
+	result := r.db.WithContext(ctx).Model(key).
+		Where("user_id = ?", key.UserID).
+		Updates(map[string]interface{}{"name": key.Name, "scope": key.Scope})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update API key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// Delete removes one of a user's keys
+func (r *APIKeyRepository) Delete(ctx context.Context, userID uuid.UUID, id string) error {
+	keyID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", keyID, userID).Delete(&domain.APIKey{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete API key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records that a key was used
+// UpdateColumn leaves updated_at alone: using a key doesn't change it
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := r.db.WithContext(ctx).Model(&domain.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+	if err != nil {
+		return fmt.Errorf("failed to record API key use: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ApprovalChainRepository and domain.TripApprovalRepository interfaces
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid"         // For trip IDs
+	"github.com/jackc/pgx/v5/pgconn" // For recognizing unique constraint violations
+	"gorm.io/gorm"                   // GORM ORM library
+	"gorm.io/gorm/clause"            // For upserts
+)
+
+// ApprovalChainRepository implements the domain.ApprovalChainRepository interface using PostgreSQL
+type ApprovalChainRepository struct {
+	db *gorm.DB
+}
+
+// NewApprovalChainRepository creates a new PostgreSQL approval chain repository
+func NewApprovalChainRepository(db *gorm.DB) *ApprovalChainRepository {
+	return &ApprovalChainRepository{db: db}
+}
+
+// Get retrieves a tenant's chain with its steps in order
+func (r *ApprovalChainRepository) Get(ctx context.Context, tenantID string) (*domain.ApprovalChain, error) {
+	var chain domain.ApprovalChain
+	err := r.db.WithContext(ctx).
+		Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("tenant_id = ?", tenantID).First(&chain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrApprovalChainNotFound
+		}
+		return nil, fmt.Errorf("failed to get approval chain: %w", err)
+	}
+	return &chain, nil
+}
+
+// Save replaces a tenant's chain in a transaction: the old steps go, the new ones are inserted
+func (r *ApprovalChainRepository) Save(ctx context.Context, chain *domain.ApprovalChain) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ?", chain.TenantID).Delete(&domain.ApprovalStep{}).Error; err != nil {
+			return fmt.Errorf("failed to replace approval steps: %w", err)
+		}
+		err := tx.Omit("Steps").Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
+		}).Create(chain).Error
+		if err != nil {
+			return fmt.Errorf("failed to save approval chain: %w", err)
+		}
+		if err := tx.Create(chain.Steps).Error; err != nil {
+			return fmt.Errorf("failed to save approval steps: %w", err)
+		}
+		return nil
+	})
+}
+
+// Delete removes a tenant's chain and its steps
+func (r *ApprovalChainRepository) Delete(ctx context.Context, tenantID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ?", tenantID).Delete(&domain.ApprovalStep{}).Error; err != nil {
+			return fmt.Errorf("failed to delete approval steps: %w", err)
+		}
+		result := tx.Where("tenant_id = ?", tenantID).Delete(&domain.ApprovalChain{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete approval chain: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrApprovalChainNotFound
+		}
+		return nil
+	})
+}
+
+// TripApprovalRepository implements the domain.TripApprovalRepository interface using PostgreSQL
+type TripApprovalRepository struct {
+	db *gorm.DB
+}
+
+// NewTripApprovalRepository creates a new PostgreSQL trip approval repository
+func NewTripApprovalRepository(db *gorm.DB) *TripApprovalRepository {
+	return &TripApprovalRepository{db: db}
+}
+
+// ListByTrip returns the approvals of a trip in the order they were given
+func (r *TripApprovalRepository) ListByTrip(ctx context.Context, tripID uuid.UUID) ([]*domain.TripApproval, error) {
+	var approvals []*domain.TripApproval
+	if err := r.db.WithContext(ctx).Where("trip_id = ?", tripID).Order("approved_at ASC").Find(&approvals).Error; err != nil {
+		return nil, fmt.Errorf("failed to list trip approvals: %w", err)
+	}
+	return approvals, nil
+}
+
+// Create records an approval
+// The unique index on (trip_id, step_id) settles two approvers approving the same step at once
+func (r *TripApprovalRepository) Create(ctx context.Context, approval *domain.TripApproval) error {
+	if err := r.db.WithContext(ctx).Create(approval).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.ErrStepAlreadyApproved
+		}
+		return fmt.Errorf("failed to record trip approval: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ApprovalDelegationRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// ApprovalDelegationRepository implements the domain.ApprovalDelegationRepository interface using PostgreSQL
+type ApprovalDelegationRepository struct {
+	db *gorm.DB
+}
+
+// NewApprovalDelegationRepository creates a new PostgreSQL delegation repository
+func NewApprovalDelegationRepository(db *gorm.DB) *ApprovalDelegationRepository {
+	return &ApprovalDelegationRepository{db: db}
+}
+
+// Create saves a new delegation
+func (r *ApprovalDelegationRepository) Create(ctx context.Context, delegation *domain.ApprovalDelegation) error {
+	if err := r.db.WithContext(ctx).Create(delegation).Error; err != nil {
+		return fmt.Errorf("failed to create delegation: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a delegation
+func (r *ApprovalDelegationRepository) GetByID(ctx context.Context, id string) (*domain.ApprovalDelegation, error) {
+	delegationID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrDelegationNotFound
+	}
+	var delegation domain.ApprovalDelegation
+	if err := r.db.WithContext(ctx).Where("id = ?", delegationID).First(&delegation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrDelegationNotFound
+		}
+		return nil, fmt.Errorf("failed to get delegation: %w", err)
+	}
+	return &delegation, nil
+}
+
+// ListByUser returns the delegations userID gave or received, latest start first
+func (r *ApprovalDelegationRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.ApprovalDelegation, error) {
+	var delegations []*domain.ApprovalDelegation
+	err := r.db.WithContext(ctx).
+		Where("approver_id = ? OR delegate_id = ?", userID, userID).
+		Order("starts_on DESC").Find(&delegations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delegations: %w", err)
+	}
+	return delegations, nil
+}
+
+// ListActiveByDelegate returns the uncancelled delegations to delegateID covering day
+func (r *ApprovalDelegationRepository) ListActiveByDelegate(ctx context.Context, delegateID uuid.UUID, day time.Time) ([]*domain.ApprovalDelegation, error) {
+	var delegations []*domain.ApprovalDelegation
+	err := r.db.WithContext(ctx).
+		Where("delegate_id = ? AND cancelled_at IS NULL AND starts_on <= ? AND ends_on >= ?", delegateID, day, day).
+		Find(&delegations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active delegations: %w", err)
+	}
+	return delegations, nil
+}
+
+// Cancel ends a delegation early
+func (r *ApprovalDelegationRepository) Cancel(ctx context.Context, id uuid.UUID, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&domain.ApprovalDelegation{}).
+		Where("id = ? AND cancelled_at IS NULL", id).
+		Update("cancelled_at", at)
+	if result.Error != nil {
+		return fmt.Errorf("failed to cancel delegation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrDelegationNotFound
+	}
+	return nil
+}
+
+// ListUnreverted returns the ended delegations whose end hasn't been recorded yet
+func (r *ApprovalDelegationRepository) ListUnreverted(ctx context.Context, day time.Time) ([]*domain.ApprovalDelegation, error) {
+	var delegations []*domain.ApprovalDelegation
+	err := r.db.WithContext(ctx).
+		Where("ends_on < ? AND cancelled_at IS NULL AND reverted_at IS NULL", day).
+		Order("ends_on ASC").Find(&delegations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ended delegations: %w", err)
+	}
+	return delegations, nil
+}
+
+// MarkReverted records that the end of a delegation has been recorded
+func (r *ApprovalDelegationRepository) MarkReverted(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&domain.ApprovalDelegation{}).Where("id = ?", id).Update("reverted_at", at).Error; err != nil {
+		return fmt.Errorf("failed to mark delegation reverted: %w", err)
+	}
+	return nil
+}
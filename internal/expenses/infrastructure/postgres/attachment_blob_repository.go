@@ -0,0 +1,72 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.AttachmentBlobRepository interface for shared attachment content
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// AttachmentBlobRepository implements the domain.AttachmentBlobRepository interface using PostgreSQL
+// Reference counts are changed with single statements, so concurrent uploads and deletes can't lose a reference
+type AttachmentBlobRepository struct {
+	db *gorm.DB
+}
+
+// NewAttachmentBlobRepository creates a new PostgreSQL attachment blob repository
+func NewAttachmentBlobRepository(db *gorm.DB) *AttachmentBlobRepository {
+	return &AttachmentBlobRepository{db: db}
+}
+
+// Acquire adds a reference to a blob, creating it on first use
+// The upsert runs as one statement, so two identical uploads racing each other end up sharing one blob
+func (r *AttachmentBlobRepository) Acquire(ctx context.Context, blob *domain.AttachmentBlob) (*domain.AttachmentBlob, error) {
+	var stored domain.AttachmentBlob
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO attachment_blobs (tenant_id, hash, storage_key, size, ref_count, created_at)
+		VALUES (?, ?, ?, ?, 1, NOW())
+		ON CONFLICT (tenant_id, hash) DO UPDATE SET ref_count = attachment_blobs.ref_count + 1
+		RETURNING tenant_id, hash, storage_key, size, ref_count, created_at`,
+		blob.TenantID, blob.Hash, blob.StorageKey, blob.Size,
+	).Scan(&stored).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to reference attachment blob: %w", err)
+	}
+	return &stored, nil
+}
+
+// Release drops a reference to a blob and removes the blob once nobody uses it
+// The removal only happens while the count is still 0, so a concurrent Acquire keeps the file alive
+func (r *AttachmentBlobRepository) Release(ctx context.Context, tenantID, hash string) (string, error) {
+	// Step 1: Drop the reference
+	var remaining struct {
+		RefCount   int64
+		StorageKey string
+	}
+	result := r.db.WithContext(ctx).Raw(`
+		UPDATE attachment_blobs SET ref_count = ref_count - 1
+		WHERE tenant_id = ? AND hash = ?
+		RETURNING ref_count, storage_key`,
+		tenantID, hash,
+	).Scan(&remaining)
+	if result.Error != nil {
+		return "", fmt.Errorf("failed to release attachment blob: %w", result.Error)
+	}
+	if result.RowsAffected == 0 || remaining.RefCount > 0 {
+		return "", nil
+	}
+
+	// Step 2: Remove the unused blob
+	deleted := r.db.WithContext(ctx).Where("tenant_id = ? AND hash = ? AND ref_count <= 0", tenantID, hash).Delete(&domain.AttachmentBlob{})
+	if deleted.Error != nil {
+		return "", fmt.Errorf("failed to delete attachment blob: %w", deleted.Error)
+	}
+	if deleted.RowsAffected == 0 {
+		return "", nil
+	}
+	return remaining.StorageKey, nil
+}
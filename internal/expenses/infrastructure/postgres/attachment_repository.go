@@ -0,0 +1,104 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.AttachmentRepository interface for receipt metadata
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// AttachmentRepository implements the domain.AttachmentRepository interface using PostgreSQL
+type AttachmentRepository struct {
+	// db is the GORM database connection shared with the expense repository
+	db *gorm.DB
+}
+
+// NewAttachmentRepository creates a new PostgreSQL attachment repository
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	return &AttachmentRepository{
+		db: db, // Store the database connection
+	}
+}
+
+// Create saves the attachment metadata
+// This method implements the domain.AttachmentRepository.Create interface
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+// GetByID retrieves an attachment by its ID
+// This method implements the domain.AttachmentRepository.GetByID interface
+func (r *AttachmentRepository) GetByID(ctx context.Context, id string) (*domain.Attachment, error) {
+	// Step 1: Validate the ID format
+	attachmentID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	// Step 2: Query the attachment
+	var attachment domain.Attachment
+	if err := r.db.WithContext(ctx).Where("id = ?", attachmentID).First(&attachment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	return &attachment, nil
+}
+
+// ListByExpense returns all attachments of an expense, oldest first
+// This method implements the domain.AttachmentRepository.ListByExpense interface
+func (r *AttachmentRepository) ListByExpense(ctx context.Context, expenseID string) ([]*domain.Attachment, error) {
+	id, err := uuid.Parse(expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	var attachments []*domain.Attachment
+	if err := r.db.WithContext(ctx).Where("expense_id = ?", id).Order("created_at ASC").Find(&attachments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// UpdateOCRText replaces the recognized text of an attachment
+// This method implements the domain.AttachmentRepository.UpdateOCRText interface
+func (r *AttachmentRepository) UpdateOCRText(ctx context.Context, id string, text string) error {
+	attachmentID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Model(&domain.Attachment{}).Where("id = ?", attachmentID).Update("ocr_text", text)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update attachment text: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// Delete removes the attachment metadata by its ID
+// This method implements the domain.AttachmentRepository.Delete interface
+func (r *AttachmentRepository) Delete(ctx context.Context, id string) error {
+	attachmentID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ?", attachmentID).Delete(&domain.Attachment{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete attachment: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAttachmentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.AuditRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// defaultAuditLimit caps audit listings that don't ask for a limit
+const defaultAuditLimit = 100
+
+// AuditRepository implements the domain.AuditRepository interface using PostgreSQL
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new PostgreSQL audit repository
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Record appends entries to the audit log
+// It joins the surrounding transaction, so a change and its audit entry are stored together
+func (r *AuditRepository) Record(ctx context.Context, entries ...*domain.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := conn(ctx, r.db).CreateInBatches(entries, 500).Error; err != nil {
+		return fmt.Errorf("failed to record audit entries: %w", err)
+	}
+	return nil
+}
+
+// List returns the matching entries, newest first
+func (r *AuditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	query := r.db.WithContext(ctx)
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+
+	var entries []*domain.AuditEntry
+	if err := query.Order("created_at DESC, id").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,37 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.BookArchiveRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// BookArchiveRepository implements the domain.BookArchiveRepository interface using PostgreSQL
+type BookArchiveRepository struct {
+	db *gorm.DB
+}
+
+// NewBookArchiveRepository creates a new PostgreSQL book archive repository
+func NewBookArchiveRepository(db *gorm.DB) *BookArchiveRepository {
+	return &BookArchiveRepository{db: db}
+}
+
+// Attachments returns the attachment manifest of the caller's expenses in their current book
+// One join instead of a query per expense keeps large books quick to export
+func (r *BookArchiveRepository) Attachments(ctx context.Context) ([]*domain.ArchivedAttachment, error) {
+	var manifest []*domain.ArchivedAttachment
+	err := ownedInBook(ctx, r.db.WithContext(ctx).Table("attachments a"), "expenses").
+		Select("a.expense_id, a.file_name, a.content_type, a.size, a.content_hash").
+		Joins("JOIN expenses ON expenses.id = a.expense_id").
+		Order("a.expense_id ASC, a.created_at ASC").
+		Scan(&manifest).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	return manifest, nil
+}
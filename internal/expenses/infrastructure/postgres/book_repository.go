@@ -0,0 +1,109 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.BookRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// BookRepository implements the domain.BookRepository interface using PostgreSQL
+// Books are owned like expenses: each caller only sees their own
+type BookRepository struct {
+	db *gorm.DB
+}
+
+// NewBookRepository creates a new PostgreSQL book repository
+func NewBookRepository(db *gorm.DB) *BookRepository {
+	return &BookRepository{db: db}
+}
+
+// Create saves a new book owned by the caller
+// A user can't keep more than domain.MaxBooksPerUser books
+func (r *BookRepository) Create(ctx context.Context, book *domain.Book) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	book.UserID = owner
+
+	var count int64
+	if err := ownedBy(ctx, conn(ctx, r.db).Model(&domain.Book{}), "user_id").Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count books: %w", err)
+	}
+	if count >= domain.MaxBooksPerUser {
+		return domain.ErrTooManyBooks
+	}
+
+	if err := conn(ctx, r.db).Create(book).Error; err != nil {
+		return fmt.Errorf("failed to create book: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves one of the caller's books
+func (r *BookRepository) GetByID(ctx context.Context, id string) (*domain.Book, error) {
+	bookID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrBookNotFound
+	}
+
+	var book domain.Book
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", bookID).First(&book).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+	return &book, nil
+}
+
+// List returns the caller's books by name
+func (r *BookRepository) List(ctx context.Context) ([]*domain.Book, error) {
+	var books []*domain.Book
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Order("name ASC, id ASC").Find(&books).Error; err != nil {
+		return nil, fmt.Errorf("failed to list books: %w", err)
+	}
+	return books, nil
+}
+
+// Delete removes one of the caller's books with its categories and budgets
+// Expenses are never deleted along with a book, so it is refused while it has any
+func (r *BookRepository) Delete(ctx context.Context, id string) error {
+	book, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Step 1: Check no expense is kept in the book any more, nor a receipt waiting to be matched
+		for _, model := range []any{&domain.Expense{}, &domain.PendingReceipt{}} {
+			var count int64
+			if err := tx.Model(model).Where("book_id = ?", book.ID).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to check book contents: %w", err)
+			}
+			if count > 0 {
+				return domain.ErrBookNotEmpty
+			}
+		}
+
+		// Step 2: Its categories, budgets and rules are of no use without it
+		for _, model := range []any{&domain.Category{}, &domain.Budget{}, &domain.CategoryRule{}} {
+			if err := tx.Where("book_id = ?", book.ID).Delete(model).Error; err != nil {
+				return fmt.Errorf("failed to delete book contents: %w", err)
+			}
+		}
+
+		// Step 3: Delete the book itself
+		if err := tx.Delete(book).Error; err != nil {
+			return fmt.Errorf("failed to delete book: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,60 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.BrandingRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm"        // GORM ORM library
+	"gorm.io/gorm/clause" // For upserts
+)
+
+// BrandingRepository implements the domain.BrandingRepository interface using PostgreSQL
+type BrandingRepository struct {
+	db *gorm.DB
+}
+
+// NewBrandingRepository creates a new PostgreSQL branding repository
+func NewBrandingRepository(db *gorm.DB) *BrandingRepository {
+	return &BrandingRepository{db: db}
+}
+
+// Get retrieves a tenant's branding
+func (r *BrandingRepository) Get(ctx context.Context, tenantID string) (*domain.Branding, error) {
+	var branding domain.Branding
+	if err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).First(&branding).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrBrandingNotFound
+		}
+		return nil, fmt.Errorf("failed to get branding: %w", err)
+	}
+	return &branding, nil
+}
+
+// Save creates or replaces a tenant's branding in one statement
+func (r *BrandingRepository) Save(ctx context.Context, branding *domain.Branding) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "logo_url", "header_text", "footer_text", "updated_at"}),
+	}).Create(branding).Error
+	if err != nil {
+		return fmt.Errorf("failed to save branding: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a tenant's branding
+func (r *BrandingRepository) Delete(ctx context.Context, tenantID string) error {
+	result := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Delete(&domain.Branding{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete branding: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrBrandingNotFound
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.BudgetRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// BudgetRepository implements the domain.BudgetRepository interface using PostgreSQL
+// Each book has its own budgets; queries only see those of the caller's current book
+type BudgetRepository struct {
+	db *gorm.DB
+}
+
+// NewBudgetRepository creates a new PostgreSQL budget repository
+func NewBudgetRepository(db *gorm.DB) *BudgetRepository {
+	return &BudgetRepository{db: db}
+}
+
+// Create saves a new budget in the caller's current book
+// The category check gives a clear error instead of a unique constraint violation
+func (r *BudgetRepository) Create(ctx context.Context, budget *domain.Budget) error {
+	book, ok := bookOf(ctx)
+	if !ok {
+		return domain.ErrBookNotFound
+	}
+	budget.BookID = book
+
+	var count int64
+	if err := inBook(ctx, conn(ctx, r.db).Model(&domain.Budget{}), "book_id").Where("category = ? AND month = ?", budget.Category, budget.Month).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check budget existence: %w", err)
+	}
+	if count > 0 {
+		return domain.ErrBudgetExists
+	}
+	return conn(ctx, r.db).Create(budget).Error
+}
+
+// GetByID retrieves a budget by its ID
+func (r *BudgetRepository) GetByID(ctx context.Context, id string) (*domain.Budget, error) {
+	budgetID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	var budget domain.Budget
+	if err := inBook(ctx, r.db.WithContext(ctx), "book_id").Where("id = ?", budgetID).First(&budget).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrBudgetNotFound
+		}
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	return &budget, nil
+}
+
+// List returns the budgets of the current book ordered by category, then month
+// Recurring budgets have an empty month, so they come before those of single months
+func (r *BudgetRepository) List(ctx context.Context) ([]*domain.Budget, error) {
+	var budgets []*domain.Budget
+	if err := inBook(ctx, conn(ctx, r.db), "book_id").Order("category ASC, month ASC").Find(&budgets).Error; err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+// Update saves changes to an existing budget
+// Budgets are read through GetByID first, so only those of the current book get here;
+// the book is set again so an update can't move a budget to another book
+func (r *BudgetRepository) Update(ctx context.Context, budget *domain.Budget) error {
+	book, ok := bookOf(ctx)
+	if !ok {
+		return domain.ErrBookNotFound
+	}
+	budget.BookID = book
+	return r.db.WithContext(ctx).Save(budget).Error
+}
+
+// Delete removes a budget by its ID
+func (r *BudgetRepository) Delete(ctx context.Context, id string) error {
+	budgetID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	result := inBook(ctx, r.db.WithContext(ctx), "book_id").Where("id = ?", budgetID).Delete(&domain.Budget{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete budget: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrBudgetNotFound
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the MCC mapping table and the keyword categorization rules
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For inserting default mappings in a stable order
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+	"gorm.io/gorm/clause"    // For ON CONFLICT upserts
+)
+
+// MCCRepository implements the domain.MCCRepository interface using PostgreSQL
+type MCCRepository struct {
+	db *gorm.DB
+}
+
+// NewMCCRepository creates a new PostgreSQL MCC mapping repository
+func NewMCCRepository(db *gorm.DB) *MCCRepository {
+	return &MCCRepository{db: db}
+}
+
+// List returns all mappings ordered by code
+func (r *MCCRepository) List(ctx context.Context) ([]*domain.MCCMapping, error) {
+	var mappings []*domain.MCCMapping
+	if err := r.db.WithContext(ctx).Order("mcc ASC").Find(&mappings).Error; err != nil {
+		return nil, fmt.Errorf("failed to list MCC mappings: %w", err)
+	}
+	return mappings, nil
+}
+
+// Get returns the mapping for a single code
+func (r *MCCRepository) Get(ctx context.Context, mcc string) (*domain.MCCMapping, error) {
+	var mapping domain.MCCMapping
+	if err := r.db.WithContext(ctx).Where("mcc = ?", mcc).First(&mapping).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrMCCMappingNotFound
+		}
+		return nil, fmt.Errorf("failed to get MCC mapping: %w", err)
+	}
+	return &mapping, nil
+}
+
+// Upsert creates the mapping or replaces the category of an existing one
+func (r *MCCRepository) Upsert(ctx context.Context, mapping *domain.MCCMapping) error {
+	// ON CONFLICT (mcc) DO UPDATE SET category = excluded.category, updated_at = excluded.updated_at
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "mcc"}},
+		DoUpdates: clause.AssignmentColumns([]string{"category", "updated_at"}),
+	}).Create(mapping).Error
+}
+
+// Delete removes the mapping for a code
+func (r *MCCRepository) Delete(ctx context.Context, mcc string) error {
+	result := r.db.WithContext(ctx).Where("mcc = ?", mcc).Delete(&domain.MCCMapping{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete MCC mapping: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrMCCMappingNotFound
+	}
+	return nil
+}
+
+// seedDefaultMCCMappings installs domain.DefaultMCCMappings when the table is empty
+// It only runs on first start, so mappings deleted by the user don't come back
+func seedDefaultMCCMappings(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&domain.MCCMapping{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count MCC mappings: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	// Sort the codes so the insert is deterministic
+	codes := make([]string, 0, len(domain.DefaultMCCMappings))
+	for code := range domain.DefaultMCCMappings {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	mappings := make([]*domain.MCCMapping, 0, len(codes))
+	for _, code := range codes {
+		mappings = append(mappings, &domain.MCCMapping{MCC: code, Category: domain.DefaultMCCMappings[code]})
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&mappings).Error; err != nil {
+		return fmt.Errorf("failed to seed MCC mappings: %w", err)
+	}
+	return nil
+}
+
+// RuleRepository implements the domain.RuleRepository interface using PostgreSQL
+type RuleRepository struct {
+	db *gorm.DB
+}
+
+// NewRuleRepository creates a new PostgreSQL categorization rule repository
+func NewRuleRepository(db *gorm.DB) *RuleRepository {
+	return &RuleRepository{db: db}
+}
+
+// Create saves a new rule in the caller's current book
+func (r *RuleRepository) Create(ctx context.Context, rule *domain.CategoryRule) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	book, ok := bookOf(ctx)
+	if !ok {
+		return domain.ErrBookNotFound
+	}
+	rule.UserID = owner
+	rule.BookID = book
+	return conn(ctx, r.db).Create(rule).Error
+}
+
+// List returns the caller's rules in their current book, highest priority first
+// Ties are broken by age so older rules keep winning over newer ones with the same priority
+func (r *RuleRepository) List(ctx context.Context) ([]*domain.CategoryRule, error) {
+	var rules []*domain.CategoryRule
+	if err := ownedInBook(ctx, r.db.WithContext(ctx), "").Order("priority DESC, created_at ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list rules: %w", err)
+	}
+	return rules, nil
+}
+
+// Delete removes one of the caller's rules in their current book by its ID
+func (r *RuleRepository) Delete(ctx context.Context, id string) error {
+	ruleID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	result := ownedInBook(ctx, r.db.WithContext(ctx), "").Where("id = ?", ruleID).Delete(&domain.CategoryRule{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrRuleNotFound
+	}
+	return nil
+}
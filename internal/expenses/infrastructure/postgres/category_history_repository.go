@@ -0,0 +1,26 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.CategoryHistoryRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// CategoryHistory returns the caller's most recent categorized expenses in the current book
+// Descriptions are loaded through the model, so encrypted ones arrive decrypted
+func (r *Repository) CategoryHistory(ctx context.Context, limit int) ([]*domain.Expense, error) {
+	var expenses []*domain.Expense
+	err := ownedInBook(ctx, r.db.WithContext(ctx), "").
+		Select("description", "normalized_description", "amount", "category").
+		Where("category <> ''").
+		Order("date DESC, created_at DESC").
+		Limit(limit).
+		Find(&expenses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load category history: %w", err)
+	}
+	return expenses, nil
+}
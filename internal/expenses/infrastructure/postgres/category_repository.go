@@ -0,0 +1,128 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.CategoryRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// CategoryRepository implements the domain.CategoryRepository interface using PostgreSQL
+// Each book has its own categories; queries only see those of the caller's current book
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository creates a new PostgreSQL category repository
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// Create saves a new category in the caller's current book
+// The name check gives a clear error instead of a unique constraint violation
+func (r *CategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	book, ok := bookOf(ctx)
+	if !ok {
+		return domain.ErrBookNotFound
+	}
+	category.BookID = book
+
+	var count int64
+	if err := inBook(ctx, conn(ctx, r.db).Model(&domain.Category{}), "book_id").Where("name = ?", category.Name).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check category existence: %w", err)
+	}
+	if count > 0 {
+		return domain.ErrCategoryExists
+	}
+	return conn(ctx, r.db).Create(category).Error
+}
+
+// List returns the categories of the current book ordered by name, archived ones included
+func (r *CategoryRepository) List(ctx context.Context) ([]*domain.Category, error) {
+	var categories []*domain.Category
+	if err := inBook(ctx, r.db.WithContext(ctx), "book_id").Order("name ASC").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	return categories, nil
+}
+
+// Delete removes a category by its ID
+// Expenses keep their category text, so history is unaffected
+// Its subcategories move up to its parent in the same transaction
+func (r *CategoryRepository) Delete(ctx context.Context, id string) error {
+	categoryID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Step 1: Find the category, to know where its subcategories go
+		var category domain.Category
+		if err := inBook(ctx, tx, "book_id").Where("id = ?", categoryID).First(&category).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrCategoryNotFound
+			}
+			return fmt.Errorf("failed to get category: %w", err)
+		}
+
+		// Step 2: Move the subcategories up a level
+		err := tx.Model(&domain.Category{}).Where("parent_id = ?", categoryID).Update("parent_id", category.ParentID).Error
+		if err != nil {
+			return fmt.Errorf("failed to move subcategories: %w", err)
+		}
+
+		// Step 3: Delete the category itself
+		if err := tx.Delete(&category).Error; err != nil {
+			return fmt.Errorf("failed to delete category: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetByID retrieves a category by its unique identifier
+func (r *CategoryRepository) GetByID(ctx context.Context, id string) (*domain.Category, error) {
+	categoryID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+
+	var category domain.Category
+	if err := inBook(ctx, r.db.WithContext(ctx), "book_id").Where("id = ?", categoryID).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	return &category, nil
+}
+
+// GetByName retrieves a category by the name expenses store
+func (r *CategoryRepository) GetByName(ctx context.Context, name string) (*domain.Category, error) {
+	var category domain.Category
+	if err := inBook(ctx, r.db.WithContext(ctx), "book_id").Where("name = ?", name).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	return &category, nil
+}
+
+// Update saves changes to an existing category
+func (r *CategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	// Select the columns explicitly so clearing them (nil) is written too
+	result := inBook(ctx, r.db.WithContext(ctx), "book_id").Model(category).Select("vat_rate", "parent_id", "archived_at").Updates(category)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update category: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCategoryNotFound
+	}
+	return nil
+}
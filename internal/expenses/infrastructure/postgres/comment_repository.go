@@ -0,0 +1,106 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.CommentRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/auth"            // The caller comments are written as
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// CommentRepository implements the domain.CommentRepository interface using PostgreSQL
+type CommentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository creates a new PostgreSQL comment repository
+func NewCommentRepository(db *gorm.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+// Create saves a comment by the caller on an expense they can see
+func (r *CommentRepository) Create(ctx context.Context, comment *domain.ExpenseComment) error {
+	if err := r.checkVisible(ctx, comment.ExpenseID); err != nil {
+		return err
+	}
+	author, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	comment.AuthorID = author
+	if err := r.db.WithContext(ctx).Create(comment).Error; err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+	return nil
+}
+
+// List returns the comments on an expense the caller can see, oldest first, with their authors' names
+func (r *CommentRepository) List(ctx context.Context, expenseID uuid.UUID) ([]*domain.ExpenseComment, error) {
+	if err := r.checkVisible(ctx, expenseID); err != nil {
+		return nil, err
+	}
+
+	// Step 1: The comments
+	var comments []*domain.ExpenseComment
+	if err := r.db.WithContext(ctx).Where("expense_id = ?", expenseID).Order("created_at ASC, id ASC").Find(&comments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+
+	// Step 2: Their authors' names as they are now, falling back to the email
+	var authorIDs []uuid.UUID
+	for _, comment := range comments {
+		if comment.AuthorID != nil {
+			authorIDs = append(authorIDs, *comment.AuthorID)
+		}
+	}
+	if len(authorIDs) == 0 {
+		return comments, nil
+	}
+	var authors []struct {
+		ID   uuid.UUID
+		Name string
+	}
+	err := r.db.WithContext(ctx).Model(&domain.User{}).
+		Select("id, COALESCE(NULLIF(name, ''), email) AS name").
+		Where("id IN ?", authorIDs).
+		Scan(&authors).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up comment authors: %w", err)
+	}
+	names := make(map[uuid.UUID]string, len(authors))
+	for _, author := range authors {
+		names[author.ID] = author.Name
+	}
+	for _, comment := range comments {
+		if comment.AuthorID != nil {
+			comment.Author = names[*comment.AuthorID]
+		}
+	}
+	return comments, nil
+}
+
+// checkVisible returns domain.ErrExpenseNotFound unless the caller owns the expense or shares a
+// group with the member it is attributed to
+// Unlike the expense list it ignores the book: a comment link works from whichever book is open
+func (r *CommentRepository) checkVisible(ctx context.Context, expenseID uuid.UUID) error {
+	db := r.db.WithContext(ctx)
+	access := ownedBy(ctx, db, "user_id")
+	if userID := auth.UserID(ctx); userID != "" {
+		// Members are linked to users by their user ID; any member of the same group may join in
+		access = access.Or("member_id IN (SELECT m.id FROM group_members m JOIN group_members me ON me.group_id = m.group_id WHERE me.user_id = ?)", userID)
+	}
+
+	var count int64
+	if err := db.Model(&domain.Expense{}).Where("id = ?", expenseID).Where(access).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check expense: %w", err)
+	}
+	if count == 0 {
+		return domain.ErrExpenseNotFound
+	}
+	return nil
+}
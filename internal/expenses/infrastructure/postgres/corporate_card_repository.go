@@ -0,0 +1,77 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.CorporateCardRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records and duplicates
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid"         // For UUID parsing and validation
+	"github.com/jackc/pgx/v5/pgconn" // For reading PostgreSQL error codes
+	"gorm.io/gorm"                   // GORM ORM library
+)
+
+// CorporateCardRepository implements the domain.CorporateCardRepository interface using PostgreSQL
+type CorporateCardRepository struct {
+	db *gorm.DB
+}
+
+// NewCorporateCardRepository creates a new PostgreSQL corporate card repository
+func NewCorporateCardRepository(db *gorm.DB) *CorporateCardRepository {
+	return &CorporateCardRepository{db: db}
+}
+
+// Create saves a new card
+// The unique index on (tenant_id, last4) turns a second mapping of the same digits into ErrCorporateCardExists
+func (r *CorporateCardRepository) Create(ctx context.Context, card *domain.CorporateCard) error {
+	if err := r.db.WithContext(ctx).Create(card).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.ErrCorporateCardExists
+		}
+		return fmt.Errorf("failed to create corporate card: %w", err)
+	}
+	return nil
+}
+
+// List returns the cards of a tenant, ordered by their digits
+func (r *CorporateCardRepository) List(ctx context.Context, tenantID string) ([]*domain.CorporateCard, error) {
+	var cards []*domain.CorporateCard
+	if err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("last4 ASC").Find(&cards).Error; err != nil {
+		return nil, fmt.Errorf("failed to list corporate cards: %w", err)
+	}
+	return cards, nil
+}
+
+// GetByLast4 retrieves a tenant's card by its digits
+func (r *CorporateCardRepository) GetByLast4(ctx context.Context, tenantID, last4 string) (*domain.CorporateCard, error) {
+	var card domain.CorporateCard
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND last4 = ?", tenantID, last4).First(&card).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrCorporateCardNotFound
+		}
+		return nil, fmt.Errorf("failed to get corporate card: %w", err)
+	}
+	return &card, nil
+}
+
+// Delete removes a tenant's card
+// Transactions already staged for the cardholder stay in their queue
+func (r *CorporateCardRepository) Delete(ctx context.Context, tenantID, id string) error {
+	cardID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrCorporateCardNotFound
+	}
+	result := r.db.WithContext(ctx).Where("tenant_id = ? AND id = ?", tenantID, cardID).Delete(&domain.CorporateCard{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete corporate card: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCorporateCardNotFound
+	}
+	return nil
+}
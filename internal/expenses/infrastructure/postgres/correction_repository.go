@@ -0,0 +1,71 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.CorrectionRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For the correction window
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For correction IDs
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// CorrectionRepository implements the domain.CorrectionRepository interface using PostgreSQL
+// Corrections are owned like expenses: each caller only sees their own
+type CorrectionRepository struct {
+	db *gorm.DB
+}
+
+// NewCorrectionRepository creates a new PostgreSQL category correction repository
+func NewCorrectionRepository(db *gorm.DB) *CorrectionRepository {
+	return &CorrectionRepository{db: db}
+}
+
+// Record saves a correction made by the caller
+func (r *CorrectionRepository) Record(ctx context.Context, correction *domain.CategoryCorrection) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	correction.UserID = owner
+	if err := conn(ctx, r.db).Create(correction).Error; err != nil {
+		return fmt.Errorf("failed to record category correction: %w", err)
+	}
+	return nil
+}
+
+// List returns the caller's corrections made since the given time, oldest first
+// Keywords are stored encrypted, so they are grouped by the caller rather than in SQL
+func (r *CorrectionRepository) List(ctx context.Context, since time.Time) ([]*domain.CategoryCorrection, error) {
+	var corrections []*domain.CategoryCorrection
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").
+		Where("created_at >= ?", since).
+		Order("created_at ASC").
+		Find(&corrections).Error; err != nil {
+		return nil, fmt.Errorf("failed to list category corrections: %w", err)
+	}
+	return corrections, nil
+}
+
+// Delete removes some of the caller's corrections
+func (r *CorrectionRepository) Delete(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := ownedBy(ctx, conn(ctx, r.db), "user_id").Where("id IN ?", ids).Delete(&domain.CategoryCorrection{}).Error; err != nil {
+		return fmt.Errorf("failed to delete category corrections: %w", err)
+	}
+	return nil
+}
+
+// Purge removes everyone's corrections made before the given time
+func (r *CorrectionRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&domain.CategoryCorrection{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge category corrections: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
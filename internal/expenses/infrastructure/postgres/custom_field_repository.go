@@ -0,0 +1,94 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.CustomFieldRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// CustomFieldRepository implements the domain.CustomFieldRepository interface using PostgreSQL
+type CustomFieldRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomFieldRepository creates a new PostgreSQL custom field repository
+func NewCustomFieldRepository(db *gorm.DB) *CustomFieldRepository {
+	return &CustomFieldRepository{db: db}
+}
+
+// Create saves a new custom field owned by the caller
+func (r *CustomFieldRepository) Create(ctx context.Context, field *domain.CustomField) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	field.UserID = owner
+	if err := r.db.WithContext(ctx).Create(field).Error; err != nil {
+		return fmt.Errorf("failed to create custom field: %w", err)
+	}
+	return nil
+}
+
+// List returns the caller's custom fields by key
+func (r *CustomFieldRepository) List(ctx context.Context) ([]*domain.CustomField, error) {
+	var fields []*domain.CustomField
+	if err := ownedBy(ctx, conn(ctx, r.db), "user_id").Order("key ASC").Find(&fields).Error; err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	return fields, nil
+}
+
+// GetByKey retrieves one of the caller's custom fields
+func (r *CustomFieldRepository) GetByKey(ctx context.Context, key string) (*domain.CustomField, error) {
+	var field domain.CustomField
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("key = ?", key).First(&field).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrCustomFieldNotFound
+		}
+		return nil, fmt.Errorf("failed to get custom field: %w", err)
+	}
+	return &field, nil
+}
+
+// UpdateLabel saves the label of one of the caller's custom fields
+func (r *CustomFieldRepository) UpdateLabel(ctx context.Context, field *domain.CustomField) error {
+	result := ownedBy(ctx, r.db.WithContext(ctx).Model(field), "user_id").Update("label", field.Label)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update custom field: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCustomFieldNotFound
+	}
+	return nil
+}
+
+// Delete removes one of the caller's custom fields and its values from all of the caller's expenses
+// Both happen in one transaction, so a field declared again later never sees stale values
+func (r *CustomFieldRepository) Delete(ctx context.Context, key string) error {
+	field, err := r.GetByKey(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Step 1: Drop the values, in every book of the caller; an emptied object becomes NULL
+		err := ownedBy(ctx, tx.Model(&domain.Expense{}), "user_id").
+			Where("jsonb_exists(metadata, ?)", field.Key).
+			Update("metadata", gorm.Expr("NULLIF(metadata - ?, '{}'::jsonb)", field.Key)).Error
+		if err != nil {
+			return fmt.Errorf("failed to remove custom field values: %w", err)
+		}
+
+		// Step 2: Delete the field
+		if err := tx.Delete(field).Error; err != nil {
+			return fmt.Errorf("failed to delete custom field: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,63 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.DemoDataRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// demoExpenses selects the IDs of the sample expenses, for the rows that hang off them
+const demoExpenses = "expense_id IN (SELECT id FROM expenses WHERE demo)"
+
+// CountDemoExpenses returns how many sample expenses there are
+// Like the telemetry counts it isn't scoped with ownedBy: the sample data belongs to the tenant
+func (r *Repository) CountDemoExpenses(ctx context.Context) (int64, error) {
+	var count int64
+	if err := conn(ctx, r.db).Model(&domain.Expense{}).Where("demo").Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count demo expenses: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteDemoExpenses removes every sample expense in one transaction
+// Sample expenses someone attached a receipt to have been put to real use: they stop being
+// sample data and are kept, since removing them would orphan the files
+func (r *Repository) DeleteDemoExpenses(ctx context.Context) (int64, error) {
+	var deleted int64
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Step 1: Keep the sample expenses that have attachments
+		kept := "demo AND id IN (SELECT expense_id FROM attachments)"
+		if err := tx.Model(&domain.Expense{}).Where(kept).Update("demo", false).Error; err != nil {
+			return fmt.Errorf("failed to keep demo expenses with attachments: %w", err)
+		}
+
+		// Step 2: Rows that hang off the sample expenses; statement lines are only unmatched
+		if err := tx.Model(&domain.StatementLine{}).Where(demoExpenses).Update("expense_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to unmatch statement lines: %w", err)
+		}
+		for kind, model := range map[string]any{
+			"expense flags":     &domain.ExpenseFlag{},
+			"donations":         &domain.Donation{},
+			"policy violations": &domain.PolicyViolation{},
+			"comments":          &domain.ExpenseComment{},
+		} {
+			if err := tx.Where(demoExpenses).Delete(model).Error; err != nil {
+				return fmt.Errorf("failed to delete demo %s: %w", kind, err)
+			}
+		}
+
+		// Step 3: The sample expenses themselves
+		result := tx.Where("demo").Delete(&domain.Expense{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete demo expenses: %w", result.Error)
+		}
+		deleted = result.RowsAffected
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,117 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.DimensionRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records and duplicates
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For archive times and report periods
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid"         // For UUID parsing and validation
+	"github.com/jackc/pgx/v5/pgconn" // For reading PostgreSQL error codes
+	"gorm.io/gorm"                   // GORM ORM library
+)
+
+// dimensionColumns maps each dimension kind to the expense column holding its code
+var dimensionColumns = map[string]string{
+	domain.DimensionCostCenter: "expenses.cost_center",
+	domain.DimensionDepartment: "expenses.department",
+}
+
+// DimensionRepository implements the domain.DimensionRepository interface using PostgreSQL
+type DimensionRepository struct {
+	db *gorm.DB
+}
+
+// NewDimensionRepository creates a new PostgreSQL dimension repository
+func NewDimensionRepository(db *gorm.DB) *DimensionRepository {
+	return &DimensionRepository{db: db}
+}
+
+// List returns a tenant's dimensions, ordered by kind and code
+func (r *DimensionRepository) List(ctx context.Context, tenantID, kind string, includeArchived bool) ([]*domain.Dimension, error) {
+	query := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if !includeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+
+	var dimensions []*domain.Dimension
+	if err := query.Order("kind ASC, code ASC").Find(&dimensions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list dimensions: %w", err)
+	}
+	return dimensions, nil
+}
+
+// Create saves a new dimension
+// The unique index on (tenant_id, kind, code) turns a second use of a code into ErrDimensionExists
+func (r *DimensionRepository) Create(ctx context.Context, dimension *domain.Dimension) error {
+	if err := r.db.WithContext(ctx).Create(dimension).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.ErrDimensionExists
+		}
+		return fmt.Errorf("failed to create dimension: %w", err)
+	}
+	return nil
+}
+
+// GetByCode retrieves a tenant's dimension by kind and code, archived or not
+func (r *DimensionRepository) GetByCode(ctx context.Context, tenantID, kind, code string) (*domain.Dimension, error) {
+	var dimension domain.Dimension
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND kind = ? AND code = ?", tenantID, kind, code).First(&dimension).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrDimensionNotFound
+		}
+		return nil, fmt.Errorf("failed to get dimension: %w", err)
+	}
+	return &dimension, nil
+}
+
+// Archive retires one of a tenant's dimensions
+// Expenses keep its code, so past reports don't change
+func (r *DimensionRepository) Archive(ctx context.Context, tenantID, id string, at time.Time) error {
+	dimensionID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrDimensionNotFound
+	}
+	result := r.db.WithContext(ctx).Model(&domain.Dimension{}).
+		Where("tenant_id = ? AND id = ? AND archived_at IS NULL", tenantID, dimensionID).
+		Update("archived_at", at)
+	if result.Error != nil {
+		return fmt.Errorf("failed to archive dimension: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrDimensionNotFound
+	}
+	return nil
+}
+
+// Totals sums the expenses charged to the tenant's dimensions of a kind per dimension and category
+// Expenses aren't tenant-scoped themselves; joining on the tenant's codes keeps other tenants' out
+func (r *DimensionRepository) Totals(ctx context.Context, tenantID, kind string, from, to time.Time) ([]*domain.DimensionTotal, error) {
+	column, ok := dimensionColumns[kind]
+	if !ok {
+		return nil, domain.ErrInvalidDimension
+	}
+
+	var totals []*domain.DimensionTotal
+	err := r.db.WithContext(ctx).
+		Table("expenses").
+		Select("d.code, d.name, expenses.category, COUNT(*) AS count, SUM("+reportingAmount+") AS amount").
+		Joins("JOIN dimensions d ON d.code = "+column+" AND d.kind = ? AND d.tenant_id = ?", kind, tenantID).
+		Where("expenses.date >= ? AND expenses.date < ?", from, to).
+		Group("d.code, d.name, expenses.category").
+		Order("d.code ASC, amount DESC").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum expenses by %s: %w", kind, err)
+	}
+	return totals, nil
+}
@@ -0,0 +1,130 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.DonationRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing rows
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For date range arguments
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID handling
+	"gorm.io/gorm"           // GORM ORM library
+	"gorm.io/gorm/clause"    // For upserts
+)
+
+// DonationRepository implements the domain.DonationRepository interface using PostgreSQL
+type DonationRepository struct {
+	db *gorm.DB
+}
+
+// NewDonationRepository creates a new PostgreSQL donation repository
+func NewDonationRepository(db *gorm.DB) *DonationRepository {
+	return &DonationRepository{db: db}
+}
+
+// Save marks the expense as a donation, or replaces the recipient details it already has
+func (r *DonationRepository) Save(ctx context.Context, donation *domain.Donation) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "expense_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"recipient_name", "recipient_tax_id", "recipient_address", "receipt_number", "updated_at"}),
+		}).
+		Create(donation).Error
+	if err != nil {
+		return fmt.Errorf("failed to save donation: %w", err)
+	}
+	return nil
+}
+
+// Get returns the donation of an expense
+func (r *DonationRepository) Get(ctx context.Context, expenseID uuid.UUID) (*domain.Donation, error) {
+	var donation domain.Donation
+	if err := r.db.WithContext(ctx).Where("expense_id = ?", expenseID).First(&donation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrDonationNotFound
+		}
+		return nil, fmt.Errorf("failed to get donation: %w", err)
+	}
+	return &donation, nil
+}
+
+// Delete unmarks the expense
+func (r *DonationRepository) Delete(ctx context.Context, expenseID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("expense_id = ?", expenseID).Delete(&domain.Donation{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete donation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrDonationNotFound
+	}
+	return nil
+}
+
+// ListGiving returns the caller's donations dated in [from, to), oldest first
+// The expenses are loaded as models, so their descriptions are decrypted like everywhere else
+func (r *DonationRepository) ListGiving(ctx context.Context, from, to time.Time) ([]*domain.GivingLine, error) {
+	// Step 1: The caller's expenses that are donations
+	var expenses []*domain.Expense
+	err := ownedInBook(ctx, r.db.WithContext(ctx), "expenses").
+		Joins("JOIN donations ON donations.expense_id = expenses.id").
+		Where("expenses.date >= ? AND expenses.date < ?", from, to).
+		Order("expenses.date ASC, expenses.id ASC").
+		Find(&expenses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list donations: %w", err)
+	}
+	if len(expenses) == 0 {
+		return nil, nil
+	}
+	ids := make([]uuid.UUID, len(expenses))
+	for i, expense := range expenses {
+		ids[i] = expense.ID
+	}
+
+	// Step 2: Their recipient details and how many files each has attached
+	var donations []*domain.Donation
+	if err := r.db.WithContext(ctx).Where("expense_id IN ?", ids).Find(&donations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list donations: %w", err)
+	}
+	byExpense := make(map[uuid.UUID]*domain.Donation, len(donations))
+	for _, donation := range donations {
+		byExpense[donation.ExpenseID] = donation
+	}
+	var counts []struct {
+		ExpenseID uuid.UUID
+		Count     int
+	}
+	err = r.db.WithContext(ctx).Model(&domain.Attachment{}).
+		Select("expense_id, COUNT(*) AS count").
+		Where("expense_id IN ?", ids).
+		Group("expense_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count donation receipts: %w", err)
+	}
+	attachments := make(map[uuid.UUID]int, len(counts))
+	for _, count := range counts {
+		attachments[count.ExpenseID] = count.Count
+	}
+
+	// Step 3: One line per donation
+	lines := make([]*domain.GivingLine, 0, len(expenses))
+	for _, expense := range expenses {
+		donation := byExpense[expense.ID]
+		if donation == nil {
+			// Unmarked between the two queries
+			continue
+		}
+		lines = append(lines, &domain.GivingLine{
+			Donation:    donation,
+			Date:        expense.Date,
+			Description: expense.Description,
+			Amount:      expense.ReportingAmount(),
+			Attachments: attachments[expense.ID],
+		})
+	}
+	return lines, nil
+}
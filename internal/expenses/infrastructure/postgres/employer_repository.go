@@ -0,0 +1,201 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.EmployerRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// EmployerRepository implements the domain.EmployerRepository interface using PostgreSQL
+// Employers and their reimbursements are owned like expenses: each caller only sees their own
+type EmployerRepository struct {
+	db *gorm.DB
+}
+
+// NewEmployerRepository creates a new PostgreSQL employer repository
+func NewEmployerRepository(db *gorm.DB) *EmployerRepository {
+	return &EmployerRepository{db: db}
+}
+
+// Create saves a new employer owned by the caller
+func (r *EmployerRepository) Create(ctx context.Context, employer *domain.Employer) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	employer.UserID = owner
+	if err := r.db.WithContext(ctx).Create(employer).Error; err != nil {
+		return fmt.Errorf("failed to create employer: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves one of the caller's employers
+func (r *EmployerRepository) GetByID(ctx context.Context, id string) (*domain.Employer, error) {
+	employerID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrEmployerNotFound
+	}
+
+	var employer domain.Employer
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", employerID).First(&employer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrEmployerNotFound
+		}
+		return nil, fmt.Errorf("failed to get employer: %w", err)
+	}
+	return &employer, nil
+}
+
+// List returns the caller's employers by name
+func (r *EmployerRepository) List(ctx context.Context) ([]*domain.Employer, error) {
+	var employers []*domain.Employer
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Order("name ASC, id ASC").Find(&employers).Error; err != nil {
+		return nil, fmt.Errorf("failed to list employers: %w", err)
+	}
+	return employers, nil
+}
+
+// Delete removes one of the caller's employers
+// Expenses charged to it and reimbursements from it would lose their employer, so it is refused while any exist
+func (r *EmployerRepository) Delete(ctx context.Context, id string) error {
+	employer, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Step 1: Check nothing refers to the employer any more
+		for _, model := range []any{&domain.Expense{}, &domain.ReimbursementIncome{}} {
+			var count int64
+			if err := tx.Model(model).Where("employer_id = ?", employer.ID).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to check employer use: %w", err)
+			}
+			if count > 0 {
+				return domain.ErrEmployerInUse
+			}
+		}
+
+		// Step 2: Delete it
+		if err := tx.Delete(employer).Error; err != nil {
+			return fmt.Errorf("failed to delete employer: %w", err)
+		}
+		return nil
+	})
+}
+
+// CreateIncome saves a reimbursement owned by the caller
+// The service has checked that the employer is one of the caller's
+func (r *EmployerRepository) CreateIncome(ctx context.Context, income *domain.ReimbursementIncome) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	income.UserID = owner
+	if err := r.db.WithContext(ctx).Create(income).Error; err != nil {
+		return fmt.Errorf("failed to create reimbursement: %w", err)
+	}
+	return nil
+}
+
+// ListIncomes returns the caller's reimbursements from an employer, newest first
+func (r *EmployerRepository) ListIncomes(ctx context.Context, employerID uuid.UUID) ([]*domain.ReimbursementIncome, error) {
+	var incomes []*domain.ReimbursementIncome
+	err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").
+		Where("employer_id = ?", employerID).
+		Order("received_on DESC, created_at DESC").
+		Find(&incomes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reimbursements: %w", err)
+	}
+	return incomes, nil
+}
+
+// DeleteIncome removes one of the caller's reimbursements from an employer
+func (r *EmployerRepository) DeleteIncome(ctx context.Context, employerID uuid.UUID, id string) error {
+	incomeID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrReimbursementNotFound
+	}
+	result := ownedBy(ctx, r.db.WithContext(ctx), "user_id").
+		Where("id = ? AND employer_id = ?", incomeID, employerID).
+		Delete(&domain.ReimbursementIncome{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete reimbursement: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrReimbursementNotFound
+	}
+	return nil
+}
+
+// Balances returns the balance of each of the caller's employers, by name
+// Claims and reimbursements are summed in SQL, one grouped query each
+func (r *EmployerRepository) Balances(ctx context.Context) ([]*domain.EmployerBalance, error) {
+	// Step 1: The caller's employers
+	employers, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(employers) == 0 {
+		return []*domain.EmployerBalance{}, nil
+	}
+
+	// Step 2: The reimbursable expenses charged to each of them
+	var claims []struct {
+		EmployerID uuid.UUID
+		Count      int
+		Amount     domain.Money
+	}
+	err = ownedInBook(ctx, r.db.WithContext(ctx).Model(&domain.Expense{}), "").
+		Select("employer_id, COUNT(*) AS count, SUM(" + reportingAmount + ") AS amount").
+		Where("reimbursable AND employer_id IS NOT NULL").
+		Group("employer_id").
+		Scan(&claims).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum reimbursable expenses: %w", err)
+	}
+
+	// Step 3: The reimbursements received from each of them
+	var incomes []struct {
+		EmployerID uuid.UUID
+		Amount     domain.Money
+	}
+	err = ownedBy(ctx, r.db.WithContext(ctx).Model(&domain.ReimbursementIncome{}), "user_id").
+		Select("employer_id, SUM(amount) AS amount").
+		Group("employer_id").
+		Scan(&incomes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum reimbursements: %w", err)
+	}
+
+	// Step 4: One balance per employer, including those with nothing claimed yet
+	balances := make([]*domain.EmployerBalance, len(employers))
+	byEmployer := make(map[uuid.UUID]*domain.EmployerBalance, len(employers))
+	for i, employer := range employers {
+		balances[i] = &domain.EmployerBalance{Employer: employer}
+		byEmployer[employer.ID] = balances[i]
+	}
+	for _, claim := range claims {
+		if balance := byEmployer[claim.EmployerID]; balance != nil {
+			balance.Expenses = claim.Count
+			balance.Claimed = claim.Amount
+		}
+	}
+	for _, income := range incomes {
+		if balance := byEmployer[income.EmployerID]; balance != nil {
+			balance.Reimbursed = income.Amount
+		}
+	}
+	for _, balance := range balances {
+		balance.Outstanding = balance.Claimed.Sub(balance.Reimbursed)
+	}
+	return balances, nil
+}
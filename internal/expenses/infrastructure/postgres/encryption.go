@@ -0,0 +1,114 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file encrypts sensitive columns: fields tagged `gorm:"serializer:encrypted"` are
+// encrypted on every write and decrypted on every read, so the rest of the code never sees ciphertext
+package postgres
+
+import (
+	"context"     // For request context (cancellation, timeouts)
+	"fmt"         // For formatted string operations and error wrapping
+	"reflect"     // For reading and setting the tagged fields
+	"sync/atomic" // For swapping the cipher while queries run
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/fieldcrypt"      // AES-GCM encryption of single values
+
+	"gorm.io/gorm/schema" // For registering the serializer
+)
+
+// fieldCipher is the cipher of the encrypted columns (nil while encryption is off)
+var fieldCipher atomic.Pointer[fieldcrypt.Cipher]
+
+// The serializer has to exist before GORM parses the first model, whether or not a key is set
+func init() {
+	schema.RegisterSerializer("encrypted", encryptedSerializer{})
+}
+
+// EnableFieldEncryption encrypts the tagged columns with c from now on
+// Without it values are written as plaintext, and encrypted values are read back as stored
+func EnableFieldEncryption(c *fieldcrypt.Cipher) {
+	fieldCipher.Store(c)
+}
+
+// fieldsEncrypted reports whether the tagged columns are written encrypted
+// Queries that compare those columns in SQL can't match ciphertext and have to work around it
+func fieldsEncrypted() bool {
+	return fieldCipher.Load() != nil
+}
+
+// encryptedSerializer is the GORM serializer behind `serializer:encrypted` (string fields only)
+// Values are bound to their table and column, so ciphertext copied elsewhere doesn't decrypt
+type encryptedSerializer struct{}
+
+// Scan decrypts a value read from the database into the field
+func (encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch value := dbValue.(type) {
+	case nil:
+	case string:
+		stored = value
+	case []byte:
+		stored = string(value)
+	default:
+		return fmt.Errorf("unsupported value %T for encrypted column %s", dbValue, field.DBName)
+	}
+
+	plaintext := stored
+	if c := fieldCipher.Load(); c != nil {
+		var err error
+		if plaintext, err = c.Decrypt(stored, columnContext(field)); err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", field.DBName, err)
+		}
+	}
+	return field.Set(ctx, dst, plaintext)
+}
+
+// Value encrypts the field's value before it is written
+// Empty values stay empty, so "no merchant" can still be told apart in SQL
+func (encryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, _ := fieldValue.(string)
+	c := fieldCipher.Load()
+	if c == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	return c.Encrypt(plaintext, columnContext(field))
+}
+
+// columnContext names where a value is stored, e.g. "expenses.description"
+func columnContext(field *schema.Field) string {
+	return field.Schema.Table + "." + field.DBName
+}
+
+// EncryptPlaintextExpenses encrypts up to batch expenses whose description or merchant isn't
+// encrypted under the current key yet, and returns how many it rewrote
+// It covers rows written before encryption was enabled and rows under a rotated-out key;
+// run it until it returns 0
+func (r *Repository) EncryptPlaintextExpenses(ctx context.Context, batch int) (int, error) {
+	c := fieldCipher.Load()
+	if c == nil {
+		return 0, nil
+	}
+
+	// Step 1: Find rows with a value that isn't under the current key
+	current := c.CurrentPrefix() + "%"
+	var expenses []*domain.Expense
+	err := r.db.WithContext(ctx).
+		Select("id", "description", "merchant").
+		Where("description NOT LIKE ? OR (merchant <> '' AND merchant NOT LIKE ?)", current, current).
+		Limit(batch).
+		Find(&expenses).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find plaintext expenses: %w", err)
+	}
+
+	// Step 2: Write them back, which encrypts them under the current key
+	// Rows already under an old key were decrypted on the way in and are re-encrypted the same way
+	rewritten := 0
+	for _, expense := range expenses {
+		err := r.db.WithContext(ctx).Model(expense).Select("description", "merchant").Updates(expense).Error
+		if err != nil {
+			return rewritten, fmt.Errorf("failed to encrypt expense %s: %w", expense.ID, err)
+		}
+		rewritten++
+	}
+	return rewritten, nil
+}
@@ -0,0 +1,174 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ErasureRepository interface
+package postgres
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"database/sql" // For the named argument matching either side of a delegation
+	"fmt"          // For formatted string operations and error wrapping
+	"time"         // For finding due erasures
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// ErasureRepository implements the domain.ErasureRepository interface using PostgreSQL
+type ErasureRepository struct {
+	db *gorm.DB
+}
+
+// NewErasureRepository creates a new PostgreSQL erasure repository
+func NewErasureRepository(db *gorm.DB) *ErasureRepository {
+	return &ErasureRepository{db: db}
+}
+
+// erasedExpenses selects the IDs of the user's expenses, for the rows that hang off them
+const erasedExpenses = "expense_id IN (SELECT id FROM expenses WHERE user_id = ?)"
+
+// erasedBooks matches the rows kept in the books of the erased user
+const erasedBooks = "book_id IN (SELECT id FROM books WHERE user_id = ?)"
+
+// ListDue returns the users whose erasure was scheduled for now or earlier, longest due first
+func (r *ErasureRepository) ListDue(ctx context.Context, now time.Time) ([]*domain.User, error) {
+	var users []*domain.User
+	if err := r.db.WithContext(ctx).Where("erases_at <= ?", now).Order("erases_at").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to list due erasures: %w", err)
+	}
+	return users, nil
+}
+
+// Erase removes the user and everything they own in one transaction
+// Either the whole account is gone afterwards or nothing is, so a failed run is simply retried
+func (r *ErasureRepository) Erase(ctx context.Context, user *domain.User) (*domain.Erasure, error) {
+	erasure := &domain.Erasure{Deleted: map[string]int64{}}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Step 1: Remember what has to leave blob storage once the rows are gone
+		if err := tx.Where(erasedExpenses, user.ID).Find(&erasure.Attachments).Error; err != nil {
+			return fmt.Errorf("failed to list attachments: %w", err)
+		}
+		var exports []*domain.ExportJob
+		if err := tx.Where("user_id = ? AND storage_key <> ''", user.ID.String()).Find(&exports).Error; err != nil {
+			return fmt.Errorf("failed to list exports: %w", err)
+		}
+		for _, export := range exports {
+			erasure.ExportFiles = append(erasure.ExportFiles, export.StorageKey)
+		}
+		var receipts []*domain.PendingReceipt
+		if err := tx.Where("user_id = ?", user.ID).Find(&receipts).Error; err != nil {
+			return fmt.Errorf("failed to list pending receipts: %w", err)
+		}
+		for _, receipt := range receipts {
+			erasure.PendingReceiptFiles = append(erasure.PendingReceiptFiles, receipt.StorageKey)
+		}
+
+		// Step 2: Rows that hang off the user's expenses
+		// Statement lines of other users' reconciliations are only unmatched
+		if err := tx.Model(&domain.StatementLine{}).Where(erasedExpenses, user.ID).Update("expense_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to unmatch statement lines: %w", err)
+		}
+		for kind, model := range map[string]any{
+			"attachments":   &domain.Attachment{},
+			"expense_flags": &domain.ExpenseFlag{},
+			"donations":     &domain.Donation{},
+			"comments":      &domain.ExpenseComment{},
+		} {
+			result := tx.Where(erasedExpenses, user.ID).Delete(model)
+			if result.Error != nil {
+				return fmt.Errorf("failed to erase %s: %w", kind, result.Error)
+			}
+			erasure.Deleted[kind] = result.RowsAffected
+		}
+
+		// Step 3: Everything the user owns directly, expenses last since the rows above point at them
+		for _, owned := range []struct {
+			kind  string
+			model any
+			where string
+			id    any
+		}{
+			{"policy_violations", &domain.PolicyViolation{}, "user_id = ?", user.ID},
+			// Comments the user wrote on other people's expenses go too; the threads lose their turns
+			{"authored_comments", &domain.ExpenseComment{}, "author_id = ?", user.ID},
+			{"category_corrections", &domain.CategoryCorrection{}, "user_id = ?", user.ID},
+			{"request_logs", &domain.RequestLog{}, "user_id = ?", user.ID},
+			{"request_log_targets", &domain.RequestLogTarget{}, "user_id = ?", user.ID},
+			// Erasure is the one time expense events are removed: they hold the expenses' contents
+			{"expense_events", &domain.ExpenseEvent{}, "user_id = ?", user.ID},
+			{"expense_snapshots", &domain.ExpenseSnapshot{}, "user_id = ?", user.ID},
+			{"planned_purchases", &domain.PlannedPurchase{}, "user_id = ?", user.ID},
+			{"recurring_expenses", &domain.RecurringExpense{}, "user_id = ?", user.ID},
+			{"category_rules", &domain.CategoryRule{}, "user_id = ?", user.ID},
+			{"receivables", &domain.Receivable{}, "user_id = ?", user.ID},
+			{"loans", &domain.Loan{}, "user_id = ?", user.ID},
+			{"reimbursement_incomes", &domain.ReimbursementIncome{}, "user_id = ?", user.ID},
+			{"reimbursement_batches", &domain.ReimbursementBatch{}, "user_id = ?", user.ID},
+			{"transfers", &domain.Transfer{}, "user_id = ?", user.ID},
+			{"accounts", &domain.Account{}, "user_id = ?", user.ID},
+			{"employers", &domain.Employer{}, "user_id = ?", user.ID},
+			{"staged_expenses", &domain.StagedExpense{}, "user_id = ?", user.ID},
+			{"corporate_cards", &domain.CorporateCard{}, "user_id = ?", user.ID},
+			{"export_jobs", &domain.ExportJob{}, "user_id = ?", user.ID.String()},
+			{"api_keys", &domain.APIKey{}, "user_id = ?", user.ID},
+			{"refresh_tokens", &domain.RefreshToken{}, "user_id = ?", user.ID},
+			{"approval_delegations", &domain.ApprovalDelegation{}, "approver_id = @id OR delegate_id = @id", sql.Named("id", user.ID)},
+			{"expenses", &domain.Expense{}, "user_id = ?", user.ID},
+			// Deleting the expenses closed their versions; the versions hold their contents too
+			{"expense_history", &expenseVersion{}, "user_id = ?", user.ID},
+			// Trips, groups, reconciliations and forms go with their parts, now that no expense points at them
+			{"trip_legs", &domain.TripLeg{}, "trip_id IN (SELECT id FROM trips WHERE user_id = ?)", user.ID},
+			{"trips", &domain.Trip{}, "user_id = ?", user.ID},
+			{"group_budgets", &domain.GroupBudget{}, "group_id IN (SELECT id FROM groups WHERE user_id = ?)", user.ID},
+			{"group_members", &domain.GroupMember{}, "group_id IN (SELECT id FROM groups WHERE user_id = ?)", user.ID},
+			{"groups", &domain.Group{}, "user_id = ?", user.ID},
+			{"statement_lines", &domain.StatementLine{}, "session_id IN (SELECT id FROM reconciliation_sessions WHERE user_id = ?)", user.ID},
+			{"reconciliation_sessions", &domain.ReconciliationSession{}, "user_id = ?", user.ID},
+			{"public_forms", &domain.PublicForm{}, "user_id = ?", user.ID},
+			{"pending_receipts", &domain.PendingReceipt{}, "user_id = ?", user.ID},
+			// The user's books go with their categories and budgets, now that no expense is kept in them
+			{"categories", &domain.Category{}, erasedBooks, user.ID},
+			{"budgets", &domain.Budget{}, erasedBooks, user.ID},
+			{"books", &domain.Book{}, "user_id = ?", user.ID},
+		} {
+			result := tx.Where(owned.where, owned.id).Delete(owned.model)
+			if result.Error != nil {
+				return fmt.Errorf("failed to erase %s: %w", owned.kind, result.Error)
+			}
+			erasure.Deleted[owned.kind] = result.RowsAffected
+		}
+
+		// Step 4: Group memberships and approval steps outlive the user, but no longer name them
+		if err := tx.Model(&domain.GroupMember{}).Where("user_id = ?", user.ID.String()).Update("user_id", "").Error; err != nil {
+			return fmt.Errorf("failed to unlink group members: %w", err)
+		}
+		if err := tx.Model(&domain.ApprovalStep{}).Where("delegate_id = ?", user.ID).
+			Updates(map[string]any{"delegate_id": nil, "delegate_from": nil, "delegate_until": nil}).Error; err != nil {
+			return fmt.Errorf("failed to unlink approval steps: %w", err)
+		}
+
+		// Step 5: Keep the audit trail, but replace the user's ID and email in it
+		// The details are rewritten as text, which catches the ID and email wherever they appear
+		id := user.ID.String()
+		result := tx.Model(&domain.AuditEntry{}).
+			Where("actor = ? OR entity_id = ? OR details::text LIKE ? OR details::text LIKE ?", id, id, "%"+id+"%", "%"+user.Email+"%").
+			Updates(map[string]any{
+				"actor":     gorm.Expr("CASE WHEN actor = ? THEN ? ELSE actor END", id, domain.ErasedUser),
+				"entity_id": gorm.Expr("CASE WHEN entity_id = ? THEN ? ELSE entity_id END", id, domain.ErasedUser),
+				"details":   gorm.Expr("replace(replace(details::text, ?, ?), ?, ?)::jsonb", id, domain.ErasedUser, user.Email, domain.ErasedUser),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to anonymize audit entries: %w", result.Error)
+		}
+		erasure.AnonymizedAuditEntries = result.RowsAffected
+
+		// Step 6: Finally the account itself
+		if err := tx.Where("id = ?", user.ID).Delete(&domain.User{}).Error; err != nil {
+			return fmt.Errorf("failed to erase user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return erasure, nil
+}
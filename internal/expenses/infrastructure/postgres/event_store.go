@@ -0,0 +1,96 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.EventStore interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records and constraint violations
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For time-travel bounds
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid"         // For UUID parsing and validation
+	"github.com/jackc/pgx/v5/pgconn" // PostgreSQL error codes
+	"gorm.io/gorm"                   // GORM ORM library
+)
+
+// EventStore implements the domain.EventStore interface using PostgreSQL
+// Events and snapshots are only inserted, never updated; the unique (expense, sequence) index
+// is what keeps two writers from both appending the next event of a stream
+type EventStore struct {
+	db *gorm.DB
+}
+
+// NewEventStore creates a new PostgreSQL event store
+func NewEventStore(db *gorm.DB) *EventStore {
+	return &EventStore{db: db}
+}
+
+// Append adds events to their streams
+// It joins the surrounding transaction, so an event is stored together with the change it records
+func (s *EventStore) Append(ctx context.Context, events ...*domain.ExpenseEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := conn(ctx, s.db).Create(events).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.ErrExpenseChangedConcurrently
+		}
+		return fmt.Errorf("failed to append expense events: %w", err)
+	}
+	return nil
+}
+
+// Load returns the latest snapshot at or before asOf and the events after it up to asOf
+func (s *EventStore) Load(ctx context.Context, expenseID uuid.UUID, asOf time.Time) (*domain.ExpenseSnapshot, []*domain.ExpenseEvent, error) {
+	// Step 1: The latest snapshot, if any
+	snapshots := ownedInBook(ctx, conn(ctx, s.db), "").Where("expense_id = ?", expenseID)
+	if !asOf.IsZero() {
+		snapshots = snapshots.Where("occurred_at <= ?", asOf)
+	}
+	var snapshot *domain.ExpenseSnapshot
+	var found domain.ExpenseSnapshot
+	err := snapshots.Order("sequence DESC").First(&found).Error
+	switch {
+	case err == nil:
+		snapshot = &found
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil, fmt.Errorf("failed to load expense snapshot: %w", err)
+	}
+
+	// Step 2: The events since
+	query := ownedInBook(ctx, conn(ctx, s.db), "").Where("expense_id = ?", expenseID)
+	if snapshot != nil {
+		query = query.Where("sequence > ?", snapshot.Sequence)
+	}
+	if !asOf.IsZero() {
+		query = query.Where("occurred_at <= ?", asOf)
+	}
+	var events []*domain.ExpenseEvent
+	if err := query.Order("sequence ASC").Find(&events).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load expense events: %w", err)
+	}
+	return snapshot, events, nil
+}
+
+// SaveSnapshot stores a snapshot, joining the surrounding transaction
+func (s *EventStore) SaveSnapshot(ctx context.Context, snapshot *domain.ExpenseSnapshot) error {
+	if err := conn(ctx, s.db).Create(snapshot).Error; err != nil {
+		return fmt.Errorf("failed to save expense snapshot: %w", err)
+	}
+	return nil
+}
+
+// Events returns the caller's stream of an expense, oldest first
+func (s *EventStore) Events(ctx context.Context, expenseID uuid.UUID) ([]*domain.ExpenseEvent, error) {
+	var events []*domain.ExpenseEvent
+	if err := ownedInBook(ctx, s.db.WithContext(ctx), "").
+		Where("expense_id = ?", expenseID).
+		Order("sequence ASC").
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list expense events: %w", err)
+	}
+	return events, nil
+}
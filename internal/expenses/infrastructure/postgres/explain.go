@@ -0,0 +1,89 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.QueryExplainer interface with EXPLAIN ANALYZE
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For date range arguments
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// explainTimeout bounds how long an explained query may run
+// EXPLAIN ANALYZE really executes the query, so a pathological filter must not hold a connection
+const explainTimeout = "5s"
+
+// ExplainExpenses explains the query GetAll runs for the given filters
+// This method implements the domain.QueryExplainer.ExplainExpenses interface
+func (r *Repository) ExplainExpenses(ctx context.Context, filters map[string]interface{}) (*domain.QueryPlan, error) {
+	return r.explain(ctx, "list_expenses", func(db *gorm.DB) *gorm.DB {
+		var expenses []*domain.Expense
+		return listQuery(ownedInBook(ctx, db, ""), filters).Find(&expenses)
+	})
+}
+
+// ExplainSpending explains the per-category and per-merchant totals for [from, to)
+// This method implements the domain.QueryExplainer.ExplainSpending interface
+func (r *Repository) ExplainSpending(ctx context.Context, from, to time.Time) ([]*domain.QueryPlan, error) {
+	byCategory, err := r.explain(ctx, "spending_by_category", func(db *gorm.DB) *gorm.DB {
+		var spending []*domain.CategorySpending
+		return spendingByCategoryQuery(ownedInBook(ctx, db, ""), from, to).Scan(&spending)
+	})
+	if err != nil {
+		return nil, err
+	}
+	byMerchant, err := r.explain(ctx, "spending_by_merchant", func(db *gorm.DB) *gorm.DB {
+		var spending []*domain.MerchantSpending
+		return spendingByMerchantQuery(ownedInBook(ctx, db, ""), from, to).Scan(&spending)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []*domain.QueryPlan{byCategory, byMerchant}, nil
+}
+
+// explain renders the query built by build without running it, then runs EXPLAIN ANALYZE on it
+// The EXPLAIN runs in a read-only transaction with a statement timeout and is always rolled back,
+// so explaining can neither change data nor run away on a production database
+func (r *Repository) explain(ctx context.Context, name string, build func(db *gorm.DB) *gorm.DB) (*domain.QueryPlan, error) {
+	// Step 1: Let GORM generate the SQL in dry-run mode (nothing is sent to the database)
+	stmt := build(r.db.WithContext(ctx).Session(&gorm.Session{DryRun: true})).Statement
+	sql := stmt.SQL.String()
+
+	// Step 2: Explain it inside a throwaway, read-only transaction
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to start explain transaction: %w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	if err := tx.Exec("SET TRANSACTION READ ONLY").Error; err != nil {
+		return nil, fmt.Errorf("failed to make explain transaction read-only: %w", err)
+	}
+	if err := tx.Exec("SET LOCAL statement_timeout = '" + explainTimeout + "'").Error; err != nil {
+		return nil, fmt.Errorf("failed to set explain timeout: %w", err)
+	}
+
+	rows, err := tx.Raw("EXPLAIN (ANALYZE, BUFFERS) "+sql, stmt.Vars...).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	// Step 3: Collect the plan, one line per row
+	plan := &domain.QueryPlan{Name: name, SQL: sql}
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("failed to read plan of %s: %w", name, err)
+		}
+		plan.Plan = append(plan.Plan, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read plan of %s: %w", name, err)
+	}
+	return plan, nil
+}
@@ -0,0 +1,92 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ExportRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For expiry checks
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// ExportRepository implements the domain.ExportRepository interface using PostgreSQL
+type ExportRepository struct {
+	db *gorm.DB
+}
+
+// NewExportRepository creates a new PostgreSQL export job repository
+func NewExportRepository(db *gorm.DB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+// Create saves a new export job
+func (r *ExportRepository) Create(ctx context.Context, job *domain.ExportJob) error {
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves an export job by its ID
+func (r *ExportRepository) GetByID(ctx context.Context, id string) (*domain.ExportJob, error) {
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrExportNotFound
+	}
+
+	var job domain.ExportJob
+	if err := r.db.WithContext(ctx).Where("id = ?", jobID).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrExportNotFound
+		}
+		return nil, fmt.Errorf("failed to get export job: %w", err)
+	}
+	return &job, nil
+}
+
+// Update saves changes to an export job
+func (r *ExportRepository) Update(ctx context.Context, job *domain.ExportJob) error {
+	if err := r.db.WithContext(ctx).Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+	return nil
+}
+
+// ListByUser returns the export jobs of userID, newest first
+func (r *ExportRepository) ListByUser(ctx context.Context, userID string) ([]*domain.ExportJob, error) {
+	var jobs []*domain.ExportJob
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list export jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// ListUnfinished returns the jobs that are queued or running
+func (r *ExportRepository) ListUnfinished(ctx context.Context) ([]*domain.ExportJob, error) {
+	var jobs []*domain.ExportJob
+	err := r.db.WithContext(ctx).
+		Where("status IN ?", []string{domain.ExportQueued, domain.ExportRunning}).
+		Order("created_at ASC").
+		Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unfinished export jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// ListExpired returns the completed jobs whose file expired before now
+func (r *ExportRepository) ListExpired(ctx context.Context, now time.Time) ([]*domain.ExportJob, error) {
+	var jobs []*domain.ExportJob
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ? AND storage_key <> ''", domain.ExportCompleted, now).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired export jobs: %w", err)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,86 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.FlagRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For date range arguments
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+	"gorm.io/gorm/clause"    // For upserts
+)
+
+// FlagRepository implements the domain.FlagRepository interface using PostgreSQL
+type FlagRepository struct {
+	db *gorm.DB
+}
+
+// NewFlagRepository creates a new PostgreSQL flag repository
+func NewFlagRepository(db *gorm.DB) *FlagRepository {
+	return &FlagRepository{db: db}
+}
+
+// Set adds the flag, or updates the note when the expense already carries it
+func (r *FlagRepository) Set(ctx context.Context, flag *domain.ExpenseFlag) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "expense_id"}, {Name: "flag"}},
+			DoUpdates: clause.AssignmentColumns([]string{"note"}),
+		}).
+		Create(flag).Error
+	if err != nil {
+		return fmt.Errorf("failed to set flag: %w", err)
+	}
+	return nil
+}
+
+// Remove clears a flag from an expense
+func (r *FlagRepository) Remove(ctx context.Context, expenseID string, flag domain.Flag) error {
+	id, err := uuid.Parse(expenseID)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Where("expense_id = ? AND flag = ?", id, flag).Delete(&domain.ExpenseFlag{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove flag: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrFlagNotFound
+	}
+	return nil
+}
+
+// ListForExpense returns the flags of an expense, oldest first
+func (r *FlagRepository) ListForExpense(ctx context.Context, expenseID string) ([]*domain.ExpenseFlag, error) {
+	id, err := uuid.Parse(expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	var flags []*domain.ExpenseFlag
+	if err := r.db.WithContext(ctx).Where("expense_id = ?", id).Order("created_at ASC").Find(&flags).Error; err != nil {
+		return nil, fmt.Errorf("failed to list flags: %w", err)
+	}
+	return flags, nil
+}
+
+// CountByFlag counts the caller's flagged expenses dated in [from, to) per flag
+func (r *FlagRepository) CountByFlag(ctx context.Context, from, to time.Time) ([]*domain.FlagCount, error) {
+	var counts []*domain.FlagCount
+	err := ownedInBook(ctx, r.db.WithContext(ctx), "expenses").
+		Table("expense_flags f").
+		Select("f.flag, COUNT(*) AS count, SUM("+reportingAmount+") AS amount").
+		Joins("JOIN expenses ON expenses.id = f.expense_id").
+		Where("expenses.date >= ? AND expenses.date < ?", from, to).
+		Group("f.flag").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count flags: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,154 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.GroupRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For date range arguments
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// GroupRepository implements the domain.GroupRepository interface using PostgreSQL
+type GroupRepository struct {
+	db *gorm.DB
+}
+
+// NewGroupRepository creates a new PostgreSQL group repository
+func NewGroupRepository(db *gorm.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+// Create saves a new group owned by the caller; GORM inserts the members in the same transaction
+func (r *GroupRepository) Create(ctx context.Context, group *domain.Group) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	group.UserID = owner
+	if err := r.db.WithContext(ctx).Create(group).Error; err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a group with its members
+func (r *GroupRepository) GetByID(ctx context.Context, id string) (*domain.Group, error) {
+	groupID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrGroupNotFound
+	}
+
+	var group domain.Group
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Preload("Members", memberOrder).Where("id = ?", groupID).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	return &group, nil
+}
+
+// List returns all groups with their members ordered by name
+func (r *GroupRepository) List(ctx context.Context) ([]*domain.Group, error) {
+	var groups []*domain.Group
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Preload("Members", memberOrder).Order("name ASC").Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+	return groups, nil
+}
+
+// AddMember saves a new member of an existing group
+func (r *GroupRepository) AddMember(ctx context.Context, member *domain.GroupMember) error {
+	if err := r.db.WithContext(ctx).Create(member).Error; err != nil {
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember deletes a member and clears the attribution of their expenses in one transaction
+func (r *GroupRepository) RemoveMember(ctx context.Context, groupID, memberID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND group_id = ?", memberID, groupID).Delete(&domain.GroupMember{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove group member: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrGroupMemberNotFound
+		}
+		if err := ownedBy(ctx, tx.Model(&domain.Expense{}), "user_id").Where("member_id = ?", memberID).Update("member_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to clear expense attribution: %w", err)
+		}
+		return nil
+	})
+}
+
+// AttributeExpenses records the member as the one who paid the caller's expenses
+// Runs in a transaction so a missing expense (or someone else's) leaves every expense untouched
+func (r *GroupRepository) AttributeExpenses(ctx context.Context, memberID uuid.UUID, expenseIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := ownedInBook(ctx, tx.Model(&domain.Expense{}), "").Where("id IN ?", expenseIDs).Update("member_id", memberID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to attribute expenses: %w", result.Error)
+		}
+		if result.RowsAffected != int64(len(expenseIDs)) {
+			return domain.ErrExpenseNotFound
+		}
+		return nil
+	})
+}
+
+// SaveBudget inserts a new group budget or updates an existing one
+func (r *GroupRepository) SaveBudget(ctx context.Context, budget *domain.GroupBudget) error {
+	if err := r.db.WithContext(ctx).Save(budget).Error; err != nil {
+		return fmt.Errorf("failed to save group budget: %w", err)
+	}
+	return nil
+}
+
+// Budgets returns the group's budgets ordered by category
+func (r *GroupRepository) Budgets(ctx context.Context, groupID uuid.UUID) ([]*domain.GroupBudget, error) {
+	var budgets []*domain.GroupBudget
+	if err := r.db.WithContext(ctx).Where("group_id = ?", groupID).Order("category ASC").Find(&budgets).Error; err != nil {
+		return nil, fmt.Errorf("failed to list group budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+// DeleteBudget removes a group budget
+func (r *GroupRepository) DeleteBudget(ctx context.Context, groupID, budgetID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND group_id = ?", budgetID, groupID).Delete(&domain.GroupBudget{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete group budget: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrBudgetNotFound
+	}
+	return nil
+}
+
+// SpendingByMember sums the group members' expenses dated in [from, to) per member and category
+// Like SpendingByCategory it uses the locked base-currency amount
+func (r *GroupRepository) SpendingByMember(ctx context.Context, groupID uuid.UUID, from, to time.Time) ([]*domain.MemberSpending, error) {
+	var spending []*domain.MemberSpending
+	err := r.db.WithContext(ctx).Model(&domain.Expense{}).
+		Select("member_id, category, SUM("+reportingAmount+") AS amount").
+		Where("member_id IN (?)", r.db.Model(&domain.GroupMember{}).Select("id").Where("group_id = ?", groupID)).
+		Where("date >= ? AND date < ?", from, to).
+		Group("member_id, category").
+		Scan(&spending).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum spending by member: %w", err)
+	}
+	return spending, nil
+}
+
+// memberOrder preloads a group's members in the order they joined
+func memberOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at ASC")
+}
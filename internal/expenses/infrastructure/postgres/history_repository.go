@@ -0,0 +1,55 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ReportHistory interface on top of the expenses_history table
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For the time reports are read as of
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// expensesAsOf is the table the expenses are read from as of a past time: the versions of the
+// expenses current then (valid_from <= t < valid_to), decoded back into expenses rows
+// It is aliased expenses, so the report queries and ownedInBook work on it unchanged
+const expensesAsOf = "(SELECT (jsonb_populate_record(NULL::expenses, data)).* FROM expenses_history " +
+	"WHERE valid_from <= ? AND valid_to > ?) AS expenses"
+
+// expenseVersion is a row of expenses_history, which the expenses_history migration creates
+// and a trigger writes; only erasure touches it from Go
+type expenseVersion struct{}
+
+// TableName tells GORM the history table name
+func (expenseVersion) TableName() string {
+	return "expenses_history"
+}
+
+// FiguresAsOf returns the spending and tax totals of the expenses as they were at the given time
+// Expense history starts when the expenses_history migration ran, so earlier times fail
+// with domain.ErrReportHistoryNotRecorded
+func (r *Repository) FiguresAsOf(ctx context.Context, at time.Time) (domain.ReportFigures, error) {
+	var started schemaMigration
+	err := r.db.WithContext(ctx).Where("name = ?", expensesHistoryMigration).Take(&started).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find when expense history started: %w", err)
+	}
+	if at.Before(started.AppliedAt) {
+		return nil, domain.ErrReportHistoryNotRecorded
+	}
+
+	past := *r
+	past.asOf = at
+	return &past, nil
+}
+
+// expenses points query at the expenses, or for the copies FiguresAsOf returns, at the
+// expenses as they were then
+func (r *Repository) expenses(query *gorm.DB) *gorm.DB {
+	if r.asOf.IsZero() {
+		return query
+	}
+	return query.Table(expensesAsOf, r.asOf, r.asOf)
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"myexpenses/internal/db"
+	"myexpenses/internal/expenses/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// testDatabase connects to the database named by TEST_DB_NAME and migrates it
+// The other settings come from DB_HOST, DB_PORT, DB_USER, DB_PASSWORD and DB_SSLMODE, as for the API
+// Without TEST_DB_NAME the test is skipped
+func testDatabase(t *testing.T) (*gorm.DB, *Repository) {
+	t.Helper()
+	name := os.Getenv("TEST_DB_NAME")
+	if name == "" {
+		t.Skip("set TEST_DB_NAME to run the PostgreSQL tests")
+	}
+	config := db.NewConfig()
+	config.DBName = name
+	conn, err := db.Connect(config)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	repo := NewRepository(conn)
+	if err := repo.AutoMigrate(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return conn, repo
+}
+
+// createHistoryExpense saves an expense of 10.00 in a category of its own, removed with its history when the test ends
+func createHistoryExpense(t *testing.T, conn *gorm.DB, repo *Repository) *domain.Expense {
+	t.Helper()
+	expense, err := domain.NewExpense("history test", domain.Cents(1000, domain.DefaultBaseCurrency), "history-"+uuid.NewString(), time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("NewExpense() error = %v", err)
+	}
+	if err := repo.Create(context.Background(), expense); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Exec("DELETE FROM expenses WHERE id = ?", expense.ID)
+		conn.Exec("DELETE FROM expenses_history WHERE expense_id = ?", expense.ID)
+	})
+	return expense
+}
+
+// spentIn returns what figures sum up for the expense's category on its day, in cents
+func spentIn(t *testing.T, figures domain.SpendingRepository, expense *domain.Expense) int64 {
+	t.Helper()
+	spending, err := figures.SpendingByCategory(context.Background(), expense.Date, expense.Date.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("SpendingByCategory() error = %v", err)
+	}
+	for _, category := range spending {
+		if category.Category == expense.Category {
+			return category.Amount.Minor
+		}
+	}
+	return 0
+}
+
+// historyVersions counts the recorded versions of an expense
+func historyVersions(t *testing.T, conn *gorm.DB, expense *domain.Expense) int64 {
+	t.Helper()
+	var count int64
+	if err := conn.Table("expenses_history").Where("expense_id = ?", expense.ID).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count versions: %v", err)
+	}
+	return count
+}
+
+func TestFiguresAsOfReturnsValuesBeforeUpdate(t *testing.T) {
+	conn, repo := testDatabase(t)
+	ctx := context.Background()
+	expense := createHistoryExpense(t, conn, repo)
+
+	var before time.Time
+	if err := conn.Raw("SELECT clock_timestamp()").Scan(&before).Error; err != nil {
+		t.Fatalf("failed to read the database clock: %v", err)
+	}
+	expense.Amount = domain.Cents(2500, domain.DefaultBaseCurrency)
+	expense.BaseAmount = expense.Amount
+	if err := repo.Update(ctx, expense); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if got := spentIn(t, repo, expense); got != 2500 {
+		t.Errorf("current spending = %d cents, want 2500", got)
+	}
+	past, err := repo.FiguresAsOf(ctx, before)
+	if err != nil {
+		t.Fatalf("FiguresAsOf() error = %v", err)
+	}
+	if got := spentIn(t, past, expense); got != 1000 {
+		t.Errorf("spending as of before the update = %d cents, want 1000", got)
+	}
+}
+
+func TestMigrationsDoNotRecordHistory(t *testing.T) {
+	conn, repo := testDatabase(t)
+	expense := createHistoryExpense(t, conn, repo)
+	versions := historyVersions(t, conn, expense)
+
+	// A data migration rewriting the expense, like expense_amounts_numeric
+	released := migrations
+	t.Cleanup(func() {
+		migrations = released
+		conn.Exec("DELETE FROM schema_migrations WHERE name = ?", "test_rewrite_expense")
+	})
+	var latest int
+	conn.Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&latest)
+	migrations = append(append([]migration(nil), released...), migration{
+		Version:    latest + 1,
+		Name:       "test_rewrite_expense",
+		Statements: []string{"UPDATE expenses SET net_amount = amount WHERE id = '" + expense.ID.String() + "'"},
+	})
+	if err := runMigrations(conn); err != nil {
+		t.Fatalf("runMigrations() error = %v", err)
+	}
+	if got := historyVersions(t, conn, expense); got != versions {
+		t.Errorf("versions after the migration = %d, want %d", got, versions)
+	}
+
+	// The trigger is back on for everything else
+	expense.Amount = domain.Cents(2500, domain.DefaultBaseCurrency)
+	if err := repo.Update(context.Background(), expense); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got := historyVersions(t, conn, expense); got != versions+1 {
+		t.Errorf("versions after an update = %d, want %d", got, versions+1)
+	}
+}
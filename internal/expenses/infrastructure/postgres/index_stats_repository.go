@@ -0,0 +1,39 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.IndexStatsRepository interface on top of pg_stat_user_indexes
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// IndexStatsRepository implements the domain.IndexStatsRepository interface using PostgreSQL
+type IndexStatsRepository struct {
+	db *gorm.DB
+}
+
+// NewIndexStatsRepository creates a new PostgreSQL index statistics repository
+func NewIndexStatsRepository(db *gorm.DB) *IndexStatsRepository {
+	return &IndexStatsRepository{db: db}
+}
+
+// IndexUsage returns the usage of every index in the current schema, largest first
+func (r *IndexStatsRepository) IndexUsage(ctx context.Context) ([]*domain.IndexUsage, error) {
+	var usage []*domain.IndexUsage
+	err := r.db.WithContext(ctx).Raw(
+		"SELECT relname AS \"table\", indexrelname AS \"index\", " +
+			"idx_scan AS scans, idx_tup_read AS reads, idx_tup_fetch AS hits, " +
+			"pg_relation_size(indexrelid) AS bytes " +
+			"FROM pg_stat_user_indexes " +
+			"WHERE schemaname = current_schema() " +
+			"ORDER BY pg_relation_size(indexrelid) DESC, relname, indexrelname",
+	).Scan(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index statistics: %w", err)
+	}
+	return usage, nil
+}
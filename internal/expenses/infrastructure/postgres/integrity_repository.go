@@ -0,0 +1,126 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.IntegrityRepository interface used by the admin integrity checker
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// IntegrityRepository implements the domain.IntegrityRepository interface using PostgreSQL
+// The tables have no foreign keys between them, so dangling references are found with anti-joins
+type IntegrityRepository struct {
+	db *gorm.DB
+}
+
+// NewIntegrityRepository creates a new PostgreSQL integrity repository
+func NewIntegrityRepository(db *gorm.DB) *IntegrityRepository {
+	return &IntegrityRepository{db: db}
+}
+
+// OrphanedAttachments returns attachments whose expense doesn't exist
+func (r *IntegrityRepository) OrphanedAttachments(ctx context.Context) ([]*domain.Attachment, error) {
+	var attachments []*domain.Attachment
+	err := r.db.WithContext(ctx).
+		Where("NOT EXISTS (SELECT 1 FROM expenses e WHERE e.id = attachments.expense_id)").
+		Order("created_at ASC").
+		Find(&attachments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find orphaned attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// ListAttachments returns every attachment record
+func (r *IntegrityRepository) ListAttachments(ctx context.Context) ([]*domain.Attachment, error) {
+	var attachments []*domain.Attachment
+	if err := r.db.WithContext(ctx).Order("created_at ASC").Find(&attachments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// ExpensesWithMissingAccount returns expenses whose account_id points at no account
+func (r *IntegrityRepository) ExpensesWithMissingAccount(ctx context.Context) ([]*domain.Expense, error) {
+	var expenses []*domain.Expense
+	err := r.db.WithContext(ctx).
+		Where("account_id IS NOT NULL").
+		Where("NOT EXISTS (SELECT 1 FROM accounts a WHERE a.id = expenses.account_id)").
+		Order("date ASC").
+		Find(&expenses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expenses with missing accounts: %w", err)
+	}
+	return expenses, nil
+}
+
+// TransfersWithMissingAccount returns transfers from or to an account that doesn't exist
+func (r *IntegrityRepository) TransfersWithMissingAccount(ctx context.Context) ([]*domain.Transfer, error) {
+	var transfers []*domain.Transfer
+	err := r.db.WithContext(ctx).
+		Where("NOT EXISTS (SELECT 1 FROM accounts a WHERE a.id = transfers.from_account_id)").
+		Or("NOT EXISTS (SELECT 1 FROM accounts a WHERE a.id = transfers.to_account_id)").
+		Order("date ASC").
+		Find(&transfers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transfers with missing accounts: %w", err)
+	}
+	return transfers, nil
+}
+
+// ClearExpenseAccount detaches an expense from its account
+func (r *IntegrityRepository) ClearExpenseAccount(ctx context.Context, expenseID string) error {
+	id, err := uuid.Parse(expenseID)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format: %w", err)
+	}
+	return r.db.WithContext(ctx).Model(&domain.Expense{}).Where("id = ?", id).Update("account_id", nil).Error
+}
+
+// ExpensesWithBaseAmountDrift returns expenses whose stored base amount doesn't match amount x rate
+// Rows from before conversions were stored (base_amount 0) are not drift and are skipped
+func (r *IntegrityRepository) ExpensesWithBaseAmountDrift(ctx context.Context, tolerance float64) ([]*domain.Expense, error) {
+	var expenses []*domain.Expense
+	err := r.db.WithContext(ctx).
+		Where("base_amount <> 0").
+		Where("ABS(base_amount - ROUND(CAST(amount * exchange_rate AS numeric), 2)) > ?", tolerance).
+		Order("date ASC").
+		Find(&expenses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find base amount drift: %w", err)
+	}
+	return expenses, nil
+}
+
+// CountCategories returns how many categories are defined
+func (r *IntegrityRepository) CountCategories(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&domain.Category{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count categories: %w", err)
+	}
+	return count, nil
+}
+
+// UnknownExpenseCategories returns the categories used by expenses that aren't in the category list
+func (r *IntegrityRepository) UnknownExpenseCategories(ctx context.Context) ([]*domain.CategoryUsage, error) {
+	var usage []*domain.CategoryUsage
+	err := r.db.WithContext(ctx).
+		Model(&domain.Expense{}).
+		Select("category, COUNT(*) AS count").
+		// Each book has its own categories, so the category must be in the expense's book
+		Where("NOT EXISTS (SELECT 1 FROM categories c WHERE c.name = expenses.category AND c.book_id IS NOT DISTINCT FROM expenses.book_id)").
+		// Uncategorized is the importer's placeholder, not a real category
+		Where("category <> ?", domain.UncategorizedCategory).
+		Group("category").
+		Order("category ASC").
+		Scan(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find unknown expense categories: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,116 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.LoanRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// LoanRepository implements the domain.LoanRepository interface using PostgreSQL
+// Loans are owned like expenses: each caller only sees their own
+type LoanRepository struct {
+	db *gorm.DB
+}
+
+// NewLoanRepository creates a new PostgreSQL loan repository
+func NewLoanRepository(db *gorm.DB) *LoanRepository {
+	return &LoanRepository{db: db}
+}
+
+// Create saves a new loan owned by the caller
+func (r *LoanRepository) Create(ctx context.Context, loan *domain.Loan) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	loan.UserID = owner
+	if err := r.db.WithContext(ctx).Create(loan).Error; err != nil {
+		return fmt.Errorf("failed to create loan: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves one of the caller's loans
+func (r *LoanRepository) GetByID(ctx context.Context, id string) (*domain.Loan, error) {
+	loanID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrLoanNotFound
+	}
+
+	var loan domain.Loan
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", loanID).First(&loan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrLoanNotFound
+		}
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+	return &loan, nil
+}
+
+// List returns the caller's loans, newest first
+func (r *LoanRepository) List(ctx context.Context) ([]*domain.Loan, error) {
+	var loans []*domain.Loan
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Order("start_date DESC").Find(&loans).Error; err != nil {
+		return nil, fmt.Errorf("failed to list loans: %w", err)
+	}
+	return loans, nil
+}
+
+// Delete removes one of the caller's loans
+// Its payments stay as expenses; they are only unlinked, in the same transaction
+func (r *LoanRepository) Delete(ctx context.Context, id string) error {
+	loanID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrLoanNotFound
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := ownedBy(ctx, tx, "user_id").Where("id = ?", loanID).Delete(&domain.Loan{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete loan: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrLoanNotFound
+		}
+		if err := tx.Model(&domain.Expense{}).Where("loan_id = ?", loanID).Update("loan_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to unlink loan payments: %w", err)
+		}
+		return nil
+	})
+}
+
+// AssignPayments links the caller's expenses to the loan
+// Runs in a transaction so a missing expense leaves every expense untouched
+func (r *LoanRepository) AssignPayments(ctx context.Context, loanID uuid.UUID, expenseIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := ownedInBook(ctx, tx.Model(&domain.Expense{}), "").Where("id IN ?", expenseIDs).Update("loan_id", loanID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to assign payments to loan: %w", result.Error)
+		}
+		if result.RowsAffected != int64(len(expenseIDs)) {
+			return domain.ErrExpenseNotFound
+		}
+		return nil
+	})
+}
+
+// RemovePayment unlinks one of the caller's expenses from the loan
+func (r *LoanRepository) RemovePayment(ctx context.Context, loanID uuid.UUID, expenseID uuid.UUID) error {
+	result := ownedInBook(ctx, r.db.WithContext(ctx).Model(&domain.Expense{}), "").
+		Where("id = ? AND loan_id = ?", expenseID, loanID).
+		Update("loan_id", nil)
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove payment from loan: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrExpenseNotFound
+	}
+	return nil
+}
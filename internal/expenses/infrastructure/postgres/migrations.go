@@ -0,0 +1,379 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file contains the versioned schema migrations that AutoMigrate can't express
+package postgres
+
+import (
+	"fmt"  // For formatted string operations and error wrapping
+	"time" // For recording when a migration ran
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// expensesHistoryMigration names the migration that started recording expense history
+const expensesHistoryMigration = "expenses_history"
+
+// expensesHistoryTrigger is the trigger the expenses_history migration records it with
+const expensesHistoryTrigger = "trg_expenses_history"
+
+// migration is one versioned schema change
+// Migrations run in order, each in its own transaction, and are recorded in schema_migrations
+// so they are applied exactly once per database. Never edit a released migration: add a new one
+type migration struct {
+	Version    int
+	Name       string
+	Statements []string
+}
+
+// schemaMigration is a row of the schema_migrations bookkeeping table
+type schemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName tells GORM the bookkeeping table name
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrations are the indexes shaped after the queries the API actually runs
+// AutoMigrate only creates the single-column indexes declared in struct tags
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "expenses_date_desc",
+		// GET /expenses lists newest first; date ranges drive every report
+		Statements: []string{
+			"CREATE INDEX IF NOT EXISTS idx_expenses_date_desc ON expenses (date DESC, id)",
+		},
+	},
+	{
+		Version: 2,
+		Name:    "expenses_category_date",
+		// Budget progress, forecasts and period comparisons sum one category over a date range
+		Statements: []string{
+			"CREATE INDEX IF NOT EXISTS idx_expenses_category_date ON expenses (category, date)",
+		},
+	},
+	{
+		Version: 3,
+		Name:    "expenses_description_trigram",
+		// ?description= is a case-insensitive substring match (ILIKE '%...%'),
+		// which only a trigram index can serve
+		Statements: []string{
+			"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+			"CREATE INDEX IF NOT EXISTS idx_expenses_description_trgm ON expenses USING GIN (description gin_trgm_ops)",
+		},
+	},
+	{
+		Version: 4,
+		Name:    "expenses_owner",
+		// Every expense query is scoped to its owner, so lists lead with user_id;
+		// the foreign key keeps expenses from pointing at users that don't exist
+		Statements: []string{
+			"ALTER TABLE expenses ADD CONSTRAINT fk_expenses_user FOREIGN KEY (user_id) REFERENCES users (id)",
+			"CREATE INDEX IF NOT EXISTS idx_expenses_user_date_desc ON expenses (user_id, date DESC, id)",
+		},
+	},
+	{
+		Version: 5,
+		Name:    "expenses_search_vector",
+		// Search stems descriptions in their own language (the language column), which an
+		// expression index can't do: casting text to regconfig isn't immutable. A trigger keeps
+		// a tsvector column up to date instead, with the words both stemmed and unstemmed.
+		// Older expenses have no language and are indexed unstemmed until they are edited
+		Statements: []string{
+			"ALTER TABLE expenses ADD COLUMN IF NOT EXISTS search_vector tsvector",
+			`CREATE OR REPLACE FUNCTION expenses_search_vector() RETURNS trigger LANGUAGE plpgsql AS $$
+			BEGIN
+				NEW.search_vector :=
+					to_tsvector(coalesce(nullif(NEW.language, ''), 'simple')::regconfig, coalesce(NEW.description, '') || ' ' || coalesce(NEW.category, '')) ||
+					to_tsvector('simple', coalesce(NEW.description, '') || ' ' || coalesce(NEW.category, ''));
+				RETURN NEW;
+			END
+			$$`,
+			"CREATE TRIGGER trg_expenses_search_vector BEFORE INSERT OR UPDATE OF description, category, language ON expenses " +
+				"FOR EACH ROW EXECUTE FUNCTION expenses_search_vector()",
+			"UPDATE expenses SET search_vector = to_tsvector('simple', coalesce(description, '') || ' ' || coalesce(category, ''))",
+			"DROP INDEX IF EXISTS idx_expenses_search",
+			"CREATE INDEX IF NOT EXISTS idx_expenses_search_vector ON expenses USING GIN (search_vector)",
+		},
+	},
+	{
+		Version: 6,
+		Name:    "expenses_search_vector_encrypted",
+		// Encrypted descriptions (see encryption.go) are left out of the search vector: indexing the
+		// ciphertext finds nothing, and indexing the plaintext would store its words readably again.
+		// Such expenses are found by category and receipt text only
+		Statements: []string{
+			`CREATE OR REPLACE FUNCTION expenses_search_vector() RETURNS trigger LANGUAGE plpgsql AS $$
+			DECLARE
+				description text := CASE WHEN NEW.description LIKE 'enc:%' THEN '' ELSE coalesce(NEW.description, '') END;
+			BEGIN
+				NEW.search_vector :=
+					to_tsvector(coalesce(nullif(NEW.language, ''), 'simple')::regconfig, description || ' ' || coalesce(NEW.category, '')) ||
+					to_tsvector('simple', description || ' ' || coalesce(NEW.category, ''));
+				RETURN NEW;
+			END
+			$$`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "books",
+		// Category names and budget categories used to be unique overall; with books they are
+		// unique within a book. The default book has no book_id, so it is compared as the nil UUID
+		Statements: []string{
+			"DROP INDEX IF EXISTS idx_categories_name",
+			"DROP INDEX IF EXISTS idx_budgets_category",
+			"CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_book_name ON categories " +
+				"(COALESCE(book_id, '00000000-0000-0000-0000-000000000000'), name)",
+			"CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_book_category ON budgets " +
+				"(COALESCE(book_id, '00000000-0000-0000-0000-000000000000'), category)",
+			"CREATE INDEX IF NOT EXISTS idx_expenses_user_book_date ON expenses (user_id, book_id, date DESC)",
+		},
+	},
+	{
+		Version: 8,
+		Name:    "expense_tags",
+		// The GIN index serves ?tag= (tags @> '["x"]'); jsonb_path_ops is smaller than the
+		// default operator class and supports exactly the containment operator
+		Statements: []string{
+			"CREATE INDEX IF NOT EXISTS idx_expenses_tags ON expenses USING GIN (tags jsonb_path_ops)",
+		},
+	},
+	{
+		Version: 9,
+		Name:    "monthly_budgets",
+		// A category may now have a budget per month next to its recurring one (month '')
+		Statements: []string{
+			"DROP INDEX IF EXISTS idx_budgets_book_category",
+			"CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_book_category_month ON budgets " +
+				"(COALESCE(book_id, '00000000-0000-0000-0000-000000000000'), category, month)",
+		},
+	},
+	{
+		Version: 10,
+		Name:    "expense_metadata",
+		// Like the tags index, it serves ?meta.<key>= (metadata @> '{"key": value}')
+		Statements: []string{
+			"CREATE INDEX IF NOT EXISTS idx_expenses_metadata ON expenses USING GIN (metadata jsonb_path_ops)",
+		},
+	},
+	{
+		Version: 11,
+		Name:    "reimbursement_status",
+		// Expenses marked reimbursable before the lifecycle existed haven't been claimed yet
+		Statements: []string{
+			"UPDATE expenses SET reimbursement_status = 'pending' WHERE reimbursable AND COALESCE(reimbursement_status, '') = ''",
+		},
+	},
+	{
+		Version: 12,
+		Name:    "account_owners",
+		// Accounts and transfers were shared before they were owned; each goes to the user whose
+		// expenses were paid from the account. Accounts nobody paid from stay with the local user
+		Statements: []string{
+			"UPDATE accounts SET user_id = (SELECT e.user_id FROM expenses e WHERE e.account_id = accounts.id AND e.user_id IS NOT NULL ORDER BY e.created_at LIMIT 1) WHERE user_id IS NULL",
+			"UPDATE transfers SET user_id = a.user_id FROM accounts a WHERE transfers.from_account_id = a.id AND transfers.user_id IS NULL",
+		},
+	},
+	{
+		Version: 13,
+		Name:    expensesHistoryMigration,
+		// expenses_history keeps every version of every expense with the time span it was current,
+		// so reports can be rebuilt as of a past time (see history_repository.go). A trigger writes
+		// it, whatever changes the expenses. Versions are stored as JSON and read back as expenses
+		// rows, which keeps them readable when columns are added later. The search vector is left
+		// out: it is derived and large. Existing expenses are recorded as of now: their past is unknown
+		Statements: []string{
+			"CREATE TABLE IF NOT EXISTS expenses_history (" +
+				"id bigserial PRIMARY KEY, expense_id uuid NOT NULL, user_id uuid, book_id uuid, data jsonb NOT NULL, " +
+				"valid_from timestamptz NOT NULL, valid_to timestamptz NOT NULL DEFAULT 'infinity')",
+			"CREATE INDEX IF NOT EXISTS idx_expenses_history_expense ON expenses_history (expense_id, valid_to)",
+			"CREATE INDEX IF NOT EXISTS idx_expenses_history_user_valid ON expenses_history (user_id, valid_from, valid_to)",
+			`CREATE OR REPLACE FUNCTION expenses_history() RETURNS trigger LANGUAGE plpgsql AS $$
+			BEGIN
+				IF TG_OP IN ('UPDATE', 'DELETE') THEN
+					UPDATE expenses_history SET valid_to = now() WHERE expense_id = OLD.id AND valid_to = 'infinity';
+				END IF;
+				IF TG_OP IN ('INSERT', 'UPDATE') THEN
+					INSERT INTO expenses_history (expense_id, user_id, book_id, data, valid_from)
+					VALUES (NEW.id, NEW.user_id, NEW.book_id, to_jsonb(NEW) - 'search_vector', now());
+				END IF;
+				RETURN NULL;
+			END
+			$$`,
+			"CREATE TRIGGER trg_expenses_history AFTER INSERT OR UPDATE OR DELETE ON expenses " +
+				"FOR EACH ROW EXECUTE FUNCTION expenses_history()",
+			"INSERT INTO expenses_history (expense_id, user_id, book_id, data, valid_from) " +
+				"SELECT id, user_id, book_id, to_jsonb(e) - 'search_vector', now() FROM expenses e",
+		},
+	},
+	{
+		Version: 14,
+		Name:    "expense_amounts_numeric",
+		// Expense amounts were doubles; they are exact cents now (domain.Money). AutoMigrate
+		// already changes the column types, so altering them again only covers databases where
+		// it didn't. Net amounts that drifted from gross - tax by float rounding are recomputed
+		Statements: []string{
+			"ALTER TABLE expenses " +
+				"ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2), " +
+				"ALTER COLUMN base_amount TYPE numeric(19,2) USING round(base_amount::numeric, 2), " +
+				"ALTER COLUMN net_amount TYPE numeric(19,2) USING round(net_amount::numeric, 2), " +
+				"ALTER COLUMN tax_amount TYPE numeric(19,2) USING round(tax_amount::numeric, 2)",
+			"UPDATE expenses SET net_amount = amount - tax_amount " +
+				"WHERE (vat_rate IS NOT NULL OR tax_amount <> 0) AND net_amount <> amount - tax_amount",
+		},
+	},
+	{
+		Version: 15,
+		Name:    "trip_group_reconciliation_form_owners",
+		// Trips, groups, reconciliations and public forms were shared before they were owned; each
+		// goes to the user whose expenses it holds. Those holding none stay with the local user.
+		// Form submissions go to the owner of their form, whose staging area they are in now
+		Statements: []string{
+			"UPDATE trips SET user_id = (SELECT e.user_id FROM expenses e WHERE e.trip_id = trips.id AND e.user_id IS NOT NULL ORDER BY e.created_at LIMIT 1) WHERE user_id IS NULL",
+			"UPDATE groups SET user_id = (SELECT e.user_id FROM expenses e JOIN group_members m ON m.id = e.member_id " +
+				"WHERE m.group_id = groups.id AND e.user_id IS NOT NULL ORDER BY e.created_at LIMIT 1) WHERE user_id IS NULL",
+			"UPDATE reconciliation_sessions SET user_id = COALESCE(" +
+				"(SELECT e.user_id FROM expenses e JOIN statement_lines l ON l.expense_id = e.id " +
+				"WHERE l.session_id = reconciliation_sessions.id AND e.user_id IS NOT NULL ORDER BY e.created_at LIMIT 1), " +
+				"(SELECT a.user_id FROM accounts a WHERE a.id = reconciliation_sessions.account_id)) WHERE user_id IS NULL",
+			"UPDATE public_forms SET user_id = (SELECT e.user_id FROM expenses e JOIN staged_expenses s ON s.expense_id = e.id " +
+				"WHERE s.form_id = public_forms.id AND e.user_id IS NOT NULL ORDER BY e.created_at LIMIT 1) WHERE user_id IS NULL",
+			"UPDATE staged_expenses SET user_id = f.user_id FROM public_forms f WHERE staged_expenses.form_id = f.id AND staged_expenses.user_id IS NULL",
+		},
+	},
+	{
+		Version: 16,
+		Name:    "budget_account_amounts_numeric",
+		// Budgets, account balances, transfers and statement lines are exact cents now, like
+		// expenses since expense_amounts_numeric, so totals add up without float rounding
+		Statements: []string{
+			"ALTER TABLE budgets ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2)",
+			"ALTER TABLE group_budgets ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2)",
+			"ALTER TABLE accounts ALTER COLUMN opening_balance TYPE numeric(19,2) USING round(opening_balance::numeric, 2)",
+			"ALTER TABLE transfers ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2)",
+			"ALTER TABLE statement_lines ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2)",
+		},
+	},
+	{
+		Version: 17,
+		Name:    "pending_receipt_owners",
+		// The receipt inbox was shared by everyone; each receipt now waits in the inbox of whoever
+		// uploaded it, in the book they uploaded it in. Receipts uploaded before can't be traced
+		// to anyone and stay with the local user's default book
+		Statements: []string{
+			"ALTER TABLE pending_receipts ADD COLUMN IF NOT EXISTS user_id uuid, ADD COLUMN IF NOT EXISTS book_id uuid",
+			"CREATE INDEX IF NOT EXISTS idx_pending_receipts_owner ON pending_receipts (user_id, book_id, created_at)",
+		},
+	},
+	{
+		Version: 18,
+		Name:    "recurring_expense_owners",
+		// Recurring expenses were shared by everyone; each now belongs to the user who added it.
+		// Nothing links the older ones to a user, so they stay with the local user
+		Statements: []string{
+			"ALTER TABLE recurring_expenses ADD COLUMN IF NOT EXISTS user_id uuid",
+			"CREATE INDEX IF NOT EXISTS idx_recurring_expenses_user_id ON recurring_expenses (user_id)",
+		},
+	},
+	{
+		Version: 19,
+		Name:    "remaining_amounts_numeric",
+		// Every other amount is exact cents now too: receivables, recurring bills, planned purchases,
+		// form submissions, reimbursements, approval and policy thresholds, rule bounds and receipts
+		Statements: []string{
+			"ALTER TABLE receivables " +
+				"ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2), " +
+				"ALTER COLUMN settled_amount TYPE numeric(19,2) USING round(settled_amount::numeric, 2)",
+			"ALTER TABLE recurring_expenses ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2)",
+			"ALTER TABLE planned_purchases " +
+				"ALTER COLUMN estimated_amount TYPE numeric(19,2) USING round(estimated_amount::numeric, 2), " +
+				"ALTER COLUMN actual_amount TYPE numeric(19,2) USING round(actual_amount::numeric, 2)",
+			"ALTER TABLE public_forms ALTER COLUMN max_amount TYPE numeric(19,2) USING round(max_amount::numeric, 2)",
+			"ALTER TABLE staged_expenses " +
+				"ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2), " +
+				"ALTER COLUMN converted_amount TYPE numeric(19,2) USING round(converted_amount::numeric, 2)",
+			"ALTER TABLE reimbursement_incomes ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2)",
+			"ALTER TABLE reimbursement_batches " +
+				"ALTER COLUMN claimed TYPE numeric(19,2) USING round(claimed::numeric, 2), " +
+				"ALTER COLUMN paid TYPE numeric(19,2) USING round(paid::numeric, 2), " +
+				"ALTER COLUMN difference TYPE numeric(19,2) USING round(difference::numeric, 2)",
+			"ALTER TABLE approval_steps ALTER COLUMN min_amount TYPE numeric(19,2) USING round(min_amount::numeric, 2)",
+			"ALTER TABLE policy_rules ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2)",
+			"ALTER TABLE policy_violations ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2)",
+			"ALTER TABLE category_rules " +
+				"ALTER COLUMN min_amount TYPE numeric(19,2) USING round(min_amount::numeric, 2), " +
+				"ALTER COLUMN max_amount TYPE numeric(19,2) USING round(max_amount::numeric, 2)",
+			"ALTER TABLE pending_receipts ALTER COLUMN amount TYPE numeric(19,2) USING round(amount::numeric, 2)",
+		},
+	},
+	{
+		Version: 20,
+		Name:    "category_rule_owners",
+		// Categorization rules were shared by the whole deployment; each now belongs to a user's
+		// book, like the categories it assigns. Every user starts with a copy of the shared rules
+		// in their default book, so their imports are categorized as before; the shared rules
+		// themselves stay with the local user
+		Statements: []string{
+			"ALTER TABLE category_rules ADD COLUMN IF NOT EXISTS user_id uuid, ADD COLUMN IF NOT EXISTS book_id uuid",
+			"CREATE INDEX IF NOT EXISTS idx_category_rules_user_id ON category_rules (user_id)",
+			"INSERT INTO category_rules (id, pattern, matcher, min_amount, max_amount, account, source, category, priority, user_id, created_at) " +
+				"SELECT gen_random_uuid(), r.pattern, r.matcher, r.min_amount, r.max_amount, r.account, r.source, r.category, r.priority, u.id, r.created_at " +
+				"FROM category_rules r CROSS JOIN users u WHERE r.user_id IS NULL AND r.book_id IS NULL",
+		},
+	},
+}
+
+// runMigrations applies the migrations that haven't run on this database yet
+func runMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var applied []int
+	if err := db.Model(&schemaMigration{}).Pluck("version", &applied).Error; err != nil {
+		return fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	done := make(map[int]bool, len(applied))
+	for _, version := range applied {
+		done[version] = true
+	}
+
+	// Once expense history is recorded, migrations run with its trigger off: they convert or
+	// backfill rows, which changes how expenses are stored but not what they were, so they must
+	// not show up as new versions. Disabling it in the migration's transaction rolls back with it
+	recordingHistory := false
+	for _, m := range migrations {
+		if done[m.Version] {
+			recordingHistory = recordingHistory || m.Name == expensesHistoryMigration
+			continue
+		}
+		quiet := recordingHistory
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if quiet {
+				if err := tx.Exec("ALTER TABLE expenses DISABLE TRIGGER " + expensesHistoryTrigger).Error; err != nil {
+					return err
+				}
+			}
+			for _, statement := range m.Statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			if quiet {
+				if err := tx.Exec("ALTER TABLE expenses ENABLE TRIGGER " + expensesHistoryTrigger).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: db.NowFunc()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		recordingHistory = recordingHistory || m.Name == expensesHistoryMigration
+	}
+	return nil
+}
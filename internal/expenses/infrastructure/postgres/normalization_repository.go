@@ -0,0 +1,132 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements description normalization rules and description autocomplete
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For ordering suggestions counted in memory
+	"strings" // For escaping LIKE patterns
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// NormalizationRuleRepository implements the domain.NormalizationRuleRepository interface using PostgreSQL
+type NormalizationRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewNormalizationRuleRepository creates a new PostgreSQL normalization rule repository
+func NewNormalizationRuleRepository(db *gorm.DB) *NormalizationRuleRepository {
+	return &NormalizationRuleRepository{db: db}
+}
+
+// Create saves a new rule
+func (r *NormalizationRuleRepository) Create(ctx context.Context, rule *domain.NormalizationRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+// List returns all rules in the order they are applied
+func (r *NormalizationRuleRepository) List(ctx context.Context) ([]*domain.NormalizationRule, error) {
+	var rules []*domain.NormalizationRule
+	if err := r.db.WithContext(ctx).Order("position ASC, created_at ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list normalization rules: %w", err)
+	}
+	return rules, nil
+}
+
+// Delete removes a rule by its ID
+func (r *NormalizationRuleRepository) Delete(ctx context.Context, id string) error {
+	ruleID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid UUID format: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ?", ruleID).Delete(&domain.NormalizationRule{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete normalization rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNormalizationRuleNotFound
+	}
+	return nil
+}
+
+// SuggestDescriptions returns the caller's most used descriptions starting with prefix
+// This method implements the domain.AutocompleteRepository interface
+func (r *Repository) SuggestDescriptions(ctx context.Context, prefix string, limit int) ([]*domain.DescriptionSuggestion, error) {
+	// Manual expenses may not have a normalized description, so fall back to the raw one
+	const text = "COALESCE(NULLIF(normalized_description, ''), description)"
+
+	// Escape LIKE wildcards so a "%" typed by the user is matched literally
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix)
+	if fieldsEncrypted() {
+		return r.suggestEncryptedDescriptions(ctx, prefix, escaped, limit)
+	}
+
+	var suggestions []*domain.DescriptionSuggestion
+	err := ownedInBook(ctx, r.db.WithContext(ctx), "").
+		Model(&domain.Expense{}).
+		// (ARRAY_AGG(... ORDER BY date DESC))[1] picks the category of the most recent use
+		Select(text+" AS description, (ARRAY_AGG(category ORDER BY date DESC))[1] AS category, COUNT(*) AS count").
+		Where(text+" ILIKE ?", escaped+"%").
+		Group(text).
+		Order("count DESC, description ASC").
+		Limit(limit).
+		Scan(&suggestions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest descriptions: %w", err)
+	}
+	return suggestions, nil
+}
+
+// suggestEncryptedDescriptions is SuggestDescriptions for encrypted descriptions
+// Only normalized descriptions can be matched in SQL; expenses without one are decrypted
+// and matched here, and the counting and ordering happen here too
+func (r *Repository) suggestEncryptedDescriptions(ctx context.Context, prefix, escaped string, limit int) ([]*domain.DescriptionSuggestion, error) {
+	// Step 1: Candidates, newest first so the first category seen is the most recent one
+	var expenses []*domain.Expense
+	err := ownedInBook(ctx, r.db.WithContext(ctx), "").
+		Select("normalized_description", "description", "category", "date").
+		Where("normalized_description ILIKE ? OR normalized_description = ''", escaped+"%").
+		Order("date DESC").
+		Find(&expenses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest descriptions: %w", err)
+	}
+
+	// Step 2: Count the matching texts
+	byText := map[string]*domain.DescriptionSuggestion{}
+	var suggestions []*domain.DescriptionSuggestion
+	for _, expense := range expenses {
+		text := expense.NormalizedDescription
+		if text == "" {
+			text = expense.Description
+		}
+		if !strings.HasPrefix(strings.ToLower(text), strings.ToLower(prefix)) {
+			continue
+		}
+		suggestion := byText[text]
+		if suggestion == nil {
+			suggestion = &domain.DescriptionSuggestion{Description: text, Category: expense.Category}
+			byText[text] = suggestion
+			suggestions = append(suggestions, suggestion)
+		}
+		suggestion.Count++
+	}
+
+	// Step 3: Most used first, then alphabetically, like the SQL version
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		return suggestions[i].Description < suggestions[j].Description
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,88 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.OCRRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm"        // GORM ORM library
+	"gorm.io/gorm/clause" // For upserts
+)
+
+// OCRRepository implements the domain.OCRRepository interface using PostgreSQL
+// Its queries aren't scoped with ownedBy: settings and usage belong to tenants, not users
+type OCRRepository struct {
+	db *gorm.DB
+}
+
+// NewOCRRepository creates a new PostgreSQL OCR repository
+func NewOCRRepository(db *gorm.DB) *OCRRepository {
+	return &OCRRepository{db: db}
+}
+
+// GetSettings retrieves a tenant's OCR settings
+func (r *OCRRepository) GetSettings(ctx context.Context, tenantID string) (*domain.OCRSettings, error) {
+	var settings domain.OCRSettings
+	if err := conn(ctx, r.db).Where("tenant_id = ?", tenantID).First(&settings).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrOCRSettingsNotFound
+		}
+		return nil, fmt.Errorf("failed to get OCR settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// SaveSettings creates or replaces a tenant's OCR settings in one statement
+func (r *OCRRepository) SaveSettings(ctx context.Context, settings *domain.OCRSettings) error {
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"provider", "languages", "updated_at"}),
+	}).Create(settings).Error
+	if err != nil {
+		return fmt.Errorf("failed to save OCR settings: %w", err)
+	}
+	return nil
+}
+
+// DeleteSettings removes a tenant's OCR settings
+func (r *OCRRepository) DeleteSettings(ctx context.Context, tenantID string) error {
+	result := conn(ctx, r.db).Where("tenant_id = ?", tenantID).Delete(&domain.OCRSettings{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete OCR settings: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrOCRSettingsNotFound
+	}
+	return nil
+}
+
+// RecordUsage adds the counts of usage to the tenant's row for the provider and month
+// The upsert adds in the database, so concurrent recognitions don't lose counts
+func (r *OCRRepository) RecordUsage(ctx context.Context, usage *domain.OCRUsage) error {
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant_id"}, {Name: "provider"}, {Name: "month"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"documents":  gorm.Expr("ocr_usages.documents + EXCLUDED.documents"),
+			"failures":   gorm.Expr("ocr_usages.failures + EXCLUDED.failures"),
+			"characters": gorm.Expr("ocr_usages.characters + EXCLUDED.characters"),
+		}),
+	}).Create(usage).Error
+	if err != nil {
+		return fmt.Errorf("failed to record OCR usage: %w", err)
+	}
+	return nil
+}
+
+// ListUsage returns a tenant's usage, most recent month first
+func (r *OCRRepository) ListUsage(ctx context.Context, tenantID string) ([]*domain.OCRUsage, error) {
+	var usage []*domain.OCRUsage
+	err := conn(ctx, r.db).Where("tenant_id = ?", tenantID).Order("month DESC, provider ASC").Find(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OCR usage: %w", err)
+	}
+	return usage, nil
+}
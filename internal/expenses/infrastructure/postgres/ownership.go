@@ -0,0 +1,81 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file scopes expense queries to the user who owns the expenses and the book they are kept in
+package postgres
+
+import (
+	"context" // For reading the caller
+
+	"myexpenses/internal/auth" // The caller whose expenses a query may see
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// ownedBy restricts query to the expenses of the caller in ctx; column is their user_id column
+// Callers without a user account (the anonymous local user) see the expenses nobody owns,
+// which are all the expenses recorded before user accounts existed
+func ownedBy(ctx context.Context, query *gorm.DB, column string) *gorm.DB {
+	userID := auth.UserID(ctx)
+	if userID == "" {
+		return query.Where(column + " IS NULL")
+	}
+	owner, err := uuid.Parse(userID)
+	if err != nil {
+		// Users are identified by UUIDs; any other ID owns nothing
+		return query.Where("FALSE")
+	}
+	return query.Where(column+" = ?", owner)
+}
+
+// ownerOf returns the user new expenses created by the caller in ctx belong to
+// (nil for the anonymous local user), or ok=false if the caller's user ID isn't valid
+func ownerOf(ctx context.Context) (owner *uuid.UUID, ok bool) {
+	userID := auth.UserID(ctx)
+	if userID == "" {
+		return nil, true
+	}
+	parsed, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// inBook restricts query to the rows of the caller's current book; column is their book_id column
+// Requests without a book work in the default book, whose rows have no book_id
+func inBook(ctx context.Context, query *gorm.DB, column string) *gorm.DB {
+	book, ok := bookOf(ctx)
+	switch {
+	case !ok:
+		// The middleware only lets valid book IDs through; anything else sees nothing
+		return query.Where("FALSE")
+	case book == nil:
+		return query.Where(column + " IS NULL")
+	default:
+		return query.Where(column+" = ?", *book)
+	}
+}
+
+// ownedInBook restricts query to the caller's rows in their current book, for tables kept per
+// owner and book like expenses; table qualifies the columns when the query joins others ("" if not)
+func ownedInBook(ctx context.Context, query *gorm.DB, table string) *gorm.DB {
+	prefix := ""
+	if table != "" {
+		prefix = table + "."
+	}
+	return inBook(ctx, ownedBy(ctx, query, prefix+"user_id"), prefix+"book_id")
+}
+
+// bookOf returns the book rows created by the caller in ctx are kept in
+// (nil for the default book), or ok=false if the book ID isn't valid
+func bookOf(ctx context.Context) (book *uuid.UUID, ok bool) {
+	bookID := auth.BookID(ctx)
+	if bookID == "" {
+		return nil, true
+	}
+	parsed, err := uuid.Parse(bookID)
+	if err != nil {
+		return nil, false
+	}
+	return &parsed, true
+}
@@ -0,0 +1,53 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.PatternRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For date range arguments and the timezone
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// timestamped is the SQL condition for expenses that were recorded with a time of day
+// Expenses entered by day are stored at midnight UTC
+const timestamped = "(date AT TIME ZONE 'UTC')::time <> '00:00'"
+
+// SpendingByWeekdayHour sums the caller's expenses dated in [from, to) per weekday and hour
+// The grouping is done in SQL, so a year of card transactions comes back as at most 7×25 rows
+func (r *Repository) SpendingByWeekdayHour(ctx context.Context, from, to time.Time, location *time.Location) ([]*domain.PatternCell, error) {
+	// Step 1: Expenses with a time are moved into the caller's timezone, which can change their
+	// weekday; the others keep their date
+	local := fmt.Sprintf("CASE WHEN %s THEN date AT TIME ZONE @tz ELSE date AT TIME ZONE 'UTC' END", timestamped)
+
+	// Step 2: Group by ISO weekday (Monday = 1) and hour, leaving the hour NULL without a time
+	var rows []struct {
+		Weekday int
+		Hour    *int
+		Amount  domain.Money
+		Count   int
+	}
+	err := ownedInBook(ctx, r.db.WithContext(ctx), "").Model(&domain.Expense{}).
+		Select(fmt.Sprintf("EXTRACT(ISODOW FROM %s)::int AS weekday, CASE WHEN %s THEN EXTRACT(HOUR FROM %s)::int END AS hour, SUM(%s) AS amount, COUNT(*) AS count",
+			local, timestamped, local, reportingAmount), map[string]interface{}{"tz": location.String()}).
+		Where("date >= ? AND date < ?", from, to).
+		Group("1, 2").
+		Order("1, 2").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum spending by weekday and hour: %w", err)
+	}
+
+	// Step 3: ISO weekdays to time.Weekday (Sunday = 0)
+	cells := make([]*domain.PatternCell, len(rows))
+	for i, row := range rows {
+		cells[i] = &domain.PatternCell{
+			Weekday: time.Weekday(row.Weekday % 7),
+			Hour:    row.Hour,
+			Amount:  row.Amount,
+			Count:   row.Count,
+		}
+	}
+	return cells, nil
+}
@@ -0,0 +1,127 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.PlannedPurchaseRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records and duplicates
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For the period of bought purchases
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid"         // For UUID parsing and validation
+	"github.com/jackc/pgx/v5/pgconn" // For reading PostgreSQL error codes
+	"gorm.io/gorm"                   // GORM ORM library
+)
+
+// PlannedPurchaseRepository implements the domain.PlannedPurchaseRepository interface using PostgreSQL
+// Planned purchases are owned like expenses: each caller only sees their own
+type PlannedPurchaseRepository struct {
+	db *gorm.DB
+}
+
+// NewPlannedPurchaseRepository creates a new PostgreSQL planned purchase repository
+func NewPlannedPurchaseRepository(db *gorm.DB) *PlannedPurchaseRepository {
+	return &PlannedPurchaseRepository{db: db}
+}
+
+// Create saves a new planned purchase owned by the caller
+func (r *PlannedPurchaseRepository) Create(ctx context.Context, purchase *domain.PlannedPurchase) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	purchase.UserID = owner
+	if err := r.db.WithContext(ctx).Create(purchase).Error; err != nil {
+		return fmt.Errorf("failed to create planned purchase: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves one of the caller's planned purchases
+func (r *PlannedPurchaseRepository) GetByID(ctx context.Context, id string) (*domain.PlannedPurchase, error) {
+	purchaseID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrPlannedPurchaseNotFound
+	}
+
+	var purchase domain.PlannedPurchase
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", purchaseID).First(&purchase).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPlannedPurchaseNotFound
+		}
+		return nil, fmt.Errorf("failed to get planned purchase: %w", err)
+	}
+	return &purchase, nil
+}
+
+// List returns the caller's planned purchases by planned day, undated ones last
+func (r *PlannedPurchaseRepository) List(ctx context.Context, status string) ([]*domain.PlannedPurchase, error) {
+	query := ownedBy(ctx, r.db.WithContext(ctx), "user_id")
+	switch status {
+	case domain.PlannedPurchaseOpen:
+		query = query.Where("expense_id IS NULL")
+	case domain.PlannedPurchaseBought:
+		query = query.Where("expense_id IS NOT NULL")
+	}
+
+	var purchases []*domain.PlannedPurchase
+	if err := query.Order("planned_for ASC NULLS LAST, created_at ASC").Find(&purchases).Error; err != nil {
+		return nil, fmt.Errorf("failed to list planned purchases: %w", err)
+	}
+	return purchases, nil
+}
+
+// ListBought returns the caller's purchases bought in [from, to), oldest first
+func (r *PlannedPurchaseRepository) ListBought(ctx context.Context, from, to time.Time) ([]*domain.PlannedPurchase, error) {
+	var purchases []*domain.PlannedPurchase
+	err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").
+		Where("expense_id IS NOT NULL AND bought_on >= ? AND bought_on < ?", from, to).
+		Order("bought_on ASC, created_at ASC").
+		Find(&purchases).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bought purchases: %w", err)
+	}
+	return purchases, nil
+}
+
+// UpdateLink saves the link of one of the caller's planned purchases to its expense
+// The unique index on expense_id turns a second link of the same expense into ErrExpenseAlreadyPlanned
+func (r *PlannedPurchaseRepository) UpdateLink(ctx context.Context, purchase *domain.PlannedPurchase) error {
+	result := ownedBy(ctx, r.db.WithContext(ctx).Model(purchase), "user_id").
+		Updates(map[string]interface{}{
+			"expense_id":    purchase.ExpenseID,
+			"actual_amount": purchase.ActualAmount,
+			"bought_on":     purchase.BoughtOn,
+		})
+	if result.Error != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(result.Error, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.ErrExpenseAlreadyPlanned
+		}
+		return fmt.Errorf("failed to update planned purchase: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPlannedPurchaseNotFound
+	}
+	return nil
+}
+
+// Delete removes one of the caller's planned purchases
+// The linked expense stays
+func (r *PlannedPurchaseRepository) Delete(ctx context.Context, id string) error {
+	purchaseID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrPlannedPurchaseNotFound
+	}
+
+	result := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", purchaseID).Delete(&domain.PlannedPurchase{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete planned purchase: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPlannedPurchaseNotFound
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.PolicyRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For the period of the violation report
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// PolicyRepository implements the domain.PolicyRepository interface using PostgreSQL
+type PolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewPolicyRepository creates a new PostgreSQL policy repository
+func NewPolicyRepository(db *gorm.DB) *PolicyRepository {
+	return &PolicyRepository{db: db}
+}
+
+// ListRules returns a tenant's rules, oldest first
+func (r *PolicyRepository) ListRules(ctx context.Context, tenantID string) ([]*domain.PolicyRule, error) {
+	var rules []*domain.PolicyRule
+	if err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("created_at ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list policy rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetRule retrieves one of a tenant's rules
+func (r *PolicyRepository) GetRule(ctx context.Context, tenantID, id string) (*domain.PolicyRule, error) {
+	ruleID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrPolicyRuleNotFound
+	}
+
+	var rule domain.PolicyRule
+	if err := r.db.WithContext(ctx).Where("tenant_id = ? AND id = ?", tenantID, ruleID).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPolicyRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get policy rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// CreateRule saves a new rule
+func (r *PolicyRepository) CreateRule(ctx context.Context, rule *domain.PolicyRule) error {
+	if err := r.db.WithContext(ctx).Create(rule).Error; err != nil {
+		return fmt.Errorf("failed to create policy rule: %w", err)
+	}
+	return nil
+}
+
+// UpdateRule saves changes to a rule
+// Selecting the columns also writes false and empty values, e.g. when a rule is disabled
+func (r *PolicyRepository) UpdateRule(ctx context.Context, rule *domain.PolicyRule) error {
+	result := r.db.WithContext(ctx).Model(rule).Where("tenant_id = ?", rule.TenantID).
+		Select("name", "kind", "category", "amount", "severity", "message", "enabled").Updates(rule)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update policy rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPolicyRuleNotFound
+	}
+	return nil
+}
+
+// DeleteRule removes one of a tenant's rules
+func (r *PolicyRepository) DeleteRule(ctx context.Context, tenantID, id string) error {
+	ruleID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrPolicyRuleNotFound
+	}
+
+	result := r.db.WithContext(ctx).Where("tenant_id = ? AND id = ?", tenantID, ruleID).Delete(&domain.PolicyRule{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete policy rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPolicyRuleNotFound
+	}
+	return nil
+}
+
+// RecordViolations saves violations
+// It deliberately ignores any transaction in ctx: a blocked expense rolls its transaction back,
+// and its violations must be kept all the same
+func (r *PolicyRepository) RecordViolations(ctx context.Context, violations ...*domain.PolicyViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Create(violations).Error; err != nil {
+		return fmt.Errorf("failed to record policy violations: %w", err)
+	}
+	return nil
+}
+
+// violationGroupings maps each grouping of the violation report to its key and label columns
+// Categories are grouped case-insensitively, as they are matched by the rules
+var violationGroupings = map[string]struct{ key, label string }{
+	domain.PolicyViolationsByUser:     {key: "COALESCE(v.user_id::text, '')", label: "COALESCE(MAX(users.email), '')"},
+	domain.PolicyViolationsByCategory: {key: "LOWER(v.category)", label: "MIN(v.category)"},
+	domain.PolicyViolationsByRule:     {key: "v.rule_id::text", label: "(ARRAY_AGG(v.rule_name ORDER BY v.occurred_at DESC))[1]"},
+}
+
+// SumViolations totals a tenant's violations in a period by user, category or rule
+func (r *PolicyRepository) SumViolations(ctx context.Context, tenantID, groupBy string, from, to time.Time) ([]*domain.PolicyViolationTotal, error) {
+	grouping, ok := violationGroupings[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown violation grouping %q", groupBy)
+	}
+
+	query := r.db.WithContext(ctx).
+		Table("policy_violations v").
+		Select(grouping.key+" AS key, "+grouping.label+" AS label, COUNT(*) AS count, "+
+			"COUNT(*) FILTER (WHERE v.severity = ?) AS blocked, COALESCE(SUM(v.amount), 0) AS amount", domain.PolicyBlock).
+		Where("v.tenant_id = ? AND v.occurred_at >= ? AND v.occurred_at < ?", tenantID, from, to)
+	if groupBy == domain.PolicyViolationsByUser {
+		query = query.Joins("LEFT JOIN users ON users.id = v.user_id")
+	}
+
+	var totals []*domain.PolicyViolationTotal
+	if err := query.Group(grouping.key).Order("count DESC, key ASC").Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum policy violations: %w", err)
+	}
+	return totals, nil
+}
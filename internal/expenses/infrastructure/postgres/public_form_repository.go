@@ -0,0 +1,145 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.PublicFormRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// PublicFormRepository implements the domain.PublicFormRepository interface using PostgreSQL
+type PublicFormRepository struct {
+	db *gorm.DB
+}
+
+// NewPublicFormRepository creates a new PostgreSQL public form repository
+func NewPublicFormRepository(db *gorm.DB) *PublicFormRepository {
+	return &PublicFormRepository{db: db}
+}
+
+// CreateForm saves a new public form owned by the caller
+func (r *PublicFormRepository) CreateForm(ctx context.Context, form *domain.PublicForm) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	form.UserID = owner
+	if err := r.db.WithContext(ctx).Create(form).Error; err != nil {
+		return fmt.Errorf("failed to create public form: %w", err)
+	}
+	return nil
+}
+
+// GetForm retrieves one of the caller's forms by its ID
+func (r *PublicFormRepository) GetForm(ctx context.Context, id string) (*domain.PublicForm, error) {
+	formID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrPublicFormNotFound
+	}
+	return r.firstForm(ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", formID))
+}
+
+// GetFormByTokenHash retrieves the form a token belongs to
+// It isn't scoped to the caller: the token is what grants access to the form
+func (r *PublicFormRepository) GetFormByTokenHash(ctx context.Context, tokenHash string) (*domain.PublicForm, error) {
+	return r.firstForm(r.db.WithContext(ctx).Where("token_hash = ?", tokenHash))
+}
+
+// firstForm runs a form lookup and maps "no rows" to ErrPublicFormNotFound
+func (r *PublicFormRepository) firstForm(query *gorm.DB) (*domain.PublicForm, error) {
+	var form domain.PublicForm
+	if err := query.First(&form).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPublicFormNotFound
+		}
+		return nil, fmt.Errorf("failed to get public form: %w", err)
+	}
+	return &form, nil
+}
+
+// ListForms returns the caller's forms, newest first
+func (r *PublicFormRepository) ListForms(ctx context.Context) ([]*domain.PublicForm, error) {
+	var forms []*domain.PublicForm
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Order("created_at DESC").Find(&forms).Error; err != nil {
+		return nil, fmt.Errorf("failed to list public forms: %w", err)
+	}
+	return forms, nil
+}
+
+// UpdateForm saves changes to a form
+func (r *PublicFormRepository) UpdateForm(ctx context.Context, form *domain.PublicForm) error {
+	if err := r.db.WithContext(ctx).Save(form).Error; err != nil {
+		return fmt.Errorf("failed to update public form: %w", err)
+	}
+	return nil
+}
+
+// CreateStaged saves a new submission
+func (r *PublicFormRepository) CreateStaged(ctx context.Context, staged *domain.StagedExpense) error {
+	if err := r.db.WithContext(ctx).Create(staged).Error; err != nil {
+		return fmt.Errorf("failed to stage submission: %w", err)
+	}
+	return nil
+}
+
+// GetStaged retrieves a submission by its ID
+func (r *PublicFormRepository) GetStaged(ctx context.Context, id string) (*domain.StagedExpense, error) {
+	stagedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrSubmissionNotFound
+	}
+
+	var staged domain.StagedExpense
+	if err := stagedFor(ctx, r.db.WithContext(ctx)).Where("id = ?", stagedID).First(&staged).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSubmissionNotFound
+		}
+		return nil, fmt.Errorf("failed to get submission: %w", err)
+	}
+	return &staged, nil
+}
+
+// ListStaged returns the submissions with the given status (all when empty), oldest first
+func (r *PublicFormRepository) ListStaged(ctx context.Context, status string) ([]*domain.StagedExpense, error) {
+	query := stagedFor(ctx, r.db.WithContext(ctx)).Order("created_at ASC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var staged []*domain.StagedExpense
+	if err := query.Find(&staged).Error; err != nil {
+		return nil, fmt.Errorf("failed to list submissions: %w", err)
+	}
+	return staged, nil
+}
+
+// UpdateStaged saves the review outcome of a submission
+func (r *PublicFormRepository) UpdateStaged(ctx context.Context, staged *domain.StagedExpense) error {
+	if err := conn(ctx, r.db).Save(staged).Error; err != nil {
+		return fmt.Errorf("failed to update submission: %w", err)
+	}
+	return nil
+}
+
+// StagedExternalIDExists reports whether a card transaction with this feed ID was staged before
+// It looks across all queues: the feed is imported by an admin, not by the cardholder
+func (r *PublicFormRepository) StagedExternalIDExists(ctx context.Context, externalID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.StagedExpense{}).Where("external_id = ?", externalID).Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check for staged card transaction: %w", err)
+	}
+	return count > 0, nil
+}
+
+// stagedFor restricts query to what the caller in ctx may review: the submissions to their
+// forms and the card transactions of their own cards
+func stagedFor(ctx context.Context, query *gorm.DB) *gorm.DB {
+	return ownedBy(ctx, query, "user_id")
+}
@@ -0,0 +1,57 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ReadOnlyRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm"        // GORM ORM library
+	"gorm.io/gorm/clause" // For upserts
+)
+
+// ReadOnlyRepository implements the domain.ReadOnlyRepository interface using PostgreSQL
+// Its queries aren't scoped with ownedBy: the switches belong to tenants, not users
+type ReadOnlyRepository struct {
+	db *gorm.DB
+}
+
+// NewReadOnlyRepository creates a new PostgreSQL read-only repository
+func NewReadOnlyRepository(db *gorm.DB) *ReadOnlyRepository {
+	return &ReadOnlyRepository{db: db}
+}
+
+// List returns every switch that is on
+func (r *ReadOnlyRepository) List(ctx context.Context) ([]*domain.ReadOnlyMode, error) {
+	var modes []*domain.ReadOnlyMode
+	if err := conn(ctx, r.db).Order("tenant_id ASC").Find(&modes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list read-only modes: %w", err)
+	}
+	return modes, nil
+}
+
+// Save switches a tenant to read-only in one statement
+func (r *ReadOnlyRepository) Save(ctx context.Context, mode *domain.ReadOnlyMode) error {
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "message", "updated_at"}),
+	}).Create(mode).Error
+	if err != nil {
+		return fmt.Errorf("failed to save read-only mode: %w", err)
+	}
+	return nil
+}
+
+// Delete switches a tenant back to writable
+func (r *ReadOnlyRepository) Delete(ctx context.Context, tenantID string) error {
+	result := conn(ctx, r.db).Where("tenant_id = ?", tenantID).Delete(&domain.ReadOnlyMode{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete read-only mode: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrReadOnlyModeNotFound
+	}
+	return nil
+}
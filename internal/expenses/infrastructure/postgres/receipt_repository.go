@@ -0,0 +1,82 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.PendingReceiptRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// PendingReceiptRepository implements the domain.PendingReceiptRepository interface using PostgreSQL
+type PendingReceiptRepository struct {
+	db *gorm.DB
+}
+
+// NewPendingReceiptRepository creates a new PostgreSQL receipt inbox repository
+func NewPendingReceiptRepository(db *gorm.DB) *PendingReceiptRepository {
+	return &PendingReceiptRepository{db: db}
+}
+
+// Create saves a pending receipt in the caller's inbox for the book they are working in
+func (r *PendingReceiptRepository) Create(ctx context.Context, receipt *domain.PendingReceipt) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	book, ok := bookOf(ctx)
+	if !ok {
+		return domain.ErrBookNotFound
+	}
+	receipt.UserID = owner
+	receipt.BookID = book
+	return r.db.WithContext(ctx).Create(receipt).Error
+}
+
+// GetByID retrieves one of the caller's pending receipts by its ID
+func (r *PendingReceiptRepository) GetByID(ctx context.Context, id string) (*domain.PendingReceipt, error) {
+	receiptID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrPendingReceiptNotFound
+	}
+
+	var receipt domain.PendingReceipt
+	if err := ownedInBook(ctx, r.db.WithContext(ctx), "").Where("id = ?", receiptID).First(&receipt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPendingReceiptNotFound
+		}
+		return nil, fmt.Errorf("failed to get pending receipt: %w", err)
+	}
+	return &receipt, nil
+}
+
+// List returns the caller's pending receipts in their current book, oldest first
+func (r *PendingReceiptRepository) List(ctx context.Context) ([]*domain.PendingReceipt, error) {
+	var receipts []*domain.PendingReceipt
+	if err := ownedInBook(ctx, r.db.WithContext(ctx), "").Order("created_at ASC").Find(&receipts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list pending receipts: %w", err)
+	}
+	return receipts, nil
+}
+
+// Delete removes one of the caller's pending receipts
+func (r *PendingReceiptRepository) Delete(ctx context.Context, id string) error {
+	receiptID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrPendingReceiptNotFound
+	}
+
+	result := ownedInBook(ctx, r.db.WithContext(ctx), "").Where("id = ?", receiptID).Delete(&domain.PendingReceipt{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete pending receipt: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPendingReceiptNotFound
+	}
+	return nil
+}
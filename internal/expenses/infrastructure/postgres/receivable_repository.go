@@ -0,0 +1,114 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ReceivableRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For case-insensitive debtor matching
+	"time"    // For judging overdue receivables
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// ReceivableRepository implements the domain.ReceivableRepository interface using PostgreSQL
+// Receivables are owned like expenses: each caller only sees their own
+type ReceivableRepository struct {
+	db *gorm.DB
+}
+
+// NewReceivableRepository creates a new PostgreSQL receivable repository
+func NewReceivableRepository(db *gorm.DB) *ReceivableRepository {
+	return &ReceivableRepository{db: db}
+}
+
+// Create saves a new receivable owned by the caller
+func (r *ReceivableRepository) Create(ctx context.Context, receivable *domain.Receivable) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	receivable.UserID = owner
+	if err := r.db.WithContext(ctx).Create(receivable).Error; err != nil {
+		return fmt.Errorf("failed to create receivable: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves one of the caller's receivables
+func (r *ReceivableRepository) GetByID(ctx context.Context, id string) (*domain.Receivable, error) {
+	receivableID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrReceivableNotFound
+	}
+
+	var receivable domain.Receivable
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", receivableID).First(&receivable).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrReceivableNotFound
+		}
+		return nil, fmt.Errorf("failed to get receivable: %w", err)
+	}
+	return &receivable, nil
+}
+
+// List returns the caller's receivables matching filter, by due date with undated ones last
+func (r *ReceivableRepository) List(ctx context.Context, filter domain.ReceivableFilter) ([]*domain.Receivable, error) {
+	query := ownedBy(ctx, r.db.WithContext(ctx), "user_id")
+	// Overdue starts the day after the due date, matching Receivable.Status
+	today := filter.AsOf.UTC().Truncate(24 * time.Hour)
+	switch filter.Status {
+	case domain.ReceivableSettled:
+		query = query.Where("settled_at IS NOT NULL")
+	case domain.ReceivableOverdue:
+		query = query.Where("settled_at IS NULL AND due_date < ?", today)
+	case domain.ReceivableOpen:
+		query = query.Where("settled_at IS NULL AND (due_date IS NULL OR due_date >= ?)", today)
+	}
+	if debtor := strings.TrimSpace(filter.Debtor); debtor != "" {
+		query = query.Where("LOWER(debtor) = ?", strings.ToLower(debtor))
+	}
+
+	var receivables []*domain.Receivable
+	if err := query.Order("due_date ASC NULLS LAST, created_at ASC").Find(&receivables).Error; err != nil {
+		return nil, fmt.Errorf("failed to list receivables: %w", err)
+	}
+	return receivables, nil
+}
+
+// Update saves the settlement of one of the caller's receivables
+func (r *ReceivableRepository) Update(ctx context.Context, receivable *domain.Receivable) error {
+	result := ownedBy(ctx, r.db.WithContext(ctx).Model(receivable), "user_id").
+		Updates(map[string]interface{}{
+			"settled_amount": receivable.SettledAmount,
+			"settled_at":     receivable.SettledAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update receivable: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrReceivableNotFound
+	}
+	return nil
+}
+
+// Delete removes one of the caller's receivables
+func (r *ReceivableRepository) Delete(ctx context.Context, id string) error {
+	receivableID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrReceivableNotFound
+	}
+
+	result := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", receivableID).Delete(&domain.Receivable{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete receivable: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrReceivableNotFound
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ReconciliationRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// ReconciliationRepository implements the domain.ReconciliationRepository interface using PostgreSQL
+type ReconciliationRepository struct {
+	db *gorm.DB
+}
+
+// NewReconciliationRepository creates a new PostgreSQL reconciliation repository
+func NewReconciliationRepository(db *gorm.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+// Create saves a new session of the caller together with its statement lines in one transaction
+func (r *ReconciliationRepository) Create(ctx context.Context, session *domain.ReconciliationSession, lines []*domain.StatementLine) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	session.UserID = owner
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return fmt.Errorf("failed to create reconciliation session: %w", err)
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(lines, 500).Error; err != nil {
+			return fmt.Errorf("failed to save statement lines: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetByID retrieves a session by its ID
+func (r *ReconciliationRepository) GetByID(ctx context.Context, id string) (*domain.ReconciliationSession, error) {
+	sessionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrReconciliationNotFound
+	}
+
+	var session domain.ReconciliationSession
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrReconciliationNotFound
+		}
+		return nil, fmt.Errorf("failed to get reconciliation session: %w", err)
+	}
+	return &session, nil
+}
+
+// List returns all sessions, newest statement period first
+func (r *ReconciliationRepository) List(ctx context.Context) ([]*domain.ReconciliationSession, error) {
+	var sessions []*domain.ReconciliationSession
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Order("period_end DESC, created_at DESC").Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list reconciliation sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// Lines returns the statement lines of a session, oldest first
+func (r *ReconciliationRepository) Lines(ctx context.Context, sessionID uuid.UUID) ([]*domain.StatementLine, error) {
+	var lines []*domain.StatementLine
+	if err := r.db.WithContext(ctx).Where("session_id = ?", sessionID).Order("date ASC, id ASC").Find(&lines).Error; err != nil {
+		return nil, fmt.Errorf("failed to list statement lines: %w", err)
+	}
+	return lines, nil
+}
+
+// SaveLines stores the match state of the given lines in one transaction
+func (r *ReconciliationRepository) SaveLines(ctx context.Context, lines []*domain.StatementLine) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, line := range lines {
+			err := tx.Model(&domain.StatementLine{}).Where("id = ?", line.ID).
+				Updates(map[string]interface{}{"expense_id": line.ExpenseID, "match_confidence": line.MatchConfidence}).Error
+			if err != nil {
+				return fmt.Errorf("failed to save statement line: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Close marks the caller's session closed and the given expenses of theirs reconciled in one transaction
+// Expenses are stamped with the session's ClosedAt time
+func (r *ReconciliationRepository) Close(ctx context.Context, session *domain.ReconciliationSession, expenseIDs []uuid.UUID) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := ownedBy(ctx, tx.Model(&domain.ReconciliationSession{}), "user_id").
+			Where("id = ? AND status = ?", session.ID, domain.ReconciliationOpen).
+			Updates(map[string]interface{}{"status": session.Status, "closed_at": session.ClosedAt})
+		if result.Error != nil {
+			return fmt.Errorf("failed to close reconciliation session: %w", result.Error)
+		}
+		// Someone else closed the session in the meantime
+		if result.RowsAffected == 0 {
+			return domain.ErrReconciliationClosed
+		}
+		if len(expenseIDs) == 0 {
+			return nil
+		}
+		if err := ownedInBook(ctx, tx.Model(&domain.Expense{}), "").Where("id IN ?", expenseIDs).Update("reconciled_at", session.ClosedAt).Error; err != nil {
+			return fmt.Errorf("failed to mark expenses reconciled: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,62 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.RecurringExpenseRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// RecurringExpenseRepository implements the domain.RecurringExpenseRepository interface using PostgreSQL
+type RecurringExpenseRepository struct {
+	db *gorm.DB
+}
+
+// NewRecurringExpenseRepository creates a new PostgreSQL recurring expense repository
+func NewRecurringExpenseRepository(db *gorm.DB) *RecurringExpenseRepository {
+	return &RecurringExpenseRepository{db: db}
+}
+
+// Create saves a new recurring expense owned by the caller
+func (r *RecurringExpenseRepository) Create(ctx context.Context, recurring *domain.RecurringExpense) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	recurring.UserID = owner
+	if err := r.db.WithContext(ctx).Create(recurring).Error; err != nil {
+		return fmt.Errorf("failed to create recurring expense: %w", err)
+	}
+	return nil
+}
+
+// List returns the caller's recurring expenses ordered by start date
+func (r *RecurringExpenseRepository) List(ctx context.Context) ([]*domain.RecurringExpense, error) {
+	var recurring []*domain.RecurringExpense
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Order("start_date ASC").Find(&recurring).Error; err != nil {
+		return nil, fmt.Errorf("failed to list recurring expenses: %w", err)
+	}
+	return recurring, nil
+}
+
+// Delete removes one of the caller's recurring expenses by its unique identifier
+func (r *RecurringExpenseRepository) Delete(ctx context.Context, id string) error {
+	recurringID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrRecurringExpenseNotFound
+	}
+
+	result := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", recurringID).Delete(&domain.RecurringExpense{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete recurring expense: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrRecurringExpenseNotFound
+	}
+	return nil
+}
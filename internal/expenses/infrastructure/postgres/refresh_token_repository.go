@@ -0,0 +1,134 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.RefreshTokenRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For revocation and expiry times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID types
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// RefreshTokenRepository implements the domain.RefreshTokenRepository interface using PostgreSQL
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new PostgreSQL refresh token repository
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create saves a new refresh token
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
+
+// GetByHash retrieves a token by the hash of its value
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+// Revoke marks a token as revoked
+// The revoked_at IS NULL condition makes this a compare-and-swap: of two concurrent
+// refreshes with the same token only one gets true
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to revoke refresh token: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Use marks a token as used up by a refresh
+// Like Revoke it is a compare-and-swap, so of two concurrent refreshes only one gets true
+func (r *RefreshTokenRepository) Use(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{"revoked_at": at, "used": true})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to use refresh token: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// SessionActive reports whether the newest token of a session is unrevoked or was used for the next one
+// A used newest token is a refresh in flight, whose next token is about to be saved
+func (r *RefreshTokenRepository) SessionActive(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	var newest domain.RefreshToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("session_id = ? OR (session_id IS NULL AND id = ?)", sessionID, sessionID).
+		Order("created_at DESC").
+		First(&newest).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return newest.RevokedAt == nil || newest.Used, nil
+}
+
+// ListUsable returns the usable tokens of a user, newest first
+func (r *RefreshTokenRepository) ListUsable(ctx context.Context, userID uuid.UUID, now time.Time) ([]*domain.RefreshToken, error) {
+	var tokens []*domain.RefreshToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refresh tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeSession revokes the usable tokens of one session of a user
+// Tokens from before sessions were tracked have no session ID and are their own session
+func (r *RefreshTokenRepository) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Where("session_id = ? OR (session_id IS NULL AND id = ?)", sessionID, sessionID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke session: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// RevokeAllForUser revokes every usable token of a user
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteExpired deletes the tokens that expired before the given time
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&domain.RefreshToken{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired refresh tokens: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,186 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ReimbursementRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// ReimbursementRepository implements the domain.ReimbursementRepository interface using PostgreSQL
+// Batches are owned like employers: each caller only sees their own
+type ReimbursementRepository struct {
+	db *gorm.DB
+}
+
+// NewReimbursementRepository creates a new PostgreSQL reimbursement batch repository
+func NewReimbursementRepository(db *gorm.DB) *ReimbursementRepository {
+	return &ReimbursementRepository{db: db}
+}
+
+// ClaimableExpenses returns the caller's expenses with the given IDs in their current book
+func (r *ReimbursementRepository) ClaimableExpenses(ctx context.Context, ids []uuid.UUID) ([]*domain.Expense, error) {
+	var expenses []*domain.Expense
+	if err := ownedInBook(ctx, r.db.WithContext(ctx), "").Where("id IN ?", ids).Find(&expenses).Error; err != nil {
+		return nil, fmt.Errorf("failed to get expenses: %w", err)
+	}
+	if len(expenses) != len(ids) {
+		return nil, domain.ErrExpenseNotFound
+	}
+	return expenses, nil
+}
+
+// CreateBatch saves a batch owned by the caller and marks its expenses submitted
+// The expenses are only moved on if they are all still pending, so two claims of the same
+// expense can't both go through
+func (r *ReimbursementRepository) CreateBatch(ctx context.Context, batch *domain.ReimbursementBatch, expenseIDs []uuid.UUID) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	batch.UserID = owner
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Step 1: Save the batch
+		if err := tx.Create(batch).Error; err != nil {
+			return fmt.Errorf("failed to create reimbursement batch: %w", err)
+		}
+
+		// Step 2: Claim the expenses
+		result := ownedInBook(ctx, tx.Model(&domain.Expense{}), "").
+			Where("id IN ? AND reimbursable AND reimbursement_status = ?", expenseIDs, domain.ReimbursementPending).
+			Updates(map[string]interface{}{
+				"reimbursement_status":   domain.ReimbursementSubmitted,
+				"reimbursement_batch_id": batch.ID,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to submit expenses: %w", result.Error)
+		}
+		if result.RowsAffected != int64(len(expenseIDs)) {
+			return domain.ErrReimbursementNotPending
+		}
+		return nil
+	})
+}
+
+// GetBatch retrieves one of the caller's batches
+func (r *ReimbursementRepository) GetBatch(ctx context.Context, id string) (*domain.ReimbursementBatch, error) {
+	batchID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrReimbursementBatchNotFound
+	}
+
+	var batch domain.ReimbursementBatch
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", batchID).First(&batch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrReimbursementBatchNotFound
+		}
+		return nil, fmt.Errorf("failed to get reimbursement batch: %w", err)
+	}
+	return &batch, nil
+}
+
+// ListBatches returns the caller's batches, newest first
+func (r *ReimbursementRepository) ListBatches(ctx context.Context) ([]*domain.ReimbursementBatch, error) {
+	var batches []*domain.ReimbursementBatch
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Order("submitted_at DESC").Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("failed to list reimbursement batches: %w", err)
+	}
+	return batches, nil
+}
+
+// BatchExpenses returns the caller's expenses claimed in a batch, by date
+func (r *ReimbursementRepository) BatchExpenses(ctx context.Context, batchID uuid.UUID) ([]*domain.Expense, error) {
+	var expenses []*domain.Expense
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").
+		Where("reimbursement_batch_id = ?", batchID).
+		Order("date ASC").
+		Find(&expenses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list batch expenses: %w", err)
+	}
+	return expenses, nil
+}
+
+// MarkPaid saves the payout of a batch, records it on the employer's balance and marks the expenses paid
+func (r *ReimbursementRepository) MarkPaid(ctx context.Context, batch *domain.ReimbursementBatch, income *domain.ReimbursementIncome) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Step 1: The payout brings down what the employer owes
+		if income != nil {
+			income.UserID = batch.UserID
+			if err := tx.Create(income).Error; err != nil {
+				return fmt.Errorf("failed to save reimbursement: %w", err)
+			}
+			batch.IncomeID = &income.ID
+		}
+
+		// Step 2: The batch, unless it was paid in the meantime
+		result := ownedBy(ctx, tx.Model(batch), "user_id").
+			Where("status = ?", domain.ReimbursementSubmitted).
+			Select("status", "paid", "paid_on", "difference", "income_id").
+			Updates(batch)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update reimbursement batch: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrReimbursementBatchPaid
+		}
+
+		// Step 3: Its expenses
+		err := ownedBy(ctx, tx.Model(&domain.Expense{}), "user_id").
+			Where("reimbursement_batch_id = ?", batch.ID).
+			Update("reimbursement_status", domain.ReimbursementPaid).Error
+		if err != nil {
+			return fmt.Errorf("failed to mark expenses paid: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteBatch withdraws a submitted batch and makes its expenses pending again
+func (r *ReimbursementRepository) DeleteBatch(ctx context.Context, batch *domain.ReimbursementBatch) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Step 1: The batch, unless it was paid in the meantime
+		result := ownedBy(ctx, tx, "user_id").
+			Where("id = ? AND status = ?", batch.ID, domain.ReimbursementSubmitted).
+			Delete(&domain.ReimbursementBatch{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete reimbursement batch: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrReimbursementBatchPaid
+		}
+
+		// Step 2: Its expenses can be claimed again
+		err := ownedBy(ctx, tx.Model(&domain.Expense{}), "user_id").
+			Where("reimbursement_batch_id = ?", batch.ID).
+			Updates(map[string]interface{}{
+				"reimbursement_status":   domain.ReimbursementPending,
+				"reimbursement_batch_id": nil,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to withdraw expenses: %w", err)
+		}
+		return nil
+	})
+}
+
+// Totals returns the count and total of the caller's reimbursable expenses per status
+// in their current book
+func (r *ReimbursementRepository) Totals(ctx context.Context) ([]*domain.ReimbursementTotal, error) {
+	var totals []*domain.ReimbursementTotal
+	err := ownedInBook(ctx, r.db.WithContext(ctx).Model(&domain.Expense{}), "").
+		Select("reimbursement_status AS status, COUNT(*) AS count, SUM(" + reportingAmount + ") AS amount").
+		Where("reimbursable AND reimbursement_status <> ''").
+		Group("reimbursement_status").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum reimbursable expenses: %w", err)
+	}
+	return totals, nil
+}
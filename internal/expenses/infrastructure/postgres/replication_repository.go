@@ -0,0 +1,49 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ReplicationRepository interface
+package postgres
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"database/sql" // For the nullable lag
+	"fmt"          // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// ReplicationRepository implements the domain.ReplicationRepository interface using PostgreSQL
+type ReplicationRepository struct {
+	db *gorm.DB
+}
+
+// NewReplicationRepository creates a new PostgreSQL replication repository
+func NewReplicationRepository(db *gorm.DB) *ReplicationRepository {
+	return &ReplicationRepository{db: db}
+}
+
+// ReplicationStatus asks the server whether it is a standby and how far behind it is
+// A standby that replayed all the WAL it received is up to date, however long ago the
+// primary last wrote, so its lag is 0 rather than the time since the last transaction
+func (r *ReplicationRepository) ReplicationStatus(ctx context.Context) (*domain.ReplicationStatus, error) {
+	var row struct {
+		InRecovery bool
+		Lag        sql.NullFloat64
+	}
+	err := r.db.WithContext(ctx).Raw(`SELECT pg_is_in_recovery() AS in_recovery,
+		CASE
+			WHEN NOT pg_is_in_recovery() THEN NULL
+			WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())::float8
+		END AS lag`).Scan(&row).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replication status: %w", err)
+	}
+
+	status := &domain.ReplicationStatus{InRecovery: row.InRecovery}
+	if row.Lag.Valid {
+		lag := row.Lag.Float64
+		status.LagSeconds = &lag
+	}
+	return status, nil
+}
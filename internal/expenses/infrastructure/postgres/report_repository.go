@@ -0,0 +1,105 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.SpendingRepository interface used by budgets and reports
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For ordering merchants summed in memory
+	"time"    // For date range arguments
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// reportingAmount is the SQL for Expense.ReportingAmount
+// Rows created before conversions were stored have base_amount 0 and fall back to amount
+const reportingAmount = "COALESCE(NULLIF(base_amount, 0), amount)"
+
+// merchantName is the SQL for the merchant an expense is attributed to
+// Imported expenses carry a merchant; manual ones fall back to their normalized description
+const merchantName = "COALESCE(NULLIF(merchant, ''), NULLIF(normalized_description, ''), description)"
+
+// SpendingByCategory sums the caller's expenses dated in [from, to) per category
+// It uses the locked base-currency amount so foreign currency expenses add up correctly
+// Inside a transaction it also sees the expenses written earlier in that transaction
+func (r *Repository) SpendingByCategory(ctx context.Context, from, to time.Time) ([]*domain.CategorySpending, error) {
+	var spending []*domain.CategorySpending
+	err := spendingByCategoryQuery(r.expenses(ownedInBook(ctx, conn(ctx, r.db), "")), from, to).Scan(&spending).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum spending by category: %w", err)
+	}
+	return spending, nil
+}
+
+// SpendingByMerchant sums the caller's expenses dated in [from, to) per merchant
+func (r *Repository) SpendingByMerchant(ctx context.Context, from, to time.Time) ([]*domain.MerchantSpending, error) {
+	if fieldsEncrypted() {
+		return r.spendingByEncryptedMerchant(ctx, from, to)
+	}
+	var spending []*domain.MerchantSpending
+	err := spendingByMerchantQuery(r.expenses(ownedInBook(ctx, r.db.WithContext(ctx), "")), from, to).Scan(&spending).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum spending by merchant: %w", err)
+	}
+	return spending, nil
+}
+
+// spendingByEncryptedMerchant is SpendingByMerchant for encrypted merchants and descriptions
+// Every ciphertext is different, so SQL can't group them; the rows are decrypted and summed here
+func (r *Repository) spendingByEncryptedMerchant(ctx context.Context, from, to time.Time) ([]*domain.MerchantSpending, error) {
+	// Step 1: Load just the columns the merchant name and amount come from
+	var expenses []*domain.Expense
+	err := r.expenses(ownedInBook(ctx, r.db.WithContext(ctx), "")).
+		Select("merchant", "normalized_description", "description", "amount", "base_amount").
+		Where("date >= ? AND date < ?", from, to).
+		Find(&expenses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum spending by merchant: %w", err)
+	}
+
+	// Step 2: Group them like merchantName does in SQL
+	byMerchant := map[string]*domain.MerchantSpending{}
+	var spending []*domain.MerchantSpending
+	for _, expense := range expenses {
+		name := expense.Merchant
+		if name == "" {
+			name = expense.NormalizedDescription
+		}
+		if name == "" {
+			name = expense.Description
+		}
+		total := byMerchant[name]
+		if total == nil {
+			total = &domain.MerchantSpending{Merchant: name}
+			byMerchant[name] = total
+			spending = append(spending, total)
+		}
+		total.Amount = total.Amount.Add(expense.ReportingAmount())
+		total.Count++
+	}
+
+	// Step 3: Largest first, like the SQL version
+	sort.SliceStable(spending, func(i, j int) bool { return spending[i].Amount.Minor > spending[j].Amount.Minor })
+	return spending, nil
+}
+
+// spendingByCategoryQuery builds the query behind SpendingByCategory
+func spendingByCategoryQuery(db *gorm.DB, from, to time.Time) *gorm.DB {
+	return db.Model(&domain.Expense{}).
+		Select("category, SUM("+reportingAmount+") AS amount").
+		Where("date >= ? AND date < ?", from, to).
+		Group("category").
+		Order("category ASC")
+}
+
+// spendingByMerchantQuery builds the query behind SpendingByMerchant
+func spendingByMerchantQuery(db *gorm.DB, from, to time.Time) *gorm.DB {
+	return db.Model(&domain.Expense{}).
+		Select(merchantName+" AS merchant, SUM("+reportingAmount+") AS amount, COUNT(*) AS count").
+		Where("date >= ? AND date < ?", from, to).
+		// Group by the expression: "merchant" alone would mean the raw column, not the alias
+		Group(merchantName).
+		Order("amount DESC")
+}
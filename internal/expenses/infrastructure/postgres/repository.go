@@ -4,11 +4,14 @@
 package postgres
 
 import (
-	"context" // For request context (cancellation, timeouts)
-	"fmt"     // For formatted string operations and error wrapping
+	"context"       // For request context (cancellation, timeouts)
+	"encoding/json" // For encoding tag filters
+	"fmt"           // For formatted string operations and error wrapping
+	"time"          // For reading reports as of a past time
 
 	// For string manipulation (though not used in this implementation)
 	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/language"        // For detecting the language of descriptions
 
 	"github.com/google/uuid" // For UUID parsing and validation
 	"gorm.io/gorm"           // GORM is an ORM (Object-Relational Mapping) library for Go
@@ -21,24 +24,64 @@ type Repository struct {
 	// db is the GORM database connection
 	// GORM provides a convenient way to interact with databases using Go structs
 	db *gorm.DB
+
+	// languages is the tenant language assumed for descriptions too short to detect
+	languages *language.Preferences
+
+	// asOf is set on the copies FiguresAsOf returns: their reports read the expenses as they were then
+	asOf time.Time
+}
+
+// RepositoryOption configures optional repository behavior
+type RepositoryOption func(*Repository)
+
+// WithLanguages sets the languages assumed when a description's language can't be detected
+// Without it such descriptions are indexed without stemming
+func WithLanguages(languages *language.Preferences) RepositoryOption {
+	return func(r *Repository) {
+		r.languages = languages
+	}
 }
 
 // NewRepository creates a new PostgreSQL repository
 // This is a constructor function that takes a GORM database connection
 // It returns a configured repository instance
-func NewRepository(db *gorm.DB) *Repository {
-	return &Repository{
+func NewRepository(db *gorm.DB, opts ...RepositoryOption) *Repository {
+	r := &Repository{
 		db: db, // Store the database connection
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// detectLanguage sets the expense's search language from its description
+func (r *Repository) detectLanguage(ctx context.Context, expense *domain.Expense) {
+	expense.Language = language.SearchConfig(r.languages.DetectOr(ctx, expense.Description))
 }
 
 // Create adds a new expense to the database
 // This method implements the domain.Repository.Create interface
 func (r *Repository) Create(ctx context.Context, expense *domain.Expense) error {
+	// The expense belongs to whoever creates it, in the book they are working in
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	book, ok := bookOf(ctx)
+	if !ok {
+		return domain.ErrBookNotFound
+	}
+	expense.UserID = owner
+	expense.BookID = book
+	r.detectLanguage(ctx, expense)
+
 	// Use GORM's Create method to insert the expense into the database
-	// WithContext(ctx) propagates the context for cancellation/timeout handling
+	// conn(ctx, ...) propagates the context for cancellation/timeout handling
+	// and joins the surrounding transaction, if the use case started one
 	// Create() automatically handles the SQL INSERT statement
-	return r.db.WithContext(ctx).Create(expense).Error
+	return conn(ctx, r.db).Create(expense).Error
 }
 
 // GetByID retrieves an expense by its ID
@@ -59,9 +102,10 @@ func (r *Repository) GetByID(ctx context.Context, id string) (*domain.Expense, e
 	// Step 3: Execute the database query
 	// WithContext(ctx) - propagates context for cancellation/timeout
 	// Where("id = ?", uuid) - adds a WHERE clause to filter by ID
+	// ownedInBook(...) - hides other users' expenses and those of other books, which then simply aren't found
 	// First(&expense) - gets the first matching record and stores it in expense
 	// .Error - gets any error that occurred during the query
-	if err := r.db.WithContext(ctx).Where("id = ?", uuid).First(&expense).Error; err != nil {
+	if err := ownedInBook(ctx, r.db.WithContext(ctx), "").Where("id = ?", uuid).First(&expense).Error; err != nil {
 		// Step 4: Handle specific error cases
 		if err == gorm.ErrRecordNotFound {
 			// If no record was found, return our domain-specific error
@@ -83,11 +127,51 @@ func (r *Repository) GetAll(ctx context.Context, filters map[string]interface{})
 	// []*domain.Expense is a slice of pointers to Expense structs
 	var expenses []*domain.Expense
 
-	// Step 2: Start building the query
+	// Step 2: Build the filtered, paginated and ordered query over the caller's expenses
 	// WithContext(ctx) propagates context for cancellation/timeout
-	query := r.db.WithContext(ctx)
+	query := listQuery(ownedInBook(ctx, r.db.WithContext(ctx), ""), filters)
+
+	// Step 3: Execute the query and populate the expenses slice
+	if err := query.Find(&expenses).Error; err != nil {
+		// If the query fails, wrap the error with context
+		return nil, fmt.Errorf("failed to get expenses: %w", err)
+	}
+
+	// Step 4: Return the results
+	return expenses, nil
+}
 
-	// Step 3: Apply filters to the query
+// listQuery builds the query GetAll runs: filters, then pagination, newest first
+// The "order" key switches to creation order ("created_asc" or "created_desc")
+// ExplainExpenses builds the same query so the plan it shows is the one production runs
+func listQuery(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
+	// Apply filters to the query
+	query = applyExpenseFilters(query, filters)
+
+	// Apply pagination if the caller asked for a page
+	if limit, ok := filters["limit"].(int); ok && limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset, ok := filters["offset"].(int); ok && offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	// Order by date descending (newest expenses first) unless the caller asked for creation order
+	// Creation order is what polling integrations page through with cursors
+	switch filters["order"] {
+	case "created_asc":
+		return query.Order("created_at ASC, id ASC")
+	case "created_desc":
+		return query.Order("created_at DESC, id DESC")
+	default:
+		return query.Order("date DESC")
+	}
+}
+
+// applyExpenseFilters adds the WHERE clauses for the supported filter keys to query
+// It is shared by GetAll, Count, Stream and the query explainer so they all see exactly the same rows
+// Unknown keys (including the "limit", "offset" and "order" keys) are ignored
+func applyExpenseFilters(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
 	// This loop iterates through each filter and adds WHERE clauses
 	for key, value := range filters {
 		switch key {
@@ -98,6 +182,54 @@ func (r *Repository) GetAll(ctx context.Context, filters map[string]interface{})
 				// %category% means "contains the category text anywhere"
 				query = query.Where("category ILIKE ?", "%"+category+"%")
 			}
+		case "reimbursable":
+			// Filter reimbursable (true) or personal (false) expenses
+			if reimbursable, ok := value.(bool); ok {
+				query = query.Where("reimbursable = ?", reimbursable)
+			}
+		case "business", "tax_deductible":
+			// Filter business or tax-deductible expenses (true) or the others (false)
+			if marked, ok := value.(bool); ok {
+				query = query.Where(key+" = ?", marked)
+			}
+		case "reimbursement_status":
+			// Filter reimbursable expenses by where they are in their lifecycle
+			if status, ok := value.(string); ok && status != "" {
+				query = query.Where("reimbursement_status = ?", status)
+			}
+		case "employer_id":
+			// Filter the expenses charged to one employer
+			if employerID, ok := value.(uuid.UUID); ok {
+				query = query.Where("employer_id = ?", employerID)
+			}
+		case "parent_expense_id":
+			// Filter the refunds of one expense
+			if parentID, ok := value.(uuid.UUID); ok {
+				query = query.Where("parent_expense_id = ?", parentID)
+			}
+		case "tag":
+			// Filter the expenses carrying a tag; @> (contains) can use the GIN index on tags
+			if tag, ok := value.(string); ok && tag != "" {
+				encoded, _ := json.Marshal([]string{tag})
+				query = query.Where("tags @> ?::jsonb", string(encoded))
+			}
+		case "tags":
+			// Filter the expenses carrying all of the tags (saved views filter by several)
+			if tags, ok := value.([]string); ok && len(tags) > 0 {
+				encoded, _ := json.Marshal(tags)
+				query = query.Where("tags @> ?::jsonb", string(encoded))
+			}
+		case "metadata":
+			// Filter by custom field values; the service has already typed them by the field schema
+			if metadata, ok := value.(domain.Metadata); ok && len(metadata) > 0 {
+				encoded, _ := json.Marshal(metadata)
+				query = query.Where("metadata @> ?::jsonb", string(encoded))
+			}
+		case "categories":
+			// Filter by an exact list of categories (a category and its subcategories)
+			if categories, ok := value.([]string); ok && len(categories) > 0 {
+				query = query.Where("category IN ?", categories)
+			}
 		case "date_from":
 			// Filter expenses from a specific date onwards
 			if dateFrom, ok := value.(string); ok && dateFrom != "" {
@@ -110,43 +242,150 @@ func (r *Repository) GetAll(ctx context.Context, filters map[string]interface{})
 			}
 		case "min_amount":
 			// Filter expenses with amount greater than or equal to min_amount
-			if minAmount, ok := value.(float64); ok && minAmount > 0 {
+			if minAmount, ok := value.(domain.Money); ok && minAmount.Minor > 0 {
 				query = query.Where("amount >= ?", minAmount)
 			}
 		case "max_amount":
 			// Filter expenses with amount less than or equal to max_amount
-			if maxAmount, ok := value.(float64); ok && maxAmount > 0 {
+			if maxAmount, ok := value.(domain.Money); ok && maxAmount.Minor > 0 {
 				query = query.Where("amount <= ?", maxAmount)
 			}
 		case "description":
 			// Filter by description with partial matching (case-insensitive)
+			// Encrypted descriptions can't be matched in SQL, so the query fails instead of finding nothing
 			if description, ok := value.(string); ok && description != "" {
+				if fieldsEncrypted() {
+					query.AddError(domain.ErrEncryptedFilter)
+					continue
+				}
 				query = query.Where("description ILIKE ?", "%"+description+"%")
 			}
+		case "flag":
+			// Only expenses carrying the review flag
+			if flag, ok := value.(domain.Flag); ok && flag != "" {
+				query = query.Where("EXISTS (SELECT 1 FROM expense_flags f WHERE f.expense_id = expenses.id AND f.flag = ?)", flag)
+			}
+		case "external_id":
+			// Exact match on the bank's transaction ID (used to skip duplicate imports)
+			if externalID, ok := value.(string); ok && externalID != "" {
+				query = query.Where("external_id = ?", externalID)
+			}
+		case "normalized_description":
+			// Exact match on the normalized description (used for duplicate detection)
+			if normalized, ok := value.(string); ok && normalized != "" {
+				query = query.Where("normalized_description = ?", normalized)
+			}
+		case "account_id":
+			// Expenses paid from a specific account (e.g. the cash wallet)
+			if accountID, ok := value.(string); ok && accountID != "" {
+				query = query.Where("account_id = ?", accountID)
+			}
+		case "created_after":
+			// Expenses recorded after the cursor position (used by polling integrations)
+			if cursor, ok := value.(domain.ExpenseCursor); ok {
+				query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
+			}
+		case "trip_id":
+			// Expenses that belong to a business trip or project
+			if tripID, ok := value.(string); ok && tripID != "" {
+				query = query.Where("trip_id = ?", tripID)
+			}
+		case "loan_id":
+			// Expenses that are payments of a loan
+			if loanID, ok := value.(string); ok && loanID != "" {
+				query = query.Where("loan_id = ?", loanID)
+			}
+		case "reconciled":
+			// Only expenses that were (true) or weren't (false) confirmed against a statement
+			if reconciled, ok := value.(bool); ok {
+				if reconciled {
+					query = query.Where("reconciled_at IS NOT NULL")
+				} else {
+					query = query.Where("reconciled_at IS NULL")
+				}
+			}
+		case "cost_center":
+			// Expenses charged to a cost center (exact code)
+			if code, ok := value.(string); ok && code != "" {
+				query = query.Where("cost_center = ?", code)
+			}
+		case "department":
+			// Expenses charged to a department (exact code)
+			if code, ok := value.(string); ok && code != "" {
+				query = query.Where("department = ?", code)
+			}
+		case "mcc":
+			// Exact match on the merchant category code
+			if mcc, ok := value.(string); ok && mcc != "" {
+				query = query.Where("mcc = ?", mcc)
+			}
 		}
 	}
+	return query
+}
 
-	// Step 4: Add ordering to the query
-	// Order by date descending (newest expenses first)
-	query = query.Order("date DESC")
+// Count returns how many expenses match the filters
+// This method implements the domain.Repository.Count interface
+func (r *Repository) Count(ctx context.Context, filters map[string]interface{}) (int64, error) {
+	var count int64
+	query := applyExpenseFilters(ownedInBook(ctx, r.db.WithContext(ctx).Model(&domain.Expense{}), ""), filters)
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count expenses: %w", err)
+	}
+	return count, nil
+}
 
-	// Step 5: Execute the query and populate the expenses slice
-	if err := query.Find(&expenses).Error; err != nil {
-		// If the query fails, wrap the error with context
-		return nil, fmt.Errorf("failed to get expenses: %w", err)
+// Stream calls fn for every expense matching the filters, newest first
+// Rows are read from a database cursor one at a time, so memory use doesn't grow with the result
+// This method implements the domain.Repository.Stream interface
+func (r *Repository) Stream(ctx context.Context, filters map[string]interface{}, fn func(*domain.Expense) error) error {
+	query := applyExpenseFilters(ownedInBook(ctx, r.db.WithContext(ctx).Model(&domain.Expense{}), ""), filters)
+	rows, err := query.Order("date DESC").Rows()
+	if err != nil {
+		return fmt.Errorf("failed to stream expenses: %w", err)
 	}
+	defer rows.Close()
 
-	// Step 6: Return the results
-	return expenses, nil
+	for rows.Next() {
+		var expense domain.Expense
+		if err := r.db.ScanRows(rows, &expense); err != nil {
+			return fmt.Errorf("failed to read expense: %w", err)
+		}
+		if err := fn(&expense); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
 
 // Update modifies an existing expense
 // This method implements the domain.Repository.Update interface
 func (r *Repository) Update(ctx context.Context, expense *domain.Expense) error {
-	// Use GORM's Save method to update the expense in the database
-	// Save() automatically handles the SQL UPDATE statement
-	// It updates all fields of the expense
-	return r.db.WithContext(ctx).Save(expense).Error
+	// The expense can't be handed to someone else or moved to another book by updating it
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	book, ok := bookOf(ctx)
+	if !ok {
+		return domain.ErrBookNotFound
+	}
+	expense.UserID = owner
+	expense.BookID = book
+	r.detectLanguage(ctx, expense)
+
+	// Update every column of the expense, but only if it belongs to the caller
+	// Select("*") includes zero values (e.g. a cleared merchant), like a full save would
+	// Unlike Save, an UPDATE that matches nothing doesn't fall back to inserting the row
+	// conn(ctx, ...) joins the surrounding transaction, if the use case started one
+	result := ownedInBook(ctx, conn(ctx, r.db).Model(expense), "").Select("*").Updates(expense)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update expense: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrExpenseNotFound
+	}
+	return nil
 }
 
 // Delete removes an expense by its ID
@@ -160,9 +399,11 @@ func (r *Repository) Delete(ctx context.Context, id string) error {
 
 	// Step 2: Execute the delete operation
 	// Where("id = ?", uuid) - filters to delete only the specific expense
+	// ownedInBook(...) - only if it belongs to the caller and the current book
+	// conn(ctx, ...) - joins the surrounding transaction, if the use case started one
 	// Delete(&domain.Expense{}) - deletes records matching the WHERE clause
 	// The empty struct is just a placeholder to tell GORM which table to delete from
-	result := r.db.WithContext(ctx).Where("id = ?", uuid).Delete(&domain.Expense{})
+	result := ownedInBook(ctx, conn(ctx, r.db), "").Where("id = ?", uuid).Delete(&domain.Expense{})
 
 	// Step 3: Check for database errors
 	if result.Error != nil {
@@ -176,7 +417,18 @@ func (r *Repository) Delete(ctx context.Context, id string) error {
 		return domain.ErrExpenseNotFound
 	}
 
-	// Step 5: Return nil to indicate success
+	// Step 5: Clear the expense's review flags, donation details and comments, which mean nothing without it
+	if err := conn(ctx, r.db).Where("expense_id = ?", uuid).Delete(&domain.ExpenseFlag{}).Error; err != nil {
+		return fmt.Errorf("failed to delete expense flags: %w", err)
+	}
+	if err := conn(ctx, r.db).Where("expense_id = ?", uuid).Delete(&domain.Donation{}).Error; err != nil {
+		return fmt.Errorf("failed to delete donation: %w", err)
+	}
+	if err := conn(ctx, r.db).Where("expense_id = ?", uuid).Delete(&domain.ExpenseComment{}).Error; err != nil {
+		return fmt.Errorf("failed to delete expense comments: %w", err)
+	}
+
+	// Step 6: Return nil to indicate success
 	return nil
 }
 
@@ -194,7 +446,7 @@ func (r *Repository) Exists(ctx context.Context, id string) (bool, error) {
 	// Where("id = ?", uuid) - filters by the specific ID
 	// Count(&count) - counts matching records and stores result in count
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&domain.Expense{}).Where("id = ?", uuid).Count(&count).Error; err != nil {
+	if err := ownedInBook(ctx, r.db.WithContext(ctx).Model(&domain.Expense{}), "").Where("id = ?", uuid).Count(&count).Error; err != nil {
 		return false, fmt.Errorf("failed to check expense existence: %w", err)
 	}
 
@@ -208,5 +460,82 @@ func (r *Repository) Exists(ctx context.Context, id string) (bool, error) {
 func (r *Repository) AutoMigrate() error {
 	// GORM's AutoMigrate automatically creates tables based on struct definitions
 	// It also adds missing columns and indexes
-	return r.db.AutoMigrate(&domain.Expense{})
+	if err := r.db.AutoMigrate(
+		&domain.User{},
+		&domain.RefreshToken{},
+		&domain.APIKey{},
+		&domain.ServiceAccount{},
+		&domain.AuditEntry{},
+		&domain.Expense{},
+		&domain.Attachment{},
+		&domain.AttachmentBlob{},
+		&domain.MCCMapping{},
+		&domain.CategoryRule{},
+		&domain.NormalizationRule{},
+		&domain.Account{},
+		&domain.Transfer{},
+		&domain.Budget{},
+		&domain.Category{},
+		&domain.ExpenseFlag{},
+		&domain.Donation{},
+		&domain.PendingReceipt{},
+		&domain.ReconciliationSession{},
+		&domain.StatementLine{},
+		&domain.Trip{},
+		&domain.TripLeg{},
+		&domain.TripApproval{},
+		&domain.Group{},
+		&domain.GroupMember{},
+		&domain.GroupBudget{},
+		&domain.PublicForm{},
+		&domain.StagedExpense{},
+		&domain.CorporateCard{},
+		&domain.PolicyRule{},
+		&domain.PolicyViolation{},
+		&domain.Dimension{},
+		&domain.RecurringExpense{},
+		&domain.ExportJob{},
+		&domain.Branding{},
+		&domain.ApprovalChain{},
+		&domain.ApprovalStep{},
+		&domain.ApprovalDelegation{},
+		&domain.Receivable{},
+		&domain.PlannedPurchase{},
+		&domain.Loan{},
+		&domain.Employer{},
+		&domain.ReimbursementIncome{},
+		&domain.Book{},
+		&domain.TelemetryInstance{},
+		&domain.ReadOnlyMode{},
+		&domain.Subscription{},
+		&domain.BillingEvent{},
+		&domain.OCRSettings{},
+		&domain.OCRUsage{},
+		&domain.View{},
+		&domain.CustomField{},
+		&domain.ExpenseComment{},
+		&domain.CategoryCorrection{},
+		&domain.ReimbursementBatch{},
+		&domain.ExpenseEvent{},
+		&domain.ExpenseSnapshot{},
+		&domain.RequestLog{},
+		&domain.RequestLogTarget{},
+	); err != nil {
+		return err
+	}
+
+	// Install the default MCC -> category table on first run
+	if err := seedDefaultMCCMappings(r.db); err != nil {
+		return err
+	}
+
+	// Create the full-text search indexes that AutoMigrate can't express
+	for _, statement := range searchIndexes {
+		if err := r.db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to create search index: %w", err)
+		}
+	}
+
+	// Apply the versioned migrations (composite and trigram indexes)
+	return runMigrations(r.db)
 }
@@ -0,0 +1,114 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.RequestLogRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For detecting missing records
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For escaping path prefixes
+	"time"    // For expiry
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For log and user IDs
+	"gorm.io/gorm"           // GORM ORM library
+	"gorm.io/gorm/clause"    // For upserts
+)
+
+// RequestLogRepository implements the domain.RequestLogRepository interface using PostgreSQL
+// Its queries aren't scoped with ownedBy: request logs are read by admins only
+type RequestLogRepository struct {
+	db *gorm.DB
+}
+
+// NewRequestLogRepository creates a new PostgreSQL request log repository
+func NewRequestLogRepository(db *gorm.DB) *RequestLogRepository {
+	return &RequestLogRepository{db: db}
+}
+
+// Create saves a request log
+func (r *RequestLogRepository) Create(ctx context.Context, log *domain.RequestLog) error {
+	if err := r.db.WithContext(ctx).Create(log).Error; err != nil {
+		return fmt.Errorf("failed to save request log: %w", err)
+	}
+	return nil
+}
+
+// List returns the logs matching filter, newest first
+func (r *RequestLogRepository) List(ctx context.Context, filter domain.RequestLogFilter) ([]*domain.RequestLog, error) {
+	query := r.db.WithContext(ctx)
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Path != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(filter.Path)
+		query = query.Where("path LIKE ?", escaped+"%")
+	}
+	if filter.Status != 0 {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var logs []*domain.RequestLog
+	if err := query.Order("created_at DESC").Limit(filter.Limit).Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list request logs: %w", err)
+	}
+	return logs, nil
+}
+
+// GetByID returns a log
+func (r *RequestLogRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.RequestLog, error) {
+	var log domain.RequestLog
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Take(&log).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrRequestLogNotFound
+		}
+		return nil, fmt.Errorf("failed to get request log: %w", err)
+	}
+	return &log, nil
+}
+
+// Purge deletes the logs that expired before the given time, and the flags that ran out
+func (r *RequestLogRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&domain.RequestLog{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge request logs: %w", result.Error)
+	}
+	if err := r.db.WithContext(ctx).Where("until < ?", before).Delete(&domain.RequestLogTarget{}).Error; err != nil {
+		return result.RowsAffected, fmt.Errorf("failed to purge request logging flags: %w", err)
+	}
+	return result.RowsAffected, nil
+}
+
+// ListTargets returns the users flagged for logging until after the given time
+func (r *RequestLogRepository) ListTargets(ctx context.Context, after time.Time) ([]*domain.RequestLogTarget, error) {
+	var targets []*domain.RequestLogTarget
+	if err := r.db.WithContext(ctx).Where("until > ?", after).Order("until ASC").Find(&targets).Error; err != nil {
+		return nil, fmt.Errorf("failed to list request logging flags: %w", err)
+	}
+	return targets, nil
+}
+
+// SaveTarget flags a user for logging in one statement
+func (r *RequestLogRepository) SaveTarget(ctx context.Context, target *domain.RequestLogTarget) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"until", "note"}),
+	}).Create(target).Error
+	if err != nil {
+		return fmt.Errorf("failed to save request logging flag: %w", err)
+	}
+	return nil
+}
+
+// DeleteTarget stops logging a user's requests
+func (r *RequestLogRepository) DeleteTarget(ctx context.Context, userID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&domain.RequestLogTarget{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete request logging flag: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrRequestLogTargetNotFound
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.RerateRepository interface used by the admin re-rating
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For date ranges
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// RerateRepository implements the domain.RerateRepository interface using PostgreSQL
+// Like the integrity repository it sees every user's expenses
+type RerateRepository struct {
+	db *gorm.DB
+}
+
+// NewRerateRepository creates a new PostgreSQL re-rating repository
+func NewRerateRepository(db *gorm.DB) *RerateRepository {
+	return &RerateRepository{db: db}
+}
+
+// ProviderConverted returns the provider-converted expenses in a date range
+func (r *RerateRepository) ProviderConverted(ctx context.Context, from, before time.Time, currency string) ([]*domain.Expense, error) {
+	query := conn(ctx, r.db).
+		Where("conversion_source = ?", domain.ConversionProvider).
+		Where("date >= ? AND date < ?", from, before)
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+
+	var expenses []*domain.Expense
+	if err := query.Order("date ASC, id").Find(&expenses).Error; err != nil {
+		return nil, fmt.Errorf("failed to find provider-converted expenses: %w", err)
+	}
+	return expenses, nil
+}
+
+// ApplyRerate stores the new conversions
+// Each update only matches while the expense still has the rate it was read with, so an edit
+// made meanwhile (which locks its own rate) wins over the correction
+func (r *RerateRepository) ApplyRerate(ctx context.Context, changes []*domain.RerateChange) ([]*domain.RerateChange, error) {
+	var applied []*domain.RerateChange
+	for _, change := range changes {
+		result := conn(ctx, r.db).Model(&domain.Expense{}).
+			Where("id = ? AND exchange_rate = ? AND conversion_source = ?", change.ExpenseID, change.OldRate, domain.ConversionProvider).
+			Updates(map[string]interface{}{
+				"exchange_rate": change.NewRate,
+				"base_amount":   change.NewBaseAmount,
+			})
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to re-rate expense %s: %w", change.ExpenseID, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			applied = append(applied, change)
+		}
+	}
+	return applied, nil
+}
@@ -0,0 +1,81 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements full-text search over expenses and OCR-extracted receipt text
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+	"myexpenses/internal/language"        // For the text search configurations
+)
+
+// receiptConfig is the PostgreSQL text search configuration used for receipt text
+// 'simple' doesn't stem or drop stop words, which works for any language and for
+// the abbreviated item names printed on receipts (e.g. "ORG MLK 1L")
+const receiptConfig = language.Simple
+
+// expenseDocument is the indexed search document of an expense
+// The search_vector column is kept up to date by a trigger (migration 5) that indexes the
+// description and category both stemmed in the expense's language and unstemmed,
+// so a query matches either way
+const expenseDocument = "expenses.search_vector"
+
+// receiptDocument is the SQL expression indexed for searching receipt text
+const receiptDocument = "to_tsvector('" + receiptConfig + "', coalesce(ocr_text, ''))"
+
+// Search returns the caller's expenses matching the query in the requested scope
+// This method implements the domain.SearchRepository.Search interface
+func (r *Repository) Search(ctx context.Context, query string, scope domain.SearchScope, searchConfig string) ([]*domain.Expense, error) {
+	// Step 1: Build the tsqueries once and reuse them in every branch
+	// plainto_tsquery turns free text into an AND of all words, ignoring punctuation
+	// Expenses are matched stemmed in searchConfig or unstemmed, like they are indexed;
+	// the configuration is passed as a parameter, so it never ends up in the SQL text
+	expenseQuery := "(plainto_tsquery(?::regconfig, ?) || plainto_tsquery('" + language.Simple + "', ?))"
+	expenseArgs := []interface{}{searchConfig, query, query}
+	receiptQuery := "plainto_tsquery('" + receiptConfig + "', ?)"
+	receiptArgs := []interface{}{query}
+
+	// Step 2: Build a sub-select per scope that yields matching expense IDs with a rank
+	expenseMatches := "SELECT id AS expense_id, ts_rank(" + expenseDocument + ", " + expenseQuery + ") AS rank " +
+		"FROM expenses WHERE " + expenseDocument + " @@ " + expenseQuery
+	receiptMatches := "SELECT expense_id, ts_rank(" + receiptDocument + ", " + receiptQuery + ") AS rank " +
+		"FROM attachments WHERE " + receiptDocument + " @@ " + receiptQuery
+	var matches string
+	var args []interface{}
+	switch scope {
+	case domain.SearchScopeExpenses:
+		matches = expenseMatches
+		args = append(append(args, expenseArgs...), expenseArgs...)
+	case domain.SearchScopeReceipts:
+		matches = receiptMatches
+		args = append(append(args, receiptArgs...), receiptArgs...)
+	case domain.SearchScopeAll:
+		matches = expenseMatches + " UNION ALL " + receiptMatches
+		args = append(append(args, expenseArgs...), expenseArgs...)
+		args = append(append(args, receiptArgs...), receiptArgs...)
+	default:
+		return nil, domain.ErrInvalidSearchScope
+	}
+
+	// Step 3: Join the matches back to the caller's expenses
+	// An expense can match several times (description + multiple receipts), so we keep its best rank
+	var expenses []*domain.Expense
+	err := ownedInBook(ctx, r.db.WithContext(ctx), "expenses").
+		Table("expenses").
+		Select("expenses.*").
+		Joins("JOIN (SELECT expense_id, MAX(rank) AS rank FROM ("+matches+") m GROUP BY expense_id) hits ON hits.expense_id = expenses.id", args...).
+		Order("hits.rank DESC, expenses.date DESC").
+		Find(&expenses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search expenses: %w", err)
+	}
+	return expenses, nil
+}
+
+// searchIndexes are the GIN indexes backing full-text search of receipts
+// AutoMigrate can't express expression indexes, so they are created with raw SQL
+// The expense search index is created by migration 5 together with its column
+var searchIndexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_attachments_ocr_search ON attachments USING GIN (" + receiptDocument + ")",
+}
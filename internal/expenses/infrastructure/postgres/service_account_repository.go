@@ -0,0 +1,108 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ServiceAccountRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// ServiceAccountRepository implements the domain.ServiceAccountRepository interface using PostgreSQL
+type ServiceAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewServiceAccountRepository creates a new PostgreSQL service account repository
+func NewServiceAccountRepository(db *gorm.DB) *ServiceAccountRepository {
+	return &ServiceAccountRepository{db: db}
+}
+
+// Create saves a new service account
+func (r *ServiceAccountRepository) Create(ctx context.Context, account *domain.ServiceAccount) error {
+	if err := r.db.WithContext(ctx).Create(account).Error; err != nil {
+		return fmt.Errorf("failed to create service account: %w", err)
+	}
+	return nil
+}
+
+// List returns all service accounts, newest first
+func (r *ServiceAccountRepository) List(ctx context.Context) ([]*domain.ServiceAccount, error) {
+	var accounts []*domain.ServiceAccount
+	if err := r.db.WithContext(ctx).Order("created_at DESC").Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// GetByID retrieves a service account
+func (r *ServiceAccountRepository) GetByID(ctx context.Context, id string) (*domain.ServiceAccount, error) {
+	accountID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrServiceAccountNotFound
+	}
+	return r.first(r.db.WithContext(ctx).Where("id = ?", accountID))
+}
+
+// GetByClientID retrieves the account with the given client ID
+func (r *ServiceAccountRepository) GetByClientID(ctx context.Context, clientID string) (*domain.ServiceAccount, error) {
+	return r.first(r.db.WithContext(ctx).Where("client_id = ?", clientID))
+}
+
+// first runs an account lookup and maps "no rows" to ErrServiceAccountNotFound
+func (r *ServiceAccountRepository) first(query *gorm.DB) (*domain.ServiceAccount, error) {
+	var account domain.ServiceAccount
+	if err := query.First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrServiceAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+	return &account, nil
+}
+
+// UpdateSecret saves a new secret hash for an account
+func (r *ServiceAccountRepository) UpdateSecret(ctx context.Context, account *domain.ServiceAccount) error {
+	result := r.db.WithContext(ctx).Model(account).Update("secret_hash", account.SecretHash)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update service account secret: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrServiceAccountNotFound
+	}
+	return nil
+}
+
+// Delete removes a service account
+// What the account created stays, still attributed to it
+func (r *ServiceAccountRepository) Delete(ctx context.Context, id string) error {
+	accountID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrServiceAccountNotFound
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ?", accountID).Delete(&domain.ServiceAccount{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete service account: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrServiceAccountNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records that an account got a token
+// UpdateColumn leaves updated_at alone: using an account doesn't change it
+func (r *ServiceAccountRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := r.db.WithContext(ctx).Model(&domain.ServiceAccount{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+	if err != nil {
+		return fmt.Errorf("failed to record service account use: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.StatsRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// StatsRepository implements the domain.StatsRepository interface using PostgreSQL
+// Its queries deliberately aren't scoped with ownedBy: they count every user's data
+type StatsRepository struct {
+	db *gorm.DB
+}
+
+// NewStatsRepository creates a new PostgreSQL stats repository
+func NewStatsRepository(db *gorm.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// GlobalStats counts users, expenses and attachments across all users
+func (r *StatsRepository) GlobalStats(ctx context.Context) (*domain.GlobalStats, error) {
+	db := r.db.WithContext(ctx)
+	stats := &domain.GlobalStats{UsersByRole: make(map[string]int64)}
+
+	// Step 1: Users per role
+	var roles []struct {
+		Role  string
+		Count int64
+	}
+	if err := db.Model(&domain.User{}).Select("role, COUNT(*) AS count").Group("role").Scan(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+	for _, row := range roles {
+		stats.UsersByRole[row.Role] = row.Count
+		stats.Users += row.Count
+	}
+
+	// Step 2: Expenses and their total
+	var expenses struct {
+		Count int64
+		Total domain.Money
+	}
+	if err := db.Model(&domain.Expense{}).Select("COUNT(*) AS count, COALESCE(SUM(" + reportingAmount + "), 0) AS total").Scan(&expenses).Error; err != nil {
+		return nil, fmt.Errorf("failed to count expenses: %w", err)
+	}
+	stats.Expenses = expenses.Count
+	stats.ExpenseTotal = expenses.Total
+
+	// Step 3: Attachments and the storage they use
+	var attachments struct {
+		Count int64
+		Bytes int64
+	}
+	if err := db.Model(&domain.Attachment{}).Select("COUNT(*) AS count, COALESCE(SUM(size), 0) AS bytes").Scan(&attachments).Error; err != nil {
+		return nil, fmt.Errorf("failed to count attachments: %w", err)
+	}
+	stats.Attachments = attachments.Count
+	stats.AttachmentBytes = attachments.Bytes
+
+	// Step 4: What is stored: each shared blob once, plus the attachments from before deduplication
+	var blobBytes, legacyBytes int64
+	if err := db.Model(&domain.AttachmentBlob{}).Select("COALESCE(SUM(size), 0)").Scan(&blobBytes).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum attachment blobs: %w", err)
+	}
+	if err := db.Model(&domain.Attachment{}).Where("content_hash IS NULL OR content_hash = ''").Select("COALESCE(SUM(size), 0)").Scan(&legacyBytes).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum attachments: %w", err)
+	}
+	stats.StoredAttachmentBytes = blobBytes + legacyBytes
+	return stats, nil
+}
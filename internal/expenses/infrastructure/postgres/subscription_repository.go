@@ -0,0 +1,70 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.SubscriptionRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm"        // GORM ORM library
+	"gorm.io/gorm/clause" // For upserts and remembering webhooks once
+)
+
+// SubscriptionRepository implements the domain.SubscriptionRepository interface using PostgreSQL
+// Subscriptions belong to tenants, not users, so its queries aren't scoped with ownedBy
+type SubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewSubscriptionRepository creates a new PostgreSQL subscription repository
+func NewSubscriptionRepository(db *gorm.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db}
+}
+
+// Get retrieves a tenant's subscription
+func (r *SubscriptionRepository) Get(ctx context.Context, tenantID string) (*domain.Subscription, error) {
+	return r.first(ctx, "tenant_id = ?", tenantID)
+}
+
+// GetByProviderID retrieves a subscription by the payment provider's ID
+func (r *SubscriptionRepository) GetByProviderID(ctx context.Context, providerID string) (*domain.Subscription, error) {
+	return r.first(ctx, "provider_id = ?", providerID)
+}
+
+// first retrieves the subscription matching a condition
+func (r *SubscriptionRepository) first(ctx context.Context, query string, arg string) (*domain.Subscription, error) {
+	var subscription domain.Subscription
+	if err := conn(ctx, r.db).Where(query, arg).First(&subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+// Save creates or replaces a tenant's subscription in one statement
+func (r *SubscriptionRepository) Save(ctx context.Context, subscription *domain.Subscription) error {
+	err := conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"plan_id", "status", "seats", "customer_id", "provider_id", "trial_ends_at", "current_period_end", "updated_at",
+		}),
+	}).Create(subscription).Error
+	if err != nil {
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+	return nil
+}
+
+// RecordEvent remembers a webhook; a second delivery of the same event inserts nothing
+func (r *SubscriptionRepository) RecordEvent(ctx context.Context, event *domain.BillingEvent) (bool, error) {
+	result := conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record billing event: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
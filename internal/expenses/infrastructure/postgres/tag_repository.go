@@ -0,0 +1,33 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.TagRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For escaping LIKE patterns
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// SuggestTags returns the caller's most used tags starting with prefix
+// This method implements the domain.TagRepository interface
+func (r *Repository) SuggestTags(ctx context.Context, prefix string, limit int) ([]*domain.TagSuggestion, error) {
+	// Escape LIKE wildcards so a "%" typed by the user is matched literally
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix)
+
+	var suggestions []*domain.TagSuggestion
+	err := ownedInBook(ctx, r.db.WithContext(ctx).Table("expenses"), "expenses").
+		// Every tag of every expense becomes a row; expenses without tags (NULL or JSON null) have none
+		Joins("CROSS JOIN LATERAL jsonb_array_elements_text(CASE WHEN jsonb_typeof(expenses.tags) = 'array' THEN expenses.tags ELSE '[]'::jsonb END) AS t(tag)").
+		Select("t.tag AS tag, COUNT(*) AS count").
+		Where("t.tag LIKE ?", escaped+"%").
+		Group("t.tag").
+		Order("count DESC, tag ASC").
+		Limit(limit).
+		Scan(&suggestions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest tags: %w", err)
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,56 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.TaxRepository interface used by the tax report
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+	"time"    // For date range arguments
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// reportingTax is the SQL for the tax share of the reporting amount
+// The tax is entered in the expense's currency, so it is converted at the same ratio as the gross amount
+const reportingTax = "(" + reportingAmount + " * tax_amount / amount)"
+
+// VATTotals sums the caller's expenses with VAT information dated in [from, to) per VAT rate
+// Like SpendingByCategory it uses the locked base-currency amounts
+func (r *Repository) VATTotals(ctx context.Context, from, to time.Time) ([]*domain.VATTotal, error) {
+	var totals []*domain.VATTotal
+	err := r.expenses(ownedInBook(ctx, conn(ctx, r.db), "")).
+		Model(&domain.Expense{}).
+		Select("vat_rate AS rate, SUM("+reportingAmount+") AS gross, "+
+			"SUM("+reportingAmount+" - "+reportingTax+") AS net, SUM("+reportingTax+") AS tax, COUNT(*) AS count").
+		Where("date >= ? AND date < ?", from, to).
+		Where("vat_rate IS NOT NULL OR tax_amount <> 0").
+		Group("vat_rate").
+		Order("vat_rate DESC NULLS LAST").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum VAT: %w", err)
+	}
+	return totals, nil
+}
+
+// MarkTotals sums the caller's business, reimbursable and tax-deductible expenses dated in
+// [from, to) per category, in the base currency
+func (r *Repository) MarkTotals(ctx context.Context, from, to time.Time) ([]*domain.MarkTotal, error) {
+	var totals []*domain.MarkTotal
+	err := r.expenses(ownedInBook(ctx, conn(ctx, r.db), "")).
+		Model(&domain.Expense{}).
+		Select("category, "+
+			"COALESCE(SUM("+reportingAmount+") FILTER (WHERE business), 0) AS business, "+
+			"COALESCE(SUM("+reportingAmount+") FILTER (WHERE reimbursable), 0) AS reimbursable, "+
+			"COALESCE(SUM("+reportingAmount+") FILTER (WHERE tax_deductible), 0) AS tax_deductible, "+
+			"SUM("+reportingAmount+") AS total").
+		Where("date >= ? AND date < ?", from, to).
+		Group("category").
+		Having("bool_or(business OR reimbursable OR tax_deductible)").
+		Order("category").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum marked expenses: %w", err)
+	}
+	return totals, nil
+}
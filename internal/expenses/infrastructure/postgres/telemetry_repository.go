@@ -0,0 +1,67 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.TelemetryRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For generating the instance ID
+	"gorm.io/gorm"           // GORM ORM library
+	"gorm.io/gorm/clause"    // For inserting the instance ID only once
+)
+
+// TelemetryRepository implements the domain.TelemetryRepository interface using PostgreSQL
+// Its queries deliberately aren't scoped with ownedBy: they count every user's data
+type TelemetryRepository struct {
+	db *gorm.DB
+}
+
+// NewTelemetryRepository creates a new PostgreSQL telemetry repository
+func NewTelemetryRepository(db *gorm.DB) *TelemetryRepository {
+	return &TelemetryRepository{db: db}
+}
+
+// InstanceID returns the ID of this installation, creating it on first use
+// Instances starting together may both try to create it; the oldest row wins for everyone
+func (r *TelemetryRepository) InstanceID(ctx context.Context) (uuid.UUID, error) {
+	db := r.db.WithContext(ctx)
+	var instances []domain.TelemetryInstance
+	if err := db.Order("created_at ASC, id ASC").Limit(1).Find(&instances).Error; err != nil {
+		return uuid.Nil, fmt.Errorf("failed to read instance ID: %w", err)
+	}
+	if len(instances) > 0 {
+		return instances[0].ID, nil
+	}
+
+	instance := &domain.TelemetryInstance{ID: uuid.New()}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(instance).Error; err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create instance ID: %w", err)
+	}
+	if err := db.Order("created_at ASC, id ASC").Limit(1).Find(&instances).Error; err != nil {
+		return uuid.Nil, fmt.Errorf("failed to read instance ID: %w", err)
+	}
+	return instances[0].ID, nil
+}
+
+// Counts returns the exact number of users, books, expenses and attachments
+func (r *TelemetryRepository) Counts(ctx context.Context) (*domain.TelemetryCounts, error) {
+	db := r.db.WithContext(ctx)
+	counts := &domain.TelemetryCounts{}
+	for _, count := range []struct {
+		model any
+		into  *int64
+	}{
+		{&domain.User{}, &counts.Users},
+		{&domain.Book{}, &counts.Books},
+		{&domain.Expense{}, &counts.Expenses},
+		{&domain.Attachment{}, &counts.Attachments},
+	} {
+		if err := db.Model(count.model).Count(count.into).Error; err != nil {
+			return nil, fmt.Errorf("failed to count: %w", err)
+		}
+	}
+	return counts, nil
+}
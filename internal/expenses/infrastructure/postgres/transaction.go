@@ -0,0 +1,44 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.Transactor interface
+package postgres
+
+import (
+	"context" // For carrying the transaction between repositories
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"gorm.io/gorm" // GORM ORM library
+)
+
+// txKey is the context key under which the current transaction is stored
+type txKey struct{}
+
+// Transactor implements the domain.Transactor interface using PostgreSQL transactions
+type Transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a new PostgreSQL transactor
+func NewTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// WithinTransaction runs fn in a transaction that repositories pick up from ctx
+// Nested calls reuse the outer transaction
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(domain.MarkTransaction(context.WithValue(ctx, txKey{}, tx)))
+	})
+}
+
+// conn returns the transaction stored in ctx, or db when there is none
+// Repository methods that may run inside a use-case transaction start their queries from it
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
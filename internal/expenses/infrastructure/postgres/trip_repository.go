@@ -0,0 +1,129 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.TripRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For comparing GORM errors
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// TripRepository implements the domain.TripRepository interface using PostgreSQL
+type TripRepository struct {
+	db *gorm.DB
+}
+
+// NewTripRepository creates a new PostgreSQL trip repository
+func NewTripRepository(db *gorm.DB) *TripRepository {
+	return &TripRepository{db: db}
+}
+
+// Create saves a new trip owned by the caller; GORM inserts the legs in the same transaction
+func (r *TripRepository) Create(ctx context.Context, trip *domain.Trip) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	trip.UserID = owner
+	if err := r.db.WithContext(ctx).Create(trip).Error; err != nil {
+		return fmt.Errorf("failed to create trip: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a trip with its legs
+func (r *TripRepository) GetByID(ctx context.Context, id string) (*domain.Trip, error) {
+	tripID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrTripNotFound
+	}
+
+	var trip domain.Trip
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Preload("Legs", legOrder).Where("id = ?", tripID).First(&trip).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrTripNotFound
+		}
+		return nil, fmt.Errorf("failed to get trip: %w", err)
+	}
+	return &trip, nil
+}
+
+// List returns all trips with their legs, most recent first
+func (r *TripRepository) List(ctx context.Context) ([]*domain.Trip, error) {
+	var trips []*domain.Trip
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Preload("Legs", legOrder).Order("start_date DESC").Find(&trips).Error; err != nil {
+		return nil, fmt.Errorf("failed to list trips: %w", err)
+	}
+	return trips, nil
+}
+
+// AssignExpenses links the caller's expenses to the trip
+// Runs in a transaction so a missing expense (or someone else's) leaves every expense untouched
+func (r *TripRepository) AssignExpenses(ctx context.Context, tripID uuid.UUID, expenseIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Expenses of an approved trip stay where they are
+		approved := tx.Model(&domain.Trip{}).Select("id").Where("approved_at IS NOT NULL")
+		var frozen int64
+		if err := ownedInBook(ctx, tx.Model(&domain.Expense{}), "").Where("id IN ? AND trip_id IN (?)", expenseIDs, approved).Count(&frozen).Error; err != nil {
+			return fmt.Errorf("failed to check expenses of approved trips: %w", err)
+		}
+		if frozen > 0 {
+			return domain.ErrTripApproved
+		}
+
+		result := ownedInBook(ctx, tx.Model(&domain.Expense{}), "").Where("id IN ?", expenseIDs).Update("trip_id", tripID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to assign expenses to trip: %w", result.Error)
+		}
+		if result.RowsAffected != int64(len(expenseIDs)) {
+			return domain.ErrExpenseNotFound
+		}
+		return nil
+	})
+}
+
+// RemoveExpense unlinks an expense from the trip
+func (r *TripRepository) RemoveExpense(ctx context.Context, tripID uuid.UUID, expenseID uuid.UUID) error {
+	result := ownedInBook(ctx, r.db.WithContext(ctx).Model(&domain.Expense{}), "").
+		Where("id = ? AND trip_id = ?", expenseID, tripID).
+		Update("trip_id", nil)
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove expense from trip: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrExpenseNotFound
+	}
+	return nil
+}
+
+// Approve saves the approval and archive fields of the trip
+// The approved_at IS NULL condition makes approval a compare-and-set: of two concurrent approvals only one wins
+func (r *TripRepository) Approve(ctx context.Context, trip *domain.Trip) error {
+	result := r.db.WithContext(ctx).Model(&domain.Trip{}).
+		Where("id = ? AND approved_at IS NULL", trip.ID).
+		Updates(map[string]interface{}{
+			"approved_at":       trip.ApprovedAt,
+			"approved_by":       trip.ApprovedBy,
+			"archive_key":       trip.ArchiveKey,
+			"archive_size":      trip.ArchiveSize,
+			"archive_checksum":  trip.ArchiveChecksum,
+			"archive_signature": trip.ArchiveSignature,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to approve trip: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrTripApproved
+	}
+	return nil
+}
+
+// legOrder preloads a trip's legs in date order
+func legOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("start_date ASC")
+}
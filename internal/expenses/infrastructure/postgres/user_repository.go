@@ -0,0 +1,161 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.UserRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing unique violations
+	"fmt"     // For formatted string operations and error wrapping
+	"strings" // For escaping email searches
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid"         // For UUID parsing and validation
+	"github.com/jackc/pgx/v5/pgconn" // PostgreSQL error codes
+	"gorm.io/gorm"                   // GORM ORM library
+)
+
+// uniqueViolation is the PostgreSQL error code of a violated unique constraint
+const uniqueViolation = "23505"
+
+// UserRepository implements the domain.UserRepository interface using PostgreSQL
+type UserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository creates a new PostgreSQL user repository
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Create saves a new user
+// Two registrations of the same email can race past the service's check; the unique index decides
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.ErrEmailTaken
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a user by its unique identifier
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	var user domain.User
+	if err := r.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// GetByEmail retrieves a user by email address
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.WithContext(ctx).Where("email = ?", domain.NormalizeEmail(email)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// List returns one page of the users matching filter, ordered by email, and how many match in total
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.User{})
+	if email := domain.NormalizeEmail(filter.Email); email != "" {
+		// Emails are stored lowercase, so a plain LIKE ignores case; escape the wildcards of the search
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(email) + "%"
+		query = query.Where("email LIKE ?", pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = domain.DefaultUserPageSize
+	}
+	var users []*domain.User
+	if err := query.Order("email ASC").Limit(limit).Offset(filter.Offset).Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, total, nil
+}
+
+// UpdateRole saves the user's role
+func (r *UserRepository) UpdateRole(ctx context.Context, user *domain.User) error {
+	result := r.db.WithContext(ctx).Model(user).Update("role", user.Role)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateDisabled saves whether the user is disabled
+func (r *UserRepository) UpdateDisabled(ctx context.Context, user *domain.User) error {
+	result := r.db.WithContext(ctx).Model(user).Update("disabled_at", user.DisabledAt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateErasure saves when the user's account is erased
+func (r *UserRepository) UpdateErasure(ctx context.Context, user *domain.User) error {
+	result := r.db.WithContext(ctx).Model(user).Update("erases_at", user.ErasesAt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdatePassword saves the user's password hash
+func (r *UserRepository) UpdatePassword(ctx context.Context, user *domain.User) error {
+	result := r.db.WithContext(ctx).Model(user).Update("password_hash", user.PasswordHash)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update password: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// Delete removes a user
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ?", userID).Delete(&domain.User{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
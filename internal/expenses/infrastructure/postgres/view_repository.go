@@ -0,0 +1,95 @@
+// Package postgres contains the PostgreSQL implementation of the repository interface
+// This file implements the domain.ViewRepository interface
+package postgres
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For recognizing missing records
+	"fmt"     // For formatted string operations and error wrapping
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+
+	"github.com/google/uuid" // For UUID parsing and validation
+	"gorm.io/gorm"           // GORM ORM library
+)
+
+// ViewRepository implements the domain.ViewRepository interface using PostgreSQL
+// Views are owned like expenses: each caller only sees their own
+type ViewRepository struct {
+	db *gorm.DB
+}
+
+// NewViewRepository creates a new PostgreSQL view repository
+func NewViewRepository(db *gorm.DB) *ViewRepository {
+	return &ViewRepository{db: db}
+}
+
+// Create saves a new view owned by the caller
+func (r *ViewRepository) Create(ctx context.Context, view *domain.View) error {
+	owner, ok := ownerOf(ctx)
+	if !ok {
+		return domain.ErrForbidden
+	}
+	view.UserID = owner
+	if err := r.db.WithContext(ctx).Create(view).Error; err != nil {
+		return fmt.Errorf("failed to create view: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves one of the caller's views
+func (r *ViewRepository) GetByID(ctx context.Context, id string) (*domain.View, error) {
+	viewID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, domain.ErrViewNotFound
+	}
+
+	var view domain.View
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", viewID).First(&view).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrViewNotFound
+		}
+		return nil, fmt.Errorf("failed to get view: %w", err)
+	}
+	return &view, nil
+}
+
+// List returns the caller's views by name
+func (r *ViewRepository) List(ctx context.Context) ([]*domain.View, error) {
+	var views []*domain.View
+	if err := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Order("LOWER(name) ASC").Find(&views).Error; err != nil {
+		return nil, fmt.Errorf("failed to list views: %w", err)
+	}
+	return views, nil
+}
+
+// Update saves the name, query and filters of one of the caller's views
+func (r *ViewRepository) Update(ctx context.Context, view *domain.View) error {
+	result := ownedBy(ctx, r.db.WithContext(ctx).Model(view), "user_id").
+		Select("name", "query", "filters", "updated_at").
+		Updates(view)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update view: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrViewNotFound
+	}
+	return nil
+}
+
+// Delete removes one of the caller's views
+func (r *ViewRepository) Delete(ctx context.Context, id string) error {
+	viewID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.ErrViewNotFound
+	}
+
+	result := ownedBy(ctx, r.db.WithContext(ctx), "user_id").Where("id = ?", viewID).Delete(&domain.View{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete view: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrViewNotFound
+	}
+	return nil
+}
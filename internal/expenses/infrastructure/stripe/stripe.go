@@ -0,0 +1,301 @@
+// Package stripe takes subscription payments with Stripe
+// This is part of the infrastructure layer - it implements domain.BillingProvider over Stripe's REST API
+package stripe
+
+import (
+	"context"       // For request context (cancellation, timeouts)
+	"crypto/hmac"   // For checking webhook signatures
+	"crypto/sha256" // The hash webhook signatures use
+	"encoding/hex"  // For decoding webhook signatures
+	"encoding/json" // For decoding Stripe's answers and events
+	"fmt"           // For formatted string operations and error wrapping
+	"io"            // For reading error answers
+	"net/http"      // For calling the API
+	"net/url"       // For form-encoding requests
+	"strconv"       // For seats and timestamps
+	"strings"       // For building requests and parsing signature headers
+	"time"          // For timeouts and webhook tolerance
+
+	"myexpenses/internal/clock"           // Time source for webhook timestamps
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// DefaultBaseURL is Stripe's API
+const DefaultBaseURL = "https://api.stripe.com"
+
+// SignatureHeader is the request header Stripe signs webhooks in
+const SignatureHeader = "Stripe-Signature"
+
+// WebhookTolerance is how old a webhook's signature may be, so recorded webhooks can't be replayed later
+const WebhookTolerance = 5 * time.Minute
+
+// Client talks to Stripe with a secret API key
+type Client struct {
+	secretKey     string
+	webhookSecret string
+	baseURL       string
+	client        *http.Client
+	clock         clock.Clock
+}
+
+// NewClient creates a Stripe client
+// secretKey is the API key ("sk_..."); webhookSecret the signing secret of the webhook endpoint ("whsec_...")
+func NewClient(secretKey, webhookSecret string, clk clock.Clock) *Client {
+	return &Client{
+		secretKey:     secretKey,
+		webhookSecret: webhookSecret,
+		baseURL:       DefaultBaseURL,
+		client:        &http.Client{Timeout: 30 * time.Second},
+		clock:         clock.Or(clk),
+	}
+}
+
+// subscription is the part of Stripe's subscription object the API needs
+type subscription struct {
+	ID               string            `json:"id"`
+	Customer         string            `json:"customer"`
+	Status           string            `json:"status"`
+	TrialEnd         int64             `json:"trial_end"`
+	CurrentPeriodEnd int64             `json:"current_period_end"`
+	Metadata         map[string]string `json:"metadata"`
+
+	// LatestInvoice is the invoice's ID, or the invoice itself when the request expanded it
+	LatestInvoice json.RawMessage `json:"latest_invoice"`
+
+	Items struct {
+		Data []struct {
+			ID       string `json:"id"`
+			Quantity int    `json:"quantity"`
+			Price    struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// Subscribe implements domain.BillingProvider
+// The subscription starts incomplete until the customer pays its first invoice on Stripe's
+// invoice page (during a trial the invoice is for nothing, but saves the card for later);
+// Stripe reports the outcome through webhooks
+func (c *Client) Subscribe(ctx context.Context, req *domain.NewSubscription) (*domain.SubscriptionState, error) {
+	// Step 1: The customer, tagged with the tenant so webhooks can be traced back
+	var customer struct {
+		ID string `json:"id"`
+	}
+	form := url.Values{}
+	form.Set("email", req.Email)
+	form.Set("metadata[tenant_id]", req.TenantID)
+	if err := c.call(ctx, http.MethodPost, "/v1/customers", form, &customer); err != nil {
+		return nil, err
+	}
+
+	// Step 2: The subscription to one price, a quantity of one per seat
+	form = url.Values{}
+	form.Set("customer", customer.ID)
+	form.Set("items[0][price]", req.PriceID)
+	form.Set("items[0][quantity]", strconv.Itoa(req.Seats))
+	form.Set("metadata[tenant_id]", req.TenantID)
+	form.Set("payment_behavior", "default_incomplete")
+	form.Set("payment_settings[save_default_payment_method]", "on_subscription")
+	form.Set("expand[]", "latest_invoice")
+	if req.TrialDays > 0 {
+		form.Set("trial_period_days", strconv.Itoa(req.TrialDays))
+	}
+	var created subscription
+	if err := c.call(ctx, http.MethodPost, "/v1/subscriptions", form, &created); err != nil {
+		return nil, err
+	}
+	return created.state(), nil
+}
+
+// Update implements domain.BillingProvider
+func (c *Client) Update(ctx context.Context, providerID, priceID string, seats int) (*domain.SubscriptionState, error) {
+	// Step 1: The subscription item to change
+	var current subscription
+	if err := c.call(ctx, http.MethodGet, "/v1/subscriptions/"+url.PathEscape(providerID), nil, &current); err != nil {
+		return nil, err
+	}
+	if len(current.Items.Data) == 0 {
+		return nil, fmt.Errorf("stripe subscription %s has no items", providerID)
+	}
+
+	// Step 2: Change its price and quantity, charging or crediting the rest of the period
+	form := url.Values{}
+	form.Set("items[0][id]", current.Items.Data[0].ID)
+	form.Set("items[0][price]", priceID)
+	form.Set("items[0][quantity]", strconv.Itoa(seats))
+	form.Set("proration_behavior", "create_prorations")
+	var updated subscription
+	if err := c.call(ctx, http.MethodPost, "/v1/subscriptions/"+url.PathEscape(providerID), form, &updated); err != nil {
+		return nil, err
+	}
+	return updated.state(), nil
+}
+
+// Cancel implements domain.BillingProvider
+func (c *Client) Cancel(ctx context.Context, providerID string) (*domain.SubscriptionState, error) {
+	var canceled subscription
+	if err := c.call(ctx, http.MethodDelete, "/v1/subscriptions/"+url.PathEscape(providerID), nil, &canceled); err != nil {
+		return nil, err
+	}
+	return canceled.state(), nil
+}
+
+// ParseEvent implements domain.BillingProvider
+// signature is the Stripe-Signature header: "t=<unix time>,v1=<hex HMAC-SHA256 of "t.payload">"
+// Only subscription events (customer.subscription.*) change a subscription; Stripe sends one
+// of them for everything that matters, a failed invoice included
+func (c *Client) ParseEvent(payload []byte, signature string) (*domain.BillingEvent, error) {
+	// Step 1: Check the signature and its age
+	if err := c.verify(payload, signature); err != nil {
+		return nil, err
+	}
+
+	// Step 2: Decode the event
+	var event struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object json.RawMessage `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil || event.ID == "" {
+		return nil, domain.ErrInvalidWebhook
+	}
+	result := &domain.BillingEvent{ID: event.ID, Type: event.Type}
+	if !strings.HasPrefix(event.Type, "customer.subscription.") {
+		return result, nil
+	}
+	var object subscription
+	if err := json.Unmarshal(event.Data.Object, &object); err != nil {
+		return nil, domain.ErrInvalidWebhook
+	}
+	result.Subscription = object.state()
+	return result, nil
+}
+
+// verify checks a webhook signature against the endpoint's secret
+// Stripe may list several v1 signatures while the secret is being rolled; one has to match
+func (c *Client) verify(payload []byte, header string) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if signature, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return domain.ErrInvalidWebhook
+	}
+	if age := c.clock.Now().Sub(time.Unix(seconds, 0)); age > WebhookTolerance || age < -WebhookTolerance {
+		return domain.ErrInvalidWebhook
+	}
+
+	mac := hmac.New(sha256.New, []byte(c.webhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+	return domain.ErrInvalidWebhook
+}
+
+// call sends a form-encoded request to the API and decodes the answer into out
+func (c *Client) call(ctx context.Context, method, path string, form url.Values, out any) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build stripe request: %w", err)
+	}
+	req.SetBasicAuth(c.secretKey, "")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call stripe: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Stripe explains what went wrong in {"error": {"message": ...}}
+		var failure struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &failure) == nil && failure.Error.Message != "" {
+			return fmt.Errorf("stripe answered %s: %s", resp.Status, failure.Error.Message)
+		}
+		return fmt.Errorf("stripe answered %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode stripe answer: %w", err)
+	}
+	return nil
+}
+
+// state converts a Stripe subscription into the domain's view of it
+func (s *subscription) state() *domain.SubscriptionState {
+	state := &domain.SubscriptionState{
+		ProviderID:       s.ID,
+		CustomerID:       s.Customer,
+		TenantID:         s.Metadata["tenant_id"],
+		Status:           status(s.Status),
+		TrialEndsAt:      unixTime(s.TrialEnd),
+		CurrentPeriodEnd: unixTime(s.CurrentPeriodEnd),
+	}
+	var invoice struct {
+		HostedInvoiceURL string `json:"hosted_invoice_url"`
+	}
+	if json.Unmarshal(s.LatestInvoice, &invoice) == nil {
+		state.PaymentURL = invoice.HostedInvoiceURL
+	}
+	if len(s.Items.Data) > 0 {
+		state.PriceID = s.Items.Data[0].Price.ID
+		state.Seats = s.Items.Data[0].Quantity
+	}
+	return state
+}
+
+// status maps Stripe's subscription statuses onto the domain's
+// An incomplete subscription is waiting for its first payment, which is as good as past due;
+// unpaid and paused ones are no longer collected, so their tenants are suspended
+func status(stripeStatus string) string {
+	switch stripeStatus {
+	case "trialing":
+		return domain.SubscriptionTrialing
+	case "active":
+		return domain.SubscriptionActive
+	case "past_due", "incomplete":
+		return domain.SubscriptionPastDue
+	case "unpaid", "paused":
+		return domain.SubscriptionSuspended
+	default:
+		// canceled, incomplete_expired
+		return domain.SubscriptionCanceled
+	}
+}
+
+// unixTime converts Stripe's Unix timestamps, where 0 means "not set"
+func unixTime(seconds int64) *time.Time {
+	if seconds == 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t
+}
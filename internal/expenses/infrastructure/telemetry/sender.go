@@ -0,0 +1,50 @@
+// Package telemetry delivers the anonymous usage reports of instances that opted in
+// This is part of the infrastructure layer - it implements domain.TelemetrySender over HTTPS
+package telemetry
+
+import (
+	"bytes"         // For the request body
+	"context"       // For request context (cancellation, timeouts)
+	"encoding/json" // For encoding the report
+	"fmt"           // For formatted string operations and error wrapping
+	"net/http"      // For posting the report
+	"time"          // For the request timeout
+
+	"myexpenses/internal/expenses/domain" // Import our domain layer
+)
+
+// HTTPSender posts reports as JSON to a collection endpoint
+type HTTPSender struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewHTTPSender creates a sender posting to endpoint
+// Reports are small, so a request that takes longer than ten seconds is given up
+func NewHTTPSender(endpoint string) *HTTPSender {
+	return &HTTPSender{endpoint: endpoint, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Send implements domain.TelemetrySender
+// Any 2xx answer counts as delivered; reports that fail aren't retried until the next one is due
+func (s *HTTPSender) Send(ctx context.Context, report *domain.TelemetryReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry report: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("telemetry endpoint answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Package fieldcrypt encrypts single database values, so sensitive columns can't be read
+// by whoever has access to the database but not to the application's keys
+package fieldcrypt
+
+import (
+	"crypto/aes"      // The block cipher
+	"crypto/cipher"   // GCM, which encrypts and authenticates
+	"crypto/rand"     // For the nonces
+	"encoding/base64" // For storing ciphertext in text columns
+	"errors"          // For the key and ciphertext errors
+	"fmt"             // For formatted string operations and error wrapping
+	"strings"         // For parsing key lists and ciphertext
+)
+
+// Prefix marks encrypted values; values without it are plaintext written before encryption was enabled
+const Prefix = "enc:"
+
+// KeySize is the size of a key in bytes (AES-256)
+const KeySize = 32
+
+// Errors returned by the cipher
+var (
+	// ErrInvalidKeys occurs when the key list can't be parsed or a key has the wrong size
+	ErrInvalidKeys = errors.New("invalid field encryption keys: want id:base64key[,id:base64key...] with 32-byte keys")
+
+	// ErrUnknownKey occurs when a value was encrypted with a key that isn't configured any more
+	ErrUnknownKey = errors.New("value was encrypted with an unknown key")
+
+	// ErrInvalidCiphertext occurs when an encrypted value was damaged or changed
+	ErrInvalidCiphertext = errors.New("encrypted value is damaged or was tampered with")
+)
+
+// Cipher encrypts values with AES-256-GCM under the current key and decrypts them under any known key
+// Keeping old keys lets the key be rotated: new writes use the new key, old rows still read,
+// and rows are re-encrypted under the new key whenever they are saved again
+type Cipher struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// ParseKeys creates a cipher from a list of keys as "id:base64key,id:base64key"
+// The first key is the current one; the others are only used to decrypt
+// Key IDs are stored with every value, so they must stay stable and can't contain ':'
+func ParseKeys(spec string) (*Cipher, error) {
+	c := &Cipher{keys: map[string]cipher.AEAD{}}
+	for _, entry := range strings.Split(spec, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" || c.keys[id] != nil {
+			return nil, ErrInvalidKeys
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != KeySize {
+			return nil, ErrInvalidKeys
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, ErrInvalidKeys
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, ErrInvalidKeys
+		}
+		c.keys[id] = aead
+		if c.current == "" {
+			c.current = id
+		}
+	}
+	return c, nil
+}
+
+// Encrypt returns plaintext encrypted under the current key, as "enc:<key id>:<base64>"
+// context binds the value to where it is stored (e.g. "expenses.description"):
+// a value copied into another column doesn't decrypt
+func (c *Cipher) Encrypt(plaintext, context string) (string, error) {
+	aead := c.keys[c.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(context))
+	return Prefix + c.current + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of a value made by Encrypt with the same context
+// Values without the prefix are returned as they are
+func (c *Cipher) Decrypt(value, context string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, Prefix), ":")
+	if !ok {
+		return "", ErrInvalidCiphertext
+	}
+	aead := c.keys[id]
+	if aead == nil {
+		return "", ErrUnknownKey
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(context))
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
+
+// CurrentPrefix is how values encrypted under the current key start
+// Values that start differently have to be written again to be protected by it
+func (c *Cipher) CurrentPrefix() string {
+	return Prefix + c.current + ":"
+}
+
+// IsEncrypted reports whether value looks like the output of Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
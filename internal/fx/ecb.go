@@ -0,0 +1,92 @@
+// Package fx looks up daily foreign exchange rates from a pluggable provider and caches them
+// This file fetches the euro reference rates the European Central Bank publishes every working day
+package fx
+
+import (
+	"context"      // For request context (cancellation, timeouts)
+	"encoding/xml" // The ECB publishes its rates as XML
+	"fmt"          // For formatted string operations and error wrapping
+	"net/http"     // For fetching the rate files
+	"time"         // For the request timeout and dates
+
+	"myexpenses/internal/clock" // Time source, to pick the file that covers a day
+)
+
+// DefaultECBURL is where the ECB publishes its reference rate files
+const DefaultECBURL = "https://www.ecb.europa.eu/stats/eurofxref"
+
+// ecbRecentDays is how far back the ECB's short history file goes, with a few days to spare
+const ecbRecentDays = 85
+
+// ECB is a Provider of the ECB's euro reference rates: about 30 currencies, free and without a key
+// Days of the last three months come from the 90-day history file; earlier days from the full
+// history since 1999, which is large but fetched at most once, as every day in it is cached
+type ECB struct {
+	baseURL string
+	client  *http.Client
+	clock   clock.Clock
+}
+
+// NewECB creates an ECB provider
+func NewECB(clk clock.Clock) *ECB {
+	return &ECB{
+		baseURL: DefaultECBURL,
+		client:  &http.Client{Timeout: 60 * time.Second},
+		clock:   clock.Or(clk),
+	}
+}
+
+// ecbEnvelope is a rate file: one cube per day holding one cube per currency
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// Name implements Provider
+func (e *ECB) Name() string {
+	return "ECB"
+}
+
+// Fetch implements Provider; it returns every day of the file that covers day
+func (e *ECB) Fetch(ctx context.Context, day time.Time) ([]DailyRates, error) {
+	file := "eurofxref-hist-90d.xml"
+	if e.clock.Now().Sub(day) > ecbRecentDays*24*time.Hour {
+		file = "eurofxref-hist.xml"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL+"/"+file, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %s", file, resp.Status)
+	}
+
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", file, err)
+	}
+	rates := make([]DailyRates, 0, len(envelope.Days))
+	for _, published := range envelope.Days {
+		date, err := time.Parse(DateLayout, published.Time)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: invalid day %q", file, published.Time)
+		}
+		daily := DailyRates{Date: date, Base: "EUR", Rates: make(map[string]float64, len(published.Rates))}
+		for _, rate := range published.Rates {
+			daily.Rates[rate.Currency] = rate.Rate
+		}
+		rates = append(rates, daily)
+	}
+	return rates, nil
+}
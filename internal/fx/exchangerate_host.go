@@ -0,0 +1,97 @@
+// Package fx looks up daily foreign exchange rates from a pluggable provider and caches them
+// This file fetches rates from exchangerate.host, which covers far more currencies than the ECB
+package fx
+
+import (
+	"context"       // For request context (cancellation, timeouts)
+	"encoding/json" // For decoding answers
+	"errors"        // For API errors
+	"fmt"           // For formatted string operations and error wrapping
+	"net/http"      // For calling the API
+	"net/url"       // For building the query
+	"strings"       // For splitting currency pairs
+	"time"          // For the request timeout and dates
+)
+
+// DefaultExchangeRateHostURL is exchangerate.host's API
+const DefaultExchangeRateHostURL = "https://api.exchangerate.host"
+
+// ExchangeRateHost is a Provider of exchangerate.host's daily rates, which needs an access key
+// Every day is one request, quoted against the account's source currency (USD on the free plan)
+type ExchangeRateHost struct {
+	accessKey string
+	baseURL   string
+	client    *http.Client
+}
+
+// NewExchangeRateHost creates an exchangerate.host provider authenticated with an access key
+func NewExchangeRateHost(accessKey string) *ExchangeRateHost {
+	return &ExchangeRateHost{
+		accessKey: accessKey,
+		baseURL:   DefaultExchangeRateHostURL,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// exchangeRateHostAnswer is the answer of the historical endpoint
+// Quotes are keyed by pair, source currency first ("USDEUR")
+type exchangeRateHostAnswer struct {
+	Success bool               `json:"success"`
+	Date    string             `json:"date"`
+	Source  string             `json:"source"`
+	Quotes  map[string]float64 `json:"quotes"`
+	Error   *struct {
+		Code int    `json:"code"`
+		Info string `json:"info"`
+	} `json:"error"`
+}
+
+// Name implements Provider
+func (h *ExchangeRateHost) Name() string {
+	return "exchangerate.host"
+}
+
+// Fetch implements Provider; it returns the rates of day only
+func (h *ExchangeRateHost) Fetch(ctx context.Context, day time.Time) ([]DailyRates, error) {
+	query := url.Values{"access_key": {h.accessKey}, "date": {day.Format(DateLayout)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.baseURL+"/historical?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		// The URL carries the access key, so don't let it reach the logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchangerate.host answered %s", resp.Status)
+	}
+
+	var answer exchangeRateHostAnswer
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("failed to decode exchangerate.host answer: %w", err)
+	}
+	if !answer.Success {
+		if answer.Error != nil {
+			return nil, fmt.Errorf("exchangerate.host error %d: %s", answer.Error.Code, answer.Error.Info)
+		}
+		return nil, errors.New("exchangerate.host answered without rates")
+	}
+
+	date, err := time.Parse(DateLayout, answer.Date)
+	if err != nil {
+		return nil, fmt.Errorf("exchangerate.host answered an invalid day %q", answer.Date)
+	}
+	daily := DailyRates{Date: date, Base: answer.Source, Rates: make(map[string]float64, len(answer.Quotes))}
+	for pair, rate := range answer.Quotes {
+		if currency, ok := strings.CutPrefix(pair, answer.Source); ok && currency != "" {
+			daily.Rates[currency] = rate
+		}
+	}
+	return []DailyRates{daily}, nil
+}
@@ -0,0 +1,182 @@
+// Package fx looks up daily foreign exchange rates from a pluggable provider and caches them
+// Rates of past days never change, so they are fetched once; the rates of today are fetched
+// again every RefreshInterval until the provider has published them
+package fx
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For the unavailable error
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For picking the latest published day
+	"strings" // For normalizing currency codes
+	"sync"    // For fetching a day only once at a time
+	"time"    // For handling dates and times
+
+	"myexpenses/internal/cache" // Where fetched rates are kept
+	"myexpenses/internal/clock" // Time source, so "today" can be driven by a fake clock
+)
+
+// ErrUnavailable occurs when the provider has no rate for a currency pair on a day
+var ErrUnavailable = errors.New("exchange rate unavailable")
+
+// RefreshInterval is how long the rates of today are cached
+// Providers publish once a day (the ECB around 16:00 CET), so until then today uses the last published rates
+const RefreshInterval = time.Hour
+
+// MaxStaleness is how far back the last published rates of a day may be
+// Weekends and holidays have no rates of their own and use those of the last working day
+const MaxStaleness = 7 * 24 * time.Hour
+
+// DateLayout is how days are written in cache keys and by providers
+const DateLayout = "2006-01-02"
+
+// DailyRates are the rates a provider published for one day: how many units of each currency
+// one unit of Base was worth
+type DailyRates struct {
+	Date  time.Time
+	Base  string
+	Rates map[string]float64
+}
+
+// rate returns how many units of to one unit of from was worth, through the base currency
+func (d DailyRates) rate(from, to string) (float64, bool) {
+	fromRate, ok := d.of(from)
+	if !ok {
+		return 0, false
+	}
+	toRate, ok := d.of(to)
+	if !ok {
+		return 0, false
+	}
+	return toRate / fromRate, true
+}
+
+// of returns the rate of a currency against the base currency
+func (d DailyRates) of(currency string) (float64, bool) {
+	if currency == d.Base {
+		return 1, true
+	}
+	rate, ok := d.Rates[currency]
+	return rate, ok && rate > 0
+}
+
+// Provider fetches published rates
+type Provider interface {
+	// Name identifies the provider in errors
+	Name() string
+
+	// Fetch returns the rates published on day, if there are any, and may return other days too
+	// (e.g. a whole history file at once), which are cached as well
+	Fetch(ctx context.Context, day time.Time) ([]DailyRates, error)
+}
+
+// Rates looks up exchange rates of any day and caches them
+type Rates struct {
+	provider Provider
+	cache    *cache.Memory
+	clock    clock.Clock
+
+	// mu makes concurrent lookups of a missing day wait for one fetch instead of each fetching
+	mu sync.Mutex
+}
+
+// NewRates creates rates fetched from provider and kept in store (nil means a cache of their own)
+func NewRates(provider Provider, store *cache.Memory, clk clock.Clock) *Rates {
+	clk = clock.Or(clk)
+	if store == nil {
+		store = cache.NewMemory(clk)
+	}
+	return &Rates{provider: provider, cache: store, clock: clk}
+}
+
+// Rate returns how many units of to one unit of from was worth on the given day, using the
+// last rates published by then; days after today use the latest rates
+// Returns ErrUnavailable if the provider has no rate for either currency
+func (r *Rates) Rate(ctx context.Context, from, to string, on time.Time) (float64, error) {
+	from, to = strings.ToUpper(strings.TrimSpace(from)), strings.ToUpper(strings.TrimSpace(to))
+	if from == to {
+		return 1, nil
+	}
+
+	day := truncateDay(on)
+	if today := truncateDay(r.clock.Now()); day.After(today) {
+		day = today
+	}
+	rates, err := r.Day(ctx, day)
+	if err != nil {
+		return 0, err
+	}
+	rate, ok := rates.rate(from, to)
+	if !ok {
+		return 0, fmt.Errorf("%w: no %s/%s rate from %s on %s", ErrUnavailable, from, to, r.provider.Name(), day.Format(DateLayout))
+	}
+	return rate, nil
+}
+
+// Day returns the last rates published on or before day, at most MaxStaleness earlier
+func (r *Rates) Day(ctx context.Context, day time.Time) (DailyRates, error) {
+	day = truncateDay(day)
+	if rates, ok := r.cache.Get(cacheKey(day)); ok {
+		return r.published(day, rates.(DailyRates))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Another lookup may have fetched it while this one waited
+	if rates, ok := r.cache.Get(cacheKey(day)); ok {
+		return r.published(day, rates.(DailyRates))
+	}
+
+	fetched, err := r.provider.Fetch(ctx, day)
+	if err != nil {
+		return DailyRates{}, fmt.Errorf("failed to fetch exchange rates from %s: %w", r.provider.Name(), err)
+	}
+
+	// Keep every published day, and remember which of them day uses
+	sort.Slice(fetched, func(i, j int) bool { return fetched[i].Date.Before(fetched[j].Date) })
+	var latest *DailyRates
+	for i := range fetched {
+		published := &fetched[i]
+		published.Date = truncateDay(published.Date)
+		r.store(published.Date, *published)
+		if !published.Date.After(day) && day.Sub(published.Date) <= MaxStaleness {
+			latest = published
+		}
+	}
+	if latest == nil {
+		// Nothing was published by then; remember that for a while rather than asking on every lookup
+		latest = &DailyRates{Date: day}
+		r.cache.Set(cacheKey(day), *latest, RefreshInterval)
+		return r.published(day, *latest)
+	}
+	r.store(day, *latest)
+	return *latest, nil
+}
+
+// published returns the rates used for day, or ErrUnavailable if there are none
+func (r *Rates) published(day time.Time, rates DailyRates) (DailyRates, error) {
+	if rates.Rates == nil {
+		return DailyRates{}, fmt.Errorf("%w: %s published no rates by %s", ErrUnavailable, r.provider.Name(), day.Format(DateLayout))
+	}
+	return rates, nil
+}
+
+// store keeps the rates used for day: for good once the day is over, until the next refresh for today
+func (r *Rates) store(day time.Time, rates DailyRates) {
+	ttl := time.Duration(0)
+	if !day.Before(truncateDay(r.clock.Now())) {
+		ttl = RefreshInterval
+	}
+	r.cache.Set(cacheKey(day), rates, ttl)
+}
+
+// cacheKey is the key the rates of day are kept under
+func cacheKey(day time.Time) string {
+	return "fx:" + day.Format(DateLayout)
+}
+
+// truncateDay returns the UTC day t falls on, as midnight
+func truncateDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
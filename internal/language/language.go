@@ -0,0 +1,159 @@
+// Package language detects the language of short free texts such as expense descriptions
+// and maps languages to PostgreSQL text search configurations
+// Full-text search stems words ("groceries" -> "groceri", "Einkäufe" -> "einkauf") and
+// drops stop words, but only correctly when it knows which language a text is in
+package language
+
+import (
+	"context"      // For the tenant a request is made in
+	"fmt"          // For describing unsupported languages
+	"strings"      // For splitting texts into words
+	"unicode"      // For classifying characters
+	"unicode/utf8" // For skipping one-letter words
+
+	"myexpenses/internal/auth" // The tenant a request is made in
+)
+
+// Simple is the PostgreSQL text search configuration that neither stems nor drops stop words
+// It is used for texts whose language is unknown, and for receipt item names like "ORG MLK 1L"
+const Simple = "simple"
+
+// searchConfigs maps the supported ISO 639-1 codes to the PostgreSQL text search
+// configuration of that language; all of them ship with PostgreSQL
+var searchConfigs = map[string]string{
+	"en": "english",
+	"de": "german",
+	"fr": "french",
+	"es": "spanish",
+	"it": "italian",
+	"nl": "dutch",
+	"pt": "portuguese",
+}
+
+// SearchConfig returns the text search configuration for a language code, or Simple
+func SearchConfig(code string) string {
+	if config, ok := searchConfigs[code]; ok {
+		return config
+	}
+	return Simple
+}
+
+// Supported reports whether code is a language with its own text search configuration
+func Supported(code string) bool {
+	_, ok := searchConfigs[code]
+	return ok
+}
+
+// markers are words that are frequent in one language and rare in the others:
+// function words plus the words that keep turning up in expense descriptions
+var markers = map[string][]string{
+	"en": {"the", "and", "for", "with", "from", "to", "of", "at", "my", "our", "lunch", "dinner", "groceries", "rent", "fuel", "ticket", "shopping", "coffee"},
+	"de": {"der", "die", "das", "und", "für", "mit", "von", "beim", "zum", "zur", "im", "mein", "einkauf", "miete", "tanken", "mittagessen", "abendessen", "fahrkarte", "lebensmittel", "geschenk"},
+	"fr": {"le", "la", "les", "et", "pour", "avec", "des", "du", "au", "aux", "chez", "mon", "courses", "loyer", "déjeuner", "dîner", "essence", "billet", "cadeau", "épicerie"},
+	"es": {"el", "los", "las", "y", "para", "con", "del", "en", "mi", "compra", "alquiler", "almuerzo", "cena", "gasolina", "billete", "regalo", "supermercado", "comida"},
+	"it": {"il", "lo", "gli", "e", "per", "con", "della", "al", "alla", "mio", "spesa", "affitto", "pranzo", "cena", "benzina", "biglietto", "regalo"},
+	"nl": {"de", "het", "een", "en", "voor", "met", "van", "bij", "naar", "mijn", "boodschappen", "huur", "lunch", "avondeten", "tanken", "kaartje", "cadeau"},
+	"pt": {"o", "os", "as", "e", "para", "com", "do", "da", "no", "na", "meu", "compras", "aluguel", "renda", "almoço", "jantar", "gasolina", "bilhete", "presente"},
+}
+
+// letters are characters that (among the supported languages) belong to one language only
+var letters = map[rune]string{
+	'ß': "de", 'ä': "de", 'ö': "de", 'ü': "de",
+	'ç': "fr", 'è': "fr", 'ê': "fr", 'à': "fr", 'œ': "fr",
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ã': "pt", 'õ': "pt",
+}
+
+// markerIndex maps each marker word to the languages it counts for
+var markerIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for code, words := range markers {
+		for _, word := range words {
+			index[word] = append(index[word], code)
+		}
+	}
+	return index
+}()
+
+// Detect guesses the language of text and returns its ISO 639-1 code
+// Descriptions are often a word or two, so it only answers when one language clearly
+// scores highest; ok=false means "can't tell" and the caller should use a default
+func Detect(text string) (code string, ok bool) {
+	// Step 1: Score marker words and language-specific letters
+	scores := make(map[string]float64)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, word := range words {
+		if utf8.RuneCountInString(word) < 2 && word != "y" && word != "e" && word != "o" {
+			continue
+		}
+		// Words shared by several languages ("de", "con") count for each of them, but less
+		for _, candidate := range markerIndex[word] {
+			scores[candidate] += 1 / float64(len(markerIndex[word]))
+		}
+		for _, r := range word {
+			if candidate, found := letters[r]; found {
+				scores[candidate]++
+			}
+		}
+	}
+
+	// Step 2: Only answer when there is a single clear winner
+	best, second := 0.0, 0.0
+	for candidate, score := range scores {
+		switch {
+		case score > best:
+			second = best
+			best, code = score, candidate
+		case score > second:
+			second = score
+		}
+	}
+	if best < 1 || best == second {
+		return "", false
+	}
+	return code, true
+}
+
+// Preferences are the languages assumed when a text's language can't be detected
+// A deployment has a default, and tenants (e.g. a German company on a shared deployment)
+// can have their own
+type Preferences struct {
+	fallback string
+	tenants  map[string]string
+}
+
+// NewPreferences creates language preferences from ISO 639-1 codes
+// fallback may be "" to search texts of unknown language without stemming
+func NewPreferences(fallback string, tenants map[string]string) (*Preferences, error) {
+	if fallback != "" && !Supported(fallback) {
+		return nil, fmt.Errorf("unsupported language %q", fallback)
+	}
+	for tenantID, code := range tenants {
+		if !Supported(code) {
+			return nil, fmt.Errorf("tenant %s: unsupported language %q", tenantID, code)
+		}
+	}
+	return &Preferences{fallback: fallback, tenants: tenants}, nil
+}
+
+// For returns the language of the caller's tenant, or "" if there is none
+// A nil Preferences has no languages, so callers can leave it unset
+func (p *Preferences) For(ctx context.Context) string {
+	if p == nil {
+		return ""
+	}
+	if code, ok := p.tenants[auth.TenantID(ctx)]; ok {
+		return code
+	}
+	return p.fallback
+}
+
+// DetectOr returns the detected language of text, or the caller's tenant language
+func (p *Preferences) DetectOr(ctx context.Context, text string) string {
+	if code, ok := Detect(text); ok {
+		return code
+	}
+	return p.For(ctx)
+}
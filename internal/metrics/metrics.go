@@ -0,0 +1,197 @@
+// Package metrics collects in-process call metrics and exposes them in the Prometheus text format
+// It only depends on the clock, so any layer can record into it; the HTTP layer only serves the output
+package metrics
+
+import (
+	"fmt"     // For writing the exposition format
+	"io"      // For writing to any output
+	"sort"    // For stable output order
+	"strings" // For escaping label values
+	"sync"    // For concurrent recording
+	"time"    // For call durations
+)
+
+// Recorder receives one observation per completed call
+// Decorators record into a Recorder so the code they wrap stays free of observability concerns
+type Recorder interface {
+	// RecordCall records a call to method of component that took duration,
+	// returned rows rows (0 when not applicable) and failed with err (nil on success)
+	RecordCall(component, method string, duration time.Duration, rows int, err error)
+}
+
+// CacheRecorder receives one observation per cache lookup
+// The hit rate of a cache is hits / (hits + misses)
+type CacheRecorder interface {
+	// RecordCacheLookup records a lookup in cache that was a hit or a miss
+	RecordCacheLookup(cache string, hit bool)
+}
+
+// Nop is a Recorder that drops everything
+type Nop struct{}
+
+// RecordCall does nothing
+func (Nop) RecordCall(string, string, time.Duration, int, error) {}
+
+// RecordCacheLookup does nothing
+func (Nop) RecordCacheLookup(string, bool) {}
+
+// latencyBuckets are the histogram upper bounds in seconds
+// They span fast primary-key lookups up to slow reports
+var latencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// callKey identifies one instrumented method
+type callKey struct {
+	component string
+	method    string
+}
+
+// callStats accumulates the observations of one method
+type callStats struct {
+	calls   int64
+	errors  int64
+	rows    int64
+	seconds float64
+	buckets []int64 // cumulative counts per latencyBuckets entry
+}
+
+// cacheStats counts the lookups of one cache
+type cacheStats struct {
+	hits   int64
+	misses int64
+}
+
+// Registry is an in-memory Recorder and CacheRecorder
+type Registry struct {
+	mu     sync.Mutex
+	calls  map[callKey]*callStats
+	caches map[string]*cacheStats
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		calls:  make(map[callKey]*callStats),
+		caches: make(map[string]*cacheStats),
+	}
+}
+
+// RecordCall adds one observation
+func (r *Registry) RecordCall(component, method string, duration time.Duration, rows int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := callKey{component: component, method: method}
+	stats, ok := r.calls[key]
+	if !ok {
+		stats = &callStats{buckets: make([]int64, len(latencyBuckets))}
+		r.calls[key] = stats
+	}
+
+	seconds := duration.Seconds()
+	stats.calls++
+	stats.rows += int64(rows)
+	stats.seconds += seconds
+	if err != nil {
+		stats.errors++
+	}
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			stats.buckets[i]++
+		}
+	}
+}
+
+// RecordCacheLookup counts one lookup
+func (r *Registry) RecordCacheLookup(cache string, hit bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.caches[cache]
+	if !ok {
+		stats = &cacheStats{}
+		r.caches[cache] = stats
+	}
+	if hit {
+		stats.hits++
+	} else {
+		stats.misses++
+	}
+}
+
+// WritePrometheus writes every metric in the Prometheus text exposition format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]callKey, 0, len(r.calls))
+	for key := range r.calls {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].component != keys[j].component {
+			return keys[i].component < keys[j].component
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	var b strings.Builder
+	b.WriteString("# HELP myexpenses_calls_total Completed calls per component and method.\n")
+	b.WriteString("# TYPE myexpenses_calls_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "myexpenses_calls_total{%s} %d\n", labels(key), r.calls[key].calls)
+	}
+	b.WriteString("# HELP myexpenses_call_errors_total Failed calls per component and method.\n")
+	b.WriteString("# TYPE myexpenses_call_errors_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "myexpenses_call_errors_total{%s} %d\n", labels(key), r.calls[key].errors)
+	}
+	b.WriteString("# HELP myexpenses_call_rows_total Rows returned per component and method.\n")
+	b.WriteString("# TYPE myexpenses_call_rows_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "myexpenses_call_rows_total{%s} %d\n", labels(key), r.calls[key].rows)
+	}
+	b.WriteString("# HELP myexpenses_call_duration_seconds Call latency per component and method.\n")
+	b.WriteString("# TYPE myexpenses_call_duration_seconds histogram\n")
+	for _, key := range keys {
+		stats := r.calls[key]
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(&b, "myexpenses_call_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels(key), bound, stats.buckets[i])
+		}
+		fmt.Fprintf(&b, "myexpenses_call_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels(key), stats.calls)
+		fmt.Fprintf(&b, "myexpenses_call_duration_seconds_sum{%s} %g\n", labels(key), stats.seconds)
+		fmt.Fprintf(&b, "myexpenses_call_duration_seconds_count{%s} %d\n", labels(key), stats.calls)
+	}
+
+	if len(r.caches) > 0 {
+		names := make([]string, 0, len(r.caches))
+		for name := range r.caches {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		b.WriteString("# HELP myexpenses_cache_hits_total Lookups answered from the cache.\n")
+		b.WriteString("# TYPE myexpenses_cache_hits_total counter\n")
+		for _, name := range names {
+			fmt.Fprintf(&b, "myexpenses_cache_hits_total{cache=%q} %d\n", escape(name), r.caches[name].hits)
+		}
+		b.WriteString("# HELP myexpenses_cache_misses_total Lookups that had to be computed.\n")
+		b.WriteString("# TYPE myexpenses_cache_misses_total counter\n")
+		for _, name := range names {
+			fmt.Fprintf(&b, "myexpenses_cache_misses_total{cache=%q} %d\n", escape(name), r.caches[name].misses)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labels renders the label set of a key
+func labels(key callKey) string {
+	return fmt.Sprintf("component=%q,method=%q", escape(key.component), escape(key.method))
+}
+
+// escape drops characters that would need escaping inside a label value
+// Component and method names are identifiers, so this only guards against misuse
+func escape(value string) string {
+	return strings.NewReplacer(`"`, "", `\`, "", "\n", "").Replace(value)
+}
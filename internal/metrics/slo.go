@@ -0,0 +1,329 @@
+// Package metrics collects in-process call metrics and exposes them in the Prometheus text format
+// This file tracks service level objectives (SLOs) per route and the rate their error budgets burn
+package metrics
+
+import (
+	"bytes"         // For webhook bodies
+	"encoding/json" // For webhook bodies
+	"errors"        // For configuration errors
+	"fmt"           // For formatted string operations and error wrapping
+	"log"           // For reporting alerts that couldn't be delivered
+	"net/http"      // For posting alerts to a webhook
+	"sort"          // For stable status order
+	"strconv"       // For parsing targets
+	"strings"       // For parsing objective lists
+	"sync"          // For concurrent recording
+	"time"          // For latency thresholds and windows
+
+	"myexpenses/internal/clock" // Time source, so windows can be driven by a fake clock
+)
+
+// ErrInvalidObjectives occurs when an objective list can't be parsed
+var ErrInvalidObjectives = errors.New(`invalid SLOs: use "METHOD /route=latency/target%" entries separated by semicolons, e.g. "GET /expenses=300ms/99.5"`)
+
+// HTTPComponent is the component requests are recorded under; their method is "METHOD /route"
+const HTTPComponent = "http"
+
+// DefaultRoute is the route of the objective that applies to every route without one of its own
+const DefaultRoute = "*"
+
+// DefaultObjectives hold lists and single expenses to 300ms and reports and exports to 2s,
+// and everything else to 1s, each for 99% of requests
+const DefaultObjectives = "*=1s/99;GET /expenses=300ms/99;GET /expenses/:id=300ms/99;" +
+	"GET /reports/compare=2s/99;GET /reports/tax=2s/99;GET /dashboard=2s/99;POST /exports=2s/99"
+
+// Objective is the SLO of one route: Target of its requests are good, which means they
+// succeed within Latency. The rest, 1 - Target, is the error budget
+type Objective struct {
+	Route   string
+	Latency time.Duration
+	Target  float64
+}
+
+// MarshalJSON shows the latency as a duration string ("300ms") rather than nanoseconds
+func (o Objective) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Route   string  `json:"route"`
+		Latency string  `json:"latency"`
+		Target  float64 `json:"target"`
+	}{o.Route, o.Latency.String(), o.Target})
+}
+
+// ParseObjectives parses a semicolon-separated list of "METHOD /route=latency/target%" entries,
+// like DefaultObjectives; the route "*" is the default for routes without an entry
+func ParseObjectives(spec string) ([]Objective, error) {
+	var objectives []Objective
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(entry, "=")
+		latencyValue, targetValue, ok2 := strings.Cut(value, "/")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidObjectives, entry)
+		}
+		latency, err := time.ParseDuration(strings.TrimSpace(latencyValue))
+		if err != nil || latency <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidObjectives, entry)
+		}
+		target, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(targetValue), "%"), 64)
+		if err != nil || target <= 0 || target >= 100 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidObjectives, entry)
+		}
+		objectives = append(objectives, Objective{Route: strings.TrimSpace(route), Latency: latency, Target: target / 100})
+	}
+	return objectives, nil
+}
+
+// Window is a span the burn rate is computed over
+type Window struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Windows are the spans burn rates are reported for, shortest first
+var Windows = []Window{{"5m", 5 * time.Minute}, {"30m", 30 * time.Minute}, {"1h", time.Hour}, {"6h", 6 * time.Hour}}
+
+// AlertRule fires when the error budget burns at least Burn times as fast as it may,
+// in both the long window and the short one. The short window makes the alert stop soon
+// after the problem does
+type AlertRule struct {
+	Severity string
+	Long     Window
+	Short    Window
+	Burn     float64
+}
+
+// AlertRules page when 2% of a month's budget is spent within an hour, and open a ticket
+// when 5% is spent within six hours (the multiwindow, multi-burn-rate alerts of the SRE workbook)
+var AlertRules = []AlertRule{
+	{Severity: "page", Long: Windows[2], Short: Windows[0], Burn: 14.4},
+	{Severity: "ticket", Long: Windows[3], Short: Windows[1], Burn: 6},
+}
+
+// Alert is an alert rule starting (Firing) or stopping (!Firing) for a route
+type Alert struct {
+	Route    string    `json:"route"`
+	Severity string    `json:"severity"`
+	Firing   bool      `json:"firing"`
+	BurnRate float64   `json:"burn_rate"`
+	At       time.Time `json:"at"`
+}
+
+// AlertHook is told about alerts that start or stop
+type AlertHook func(Alert)
+
+// LogAlerts is an AlertHook that logs alerts
+func LogAlerts(alert Alert) {
+	if alert.Firing {
+		log.Printf("SLO alert (%s): %s burns its error budget %.1fx too fast", alert.Severity, alert.Route, alert.BurnRate)
+	} else {
+		log.Printf("SLO alert (%s) resolved: %s", alert.Severity, alert.Route)
+	}
+}
+
+// WebhookAlerts returns an AlertHook that posts alerts as JSON to url
+// Delivery is best-effort: failures are logged, not retried
+func WebhookAlerts(url string) AlertHook {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(alert Alert) {
+		body, err := json.Marshal(alert)
+		if err != nil {
+			log.Printf("failed to encode SLO alert: %v", err)
+			return
+		}
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("failed to post SLO alert: %v", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			log.Printf("failed to post SLO alert: webhook answered %s", resp.Status)
+		}
+	}
+}
+
+// sloMinutes is how many one-minute buckets are kept per route: the longest window
+const sloMinutes = 6 * 60
+
+// sloBucket counts the requests of one minute
+type sloBucket struct {
+	minute int64 // Unix minute the counts are for
+	total  int64
+	bad    int64
+}
+
+// routeSLO is the recorded traffic of one route
+type routeSLO struct {
+	objective Objective
+	buckets   [sloMinutes]sloBucket
+	firing    map[string]bool // severity -> firing
+}
+
+// WindowStatus is how a route did over one window
+type WindowStatus struct {
+	Window   string  `json:"window"`
+	Requests int64   `json:"requests"`
+	Bad      int64   `json:"bad"`
+	BurnRate float64 `json:"burn_rate"`
+}
+
+// SLOStatus is how a route is doing against its objective
+type SLOStatus struct {
+	Objective Objective      `json:"objective"`
+	Windows   []WindowStatus `json:"windows"`
+
+	// Alerts are the severities whose alert is firing
+	Alerts []string `json:"alerts"`
+}
+
+// SLOTracker is a Recorder that checks every HTTP request against the objective of its route
+// It keeps six hours of per-minute counts per route in memory, so every instance tracks its own traffic
+type SLOTracker struct {
+	clock      clock.Clock
+	objectives map[string]Objective
+	hooks      []AlertHook
+
+	mu     sync.Mutex
+	routes map[string]*routeSLO
+}
+
+// NewSLOTracker creates a tracker for objectives; hooks are told when alerts start or stop
+func NewSLOTracker(objectives []Objective, clk clock.Clock, hooks ...AlertHook) *SLOTracker {
+	t := &SLOTracker{
+		clock:      clock.Or(clk),
+		objectives: make(map[string]Objective, len(objectives)),
+		hooks:      hooks,
+		routes:     make(map[string]*routeSLO),
+	}
+	for _, objective := range objectives {
+		t.objectives[objective.Route] = objective
+	}
+	return t
+}
+
+// RecordCall counts an HTTP request as good or bad; other calls are ignored
+func (t *SLOTracker) RecordCall(component, method string, duration time.Duration, _ int, err error) {
+	if component != HTTPComponent {
+		return
+	}
+	objective, ok := t.objectives[method]
+	if !ok {
+		if objective, ok = t.objectives[DefaultRoute]; !ok {
+			return
+		}
+		objective.Route = method
+	}
+
+	minute := t.clock.Now().Unix() / 60
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	route, ok := t.routes[method]
+	if !ok {
+		route = &routeSLO{objective: objective, firing: make(map[string]bool)}
+		t.routes[method] = route
+	}
+	bucket := &route.buckets[minute%sloMinutes]
+	if bucket.minute != minute {
+		*bucket = sloBucket{minute: minute}
+	}
+	bucket.total++
+	if err != nil || duration > objective.Latency {
+		bucket.bad++
+	}
+}
+
+// Status returns every route that had traffic in the last six hours, by route
+func (t *SLOTracker) Status() []SLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	statuses := t.statuses(t.clock.Now())
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Objective.Route < statuses[j].Objective.Route })
+	return statuses
+}
+
+// Evaluate checks the alert rules of every route and tells the hooks about alerts that
+// started or stopped since the last evaluation; it runs on a schedule
+func (t *SLOTracker) Evaluate() {
+	now := t.clock.Now()
+	var changed []Alert
+
+	t.mu.Lock()
+	for _, route := range t.routes {
+		for _, rule := range AlertRules {
+			long := route.window(now, rule.Long.Duration).BurnRate
+			short := route.window(now, rule.Short.Duration).BurnRate
+			firing := long >= rule.Burn && short >= rule.Burn
+			if firing != route.firing[rule.Severity] {
+				route.firing[rule.Severity] = firing
+				changed = append(changed, Alert{Route: route.objective.Route, Severity: rule.Severity, Firing: firing, BurnRate: long, At: now})
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	// Hooks may be slow (webhooks), so they run without holding the lock
+	for _, alert := range changed {
+		for _, hook := range t.hooks {
+			hook(alert)
+		}
+	}
+}
+
+// statuses builds the status of every route with recent traffic. Callers hold mu
+func (t *SLOTracker) statuses(now time.Time) []SLOStatus {
+	statuses := make([]SLOStatus, 0, len(t.routes))
+	for _, route := range t.routes {
+		status := SLOStatus{Objective: route.objective, Alerts: []string{}}
+		for _, window := range Windows {
+			ws := route.window(now, window.Duration)
+			ws.Window = window.Name
+			status.Windows = append(status.Windows, ws)
+		}
+		if status.Windows[len(status.Windows)-1].Requests == 0 {
+			continue
+		}
+		for _, rule := range AlertRules {
+			if route.firing[rule.Severity] {
+				status.Alerts = append(status.Alerts, rule.Severity)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// window sums the buckets of the last d and computes the burn rate: the share of bad
+// requests divided by the share the objective allows (1 means the budget lasts exactly)
+func (r *routeSLO) window(now time.Time, d time.Duration) WindowStatus {
+	current := now.Unix() / 60
+	oldest := current - int64(d/time.Minute) + 1
+	var status WindowStatus
+	for _, bucket := range r.buckets {
+		if bucket.minute >= oldest && bucket.minute <= current {
+			status.Requests += bucket.total
+			status.Bad += bucket.bad
+		}
+	}
+	if status.Requests > 0 {
+		status.BurnRate = float64(status.Bad) / float64(status.Requests) / (1 - r.objective.Target)
+	}
+	return status
+}
+
+// Tee returns a Recorder that records every call into all of recorders
+func Tee(recorders ...Recorder) Recorder {
+	return tee(recorders)
+}
+
+// tee is the Recorder Tee returns
+type tee []Recorder
+
+// RecordCall records into every recorder
+func (t tee) RecordCall(component, method string, duration time.Duration, rows int, err error) {
+	for _, recorder := range t {
+		recorder.RecordCall(component, method, duration, rows, err)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build cgo && (linux || darwin || freebsd)
+
+// Package plugins collects the extension modules that add behavior to the API
+// This file loads modules from Go plugins on platforms that support them
+package plugins
+
+import (
+	"fmt"    // For formatted string operations and error wrapping
+	"plugin" // For opening Go plugins
+)
+
+// Load opens the Go plugin at path and registers the Module it exports
+// The plugin must be built with the same Go version and the same versions of every package
+// it shares with the API (including this module), or opening it fails
+func Load(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+	symbol, err := p.Lookup("Module")
+	if err != nil {
+		return fmt.Errorf("plugin %s doesn't export Module: %w", path, err)
+	}
+
+	// "var Module plugins.Module" and "var Module = &plugins.Module{...}" both work
+	switch module := symbol.(type) {
+	case *Module:
+		return register(module)
+	case **Module:
+		return register(*module)
+	default:
+		return fmt.Errorf("plugin %s exports Module as %T, not plugins.Module", path, symbol)
+	}
+}
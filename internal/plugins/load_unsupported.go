@@ -0,0 +1,13 @@
+//go:build !cgo || !(linux || darwin || freebsd)
+
+// Package plugins collects the extension modules that add behavior to the API
+// This file stands in for the plugin loader where Go plugins aren't available
+package plugins
+
+import "fmt" // For formatted string operations and error wrapping
+
+// Load always fails: this build can't open Go plugins
+// In-tree modules registered with build tags work everywhere
+func Load(path string) error {
+	return fmt.Errorf("%w: %s", ErrUnsupported, path)
+}
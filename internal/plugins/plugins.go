@@ -0,0 +1,139 @@
+// Package plugins collects the extension modules that add behavior to the API without
+// patching the core services
+//
+// A module is a Module value registered under a unique name. There are two ways to add one:
+//   - In-tree: a package that calls Register from its init function, compiled in with a build
+//     tag. A fork adds a file like cmd/api/modules_weekday.go, guarded by "//go:build weekday",
+//     that imports the package for its side effects, and builds with -tags weekday
+//   - Go plugins: a package built with -buildmode=plugin that exports a variable named Module.
+//     PLUGINS lists the .so files to load at start-up (see Load for the restrictions)
+//
+// Modules are set up once all repositories exist; their hooks and reports are then handed to
+// the services through the application options
+package plugins
+
+import (
+	"errors"  // For creating and comparing errors
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For listing modules by name
+	"strings" // For validating module names
+
+	"myexpenses/internal/clock"                // Time source
+	"myexpenses/internal/expenses/application" // Extension points of the use cases
+	"myexpenses/internal/expenses/domain"      // Import our domain layer
+)
+
+// ErrUnsupported occurs when Go plugins are loaded by a build that can't load them
+var ErrUnsupported = errors.New("go plugins are not supported by this build")
+
+// Module is one extension module and everything it adds
+// Every field but Name is optional
+type Module struct {
+	// Name identifies the module in logs and must be unique
+	Name string
+
+	// Setup is called once at start-up, before the API serves requests
+	// Modules that read data keep what they need from host
+	Setup func(host *Host) error
+
+	// BeforeCreate see expenses created through POST /expenses before they are saved
+	BeforeCreate []application.BeforeCreateHook
+
+	// AfterImport are told about the expenses each statement import created
+	AfterImport []application.AfterImportHook
+
+	// Reports are served under GET /reports/custom/{name}
+	Reports []*application.ReportProvider
+}
+
+// Host is what the API shares with modules during Setup
+// The repositories are scoped to the caller of each request, like the core services' are
+type Host struct {
+	Expenses   domain.Repository
+	Spending   domain.SpendingRepository
+	Categories domain.CategoryRepository
+	Budgets    domain.BudgetRepository
+	Clock      clock.Clock
+}
+
+// modules holds the registered modules by name
+var modules = map[string]*Module{}
+
+// Register adds a module; it is meant to be called from an init function
+// It panics if the name is empty or already taken, since that is a build mistake
+func Register(module *Module) {
+	if err := register(module); err != nil {
+		panic(err)
+	}
+}
+
+// register adds a module, or explains why it can't
+func register(module *Module) error {
+	if module == nil || strings.TrimSpace(module.Name) == "" {
+		return errors.New("plugins: module without a name")
+	}
+	if _, ok := modules[module.Name]; ok {
+		return fmt.Errorf("plugins: module %q registered twice", module.Name)
+	}
+	modules[module.Name] = module
+	return nil
+}
+
+// Modules returns the registered modules ordered by name
+func Modules() []*Module {
+	list := make([]*Module, 0, len(modules))
+	for _, module := range modules {
+		list = append(list, module)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Names returns the names of the registered modules in order
+func Names() []string {
+	names := make([]string, 0, len(modules))
+	for _, module := range Modules() {
+		names = append(names, module.Name)
+	}
+	return names
+}
+
+// Setup calls the Setup function of every module, in name order
+func Setup(host *Host) error {
+	for _, module := range Modules() {
+		if module.Setup == nil {
+			continue
+		}
+		if err := module.Setup(host); err != nil {
+			return fmt.Errorf("failed to set up module %s: %w", module.Name, err)
+		}
+	}
+	return nil
+}
+
+// BeforeCreateHooks returns the before-create hooks of every module, in name order
+func BeforeCreateHooks() []application.BeforeCreateHook {
+	var hooks []application.BeforeCreateHook
+	for _, module := range Modules() {
+		hooks = append(hooks, module.BeforeCreate...)
+	}
+	return hooks
+}
+
+// AfterImportHooks returns the after-import hooks of every module, in name order
+func AfterImportHooks() []application.AfterImportHook {
+	var hooks []application.AfterImportHook
+	for _, module := range Modules() {
+		hooks = append(hooks, module.AfterImport...)
+	}
+	return hooks
+}
+
+// Reports returns the report providers of every module
+func Reports() []*application.ReportProvider {
+	var reports []*application.ReportProvider
+	for _, module := range Modules() {
+		reports = append(reports, module.Reports...)
+	}
+	return reports
+}
@@ -0,0 +1,88 @@
+// Package weekday is an example extension module: a report of spending by day of the week
+// It is compiled in only with the "weekday" build tag (see cmd/api/modules_weekday.go), and
+// shows forks how a module registers itself, receives repositories and provides a report
+package weekday
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"fmt"     // For wrapping invalid periods
+	"time"    // For days of the week and the period filters
+
+	"myexpenses/internal/expenses/application" // Extension points of the use cases
+	"myexpenses/internal/expenses/domain"      // Import our domain layer
+	"myexpenses/internal/plugins"              // Module registry
+	"myexpenses/internal/report"               // Shared report renderers
+)
+
+// expenses is the expense repository the API shares with modules during Setup
+var expenses domain.Repository
+
+func init() {
+	plugins.Register(&plugins.Module{
+		Name: "weekday",
+		Setup: func(host *plugins.Host) error {
+			expenses = host.Expenses
+			return nil
+		},
+		Reports: []*application.ReportProvider{
+			{
+				Name:        "weekday",
+				Title:       "Spending by weekday",
+				Description: "How much is spent on each day of the week in ?period= (e.g. 2026-03)",
+				Build:       build,
+			},
+		},
+	})
+}
+
+// build adds up the caller's spending in the period by day of the week, Monday first
+func build(ctx context.Context, params map[string]string) (*report.Document, error) {
+	period, err := domain.ParsePeriod(params["period"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: period is required (YYYY, YYYY-MM, YYYY-MM-DD or a day range)", domain.ErrInvalidReportParameters)
+	}
+
+	var counts [7]int
+	var amounts [7]domain.Money
+	var total domain.Money
+	filters := map[string]interface{}{
+		"date_from": period.Start.Format(time.RFC3339),
+		"date_to":   period.End.Add(-time.Nanosecond).Format(time.RFC3339Nano),
+	}
+	err = expenses.Stream(ctx, filters, func(expense *domain.Expense) error {
+		// time.Weekday starts on Sunday; the report starts on Monday
+		day := (int(expense.Date.Weekday()) + 6) % 7
+		counts[day]++
+		amounts[day] = amounts[day].Add(expense.ReportingAmount())
+		total = total.Add(expense.ReportingAmount())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]report.Row, 0, 7)
+	for day := 0; day < 7; day++ {
+		name := time.Weekday((day + 1) % 7).String()
+		rows = append(rows, report.Row{name, counts[day], amounts[day].Float64()})
+	}
+	return &report.Document{
+		Title: "Spending by weekday " + period.Label,
+		Summary: []report.Field{
+			{Key: "period", Label: "Period", Kind: report.KindText, Value: period.Label},
+			{Key: "total", Label: "Total", Kind: report.KindAmount, Value: total.Float64()},
+		},
+		Sections: []*report.Section{
+			{
+				Key:   "weekdays",
+				Title: "By weekday",
+				Columns: []report.Column{
+					{Key: "weekday", Title: "Weekday", Kind: report.KindText},
+					{Key: "count", Title: "Expenses", Kind: report.KindNumber},
+					{Key: "amount", Title: "Amount", Kind: report.KindAmount},
+				},
+				Rows: report.SliceRows(rows),
+			},
+		},
+	}, nil
+}
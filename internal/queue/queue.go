@@ -0,0 +1,117 @@
+// Package queue runs background tasks on a fixed pool of workers
+// It is the in-process counterpart of the scheduler: the scheduler runs jobs on an interval,
+// the queue runs one-off tasks (e.g. a large export) as soon as a worker is free
+// Tasks live in memory only, so whatever is queued or running when the process stops is lost;
+// callers that need to survive a restart must record their tasks elsewhere and resubmit them
+package queue
+
+import (
+	"context" // For stopping tasks on shutdown
+	"errors"  // For the queue errors
+	"log"     // For logging task failures
+	"sync"    // For waiting on workers
+)
+
+// Task is a unit of background work
+// The context is cancelled when the queue stops
+type Task func(ctx context.Context) error
+
+// ErrFull occurs when enqueuing while every slot of the queue is taken
+var ErrFull = errors.New("task queue is full")
+
+// ErrNotRunning occurs when enqueuing before Start or after Stop
+var ErrNotRunning = errors.New("task queue is not running")
+
+// item is a queued task
+type item struct {
+	name string
+	task Task
+}
+
+// Queue runs enqueued tasks on a fixed number of workers, in the order they were enqueued
+type Queue struct {
+	workers int
+	items   chan item
+
+	mu      sync.RWMutex
+	running bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates a stopped queue with workers workers and room for capacity waiting tasks
+func New(workers, capacity int) *Queue {
+	if workers <= 0 {
+		workers = 1
+	}
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &Queue{
+		workers: workers,
+		items:   make(chan item, capacity),
+	}
+}
+
+// Start launches the workers
+func (q *Queue) Start(ctx context.Context) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.running {
+		return
+	}
+
+	ctx, q.cancel = context.WithCancel(ctx)
+	q.running = true
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+}
+
+// Stop cancels running tasks, drops the waiting ones and waits for the workers to return
+func (q *Queue) Stop() {
+	q.mu.Lock()
+	if !q.running {
+		q.mu.Unlock()
+		return
+	}
+	q.running = false
+	q.cancel()
+	q.mu.Unlock()
+
+	q.wg.Wait()
+}
+
+// Enqueue adds a task without waiting for a free slot
+// name identifies the task in logs
+func (q *Queue) Enqueue(name string, task Task) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if !q.running {
+		return ErrNotRunning
+	}
+
+	select {
+	case q.items <- item{name: name, task: task}:
+		return nil
+	default:
+		return ErrFull
+	}
+}
+
+// work runs tasks until the queue stops
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case it := <-q.items:
+			if err := it.task(ctx); err != nil {
+				log.Printf("queued task %s failed: %v", it.name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Package ratelimit limits how many requests a client may make, with a token bucket per client
+// This file weighs requests, so expensive endpoints take more of a client's budget than cheap ones
+package ratelimit
+
+import (
+	"errors"  // For configuration errors
+	"fmt"     // For formatted string operations and error wrapping
+	"sort"    // For matching the longest prefix first
+	"strconv" // For parsing weights
+	"strings" // For parsing weight lists and matching prefixes
+)
+
+// ErrInvalidCosts occurs when a cost weight list can't be parsed
+var ErrInvalidCosts = errors.New("invalid cost weights: use /prefix=cost pairs separated by commas, with whole costs")
+
+// DefaultCosts weighs exports and analytics above plain reads and writes, which cost 1
+// Health checks and metrics scrapes are free
+const DefaultCosts = "/exports=20,/reports=10,/insights=10,/dashboard=5,/expenses/search=5,/health=0,/metrics=0"
+
+// Costs weighs requests by the path they go to
+// The longest matching path prefix decides; paths no prefix matches cost 1, and a cost of 0 is free
+type Costs struct {
+	weights []weight
+}
+
+// weight is the cost of the paths under one prefix
+type weight struct {
+	prefix string
+	cost   int
+}
+
+// ParseCosts parses a comma-separated list of /prefix=cost pairs, like DefaultCosts
+func ParseCosts(spec string) (Costs, error) {
+	var costs Costs
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		prefix, value, ok := strings.Cut(pair, "=")
+		prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "/")
+		cost, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || !strings.HasPrefix(prefix, "/") || err != nil || cost < 0 {
+			return Costs{}, fmt.Errorf("%w: %q", ErrInvalidCosts, pair)
+		}
+		costs.weights = append(costs.weights, weight{prefix: prefix, cost: cost})
+	}
+	sort.SliceStable(costs.weights, func(i, j int) bool {
+		return len(costs.weights[i].prefix) > len(costs.weights[j].prefix)
+	})
+	return costs, nil
+}
+
+// Of returns the cost of a request to path
+func (c Costs) Of(path string) int {
+	for _, w := range c.weights {
+		if path == w.prefix || strings.HasPrefix(path, w.prefix+"/") {
+			return w.cost
+		}
+	}
+	return 1
+}
@@ -0,0 +1,167 @@
+// Package ratelimit limits how many requests a client may make, with a token bucket per client
+// Each client has a bucket holding up to Burst tokens that refills at Rate tokens per second;
+// every request takes a token (or its cost, see Costs), and a request finding too few is refused.
+// Buckets live in memory (one set per API instance) or in Redis (shared by all instances)
+package ratelimit
+
+import (
+	"context" // For request context (cancellation, timeouts)
+	"errors"  // For configuration errors
+	"math"    // For rounding waits up
+	"sync"    // For guarding the in-memory buckets
+	"time"    // For refill timing
+
+	"myexpenses/internal/clock" // Time source, so refills can be driven by a fake clock
+)
+
+// ErrInvalidConfig occurs when a limit has no positive rate or burst
+var ErrInvalidConfig = errors.New("invalid rate limit: rate and burst must be positive")
+
+// Config is a token bucket limit
+type Config struct {
+	// Rate is how many tokens are added per second (the sustained request rate)
+	Rate float64
+
+	// Burst is the bucket size: how many requests may be made at once after a quiet period
+	Burst int
+}
+
+// PerMinute returns the limit of requests per minute, with burst requests at once
+func PerMinute(requests, burst int) Config {
+	return Config{Rate: float64(requests) / 60, Burst: burst}
+}
+
+// validate checks the limit can ever let a request through
+func (c Config) validate() error {
+	if c.Rate <= 0 || c.Burst <= 0 {
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+// Result is the outcome of one request against its client's bucket
+type Result struct {
+	// Allowed says whether the request may proceed
+	Allowed bool
+
+	// Limit is the bucket size and Remaining the whole tokens left after this request
+	Limit     int
+	Remaining int
+
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+
+	// RetryAfter is how long until the next request is allowed (0 when Allowed)
+	RetryAfter time.Duration
+}
+
+// Limiter takes tokens from a client's bucket
+type Limiter interface {
+	// Allow takes a token from the bucket of key (e.g. a user ID)
+	Allow(ctx context.Context, key string) (Result, error)
+
+	// AllowN takes n tokens from the bucket of key, for requests that cost more than one
+	// n is capped at the bucket size, so every request can eventually go through
+	AllowN(ctx context.Context, key string, n int) (Result, error)
+}
+
+// cost caps the tokens a request takes at the bucket size
+func (c Config) cost(n int) float64 {
+	if n > c.Burst {
+		return float64(c.Burst)
+	}
+	return float64(n)
+}
+
+// result builds the Result for a bucket left with tokens (possibly fractional) after a request for cost tokens
+func (c Config) result(allowed bool, tokens, cost float64) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     c.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(c.Burst) - tokens) / c.Rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((cost - tokens) / c.Rate)
+	}
+	return result
+}
+
+// seconds converts a number of seconds into a duration
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
+
+// bucket is an in-memory token bucket
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// maxBuckets bounds how many clients are tracked before full (idle) buckets are dropped
+const maxBuckets = 100000
+
+// Memory is a Limiter keeping the buckets in process memory
+// Every API instance limits on its own, so N instances allow up to N times the configured rate
+type Memory struct {
+	config  Config
+	clock   clock.Clock
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewMemory creates an in-memory limiter
+func NewMemory(config Config, clk clock.Clock) (*Memory, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &Memory{
+		config:  config,
+		clock:   clock.Or(clk),
+		buckets: make(map[string]*bucket),
+	}, nil
+}
+
+// Allow implements Limiter
+func (m *Memory) Allow(ctx context.Context, key string) (Result, error) {
+	return m.AllowN(ctx, key, 1)
+}
+
+// AllowN implements Limiter
+func (m *Memory) AllowN(_ context.Context, key string, n int) (Result, error) {
+	cost := m.config.cost(n)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+
+	// Step 1: Refill the bucket for the time since the last request; new clients start full
+	b, ok := m.buckets[key]
+	if !ok {
+		if len(m.buckets) >= maxBuckets {
+			m.prune(now)
+		}
+		b = &bucket{tokens: float64(m.config.Burst), updated: now}
+		m.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(m.config.Burst), b.tokens+now.Sub(b.updated).Seconds()*m.config.Rate)
+	b.updated = now
+
+	// Step 2: Take the tokens if there are enough
+	allowed := b.tokens >= cost
+	if allowed {
+		b.tokens -= cost
+	}
+	return m.config.result(allowed, b.tokens, cost), nil
+}
+
+// prune drops the buckets that have refilled completely; they are the same as new ones. Callers hold mu
+func (m *Memory) prune(now time.Time) {
+	for key, b := range m.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*m.config.Rate >= float64(m.config.Burst) {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// Package ratelimit limits how many requests a client may make, with a token bucket per client
+// This file keeps the buckets in Redis, so every API instance draws from the same bucket
+package ratelimit
+
+import (
+	"bufio"        // For reading replies
+	"context"      // For request context (cancellation, timeouts)
+	"crypto/sha1"  // For the script digest EVALSHA runs it by
+	"crypto/tls"   // For rediss:// connections
+	"encoding/hex" // For encoding the script digest
+	"errors"       // For protocol errors
+	"fmt"          // For formatted string operations and error wrapping
+	"io"           // For reading bulk replies
+	"net"          // For connecting to Redis
+	"net/url"      // For parsing REDIS_URL
+	"strconv"      // For encoding and decoding numbers
+	"strings"      // For the database number in the URL path
+	"time"         // For timeouts and the bucket clock
+
+	"myexpenses/internal/clock" // Time source for refills
+)
+
+// redisTimeout bounds every Redis command whose context has no deadline
+const redisTimeout = time.Second
+
+// redisPoolSize is how many idle connections are kept
+const redisPoolSize = 8
+
+// tokenBucketScript refills and takes from a bucket atomically
+// KEYS[1] is the bucket; ARGV are the rate in tokens per millisecond, the burst, the time in milliseconds
+// and the tokens the request costs
+// It returns whether the request is allowed and the tokens left in thousandths (Redis truncates numbers to integers)
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(bucket[1])
+local updated = tonumber(bucket[2])
+if tokens == nil then
+  tokens = burst
+  updated = now
+end
+if now > updated then
+  tokens = math.min(burst, tokens + (now - updated) * rate)
+  updated = now
+end
+local allowed = 0
+if tokens >= cost then
+  tokens = tokens - cost
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(updated))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + 1000)
+return {allowed, math.floor(tokens * 1000)}
+`
+
+// tokenBucketSHA is the SHA1 digest Redis caches tokenBucketScript under
+// Requests run the script by its digest; only a server that hasn't seen it yet gets the source
+var tokenBucketSHA = func() string {
+	sum := sha1.Sum([]byte(tokenBucketScript))
+	return hex.EncodeToString(sum[:])
+}()
+
+// Redis is a Limiter keeping the buckets in Redis
+// Buckets expire once they would be full again, so idle clients cost nothing
+type Redis struct {
+	config Config
+	clock  clock.Clock
+	prefix string
+	pool   *redisPool
+}
+
+// NewRedis creates a limiter on the Redis server at rawURL
+// ("redis://[:password@]host:port[/db]", or rediss:// for TLS); keys are prefixed with prefix
+// The URL is only parsed here: connections are opened on first use
+func NewRedis(rawURL, prefix string, config Config, clk clock.Clock) (*Redis, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	pool, err := newRedisPool(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Redis{config: config, clock: clock.Or(clk), prefix: prefix, pool: pool}, nil
+}
+
+// Allow implements Limiter
+func (r *Redis) Allow(ctx context.Context, key string) (Result, error) {
+	return r.AllowN(ctx, key, 1)
+}
+
+// AllowN implements Limiter
+func (r *Redis) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	cost := r.config.cost(n)
+	now := r.clock.Now().UnixMilli()
+	args := []string{"1", r.prefix + key,
+		strconv.FormatFloat(r.config.Rate/1000, 'g', -1, 64),
+		strconv.Itoa(r.config.Burst),
+		strconv.FormatInt(now, 10),
+		strconv.FormatFloat(cost, 'g', -1, 64),
+	}
+	reply, err := r.pool.do(ctx, append([]string{"EVALSHA", tokenBucketSHA}, args...)...)
+	var serverErr redisError
+	if errors.As(err, &serverErr) && serverErr.noScript() {
+		// The server restarted or flushed its script cache; EVAL caches the script again
+		reply, err = r.pool.do(ctx, append([]string{"EVAL", tokenBucketScript}, args...)...)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	values, ok := reply.([]any)
+	if !ok || len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+	allowed, _ := values[0].(int64)
+	milliTokens, _ := values[1].(int64)
+	return r.config.result(allowed == 1, float64(milliTokens)/1000, cost), nil
+}
+
+// redisPool hands out connections to one Redis server
+type redisPool struct {
+	network  string
+	address  string
+	tls      *tls.Config
+	password string
+	username string
+	database int
+	idle     chan *redisConn
+}
+
+// newRedisPool parses a Redis URL
+func newRedisPool(rawURL string) (*redisPool, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+		return nil, fmt.Errorf("invalid Redis URL %q", rawURL)
+	}
+	pool := &redisPool{network: "tcp", address: u.Host, idle: make(chan *redisConn, redisPoolSize)}
+	if u.Port() == "" {
+		pool.address = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.Scheme == "rediss" {
+		pool.tls = &tls.Config{ServerName: u.Hostname()}
+	}
+	if u.User != nil {
+		pool.username = u.User.Username()
+		pool.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if pool.database, err = strconv.Atoi(db); err != nil || pool.database < 0 {
+			return nil, fmt.Errorf("invalid Redis database %q", db)
+		}
+	}
+	return pool, nil
+}
+
+// do runs one command on an idle connection (or a new one)
+// Connections that failed are closed rather than reused, since their state is unknown
+func (p *redisPool) do(ctx context.Context, args ...string) (any, error) {
+	var conn *redisConn
+	select {
+	case conn = <-p.idle:
+	default:
+		var err error
+		if conn, err = p.dial(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := conn.do(ctx, args...)
+	var serverErr redisError
+	if err != nil && !errors.As(err, &serverErr) {
+		conn.Close()
+		return nil, err
+	}
+	select {
+	case p.idle <- conn:
+	default:
+		conn.Close()
+	}
+	return reply, err
+}
+
+// dial opens a connection and logs in
+func (p *redisPool) dial(ctx context.Context) (*redisConn, error) {
+	dialer := &net.Dialer{Timeout: redisTimeout}
+	var c net.Conn
+	var err error
+	if p.tls != nil {
+		c, err = (&tls.Dialer{NetDialer: dialer, Config: p.tls}).DialContext(ctx, p.network, p.address)
+	} else {
+		c, err = dialer.DialContext(ctx, p.network, p.address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	conn := &redisConn{conn: c, reader: bufio.NewReader(c)}
+
+	// Step 1: Authenticate (Redis 6 ACL users send a user name too)
+	if p.password != "" {
+		args := []string{"AUTH", p.password}
+		if p.username != "" {
+			args = []string{"AUTH", p.username, p.password}
+		}
+		if _, err := conn.do(ctx, args...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	// Step 2: Select the database
+	if p.database != 0 {
+		if _, err := conn.do(ctx, "SELECT", strconv.Itoa(p.database)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// redisError is an error reply from the server
+type redisError string
+
+// Error implements error
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// noScript reports whether the server doesn't have the script EVALSHA asked for
+func (e redisError) noScript() bool {
+	return strings.HasPrefix(string(e), "NOSCRIPT")
+}
+
+// redisConn is one connection speaking RESP, the Redis protocol
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Close closes the connection
+func (c *redisConn) Close() error {
+	return c.conn.Close()
+}
+
+// do sends a command and reads its reply
+// Replies are string (simple and bulk strings), int64, []any, nil or a redisError
+func (c *redisConn) do(ctx context.Context, args ...string) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	// Commands are arrays of bulk strings
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+
+	reply, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	if serverErr, ok := reply.(redisError); ok {
+		return nil, serverErr
+	}
+	return reply, nil
+}
+
+// read reads one reply
+func (c *redisConn) read() (any, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, errors.New("redis: malformed reply")
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return redisError(body), nil
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		values := make([]any, count)
+		for i := range values {
+			if values[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply type %q", kind)
+	}
+}
@@ -0,0 +1,292 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"myexpenses/internal/clock"
+)
+
+func TestRedisConnRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    any
+		wantErr bool
+	}{
+		{name: "simple string", raw: "+OK\r\n", want: "OK"},
+		{name: "error", raw: "-NOSCRIPT No matching script\r\n", want: redisError("NOSCRIPT No matching script")},
+		{name: "integer", raw: ":42\r\n", want: int64(42)},
+		{name: "negative integer", raw: ":-7\r\n", want: int64(-7)},
+		{name: "bulk string", raw: "$5\r\nhello\r\n", want: "hello"},
+		{name: "bulk string with CRLF inside", raw: "$4\r\na\r\nb\r\n", want: "a\r\nb"},
+		{name: "empty bulk string", raw: "$0\r\n\r\n", want: ""},
+		{name: "nil bulk string", raw: "$-1\r\n", want: nil},
+		{name: "array", raw: "*2\r\n:1\r\n:2500\r\n", want: []any{int64(1), int64(2500)}},
+		{name: "nested array", raw: "*2\r\n*1\r\n+a\r\n$-1\r\n", want: []any{[]any{"a"}, nil}},
+		{name: "nil array", raw: "*-1\r\n", want: nil},
+		{name: "missing CRLF", raw: "+OK\n", wantErr: true},
+		{name: "bad integer", raw: ":x\r\n", wantErr: true},
+		{name: "truncated bulk string", raw: "$10\r\nshort\r\n", wantErr: true},
+		{name: "truncated array", raw: "*2\r\n:1\r\n", wantErr: true},
+		{name: "unknown type", raw: "?1\r\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &redisConn{reader: bufio.NewReader(strings.NewReader(tt.raw))}
+			got, err := conn.read()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("read() = %#v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("read() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("read() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedisBucketDeniesAndRefills(t *testing.T) {
+	server := newFakeRedis(t)
+	clk := clock.NewFake(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+	// One token a second, two at once
+	limiter, err := NewRedis("redis://"+server.address, "test:", PerMinute(60, 2), clk)
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+	ctx := context.Background()
+
+	allow := func(want bool, wantRemaining int) Result {
+		t.Helper()
+		result, err := limiter.Allow(ctx, "alice")
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		if result.Allowed != want || result.Remaining != wantRemaining {
+			t.Fatalf("Allow() = allowed %v, remaining %d; want %v, %d", result.Allowed, result.Remaining, want, wantRemaining)
+		}
+		return result
+	}
+
+	allow(true, 1)
+	allow(true, 0)
+	denied := allow(false, 0)
+	if denied.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v, want 1s", denied.RetryAfter)
+	}
+
+	// Other clients have their own bucket
+	if result, err := limiter.Allow(ctx, "bob"); err != nil || !result.Allowed {
+		t.Fatalf("Allow(bob) = %+v, %v; want allowed", result, err)
+	}
+
+	clk.Advance(500 * time.Millisecond)
+	allow(false, 0)
+	clk.Advance(500 * time.Millisecond)
+	allow(true, 0)
+
+	// The bucket never fills beyond its burst
+	clk.Advance(time.Minute)
+	allow(true, 1)
+
+	// Requests costing more than the burst are capped at it
+	result, err := limiter.AllowN(ctx, "alice", 5)
+	if err != nil || result.Allowed {
+		t.Fatalf("AllowN(5) = %+v, %v; want denied", result, err)
+	}
+	clk.Advance(time.Second)
+	if result, err = limiter.AllowN(ctx, "alice", 5); err != nil || !result.Allowed {
+		t.Fatalf("AllowN(5) = %+v, %v; want allowed once the bucket is full", result, err)
+	}
+}
+
+func TestRedisSendsScriptOnlyWhenNotCached(t *testing.T) {
+	server := newFakeRedis(t)
+	limiter, err := NewRedis("redis://"+server.address, "test:", PerMinute(60, 10), clock.NewFake(time.Unix(0, 0)))
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := limiter.Allow(ctx, "alice"); err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+	}
+	if got, want := server.commands(), []string{"EVALSHA", "EVAL", "EVALSHA", "EVALSHA"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("commands = %v, want %v", got, want)
+	}
+
+	// A server that lost its script cache gets the script again
+	server.flushScripts()
+	if _, err := limiter.Allow(ctx, "alice"); err != nil {
+		t.Fatalf("Allow() after SCRIPT FLUSH error = %v", err)
+	}
+	if got := server.commands(); !reflect.DeepEqual(got[len(got)-2:], []string{"EVALSHA", "EVAL"}) {
+		t.Errorf("commands after SCRIPT FLUSH = %v, want EVALSHA then EVAL", got)
+	}
+}
+
+func TestRedisReportsServerErrors(t *testing.T) {
+	server := newFakeRedis(t)
+	server.mu.Lock()
+	server.fail = "ERR out of memory"
+	server.mu.Unlock()
+	limiter, err := NewRedis("redis://"+server.address, "test:", PerMinute(60, 10), nil)
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+	_, err = limiter.Allow(context.Background(), "alice")
+	var serverErr redisError
+	if !errors.As(err, &serverErr) || string(serverErr) != "ERR out of memory" {
+		t.Fatalf("Allow() error = %v, want the server's error", err)
+	}
+}
+
+func TestNewRedisRejectsInvalidURLs(t *testing.T) {
+	for _, raw := range []string{"", "localhost:6379", "http://localhost", "redis://", "redis://localhost/x", "redis://localhost/-1"} {
+		if _, err := NewRedis(raw, "", PerMinute(60, 10), nil); err == nil {
+			t.Errorf("NewRedis(%q) succeeded, want an error", raw)
+		}
+	}
+}
+
+// fakeRedis is just enough of a Redis server for the limiter: it answers EVALSHA and EVAL of
+// tokenBucketScript by running the same token bucket in Go
+type fakeRedis struct {
+	address string
+
+	mu sync.Mutex
+	// fail, when set, is the error every command is answered with
+	fail    string
+	scripts map[string]bool
+	buckets map[string][2]float64
+	seen    []string
+}
+
+// newFakeRedis starts a fake server that is stopped when the test ends
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeRedis{address: listener.Addr().String(), scripts: map[string]bool{}, buckets: map[string][2]float64{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+// serve answers the commands sent on one connection
+func (s *fakeRedis) serve(c net.Conn) {
+	defer c.Close()
+	conn := &redisConn{conn: c, reader: bufio.NewReader(c)}
+	for {
+		request, err := conn.read()
+		if err != nil {
+			return
+		}
+		args, ok := request.([]any)
+		if !ok || len(args) == 0 {
+			return
+		}
+		words := make([]string, len(args))
+		for i, arg := range args {
+			words[i], _ = arg.(string)
+		}
+		if _, err := c.Write([]byte(s.answer(words))); err != nil {
+			return
+		}
+	}
+}
+
+// answer runs one command and encodes its reply
+func (s *fakeRedis) answer(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen = append(s.seen, args[0])
+	if s.fail != "" {
+		return "-" + s.fail + "\r\n"
+	}
+
+	switch args[0] {
+	case "EVAL":
+		sum := sha1.Sum([]byte(args[1]))
+		s.scripts[hex.EncodeToString(sum[:])] = true
+	case "EVALSHA":
+		if !s.scripts[args[1]] {
+			return "-NOSCRIPT No matching script. Please use EVAL.\r\n"
+		}
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+	if args[2] != "1" || len(args) != 8 {
+		return "-ERR wrong number of arguments\r\n"
+	}
+
+	allowed, milliTokens := s.take(args[3], number(args[4]), number(args[5]), number(args[6]), number(args[7]))
+	return "*2\r\n:" + strconv.Itoa(allowed) + "\r\n:" + strconv.FormatInt(milliTokens, 10) + "\r\n"
+}
+
+// take is tokenBucketScript in Go
+func (s *fakeRedis) take(key string, rate, burst, now, cost float64) (allowed int, milliTokens int64) {
+	tokens, updated := burst, now
+	if bucket, ok := s.buckets[key]; ok {
+		tokens, updated = bucket[0], bucket[1]
+	}
+	if now > updated {
+		tokens = math.Min(burst, tokens+(now-updated)*rate)
+		updated = now
+	}
+	if tokens >= cost {
+		tokens -= cost
+		allowed = 1
+	}
+	s.buckets[key] = [2]float64{tokens, updated}
+	return allowed, int64(math.Floor(tokens * 1000))
+}
+
+// commands returns the names of the commands received so far
+func (s *fakeRedis) commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.seen...)
+}
+
+// flushScripts forgets the cached scripts, like SCRIPT FLUSH or a restart
+func (s *fakeRedis) flushScripts() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts = map[string]bool{}
+}
+
+// number parses a numeric script argument
+func number(arg string) float64 {
+	value, _ := strconv.ParseFloat(arg, 64)
+	return value
+}
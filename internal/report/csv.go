@@ -0,0 +1,68 @@
+// Package report contains the output formats reports can be rendered in
+// This file contains the CSV renderer
+package report
+
+import (
+	"encoding/csv" // For correctly quoted CSV output
+	"io"           // For streaming rendered output
+)
+
+// CSVRenderer renders a Document as CSV
+// The summary comes first as label/value lines, then each section as a header line followed by
+// its rows, separated by blank lines, so spreadsheet users see the whole report in one sheet
+type CSVRenderer struct{}
+
+// ContentType returns the CSV MIME type
+func (CSVRenderer) ContentType() string { return "text/csv; charset=utf-8" }
+
+// Extension returns the CSV file extension
+func (CSVRenderer) Extension() string { return "csv" }
+
+// Render streams doc as CSV into w
+func (CSVRenderer) Render(w io.Writer, doc *Document) error {
+	out := csv.NewWriter(w)
+
+	// Step 1: Summary as label/value pairs
+	for _, field := range doc.Summary {
+		if err := out.Write([]string{field.Label, FormatValue(field.Kind, field.Value)}); err != nil {
+			return err
+		}
+	}
+
+	// Step 2: Each section with its own header line
+	for i, section := range doc.Sections {
+		if len(doc.Summary) > 0 || i > 0 {
+			if err := out.Write([]string{""}); err != nil {
+				return err
+			}
+		}
+		if err := out.Write([]string{section.Title}); err != nil {
+			return err
+		}
+
+		header := make([]string, len(section.Columns))
+		for j, column := range section.Columns {
+			header[j] = column.Title
+		}
+		if err := out.Write(header); err != nil {
+			return err
+		}
+
+		record := make([]string, len(section.Columns))
+		err := section.Rows(func(row Row) error {
+			for j, column := range section.Columns {
+				record[j] = ""
+				if j < len(row) {
+					record[j] = FormatValue(column.Kind, row[j])
+				}
+			}
+			return out.Write(record)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,106 @@
+// Package report contains the output formats reports can be rendered in
+// This file contains the HTML email renderer
+package report
+
+import (
+	"bufio"         // For buffering the many small writes
+	"html/template" // For escaping user content in HTML
+	"io"            // For streaming rendered output
+)
+
+// HTMLRenderer renders a Document as a self-contained HTML page suitable as an email body
+// Email clients ignore <style> blocks and external CSS, so all styling is inline
+type HTMLRenderer struct{}
+
+// ContentType returns the HTML MIME type
+func (HTMLRenderer) ContentType() string { return "text/html; charset=utf-8" }
+
+// Extension returns the HTML file extension
+func (HTMLRenderer) Extension() string { return "html" }
+
+// htmlTemplates are the fragments of the page
+// Rows are rendered one at a time with the "row" template so the page can be streamed
+var htmlTemplates = template.Must(template.New("html").Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="margin:0;padding:24px;background:#f5f5f5;font-family:Helvetica,Arial,sans-serif;color:#222">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="max-width:720px;margin:0 auto;background:#fff;padding:24px">
+<tr><td>
+{{with .Branding}}{{if .LogoURL}}<img src="{{.LogoURL}}" alt="{{.Name}}" style="max-height:48px;display:block;margin:0 0 8px">{{end}}
+{{if .Name}}<div style="font-size:14px;font-weight:bold;color:#444">{{.Name}}</div>{{end}}
+{{if .Header}}<div style="font-size:13px;color:#666;margin:4px 0 0">{{.Header}}</div>{{end}}
+<hr style="border:0;border-top:1px solid #eee;margin:12px 0 16px">{{end}}
+<h1 style="font-size:22px;margin:0 0 16px">{{.Title}}</h1>
+{{if .Summary}}<table cellpadding="4" cellspacing="0" style="margin-bottom:16px">
+{{range .Summary}}<tr><td style="color:#666">{{.Label}}</td><td style="font-weight:bold;text-align:right">{{.Text}}</td></tr>
+{{end}}</table>{{end}}
+{{end}}
+{{define "section"}}<h2 style="font-size:16px;margin:24px 0 8px">{{.Title}}</h2>
+<table width="100%" cellpadding="6" cellspacing="0" style="border-collapse:collapse;font-size:13px">
+<tr>{{range .Columns}}<th style="border-bottom:2px solid #ddd;text-align:{{if .Right}}right{{else}}left{{end}}">{{.Title}}</th>{{end}}</tr>
+{{end}}
+{{define "row"}}<tr>{{range .}}<td style="border-bottom:1px solid #eee;text-align:{{if .Right}}right{{else}}left{{end}}">{{.Text}}</td>{{end}}</tr>
+{{end}}
+{{define "section_end"}}</table>
+{{end}}
+{{define "foot"}}{{if .}}<p style="font-size:12px;color:#888;margin:24px 0 0;border-top:1px solid #eee;padding-top:12px">{{.}}</p>{{end}}
+</td></tr></table>
+</body></html>
+{{end}}`))
+
+// htmlCell is a formatted value with its alignment
+type htmlCell struct {
+	Title string
+	Label string
+	Text  string
+	Right bool
+}
+
+// Render streams doc as HTML into w
+func (HTMLRenderer) Render(w io.Writer, doc *Document) error {
+	out := bufio.NewWriter(w)
+
+	summary := make([]htmlCell, len(doc.Summary))
+	for i, field := range doc.Summary {
+		summary[i] = htmlCell{Label: field.Label, Text: FormatValue(field.Kind, field.Value)}
+	}
+	if err := htmlTemplates.ExecuteTemplate(out, "head", map[string]any{"Title": doc.Title, "Branding": doc.Branding, "Summary": summary}); err != nil {
+		return err
+	}
+
+	for _, section := range doc.Sections {
+		columns := make([]htmlCell, len(section.Columns))
+		for i, column := range section.Columns {
+			columns[i] = htmlCell{Title: column.Title, Right: rightAligned(column.Kind)}
+		}
+		if err := htmlTemplates.ExecuteTemplate(out, "section", map[string]any{"Title": section.Title, "Columns": columns}); err != nil {
+			return err
+		}
+
+		cells := make([]htmlCell, len(section.Columns))
+		err := section.Rows(func(row Row) error {
+			for i, column := range section.Columns {
+				cells[i] = htmlCell{Right: columns[i].Right}
+				if i < len(row) {
+					cells[i].Text = FormatValue(column.Kind, row[i])
+				}
+			}
+			return htmlTemplates.ExecuteTemplate(out, "row", cells)
+		})
+		if err != nil {
+			return err
+		}
+		if err := htmlTemplates.ExecuteTemplate(out, "section_end", nil); err != nil {
+			return err
+		}
+	}
+
+	footer := ""
+	if doc.Branding != nil {
+		footer = doc.Branding.Footer
+	}
+	if err := htmlTemplates.ExecuteTemplate(out, "foot", footer); err != nil {
+		return err
+	}
+	return out.Flush()
+}
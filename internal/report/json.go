@@ -0,0 +1,111 @@
+// Package report contains the output formats reports can be rendered in
+// This file contains the JSON renderer
+package report
+
+import (
+	"bufio"         // For buffering the many small writes
+	"encoding/json" // For encoding keys and values
+	"io"            // For streaming rendered output
+)
+
+// JSONRenderer renders a Document as a JSON object
+// Rows are written as objects keyed by column key, in column order, one at a time
+//
+//	{"title": "...", "summary": {...}, "sections": [{"key": "...", "title": "...", "rows": [{...}]}]}
+type JSONRenderer struct{}
+
+// ContentType returns the JSON MIME type
+func (JSONRenderer) ContentType() string { return "application/json; charset=utf-8" }
+
+// Extension returns the JSON file extension
+func (JSONRenderer) Extension() string { return "json" }
+
+// Render streams doc as JSON into w
+func (JSONRenderer) Render(w io.Writer, doc *Document) error {
+	out := &jsonWriter{w: bufio.NewWriter(w)}
+
+	out.raw(`{"title":`)
+	out.value(doc.Title)
+
+	out.raw(`,"summary":{`)
+	for i, field := range doc.Summary {
+		if i > 0 {
+			out.raw(",")
+		}
+		out.value(field.Key)
+		out.raw(":")
+		out.value(jsonValue(field.Value))
+	}
+	out.raw(`},"sections":[`)
+
+	for i, section := range doc.Sections {
+		if i > 0 {
+			out.raw(",")
+		}
+		out.raw(`{"key":`)
+		out.value(section.Key)
+		out.raw(`,"title":`)
+		out.value(section.Title)
+		out.raw(`,"rows":[`)
+
+		first := true
+		err := section.Rows(func(row Row) error {
+			if !first {
+				out.raw(",")
+			}
+			first = false
+
+			out.raw("{")
+			for j, column := range section.Columns {
+				if j > 0 {
+					out.raw(",")
+				}
+				out.value(column.Key)
+				out.raw(":")
+				if j < len(row) {
+					out.value(jsonValue(row[j]))
+				} else {
+					out.raw("null")
+				}
+			}
+			out.raw("}")
+			return out.err
+		})
+		if err != nil {
+			return err
+		}
+		out.raw("]}")
+	}
+	out.raw("]}\n")
+
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+// jsonWriter remembers the first write error so the render code can stay linear
+type jsonWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// raw writes s verbatim
+func (j *jsonWriter) raw(s string) {
+	if j.err == nil {
+		_, j.err = j.w.WriteString(s)
+	}
+}
+
+// value writes v encoded as JSON
+func (j *jsonWriter) value(v any) {
+	if j.err != nil {
+		return
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		j.err = err
+		return
+	}
+	_, j.err = j.w.Write(encoded)
+}
@@ -0,0 +1,272 @@
+// Package report contains the output formats reports can be rendered in
+// This file contains the PDF renderer
+package report
+
+import (
+	"bufio"   // For buffering output
+	"bytes"   // For building page content streams
+	"fmt"     // For writing PDF syntax
+	"io"      // For streaming rendered output
+	"strings" // For escaping text
+)
+
+// PDFRenderer renders a Document as a plain A4 PDF using the built-in Helvetica fonts
+// Pages are written as soon as they are full, so only one page is held in memory at a time
+// The page tree object is reserved up front and written last, which PDF allows because
+// objects are located through the cross-reference table rather than by position
+type PDFRenderer struct{}
+
+// ContentType returns the PDF MIME type
+func (PDFRenderer) ContentType() string { return "application/pdf" }
+
+// Extension returns the PDF file extension
+func (PDFRenderer) Extension() string { return "pdf" }
+
+// Page geometry in points (1/72 inch)
+const (
+	pdfPageWidth  = 595.0 // A4
+	pdfPageHeight = 842.0
+	pdfMargin     = 40.0
+	pdfFontSize   = 9.0
+	pdfLineHeight = 13.0
+	pdfCharWidth  = 5.0 // Approximate Helvetica advance at 9pt, used for truncation and alignment
+)
+
+// Fixed object numbers
+const (
+	pdfCatalogID  = 1
+	pdfPagesID    = 2
+	pdfFontID     = 3
+	pdfBoldFontID = 4
+)
+
+// Render streams doc as PDF into w
+func (PDFRenderer) Render(w io.Writer, doc *Document) error {
+	p := &pdfWriter{out: bufio.NewWriter(w), offsets: map[int]int64{}, nextID: pdfBoldFontID + 1}
+	if doc.Branding != nil {
+		p.footer = doc.Branding.Footer
+	}
+
+	// Step 1: Header, catalog and fonts
+	p.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	p.object(pdfCatalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesID))
+	p.object(pdfFontID, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	p.object(pdfBoldFontID, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	p.newPage()
+
+	// Step 2: Branding, title and summary
+	if doc.Branding != nil {
+		if doc.Branding.Name != "" {
+			p.line(doc.Branding.Name, pdfMargin, true, 11)
+		}
+		if doc.Branding.Header != "" {
+			p.line(doc.Branding.Header, pdfMargin, false, pdfFontSize)
+		}
+		p.gap()
+	}
+	p.line(doc.Title, pdfMargin, true, 14)
+	p.gap()
+	for _, field := range doc.Summary {
+		p.ensureSpace(1)
+		p.text(field.Label, pdfMargin, false, pdfFontSize)
+		p.text(FormatValue(field.Kind, field.Value), pdfMargin+200, true, pdfFontSize)
+		p.advance()
+	}
+
+	// Step 3: Sections, repeating the column header on every new page
+	for _, section := range doc.Sections {
+		p.gap()
+		p.ensureSpace(3)
+		p.line(section.Title, pdfMargin, true, 11)
+
+		widths := pdfColumnWidths(len(section.Columns))
+		header := func() {
+			x := pdfMargin
+			for i, column := range section.Columns {
+				p.cell(column.Title, x, widths[i], rightAligned(column.Kind), true)
+				x += widths[i]
+			}
+			p.advance()
+		}
+		header()
+
+		err := section.Rows(func(row Row) error {
+			if p.ensureSpace(1) {
+				header()
+			}
+			x := pdfMargin
+			for i, column := range section.Columns {
+				value := ""
+				if i < len(row) {
+					value = FormatValue(column.Kind, row[i])
+				}
+				p.cell(value, x, widths[i], rightAligned(column.Kind), false)
+				x += widths[i]
+			}
+			p.advance()
+			return p.err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Step 4: Last page, page tree, cross-reference table and trailer
+	p.flushPage()
+	kids := make([]string, len(p.pages))
+	for i, id := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+	p.object(pdfPagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+
+	xref := p.written
+	p.printf("xref\n0 %d\n0000000000 65535 f \n", p.nextID)
+	for id := 1; id < p.nextID; id++ {
+		p.printf("%010d 00000 n \n", p.offsets[id])
+	}
+	p.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", p.nextID, pdfCatalogID, xref)
+
+	if p.err != nil {
+		return p.err
+	}
+	return p.out.Flush()
+}
+
+// pdfColumnWidths splits the printable width evenly between n columns
+func pdfColumnWidths(n int) []float64 {
+	widths := make([]float64, n)
+	for i := range widths {
+		widths[i] = (pdfPageWidth - 2*pdfMargin) / float64(n)
+	}
+	return widths
+}
+
+// pdfWriter tracks object offsets and the page being filled
+type pdfWriter struct {
+	out     *bufio.Writer
+	written int64
+	offsets map[int]int64
+	nextID  int
+	pages   []int
+	err     error
+
+	content bytes.Buffer // content stream of the current page
+	y       float64      // baseline of the next line on the current page
+	footer  string       // branding footer printed at the bottom of every page
+}
+
+// printf writes PDF syntax and counts the bytes for the cross-reference table
+func (p *pdfWriter) printf(format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(p.out, format, args...)
+	p.written += int64(n)
+	p.err = err
+}
+
+// object writes an indirect object and records its offset
+func (p *pdfWriter) object(id int, body string) {
+	p.offsets[id] = p.written
+	p.printf("%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// newPage starts an empty page
+// The footer is drawn first, in the bottom margin, so it never collides with the rows
+func (p *pdfWriter) newPage() {
+	p.content.Reset()
+	if p.footer != "" {
+		p.y = pdfMargin / 2
+		p.cell(p.footer, pdfMargin, pdfPageWidth-2*pdfMargin, false, false)
+	}
+	p.y = pdfPageHeight - pdfMargin
+}
+
+// flushPage writes the current page's content stream and page object
+func (p *pdfWriter) flushPage() {
+	contentID, pageID := p.nextID, p.nextID+1
+	p.nextID += 2
+
+	p.offsets[contentID] = p.written
+	p.printf("%d 0 obj\n<< /Length %d >>\nstream\n", contentID, p.content.Len())
+	if p.err == nil {
+		n, err := p.out.Write(p.content.Bytes())
+		p.written += int64(n)
+		p.err = err
+	}
+	p.printf("\nendstream\nendobj\n")
+
+	p.object(pageID, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.0f %.0f] /Contents %d 0 R /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> >>",
+		pdfPagesID, pdfPageWidth, pdfPageHeight, contentID, pdfFontID, pdfBoldFontID))
+	p.pages = append(p.pages, pageID)
+}
+
+// ensureSpace starts a new page if fewer than lines lines are left
+// It reports whether a new page was started
+func (p *pdfWriter) ensureSpace(lines int) bool {
+	if p.y-float64(lines-1)*pdfLineHeight >= pdfMargin {
+		return false
+	}
+	p.flushPage()
+	p.newPage()
+	return true
+}
+
+// text draws s with its baseline at the current line
+func (p *pdfWriter) text(s string, x float64, bold bool, size float64) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, p.y, pdfEscape(s))
+}
+
+// cell draws s inside a column, truncated to fit and optionally right-aligned
+func (p *pdfWriter) cell(s string, x, width float64, right, bold bool) {
+	maxChars := int((width - 6) / pdfCharWidth)
+	runes := []rune(s)
+	if maxChars > 1 && len(runes) > maxChars {
+		runes = append(runes[:maxChars-1], '.')
+	}
+	if right {
+		x += width - 6 - float64(len(runes))*pdfCharWidth
+	}
+	p.text(string(runes), x, bold, pdfFontSize)
+}
+
+// line draws a single line of text and moves to the next line
+func (p *pdfWriter) line(s string, x float64, bold bool, size float64) {
+	p.text(s, x, bold, size)
+	p.y -= size + 4
+}
+
+// advance moves to the next line
+func (p *pdfWriter) advance() {
+	p.y -= pdfLineHeight
+}
+
+// gap leaves half a line of vertical space
+func (p *pdfWriter) gap() {
+	p.y -= pdfLineHeight / 2
+}
+
+// pdfEscape converts s to WinAnsi bytes inside a PDF string literal
+// Characters outside WinAnsi are replaced with '?'
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '€':
+			b.WriteByte(0x80)
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
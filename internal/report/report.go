@@ -0,0 +1,260 @@
+// Package report contains the output formats reports can be rendered in
+// A report describes its content once, as a Document made of tabular sections,
+// and every Renderer (JSON, CSV, PDF, HTML email) knows how to write any Document
+// This way a new report type supports all formats without format-specific code
+package report
+
+import (
+	"errors"  // For creating and comparing errors
+	"fmt"     // For formatting cell values
+	"io"      // For streaming rendered output
+	"sort"    // For listing the registered formats
+	"strings" // For normalizing format names
+	"time"    // For formatting date cells
+)
+
+// ErrUnknownFormat occurs when a report is requested in a format no renderer handles
+var ErrUnknownFormat = errors.New("unknown report format")
+
+// errStop is returned by an emit callback to stop a row source early (used by pagination)
+var errStop = errors.New("stop")
+
+// ColumnKind tells renderers how to format and align a column
+type ColumnKind string
+
+const (
+	// KindText is left-aligned free text
+	KindText ColumnKind = "text"
+
+	// KindAmount is a money amount with two decimals
+	KindAmount ColumnKind = "amount"
+
+	// KindPercent is a percentage with two decimals
+	KindPercent ColumnKind = "percent"
+
+	// KindNumber is an integer count
+	KindNumber ColumnKind = "number"
+
+	// KindDate is a calendar date
+	KindDate ColumnKind = "date"
+)
+
+// Column describes one column of a section
+type Column struct {
+	// Key is the machine-readable name (used as the JSON field name)
+	Key string
+
+	// Title is the human-readable header
+	Title string
+
+	// Kind controls formatting and alignment
+	Kind ColumnKind
+}
+
+// Row is one line of a section, with one value per column
+// nil values render as empty cells (or null in JSON)
+type Row []any
+
+// RowSource streams the rows of a section by calling emit once per row
+// Sources backed by a database cursor can emit rows as they are read,
+// so large reports never have to be held in memory
+// If emit returns an error the source must stop and return that error
+type RowSource func(emit func(Row) error) error
+
+// SliceRows returns a RowSource over rows that are already in memory
+func SliceRows(rows []Row) RowSource {
+	return func(emit func(Row) error) error {
+		for _, row := range rows {
+			if err := emit(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Field is a labelled summary value shown above the sections
+type Field struct {
+	Key   string
+	Label string
+	Kind  ColumnKind
+	Value any
+}
+
+// Section is a titled table
+type Section struct {
+	Key     string
+	Title   string
+	Columns []Column
+	Rows    RowSource
+}
+
+// Branding labels a rendered report with its organisation
+// The PDF and HTML renderers show it; the data formats (JSON, CSV) ignore it
+type Branding struct {
+	// Name is shown above the title
+	Name string
+
+	// LogoURL is an image shown above the title in HTML (PDFs only show the name)
+	LogoURL string
+
+	// Header is printed under the name, Footer at the bottom of the report
+	// (on every page of a PDF)
+	Header string
+	Footer string
+}
+
+// Document is a complete report ready to be rendered
+type Document struct {
+	// Title is shown as the report heading (and PDF/email title)
+	Title string
+
+	// Branding labels the report; nil renders it unbranded
+	Branding *Branding
+
+	// Summary holds the headline figures
+	Summary []Field
+
+	// Sections are the report's tables, rendered in order
+	Sections []*Section
+}
+
+// Renderer writes a Document in one output format
+type Renderer interface {
+	// ContentType is the MIME type of the output
+	ContentType() string
+
+	// Extension is the file extension used for downloads (without the dot)
+	Extension() string
+
+	// Render streams doc into w
+	Render(w io.Writer, doc *Document) error
+}
+
+// renderers holds the registered formats
+var renderers = map[string]Renderer{
+	"json": JSONRenderer{},
+	"csv":  CSVRenderer{},
+	"html": HTMLRenderer{},
+	"pdf":  PDFRenderer{},
+}
+
+// Register adds or replaces the renderer for a format
+// It must be called during start-up, before requests are served
+func Register(format string, renderer Renderer) {
+	renderers[strings.ToLower(format)] = renderer
+}
+
+// Lookup returns the renderer for a format name (case-insensitive)
+// An empty format means JSON
+func Lookup(format string) (Renderer, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = "json"
+	}
+	renderer, ok := renderers[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (supported: %s)", ErrUnknownFormat, format, strings.Join(Formats(), ", "))
+	}
+	return renderer, nil
+}
+
+// Formats lists the registered format names
+func Formats() []string {
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Paginate limits every section of doc to one page of rows
+// page is 1-based; a pageSize of 0 or less leaves the document unchanged
+func Paginate(doc *Document, page, pageSize int) *Document {
+	if pageSize <= 0 {
+		return doc
+	}
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * pageSize
+
+	paged := &Document{Title: doc.Title, Branding: doc.Branding, Summary: doc.Summary, Sections: make([]*Section, len(doc.Sections))}
+	for i, section := range doc.Sections {
+		copied := *section
+		copied.Rows = pageRows(section.Rows, offset, pageSize)
+		paged.Sections[i] = &copied
+	}
+	return paged
+}
+
+// pageRows skips offset rows of source and emits at most limit rows
+// The source is stopped as soon as the page is full
+func pageRows(source RowSource, offset, limit int) RowSource {
+	return func(emit func(Row) error) error {
+		index := 0
+		err := source(func(row Row) error {
+			defer func() { index++ }()
+			if index < offset {
+				return nil
+			}
+			if index >= offset+limit {
+				return errStop
+			}
+			return emit(row)
+		})
+		if errors.Is(err, errStop) {
+			return nil
+		}
+		return err
+	}
+}
+
+// FormatValue formats a cell or summary value as text for the text-based renderers
+func FormatValue(kind ColumnKind, value any) string {
+	if value == nil {
+		return ""
+	}
+	switch v := value.(type) {
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return FormatValue(kind, *v)
+	case float64:
+		switch kind {
+		case KindPercent:
+			return fmt.Sprintf("%.2f%%", v)
+		case KindNumber:
+			return fmt.Sprintf("%.0f", v)
+		default:
+			return fmt.Sprintf("%.2f", v)
+		}
+	case time.Time:
+		if kind == KindDate {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// jsonValue unwraps pointer values so they encode as plain numbers or null
+func jsonValue(value any) any {
+	if v, ok := value.(*float64); ok {
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return value
+}
+
+// rightAligned reports whether a column kind is numeric
+func rightAligned(kind ColumnKind) bool {
+	return kind == KindAmount || kind == KindPercent || kind == KindNumber
+}
@@ -0,0 +1,77 @@
+// Package scheduler runs background jobs at a fixed interval
+// It is intentionally small: jobs run in-process, one goroutine per job,
+// and a job never overlaps with itself because the next wait starts after the previous run
+package scheduler
+
+import (
+	"context" // For stopping jobs on shutdown
+	"log"     // For logging job failures
+	"sync"    // For waiting on running jobs
+	"time"    // For intervals
+
+	"myexpenses/internal/clock" // Time source, so schedules can be driven by a fake clock
+)
+
+// Job is a unit of background work
+// The context is cancelled when the scheduler stops
+type Job func(ctx context.Context) error
+
+// entry is a registered job
+type entry struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+// Scheduler runs registered jobs every interval until stopped
+type Scheduler struct {
+	clock   clock.Clock
+	entries []entry
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates an empty scheduler driven by clk (nil means the system clock)
+func New(clk clock.Clock) *Scheduler {
+	return &Scheduler{clock: clock.Or(clk)}
+}
+
+// Every registers job to run every interval, starting one interval after Start
+// It must be called before Start
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.entries = append(s.entries, entry{name: name, interval: interval, job: job})
+}
+
+// Start launches the registered jobs in the background
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.loop(ctx, e)
+	}
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// loop runs one job on its interval
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	defer s.wg.Done()
+
+	for {
+		// Wait for the next run; the wait starts after the previous run finished
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(e.interval):
+			if err := e.job(ctx); err != nil {
+				log.Printf("scheduled job %s failed: %v", e.name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,329 @@
+// Package script is a small, sandboxed expression language for admin-defined conditions
+// This file evaluates the parsed nodes
+package script
+
+import (
+	"fmt"     // For formatted error messages
+	"math"    // For abs, round and the remainder
+	"regexp"  // For matches
+	"strings" // For the string functions and operators
+)
+
+// node is one element of a parsed expression
+type node interface {
+	eval(env map[string]any) (any, error)
+}
+
+// literal is a number, string or boolean written in the expression
+type literal struct{ value any }
+
+func (n literal) eval(map[string]any) (any, error) { return n.value, nil }
+
+// variable reads one of the variables the expression was given
+type variable struct{ name string }
+
+func (n variable) eval(env map[string]any) (any, error) {
+	value, ok := env[n.name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no value", ErrRuntime, n.name)
+	}
+	return value, nil
+}
+
+// list is a list literal
+type list struct{ items []node }
+
+func (n list) eval(env map[string]any) (any, error) {
+	values := make([]any, len(n.items))
+	for i, item := range n.items {
+		value, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// unary is negation or logical not
+type unary struct {
+	op      string
+	operand node
+}
+
+func (n unary) eval(env map[string]any) (any, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: ! needs true/false, not %s", ErrRuntime, typeName(value))
+		}
+		return !b, nil
+	}
+	number, ok := value.(float64)
+	if !ok {
+		return nil, fmt.Errorf("%w: - needs a number, not %s", ErrRuntime, typeName(value))
+	}
+	return -number, nil
+}
+
+// matchNode matches text against a regular expression compiled with the program
+type matchNode struct {
+	text    node
+	pattern *regexp.Regexp
+}
+
+func (n matchNode) eval(env map[string]any) (any, error) {
+	value, err := n.text.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: matches needs a string, not %s", ErrRuntime, typeName(value))
+	}
+	return n.pattern.MatchString(text), nil
+}
+
+// binary is every operator with two operands
+type binary struct {
+	op          string
+	left, right node
+}
+
+func (n binary) eval(env map[string]any) (any, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || only evaluate the right side when they need it
+	switch n.op {
+	case "&&", "and", "||", "or":
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s needs true/false, not %s", ErrRuntime, n.op, typeName(left))
+		}
+		if (n.op == "&&" || n.op == "and") != l {
+			return l, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s needs true/false, not %s", ErrRuntime, n.op, typeName(right))
+		}
+		return r, nil
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		items, ok := right.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: in needs a list on the right, not %s", ErrRuntime, typeName(right))
+		}
+		return containsItem(items, left), nil
+	case "contains":
+		if items, ok := left.([]any); ok {
+			return containsItem(items, right), nil
+		}
+		return stringOp(n.op, left, right, strings.Contains)
+	case "startsWith":
+		return stringOp(n.op, left, right, strings.HasPrefix)
+	case "endsWith":
+		return stringOp(n.op, left, right, strings.HasSuffix)
+	case "+":
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
+		}
+	case "<", "<=", ">", ">=":
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return compare(n.op, strings.Compare(l, r)), nil
+			}
+		}
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%w: %s can't combine %s and %s", ErrRuntime, n.op, typeName(left), typeName(right))
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("%w: division by zero", ErrRuntime)
+		}
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return nil, fmt.Errorf("%w: division by zero", ErrRuntime)
+		}
+		return math.Mod(l, r), nil
+	default:
+		switch {
+		case l < r:
+			return compare(n.op, -1), nil
+		case l > r:
+			return compare(n.op, 1), nil
+		default:
+			return compare(n.op, 0), nil
+		}
+	}
+}
+
+// compare turns the result of a three-way comparison into the operator's answer
+func compare(op string, order int) bool {
+	switch op {
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	default:
+		return order >= 0
+	}
+}
+
+// equal compares two values; values of different types are never equal
+func equal(left, right any) bool {
+	l, lok := left.([]any)
+	r, rok := right.([]any)
+	if lok || rok {
+		if !lok || !rok || len(l) != len(r) {
+			return false
+		}
+		for i := range l {
+			if !equal(l[i], r[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return left == right
+}
+
+// containsItem reports whether items holds value
+func containsItem(items []any, value any) bool {
+	for _, item := range items {
+		if equal(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringOp applies a string predicate to two strings
+func stringOp(op string, left, right any, fn func(string, string) bool) (any, error) {
+	l, lok := left.(string)
+	r, rok := right.(string)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%w: %s needs strings, not %s and %s", ErrRuntime, op, typeName(left), typeName(right))
+	}
+	return fn(l, r), nil
+}
+
+// function is a built-in function
+type function struct {
+	arity int
+	apply func(args []any) (any, error)
+}
+
+// functions are the built-in functions, the only calls an expression can make
+var functions = map[string]function{
+	"lower": {1, stringFunction(strings.ToLower)},
+	"upper": {1, stringFunction(strings.ToUpper)},
+	"trim":  {1, stringFunction(strings.TrimSpace)},
+	"len": {1, func(args []any) (any, error) {
+		switch v := args[0].(type) {
+		case string:
+			return float64(len([]rune(v))), nil
+		case []any:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("%w: len needs a string or a list, not %s", ErrRuntime, typeName(args[0]))
+	}},
+	"abs":   {1, numberFunction(math.Abs)},
+	"round": {1, numberFunction(func(n float64) float64 { return math.Round(n*100) / 100 })},
+	"min":   {2, pairFunction(math.Min)},
+	"max":   {2, pairFunction(math.Max)},
+}
+
+// stringFunction adapts a string function
+func stringFunction(fn func(string) string) func(args []any) (any, error) {
+	return func(args []any) (any, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: needs a string, not %s", ErrRuntime, typeName(args[0]))
+		}
+		return fn(s), nil
+	}
+}
+
+// numberFunction adapts a function of one number
+func numberFunction(fn func(float64) float64) func(args []any) (any, error) {
+	return func(args []any) (any, error) {
+		n, ok := args[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("%w: needs a number, not %s", ErrRuntime, typeName(args[0]))
+		}
+		return fn(n), nil
+	}
+}
+
+// pairFunction adapts a function of two numbers
+func pairFunction(fn func(float64, float64) float64) func(args []any) (any, error) {
+	return func(args []any) (any, error) {
+		a, aok := args[0].(float64)
+		b, bok := args[1].(float64)
+		if !aok || !bok {
+			return nil, fmt.Errorf("%w: needs two numbers", ErrRuntime)
+		}
+		return fn(a, b), nil
+	}
+}
+
+// call is a call of a built-in function
+type call struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n call) eval(env map[string]any) (any, error) {
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	value, err := n.fn.apply(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return value, nil
+}
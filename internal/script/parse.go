@@ -0,0 +1,206 @@
+// Package script is a small, sandboxed expression language for admin-defined conditions
+// This file parses tokens into a tree of nodes, using precedence climbing
+package script
+
+import (
+	"fmt"     // For formatted error messages
+	"regexp"  // For compiling matches patterns
+	"strings" // For listing names in errors
+)
+
+// parser holds the state of one Compile
+type parser struct {
+	tokens    []token
+	index     int
+	depth     int
+	known     map[string]bool
+	variables []string
+}
+
+// binaryPrecedence gives the precedence of every binary operator; higher binds tighter
+var binaryPrecedence = map[string]int{
+	"||": 1, "or": 1,
+	"&&": 2, "and": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"in": 3, "contains": 3, "startsWith": 3, "endsWith": 3, "matches": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5,
+}
+
+// peek returns the next token without consuming it
+func (p *parser) peek() token {
+	return p.tokens[p.index]
+}
+
+// next consumes and returns the next token
+func (p *parser) next() token {
+	tok := p.tokens[p.index]
+	if tok.kind != tokenEOF {
+		p.index++
+	}
+	return tok
+}
+
+// expect consumes the next token, which must be the operator text
+func (p *parser) expect(text string) error {
+	if tok := p.next(); tok.kind != tokenOperator || tok.text != text {
+		return p.errorf(tok, "expected %q, found %s", text, tok)
+	}
+	return nil
+}
+
+// errorf reports a syntax error at tok
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return fmt.Errorf("%w at %d: %s", ErrSyntax, tok.pos, fmt.Sprintf(format, args...))
+}
+
+// operator returns the binary operator tok stands for, if any
+func operator(tok token) (string, bool) {
+	if tok.kind != tokenOperator && tok.kind != tokenIdent {
+		return "", false
+	}
+	_, ok := binaryPrecedence[tok.text]
+	return tok.text, ok
+}
+
+// parseExpression parses binary operators binding tighter than minPrecedence
+func (p *parser) parseExpression(minPrecedence int) (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > MaxDepth {
+		return nil, p.errorf(p.peek(), "nested deeper than %d levels", MaxDepth)
+	}
+
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		op, ok := operator(tok)
+		if !ok || binaryPrecedence[op] <= minPrecedence {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseExpression(binaryPrecedence[op])
+		if err != nil {
+			return nil, err
+		}
+		if op == "matches" {
+			pattern, ok := right.(literal)
+			text, isString := pattern.value.(string)
+			if !ok || !isString {
+				return nil, p.errorf(tok, "matches needs a string literal pattern")
+			}
+			re, err := regexp.Compile(text)
+			if err != nil {
+				return nil, p.errorf(tok, "invalid pattern: %v", err)
+			}
+			left = matchNode{text: left, pattern: re}
+			continue
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+}
+
+// parseUnary parses negation and logical not
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	if tok.text == "!" || tok.text == "-" || tok.kind == tokenIdent && tok.text == "not" {
+		p.next()
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > MaxDepth {
+			return nil, p.errorf(tok, "nested deeper than %d levels", MaxDepth)
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		op := tok.text
+		if op == "not" {
+			op = "!"
+		}
+		return unary{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses literals, variables, function calls, lists and parentheses
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber, tokenString:
+		return literal{value: tok.value}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return literal{value: true}, nil
+		case "false":
+			return literal{value: false}, nil
+		}
+		if next := p.peek(); next.kind == tokenOperator && next.text == "(" {
+			return p.parseCall(tok)
+		}
+		if !p.known[tok.text] {
+			return nil, p.errorf(tok, "unknown name %q (available: %s)", tok.text, strings.Join(sortedNames(p.variables), ", "))
+		}
+		return variable{name: tok.text}, nil
+	case tokenOperator:
+		switch tok.text {
+		case "(":
+			inner, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return list{items: items}, nil
+		}
+	}
+	return nil, p.errorf(tok, "unexpected %s", tok)
+}
+
+// parseCall parses the arguments of a call of a built-in function
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, p.errorf(name, "unknown function %q", name.text)
+	}
+	p.next() // (
+	args, err := p.parseList(")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != fn.arity {
+		return nil, p.errorf(name, "%s takes %d argument(s), not %d", name.text, fn.arity, len(args))
+	}
+	return call{name: name.text, fn: fn, args: args}, nil
+}
+
+// parseList parses comma-separated expressions up to the closing token
+func (p *parser) parseList(closing string) ([]node, error) {
+	var items []node
+	if tok := p.peek(); tok.kind == tokenOperator && tok.text == closing {
+		p.next()
+		return items, nil
+	}
+	for {
+		item, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		tok := p.next()
+		if tok.kind == tokenOperator && tok.text == closing {
+			return items, nil
+		}
+		if tok.kind != tokenOperator || tok.text != "," {
+			return nil, p.errorf(tok, "expected \",\" or %q, found %s", closing, tok)
+		}
+	}
+}
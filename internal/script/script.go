@@ -0,0 +1,155 @@
+// Package script is a small, sandboxed expression language for admin-defined conditions
+// (e.g. `amount > 200 && weekday in ["Saturday", "Sunday"]`)
+//
+// Expressions can only read the variables they are given and call the built-in functions
+// below: there are no assignments, loops, imports or access to the host, so evaluating one
+// always terminates and can't have side effects. Sources are limited in length and nesting,
+// and regular expressions use Go's linear-time RE2 engine
+//
+// The language:
+//   - literals: numbers (12, 3.5), strings ("x" or 'x'), true, false and lists ([1, 2])
+//   - arithmetic: + - * / % (+ also joins strings)
+//   - comparison: == != < <= > >= (numbers and strings)
+//   - logic: && || ! (also and, or, not)
+//   - membership: x in [..], list contains x, text contains "x", startsWith, endsWith
+//   - matches: text matches "regex" (the pattern must be a string literal)
+//   - functions: lower(s), upper(s), trim(s), len(s or list), abs(n), round(n), min(a, b), max(a, b)
+package script
+
+import (
+	"errors" // For creating and comparing errors
+	"fmt"    // For formatted error messages
+	"sort"   // For listing variable names in errors
+)
+
+// MaxSourceLength bounds the length of an expression
+const MaxSourceLength = 2000
+
+// MaxDepth bounds how deeply an expression may nest
+const MaxDepth = 32
+
+var (
+	// ErrSyntax occurs when an expression can't be compiled
+	ErrSyntax = errors.New("script syntax error")
+
+	// ErrRuntime occurs when an expression fails while being evaluated (e.g. a type mismatch)
+	ErrRuntime = errors.New("script error")
+)
+
+// Program is a compiled expression, safe to evaluate concurrently
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile parses source, allowing only the given variable names
+// Unknown variables are reported now rather than when the expression runs
+func Compile(source string, variables []string) (*Program, error) {
+	if len(source) > MaxSourceLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrSyntax, MaxSourceLength)
+	}
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(variables))
+	for _, name := range variables {
+		known[name] = true
+	}
+	p := &parser{tokens: tokens, known: known, variables: variables}
+	root, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+// Source returns the expression the program was compiled from
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Eval evaluates the program with the given variables
+// Values are float64 (ints are converted), string, bool or []any; anything else is refused
+func (p *Program) Eval(variables map[string]any) (any, error) {
+	env := make(map[string]any, len(variables))
+	for name, value := range variables {
+		normalized, err := normalize(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: variable %s: %v", ErrRuntime, name, err)
+		}
+		env[name] = normalized
+	}
+	return p.root.eval(env)
+}
+
+// EvalBool evaluates the program and requires a true or false result
+func (p *Program) EvalBool(variables map[string]any) (bool, error) {
+	value, err := p.Eval(variables)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: the expression gives %s, not true or false", ErrRuntime, typeName(value))
+	}
+	return result, nil
+}
+
+// normalize converts a Go value into one of the value types of the language
+func normalize(value any) (any, error) {
+	switch v := value.(type) {
+	case float64, string, bool:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float32:
+		return float64(v), nil
+	case []string:
+		list := make([]any, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list, nil
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			normalized, err := normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = normalized
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+// typeName names a value's type in error messages
+func typeName(value any) string {
+	switch value.(type) {
+	case float64:
+		return "a number"
+	case string:
+		return "a string"
+	case bool:
+		return "true/false"
+	case []any:
+		return "a list"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// sortedNames lists names for error messages
+func sortedNames(names []string) []string {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+	return sorted
+}
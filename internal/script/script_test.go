@@ -0,0 +1,236 @@
+package script
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testVariables are the variables every test expression may use
+var testVariables = []string{"amount", "category", "tags", "weekday", "note"}
+
+// testEnv gives them values
+var testEnv = map[string]any{
+	"amount":   250,
+	"category": "Travel",
+	"tags":     []string{"client", "q3"},
+	"weekday":  "Saturday",
+	"note":     "  Taxi to the airport ",
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		source string
+		want   any
+	}{
+		// Literals
+		{`12`, 12.0},
+		{`3.5`, 3.5},
+		{`.5`, 0.5},
+		{`"double"`, "double"},
+		{`'single'`, "single"},
+		{`"tab\there\nline \"quoted\""`, "tab\there\nline \"quoted\""},
+		{`true`, true},
+		{`false`, false},
+		{`[]`, []any{}},
+		{`[1, "a", true]`, []any{1.0, "a", true}},
+
+		// Arithmetic, with the usual precedence and left associativity
+		{`1 + 2 * 3`, 7.0},
+		{`(1 + 2) * 3`, 9.0},
+		{`10 - 4 - 3`, 3.0},
+		{`12 / 4 / 3`, 1.0},
+		{`7 % 4`, 3.0},
+		{`-amount + 50`, -200.0},
+		{`--2`, 2.0},
+		{`"a" + "b"`, "ab"},
+
+		// Comparison
+		{`amount > 200`, true},
+		{`amount >= 250`, true},
+		{`amount < 250`, false},
+		{`amount <= 249.99`, false},
+		{`amount == 250`, true},
+		{`amount != 250`, false},
+		{`"apple" < "banana"`, true},
+		{`category == "Travel"`, true},
+		{`category == 1`, false},
+		{`[1, 2] == [1, 2]`, true},
+		{`[1, 2] == [2, 1]`, false},
+
+		// Logic, in both spellings
+		{`amount > 200 && weekday in ["Saturday", "Sunday"]`, true},
+		{`amount > 300 || category == "Travel"`, true},
+		{`amount > 200 and not (category == "Meals")`, true},
+		{`false or true and false`, false},
+		{`!true`, false},
+		{`!!true`, true},
+
+		// && and || don't evaluate what they don't need
+		{`false && missing_value > 1`, false},
+		{`true || missing_value > 1`, true},
+
+		// Membership and string operators
+		{`"client" in tags`, true},
+		{`"internal" in tags`, false},
+		{`tags contains "q3"`, true},
+		{`note contains "Taxi"`, true},
+		{`category startsWith "Tr"`, true},
+		{`category endsWith "vel"`, true},
+		{`note matches "(?i)airport"`, true},
+		{`category matches "^Meal"`, false},
+
+		// Functions
+		{`lower(category)`, "travel"},
+		{`upper(category)`, "TRAVEL"},
+		{`trim(note)`, "Taxi to the airport"},
+		{`len("héllo")`, 5.0},
+		{`len(tags)`, 2.0},
+		{`abs(-3)`, 3.0},
+		{`round(2.345)`, 2.35},
+		{`min(amount, 100)`, 100.0},
+		{`max(amount, 100)`, 250.0},
+		{`lower(trim(note)) startsWith "taxi"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			program, err := Compile(tt.source, append(testVariables, "missing_value"))
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			got, err := program.Eval(testEnv)
+			if err != nil {
+				t.Fatalf("Eval() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eval() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantMsg string
+	}{
+		{"empty", ``, "unexpected end of expression"},
+		{"unknown variable", `amount > limit`, `unknown name "limit" (available: amount, category, note, tags, weekday)`},
+		{"unknown function", `sqrt(amount)`, `unknown function "sqrt"`},
+		{"wrong arity", `min(amount)`, "min takes 2 argument(s), not 1"},
+		{"unterminated string", `category == "Travel`, "unterminated string"},
+		{"unexpected character", `amount > 200 ; true`, `unexpected character ";"`},
+		{"invalid number", `1.2.3`, `invalid number "1.2.3"`},
+		{"missing operand", `amount >`, "unexpected end of expression"},
+		{"trailing tokens", `amount 200`, `unexpected "200"`},
+		{"unclosed parenthesis", `(amount > 200`, `expected ")"`},
+		{"unclosed list", `weekday in ["Saturday"`, `expected "," or "]"`},
+		{"single =", `amount = 200`, `unexpected character "="`},
+		{"pattern not a literal", `note matches category`, "matches needs a string literal pattern"},
+		{"invalid pattern", `note matches "("`, "invalid pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.source, testVariables)
+			if !errors.Is(err, ErrSyntax) {
+				t.Fatalf("Compile(%q) error = %v, want ErrSyntax", tt.source, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("Compile(%q) error = %q, want it to mention %q", tt.source, err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestCompileLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr bool
+	}{
+		{"longest source", `amount > 1` + strings.Repeat(" ", MaxSourceLength-len(`amount > 1`)), false},
+		{"source too long", `amount > 1` + strings.Repeat(" ", MaxSourceLength-len(`amount > 1`)+1), true},
+		{"deepest parentheses", strings.Repeat("(", MaxDepth-1) + "1" + strings.Repeat(")", MaxDepth-1), false},
+		{"parentheses too deep", strings.Repeat("(", MaxDepth) + "1" + strings.Repeat(")", MaxDepth), true},
+		{"deepest negation", strings.Repeat("!", MaxDepth-1) + "true", false},
+		{"negation too deep", strings.Repeat("!", MaxDepth) + "true", true},
+		{"nested lists too deep", strings.Repeat("[", MaxDepth) + "1" + strings.Repeat("]", MaxDepth), true},
+		// Long chains of one operator don't nest
+		{"long chain", "1" + strings.Repeat(" + 1", 200), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.source, testVariables)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Compile() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrSyntax) {
+				t.Errorf("Compile() error = %v, want ErrSyntax", err)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		env     map[string]any
+		wantMsg string
+	}{
+		{"number plus string", `amount + category`, testEnv, "+ can't combine a number and a string"},
+		{"comparing a list", `tags > 1`, testEnv, "> can't combine a list and a number"},
+		{"and on a number", `amount && true`, testEnv, "&& needs true/false, not a number"},
+		{"or with a string", `false || category`, testEnv, "|| needs true/false, not a string"},
+		{"not a number", `!amount`, testEnv, "! needs true/false, not a number"},
+		{"negate a string", `-category`, testEnv, "- needs a number, not a string"},
+		{"in without a list", `"a" in category`, testEnv, "in needs a list on the right, not a string"},
+		{"startsWith a number", `amount startsWith "2"`, testEnv, "startsWith needs strings, not a number and a string"},
+		{"matches a number", `amount matches "2"`, testEnv, "matches needs a string, not a number"},
+		{"division by zero", `amount / 0`, testEnv, "division by zero"},
+		{"remainder by zero", `amount % 0`, testEnv, "division by zero"},
+		{"function type", `lower(amount)`, testEnv, "lower: script error: needs a string, not a number"},
+		{"len of a number", `len(amount)`, testEnv, "len needs a string or a list, not a number"},
+		{"min of strings", `min(category, 1)`, testEnv, "needs two numbers"},
+		{"missing variable", `amount > 1`, map[string]any{}, "amount has no value"},
+		{"unsupported variable type", `amount > 1`, map[string]any{"amount": struct{}{}}, "variable amount: unsupported type struct {}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.source, testVariables)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			_, err = program.Eval(tt.env)
+			if !errors.Is(err, ErrRuntime) {
+				t.Fatalf("Eval() error = %v, want ErrRuntime", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("Eval() error = %q, want it to mention %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestEvalBool(t *testing.T) {
+	program, err := Compile(`amount > 200`, testVariables)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if got, err := program.EvalBool(testEnv); err != nil || !got {
+		t.Errorf("EvalBool() = %v, %v; want true", got, err)
+	}
+	if program.Source() != `amount > 200` {
+		t.Errorf("Source() = %q", program.Source())
+	}
+
+	program, err = Compile(`amount + 1`, testVariables)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if _, err := program.EvalBool(testEnv); !errors.Is(err, ErrRuntime) || !strings.Contains(err.Error(), "gives a number, not true or false") {
+		t.Errorf("EvalBool() error = %v, want a non-boolean result error", err)
+	}
+}
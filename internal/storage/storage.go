@@ -0,0 +1,146 @@
+// Package storage contains the blob storage abstraction used for uploaded files
+// Receipts, invoices and other attachments are binary files that don't belong in PostgreSQL
+// This package hides where those bytes actually live (local disk today, object storage later)
+package storage
+
+import (
+	"context"       // For request context (cancellation, timeouts)
+	"errors"        // For creating and comparing errors
+	"fmt"           // For formatted string operations and error wrapping
+	"io"            // For streaming file contents without loading them fully in memory
+	"os"            // For file system operations
+	"path/filepath" // For building OS-independent file paths
+	"strings"       // For validating storage keys
+)
+
+// ErrObjectNotFound occurs when a blob with the given key doesn't exist in the storage backend
+var ErrObjectNotFound = errors.New("storage object not found")
+
+// ErrInvalidKey occurs when a storage key is empty or tries to escape the storage root
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage defines the contract for storing and retrieving binary objects
+// Keys are opaque, slash-separated strings chosen by the caller (e.g. "attachments/<id>")
+// Any backend (local disk, S3, GCS) can implement this interface
+type Storage interface {
+	// Put streams the content of r into the object identified by key
+	// Returns the number of bytes written
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+
+	// Get opens the object identified by key for reading
+	// The caller is responsible for closing the returned reader
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the object identified by key
+	// Deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalStorage implements Storage on the local file system
+// It is the default backend for development and single-node deployments
+type LocalStorage struct {
+	// root is the directory under which all objects are stored
+	root string
+}
+
+// NewLocalStorage creates a new file system backed storage rooted at dir
+// The directory is created if it doesn't exist yet
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	// 0o750 gives the owner full access and the group read access
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStorage{root: dir}, nil
+}
+
+// Put writes the content of r to a file derived from key
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	// Step 1: Resolve the key to a path inside the storage root
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	// Step 2: Make sure the parent directory exists
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	// Step 3: Write to a temporary file first and rename it afterwards
+	// This way readers never see a half-written object
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once the rename succeeded
+
+	// Step 4: Copy the content, honouring context cancellation
+	written, err := io.Copy(tmp, &contextReader{ctx: ctx, r: r})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write object: %w", err)
+	}
+
+	// Step 5: Atomically move the file into place
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to store object: %w", err)
+	}
+	return written, nil
+}
+
+// Get opens the file derived from key for reading
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to open object: %w", err)
+	}
+	return file, nil
+}
+
+// Delete removes the file derived from key
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	// A missing file is fine - the end result is the same
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// path converts a storage key into an absolute path inside the storage root
+// It rejects keys that would escape the root (e.g. "../../etc/passwd")
+func (s *LocalStorage) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, "..") || strings.HasPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// contextReader wraps an io.Reader and stops reading once the context is cancelled
+// This prevents large uploads from continuing after the client went away
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read implements io.Reader
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
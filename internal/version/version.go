@@ -0,0 +1,41 @@
+// Package version describes the build that is running
+// The variables are set at build time with -ldflags, for example:
+//
+//	go build -ldflags "-X myexpenses/internal/version.Version=1.4.0 \
+//	  -X myexpenses/internal/version.Commit=$(git rev-parse HEAD) \
+//	  -X myexpenses/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/api
+//
+// Builds without ldflags (e.g. go run) report "dev" and "unknown"
+package version
+
+import "runtime" // For the Go version the binary was built with
+
+// Build information, overridden with -ldflags "-X ..."
+var (
+	// Version is the release version of the build
+	Version = "dev"
+
+	// Commit is the git SHA the build was made from
+	Commit = "unknown"
+
+	// BuildTime is when the build was made, in RFC 3339
+	BuildTime = "unknown"
+)
+
+// Info is the build information of the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}